@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -9,7 +10,8 @@ import (
 
 // BackupRetentionPolicy is a policy used to trigger automatic deletion of
 // backups produced by a particular schedule. Deletion will be triggered by
-// count (keeping a maximum number of backups around).
+// count (keeping a maximum number of backups around), by age and by the
+// total storage size of the backups.
 type BackupRetentionPolicy struct {
 	// BackupRetention is the number of successful backups to keep around.
 	// The default is 7.
@@ -19,6 +21,55 @@ type BackupRetentionPolicy struct {
 	// +kubebuilder:validation:Maximum=512
 	// +optional
 	BackupRetention *int32 `json:"backupRetention,omitempty"`
+
+	// MaxAgeDays deletes the backups created more than the number of days
+	// ago. The newest successful backup is kept regardless.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxAgeDays *int32 `json:"maxAgeDays,omitempty"`
+
+	// MaxTotalSize deletes the oldest backups once the backups take more
+	// storage in total, backups outside of GCS count as empty. The newest
+	// successful backup is kept regardless.
+	// +optional
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+}
+
+// BackupPruneReason is the retention limit a backup was deleted for.
+type BackupPruneReason string
+
+const (
+	// BackupPrunedByCount is the reason of the backups deleted beyond the
+	// BackupRetention count.
+	BackupPrunedByCount BackupPruneReason = "Count"
+	// BackupPrunedByAge is the reason of the backups deleted for being older
+	// than MaxAgeDays.
+	BackupPrunedByAge BackupPruneReason = "Age"
+	// BackupPrunedBySize is the reason of the backups deleted beyond the
+	// MaxTotalSize.
+	BackupPrunedBySize BackupPruneReason = "Size"
+)
+
+//+kubebuilder:object:generate=true
+
+// PrunedBackupRecord is a record of a Backup deleted by the retention policy.
+type PrunedBackupRecord struct {
+	// BackupName is the name of the deleted Backup.
+	BackupName string `json:"backupName"`
+
+	// CreationTime is the time that the Backup was created.
+	// +nullable
+	CreationTime metav1.Time `json:"creationTime"`
+
+	// PruneTime is the time that the Backup was deleted.
+	PruneTime metav1.Time `json:"pruneTime"`
+
+	// Reason is the retention limit the Backup was deleted for.
+	Reason BackupPruneReason `json:"reason"`
+
+	// Message explains the reason.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:generate=true
@@ -85,6 +136,16 @@ type BackupScheduleStatus struct {
 	// BackupHistory stores the records for up to 7 of the latest backups.
 	// +optional
 	BackupHistory []BackupHistoryRecord `json:"backupHistory,omitempty"`
+
+	// BackupTotalSize is the total storage size of the current existing
+	// backups, measured when MaxTotalSize is set.
+	// +optional
+	BackupTotalSize *resource.Quantity `json:"backupTotalSize,omitempty"`
+
+	// PrunedBackups stores the records for up to 7 of the latest backups
+	// deleted by the retention policy.
+	// +optional
+	PrunedBackups []PrunedBackupRecord `json:"prunedBackups,omitempty"`
 }
 
 // BackupSchedule represent the contract for the Anthos DB Operator compliant
@@ -165,6 +165,39 @@ type PodSpec struct {
 	// an instance on a node with a corresponding taint
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// SecurityContext holds pod-level security attributes of Instance Pods.
+	// Fields set here override the defaults derived from the database UID
+	// and GID.
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// ContainerSecurityContexts holds the security attributes of the
+	// individual containers of Instance Pods.
+	// +optional
+	ContainerSecurityContexts *ContainerSecurityContexts `json:"containerSecurityContexts,omitempty"`
+}
+
+// ContainerSecurityContexts holds per container security attributes. Fields
+// set here override the operator defaults of the matching container.
+// +kubebuilder:object:generate=true
+type ContainerSecurityContexts struct {
+	// Database is applied to the database container and to the init
+	// container preparing the agents.
+	// +optional
+	Database *corev1.SecurityContext `json:"database,omitempty"`
+
+	// DBDaemon is applied to the database daemon container.
+	// +optional
+	DBDaemon *corev1.SecurityContext `json:"dbdaemon,omitempty"`
+
+	// Logging is applied to the alert and listener log sidecars.
+	// +optional
+	Logging *corev1.SecurityContext `json:"logging,omitempty"`
+
+	// Monitoring is applied to the monitoring agent container.
+	// +optional
+	Monitoring *corev1.SecurityContext `json:"monitoring,omitempty"`
 }
 
 // DBLoadBalancerOptions contains customization options for the Kubernetes
@@ -88,6 +88,16 @@ func (in *BackupRetentionPolicy) DeepCopyInto(out *BackupRetentionPolicy) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxAgeDays != nil {
+		in, out := &in.MaxAgeDays, &out.MaxAgeDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTotalSize != nil {
+		in, out := &in.MaxTotalSize, &out.MaxTotalSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionPolicy.
@@ -156,6 +166,18 @@ func (in *BackupScheduleStatus) DeepCopyInto(out *BackupScheduleStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BackupTotalSize != nil {
+		in, out := &in.BackupTotalSize, &out.BackupTotalSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PrunedBackups != nil {
+		in, out := &in.PrunedBackups, &out.PrunedBackups
+		*out = make([]PrunedBackupRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleStatus.
@@ -246,6 +268,41 @@ func (in *ConfigSpec) DeepCopy() *ConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerSecurityContexts) DeepCopyInto(out *ContainerSecurityContexts) {
+	*out = *in
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DBDaemon != nil {
+		in, out := &in.DBDaemon, &out.DBDaemon
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSecurityContexts.
+func (in *ContainerSecurityContexts) DeepCopy() *ContainerSecurityContexts {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerSecurityContexts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CredentialSpec) DeepCopyInto(out *CredentialSpec) {
 	*out = *in
@@ -637,6 +694,16 @@ func (in *PodSpec) DeepCopyInto(out *PodSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ContainerSecurityContexts != nil {
+		in, out := &in.ContainerSecurityContexts, &out.ContainerSecurityContexts
+		*out = new(ContainerSecurityContexts)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSpec.
@@ -649,6 +716,23 @@ func (in *PodSpec) DeepCopy() *PodSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrunedBackupRecord) DeepCopyInto(out *PrunedBackupRecord) {
+	*out = *in
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+	in.PruneTime.DeepCopyInto(&out.PruneTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrunedBackupRecord.
+func (in *PrunedBackupRecord) DeepCopy() *PrunedBackupRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(PrunedBackupRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRetention) DeepCopyInto(out *ResourceRetention) {
 	*out = *in
@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -53,6 +56,9 @@ type backupScheduleControl interface {
 type backupControl interface {
 	List(cronAnythingName string) ([]v1alpha1.Backup, error)
 	Delete(backup v1alpha1.Backup) error
+	// Size returns the storage size of a backup in bytes, as recorded in
+	// its status.
+	Size(backup v1alpha1.Backup) (int64, error)
 }
 
 var _ reconcile.Reconciler = &BackupScheduleReconciler{}
@@ -65,6 +71,7 @@ type BackupScheduleReconciler struct {
 	backupScheduleCtrl backupScheduleControl
 	cronAnythingCtrl   cronAnythingControl
 	backupCtrl         backupControl
+	currentTime        func() time.Time
 }
 
 // Reconcile is a generic reconcile function for BackupSchedule resources.
@@ -91,25 +98,26 @@ func (r *BackupScheduleReconciler) Reconcile(_ context.Context, req ctrl.Request
 		return reconcile.Result{}, err
 	}
 
-	var backups []v1alpha1.Backup
+	backups, err := r.getSortedBackupsForCron(cron)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	prune, pruneErr := r.pruneBackups(backupSchedule.BackupScheduleSpec().BackupRetentionPolicy, backups, r.currentTime())
 
 	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-		backups, err = r.getSortedBackupsForCron(cron)
-		if err != nil {
-			return err
-		}
 		backupSchedule, err := r.backupScheduleCtrl.Get(req.Name, req.Namespace)
 		if err != nil {
 			return err
 		}
-		return r.updateHistory(backupSchedule, backups)
+		recordPrunedBackups(backupSchedule, prune)
+		return r.updateHistory(backupSchedule, prune.kept)
 	})
 
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	return ctrl.Result{}, r.pruneBackups(backupSchedule.BackupScheduleSpec().BackupRetentionPolicy, backups)
+	return ctrl.Result{}, pruneErr
 }
 
 // NewBackupScheduleReconciler returns a BackupScheduleReconciler object.
@@ -121,6 +129,7 @@ func NewBackupScheduleReconciler(mgr manager.Manager, bsCtrl backupScheduleContr
 		backupScheduleCtrl: bsCtrl,
 		cronAnythingCtrl:   caCtrl,
 		backupCtrl:         backupCtrl,
+		currentTime:        time.Now,
 	}
 }
 
@@ -227,28 +236,121 @@ func (r *BackupScheduleReconciler) updateHistory(backupSchedule v1alpha1.BackupS
 	return r.backupScheduleCtrl.UpdateStatus(backupSchedule)
 }
 
-func (r *BackupScheduleReconciler) pruneBackups(retention *v1alpha1.BackupRetentionPolicy, sortedBackups []v1alpha1.Backup) error {
+// pruneResult is the outcome of applying a retention policy to the backups
+// of a schedule.
+type pruneResult struct {
+	// kept are the backups, newest first, the policy keeps.
+	kept []v1alpha1.Backup
+	// pruned are the records of the backups deleted, newest first.
+	pruned []v1alpha1.PrunedBackupRecord
+	// totalSize is the storage size of the kept backups, only measured
+	// when the policy limits it.
+	totalSize *resource.Quantity
+}
+
+// pruneBackups deletes the backups, sorted newest first, the retention
+// policy doesn't keep: those beyond the retention count of successful
+// backups, those older than the max age and the oldest ones beyond the max
+// total size. Backups in progress and the newest successful backup are
+// only deleted by count.
+func (r *BackupScheduleReconciler) pruneBackups(retention *v1alpha1.BackupRetentionPolicy, sortedBackups []v1alpha1.Backup, now time.Time) (pruneResult, error) {
 	max := defaultRetention
-	if retention != nil && retention.BackupRetention != nil {
-		max = *retention.BackupRetention
-	}
-	if max == 0 {
-		return nil
+	var maxAge time.Duration
+	var maxSize *resource.Quantity
+	if retention != nil {
+		if retention.BackupRetention != nil {
+			max = *retention.BackupRetention
+		}
+		if retention.MaxAgeDays != nil {
+			maxAge = time.Duration(*retention.MaxAgeDays) * 24 * time.Hour
+		}
+		maxSize = retention.MaxTotalSize
 	}
 
+	var result pruneResult
+	var totalSize int64
 	count := max
-	for _, backup := range sortedBackups {
-		if count <= 0 {
-			r.Log.Info("deleting backup", "backup", backup)
-			if err := r.backupCtrl.Delete(backup); err != nil {
-				return err
+	keptSucceeded := false
+	for i, backup := range sortedBackups {
+		phase := backup.BackupStatus().Phase
+		finished := phase == v1alpha1.BackupSucceeded || phase == v1alpha1.BackupFailed
+		var size int64
+		if maxSize != nil {
+			var err error
+			if size, err = r.backupCtrl.Size(backup); err != nil {
+				result.kept = append(result.kept, sortedBackups[i:]...)
+				return result, fmt.Errorf("failed to get the size of backup %q: %v", backup.GetName(), err)
+			}
+		}
+
+		var reason v1alpha1.BackupPruneReason
+		var message string
+		created := backup.GetCreationTimestamp()
+		switch {
+		case max > 0 && count <= 0:
+			reason, message = v1alpha1.BackupPrunedByCount, fmt.Sprintf("%d newer successful backups are retained", max)
+		case !finished || (phase == v1alpha1.BackupSucceeded && !keptSucceeded):
+			// Kept regardless of age and size.
+		case maxAge > 0 && created.Time.Add(maxAge).Before(now):
+			reason, message = v1alpha1.BackupPrunedByAge, fmt.Sprintf("created more than %d days ago", *retention.MaxAgeDays)
+		case maxSize != nil && totalSize+size > maxSize.Value():
+			reason, message = v1alpha1.BackupPrunedBySize, fmt.Sprintf("its %s would take the backups over %s", resource.NewQuantity(size, resource.BinarySI), maxSize)
+		}
+
+		if reason == "" {
+			result.kept = append(result.kept, backup)
+			totalSize += size
+			if phase == v1alpha1.BackupSucceeded {
+				keptSucceeded = true
+				if count > 0 {
+					count -= 1
+				}
 			}
+			continue
 		}
-		if backup.BackupStatus().Phase == v1alpha1.BackupSucceeded && count > 0 {
-			count -= 1
+		r.Log.Info("deleting backup", "backup", backup.GetName(), "reason", reason, "message", message)
+		if err := r.backupCtrl.Delete(backup); err != nil {
+			result.kept = append(result.kept, sortedBackups[i:]...)
+			return result, err
 		}
+		result.pruned = append(result.pruned, v1alpha1.PrunedBackupRecord{
+			BackupName:   backup.GetName(),
+			CreationTime: created,
+			PruneTime:    metav1.NewTime(now),
+			Reason:       reason,
+			Message:      message,
+		})
 	}
-	return nil
+	if maxSize != nil {
+		result.totalSize = resource.NewQuantity(totalSize, resource.BinarySI)
+	}
+	return result, nil
+}
+
+// recordPrunedBackups adds the records of the backups pruned to the status
+// of the schedule, a backup still needed by others is pruned again until
+// it is deleted and only recorded once.
+func recordPrunedBackups(backupSchedule v1alpha1.BackupSchedule, prune pruneResult) {
+	status := backupSchedule.BackupScheduleStatus()
+	status.BackupTotalSize = prune.totalSize
+	recorded := make(map[string]bool)
+	for _, record := range status.PrunedBackups {
+		recorded[record.BackupName] = true
+	}
+	var records []v1alpha1.PrunedBackupRecord
+	for _, record := range prune.pruned {
+		if !recorded[record.BackupName] {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		return
+	}
+	records = append(records, status.PrunedBackups...)
+	if len(records) > int(defaultMaxHistoryRecords) {
+		records = records[:defaultMaxHistoryRecords]
+	}
+	status.PrunedBackups = records
 }
 
 func (r *BackupScheduleReconciler) compareTemplate(left, right []byte) (bool, error) {
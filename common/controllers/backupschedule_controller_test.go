@@ -17,6 +17,7 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -24,6 +25,7 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -417,6 +419,137 @@ func TestReconcileWithBackupPrune(t *testing.T) {
 	}
 }
 
+func TestPruneBackups(t *testing.T) {
+	reconciler, _, _, backupCtrl := newTestBackupScheduleReconciler()
+	now := timeFromStr(t, "2020-12-21T04:30:00Z")
+	backups := makeSortedBackups(t, 5)
+	for i, b := range backups {
+		b.Name = fmt.Sprintf("backup-%d", i)
+	}
+	// The backups are 1GiB each but backup-2 of 2GiB, the newest is in
+	// progress.
+	backups[0].Status.Phase = v1alpha1.BackupInProgress
+	backupCtrl.size = func(backup v1alpha1.Backup) (int64, error) {
+		if backup.GetName() == "backup-2" {
+			return 2 << 30, nil
+		}
+		return 1 << 30, nil
+	}
+	maxSize := resource.MustParse("2.5Gi")
+
+	testCases := []struct {
+		name        string
+		retention   *v1alpha1.BackupRetentionPolicy
+		now         time.Time
+		wantDeleted []string
+		wantReasons []v1alpha1.BackupPruneReason
+		wantSize    *resource.Quantity
+	}{
+		{
+			name:      "within the default count",
+			retention: nil,
+			now:       now,
+		},
+		{
+			name:        "by count",
+			retention:   &v1alpha1.BackupRetentionPolicy{BackupRetention: pointer.Int32Ptr(2)},
+			now:         now,
+			wantDeleted: []string{"backup-3", "backup-4"},
+			wantReasons: []v1alpha1.BackupPruneReason{v1alpha1.BackupPrunedByCount, v1alpha1.BackupPrunedByCount},
+		},
+		{
+			name:        "by age",
+			retention:   &v1alpha1.BackupRetentionPolicy{MaxAgeDays: pointer.Int32Ptr(1)},
+			now:         now.Add(21 * time.Hour),
+			wantDeleted: []string{"backup-3", "backup-4"},
+			wantReasons: []v1alpha1.BackupPruneReason{v1alpha1.BackupPrunedByAge, v1alpha1.BackupPrunedByAge},
+		},
+		{
+			name:        "by age keeps the newest successful backup",
+			retention:   &v1alpha1.BackupRetentionPolicy{MaxAgeDays: pointer.Int32Ptr(1)},
+			now:         now.Add(30 * 24 * time.Hour),
+			wantDeleted: []string{"backup-2", "backup-3", "backup-4"},
+			wantReasons: []v1alpha1.BackupPruneReason{v1alpha1.BackupPrunedByAge, v1alpha1.BackupPrunedByAge, v1alpha1.BackupPrunedByAge},
+		},
+		{
+			name:        "by size",
+			retention:   &v1alpha1.BackupRetentionPolicy{MaxTotalSize: &maxSize},
+			now:         now,
+			wantDeleted: []string{"backup-2", "backup-3", "backup-4"},
+			wantReasons: []v1alpha1.BackupPruneReason{v1alpha1.BackupPrunedBySize, v1alpha1.BackupPrunedBySize, v1alpha1.BackupPrunedBySize},
+			wantSize:    resource.NewQuantity(2<<30, resource.BinarySI),
+		},
+		{
+			name:        "by size and count",
+			retention:   &v1alpha1.BackupRetentionPolicy{BackupRetention: pointer.Int32Ptr(2), MaxTotalSize: resource.NewQuantity(3<<30, resource.BinarySI)},
+			now:         now,
+			wantDeleted: []string{"backup-2", "backup-4"},
+			wantReasons: []v1alpha1.BackupPruneReason{v1alpha1.BackupPrunedBySize, v1alpha1.BackupPrunedByCount},
+			wantSize:    resource.NewQuantity(3<<30, resource.BinarySI),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotDeleted []string
+			backupCtrl.delete = func(backup v1alpha1.Backup) error {
+				gotDeleted = append(gotDeleted, backup.GetName())
+				return nil
+			}
+			var sortedBackups []v1alpha1.Backup
+			for _, b := range backups {
+				sortedBackups = append(sortedBackups, b)
+			}
+			got, err := reconciler.pruneBackups(tc.retention, sortedBackups, tc.now)
+			if err != nil {
+				t.Fatalf("reconciler.pruneBackups want nil, got %v", err)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, gotDeleted); diff != "" {
+				t.Errorf("reconciler.pruneBackups got unexpected backups deleted: -want +got %v", diff)
+			}
+			var gotReasons []v1alpha1.BackupPruneReason
+			for _, record := range got.pruned {
+				gotReasons = append(gotReasons, record.Reason)
+			}
+			if diff := cmp.Diff(tc.wantReasons, gotReasons); diff != "" {
+				t.Errorf("reconciler.pruneBackups got unexpected reasons: -want +got %v", diff)
+			}
+			if len(got.kept)+len(gotDeleted) != len(backups) {
+				t.Errorf("reconciler.pruneBackups kept %d backups, want %d", len(got.kept), len(backups)-len(gotDeleted))
+			}
+			if (got.totalSize == nil) != (tc.wantSize == nil) || (got.totalSize != nil && got.totalSize.Cmp(*tc.wantSize) != 0) {
+				t.Errorf("reconciler.pruneBackups got total size %v, want %v", got.totalSize, tc.wantSize)
+			}
+		})
+	}
+}
+
+func TestRecordPrunedBackups(t *testing.T) {
+	schedule := &mockBackupSchedule{}
+	record := func(name string) v1alpha1.PrunedBackupRecord {
+		return v1alpha1.PrunedBackupRecord{BackupName: name, Reason: v1alpha1.BackupPrunedByAge}
+	}
+	recordPrunedBackups(schedule, pruneResult{pruned: []v1alpha1.PrunedBackupRecord{record("b1"), record("b0")}})
+	// A backup kept by its incremental chain is pruned again.
+	recordPrunedBackups(schedule, pruneResult{pruned: []v1alpha1.PrunedBackupRecord{record("b3"), record("b2"), record("b0")}})
+	var got []string
+	for _, r := range schedule.Status.PrunedBackups {
+		got = append(got, r.BackupName)
+	}
+	if diff := cmp.Diff([]string{"b3", "b2", "b1", "b0"}, got); diff != "" {
+		t.Errorf("recordPrunedBackups got unexpected records: -want +got %v", diff)
+	}
+
+	var many []v1alpha1.PrunedBackupRecord
+	for i := 10; i < 20; i++ {
+		many = append(many, record(fmt.Sprint(i)))
+	}
+	recordPrunedBackups(schedule, pruneResult{pruned: many})
+	if len(schedule.Status.PrunedBackups) != int(defaultMaxHistoryRecords) {
+		t.Errorf("recordPrunedBackups kept %d records, want %d", len(schedule.Status.PrunedBackups), defaultMaxHistoryRecords)
+	}
+}
+
 func TestUpdateBackupHistory(t *testing.T) {
 	reconciler, backupScheduleCtrl, _, _ := newTestBackupScheduleReconciler()
 	testCases := []struct {
@@ -492,6 +625,7 @@ func newTestBackupScheduleReconciler() (reconciler *BackupScheduleReconciler,
 		backupScheduleCtrl: backupScheduleCtrl,
 		cronAnythingCtrl:   cronAnythingCtrl,
 		backupCtrl:         backupCtrl,
+		currentTime:        time.Now,
 	}, backupScheduleCtrl, cronAnythingCtrl, backupCtrl
 }
 
@@ -603,6 +737,7 @@ func (f *mockCronAnythingControl) UpdateStatus(cron v1alpha1.CronAnything) error
 type mockBackupControl struct {
 	list   func(cronAnythingName string) ([]v1alpha1.Backup, error)
 	delete func(backup v1alpha1.Backup) error
+	size   func(backup v1alpha1.Backup) (int64, error)
 }
 
 func (f *mockBackupControl) List(cronAnythingName string) ([]v1alpha1.Backup, error) {
@@ -611,6 +746,9 @@ func (f *mockBackupControl) List(cronAnythingName string) ([]v1alpha1.Backup, er
 func (f *mockBackupControl) Delete(backup v1alpha1.Backup) error {
 	return f.delete(backup)
 }
+func (f *mockBackupControl) Size(backup v1alpha1.Backup) (int64, error) {
+	return f.size(backup)
+}
 
 func diffSpecs(t *testing.T, got, want string) {
 	if diff := cmp.Diff(got, want); diff != "" {
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -40,27 +41,78 @@ func ReadConfig(config []byte) ([]MetricSet, error) {
 
 	// Validate and transform config values
 	for i := 0; i < len(ms); i++ {
-		if err := validPromName(ms[i].Name); err != nil {
-			return nil, err
-		}
-		if err := validPromName(ms[i].Namespace); err != nil {
+		if err := validateMetricSet(&ms[i]); err != nil {
 			return nil, err
 		}
+	}
 
-		foundNonLabel := false
-		for j := 0; j < len(ms[i].Metrics); j++ {
-			foundNonLabel = foundNonLabel || ms[i].Metrics[j].Usage != Label
-			ms[i].Metrics[j].column = strings.ToLower(ms[i].Metrics[j].Name)
-			if err := validPromName(ms[i].Metrics[j].Name); err != nil {
-				return nil, err
-			}
+	return ms, nil
+}
+
+// ReadConfigDir reads every file in dir as a list of MetricSets. Unlike
+// ReadConfig a file or MetricSet which fails to parse does not fail the
+// whole config, it is logged and skipped. This is used for user provided
+// metrics which should never stop the default metrics from being exported.
+func ReadConfigDir(log logr.Logger, dir string) []MetricSet {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Error(err, "failed to read custom config directory", "dir", dir)
+		return nil
+	}
+	var ms []MetricSet
+	for _, e := range entries {
+		// Skip the hidden files and directories of ConfigMap volumes.
+		if strings.HasPrefix(e.Name(), ".") || e.IsDir() {
+			continue
 		}
-		if !foundNonLabel {
-			return nil, fmt.Errorf("MetricSet %s does not contain a reportable metric (only Labels found)", ms[i].Name)
+		path := filepath.Join(dir, e.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Error(err, "failed to read custom config", "configFile", path)
+			continue
+		}
+		conf := []MetricSet{}
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			log.Error(err, "failed to parse custom config", "configFile", path)
+			continue
+		}
+		for i := range conf {
+			if err := validateMetricSet(&conf[i]); err != nil {
+				log.Error(err, "skipping invalid custom metric set", "configFile", path, "metricSet", conf[i].Name)
+				continue
+			}
+			log.Info("Loaded custom metric set", "configFile", path, "metricSet", conf[i].Name)
+			ms = append(ms, conf[i])
 		}
 	}
+	return ms
+}
 
-	return ms, nil
+// validateMetricSet validates the names of a MetricSet and its metrics and
+// fills out their internal fields.
+func validateMetricSet(ms *MetricSet) error {
+	if err := validPromName(ms.Name); err != nil {
+		return err
+	}
+	if err := validPromName(ms.Namespace); err != nil {
+		return err
+	}
+	if ms.Query == "" {
+		return fmt.Errorf("MetricSet %s does not contain a query", ms.Name)
+	}
+
+	foundNonLabel := false
+	for j := 0; j < len(ms.Metrics); j++ {
+		foundNonLabel = foundNonLabel || ms.Metrics[j].Usage != Label
+		ms.Metrics[j].column = strings.ToLower(ms.Metrics[j].Name)
+		if err := validPromName(ms.Metrics[j].Name); err != nil {
+			return err
+		}
+	}
+	if !foundNonLabel {
+		return fmt.Errorf("MetricSet %s does not contain a reportable metric (only Labels found)", ms.Name)
+	}
+	return nil
 }
 
 // Return the DSN as specified by the DATA_SOURCE* env vars, reading the
@@ -90,7 +142,10 @@ func GetDefaultDSN(log logr.Logger) *url.URL {
 	return uri
 }
 
-func StartExporting(log logr.Logger, reg *prometheus.Registry, db DBFactory, configFiles []string, extraLabels map[string]string) {
+// StartExporting serves the metrics of the MetricSets in configFiles, which
+// must all be valid, and of the MetricSets in customConfigDir, if set, which
+// are loaded with ReadConfigDir.
+func StartExporting(log logr.Logger, reg *prometheus.Registry, db DBFactory, configFiles []string, customConfigDir string, extraLabels map[string]string) {
 	var ms []MetricSet
 	for _, c := range configFiles {
 		log.Info("Loading config", "path", c)
@@ -106,6 +161,10 @@ func StartExporting(log logr.Logger, reg *prometheus.Registry, db DBFactory, con
 		}
 		ms = append(ms, conf...)
 	}
+	if customConfigDir != "" {
+		log.Info("Loading custom config", "dir", customConfigDir)
+		ms = append(ms, ReadConfigDir(log, customConfigDir)...)
+	}
 
 	mon := NewMonitor(log, db, ms)
 	prometheus.WrapRegistererWith(extraLabels, reg).MustRegister(mon)
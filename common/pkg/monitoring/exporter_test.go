@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -188,3 +189,46 @@ func TestReadConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestReadConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"valid": `
+- name: process
+  namespace: custom
+  query: SELECT COUNT(*) as count FROM v$process
+  metrics:
+    - name: count
+      desc: Gauge metric with count of processes.
+      usage: gauge
+- name: some
+  namespace: custom
+  query: select * from dual
+  metrics:
+    -  name: a
+       desc: b
+       usage: label
+`,
+		"malformed":  "- 11193101jf1",
+		".hidden":    "- name: hidden",
+		"no_queries": "- name: empty\n  namespace: custom\n  metrics:\n    - name: a\n      usage: gauge\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Unable to write %s: %v", name, err)
+		}
+	}
+
+	ms := ReadConfigDir(klog.NewKlogr(), dir)
+	var got []string
+	for _, m := range ms {
+		got = append(got, m.Namespace+"_"+m.Name)
+	}
+	if diff := cmp.Diff([]string{"custom_process"}, got); diff != "" {
+		t.Errorf("ReadConfigDir() got unexpected metric sets (-want +got):\n%v", diff)
+	}
+
+	if ms := ReadConfigDir(klog.NewKlogr(), filepath.Join(dir, "missing")); ms != nil {
+		t.Errorf("ReadConfigDir() on a missing directory got %+v, want nil", ms)
+	}
+}
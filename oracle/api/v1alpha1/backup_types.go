@@ -42,8 +42,11 @@ type BackupSpec struct {
 	// Mode specifies how this backup will be managed by the operator.
 	// if it is not set, the operator tries to create a backup based on the specifications.
 	// if it is set to VerifyExists, the operator verifies the existence of a backup.
+	// if it is set to ValidateRestore, the operator validates that the
+	// database can be restored from its backups and uploads a report to
+	// the GCS path, without creating a backup.
 	// +optional
-	// +kubebuilder:validation:Enum=VerifyExists
+	// +kubebuilder:validation:Enum=VerifyExists;ValidateRestore
 	Mode BackupMode `json:"mode,omitempty"`
 
 	// Backup sub-type, which is only relevant for a Physical backup type
@@ -59,6 +62,13 @@ type BackupSpec struct {
 	// as the default set via the Config (global user preferences).
 	VolumeSnapshotClass string `json:"volumeSnapshotClass,omitempty"`
 
+	// For a Snapshot backup, optionally shut down the database before the
+	// volume snapshots are taken and start it up once they are cut, for a
+	// consistent cold backup instead of a crash-consistent one. The
+	// database is unavailable meanwhile. The default is false.
+	// +optional
+	Cold bool `json:"cold,omitempty"`
+
 	// For a Physical backup this slice can be used to indicate what
 	// PDBs, schemas, tablespaces or tables to back up.
 	// +optional
@@ -80,12 +90,30 @@ type BackupSpec struct {
 	CheckLogical bool `json:"checkLogical,omitempty"`
 
 	// For a Physical backup, optionally indicate a degree of parallelism
-	// also known as DOP.
+	// also known as DOP. The number of backup channels is capped at half
+	// the CPU limit of the database container unless IgnoreCPULimit is set.
 	// +optional
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=100
 	Dop int32 `json:"dop,omitempty"`
 
+	// For a Physical backup, optionally allocate as many backup channels as
+	// the DOP even if they oversubscribe the CPU limit of the database
+	// container and slow down the database workload. The default is false.
+	// +optional
+	IgnoreCPULimit bool `json:"ignoreCPULimit,omitempty"`
+
+	// For a Physical backup to a GCS bucket, optionally stream the backup
+	// pieces of the datafiles from each of the dop backup channels through
+	// a named pipe straight to GCS, instead of staging the whole backup on
+	// local disk before uploading it. Each channel writes its datafiles in
+	// a single backup piece, which rules out a sectionSize, a filesperset
+	// and image copies. A restore from the backup streams the pieces back
+	// the same way.
+	// The default is false.
+	// +optional
+	StreamToGcs bool `json:"streamToGcs,omitempty"`
+
 	// For a Physical backup, optionally specify an incremental level.
 	// The default is 0 (the whole database).
 	// +optional
@@ -138,6 +166,9 @@ const (
 	// VerifyExists means the operator will verify the existence of a backup
 	// instead of creating a new backup.
 	VerifyExists BackupMode = "VerifyExists"
+	// ValidateRestore means the operator will run an RMAN restore validation
+	// of the database instead of creating a new backup.
+	ValidateRestore BackupMode = "ValidateRestore"
 )
 
 // BackupStatus defines the observed state of Backup.
@@ -151,6 +182,57 @@ type BackupStatus struct {
 	StartTime *metav1.Time `json:"startTime,omitempty"`
 	// +optional
 	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Size is the storage size of a physical backup in GCS, measured once
+	// the backup succeeded.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// SnapshotHandles maps the VolumeSnapshot names of a Snapshot backup to
+	// the snapshot handles of the storage system.
+	// +optional
+	SnapshotHandles map[string]string `json:"snapshotHandles,omitempty"`
+	// RestoreValidation is the outcome of a ValidateRestore mode backup.
+	// +optional
+	RestoreValidation *RestoreValidationStatus `json:"restoreValidation,omitempty"`
+	// IncrementalChain places a backup taken by a BackupSchedule in
+	// incremental chain mode in its chain.
+	// +optional
+	IncrementalChain *IncrementalChainStatus `json:"incrementalChain,omitempty"`
+}
+
+// IncrementalChainStatus describes the place of a backup in an incremental
+// backup chain.
+type IncrementalChainStatus struct {
+	// Level is the incremental level the backup was taken at.
+	Level int32 `json:"level"`
+	// Cumulative is true for a cumulative level 1 backup.
+	// +optional
+	Cumulative bool `json:"cumulative,omitempty"`
+	// Anchor is the name of the level 0 Backup the chain starts with.
+	Anchor string `json:"anchor"`
+	// Parents lists the level 1 Backups, oldest first, a restore of the
+	// backup needs on top of the anchor.
+	// +optional
+	Parents []string `json:"parents,omitempty"`
+}
+
+// RestoreValidationStatus summarizes an RMAN restore validation report.
+type RestoreValidationStatus struct {
+	// Restorable is true if RMAN could validate a restore of the database.
+	Restorable bool `json:"restorable"`
+	// NeedBackupFiles lists the datafiles which need a new backup under the
+	// retention policy.
+	// +optional
+	NeedBackupFiles []string `json:"needBackupFiles,omitempty"`
+	// UnrecoverableFiles lists the datafiles which cannot be recovered
+	// because of unrecoverable operations.
+	// +optional
+	UnrecoverableFiles []string `json:"unrecoverableFiles,omitempty"`
+	// FailedCommands lists the RMAN commands which failed.
+	// +optional
+	FailedCommands []string `json:"failedCommands,omitempty"`
+	// ReportGcsPath is where the consolidated report was uploaded.
+	// +optional
+	ReportGcsPath string `json:"reportGcsPath,omitempty"`
 }
 
 // +kubebuilder:object:root=true
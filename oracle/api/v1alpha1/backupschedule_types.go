@@ -42,6 +42,28 @@ type BackupScheduleSpec struct {
 	// BackupLabels define the desired labels that scheduled backups will be created with.
 	// +optional
 	BackupLabels map[string]string `json:"backupLabels,omitempty"`
+
+	// IncrementalChain turns the scheduled Physical backups into a chain of
+	// a periodic level 0 backup followed by level 1 incremental backups.
+	// The level set in BackupSpec is ignored.
+	// +optional
+	IncrementalChain *IncrementalChainSpec `json:"incrementalChain,omitempty"`
+}
+
+// IncrementalChainSpec defines how a BackupSchedule anchors its chain of
+// incremental backups.
+type IncrementalChainSpec struct {
+	// Level0Interval is how long a level 0 backup anchors the chain before
+	// the next scheduled backup takes a new level 0 backup.
+	// The default is 168h.
+	// +optional
+	Level0Interval *metav1.Duration `json:"level0Interval,omitempty"`
+
+	// Cumulative takes cumulative level 1 backups, which only depend on the
+	// level 0 backup, instead of differential ones, which depend on every
+	// level 1 backup taken since. The default is false.
+	// +optional
+	Cumulative bool `json:"cumulative,omitempty"`
 }
 
 // BackupScheduleStatus defines the observed state of BackupSchedule.
@@ -15,6 +15,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
@@ -40,6 +41,197 @@ type DatabaseSpec struct {
 	// Users specifies an optional list of users to be created in this database.
 	// +optional
 	Users []UserSpec `json:"users"`
+
+	// InMemory configures the Oracle Database In-Memory column store for
+	// this database. It requires Enterprise Edition.
+	// +optional
+	InMemory *InMemorySpec `json:"inMemory,omitempty"`
+
+	// Partitioning lists the range partitioned tables of this database whose
+	// partitions are maintained by the operator. It requires Enterprise
+	// Edition.
+	// +optional
+	Partitioning []PartitioningPolicy `json:"partitioning,omitempty"`
+
+	// NLSParameters sets NLS parameters of this database by lower case name,
+	// e.g. nls_date_format set to YYYY-MM-DD. The static parameters take
+	// effect once the database restarts. The parameters removed from the map fall
+	// back to the values of the instance.
+	// +optional
+	NLSParameters map[string]string `json:"nlsParameters,omitempty"`
+
+	// ApplicationContexts lists the application contexts of this database,
+	// e.g. set by the VPD policy functions for row level security. The
+	// contexts removed from the list are dropped.
+	// +optional
+	ApplicationContexts []ApplicationContextSpec `json:"applicationContexts,omitempty"`
+
+	// VPDPolicies lists the Virtual Private Database (DBMS_RLS) policies of
+	// the tables of this database. The policies removed from the list are
+	// dropped.
+	// +optional
+	VPDPolicies []VPDPolicySpec `json:"vpdPolicies,omitempty"`
+
+	// Editions configures the editions of this database for edition-based
+	// redefinition.
+	// +optional
+	Editions *EditionsSpec `json:"editions,omitempty"`
+
+	// Services lists the services of this database the applications connect
+	// to. The services are created if missing and started. Services removed
+	// from the list are left as they are.
+	// +optional
+	Services []DatabaseServiceSpec `json:"services,omitempty"`
+
+	// Resources limits the resources the database uses in the container
+	// database of the instance. The limits are set as PDB parameters and
+	// set back if they are changed in the database. The limits removed from
+	// the spec fall back to the values of the instance.
+	// +optional
+	Resources *PDBResourcesSpec `json:"resources,omitempty"`
+}
+
+// PDBResourcesSpec defines the resource limits of a database.
+type PDBResourcesSpec struct {
+	// CPUCount is the number of CPUs the database uses (cpu_count). It
+	// requires a Resource Manager plan of the instance to be enforced.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CPUCount *int32 `json:"cpuCount,omitempty"`
+
+	// SGATarget is the SGA memory guaranteed to the database (sga_target).
+	// +optional
+	SGATarget *resource.Quantity `json:"sgaTarget,omitempty"`
+
+	// PGAAggregateTarget is the PGA memory the database aims at
+	// (pga_aggregate_target).
+	// +optional
+	PGAAggregateTarget *resource.Quantity `json:"pgaAggregateTarget,omitempty"`
+
+	// MaxIOPS and MaxMBPS limit the I/O requests and megabytes per second
+	// of the database (max_iops and max_mbps), 0 for no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxIOPS *int32 `json:"maxIOPS,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMBPS *int32 `json:"maxMBPS,omitempty"`
+}
+
+// DatabaseServiceSpec defines a database service.
+type DatabaseServiceSpec struct {
+	// Name is the service name, also used as its network name.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z][A-Za-z0-9_.#$-]*$`
+	Name string `json:"name"`
+
+	// HANotifications publishes the Fast Application Notification (FAN)
+	// events of the service (aq_ha_notifications), so that the client
+	// connection pools drain and reconnect on planned and unplanned
+	// outages. Requires spec.ons of the instance.
+	// +optional
+	HANotifications bool `json:"haNotifications,omitempty"`
+}
+
+// EditionsSpec defines the editions of a database.
+type EditionsSpec struct {
+	// Names lists the editions created in order, each one as a child of the
+	// newest edition, e.g. the edition of each release of an application.
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// Default is the edition the sessions use unless they set another one.
+	// The default edition is left unchanged if unset.
+	// +optional
+	Default string `json:"default,omitempty"`
+
+	// Retired lists the editions to drop with their editioned objects. Only
+	// the oldest and the newest editions can be dropped, once no session or
+	// service uses them.
+	// +optional
+	Retired []string `json:"retired,omitempty"`
+}
+
+// ApplicationContextSpec defines an application context.
+type ApplicationContextSpec struct {
+	// Name of the context.
+	Name string `json:"name"`
+
+	// Package is the trusted package setting the attributes of the context,
+	// as SCHEMA.PACKAGE.
+	Package string `json:"package"`
+
+	// AccessedGlobally shares the attributes of the context across sessions.
+	// +optional
+	AccessedGlobally bool `json:"accessedGlobally,omitempty"`
+}
+
+// VPDStatementType is a statement type a VPD policy applies to.
+// +kubebuilder:validation:Enum=Select;Insert;Update;Delete;Index
+type VPDStatementType string
+
+// VPDPolicySpec defines a Virtual Private Database policy of a table.
+type VPDPolicySpec struct {
+	// Name of the policy.
+	Name string `json:"name"`
+
+	// Table as OWNER.TABLE.
+	Table string `json:"table"`
+
+	// Function returns the predicate of the policy, as SCHEMA.FUNCTION or
+	// SCHEMA.PACKAGE.FUNCTION. It has to exist.
+	Function string `json:"function"`
+
+	// StatementTypes the policy applies to, all but Index if unset.
+	// +optional
+	StatementTypes []VPDStatementType `json:"statementTypes,omitempty"`
+
+	// UpdateCheck checks the rows inserted or updated against the policy.
+	// +optional
+	UpdateCheck bool `json:"updateCheck,omitempty"`
+
+	// PolicyType tells how often the predicate is evaluated, Dynamic by
+	// default.
+	// +kubebuilder:validation:Enum=Dynamic;Static;SharedStatic;ContextSensitive;SharedContextSensitive
+	// +optional
+	PolicyType string `json:"policyType,omitempty"`
+}
+
+// PartitioningPolicy maintains the partitions of a range partitioned table.
+type PartitioningPolicy struct {
+	// Table as OWNER.TABLE.
+	Table string `json:"table"`
+
+	// Interval sets up interval partitioning creating a partition per Day,
+	// Week, Month or Year. None converts the table back to range
+	// partitioning. The partitioning is left unchanged if unset.
+	// +kubebuilder:validation:Enum=None;Day;Week;Month;Year
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// Retain keeps the newest Retain partitions and drops the older ones,
+	// e.g. 30 keeps the last 30 daily partitions. All partitions are kept if
+	// unset.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retain int32 `json:"retain,omitempty"`
+}
+
+// InMemorySpec configures the In-Memory column store.
+type InMemorySpec struct {
+	// Size of the In-Memory column store (inmemory_size) of the instance,
+	// e.g. 2Gi. It has to fit in the SGA, 0 disables the column store.
+	// A new size takes effect once the database restarts. The size is left
+	// unchanged if unset.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Tablespaces whose tables are populated in the column store by default.
+	// +optional
+	Tablespaces []string `json:"tablespaces,omitempty"`
+
+	// Tables populated in the column store, as OWNER.TABLE.
+	// +optional
+	Tables []string `json:"tables,omitempty"`
 }
 
 // UserSpec defines the desired state of the Database Users.
@@ -50,6 +242,28 @@ type UserSpec struct {
 	// Privileges specifies an optional list of privileges to grant to the user.
 	// +optional
 	Privileges []PrivilegeSpec `json:"privileges"`
+
+	// Quotas specifies the space the user may allocate in tablespaces. The
+	// quotas on the tablespaces which aren't listed are left unchanged.
+	// +optional
+	Quotas []TablespaceQuotaSpec `json:"quotas,omitempty"`
+}
+
+// TablespaceQuotaSpec defines the quota of a user on a tablespace.
+type TablespaceQuotaSpec struct {
+	// Tablespace is the name of the tablespace.
+	// +required
+	Tablespace string `json:"tablespace"`
+
+	// Size is the space the user may allocate in the tablespace, 0 revokes
+	// the quota. Oracle rounds it to whole blocks.
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+
+	// Unlimited lets the user allocate space in the tablespace without
+	// limit, Size is ignored.
+	// +optional
+	Unlimited bool `json:"unlimited,omitempty"`
 }
 
 // PrivilegeSpec defines the desired state of roles and privileges.
@@ -75,6 +289,132 @@ type DatabaseStatus struct {
 	// IsChangeApplied indicates whether database changes have been applied
 	// +optional
 	IsChangeApplied metav1.ConditionStatus `json:"isChangeApplied,omitempty"`
+
+	// InMemory reports the In-Memory column store of the database.
+	// +optional
+	InMemory *InMemoryStatus `json:"inMemory,omitempty"`
+
+	// Partitioning reports the last maintenance of the tables of the
+	// partitioning policies.
+	// +optional
+	Partitioning []PartitioningStatus `json:"partitioning,omitempty"`
+
+	// NLS reports the NLS settings of the database.
+	// +optional
+	NLS *NLSStatus `json:"nls,omitempty"`
+
+	// Resources reports the resource limits of the database.
+	// +optional
+	Resources *PDBResourcesStatus `json:"resources,omitempty"`
+
+	// ApplicationContexts and VPDPolicies list the application contexts and
+	// the VPD policies, as OWNER.TABLE.POLICY, applied to the database.
+	// +optional
+	ApplicationContexts []string `json:"applicationContexts,omitempty"`
+	// +optional
+	VPDPolicies []string `json:"vpdPolicies,omitempty"`
+
+	// Editions lists the editions of the database, from the oldest to the
+	// newest, and DefaultEdition is its default edition.
+	// +optional
+	Editions []string `json:"editions,omitempty"`
+	// +optional
+	DefaultEdition string `json:"defaultEdition,omitempty"`
+
+	// Services lists the services of the spec created and started in the
+	// database.
+	// +optional
+	Services []string `json:"services,omitempty"`
+
+	// Endpoint is the Kubernetes Service of the database, in the format of
+	// <serviceName>.<namespace>, if the instance exposes its databases
+	// through their own Services. Clients connect to the PDB service of the
+	// database through it.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// URL is the external address of the Service of the database if it is
+	// a load balancer.
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// PDBResourcesStatus reports the resource limits of a database.
+type PDBResourcesStatus struct {
+	// Parameters are the values in effect of the resource parameters of the
+	// spec by parameter name, e.g. cpu_count.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Drifted lists the parameters found changed in the database by the
+	// last check, and set back to the spec. LastDriftTime is the last time
+	// parameters were found changed.
+	// +optional
+	Drifted []string `json:"drifted,omitempty"`
+	// +optional
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+}
+
+// NLSStatus reports the NLS settings of a database.
+type NLSStatus struct {
+	// Parameters are the values in effect of the NLS parameters of the spec.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// CharacterSet and NationalCharacterSet are the character sets the
+	// database was created with.
+	// +optional
+	CharacterSet string `json:"characterSet,omitempty"`
+	// +optional
+	NationalCharacterSet string `json:"nationalCharacterSet,omitempty"`
+
+	// RestartRequired is set if NLS parameters of the spec take effect once
+	// the database restarts.
+	// +optional
+	RestartRequired bool `json:"restartRequired,omitempty"`
+}
+
+// PartitioningStatus reports the last maintenance of a partitioned table.
+type PartitioningStatus struct {
+	Table string `json:"table"`
+
+	// Partitions is the number of partitions of the table.
+	// +optional
+	Partitions int32 `json:"partitions,omitempty"`
+
+	// DroppedPartitions are the partitions dropped by the last maintenance.
+	// +optional
+	DroppedPartitions []string `json:"droppedPartitions,omitempty"`
+
+	// LastMaintenanceTime is the time of the last maintenance.
+	// +optional
+	LastMaintenanceTime *metav1.Time `json:"lastMaintenanceTime,omitempty"`
+}
+
+// InMemoryStatus reports the In-Memory column store.
+type InMemoryStatus struct {
+	// Size of the In-Memory column store in effect.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// RestartRequired is set if the size in the spec takes effect once the
+	// database restarts.
+	// +optional
+	RestartRequired bool `json:"restartRequired,omitempty"`
+
+	// Tablespaces and Tables are marked INMEMORY by the operator.
+	// +optional
+	Tablespaces []string `json:"tablespaces,omitempty"`
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// PopulatedSegments is the number of segments completely populated in
+	// the column store, PopulatingSegments the number of segments still
+	// being populated.
+	// +optional
+	PopulatedSegments int32 `json:"populatedSegments,omitempty"`
+	// +optional
+	PopulatingSegments int32 `json:"populatingSegments,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -83,6 +423,7 @@ type DatabaseStatus struct {
 // +kubebuilder:printcolumn:JSONPath=".spec.instance",name="Instance",type="string"
 // +kubebuilder:printcolumn:JSONPath=".status.usernames",name="Users",type="string"
 // +kubebuilder:printcolumn:JSONPath=".status.phase",name="Phase",type="string"
+// +kubebuilder:printcolumn:JSONPath=".status.endpoint",name="Endpoint",type="string",priority=1
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=="Ready")].status`,name="DatabaseReadyStatus",type="string"
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=="Ready")].reason`,name="DatabaseReadyReason",type="string"
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=="Ready")].message`,name="DatabaseReadyMessage",type="string",priority=1
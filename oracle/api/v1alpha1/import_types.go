@@ -36,16 +36,44 @@ type ImportSpec struct {
 
 	// GcsPath is a full path to the input file in GCS containing import data.
 	// A user is to ensure proper write access to the bucket from within the
-	// Oracle Operator.
-	// +required
+	// Oracle Operator. Required unless NetworkLink is set.
+	// +optional
 	GcsPath string `json:"gcsPath,omitempty"`
 
+	// NetworkLink is the name of a database link in the database to import
+	// into. If set, data is imported directly from the database the link
+	// points to instead of from a dump file in GcsPath.
+	// +optional
+	NetworkLink string `json:"networkLink,omitempty"`
+
 	// GcsLogPath is an optional path in GCS to copy import log to.
 	// A user is to ensure proper write access to the bucket from within the
 	// Oracle Operator.
 	// +optional
 	GcsLogPath string `json:"gcsLogPath,omitempty"`
 
+	// RemapSchemas maps source schema names to the schemas their objects
+	// are imported into.
+	// +optional
+	RemapSchemas map[string]string `json:"remapSchemas,omitempty"`
+
+	// RemapTablespaces maps source tablespace names to the tablespaces
+	// their objects are imported into.
+	// +optional
+	RemapTablespaces map[string]string `json:"remapTablespaces,omitempty"`
+
+	// Includes are impdp INCLUDE filters, object types optionally followed
+	// by a name clause, e.g. TABLE:"IN ('EMPLOYEES')". Only the objects
+	// matching a filter are imported. Can't be combined with Excludes.
+	// +optional
+	Includes []string `json:"includes,omitempty"`
+
+	// Excludes are impdp EXCLUDE filters, object types optionally followed
+	// by a name clause, e.g. SCHEMA:"='HR'". The objects matching a filter
+	// are not imported. Can't be combined with Includes.
+	// +optional
+	Excludes []string `json:"excludes,omitempty"`
+
 	// Options is a map of options and their values for usage with the
 	// specified Import Type. Right now this is only supported for passing
 	// additional impdp specific options.
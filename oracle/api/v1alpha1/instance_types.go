@@ -15,6 +15,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
@@ -100,9 +102,633 @@ type InstanceSpec struct {
 	// +optional
 	ReplicationSettings *ReplicationSettings `json:"replicationSettings,omitempty"`
 
+	// DataGuard specifies the Data Guard settings of a standby instance.
+	// +optional
+	DataGuard *DataGuardSpec `json:"dataGuard,omitempty"`
+
 	// EnableDnfs enables configuration of Oracle's dNFS functionality.
 	// +optional
 	EnableDnfs bool `json:"enableDnfs,omitempty"`
+
+	// TDE specifies Transparent Data Encryption settings.
+	// +optional
+	TDE *TDESpec `json:"tde,omitempty"`
+
+	// NetworkEncryption specifies Oracle native network encryption settings
+	// for client connections (an alternative to TCPS).
+	// +optional
+	NetworkEncryption *NetworkEncryptionSpec `json:"networkEncryption,omitempty"`
+
+	// AllowedClients restricts which client hosts may connect to the
+	// listener (Oracle valid node checking). Connections from the
+	// database pod itself and from the cluster pod network are always
+	// allowed.
+	// +optional
+	AllowedClients *AllowedClientsSpec `json:"allowedClients,omitempty"`
+
+	// TCPS specifies a TCPS listener encrypting client connections with
+	// TLS on the ssl-listener port.
+	// +optional
+	TCPS *TCPSSpec `json:"tcps,omitempty"`
+
+	// ConnectionPool deploys a connection pooler in front of the instance,
+	// with its own Service, so that clients opening and closing many
+	// connections don't each get a dedicated server process.
+	// +optional
+	ConnectionPool *ConnectionPoolSpec `json:"connectionPool,omitempty"`
+
+	// RecoveryArea specifies fast recovery area (FRA) space management.
+	// +optional
+	RecoveryArea *RecoveryAreaSpec `json:"recoveryArea,omitempty"`
+
+	// RMANConfig specifies the RMAN persistent configuration. RMAN settings
+	// are not managed if not set.
+	// +optional
+	RMANConfig *RMANConfigSpec `json:"rmanConfig,omitempty"`
+
+	// RMANCatalogExport exports a copy of the control file and the RMAN
+	// configuration and backup records to GCS on a schedule, so that a
+	// restore on a new host can find the backups if the control file is
+	// lost. The catalog is not exported if not set.
+	// +optional
+	RMANCatalogExport *RMANCatalogExportSpec `json:"rmanCatalogExport,omitempty"`
+
+	// AWRConfig specifies the AWR snapshot settings. A shorter retention
+	// reduces the SYSAUX usage, a longer one helps performance analysis.
+	// AWR settings are not managed if not set.
+	// +optional
+	AWRConfig *AWRConfigSpec `json:"awrConfig,omitempty"`
+
+	// SQLPlanManagement captures SQL plan baselines automatically during
+	// a recurring window, e.g. a low traffic period, so that the captured
+	// plans are representative and the capture overhead doesn't affect the
+	// peak load. SQL plan baseline capture is not managed if not set.
+	// +optional
+	SQLPlanManagement *SQLPlanManagementSpec `json:"sqlPlanManagement,omitempty"`
+
+	// LicensedOptions are the options licensed on top of spec.edition. The
+	// options which require a license are only enabled if the edition
+	// includes them, as Express and Free do, or if they are listed here.
+	// +optional
+	LicensedOptions []LicensedOption `json:"licensedOptions,omitempty"`
+
+	// PrepareForStorageMigration reopens the read write PDBs read only and
+	// checkpoints the database, so that its storage can be copied. Unset it
+	// once the copy is done to reopen the PDBs read write.
+	// +optional
+	PrepareForStorageMigration bool `json:"prepareForStorageMigration,omitempty"`
+
+	// RequiredOptions are the database options the applications use. They
+	// are verified to be installed and VALID in dba_registry, so that an
+	// image without them is caught before the applications fail.
+	// +optional
+	RequiredOptions []DatabaseOption `json:"requiredOptions,omitempty"`
+
+	// RecompileInvalidOptions recompiles the required options found
+	// INVALID and validates them again.
+	// +optional
+	RecompileInvalidOptions bool `json:"recompileInvalidOptions,omitempty"`
+
+	// Hooks are SQL scripts the operator runs at lifecycle phases of the
+	// instance, in their order. The scripts should be idempotent, a phase
+	// blocked by a failed hook runs its hooks again when it is retried.
+	// +optional
+	Hooks []InstanceHook `json:"hooks,omitempty"`
+
+	// ONS configures the Oracle Notification Service daemon which delivers
+	// the Fast Application Notification (FAN) events of the services with
+	// HA notifications to the client connection pools. ONS is not managed if
+	// not set.
+	// +optional
+	ONS *ONSSpec `json:"ons,omitempty"`
+
+	// WriteCanary periodically writes a row to a one row table and reads it
+	// back, reporting the outcome as the DatabaseWritable condition, to
+	// catch a database which accepts connections but not writes. The
+	// canary doesn't run if not set.
+	// +optional
+	WriteCanary *WriteCanarySpec `json:"writeCanary,omitempty"`
+
+	// OrphanedPDBPolicy is what is done with the PDBs of the CDB which have
+	// no Database resource, Report by default.
+	// +kubebuilder:default=Report
+	// +optional
+	OrphanedPDBPolicy OrphanedPDBPolicy `json:"orphanedPDBPolicy,omitempty"`
+
+	// MonitoringConfig configures the metrics exported by the monitoring
+	// agent on top of its curated default set.
+	// +optional
+	MonitoringConfig *MonitoringConfig `json:"monitoringConfig,omitempty"`
+
+	// DatabaseServices exposes each Database (PDB) of the instance through
+	// its own Kubernetes Service, named after the Database resource, in
+	// addition to the CDB load balancer. The Services are removed if not
+	// set.
+	// +optional
+	DatabaseServices *DatabaseServicesSpec `json:"databaseServices,omitempty"`
+
+	// AutoResize expands the DataDisk when the usage of its filesystem
+	// crosses a threshold. The expanded size overrides the DataDisk size of
+	// the spec while it is larger. The disk is not expanded if not set.
+	// +optional
+	AutoResize *AutoResizeSpec `json:"autoResize,omitempty"`
+}
+
+// AutoResizeSpec defines when and by how much the DataDisk is expanded.
+type AutoResizeSpec struct {
+	// ThresholdPercent is the usage of the DataDisk filesystem, in percent,
+	// above which the disk is expanded (the default is 80).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +kubebuilder:default=80
+	// +optional
+	ThresholdPercent int32 `json:"thresholdPercent,omitempty"`
+
+	// IncreasePercent is the percentage of the current size added to the
+	// disk on each expansion (the default is 20).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=20
+	// +optional
+	IncreasePercent int32 `json:"increasePercent,omitempty"`
+
+	// MaxSize is the size the disk is never expanded beyond. There is no
+	// limit if not set.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+}
+
+// DatabaseServicesSpec defines the Services of the Databases of an instance.
+type DatabaseServicesSpec struct {
+	// Type is the type of the Services, ClusterIP by default.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer
+	// +kubebuilder:default=ClusterIP
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations are added to the Services, e.g. to make the load balancers
+	// internal.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// MonitoringConfig configures the monitoring agent of an instance.
+type MonitoringConfig struct {
+	// CustomMetricsConfigMap is a ConfigMap in the namespace of the instance
+	// whose keys each hold a list of metric sets, in the format of the
+	// default metrics of the monitoring agent, appended to the default set.
+	// Metric sets which fail to parse are logged and skipped.
+	// +optional
+	CustomMetricsConfigMap *corev1.LocalObjectReference `json:"customMetricsConfigMap,omitempty"`
+}
+
+// ONSSpec defines the ons.config of the ONS daemon.
+type ONSSpec struct {
+	// LocalPort is the port ONS listens on for local clients
+	// (the default is 6100).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	LocalPort int32 `json:"localPort,omitempty"`
+
+	// RemotePort is the port ONS listens on for the clients and the other
+	// ONS daemons (the default is 6200).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	RemotePort int32 `json:"remotePort,omitempty"`
+
+	// Nodes lists the host:port of the other ONS daemons, e.g. of a standby,
+	// the events are forwarded to.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// WriteCanarySpec defines how often the write canary runs.
+type WriteCanarySpec struct {
+	// Interval is the time between canary runs (the default is 1 minute).
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// LatencyThreshold is the write latency above which the database is
+	// reported as slow (the default is 5 seconds).
+	// +optional
+	LatencyThreshold *metav1.Duration `json:"latencyThreshold,omitempty"`
+}
+
+// TDESpec defines Transparent Data Encryption (encryption at rest) settings.
+type TDESpec struct {
+	// EnforceAll requires every user tablespace to be encrypted.
+	// Unencrypted user tablespaces are reported in the instance status.
+	// +optional
+	EnforceAll bool `json:"enforceAll,omitempty"`
+
+	// EncryptOnline encrypts unencrypted user tablespaces online
+	// when EnforceAll is set. Requires an open TDE keystore.
+	// +optional
+	EncryptOnline bool `json:"encryptOnline,omitempty"`
+
+	// EncryptNewTablespaces sets the default encryption policy of new
+	// tablespaces, including those created for imports. If true, they are
+	// encrypted by default, if false, only if their DDL requests it. The
+	// policy is not managed if not set. From Oracle 21c the policy only
+	// applies once the database restarts.
+	// +optional
+	EncryptNewTablespaces *bool `json:"encryptNewTablespaces,omitempty"`
+
+	// WalletPasswordGsmSecretRef references the GSM secret version holding
+	// the password of the TDE keystore. Password rotations add versions to
+	// the secret, the operator then uses the version it added.
+	// +optional
+	WalletPasswordGsmSecretRef *commonv1alpha1.GsmSecretReference `json:"walletPasswordGsmSecretRef,omitempty"`
+
+	// WalletPasswordRotationDays rotates the TDE keystore password every
+	// given number of days. Requires WalletPasswordGsmSecretRef.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WalletPasswordRotationDays int32 `json:"walletPasswordRotationDays,omitempty"`
+
+	// ManageKeystore has the operator create the TDE keystore in
+	// wallet_root, open it in all the containers and set their master
+	// keys, using the password of WalletPasswordGsmSecretRef. Databases
+	// without wallet_root are restarted once to set it. Requires Oracle 18c
+	// or later.
+	// +optional
+	ManageKeystore bool `json:"manageKeystore,omitempty"`
+
+	// AutoLogin creates an auto-login keystore next to the password
+	// keystore of ManageKeystore, so that the keystore opens when the
+	// database starts.
+	// +optional
+	AutoLogin bool `json:"autoLogin,omitempty"`
+}
+
+// KeystoreStatus is the TDE keystore status of the CDB root, or of the
+// first PDB whose keystore isn't open.
+type KeystoreStatus struct {
+	// Location is the directory of the keystore.
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// WalletType is the type of the open keystore, PASSWORD or AUTOLOGIN.
+	// +optional
+	WalletType string `json:"walletType,omitempty"`
+
+	// Status is the keystore status, e.g. OPEN, CLOSED or
+	// OPEN_NO_MASTER_KEY.
+	// +optional
+	Status string `json:"status,omitempty"`
+}
+
+// NetworkEncryptionSpec defines Oracle native network encryption settings
+// applied through sqlnet.ora.
+type NetworkEncryptionSpec struct {
+	// Level is the server side encryption negotiation level
+	// (the default is REQUIRED).
+	// +kubebuilder:validation:Enum=REQUIRED;REQUESTED;ACCEPTED;REJECTED
+	// +optional
+	Level string `json:"level,omitempty"`
+
+	// Algorithms lists the encryption algorithms the server accepts
+	// in order of preference (the default is AES256).
+	// +optional
+	Algorithms []string `json:"algorithms,omitempty"`
+}
+
+// AllowedClientsSpec defines the listener access control list applied
+// through tcp.invited_nodes and tcp.excluded_nodes in sqlnet.ora.
+// Entries are host names, IP addresses or CIDR blocks.
+type AllowedClientsSpec struct {
+	// Invited lists the clients allowed to connect. When set, all
+	// other clients are rejected.
+	// +optional
+	Invited []string `json:"invited,omitempty"`
+
+	// Excluded lists the clients denied to connect. It is ignored by
+	// the listener when Invited is set.
+	// +optional
+	Excluded []string `json:"excluded,omitempty"`
+}
+
+// TCPSSpec defines the TCPS listener of the instance and its Oracle wallet.
+type TCPSSpec struct {
+	// WalletSecret names a Secret in the namespace of the instance holding
+	// the Oracle wallet of the listener: an auto-login cwallet.sso and
+	// optionally its ewallet.p12. If not set, the operator generates a
+	// wallet with a self-signed certificate and publishes the certificate
+	// for clients to trust in the ConfigMap <instance>-tcps-cert.
+	// +optional
+	WalletSecret string `json:"walletSecret,omitempty"`
+}
+
+// TCPSStatus describes the TCPS listener last configured on the instance.
+type TCPSStatus struct {
+	// Port is the port of the TCPS listener.
+	Port int32 `json:"port"`
+
+	// WalletSecret is the Secret the wallet was installed from, empty for a
+	// generated wallet.
+	// +optional
+	WalletSecret string `json:"walletSecret,omitempty"`
+
+	// WalletSecretVersion is the resource version of the Secret installed.
+	// +optional
+	WalletSecretVersion string `json:"walletSecretVersion,omitempty"`
+
+	// CertificateConfigMap names the ConfigMap holding the certificate of a
+	// generated wallet.
+	// +optional
+	CertificateConfigMap string `json:"certificateConfigMap,omitempty"`
+}
+
+// ConnectionPoolType is the kind of connection pooler of an instance.
+// +kubebuilder:validation:Enum=Proxy;CMAN
+type ConnectionPoolType string
+
+const (
+	// ConnectionPoolProxy is a TCP proxy (HAProxy) limiting the client
+	// connections and queueing those above the database connection limit.
+	ConnectionPoolProxy ConnectionPoolType = "Proxy"
+	// ConnectionPoolCMAN is Oracle Connection Manager.
+	ConnectionPoolCMAN ConnectionPoolType = "CMAN"
+)
+
+// ConnectionPoolSpec defines the connection pooler of an instance.
+type ConnectionPoolSpec struct {
+	// Type is the kind of pooler, Proxy by default.
+	// +optional
+	Type ConnectionPoolType `json:"type,omitempty"`
+
+	// Image of the pooler. It defaults to the proxy image of the operator
+	// for the Proxy type. The CMAN type requires an image of an Oracle
+	// client installation with Connection Manager, cmctl on its PATH.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Replicas is the number of pooler pods, 1 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// MaxConnections limits the client connections of each pooler pod,
+	// 1024 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+
+	// MaxServerConnections limits the database connections of each pooler
+	// pod of the Proxy type, the clients above the limit wait for a
+	// connection. Unlimited if not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxServerConnections int32 `json:"maxServerConnections,omitempty"`
+
+	// IdleTimeout closes the client connections idle for longer, 1h by
+	// default.
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+
+	// ServiceType is the type of the Service of the pooler, ClusterIP by
+	// default.
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// Resources of the pooler container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ConnectionPoolStatus reports the connection pooler of an instance.
+type ConnectionPoolStatus struct {
+	// Endpoint is the Service of the pooler clients connect to, in the
+	// format of <serviceName>.<namespace>:<port>.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ReadyReplicas is the number of ready pooler pods.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+}
+
+// RecoveryAreaSpec defines fast recovery area (FRA) space management.
+type RecoveryAreaSpec struct {
+	// DeleteObsoleteThreshold is the FRA usage percentage at which backups
+	// obsolete according to the RMAN retention policy are deleted.
+	// Disabled if not set.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DeleteObsoleteThreshold int32 `json:"deleteObsoleteThreshold,omitempty"`
+
+	// DropRestorePointsThreshold is the FRA usage percentage, not counting
+	// reclaimable space, at which the oldest guaranteed restore points are
+	// dropped until the usage is below the threshold. Guaranteed restore
+	// points keep their flashback logs until dropped and can otherwise fill
+	// the FRA and hang the archiver. Disabled if not set.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DropRestorePointsThreshold int32 `json:"dropRestorePointsThreshold,omitempty"`
+
+	// ArchivelogBackup backs up archived logs to GCS and deletes them
+	// from the FRA on a schedule or when the FRA usage is high.
+	// +optional
+	ArchivelogBackup *ArchivelogBackupSpec `json:"archivelogBackup,omitempty"`
+}
+
+// ArchivelogBackupSpec defines when archived logs are backed up and then
+// deleted from the FRA. Only archived logs that were backed up are deleted.
+type ArchivelogBackupSpec struct {
+	// GcsPath is the GCS location archived log backups are uploaded to.
+	// +kubebuilder:validation:Pattern=`^gs:\/\/.+$`
+	// +required
+	GcsPath string `json:"gcsPath"`
+
+	// Threshold is the FRA usage percentage at which archived logs are
+	// backed up and deleted.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Threshold int32 `json:"threshold,omitempty"`
+
+	// Schedule is a cron-style expression of the schedule on which archived
+	// logs are backed up and deleted. For allowed syntax, see
+	// en.wikipedia.org/wiki/Cron and godoc.org/github.com/robfig/cron.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// KeepWindow keeps archived logs started within the window in the FRA,
+	// e.g. for fast local recovery (the default is 0, all backed up
+	// archived logs are deleted).
+	// +optional
+	KeepWindow *metav1.Duration `json:"keepWindow,omitempty"`
+}
+
+// RMANConfigSpec defines the RMAN persistent configuration applied with
+// RMAN CONFIGURE commands.
+type RMANConfigSpec struct {
+	// RecoveryWindowDays sets the retention policy to a recovery window
+	// of the given number of days. Mutually exclusive with Redundancy.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RecoveryWindowDays int32 `json:"recoveryWindowDays,omitempty"`
+
+	// Redundancy sets the retention policy to keep the given number of
+	// backups of each datafile (the default is 1).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Redundancy int32 `json:"redundancy,omitempty"`
+
+	// ControlfileAutobackup backs up the control file and the spfile after
+	// each backup and structural change (the default is true).
+	// +optional
+	ControlfileAutobackup *bool `json:"controlfileAutobackup,omitempty"`
+
+	// Parallelism is the number of channels allocated for disk backups
+	// and restores which do not allocate channels explicitly
+	// (the default is 1).
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Parallelism int32 `json:"parallelism,omitempty"`
+}
+
+// LicensedOption is a separately licensed option of Enterprise Edition.
+// +kubebuilder:validation:Enum=Partitioning;AdvancedCompression;DatabaseInMemory;DiagnosticsPack;TuningPack
+type LicensedOption string
+
+// DatabaseOption is a database option applications may require, JSON
+// needs Oracle XML DB.
+// +kubebuilder:validation:Enum=Text;JSON;Spatial
+type DatabaseOption string
+
+// HookPhase is a lifecycle phase of an instance which runs hooks.
+// +kubebuilder:validation:Enum=PostProvision;PreBackup;PostRestore;PreUpgrade
+type HookPhase string
+
+const (
+	// PostProvisionHook runs once the instance is created.
+	PostProvisionHook HookPhase = "PostProvision"
+	// PreBackupHook runs before the backups of the instance are taken.
+	PreBackupHook HookPhase = "PreBackup"
+	// PostRestoreHook runs once a restore of the instance succeeded.
+	PostRestoreHook HookPhase = "PostRestore"
+	// PreUpgradeHook runs before the images of the instance are patched.
+	PreUpgradeHook HookPhase = "PreUpgrade"
+)
+
+// HookFailurePolicy describes what a failed hook does to its phase.
+// +kubebuilder:validation:Enum=Block;Continue
+type HookFailurePolicy string
+
+const (
+	// HookBlock fails the phase, the hooks after the failed one don't run.
+	HookBlock HookFailurePolicy = "Block"
+	// HookContinue reports the failure and carries on with the phase.
+	HookContinue HookFailurePolicy = "Continue"
+)
+
+// OrphanedPDBPolicy describes what is done with the PDBs without a Database
+// resource.
+// +kubebuilder:validation:Enum=Report;Adopt;Drop
+type OrphanedPDBPolicy string
+
+const (
+	// OrphanedPDBReport lists the orphaned PDBs in the instance status.
+	OrphanedPDBReport OrphanedPDBPolicy = "Report"
+	// OrphanedPDBAdopt creates a Database resource for each orphaned PDB.
+	OrphanedPDBAdopt OrphanedPDBPolicy = "Adopt"
+	// OrphanedPDBDrop drops the orphaned PDBs with their datafiles.
+	OrphanedPDBDrop OrphanedPDBPolicy = "Drop"
+)
+
+// InstanceHook is a SQL script run at a lifecycle phase of an instance. The
+// script comes from GCS or from a ConfigMap in the namespace of the instance.
+type InstanceHook struct {
+	// Name identifies the hook in events.
+	// +required
+	Name string `json:"name"`
+
+	// Phase is the lifecycle phase which runs the hook.
+	// +required
+	Phase HookPhase `json:"phase"`
+
+	// GcsPath is the gs:// URI of the script.
+	// +kubebuilder:validation:Pattern=`^gs:\/\/.+$`
+	// +optional
+	GcsPath string `json:"gcsPath,omitempty"`
+
+	// ConfigMap is the ConfigMap key holding the script.
+	// +optional
+	ConfigMap *HookConfigMapRef `json:"configMap,omitempty"`
+
+	// PDBName is the PDB the script runs in, the CDB root if not set.
+	// +optional
+	PDBName string `json:"pdbName,omitempty"`
+
+	// FailurePolicy is what a failure of the hook does to its phase, Block
+	// by default.
+	// +kubebuilder:default=Block
+	// +optional
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// HookConfigMapRef references a key of a ConfigMap.
+type HookConfigMapRef struct {
+	// Name is the name of the ConfigMap.
+	// +required
+	Name string `json:"name"`
+
+	// Key is the key of the script in the ConfigMap.
+	// +required
+	Key string `json:"key"`
+}
+
+// AWRConfigSpec defines the AWR snapshot settings applied with
+// DBMS_WORKLOAD_REPOSITORY.MODIFY_SNAPSHOT_SETTINGS.
+type AWRConfigSpec struct {
+	// RetentionDays is how long AWR keeps snapshots (the default is 8 days).
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3650
+	// +optional
+	RetentionDays int32 `json:"retentionDays,omitempty"`
+
+	// SnapshotIntervalMinutes is the interval between snapshots
+	// (the default is 60 minutes).
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=1440
+	// +optional
+	SnapshotIntervalMinutes int32 `json:"snapshotIntervalMinutes,omitempty"`
+}
+
+// RMANCatalogExportSpec defines when and where the RMAN catalog is exported.
+type RMANCatalogExportSpec struct {
+	// Schedule is a cron-style expression of when the catalog is exported.
+	// For allowed syntax, see en.wikipedia.org/wiki/Cron and
+	// godoc.org/github.com/robfig/cron.
+	// +required
+	Schedule string `json:"schedule"`
+
+	// GcsPath is the GCS directory of the exports, each export is uploaded
+	// to a sub directory named after its UTC time, e.g.
+	// gs://bucket/rman-catalog/20261016T020000Z. Old exports are not
+	// deleted, a bucket lifecycle rule can expire them.
+	// +required
+	// +kubebuilder:validation:Pattern=`^gs:\/\/.+$`
+	GcsPath string `json:"gcsPath"`
+}
+
+// SQLPlanManagementSpec defines the window during which SQL plan baselines
+// are captured with optimizer_capture_sql_plan_baselines.
+type SQLPlanManagementSpec struct {
+	// CaptureSchedule is a cron-style expression of when the capture window
+	// starts. For allowed syntax, see en.wikipedia.org/wiki/Cron and
+	// godoc.org/github.com/robfig/cron.
+	// +required
+	CaptureSchedule string `json:"captureSchedule"`
+
+	// CaptureDuration is how long the capture window lasts (the default is
+	// 1 hour).
+	// +optional
+	CaptureDuration *metav1.Duration `json:"captureDuration,omitempty"`
 }
 
 type BackupReference struct {
@@ -148,6 +774,12 @@ type RestoreSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	TimeLimitMinutes int32 `json:"timeLimitMinutes,omitempty"`
 
+	// CloneToNewInstance restores the backup into a new Instance instead of
+	// this one, which is left untouched. The new Instance is a copy of this
+	// one with a new DBID. Force is not required to clone an instance.
+	// +optional
+	CloneToNewInstance *CloneSpec `json:"cloneToNewInstance,omitempty"`
+
 	// To overwrite an existing, up and running instance,
 	// an explicit athorization is required. This is safeguard to avoid
 	// accidentally destroying a perfectly healthy (status=Ready) instance.
@@ -167,6 +799,15 @@ type RestoreSpec struct {
 	RequestTime metav1.Time `json:"requestTime"`
 }
 
+// CloneSpec defines the Instance a backup is cloned into.
+type CloneSpec struct {
+	// InstanceName is the name of the new Instance, in the namespace of
+	// this one. It must not exist yet.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	InstanceName string `json:"instanceName"`
+}
+
 type PITRRestoreSpec struct {
 	// Incarnation number to restore to. This is optional, default to current incarnation.
 	// +optional
@@ -187,6 +828,12 @@ type PITRRestoreSpec struct {
 	// PITRRef specifies the PITR object from which to read backup data.
 	// +optional
 	PITRRef *PITRReference `json:"pitrRef,omitempty"`
+
+	// DryRun only checks that the replicated redo logs recover the backup to
+	// the restore point and reports it in the PITRTargetRecoverable
+	// condition, the database isn't restored.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 type PITRReference struct {
@@ -230,29 +877,532 @@ type ReplicationSettings struct {
 	BackupURI string `json:"backupURI"`
 }
 
-// DataGuardOutput shows Data Guard utility output.
-type DataGuardOutput struct {
-	// LastUpdateTime is the last time the DataGuardOutput updated based on DB
-	// Data Guard utility output.
-	// +required
-	// +kubebuilder:validation:Type=string
-	// +kubebuilder:validation:Format=date-time
-	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+// DataGuardSpec specifies the Data Guard settings of a standby instance.
+type DataGuardSpec struct {
+	// ProtectionMode is the protection mode of the Data Guard configuration.
+	// The stricter modes switch the redo transport to the standby to SYNC,
+	// the standby needs a standby redo log per online redo log group.
+	// +kubebuilder:validation:Enum="MAXIMUM PERFORMANCE";"MAXIMUM AVAILABILITY";"MAXIMUM PROTECTION"
+	// +optional
+	ProtectionMode string `json:"protectionMode,omitempty"`
 
-	// StatusOutput is the output of "show configuration" and "show database <standby DB unique name>".
-	StatusOutput []string `json:"statusOutput"`
-}
+	// RedoCompression compresses the redo shipped to the standby, it
+	// requires the Advanced Compression option in the declared license.
+	// +optional
+	RedoCompression bool `json:"redoCompression,omitempty"`
 
-// InstanceStatus defines the observed state of Instance.
-type InstanceStatus struct {
-	// InstanceStatus represents the database engine agnostic
-	// part of the status describing the observed state of an Instance.
-	commonv1alpha1.InstanceStatus `json:",inline"`
+	// RedoEncryption requires Oracle native network encryption for the
+	// connections to the standby listener, which redo transport uses.
+	// +optional
+	RedoEncryption bool `json:"redoEncryption,omitempty"`
 
-	// List of database names (e.g. PDBs) hosted in the Instance.
-	DatabaseNames []string `json:"databasenames,omitempty"`
+	// Drill requests a disaster recovery drill, a switchover to the
+	// standby, validation queries on it and a switchover back.
+	// +optional
+	Drill *DRDrillSpec `json:"drill,omitempty"`
 
-	// Last backup ID.
+	// Observer monitors the primary from the standby and fails over to the
+	// standby when the primary is lost, like a Data Guard observer.
+	// +optional
+	Observer *ObserverSpec `json:"observer,omitempty"`
+
+	// RoleTransition requests a switchover or failover to the standby.
+	// +optional
+	RoleTransition *RoleTransitionSpec `json:"roleTransition,omitempty"`
+
+	// PrimaryService is the name of a Service of the namespace which clients
+	// connect to the primary with. The operator points it at the standby
+	// once the standby takes over the primary role.
+	// +optional
+	PrimaryService string `json:"primaryService,omitempty"`
+}
+
+// FailoverPolicy is what the observer does when the primary is lost.
+// +kubebuilder:validation:Enum=Manual;Automatic
+type FailoverPolicy string
+
+const (
+	// ManualFailover only reports the health of the primary, a failover is
+	// requested with a RoleTransition.
+	ManualFailover FailoverPolicy = "Manual"
+	// AutomaticFailover fails over to the standby once the primary has been
+	// lost for the failover threshold.
+	AutomaticFailover FailoverPolicy = "Automatic"
+)
+
+// ObserverSpec configures the monitoring of the primary from the standby.
+type ObserverSpec struct {
+	// Policy is Automatic to fail over when the primary is lost, Manual to
+	// only report it. Defaults to Manual.
+	// +optional
+	Policy FailoverPolicy `json:"policy,omitempty"`
+
+	// Threshold is how long the primary has to be lost before an automatic
+	// failover, the primary is lost when neither its listener answers nor
+	// the broker of the standby reports a healthy configuration.
+	// Defaults to 2m.
+	// +optional
+	Threshold *metav1.Duration `json:"threshold,omitempty"`
+
+	// LagLimit blocks automatic failovers when the redo transport lag last
+	// observed while the primary was healthy exceeds it, it bounds the data
+	// a failover loses. Unset allows any lag.
+	// +optional
+	LagLimit *metav1.Duration `json:"lagLimit,omitempty"`
+
+	// PrimaryInstance is the name of the Instance of the namespace running
+	// the primary. A standby partitioned from the network sees a primary
+	// which still serves clients as lost, so failovers first stop this
+	// Instance and wait for its database pod to be gone. Automatic failovers
+	// are blocked without it.
+	// +optional
+	PrimaryInstance string `json:"primaryInstance,omitempty"`
+}
+
+// RoleTransitionType is a kind of role transition to the standby.
+// +kubebuilder:validation:Enum=Switchover;Failover
+type RoleTransitionType string
+
+const (
+	// SwitchoverTransition switches the roles of the primary and the standby
+	// without data loss, it needs the primary.
+	SwitchoverTransition RoleTransitionType = "Switchover"
+	// FailoverTransition makes the standby the primary without the primary, the redo
+	// the standby didn't receive is lost.
+	FailoverTransition RoleTransitionType = "Failover"
+)
+
+// RoleTransitionSpec requests a role transition to the standby.
+type RoleTransitionSpec struct {
+	// Type is Switchover or Failover.
+	// +required
+	// +kubebuilder:validation:Required
+	Type RoleTransitionType `json:"type"`
+
+	// Request version as a date-time, a role transition runs once per
+	// RequestTime.
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	RequestTime metav1.Time `json:"requestTime"`
+}
+
+// ObserverStatus describes the health of the primary as last checked by the
+// observer.
+type ObserverStatus struct {
+	// LastCheckTime is the time of the last check of the primary.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// LostSince is the first check which found the primary lost since it was
+	// last healthy, unset while the primary is healthy.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LostSince *metav1.Time `json:"lostSince,omitempty"`
+
+	// BrokerStatus is the Data Guard configuration status reported by the
+	// broker of the standby, e.g. SUCCESS or ERROR.
+	// +optional
+	BrokerStatus string `json:"brokerStatus,omitempty"`
+
+	// TransportLagSeconds is the redo transport lag observed when the
+	// primary was last healthy, -1 if it isn't known.
+	TransportLagSeconds int64 `json:"transportLagSeconds"`
+
+	// Message describes why the primary is lost or the failover is blocked.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// RoleTransitionStatus describes the last role transition to the standby.
+type RoleTransitionStatus struct {
+	// Type is Switchover or Failover.
+	Type RoleTransitionType `json:"type"`
+
+	// RequestTime is the RequestTime of the requested role transition,
+	// unset for automatic failovers.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	RequestTime *metav1.Time `json:"requestTime,omitempty"`
+
+	// State is Completed or Failed.
+	State string `json:"state"`
+
+	// StartTime is the time the role transition started.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the role transition finished.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message describes the failure of the role transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DRDrillSpec requests a disaster recovery drill.
+type DRDrillSpec struct {
+	// Request version as a date-time, a drill runs once per RequestTime.
+	// Drills with the same RequestTime or earlier than the last drill are
+	// ignored.
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	RequestTime metav1.Time `json:"requestTime"`
+
+	// ValidationQueries run on the standby while it is the primary, the
+	// roles are switched back and the drill fails if one of them fails.
+	// +optional
+	ValidationQueries []string `json:"validationQueries,omitempty"`
+}
+
+// DRDrillStatus describes the last disaster recovery drill.
+type DRDrillStatus struct {
+	// RequestTime is the RequestTime of the drill.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	RequestTime metav1.Time `json:"requestTime"`
+
+	// State is the final state of the drill, Completed if it succeeded.
+	// RolledBack means the drill failed and the roles were switched back,
+	// Failed means they may still be switched.
+	State string `json:"state"`
+
+	// FailedState is the step of the drill which failed.
+	// +optional
+	FailedState string `json:"failedState,omitempty"`
+
+	// StartTime is the time the drill started.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the drill finished.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// SwitchoverSeconds is the duration of the switchover to the standby.
+	// +optional
+	SwitchoverSeconds int64 `json:"switchoverSeconds,omitempty"`
+
+	// SwitchbackSeconds is the duration of the switchover back.
+	// +optional
+	SwitchbackSeconds int64 `json:"switchbackSeconds,omitempty"`
+
+	// Message describes the failure of the drill.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DataGuardOutput shows Data Guard utility output.
+type DataGuardOutput struct {
+	// LastUpdateTime is the last time the DataGuardOutput updated based on DB
+	// Data Guard utility output.
+	// +required
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+
+	// StatusOutput is the output of "show configuration" and "show database <standby DB unique name>".
+	StatusOutput []string `json:"statusOutput"`
+}
+
+// DatabaseInstanceInfo describes the running database instance.
+type DatabaseInstanceInfo struct {
+	// StartupTime is the time the database instance was last started.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	StartupTime *metav1.Time `json:"startupTime,omitempty"`
+
+	// Uptime is how long the database instance has been running.
+	// +optional
+	Uptime *metav1.Duration `json:"uptime,omitempty"`
+
+	// Incarnation is the current database incarnation number, it changes
+	// when the database is opened with resetlogs.
+	// +optional
+	Incarnation string `json:"incarnation,omitempty"`
+
+	// OpenMode is the database open mode, e.g. READ WRITE or MOUNTED.
+	// +optional
+	OpenMode string `json:"openMode,omitempty"`
+
+	// DatabaseRole is PRIMARY or the type of the standby database.
+	// +optional
+	DatabaseRole string `json:"databaseRole,omitempty"`
+
+	// ProtectionMode is the Data Guard protection mode, it is only set if
+	// Data Guard is configured.
+	// +optional
+	ProtectionMode string `json:"protectionMode,omitempty"`
+
+	// Version is the database version.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// PDBStatus describes the observed state of a PDB.
+type PDBStatus struct {
+	// Name is the PDB name.
+	Name string `json:"name"`
+
+	// OpenMode is the PDB open mode, e.g. READ WRITE or MOUNTED.
+	// +optional
+	OpenMode string `json:"openMode,omitempty"`
+
+	// Restricted indicates whether only users with the RESTRICTED SESSION
+	// privilege can connect to the PDB.
+	// +optional
+	Restricted bool `json:"restricted,omitempty"`
+
+	// SizeBytes is the total size of the PDB data files.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+}
+
+// DiskUsageSample is the used space of a mount at a point in time.
+type DiskUsageSample struct {
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	Time metav1.Time `json:"time"`
+
+	UsedBytes int64 `json:"usedBytes"`
+}
+
+// DiskUsageStatus describes the usage trend of a database mount.
+type DiskUsageStatus struct {
+	// Mount is the mount path, e.g. /u02.
+	Mount string `json:"mount"`
+
+	// TotalBytes is the size of the mount.
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+
+	// Samples are the recent used space samples, oldest first.
+	// +optional
+	Samples []DiskUsageSample `json:"samples,omitempty"`
+
+	// GrowthBytesPerHour is the growth rate of the used space, estimated
+	// from the samples.
+	// +optional
+	GrowthBytesPerHour int64 `json:"growthBytesPerHour,omitempty"`
+
+	// EstimatedFullTime is when the mount is projected to be full at the
+	// current growth rate, it is not set if the usage is not growing.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	EstimatedFullTime *metav1.Time `json:"estimatedFullTime,omitempty"`
+}
+
+// DiskGrowthStatus tracks an expanded disk, from the PVC expansion to the
+// filesystem the database uses.
+type DiskGrowthStatus struct {
+	// Mount is the mount path of the disk, e.g. /u02.
+	Mount string `json:"mount"`
+
+	// Size is the size the disk was expanded to.
+	Size resource.Quantity `json:"size"`
+
+	// FilesystemBytes is the size of the filesystem last seen by the
+	// database.
+	// +optional
+	FilesystemBytes int64 `json:"filesystemBytes,omitempty"`
+
+	// Verified is set once the filesystem grew to the disk size.
+	// +optional
+	Verified bool `json:"verified,omitempty"`
+
+	// AutoextendedDatafiles are the datafiles on the mount whose autoextend
+	// limit was raised to use the new space.
+	// +optional
+	AutoextendedDatafiles []string `json:"autoextendedDatafiles,omitempty"`
+}
+
+// CloneStatus reports a clone of the instance into a new Instance.
+type CloneStatus struct {
+	// InstanceName is the name of the new Instance.
+	InstanceName string `json:"instanceName"`
+
+	// RequestTime is the request time of the clone.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	RequestTime metav1.Time `json:"requestTime"`
+
+	// BackupID is the ID of the backup the new Instance is restored from.
+	// +optional
+	BackupID string `json:"backupId,omitempty"`
+
+	// Message explains why the clone failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AutoResizeStatus reports the automatic expansion of the DataDisk.
+type AutoResizeStatus struct {
+	// LastCheckTime is when the usage of the DataDisk was last checked.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// UsedPercent is the usage of the DataDisk filesystem, in percent, at
+	// the last check.
+	// +optional
+	UsedPercent int32 `json:"usedPercent,omitempty"`
+
+	// Capacity is the capacity of the DataDisk PVC at the last check.
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// Size is the size the DataDisk was last expanded to, it overrides the
+	// DataDisk size of the spec while it is larger.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// LastResizeTime is when the DataDisk was last expanded.
+	// +optional
+	LastResizeTime *metav1.Time `json:"lastResizeTime,omitempty"`
+}
+
+// DeadlockStatus reports the deadlocks found in the alert log.
+type DeadlockStatus struct {
+	// LastScanTime is when the alert log was last scanned.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastScanTime metav1.Time `json:"lastScanTime"`
+
+	// Count is the number of deadlocks found since the scans started.
+	// +optional
+	Count int64 `json:"count,omitempty"`
+
+	// LastDeadlockTime is when the most recent deadlock was logged.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastDeadlockTime *metav1.MicroTime `json:"lastDeadlockTime,omitempty"`
+
+	// LastDeadlock summarizes the sessions and the objects of the most
+	// recent deadlock.
+	// +optional
+	LastDeadlock string `json:"lastDeadlock,omitempty"`
+
+	// LastTraceFile is the trace file with the details of the most recent
+	// deadlock.
+	// +optional
+	LastTraceFile string `json:"lastTraceFile,omitempty"`
+}
+
+// RMANCatalogExportStatus reports the RMAN catalog exports.
+type RMANCatalogExportStatus struct {
+	// LastExportTime is when the catalog was last exported.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastExportTime *metav1.Time `json:"lastExportTime,omitempty"`
+
+	// LastExportGcsPath is the GCS directory of the last export.
+	// +optional
+	LastExportGcsPath string `json:"lastExportGcsPath,omitempty"`
+
+	// NextExportTime is when the catalog is next exported.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	NextExportTime *metav1.Time `json:"nextExportTime,omitempty"`
+}
+
+// SQLPlanManagementStatus reports the SQL plan baseline capture.
+type SQLPlanManagementStatus struct {
+	// CaptureEnabled is whether SQL plan baselines are being captured.
+	// +optional
+	CaptureEnabled bool `json:"captureEnabled,omitempty"`
+
+	// NextChangeTime is when the capture is next enabled or disabled.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	NextChangeTime *metav1.Time `json:"nextChangeTime,omitempty"`
+
+	// Baselines is the number of SQL plan baselines.
+	// +optional
+	Baselines int64 `json:"baselines,omitempty"`
+
+	// AcceptedBaselines is the number of SQL plan baselines the optimizer
+	// may use.
+	// +optional
+	AcceptedBaselines int64 `json:"acceptedBaselines,omitempty"`
+
+	// AutoCapturedBaselines is the number of SQL plan baselines captured
+	// automatically.
+	// +optional
+	AutoCapturedBaselines int64 `json:"autoCapturedBaselines,omitempty"`
+
+	// WindowStartTime is when the current or last capture window started.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	WindowStartTime *metav1.Time `json:"windowStartTime,omitempty"`
+
+	// LastWindowCapturedBaselines is the number of SQL plan baselines
+	// captured since WindowStartTime.
+	// +optional
+	LastWindowCapturedBaselines int64 `json:"lastWindowCapturedBaselines,omitempty"`
+
+	// LastCaptureTime is when the most recent SQL plan baseline was
+	// captured automatically.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastCaptureTime *metav1.Time `json:"lastCaptureTime,omitempty"`
+}
+
+// WriteCanaryStatus reports the last write canary run.
+type WriteCanaryStatus struct {
+	// LastRunTime is when the canary last ran.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+
+	// LastSuccessTime is when the canary last wrote and read back its row.
+	// +optional
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Format=date-time
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// WriteLatency is how long the last successful write took.
+	// +optional
+	WriteLatency *metav1.Duration `json:"writeLatency,omitempty"`
+
+	// ReadLatency is how long the last successful read took.
+	// +optional
+	ReadLatency *metav1.Duration `json:"readLatency,omitempty"`
+}
+
+// InstanceStatus defines the observed state of Instance.
+type InstanceStatus struct {
+	// InstanceStatus represents the database engine agnostic
+	// part of the status describing the observed state of an Instance.
+	commonv1alpha1.InstanceStatus `json:",inline"`
+
+	// List of database names (e.g. PDBs) hosted in the Instance.
+	DatabaseNames []string `json:"databasenames,omitempty"`
+
+	// Last backup ID.
 	BackupID string `json:"backupid,omitempty"`
 
 	// +optional
@@ -260,6 +1410,15 @@ type InstanceStatus struct {
 	// +kubebuilder:validation:Format=date-time
 	LastRestoreTime *metav1.Time `json:"lastRestoreTime,omitempty"`
 
+	// LastClone reports the last clone of the instance into a new Instance.
+	// +optional
+	LastClone *CloneStatus `json:"lastClone,omitempty"`
+
+	// LastArchivelogBackupTime is the time archived logs were last backed
+	// up and deleted from the FRA.
+	// +optional
+	LastArchivelogBackupTime *metav1.Time `json:"lastArchivelogBackupTime,omitempty"`
+
 	// CurrentParameters stores the last successfully set instance parameters.
 	CurrentParameters map[string]string `json:"currentParameters,omitempty"`
 
@@ -269,6 +1428,10 @@ type InstanceStatus struct {
 	// CurrentDatabaseIncarnation stores the current incarnation number
 	CurrentDatabaseIncarnation string `json:"currentDatabaseIncarnation,omitempty"`
 
+	// DBID stores the DBID of the database. RMAN needs it to restore the
+	// controlfile from an autobackup.
+	DBID string `json:"dbid,omitempty"`
+
 	// CurrentReplicationSettings stores the current replication settings of the
 	// standby instance. Standby data replication uses it to promote a standby
 	// instance. It will be updated to match with spec.replicationSettings before
@@ -302,6 +1465,126 @@ type InstanceStatus struct {
 
 	// DnfsEnabled stores whether dNFS has already been enabled or not.
 	DnfsEnabled bool `json:"DnfsEnabled,omitempty"`
+
+	// UnencryptedTablespaces lists user tablespaces found unencrypted
+	// by the last encryption verification, qualified by the container name.
+	// +optional
+	UnencryptedTablespaces []string `json:"unencryptedTablespaces,omitempty"`
+
+	// EncryptionVerifiedDatabases lists the PDBs covered by the last
+	// encryption verification.
+	// +optional
+	EncryptionVerifiedDatabases []string `json:"encryptionVerifiedDatabases,omitempty"`
+
+	// WalletPasswordVersion is the GSM secret version holding the current
+	// TDE keystore password, e.g. projects/p/secrets/s/versions/2.
+	// +optional
+	WalletPasswordVersion string `json:"walletPasswordVersion,omitempty"`
+
+	// WalletPasswordRotationTime is when the TDE keystore password was last
+	// rotated, or when the operator started tracking it.
+	// +optional
+	WalletPasswordRotationTime *metav1.Time `json:"walletPasswordRotationTime,omitempty"`
+
+	// Keystore is the TDE keystore status last verified.
+	// +optional
+	Keystore *KeystoreStatus `json:"keystore,omitempty"`
+
+	// CurrentNetworkEncryption is the network encryption configuration
+	// last applied to the listener.
+	// +optional
+	CurrentNetworkEncryption *NetworkEncryptionSpec `json:"currentNetworkEncryption,omitempty"`
+
+	// CurrentAllowedClients is the valid node checking configuration last
+	// applied to the listener, including the cluster pod CIDR blocks.
+	// +optional
+	CurrentAllowedClients *AllowedClientsSpec `json:"currentAllowedClients,omitempty"`
+
+	// TCPS is the TCPS listener configuration last applied.
+	// +optional
+	TCPS *TCPSStatus `json:"tcps,omitempty"`
+
+	// ConnectionPool reports the connection pooler of the instance.
+	// +optional
+	ConnectionPool *ConnectionPoolStatus `json:"connectionPool,omitempty"`
+
+	// InstanceInfo describes the running database instance, refreshed on
+	// every reconcile of a ready Instance.
+	// +optional
+	InstanceInfo *DatabaseInstanceInfo `json:"instanceInfo,omitempty"`
+
+	// PDBs summarizes the PDBs of the Instance, refreshed on every
+	// reconcile of a ready Instance.
+	// +optional
+	PDBs []PDBStatus `json:"pdbs,omitempty"`
+
+	// StorageMigrationPDBs are the PDBs reopened read only for a storage
+	// migration, they are reopened read write once the migration completes.
+	// +optional
+	StorageMigrationPDBs []string `json:"storageMigrationPDBs,omitempty"`
+
+	// AWRBaselines are the most recent AWR baselines captured around
+	// patching, to compare the performance before and after.
+	// +optional
+	AWRBaselines []string `json:"awrBaselines,omitempty"`
+
+	// DiskUsage tracks the usage of the data and log mounts to predict when
+	// they will be full.
+	// +optional
+	DiskUsage []DiskUsageStatus `json:"diskUsage,omitempty"`
+
+	// DiskGrowth tracks the expanded disks until the database can use the
+	// new space.
+	// +optional
+	DiskGrowth []DiskGrowthStatus `json:"diskGrowth,omitempty"`
+
+	// AutoResize reports the usage of the DataDisk and its automatic
+	// expansions.
+	// +optional
+	AutoResize *AutoResizeStatus `json:"autoResize,omitempty"`
+
+	// Deadlocks reports the deadlocks found in the alert log.
+	// +optional
+	Deadlocks *DeadlockStatus `json:"deadlocks,omitempty"`
+
+	// SQLPlanManagement reports the SQL plan baseline capture.
+	// +optional
+	SQLPlanManagement *SQLPlanManagementStatus `json:"sqlPlanManagement,omitempty"`
+
+	// RMANCatalogExport reports the RMAN catalog exports.
+	// +optional
+	RMANCatalogExport *RMANCatalogExportStatus `json:"rmanCatalogExport,omitempty"`
+
+	// WriteCanary reports the last write canary run.
+	// +optional
+	WriteCanary *WriteCanaryStatus `json:"writeCanary,omitempty"`
+
+	// OrphanedPDBs are the PDBs of the CDB which have no Database resource.
+	// +optional
+	OrphanedPDBs []string `json:"orphanedPDBs,omitempty"`
+
+	// MissingPDBs are the PDBs of the Database resources which the CDB
+	// doesn't have, e.g. still being created or dropped manually.
+	// +optional
+	MissingPDBs []string `json:"missingPDBs,omitempty"`
+
+	// LastDRDrill describes the last disaster recovery drill.
+	// +optional
+	LastDRDrill *DRDrillStatus `json:"lastDRDrill,omitempty"`
+
+	// Observer describes the health of the primary of a standby.
+	// +optional
+	Observer *ObserverStatus `json:"observer,omitempty"`
+
+	// LastRoleTransition describes the last switchover or failover to the
+	// standby.
+	// +optional
+	LastRoleTransition *RoleTransitionStatus `json:"lastRoleTransition,omitempty"`
+
+	// InstalledOptions is the status of the components of dba_registry by
+	// component ID, refreshed when the required options are verified.
+	// +optional
+	InstalledOptions map[string]string `json:"installedOptions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
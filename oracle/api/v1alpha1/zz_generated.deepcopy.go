@@ -23,10 +23,136 @@ package v1alpha1
 
 import (
 	apiv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWRConfigSpec) DeepCopyInto(out *AWRConfigSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWRConfigSpec.
+func (in *AWRConfigSpec) DeepCopy() *AWRConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AWRConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedClientsSpec) DeepCopyInto(out *AllowedClientsSpec) {
+	*out = *in
+	if in.Invited != nil {
+		in, out := &in.Invited, &out.Invited
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Excluded != nil {
+		in, out := &in.Excluded, &out.Excluded
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedClientsSpec.
+func (in *AllowedClientsSpec) DeepCopy() *AllowedClientsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedClientsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationContextSpec) DeepCopyInto(out *ApplicationContextSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationContextSpec.
+func (in *ApplicationContextSpec) DeepCopy() *ApplicationContextSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationContextSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchivelogBackupSpec) DeepCopyInto(out *ArchivelogBackupSpec) {
+	*out = *in
+	if in.KeepWindow != nil {
+		in, out := &in.KeepWindow, &out.KeepWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchivelogBackupSpec.
+func (in *ArchivelogBackupSpec) DeepCopy() *ArchivelogBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchivelogBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoResizeSpec) DeepCopyInto(out *AutoResizeSpec) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoResizeSpec.
+func (in *AutoResizeSpec) DeepCopy() *AutoResizeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoResizeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoResizeStatus) DeepCopyInto(out *AutoResizeStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LastResizeTime != nil {
+		in, out := &in.LastResizeTime, &out.LastResizeTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoResizeStatus.
+func (in *AutoResizeStatus) DeepCopy() *AutoResizeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoResizeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
@@ -172,6 +298,11 @@ func (in *BackupScheduleSpec) DeepCopyInto(out *BackupScheduleSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.IncrementalChain != nil {
+		in, out := &in.IncrementalChain, &out.IncrementalChain
+		*out = new(IncrementalChainSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleSpec.
@@ -240,6 +371,28 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.SnapshotHandles != nil {
+		in, out := &in.SnapshotHandles, &out.SnapshotHandles
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RestoreValidation != nil {
+		in, out := &in.RestoreValidation, &out.RestoreValidation
+		*out = new(RestoreValidationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IncrementalChain != nil {
+		in, out := &in.IncrementalChain, &out.IncrementalChain
+		*out = new(IncrementalChainStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
@@ -279,6 +432,37 @@ func (in *Config) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSpec) DeepCopyInto(out *CloneSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSpec.
+func (in *CloneSpec) DeepCopy() *CloneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneStatus) DeepCopyInto(out *CloneStatus) {
+	*out = *in
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneStatus.
+func (in *CloneStatus) DeepCopy() *CloneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigList) DeepCopyInto(out *ConfigList) {
 	*out = *in
@@ -342,6 +526,47 @@ func (in *ConfigStatus) DeepCopy() *ConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionPoolSpec) DeepCopyInto(out *ConnectionPoolSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionPoolSpec.
+func (in *ConnectionPoolSpec) DeepCopy() *ConnectionPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionPoolStatus) DeepCopyInto(out *ConnectionPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionPoolStatus.
+func (in *ConnectionPoolStatus) DeepCopy() *ConnectionPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CronAnything) DeepCopyInto(out *CronAnything) {
 	*out = *in
@@ -433,6 +658,51 @@ func (in *CronAnythingStatus) DeepCopy() *CronAnythingStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRDrillSpec) DeepCopyInto(out *DRDrillSpec) {
+	*out = *in
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+	if in.ValidationQueries != nil {
+		in, out := &in.ValidationQueries, &out.ValidationQueries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRDrillSpec.
+func (in *DRDrillSpec) DeepCopy() *DRDrillSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DRDrillSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRDrillStatus) DeepCopyInto(out *DRDrillStatus) {
+	*out = *in
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRDrillStatus.
+func (in *DRDrillStatus) DeepCopy() *DRDrillStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DRDrillStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataGuardOutput) DeepCopyInto(out *DataGuardOutput) {
 	*out = *in
@@ -454,6 +724,36 @@ func (in *DataGuardOutput) DeepCopy() *DataGuardOutput {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataGuardSpec) DeepCopyInto(out *DataGuardSpec) {
+	*out = *in
+	if in.Drill != nil {
+		in, out := &in.Drill, &out.Drill
+		*out = new(DRDrillSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Observer != nil {
+		in, out := &in.Observer, &out.Observer
+		*out = new(ObserverSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleTransition != nil {
+		in, out := &in.RoleTransition, &out.RoleTransition
+		*out = new(RoleTransitionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataGuardSpec.
+func (in *DataGuardSpec) DeepCopy() *DataGuardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataGuardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Database) DeepCopyInto(out *Database) {
 	*out = *in
@@ -481,6 +781,30 @@ func (in *Database) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseInstanceInfo) DeepCopyInto(out *DatabaseInstanceInfo) {
+	*out = *in
+	if in.StartupTime != nil {
+		in, out := &in.StartupTime, &out.StartupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Uptime != nil {
+		in, out := &in.Uptime, &out.Uptime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInstanceInfo.
+func (in *DatabaseInstanceInfo) DeepCopy() *DatabaseInstanceInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseInstanceInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseList) DeepCopyInto(out *DatabaseList) {
 	*out = *in
@@ -513,6 +837,43 @@ func (in *DatabaseList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseServiceSpec) DeepCopyInto(out *DatabaseServiceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseServiceSpec.
+func (in *DatabaseServiceSpec) DeepCopy() *DatabaseServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseServicesSpec) DeepCopyInto(out *DatabaseServicesSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseServicesSpec.
+func (in *DatabaseServicesSpec) DeepCopy() *DatabaseServicesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseServicesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 	*out = *in
@@ -529,6 +890,50 @@ func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.InMemory != nil {
+		in, out := &in.InMemory, &out.InMemory
+		*out = new(InMemorySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = make([]PartitioningPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.NLSParameters != nil {
+		in, out := &in.NLSParameters, &out.NLSParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ApplicationContexts != nil {
+		in, out := &in.ApplicationContexts, &out.ApplicationContexts
+		*out = make([]ApplicationContextSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.VPDPolicies != nil {
+		in, out := &in.VPDPolicies, &out.VPDPolicies
+		*out = make([]VPDPolicySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Editions != nil {
+		in, out := &in.Editions, &out.Editions
+		*out = new(EditionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]DatabaseServiceSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(PDBResourcesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
@@ -557,8 +962,50 @@ func (in *DatabaseStatus) DeepCopyInto(out *DatabaseStatus) {
 			(*out)[key] = val
 		}
 	}
-}
-
+	if in.InMemory != nil {
+		in, out := &in.InMemory, &out.InMemory
+		*out = new(InMemoryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = make([]PartitioningStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NLS != nil {
+		in, out := &in.NLS, &out.NLS
+		*out = new(NLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(PDBResourcesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ApplicationContexts != nil {
+		in, out := &in.ApplicationContexts, &out.ApplicationContexts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VPDPolicies != nil {
+		in, out := &in.VPDPolicies, &out.VPDPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Editions != nil {
+		in, out := &in.Editions, &out.Editions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseStatus.
 func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
 	if in == nil {
@@ -569,6 +1016,114 @@ func (in *DatabaseStatus) DeepCopy() *DatabaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeadlockStatus) DeepCopyInto(out *DeadlockStatus) {
+	*out = *in
+	in.LastScanTime.DeepCopyInto(&out.LastScanTime)
+	if in.LastDeadlockTime != nil {
+		in, out := &in.LastDeadlockTime, &out.LastDeadlockTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeadlockStatus.
+func (in *DeadlockStatus) DeepCopy() *DeadlockStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeadlockStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskGrowthStatus) DeepCopyInto(out *DiskGrowthStatus) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.AutoextendedDatafiles != nil {
+		in, out := &in.AutoextendedDatafiles, &out.AutoextendedDatafiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskGrowthStatus.
+func (in *DiskGrowthStatus) DeepCopy() *DiskGrowthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskGrowthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskUsageSample) DeepCopyInto(out *DiskUsageSample) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskUsageSample.
+func (in *DiskUsageSample) DeepCopy() *DiskUsageSample {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskUsageSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskUsageStatus) DeepCopyInto(out *DiskUsageStatus) {
+	*out = *in
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]DiskUsageSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EstimatedFullTime != nil {
+		in, out := &in.EstimatedFullTime, &out.EstimatedFullTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskUsageStatus.
+func (in *DiskUsageStatus) DeepCopy() *DiskUsageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskUsageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EditionsSpec) DeepCopyInto(out *EditionsSpec) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retired != nil {
+		in, out := &in.Retired, &out.Retired
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EditionsSpec.
+func (in *EditionsSpec) DeepCopy() *EditionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EditionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Export) DeepCopyInto(out *Export) {
 	*out = *in
@@ -674,6 +1229,21 @@ func (in *ExportStatus) DeepCopy() *ExportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookConfigMapRef) DeepCopyInto(out *HookConfigMapRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookConfigMapRef.
+func (in *HookConfigMapRef) DeepCopy() *HookConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(HookConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Import) DeepCopyInto(out *Import) {
 	*out = *in
@@ -736,6 +1306,30 @@ func (in *ImportList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImportSpec) DeepCopyInto(out *ImportSpec) {
 	*out = *in
+	if in.RemapSchemas != nil {
+		in, out := &in.RemapSchemas, &out.RemapSchemas
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RemapTablespaces != nil {
+		in, out := &in.RemapTablespaces, &out.RemapTablespaces
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Includes != nil {
+		in, out := &in.Includes, &out.Includes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Excludes != nil {
+		in, out := &in.Excludes, &out.Excludes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make(map[string]string, len(*in))
@@ -777,6 +1371,106 @@ func (in *ImportStatus) DeepCopy() *ImportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InMemorySpec) DeepCopyInto(out *InMemorySpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Tablespaces != nil {
+		in, out := &in.Tablespaces, &out.Tablespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InMemorySpec.
+func (in *InMemorySpec) DeepCopy() *InMemorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InMemorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InMemoryStatus) DeepCopyInto(out *InMemoryStatus) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Tablespaces != nil {
+		in, out := &in.Tablespaces, &out.Tablespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InMemoryStatus.
+func (in *InMemoryStatus) DeepCopy() *InMemoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InMemoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncrementalChainSpec) DeepCopyInto(out *IncrementalChainSpec) {
+	*out = *in
+	if in.Level0Interval != nil {
+		in, out := &in.Level0Interval, &out.Level0Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncrementalChainSpec.
+func (in *IncrementalChainSpec) DeepCopy() *IncrementalChainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IncrementalChainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IncrementalChainStatus) DeepCopyInto(out *IncrementalChainStatus) {
+	*out = *in
+	if in.Parents != nil {
+		in, out := &in.Parents, &out.Parents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IncrementalChainStatus.
+func (in *IncrementalChainStatus) DeepCopy() *IncrementalChainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IncrementalChainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Instance) DeepCopyInto(out *Instance) {
 	*out = *in
@@ -804,6 +1498,26 @@ func (in *Instance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceHook) DeepCopyInto(out *InstanceHook) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(HookConfigMapRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceHook.
+func (in *InstanceHook) DeepCopy() *InstanceHook {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceList) DeepCopyInto(out *InstanceList) {
 	*out = *in
@@ -876,6 +1590,103 @@ func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 		*out = new(ReplicationSettings)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DataGuard != nil {
+		in, out := &in.DataGuard, &out.DataGuard
+		*out = new(DataGuardSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TDE != nil {
+		in, out := &in.TDE, &out.TDE
+		*out = new(TDESpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkEncryption != nil {
+		in, out := &in.NetworkEncryption, &out.NetworkEncryption
+		*out = new(NetworkEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedClients != nil {
+		in, out := &in.AllowedClients, &out.AllowedClients
+		*out = new(AllowedClientsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPS != nil {
+		in, out := &in.TCPS, &out.TCPS
+		*out = new(TCPSSpec)
+		**out = **in
+	}
+	if in.ConnectionPool != nil {
+		in, out := &in.ConnectionPool, &out.ConnectionPool
+		*out = new(ConnectionPoolSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecoveryArea != nil {
+		in, out := &in.RecoveryArea, &out.RecoveryArea
+		*out = new(RecoveryAreaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RMANConfig != nil {
+		in, out := &in.RMANConfig, &out.RMANConfig
+		*out = new(RMANConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RMANCatalogExport != nil {
+		in, out := &in.RMANCatalogExport, &out.RMANCatalogExport
+		*out = new(RMANCatalogExportSpec)
+		**out = **in
+	}
+	if in.AWRConfig != nil {
+		in, out := &in.AWRConfig, &out.AWRConfig
+		*out = new(AWRConfigSpec)
+		**out = **in
+	}
+	if in.SQLPlanManagement != nil {
+		in, out := &in.SQLPlanManagement, &out.SQLPlanManagement
+		*out = new(SQLPlanManagementSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LicensedOptions != nil {
+		in, out := &in.LicensedOptions, &out.LicensedOptions
+		*out = make([]LicensedOption, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredOptions != nil {
+		in, out := &in.RequiredOptions, &out.RequiredOptions
+		*out = make([]DatabaseOption, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]InstanceHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ONS != nil {
+		in, out := &in.ONS, &out.ONS
+		*out = new(ONSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WriteCanary != nil {
+		in, out := &in.WriteCanary, &out.WriteCanary
+		*out = new(WriteCanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MonitoringConfig != nil {
+		in, out := &in.MonitoringConfig, &out.MonitoringConfig
+		*out = new(MonitoringConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseServices != nil {
+		in, out := &in.DatabaseServices, &out.DatabaseServices
+		*out = new(DatabaseServicesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoResize != nil {
+		in, out := &in.AutoResize, &out.AutoResize
+		*out = new(AutoResizeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSpec.
@@ -901,6 +1712,15 @@ func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 		in, out := &in.LastRestoreTime, &out.LastRestoreTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastClone != nil {
+		in, out := &in.LastClone, &out.LastClone
+		*out = new(CloneStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastArchivelogBackupTime != nil {
+		in, out := &in.LastArchivelogBackupTime, &out.LastArchivelogBackupTime
+		*out = (*in).DeepCopy()
+	}
 	if in.CurrentParameters != nil {
 		in, out := &in.CurrentParameters, &out.CurrentParameters
 		*out = make(map[string]string, len(*in))
@@ -939,17 +1759,375 @@ func (in *InstanceStatus) DeepCopyInto(out *InstanceStatus) {
 			(*out)[key] = val
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceStatus.
-func (in *InstanceStatus) DeepCopy() *InstanceStatus {
-	if in == nil {
-		return nil
+	if in.UnencryptedTablespaces != nil {
+		in, out := &in.UnencryptedTablespaces, &out.UnencryptedTablespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	out := new(InstanceStatus)
-	in.DeepCopyInto(out)
-	return out
-}
+	if in.EncryptionVerifiedDatabases != nil {
+		in, out := &in.EncryptionVerifiedDatabases, &out.EncryptionVerifiedDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WalletPasswordRotationTime != nil {
+		in, out := &in.WalletPasswordRotationTime, &out.WalletPasswordRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Keystore != nil {
+		in, out := &in.Keystore, &out.Keystore
+		*out = new(KeystoreStatus)
+		**out = **in
+	}
+	if in.CurrentNetworkEncryption != nil {
+		in, out := &in.CurrentNetworkEncryption, &out.CurrentNetworkEncryption
+		*out = new(NetworkEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CurrentAllowedClients != nil {
+		in, out := &in.CurrentAllowedClients, &out.CurrentAllowedClients
+		*out = new(AllowedClientsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPS != nil {
+		in, out := &in.TCPS, &out.TCPS
+		*out = new(TCPSStatus)
+		**out = **in
+	}
+	if in.ConnectionPool != nil {
+		in, out := &in.ConnectionPool, &out.ConnectionPool
+		*out = new(ConnectionPoolStatus)
+		**out = **in
+	}
+	if in.InstanceInfo != nil {
+		in, out := &in.InstanceInfo, &out.InstanceInfo
+		*out = new(DatabaseInstanceInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PDBs != nil {
+		in, out := &in.PDBs, &out.PDBs
+		*out = make([]PDBStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageMigrationPDBs != nil {
+		in, out := &in.StorageMigrationPDBs, &out.StorageMigrationPDBs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AWRBaselines != nil {
+		in, out := &in.AWRBaselines, &out.AWRBaselines
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskUsage != nil {
+		in, out := &in.DiskUsage, &out.DiskUsage
+		*out = make([]DiskUsageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiskGrowth != nil {
+		in, out := &in.DiskGrowth, &out.DiskGrowth
+		*out = make([]DiskGrowthStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AutoResize != nil {
+		in, out := &in.AutoResize, &out.AutoResize
+		*out = new(AutoResizeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Deadlocks != nil {
+		in, out := &in.Deadlocks, &out.Deadlocks
+		*out = new(DeadlockStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQLPlanManagement != nil {
+		in, out := &in.SQLPlanManagement, &out.SQLPlanManagement
+		*out = new(SQLPlanManagementStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RMANCatalogExport != nil {
+		in, out := &in.RMANCatalogExport, &out.RMANCatalogExport
+		*out = new(RMANCatalogExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WriteCanary != nil {
+		in, out := &in.WriteCanary, &out.WriteCanary
+		*out = new(WriteCanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanedPDBs != nil {
+		in, out := &in.OrphanedPDBs, &out.OrphanedPDBs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MissingPDBs != nil {
+		in, out := &in.MissingPDBs, &out.MissingPDBs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastDRDrill != nil {
+		in, out := &in.LastDRDrill, &out.LastDRDrill
+		*out = new(DRDrillStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Observer != nil {
+		in, out := &in.Observer, &out.Observer
+		*out = new(ObserverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastRoleTransition != nil {
+		in, out := &in.LastRoleTransition, &out.LastRoleTransition
+		*out = new(RoleTransitionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstalledOptions != nil {
+		in, out := &in.InstalledOptions, &out.InstalledOptions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceStatus.
+func (in *InstanceStatus) DeepCopy() *InstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeystoreStatus) DeepCopyInto(out *KeystoreStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoreStatus.
+func (in *KeystoreStatus) DeepCopy() *KeystoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConfig) DeepCopyInto(out *MonitoringConfig) {
+	*out = *in
+	if in.CustomMetricsConfigMap != nil {
+		in, out := &in.CustomMetricsConfigMap, &out.CustomMetricsConfigMap
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConfig.
+func (in *MonitoringConfig) DeepCopy() *MonitoringConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NLSStatus) DeepCopyInto(out *NLSStatus) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NLSStatus.
+func (in *NLSStatus) DeepCopy() *NLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkEncryptionSpec) DeepCopyInto(out *NetworkEncryptionSpec) {
+	*out = *in
+	if in.Algorithms != nil {
+		in, out := &in.Algorithms, &out.Algorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkEncryptionSpec.
+func (in *NetworkEncryptionSpec) DeepCopy() *NetworkEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ONSSpec) DeepCopyInto(out *ONSSpec) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ONSSpec.
+func (in *ONSSpec) DeepCopy() *ONSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ONSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObserverSpec) DeepCopyInto(out *ObserverSpec) {
+	*out = *in
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LagLimit != nil {
+		in, out := &in.LagLimit, &out.LagLimit
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObserverSpec.
+func (in *ObserverSpec) DeepCopy() *ObserverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObserverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObserverStatus) DeepCopyInto(out *ObserverStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.LostSince != nil {
+		in, out := &in.LostSince, &out.LostSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObserverStatus.
+func (in *ObserverStatus) DeepCopy() *ObserverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ObserverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDBResourcesSpec) DeepCopyInto(out *PDBResourcesSpec) {
+	*out = *in
+	if in.CPUCount != nil {
+		in, out := &in.CPUCount, &out.CPUCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SGATarget != nil {
+		in, out := &in.SGATarget, &out.SGATarget
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.PGAAggregateTarget != nil {
+		in, out := &in.PGAAggregateTarget, &out.PGAAggregateTarget
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxIOPS != nil {
+		in, out := &in.MaxIOPS, &out.MaxIOPS
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxMBPS != nil {
+		in, out := &in.MaxMBPS, &out.MaxMBPS
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDBResourcesSpec.
+func (in *PDBResourcesSpec) DeepCopy() *PDBResourcesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PDBResourcesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDBResourcesStatus) DeepCopyInto(out *PDBResourcesStatus) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Drifted != nil {
+		in, out := &in.Drifted, &out.Drifted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastDriftTime != nil {
+		in, out := &in.LastDriftTime, &out.LastDriftTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDBResourcesStatus.
+func (in *PDBResourcesStatus) DeepCopy() *PDBResourcesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PDBResourcesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDBStatus) DeepCopyInto(out *PDBStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDBStatus.
+func (in *PDBStatus) DeepCopy() *PDBStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PDBStatus)
+	in.DeepCopyInto(out)
+	return out
+}
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PITR) DeepCopyInto(out *PITR) {
@@ -1110,6 +2288,123 @@ func (in *PITRStatus) DeepCopy() *PITRStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitioningPolicy) DeepCopyInto(out *PartitioningPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitioningPolicy.
+func (in *PartitioningPolicy) DeepCopy() *PartitioningPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitioningPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitioningStatus) DeepCopyInto(out *PartitioningStatus) {
+	*out = *in
+	if in.DroppedPartitions != nil {
+		in, out := &in.DroppedPartitions, &out.DroppedPartitions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastMaintenanceTime != nil {
+		in, out := &in.LastMaintenanceTime, &out.LastMaintenanceTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitioningStatus.
+func (in *PartitioningStatus) DeepCopy() *PartitioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RMANCatalogExportSpec) DeepCopyInto(out *RMANCatalogExportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RMANCatalogExportSpec.
+func (in *RMANCatalogExportSpec) DeepCopy() *RMANCatalogExportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RMANCatalogExportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RMANCatalogExportStatus) DeepCopyInto(out *RMANCatalogExportStatus) {
+	*out = *in
+	if in.LastExportTime != nil {
+		in, out := &in.LastExportTime, &out.LastExportTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextExportTime != nil {
+		in, out := &in.NextExportTime, &out.NextExportTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RMANCatalogExportStatus.
+func (in *RMANCatalogExportStatus) DeepCopy() *RMANCatalogExportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RMANCatalogExportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RMANConfigSpec) DeepCopyInto(out *RMANConfigSpec) {
+	*out = *in
+	if in.ControlfileAutobackup != nil {
+		in, out := &in.ControlfileAutobackup, &out.ControlfileAutobackup
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RMANConfigSpec.
+func (in *RMANConfigSpec) DeepCopy() *RMANConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RMANConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryAreaSpec) DeepCopyInto(out *RecoveryAreaSpec) {
+	*out = *in
+	if in.ArchivelogBackup != nil {
+		in, out := &in.ArchivelogBackup, &out.ArchivelogBackup
+		*out = new(ArchivelogBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryAreaSpec.
+func (in *RecoveryAreaSpec) DeepCopy() *RecoveryAreaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryAreaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Release) DeepCopyInto(out *Release) {
 	*out = *in
@@ -1228,6 +2523,11 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 		*out = new(PITRRestoreSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CloneToNewInstance != nil {
+		in, out := &in.CloneToNewInstance, &out.CloneToNewInstance
+		*out = new(CloneSpec)
+		**out = **in
+	}
 	in.RequestTime.DeepCopyInto(&out.RequestTime)
 }
 
@@ -1241,6 +2541,79 @@ func (in *RestoreSpec) DeepCopy() *RestoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreValidationStatus) DeepCopyInto(out *RestoreValidationStatus) {
+	*out = *in
+	if in.NeedBackupFiles != nil {
+		in, out := &in.NeedBackupFiles, &out.NeedBackupFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnrecoverableFiles != nil {
+		in, out := &in.UnrecoverableFiles, &out.UnrecoverableFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedCommands != nil {
+		in, out := &in.FailedCommands, &out.FailedCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreValidationStatus.
+func (in *RestoreValidationStatus) DeepCopy() *RestoreValidationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreValidationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleTransitionSpec) DeepCopyInto(out *RoleTransitionSpec) {
+	*out = *in
+	in.RequestTime.DeepCopyInto(&out.RequestTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleTransitionSpec.
+func (in *RoleTransitionSpec) DeepCopy() *RoleTransitionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleTransitionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleTransitionStatus) DeepCopyInto(out *RoleTransitionStatus) {
+	*out = *in
+	if in.RequestTime != nil {
+		in, out := &in.RequestTime, &out.RequestTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleTransitionStatus.
+func (in *RoleTransitionStatus) DeepCopy() *RoleTransitionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleTransitionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCNWindow) DeepCopyInto(out *SCNWindow) {
 	*out = *in
@@ -1256,6 +2629,124 @@ func (in *SCNWindow) DeepCopy() *SCNWindow {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLPlanManagementSpec) DeepCopyInto(out *SQLPlanManagementSpec) {
+	*out = *in
+	if in.CaptureDuration != nil {
+		in, out := &in.CaptureDuration, &out.CaptureDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLPlanManagementSpec.
+func (in *SQLPlanManagementSpec) DeepCopy() *SQLPlanManagementSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLPlanManagementSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SQLPlanManagementStatus) DeepCopyInto(out *SQLPlanManagementStatus) {
+	*out = *in
+	if in.NextChangeTime != nil {
+		in, out := &in.NextChangeTime, &out.NextChangeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WindowStartTime != nil {
+		in, out := &in.WindowStartTime, &out.WindowStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastCaptureTime != nil {
+		in, out := &in.LastCaptureTime, &out.LastCaptureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SQLPlanManagementStatus.
+func (in *SQLPlanManagementStatus) DeepCopy() *SQLPlanManagementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SQLPlanManagementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSSpec) DeepCopyInto(out *TCPSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSSpec.
+func (in *TCPSSpec) DeepCopy() *TCPSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSStatus) DeepCopyInto(out *TCPSStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSStatus.
+func (in *TCPSStatus) DeepCopy() *TCPSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TDESpec) DeepCopyInto(out *TDESpec) {
+	*out = *in
+	if in.EncryptNewTablespaces != nil {
+		in, out := &in.EncryptNewTablespaces, &out.EncryptNewTablespaces
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WalletPasswordGsmSecretRef != nil {
+		in, out := &in.WalletPasswordGsmSecretRef, &out.WalletPasswordGsmSecretRef
+		*out = new(apiv1alpha1.GsmSecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TDESpec.
+func (in *TDESpec) DeepCopy() *TDESpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TDESpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TablespaceQuotaSpec) DeepCopyInto(out *TablespaceQuotaSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceQuotaSpec.
+func (in *TablespaceQuotaSpec) DeepCopy() *TablespaceQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TablespaceQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimeWindow) DeepCopyInto(out *TimeWindow) {
 	*out = *in
@@ -1282,6 +2773,13 @@ func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 		*out = make([]PrivilegeSpec, len(*in))
 		copy(*out, *in)
 	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = make([]TablespaceQuotaSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSpec.
@@ -1293,3 +2791,81 @@ func (in *UserSpec) DeepCopy() *UserSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPDPolicySpec) DeepCopyInto(out *VPDPolicySpec) {
+	*out = *in
+	if in.StatementTypes != nil {
+		in, out := &in.StatementTypes, &out.StatementTypes
+		*out = make([]VPDStatementType, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPDPolicySpec.
+func (in *VPDPolicySpec) DeepCopy() *VPDPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VPDPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WriteCanarySpec) DeepCopyInto(out *WriteCanarySpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LatencyThreshold != nil {
+		in, out := &in.LatencyThreshold, &out.LatencyThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WriteCanarySpec.
+func (in *WriteCanarySpec) DeepCopy() *WriteCanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WriteCanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WriteCanaryStatus) DeepCopyInto(out *WriteCanaryStatus) {
+	*out = *in
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WriteLatency != nil {
+		in, out := &in.WriteLatency, &out.WriteLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReadLatency != nil {
+		in, out := &in.ReadLatency, &out.ReadLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WriteCanaryStatus.
+func (in *WriteCanaryStatus) DeepCopy() *WriteCanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WriteCanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// A program to report the configuration drift between two instances of a
+// namespace: the database version, the non-default parameters and the
+// installed SQL patches.
+// It connects to the Database Daemons through their services, so it has to
+// run in the cluster, e.g. as a Job, with a service account allowed to get
+// the services and secrets of the namespace.
+// It prints one difference per line and exits with 1 if there are any.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+)
+
+const exitErrorCode = consts.DefaultExitErrorCode
+
+var (
+	namespace     = flag.String("namespace", "", "Namespace of the instances")
+	instance      = flag.String("instance", "", "Name of the instance to compare")
+	otherInstance = flag.String("other_instance", "", "Name of the instance to compare it with")
+	reqTimeout    = flag.Duration("request_timeout", 5*time.Minute, "Maximum amount of time allowed to fetch the configurations (default is 5 min)")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	if *namespace == "" || *instance == "" || *otherInstance == "" {
+		klog.ErrorS(nil, "--namespace, --instance and --other_instance are required")
+		os.Exit(exitErrorCode)
+	}
+
+	r, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: clientgoscheme.Scheme})
+	if err != nil {
+		klog.ErrorS(err, "Failed to create the Kubernetes client")
+		os.Exit(exitErrorCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *reqTimeout)
+	defer cancel()
+
+	drifts, err := controllers.CompareInstanceConfig(ctx, r, &controllers.GRPCDatabaseClientFactory{}, *namespace, *instance, *otherInstance)
+	if err != nil {
+		klog.ErrorS(err, "Failed to compare the instance configurations")
+		os.Exit(exitErrorCode)
+	}
+	for _, d := range drifts {
+		fmt.Println(d)
+	}
+	if len(drifts) > 0 {
+		os.Exit(1)
+	}
+}
@@ -28,22 +28,32 @@ import (
 	"net"
 	"os"
 	"os/user"
+	"path/filepath"
 	"syscall"
 
 	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
 
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
 	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/dbdaemon"
 )
 
 const (
-	lockFile      = "/var/tmp/dbdaemon.lock"
+	lockFileName  = "dbdaemon.lock"
 	exitErrorCode = consts.DefaultExitErrorCode
 )
 
-var cdbNameFromYaml = flag.String("cdb_name", "GCLOUD", "Name of the CDB to create")
+var (
+	cdbNameFromYaml = flag.String("cdb_name", "GCLOUD", "Name of the CDB to create")
+	configRoot      = flag.String("config_root", "/"+consts.DataMount, "Writable mount holding the listener and database config files")
+	scratchDir      = flag.String("scratch_dir", "/var/tmp", "Writable directory holding the lock file and the temporary files")
+	fileMode        = flag.String("file_mode", "", "Octal mode of the created files, empty keeps the default mode of each file")
+	dirMode         = flag.String("dir_mode", "", "Octal mode of the created directories, empty keeps the default mode of each directory")
+	umask           = flag.String("umask", "", "Octal permissions cleared from the modes of the created files and directories")
+	tlsCertDir      = flag.String("tls_cert_dir", "", "Directory of the CA certificate and key pair the gRPC API is served to remote clients and the proxy is dialed with over mutual TLS, plaintext if empty")
+)
 
 // A user running this program should not be root and
 // a primary group should be either dba or oinstall.
@@ -81,6 +91,7 @@ func userCheck(skipChecking bool) error {
 }
 
 func agentInit() error {
+	lockFile := filepath.Join(*scratchDir, lockFileName)
 	lock, err := os.Create(lockFile)
 	if err != nil {
 		klog.ErrorS(err, "failed to access lock file", "lockFile", lockFile)
@@ -107,6 +118,18 @@ func main() {
 		os.Exit(exitErrorCode)
 	}
 
+	// Only the writable roots are written to, the container root filesystem
+	// can be read-only.
+	if err := dbdaemon.SetWritableRoots(*configRoot, *scratchDir); err != nil {
+		klog.ErrorS(err, "failed to set the writable roots")
+		os.Exit(exitErrorCode)
+	}
+
+	if err := dbdaemon.SetPermissions(*fileMode, *dirMode, *umask); err != nil {
+		klog.ErrorS(err, "failed to set the permission policy")
+		os.Exit(exitErrorCode)
+	}
+
 	lis, err = net.Listen("tcp", fmt.Sprintf(":%d", consts.DefaultDBDaemonPort))
 
 	if err != nil {
@@ -121,14 +144,28 @@ func main() {
 		os.Exit(exitErrorCode)
 	}
 
-	grpcSvr := grpc.NewServer()
-	dbdaemonServer, err := dbdaemon.New(context.Background(), *cdbNameFromYaml)
+	var (
+		serverOpts []grpc.ServerOption
+		dialOpts   []grpc.DialOption
+	)
+	if *tlsCertDir != "" {
+		creds, err := dbdaemonlib.ClientCredentials(*tlsCertDir)
+		if err != nil {
+			klog.ErrorS(err, "failed to load the TLS certificates", "dir", *tlsCertDir)
+			os.Exit(exitErrorCode)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(dbdaemonlib.LocalOrServerCredentials(*tlsCertDir)))
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	}
+
+	grpcSvr := grpc.NewServer(serverOpts...)
+	dbdaemonServer, err := dbdaemon.New(context.Background(), *cdbNameFromYaml, dialOpts...)
 	if err != nil {
 		klog.ErrorS(err, "failed to execute dbdaemon.New")
 		os.Exit(exitErrorCode)
 	}
 	dbdpb.RegisterDatabaseDaemonServer(grpcSvr, dbdaemonServer)
 
-	klog.InfoS("Starting a Database Daemon...", "host", hostname, "listenerAddr", lis.Addr())
+	klog.InfoS("Starting a Database Daemon...", "host", hostname, "listenerAddr", lis.Addr(), "mutualTLS", *tlsCertDir != "")
 	grpcSvr.Serve(lis)
 }
@@ -23,4 +23,5 @@ if (( ${rc} != 0 )); then
   echo "$(date +%Y-%m-%d.%H:%M:%S) Error occurred while attempting to enable Unified Auditing in the dbdaemon container: ${rc}"  >> "${SCRIPTS_DIR}/init_dbdaemon.log"
 fi
 
-${SCRIPTS_DIR}/dbdaemon --cdb_name="$1"
+# Any further arguments are dbdaemon flags, e.g. --tls_cert_dir.
+${SCRIPTS_DIR}/dbdaemon --cdb_name="$1" "${@:2}"
@@ -25,6 +25,7 @@ import (
 	"syscall"
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/pkg/monitoring"
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
 	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/dbdaemonproxy"
@@ -44,6 +45,7 @@ var (
 	port            = flag.Int("port", 0, "Optional port to bind a Database Daemon Proxy to.")
 	skipUserCheck   = flag.Bool("skip_user_check", false, "Optionally skip a check of a user who runs the Database Daemon Proxy (by default it should be a database software owner)")
 	cdbNameFromYaml = flag.String("cdb_name", "GCLOUD", "Name of the CDB to create")
+	tlsCertDir      = flag.String("tls_cert_dir", "", "Directory of the CA certificate and key pair the gRPC API is served with over mutual TLS, plaintext if empty")
 )
 
 // A user running this program should not be root and
@@ -175,7 +177,11 @@ func main() {
 		os.Exit(exitErrorCode)
 	}
 
-	grpcSvr := grpc.NewServer()
+	var serverOpts []grpc.ServerOption
+	if *tlsCertDir != "" {
+		serverOpts = append(serverOpts, grpc.Creds(dbdaemonlib.ServerCredentials(*tlsCertDir)))
+	}
+	grpcSvr := grpc.NewServer(serverOpts...)
 	s, err := dbdaemonproxy.New(hostname, *cdbNameFromYaml)
 	if err != nil {
 		klog.ErrorS(err, "dbdaemonproxy/main: failed to execute New")
@@ -191,6 +197,6 @@ func main() {
 			{Mount: "/u03", Name: "LogDisk"},
 		}, nil)
 
-	klog.InfoS("Starting a Database Daemon Proxy...", "host", hostname, "address", lis.Addr())
+	klog.InfoS("Starting a Database Daemon Proxy...", "host", hostname, "address", lis.Addr(), "mutualTLS", *tlsCertDir != "")
 	grpcSvr.Serve(lis)
 }
@@ -37,7 +37,9 @@ if (( ${rc} != 0 )); then
   echo "$(date +%Y-%m-%d.%H:%M:%S) Error occurred while attempting to enable Unified Auditing in the oracledb container: ${rc}"  >> "${SCRIPTS_DIR}/init_oracle.log"
 fi
 
-${SCRIPTS_DIR}/dbdaemon_proxy --cdb_name="$1" &
+# Any arguments after the CDB name and the domain are dbdaemon_proxy flags,
+# e.g. --tls_cert_dir.
+${SCRIPTS_DIR}/dbdaemon_proxy --cdb_name="$1" "${@:3}" &
 childPID=$!
 echo "$(date +%Y-%m-%d.%H:%M:%S) Initializing database daemon proxy with PID $childPID"  >> "${SCRIPTS_DIR}/init_oracle.log"
 
@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const hostStatsTimeout = 10 * time.Second
+
+var (
+	hostCPUSeconds = prometheus.NewDesc("ods_host_cpu_seconds_total", "Cumulative time spent by all CPUs of the database pod in each mode.", []string{"mode"}, nil)
+	hostCPUCount   = prometheus.NewDesc("ods_host_cpu_count", "Number of CPUs visible to the database pod.", nil, nil)
+	hostLoad       = prometheus.NewDesc("ods_host_load_average", "Load average of the database pod host.", []string{"period"}, nil)
+	hostMemory     = prometheus.NewDesc("ods_host_memory_bytes", "Memory and swap of the database pod host in bytes.", []string{"type"}, nil)
+	hostMount      = prometheus.NewDesc("ods_host_mount_bytes", "Disk space of the database pod mounts in bytes.", []string{"mount", "type"}, nil)
+	hostDiskOps    = prometheus.NewDesc("ods_host_disk_operations_total", "Cumulative completed I/O operations of the devices backing the database pod mounts.", []string{"device", "op"}, nil)
+	hostDiskBytes  = prometheus.NewDesc("ods_host_disk_bytes_total", "Cumulative bytes transferred by the devices backing the database pod mounts.", []string{"device", "op"}, nil)
+	hostDiskIO     = prometheus.NewDesc("ods_host_disk_io_seconds_total", "Cumulative time the devices backing the database pod mounts spent doing I/O.", []string{"device"}, nil)
+)
+
+// hostStatsCollector reports the OS stats of the database pod, as returned
+// by the GetHostStats call of the database daemon, alongside the database
+// metrics.
+type hostStatsCollector struct {
+	log      logr.Logger
+	address  string
+	dialOpts []grpc.DialOption
+}
+
+// Describe intentionally left blank as the mounts and devices are only known
+// when collecting.
+func (c *hostStatsCollector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *hostStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostStatsTimeout)
+	defer cancel()
+	conn, err := dbdaemonlib.DatabaseDaemonDialService(ctx, c.address, append([]grpc.DialOption{grpc.WithBlock()}, c.dialOpts...)...)
+	if err != nil {
+		c.log.Error(err, "failed to connect to the database daemon", "address", c.address)
+		return
+	}
+	defer conn.Close()
+	resp, err := dbdpb.NewDatabaseDaemonClient(conn).GetHostStats(ctx, &dbdpb.GetHostStatsRequest{})
+	if err != nil {
+		c.log.Error(err, "failed to get host stats")
+		return
+	}
+	for _, m := range hostStatsMetrics(resp) {
+		ch <- m
+	}
+}
+
+// hostStatsMetrics converts a GetHostStats response into metrics.
+func hostStatsMetrics(resp *dbdpb.GetHostStatsResponse) []prometheus.Metric {
+	cpu, mem := resp.GetCpu(), resp.GetMemory()
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(hostCPUSeconds, prometheus.CounterValue, cpu.GetUserSeconds(), "user"),
+		prometheus.MustNewConstMetric(hostCPUSeconds, prometheus.CounterValue, cpu.GetSystemSeconds(), "system"),
+		prometheus.MustNewConstMetric(hostCPUSeconds, prometheus.CounterValue, cpu.GetIdleSeconds(), "idle"),
+		prometheus.MustNewConstMetric(hostCPUSeconds, prometheus.CounterValue, cpu.GetIowaitSeconds(), "iowait"),
+		prometheus.MustNewConstMetric(hostCPUCount, prometheus.GaugeValue, float64(cpu.GetCpuCount())),
+		prometheus.MustNewConstMetric(hostLoad, prometheus.GaugeValue, cpu.GetLoad1(), "1m"),
+		prometheus.MustNewConstMetric(hostLoad, prometheus.GaugeValue, cpu.GetLoad5(), "5m"),
+		prometheus.MustNewConstMetric(hostLoad, prometheus.GaugeValue, cpu.GetLoad15(), "15m"),
+		prometheus.MustNewConstMetric(hostMemory, prometheus.GaugeValue, float64(mem.GetTotalBytes()), "total"),
+		prometheus.MustNewConstMetric(hostMemory, prometheus.GaugeValue, float64(mem.GetAvailableBytes()), "available"),
+		prometheus.MustNewConstMetric(hostMemory, prometheus.GaugeValue, float64(mem.GetSwapTotalBytes()), "swap_total"),
+		prometheus.MustNewConstMetric(hostMemory, prometheus.GaugeValue, float64(mem.GetSwapFreeBytes()), "swap_free"),
+	}
+	for _, m := range resp.GetMounts() {
+		metrics = append(metrics,
+			prometheus.MustNewConstMetric(hostMount, prometheus.GaugeValue, float64(m.GetTotalBytes()), m.GetPath(), "total"),
+			prometheus.MustNewConstMetric(hostMount, prometheus.GaugeValue, float64(m.GetUsedBytes()), m.GetPath(), "used"),
+			prometheus.MustNewConstMetric(hostMount, prometheus.GaugeValue, float64(m.GetAvailableBytes()), m.GetPath(), "available"),
+		)
+	}
+	for _, d := range resp.GetDisks() {
+		metrics = append(metrics,
+			prometheus.MustNewConstMetric(hostDiskOps, prometheus.CounterValue, float64(d.GetReadsCompleted()), d.GetDevice(), "read"),
+			prometheus.MustNewConstMetric(hostDiskOps, prometheus.CounterValue, float64(d.GetWritesCompleted()), d.GetDevice(), "write"),
+			prometheus.MustNewConstMetric(hostDiskBytes, prometheus.CounterValue, float64(d.GetReadBytes()), d.GetDevice(), "read"),
+			prometheus.MustNewConstMetric(hostDiskBytes, prometheus.CounterValue, float64(d.GetWrittenBytes()), d.GetDevice(), "write"),
+			prometheus.MustNewConstMetric(hostDiskIO, prometheus.CounterValue, d.GetIoSeconds(), d.GetDevice()),
+		)
+	}
+	return metrics
+}
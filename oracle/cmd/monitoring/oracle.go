@@ -4,11 +4,15 @@ import (
 	"database/sql"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/pkg/monitoring"
 	_ "github.com/godror/godror"
 	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
+
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
 )
 
 type godrorFactory struct {
@@ -39,12 +43,37 @@ func main() {
 	registry := prometheus.NewRegistry()
 	log := klog.NewKlogr()
 	db := godrorFactory{dsn: monitoring.GetDefaultDSN(log).String()}
+	// The database daemon reports the OS stats of the database pod, the
+	// redo rate and the PGA usage of the database.
+	if address := os.Getenv("DBDAEMON_ADDRESS"); address != "" {
+		// The database daemon requires mutual TLS if its certificates are
+		// mounted.
+		var dialOpts []grpc.DialOption
+		if dir := os.Getenv("DBDAEMON_TLS_DIR"); dir != "" {
+			creds, err := dbdaemonlib.ClientCredentials(dir)
+			if err != nil {
+				log.Error(err, "failed to load the database daemon TLS certificates", "dir", dir)
+				os.Exit(1)
+			}
+			dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+		}
+		registry.MustRegister(&hostStatsCollector{log: log, address: address, dialOpts: dialOpts})
+		registry.MustRegister(&redoRateCollector{log: log, address: address, dialOpts: dialOpts})
+		registry.MustRegister(&pgaUsageCollector{log: log, address: address, dialOpts: dialOpts})
+		// The write canary writes to the database on each scrape, it only
+		// runs if enabled in the instance spec.
+		if os.Getenv("WRITE_CANARY") == "true" {
+			registry.MustRegister(&writeCanaryCollector{log: log, address: address, dialOpts: dialOpts})
+		}
+	}
 	monitoring.StartExporting(
 		log,
 		registry,
 		db,
 		[]string{"/oracle_metrics.yaml",
 			"/oracle_unified_metrics.yaml"},
+		// Custom metrics from the ConfigMap referenced by the instance spec.
+		os.Getenv("CUSTOM_METRICS_DIR"),
 		nil,
 	)
 	log.Info("Shutting down")
@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const pgaUsageTimeout = 30 * time.Second
+
+var (
+	pgaBytes              = prometheus.NewDesc("ods_pga_bytes", "PGA aggregate target, allocated, in use and maximum allocated memory of the instance and the aggregate target recommended by the PGA advice in bytes.", []string{"type"}, nil)
+	pgaOverAllocations    = prometheus.NewDesc("ods_pga_over_allocations_total", "Times the instance allocated more PGA than the aggregate target since it started.", nil, nil)
+	pgaCacheHitPercentage = prometheus.NewDesc("ods_pga_cache_hit_percentage", "Share of the work area bytes processed without extra passes since the instance started.", nil, nil)
+)
+
+// pgaUsageCollector reports the PGA usage of the database, as returned by
+// the GetPGAUsage call of the database daemon.
+type pgaUsageCollector struct {
+	log      logr.Logger
+	address  string
+	dialOpts []grpc.DialOption
+}
+
+func (c *pgaUsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pgaBytes
+	ch <- pgaOverAllocations
+	ch <- pgaCacheHitPercentage
+}
+
+func (c *pgaUsageCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), pgaUsageTimeout)
+	defer cancel()
+	conn, err := dbdaemonlib.DatabaseDaemonDialService(ctx, c.address, append([]grpc.DialOption{grpc.WithBlock()}, c.dialOpts...)...)
+	if err != nil {
+		c.log.Error(err, "failed to connect to the database daemon", "address", c.address)
+		return
+	}
+	defer conn.Close()
+	resp, err := dbdpb.NewDatabaseDaemonClient(conn).GetPGAUsage(ctx, &dbdpb.GetPGAUsageRequest{})
+	if err != nil {
+		c.log.Error(err, "failed to get PGA usage")
+		return
+	}
+	for _, m := range pgaUsageMetrics(resp) {
+		ch <- m
+	}
+}
+
+// pgaUsageMetrics converts a GetPGAUsage response into metrics.
+func pgaUsageMetrics(resp *dbdpb.GetPGAUsageResponse) []prometheus.Metric {
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(pgaBytes, prometheus.GaugeValue, float64(resp.GetAggregateTargetBytes()), "target"),
+		prometheus.MustNewConstMetric(pgaBytes, prometheus.GaugeValue, float64(resp.GetAllocatedBytes()), "allocated"),
+		prometheus.MustNewConstMetric(pgaBytes, prometheus.GaugeValue, float64(resp.GetInUseBytes()), "in_use"),
+		prometheus.MustNewConstMetric(pgaBytes, prometheus.GaugeValue, float64(resp.GetMaxAllocatedBytes()), "max_allocated"),
+		prometheus.MustNewConstMetric(pgaBytes, prometheus.GaugeValue, float64(resp.GetRecommendedAggregateTargetBytes()), "recommended_target"),
+		prometheus.MustNewConstMetric(pgaOverAllocations, prometheus.CounterValue, float64(resp.GetOverAllocationCount())),
+		prometheus.MustNewConstMetric(pgaCacheHitPercentage, prometheus.GaugeValue, resp.GetCacheHitPercentage()),
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	redoRateTimeout = 30 * time.Second
+	// redoRateWindowHours keeps the archived log history read on each
+	// scrape short, longer trends are left to the time series.
+	redoRateWindowHours = 24
+)
+
+var (
+	redoBytes            = prometheus.NewDesc("ods_redo_bytes_total", "Redo generated since the database instance started in bytes.", nil, nil)
+	redoBytesPerSecond   = prometheus.NewDesc("ods_redo_bytes_per_second", "Average rate of redo archived over the last day in bytes per second.", nil, nil)
+	redoPeakBytesPerHour = prometheus.NewDesc("ods_redo_peak_bytes_per_hour", "Most redo archived in an hour over the last day in bytes.", nil, nil)
+	redoRecommendedBytes = prometheus.NewDesc("ods_redo_recommended_bytes", "Online and standby redo log size and archived log disk size recommended by the redo rate of the last day.", []string{"type"}, nil)
+	redoQuietestHour     = prometheus.NewDesc("ods_redo_quietest_hour_utc", "Hour of the day, in UTC, with the least redo archived over the last day.", nil, nil)
+)
+
+// redoRateCollector reports the redo generated by the database, as returned
+// by the GetRedoRate call of the database daemon.
+type redoRateCollector struct {
+	log      logr.Logger
+	address  string
+	dialOpts []grpc.DialOption
+}
+
+func (c *redoRateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redoBytes
+	ch <- redoBytesPerSecond
+	ch <- redoPeakBytesPerHour
+	ch <- redoRecommendedBytes
+	ch <- redoQuietestHour
+}
+
+func (c *redoRateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), redoRateTimeout)
+	defer cancel()
+	conn, err := dbdaemonlib.DatabaseDaemonDialService(ctx, c.address, append([]grpc.DialOption{grpc.WithBlock()}, c.dialOpts...)...)
+	if err != nil {
+		c.log.Error(err, "failed to connect to the database daemon", "address", c.address)
+		return
+	}
+	defer conn.Close()
+	resp, err := dbdpb.NewDatabaseDaemonClient(conn).GetRedoRate(ctx, &dbdpb.GetRedoRateRequest{WindowHours: redoRateWindowHours})
+	if err != nil {
+		c.log.Error(err, "failed to get redo rate")
+		return
+	}
+	for _, m := range redoRateMetrics(resp) {
+		ch <- m
+	}
+}
+
+// redoRateMetrics converts a GetRedoRate response into metrics.
+func redoRateMetrics(resp *dbdpb.GetRedoRateResponse) []prometheus.Metric {
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(redoBytes, prometheus.CounterValue, float64(resp.GetRedoSizeBytes())),
+		prometheus.MustNewConstMetric(redoBytesPerSecond, prometheus.GaugeValue, resp.GetAverageBytesPerSecond()),
+		prometheus.MustNewConstMetric(redoPeakBytesPerHour, prometheus.GaugeValue, float64(resp.GetPeakBytesPerHour())),
+		prometheus.MustNewConstMetric(redoRecommendedBytes, prometheus.GaugeValue, float64(resp.GetRecommendedRedoLogSizeBytes()), "redo_log"),
+		prometheus.MustNewConstMetric(redoRecommendedBytes, prometheus.GaugeValue, float64(resp.GetRecommendedLogDiskBytes()), "log_disk"),
+		prometheus.MustNewConstMetric(redoQuietestHour, prometheus.GaugeValue, float64(resp.GetQuietestHourUtc())),
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	dbdaemonlib "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const writeCanaryTimeout = 30 * time.Second
+
+var (
+	writeCanarySuccess = prometheus.NewDesc("ods_write_canary_success", "Whether the write canary row was written and read back.", nil, nil)
+	writeCanaryLatency = prometheus.NewDesc("ods_write_canary_latency_seconds", "Time the write canary row took to be written and to be read back.", []string{"type"}, nil)
+)
+
+// writeCanaryCollector runs the write canary of the database daemon on each
+// scrape, so that a database which accepts connections but not writes is
+// reported.
+type writeCanaryCollector struct {
+	log      logr.Logger
+	address  string
+	dialOpts []grpc.DialOption
+}
+
+func (c *writeCanaryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- writeCanarySuccess
+	ch <- writeCanaryLatency
+}
+
+func (c *writeCanaryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeCanaryTimeout)
+	defer cancel()
+	conn, err := dbdaemonlib.DatabaseDaemonDialService(ctx, c.address, append([]grpc.DialOption{grpc.WithBlock()}, c.dialOpts...)...)
+	if err != nil {
+		c.log.Error(err, "failed to connect to the database daemon", "address", c.address)
+		return
+	}
+	defer conn.Close()
+	resp, err := dbdpb.NewDatabaseDaemonClient(conn).RunWriteCanary(ctx, &dbdpb.RunWriteCanaryRequest{})
+	if err != nil {
+		c.log.Error(err, "write canary failed")
+	}
+	for _, m := range writeCanaryMetrics(resp, err) {
+		ch <- m
+	}
+}
+
+// writeCanaryMetrics converts a RunWriteCanary response into metrics, a
+// failed run only reports the failure.
+func writeCanaryMetrics(resp *dbdpb.RunWriteCanaryResponse, err error) []prometheus.Metric {
+	if err != nil {
+		return []prometheus.Metric{prometheus.MustNewConstMetric(writeCanarySuccess, prometheus.GaugeValue, 0)}
+	}
+	return []prometheus.Metric{
+		prometheus.MustNewConstMetric(writeCanarySuccess, prometheus.GaugeValue, 1),
+		prometheus.MustNewConstMetric(writeCanaryLatency, prometheus.GaugeValue, resp.GetWriteSeconds(), "write"),
+		prometheus.MustNewConstMetric(writeCanaryLatency, prometheus.GaugeValue, resp.GetReadSeconds(), "read"),
+	}
+}
@@ -37,6 +37,7 @@ var dbservice = flag.String("dbservice", "", "The DB service.")
 var dbport = flag.Int("dbport", 0, "The DB service port.")
 var dest = flag.String("dest", "", "The dest url to the replication destination location")
 var retentionDays = flag.Int("retentiondays", 7, "how long(in days) PITR need to retain redo logs")
+var tlsCertDir = flag.String("tls_cert_dir", "", "Directory of the CA certificate and key pair the DB service is dialed with over mutual TLS, plaintext if empty")
 
 func main() {
 	klog.InitFlags(nil)
@@ -55,7 +56,16 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	conn, err := common.DatabaseDaemonDialService(ctx, fmt.Sprintf("%s:%d", *dbservice, *dbport), grpc.WithBlock())
+	dialOpts := []grpc.DialOption{grpc.WithBlock()}
+	if *tlsCertDir != "" {
+		creds, err := common.ClientCredentials(*tlsCertDir)
+		if err != nil {
+			klog.ErrorS(err, "PITR Agent failed to load the TLS certificates", "dir", *tlsCertDir)
+			os.Exit(1)
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	}
+	conn, err := common.DatabaseDaemonDialService(ctx, fmt.Sprintf("%s:%d", *dbservice, *dbport), dialOpts...)
 	if err != nil {
 		klog.ErrorS(err, "PITR Agent failed to connect to dbdaemon")
 		os.Exit(1)
@@ -26,6 +26,7 @@ import (
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -37,6 +38,7 @@ import (
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
 )
 
@@ -48,6 +50,11 @@ var (
 	msgSep               = "; "
 	timeNow              = time.Now
 	reconcileTimeout     = 3 * time.Minute
+	// coldSnapshotPollInterval and coldSnapshotTimeout bound the wait for
+	// the volume snapshots of a cold backup to be cut while the database
+	// is down, within reconcileTimeout.
+	coldSnapshotPollInterval = 5 * time.Second
+	coldSnapshotTimeout      = 2 * time.Minute
 )
 
 // BackupReconciler reconciles a Backup object.
@@ -70,13 +77,17 @@ type backupControl interface {
 	LoadConfig(namespace string) (*v1alpha1.Config, error)
 	UpdateStatus(obj client.Object) error
 	UpdateBackup(obj client.Object) error
+	// Size returns the storage size of a physical backup in GCS.
+	Size(ctx context.Context, backup *v1alpha1.Backup) (int64, error)
 }
 
 // +kubebuilder:rbac:groups=oracle.db.anthosapis.com,resources=backups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=oracle.db.anthosapis.com,resources=backups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=oracle.db.anthosapis.com,resources=backupschedules,verbs=get;list;watch
 // +kubebuilder:rbac:groups=oracle.db.anthosapis.com,resources=instances,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=oracle.db.anthosapis.com,resources=instances/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshotclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshotcontents,verbs=get;list;watch
 // +kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources=volumesnapshots,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
@@ -100,6 +111,28 @@ func backupSubType(st string) controllers.PhysicalBackupRequest_Type {
 	return controllers.PhysicalBackupRequest_INSTANCE
 }
 
+// missingPDBs returns the PDBs a Database level backup requests which the
+// instance doesn't have. PDB names are case-insensitive.
+func missingPDBs(backup *v1alpha1.Backup, inst *v1alpha1.Instance) []string {
+	if backup.Spec.Type != commonv1alpha1.BackupTypePhysical || backupSubType(backup.Spec.Subtype) != controllers.PhysicalBackupRequest_DATABASE {
+		return nil
+	}
+	var missing []string
+	for _, pdb := range backup.Spec.BackupItems {
+		found := false
+		for _, name := range inst.Status.DatabaseNames {
+			if strings.EqualFold(pdb, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, pdb)
+		}
+	}
+	return missing
+}
+
 // updateBackupStatus updates the phase of Backup and Instance objects to the required state.
 func (r *BackupReconciler) updateBackupStatus(ctx context.Context, backup *v1alpha1.Backup, inst *v1alpha1.Instance) error {
 	readyCond := k8s.FindCondition(backup.Status.Conditions, k8s.Ready)
@@ -141,6 +174,10 @@ func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res
 		return r.reconcileVerifyExists(ctx, backup, log)
 	}
 
+	if backup.Spec.Mode == v1alpha1.ValidateRestore {
+		return r.reconcileValidateRestore(ctx, backup, log)
+	}
+
 	if !backup.DeletionTimestamp.IsZero() {
 		return r.reconcileBackupDeletion(ctx, backup, log)
 	}
@@ -252,9 +289,25 @@ func (r *BackupReconciler) reconcileBackupCreation(ctx context.Context, backup *
 			log.Info("reconcileBackupCreation: BackupPending->BackupFailed")
 			return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
 		}
+		if missing := missingPDBs(backup, inst); len(missing) > 0 {
+			msg := fmt.Sprintf("PDB(s) %s requested in .spec.backupItems not found in instance %s", strings.Join(missing, ", "), inst.Name)
+			r.Recorder.Event(backup, corev1.EventTypeWarning, k8s.BackupFailed, msg)
+			backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, v1.ConditionFalse, k8s.BackupFailed, msg)
+			backup.Status.Phase = commonv1alpha1.BackupFailed
+			log.Info("reconcileBackupCreation: BackupPending->BackupFailed")
+			return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+		}
 		// backup type is validated in validateBackupSpec
 		b := r.OracleBackupFactory.newOracleBackup(r, backup, inst, log)
 		if backup.Status.BackupID == "" || backup.Status.BackupTime == "" || backup.Status.StartTime == nil {
+			chain, err := r.planIncrementalChain(ctx, backup)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to plan the incremental backup chain: %v", err)
+			}
+			if chain != nil {
+				log.Info("incremental backup chain planned", "level", chain.Level, "anchor", chain.Anchor, "parents", chain.Parents)
+			}
+			backup.Status.IncrementalChain = chain
 			backup.Status.BackupID = b.generateID()
 			backup.Status.BackupTime = timeNow().Format("20060102150405")
 			startTime := metav1.NewTime(timeNow())
@@ -265,13 +318,24 @@ func (r *BackupReconciler) reconcileBackupCreation(ctx context.Context, backup *
 		}
 
 		if err := r.addBackupMetadata(ctx, backup, &oracleBackupMetadata{
-			incarnation:       inst.Status.CurrentDatabaseIncarnation,
-			parentIncarnation: inst.Status.LastDatabaseIncarnation,
-			databaseImage:     inst.Status.ActiveImages["service"],
+			incarnation:           inst.Status.CurrentDatabaseIncarnation,
+			parentIncarnation:     inst.Status.LastDatabaseIncarnation,
+			databaseImage:         inst.Status.ActiveImages["service"],
+			databaseVersion:       r.databaseVersion(ctx, inst, log),
+			walletPasswordVersion: inst.Status.WalletPasswordVersion,
 		}); err != nil {
 			return ctrl.Result{}, err
 		}
 
+		if err := controllers.RunHooks(ctx, r, r.DatabaseClientFactory, r.Recorder, inst, v1alpha1.PreBackupHook); err != nil {
+			msg := fmt.Sprintf("pre-backup hook failed: %v", err)
+			r.Recorder.Event(backup, corev1.EventTypeWarning, k8s.BackupFailed, msg)
+			backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, v1.ConditionFalse, k8s.BackupFailed, msg)
+			backup.Status.Phase = commonv1alpha1.BackupFailed
+			log.Info("reconcileBackupCreation: BackupPending->BackupFailed")
+			return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+		}
+
 		if err := b.create(ctx); err != nil {
 			// default retry
 			return ctrl.Result{}, err
@@ -308,6 +372,15 @@ func (r *BackupReconciler) reconcileBackupCreation(ctx context.Context, backup *
 				duration := metav1.Duration{Duration: metav1.Now().Sub(backup.Status.StartTime.Time)}
 				backup.Status.Duration = &duration
 				backup.Status.GcsPath = controllers.GetBackupGcsPath(backup)
+				if backup.Spec.Type == commonv1alpha1.BackupTypePhysical && backup.Status.GcsPath != "" {
+					// Recorded once so that pruning by size doesn't list the
+					// backup files on every schedule reconcile.
+					if size, err := r.BackupCtrl.Size(ctx, backup); err != nil {
+						log.Error(err, "failed to measure the backup in GCS")
+					} else {
+						backup.Status.Size = resource.NewQuantity(size, resource.BinarySI)
+					}
+				}
 				log.Info("reconcileBackupCreation: BackupInProgress->BackupReady")
 			} else {
 				r.Recorder.Event(backup, corev1.EventTypeWarning, "BackupFailed", err.Error())
@@ -328,6 +401,17 @@ func (r *BackupReconciler) reconcileBackupCreation(ctx context.Context, backup *
 			// Immediately return to update the object and do the rest of work in the next reconcile cycle.
 			return ctrl.Result{}, r.Update(ctx, backup)
 		}
+		if backup.Spec.Type == commonv1alpha1.BackupTypePhysical && backup.Status.GcsPath != "" && backup.Status.Size == nil {
+			// Backups which succeeded before their size was recorded are
+			// measured once here, the backup schedule only reads it.
+			size, err := r.BackupCtrl.Size(ctx, backup)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to measure the backup in GCS: %v", err)
+			}
+			backup.Status.Size = resource.NewQuantity(size, resource.BinarySI)
+			log.Info("recorded the size of the backup", "size", backup.Status.Size)
+			return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+		}
 		return ctrl.Result{}, nil
 	default:
 		log.Info("no action needed", "backupReady", backupReadyCond)
@@ -431,5 +515,29 @@ func (r *BackupReconciler) addBackupMetadata(ctx context.Context, backup *v1alph
 	if backupMetadata.databaseImage != "" {
 		backup.Annotations[controllers.DatabaseImageAnnotation] = backupMetadata.databaseImage
 	}
+	if backupMetadata.databaseVersion != "" {
+		backup.Annotations[controllers.DatabaseVersionAnnotation] = backupMetadata.databaseVersion
+	}
+	if backupMetadata.walletPasswordVersion != "" {
+		backup.Annotations[controllers.WalletPasswordVersionAnnotation] = backupMetadata.walletPasswordVersion
+	}
 	return r.BackupCtrl.UpdateBackup(backup)
 }
+
+// databaseVersion returns the full version of the Oracle home of the
+// instance, which a restore of the backup is checked against, or an empty
+// string if it is unknown.
+func (r *BackupReconciler) databaseVersion(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) string {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.Namespace, inst.Name)
+	if err != nil {
+		log.Error(err, "failed to create database daemon client")
+		return ""
+	}
+	defer closeConn()
+	resp, err := dbClient.FetchServiceImageMetaData(ctx, &dbdpb.FetchServiceImageMetaDataRequest{})
+	if err != nil {
+		log.Error(err, "failed to fetch the service image metadata")
+		return ""
+	}
+	return resp.GetVersionFull()
+}
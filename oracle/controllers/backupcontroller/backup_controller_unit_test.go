@@ -10,6 +10,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,7 +22,9 @@ import (
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
 )
 
@@ -44,6 +47,7 @@ type mockBackupControl struct {
 	loadConfig         func(namespace string) (*v1alpha1.Config, error)
 	updateStatus       func(obj client.Object) error
 	updateBackup       func(obj client.Object) error
+	size               func(ctx context.Context, backup *v1alpha1.Backup) (int64, error)
 }
 
 func (c *mockBackupControl) ValidateBackupSpec(backup *v1alpha1.Backup) bool {
@@ -70,6 +74,10 @@ func (c *mockBackupControl) UpdateBackup(obj client.Object) error {
 	return c.updateBackup(obj)
 }
 
+func (c *mockBackupControl) Size(ctx context.Context, backup *v1alpha1.Backup) (int64, error) {
+	return c.size(ctx, backup)
+}
+
 type mockOracleBackup struct {
 	statusFunc      func(ctx context.Context) (done bool, err error)
 	createCalledCnt int
@@ -217,6 +225,8 @@ func TestReconcileBackupCreation(t *testing.T) {
 		instNotReady                    bool
 		createDone                      bool
 		createError                     error
+		gcsDir                          string
+		finalizers                      []string
 		wantNewStatus                   v1alpha1.BackupStatus
 		wantReconcileResult             ctrl.Result
 		wantOracleBackupCreateCalledCnt int
@@ -335,6 +345,78 @@ func TestReconcileBackupCreation(t *testing.T) {
 			},
 			wantOracleBackupStatusCalledCnt: 1,
 			wantReconcileResult:             ctrl.Result{},
+		}, {
+			name:       "Physical backup records its size when create is done",
+			createDone: true,
+			gcsDir:     "gs://bucket/backups",
+			oldStatus: v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupInProgress,
+					Conditions: []metav1.Condition{
+						{
+							Type:   k8s.Ready,
+							Status: metav1.ConditionFalse,
+							Reason: k8s.BackupInProgress,
+						},
+					},
+				},
+				BackupID:   testBackupID,
+				BackupTime: testTimeNow.Format("20060102150405"),
+				StartTime:  &testTimeNow,
+			},
+			wantNewStatus: v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupSucceeded,
+					Conditions: []metav1.Condition{
+						{
+							Type:   k8s.Ready,
+							Status: metav1.ConditionTrue,
+							Reason: k8s.BackupReady,
+						},
+					},
+				},
+				GcsPath:    "gs://bucket/backups/" + testBackupName,
+				BackupID:   testBackupID,
+				BackupTime: testTimeNow.Format("20060102150405"),
+				StartTime:  &testTimeNow,
+				Size:       resource.NewQuantity(4096, resource.BinarySI),
+			},
+			wantOracleBackupStatusCalledCnt: 1,
+			wantReconcileResult:             ctrl.Result{},
+		}, {
+			name:       "Succeeded physical backup without a size records its size",
+			gcsDir:     "gs://bucket/backups",
+			finalizers: []string{controllers.FinalizerName},
+			oldStatus: v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupSucceeded,
+					Conditions: []metav1.Condition{
+						{
+							Type:   k8s.Ready,
+							Status: metav1.ConditionTrue,
+							Reason: k8s.BackupReady,
+						},
+					},
+				},
+				GcsPath:  "gs://bucket/backups/" + testBackupName,
+				BackupID: testBackupID,
+			},
+			wantNewStatus: v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupSucceeded,
+					Conditions: []metav1.Condition{
+						{
+							Type:   k8s.Ready,
+							Status: metav1.ConditionTrue,
+							Reason: k8s.BackupReady,
+						},
+					},
+				},
+				GcsPath:  "gs://bucket/backups/" + testBackupName,
+				BackupID: testBackupID,
+				Size:     resource.NewQuantity(4096, resource.BinarySI),
+			},
+			wantReconcileResult: ctrl.Result{},
 		}, {
 			name:        "Status transition from inprogress to fail when create fails",
 			createDone:  true,
@@ -388,6 +470,9 @@ func TestReconcileBackupCreation(t *testing.T) {
 			backupCtrl.validateBackupSpec = func(backup *v1alpha1.Backup) bool {
 				return true
 			}
+			backupCtrl.size = func(ctx context.Context, backup *v1alpha1.Backup) (int64, error) {
+				return 4096, nil
+			}
 			backupCtrl.getInstance = func(name, namespace string) (*v1alpha1.Instance, error) {
 				readyCond := metav1.Condition{Type: k8s.Ready, Status: metav1.ConditionTrue}
 				if tc.instNotReady {
@@ -412,7 +497,13 @@ func TestReconcileBackupCreation(t *testing.T) {
 			oracleBackup.statusFunc = func(ctx context.Context) (done bool, err error) {
 				return tc.createDone, tc.createError
 			}
-			gotReconcileResult, _ := reconciler.reconcileBackupCreation(context.Background(), newBackupWithStatus(tc.oldStatus), reconciler.Log)
+			backup := newBackupWithStatus(tc.oldStatus)
+			if tc.gcsDir != "" {
+				backup.Spec.Type = commonv1alpha1.BackupTypePhysical
+				backup.Spec.GcsDir = tc.gcsDir
+			}
+			backup.Finalizers = tc.finalizers
+			gotReconcileResult, _ := reconciler.reconcileBackupCreation(context.Background(), backup, reconciler.Log)
 			if diff := cmp.Diff(gotReconcileResult, tc.wantReconcileResult); diff != "" {
 				t.Errorf("reconciler.reconcileBackupCreation got unexpected reconcile result: -want +got %v", diff)
 			}
@@ -632,3 +723,67 @@ func newTestBackupReconciler() (reconciler *BackupReconciler,
 		DatabaseClientFactory: &testhelpers.FakeDatabaseClientFactory{Dbclient: dbClient},
 	}, b, c, dbClient
 }
+
+func TestMissingPDBs(t *testing.T) {
+	inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{DatabaseNames: []string{"PDB1", "pdb2"}}}
+	tests := []struct {
+		name    string
+		subtype string
+		items   []string
+		want    []string
+	}{
+		{name: "all found", subtype: "Database", items: []string{"pdb1", "PDB2"}},
+		{name: "missing", subtype: "Database", items: []string{"pdb1", "pdb3"}, want: []string{"pdb3"}},
+		{name: "instance level", subtype: "Instance", items: []string{"pdb3"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backup := &v1alpha1.Backup{Spec: v1alpha1.BackupSpec{
+				BackupSpec:  commonv1alpha1.BackupSpec{Type: commonv1alpha1.BackupTypePhysical},
+				Subtype:     tc.subtype,
+				BackupItems: tc.items,
+			}}
+			if diff := cmp.Diff(tc.want, missingPDBs(backup, inst)); diff != "" {
+				t.Errorf("missingPDBs got unexpected PDBs (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestAddBackupMetadataDatabaseVersion(t *testing.T) {
+	reconciler, _, backupControl, dbClient := newTestBackupReconciler()
+	dbClient.SetMethodToResp("FetchServiceImageMetaData", &dbdpb.FetchServiceImageMetaDataResponse{Version: "19.3", VersionFull: "19.15.0.0.0"})
+	var updated *v1alpha1.Backup
+	backupControl.updateBackup = func(obj client.Object) error {
+		updated = obj.(*v1alpha1.Backup)
+		return nil
+	}
+	ctx := context.Background()
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: testInstanceName, Namespace: testNamespace}}
+	backup := newBackupWithStatus(v1alpha1.BackupStatus{})
+	if err := reconciler.addBackupMetadata(ctx, backup, &oracleBackupMetadata{
+		databaseVersion: reconciler.databaseVersion(ctx, inst, reconciler.Log),
+	}); err != nil {
+		t.Fatalf("addBackupMetadata failed: %v", err)
+	}
+	if got := updated.Annotations[controllers.DatabaseVersionAnnotation]; got != "19.15.0.0.0" {
+		t.Errorf("addBackupMetadata got database version annotation %q, want 19.15.0.0.0", got)
+	}
+}
+
+func TestAddBackupMetadataWalletPasswordVersion(t *testing.T) {
+	reconciler, _, backupControl, _ := newTestBackupReconciler()
+	var updated *v1alpha1.Backup
+	backupControl.updateBackup = func(obj client.Object) error {
+		updated = obj.(*v1alpha1.Backup)
+		return nil
+	}
+	const version = "projects/p/secrets/tde-password/versions/4"
+	backup := newBackupWithStatus(v1alpha1.BackupStatus{})
+	if err := reconciler.addBackupMetadata(context.Background(), backup, &oracleBackupMetadata{walletPasswordVersion: version}); err != nil {
+		t.Fatalf("addBackupMetadata failed: %v", err)
+	}
+	if got := updated.Annotations[controllers.WalletPasswordVersionAnnotation]; got != version {
+		t.Errorf("addBackupMetadata got wallet password version annotation %q, want %q", got, version)
+	}
+}
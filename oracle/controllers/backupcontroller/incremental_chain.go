@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backupcontroller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+)
+
+// defaultLevel0Interval is how long a level 0 backup anchors an incremental
+// chain unless the BackupSchedule sets its own interval.
+const defaultLevel0Interval = 7 * 24 * time.Hour
+
+// planIncrementalChain places a backup created by a BackupSchedule in
+// incremental chain mode in its chain, it returns nil for other backups.
+func (r *BackupReconciler) planIncrementalChain(ctx context.Context, backup *v1alpha1.Backup) (*v1alpha1.IncrementalChainStatus, error) {
+	scheduleName := backup.Labels[controllers.IncrementalChainLabel]
+	if scheduleName == "" || backup.Spec.Type != commonv1alpha1.BackupTypePhysical {
+		return nil, nil
+	}
+	var schedule v1alpha1.BackupSchedule
+	if err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: scheduleName}, &schedule); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if schedule.Spec.IncrementalChain == nil {
+		return nil, nil
+	}
+	var backups v1alpha1.BackupList
+	if err := r.List(ctx, &backups, client.InNamespace(backup.Namespace), client.MatchingLabels{controllers.IncrementalChainLabel: scheduleName}); err != nil {
+		return nil, err
+	}
+	return nextChainLink(*schedule.Spec.IncrementalChain, backup.Name, backups.Items, timeNow()), nil
+}
+
+// nextChainLink returns the place of the named backup in the chain of the
+// other backups. It takes a new level 0 backup if the chain has no succeeded
+// level 0 backup or the latest one is older than the level 0 interval, and a
+// level 1 backup on top of the latest one otherwise.
+func nextChainLink(spec v1alpha1.IncrementalChainSpec, name string, backups []v1alpha1.Backup, now time.Time) *v1alpha1.IncrementalChainStatus {
+	interval := defaultLevel0Interval
+	if spec.Level0Interval != nil {
+		interval = spec.Level0Interval.Duration
+	}
+
+	var links []v1alpha1.Backup
+	for _, b := range backups {
+		if b.Name == name || !b.DeletionTimestamp.IsZero() || b.Status.Phase != commonv1alpha1.BackupSucceeded ||
+			b.Status.IncrementalChain == nil || b.Status.StartTime == nil {
+			continue
+		}
+		links = append(links, b)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Status.StartTime.Before(links[j].Status.StartTime)
+	})
+
+	anchor := -1
+	for i, b := range links {
+		if b.Status.IncrementalChain.Level == 0 {
+			anchor = i
+		}
+	}
+	if anchor < 0 || now.Sub(links[anchor].Status.StartTime.Time) >= interval {
+		return &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: name}
+	}
+
+	link := &v1alpha1.IncrementalChainStatus{Level: 1, Cumulative: spec.Cumulative, Anchor: links[anchor].Name}
+	if spec.Cumulative {
+		return link
+	}
+	// A differential backup holds the changes since the latest level 1
+	// backup, which holds every change since the level 0 backup if it is
+	// cumulative.
+	for _, b := range links[anchor+1:] {
+		if b.Status.IncrementalChain.Cumulative {
+			link.Parents = nil
+		}
+		link.Parents = append(link.Parents, b.Name)
+	}
+	return link
+}
+
+// incrementalLevel returns the incremental level of a physical backup and
+// whether it is cumulative, the level planned for its chain takes precedence
+// over the one requested in the spec.
+func incrementalLevel(backup *v1alpha1.Backup) (int32, bool) {
+	if chain := backup.Status.IncrementalChain; chain != nil {
+		return chain.Level, chain.Cumulative
+	}
+	return backup.Spec.Level, false
+}
@@ -0,0 +1,153 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backupcontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+)
+
+func chainBackup(name string, started time.Time, phase commonv1alpha1.BackupPhase, chain *v1alpha1.IncrementalChainStatus) v1alpha1.Backup {
+	startTime := metav1.NewTime(started)
+	return v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      name,
+			Labels:    map[string]string{controllers.IncrementalChainLabel: "nightly"},
+		},
+		Spec: v1alpha1.BackupSpec{BackupSpec: commonv1alpha1.BackupSpec{Instance: testInstanceName, Type: commonv1alpha1.BackupTypePhysical}},
+		Status: v1alpha1.BackupStatus{
+			BackupStatus:     commonv1alpha1.BackupStatus{Phase: phase},
+			StartTime:        &startTime,
+			IncrementalChain: chain,
+		},
+	}
+}
+
+func TestNextChainLink(t *testing.T) {
+	now := testTimeNow.Time
+	day := 24 * time.Hour
+	level0 := &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "l0"}
+	tests := []struct {
+		name    string
+		spec    v1alpha1.IncrementalChainSpec
+		backups []v1alpha1.Backup
+		want    *v1alpha1.IncrementalChainStatus
+	}{
+		{
+			name: "first backup",
+			want: &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "next"},
+		},
+		{
+			name: "failed level 0 backup",
+			backups: []v1alpha1.Backup{
+				chainBackup("l0", now.Add(-day), commonv1alpha1.BackupFailed, level0),
+			},
+			want: &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "next"},
+		},
+		{
+			name: "differential",
+			backups: []v1alpha1.Backup{
+				chainBackup("l1b", now.Add(-day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a"}}),
+				chainBackup("l0", now.Add(-3*day), commonv1alpha1.BackupSucceeded, level0),
+				chainBackup("l1a", now.Add(-2*day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0"}),
+				chainBackup("l1x", now.Add(-day/2), commonv1alpha1.BackupFailed, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a", "l1b"}}),
+			},
+			want: &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a", "l1b"}},
+		},
+		{
+			name: "differential after a cumulative backup",
+			backups: []v1alpha1.Backup{
+				chainBackup("l0", now.Add(-3*day), commonv1alpha1.BackupSucceeded, level0),
+				chainBackup("l1a", now.Add(-2*day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0"}),
+				chainBackup("l1b", now.Add(-day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Cumulative: true, Anchor: "l0"}),
+			},
+			want: &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1b"}},
+		},
+		{
+			name: "cumulative",
+			spec: v1alpha1.IncrementalChainSpec{Cumulative: true},
+			backups: []v1alpha1.Backup{
+				chainBackup("l0", now.Add(-3*day), commonv1alpha1.BackupSucceeded, level0),
+				chainBackup("l1a", now.Add(-2*day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0"}),
+			},
+			want: &v1alpha1.IncrementalChainStatus{Level: 1, Cumulative: true, Anchor: "l0"},
+		},
+		{
+			name: "level 0 interval elapsed",
+			spec: v1alpha1.IncrementalChainSpec{Level0Interval: &metav1.Duration{Duration: 2 * day}},
+			backups: []v1alpha1.Backup{
+				chainBackup("l0", now.Add(-3*day), commonv1alpha1.BackupSucceeded, level0),
+				chainBackup("l1a", now.Add(-2*day), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0"}),
+			},
+			want: &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "next"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := nextChainLink(tc.spec, "next", tc.backups, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nextChainLink got unexpected chain (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestPlanIncrementalChain(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	schedule := &v1alpha1.BackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "nightly"},
+		Spec:       v1alpha1.BackupScheduleSpec{IncrementalChain: &v1alpha1.IncrementalChainSpec{Cumulative: true}},
+	}
+	level0 := chainBackup("l0", testTimeNow.Add(-time.Hour), commonv1alpha1.BackupSucceeded, &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "l0"})
+	next := chainBackup("next", testTimeNow.Time, "", nil)
+	unlabeled := chainBackup("adhoc", testTimeNow.Time, "", nil)
+	unlabeled.Labels = nil
+
+	reconciler, _, _, _ := newTestBackupReconciler()
+	reconciler.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(schedule, &level0).Build()
+	timeNow = func() time.Time { return testTimeNow.Time }
+	defer func() { timeNow = time.Now }()
+
+	got, err := reconciler.planIncrementalChain(context.Background(), &next)
+	if err != nil {
+		t.Fatalf("planIncrementalChain failed: %v", err)
+	}
+	want := &v1alpha1.IncrementalChainStatus{Level: 1, Cumulative: true, Anchor: "l0"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("planIncrementalChain got unexpected chain (-want +got):\n%v", diff)
+	}
+	if level, cumulative := incrementalLevel(&v1alpha1.Backup{Status: v1alpha1.BackupStatus{IncrementalChain: got}}); level != 1 || !cumulative {
+		t.Errorf("incrementalLevel got level %d cumulative %v, want level 1 cumulative", level, cumulative)
+	}
+
+	got, err = reconciler.planIncrementalChain(context.Background(), &unlabeled)
+	if err != nil || got != nil {
+		t.Errorf("planIncrementalChain got %v, %v for a backup outside of a chain, want nil", got, err)
+	}
+}
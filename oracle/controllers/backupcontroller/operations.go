@@ -3,18 +3,26 @@ package backupcontroller
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// pdbNameRe matches the unquoted PDB names a Database level backup may
+// request, Oracle limits them to 30 characters.
+var pdbNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,29}$`)
+
 type RealBackupControl struct {
 	Client client.Client
+	// GCS measures the backups in GCS, it defaults to the GCS client.
+	GCS util.GCSUtil
 }
 
 func (c *RealBackupControl) GetBackup(name, namespace string) (*v1alpha1.Backup, error) {
@@ -62,6 +70,14 @@ func (c *RealBackupControl) UpdateBackup(obj client.Object) error {
 	return c.Client.Update(context.TODO(), obj)
 }
 
+func (c *RealBackupControl) Size(ctx context.Context, backup *v1alpha1.Backup) (int64, error) {
+	gcs := c.GCS
+	if gcs == nil {
+		gcs = &util.GCSUtilImpl{}
+	}
+	return gcs.Size(ctx, backup.Status.GcsPath)
+}
+
 func (c *RealBackupControl) ValidateBackupSpec(backup *v1alpha1.Backup) bool {
 	var errMsgs []string
 	if backup.Spec.Type != commonv1alpha1.BackupTypeSnapshot && backup.Spec.Type != commonv1alpha1.BackupTypePhysical {
@@ -70,6 +86,25 @@ func (c *RealBackupControl) ValidateBackupSpec(backup *v1alpha1.Backup) bool {
 	if backup.Spec.Type == commonv1alpha1.BackupTypeSnapshot && backup.Spec.Subtype != "" && backup.Spec.Subtype != "Instance" {
 		errMsgs = append(errMsgs, fmt.Sprintf("%s backup only support .spec.subtype 'Instance'", backup.Spec.Type))
 	}
+	if backup.Spec.Type == commonv1alpha1.BackupTypePhysical {
+		switch backup.Spec.Subtype {
+		case "", "Instance":
+		case "Database":
+			if len(backup.Spec.BackupItems) == 0 {
+				errMsgs = append(errMsgs, "a Database level backup requires the PDB names in .spec.backupItems")
+			}
+			for _, pdb := range backup.Spec.BackupItems {
+				if !pdbNameRe.MatchString(pdb) {
+					errMsgs = append(errMsgs, fmt.Sprintf("%q in .spec.backupItems is not a valid PDB name", pdb))
+				}
+			}
+		default:
+			errMsgs = append(errMsgs, fmt.Sprintf("%s backup only support .spec.subtype 'Instance' or 'Database'", backup.Spec.Type))
+		}
+	}
+	if backup.Spec.Cold && backup.Spec.Type != commonv1alpha1.BackupTypeSnapshot {
+		errMsgs = append(errMsgs, fmt.Sprintf("%s backup does not support .spec.cold", backup.Spec.Type))
+	}
 	if backup.Spec.Instance == "" {
 		errMsgs = append(errMsgs, fmt.Sprintf("spec.Instance is not set in the backup request: %v", backup))
 	}
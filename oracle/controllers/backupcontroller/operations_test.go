@@ -54,6 +54,69 @@ func TestValidateBackupSpec(t *testing.T) {
 				Subtype: "Database",
 			},
 			wantRes: false,
+		}, {
+			name: "Valid cold snapshot backup spec",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypeSnapshot,
+				},
+				Cold: true,
+			},
+			wantRes: true,
+		}, {
+			name: "Invalid cold physical backup",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypePhysical,
+				},
+				Cold: true,
+			},
+			wantRes: false,
+		}, {
+			name: "Valid database level physical backup",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypePhysical,
+				},
+				Subtype:     "Database",
+				BackupItems: []string{"pdb1", "PDB_2"},
+			},
+			wantRes: true,
+		}, {
+			name: "Invalid database level physical backup without PDBs",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypePhysical,
+				},
+				Subtype: "Database",
+			},
+			wantRes: false,
+		}, {
+			name: "Invalid PDB name",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypePhysical,
+				},
+				Subtype:     "Database",
+				BackupItems: []string{"pdb1; drop"},
+			},
+			wantRes: false,
+		}, {
+			name: "Unsupported tablespace level physical backup",
+			spec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypePhysical,
+				},
+				Subtype:     "Tablespace",
+				BackupItems: []string{"USERS"},
+			},
+			wantRes: false,
 		}, {
 			name: "Invalid missing spec.instance",
 			spec: v1alpha1.BackupSpec{
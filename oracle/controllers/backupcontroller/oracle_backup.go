@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -17,6 +18,7 @@ import (
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/pkg/utils"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
 type oracleBackupFactory interface {
@@ -58,6 +60,10 @@ type oracleBackupMetadata struct {
 	parentIncarnation string
 	scn               string
 	databaseImage     string
+	databaseVersion   string
+	// walletPasswordVersion is the GSM secret version holding the TDE
+	// keystore password of the instance.
+	walletPasswordVersion string
 }
 
 type snapshotBackup struct {
@@ -101,28 +107,89 @@ func (b *snapshotBackup) create(ctx context.Context) error {
 	}
 	applyOpts := []client.PatchOption{client.ForceOwnership, client.FieldOwner("backup-controller")}
 
-	return utils.SnapshotDisks(ctx, controllers.DiskSpecs(b.inst, config), b.backup, b.r.Client, b.r.Scheme, getPvcNames, applyOpts)
+	snapshot := func(ctx context.Context) error {
+		return utils.SnapshotDisks(ctx, controllers.DiskSpecs(b.inst, config), b.backup, b.r.Client, b.r.Scheme, getPvcNames, applyOpts)
+	}
+	if !b.backup.Spec.Cold {
+		return snapshot(ctx)
+	}
+
+	dbClient, closeConn, err := b.r.DatabaseClientFactory.New(ctx, b.r, b.inst.Namespace, b.inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	return coldSnapshot(ctx, dbClient, b.inst.Spec.CDBName, snapshot, b.snapshotsCut, b.log)
 }
 
-func (b *snapshotBackup) delete(ctx context.Context) error {
-	// snapshot backup deletion is handled by k8s garbage collection.
-	return nil
+// coldSnapshot takes a consistent cold backup: it shuts the database down,
+// takes the volume snapshots and starts the database up again once cut
+// reports that all snapshots were cut, while their upload may still be in
+// progress. The database is started up even if the snapshots fail or the
+// reconcile times out.
+func coldSnapshot(ctx context.Context, dbClient dbdpb.DatabaseDaemonClient, cdbName string, snapshot func(context.Context) error, cut func(context.Context) (bool, error), log logr.Logger) (err error) {
+	log.Info("cold snapshot backup: shutting down the database")
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName: cdbName,
+		Option:       "immediate",
+	}); err != nil {
+		return fmt.Errorf("failed to shut down the database: %v", err)
+	}
+	defer func() {
+		log.Info("cold snapshot backup: starting up the database")
+		startCtx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+		defer cancel()
+		if _, startErr := dbClient.BounceDatabase(startCtx, &dbdpb.BounceDatabaseRequest{
+			Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
+			DatabaseName: cdbName,
+		}); startErr != nil {
+			if err != nil {
+				err = fmt.Errorf("%v; failed to start up the database: %v", err, startErr)
+			} else {
+				err = fmt.Errorf("failed to start up the database: %v", startErr)
+			}
+		}
+	}()
+
+	if err := snapshot(ctx); err != nil {
+		return err
+	}
+	deadline := timeNow().Add(coldSnapshotTimeout)
+	for {
+		done, err := cut(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			log.Info("cold snapshot backup: volume snapshots cut")
+			return nil
+		}
+		if !timeNow().Before(deadline) {
+			return fmt.Errorf("volume snapshots were not cut within %v", coldSnapshotTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(coldSnapshotPollInterval):
+		}
+	}
 }
 
-func (b *snapshotBackup) status(ctx context.Context) (done bool, err error) {
-	b.log.Info("found a backup request in-progress")
+// volumeSnapshots returns the volume snapshots of the backup.
+func (b *snapshotBackup) volumeSnapshots(ctx context.Context) ([]snapv1.VolumeSnapshot, error) {
 	ns := b.backup.Namespace
 	sel := labels.NewSelector()
 	vsLabels := []string{b.backup.Status.BackupID + "-u02", b.backup.Status.BackupID + "-u03"}
 	req1, err := labels.NewRequirement("name", selection.In, vsLabels)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	sel.Add(*req1)
 
 	req2, err := labels.NewRequirement("namespace", selection.Equals, []string{ns})
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	sel.Add(*req2)
 
@@ -134,18 +201,84 @@ func (b *snapshotBackup) status(ctx context.Context) (done bool, err error) {
 	var volSnaps snapv1.VolumeSnapshotList
 	if err := b.r.List(ctx, &volSnaps, listOpts...); err != nil {
 		b.log.Error(err, "failed to get a volume snapshot")
-		return false, err
+		return nil, err
 	}
 	b.log.Info("list of found volume snapshots", "volSnaps", volSnaps)
+	return volSnaps.Items, nil
+}
+
+// snapshotsCut reports whether the point in time image of all volume
+// snapshots of the backup was taken, that is they have a creation time.
+func (b *snapshotBackup) snapshotsCut(ctx context.Context) (bool, error) {
+	volSnaps, err := b.volumeSnapshots(ctx)
+	if err != nil {
+		return false, err
+	}
+	return volumeSnapshotsCut(volSnaps)
+}
+
+// volumeSnapshotsCut reports whether all volume snapshots have a creation
+// time. It fails if a volume snapshot failed.
+func volumeSnapshotsCut(volSnaps []snapv1.VolumeSnapshot) (bool, error) {
+	if len(volSnaps) < 1 {
+		return false, nil
+	}
+	for _, vs := range volSnaps {
+		if vs.Status == nil {
+			return false, nil
+		}
+		if vs.Status.Error != nil && vs.Status.Error.Message != nil {
+			return false, fmt.Errorf("volumeSnapshot %s/%s failed with: %s", vs.Namespace, vs.Name, *vs.Status.Error.Message)
+		}
+		if vs.Status.CreationTime == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-	if len(volSnaps.Items) < 1 {
+// recordSnapshotHandles records the snapshot handles of the bound volume
+// snapshot contents in the backup status.
+func (b *snapshotBackup) recordSnapshotHandles(ctx context.Context, volSnaps []snapv1.VolumeSnapshot) error {
+	for _, vs := range volSnaps {
+		if vs.Status == nil || vs.Status.BoundVolumeSnapshotContentName == nil {
+			continue
+		}
+		var content snapv1.VolumeSnapshotContent
+		if err := b.r.Get(ctx, client.ObjectKey{Name: *vs.Status.BoundVolumeSnapshotContentName}, &content); err != nil {
+			return fmt.Errorf("failed to get volumeSnapshotContent %s: %v", *vs.Status.BoundVolumeSnapshotContentName, err)
+		}
+		if content.Status == nil || content.Status.SnapshotHandle == nil {
+			continue
+		}
+		if b.backup.Status.SnapshotHandles == nil {
+			b.backup.Status.SnapshotHandles = make(map[string]string)
+		}
+		b.backup.Status.SnapshotHandles[vs.Name] = *content.Status.SnapshotHandle
+	}
+	return nil
+}
+
+func (b *snapshotBackup) delete(ctx context.Context) error {
+	// snapshot backup deletion is handled by k8s garbage collection.
+	return nil
+}
+
+func (b *snapshotBackup) status(ctx context.Context) (done bool, err error) {
+	b.log.Info("found a backup request in-progress")
+	volSnaps, err := b.volumeSnapshots(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if len(volSnaps) < 1 {
 		b.log.Info("no volume snapshots found for a backup request marked as in-progress.", "backup.Status", b.backup.Status)
 		return false, errors.New("no volume snapshots found")
 	}
 	b.log.Info("found a volume snapshot(s) for a backup request in-progress")
 
 	vsStatus := make(map[string]bool)
-	for i, vs := range volSnaps.Items {
+	for i, vs := range volSnaps {
 		b.log.Info("iterating over volume snapshots", "VolumeSnapshot#", i, "name", vs.Name)
 		vsStatus[vs.Name] = false
 
@@ -169,9 +302,51 @@ func (b *snapshotBackup) status(ctx context.Context) (done bool, err error) {
 	}
 	b.log.Info("summary of VolumeSnapshot statuses", "vsStatus", vsStatus)
 
+	if err := b.recordSnapshotHandles(ctx, volSnaps); err != nil {
+		return false, err
+	}
+	problems, err := b.validate(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(problems) > 0 {
+		return true, fmt.Errorf("snapshot backup is not restorable: %s", strings.Join(problems, msgSep))
+	}
 	return true, nil
 }
 
+// validate checks that the snapshotted volumes hold all the database files
+// and that no datafile needs media recovery, similar to an RMAN restore
+// validate of a physical backup. It returns why the backup is not
+// restorable.
+func (b *snapshotBackup) validate(ctx context.Context) ([]string, error) {
+	config, err := b.r.BackupCtrl.LoadConfig(b.backup.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var mountPaths []string
+	for _, spec := range controllers.DiskSpecs(b.inst, config) {
+		var mount string
+		if controllers.IsReservedDiskName(spec.Name) {
+			_, mount = controllers.GetPVCNameAndMount(b.inst.Name, spec.Name)
+		} else {
+			_, mount = controllers.GetCustomPVCNameAndMount(b.inst, spec.Name)
+		}
+		mountPaths = append(mountPaths, "/"+mount)
+	}
+
+	dbClient, closeConn, err := b.r.DatabaseClientFactory.New(ctx, b.r, b.inst.Namespace, b.inst.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer closeConn()
+	resp, err := dbClient.ValidateSnapshotFiles(ctx, &dbdpb.ValidateSnapshotFilesRequest{MountPaths: mountPaths})
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate the snapshotted database files: %v", err)
+	}
+	return resp.GetProblems(), nil
+}
+
 func (b *snapshotBackup) generateID() string {
 	return fmt.Sprintf(backupName, b.backup.Spec.Instance, timeNow().Format("20060102"), "snap", timeNow().Nanosecond())
 }
@@ -204,23 +379,28 @@ func (b *physicalBackup) create(ctx context.Context) error {
 		backupset = b.backup.Spec.Backupset
 	}
 
+	level, cumulative := incrementalLevel(b.backup)
+
 	ctxBackup, cancel := context.WithTimeout(ctx, timeLimitMinutes)
 	defer cancel()
 
 	req := &controllers.PhysicalBackupRequest{
-		BackupSubType: backupSubType(b.backup.Spec.Subtype),
-		BackupItems:   b.backup.Spec.BackupItems,
-		Backupset:     *backupset,
-		CheckLogical:  b.backup.Spec.CheckLogical,
-		Compressed:    b.backup.Spec.Compressed,
-		Dop:           dop,
-		Level:         b.backup.Spec.Level,
-		Filesperset:   b.backup.Spec.Filesperset,
-		SectionSize:   b.backup.SectionSize(),
-		LocalPath:     b.backup.Spec.LocalPath,
-		BackupTag:     b.backup.Status.BackupTime,
-		GcsPath:       b.backup.Spec.GcsPath,
-		LroInput:      &controllers.LROInput{OperationId: lroOperationID(b.backup)},
+		BackupSubType:  backupSubType(b.backup.Spec.Subtype),
+		BackupItems:    b.backup.Spec.BackupItems,
+		Backupset:      *backupset,
+		CheckLogical:   b.backup.Spec.CheckLogical,
+		Compressed:     b.backup.Spec.Compressed,
+		Dop:            dop,
+		IgnoreCPULimit: b.backup.Spec.IgnoreCPULimit,
+		Level:          level,
+		Cumulative:     cumulative,
+		Filesperset:    b.backup.Spec.Filesperset,
+		SectionSize:    b.backup.SectionSize(),
+		LocalPath:      b.backup.Spec.LocalPath,
+		BackupTag:      b.backup.Status.BackupTime,
+		GcsPath:        b.backup.Spec.GcsPath,
+		Stream:         b.backup.Spec.StreamToGcs,
+		LroInput:       &controllers.LROInput{OperationId: lroOperationID(b.backup)},
 	}
 	if _, err := controllers.PhysicalBackup(ctxBackup, b.r, b.r.DatabaseClientFactory, b.backup.Namespace, b.backup.Spec.Instance, *req); err != nil &&
 		!controllers.IsAlreadyExistsError(err) {
@@ -274,6 +454,25 @@ func (b *physicalBackup) metadata(ctx context.Context) (metadata *oracleBackupMe
 	if resp == nil {
 		return nil, nil
 	}
+	if gcsPath := controllers.GetBackupGcsPath(b.backup); gcsPath != "" {
+		// Restores fall back to RMAN and the annotations without the
+		// metadata object, failing to write it doesn't fail the backup.
+		if err := controllers.WriteBackupMetadata(ctx, b.r, b.r.DatabaseClientFactory, b.backup.Namespace, b.backup.Spec.Instance, controllers.WriteBackupMetadataRequest{
+			GcsPath: gcsPath,
+			Metadata: &dbdpb.BackupMetadata{
+				Tag:                   b.backup.Status.BackupTime,
+				Scn:                   resp.BackupScn,
+				Incarnation:           resp.BackupIncarnation,
+				Timestamp:             resp.BackupTimestamp,
+				SourceVersion:         b.backup.Annotations[controllers.DatabaseVersionAnnotation],
+				SizeBytes:             resp.BackupSizeBytes,
+				Type:                  string(b.backup.Spec.Type),
+				WalletPasswordVersion: b.backup.Annotations[controllers.WalletPasswordVersionAnnotation],
+			},
+		}); err != nil {
+			b.log.Error(err, "failed to write the backup metadata object", "gcsPath", gcsPath)
+		}
+	}
 	return &oracleBackupMetadata{
 		timestamp:   resp.BackupTimestamp.AsTime(),
 		incarnation: resp.BackupIncarnation,
@@ -2,14 +2,23 @@ package backupcontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	"google.golang.org/grpc"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
 func TestPhysicalBackupCreate(t *testing.T) {
@@ -159,3 +168,224 @@ func TestPhysicalBackupStatus(t *testing.T) {
 		})
 	}
 }
+
+// bounceRecordingClient records the database bounces in steps.
+type bounceRecordingClient struct {
+	*testhelpers.FakeDatabaseClient
+	steps       *[]string
+	shutdownErr error
+	startupErr  error
+}
+
+func (c *bounceRecordingClient) BounceDatabase(ctx context.Context, in *dbdpb.BounceDatabaseRequest, opts ...grpc.CallOption) (*dbdpb.BounceDatabaseResponse, error) {
+	*c.steps = append(*c.steps, strings.ToLower(in.GetOperation().String()))
+	if in.GetOperation() == dbdpb.BounceDatabaseRequest_SHUTDOWN {
+		return &dbdpb.BounceDatabaseResponse{}, c.shutdownErr
+	}
+	return &dbdpb.BounceDatabaseResponse{}, c.startupErr
+}
+
+func TestColdSnapshot(t *testing.T) {
+	oldInterval, oldTimeout := coldSnapshotPollInterval, coldSnapshotTimeout
+	defer func() {
+		coldSnapshotPollInterval, coldSnapshotTimeout = oldInterval, oldTimeout
+	}()
+	coldSnapshotPollInterval = 0
+
+	testCases := []struct {
+		name        string
+		shutdownErr error
+		startupErr  error
+		snapshotErr error
+		cutErr      error
+		cutAfter    int
+		timeout     time.Duration
+		wantSteps   []string
+		wantErr     bool
+	}{
+		{
+			name:      "snapshots cut",
+			cutAfter:  2,
+			timeout:   time.Minute,
+			wantSteps: []string{"shutdown", "snapshot", "cut", "cut", "startup"},
+		},
+		{
+			name:        "shutdown fails",
+			shutdownErr: errors.New("ORA-01089"),
+			timeout:     time.Minute,
+			wantSteps:   []string{"shutdown"},
+			wantErr:     true,
+		},
+		{
+			name:        "snapshot fails",
+			snapshotErr: errors.New("no volumeSnapshotClass"),
+			timeout:     time.Minute,
+			wantSteps:   []string{"shutdown", "snapshot", "startup"},
+			wantErr:     true,
+		},
+		{
+			name:      "volume snapshot fails",
+			cutErr:    errors.New("volumeSnapshot failed"),
+			timeout:   time.Minute,
+			wantSteps: []string{"shutdown", "snapshot", "cut", "startup"},
+			wantErr:   true,
+		},
+		{
+			name:      "snapshots not cut in time",
+			cutAfter:  2,
+			wantSteps: []string{"shutdown", "snapshot", "cut", "startup"},
+			wantErr:   true,
+		},
+		{
+			name:       "startup fails",
+			startupErr: errors.New("ORA-01078"),
+			cutAfter:   1,
+			timeout:    time.Minute,
+			wantSteps:  []string{"shutdown", "snapshot", "cut", "startup"},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			coldSnapshotTimeout = tc.timeout
+			var steps []string
+			dbClient := &bounceRecordingClient{
+				FakeDatabaseClient: &testhelpers.FakeDatabaseClient{},
+				steps:              &steps,
+				shutdownErr:        tc.shutdownErr,
+				startupErr:         tc.startupErr,
+			}
+			snapshot := func(context.Context) error {
+				steps = append(steps, "snapshot")
+				return tc.snapshotErr
+			}
+			cuts := 0
+			cut := func(context.Context) (bool, error) {
+				steps = append(steps, "cut")
+				cuts++
+				return cuts >= tc.cutAfter, tc.cutErr
+			}
+
+			err := coldSnapshot(context.Background(), dbClient, "GCLOUD", snapshot, cut, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("coldSnapshot got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantSteps, steps); diff != "" {
+				t.Errorf("coldSnapshot got unexpected steps (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestVolumeSnapshotsCut(t *testing.T) {
+	now := metav1.Now()
+	msg := "snapshot quota exceeded"
+	testCases := []struct {
+		name     string
+		volSnaps []snapv1.VolumeSnapshot
+		want     bool
+		wantErr  bool
+	}{
+		{
+			name: "no volume snapshots",
+		},
+		{
+			name: "status missing",
+			volSnaps: []snapv1.VolumeSnapshot{
+				{Status: &snapv1.VolumeSnapshotStatus{CreationTime: &now}},
+				{},
+			},
+		},
+		{
+			name: "not cut",
+			volSnaps: []snapv1.VolumeSnapshot{
+				{Status: &snapv1.VolumeSnapshotStatus{CreationTime: &now}},
+				{Status: &snapv1.VolumeSnapshotStatus{}},
+			},
+		},
+		{
+			name: "cut",
+			volSnaps: []snapv1.VolumeSnapshot{
+				{Status: &snapv1.VolumeSnapshotStatus{CreationTime: &now}},
+				{Status: &snapv1.VolumeSnapshotStatus{CreationTime: &now, ReadyToUse: proto.Bool(false)}},
+			},
+			want: true,
+		},
+		{
+			name: "failed",
+			volSnaps: []snapv1.VolumeSnapshot{
+				{Status: &snapv1.VolumeSnapshotStatus{Error: &snapv1.VolumeSnapshotError{Message: &msg}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := volumeSnapshotsCut(tc.volSnaps)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("volumeSnapshotsCut got error %v, want error %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("volumeSnapshotsCut = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotBackupValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		disks          []commonv1alpha1.DiskSpec
+		problems       []string
+		wantMountPaths []string
+	}{
+		{
+			name:           "default disks",
+			wantMountPaths: []string{"/u02", "/u03"},
+		},
+		{
+			name: "custom disk",
+			disks: []commonv1alpha1.DiskSpec{
+				{Name: "DataDisk"},
+				{Name: "LogDisk"},
+				{Name: "ExtraDisk"},
+			},
+			wantMountPaths: []string{"/u02", "/u03", "/extradisk"},
+		},
+		{
+			name:           "not restorable",
+			problems:       []string{"redo log +DATA/GCLOUD/redo02.log is not on a snapshotted volume"},
+			wantMountPaths: []string{"/u02", "/u03"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _, backupControl, dbClient := newTestBackupReconciler()
+			backupControl.loadConfig = func(namespace string) (*v1alpha1.Config, error) {
+				return nil, nil
+			}
+			dbClient.SetMethodToResp("ValidateSnapshotFiles", &dbdpb.ValidateSnapshotFilesResponse{Problems: tc.problems})
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Name: testInstanceName, Namespace: testNamespace},
+				Spec:       v1alpha1.InstanceSpec{InstanceSpec: commonv1alpha1.InstanceSpec{Disks: tc.disks}},
+			}
+			b := &snapshotBackup{
+				r:      r,
+				backup: newBackupWithSpec(v1alpha1.BackupSpec{}),
+				inst:   inst,
+				log:    r.Log,
+			}
+
+			problems, err := b.validate(context.Background())
+			if err != nil {
+				t.Fatalf("snapshotBackup.validate() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.problems, problems); diff != "" {
+				t.Errorf("snapshotBackup.validate() got unexpected problems (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantMountPaths, dbClient.GotValidateSnapshotFilesRequest.GetMountPaths()); diff != "" {
+				t.Errorf("snapshotBackup.validate() got unexpected mount paths (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
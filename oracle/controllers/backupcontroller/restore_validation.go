@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backupcontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const restoreValidationReportName = "restore-validation-report.txt"
+
+// restoreValidationReportPath returns the GCS object a ValidateRestore
+// backup uploads its report to.
+func restoreValidationReportPath(backup *v1alpha1.Backup) string {
+	return strings.TrimSuffix(controllers.GetBackupGcsPath(backup), "/") + "/" + restoreValidationReportName
+}
+
+// reconcileValidateRestore validates that the database of the instance can be
+// restored from its backups and records the result in the backup status.
+// Scheduling a BackupSchedule with the ValidateRestore mode runs the
+// validation periodically.
+func (r *BackupReconciler) reconcileValidateRestore(ctx context.Context, backup *v1alpha1.Backup, log logr.Logger) (ctrl.Result, error) {
+	var errMsgs []string
+	if backup.Spec.Type != commonv1alpha1.BackupTypePhysical {
+		errMsgs = append(errMsgs, fmt.Sprintf("%v backup does not support ValidateRestore mode", backup.Spec.Type))
+	}
+	if controllers.GetBackupGcsPath(backup) == "" {
+		errMsgs = append(errMsgs, "Either .spec.gcsPath or .spec.gcsDir must be specified, ValidateRestore mode uploads its report to GCS")
+	}
+	if len(errMsgs) > 0 {
+		backup.Status.Phase = commonv1alpha1.BackupFailed
+		msg := strings.Join(errMsgs, msgSep)
+		r.Recorder.Event(backup, corev1.EventTypeWarning, k8s.NotSupported, msg)
+		backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, metav1.ConditionFalse, k8s.NotSupported, msg)
+		return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+	}
+
+	state := ""
+	if cond := k8s.FindCondition(backup.Status.Conditions, k8s.Ready); cond != nil {
+		state = cond.Reason
+	}
+	switch state {
+	case "":
+		inst, err := r.instReady(ctx, backup.Namespace, backup.Spec.Instance)
+		if err != nil {
+			log.Error(err, "instance not ready")
+			return ctrl.Result{RequeueAfter: requeueInterval}, nil
+		}
+		log.Info("Validating the restore of the database")
+		if _, err := controllers.RestoreValidationReport(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.RestoreValidationReportRequest{
+			GcsPath:      restoreValidationReportPath(backup),
+			CheckLogical: backup.Spec.CheckLogical,
+			LroInput:     &controllers.LROInput{OperationId: lroOperationID(backup)},
+		}); err != nil {
+			// default retry
+			return ctrl.Result{}, err
+		}
+		startTime := metav1.NewTime(timeNow())
+		backup.Status.StartTime = &startTime
+		backup.Status.Phase = commonv1alpha1.BackupInProgress
+		backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, metav1.ConditionFalse, k8s.BackupInProgress, "Validating the restore of the database.")
+		return ctrl.Result{RequeueAfter: statusCheckInterval}, r.BackupCtrl.UpdateStatus(backup)
+
+	case k8s.BackupInProgress:
+		id := lroOperationID(backup)
+		operation, err := controllers.GetLROOperation(ctx, r.DatabaseClientFactory, r, id, backup.Namespace, backup.Spec.Instance)
+		if err != nil {
+			if !strings.Contains(err.Error(), "code = NotFound") {
+				return ctrl.Result{}, err
+			}
+			// The validation was interrupted and the LRO is lost.
+			r.setRestoreValidationFailed(backup, "Restore validation interrupted")
+			return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+		}
+		if !operation.GetDone() {
+			log.Info("reconcileValidateRestore: InProgress")
+			return ctrl.Result{RequeueAfter: statusCheckInterval}, nil
+		}
+
+		if operation.GetError() != nil {
+			r.setRestoreValidationFailed(backup, fmt.Sprintf("Restore validation failed: %s", operation.GetError().GetMessage()))
+		} else {
+			resp := &dbdpb.RestoreValidationReportResponse{}
+			if err := operation.GetResponse().UnmarshalTo(resp); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to unmarshal the restore validation response: %v", err)
+			}
+			r.setRestoreValidationResult(backup, resp)
+		}
+		if backup.Status.StartTime != nil {
+			backup.Status.Duration = &metav1.Duration{Duration: timeNow().Sub(backup.Status.StartTime.Time).Round(time.Second)}
+		}
+		if err := controllers.DeleteLROOperation(ctx, r.DatabaseClientFactory, r, id, backup.Namespace, backup.Spec.Instance); err != nil {
+			log.Error(err, "failed to delete the restore validation LRO", "id", id)
+		}
+		return ctrl.Result{}, r.BackupCtrl.UpdateStatus(backup)
+
+	default:
+		log.Info("no action needed", "state", state)
+		return ctrl.Result{}, nil
+	}
+}
+
+// setRestoreValidationResult records a completed restore validation, the
+// backup is ready only if the database is restorable and fully recoverable.
+func (r *BackupReconciler) setRestoreValidationResult(backup *v1alpha1.Backup, resp *dbdpb.RestoreValidationReportResponse) {
+	backup.Status.RestoreValidation = &v1alpha1.RestoreValidationStatus{
+		Restorable:         resp.GetRestorable(),
+		NeedBackupFiles:    resp.GetNeedBackupFiles(),
+		UnrecoverableFiles: resp.GetUnrecoverableFiles(),
+		FailedCommands:     resp.GetFailedCommands(),
+		ReportGcsPath:      resp.GetGcsPath(),
+	}
+	if len(resp.GetNeedBackupFiles()) > 0 {
+		r.Recorder.Eventf(backup, corev1.EventTypeWarning, "BackupNeeded", "Datafiles need a backup: %s", strings.Join(resp.GetNeedBackupFiles(), ", "))
+	}
+
+	var problems []string
+	if !resp.GetRestorable() {
+		problems = append(problems, "the database cannot be restored from its backups")
+	}
+	if len(resp.GetUnrecoverableFiles()) > 0 {
+		problems = append(problems, fmt.Sprintf("unrecoverable datafiles: %s", strings.Join(resp.GetUnrecoverableFiles(), ", ")))
+	}
+	if len(resp.GetFailedCommands()) > 0 {
+		problems = append(problems, fmt.Sprintf("failed RMAN commands: %s", strings.Join(resp.GetFailedCommands(), ", ")))
+	}
+	if len(problems) > 0 {
+		r.setRestoreValidationFailed(backup, fmt.Sprintf("Restore validation failed: %s, see %s", strings.Join(problems, msgSep), resp.GetGcsPath()))
+		return
+	}
+
+	msg := fmt.Sprintf("Validated the restore of the database, see %s", resp.GetGcsPath())
+	r.Recorder.Event(backup, corev1.EventTypeNormal, "RestoreValidated", msg)
+	backup.Status.Phase = commonv1alpha1.BackupSucceeded
+	backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, metav1.ConditionTrue, k8s.BackupReady, msg)
+}
+
+func (r *BackupReconciler) setRestoreValidationFailed(backup *v1alpha1.Backup, msg string) {
+	r.Recorder.Event(backup, corev1.EventTypeWarning, "RestoreValidationFailed", msg)
+	backup.Status.Phase = commonv1alpha1.BackupFailed
+	backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, metav1.ConditionFalse, k8s.BackupFailed, msg)
+}
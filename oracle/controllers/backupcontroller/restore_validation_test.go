@@ -0,0 +1,263 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backupcontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/types/known/anypb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestReconcileValidateRestore(t *testing.T) {
+	validateSpec := v1alpha1.BackupSpec{
+		BackupSpec: commonv1alpha1.BackupSpec{
+			Instance: testInstanceName,
+			Type:     commonv1alpha1.BackupTypePhysical,
+		},
+		Mode:         v1alpha1.ValidateRestore,
+		CheckLogical: true,
+		GcsPath:      testGCSPath,
+	}
+	inProgress := v1alpha1.BackupStatus{
+		BackupStatus: commonv1alpha1.BackupStatus{
+			Phase: commonv1alpha1.BackupInProgress,
+			Conditions: []metav1.Condition{{
+				Type:   k8s.Ready,
+				Status: metav1.ConditionFalse,
+				Reason: k8s.BackupInProgress,
+			}},
+		},
+	}
+	reportPath := testGCSPath + "/" + restoreValidationReportName
+
+	testCases := []struct {
+		name                string
+		backupSpec          v1alpha1.BackupSpec
+		backupStatus        v1alpha1.BackupStatus
+		operationStatus     testhelpers.FakeOperationStatus
+		operationResp       *dbdpb.RestoreValidationReportResponse
+		wantStartCalledCnt  int
+		wantDeleteCalledCnt int
+		wantNewStatus       *v1alpha1.BackupStatus
+		wantReconcileResult ctrl.Result
+	}{
+		{
+			name: "Unsupported spec.type",
+			backupSpec: v1alpha1.BackupSpec{
+				BackupSpec: commonv1alpha1.BackupSpec{
+					Instance: testInstanceName,
+					Type:     commonv1alpha1.BackupTypeSnapshot,
+				},
+				Mode: v1alpha1.ValidateRestore,
+			},
+			wantNewStatus: &v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupFailed,
+					Conditions: []metav1.Condition{{
+						Type:   k8s.Ready,
+						Status: metav1.ConditionFalse,
+						Reason: k8s.NotSupported,
+					}},
+				},
+			},
+		},
+		{
+			name:               "Start the validation",
+			backupSpec:         validateSpec,
+			wantStartCalledCnt: 1,
+			wantNewStatus:      &inProgress,
+			wantReconcileResult: ctrl.Result{
+				RequeueAfter: statusCheckInterval,
+			},
+		},
+		{
+			name:            "Validation running",
+			backupSpec:      validateSpec,
+			backupStatus:    inProgress,
+			operationStatus: testhelpers.StatusRunning,
+			wantReconcileResult: ctrl.Result{
+				RequeueAfter: statusCheckInterval,
+			},
+		},
+		{
+			name:            "Validation interrupted",
+			backupSpec:      validateSpec,
+			backupStatus:    inProgress,
+			operationStatus: testhelpers.StatusNotFound,
+			wantNewStatus: &v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupFailed,
+					Conditions: []metav1.Condition{{
+						Type:   k8s.Ready,
+						Status: metav1.ConditionFalse,
+						Reason: k8s.BackupFailed,
+					}},
+				},
+			},
+		},
+		{
+			name:                "Validation failed",
+			backupSpec:          validateSpec,
+			backupStatus:        inProgress,
+			operationStatus:     testhelpers.StatusDoneWithError,
+			wantDeleteCalledCnt: 1,
+			wantNewStatus: &v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupFailed,
+					Conditions: []metav1.Condition{{
+						Type:   k8s.Ready,
+						Status: metav1.ConditionFalse,
+						Reason: k8s.BackupFailed,
+					}},
+				},
+			},
+		},
+		{
+			name:            "Database restorable",
+			backupSpec:      validateSpec,
+			backupStatus:    inProgress,
+			operationStatus: testhelpers.StatusDone,
+			operationResp: &dbdpb.RestoreValidationReportResponse{
+				Restorable:      true,
+				NeedBackupFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf"},
+				GcsPath:         reportPath,
+			},
+			wantDeleteCalledCnt: 1,
+			wantNewStatus: &v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupSucceeded,
+					Conditions: []metav1.Condition{{
+						Type:   k8s.Ready,
+						Status: metav1.ConditionTrue,
+						Reason: k8s.BackupReady,
+					}},
+				},
+				RestoreValidation: &v1alpha1.RestoreValidationStatus{
+					Restorable:      true,
+					NeedBackupFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf"},
+					ReportGcsPath:   reportPath,
+				},
+			},
+		},
+		{
+			name:            "Unrecoverable datafiles",
+			backupSpec:      validateSpec,
+			backupStatus:    inProgress,
+			operationStatus: testhelpers.StatusDone,
+			operationResp: &dbdpb.RestoreValidationReportResponse{
+				Restorable:         true,
+				UnrecoverableFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf"},
+				GcsPath:            reportPath,
+			},
+			wantDeleteCalledCnt: 1,
+			wantNewStatus: &v1alpha1.BackupStatus{
+				BackupStatus: commonv1alpha1.BackupStatus{
+					Phase: commonv1alpha1.BackupFailed,
+					Conditions: []metav1.Condition{{
+						Type:   k8s.Ready,
+						Status: metav1.ConditionFalse,
+						Reason: k8s.BackupFailed,
+					}},
+				},
+				RestoreValidation: &v1alpha1.RestoreValidationStatus{
+					Restorable:         true,
+					UnrecoverableFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf"},
+					ReportGcsPath:      reportPath,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reconciler, _, backupCtrl, dbClient := newTestBackupReconciler()
+			var gotNewStatus *v1alpha1.BackupStatus
+			backupCtrl.updateStatus = func(obj client.Object) error {
+				if b, ok := obj.(*v1alpha1.Backup); ok {
+					gotNewStatus = b.Status.DeepCopy()
+				}
+				return nil
+			}
+			backupCtrl.getInstance = func(name, namespace string) (*v1alpha1.Instance, error) {
+				return &v1alpha1.Instance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      testInstanceName,
+						Namespace: testNamespace,
+					},
+					Status: v1alpha1.InstanceStatus{
+						InstanceStatus: commonv1alpha1.InstanceStatus{
+							Conditions: []metav1.Condition{{Type: k8s.Ready, Status: metav1.ConditionTrue}},
+						},
+					},
+				}, nil
+			}
+			dbClient.SetNextGetOperationStatus(tc.operationStatus)
+			if tc.operationResp != nil {
+				resp, err := anypb.New(tc.operationResp)
+				if err != nil {
+					t.Fatalf("anypb.New failed: %v", err)
+				}
+				dbClient.SetMethodToResp("GetOperation", &lropb.Operation{
+					Done:   true,
+					Result: &lropb.Operation_Response{Response: resp},
+				})
+			}
+
+			backup := newBackupWithSpec(tc.backupSpec)
+			backup.Status = *tc.backupStatus.DeepCopy()
+			gotReconcileResult, err := reconciler.reconcileValidateRestore(context.Background(), backup, reconciler.Log)
+			if err != nil {
+				t.Fatalf("reconciler.reconcileValidateRestore failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantReconcileResult, gotReconcileResult); diff != "" {
+				t.Errorf("reconciler.reconcileValidateRestore got unexpected reconcile result: -want +got %v", diff)
+			}
+
+			statusCmpOptions := []cmp.Option{
+				cmpopts.IgnoreFields(metav1.Condition{}, "Message"),
+				cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"),
+				cmpopts.IgnoreFields(v1alpha1.BackupStatus{}, "StartTime", "Duration"),
+			}
+			if diff := cmp.Diff(tc.wantNewStatus, gotNewStatus, statusCmpOptions...); diff != "" {
+				t.Errorf("reconciler.reconcileValidateRestore got unexpected backup status: -want +got %v", diff)
+			}
+			if got := dbClient.RestoreValidationReportAsyncCalledCnt(); got != tc.wantStartCalledCnt {
+				t.Errorf("RestoreValidationReportAsync called %d times, want %d", got, tc.wantStartCalledCnt)
+			}
+			if tc.wantStartCalledCnt > 0 {
+				got := dbClient.GotRestoreValidationReportAsyncRequest
+				if got.GetSyncRequest().GetGcsPath() != reportPath || !got.GetSyncRequest().GetCheckLogical() {
+					t.Errorf("RestoreValidationReportAsync got request %v, want gcs path %q with check logical", got, reportPath)
+				}
+			}
+			if got := dbClient.DeleteOperationCalledCnt(); got != tc.wantDeleteCalledCnt {
+				t.Errorf("DeleteOperation called %d times, want %d", got, tc.wantDeleteCalledCnt)
+			}
+		})
+	}
+}
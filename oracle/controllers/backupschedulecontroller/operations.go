@@ -25,6 +25,8 @@ import (
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
 )
 
 type RealBackupScheduleControl struct {
@@ -57,7 +59,7 @@ func (r *RealBackupScheduleControl) GetBackupBytes(schedule commonv1alpha1.Backu
 		return nil, err
 	}
 
-	metadataBytes, err := json.Marshal(metav1.ObjectMeta{Labels: schedule.(*v1alpha1.BackupSchedule).Spec.BackupLabels})
+	metadataBytes, err := json.Marshal(metav1.ObjectMeta{Labels: backupLabels(schedule.(*v1alpha1.BackupSchedule))})
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +77,23 @@ func (r *RealBackupScheduleControl) GetBackupBytes(schedule commonv1alpha1.Backu
 	return json.Marshal(backupMap)
 }
 
+// backupLabels returns the labels of the scheduled backups, the backups of
+// an incremental chain are labeled with the schedule name.
+func backupLabels(schedule *v1alpha1.BackupSchedule) map[string]string {
+	if schedule.Spec.IncrementalChain == nil {
+		return schedule.Spec.BackupLabels
+	}
+	labels := map[string]string{controllers.IncrementalChainLabel: schedule.Name}
+	for k, v := range schedule.Spec.BackupLabels {
+		labels[k] = v
+	}
+	return labels
+}
+
 type RealBackupControl struct {
 	Client client.Client
+	// GCS measures the backups in GCS, it defaults to the GCS client.
+	GCS util.GCSUtil
 }
 
 func (r *RealBackupControl) List(cronAnythingName string) ([]commonv1alpha1.Backup, error) {
@@ -98,6 +115,57 @@ func (r *RealBackupControl) List(cronAnythingName string) ([]commonv1alpha1.Back
 	return backups, nil
 }
 
+// Delete deletes a backup unless a backup of its incremental chain still
+// needs it for a restore, the backup is pruned along with the last one.
 func (r *RealBackupControl) Delete(backup commonv1alpha1.Backup) error {
-	return r.Client.Delete(context.TODO(), backup.(*v1alpha1.Backup))
+	b := backup.(*v1alpha1.Backup)
+	if chain := b.Labels[controllers.IncrementalChainLabel]; chain != "" {
+		var backups v1alpha1.BackupList
+		if err := r.Client.List(context.TODO(), &backups, client.InNamespace(b.Namespace), client.MatchingLabels{controllers.IncrementalChainLabel: chain}); err != nil {
+			return err
+		}
+		if neededByChain(b.Name, backups.Items) {
+			return nil
+		}
+	}
+	return r.Client.Delete(context.TODO(), b)
+}
+
+// Size returns the size of the backup files in GCS, backups kept elsewhere
+// count as empty. The size is the one the Backup controller recorded, backups
+// it didn't record one for yet are measured without recording it.
+func (r *RealBackupControl) Size(backup commonv1alpha1.Backup) (int64, error) {
+	b := backup.(*v1alpha1.Backup)
+	if b.Status.Size != nil {
+		return b.Status.Size.Value(), nil
+	}
+	gcsPath := controllers.GetBackupGcsPath(b)
+	if b.Spec.Type != commonv1alpha1.BackupTypePhysical || gcsPath == "" {
+		return 0, nil
+	}
+	gcs := r.GCS
+	if gcs == nil {
+		gcs = &util.GCSUtilImpl{}
+	}
+	return gcs.Size(context.TODO(), gcsPath)
+}
+
+// neededByChain returns true if a backup, which isn't being deleted, depends
+// on the named backup in its incremental chain.
+func neededByChain(name string, backups []v1alpha1.Backup) bool {
+	for _, b := range backups {
+		chain := b.Status.IncrementalChain
+		if b.Name == name || !b.DeletionTimestamp.IsZero() || chain == nil {
+			continue
+		}
+		if chain.Anchor == name {
+			return true
+		}
+		for _, p := range chain.Parents {
+			if p == name {
+				return true
+			}
+		}
+	}
+	return false
 }
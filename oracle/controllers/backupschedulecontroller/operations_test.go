@@ -0,0 +1,114 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backupschedulecontroller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+)
+
+// fakeGCSUtil measures the backups from a fixed set of sizes.
+type fakeGCSUtil struct {
+	sizes    map[string]int64
+	measured []string
+}
+
+func (g *fakeGCSUtil) Download(ctx context.Context, gcsPath string) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) Delete(ctx context.Context, gcsPath string) error {
+	return errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) Size(ctx context.Context, gcsPath string) (int64, error) {
+	g.measured = append(g.measured, gcsPath)
+	return g.sizes[gcsPath], nil
+}
+
+func (g *fakeGCSUtil) UploadFile(ctx context.Context, gcsPath, filePath, contentType string) error {
+	return errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) SplitURI(url string) (string, string, error) {
+	return "", "", errors.New("not implemented")
+}
+
+func newBackup(name string, backupType commonv1alpha1.BackupType, size *resource.Quantity) *v1alpha1.Backup {
+	b := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db"},
+		Spec: v1alpha1.BackupSpec{
+			BackupSpec: commonv1alpha1.BackupSpec{Instance: "mydb", Type: backupType},
+			GcsDir:     "gs://bucket/backups",
+		},
+	}
+	b.Status.Size = size
+	return b
+}
+
+func TestRealBackupControlSize(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	recorded := newBackup("b1", commonv1alpha1.BackupTypePhysical, resource.NewQuantity(1024, resource.BinarySI))
+	unrecorded := newBackup("b10", commonv1alpha1.BackupTypePhysical, nil)
+	snapshot := newBackup("b11", commonv1alpha1.BackupTypeSnapshot, nil)
+	gcs := &fakeGCSUtil{sizes: map[string]int64{
+		"gs://bucket/backups/b1":  4096,
+		"gs://bucket/backups/b10": 2048,
+	}}
+	c := &RealBackupControl{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(recorded, unrecorded, snapshot).Build(),
+		GCS:    gcs,
+	}
+
+	tests := []struct {
+		backup *v1alpha1.Backup
+		want   int64
+	}{
+		{backup: recorded, want: 1024},
+		{backup: unrecorded, want: 2048},
+		{backup: snapshot, want: 0},
+	}
+	for _, tc := range tests {
+		got, err := c.Size(tc.backup)
+		if err != nil || got != tc.want {
+			t.Errorf("Size(%q)=(%d, %v); wanted (%d, nil)", tc.backup.Name, got, err, tc.want)
+		}
+	}
+	if len(gcs.measured) != 1 || gcs.measured[0] != "gs://bucket/backups/b10" {
+		t.Errorf("Size measured %v in GCS; wanted only the backup without a recorded size", gcs.measured)
+	}
+
+	var stored v1alpha1.Backup
+	if err := c.Client.Get(context.Background(), client.ObjectKeyFromObject(unrecorded), &stored); err != nil {
+		t.Fatalf("failed to get backup %q: %v", unrecorded.Name, err)
+	}
+	if stored.Status.Size != nil || stored.ResourceVersion != unrecorded.ResourceVersion {
+		t.Errorf("Size updated backup %q to size %v; wanted it left to the Backup controller", unrecorded.Name, stored.Status.Size)
+	}
+}
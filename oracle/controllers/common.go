@@ -23,6 +23,7 @@ import (
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -50,8 +51,20 @@ const (
 	SCNAnnotation               = "scn"
 	TimestampAnnotation         = "timestamp"
 	DatabaseImageAnnotation     = "database-image"
+	DatabaseVersionAnnotation   = "database-version"
 	ParameterUpdateStateMachine = "ParameterUpdateStateMachine"
 	DatabaseContainerName       = "oracledb"
+
+	// WalletPasswordVersionAnnotation is the GSM secret version holding the
+	// TDE keystore password of the instance when the backup was taken.
+	WalletPasswordVersionAnnotation = "wallet-password-version"
+
+	// ClonedFromAnnotation names the Instance an Instance was cloned from.
+	ClonedFromAnnotation = "cloned-from"
+
+	// IncrementalChainLabel names the BackupSchedule whose incremental chain
+	// a scheduled backup belongs to.
+	IncrementalChainLabel = "incremental-chain"
 )
 
 var (
@@ -61,6 +74,15 @@ var (
 	AgentSvcName = "%s-agent-svc"
 	// DbdaemonSvcName is a string template for dbdaemon service names.
 	DbdaemonSvcName = "%s-dbdaemon-svc"
+	// DBDaemonTLSSecretName is a string template for the Secrets holding the
+	// mutual TLS certificates of the database daemons.
+	DBDaemonTLSSecretName = "%s-dbdaemon-tls"
+	// DBDaemonCASecretName is a string template for the Secrets holding the
+	// CA the database daemon certificates are issued by.
+	DBDaemonCASecretName = "%s-dbdaemon-ca"
+	// TCPSCertConfigMapName is a string template for the ConfigMaps holding
+	// the certificate of a generated TCPS listener wallet.
+	TCPSCertConfigMapName = "%s-tcps-cert"
 	// SvcEndpoint is a string template for service endpoints.
 	SvcEndpoint     = "%s.%s" // SvcName.namespaceName
 	sourceCidrRange = []string{"0.0.0.0/0"}
@@ -76,6 +98,13 @@ var (
 	DatabaseTaskType = "oracle-db"
 	// MonitorTaskType is the value of the 'task-type' label assigned to the monitoring deployment.
 	MonitorTaskType = "monitor"
+	// ConnectionPoolName is a string template for the names of the connection
+	// pooler Deployments and ConfigMaps.
+	ConnectionPoolName = "%s-pool"
+	// ConnectionPoolSvcName is a string template for connection pooler service names.
+	ConnectionPoolSvcName = "%s-pool-svc"
+	// ConnectionPoolTaskType is the value of the 'task-type' label assigned to the connection pooler pods.
+	ConnectionPoolTaskType = "connection-pool"
 	// DefaultDiskSpecs is the default DiskSpec settings.
 	DefaultDiskSpecs = map[string]commonv1alpha1.DiskSpec{
 		"DataDisk": {
@@ -113,6 +142,9 @@ type StsParams struct {
 	Config         *v1alpha1.Config
 	Log            logr.Logger
 	Services       []commonv1alpha1.Service
+	// DBDaemonTLSSecret is the Secret the database daemon certificates are
+	// mounted from, the daemons serve plaintext gRPC if empty.
+	DBDaemonTLSSecret string
 }
 
 type ConnCloseFunc func()
@@ -152,7 +184,21 @@ func (d *GRPCDatabaseClientFactory) New(ctx context.Context, r client.Reader, na
 		return nil, nil, err
 	}
 
-	conn, err := common.DatabaseDaemonDialService(ctx, fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, consts.DefaultDBDaemonPort), grpc.WithBlock())
+	// The database daemon of an instance with a TLS Secret requires mutual
+	// TLS.
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	tlsSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: fmt.Sprintf(DBDaemonTLSSecretName, instName), Namespace: namespace}, tlsSecret); err == nil {
+		creds, err := DBDaemonClientCredentials(tlsSecret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load the database daemon TLS certificates: %v", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	} else if !apierrors.IsNotFound(err) {
+		return nil, nil, err
+	}
+
+	conn, err := common.DatabaseDaemonDialService(ctx, fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, consts.DefaultDBDaemonPort), opts...)
 	if err != nil {
 		return nil, func() error { return nil }, err
 	}
@@ -18,10 +18,11 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -63,6 +64,39 @@ var overrideParamTypeStatic = map[string]bool{
 	"sga_target": true,
 }
 
+// ReservedParameters holds the list of parameters that aren't allowed for modification.
+var ReservedParameters = map[string]bool{
+	"audit_file_dest":           true,
+	"audit_trail":               true,
+	"compatible":                true,
+	"control_files":             true,
+	"db_block_size":             true,
+	"db_recovery_file_dest":     true,
+	"diagnostic_dest":           true,
+	"dispatchers":               true,
+	"enable_pluggable_database": true,
+	"filesystemio_options":      true,
+	"local_listener":            true,
+	"remote_login_passwordfile": true,
+	"undo_tablespace":           true,
+	"log_archive_dest_1":        true,
+	"log_archive_dest_state_1":  true,
+	"log_archive_format":        true,
+	"standby_file_management":   true,
+}
+
+// instanceParameters identify the database or its files, they are left
+// out of exported parameters applied to other instances.
+var instanceParameters = map[string]bool{
+	"db_create_file_dest": true,
+	"db_domain":           true,
+	"db_name":             true,
+	"db_unique_name":      true,
+	"instance_name":       true,
+	"service_names":       true,
+	"spfile":              true,
+}
+
 // GetLROOperation returns LRO operation for the specified namespace instance and operation id.
 func GetLROOperation(ctx context.Context, dbClientFactory DatabaseClientFactory, r client.Reader, id, namespace, instName string) (*lropb.Operation, error) {
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
@@ -293,7 +327,6 @@ func CreateDatabase(ctx context.Context, r client.Reader, dbClientFactory Databa
 	pdbDir := filepath.Join(cdbDir, strings.ToUpper(req.Name))
 	toCreate := []string{
 		fmt.Sprintf("%s/data", pdbDir),
-		fmt.Sprintf("%s/%s", pdbDir, consts.DpdumpDir.Linux),
 		fmt.Sprintf("%s/rman", consts.OracleBase),
 	}
 
@@ -338,12 +371,7 @@ func CreateDatabase(ctx context.Context, r client.Reader, dbClientFactory Databa
 	klog.InfoS("config_agent_helpers/CreateDatabase: created PDB_ADMIN and PDB Loader users")
 
 	// Separate out the directory treatment for the ease of troubleshooting.
-	_, err = dbClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{
-		sql.QuerySetSessionContainer(p.pluggableDatabaseName),
-		sql.QueryCreateDir(consts.DpdumpDir.Oracle, filepath.Join(p.pathPrefix, consts.DpdumpDir.Linux)),
-		sql.QueryGrantPrivileges(fmt.Sprintf("read,write on directory %s", consts.DpdumpDir.Oracle), consts.PDBLoaderUser),
-	}, Suppress: false})
-	if err != nil {
+	if _, err := dbClient.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: p.pluggableDatabaseName}); err != nil {
 		klog.ErrorS(err, "CreateDatabase: failed to create a Data Pump directory", "datapumpDir", consts.DpdumpDir)
 	}
 	klog.InfoS("config_agent_helpers/CreateDatabase: DONE", "pdb", p.pluggableDatabaseName)
@@ -462,27 +490,49 @@ type UpdateUsersRequest struct {
 	UserSpecs []*User
 }
 
+type UpdateUsersResponseFailure struct {
+	UserName string
+	Error    string
+}
+
+// UpdateUsersResponse reports the users UpdateUsers changed and the ones it
+// failed to change. Failed users still differ from their spec, so the next
+// UpdateUsers call retries only them.
+type UpdateUsersResponse struct {
+	Succeeded []string
+	Failed    []*UpdateUsersResponseFailure
+}
+
 // UpdateUsers update/create users as requested.
-func UpdateUsers(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req UpdateUsersRequest) error {
+// A failure to change a user doesn't stop the others from being changed, it
+// is reported in the response instead.
+func UpdateUsers(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req UpdateUsersRequest) (*UpdateUsersResponse, error) {
 	klog.InfoS("config_agent_helpers/UpdateUsers", "namespace", namespace, "instName", instName, "pdbName", req.PdbName)
 
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return fmt.Errorf("config_agent_helpers/UpdateUsers: failed to create database daemon client: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/UpdateUsers: failed to create database daemon client: %v", err)
 	}
 	defer closeConn()
 	us := newUsers(req.PdbName, req.UserSpecs)
 	toCreate, toUpdate, _, toUpdatePwd, err := us.diff(ctx, dbClient)
 	if err != nil {
-		return fmt.Errorf("config_agent_helpers/UpdateUsers: failed to get difference between env and spec for users: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/UpdateUsers: failed to get difference between env and spec for users: %v", err)
+	}
+	// userErrs records the first error of each user changed, nil on success.
+	userErrs := make(map[string]error)
+	record := func(u *user, err error) {
+		if userErrs[u.userName] == nil {
+			userErrs[u.userName] = err
+		}
 	}
-	foundErr := false
 	for _, u := range toCreate {
 		klog.InfoS("config_agent_helpers/UpdateUsers", "creating user", u.userName)
-		if err := u.create(ctx, dbClient); err != nil {
+		err := u.create(ctx, dbClient)
+		if err != nil {
 			klog.ErrorS(err, "failed to create user")
-			foundErr = true
 		}
+		record(u, err)
 	}
 
 	for _, u := range toUpdate {
@@ -491,25 +541,37 @@ func UpdateUsers(ctx context.Context, r client.Reader, dbClientFactory DatabaseC
 		// Grant dba role to a user will automatically give unlimited tablespace privilege.
 		// Revoke dba role will automatically revoke  unlimited tablespace privilege.
 		// thus user update will first update role and then update sys privi.
-		if err := u.update(ctx, dbClient, us.databaseRoles); err != nil {
+		err := u.update(ctx, dbClient, us.databaseRoles)
+		if err != nil {
 			klog.ErrorS(err, "failed to update user")
-			foundErr = true
 		}
+		record(u, err)
 	}
 
 	for _, u := range toUpdatePwd {
 		klog.InfoS("config_agent_helpers/UpdateUsers", "updating user pwd", u.userName)
-		if err := u.updatePassword(ctx, dbClient); err != nil {
+		err := u.updatePassword(ctx, dbClient)
+		if err != nil {
 			klog.ErrorS(err, "failed to update user password")
-			foundErr = true
 		}
+		record(u, err)
 	}
 
-	if foundErr {
-		return errors.New("failed to update users")
+	resp := &UpdateUsersResponse{}
+	var names []string
+	for name := range userErrs {
+		names = append(names, name)
 	}
-	klog.InfoS("config_agent_helpers/UpdateUsers: DONE")
-	return nil
+	sort.Strings(names)
+	for _, name := range names {
+		if err := userErrs[name]; err != nil {
+			resp.Failed = append(resp.Failed, &UpdateUsersResponseFailure{UserName: name, Error: err.Error()})
+		} else {
+			resp.Succeeded = append(resp.Succeeded, name)
+		}
+	}
+	klog.InfoS("config_agent_helpers/UpdateUsers: DONE", "succeeded", resp.Succeeded, "failed", len(resp.Failed))
+	return resp, nil
 }
 
 // SetParameter sets database parameter as requested.
@@ -629,6 +691,16 @@ type User struct {
 	// only being used for plaintext password scenario.
 	// GSM doesn't use this field.
 	LastPassword string
+	// Quotas are the quotas of the user on tablespaces, the quotas on the
+	// other tablespaces are left unchanged.
+	Quotas []UserQuota
+}
+
+// UserQuota is the quota of a user on a tablespace, MaxBytes is -1 for an
+// unlimited quota.
+type UserQuota struct {
+	Tablespace string
+	MaxBytes   int64
 }
 
 type GsmSecretReference struct {
@@ -649,7 +721,6 @@ type pdb struct {
 	hostName                  string
 	listenerDir               string
 	listeners                 map[string]*consts.Listener
-	pathPrefix                string
 	pluggableAdminPasswd      string
 	pluggableDatabaseName     string
 	skipUserCheck             bool
@@ -670,7 +741,6 @@ func buildPDB(cdbName, pdbName, pdbAdminPass, version string, listeners map[stri
 		dataFilesDir:              fmt.Sprintf(consts.PDBDataDir, consts.DataMount, cdbName, pdbName),
 		defaultTablespace:         fmt.Sprintf("%s_USERS", pdbName),
 		defaultTablespaceDatafile: fmt.Sprintf(consts.PDBDataDir+"/%s_users.dbf", consts.DataMount, cdbName, pdbName, strings.ToLower(pdbName)),
-		pathPrefix:                fmt.Sprintf(consts.PDBPathPrefix, consts.DataMount, cdbName, pdbName),
 		fileConvertFrom:           fmt.Sprintf(consts.PDBSeedDir, consts.DataMount, cdbName),
 		fileConvertTo:             fmt.Sprintf(consts.PDBDataDir, consts.DataMount, cdbName, pdbName),
 		listenerDir:               fmt.Sprintf(consts.ListenerDir, consts.DataMount),
@@ -765,6 +835,25 @@ var AccessSecretVersionFunc = func(ctx context.Context, name string) (string, er
 	return string(result.Payload.Data[:]), nil
 }
 
+// AddSecretVersionFunc adds a version holding payload to the given secret,
+// e.g. projects/p/secrets/s, and returns the name of the new version.
+var AddSecretVersionFunc = func(ctx context.Context, secret, payload string) (string, error) {
+	client, closeConn, err := newGsmClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/AddSecretVersionFunc: failed to create secretmanager client: %v", err)
+	}
+	defer closeConn()
+
+	result, err := client.AddSecretVersion(ctx, &secretmanagerpb.AddSecretVersionRequest{
+		Parent:  secret,
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(payload)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/AddSecretVersionFunc: failed to add secret version: %v", err)
+	}
+	return result.Name, nil
+}
+
 type BootstrapDatabaseRequest struct {
 	CdbName      string
 	Version      string
@@ -803,6 +892,20 @@ func BootstrapDatabase(ctx context.Context, r client.Reader, dbClientFactory Dat
 		return &lropb.Operation{Done: true}, nil
 	}
 
+	oratab, err := dbClient.ValidateOratab(ctx, &dbdpb.ValidateOratabRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/BootstrapDatabase: failed to validate oratab: %v", err)
+	}
+	if len(oratab.GetIssues()) > 0 {
+		return nil, fmt.Errorf("config_agent_helpers/BootstrapDatabase: oratab is malformed: %s", strings.Join(oratab.GetIssues(), "; "))
+	}
+	if len(oratab.GetWarnings()) > 0 {
+		klog.InfoS("config_agent_helpers/BootstrapDatabase: oratab warnings", "warnings", oratab.GetWarnings())
+	}
+	if oratab.GetDatabaseTypeMissing() {
+		klog.InfoS("config_agent_helpers/BootstrapDatabase: oratab has no DATABASETYPE comment, assuming the default database type")
+	}
+
 	switch req.Mode {
 	case BootstrapDatabaseRequest_ProvisionUnseeded:
 		task := provision.NewBootstrapDatabaseTaskForUnseeded(req.CdbName, req.DbUniqueName, req.Dbdomain, dbClient)
@@ -957,7 +1060,19 @@ func VerifyPhysicalBackup(ctx context.Context, r client.Reader, dbClientFactory
 		GcsPath:               req.GcsPath,
 		AccessPermissionCheck: true,
 	}); err != nil {
-		return &VerifyPhysicalBackupResponse{ErrMsgs: []string{err.Error()}}, nil
+		errMsgs := []string{err.Error()}
+		// Point out the missing permissions, the download error alone doesn't
+		// tell a missing backup from a misconfigured service account.
+		permResp, permErr := dbClient.CheckStoragePermissions(ctx, &dbdpb.CheckStoragePermissionsRequest{GcsPath: req.GcsPath, ReadOnly: true})
+		if permErr != nil {
+			klog.ErrorS(permErr, "config_agent_helpers/VerifyPhysicalBackup: failed to check storage permissions", "gcsPath", req.GcsPath)
+		}
+		for _, p := range permResp.GetPermissions() {
+			if !p.GetGranted() && !p.GetSkipped() {
+				errMsgs = append(errMsgs, fmt.Sprintf("missing %s permission on %s: %s", p.GetIamPermission(), req.GcsPath, p.GetError()))
+			}
+		}
+		return &VerifyPhysicalBackupResponse{ErrMsgs: errMsgs}, nil
 	}
 	return &VerifyPhysicalBackupResponse{}, nil
 }
@@ -977,6 +1092,14 @@ type PhysicalBackupRequest struct {
 	GcsPath     string
 	LroInput    *LROInput
 	BackupTag   string
+	// IgnoreCPULimit turns off capping the DOP at the CPU limit of the
+	// database container.
+	IgnoreCPULimit bool
+	// Cumulative takes a cumulative incremental backup at a Level above 0.
+	Cumulative bool
+	// Stream streams the datafile backup pieces of the Dop channels to
+	// GcsPath without staging them on local disk.
+	Stream bool
 }
 
 type PhysicalBackupRequest_Type int32
@@ -989,6 +1112,17 @@ const (
 	PhysicalBackupRequest_DATAFILE     PhysicalBackupRequest_Type = 4
 )
 
+// pdbGranularity returns the RMAN backup spec of a PDB level backup, e.g.
+// pluggable database PDB1, PDB2. Unquoted PDB names are case-insensitive in
+// RMAN, they are uppercased to match the names the database reports.
+func pdbGranularity(pdbs []string) string {
+	names := make([]string, 0, len(pdbs))
+	for _, pdb := range pdbs {
+		names = append(names, strings.ToUpper(pdb))
+	}
+	return "pluggable database " + strings.Join(names, ", ")
+}
+
 // PhysicalBackup starts an RMAN backup and stores it in the GCS bucket provided.
 func PhysicalBackup(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req PhysicalBackupRequest) (*lropb.Operation, error) {
 	klog.InfoS("config_agent_helpers/PhysicalBackup", "namespace", namespace, "instName", instName, "gcsPath", req.GcsPath, "localPath", req.LocalPath)
@@ -997,19 +1131,10 @@ func PhysicalBackup(ctx context.Context, r client.Reader, dbClientFactory Databa
 	case PhysicalBackupRequest_INSTANCE:
 		granularity = "database"
 	case PhysicalBackupRequest_DATABASE:
-		if req.BackupItems == nil {
+		if len(req.BackupItems) == 0 {
 			return &lropb.Operation{}, fmt.Errorf("config_agent_helpers/PhysicalBackup: failed a pre-flight check: a PDB backup is requested, but no PDB name(s) given")
 		}
-
-		granularity = "pluggable database "
-		for i, pdb := range req.BackupItems {
-			if i == 0 {
-				granularity += pdb
-			} else {
-				granularity += ", "
-				granularity += pdb
-			}
-		}
+		granularity = pdbGranularity(req.BackupItems)
 	default:
 		return &lropb.Operation{}, fmt.Errorf("config_agent_helpers/PhysicalBackup: unsupported in this release sub backup type of %v", req.BackupSubType)
 	}
@@ -1024,19 +1149,22 @@ func PhysicalBackup(ctx context.Context, r client.Reader, dbClientFactory Databa
 
 	sectionSize := resource.NewQuantity(int64(req.SectionSize), resource.DecimalSI)
 	return backup.PhysicalBackup(ctx, &backup.Params{
-		Client:       dbClient,
-		Granularity:  granularity,
-		Backupset:    req.Backupset,
-		CheckLogical: req.CheckLogical,
-		Compressed:   req.Compressed,
-		DOP:          req.Dop,
-		Level:        req.Level,
-		Filesperset:  req.Filesperset,
-		SectionSize:  *sectionSize,
-		LocalPath:    req.LocalPath,
-		GCSPath:      req.GcsPath,
-		BackupTag:    req.BackupTag,
-		OperationID:  req.LroInput.OperationId,
+		Client:         dbClient,
+		Granularity:    granularity,
+		Backupset:      req.Backupset,
+		CheckLogical:   req.CheckLogical,
+		Compressed:     req.Compressed,
+		DOP:            req.Dop,
+		Level:          req.Level,
+		Filesperset:    req.Filesperset,
+		SectionSize:    *sectionSize,
+		LocalPath:      req.LocalPath,
+		GCSPath:        req.GcsPath,
+		BackupTag:      req.BackupTag,
+		OperationID:    req.LroInput.OperationId,
+		IgnoreCPULimit: req.IgnoreCPULimit,
+		Cumulative:     req.Cumulative,
+		Stream:         req.Stream,
 	})
 }
 
@@ -1051,10 +1179,17 @@ type PhysicalRestoreRequest struct {
 	LogGcsPath        string
 	Incarnation       string
 	BackupIncarnation string
+	DBID              string
 	StartTime         *timestamppb.Timestamp
 	EndTime           *timestamppb.Timestamp
 	StartScn          int64
 	EndScn            int64
+	// Stream feeds the streamed datafile backup pieces of the backup to
+	// RMAN from GcsPath without staging them on local disk.
+	Stream bool
+	// ChainGcsPaths are the GCS paths of the backups, level 0 backup first,
+	// the incremental backup in GcsPath builds on.
+	ChainGcsPaths []string
 }
 
 // PhysicalRestore restores an RMAN backup (downloaded from GCS).
@@ -1078,10 +1213,13 @@ func PhysicalRestore(ctx context.Context, r client.Reader, dbClientFactory Datab
 		LogGcsDir:         req.LogGcsPath,
 		Incarnation:       req.Incarnation,
 		BackupIncarnation: req.BackupIncarnation,
+		DBID:              req.DBID,
 		StartTime:         req.StartTime,
 		EndTime:           req.EndTime,
 		StartSCN:          req.StartScn,
 		EndSCN:            req.EndScn,
+		Stream:            req.Stream,
+		ChainGCSPaths:     req.ChainGcsPaths,
 	})
 }
 
@@ -1090,6 +1228,8 @@ type CheckStatusRequest struct {
 	CdbName         string
 	CheckStatusType CheckStatusRequest_Type
 	DbDomain        string
+	// VerifyKeystore reports the TDE keystore status of the database.
+	VerifyKeystore bool
 }
 
 type CheckStatusRequest_Type int32
@@ -1102,12 +1242,42 @@ const (
 type CheckStatusResponse struct {
 	Status       string
 	ErrorMessage string
+	// KeystoreStatus, KeystoreWalletType and KeystoreLocation describe the
+	// TDE keystore if VerifyKeystore was requested, see keystoreStatus.
+	KeystoreStatus     string
+	KeystoreWalletType string
+	KeystoreLocation   string
+}
+
+// keystoreStatusSQL reports the keystore of each open container, the CDB
+// root first.
+const keystoreStatusSQL = "select c.name, w.wrl_parameter, w.wallet_type, w.status " +
+	"from v$encryption_wallet w join v$containers c on c.con_id = w.con_id " +
+	"where c.open_mode = 'READ WRITE' order by w.con_id"
+
+// keystoreStatus returns the keystoreStatusSQL row of the CDB root, or of
+// the first PDB whose keystore isn't open if the one of the root is, so
+// that the status is only OPEN once all the containers can use their keys.
+func keystoreStatus(rows []map[string]string) (map[string]string, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("got no keystore rows")
+	}
+	if rows[0]["STATUS"] != "OPEN" {
+		return rows[0], nil
+	}
+	for _, row := range rows[1:] {
+		if row["STATUS"] != "OPEN" {
+			return row, nil
+		}
+	}
+	return rows[0], nil
 }
 
 // CheckStatus runs a requested set of state checks.
 // The Instance state check consists of:
 //   - checking the provisioning done file.
 //   - running a CDB connection test via DB Daemon.
+//   - reporting the TDE keystore status, if requested.
 func CheckStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CheckStatusRequest) (*CheckStatusResponse, error) {
 	klog.InfoS("config_agent_helpers/CheckStatus", "namespace", namespace, "instName", instName, "name", req.Name, "cdbName", req.CdbName, "checkStatusType", req.CheckStatusType)
 
@@ -1125,7 +1295,7 @@ func CheckStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseC
 	defer closeConn()
 	klog.V(1).InfoS("config_agent_helpers/CheckStatus: checking if provisioning file exists")
 
-	resp, err := dbClient.FileExists(ctx, &dbdpb.FileExistsRequest{Name: consts.ProvisioningDoneFile})
+	resp, err := dbClient.FileExists(ctx, &dbdpb.FileExistsRequest{Name: consts.ProvisioningDoneFile, WaitSeconds: provisioningDoneWaitSeconds})
 	if err != nil {
 		return nil, fmt.Errorf("config_agent_helpers/CheckStatus: failed to check a provisioning file: %v", err)
 	}
@@ -1148,7 +1318,28 @@ func CheckStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseC
 	}
 	klog.InfoS("config_agent_helpers/CheckStatus", "PDB query response", resp2)
 
-	return &CheckStatusResponse{Status: "Ready"}, nil
+	if !req.VerifyKeystore {
+		return &CheckStatusResponse{Status: "Ready"}, nil
+	}
+	resp3, err := dbClient.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{keystoreStatusSQL}, Suppress: false})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CheckStatus: failed to query the keystore: %v", err)
+	}
+	rows, err := parseSQLResponse(resp3)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CheckStatus: failed to parse the keystore status: %v", err)
+	}
+	keystore, err := keystoreStatus(rows)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CheckStatus: %v", err)
+	}
+	klog.InfoS("config_agent_helpers/CheckStatus", "keystore", keystore)
+	return &CheckStatusResponse{
+		Status:             "Ready",
+		KeystoreStatus:     keystore["STATUS"],
+		KeystoreWalletType: keystore["WALLET_TYPE"],
+		KeystoreLocation:   keystore["WRL_PARAMETER"],
+	}, nil
 }
 
 type DataPumpImportRequest struct {
@@ -1158,6 +1349,14 @@ type DataPumpImportRequest struct {
 	GcsPath string
 	// GCS path to output log file
 	GcsLogPath string
+	// Database link to import from instead of a dump file.
+	NetworkLink string
+	// Source to target schema and tablespace names.
+	RemapSchemas     map[string]string
+	RemapTablespaces map[string]string
+	// INCLUDE and EXCLUDE object filters.
+	Includes []string
+	Excludes []string
 	// Additional command options from the user.
 	Options  map[string]string
 	LroInput *LROInput
@@ -1173,28 +1372,120 @@ var AllowedImpdpParams = map[string]bool{
 	"NETWORK_LINK":        true,
 }
 
-// DataPumpImport imports data dump file provided in GCS path.
-func DataPumpImport(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req DataPumpImportRequest) (*lropb.Operation, error) {
-	klog.InfoS("config_agent_helpers/DataPumpImport", "namespace", namespace, "instName", instName, "pdbName", req.PdbName, "dbDomain", req.DbDomain, "gcsPath", req.GcsPath)
+var (
+	// impdpName matches the schema and tablespace names impdp accepts
+	// unquoted in remaps.
+	impdpName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]{0,127}$`)
+	// impdpLinkName matches database link names, optionally qualified by
+	// a domain and a connection qualifier.
+	impdpLinkName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#.@]{0,127}$`)
+	// impdpFilter matches INCLUDE and EXCLUDE filters, an object type
+	// optionally followed by a double-quoted name clause. Nothing follows
+	// the closing quote, so a filter can't add another impdp parameter.
+	impdpFilter = regexp.MustCompile(`^[A-Za-z][A-Za-z_/]*(:"[^"\n]+")?$`)
+)
 
-	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
-	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/DataPumpImport: failed to create database daemon client: %v", err)
+// impdpRemaps returns the impdp params of remaps, sorted by source name.
+func impdpRemaps(param string, remaps map[string]string) ([]string, error) {
+	var params []string
+	for from, to := range remaps {
+		if !impdpName.MatchString(from) || !impdpName.MatchString(to) {
+			return nil, fmt.Errorf("invalid %s %q:%q, names must be unquoted identifiers", param, from, to)
+		}
+		params = append(params, fmt.Sprintf("%s=%s:%s", param, strings.ToUpper(from), strings.ToUpper(to)))
+	}
+	sort.Strings(params)
+	return params, nil
+}
+
+// dataPumpImportParams validates req and returns the impdp command params
+// importing it.
+func dataPumpImportParams(req DataPumpImportRequest) ([]string, error) {
+	if (req.GcsPath == "") == (req.NetworkLink == "") {
+		return nil, fmt.Errorf("exactly one of a GCS path or a network link is required")
+	}
+	if len(req.Includes) > 0 && len(req.Excludes) > 0 {
+		return nil, fmt.Errorf("includes and excludes can't be combined")
 	}
-	defer func() { _ = closeConn() }()
 
 	commandParams := []string{
 		"FULL=YES",
 		"METRICS=YES",
 		"LOGTIME=ALL",
 	}
-	for k, v := range req.Options {
-		k = strings.ToUpper(k)
-		if _, found := AllowedImpdpParams[k]; found {
-			param := k + "=" + v
-			commandParams = append(commandParams, param)
+	if req.NetworkLink != "" {
+		if !impdpLinkName.MatchString(req.NetworkLink) {
+			return nil, fmt.Errorf("invalid network link %q", req.NetworkLink)
+		}
+		commandParams = append(commandParams, "NETWORK_LINK="+strings.ToUpper(req.NetworkLink))
+	}
+	for _, remap := range []struct {
+		param  string
+		remaps map[string]string
+	}{
+		{"REMAP_SCHEMA", req.RemapSchemas},
+		{"REMAP_TABLESPACE", req.RemapTablespaces},
+	} {
+		params, err := impdpRemaps(remap.param, remap.remaps)
+		if err != nil {
+			return nil, err
+		}
+		commandParams = append(commandParams, params...)
+	}
+	for _, filter := range []struct {
+		param   string
+		filters []string
+	}{
+		{"INCLUDE", req.Includes},
+		{"EXCLUDE", req.Excludes},
+	} {
+		for _, f := range filter.filters {
+			if !impdpFilter.MatchString(f) {
+				return nil, fmt.Errorf("invalid %s filter %q", strings.ToLower(filter.param), f)
+			}
+			commandParams = append(commandParams, filter.param+"="+f)
+		}
+	}
+
+	var keys []string
+	for k := range req.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		param := strings.ToUpper(k)
+		if _, found := AllowedImpdpParams[param]; !found {
+			continue
+		}
+		if param == "NETWORK_LINK" && req.NetworkLink != "" {
+			return nil, fmt.Errorf("the network link is set twice, in the spec and in the options")
 		}
+		commandParams = append(commandParams, param+"="+req.Options[k])
 	}
+	return commandParams, nil
+}
+
+// ValidateDataPumpImport returns an error if req can't be imported.
+func ValidateDataPumpImport(req DataPumpImportRequest) error {
+	_, err := dataPumpImportParams(req)
+	return err
+}
+
+// DataPumpImport imports data dump file provided in GCS path, or the data
+// of the database NetworkLink points to.
+func DataPumpImport(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req DataPumpImportRequest) (*lropb.Operation, error) {
+	klog.InfoS("config_agent_helpers/DataPumpImport", "namespace", namespace, "instName", instName, "pdbName", req.PdbName, "dbDomain", req.DbDomain, "gcsPath", req.GcsPath, "networkLink", req.NetworkLink)
+
+	commandParams, err := dataPumpImportParams(req)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/DataPumpImport: %v", err)
+	}
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/DataPumpImport: failed to create database daemon client: %v", err)
+	}
+	defer func() { _ = closeConn() }()
 
 	return dbClient.DataPumpImportAsync(ctx, &dbdpb.DataPumpImportAsyncRequest{
 		SyncRequest: &dbdpb.DataPumpImportRequest{
@@ -1329,6 +1620,7 @@ type PhysicalBackupMetadataResponse struct {
 	BackupScn         string
 	BackupIncarnation string
 	BackupTimestamp   *timestamppb.Timestamp
+	BackupSizeBytes   int64
 }
 
 // PhysicalBackupMetadata fetches backup scn/timestamp/incarnation with provided backup tag.
@@ -1397,180 +1689,619 @@ func PhysicalBackupMetadata(ctx context.Context, r client.Reader, dbClientFactor
 		return nil, fmt.Errorf("config_agent_helpers/PhysicalBackupMetadata: failed to query database incarnation: %s", err)
 	}
 
-	klog.InfoS("config_agent_helpers/PhysicalBackupMetadata", "backup incarnation", incResp.Incarnation, "backup scn", maxSCN, "backup time", backupTime)
+	backupSizeSQL := "select nvl(sum(bytes), 0) as backupsize from v$backup_piece where tag = '%s'"
+	backupSizeResp, err := fetchAndParseSingleResultQuery(ctx, dbClient, fmt.Sprintf(backupSizeSQL, strings.ToUpper(req.BackupTag)))
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/PhysicalBackupMetadata: failed to query backup size: %s", err)
+	}
+	var backupSize int64
+	if backupSizeResp != "" {
+		if backupSize, err = strconv.ParseInt(backupSizeResp, 10, 64); err != nil {
+			return nil, fmt.Errorf("config_agent_helpers/PhysicalBackupMetadata: failed to parse backup size %q: %v", backupSizeResp, err)
+		}
+	}
+
+	klog.InfoS("config_agent_helpers/PhysicalBackupMetadata", "backup incarnation", incResp.Incarnation, "backup scn", maxSCN, "backup time", backupTime, "backup size", backupSize)
 	return &PhysicalBackupMetadataResponse{
 		BackupIncarnation: incResp.Incarnation,
 		BackupScn:         strconv.FormatInt(maxSCN, 10),
 		BackupTimestamp:   timestamppb.New(backupTime),
+		BackupSizeBytes:   backupSize,
 	}, nil
 }
 
-type FetchDatabaseIncarnationResponse struct {
-	Incarnation string
+type WriteBackupMetadataRequest struct {
+	GcsPath  string
+	Metadata *dbdpb.BackupMetadata
 }
 
-// FetchDatabaseIncarnation fetches the database incarnation number.
-func FetchDatabaseIncarnation(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*FetchDatabaseIncarnationResponse, error) {
-	klog.InfoS("config_agent_helpers/FetchDatabaseIncarnation", "namespace", namespace, "instName", instName)
+// WriteBackupMetadata writes the metadata of a backup to a JSON object next
+// to the backup in GCS, so restores read it without an RMAN round-trip.
+func WriteBackupMetadata(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req WriteBackupMetadataRequest) error {
+	klog.InfoS("config_agent_helpers/WriteBackupMetadata", "namespace", namespace, "instName", instName, "gcsPath", req.GcsPath, "backupTag", req.Metadata.GetTag())
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
-	defer func() { _ = closeConn() }()
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/FetchDatabaseIncarnation: failed to create database daemon client: %w", err)
+		return fmt.Errorf("config_agent_helpers/WriteBackupMetadata: failed to create database daemon client: %w", err)
 	}
-	inc, err := fetchAndParseSingleResultQuery(ctx, dbClient, consts.GetDatabaseIncarnationSQL)
-	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/FetchDatabaseIncarnation: failed to query database incarnation: %s", err)
+	defer closeConn()
+	if _, err := dbClient.WriteBackupMetadata(ctx, &dbdpb.WriteBackupMetadataRequest{GcsPath: req.GcsPath, Metadata: req.Metadata}); err != nil {
+		return fmt.Errorf("config_agent_helpers/WriteBackupMetadata: %w", err)
 	}
-	return &FetchDatabaseIncarnationResponse{Incarnation: inc}, nil
-}
-
-type VerifyStandbySettingsRequest struct {
-	PrimaryHost         string
-	PrimaryPort         int32
-	PrimaryService      string
-	PrimaryUser         string
-	PrimaryCredential   *Credential
-	StandbyDbUniqueName string
-	StandbyCdbName      string
-	BackupGcsPath       string
-	PasswordFileGcsPath string
-	StandbyVersion      string
+	return nil
 }
 
-type VerifyStandbySettingsResponse struct {
-	Errors []*standbyhelpers.StandbySettingErr
+// ReadBackupMetadata reads the metadata object of a backup in GCS, it
+// returns nil for backups taken before the metadata objects were written.
+func ReadBackupMetadata(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, gcsPath string) (*dbdpb.BackupMetadata, error) {
+	klog.InfoS("config_agent_helpers/ReadBackupMetadata", "namespace", namespace, "instName", instName, "gcsPath", gcsPath)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ReadBackupMetadata: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+	resp, err := dbClient.ReadBackupMetadata(ctx, &dbdpb.ReadBackupMetadataRequest{GcsPath: gcsPath})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ReadBackupMetadata: %w", err)
+	}
+	if !resp.GetFound() {
+		return nil, nil
+	}
+	return resp.GetMetadata(), nil
 }
 
-type Credential struct {
-	// Types that are assignable to Source:
-	//	*Credential_GsmSecretReference
-	Source isCredentialSource
+type RestoreValidationReportRequest struct {
+	// GcsPath is where the consolidated report is uploaded.
+	GcsPath string
+	// CheckLogical also validates blocks for logical corruption.
+	CheckLogical bool
+	LroInput     *LROInput
 }
 
-func (x *Credential) GetGsmSecretReference() *GsmSecretReference {
-	if x, ok := x.Source.(*CredentialGsmSecretReference); ok {
-		return x.GsmSecretReference
+// RestoreValidationReport starts an LRO which validates that the database
+// can be restored from its backups, see dbdaemon->RestoreValidationReportAsync().
+func RestoreValidationReport(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req RestoreValidationReportRequest) (*lropb.Operation, error) {
+	klog.InfoS("config_agent_helpers/RestoreValidationReport", "namespace", namespace, "instName", instName, "gcsPath", req.GcsPath, "checkLogical", req.CheckLogical)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/RestoreValidationReport: failed to create database daemon client: %w", err)
 	}
-	return nil
-}
+	defer closeConn()
 
-type isCredentialSource interface {
-	isCredentialSource()
+	return dbClient.RestoreValidationReportAsync(ctx, &dbdpb.RestoreValidationReportAsyncRequest{
+		SyncRequest: &dbdpb.RestoreValidationReportRequest{
+			GcsPath:      req.GcsPath,
+			CheckLogical: req.CheckLogical,
+		},
+		LroInput: &dbdpb.LROInput{
+			OperationId: req.LroInput.OperationId,
+		},
+	})
 }
 
-type CredentialGsmSecretReference struct {
-	GsmSecretReference *GsmSecretReference
+type RotateWalletPasswordRequest struct {
+	// PasswordRef is the GSM secret version holding the current keystore
+	// password.
+	PasswordRef GsmSecretReference
+	NewPassword string
 }
 
-func (*CredentialGsmSecretReference) isCredentialSource() {}
-
-// VerifyStandbySettings does preflight checks on standby settings.
-func VerifyStandbySettings(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req VerifyStandbySettingsRequest) (*VerifyStandbySettingsResponse, error) {
-	klog.InfoS("config_agent_helpers/VerifyStandbySettings", "namespace", namespace, "instName", instName, "primaryHost", req.PrimaryHost, "standbyDbUniqueName", req.StandbyDbUniqueName)
-
+// RotateWalletPassword changes the TDE keystore password to NewPassword and
+// adds it as a new version of the password secret, whose name it returns.
+// If the secret can't be updated the keystore password is changed back, so
+// the secret keeps holding the password opening the keystore.
+func RotateWalletPassword(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req RotateWalletPasswordRequest) (string, error) {
+	klog.InfoS("config_agent_helpers/RotateWalletPassword", "namespace", namespace, "instName", instName, "projectId", req.PasswordRef.ProjectId, "secretId", req.PasswordRef.SecretId, "version", req.PasswordRef.Version)
+	current, err := AccessSecretVersionFunc(ctx, fmt.Sprintf(gsmSecretStr, req.PasswordRef.ProjectId, req.PasswordRef.SecretId, req.PasswordRef.Version))
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/RotateWalletPassword: failed to read the current keystore password: %w", err)
+	}
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/VerifyStandbySettings: failed to create database daemon dbdClient: %v", err)
+		return "", fmt.Errorf("config_agent_helpers/RotateWalletPassword: failed to create database daemon client: %w", err)
 	}
 	defer closeConn()
 
-	sa := secret.NewGSMSecretAccessor(
-		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
-		req.PrimaryCredential.GetGsmSecretReference().SecretId,
-		req.PrimaryCredential.GetGsmSecretReference().Version,
-	)
-	defer sa.Clear()
-
-	primaryDB := &standby.Primary{
-		Host:             req.PrimaryHost,
-		Port:             int(req.PrimaryPort),
-		Service:          req.PrimaryService,
-		User:             req.PrimaryUser,
-		PasswordAccessor: sa,
+	resp, err := dbClient.RotateWalletPassword(ctx, &dbdpb.RotateWalletPasswordRequest{CurrentPassword: current, NewPassword: req.NewPassword})
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/RotateWalletPassword: %w", err)
 	}
-
-	standbyDB := &standby.Standby{
-		CDBName:      req.StandbyCdbName,
-		DBUniqueName: req.StandbyDbUniqueName,
-		Version:      req.StandbyVersion,
+	version, err := AddSecretVersionFunc(ctx, fmt.Sprintf("projects/%s/secrets/%s", req.PasswordRef.ProjectId, req.PasswordRef.SecretId), req.NewPassword)
+	if err == nil {
+		return version, nil
+	}
+	if _, revertErr := dbClient.RotateWalletPassword(ctx, &dbdpb.RotateWalletPasswordRequest{CurrentPassword: req.NewPassword, NewPassword: current}); revertErr != nil {
+		return "", fmt.Errorf("config_agent_helpers/RotateWalletPassword: failed to store the new keystore password (%v) and to change the keystore password back (%v), the keystore backup in %s opens with the password in the secret", err, revertErr, resp.GetKeystoreLocation())
 	}
+	return "", fmt.Errorf("config_agent_helpers/RotateWalletPassword: failed to store the new keystore password, changed it back: %w", err)
+}
 
-	settingErrs := standby.VerifyStandbySettings(ctx, primaryDB, standbyDB, req.PasswordFileGcsPath, req.BackupGcsPath, dbClient)
-	//the returned error is always nil because all the errors that occurred during the verification have been added in settingErrs.
-	return &VerifyStandbySettingsResponse{
-		Errors: settingErrs,
-	}, nil
+type SetupKeystoreRequest struct {
+	// PasswordRef is the GSM secret version holding the keystore password.
+	PasswordRef GsmSecretReference
+	AutoLogin   bool
 }
 
-type CreateStandbyRequest struct {
-	PrimaryHost         string
-	PrimaryPort         int32
-	PrimaryService      string
-	PrimaryUser         string
-	PrimaryCredential   *Credential
-	StandbyDbUniqueName string
-	StandbyLogDiskSize  int64
-	StandbyDbDomain     string
-	BackupGcsPath       string
-	LroInput            *LROInput
+type SetupKeystoreResponse struct {
+	Location   string
+	WalletType string
+	Status     string
+	Created    bool
+	// RestartRequired is set once wallet_root was set in the spfile, the
+	// setup has to be repeated after the database restarts.
+	RestartRequired bool
+	// MasterKeyContainers lists the containers whose master key was set.
+	MasterKeyContainers []string
 }
 
-// CreateStandby creates a standby database.
-func CreateStandby(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CreateStandbyRequest) (*lropb.Operation, error) {
-	klog.InfoS("config_agent_helpers/CreateStandby",
-		"namespace", namespace,
-		"instName", instName,
-		"primaryHost", req.PrimaryHost,
-		"primaryPort", req.PrimaryPort,
-		"primaryService", req.PrimaryService,
-		"primaryUser", req.PrimaryUser,
-		"standbyDbUniqueName", req.StandbyDbUniqueName,
-	)
+// SetupKeystore creates and opens the TDE keystore with the password of
+// PasswordRef and sets the master keys of the containers without one.
+func SetupKeystore(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req SetupKeystoreRequest) (*SetupKeystoreResponse, error) {
+	klog.InfoS("config_agent_helpers/SetupKeystore", "namespace", namespace, "instName", instName, "projectId", req.PasswordRef.ProjectId, "secretId", req.PasswordRef.SecretId, "version", req.PasswordRef.Version, "autoLogin", req.AutoLogin)
+	password, err := AccessSecretVersionFunc(ctx, fmt.Sprintf(gsmSecretStr, req.PasswordRef.ProjectId, req.PasswordRef.SecretId, req.PasswordRef.Version))
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/SetupKeystore: failed to read the keystore password: %w", err)
+	}
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/CreateStandby: failed to create database daemon dbdClient: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/SetupKeystore: failed to create database daemon client: %w", err)
 	}
 	defer closeConn()
 
-	sa := secret.NewGSMSecretAccessor(
-		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
-		req.PrimaryCredential.GetGsmSecretReference().SecretId,
-		req.PrimaryCredential.GetGsmSecretReference().Version,
-	)
-	defer sa.Clear()
-
-	primaryDB := &standby.Primary{
-		Host:             req.PrimaryHost,
-		Port:             int(req.PrimaryPort),
-		Service:          req.PrimaryService,
-		User:             req.PrimaryUser,
-		PasswordAccessor: sa,
+	resp, err := dbClient.ConfigureKeystore(ctx, &dbdpb.ConfigureKeystoreRequest{KeystorePassword: password, AutoLogin: req.AutoLogin})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/SetupKeystore: %w", err)
 	}
-
-	standbyDB := &standby.Standby{
-		DBUniqueName: req.StandbyDbUniqueName,
-		Port:         consts.SecureListenerPort,
-		DBDomain:     req.StandbyDbDomain,
-		LogDiskSize:  req.StandbyLogDiskSize,
+	setup := &SetupKeystoreResponse{
+		Location:        resp.GetKeystoreLocation(),
+		WalletType:      resp.GetWalletType(),
+		Status:          resp.GetStatus(),
+		Created:         resp.GetCreated(),
+		RestartRequired: resp.GetRestartRequired(),
 	}
-
-	lro, err := standby.CreateStandby(ctx, primaryDB, standbyDB, req.BackupGcsPath, req.LroInput.OperationId, dbClient)
+	if setup.RestartRequired {
+		return setup, nil
+	}
+	keyResp, err := dbClient.SetMasterKey(ctx, &dbdpb.SetMasterKeyRequest{KeystorePassword: password})
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/CreateStandby: failed to create standby: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/SetupKeystore: %w", err)
 	}
-
-	return lro, nil
+	setup.Status = keyResp.GetStatus()
+	setup.MasterKeyContainers = keyResp.GetContainers()
+	return setup, nil
 }
 
-type SetUpDataGuardRequest struct {
-	PrimaryHost         string
-	PrimaryPort         int32
-	PrimaryService      string
-	PrimaryUser         string
-	PrimaryCredential   *Credential
-	StandbyDbUniqueName string
-	StandbyHost         string
-	PasswordFileGcsPath string
+type FetchDatabaseIncarnationResponse struct {
+	Incarnation string
+}
+
+// FetchDatabaseIncarnation fetches the database incarnation number.
+func FetchDatabaseIncarnation(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*FetchDatabaseIncarnationResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchDatabaseIncarnation", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	defer func() { _ = closeConn() }()
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchDatabaseIncarnation: failed to create database daemon client: %w", err)
+	}
+	inc, err := fetchAndParseSingleResultQuery(ctx, dbClient, consts.GetDatabaseIncarnationSQL)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchDatabaseIncarnation: failed to query database incarnation: %s", err)
+	}
+	return &FetchDatabaseIncarnationResponse{Incarnation: inc}, nil
+}
+
+type FetchDBIDResponse struct {
+	DBID string
+	Name string
+}
+
+// FetchDBID fetches the DBID of the database.
+func FetchDBID(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*FetchDBIDResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchDBID", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchDBID: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetDBID(ctx, &dbdpb.GetDBIDRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchDBID: failed to query the DBID: %v", err)
+	}
+	return &FetchDBIDResponse{DBID: strconv.FormatInt(resp.GetDbid(), 10), Name: resp.GetName()}, nil
+}
+
+type FetchInstanceInfoResponse struct {
+	StartupTime    time.Time
+	Uptime         time.Duration
+	Incarnation    string
+	OpenMode       string
+	DatabaseRole   string
+	ProtectionMode string
+	Version        string
+}
+
+// FetchInstanceInfo fetches the startup time, the uptime and the state of
+// the database instance.
+func FetchInstanceInfo(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*FetchInstanceInfoResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchInstanceInfo", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchInstanceInfo: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetInstanceInfo(ctx, &dbdpb.GetInstanceInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchInstanceInfo: failed to get the instance info: %v", err)
+	}
+	info := &FetchInstanceInfoResponse{
+		Uptime:         time.Duration(resp.GetUptimeSeconds()) * time.Second,
+		Incarnation:    resp.GetIncarnation(),
+		OpenMode:       resp.GetOpenMode(),
+		DatabaseRole:   resp.GetDatabaseRole(),
+		ProtectionMode: resp.GetProtectionMode(),
+		Version:        resp.GetVersion(),
+	}
+	if resp.GetStartupTime() != nil {
+		info.StartupTime = resp.GetStartupTime().AsTime()
+	}
+	return info, nil
+}
+
+type SelfTestResponse struct {
+	// Failures maps the failed checks to their errors.
+	Failures map[string]string
+}
+
+// SelfTest runs the database daemon self-test, GCS access is checked if
+// gcsPath is set.
+func SelfTest(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, gcsPath string) (*SelfTestResponse, error) {
+	klog.InfoS("config_agent_helpers/SelfTest", "namespace", namespace, "instName", instName, "gcsPath", gcsPath)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/SelfTest: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.SelfTest(ctx, &dbdpb.SelfTestRequest{GcsPath: gcsPath})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/SelfTest: failed to run the self-test: %v", err)
+	}
+	failures := make(map[string]string)
+	for _, check := range resp.GetChecks() {
+		if !check.GetPassed() {
+			failures[check.GetName()] = check.GetError()
+		}
+	}
+	return &SelfTestResponse{Failures: failures}, nil
+}
+
+// PDBState describes the state of a PDB.
+type PDBState struct {
+	Name       string
+	OpenMode   string
+	Restricted bool
+	SizeBytes  int64
+}
+
+type FetchPDBStatesResponse struct {
+	PDBs []PDBState
+}
+
+// FetchPDBStates fetches the state of all PDBs in the CDB.
+func FetchPDBStates(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*FetchPDBStatesResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchPDBStates", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPDBStates: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{consts.ListPDBStatesSQL}})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPDBStates: failed to query PDBs: %v", err)
+	}
+	rows, err := parseSQLResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPDBStates: %v", err)
+	}
+	pdbs := make([]PDBState, 0, len(rows))
+	for _, row := range rows {
+		pdb := PDBState{
+			Name:       row["NAME"],
+			OpenMode:   row["OPEN_MODE"],
+			Restricted: row["RESTRICTED"] == "YES",
+		}
+		// TOTAL_SIZE is 0 for PDBs which are not open.
+		if size := row["TOTAL_SIZE"]; size != "" {
+			if pdb.SizeBytes, err = strconv.ParseInt(size, 10, 64); err != nil {
+				return nil, fmt.Errorf("config_agent_helpers/FetchPDBStates: failed to parse the size of PDB %q: %v", pdb.Name, err)
+			}
+		}
+		pdbs = append(pdbs, pdb)
+	}
+	return &FetchPDBStatesResponse{PDBs: pdbs}, nil
+}
+
+// ParameterManifest holds the exported parameters of an instance, in the
+// form of Instance spec.parameters.
+type ParameterManifest struct {
+	// Static parameters require a database restart to be applied.
+	Static  map[string]string
+	Dynamic map[string]string
+}
+
+// Parameters returns all parameters of the manifest.
+func (m *ParameterManifest) Parameters() map[string]string {
+	params := make(map[string]string, len(m.Static)+len(m.Dynamic))
+	for k, v := range m.Static {
+		params[k] = v
+	}
+	for k, v := range m.Dynamic {
+		params[k] = v
+	}
+	return params
+}
+
+// ExportParameters exports the non-default parameters of the database which
+// can be set on another instance with SetParameter.
+func ExportParameters(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*ParameterManifest, error) {
+	klog.InfoS("config_agent_helpers/ExportParameters", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ExportParameters: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ExportParameters(ctx, &dbdpb.ExportParametersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ExportParameters: failed to export parameters: %v", err)
+	}
+	manifest := &ParameterManifest{Static: make(map[string]string), Dynamic: make(map[string]string)}
+	for _, p := range resp.GetParameters() {
+		if ReservedParameters[p.GetName()] || instanceParameters[p.GetName()] {
+			continue
+		}
+		if !sql.IsValidParameterValue(p.GetValue(), p.GetStringType()) {
+			klog.InfoS("config_agent_helpers/ExportParameters: skipping parameter with unsupported value", "name", p.GetName(), "value", p.GetValue())
+			continue
+		}
+		if p.GetStatic() || overrideParamTypeStatic[p.GetName()] {
+			manifest.Static[p.GetName()] = p.GetValue()
+		} else {
+			manifest.Dynamic[p.GetName()] = p.GetValue()
+		}
+	}
+	return manifest, nil
+}
+
+// ParameterChange is a parameter which differs from a baseline.
+type ParameterChange struct {
+	Name          string
+	BaselineValue string
+	CurrentValue  string
+	// Static parameters require a database restart to be applied.
+	Static bool
+}
+
+// ParameterDiff holds the changes of the parameters of an instance since a
+// baseline manifest.
+type ParameterDiff struct {
+	Added   []ParameterChange
+	Removed []ParameterChange
+	Changed []ParameterChange
+}
+
+// parameterChanges leaves out the parameters ExportParameters doesn't export.
+func parameterChanges(changes []*dbdpb.DiffParametersAgainstBaselineResponse_Change) []ParameterChange {
+	var out []ParameterChange
+	for _, c := range changes {
+		if ReservedParameters[c.GetName()] || instanceParameters[c.GetName()] {
+			continue
+		}
+		out = append(out, ParameterChange{Name: c.GetName(), BaselineValue: c.GetBaselineValue(), CurrentValue: c.GetCurrentValue(), Static: c.GetStatic()})
+	}
+	return out
+}
+
+// DiffParametersAgainstBaseline compares the parameters of an instance with a
+// baseline manifest saved from ExportParameters, so that the configuration
+// changes since the baseline can be reviewed.
+func DiffParametersAgainstBaseline(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, baseline *ParameterManifest) (*ParameterDiff, error) {
+	klog.InfoS("config_agent_helpers/DiffParametersAgainstBaseline", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/DiffParametersAgainstBaseline: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	req := &dbdpb.DiffParametersAgainstBaselineRequest{Baseline: &dbdpb.ExportParametersResponse{}}
+	for _, params := range []struct {
+		values map[string]string
+		static bool
+	}{{baseline.Static, true}, {baseline.Dynamic, false}} {
+		for name, value := range params.values {
+			req.Baseline.Parameters = append(req.Baseline.Parameters, &dbdpb.ExportParametersResponse_Parameter{Name: name, Value: value, Static: params.static})
+		}
+	}
+	resp, err := dbClient.DiffParametersAgainstBaseline(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/DiffParametersAgainstBaseline: failed to diff parameters: %v", err)
+	}
+	return &ParameterDiff{
+		Added:   parameterChanges(resp.GetAdded()),
+		Removed: parameterChanges(resp.GetRemoved()),
+		Changed: parameterChanges(resp.GetChanged()),
+	}, nil
+}
+
+// NormalizeParameters scopes the spfile parameters of an instance to all
+// instances (sid='*') so that later parameter updates take effect. It returns
+// the statements which were run.
+func NormalizeParameters(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) ([]string, error) {
+	klog.InfoS("config_agent_helpers/NormalizeParameters", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/NormalizeParameters: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.NormalizeParameters(ctx, &dbdpb.NormalizeParametersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/NormalizeParameters: failed to normalize parameters: %v", err)
+	}
+	return resp.GetStatements(), nil
+}
+
+type VerifyStandbySettingsRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	StandbyCdbName      string
+	BackupGcsPath       string
+	PasswordFileGcsPath string
+	StandbyVersion      string
+}
+
+type VerifyStandbySettingsResponse struct {
+	Errors []*standbyhelpers.StandbySettingErr
+}
+
+type Credential struct {
+	// Types that are assignable to Source:
+	//	*Credential_GsmSecretReference
+	Source isCredentialSource
+}
+
+func (x *Credential) GetGsmSecretReference() *GsmSecretReference {
+	if x, ok := x.Source.(*CredentialGsmSecretReference); ok {
+		return x.GsmSecretReference
+	}
+	return nil
+}
+
+type isCredentialSource interface {
+	isCredentialSource()
+}
+
+type CredentialGsmSecretReference struct {
+	GsmSecretReference *GsmSecretReference
+}
+
+func (*CredentialGsmSecretReference) isCredentialSource() {}
+
+// VerifyStandbySettings does preflight checks on standby settings.
+func VerifyStandbySettings(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req VerifyStandbySettingsRequest) (*VerifyStandbySettingsResponse, error) {
+	klog.InfoS("config_agent_helpers/VerifyStandbySettings", "namespace", namespace, "instName", instName, "primaryHost", req.PrimaryHost, "standbyDbUniqueName", req.StandbyDbUniqueName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/VerifyStandbySettings: failed to create database daemon dbdClient: %v", err)
+	}
+	defer closeConn()
+
+	sa := secret.NewGSMSecretAccessor(
+		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
+		req.PrimaryCredential.GetGsmSecretReference().SecretId,
+		req.PrimaryCredential.GetGsmSecretReference().Version,
+	)
+	defer sa.Clear()
+
+	primaryDB := &standby.Primary{
+		Host:             req.PrimaryHost,
+		Port:             int(req.PrimaryPort),
+		Service:          req.PrimaryService,
+		User:             req.PrimaryUser,
+		PasswordAccessor: sa,
+	}
+
+	standbyDB := &standby.Standby{
+		CDBName:      req.StandbyCdbName,
+		DBUniqueName: req.StandbyDbUniqueName,
+		Version:      req.StandbyVersion,
+	}
+
+	settingErrs := standby.VerifyStandbySettings(ctx, primaryDB, standbyDB, req.PasswordFileGcsPath, req.BackupGcsPath, dbClient)
+	//the returned error is always nil because all the errors that occurred during the verification have been added in settingErrs.
+	return &VerifyStandbySettingsResponse{
+		Errors: settingErrs,
+	}, nil
+}
+
+type CreateStandbyRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	StandbyLogDiskSize  int64
+	StandbyDbDomain     string
+	BackupGcsPath       string
+	LroInput            *LROInput
+}
+
+// CreateStandby creates a standby database.
+func CreateStandby(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CreateStandbyRequest) (*lropb.Operation, error) {
+	klog.InfoS("config_agent_helpers/CreateStandby",
+		"namespace", namespace,
+		"instName", instName,
+		"primaryHost", req.PrimaryHost,
+		"primaryPort", req.PrimaryPort,
+		"primaryService", req.PrimaryService,
+		"primaryUser", req.PrimaryUser,
+		"standbyDbUniqueName", req.StandbyDbUniqueName,
+	)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CreateStandby: failed to create database daemon dbdClient: %v", err)
+	}
+	defer closeConn()
+
+	sa := secret.NewGSMSecretAccessor(
+		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
+		req.PrimaryCredential.GetGsmSecretReference().SecretId,
+		req.PrimaryCredential.GetGsmSecretReference().Version,
+	)
+	defer sa.Clear()
+
+	primaryDB := &standby.Primary{
+		Host:             req.PrimaryHost,
+		Port:             int(req.PrimaryPort),
+		Service:          req.PrimaryService,
+		User:             req.PrimaryUser,
+		PasswordAccessor: sa,
+	}
+
+	standbyDB := &standby.Standby{
+		DBUniqueName: req.StandbyDbUniqueName,
+		Port:         consts.SecureListenerPort,
+		DBDomain:     req.StandbyDbDomain,
+		LogDiskSize:  req.StandbyLogDiskSize,
+	}
+
+	lro, err := standby.CreateStandby(ctx, primaryDB, standbyDB, req.BackupGcsPath, req.LroInput.OperationId, dbClient)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CreateStandby: failed to create standby: %v", err)
+	}
+
+	return lro, nil
+}
+
+type SetUpDataGuardRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	StandbyHost         string
+	PasswordFileGcsPath string
+	RedoCompression     bool
+	RedoEncryption      bool
 }
 
 // SetUpDataGuard updates Data Guard configuration.
@@ -1583,11 +2314,744 @@ func SetUpDataGuard(ctx context.Context, r client.Reader, dbClientFactory Databa
 		"primaryService", req.PrimaryService,
 		"primaryUser", req.PrimaryUser,
 		"standbyDbUniqueName", req.StandbyDbUniqueName,
-		"standbyHost", req.StandbyHost,
+		"standbyHost", req.StandbyHost,
+	)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/SetupDataGuard: failed to create database daemon dbdClient: %v", err)
+	}
+	defer closeConn()
+
+	sa := secret.NewGSMSecretAccessor(
+		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
+		req.PrimaryCredential.GetGsmSecretReference().SecretId,
+		req.PrimaryCredential.GetGsmSecretReference().Version,
+	)
+	defer sa.Clear()
+
+	primaryDB := &standby.Primary{
+		Host:             req.PrimaryHost,
+		Port:             int(req.PrimaryPort),
+		Service:          req.PrimaryService,
+		User:             req.PrimaryUser,
+		PasswordAccessor: sa,
+	}
+
+	standbyDB := &standby.Standby{
+		DBUniqueName:    req.StandbyDbUniqueName,
+		Host:            req.StandbyHost,
+		Port:            consts.SecureListenerPort,
+		RedoCompression: req.RedoCompression,
+		RedoEncryption:  req.RedoEncryption,
+	}
+
+	if err := standby.SetUpDataGuard(ctx, primaryDB, standbyDB, req.PasswordFileGcsPath, dbClient); err != nil {
+		return fmt.Errorf("failed to set up Data Guard: %v", err)
+	}
+
+	return nil
+}
+
+type PromoteStandbyRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	StandbyHost         string
+}
+
+// PromoteStandby promotes standby database to primary.
+func PromoteStandby(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req PromoteStandbyRequest) error {
+	klog.InfoS("config_agent_helpers/PromoteStandby",
+		"namespace", namespace,
+		"instName", instName,
+		"primaryHost", req.PrimaryHost,
+		"primaryPort", req.PrimaryPort,
+		"primaryService", req.PrimaryService,
+		"primaryUser", req.PrimaryUser,
+		"standbyDbUniqueName", req.StandbyDbUniqueName,
+		"standbyHost", req.StandbyHost,
+	)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/PromoteStandby: failed to create database daemon dbdClient: %v", err)
+	}
+	defer closeConn()
+
+	sa := secret.NewGSMSecretAccessor(
+		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
+		req.PrimaryCredential.GetGsmSecretReference().SecretId,
+		req.PrimaryCredential.GetGsmSecretReference().Version,
+	)
+	defer sa.Clear()
+
+	primaryDB := &standby.Primary{
+		Host:             req.PrimaryHost,
+		Port:             int(req.PrimaryPort),
+		Service:          req.PrimaryService,
+		User:             req.PrimaryUser,
+		PasswordAccessor: sa,
+	}
+
+	standbyDB := &standby.Standby{
+		DBUniqueName: req.StandbyDbUniqueName,
+	}
+
+	if err := standby.PromoteStandby(ctx, primaryDB, standbyDB, dbClient); err != nil {
+		return fmt.Errorf("failed to promote standby: %v", err)
+	}
+
+	return nil
+}
+
+type DataGuardStatusRequest struct {
+	StandbyDbUniqueName string
+}
+
+type DataGuardStatusResponse struct {
+	Output []string
+}
+
+// DataGuardStatus returns Data Guard configuration status and standby DB status.
+func DataGuardStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req DataGuardStatusRequest) (*DataGuardStatusResponse, error) {
+	klog.InfoS("config_agent_helpers/DataGuardStatus", "namespace", namespace, "instName", instName, "standbyDbUniqueName", req.StandbyDbUniqueName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/DataGuardStatus: failed to create database daemon dbdClient: %v", err)
+	}
+	defer closeConn()
+
+	output, err := standby.DataGuardStatus(ctx, req.StandbyDbUniqueName, dbClient)
+	return &DataGuardStatusResponse{
+		Output: output,
+	}, err
+}
+
+type ApplyDataPatchRequest struct {
+	LroInput *LROInput
+}
+
+// ApplyDataPatch calls dbdaemon->ApplyDataPatch()
+func ApplyDataPatch(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ApplyDataPatchRequest) (*lropb.Operation, error) {
+	klog.InfoS("config_agent_helpersApplyDataPatch", "namespace", namespace, "instName", instName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ApplyDataPatch: failed to create database daemon client: %w", err)
+	}
+	defer func() { _ = closeConn() }()
+
+	return dbClient.ApplyDataPatchAsync(ctx, &dbdpb.ApplyDataPatchAsyncRequest{
+		LroInput: &dbdpb.LROInput{
+			OperationId: req.LroInput.OperationId,
+		},
+	})
+}
+
+type ForceLogSwitchResponse struct {
+	Thread   int64
+	Sequence int64
+}
+
+// ForceLogSwitch archives the current redo log, see dbdaemon->ForceLogSwitch().
+func ForceLogSwitch(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*ForceLogSwitchResponse, error) {
+	klog.InfoS("config_agent_helpers/ForceLogSwitch", "namespace", namespace, "instName", instName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ForceLogSwitch: failed to create database daemon client: %v", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ForceLogSwitch(ctx, &dbdpb.ForceLogSwitchRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ForceLogSwitch: failed to switch the redo log: %v", err)
+	}
+	return &ForceLogSwitchResponse{Thread: resp.GetThread(), Sequence: resp.GetSequence()}, nil
+}
+
+type ConfigureInMemoryRequest struct {
+	PdbName string
+	// SizeBytes is the new inmemory_size, it is left unchanged if nil.
+	SizeBytes             *int64
+	InMemoryTablespaces   []string
+	InMemoryTables        []string
+	NoInMemoryTablespaces []string
+	NoInMemoryTables      []string
+}
+
+// ConfigureInMemory sizes the In-Memory column store and sets the INMEMORY
+// attribute of tablespaces and tables, see dbdaemon->ConfigureInMemory().
+// It reports whether the database needs a restart for the size to apply.
+func ConfigureInMemory(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ConfigureInMemoryRequest) (bool, error) {
+	klog.InfoS("config_agent_helpers/ConfigureInMemory", "namespace", namespace, "instName", instName, "pdbName", req.PdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/ConfigureInMemory: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	dbReq := &dbdpb.ConfigureInMemoryRequest{
+		PdbName:               req.PdbName,
+		InmemoryTablespaces:   req.InMemoryTablespaces,
+		InmemoryTables:        req.InMemoryTables,
+		NoInmemoryTablespaces: req.NoInMemoryTablespaces,
+		NoInmemoryTables:      req.NoInMemoryTables,
+	}
+	if req.SizeBytes != nil {
+		dbReq.Resize = true
+		dbReq.SizeBytes = *req.SizeBytes
+	}
+	resp, err := dbClient.ConfigureInMemory(ctx, dbReq)
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/ConfigureInMemory: failed to configure the In-Memory column store: %v", err)
+	}
+	return resp.GetRestartRequired(), nil
+}
+
+type FetchInMemoryStatusResponse struct {
+	SizeBytes int64
+	// PopulatedSegments and PopulatingSegments count the In-Memory segments
+	// completely and partially populated.
+	PopulatedSegments  int32
+	PopulatingSegments int32
+}
+
+// FetchInMemoryStatus summarizes the population of the In-Memory segments of
+// the PDB, see dbdaemon->GetInMemoryStatus().
+func FetchInMemoryStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, pdbName string) (*FetchInMemoryStatusResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchInMemoryStatus", "namespace", namespace, "instName", instName, "pdbName", pdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchInMemoryStatus: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetInMemoryStatus(ctx, &dbdpb.GetInMemoryStatusRequest{PdbName: pdbName})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchInMemoryStatus: failed to get the In-Memory status: %v", err)
+	}
+	status := &FetchInMemoryStatusResponse{SizeBytes: resp.GetSizeBytes()}
+	for _, seg := range resp.GetSegments() {
+		if seg.GetPopulateStatus() == "COMPLETED" && seg.GetBytesNotPopulated() == 0 {
+			status.PopulatedSegments++
+		} else {
+			status.PopulatingSegments++
+		}
+	}
+	return status, nil
+}
+
+type MaintainPartitionsRequest struct {
+	PdbName string
+	// Table is given as OWNER.TABLE.
+	Table string
+	// Interval is one of None, Day, Week, Month or Year, the interval
+	// partitioning is left unchanged if empty.
+	Interval string
+	// Retain is the number of newest partitions to keep, 0 keeps all.
+	Retain int32
+}
+
+type MaintainPartitionsResponse struct {
+	DroppedPartitions []string
+	Partitions        int32
+}
+
+// MaintainPartitions sets up the interval partitioning of a table and drops
+// the partitions falling out of its retention, see
+// dbdaemon->MaintainPartitions().
+func MaintainPartitions(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req MaintainPartitionsRequest) (*MaintainPartitionsResponse, error) {
+	klog.InfoS("config_agent_helpers/MaintainPartitions", "namespace", namespace, "instName", instName, "pdbName", req.PdbName, "table", req.Table)
+
+	dbReq := &dbdpb.MaintainPartitionsRequest{
+		PdbName: req.PdbName,
+		Table:   req.Table,
+		Retain:  req.Retain,
+	}
+	if req.Interval != "" {
+		interval, ok := dbdpb.MaintainPartitionsRequest_Interval_value[strings.ToUpper(req.Interval)]
+		if !ok {
+			return nil, fmt.Errorf("config_agent_helpers/MaintainPartitions: unsupported interval %q", req.Interval)
+		}
+		dbReq.Interval = dbdpb.MaintainPartitionsRequest_Interval(interval)
+	}
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/MaintainPartitions: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.MaintainPartitions(ctx, dbReq)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/MaintainPartitions: failed to maintain the partitions of %s: %v", req.Table, err)
+	}
+	return &MaintainPartitionsResponse{DroppedPartitions: resp.GetDroppedPartitions(), Partitions: resp.GetPartitions()}, nil
+}
+
+type CreateAWRBaselineRequest struct {
+	BaselineName string
+	WindowHours  int32
+	// Future schedules the baseline of the next WindowHours instead of
+	// capturing the last ones.
+	Future         bool
+	ExpirationDays int32
+}
+
+// CreateAWRBaseline captures or schedules an AWR baseline, see
+// dbdaemon->CreateAWRBaseline().
+func CreateAWRBaseline(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CreateAWRBaselineRequest) error {
+	klog.InfoS("config_agent_helpers/CreateAWRBaseline", "namespace", namespace, "instName", instName, "baselineName", req.BaselineName, "future", req.Future)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/CreateAWRBaseline: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	if _, err := dbClient.CreateAWRBaseline(ctx, &dbdpb.CreateAWRBaselineRequest{
+		BaselineName:   req.BaselineName,
+		WindowHours:    req.WindowHours,
+		Future:         req.Future,
+		ExpirationDays: req.ExpirationDays,
+	}); err != nil {
+		return fmt.Errorf("config_agent_helpers/CreateAWRBaseline: failed to create baseline %s: %v", req.BaselineName, err)
+	}
+	return nil
+}
+
+// SetUserQuotas sets the quotas of the users which have some, see
+// dbdaemon->SetUserQuota().
+func SetUserQuotas(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, pdbName string, users []*User) error {
+	klog.InfoS("config_agent_helpers/SetUserQuotas", "namespace", namespace, "instName", instName, "pdbName", pdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/SetUserQuotas: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	for _, u := range users {
+		req := &dbdpb.SetUserQuotaRequest{PdbName: pdbName, UserName: u.Name}
+		for _, q := range u.Quotas {
+			req.Quotas = append(req.Quotas, &dbdpb.SetUserQuotaRequest_Quota{Tablespace: q.Tablespace, MaxBytes: q.MaxBytes})
+		}
+		if len(req.Quotas) == 0 {
+			continue
+		}
+		if _, err := dbClient.SetUserQuota(ctx, req); err != nil {
+			return fmt.Errorf("config_agent_helpers/SetUserQuotas: failed to set the quotas of user %s: %v", u.Name, err)
+		}
+	}
+	return nil
+}
+
+type SetNLSParametersRequest struct {
+	PdbName string
+	// Parameters are the NLS parameter values by name, e.g.
+	// nls_date_format, ResetParameters fall back to the instance values.
+	Parameters      map[string]string
+	ResetParameters []string
+}
+
+// SetNLSParameters sets and resets NLS parameters of a PDB, see
+// dbdaemon->SetNLSSettings(). It reports whether the database needs a
+// restart for them to take effect.
+func SetNLSParameters(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req SetNLSParametersRequest) (bool, error) {
+	klog.InfoS("config_agent_helpers/SetNLSParameters", "namespace", namespace, "instName", instName, "pdbName", req.PdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/SetNLSParameters: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.SetNLSSettings(ctx, &dbdpb.SetNLSSettingsRequest{
+		PdbName:         req.PdbName,
+		Parameters:      req.Parameters,
+		ResetParameters: req.ResetParameters,
+	})
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/SetNLSParameters: failed to set the NLS parameters: %v", err)
+	}
+	return resp.GetRestartRequired(), nil
+}
+
+type FetchNLSSettingsResponse struct {
+	// Parameters are the NLS parameter values in effect by name.
+	Parameters           map[string]string
+	CharacterSet         string
+	NationalCharacterSet string
+}
+
+// FetchNLSSettings reports the NLS parameters of a PDB and the character
+// sets of the database, see dbdaemon->GetNLSSettings().
+func FetchNLSSettings(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, pdbName string) (*FetchNLSSettingsResponse, error) {
+	klog.InfoS("config_agent_helpers/FetchNLSSettings", "namespace", namespace, "instName", instName, "pdbName", pdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchNLSSettings: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetNLSSettings(ctx, &dbdpb.GetNLSSettingsRequest{PdbName: pdbName})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchNLSSettings: failed to get the NLS settings: %v", err)
+	}
+	settings := &FetchNLSSettingsResponse{
+		Parameters:           make(map[string]string),
+		CharacterSet:         resp.GetDatabaseParameters()["NLS_CHARACTERSET"],
+		NationalCharacterSet: resp.GetDatabaseParameters()["NLS_NCHAR_CHARACTERSET"],
+	}
+	for _, p := range resp.GetParameters() {
+		settings.Parameters[p.GetName()] = p.GetValue()
+	}
+	return settings, nil
+}
+
+type ConfigurePDBResourcesRequest struct {
+	PdbName string
+	// Parameters are the integer resource parameter values by name, e.g.
+	// cpu_count, ResetParameters fall back to the instance values.
+	Parameters      map[string]string
+	ResetParameters []string
+}
+
+type ConfigurePDBResourcesResponse struct {
+	// Parameters are the resource parameter values in effect by name.
+	Parameters map[string]string
+	// Changed are the parameters which were set or reset.
+	Changed []string
+}
+
+// ConfigurePDBResources sets and resets resource parameters of a PDB, see
+// dbdaemon->ConfigurePDBResources().
+func ConfigurePDBResources(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ConfigurePDBResourcesRequest) (*ConfigurePDBResourcesResponse, error) {
+	klog.InfoS("config_agent_helpers/ConfigurePDBResources", "namespace", namespace, "instName", instName, "pdbName", req.PdbName)
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigurePDBResources: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigurePDBResources(ctx, &dbdpb.ConfigurePDBResourcesRequest{
+		PdbName:         req.PdbName,
+		Parameters:      req.Parameters,
+		ResetParameters: req.ResetParameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigurePDBResources: failed to configure the resource parameters: %v", err)
+	}
+	return &ConfigurePDBResourcesResponse{Parameters: resp.GetParameters(), Changed: resp.GetChanged()}, nil
+}
+
+// ApplicationContext is an application context set by a trusted package
+// given as SCHEMA.PACKAGE.
+type ApplicationContext struct {
+	Name             string
+	Package          string
+	AccessedGlobally bool
+}
+
+// VPDPolicy is a VPD policy of a table given as OWNER.TABLE whose predicate
+// is returned by a function given as SCHEMA.FUNCTION or
+// SCHEMA.PACKAGE.FUNCTION.
+type VPDPolicy struct {
+	Name           string
+	Table          string
+	Function       string
+	StatementTypes []string
+	UpdateCheck    bool
+	// PolicyType is one of Dynamic, Static, SharedStatic, ContextSensitive
+	// or SharedContextSensitive, it defaults to Dynamic.
+	PolicyType string
+}
+
+var vpdPolicyTypes = map[string]dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_PolicyType{
+	"":                       dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_POLICY_TYPE_UNSPECIFIED,
+	"Dynamic":                dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_DYNAMIC,
+	"Static":                 dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_STATIC,
+	"SharedStatic":           dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_SHARED_STATIC,
+	"ContextSensitive":       dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_CONTEXT_SENSITIVE,
+	"SharedContextSensitive": dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_SHARED_CONTEXT_SENSITIVE,
+}
+
+type ConfigureRowLevelSecurityRequest struct {
+	PdbName                 string
+	ApplicationContexts     []ApplicationContext
+	DropApplicationContexts []string
+	VPDPolicies             []VPDPolicy
+	// DropVPDPolicies are given as OWNER.TABLE.POLICY.
+	DropVPDPolicies []string
+}
+
+// ConfigureRowLevelSecurity creates and drops application contexts and VPD
+// policies of a PDB, see dbdaemon->ConfigureRowLevelSecurity(). It returns
+// the policies added, replaced or dropped as OWNER.TABLE.POLICY.
+func ConfigureRowLevelSecurity(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ConfigureRowLevelSecurityRequest) ([]string, error) {
+	klog.InfoS("config_agent_helpers/ConfigureRowLevelSecurity", "namespace", namespace, "instName", instName, "pdbName", req.PdbName)
+
+	dbReq := &dbdpb.ConfigureRowLevelSecurityRequest{
+		PdbName:                 req.PdbName,
+		DropApplicationContexts: req.DropApplicationContexts,
+	}
+	for _, c := range req.ApplicationContexts {
+		dbReq.ApplicationContexts = append(dbReq.ApplicationContexts, &dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{
+			Name:             c.Name,
+			Package:          c.Package,
+			AccessedGlobally: c.AccessedGlobally,
+		})
+	}
+	for _, p := range req.VPDPolicies {
+		policyType, ok := vpdPolicyTypes[p.PolicyType]
+		if !ok {
+			return nil, fmt.Errorf("config_agent_helpers/ConfigureRowLevelSecurity: unsupported policy type %q of VPD policy %q", p.PolicyType, p.Name)
+		}
+		dbReq.VpdPolicies = append(dbReq.VpdPolicies, &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{
+			Name:           p.Name,
+			Table:          p.Table,
+			Function:       p.Function,
+			StatementTypes: p.StatementTypes,
+			UpdateCheck:    p.UpdateCheck,
+			PolicyType:     policyType,
+		})
+	}
+	for _, p := range req.DropVPDPolicies {
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			return nil, fmt.Errorf("config_agent_helpers/ConfigureRowLevelSecurity: VPD policy %q is not of the form OWNER.TABLE.POLICY", p)
+		}
+		dbReq.DropVpdPolicies = append(dbReq.DropVpdPolicies, &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Table: p[:i], Name: p[i+1:]})
+	}
+
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureRowLevelSecurity: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureRowLevelSecurity(ctx, dbReq)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureRowLevelSecurity: failed to configure row level security: %v", err)
+	}
+	return resp.GetChangedVpdPolicies(), nil
+}
+
+type SetProtectionModeRequest struct {
+	StandbyDbUniqueName string
+	ProtectionMode      string
+}
+
+// SetProtectionMode sets the protection mode of the Data Guard configuration
+// of a standby.
+func SetProtectionMode(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req SetProtectionModeRequest) error {
+	klog.InfoS("config_agent_helpers/SetProtectionMode", "namespace", namespace, "instName", instName, "standbyDbUniqueName", req.StandbyDbUniqueName, "protectionMode", req.ProtectionMode)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/SetProtectionMode: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	if err := standby.SetProtectionMode(ctx, req.StandbyDbUniqueName, req.ProtectionMode, dbClient); err != nil {
+		return fmt.Errorf("config_agent_helpers/SetProtectionMode: %v", err)
+	}
+	return nil
+}
+
+type ConfigureEditionsRequest struct {
+	PdbName        string
+	Editions       []string
+	DropEditions   []string
+	DefaultEdition string
+}
+
+type ConfigureEditionsResponse struct {
+	Editions        []string
+	DefaultEdition  string
+	CreatedEditions []string
+	DroppedEditions []string
+}
+
+// ConfigureEditions creates and drops the editions of a PDB and sets its
+// default edition, see dbdaemon->ConfigureEditions().
+func ConfigureEditions(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ConfigureEditionsRequest) (*ConfigureEditionsResponse, error) {
+	klog.InfoS("config_agent_helpers/ConfigureEditions", "namespace", namespace, "instName", instName, "req", req)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureEditions: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureEditions(ctx, &dbdpb.ConfigureEditionsRequest{
+		PdbName:        req.PdbName,
+		Editions:       req.Editions,
+		DropEditions:   req.DropEditions,
+		DefaultEdition: req.DefaultEdition,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureEditions: failed to configure editions: %v", err)
+	}
+	return &ConfigureEditionsResponse{
+		Editions:        resp.GetEditions(),
+		DefaultEdition:  resp.GetDefaultEdition(),
+		CreatedEditions: resp.GetCreatedEditions(),
+		DroppedEditions: resp.GetDroppedEditions(),
+	}, nil
+}
+
+type FANService struct {
+	Name            string
+	HANotifications bool
+}
+
+type ONSConfig struct {
+	LocalPort  int32
+	RemotePort int32
+	Nodes      []string
+}
+
+type ConfigureFANRequest struct {
+	PdbName  string
+	Services []FANService
+	// ONS is left unchanged if nil.
+	ONS *ONSConfig
+}
+
+type ConfigureFANResponse struct {
+	CreatedServices  []string
+	ModifiedServices []string
+	ONSConfig        string
+	ONSRestarted     bool
+}
+
+// ConfigureFAN creates and starts the services of a PDB with FAN HA
+// notifications on or off and configures ONS, see dbdaemon->ConfigureFAN().
+func ConfigureFAN(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ConfigureFANRequest) (*ConfigureFANResponse, error) {
+	klog.InfoS("config_agent_helpers/ConfigureFAN", "namespace", namespace, "instName", instName, "req", req)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureFAN: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	dbReq := &dbdpb.ConfigureFANRequest{PdbName: req.PdbName}
+	for _, svc := range req.Services {
+		dbReq.Services = append(dbReq.Services, &dbdpb.ConfigureFANRequest_Service{
+			Name:            svc.Name,
+			HaNotifications: svc.HANotifications,
+		})
+	}
+	if req.ONS != nil {
+		dbReq.Ons = &dbdpb.ConfigureFANRequest_ONS{
+			LocalPort:  req.ONS.LocalPort,
+			RemotePort: req.ONS.RemotePort,
+			Nodes:      req.ONS.Nodes,
+		}
+	}
+	resp, err := dbClient.ConfigureFAN(ctx, dbReq)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/ConfigureFAN: failed to configure FAN: %v", err)
+	}
+	return &ConfigureFANResponse{
+		CreatedServices:  resp.GetCreatedServices(),
+		ModifiedServices: resp.GetModifiedServices(),
+		ONSConfig:        resp.GetOnsConfig(),
+		ONSRestarted:     resp.GetOnsRestarted(),
+	}, nil
+}
+
+type CreateManagedTriggerRequest struct {
+	PdbName string
+	// Name is the trigger name without the ELCARRO_TRG_ prefix.
+	Name   string
+	Timing string
+	Events []string
+	// Table is the OWNER.TABLE of a DML trigger, empty for a database
+	// trigger.
+	Table      string
+	ForEachRow bool
+	Body       string
+}
+
+// CreateManagedTrigger creates or replaces an operator owned trigger of a
+// PDB and returns its name, see dbdaemon->CreateManagedTrigger().
+func CreateManagedTrigger(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CreateManagedTriggerRequest) (string, error) {
+	klog.InfoS("config_agent_helpers/CreateManagedTrigger", "namespace", namespace, "instName", instName, "pdbName", req.PdbName, "name", req.Name, "timing", req.Timing, "events", req.Events, "table", req.Table)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/CreateManagedTrigger: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.CreateManagedTrigger(ctx, &dbdpb.CreateManagedTriggerRequest{
+		PdbName:    req.PdbName,
+		Name:       req.Name,
+		Timing:     req.Timing,
+		Events:     req.Events,
+		Table:      req.Table,
+		ForEachRow: req.ForEachRow,
+		Body:       req.Body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("config_agent_helpers/CreateManagedTrigger: failed to create trigger %s: %v", req.Name, err)
+	}
+	return resp.GetTriggerName(), nil
+}
+
+// DropManagedTrigger drops an operator owned trigger of a PDB, it returns
+// false if the trigger didn't exist, see dbdaemon->DropManagedTrigger().
+func DropManagedTrigger(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, pdbName, name string) (bool, error) {
+	klog.InfoS("config_agent_helpers/DropManagedTrigger", "namespace", namespace, "instName", instName, "pdbName", pdbName, "name", name)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/DropManagedTrigger: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.DropManagedTrigger(ctx, &dbdpb.DropManagedTriggerRequest{PdbName: pdbName, Name: name})
+	if err != nil {
+		return false, fmt.Errorf("config_agent_helpers/DropManagedTrigger: failed to drop trigger %s: %v", name, err)
+	}
+	return resp.GetDropped(), nil
+}
+
+type CreateLogicalStandbyRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	StandbyCdbName      string
+	// LogicalStandbyDbName is the new database name of the logical standby,
+	// the standby keeps the name and DBID of the primary if it is empty.
+	LogicalStandbyDbName string
+}
+
+// CreateLogicalStandby converts a physical standby to a logical standby
+// maintained by SQL Apply. It returns the primary tables SQL Apply does not
+// maintain.
+func CreateLogicalStandby(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CreateLogicalStandbyRequest) ([]string, error) {
+	klog.InfoS("config_agent_helpers/CreateLogicalStandby",
+		"namespace", namespace,
+		"instName", instName,
+		"primaryHost", req.PrimaryHost,
+		"primaryPort", req.PrimaryPort,
+		"primaryService", req.PrimaryService,
+		"primaryUser", req.PrimaryUser,
+		"standbyDbUniqueName", req.StandbyDbUniqueName,
+		"logicalStandbyDbName", req.LogicalStandbyDbName,
 	)
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return fmt.Errorf("config_agent_helpers/SetupDataGuard: failed to create database daemon dbdClient: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/CreateLogicalStandby: failed to create database daemon client: %w", err)
 	}
 	defer closeConn()
 
@@ -1605,33 +3069,80 @@ func SetUpDataGuard(ctx context.Context, r client.Reader, dbClientFactory Databa
 		User:             req.PrimaryUser,
 		PasswordAccessor: sa,
 	}
-
 	standbyDB := &standby.Standby{
+		CDBName:      req.StandbyCdbName,
 		DBUniqueName: req.StandbyDbUniqueName,
-		Host:         req.StandbyHost,
-		Port:         consts.SecureListenerPort,
 	}
 
-	if err := standby.SetUpDataGuard(ctx, primaryDB, standbyDB, req.PasswordFileGcsPath, dbClient); err != nil {
-		return fmt.Errorf("failed to set up Data Guard: %v", err)
+	unsupported, err := standby.CreateLogicalStandby(ctx, primaryDB, standbyDB, req.LogicalStandbyDbName, dbClient)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CreateLogicalStandby: failed to create logical standby: %v", err)
 	}
+	return unsupported, nil
+}
+
+type SetSQLApplyRequest struct {
+	// Start starts SQL Apply, otherwise it is stopped.
+	Start bool
+	// Abort rolls back the transactions being applied when stopping.
+	Abort bool
+}
+
+// SetSQLApply starts or stops SQL Apply on a logical standby.
+func SetSQLApply(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req SetSQLApplyRequest) error {
+	klog.InfoS("config_agent_helpers/SetSQLApply", "namespace", namespace, "instName", instName, "start", req.Start, "abort", req.Abort)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/SetSQLApply: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
 
+	if req.Start {
+		err = standby.StartSQLApply(ctx, dbClient)
+	} else {
+		err = standby.StopSQLApply(ctx, req.Abort, dbClient)
+	}
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/SetSQLApply: %v", err)
+	}
 	return nil
 }
 
-type PromoteStandbyRequest struct {
+type UpdateSQLApplySkipRulesRequest struct {
+	Skip   []standby.SkipRule
+	Unskip []standby.SkipRule
+}
+
+// UpdateSQLApplySkipRules adds and removes the SQL Apply skip rules of a
+// logical standby.
+func UpdateSQLApplySkipRules(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req UpdateSQLApplySkipRulesRequest) error {
+	klog.InfoS("config_agent_helpers/UpdateSQLApplySkipRules", "namespace", namespace, "instName", instName, "skip", req.Skip, "unskip", req.Unskip)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/UpdateSQLApplySkipRules: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	if err := standby.UpdateSkipRules(ctx, req.Skip, req.Unskip, dbClient); err != nil {
+		return fmt.Errorf("config_agent_helpers/UpdateSQLApplySkipRules: %v", err)
+	}
+	return nil
+}
+
+type RunDRDrillRequest struct {
 	PrimaryHost         string
 	PrimaryPort         int32
 	PrimaryService      string
 	PrimaryUser         string
 	PrimaryCredential   *Credential
 	StandbyDbUniqueName string
-	StandbyHost         string
+	ValidationQueries   []string
 }
 
-// PromoteStandby promotes standby database to primary.
-func PromoteStandby(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req PromoteStandbyRequest) error {
-	klog.InfoS("config_agent_helpers/PromoteStandby",
+// RunDRDrill switches a standby to the primary role, runs the validation
+// queries on it and switches the roles back.
+func RunDRDrill(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req RunDRDrillRequest) (*standby.DRDrillResult, error) {
+	klog.InfoS("config_agent_helpers/RunDRDrill",
 		"namespace", namespace,
 		"instName", instName,
 		"primaryHost", req.PrimaryHost,
@@ -1639,11 +3150,10 @@ func PromoteStandby(ctx context.Context, r client.Reader, dbClientFactory Databa
 		"primaryService", req.PrimaryService,
 		"primaryUser", req.PrimaryUser,
 		"standbyDbUniqueName", req.StandbyDbUniqueName,
-		"standbyHost", req.StandbyHost,
 	)
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return fmt.Errorf("config_agent_helpers/PromoteStandby: failed to create database daemon dbdClient: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/RunDRDrill: failed to create database daemon client: %w", err)
 	}
 	defer closeConn()
 
@@ -1661,58 +3171,97 @@ func PromoteStandby(ctx context.Context, r client.Reader, dbClientFactory Databa
 		User:             req.PrimaryUser,
 		PasswordAccessor: sa,
 	}
-
 	standbyDB := &standby.Standby{
 		DBUniqueName: req.StandbyDbUniqueName,
 	}
-
-	if err := standby.PromoteStandby(ctx, primaryDB, standbyDB, dbClient); err != nil {
-		return fmt.Errorf("failed to promote standby: %v", err)
-	}
-
-	return nil
+	return standby.RunDRDrill(ctx, primaryDB, standbyDB, req.ValidationQueries, dbClient), nil
 }
 
-type DataGuardStatusRequest struct {
+type CheckPrimaryHealthRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
 	StandbyDbUniqueName string
 }
 
-type DataGuardStatusResponse struct {
-	Output []string
-}
-
-// DataGuardStatus returns Data Guard configuration status and standby DB status.
-func DataGuardStatus(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req DataGuardStatusRequest) (*DataGuardStatusResponse, error) {
-	klog.InfoS("config_agent_helpers/DataGuardStatus", "namespace", namespace, "instName", instName, "standbyDbUniqueName", req.StandbyDbUniqueName)
+// CheckPrimaryHealth checks the primary of a standby from the standby, its
+// listener and the Data Guard configuration status.
+func CheckPrimaryHealth(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req CheckPrimaryHealthRequest) (*standby.PrimaryHealth, error) {
+	klog.InfoS("config_agent_helpers/CheckPrimaryHealth",
+		"namespace", namespace,
+		"instName", instName,
+		"primaryHost", req.PrimaryHost,
+		"primaryPort", req.PrimaryPort,
+		"primaryService", req.PrimaryService,
+		"standbyDbUniqueName", req.StandbyDbUniqueName,
+	)
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/DataGuardStatus: failed to create database daemon dbdClient: %v", err)
+		return nil, fmt.Errorf("config_agent_helpers/CheckPrimaryHealth: failed to create database daemon client: %w", err)
 	}
 	defer closeConn()
 
-	output, err := standby.DataGuardStatus(ctx, req.StandbyDbUniqueName, dbClient)
-	return &DataGuardStatusResponse{
-		Output: output,
-	}, err
+	primaryDB := &standby.Primary{
+		Host:    req.PrimaryHost,
+		Port:    int(req.PrimaryPort),
+		Service: req.PrimaryService,
+	}
+	standbyDB := &standby.Standby{
+		DBUniqueName: req.StandbyDbUniqueName,
+	}
+	return standby.CheckPrimaryHealth(ctx, primaryDB, standbyDB, dbClient)
 }
 
-type ApplyDataPatchRequest struct {
-	LroInput *LROInput
+type TransitionRoleRequest struct {
+	PrimaryHost         string
+	PrimaryPort         int32
+	PrimaryService      string
+	PrimaryUser         string
+	PrimaryCredential   *Credential
+	StandbyDbUniqueName string
+	// Switchover switches the roles without data loss, it needs the
+	// primary. Otherwise the standby fails over without the primary.
+	Switchover bool
 }
 
-// ApplyDataPatch calls dbdaemon->ApplyDataPatch()
-func ApplyDataPatch(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req ApplyDataPatchRequest) (*lropb.Operation, error) {
-	klog.InfoS("config_agent_helpersApplyDataPatch", "namespace", namespace, "instName", instName)
-
+// TransitionRole switches over or fails over to a standby.
+func TransitionRole(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string, req TransitionRoleRequest) error {
+	klog.InfoS("config_agent_helpers/TransitionRole",
+		"namespace", namespace,
+		"instName", instName,
+		"primaryHost", req.PrimaryHost,
+		"primaryPort", req.PrimaryPort,
+		"primaryService", req.PrimaryService,
+		"primaryUser", req.PrimaryUser,
+		"standbyDbUniqueName", req.StandbyDbUniqueName,
+		"switchover", req.Switchover,
+	)
 	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
 	if err != nil {
-		return nil, fmt.Errorf("config_agent_helpers/ApplyDataPatch: failed to create database daemon client: %w", err)
+		return fmt.Errorf("config_agent_helpers/TransitionRole: failed to create database daemon client: %w", err)
 	}
-	defer func() { _ = closeConn() }()
+	defer closeConn()
 
-	return dbClient.ApplyDataPatchAsync(ctx, &dbdpb.ApplyDataPatchAsyncRequest{
-		LroInput: &dbdpb.LROInput{
-			OperationId: req.LroInput.OperationId,
-		},
-	})
+	standbyDB := &standby.Standby{
+		DBUniqueName: req.StandbyDbUniqueName,
+	}
+	if !req.Switchover {
+		return standby.Failover(ctx, standbyDB, dbClient)
+	}
+
+	sa := secret.NewGSMSecretAccessor(
+		req.PrimaryCredential.GetGsmSecretReference().ProjectId,
+		req.PrimaryCredential.GetGsmSecretReference().SecretId,
+		req.PrimaryCredential.GetGsmSecretReference().Version,
+	)
+	defer sa.Clear()
+
+	primaryDB := &standby.Primary{
+		Host:             req.PrimaryHost,
+		Port:             int(req.PrimaryPort),
+		Service:          req.PrimaryService,
+		User:             req.PrimaryUser,
+		PasswordAccessor: sa,
+	}
+	return standby.Switchover(ctx, primaryDB, standbyDB, dbClient)
 }
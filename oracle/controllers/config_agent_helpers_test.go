@@ -0,0 +1,817 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/testing/protocmp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestExportParameters(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("ExportParameters", &dbdpb.ExportParametersResponse{
+		Parameters: []*dbdpb.ExportParametersResponse_Parameter{
+			{Name: "sga_target", Value: "2147483648"},
+			{Name: "processes", Value: "300", Static: true},
+			{Name: "open_cursors", Value: "500"},
+			{Name: "nls_date_format", Value: "YYYY-MM-DD HH24:MI:SS", StringType: true},
+			{Name: "utl_file_dir", Value: "/u02/app/o'dir", Static: true, StringType: true},
+			{Name: "control_files", Value: "/u02/app/oracle/oradata/GCLOUD/control01.ctl", Static: true, StringType: true},
+			{Name: "db_name", Value: "GCLOUD", Static: true, StringType: true},
+			{Name: "db_unique_name", Value: "GCLOUD_A", Static: true, StringType: true},
+			{Name: "log_archive_dest_1", Value: "LOCATION=USE_DB_RECOVERY_FILE_DEST", StringType: true},
+			{Name: "optimizer_index_cost", Value: "not valid"},
+		},
+	})
+
+	got, err := controllers.ExportParameters(context.Background(), nil, factory, "db", "source")
+	if err != nil {
+		t.Fatalf("ExportParameters failed: %v", err)
+	}
+	want := &controllers.ParameterManifest{
+		Static: map[string]string{
+			"processes":    "300",
+			"sga_target":   "2147483648",
+			"utl_file_dir": "/u02/app/o'dir",
+		},
+		Dynamic: map[string]string{
+			"nls_date_format": "YYYY-MM-DD HH24:MI:SS",
+			"open_cursors":    "500",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ExportParameters got unexpected manifest (-want +got):\n%v", diff)
+	}
+}
+
+func TestDiffParametersAgainstBaseline(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("DiffParametersAgainstBaseline", &dbdpb.DiffParametersAgainstBaselineResponse{
+		Added: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "db_name", CurrentValue: "GCLOUD", Static: true},
+			{Name: "processes", CurrentValue: "600", Static: true},
+		},
+		Removed: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "open_cursors", BaselineValue: "500", CurrentValue: "300"},
+		},
+		Changed: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "nls_date_format", BaselineValue: "YYYY-MM-DD", CurrentValue: "DD-MON-RR", Static: true},
+		},
+	})
+
+	got, err := controllers.DiffParametersAgainstBaseline(context.Background(), nil, factory, "db", "inst", &controllers.ParameterManifest{
+		Static:  map[string]string{"nls_date_format": "YYYY-MM-DD"},
+		Dynamic: map[string]string{"open_cursors": "500"},
+	})
+	if err != nil {
+		t.Fatalf("DiffParametersAgainstBaseline failed: %v", err)
+	}
+	want := &controllers.ParameterDiff{
+		Added:   []controllers.ParameterChange{{Name: "processes", CurrentValue: "600", Static: true}},
+		Removed: []controllers.ParameterChange{{Name: "open_cursors", BaselineValue: "500", CurrentValue: "300"}},
+		Changed: []controllers.ParameterChange{{Name: "nls_date_format", BaselineValue: "YYYY-MM-DD", CurrentValue: "DD-MON-RR", Static: true}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffParametersAgainstBaseline got unexpected diff (-want +got):\n%v", diff)
+	}
+
+	wantBaseline := &dbdpb.ExportParametersResponse{
+		Parameters: []*dbdpb.ExportParametersResponse_Parameter{
+			{Name: "nls_date_format", Value: "YYYY-MM-DD", Static: true},
+			{Name: "open_cursors", Value: "500"},
+		},
+	}
+	if diff := cmp.Diff(wantBaseline, factory.Dbclient.GotDiffParametersAgainstBaselineRequest.GetBaseline(), protocmp.Transform()); diff != "" {
+		t.Errorf("DiffParametersAgainstBaseline sent unexpected baseline (-want +got):\n%v", diff)
+	}
+}
+
+func TestSetParameter(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		value      string
+		queryValue string
+		wantStatic bool
+		wantCmd    string
+	}{
+		{
+			name:       "dynamic parameter",
+			key:        "open_cursors",
+			value:      "500",
+			queryValue: "IMMEDIATE",
+			wantCmd:    "alter system set open_cursors=500",
+		},
+		{
+			name:       "static parameter",
+			key:        "processes",
+			value:      "300",
+			queryValue: "FALSE",
+			wantStatic: true,
+			wantCmd:    "alter system set processes=300 scope=spfile",
+		},
+		{
+			name:       "static parameter by override",
+			key:        "sga_target",
+			value:      "2147483648",
+			queryValue: "IMMEDIATE",
+			wantStatic: true,
+			wantCmd:    "alter system set sga_target=2147483648 scope=spfile",
+		},
+		{
+			// The type query returns "2" as well, string values are quoted.
+			name:       "string parameter",
+			key:        "utl_file_dir",
+			value:      "/u02/app/o'dir",
+			queryValue: "2",
+			wantCmd:    "alter system set utl_file_dir='/u02/app/o''dir'",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			// The issys_modifiable and type queries get the same row.
+			factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{
+				Msg: []string{fmt.Sprintf(`{"VALUE": %q}`, tc.queryValue)},
+			})
+
+			static, err := controllers.SetParameter(context.Background(), factory, nil, "db", "inst", tc.key, tc.value)
+			if err != nil {
+				t.Fatalf("SetParameter(%s=%s) failed: %v", tc.key, tc.value, err)
+			}
+			if static != tc.wantStatic {
+				t.Errorf("SetParameter(%s=%s) static=%v, want %v", tc.key, tc.value, static, tc.wantStatic)
+			}
+			if got := factory.Dbclient.GotRunSQLPlusRequest.GetCommands(); !cmp.Equal(got, []string{tc.wantCmd}) {
+				t.Errorf("SetParameter(%s=%s) ran %q, want %q", tc.key, tc.value, got, tc.wantCmd)
+			}
+		})
+	}
+}
+
+func TestBootstrapDatabaseOratab(t *testing.T) {
+	tests := []struct {
+		name    string
+		oratab  *dbdpb.ValidateOratabResponse
+		wantErr string
+	}{
+		{
+			name:    "malformed",
+			oratab:  &dbdpb.ValidateOratabResponse{Issues: []string{`line 2: unknown database type "ORACLE_EXPRESS"`}},
+			wantErr: "ORACLE_EXPRESS",
+		},
+		{
+			name:   "missing",
+			oratab: &dbdpb.ValidateOratabResponse{DatabaseTypeMissing: true, Warnings: []string{"/etc/oratab not found"}},
+		},
+		{
+			name:   "empty",
+			oratab: &dbdpb.ValidateOratabResponse{DatabaseTypeMissing: true, Warnings: []string{"no database entries found"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("ValidateOratab", tc.oratab)
+
+			_, err := controllers.BootstrapDatabase(context.Background(), nil, factory, "db", "inst", controllers.BootstrapDatabaseRequest{
+				CdbName: "GCLOUD",
+				Mode:    controllers.BootstrapDatabaseRequest_Restore,
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("BootstrapDatabase got error %v, want the oratab issues", err)
+				}
+				if got := factory.Dbclient.CreateListenerCalledCnt(); got != 0 {
+					t.Errorf("BootstrapDatabase called CreateListener %d times, want 0", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BootstrapDatabase failed: %v", err)
+			}
+			if got := factory.Dbclient.CreateFileCalledCnt(); got != 1 {
+				t.Errorf("BootstrapDatabase called CreateFile %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestVerifyPhysicalBackupReportsMissingPermissions(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToError("DownloadDirectoryFromGCS", fmt.Errorf(`Bucket("bucket").Objects(): googleapi: Error 403: access denied`))
+	factory.Dbclient.SetMethodToResp("CheckStoragePermissions", &dbdpb.CheckStoragePermissionsResponse{
+		Permissions: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+			{Name: "list", IamPermission: "storage.objects.list", Error: "googleapi: Error 403: access denied"},
+			{Name: "read", IamPermission: "storage.objects.get", Skipped: true, Error: "no object to read under the prefix"},
+			{Name: "write", IamPermission: "storage.objects.create", Skipped: true, Error: "read only check"},
+			{Name: "delete", IamPermission: "storage.objects.delete", Skipped: true, Error: "read only check"},
+		},
+	})
+	resp, err := controllers.VerifyPhysicalBackup(context.Background(), nil, factory, "db", "inst", controllers.VerifyPhysicalBackupRequest{GcsPath: "gs://bucket/backup"})
+	if err != nil {
+		t.Fatalf("VerifyPhysicalBackup failed: %v", err)
+	}
+	if got := factory.Dbclient.GotCheckStoragePermissionsRequest; !got.GetReadOnly() {
+		t.Errorf("VerifyPhysicalBackup checked storage permissions with %v, want a read only check", got)
+	}
+	want := []string{
+		`Bucket("bucket").Objects(): googleapi: Error 403: access denied`,
+		"missing storage.objects.list permission on gs://bucket/backup: googleapi: Error 403: access denied",
+	}
+	if diff := cmp.Diff(want, resp.ErrMsgs); diff != "" {
+		t.Errorf("VerifyPhysicalBackup got unexpected error messages (-want +got):\n%v", diff)
+	}
+}
+
+func TestPhysicalBackupPDBs(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	if _, err := controllers.PhysicalBackup(context.Background(), nil, factory, "db", "inst", controllers.PhysicalBackupRequest{
+		BackupSubType: controllers.PhysicalBackupRequest_DATABASE,
+		BackupItems:   []string{"pdb1", "Pdb2"},
+		Backupset:     true,
+		Dop:           1,
+		LroInput:      &controllers.LROInput{OperationId: "op"},
+	}); err != nil {
+		t.Fatalf("PhysicalBackup failed: %v", err)
+	}
+	script := factory.Dbclient.GotRMANAsyncRequest.GetSyncRequest().GetScripts()
+	if len(script) != 1 || !strings.Contains(script[0], "(pluggable database PDB1, PDB2)") {
+		t.Errorf("PhysicalBackup ran %v, want a backup of the PDB1 and PDB2 pluggable databases", script)
+	}
+
+	if _, err := controllers.PhysicalBackup(context.Background(), nil, factory, "db", "inst", controllers.PhysicalBackupRequest{
+		BackupSubType: controllers.PhysicalBackupRequest_DATABASE,
+		BackupItems:   []string{},
+		LroInput:      &controllers.LROInput{OperationId: "op"},
+	}); err == nil {
+		t.Errorf("PhysicalBackup without PDBs succeeded, want an error")
+	}
+}
+
+func TestUpdateUsersPartialFailure(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	// Every query returns ALICE as the only user, granted the CONNECT role
+	// and the CREATE SESSION privilege.
+	factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{
+		Msg: []string{`{"USERNAME": "ALICE", "ROLE": "CONNECT", "PRIVILEGE": "CREATE SESSION", "GRANTED_ROLE": "CONNECT"}`},
+	})
+	factory.Dbclient.RunSQLPlusFunc = func(in *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+		for _, cmd := range in.GetCommands() {
+			if strings.Contains(cmd, "CAROL") {
+				return nil, fmt.Errorf("ORA-01920: user name 'CAROL' conflicts with another user or role name")
+			}
+		}
+		return &dbdpb.RunCMDResponse{}, nil
+	}
+
+	resp, err := controllers.UpdateUsers(context.Background(), nil, factory, "db", "inst", controllers.UpdateUsersRequest{
+		PdbName: "pdb1",
+		UserSpecs: []*controllers.User{
+			{Name: "alice", Privileges: []string{"connect", "create session", "create table"}},
+			{Name: "bob", Password: "bob_pwd", Privileges: []string{"connect"}},
+			{Name: "carol", Password: "carol_pwd", Privileges: []string{"connect"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUsers failed: %v", err)
+	}
+	want := &controllers.UpdateUsersResponse{
+		Succeeded: []string{"ALICE", "BOB"},
+		Failed: []*controllers.UpdateUsersResponseFailure{
+			{UserName: "CAROL"},
+		},
+	}
+	if diff := cmp.Diff(want, resp, cmpopts.IgnoreFields(controllers.UpdateUsersResponseFailure{}, "Error")); diff != "" {
+		t.Errorf("UpdateUsers got unexpected response (-want +got):\n%v", diff)
+	}
+	if len(resp.Failed) == 1 && !strings.Contains(resp.Failed[0].Error, "ORA-01920") {
+		t.Errorf("UpdateUsers reported CAROL failing with %q, want the ORA-01920 error", resp.Failed[0].Error)
+	}
+}
+
+func TestUpdateUsersPasswordPolicy(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{
+		Msg: []string{`{"USERNAME": "ALICE", "ROLE": "CONNECT", "PRIVILEGE": "CREATE SESSION", "GRANTED_ROLE": "CONNECT"}`},
+	})
+	factory.Dbclient.RunSQLPlusFunc = func(in *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+		return nil, fmt.Errorf("failed to run %q: ORA-28003: password verification for the specified password failed\nORA-20001: Password length less than 8", in.GetCommands())
+	}
+
+	resp, err := controllers.UpdateUsers(context.Background(), nil, factory, "db", "inst", controllers.UpdateUsersRequest{
+		PdbName: "pdb1",
+		UserSpecs: []*controllers.User{
+			{Name: "alice", Password: "short", LastPassword: "long_enough", Privileges: []string{"connect", "create session"}},
+			{Name: "bob", Password: "weak", Privileges: []string{"connect"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUsers failed: %v", err)
+	}
+	if len(resp.Failed) != 2 {
+		t.Fatalf("UpdateUsers got failures %v, want ALICE and BOB", resp.Failed)
+	}
+	for _, f := range resp.Failed {
+		if !strings.Contains(f.Error, "doesn't meet the password policy") {
+			t.Errorf("UpdateUsers reported %s failing with %q, want the password policy error", f.UserName, f.Error)
+		}
+		for _, pwd := range []string{"short", "weak"} {
+			if strings.Contains(f.Error, pwd) {
+				t.Errorf("UpdateUsers reported %s failing with %q, which contains the password %q", f.UserName, f.Error, pwd)
+			}
+		}
+	}
+}
+
+func TestUsersQuotaDrift(t *testing.T) {
+	tests := []struct {
+		name        string
+		quotas      []controllers.UserQuota
+		wantChanged bool
+		wantQuotas  []*dbdpb.SetUserQuotaRequest_Quota
+	}{
+		{
+			name:   "no quotas",
+			quotas: nil,
+		},
+		{
+			name:   "in sync",
+			quotas: []controllers.UserQuota{{Tablespace: "users", MaxBytes: 10 << 20}},
+		},
+		{
+			name:   "rounded to blocks",
+			quotas: []controllers.UserQuota{{Tablespace: "USERS", MaxBytes: 10<<20 - 100}},
+		},
+		{
+			name:        "larger quota",
+			quotas:      []controllers.UserQuota{{Tablespace: "USERS", MaxBytes: 20 << 20}},
+			wantChanged: true,
+			wantQuotas:  []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: "USERS", MaxBytes: 20 << 20}},
+		},
+		{
+			name:        "unlimited quota",
+			quotas:      []controllers.UserQuota{{Tablespace: "USERS", MaxBytes: -1}},
+			wantChanged: true,
+			wantQuotas:  []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: "USERS", MaxBytes: -1}},
+		},
+		{
+			name:        "quota on another tablespace",
+			quotas:      []controllers.UserQuota{{Tablespace: "USERS", MaxBytes: 10 << 20}, {Tablespace: "DATA", MaxBytes: 1 << 30}},
+			wantChanged: true,
+			wantQuotas:  []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: "DATA", MaxBytes: 1 << 30}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			// Every query returns ALICE as the only user, granted the CONNECT
+			// role and the CREATE SESSION privilege, with a quota of 10 MiB on the
+			// USERS tablespace.
+			factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{
+				Msg: []string{`{"USERNAME": "ALICE", "ROLE": "CONNECT", "PRIVILEGE": "CREATE SESSION", "GRANTED_ROLE": "CONNECT", "TABLESPACE_NAME": "USERS", "MAX_BYTES": "10485760", "BLOCK_SIZE": "8192"}`},
+			})
+			users := []*controllers.User{{Name: "alice", Privileges: []string{"connect", "create session"}, Quotas: tc.quotas}}
+
+			changed, err := controllers.UsersChanged(context.Background(), nil, factory, "db", "inst", controllers.UsersChangedRequest{PdbName: "pdb1", UserSpecs: users})
+			if err != nil {
+				t.Fatalf("UsersChanged failed: %v", err)
+			}
+			if changed.Changed != tc.wantChanged {
+				t.Errorf("UsersChanged got changed %v, want %v", changed.Changed, tc.wantChanged)
+			}
+
+			if _, err := controllers.UpdateUsers(context.Background(), nil, factory, "db", "inst", controllers.UpdateUsersRequest{PdbName: "pdb1", UserSpecs: users}); err != nil {
+				t.Fatalf("UpdateUsers failed: %v", err)
+			}
+			var want []*dbdpb.SetUserQuotaRequest
+			if tc.wantQuotas != nil {
+				want = []*dbdpb.SetUserQuotaRequest{{PdbName: "PDB1", UserName: "ALICE", Quotas: tc.wantQuotas}}
+			}
+			if diff := cmp.Diff(want, factory.Dbclient.GotSetUserQuotaRequests, protocmp.Transform()); diff != "" {
+				t.Errorf("UpdateUsers got unexpected SetUserQuota requests (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestRotateWalletPassword(t *testing.T) {
+	ref := controllers.GsmSecretReference{ProjectId: "p", SecretId: "tde-password", Version: "3"}
+	tests := []struct {
+		name        string
+		addErr      error
+		rotateErr   error
+		want        string
+		wantErr     bool
+		wantRotated []*dbdpb.RotateWalletPasswordRequest
+		wantAdded   []string
+	}{
+		{
+			name: "secret updated",
+			want: "projects/p/secrets/tde-password/versions/4",
+			wantRotated: []*dbdpb.RotateWalletPasswordRequest{
+				{CurrentPassword: "Old_pw1", NewPassword: "New_pw2"},
+			},
+			wantAdded: []string{"projects/p/secrets/tde-password=New_pw2"},
+		},
+		{
+			name:    "secret update fails",
+			addErr:  fmt.Errorf("permission denied"),
+			wantErr: true,
+			wantRotated: []*dbdpb.RotateWalletPasswordRequest{
+				{CurrentPassword: "Old_pw1", NewPassword: "New_pw2"},
+				{CurrentPassword: "New_pw2", NewPassword: "Old_pw1"},
+			},
+			wantAdded: []string{"projects/p/secrets/tde-password=New_pw2"},
+		},
+		{
+			name:      "keystore password change fails",
+			rotateErr: fmt.Errorf("ORA-28353: failed to open wallet"),
+			wantErr:   true,
+			wantRotated: []*dbdpb.RotateWalletPasswordRequest{
+				{CurrentPassword: "Old_pw1", NewPassword: "New_pw2"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.rotateErr != nil {
+				factory.Dbclient.SetMethodToError("RotateWalletPassword", tc.rotateErr)
+			}
+			var added []string
+			access, addVersion := controllers.AccessSecretVersionFunc, controllers.AddSecretVersionFunc
+			defer func() {
+				controllers.AccessSecretVersionFunc, controllers.AddSecretVersionFunc = access, addVersion
+			}()
+			controllers.AccessSecretVersionFunc = func(ctx context.Context, name string) (string, error) {
+				if name != "projects/p/secrets/tde-password/versions/3" {
+					t.Fatalf("AccessSecretVersionFunc got unexpected secret version %q", name)
+				}
+				return "Old_pw1", nil
+			}
+			controllers.AddSecretVersionFunc = func(ctx context.Context, secret, payload string) (string, error) {
+				added = append(added, secret+"="+payload)
+				return secret + "/versions/4", tc.addErr
+			}
+
+			got, err := controllers.RotateWalletPassword(context.Background(), nil, factory, "db", "inst", controllers.RotateWalletPasswordRequest{PasswordRef: ref, NewPassword: "New_pw2"})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("RotateWalletPassword got error %v, want error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && got != tc.want {
+				t.Errorf("RotateWalletPassword = %q, want %q", got, tc.want)
+			}
+			if diff := cmp.Diff(tc.wantRotated, factory.Dbclient.GotRotateWalletPasswordRequests, protocmp.Transform()); diff != "" {
+				t.Errorf("RotateWalletPassword requests got unexpected result (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantAdded, added); diff != "" {
+				t.Errorf("AddSecretVersionFunc calls got unexpected result (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCheckStatusKeystore(t *testing.T) {
+	tests := []struct {
+		name           string
+		rows           []string
+		wantStatus     string
+		wantWalletType string
+	}{
+		{
+			name: "all open",
+			rows: []string{
+				`{"NAME":"CDB$ROOT","WRL_PARAMETER":"/u02/app/oracle/keystore/tde/","WALLET_TYPE":"AUTOLOGIN","STATUS":"OPEN"}`,
+				`{"NAME":"PDB1","WRL_PARAMETER":"","WALLET_TYPE":"AUTOLOGIN","STATUS":"OPEN"}`,
+			},
+			wantStatus:     "OPEN",
+			wantWalletType: "AUTOLOGIN",
+		},
+		{
+			name: "PDB without master key",
+			rows: []string{
+				`{"NAME":"CDB$ROOT","WRL_PARAMETER":"/u02/app/oracle/keystore/tde/","WALLET_TYPE":"PASSWORD","STATUS":"OPEN"}`,
+				`{"NAME":"PDB1","WRL_PARAMETER":"","WALLET_TYPE":"PASSWORD","STATUS":"OPEN_NO_MASTER_KEY"}`,
+			},
+			wantStatus:     "OPEN_NO_MASTER_KEY",
+			wantWalletType: "PASSWORD",
+		},
+		{
+			name: "closed",
+			rows: []string{
+				`{"NAME":"CDB$ROOT","WRL_PARAMETER":"/u02/app/oracle/keystore/tde/","WALLET_TYPE":"UNKNOWN","STATUS":"CLOSED"}`,
+				`{"NAME":"PDB1","WRL_PARAMETER":"","WALLET_TYPE":"UNKNOWN","STATUS":"CLOSED"}`,
+			},
+			wantStatus:     "CLOSED",
+			wantWalletType: "UNKNOWN",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("FileExists", &dbdpb.FileExistsResponse{Exists: true})
+			factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{Msg: tc.rows})
+
+			got, err := controllers.CheckStatus(context.Background(), nil, factory, "db", "inst", controllers.CheckStatusRequest{
+				CheckStatusType: controllers.CheckStatusRequest_INSTANCE,
+				VerifyKeystore:  true,
+			})
+			if err != nil {
+				t.Fatalf("CheckStatus failed: %v", err)
+			}
+			if got.Status != "Ready" || got.KeystoreStatus != tc.wantStatus || got.KeystoreWalletType != tc.wantWalletType {
+				t.Errorf("CheckStatus got status %q, keystore %q of type %q, want Ready, %q of type %q", got.Status, got.KeystoreStatus, got.KeystoreWalletType, tc.wantStatus, tc.wantWalletType)
+			}
+		})
+	}
+}
+
+func TestSetupKeystore(t *testing.T) {
+	ref := controllers.GsmSecretReference{ProjectId: "p", SecretId: "tde-password", Version: "1"}
+	tests := []struct {
+		name              string
+		configureResp     *dbdpb.ConfigureKeystoreResponse
+		want              *controllers.SetupKeystoreResponse
+		wantSetMasterKeys int
+	}{
+		{
+			name:          "restart required",
+			configureResp: &dbdpb.ConfigureKeystoreResponse{Status: "NOT_AVAILABLE", RestartRequired: true},
+			want:          &controllers.SetupKeystoreResponse{Status: "NOT_AVAILABLE", RestartRequired: true},
+		},
+		{
+			name:          "created",
+			configureResp: &dbdpb.ConfigureKeystoreResponse{KeystoreLocation: "/u02/app/oracle/keystore/tde/", WalletType: "PASSWORD", Status: "OPEN_NO_MASTER_KEY", Created: true, Opened: true},
+			want: &controllers.SetupKeystoreResponse{
+				Location:            "/u02/app/oracle/keystore/tde/",
+				WalletType:          "PASSWORD",
+				Status:              "OPEN",
+				Created:             true,
+				MasterKeyContainers: []string{"CDB$ROOT", "PDB1"},
+			},
+			wantSetMasterKeys: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("ConfigureKeystore", tc.configureResp)
+			factory.Dbclient.SetMethodToResp("SetMasterKey", &dbdpb.SetMasterKeyResponse{Containers: []string{"CDB$ROOT", "PDB1"}, Status: "OPEN"})
+			access := controllers.AccessSecretVersionFunc
+			defer func() { controllers.AccessSecretVersionFunc = access }()
+			controllers.AccessSecretVersionFunc = func(ctx context.Context, name string) (string, error) {
+				if name != "projects/p/secrets/tde-password/versions/1" {
+					t.Fatalf("AccessSecretVersionFunc got unexpected secret version %q", name)
+				}
+				return "Tde_pw1", nil
+			}
+
+			got, err := controllers.SetupKeystore(context.Background(), nil, factory, "db", "inst", controllers.SetupKeystoreRequest{PasswordRef: ref, AutoLogin: true})
+			if err != nil {
+				t.Fatalf("SetupKeystore failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("SetupKeystore got unexpected response (-want +got):\n%v", diff)
+			}
+			wantReq := &dbdpb.ConfigureKeystoreRequest{KeystorePassword: "Tde_pw1", AutoLogin: true}
+			if diff := cmp.Diff(wantReq, factory.Dbclient.GotConfigureKeystoreRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("SetupKeystore got unexpected ConfigureKeystore request (-want +got):\n%v", diff)
+			}
+			if got := factory.Dbclient.SetMasterKeyCalledCnt(); got != tc.wantSetMasterKeys {
+				t.Errorf("SetupKeystore called SetMasterKey %d times, want %d", got, tc.wantSetMasterKeys)
+			}
+		})
+	}
+}
+
+func TestDataPumpImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     controllers.DataPumpImportRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "dump file",
+			req: controllers.DataPumpImportRequest{
+				GcsPath:          "gs://bucket/export.dmp",
+				RemapSchemas:     map[string]string{"scott": "scott_test", "HR": "HR_TEST"},
+				RemapTablespaces: map[string]string{"USERS": "APP_DATA"},
+				Includes:         []string{`TABLE:"IN ('EMPLOYEES')"`},
+				Options:          map[string]string{"parallel": "2", "logfile": "x.log"},
+			},
+			want: []string{
+				"FULL=YES",
+				"METRICS=YES",
+				"LOGTIME=ALL",
+				"REMAP_SCHEMA=HR:HR_TEST",
+				"REMAP_SCHEMA=SCOTT:SCOTT_TEST",
+				"REMAP_TABLESPACE=USERS:APP_DATA",
+				`INCLUDE=TABLE:"IN ('EMPLOYEES')"`,
+				"PARALLEL=2",
+			},
+		},
+		{
+			name: "network link",
+			req: controllers.DataPumpImportRequest{
+				NetworkLink: "src_link.example.com",
+				Excludes:    []string{"STATISTICS"},
+			},
+			want: []string{
+				"FULL=YES",
+				"METRICS=YES",
+				"LOGTIME=ALL",
+				"NETWORK_LINK=SRC_LINK.EXAMPLE.COM",
+				"EXCLUDE=STATISTICS",
+			},
+		},
+		{
+			name:    "no source",
+			req:     controllers.DataPumpImportRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "dump file and network link",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", NetworkLink: "src_link"},
+			wantErr: true,
+		},
+		{
+			name:    "network link in the options too",
+			req:     controllers.DataPumpImportRequest{NetworkLink: "src_link", Options: map[string]string{"NETWORK_LINK": "other"}},
+			wantErr: true,
+		},
+		{
+			name:    "includes and excludes",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", Includes: []string{"TABLE"}, Excludes: []string{"STATISTICS"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid schema remap",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", RemapSchemas: map[string]string{"HR": "HR:X"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid network link",
+			req:     controllers.DataPumpImportRequest{NetworkLink: "src link"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid filter",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", Excludes: []string{"SCHEMA:\"='HR'\"\nFULL=NO"}},
+			wantErr: true,
+		},
+		{
+			name:    "filter with another parameter",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", Includes: []string{`TABLE:"='X'" PARFILE=/some/file`}},
+			wantErr: true,
+		},
+		{
+			name:    "filter with an unquoted name clause",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", Excludes: []string{`TABLE:='X' PARFILE=/some/file`}},
+			wantErr: true,
+		},
+		{
+			name:    "filter with whitespace in the object type",
+			req:     controllers.DataPumpImportRequest{GcsPath: "gs://bucket/export.dmp", Excludes: []string{"STATISTICS PARFILE=/some/file"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			tc.req.PdbName = "PDB1"
+			tc.req.LroInput = &controllers.LROInput{OperationId: "Import_1"}
+
+			_, err := controllers.DataPumpImport(context.Background(), nil, factory, "db", "inst", tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("DataPumpImport got error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				if got := factory.Dbclient.DataPumpImportAsyncCalledCnt(); got != 0 {
+					t.Errorf("DataPumpImport of an invalid request called DataPumpImportAsync %d times, want 0", got)
+				}
+				return
+			}
+			got := factory.Dbclient.GotDataPumpImportAsyncRequest.GetSyncRequest().GetCommandParams()
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DataPumpImport got unexpected command params (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+// instanceClientFactory returns the fake database client of each instance.
+type instanceClientFactory map[string]*testhelpers.FakeDatabaseClient
+
+func (f instanceClientFactory) New(ctx context.Context, r client.Reader, namespace, instName string) (dbdpb.DatabaseDaemonClient, func() error, error) {
+	dbClient, ok := f[instName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown instance %s", instName)
+	}
+	return dbClient, func() error { return nil }, nil
+}
+
+func newConfigClient(version string, params map[string]string, patchActions ...string) *testhelpers.FakeDatabaseClient {
+	dbClient := &testhelpers.FakeDatabaseClient{}
+	dbClient.SetMethodToResp("GetInstanceInfo", &dbdpb.GetInstanceInfoResponse{Version: version})
+	exported := &dbdpb.ExportParametersResponse{}
+	for name, value := range params {
+		exported.Parameters = append(exported.Parameters, &dbdpb.ExportParametersResponse_Parameter{Name: name, Value: value})
+	}
+	dbClient.SetMethodToResp("ExportParameters", exported)
+	inventory := &dbdpb.RunCMDResponse{}
+	for _, a := range patchActions {
+		id, action, _ := strings.Cut(a, ":")
+		inventory.Msg = append(inventory.Msg, fmt.Sprintf(`{"PATCH_ID":%q,"ACTION":%q}`, id, action))
+	}
+	dbClient.SetMethodToResp("RunSQLPlusFormatted", inventory)
+	return dbClient
+}
+
+func TestCompareInstanceConfig(t *testing.T) {
+	factory := instanceClientFactory{
+		"mydb": newConfigClient("19.3.0.0.0",
+			map[string]string{"db_name": "GCLOUD", "open_cursors": "300", "sga_target": "2147483648"},
+			"29517242:APPLY", "29585399:APPLY"),
+		"mydb2": newConfigClient("19.3.0.0.0",
+			map[string]string{"db_name": "GCLOUD2", "open_cursors": "500", "sga_target": "2147483648"},
+			"29517242:APPLY", "29585399:APPLY", "29585399:ROLLBACK", "30125133:APPLY"),
+		"mydb3": newConfigClient("19.3.0.0.0",
+			map[string]string{"open_cursors": "300", "sga_target": "2147483648"},
+			"29517242:APPLY", "29585399:APPLY"),
+	}
+
+	tests := []struct {
+		name      string
+		otherInst string
+		want      []controllers.ConfigDrift
+		wantErr   bool
+	}{
+		{
+			name:      "drift",
+			otherInst: "mydb2",
+			want: []controllers.ConfigDrift{
+				{Kind: "parameter", Name: "open_cursors", Value: "300", OtherValue: "500"},
+				{Kind: "patch", Name: "29585399", Value: "installed"},
+				{Kind: "patch", Name: "30125133", OtherValue: "installed"},
+			},
+		},
+		{
+			name:      "no drift",
+			otherInst: "mydb3",
+		},
+		{
+			name:      "unknown instance",
+			otherInst: "mydb4",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := controllers.CompareInstanceConfig(context.Background(), nil, factory, "db", "mydb", tc.otherInst)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CompareInstanceConfig got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CompareInstanceConfig got unexpected drift (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
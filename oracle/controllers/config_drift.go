@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// patchInventorySQL lists the SQL patch actions in the order they were
+// applied, the last action of a patch tells whether it is installed.
+const patchInventorySQL = "select patch_id, action from dba_registry_sqlpatch where status='SUCCESS' order by action_time"
+
+// InstanceConfig is the configuration of an instance fleets are expected to
+// keep uniform.
+type InstanceConfig struct {
+	Version    string
+	Parameters map[string]string
+	// Patches are the IDs of the installed SQL patches.
+	Patches []string
+}
+
+// ConfigDrift is a difference between the configurations of two instances.
+// A value is empty if the setting is missing on the instance.
+type ConfigDrift struct {
+	// Kind is one of version, parameter or patch.
+	Kind       string
+	Name       string
+	Value      string
+	OtherValue string
+}
+
+func (d ConfigDrift) String() string {
+	return fmt.Sprintf("%s %s: %q != %q", d.Kind, d.Name, d.Value, d.OtherValue)
+}
+
+// FetchPatchInventory returns the IDs of the SQL patches installed in the
+// database.
+func FetchPatchInventory(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) ([]string, error) {
+	klog.InfoS("config_agent_helpers/FetchPatchInventory", "namespace", namespace, "instName", instName)
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPatchInventory: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	resp, err := dbClient.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{patchInventorySQL}})
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPatchInventory: failed to query the patch inventory: %v", err)
+	}
+	rows, err := parseSQLResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/FetchPatchInventory: %v", err)
+	}
+	installed := make(map[string]bool)
+	for _, row := range rows {
+		installed[row["PATCH_ID"]] = strings.EqualFold(row["ACTION"], "APPLY")
+	}
+	var patches []string
+	for id, ok := range installed {
+		if ok {
+			patches = append(patches, id)
+		}
+	}
+	sort.Strings(patches)
+	return patches, nil
+}
+
+// FetchInstanceConfig returns the configuration of the instance compared by
+// CompareInstanceConfig.
+func FetchInstanceConfig(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName string) (*InstanceConfig, error) {
+	info, err := FetchInstanceInfo(ctx, r, dbClientFactory, namespace, instName)
+	if err != nil {
+		return nil, err
+	}
+	params, err := ExportParameters(ctx, r, dbClientFactory, namespace, instName)
+	if err != nil {
+		return nil, err
+	}
+	patches, err := FetchPatchInventory(ctx, r, dbClientFactory, namespace, instName)
+	if err != nil {
+		return nil, err
+	}
+	return &InstanceConfig{Version: info.Version, Parameters: params.Parameters(), Patches: patches}, nil
+}
+
+// DiffInstanceConfig returns the differences between two instance
+// configurations, sorted by kind and name.
+func DiffInstanceConfig(config, other *InstanceConfig) []ConfigDrift {
+	var drifts []ConfigDrift
+	if config.Version != other.Version {
+		drifts = append(drifts, ConfigDrift{Kind: "version", Name: "version", Value: config.Version, OtherValue: other.Version})
+	}
+
+	var params []string
+	for name := range config.Parameters {
+		params = append(params, name)
+	}
+	for name := range other.Parameters {
+		if _, ok := config.Parameters[name]; !ok {
+			params = append(params, name)
+		}
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		if v, otherV := config.Parameters[name], other.Parameters[name]; v != otherV {
+			drifts = append(drifts, ConfigDrift{Kind: "parameter", Name: name, Value: v, OtherValue: otherV})
+		}
+	}
+
+	toAdd, _, toRemove := compare(config.Patches, other.Patches)
+	var patches []ConfigDrift
+	for _, id := range toAdd {
+		patches = append(patches, ConfigDrift{Kind: "patch", Name: id, Value: "installed"})
+	}
+	for _, id := range toRemove {
+		patches = append(patches, ConfigDrift{Kind: "patch", Name: id, OtherValue: "installed"})
+	}
+	sort.Slice(patches, func(i, j int) bool { return patches[i].Name < patches[j].Name })
+	return append(drifts, patches...)
+}
+
+// CompareInstanceConfig returns the configuration drift between two instances
+// of the namespace.
+func CompareInstanceConfig(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, namespace, instName, otherInstName string) ([]ConfigDrift, error) {
+	klog.InfoS("config_agent_helpers/CompareInstanceConfig", "namespace", namespace, "instName", instName, "otherInstName", otherInstName)
+	config, err := FetchInstanceConfig(ctx, r, dbClientFactory, namespace, instName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CompareInstanceConfig: failed to fetch the configuration of %s: %v", instName, err)
+	}
+	other, err := FetchInstanceConfig(ctx, r, dbClientFactory, namespace, otherInstName)
+	if err != nil {
+		return nil, fmt.Errorf("config_agent_helpers/CompareInstanceConfig: failed to fetch the configuration of %s: %v", otherInstName, err)
+	}
+	return DiffInstanceConfig(config, other), nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffInstanceConfig(t *testing.T) {
+	config := &InstanceConfig{
+		Version: "19.3.0.0.0",
+		Parameters: map[string]string{
+			"open_cursors": "300",
+			"processes":    "300",
+			"sga_target":   "2147483648",
+		},
+		Patches: []string{"29517242", "29585399"},
+	}
+	tests := []struct {
+		name  string
+		other *InstanceConfig
+		want  []ConfigDrift
+	}{
+		{
+			name: "same",
+			other: &InstanceConfig{
+				Version: "19.3.0.0.0",
+				Parameters: map[string]string{
+					"open_cursors": "300",
+					"processes":    "300",
+					"sga_target":   "2147483648",
+				},
+				Patches: []string{"29517242", "29585399"},
+			},
+		},
+		{
+			name: "drifted",
+			other: &InstanceConfig{
+				Version: "19.10.0.0.0",
+				Parameters: map[string]string{
+					"open_cursors":    "500",
+					"processes":       "300",
+					"nls_date_format": "YYYY-MM-DD",
+				},
+				Patches: []string{"29517242", "32218454"},
+			},
+			want: []ConfigDrift{
+				{Kind: "version", Name: "version", Value: "19.3.0.0.0", OtherValue: "19.10.0.0.0"},
+				{Kind: "parameter", Name: "nls_date_format", OtherValue: "YYYY-MM-DD"},
+				{Kind: "parameter", Name: "open_cursors", Value: "300", OtherValue: "500"},
+				{Kind: "parameter", Name: "sga_target", Value: "2147483648"},
+				{Kind: "patch", Name: "29585399", Value: "installed"},
+				{Kind: "patch", Name: "32218454", OtherValue: "installed"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := DiffInstanceConfig(config, tc.other)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DiffInstanceConfig got unexpected drift (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
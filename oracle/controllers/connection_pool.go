@@ -0,0 +1,239 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+)
+
+const (
+	// ConnectionPoolConfigHashAnnotation is set on the pooler pods to the
+	// hash of their configuration, so that its changes roll them.
+	ConnectionPoolConfigHashAnnotation = "connection-pool-config-hash"
+
+	// DefaultConnectionPoolMaxConnections is the default client connection
+	// limit of a pooler pod.
+	DefaultConnectionPoolMaxConnections = 1024
+	// DefaultConnectionPoolIdleTimeout is the default idle timeout of the
+	// client connections of a pooler.
+	DefaultConnectionPoolIdleTimeout = time.Hour
+
+	// cmanName is the name of the Connection Manager instance of a CMAN
+	// pooler.
+	cmanName = "cman_pool"
+	// connectionPoolConfigDir is where the pooler ConfigMap is mounted.
+	connectionPoolConfigDir = "/pool-config"
+	haproxyConfig           = "haproxy.cfg"
+	cmanConfig              = "cman.ora"
+)
+
+// ConnectionPoolType returns the pooler type of spec, Proxy by default.
+func ConnectionPoolType(spec *v1alpha1.ConnectionPoolSpec) v1alpha1.ConnectionPoolType {
+	if spec.Type == "" {
+		return v1alpha1.ConnectionPoolProxy
+	}
+	return spec.Type
+}
+
+// connectionPoolTarget returns the address the pooler forwards the
+// connections to, the database Service of the instance.
+func connectionPoolTarget(inst *v1alpha1.Instance) string {
+	return fmt.Sprintf(SvcEndpoint, fmt.Sprintf(SvcName, inst.Name), inst.Namespace)
+}
+
+func connectionPoolLimits(spec *v1alpha1.ConnectionPoolSpec) (int32, time.Duration) {
+	maxConns := spec.MaxConnections
+	if maxConns == 0 {
+		maxConns = DefaultConnectionPoolMaxConnections
+	}
+	idleTimeout := DefaultConnectionPoolIdleTimeout
+	if spec.IdleTimeout != nil {
+		idleTimeout = spec.IdleTimeout.Duration
+	}
+	return maxConns, idleTimeout
+}
+
+// ConnectionPoolConfig returns the configuration files of the pooler of
+// the instance, by file name.
+func ConnectionPoolConfig(inst *v1alpha1.Instance) (map[string]string, error) {
+	spec := inst.Spec.ConnectionPool
+	maxConns, idleTimeout := connectionPoolLimits(spec)
+	if idleTimeout < time.Second {
+		return nil, fmt.Errorf("connection pool idle timeout %v is shorter than a second", idleTimeout)
+	}
+	target := connectionPoolTarget(inst)
+
+	switch ConnectionPoolType(spec) {
+	case v1alpha1.ConnectionPoolProxy:
+		serverMaxConns := ""
+		if spec.MaxServerConnections > 0 {
+			serverMaxConns = fmt.Sprintf(" maxconn %d", spec.MaxServerConnections)
+		}
+		// The clients above the database connection limit of the server
+		// wait in the queue of the backend for up to the idle timeout.
+		return map[string]string{haproxyConfig: fmt.Sprintf(`global
+  maxconn %[1]d
+
+defaults
+  mode tcp
+  timeout connect 10s
+  timeout client %[2]ds
+  timeout server %[2]ds
+  timeout queue %[2]ds
+
+frontend pool
+  bind :%[3]d
+  maxconn %[1]d
+  default_backend database
+
+backend database
+  server database %[4]s:%[3]d%[5]s
+`, maxConns, int64(idleTimeout.Seconds()), consts.SecureListenerPort, target, serverMaxConns)}, nil
+
+	case v1alpha1.ConnectionPoolCMAN:
+		if spec.MaxServerConnections > 0 {
+			return nil, fmt.Errorf("maxServerConnections isn't supported by the %s connection pool", v1alpha1.ConnectionPoolCMAN)
+		}
+		// Connection Manager only forwards the connections to the database
+		// Service of the instance.
+		return map[string]string{cmanConfig: fmt.Sprintf(`%[1]s =
+  (configuration =
+    (address = (protocol = tcp)(host = 0.0.0.0)(port = %[2]d))
+    (rule_list =
+      (rule = (src = *)(dst = %[3]s)(srv = *)(act = accept))
+      (rule = (src = *)(dst = 127.0.0.1)(srv = cmon)(act = accept)))
+    (parameter_list =
+      (max_connections = %[4]d)
+      (idle_timeout = %[5]d)
+      (log_level = user)))
+`, cmanName, consts.SecureListenerPort, target, maxConns, int64(idleTimeout.Seconds()))}, nil
+	}
+	return nil, fmt.Errorf("unsupported connection pool type %q", spec.Type)
+}
+
+// ConnectionPoolConfigHash returns the hash of the pooler configuration.
+func ConnectionPoolConfigHash(config map[string]string) string {
+	var names []string
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, config[name])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// ConnectionPoolLabels returns the labels of the pooler pods of the
+// instance. They don't carry the instance label, the Services selecting
+// the pods of the instance by it don't route to the pooler.
+func ConnectionPoolLabels(inst *v1alpha1.Instance) map[string]string {
+	return map[string]string{"instance-pool": inst.Name, "task-type": ConnectionPoolTaskType}
+}
+
+// ConnectionPoolPodTemplate returns the pod template of the pooler of the
+// instance running image with the configuration of the ConfigMap cmName.
+func ConnectionPoolPodTemplate(inst *v1alpha1.Instance, image, cmName, configHash string) corev1.PodTemplateSpec {
+	spec := inst.Spec.ConnectionPool
+	c := corev1.Container{
+		Name:      "pool",
+		Image:     image,
+		Resources: spec.Resources,
+		Ports: []corev1.ContainerPort{
+			{Name: "pool", Protocol: corev1.ProtocolTCP, ContainerPort: consts.SecureListenerPort},
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(consts.SecureListenerPort)},
+			},
+			PeriodSeconds: 10,
+		},
+		SecurityContext: containerSecurityContext(false, nil),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "pool-config", MountPath: connectionPoolConfigDir, ReadOnly: true},
+		},
+	}
+	switch ConnectionPoolType(spec) {
+	case v1alpha1.ConnectionPoolCMAN:
+		// cmctl starts Connection Manager in the background, the container
+		// lives as long as it answers.
+		c.Command = []string{"/bin/sh", "-c", strings.Join([]string{
+			"cmctl startup -c " + cmanName,
+			"while cmctl show status -c " + cmanName + " > /dev/null; do sleep 30; done",
+		}, " && ")}
+		c.Env = []corev1.EnvVar{{Name: "TNS_ADMIN", Value: connectionPoolConfigDir}}
+	default:
+		c.Command = []string{"haproxy", "-W", "-db", "-f", connectionPoolConfigDir + "/" + haproxyConfig}
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      ConnectionPoolLabels(inst),
+			Annotations: map[string]string{ConnectionPoolConfigHashAnnotation: configHash},
+		},
+		Spec: corev1.PodSpec{
+			Containers:  []corev1.Container{c},
+			Tolerations: inst.Spec.PodSpec.Tolerations,
+			Volumes: []corev1.Volume{{
+				Name: "pool-config",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: cmName},
+					},
+				},
+			}},
+		},
+	}
+}
+
+// ConnectionPoolServiceSpec returns the spec of the Service of the pooler
+// of the instance.
+func ConnectionPoolServiceSpec(inst *v1alpha1.Instance) corev1.ServiceSpec {
+	svcType := inst.Spec.ConnectionPool.ServiceType
+	if svcType == "" {
+		svcType = corev1.ServiceTypeClusterIP
+	}
+	spec := corev1.ServiceSpec{
+		Selector: ConnectionPoolLabels(inst),
+		Ports: []corev1.ServicePort{
+			{
+				Name:       "pool",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       consts.SecureListenerPort,
+				TargetPort: intstr.FromInt(consts.SecureListenerPort),
+			},
+		},
+		Type: svcType,
+	}
+	if svcType == corev1.ServiceTypeLoadBalancer {
+		spec.LoadBalancerSourceRanges = sourceCidrRange
+		if len(inst.Spec.SourceCidrRanges) > 0 {
+			spec.LoadBalancerSourceRanges = inst.Spec.SourceCidrRanges
+		}
+	}
+	return spec
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+)
+
+func TestConnectionPoolConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     v1alpha1.ConnectionPoolSpec
+		wantFile string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "proxy defaults",
+			spec:     v1alpha1.ConnectionPoolSpec{},
+			wantFile: "haproxy.cfg",
+			want: []string{
+				"  maxconn 1024\n",
+				"  timeout client 3600s\n",
+				"  bind :6021\n",
+				"  server database mydb-svc.db:6021\n",
+			},
+		},
+		{
+			name: "proxy limits",
+			spec: v1alpha1.ConnectionPoolSpec{
+				Type:                 v1alpha1.ConnectionPoolProxy,
+				MaxConnections:       200,
+				MaxServerConnections: 20,
+				IdleTimeout:          &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			wantFile: "haproxy.cfg",
+			want: []string{
+				"  maxconn 200\n",
+				"  timeout queue 300s\n",
+				"  server database mydb-svc.db:6021 maxconn 20\n",
+			},
+		},
+		{
+			name: "cman",
+			spec: v1alpha1.ConnectionPoolSpec{
+				Type:           v1alpha1.ConnectionPoolCMAN,
+				MaxConnections: 500,
+			},
+			wantFile: "cman.ora",
+			want: []string{
+				"cman_pool =\n",
+				"(address = (protocol = tcp)(host = 0.0.0.0)(port = 6021))",
+				"(rule = (src = *)(dst = mydb-svc.db)(srv = *)(act = accept))",
+				"(max_connections = 500)",
+				"(idle_timeout = 3600)",
+			},
+		},
+		{
+			name:    "cman server connection limit",
+			spec:    v1alpha1.ConnectionPoolSpec{Type: v1alpha1.ConnectionPoolCMAN, MaxServerConnections: 20},
+			wantErr: true,
+		},
+		{
+			name:    "idle timeout too short",
+			spec:    v1alpha1.ConnectionPoolSpec{IdleTimeout: &metav1.Duration{Duration: time.Millisecond}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+				Spec:       v1alpha1.InstanceSpec{ConnectionPool: &tc.spec},
+			}
+			config, err := ConnectionPoolConfig(inst)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ConnectionPoolConfig got error %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(config) != 1 {
+				t.Fatalf("ConnectionPoolConfig got files %v, want only %s", config, tc.wantFile)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(config[tc.wantFile], want) {
+					t.Errorf("ConnectionPoolConfig got %s:\n%s\nwant it to contain %q", tc.wantFile, config[tc.wantFile], want)
+				}
+			}
+		})
+	}
+}
+
+func TestConnectionPoolPodTemplate(t *testing.T) {
+	inst := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+		Spec:       v1alpha1.InstanceSpec{ConnectionPool: &v1alpha1.ConnectionPoolSpec{Type: v1alpha1.ConnectionPoolCMAN}},
+	}
+	template := ConnectionPoolPodTemplate(inst, "cman:19", "mydb-pool", "hash")
+	if _, ok := template.Labels["instance"]; ok {
+		t.Errorf("ConnectionPoolPodTemplate got labels %v, want no instance label", template.Labels)
+	}
+	if got := template.Annotations[ConnectionPoolConfigHashAnnotation]; got != "hash" {
+		t.Errorf("ConnectionPoolPodTemplate got config hash %q, want %q", got, "hash")
+	}
+	c := template.Spec.Containers[0]
+	if c.Image != "cman:19" || !strings.Contains(strings.Join(c.Command, " "), "cmctl startup -c cman_pool") {
+		t.Errorf("ConnectionPoolPodTemplate got container %s %v, want cmctl of the image", c.Image, c.Command)
+	}
+	if len(c.Env) != 1 || c.Env[0].Name != "TNS_ADMIN" || c.Env[0].Value != connectionPoolConfigDir {
+		t.Errorf("ConnectionPoolPodTemplate got env %v, want TNS_ADMIN=%s", c.Env, connectionPoolConfigDir)
+	}
+
+	config := map[string]string{"cman.ora": "a"}
+	if ConnectionPoolConfigHash(config) == ConnectionPoolConfigHash(map[string]string{"cman.ora": "b"}) {
+		t.Errorf("ConnectionPoolConfigHash got the same hash for different configurations")
+	}
+}
@@ -17,10 +17,12 @@ package databasecontroller
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -100,7 +102,7 @@ func (r *DatabaseReconciler) updateIsChangeApplied(ctx context.Context, db *v1al
 // +kubebuilder:rbac:groups=database.oracle.db.anthosapis.com,resources=databases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=database.oracle.db.anthosapis.com,resources=databases/status,verbs=get;update;patch
 
-// +kubebuilder:rbac:groups=core,resources=services,verbs=list;watch;get;patch
+// +kubebuilder:rbac:groups=core,resources=services,verbs=list;watch;get;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods/status,verbs=get;update;patch
@@ -259,7 +261,39 @@ func (r *DatabaseReconciler) ReconcileDatabaseCreation(ctx context.Context, req
 			log.Error(err, "failed to sync database")
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+		if err := SyncInMemory(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync the In-Memory column store")
+			return ctrl.Result{}, err
+		}
+		if err := SyncNLS(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync the NLS parameters")
+			return ctrl.Result{}, err
+		}
+		if err := SyncResources(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync the resource parameters")
+			return ctrl.Result{}, err
+		}
+		if err := SyncRowLevelSecurity(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync row level security")
+			return ctrl.Result{}, err
+		}
+		if err := SyncEditions(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync editions")
+			return ctrl.Result{}, err
+		}
+		if err := SyncServices(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to sync services")
+			return ctrl.Result{}, err
+		}
+		if err := SyncEndpoint(ctx, r, &db, &inst, log); err != nil {
+			log.Error(err, "failed to sync the database service")
+			return ctrl.Result{}, err
+		}
+		if err := SyncPartitioning(ctx, r, &db, log); err != nil {
+			log.Error(err, "failed to maintain partitions")
+			return ctrl.Result{}, err
+		}
+		return requeueResult(&db), nil
 	}
 
 	log.V(1).Info("[DEBUG] create users", "Database", db.Spec.Name, "Users/Privs", db.Spec.Users)
@@ -267,6 +301,46 @@ func (r *DatabaseReconciler) ReconcileDatabaseCreation(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
+	if err := SyncInMemory(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync the In-Memory column store")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncNLS(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync the NLS parameters")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncResources(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync the resource parameters")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncRowLevelSecurity(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync row level security")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncEditions(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync editions")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncServices(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to sync services")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncEndpoint(ctx, r, &db, &inst, log); err != nil {
+		log.Error(err, "failed to sync the database service")
+		return ctrl.Result{}, err
+	}
+
+	if err := SyncPartitioning(ctx, r, &db, log); err != nil {
+		log.Error(err, "failed to maintain partitions")
+		return ctrl.Result{}, err
+	}
+
 	// check DB name against existing ones to decide whether this is a new DB
 	if !util.Contains(inst.Status.DatabaseNames, db.Spec.Name) {
 		log.Info("found a new DB", "dbName", db.Spec.Name)
@@ -285,7 +359,7 @@ func (r *DatabaseReconciler) ReconcileDatabaseCreation(ctx context.Context, req
 
 	log.Info("reconciling database: DONE")
 
-	return ctrl.Result{}, nil
+	return requeueResult(&db), nil
 }
 
 func (r *DatabaseReconciler) instanceToDatabases(obj client.Object) []ctrl.Request {
@@ -304,7 +378,7 @@ func (r *DatabaseReconciler) instanceToDatabases(obj client.Object) []ctrl.Reque
 // SetupWithManager starts the reconciler loop.
 func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	// UpdateFunc is used to judge if instance event is a 'DatabaseInstanceReady' event. If that is true, the event will be processed by the database reconciler
+	// UpdateFunc is used to judge if instance event is a 'DatabaseInstanceReady' event, or a change of the database Services of a ready instance. If that is true, the event will be processed by the database reconciler
 	databaseInstanceReadyPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			oldInstance, ok := e.ObjectOld.(*v1alpha1.Instance)
@@ -312,9 +386,6 @@ func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				r.Log.Info("Expected instance", "type", e.ObjectOld.GetObjectKind().GroupVersionKind().String())
 				return false
 			}
-			if cond := k8s.FindCondition(oldInstance.Status.Conditions, k8s.DatabaseInstanceReady); k8s.ConditionStatusEquals(cond, v1.ConditionTrue) {
-				return false
-			}
 			newInstance, ok := e.ObjectNew.(*v1alpha1.Instance)
 			if !ok {
 				r.Log.Info("Expected instance", "type", e.ObjectNew.GetObjectKind().GroupVersionKind().String())
@@ -323,6 +394,14 @@ func (r *DatabaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if cond := k8s.FindCondition(newInstance.Status.Conditions, k8s.DatabaseInstanceReady); !k8s.ConditionStatusEquals(cond, v1.ConditionTrue) {
 				return false
 			}
+			// The Services of the databases follow the instance spec.
+			if !equality.Semantic.DeepEqual(oldInstance.Spec.DatabaseServices, newInstance.Spec.DatabaseServices) {
+				r.Log.Info("DatabaseServices changed")
+				return true
+			}
+			if cond := k8s.FindCondition(oldInstance.Status.Conditions, k8s.DatabaseInstanceReady); k8s.ConditionStatusEquals(cond, v1.ConditionTrue) {
+				return false
+			}
 			r.Log.Info("DatabaseInstanceReady changes to true")
 			return true
 		},
@@ -391,6 +470,130 @@ func validateSpec(db *v1alpha1.Database) error {
 			}
 		}
 	}
+	if im := db.Spec.InMemory; im != nil {
+		if im.Size != nil && im.Size.Sign() < 0 {
+			return fmt.Errorf("resources/validateSpec: In-Memory size %q is negative", im.Size.String())
+		}
+		for _, ts := range im.Tablespaces {
+			if _, err := sql.ObjectName(ts); err != nil {
+				return fmt.Errorf("resources/validateSpec: invalid In-Memory tablespace %q: %w", ts, err)
+			}
+		}
+		for _, t := range im.Tables {
+			if err := validateTable(t); err != nil {
+				return fmt.Errorf("resources/validateSpec: invalid In-Memory table: %w", err)
+			}
+		}
+	}
+	for name := range db.Spec.NLSParameters {
+		if name != strings.ToLower(name) || !strings.HasPrefix(name, "nls_") {
+			return fmt.Errorf("resources/validateSpec: invalid NLS parameter %q, expected a lower case nls_ parameter", name)
+		}
+	}
+	if res := db.Spec.Resources; res != nil {
+		if res.SGATarget != nil && res.SGATarget.Sign() < 0 {
+			return fmt.Errorf("resources/validateSpec: SGA target %q is negative", res.SGATarget.String())
+		}
+		if res.PGAAggregateTarget != nil && res.PGAAggregateTarget.Sign() < 0 {
+			return fmt.Errorf("resources/validateSpec: PGA aggregate target %q is negative", res.PGAAggregateTarget.String())
+		}
+	}
+	contexts := make(map[string]bool)
+	for _, c := range db.Spec.ApplicationContexts {
+		if _, err := sql.ObjectName(c.Name); err != nil || c.Name == "" {
+			return fmt.Errorf("resources/validateSpec: invalid application context name %q", c.Name)
+		}
+		if err := validateQualifiedName(c.Package, 2); err != nil {
+			return fmt.Errorf("resources/validateSpec: invalid package of application context %q: %w", c.Name, err)
+		}
+		if contexts[strings.ToUpper(c.Name)] {
+			return fmt.Errorf("resources/validateSpec: duplicate application context %q", c.Name)
+		}
+		contexts[strings.ToUpper(c.Name)] = true
+	}
+	policies := make(map[string]bool)
+	for _, p := range db.Spec.VPDPolicies {
+		if _, err := sql.ObjectName(p.Name); err != nil || p.Name == "" {
+			return fmt.Errorf("resources/validateSpec: invalid VPD policy name %q", p.Name)
+		}
+		if err := validateTable(p.Table); err != nil {
+			return fmt.Errorf("resources/validateSpec: invalid table of VPD policy %q: %w", p.Name, err)
+		}
+		if validateQualifiedName(p.Function, 2) != nil && validateQualifiedName(p.Function, 3) != nil {
+			return fmt.Errorf("resources/validateSpec: function %q of VPD policy %q is not of the form SCHEMA.FUNCTION or SCHEMA.PACKAGE.FUNCTION", p.Function, p.Name)
+		}
+		key := strings.ToUpper(p.Table + "." + p.Name)
+		if policies[key] {
+			return fmt.Errorf("resources/validateSpec: duplicate VPD policy %q of table %q", p.Name, p.Table)
+		}
+		policies[key] = true
+	}
+	tables := make(map[string]bool)
+	for _, p := range db.Spec.Partitioning {
+		if err := validateTable(p.Table); err != nil {
+			return fmt.Errorf("resources/validateSpec: invalid partitioned table: %w", err)
+		}
+		if tables[strings.ToUpper(p.Table)] {
+			return fmt.Errorf("resources/validateSpec: duplicate partitioning policy for table %q", p.Table)
+		}
+		tables[strings.ToUpper(p.Table)] = true
+	}
+	if e := db.Spec.Editions; e != nil {
+		editions := make(map[string]bool)
+		for _, name := range append(append([]string{}, e.Names...), e.Retired...) {
+			if _, err := sql.ObjectName(name); err != nil || name == "" {
+				return fmt.Errorf("resources/validateSpec: invalid edition name %q", name)
+			}
+			if editions[strings.ToUpper(name)] {
+				return fmt.Errorf("resources/validateSpec: edition %q is listed twice", name)
+			}
+			editions[strings.ToUpper(name)] = true
+		}
+		if _, err := sql.ObjectName(e.Default); err != nil {
+			return fmt.Errorf("resources/validateSpec: invalid default edition %q", e.Default)
+		}
+		for _, name := range e.Retired {
+			if strings.EqualFold(name, e.Default) {
+				return fmt.Errorf("resources/validateSpec: default edition %q is retired", e.Default)
+			}
+		}
+	}
+	services := make(map[string]bool)
+	for _, svc := range db.Spec.Services {
+		if services[strings.ToUpper(svc.Name)] {
+			return fmt.Errorf("resources/validateSpec: service %q is listed twice", svc.Name)
+		}
+		services[strings.ToUpper(svc.Name)] = true
+	}
 
 	return nil
 }
+
+// validateTable validates a table given as OWNER.TABLE.
+func validateTable(t string) error {
+	parts := strings.Split(t, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("table %q is not of the form OWNER.TABLE", t)
+	}
+	for _, p := range parts {
+		if _, err := sql.ObjectName(p); err != nil {
+			return fmt.Errorf("table %q: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// validateQualifiedName validates a name made of n dot separated parts,
+// e.g. SCHEMA.PACKAGE.
+func validateQualifiedName(name string, n int) error {
+	parts := strings.Split(name, ".")
+	if len(parts) != n {
+		return fmt.Errorf("%q has %d parts, want %d", name, len(parts), n)
+	}
+	for _, p := range parts {
+		if _, err := sql.ObjectName(p); err != nil || p == "" {
+			return fmt.Errorf("invalid name %q", name)
+		}
+	}
+	return nil
+}
@@ -18,18 +18,28 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/integer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	commonutils "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/pkg/utils"
 	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
 	k8s "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
 )
 
@@ -40,6 +50,15 @@ const (
 
 var (
 	dialTimeout = 10 * time.Minute
+
+	// partitionMaintenanceInterval is how often the partitions of the tables
+	// of the partitioning policies are maintained.
+	partitionMaintenanceInterval = time.Hour
+
+	// resourcesCheckInterval is how often the resource parameters of the
+	// databases with resource limits are checked for changes in the
+	// database.
+	resourcesCheckInterval = 10 * time.Minute
 )
 
 // NewDatabase attempts to create a new PDB if it doesn't exist yet.
@@ -185,6 +204,18 @@ func NewUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database,
 	}
 	log.Info("resources/NewUsers: CreateUsers succeeded with this output", "output", cdOut)
 
+	var quotaUsers []*controllers.User
+	for _, u := range db.Spec.Users {
+		if len(u.Quotas) > 0 {
+			quotaUsers = append(quotaUsers, &controllers.User{Name: u.Name, Quotas: userQuotas(u.Quotas)})
+		}
+	}
+	if len(quotaUsers) > 0 {
+		if err := controllers.SetUserQuotas(ctx, r, r.DatabaseClientFactory, db.Namespace, db.Spec.Instance, db.Spec.Name, quotaUsers); err != nil {
+			log.Error(err, "resources/NewUsers: failed on SetUserQuota gRPC call")
+		}
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		log.Error(err, "resources/NewUsers: failed to get a hostname")
@@ -202,6 +233,19 @@ func NewUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database,
 	return nil
 }
 
+// userQuotas converts the quotas of a user spec, an unlimited quota is -1.
+func userQuotas(quotas []v1alpha1.TablespaceQuotaSpec) []controllers.UserQuota {
+	var res []controllers.UserQuota
+	for _, q := range quotas {
+		maxBytes := q.Size.Value()
+		if q.Unlimited {
+			maxBytes = -1
+		}
+		res = append(res, controllers.UserQuota{Tablespace: q.Tablespace, MaxBytes: maxBytes})
+	}
+	return res
+}
+
 // SyncUsers attempts to update PDB users.
 func SyncUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, cdbName string, log logr.Logger) error {
 	log.Info("resources/syncUsers: sync database users requested", "db", db)
@@ -223,6 +267,7 @@ func SyncUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database
 		userSpec := &controllers.User{
 			Name:       user.Name,
 			Privileges: privs,
+			Quotas:     userQuotas(user.Quotas),
 		}
 		// database_controller.validateSpec has validated the spec earlier;
 		// So no duplicated validation here.
@@ -269,10 +314,26 @@ func SyncUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database
 			PdbName:   db.Spec.Name,
 			UserSpecs: userSpecs,
 		}
-		if err := controllers.UpdateUsers(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, *req); err != nil {
+		updateResp, err := controllers.UpdateUsers(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, *req)
+		if err != nil {
 			log.Error(err, "resources/syncUsers: failed on UpdateUser gRPC call")
 			return err
 		}
+		if len(updateResp.Failed) != 0 {
+			// The users updated successfully are in sync now, returning an
+			// error requeues the reconcile which retries only the failed ones.
+			var failed, msg []string
+			for _, f := range updateResp.Failed {
+				failed = append(failed, f.UserName)
+				msg = append(msg, fmt.Sprintf("User %q failed to update: %s", f.UserName, f.Error))
+			}
+			db.Status.Conditions = k8s.Upsert(db.Status.Conditions, k8s.UserReady, v1.ConditionFalse, k8s.UserOutOfSync, strings.Join(msg, "."))
+			r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncUser, fmt.Sprintf("Failed to sync users %v for database %q, synced %v", failed, db.Spec.Name, updateResp.Succeeded))
+			if err := r.Status().Update(ctx, db); err != nil {
+				return err
+			}
+			return fmt.Errorf("resources/syncUsers: failed to update users %v", failed)
+		}
 		log.Info("resources/syncUsers: update database users done", "CDB", cdbName, "PDB", db.Spec.Name)
 	}
 	log.Info("resources/syncUsers: sync database users done", "CDB", cdbName, "PDB", db.Spec.Name)
@@ -318,3 +379,462 @@ func SyncUsers(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database
 	}
 	return nil
 }
+
+// SyncInMemory applies the In-Memory column store spec to the database and
+// reports the population of its segments.
+func SyncInMemory(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	spec, applied := db.Spec.InMemory, db.Status.InMemory
+	if spec == nil && applied == nil {
+		return nil
+	}
+	if spec == nil {
+		spec = &v1alpha1.InMemorySpec{}
+	}
+	if applied == nil {
+		applied = &v1alpha1.InMemoryStatus{}
+	}
+	log.Info("resources/syncInMemory: sync In-Memory column store requested", "PDB", db.Spec.Name, "inMemory", spec)
+
+	req := controllers.ConfigureInMemoryRequest{
+		PdbName:               db.Spec.Name,
+		InMemoryTablespaces:   spec.Tablespaces,
+		InMemoryTables:        spec.Tables,
+		NoInMemoryTablespaces: missing(applied.Tablespaces, spec.Tablespaces),
+		NoInMemoryTables:      missing(applied.Tables, spec.Tables),
+	}
+	if spec.Size != nil {
+		size := spec.Size.Value()
+		req.SizeBytes = &size
+	}
+	restartRequired, err := controllers.ConfigureInMemory(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, req)
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncInMemory, fmt.Sprintf("Failed to configure the In-Memory column store for database %q: %v", db.Spec.Name, err))
+		return err
+	}
+	status, err := controllers.FetchInMemoryStatus(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, db.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	db.Status.InMemory = &v1alpha1.InMemoryStatus{
+		Size:               resource.NewQuantity(status.SizeBytes, resource.BinarySI),
+		RestartRequired:    restartRequired,
+		Tablespaces:        spec.Tablespaces,
+		Tables:             spec.Tables,
+		PopulatedSegments:  status.PopulatedSegments,
+		PopulatingSegments: status.PopulatingSegments,
+	}
+	r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedInMemory, fmt.Sprintf("Synced the In-Memory column store for database %q", db.Spec.Name))
+	log.Info("resources/syncInMemory: sync In-Memory column store done", "PDB", db.Spec.Name, "status", db.Status.InMemory)
+	return r.Status().Update(ctx, db)
+}
+
+// SyncNLS applies the NLS parameters of the spec to the database and
+// reports those in effect.
+func SyncNLS(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	spec := db.Spec.NLSParameters
+	if len(spec) == 0 && db.Status.NLS == nil {
+		return nil
+	}
+	log.Info("resources/syncNLS: sync NLS parameters requested", "PDB", db.Spec.Name, "nlsParameters", spec)
+
+	var applied []string
+	if db.Status.NLS != nil {
+		for name := range db.Status.NLS.Parameters {
+			applied = append(applied, name)
+		}
+		sort.Strings(applied)
+	}
+	var names []string
+	for name := range spec {
+		names = append(names, name)
+	}
+	restartRequired, err := controllers.SetNLSParameters(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, controllers.SetNLSParametersRequest{
+		PdbName:         db.Spec.Name,
+		Parameters:      spec,
+		ResetParameters: missing(applied, names),
+	})
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncNLS, fmt.Sprintf("Failed to set the NLS parameters of database %q: %v", db.Spec.Name, err))
+		return err
+	}
+	settings, err := controllers.FetchNLSSettings(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, db.Spec.Name)
+	if err != nil {
+		return err
+	}
+
+	status := &v1alpha1.NLSStatus{
+		CharacterSet:         settings.CharacterSet,
+		NationalCharacterSet: settings.NationalCharacterSet,
+		RestartRequired:      restartRequired,
+	}
+	for name := range spec {
+		if status.Parameters == nil {
+			status.Parameters = make(map[string]string)
+		}
+		status.Parameters[name] = settings.Parameters[name]
+	}
+	db.Status.NLS = status
+	r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedNLS, fmt.Sprintf("Synced the NLS parameters of database %q", db.Spec.Name))
+	log.Info("resources/syncNLS: sync NLS parameters done", "PDB", db.Spec.Name, "status", db.Status.NLS)
+	return r.Status().Update(ctx, db)
+}
+
+// pdbResourceParameters returns the resource parameters of the spec by
+// parameter name, the sizes in bytes.
+func pdbResourceParameters(spec *v1alpha1.PDBResourcesSpec) map[string]string {
+	params := make(map[string]string)
+	if spec == nil {
+		return params
+	}
+	if spec.CPUCount != nil {
+		params["cpu_count"] = strconv.Itoa(int(*spec.CPUCount))
+	}
+	if spec.SGATarget != nil {
+		params["sga_target"] = strconv.FormatInt(spec.SGATarget.Value(), 10)
+	}
+	if spec.PGAAggregateTarget != nil {
+		params["pga_aggregate_target"] = strconv.FormatInt(spec.PGAAggregateTarget.Value(), 10)
+	}
+	if spec.MaxIOPS != nil {
+		params["max_iops"] = strconv.Itoa(int(*spec.MaxIOPS))
+	}
+	if spec.MaxMBPS != nil {
+		params["max_mbps"] = strconv.Itoa(int(*spec.MaxMBPS))
+	}
+	return params
+}
+
+// SyncResources sets the resource parameters of the spec in the database
+// and resets those removed from the spec. The parameters found changed in
+// the database since they were set are set back and reported as drifted.
+func SyncResources(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	spec, applied := pdbResourceParameters(db.Spec.Resources), db.Status.Resources
+	if len(spec) == 0 && applied == nil {
+		return nil
+	}
+	log.Info("resources/syncResources: sync resource parameters requested", "PDB", db.Spec.Name, "parameters", spec)
+
+	var appliedNames, names []string
+	if applied != nil {
+		for name := range applied.Parameters {
+			appliedNames = append(appliedNames, name)
+		}
+		sort.Strings(appliedNames)
+	}
+	for name := range spec {
+		names = append(names, name)
+	}
+	resp, err := controllers.ConfigurePDBResources(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, controllers.ConfigurePDBResourcesRequest{
+		PdbName:         db.Spec.Name,
+		Parameters:      spec,
+		ResetParameters: missing(appliedNames, names),
+	})
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncResources, fmt.Sprintf("Failed to set the resource parameters of database %q: %v", db.Spec.Name, err))
+		return err
+	}
+
+	var status *v1alpha1.PDBResourcesStatus
+	if len(spec) > 0 {
+		status = &v1alpha1.PDBResourcesStatus{Parameters: make(map[string]string)}
+		for name := range spec {
+			status.Parameters[name] = resp.Parameters[name]
+		}
+		if applied != nil {
+			status.LastDriftTime = applied.LastDriftTime
+		}
+		// The parameters set though already in effect when last synced
+		// were changed in the database.
+		for _, name := range resp.Changed {
+			if value, ok := spec[name]; ok && applied != nil && applied.Parameters[name] == value {
+				status.Drifted = append(status.Drifted, name)
+			}
+		}
+		if len(status.Drifted) > 0 {
+			now := v1.Now()
+			status.LastDriftTime = &now
+			r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.ResourcesDrifted, fmt.Sprintf("Resource parameters %v of database %q were changed in the database, set them back", status.Drifted, db.Spec.Name))
+		}
+	}
+	if len(resp.Changed) > 0 {
+		r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedResources, fmt.Sprintf("Synced the resource parameters %v of database %q", resp.Changed, db.Spec.Name))
+	}
+	if reflect.DeepEqual(status, applied) {
+		return nil
+	}
+	db.Status.Resources = status
+	log.Info("resources/syncResources: sync resource parameters done", "PDB", db.Spec.Name, "status", db.Status.Resources)
+	return r.Status().Update(ctx, db)
+}
+
+// SyncRowLevelSecurity creates and drops the application contexts and the
+// VPD policies of the database following its spec.
+func SyncRowLevelSecurity(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	if len(db.Spec.ApplicationContexts) == 0 && len(db.Spec.VPDPolicies) == 0 && len(db.Status.ApplicationContexts) == 0 && len(db.Status.VPDPolicies) == 0 {
+		return nil
+	}
+	log.Info("resources/syncRowLevelSecurity: sync row level security requested", "PDB", db.Spec.Name, "applicationContexts", db.Spec.ApplicationContexts, "vpdPolicies", db.Spec.VPDPolicies)
+
+	req := controllers.ConfigureRowLevelSecurityRequest{PdbName: db.Spec.Name}
+	var contexts, policies []string
+	for _, c := range db.Spec.ApplicationContexts {
+		req.ApplicationContexts = append(req.ApplicationContexts, controllers.ApplicationContext{
+			Name:             c.Name,
+			Package:          c.Package,
+			AccessedGlobally: c.AccessedGlobally,
+		})
+		contexts = append(contexts, strings.ToUpper(c.Name))
+	}
+	for _, p := range db.Spec.VPDPolicies {
+		var types []string
+		for _, t := range p.StatementTypes {
+			types = append(types, string(t))
+		}
+		req.VPDPolicies = append(req.VPDPolicies, controllers.VPDPolicy{
+			Name:           p.Name,
+			Table:          p.Table,
+			Function:       p.Function,
+			StatementTypes: types,
+			UpdateCheck:    p.UpdateCheck,
+			PolicyType:     p.PolicyType,
+		})
+		policies = append(policies, strings.ToUpper(p.Table+"."+p.Name))
+	}
+	req.DropApplicationContexts = missing(db.Status.ApplicationContexts, contexts)
+	req.DropVPDPolicies = missing(db.Status.VPDPolicies, policies)
+
+	changed, err := controllers.ConfigureRowLevelSecurity(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, req)
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncRowLevelSecurity, fmt.Sprintf("Failed to configure row level security for database %q: %v", db.Spec.Name, err))
+		return err
+	}
+	db.Status.ApplicationContexts = contexts
+	db.Status.VPDPolicies = policies
+	if len(changed) > 0 {
+		r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedRowLevelSecurity, fmt.Sprintf("Synced row level security for database %q, changed VPD policies %v", db.Spec.Name, changed))
+	}
+	log.Info("resources/syncRowLevelSecurity: sync row level security done", "PDB", db.Spec.Name, "changedVPDPolicies", changed)
+	return r.Status().Update(ctx, db)
+}
+
+// SyncEditions creates, retires and sets the default edition of the database
+// following its spec.
+func SyncEditions(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	if db.Spec.Editions == nil {
+		return nil
+	}
+	log.Info("resources/syncEditions: sync editions requested", "PDB", db.Spec.Name, "editions", db.Spec.Editions)
+
+	resp, err := controllers.ConfigureEditions(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, controllers.ConfigureEditionsRequest{
+		PdbName:        db.Spec.Name,
+		Editions:       db.Spec.Editions.Names,
+		DropEditions:   db.Spec.Editions.Retired,
+		DefaultEdition: db.Spec.Editions.Default,
+	})
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncEditions, fmt.Sprintf("Failed to configure editions for database %q: %v", db.Spec.Name, err))
+		return err
+	}
+	db.Status.Editions = resp.Editions
+	db.Status.DefaultEdition = resp.DefaultEdition
+	if len(resp.CreatedEditions) > 0 || len(resp.DroppedEditions) > 0 {
+		r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedEditions, fmt.Sprintf("Synced editions for database %q, created %v, dropped %v, default edition %s", db.Spec.Name, resp.CreatedEditions, resp.DroppedEditions, resp.DefaultEdition))
+	}
+	log.Info("resources/syncEditions: sync editions done", "PDB", db.Spec.Name, "editions", resp.Editions, "defaultEdition", resp.DefaultEdition)
+	return r.Status().Update(ctx, db)
+}
+
+// SyncServices creates and starts the services of the database and turns
+// their FAN HA notifications on or off following its spec.
+func SyncServices(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	if len(db.Spec.Services) == 0 {
+		return nil
+	}
+	log.Info("resources/syncServices: sync services requested", "PDB", db.Spec.Name, "services", db.Spec.Services)
+
+	req := controllers.ConfigureFANRequest{PdbName: db.Spec.Name}
+	var names []string
+	for _, svc := range db.Spec.Services {
+		req.Services = append(req.Services, controllers.FANService{Name: svc.Name, HANotifications: svc.HANotifications})
+		names = append(names, svc.Name)
+	}
+	resp, err := controllers.ConfigureFAN(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, req)
+	if err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncServices, fmt.Sprintf("Failed to configure services for database %q: %v", db.Spec.Name, err))
+		return err
+	}
+	db.Status.Services = names
+	if len(resp.CreatedServices) > 0 || len(resp.ModifiedServices) > 0 {
+		r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedServices, fmt.Sprintf("Synced services for database %q, created %v, modified HA notifications of %v", db.Spec.Name, resp.CreatedServices, resp.ModifiedServices))
+	}
+	log.Info("resources/syncServices: sync services done", "PDB", db.Spec.Name, "createdServices", resp.CreatedServices, "modifiedServices", resp.ModifiedServices)
+	return r.Status().Update(ctx, db)
+}
+
+// SyncEndpoint creates the Kubernetes Service of the database, or deletes
+// it, following the DatabaseServices spec of the instance and reports it as
+// the endpoint of the database.
+func SyncEndpoint(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, inst *v1alpha1.Instance, log logr.Logger) error {
+	svc := &corev1.Service{ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf(controllers.SvcName, db.Name), Namespace: db.Namespace}}
+	if inst.Spec.DatabaseServices == nil {
+		if db.Status.Endpoint == "" {
+			return nil
+		}
+		log.Info("resources/syncEndpoint: deleting the database service", "PDB", db.Spec.Name, "service", svc.Name)
+		if err := r.Delete(ctx, svc); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.DeletedEndpoint, fmt.Sprintf("Deleted service %q of database %q", svc.Name, db.Spec.Name))
+		db.Status.Endpoint = ""
+		db.Status.URL = ""
+		return r.Status().Update(ctx, db)
+	}
+
+	// Never take over a Service the database doesn't own, e.g. the load
+	// balancer of an instance of the same name.
+	existing := &corev1.Service{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(svc), existing); err == nil && !v1.IsControlledBy(existing, db) {
+		err := fmt.Errorf("resources/syncEndpoint: service %q already exists and isn't owned by database %q", svc.Name, db.Name)
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncEndpoint, err.Error())
+		return err
+	} else if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	want := newDatabaseSvc(db, inst)
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		svc.Annotations = want.Annotations
+		svc.Spec.Type = want.Spec.Type
+		svc.Spec.Selector = want.Spec.Selector
+		svc.Spec.Ports = want.Spec.Ports
+		svc.Spec.LoadBalancerSourceRanges = want.Spec.LoadBalancerSourceRanges
+		return ctrl.SetControllerReference(db, svc, r.Scheme)
+	}); err != nil {
+		r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToSyncEndpoint, fmt.Sprintf("Failed to sync service %q of database %q: %v", svc.Name, db.Spec.Name, err))
+		return err
+	}
+
+	endpoint := fmt.Sprintf(controllers.SvcEndpoint, svc.Name, svc.Namespace)
+	url := commonutils.LoadBalancerURL(svc, consts.SecureListenerPort)
+	if db.Status.Endpoint == endpoint && db.Status.URL == url {
+		return nil
+	}
+	db.Status.Endpoint = endpoint
+	db.Status.URL = url
+	r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.SyncedEndpoint, fmt.Sprintf("Database %q is exposed through service %q", db.Spec.Name, svc.Name))
+	log.Info("resources/syncEndpoint: sync database service done", "PDB", db.Spec.Name, "endpoint", endpoint, "url", url)
+	return r.Status().Update(ctx, db)
+}
+
+// newDatabaseSvc returns the Service of a database. It selects the database
+// pod like the load balancer of the instance, the listener routes the
+// connections to the PDB service named after the database.
+func newDatabaseSvc(db *v1alpha1.Database, inst *v1alpha1.Instance) *corev1.Service {
+	spec := inst.Spec.DatabaseServices
+	svcType := spec.Type
+	if svcType == "" {
+		svcType = corev1.ServiceTypeClusterIP
+	}
+	svc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        fmt.Sprintf(controllers.SvcName, db.Name),
+			Namespace:   db.Namespace,
+			Annotations: spec.Annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"instance":  inst.Name,
+				"task-type": controllers.DatabaseTaskType,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "secure-listener",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       consts.SecureListenerPort,
+					TargetPort: intstr.FromInt(consts.SecureListenerPort),
+				},
+				{
+					Name:       "ssl-listener",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       consts.SSLListenerPort,
+					TargetPort: intstr.FromInt(consts.SSLListenerPort),
+				},
+			},
+			Type: svcType,
+		},
+	}
+	if svcType == corev1.ServiceTypeLoadBalancer {
+		svc.Spec.LoadBalancerSourceRanges = []string{"0.0.0.0/0"}
+		if len(inst.Spec.SourceCidrRanges) > 0 {
+			svc.Spec.LoadBalancerSourceRanges = inst.Spec.SourceCidrRanges
+		}
+	}
+	return svc
+}
+
+// missing returns the elements of applied which aren't in spec.
+func missing(applied, spec []string) []string {
+	var out []string
+	for _, a := range applied {
+		found := false
+		for _, s := range spec {
+			if strings.EqualFold(a, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// SyncPartitioning maintains the partitions of the tables of the
+// partitioning policies of the database.
+func SyncPartitioning(ctx context.Context, r *DatabaseReconciler, db *v1alpha1.Database, log logr.Logger) error {
+	if len(db.Spec.Partitioning) == 0 && len(db.Status.Partitioning) == 0 {
+		return nil
+	}
+	var statuses []v1alpha1.PartitioningStatus
+	for _, policy := range db.Spec.Partitioning {
+		log.Info("resources/syncPartitioning: partition maintenance requested", "PDB", db.Spec.Name, "policy", policy)
+		resp, err := controllers.MaintainPartitions(ctx, r, r.DatabaseClientFactory, db.GetNamespace(), db.Spec.Instance, controllers.MaintainPartitionsRequest{
+			PdbName:  db.Spec.Name,
+			Table:    policy.Table,
+			Interval: policy.Interval,
+			Retain:   policy.Retain,
+		})
+		if err != nil {
+			r.Recorder.Eventf(db, corev1.EventTypeWarning, k8s.FailedToMaintainPartitions, fmt.Sprintf("Failed to maintain the partitions of table %s in database %q: %v", policy.Table, db.Spec.Name, err))
+			return err
+		}
+		if len(resp.DroppedPartitions) != 0 {
+			r.Recorder.Eventf(db, corev1.EventTypeNormal, k8s.DroppedPartitions, fmt.Sprintf("Dropped partitions %v of table %s in database %q", resp.DroppedPartitions, policy.Table, db.Spec.Name))
+		}
+		now := v1.Now()
+		statuses = append(statuses, v1alpha1.PartitioningStatus{
+			Table:               policy.Table,
+			Partitions:          resp.Partitions,
+			DroppedPartitions:   resp.DroppedPartitions,
+			LastMaintenanceTime: &now,
+		})
+	}
+	db.Status.Partitioning = statuses
+	log.Info("resources/syncPartitioning: partition maintenance done", "PDB", db.Spec.Name, "status", statuses)
+	return r.Status().Update(ctx, db)
+}
+
+// requeueResult requeues the reconcile of a database with resource limits
+// so their changes in the database are set back, and of a database with
+// partitioning policies so partitions keep being dropped as they expire.
+func requeueResult(db *v1alpha1.Database) ctrl.Result {
+	switch {
+	case db.Spec.Resources != nil:
+		return ctrl.Result{RequeueAfter: resourcesCheckInterval}
+	case len(db.Spec.Partitioning) != 0:
+		return ctrl.Result{RequeueAfter: partitionMaintenanceInterval}
+	}
+	return ctrl.Result{}
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databasecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestNewDatabaseSvc(t *testing.T) {
+	db := &v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Name: "pdb1", Namespace: "db"}}
+	tests := []struct {
+		name             string
+		spec             v1alpha1.InstanceSpec
+		wantType         corev1.ServiceType
+		wantSourceRanges []string
+	}{
+		{
+			name:     "default type",
+			spec:     v1alpha1.InstanceSpec{DatabaseServices: &v1alpha1.DatabaseServicesSpec{}},
+			wantType: corev1.ServiceTypeClusterIP,
+		},
+		{
+			name:             "load balancer",
+			spec:             v1alpha1.InstanceSpec{DatabaseServices: &v1alpha1.DatabaseServicesSpec{Type: corev1.ServiceTypeLoadBalancer}},
+			wantType:         corev1.ServiceTypeLoadBalancer,
+			wantSourceRanges: []string{"0.0.0.0/0"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: "mydb", Namespace: "db"}}
+			inst.Spec.DatabaseServices = tc.spec.DatabaseServices
+			svc := newDatabaseSvc(db, inst)
+			if svc.Name != "pdb1-svc" {
+				t.Errorf("newDatabaseSvc got name %q, want %q", svc.Name, "pdb1-svc")
+			}
+			if svc.Spec.Type != tc.wantType {
+				t.Errorf("newDatabaseSvc got type %q, want %q", svc.Spec.Type, tc.wantType)
+			}
+			if diff := cmp.Diff(tc.wantSourceRanges, svc.Spec.LoadBalancerSourceRanges); diff != "" {
+				t.Errorf("newDatabaseSvc got unexpected source ranges (-want +got):\n%v", diff)
+			}
+			wantSelector := map[string]string{"instance": "mydb", "task-type": "oracle-db"}
+			if diff := cmp.Diff(wantSelector, svc.Spec.Selector); diff != "" {
+				t.Errorf("newDatabaseSvc got unexpected selector (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestSyncResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	db := &v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Name: "pdb1", Namespace: "db"}}
+	db.Spec.Name = "pdb1"
+	db.Spec.Instance = "mydb"
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &DatabaseReconciler{
+		Client:                fake.NewClientBuilder().WithScheme(scheme).WithObjects(db).Build(),
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	sgaTarget := resource.MustParse("1Gi")
+	inEffect := map[string]string{"cpu_count": "2", "sga_target": "1073741824"}
+
+	steps := []struct {
+		name        string
+		spec        *v1alpha1.PDBResourcesSpec
+		changed     []string
+		wantCalls   int
+		wantReset   []string
+		wantStatus  map[string]string
+		wantDrifted []string
+		// wantDriftTime is set once a drift was found.
+		wantDriftTime bool
+	}{
+		{
+			name: "never configured",
+		},
+		{
+			name:       "limits added",
+			spec:       &v1alpha1.PDBResourcesSpec{CPUCount: pointer.Int32(2), SGATarget: &sgaTarget},
+			changed:    []string{"cpu_count", "sga_target"},
+			wantCalls:  1,
+			wantStatus: inEffect,
+		},
+		{
+			name:       "limits unchanged",
+			spec:       &v1alpha1.PDBResourcesSpec{CPUCount: pointer.Int32(2), SGATarget: &sgaTarget},
+			wantCalls:  2,
+			wantStatus: inEffect,
+		},
+		{
+			name:          "limit changed in the database",
+			spec:          &v1alpha1.PDBResourcesSpec{CPUCount: pointer.Int32(2), SGATarget: &sgaTarget},
+			changed:       []string{"cpu_count"},
+			wantCalls:     3,
+			wantStatus:    inEffect,
+			wantDrifted:   []string{"cpu_count"},
+			wantDriftTime: true,
+		},
+		{
+			name:          "limit removed",
+			spec:          &v1alpha1.PDBResourcesSpec{CPUCount: pointer.Int32(2)},
+			changed:       []string{"sga_target"},
+			wantCalls:     4,
+			wantReset:     []string{"sga_target"},
+			wantStatus:    map[string]string{"cpu_count": "2"},
+			wantDriftTime: true,
+		},
+		{
+			name:      "spec removed",
+			changed:   []string{"cpu_count"},
+			wantCalls: 5,
+			wantReset: []string{"cpu_count"},
+		},
+		{
+			name:      "spec still removed",
+			wantCalls: 5,
+		},
+	}
+	calls := 0
+	for _, step := range steps {
+		db.Spec.Resources = step.spec
+		factory.Dbclient.SetMethodToResp("ConfigurePDBResources", &dbdpb.ConfigurePDBResourcesResponse{Parameters: inEffect, Changed: step.changed})
+		if err := SyncResources(context.Background(), r, db, logr.Discard()); err != nil {
+			t.Fatalf("%s: SyncResources failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.ConfigurePDBResourcesCalledCnt(); got != step.wantCalls {
+			t.Fatalf("%s: ConfigurePDBResources called %d times, want %d", step.name, got, step.wantCalls)
+		}
+		if step.wantCalls > calls {
+			reqs := factory.Dbclient.GotConfigurePDBResourcesRequests
+			if diff := cmp.Diff(step.wantReset, reqs[len(reqs)-1].GetResetParameters()); diff != "" {
+				t.Errorf("%s: ConfigurePDBResources got unexpected reset parameters (-want +got):\n%v", step.name, diff)
+			}
+		}
+		calls = step.wantCalls
+		var gotStatus map[string]string
+		var gotDrifted []string
+		if status := db.Status.Resources; status != nil {
+			gotStatus, gotDrifted = status.Parameters, status.Drifted
+			if got := status.LastDriftTime != nil; got != step.wantDriftTime {
+				t.Errorf("%s: got status.resources.lastDriftTime %v, want set: %v", step.name, status.LastDriftTime, step.wantDriftTime)
+			}
+		}
+		if diff := cmp.Diff(step.wantStatus, gotStatus); diff != "" {
+			t.Errorf("%s: got unexpected status.resources.parameters (-want +got):\n%v", step.name, diff)
+		}
+		if diff := cmp.Diff(step.wantDrifted, gotDrifted); diff != "" {
+			t.Errorf("%s: got unexpected status.resources.drifted (-want +got):\n%v", step.name, diff)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+)
+
+const (
+	// caKey is the private key of the CA in the CA Secret of an instance.
+	caKey = "ca.key"
+
+	dbdaemonTLSVolume = "dbdaemon-tls"
+
+	// dbdaemonCALifetime is the lifetime of the CA of an instance, the CA
+	// isn't rotated.
+	dbdaemonCALifetime = 10 * 365 * 24 * time.Hour
+	// dbdaemonCertLifetime is the lifetime of the database daemon
+	// certificates, they're renewed once less than a third of it is left.
+	dbdaemonCertLifetime = 90 * 24 * time.Hour
+	dbdaemonCertRenewal  = dbdaemonCertLifetime / 3
+)
+
+// newSerialNumber returns a random certificate serial number.
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// encodeKey returns the PEM encoding of the private key.
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// decodePEM returns the DER bytes of the first PEM block of data.
+func decodePEM(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return block.Bytes, nil
+}
+
+// NewDBDaemonCA returns the Secret holding the CA the database daemon
+// certificates of the instance are issued by. The CA key never leaves the
+// operator, the CA Secret isn't mounted in any pod.
+func NewDBDaemonCA(inst *v1alpha1.Instance, now time.Time) (*corev1.Secret, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s/%s dbdaemon CA", inst.Namespace, inst.Name)},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(dbdaemonCALifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(DBDaemonCASecretName, inst.Name), Namespace: inst.Namespace},
+		Data: map[string][]byte{
+			common.CACertFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			caKey:             keyPEM,
+		},
+	}, nil
+}
+
+// IssueDBDaemonCert issues a new database daemon certificate by the CA in
+// the CA Secret ca into the TLS Secret tlsSecret. The same certificate
+// authenticates the daemons to their clients and the clients to the
+// daemons.
+func IssueDBDaemonCert(ca, tlsSecret *corev1.Secret, now time.Time) error {
+	caDER, err := decodePEM(ca.Data[common.CACertFile])
+	if err != nil {
+		return fmt.Errorf("failed to decode the CA certificate: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return fmt.Errorf("failed to parse the CA certificate: %v", err)
+	}
+	caKeyDER, err := decodePEM(ca.Data[caKey])
+	if err != nil {
+		return fmt.Errorf("failed to decode the CA key: %v", err)
+	}
+	caPrivateKey, err := x509.ParseECPrivateKey(caKeyDER)
+	if err != nil {
+		return fmt.Errorf("failed to parse the CA key: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := newSerialNumber()
+	if err != nil {
+		return err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: common.TLSServerName},
+		DNSNames:     []string{common.TLSServerName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(dbdaemonCertLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caPrivateKey)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	tlsSecret.Type = corev1.SecretTypeTLS
+	tlsSecret.Data = map[string][]byte{
+		common.CACertFile:  ca.Data[common.CACertFile],
+		common.TLSCertFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		common.TLSKeyFile:  keyPEM,
+	}
+	return nil
+}
+
+// DBDaemonCertNeedsRenewal returns whether the certificate in the TLS Secret
+// expires within the renewal window, or can't be read.
+func DBDaemonCertNeedsRenewal(tlsSecret *corev1.Secret, now time.Time) bool {
+	der, err := decodePEM(tlsSecret.Data[common.TLSCertFile])
+	if err != nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return true
+	}
+	return now.Add(dbdaemonCertRenewal).After(cert.NotAfter)
+}
+
+// DBDaemonClientCredentials returns the credentials the operator dials the
+// database daemon with over mutual TLS.
+func DBDaemonClientCredentials(tlsSecret *corev1.Secret) (credentials.TransportCredentials, error) {
+	return common.ClientCredentialsFromPEM(tlsSecret.Data[common.CACertFile], tlsSecret.Data[common.TLSCertFile], tlsSecret.Data[common.TLSKeyFile])
+}
+
+// DBDaemonTLSVolume returns the volume of the TLS Secret of the database
+// daemon.
+func DBDaemonTLSVolume(secretName string) corev1.Volume {
+	return corev1.Volume{
+		Name: dbdaemonTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secretName},
+		},
+	}
+}
+
+// DBDaemonTLSVolumeMount returns the mount of the DBDaemonTLSVolume.
+func DBDaemonTLSVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: dbdaemonTLSVolume, MountPath: consts.DBDaemonTLSDir, ReadOnly: true}
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+)
+
+func newTestDBDaemonCert(t *testing.T, now time.Time) (*corev1.Secret, *corev1.Secret) {
+	t.Helper()
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"}}
+	ca, err := NewDBDaemonCA(inst, now)
+	if err != nil {
+		t.Fatalf("NewDBDaemonCA failed: %v", err)
+	}
+	tlsSecret := &corev1.Secret{}
+	if err := IssueDBDaemonCert(ca, tlsSecret, now); err != nil {
+		t.Fatalf("IssueDBDaemonCert failed: %v", err)
+	}
+	return ca, tlsSecret
+}
+
+func TestIssueDBDaemonCert(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	ca, tlsSecret := newTestDBDaemonCert(t, now)
+
+	if ca.Name != "mydb-dbdaemon-ca" {
+		t.Errorf("NewDBDaemonCA got Secret %q, want mydb-dbdaemon-ca", ca.Name)
+	}
+	if tlsSecret.Type != corev1.SecretTypeTLS {
+		t.Errorf("IssueDBDaemonCert got Secret type %q, want %q", tlsSecret.Type, corev1.SecretTypeTLS)
+	}
+	if _, ok := tlsSecret.Data[caKey]; ok {
+		t.Errorf("IssueDBDaemonCert copied the CA key into the TLS Secret")
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "new certificate", now: now, want: false},
+		{name: "before the renewal window", now: now.Add(59 * 24 * time.Hour), want: false},
+		{name: "in the renewal window", now: now.Add(61 * 24 * time.Hour), want: true},
+		{name: "expired", now: now.Add(91 * 24 * time.Hour), want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DBDaemonCertNeedsRenewal(tlsSecret, tc.now); got != tc.want {
+				t.Errorf("DBDaemonCertNeedsRenewal got %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if !DBDaemonCertNeedsRenewal(&corev1.Secret{}, now) {
+		t.Errorf("DBDaemonCertNeedsRenewal got false for a Secret without a certificate, want true")
+	}
+}
+
+// handshake runs a TLS handshake between the database daemon serving the
+// certificates in dir and a client of the operator with clientSecret.
+func handshake(t *testing.T, dir string, clientSecret *corev1.Secret) (serverErr, clientErr error) {
+	t.Helper()
+	clientCreds, err := DBDaemonClientCredentials(clientSecret)
+	if err != nil {
+		t.Fatalf("DBDaemonClientCredentials failed: %v", err)
+	}
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := common.ServerCredentials(dir).ServerHandshake(serverConn)
+		// Unblock the client if the server rejected it.
+		serverConn.Close()
+		done <- err
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _, clientErr = clientCreds.ClientHandshake(ctx, "10.0.0.1:3203", clientConn)
+	return <-done, clientErr
+}
+
+func TestDBDaemonMutualTLS(t *testing.T) {
+	now := time.Now()
+	_, tlsSecret := newTestDBDaemonCert(t, now)
+	dir := t.TempDir()
+	for name, data := range tlsSecret.Data {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if serverErr, clientErr := handshake(t, dir, tlsSecret); serverErr != nil || clientErr != nil {
+		t.Errorf("handshake with a certificate of the instance CA failed: server %v, client %v", serverErr, clientErr)
+	}
+
+	// A certificate of another instance's CA is rejected.
+	_, otherSecret := newTestDBDaemonCert(t, now)
+	if serverErr, _ := handshake(t, dir, otherSecret); serverErr == nil {
+		t.Errorf("handshake with a certificate of another CA succeeded, want an error")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// hookScriptRequest returns the request running the script of a hook,
+// reading the script from its ConfigMap if the hook has no GCS path.
+func hookScriptRequest(ctx context.Context, r client.Reader, namespace string, hook v1alpha1.InstanceHook) (*dbdpb.RunSQLScriptFromGCSRequest, error) {
+	req := &dbdpb.RunSQLScriptFromGCSRequest{PdbName: hook.PDBName}
+	switch {
+	case hook.GcsPath != "":
+		req.Source = &dbdpb.RunSQLScriptFromGCSRequest_GcsPath{GcsPath: hook.GcsPath}
+	case hook.ConfigMap != nil:
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: hook.ConfigMap.Name}, cm); err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s: %v", hook.ConfigMap.Name, err)
+		}
+		script, ok := cm.Data[hook.ConfigMap.Key]
+		if !ok {
+			return nil, fmt.Errorf("ConfigMap %s has no key %s", hook.ConfigMap.Name, hook.ConfigMap.Key)
+		}
+		req.Source = &dbdpb.RunSQLScriptFromGCSRequest_Script{Script: script}
+	default:
+		return nil, fmt.Errorf("the hook has neither a GCS path nor a ConfigMap")
+	}
+	return req, nil
+}
+
+// RunHooks runs the hooks of the instance for the phase in their order and
+// records an event for each of them. A failed hook with the Block failure
+// policy stops the hooks and its error is returned, the failures of the
+// other hooks are only recorded.
+func RunHooks(ctx context.Context, r client.Reader, dbClientFactory DatabaseClientFactory, recorder record.EventRecorder, inst *v1alpha1.Instance, phase v1alpha1.HookPhase) error {
+	var hooks []v1alpha1.InstanceHook
+	for _, hook := range inst.Spec.Hooks {
+		if hook.Phase == phase {
+			hooks = append(hooks, hook)
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	klog.InfoS("config_agent_helpers/RunHooks", "namespace", inst.Namespace, "instName", inst.Name, "phase", phase, "hooks", len(hooks))
+	dbClient, closeConn, err := dbClientFactory.New(ctx, r, inst.Namespace, inst.Name)
+	if err != nil {
+		return fmt.Errorf("config_agent_helpers/RunHooks: failed to create database daemon client: %w", err)
+	}
+	defer closeConn()
+
+	for _, hook := range hooks {
+		req, err := hookScriptRequest(ctx, r, inst.Namespace, hook)
+		if err == nil {
+			_, err = dbClient.RunSQLScriptFromGCS(ctx, req)
+		}
+		if err == nil {
+			recorder.Eventf(inst, corev1.EventTypeNormal, k8s.HookSucceeded, "%s hook %s succeeded", phase, hook.Name)
+			continue
+		}
+		recorder.Eventf(inst, corev1.EventTypeWarning, k8s.HookFailed, "%s hook %s failed: %v", phase, hook.Name, err)
+		if hook.FailurePolicy != v1alpha1.HookContinue {
+			return fmt.Errorf("%s hook %s failed: %v", phase, hook.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// configMapReader serves the ConfigMaps it holds.
+type configMapReader struct {
+	client.Reader
+	configMaps map[string]*corev1.ConfigMap
+}
+
+func (r *configMapReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	cm, ok := r.configMaps[key.String()]
+	if !ok {
+		return fmt.Errorf("ConfigMap %s not found", key)
+	}
+	cm.DeepCopyInto(obj.(*corev1.ConfigMap))
+	return nil
+}
+
+func TestRunHooks(t *testing.T) {
+	hooks := []v1alpha1.InstanceHook{
+		{Name: "grants", Phase: v1alpha1.PostProvisionHook, GcsPath: "gs://bucket/grants.sql", PDBName: "PDB1"},
+		{Name: "freeze", Phase: v1alpha1.PreBackupHook, GcsPath: "gs://bucket/freeze.sql", FailurePolicy: v1alpha1.HookContinue},
+		{Name: "unlock", Phase: v1alpha1.PostRestoreHook, ConfigMap: &v1alpha1.HookConfigMapRef{Name: "hooks", Key: "unlock.sql"}},
+		{Name: "mask", Phase: v1alpha1.PostRestoreHook, GcsPath: "gs://bucket/mask.sql", FailurePolicy: v1alpha1.HookBlock},
+		{Name: "drain", Phase: v1alpha1.PreBackupHook, GcsPath: "gs://bucket/drain.sql"},
+	}
+	tests := []struct {
+		name       string
+		phase      v1alpha1.HookPhase
+		fail       string
+		wantErr    bool
+		wantHooks  []string
+		wantEvents int
+	}{
+		{
+			name:       "phase without hooks",
+			phase:      v1alpha1.PreUpgradeHook,
+			wantHooks:  nil,
+			wantEvents: 0,
+		},
+		{
+			name:       "hooks of the phase only",
+			phase:      v1alpha1.PreBackupHook,
+			wantHooks:  []string{"gs://bucket/freeze.sql", "gs://bucket/drain.sql"},
+			wantEvents: 2,
+		},
+		{
+			name:       "continue after a failure",
+			phase:      v1alpha1.PreBackupHook,
+			fail:       "gs://bucket/freeze.sql",
+			wantHooks:  []string{"gs://bucket/freeze.sql", "gs://bucket/drain.sql"},
+			wantEvents: 2,
+		},
+		{
+			name:       "block on a failure",
+			phase:      v1alpha1.PostRestoreHook,
+			fail:       "alter user app account unlock;",
+			wantErr:    true,
+			wantHooks:  []string{"alter user app account unlock;"},
+			wantEvents: 1,
+		},
+		{
+			name:       "block by default",
+			phase:      v1alpha1.PostProvisionHook,
+			fail:       "gs://bucket/grants.sql",
+			wantErr:    true,
+			wantHooks:  []string{"gs://bucket/grants.sql"},
+			wantEvents: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.RunSQLScriptFromGCSFunc = func(in *dbdpb.RunSQLScriptFromGCSRequest) (*dbdpb.RunSQLScriptFromGCSResponse, error) {
+				if tc.fail != "" && (in.GetGcsPath() == tc.fail || in.GetScript() == tc.fail) {
+					return nil, errors.New("ORA-01918: user does not exist")
+				}
+				return &dbdpb.RunSQLScriptFromGCSResponse{Statements: 1}, nil
+			}
+			reader := &configMapReader{configMaps: map[string]*corev1.ConfigMap{
+				"db/hooks": {Data: map[string]string{"unlock.sql": "alter user app account unlock;"}},
+			}}
+			recorder := record.NewFakeRecorder(10)
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+				Spec:       v1alpha1.InstanceSpec{Hooks: hooks},
+			}
+
+			err := controllers.RunHooks(context.Background(), reader, factory, recorder, inst, tc.phase)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("RunHooks got error %v, want error %v", err, tc.wantErr)
+			}
+			var gotHooks []string
+			for _, req := range factory.Dbclient.GotRunSQLScriptFromGCSRequests {
+				gotHooks = append(gotHooks, req.GetGcsPath()+req.GetScript())
+			}
+			if diff := cmp.Diff(tc.wantHooks, gotHooks); diff != "" {
+				t.Errorf("RunHooks ran unexpected hooks (-want +got):\n%v", diff)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("RunHooks recorded %d events, want %d", got, tc.wantEvents)
+			}
+		})
+	}
+}
@@ -164,20 +164,32 @@ func (r *ImportReconciler) handleNotStartedImport(ctx context.Context, log logr.
 			" %q != %q", imp.Spec.Instance, db.Spec.Instance)
 	}
 
+	dataPumpReq := &controllers.DataPumpImportRequest{
+		PdbName:          db.Spec.Name,
+		DbDomain:         inst.Spec.DBDomain,
+		GcsPath:          imp.Spec.GcsPath,
+		GcsLogPath:       imp.Spec.GcsLogPath,
+		NetworkLink:      imp.Spec.NetworkLink,
+		RemapSchemas:     imp.Spec.RemapSchemas,
+		RemapTablespaces: imp.Spec.RemapTablespaces,
+		Includes:         imp.Spec.Includes,
+		Excludes:         imp.Spec.Excludes,
+		Options:          imp.Spec.Options,
+		LroInput:         &controllers.LROInput{OperationId: lroOperationID(imp)},
+	}
+	// An invalid spec won't become valid by retrying.
+	if err := controllers.ValidateDataPumpImport(*dataPumpReq); err != nil {
+		impWrapper.setState(k8s.ImportFailed, fmt.Sprintf("invalid import spec: %v", err))
+		r.Recorder.Eventf(imp, corev1.EventTypeWarning, k8s.ImportFailed, "Invalid import spec: %v", err)
+		return ctrl.Result{}, nil
+	}
+
 	dbReady := k8s.ConditionStatusEquals(
 		k8s.FindCondition(db.Status.Conditions, k8s.Ready),
 		metav1.ConditionTrue)
 
 	// if can start, begin import
 	if dbReady {
-		dataPumpReq := &controllers.DataPumpImportRequest{
-			PdbName:    db.Spec.Name,
-			DbDomain:   inst.Spec.DBDomain,
-			GcsPath:    imp.Spec.GcsPath,
-			GcsLogPath: imp.Spec.GcsLogPath,
-			Options:    imp.Spec.Options,
-			LroInput:   &controllers.LROInput{OperationId: lroOperationID(imp)},
-		}
 		resp, err := controllers.DataPumpImport(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, *dataPumpReq)
 		if err != nil {
 			if !controllers.IsAlreadyExistsError(err) {
@@ -227,7 +239,7 @@ func (r *ImportReconciler) handleRunningImport(ctx context.Context, log logr.Log
 		impWrapper.setState(
 			k8s.ImportFailed,
 			fmt.Sprintf("Failed to import on %s from %s: %s",
-				time.Now().Format(time.RFC3339), imp.Spec.GcsPath, operation.GetError().GetMessage()))
+				time.Now().Format(time.RFC3339), importSource(imp), operation.GetError().GetMessage()))
 
 		r.Recorder.Eventf(imp, corev1.EventTypeWarning, k8s.ImportFailed, fmt.Sprintf("Import error: %v", operation.GetError().GetMessage()))
 
@@ -242,7 +254,7 @@ func (r *ImportReconciler) handleRunningImport(ctx context.Context, log logr.Log
 	impWrapper.setState(
 		k8s.ImportComplete,
 		fmt.Sprintf("Imported data on %s from %s",
-			time.Now().Format(time.RFC3339), imp.Spec.GcsPath))
+			time.Now().Format(time.RFC3339), importSource(imp)))
 
 	return ctrl.Result{}, nil
 }
@@ -257,3 +269,11 @@ func (r *ImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
 func lroOperationID(imp *v1alpha1.Import) string {
 	return fmt.Sprintf("Import_%s", imp.GetUID())
 }
+
+// importSource describes where imp imports data from.
+func importSource(imp *v1alpha1.Import) string {
+	if imp.Spec.NetworkLink != "" {
+		return fmt.Sprintf("database link %s", imp.Spec.NetworkLink)
+	}
+	return imp.Spec.GcsPath
+}
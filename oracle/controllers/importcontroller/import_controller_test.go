@@ -225,6 +225,20 @@ var _ = Describe("Import controller", func() {
 			Expect(getConditionReason(ctx, importObjectKey, k8s.Ready)).Should(Equal(k8s.ImportPending))
 		})
 	})
+
+	Context("Import spec is invalid", func() {
+
+		It("Should fail the Import without starting it", func() {
+			By("creating an import with both a dump file and a network link")
+			imp.Spec.NetworkLink = "src_link"
+			Expect(k8sClient.Create(ctx, imp)).Should(Succeed())
+
+			Eventually(func() (string, error) {
+				return getConditionReason(ctx, importObjectKey, k8s.Ready)
+			}, timeout, interval).Should(Equal(k8s.ImportFailed))
+			Expect(fakeDatabaseClient.DataPumpImportAsyncCalledCnt()).Should(Equal(0))
+		})
+	})
 })
 
 func getConditionStatus(ctx context.Context, objKey client.ObjectKey, condType string) (metav1.ConditionStatus, error) {
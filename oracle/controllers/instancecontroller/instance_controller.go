@@ -60,6 +60,12 @@ type InstanceReconciler struct {
 	Images        map[string]string
 	Recorder      record.EventRecorder
 	InstanceLocks *sync.Map
+	// DBDaemonPlaintext disables issuing the database daemon certificates
+	// of new instances.
+	DBDaemonPlaintext bool
+	// ConnectionPoolImage is the default image of the Proxy connection
+	// poolers.
+	ConnectionPoolImage string
 
 	DatabaseClientFactory controllers.DatabaseClientFactory
 }
@@ -79,6 +85,7 @@ func (r *InstanceReconciler) Scheme() *runtime.Scheme {
 // +kubebuilder:rbac:groups=core,resources=services,verbs=list;watch;get;patch;create;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;create;update
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
@@ -95,6 +102,7 @@ const (
 	dateFormat                           = "20060102"
 	DefaultStsPatchingTimeout            = 25 * time.Minute
 	reconcileTimeout                     = 3 * time.Minute
+	hookRetryInterval                    = time.Minute
 )
 
 func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, respErr error) {
@@ -222,20 +230,27 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		return ctrl.Result{}, err
 	}
 
+	dbdaemonTLSSecret, err := r.reconcileDBDaemonTLS(ctx, &inst, log)
+	if err != nil {
+		log.Error(err, "failed to reconcile the dbdaemon certificates")
+		return ctrl.Result{}, err
+	}
+
 	// Create a StatefulSet if needed.
 	sp := controllers.StsParams{
-		Inst:           &inst,
-		Scheme:         r.Scheme(),
-		Namespace:      req.NamespacedName.Namespace,
-		Images:         images,
-		SvcName:        fmt.Sprintf(controllers.SvcName, inst.Name),
-		StsName:        fmt.Sprintf(controllers.StsName, inst.Name),
-		PrivEscalation: false,
-		ConfigMap:      cm,
-		Disks:          controllers.DiskSpecs(&inst, config),
-		Config:         config,
-		Log:            log,
-		Services:       enabledServices,
+		Inst:              &inst,
+		Scheme:            r.Scheme(),
+		Namespace:         req.NamespacedName.Namespace,
+		Images:            images,
+		SvcName:           fmt.Sprintf(controllers.SvcName, inst.Name),
+		StsName:           fmt.Sprintf(controllers.StsName, inst.Name),
+		PrivEscalation:    false,
+		ConfigMap:         cm,
+		Disks:             controllers.DiskSpecs(&inst, config),
+		Config:            config,
+		Log:               log,
+		Services:          enabledServices,
+		DBDaemonTLSSecret: dbdaemonTLSSecret,
 	}
 
 	if IsPatchingStateMachineEntryCondition(inst.Spec.Services, inst.Status.ActiveImages, sp.Images, inst.Status.LastFailedImages, instanceReadyCond, dbInstanceCond) ||
@@ -278,10 +293,98 @@ func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 
 	if k8s.ConditionStatusEquals(instanceReadyCond, v1.ConditionTrue) && k8s.ConditionStatusEquals(dbInstanceCond, v1.ConditionTrue) {
 		log.Info("instance has already been provisioned and ready")
-		if res, err := r.reconcileMonitoring(ctx, &inst, log, images); err != nil || res.RequeueAfter > 0 {
-			return res, err
+		if err := r.reconcileLicense(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to declare the database license")
+		}
+		if err := r.reconcileKeystore(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to set up the TDE keystore")
+		}
+		if err := r.reconcileEncryption(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to verify tablespace encryption")
+		}
+		if err := r.reconcileTablespaceEncryptionPolicy(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to set the tablespace encryption policy")
+		}
+		if err := r.reconcileWalletPassword(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to rotate the TDE keystore password")
+		}
+		if err := r.reconcileNetworkEncryption(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure network encryption")
+		}
+		if err := r.reconcileAllowedClients(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure allowed clients")
+		}
+		if err := r.reconcileTCPS(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure the TCPS listener")
 		}
-		return ctrl.Result{}, r.updateDatabaseIncarnationStatus(ctx, &inst, r.Log)
+		if err := r.reconcileConnectionPool(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to deploy the connection pool")
+		}
+		if err := r.reconcileRMANConfig(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure RMAN")
+		}
+		if err := r.reconcileAWRConfig(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure AWR")
+		}
+		if err := r.reconcileONS(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to configure ONS")
+		}
+		if err := r.updateInstanceInfoStatus(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to update instance info")
+		}
+		if err := r.updatePDBStatus(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to update PDB status")
+		}
+		if err := r.reconcileOrphanedPDBs(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to reconcile the PDBs with the Database resources")
+		}
+		if err := r.reconcileRequiredOptions(ctx, &inst, log); err != nil {
+			log.Error(err, "failed to verify the required options")
+		}
+		recoveryAreaResult, err := r.reconcileRecoveryArea(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to reconcile recovery area usage")
+		}
+		storageMigrationResult, err := r.reconcileStorageMigration(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to reconcile storage migration")
+		}
+		diskUsageResult, err := r.reconcileDiskUsage(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to reconcile disk usage")
+		}
+		diskGrowthResult, err := r.reconcileDiskGrowth(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to verify disk growth")
+		}
+		autoResizeResult, err := r.reconcileAutoResize(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to auto resize the DataDisk")
+		}
+		deadlockResult, err := r.reconcileDeadlocks(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to scan the alert log for deadlocks")
+		}
+		sqlPlanManagementResult, err := r.reconcileSQLPlanManagement(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to reconcile SQL plan baseline capture")
+		}
+		rmanCatalogExportResult, err := r.reconcileRMANCatalogExport(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to export the RMAN catalog")
+		}
+		writeCanaryResult, err := r.reconcileWriteCanary(ctx, &inst, log)
+		if err != nil {
+			log.Error(err, "failed to run the write canary")
+		}
+		monitoringResult, err := r.reconcileMonitoring(ctx, &inst, log, images)
+		if err != nil {
+			return monitoringResult, err
+		}
+		if monitoringResult.RequeueAfter > 0 {
+			return mergeResults(monitoringResult, recoveryAreaResult, storageMigrationResult, diskUsageResult, diskGrowthResult, autoResizeResult, deadlockResult, sqlPlanManagementResult, rmanCatalogExportResult, writeCanaryResult), nil
+		}
+		return mergeResults(recoveryAreaResult, storageMigrationResult, diskUsageResult, diskGrowthResult, autoResizeResult, deadlockResult, sqlPlanManagementResult, rmanCatalogExportResult, writeCanaryResult), r.updateDatabaseIncarnationStatus(ctx, &inst, r.Log)
 	}
 
 	if result, err := r.createStatefulSet(ctx, &inst, sp, applyOpts, log); err != nil {
@@ -585,7 +688,7 @@ func (r *InstanceReconciler) reconcileDatabaseInstance(ctx context.Context, inst
 		done, err := controllers.IsLROOperationDone(ctx, r.DatabaseClientFactory, r.Client, id, inst.GetNamespace(), inst.GetName())
 		if !done {
 			log.Info("CreateCDB still in progress, waiting")
-			return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+			return ctrl.Result{RequeueAfter: databaseProvisioningRequeueAfter(inst)}, nil
 		}
 		controllers.DeleteLROOperation(ctx, r.DatabaseClientFactory, r.Client, id, inst.Namespace, inst.Name)
 		if err != nil {
@@ -632,7 +735,7 @@ func (r *InstanceReconciler) reconcileDatabaseInstance(ctx context.Context, inst
 		done, err := controllers.IsLROOperationDone(ctx, r.DatabaseClientFactory, r.Client, id, inst.GetNamespace(), inst.GetName())
 		if !done {
 			log.Info("BootstrapDatabase still in progress, waiting")
-			return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
+			return ctrl.Result{RequeueAfter: databaseProvisioningRequeueAfter(inst)}, nil
 		}
 		controllers.DeleteLROOperation(ctx, r.DatabaseClientFactory, r.Client, id, inst.Namespace, inst.Name)
 		if err != nil {
@@ -647,12 +750,25 @@ func (r *InstanceReconciler) reconcileDatabaseInstance(ctx context.Context, inst
 	case k8s.ReconcileServices:
 		res, err := r.reconcileMonitoring(ctx, inst, log, images)
 		if err == nil && res.RequeueAfter == 0 {
+			if msg := r.selfTestFailure(ctx, inst, r.selfTestBackupsGCSPath(ctx, inst, log), log); msg != "" {
+				k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionFalse, k8s.ReconcileServices, msg)
+				return ctrl.Result{RequeueAfter: selfTestRetryInterval}, r.Status().Update(ctx, inst)
+			}
+			if err := controllers.RunHooks(ctx, r, r.DatabaseClientFactory, r.Recorder, inst, v1alpha1.PostProvisionHook); err != nil {
+				log.Error(err, "post-provision hook failed")
+				k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionFalse, k8s.ReconcileServices, err.Error())
+				return ctrl.Result{RequeueAfter: hookRetryInterval}, r.Status().Update(ctx, inst)
+			}
 			k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionTrue, k8s.CreateComplete, "")
 			return ctrl.Result{Requeue: true}, r.Status().Update(ctx, inst)
 		}
 		return res, err
 	case k8s.RestorePending:
 		if k8s.ConditionReasonEquals(instanceReadyCond, k8s.RestoreComplete) {
+			if msg := r.selfTestFailure(ctx, inst, r.selfTestBackupsGCSPath(ctx, inst, log), log); msg != "" {
+				k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionFalse, k8s.RestorePending, msg)
+				return ctrl.Result{RequeueAfter: selfTestRetryInterval}, r.Status().Update(ctx, inst)
+			}
 			k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionTrue, k8s.CreateComplete, "")
 			return ctrl.Result{Requeue: true}, r.Status().Update(ctx, inst)
 		}
@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	// autoResizeCheckInterval is the delay between checks of the DataDisk
+	// usage.
+	autoResizeCheckInterval           = 5 * time.Minute
+	defaultAutoResizeThresholdPercent = 80
+	defaultAutoResizeIncreasePercent  = 20
+)
+
+// autoResizeSize returns the size the disk of the given capacity is
+// expanded to, rounded up to a GiB, zero if it is below the threshold. It
+// reports whether the expansion is limited by the maximum size of the spec.
+func autoResizeSize(spec *v1alpha1.AutoResizeSpec, capacity resource.Quantity, usedPercent int32) (resource.Quantity, bool) {
+	threshold, increase := spec.ThresholdPercent, spec.IncreasePercent
+	if threshold == 0 {
+		threshold = defaultAutoResizeThresholdPercent
+	}
+	if increase == 0 {
+		increase = defaultAutoResizeIncreasePercent
+	}
+	if usedPercent < threshold {
+		return resource.Quantity{}, false
+	}
+
+	bytes := capacity.Value() * int64(100+increase) / 100
+	bytes = (bytes + 1<<30 - 1) / (1 << 30) * (1 << 30)
+	size := *resource.NewQuantity(bytes, resource.BinarySI)
+	if spec.MaxSize != nil && size.Cmp(*spec.MaxSize) > 0 {
+		size = spec.MaxSize.DeepCopy()
+		if size.Cmp(capacity) <= 0 {
+			return resource.Quantity{}, true
+		}
+		return size, true
+	}
+	return size, false
+}
+
+// dataDiskGrowthPending reports whether a DataDisk expansion is still
+// waiting for the database to see the new space.
+func dataDiskGrowthPending(inst *v1alpha1.Instance) bool {
+	for _, g := range inst.Status.DiskGrowth {
+		if g.Mount == "/"+consts.DataMount && !g.Verified {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAutoResize checks the usage of the DataDisk filesystem every
+// autoResizeCheckInterval and expands the disk when it crosses the
+// threshold of the spec. The expanded size is kept in the instance status,
+// it overrides the DataDisk size of the spec so that the disk is resized
+// like a spec change on the next reconcile.
+func (r *InstanceReconciler) reconcileAutoResize(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	spec := inst.Spec.AutoResize
+	if spec == nil {
+		return ctrl.Result{}, nil
+	}
+	status := inst.Status.AutoResize
+	if status == nil {
+		status = &v1alpha1.AutoResizeStatus{}
+	}
+	now := time.Now()
+	if elapsed := now.Sub(status.LastCheckTime.Time); elapsed < autoResizeCheckInterval {
+		return ctrl.Result{RequeueAfter: autoResizeCheckInterval - elapsed}, nil
+	}
+	result := ctrl.Result{RequeueAfter: autoResizeCheckInterval}
+	if dataDiskGrowthPending(inst) {
+		log.Info("DataDisk expansion in progress, skipping the auto resize check")
+		return result, nil
+	}
+
+	pvcName, mount := controllers.GetPVCNameAndMount(inst.Name, "DataDisk")
+	pvc := &corev1.PersistentVolumeClaim{}
+	key := client.ObjectKey{Namespace: inst.Namespace, Name: fmt.Sprintf("%s-%s-0", pvcName, fmt.Sprintf(controllers.StsName, inst.Name))}
+	if err := r.Get(ctx, key, pvc); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the DataDisk PVC: %v", err)
+	}
+	capacity := pvc.Status.Capacity.Storage().DeepCopy()
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+	stats, err := dbClient.GetHostStats(ctx, &dbdpb.GetHostStatsRequest{Mounts: []string{"/" + mount}})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DiskAutoResizeCheckFailed, "Failed to get the usage of the DataDisk: %v", err)
+		return ctrl.Result{}, err
+	}
+	if len(stats.GetMounts()) != 1 || stats.GetMounts()[0].GetTotalBytes() <= 0 {
+		return ctrl.Result{}, fmt.Errorf("failed to get the usage of /%s: got %v", mount, stats.GetMounts())
+	}
+	m := stats.GetMounts()[0]
+
+	status.LastCheckTime = v1.NewTime(now)
+	status.UsedPercent = int32(m.GetUsedBytes() * 100 / m.GetTotalBytes())
+	status.Capacity = &capacity
+	inst.Status.AutoResize = status
+
+	size, limited := autoResizeSize(spec, capacity, status.UsedPercent)
+	if size.IsZero() {
+		if limited {
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DiskAutoResizeLimited, "DataDisk is %d%% used and already at its maximum size %s", status.UsedPercent, spec.MaxSize.String())
+		}
+		return result, nil
+	}
+	log.Info("expanding the DataDisk", "usedPercent", status.UsedPercent, "capacity", capacity.String(), "size", size.String())
+	status.Size = &size
+	status.LastResizeTime = &status.LastCheckTime
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.DiskAutoResized, "DataDisk is %d%% used, expanding it from %s to %s", status.UsedPercent, capacity.String(), size.String())
+	if limited {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DiskAutoResizeLimited, "DataDisk expansion is limited to its maximum size %s", spec.MaxSize.String())
+	}
+	// Resize the disk on the next reconcile, right away.
+	return ctrl.Result{RequeueAfter: time.Second}, nil
+}
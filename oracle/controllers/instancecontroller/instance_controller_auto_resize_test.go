@@ -0,0 +1,161 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestAutoResizeSize(t *testing.T) {
+	maxSize := resource.MustParse("150Gi")
+	tests := []struct {
+		name        string
+		spec        v1alpha1.AutoResizeSpec
+		usedPercent int32
+		wantSize    string
+		wantLimited bool
+	}{
+		{
+			name:        "below the default threshold",
+			usedPercent: 79,
+		},
+		{
+			name:        "default increase",
+			usedPercent: 80,
+			wantSize:    "120Gi",
+		},
+		{
+			name:        "custom threshold and increase",
+			spec:        v1alpha1.AutoResizeSpec{ThresholdPercent: 90, IncreasePercent: 5},
+			usedPercent: 95,
+			wantSize:    "105Gi",
+		},
+		{
+			name:        "smallest increase",
+			spec:        v1alpha1.AutoResizeSpec{IncreasePercent: 1},
+			usedPercent: 85,
+			wantSize:    "101Gi",
+		},
+		{
+			name:        "limited by the maximum size",
+			spec:        v1alpha1.AutoResizeSpec{IncreasePercent: 100, MaxSize: &maxSize},
+			usedPercent: 85,
+			wantSize:    "150Gi",
+			wantLimited: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			size, limited := autoResizeSize(&tc.spec, resource.MustParse("100Gi"), tc.usedPercent)
+			want := resource.Quantity{}
+			if tc.wantSize != "" {
+				want = resource.MustParse(tc.wantSize)
+			}
+			if !size.Equal(want) || limited != tc.wantLimited {
+				t.Errorf("autoResizeSize got %v, limited %v, want %v, limited %v", size.String(), limited, want.String(), tc.wantLimited)
+			}
+		})
+	}
+
+	// A disk at its maximum size is not expanded.
+	atMax := resource.MustParse("150Gi")
+	if size, limited := autoResizeSize(&v1alpha1.AutoResizeSpec{MaxSize: &maxSize}, atMax, 90); !size.IsZero() || !limited {
+		t.Errorf("autoResizeSize got %v, limited %v for a disk at its maximum size, want zero, limited", size.String(), limited)
+	}
+}
+
+func TestReconcileAutoResize(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    *v1alpha1.AutoResizeStatus
+		growth    []v1alpha1.DiskGrowthStatus
+		usedBytes int64
+		wantCalls int
+		wantSize  string
+	}{
+		{
+			name:      "below the threshold",
+			usedBytes: 50 << 30,
+			wantCalls: 1,
+		},
+		{
+			name:      "above the threshold",
+			usedBytes: 90 << 30,
+			wantCalls: 1,
+			wantSize:  "120Gi",
+		},
+		{
+			name:   "checked recently",
+			status: &v1alpha1.AutoResizeStatus{LastCheckTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+		},
+		{
+			name:   "expansion in progress",
+			growth: []v1alpha1.DiskGrowthStatus{{Mount: "/u02", Size: resource.MustParse("120Gi")}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			pvc := newTestPVC("100Gi", "100Gi")
+			pvc.Namespace = "db"
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("GetHostStats", &dbdpb.GetHostStatsResponse{
+				Mounts: []*dbdpb.GetHostStatsResponse_Mount{{Path: "/u02", TotalBytes: 100 << 30, UsedBytes: tc.usedBytes}},
+			})
+			r := &InstanceReconciler{
+				Client:                fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build(),
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+				Spec:       v1alpha1.InstanceSpec{AutoResize: &v1alpha1.AutoResizeSpec{}},
+				Status:     v1alpha1.InstanceStatus{AutoResize: tc.status, DiskGrowth: tc.growth},
+			}
+
+			if _, err := r.reconcileAutoResize(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileAutoResize failed: %v", err)
+			}
+			if got := factory.Dbclient.GetHostStatsCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileAutoResize called GetHostStats %d times, want %d", got, tc.wantCalls)
+			}
+			var gotSize string
+			if s := inst.Status.AutoResize; s != nil && s.Size != nil {
+				gotSize = s.Size.String()
+			}
+			if gotSize != tc.wantSize {
+				t.Errorf("reconcileAutoResize got size %q, want %q", gotSize, tc.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// reconcileAWRConfig applies spec.awrConfig to the AWR snapshot settings.
+// The dbdaemon only modifies the settings which differ, so this is safe to
+// call on every reconcile.
+func (r *InstanceReconciler) reconcileAWRConfig(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.AWRConfig == nil {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureAWR(ctx, &dbdpb.ConfigureAWRRequest{
+		RetentionDays:           inst.Spec.AWRConfig.RetentionDays,
+		SnapshotIntervalMinutes: inst.Spec.AWRConfig.SnapshotIntervalMinutes,
+	})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.AWRConfigureFailed, "Failed to configure AWR: %v", err)
+		return err
+	}
+	if resp.GetAppliedStatement() != "" {
+		log.Info("AWR configured", "statement", resp.GetAppliedStatement())
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.AWRConfigured, "AWR configured: retention of %d days, snapshots every %d minutes", resp.GetRetentionDays(), resp.GetSnapshotIntervalMinutes())
+	}
+	return nil
+}
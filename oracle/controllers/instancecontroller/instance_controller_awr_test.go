@@ -0,0 +1,90 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileAWRConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        *v1alpha1.AWRConfigSpec
+		err         error
+		wantErr     bool
+		wantCalls   int
+		wantRequest *dbdpb.ConfigureAWRRequest
+	}{
+		{
+			name: "not managed",
+		},
+		{
+			name:        "retention and interval",
+			spec:        &v1alpha1.AWRConfigSpec{RetentionDays: 30, SnapshotIntervalMinutes: 15},
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureAWRRequest{RetentionDays: 30, SnapshotIntervalMinutes: 15},
+		},
+		{
+			name:        "retention only",
+			spec:        &v1alpha1.AWRConfigSpec{RetentionDays: 3},
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureAWRRequest{RetentionDays: 3},
+		},
+		{
+			name:        "failed",
+			spec:        &v1alpha1.AWRConfigSpec{SnapshotIntervalMinutes: 5},
+			err:         errors.New("snapshot interval of 5 minutes is out of range"),
+			wantErr:     true,
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureAWRRequest{SnapshotIntervalMinutes: 5},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("ConfigureAWR", tc.err)
+			}
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{AWRConfig: tc.spec}}
+
+			err := r.reconcileAWRConfig(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileAWRConfig got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.ConfigureAWRCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileAWRConfig called ConfigureAWR %d times, want %d", got, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantRequest, factory.Dbclient.GotConfigureAWRRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("reconcileAWRConfig got unexpected request (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// cloneRequested returns whether the instance requests a clone later than
+// the last one.
+func cloneRequested(inst *v1alpha1.Instance) bool {
+	if inst.Spec.Restore == nil || inst.Spec.Restore.CloneToNewInstance == nil {
+		return false
+	}
+	last := inst.Status.LastClone
+	return last == nil || inst.Spec.Restore.RequestTime.Rfc3339Copy().After(last.RequestTime.Time)
+}
+
+// isClone returns whether the instance was cloned from another one.
+func isClone(inst *v1alpha1.Instance) bool {
+	return inst.Annotations[controllers.ClonedFromAnnotation] != ""
+}
+
+// newCloneInstance returns the Instance the backup requested by the restore
+// spec of source is cloned into. The clone is a copy of source restoring the
+// same backup, without the settings tying it to source: replication, Data
+// Guard, the DB unique name and the RMAN catalog export. pitrRef is the PITR
+// of source, it is only used by PITR restores which don't name one.
+func newCloneInstance(source *v1alpha1.Instance, pitrRef *v1alpha1.PITRReference) *v1alpha1.Instance {
+	spec := source.Spec.DeepCopy()
+	spec.Restore.CloneToNewInstance = nil
+	spec.Restore.Force = true
+	if spec.Restore.PITRRestore != nil && spec.Restore.PITRRestore.PITRRef == nil {
+		spec.Restore.PITRRestore.PITRRef = pitrRef
+	}
+	spec.ReplicationSettings = nil
+	spec.DataGuard = nil
+	spec.DBUniqueName = ""
+	spec.RMANCatalogExport = nil
+	spec.PrepareForStorageMigration = false
+	return &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        source.Spec.Restore.CloneToNewInstance.InstanceName,
+			Namespace:   source.Namespace,
+			Annotations: map[string]string{controllers.ClonedFromAnnotation: source.Name},
+		},
+		Spec: *spec,
+	}
+}
+
+// cloneToNewInstance creates the Instance the restore spec of the instance
+// requests to clone a backup into. The new Instance then restores the
+// backup like any other restore, the instance itself is left untouched.
+// Returns a non-empty result while waiting for the backup to be ready.
+func (r *InstanceReconciler) cloneToNewInstance(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	if !cloneRequested(inst) {
+		log.Info(fmt.Sprintf("skipping the clone request as requestTime=%v is not later than the last clone", inst.Spec.Restore.RequestTime))
+		return ctrl.Result{}, nil
+	}
+	name := inst.Spec.Restore.CloneToNewInstance.InstanceName
+	if name == inst.Name {
+		return ctrl.Result{}, r.setCloneFailed(ctx, inst, "an instance can't be cloned into itself", log)
+	}
+	if inst.Spec.Restore.BackupType != "Snapshot" && inst.Spec.Restore.BackupType != "Physical" {
+		return ctrl.Result{}, r.setCloneFailed(ctx, inst, "a BackupType is a mandatory parameter for a clone", log)
+	}
+
+	backup, err := r.findBackupForRestore(ctx, *inst, inst.Namespace, log)
+	if err != nil {
+		return ctrl.Result{}, r.setCloneFailed(ctx, inst, err.Error(), log)
+	}
+	backupReadyCond := k8s.FindCondition(backup.Status.Conditions, k8s.Ready)
+	if !k8s.ConditionStatusEquals(backupReadyCond, metav1.ConditionTrue) {
+		if k8s.ConditionReasonEquals(backupReadyCond, k8s.BackupFailed) {
+			return ctrl.Result{}, r.setCloneFailed(ctx, inst, "Backup is in failed state", log)
+		}
+		log.Info("Backup is in progress, waiting")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	// A local backup is on the disks of the instance, out of reach of the
+	// clone.
+	if backup.Spec.Type == "Physical" && backup.Spec.GcsPath == "" {
+		return ctrl.Result{}, r.setCloneFailed(ctx, inst, "only physical backups stored in GCS can be cloned", log)
+	}
+
+	var pitrRef *v1alpha1.PITRReference
+	if inst.Spec.Restore.PITRRestore != nil && inst.Spec.Restore.PITRRestore.PITRRef == nil {
+		p, err := r.findRestorePITR(ctx, inst)
+		if err != nil {
+			return ctrl.Result{}, r.setCloneFailed(ctx, inst, err.Error(), log)
+		}
+		pitrRef = &v1alpha1.PITRReference{Namespace: p.Namespace, Name: p.Name}
+	}
+
+	clone := newCloneInstance(inst, pitrRef)
+	var existing v1alpha1.Instance
+	if err := r.Get(ctx, client.ObjectKeyFromObject(clone), &existing); err == nil {
+		// A clone created by an earlier reconcile of the same request is
+		// still restoring the backup.
+		if existing.Annotations[controllers.ClonedFromAnnotation] != inst.Name || existing.Spec.Restore == nil ||
+			!existing.Spec.Restore.RequestTime.Equal(&inst.Spec.Restore.RequestTime) {
+			return ctrl.Result{}, r.setCloneFailed(ctx, inst, fmt.Sprintf("instance %q already exists", name), log)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	} else if err := r.Create(ctx, clone); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to create the clone instance %q: %v", name, err)
+	}
+
+	log.Info("clone instance created", "clone", name, "backup", backup.Name)
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.CloneCreated, "Created instance %q to restore backup %s (type %s) into",
+		name, backup.Status.BackupID, inst.Spec.Restore.BackupType)
+	lastClone := &v1alpha1.CloneStatus{
+		InstanceName: name,
+		RequestTime:  *inst.Spec.Restore.RequestTime.DeepCopy(),
+		BackupID:     backup.Status.BackupID,
+	}
+	inst.Spec.Restore = nil
+	if err := r.Update(ctx, inst); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update instance spec: %v", err)
+	}
+	inst.Status.LastClone = lastClone
+	return ctrl.Result{}, nil
+}
+
+// Update spec and status of the instance to reflect clone failure.
+func (r *InstanceReconciler) setCloneFailed(ctx context.Context, inst *v1alpha1.Instance, reason string, log logr.Logger) error {
+	log.Error(goerrors.New(reason), "Clone failed")
+	r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.CloneFailed, reason)
+	lastClone := &v1alpha1.CloneStatus{
+		InstanceName: inst.Spec.Restore.CloneToNewInstance.InstanceName,
+		RequestTime:  *inst.Spec.Restore.RequestTime.DeepCopy(),
+		Message:      reason,
+	}
+	// Remove restore spec. Update the inst object in place.
+	inst.Spec.Restore = nil
+	if err := r.Update(ctx, inst); err != nil {
+		return fmt.Errorf("failed to update instance spec: %v", err)
+	}
+	inst.Status.LastClone = lastClone
+	return nil
+}
+
+// changeCloneDBID gives the database restored into a clone a DBID of its
+// own, so that RMAN, e.g. a recovery catalog, doesn't mistake it for the
+// database it was cloned from. NID requires a mounted database and leaves
+// it shut down, it is then opened with new redo logs.
+func (r *InstanceReconciler) changeCloneDBID(ctx context.Context, inst *v1alpha1.Instance) error {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName: inst.Spec.CDBName,
+		Option:       "immediate",
+	}); err != nil {
+		return fmt.Errorf("shutdown failed: %v", err)
+	}
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
+		DatabaseName: inst.Spec.CDBName,
+		Option:       "mount",
+	}); err != nil {
+		return fmt.Errorf("startup mount failed: %v", err)
+	}
+	if _, err := dbClient.NID(ctx, &dbdpb.NIDRequest{Sid: inst.Spec.CDBName}); err != nil {
+		return fmt.Errorf("nid failed: %v", err)
+	}
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
+		DatabaseName: inst.Spec.CDBName,
+		Option:       "mount",
+	}); err != nil {
+		return fmt.Errorf("startup mount after nid failed: %v", err)
+	}
+	if _, err := dbClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{"alter database open resetlogs", "alter pluggable database all open"},
+	}); err != nil {
+		return fmt.Errorf("resetlogs open failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestNewCloneInstance(t *testing.T) {
+	requestTime := metav1.NewTime(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	source := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+		Spec: v1alpha1.InstanceSpec{
+			CDBName:             "GCLOUD",
+			DBUniqueName:        "GCLOUD_A",
+			ReplicationSettings: &v1alpha1.ReplicationSettings{PrimaryHost: "primary"},
+			RMANCatalogExport:   &v1alpha1.RMANCatalogExportSpec{},
+			Restore: &v1alpha1.RestoreSpec{
+				BackupType:         "Physical",
+				PITRRestore:        &v1alpha1.PITRRestoreSpec{SCN: "1234"},
+				RequestTime:        requestTime,
+				CloneToNewInstance: &v1alpha1.CloneSpec{InstanceName: "mydb-dev"},
+			},
+		},
+	}
+	pitrRef := &v1alpha1.PITRReference{Namespace: "db", Name: "mydb-pitr"}
+
+	got := newCloneInstance(source, pitrRef)
+
+	want := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "db",
+			Name:        "mydb-dev",
+			Annotations: map[string]string{controllers.ClonedFromAnnotation: "mydb"},
+		},
+		Spec: v1alpha1.InstanceSpec{
+			CDBName: "GCLOUD",
+			Restore: &v1alpha1.RestoreSpec{
+				BackupType:  "Physical",
+				PITRRestore: &v1alpha1.PITRRestoreSpec{SCN: "1234", PITRRef: pitrRef},
+				Force:       true,
+				RequestTime: requestTime,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("newCloneInstance got unexpected instance (-want +got):\n%v", diff)
+	}
+	if source.Spec.Restore.CloneToNewInstance == nil || source.Spec.ReplicationSettings == nil {
+		t.Errorf("newCloneInstance modified the source instance: %+v", source.Spec)
+	}
+}
+
+func TestCloneToNewInstance(t *testing.T) {
+	requestTime := metav1.NewTime(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	tests := []struct {
+		name          string
+		cloneName     string
+		lastClone     *v1alpha1.CloneStatus
+		existing      *v1alpha1.Instance
+		localBackup   bool
+		wantCreated   bool
+		wantLastClone *v1alpha1.CloneStatus
+	}{
+		{
+			name:          "clone created",
+			cloneName:     "mydb-dev",
+			wantCreated:   true,
+			wantLastClone: &v1alpha1.CloneStatus{InstanceName: "mydb-dev", RequestTime: requestTime, BackupID: "mydb-backup-id"},
+		},
+		{
+			name:          "clone into itself",
+			cloneName:     "mydb",
+			wantLastClone: &v1alpha1.CloneStatus{InstanceName: "mydb", RequestTime: requestTime, Message: "an instance can't be cloned into itself"},
+		},
+		{
+			name:          "instance already exists",
+			cloneName:     "mydb-dev",
+			existing:      &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-dev"}},
+			wantLastClone: &v1alpha1.CloneStatus{InstanceName: "mydb-dev", RequestTime: requestTime, Message: `instance "mydb-dev" already exists`},
+		},
+		{
+			name:          "local backup",
+			cloneName:     "mydb-dev",
+			localBackup:   true,
+			wantLastClone: &v1alpha1.CloneStatus{InstanceName: "mydb-dev", RequestTime: requestTime, Message: "only physical backups stored in GCS can be cloned"},
+		},
+		{
+			name:          "request already handled",
+			cloneName:     "mydb-dev",
+			lastClone:     &v1alpha1.CloneStatus{InstanceName: "mydb-dev", RequestTime: requestTime},
+			wantLastClone: &v1alpha1.CloneStatus{InstanceName: "mydb-dev", RequestTime: requestTime},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+				Spec: v1alpha1.InstanceSpec{
+					CDBName: "GCLOUD",
+					Restore: &v1alpha1.RestoreSpec{
+						BackupType:         "Physical",
+						BackupID:           "mydb-backup-id",
+						RequestTime:        requestTime,
+						CloneToNewInstance: &v1alpha1.CloneSpec{InstanceName: tc.cloneName},
+					},
+				},
+				Status: v1alpha1.InstanceStatus{LastClone: tc.lastClone},
+			}
+			backup := &v1alpha1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-backup"},
+				Spec:       v1alpha1.BackupSpec{BackupSpec: commonv1alpha1.BackupSpec{Instance: "mydb", Type: "Physical"}},
+				Status: v1alpha1.BackupStatus{
+					BackupStatus: commonv1alpha1.BackupStatus{
+						Conditions: []metav1.Condition{{Type: k8s.Ready, Status: metav1.ConditionTrue, Reason: k8s.BackupReady}},
+					},
+					BackupID: "mydb-backup-id",
+				},
+			}
+			if !tc.localBackup {
+				backup.Spec.GcsPath = "gs://bucket/mydb"
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(inst, backup)
+			if tc.existing != nil {
+				builder = builder.WithObjects(tc.existing)
+			}
+			r := &InstanceReconciler{
+				Client:                builder.Build(),
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: &testhelpers.FakeDatabaseClientFactory{},
+			}
+
+			if _, err := r.cloneToNewInstance(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("cloneToNewInstance failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantLastClone, inst.Status.LastClone); diff != "" {
+				t.Errorf("cloneToNewInstance got unexpected last clone (-want +got):\n%v", diff)
+			}
+			var clone v1alpha1.Instance
+			err := r.Get(context.Background(), types.NamespacedName{Namespace: "db", Name: "mydb-dev"}, &clone)
+			created := err == nil && clone.Annotations[controllers.ClonedFromAnnotation] == "mydb"
+			if created != tc.wantCreated {
+				t.Errorf("cloneToNewInstance created the clone: %v, want %v", created, tc.wantCreated)
+			}
+			if created && (clone.Spec.Restore == nil || clone.Spec.Restore.BackupID != "mydb-backup-id" || !clone.Spec.Restore.Force) {
+				t.Errorf("cloneToNewInstance created a clone with restore spec %+v, want a forced restore of mydb-backup-id", clone.Spec.Restore)
+			}
+			if tc.lastClone == nil && inst.Spec.Restore != nil {
+				t.Errorf("cloneToNewInstance kept the restore spec %+v, want it removed", inst.Spec.Restore)
+			}
+		})
+	}
+}
+
+func TestChangeCloneDBID(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{DatabaseClientFactory: factory}
+	inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{CDBName: "GCLOUD"}}
+
+	if err := r.changeCloneDBID(context.Background(), inst); err != nil {
+		t.Fatalf("changeCloneDBID failed: %v", err)
+	}
+	if got := factory.Dbclient.NIDCalledCnt(); got != 1 {
+		t.Errorf("changeCloneDBID called NID %d times, want 1", got)
+	}
+	if got := factory.Dbclient.GotRunSQLPlusRequest.GetCommands(); len(got) == 0 || got[0] != "alter database open resetlogs" {
+		t.Errorf("changeCloneDBID ran %v, want the database opened with resetlogs", got)
+	}
+}
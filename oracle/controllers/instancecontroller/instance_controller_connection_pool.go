@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// connectionPoolImage returns the image of the pooler of spec. The proxy
+// image of the operator is the default of the Proxy type, Connection
+// Manager is only in images of Oracle client installations users supply.
+func (r *InstanceReconciler) connectionPoolImage(spec *v1alpha1.ConnectionPoolSpec) (string, error) {
+	if spec.Image != "" {
+		return spec.Image, nil
+	}
+	if controllers.ConnectionPoolType(spec) == v1alpha1.ConnectionPoolCMAN {
+		return "", fmt.Errorf("the %s connection pool requires an image", v1alpha1.ConnectionPoolCMAN)
+	}
+	if r.ConnectionPoolImage == "" {
+		return "", fmt.Errorf("no connection pool image is configured for the operator")
+	}
+	return r.ConnectionPoolImage, nil
+}
+
+// reconcileConnectionPool deploys the connection pooler of
+// spec.connectionPool in front of the instance, a Deployment configured by
+// a ConfigMap and exposed by its own Service. The pooler resources are
+// deleted when the spec is removed.
+func (r *InstanceReconciler) reconcileConnectionPool(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	name := fmt.Sprintf(controllers.ConnectionPoolName, inst.Name)
+	svcName := fmt.Sprintf(controllers.ConnectionPoolSvcName, inst.Name)
+	spec := inst.Spec.ConnectionPool
+	if spec == nil {
+		if inst.Status.ConnectionPool == nil {
+			return nil
+		}
+		for _, obj := range []client.Object{
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: svcName}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name}},
+		} {
+			if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete the connection pool %T %q: %v", obj, obj.GetName(), err)
+			}
+		}
+		inst.Status.ConnectionPool = nil
+		log.Info("connection pool removed")
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.ConnectionPoolRemoved, "Connection pool removed")
+		return nil
+	}
+
+	if err := r.applyConnectionPool(ctx, inst, name, svcName); err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ConnectionPoolFailed, "Failed to deploy the connection pool: %v", err)
+		return err
+	}
+
+	var deployment appsv1.Deployment
+	if err := r.Get(ctx, client.ObjectKey{Namespace: inst.Namespace, Name: name}, &deployment); err != nil {
+		return err
+	}
+	want := &v1alpha1.ConnectionPoolStatus{
+		Endpoint:      fmt.Sprintf("%s:%d", fmt.Sprintf(controllers.SvcEndpoint, svcName, inst.Namespace), consts.SecureListenerPort),
+		ReadyReplicas: deployment.Status.ReadyReplicas,
+	}
+	if last := inst.Status.ConnectionPool; last == nil || last.Endpoint != want.Endpoint {
+		log.Info("connection pool deployed", "type", controllers.ConnectionPoolType(spec), "endpoint", want.Endpoint)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.ConnectionPoolConfigured, "%s connection pool deployed, clients connect to %s", controllers.ConnectionPoolType(spec), want.Endpoint)
+	}
+	inst.Status.ConnectionPool = want
+	return nil
+}
+
+// applyConnectionPool creates or updates the ConfigMap and Deployment name
+// and the Service svcName of the pooler.
+func (r *InstanceReconciler) applyConnectionPool(ctx context.Context, inst *v1alpha1.Instance, name, svcName string) error {
+	spec := inst.Spec.ConnectionPool
+	image, err := r.connectionPoolImage(spec)
+	if err != nil {
+		return err
+	}
+	config, err := controllers.ConnectionPoolConfig(inst)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name}}
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Data = config
+		return ctrl.SetControllerReference(inst, cm, r.Scheme())
+	}); err != nil {
+		return fmt.Errorf("failed to apply ConfigMap %q: %v", name, err)
+	}
+
+	replicas := pointer.Int32(controllers.DefaultReplicaCnt)
+	if spec.Replicas != nil {
+		replicas = spec.Replicas
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name}}
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, deployment, func() error {
+		deployment.Spec.Replicas = replicas
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: controllers.ConnectionPoolLabels(inst)}
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
+		deployment.Spec.Template = controllers.ConnectionPoolPodTemplate(inst, image, name, controllers.ConnectionPoolConfigHash(config))
+		return ctrl.SetControllerReference(inst, deployment, r.Scheme())
+	}); err != nil {
+		return fmt.Errorf("failed to apply Deployment %q: %v", name, err)
+	}
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: svcName}}
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		want := controllers.ConnectionPoolServiceSpec(inst)
+		svc.Spec.Type = want.Type
+		svc.Spec.Selector = want.Selector
+		svc.Spec.Ports = want.Ports
+		svc.Spec.LoadBalancerSourceRanges = want.LoadBalancerSourceRanges
+		return ctrl.SetControllerReference(inst, svc, r.Scheme())
+	}); err != nil {
+		return fmt.Errorf("failed to apply Service %q: %v", svcName, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+)
+
+func TestReconcileConnectionPool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"}}
+	r := &InstanceReconciler{
+		Client:              fake.NewClientBuilder().WithScheme(scheme).WithObjects(inst).Build(),
+		SchemeVal:           scheme,
+		Recorder:            record.NewFakeRecorder(10),
+		ConnectionPoolImage: "haproxy:2.6",
+	}
+	ctx := context.Background()
+	name := types.NamespacedName{Namespace: "db", Name: "mydb-pool"}
+	svcName := types.NamespacedName{Namespace: "db", Name: "mydb-pool-svc"}
+
+	steps := []struct {
+		name         string
+		spec         *v1alpha1.ConnectionPoolSpec
+		wantErr      bool
+		wantImage    string
+		wantReplicas int32
+		wantSvcType  corev1.ServiceType
+	}{
+		{
+			name: "never configured",
+		},
+		{
+			name:         "proxy",
+			spec:         &v1alpha1.ConnectionPoolSpec{},
+			wantImage:    "haproxy:2.6",
+			wantReplicas: 1,
+			wantSvcType:  corev1.ServiceTypeClusterIP,
+		},
+		{
+			name:    "cman without an image",
+			spec:    &v1alpha1.ConnectionPoolSpec{Type: v1alpha1.ConnectionPoolCMAN},
+			wantErr: true,
+			// The deployed pooler is left alone.
+			wantImage:    "haproxy:2.6",
+			wantReplicas: 1,
+			wantSvcType:  corev1.ServiceTypeClusterIP,
+		},
+		{
+			name:         "cman",
+			spec:         &v1alpha1.ConnectionPoolSpec{Type: v1alpha1.ConnectionPoolCMAN, Image: "cman:19", Replicas: pointer.Int32(2), ServiceType: corev1.ServiceTypeLoadBalancer},
+			wantImage:    "cman:19",
+			wantReplicas: 2,
+			wantSvcType:  corev1.ServiceTypeLoadBalancer,
+		},
+		{
+			name: "spec removed",
+		},
+	}
+	for _, step := range steps {
+		inst.Spec.ConnectionPool = step.spec
+		err := r.reconcileConnectionPool(ctx, inst, logr.Discard())
+		if gotErr := err != nil; gotErr != step.wantErr {
+			t.Fatalf("%s: reconcileConnectionPool got error %v, want error: %v", step.name, err, step.wantErr)
+		}
+
+		var deployment appsv1.Deployment
+		var svc corev1.Service
+		var cm corev1.ConfigMap
+		if step.wantImage == "" {
+			for _, obj := range []client.Object{&deployment, &svc, &cm} {
+				key := name
+				if obj == &svc {
+					key = svcName
+				}
+				if err := r.Get(ctx, key, obj); !apierrors.IsNotFound(err) {
+					t.Errorf("%s: got %T %s error %v, want not found", step.name, obj, key, err)
+				}
+			}
+			if inst.Status.ConnectionPool != nil {
+				t.Errorf("%s: got connection pool status %+v, want nil", step.name, inst.Status.ConnectionPool)
+			}
+			continue
+		}
+
+		if err := r.Get(ctx, name, &deployment); err != nil {
+			t.Fatalf("%s: failed to get the pooler Deployment: %v", step.name, err)
+		}
+		if got := deployment.Spec.Template.Spec.Containers[0].Image; got != step.wantImage {
+			t.Errorf("%s: got pooler image %q, want %q", step.name, got, step.wantImage)
+		}
+		if got := *deployment.Spec.Replicas; got != step.wantReplicas {
+			t.Errorf("%s: got %d pooler replicas, want %d", step.name, got, step.wantReplicas)
+		}
+		if err := r.Get(ctx, name, &cm); err != nil {
+			t.Fatalf("%s: failed to get the pooler ConfigMap: %v", step.name, err)
+		}
+		if got, want := deployment.Spec.Template.Annotations[controllers.ConnectionPoolConfigHashAnnotation], controllers.ConnectionPoolConfigHash(cm.Data); got != want {
+			t.Errorf("%s: got pooler config hash %q, want %q of the ConfigMap", step.name, got, want)
+		}
+		if err := r.Get(ctx, svcName, &svc); err != nil {
+			t.Fatalf("%s: failed to get the pooler Service: %v", step.name, err)
+		}
+		if svc.Spec.Type != step.wantSvcType {
+			t.Errorf("%s: got pooler Service type %q, want %q", step.name, svc.Spec.Type, step.wantSvcType)
+		}
+		if got, want := inst.Status.ConnectionPool, (&v1alpha1.ConnectionPoolStatus{Endpoint: "mydb-pool-svc.db:6021"}); got == nil || *got != *want {
+			t.Errorf("%s: got connection pool status %+v, want %+v", step.name, got, want)
+		}
+	}
+}
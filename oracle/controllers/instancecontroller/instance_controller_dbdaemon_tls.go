@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// dbdaemonTLSSecretName returns the name of the TLS Secret of the database
+// daemon of the instance, empty if the daemon serves plaintext.
+func (r *InstanceReconciler) dbdaemonTLSSecretName(ctx context.Context, inst *v1alpha1.Instance) (string, error) {
+	name := fmt.Sprintf(controllers.DBDaemonTLSSecretName, inst.Name)
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: name}, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+// reconcileDBDaemonTLS issues the certificates the database daemon of the
+// instance and its clients authenticate each other with, and renews them
+// before they expire. Certificates are only issued to new instances: the
+// pods of an existing instance aren't re-rendered with them. A TLS Secret
+// without a CA Secret was issued outside of the operator, e.g. by
+// cert-manager, and is left to its issuer to renew.
+// Returns the name of the TLS Secret, empty if the daemon serves plaintext.
+func (r *InstanceReconciler) reconcileDBDaemonTLS(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (string, error) {
+	name := fmt.Sprintf(controllers.DBDaemonTLSSecretName, inst.Name)
+	caName := fmt.Sprintf(controllers.DBDaemonCASecretName, inst.Name)
+	now := time.Now()
+
+	tlsSecret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: name}, tlsSecret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	if apierrors.IsNotFound(err) {
+		if r.DBDaemonPlaintext {
+			return "", nil
+		}
+		var sts appsv1.StatefulSet
+		if err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: fmt.Sprintf(controllers.StsName, inst.Name)}, &sts); err == nil {
+			return "", nil
+		} else if !apierrors.IsNotFound(err) {
+			return "", err
+		}
+
+		ca := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: caName}, ca); apierrors.IsNotFound(err) {
+			if ca, err = controllers.NewDBDaemonCA(inst, now); err != nil {
+				return "", fmt.Errorf("failed to create the dbdaemon CA: %v", err)
+			}
+			if err := ctrl.SetControllerReference(inst, ca, r.Scheme()); err != nil {
+				return "", err
+			}
+			if err := r.Create(ctx, ca); err != nil {
+				return "", fmt.Errorf("failed to create the dbdaemon CA Secret: %v", err)
+			}
+		} else if err != nil {
+			return "", err
+		}
+
+		tlsSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: inst.Namespace}}
+		if err := controllers.IssueDBDaemonCert(ca, tlsSecret, now); err != nil {
+			return "", fmt.Errorf("failed to issue the dbdaemon certificate: %v", err)
+		}
+		if err := ctrl.SetControllerReference(inst, tlsSecret, r.Scheme()); err != nil {
+			return "", err
+		}
+		if err := r.Create(ctx, tlsSecret); err != nil {
+			return "", fmt.Errorf("failed to create the dbdaemon TLS Secret: %v", err)
+		}
+		log.Info("issued the dbdaemon certificate", "secret", name)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.DBDaemonCertIssued, "Issued the database daemon certificate into Secret %q", name)
+		return name, nil
+	}
+
+	if !controllers.DBDaemonCertNeedsRenewal(tlsSecret, now) {
+		return name, nil
+	}
+	ca := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: caName}, ca); apierrors.IsNotFound(err) {
+		log.Info("dbdaemon certificate is due for renewal but wasn't issued by the operator", "secret", name)
+		return name, nil
+	} else if err != nil {
+		return "", err
+	}
+	if err := controllers.IssueDBDaemonCert(ca, tlsSecret, now); err != nil {
+		return "", fmt.Errorf("failed to renew the dbdaemon certificate: %v", err)
+	}
+	if err := r.Update(ctx, tlsSecret); err != nil {
+		return "", fmt.Errorf("failed to update the dbdaemon TLS Secret: %v", err)
+	}
+	log.Info("renewed the dbdaemon certificate", "secret", name)
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.DBDaemonCertRenewed, "Renewed the database daemon certificate in Secret %q", name)
+	return name, nil
+}
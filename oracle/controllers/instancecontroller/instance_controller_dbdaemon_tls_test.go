@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+)
+
+func TestReconcileDBDaemonTLS(t *testing.T) {
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"}}
+	ca, err := controllers.NewDBDaemonCA(inst, time.Now())
+	if err != nil {
+		t.Fatalf("NewDBDaemonCA failed: %v", err)
+	}
+	issued := func(now time.Time) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-dbdaemon-tls"}}
+		if err := controllers.IssueDBDaemonCert(ca, s, now); err != nil {
+			t.Fatalf("IssueDBDaemonCert failed: %v", err)
+		}
+		return s
+	}
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-sts"}}
+	fresh := issued(time.Now())
+	expiring := issued(time.Now().Add(-80 * 24 * time.Hour))
+	external := expiring.DeepCopy()
+
+	tests := []struct {
+		name        string
+		plaintext   bool
+		objects     []client.Object
+		want        string
+		wantValid   bool
+		wantRenewed bool
+	}{
+		{
+			name:      "new instance",
+			want:      "mydb-dbdaemon-tls",
+			wantValid: true,
+		},
+		{
+			name:      "new instance in plaintext mode",
+			plaintext: true,
+		},
+		{
+			name:    "existing instance",
+			objects: []client.Object{sts},
+		},
+		{
+			name:      "valid certificate",
+			objects:   []client.Object{sts, ca.DeepCopy(), fresh.DeepCopy()},
+			want:      "mydb-dbdaemon-tls",
+			wantValid: true,
+		},
+		{
+			name:        "expiring certificate",
+			objects:     []client.Object{sts, ca.DeepCopy(), expiring.DeepCopy()},
+			want:        "mydb-dbdaemon-tls",
+			wantValid:   true,
+			wantRenewed: true,
+		},
+		{
+			name:    "expiring certificate issued outside of the operator",
+			objects: []client.Object{sts, external},
+			want:    "mydb-dbdaemon-tls",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			r := &InstanceReconciler{
+				Client:            fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(),
+				SchemeVal:         scheme,
+				Recorder:          record.NewFakeRecorder(10),
+				DBDaemonPlaintext: tc.plaintext,
+			}
+
+			got, err := r.reconcileDBDaemonTLS(context.Background(), inst.DeepCopy(), logr.Discard())
+			if err != nil {
+				t.Fatalf("reconcileDBDaemonTLS failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("reconcileDBDaemonTLS got TLS Secret %q, want %q", got, tc.want)
+			}
+			if tc.want == "" {
+				return
+			}
+			var tlsSecret corev1.Secret
+			if err := r.Get(context.Background(), types.NamespacedName{Namespace: "db", Name: tc.want}, &tlsSecret); err != nil {
+				t.Fatalf("failed to get the TLS Secret: %v", err)
+			}
+			if valid := !controllers.DBDaemonCertNeedsRenewal(&tlsSecret, time.Now()); valid != tc.wantValid {
+				t.Errorf("reconcileDBDaemonTLS left a certificate valid: %v, want %v", valid, tc.wantValid)
+			}
+			renewed := !bytes.Equal(tlsSecret.Data[common.TLSCertFile], expiring.Data[common.TLSCertFile])
+			if tc.wantRenewed && !renewed {
+				t.Errorf("reconcileDBDaemonTLS didn't renew the expiring certificate")
+			}
+		})
+	}
+}
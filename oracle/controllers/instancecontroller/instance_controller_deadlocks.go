@@ -0,0 +1,100 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	// deadlockScanInterval is the delay between scans of the alert log for
+	// deadlocks.
+	deadlockScanInterval = 5 * time.Minute
+	// maxDeadlockEvents bounds the events emitted by a scan, the status
+	// still counts all the deadlocks found.
+	maxDeadlockEvents = 5
+)
+
+// deadlocksSince returns the time after which the deadlocks are new. The
+// scans overlap by deadlockScanInterval in case the database and operator
+// clocks differ, the deadlocks already reported are skipped by their time.
+func deadlocksSince(status *v1alpha1.DeadlockStatus, now time.Time) time.Time {
+	if status == nil {
+		return now.Add(-deadlockScanInterval)
+	}
+	since := status.LastScanTime.Add(-deadlockScanInterval)
+	if status.LastDeadlockTime != nil && status.LastDeadlockTime.After(since) {
+		since = status.LastDeadlockTime.Time
+	}
+	return since
+}
+
+// reconcileDeadlocks scans the alert log for deadlocks every
+// deadlockScanInterval, emits an event summarizing each new deadlock and
+// keeps the most recent one in the instance status.
+func (r *InstanceReconciler) reconcileDeadlocks(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	now := time.Now()
+	if status := inst.Status.Deadlocks; status != nil && now.Sub(status.LastScanTime.Time) < deadlockScanInterval {
+		return ctrl.Result{RequeueAfter: deadlockScanInterval - now.Sub(status.LastScanTime.Time)}, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetDeadlocks(ctx, &dbdpb.GetDeadlocksRequest{Since: timestamppb.New(deadlocksSince(inst.Status.Deadlocks, now))})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := &v1alpha1.DeadlockStatus{}
+	if inst.Status.Deadlocks != nil {
+		status = inst.Status.Deadlocks.DeepCopy()
+	}
+	status.LastScanTime = v1.NewTime(now)
+	deadlocks := resp.GetDeadlocks()
+	first := len(deadlocks) - maxDeadlockEvents
+	if first < 0 {
+		first = 0
+	}
+	for _, d := range deadlocks[first:] {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DeadlockDetected, "Deadlock at %s: %s, see trace file %s",
+			d.GetTime().AsTime().Format(time.RFC3339), d.GetSummary(), d.GetTraceFile())
+	}
+	if n := len(deadlocks); n > 0 {
+		last := deadlocks[n-1]
+		log.Info("deadlocks detected", "count", n, "last", last.GetSummary())
+		status.Count += int64(n)
+		lastTime := v1.NewMicroTime(last.GetTime().AsTime())
+		status.LastDeadlockTime = &lastTime
+		status.LastDeadlock = last.GetSummary()
+		status.LastTraceFile = last.GetTraceFile()
+	}
+	inst.Status.Deadlocks = status
+	return ctrl.Result{RequeueAfter: deadlockScanInterval}, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestDeadlocksSince(t *testing.T) {
+	now := time.Now()
+	lastDeadlock := metav1.NewMicroTime(now.Add(-time.Minute))
+	oldDeadlock := metav1.NewMicroTime(now.Add(-time.Hour))
+	tests := []struct {
+		name   string
+		status *v1alpha1.DeadlockStatus
+		want   time.Time
+	}{
+		{
+			name: "first scan",
+			want: now.Add(-deadlockScanInterval),
+		},
+		{
+			name:   "no deadlock",
+			status: &v1alpha1.DeadlockStatus{LastScanTime: metav1.NewTime(now.Add(-10 * time.Minute))},
+			want:   now.Add(-10*time.Minute - deadlockScanInterval),
+		},
+		{
+			name:   "recent deadlock",
+			status: &v1alpha1.DeadlockStatus{LastScanTime: metav1.NewTime(now), LastDeadlockTime: &lastDeadlock},
+			want:   lastDeadlock.Time,
+		},
+		{
+			name:   "old deadlock",
+			status: &v1alpha1.DeadlockStatus{LastScanTime: metav1.NewTime(now), LastDeadlockTime: &oldDeadlock},
+			want:   now.Add(-deadlockScanInterval),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := deadlocksSince(tc.status, now); !got.Equal(tc.want) {
+				t.Errorf("deadlocksSince got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDeadlocks(t *testing.T) {
+	now := time.Now()
+	deadlockTime := now.Add(-time.Minute).Truncate(time.Microsecond)
+	deadlock := &dbdpb.GetDeadlocksResponse_Deadlock{
+		Time:      timestamppb.New(deadlockTime),
+		TraceFile: "/u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc",
+		Summary:   "sessions 191 (SCOTT), 195 (APP) deadlocked on SCOTT.EMP in PDB1",
+	}
+	var many []*dbdpb.GetDeadlocksResponse_Deadlock
+	for i := 0; i < maxDeadlockEvents+2; i++ {
+		many = append(many, deadlock)
+	}
+	tests := []struct {
+		name       string
+		status     *v1alpha1.DeadlockStatus
+		deadlocks  []*dbdpb.GetDeadlocksResponse_Deadlock
+		err        error
+		wantErr    bool
+		wantCalls  int
+		wantCount  int64
+		wantEvents int
+	}{
+		{
+			name:      "scanned recently",
+			status:    &v1alpha1.DeadlockStatus{LastScanTime: metav1.NewTime(now.Add(-time.Minute)), Count: 1},
+			wantCalls: 0,
+			wantCount: 1,
+		},
+		{
+			name:      "no deadlock",
+			wantCalls: 1,
+		},
+		{
+			name:       "new deadlock",
+			status:     &v1alpha1.DeadlockStatus{LastScanTime: metav1.NewTime(now.Add(-time.Hour)), Count: 1},
+			deadlocks:  []*dbdpb.GetDeadlocksResponse_Deadlock{deadlock},
+			wantCalls:  1,
+			wantCount:  2,
+			wantEvents: 1,
+		},
+		{
+			name:       "many deadlocks",
+			deadlocks:  many,
+			wantCalls:  1,
+			wantCount:  maxDeadlockEvents + 2,
+			wantEvents: maxDeadlockEvents,
+		},
+		{
+			name:      "failed",
+			err:       errors.New("failed to read the alert log"),
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("GetDeadlocks", &dbdpb.GetDeadlocksResponse{Deadlocks: tc.deadlocks})
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("GetDeadlocks", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{Deadlocks: tc.status}}
+
+			result, err := r.reconcileDeadlocks(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileDeadlocks got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.GetDeadlocksCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileDeadlocks called GetDeadlocks %d times, want %d", got, tc.wantCalls)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileDeadlocks emitted %d events, want %d", got, tc.wantEvents)
+			}
+			if tc.wantErr {
+				return
+			}
+			if result.RequeueAfter <= 0 || result.RequeueAfter > deadlockScanInterval {
+				t.Errorf("reconcileDeadlocks got requeue after %v, want up to %v", result.RequeueAfter, deadlockScanInterval)
+			}
+			status := inst.Status.Deadlocks
+			if status == nil {
+				t.Fatalf("reconcileDeadlocks didn't set the deadlock status")
+			}
+			if status.Count != tc.wantCount {
+				t.Errorf("reconcileDeadlocks got count %d, want %d", status.Count, tc.wantCount)
+			}
+			if tc.status != nil && tc.wantCalls > 0 {
+				want := tc.status.LastScanTime.Add(-deadlockScanInterval)
+				if got := factory.Dbclient.GotGetDeadlocksRequest.GetSince().AsTime(); !got.Equal(want) {
+					t.Errorf("reconcileDeadlocks asked for the deadlocks since %v, want since %v", got, want)
+				}
+			}
+			if len(tc.deadlocks) == 0 {
+				return
+			}
+			if status.LastDeadlockTime == nil || !status.LastDeadlockTime.Time.Equal(deadlockTime) || status.LastDeadlock != deadlock.GetSummary() || status.LastTraceFile != deadlock.GetTraceFile() {
+				t.Errorf("reconcileDeadlocks got status %+v, want the last deadlock at %v", status, deadlockTime)
+			}
+		})
+	}
+}
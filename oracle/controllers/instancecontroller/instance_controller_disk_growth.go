@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// diskGrowthPollInterval is the delay between checks of a filesystem which
+// did not grow yet.
+const diskGrowthPollInterval = time.Minute
+
+// pvcResizeRequest returns a copy of the PVC requesting newSize, nil if the
+// PVC requests it already.
+func pvcResizeRequest(pvc *corev1.PersistentVolumeClaim, newSize resource.Quantity) *corev1.PersistentVolumeClaim {
+	if pvc.Spec.Resources.Requests.Storage().Equal(newSize) {
+		return nil
+	}
+	resized := pvc.DeepCopy()
+	if resized.Spec.Resources.Requests == nil {
+		resized.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	resized.Spec.Resources.Requests[corev1.ResourceStorage] = newSize
+	return resized
+}
+
+// pvcResizeState reports whether the expansion of the PVC to newSize
+// completed, or whether its volume is expanded and its filesystem is only
+// resized once the volume is mounted again.
+func pvcResizeState(pvc *corev1.PersistentVolumeClaim, newSize resource.Quantity) (expanded, fsResizePending bool) {
+	if pvc.Status.Capacity.Storage().Cmp(newSize) >= 0 {
+		return true, false
+	}
+	for _, c := range pvc.Status.Conditions {
+		if c.Type == corev1.PersistentVolumeClaimFileSystemResizePending && c.Status == corev1.ConditionTrue {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// pvcMountPath returns where the database container mounts the volume claim
+// template name of the statefulset, empty if it doesn't.
+func pvcMountPath(sts *appsv1.StatefulSet, name string) string {
+	dbContainer := findContainer(sts.Spec.Template.Spec.Containers, controllers.DatabaseContainerName)
+	if dbContainer == nil {
+		return ""
+	}
+	for _, m := range dbContainer.VolumeMounts {
+		if m.Name == name {
+			return m.MountPath
+		}
+	}
+	return ""
+}
+
+// recordDiskGrowth tracks the disks whose PVCs were expanded until
+// reconcileDiskGrowth verifies the database can use the new space.
+func recordDiskGrowth(inst *v1alpha1.Instance, sts *appsv1.StatefulSet, pvcs []*corev1.PersistentVolumeClaim) {
+	for _, pvc := range pvcs {
+		mount := pvcMountPath(sts, pvc.GetName())
+		if mount == "" {
+			continue
+		}
+		growth := v1alpha1.DiskGrowthStatus{Mount: mount, Size: pvc.Spec.Resources.Requests.Storage().DeepCopy()}
+		found := false
+		for i := range inst.Status.DiskGrowth {
+			if inst.Status.DiskGrowth[i].Mount == mount {
+				inst.Status.DiskGrowth[i] = growth
+				found = true
+			}
+		}
+		if !found {
+			inst.Status.DiskGrowth = append(inst.Status.DiskGrowth, growth)
+		}
+	}
+}
+
+// reconcileDiskGrowth verifies the filesystems of the expanded disks grew,
+// which lets the dbdaemon lift the autoextend limit of the datafiles close
+// to it, and polls the filesystems which did not grow yet.
+func (r *InstanceReconciler) reconcileDiskGrowth(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	var pending []int
+	for i, g := range inst.Status.DiskGrowth {
+		if !g.Verified {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	var result ctrl.Result
+	for _, i := range pending {
+		g := &inst.Status.DiskGrowth[i]
+		resp, err := dbClient.GrowMount(ctx, &dbdpb.GrowMountRequest{Mount: g.Mount, ExpectedBytes: g.Size.Value()})
+		if err != nil {
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DiskGrowthFailed, "Failed to verify the growth of %s: %v", g.Mount, err)
+			return ctrl.Result{}, err
+		}
+		g.FilesystemBytes = resp.GetTotalBytes()
+		if !resp.GetGrown() {
+			log.Info("filesystem did not grow yet", "mount", g.Mount, "size", g.Size.String(), "filesystemBytes", g.FilesystemBytes)
+			result = ctrl.Result{RequeueAfter: diskGrowthPollInterval}
+			continue
+		}
+		g.Verified = true
+		g.AutoextendedDatafiles = resp.GetAutoextendedDatafiles()
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.DiskGrown, "Filesystem %s grew to %.1f GiB, raised the autoextend limit of %d datafiles",
+			g.Mount, float64(g.FilesystemBytes)/(1<<30), len(g.AutoextendedDatafiles))
+	}
+	return result, nil
+}
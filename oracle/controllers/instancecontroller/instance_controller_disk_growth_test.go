@@ -0,0 +1,219 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func newTestPVC(request, capacity string, conditions ...corev1.PersistentVolumeClaimCondition) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvc.Name = "mydb-pvc-u02-mydb-sts-0"
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(request)}
+	if capacity != "" {
+		pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)}
+	}
+	pvc.Status.Conditions = conditions
+	return pvc
+}
+
+func TestPVCResizeRequest(t *testing.T) {
+	newSize := resource.MustParse("200Gi")
+
+	pvc := newTestPVC("100Gi", "100Gi")
+	got := pvcResizeRequest(pvc, newSize)
+	if got == nil {
+		t.Fatal("pvcResizeRequest returned nil, want a PVC requesting 200Gi")
+	}
+	if size := got.Spec.Resources.Requests.Storage(); !size.Equal(newSize) {
+		t.Errorf("pvcResizeRequest requested %v, want %v", size, newSize)
+	}
+	if size := pvc.Spec.Resources.Requests.Storage(); !size.Equal(resource.MustParse("100Gi")) {
+		t.Errorf("pvcResizeRequest modified the PVC request to %v, want it unchanged", size)
+	}
+	if got.Name != pvc.Name {
+		t.Errorf("pvcResizeRequest got PVC %q, want %q", got.Name, pvc.Name)
+	}
+
+	if got := pvcResizeRequest(newTestPVC("200Gi", "100Gi"), newSize); got != nil {
+		t.Errorf("pvcResizeRequest got %v for a PVC requesting 200Gi already, want nil", got)
+	}
+}
+
+func TestPVCResizeState(t *testing.T) {
+	fsResizePending := corev1.PersistentVolumeClaimCondition{
+		Type:   corev1.PersistentVolumeClaimFileSystemResizePending,
+		Status: corev1.ConditionTrue,
+	}
+	tests := []struct {
+		name                string
+		pvc                 *corev1.PersistentVolumeClaim
+		wantExpanded        bool
+		wantFSResizePending bool
+	}{
+		{
+			name: "in progress",
+			pvc:  newTestPVC("200Gi", "100Gi"),
+		},
+		{
+			name:         "expanded",
+			pvc:          newTestPVC("200Gi", "200Gi"),
+			wantExpanded: true,
+		},
+		{
+			name:         "expanded beyond the request",
+			pvc:          newTestPVC("200Gi", "204Gi"),
+			wantExpanded: true,
+		},
+		{
+			name:                "filesystem resize pending",
+			pvc:                 newTestPVC("200Gi", "100Gi", fsResizePending),
+			wantFSResizePending: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expanded, pending := pvcResizeState(tc.pvc, resource.MustParse("200Gi"))
+			if expanded != tc.wantExpanded || pending != tc.wantFSResizePending {
+				t.Errorf("pvcResizeState got expanded %v, filesystem resize pending %v, want %v and %v", expanded, pending, tc.wantExpanded, tc.wantFSResizePending)
+			}
+		})
+	}
+}
+
+func TestRecordDiskGrowth(t *testing.T) {
+	sts := &appsv1.StatefulSet{}
+	sts.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: controllers.DatabaseContainerName,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "mydb-pvc-u02", MountPath: "/u02"},
+			{Name: "mydb-pvc-u03", MountPath: "/u03"},
+		},
+	}}
+	template := func(name, size string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Name = name
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+		return pvc
+	}
+	inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{DiskGrowth: []v1alpha1.DiskGrowthStatus{
+		{Mount: "/u02", Size: resource.MustParse("150Gi"), FilesystemBytes: 147 << 30, Verified: true},
+	}}}
+
+	recordDiskGrowth(inst, sts, []*corev1.PersistentVolumeClaim{
+		template("mydb-pvc-u02", "200Gi"),
+		template("mydb-pvc-u03", "300Gi"),
+		template("mydb-pvc-unknown", "10Gi"),
+	})
+	want := []v1alpha1.DiskGrowthStatus{
+		{Mount: "/u02", Size: resource.MustParse("200Gi")},
+		{Mount: "/u03", Size: resource.MustParse("300Gi")},
+	}
+	if diff := cmp.Diff(want, inst.Status.DiskGrowth); diff != "" {
+		t.Errorf("recordDiskGrowth got unexpected status (-want +got):\n%v", diff)
+	}
+}
+
+func TestReconcileDiskGrowth(t *testing.T) {
+	tests := []struct {
+		name         string
+		growth       []v1alpha1.DiskGrowthStatus
+		resp         *dbdpb.GrowMountResponse
+		err          error
+		wantErr      bool
+		wantCalls    int
+		wantRequeue  bool
+		wantVerified bool
+	}{
+		{
+			name:         "nothing pending",
+			growth:       []v1alpha1.DiskGrowthStatus{{Mount: "/u02", Size: resource.MustParse("200Gi"), Verified: true}},
+			wantVerified: true,
+		},
+		{
+			name:         "grown",
+			growth:       []v1alpha1.DiskGrowthStatus{{Mount: "/u02", Size: resource.MustParse("200Gi")}},
+			resp:         &dbdpb.GrowMountResponse{TotalBytes: 196 << 30, Grown: true, AutoextendedDatafiles: []string{"/u02/users01.dbf"}},
+			wantCalls:    1,
+			wantVerified: true,
+		},
+		{
+			name:        "not grown yet",
+			growth:      []v1alpha1.DiskGrowthStatus{{Mount: "/u02", Size: resource.MustParse("200Gi")}},
+			resp:        &dbdpb.GrowMountResponse{TotalBytes: 98 << 30},
+			wantCalls:   1,
+			wantRequeue: true,
+		},
+		{
+			name:      "failed",
+			growth:    []v1alpha1.DiskGrowthStatus{{Mount: "/u02", Size: resource.MustParse("200Gi")}},
+			err:       errors.New("df failed"),
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("GrowMount", tc.resp)
+			}
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("GrowMount", tc.err)
+			}
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{DiskGrowth: tc.growth}}
+
+			result, err := r.reconcileDiskGrowth(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileDiskGrowth got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.GrowMountCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileDiskGrowth called GrowMount %d times, want %d", got, tc.wantCalls)
+			}
+			if tc.wantCalls > 0 && factory.Dbclient.GotGrowMountRequest.GetExpectedBytes() != 200<<30 {
+				t.Errorf("reconcileDiskGrowth requested %d expected bytes, want %d", factory.Dbclient.GotGrowMountRequest.GetExpectedBytes(), int64(200<<30))
+			}
+			if gotRequeue := result.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("reconcileDiskGrowth got requeue after %v, want requeue: %v", result.RequeueAfter, tc.wantRequeue)
+			}
+			got := inst.Status.DiskGrowth[0]
+			if got.Verified != tc.wantVerified {
+				t.Errorf("reconcileDiskGrowth got verified %v, want %v", got.Verified, tc.wantVerified)
+			}
+			if tc.resp != nil && (got.FilesystemBytes != tc.resp.GetTotalBytes() || !cmp.Equal(got.AutoextendedDatafiles, tc.resp.GetAutoextendedDatafiles())) {
+				t.Errorf("reconcileDiskGrowth got status %+v, want the filesystem size and autoextended datafiles of %v", got, tc.resp)
+			}
+		})
+	}
+}
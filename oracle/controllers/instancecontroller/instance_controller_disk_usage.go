@@ -0,0 +1,186 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	// diskUsageSampleInterval is the delay between samples of the mount
+	// usage.
+	diskUsageSampleInterval = 30 * time.Minute
+	// diskUsageWindow is the age of the oldest sample used to estimate the
+	// growth rate.
+	diskUsageWindow = 24 * time.Hour
+	// minDiskUsageSpan is the time the samples must span before the growth
+	// rate is estimated, shorter spans are dominated by noise.
+	minDiskUsageSpan = 2 * time.Hour
+	// diskFullWarningWindow is the lead time given to expand a disk before
+	// it is projected to be full.
+	diskFullWarningWindow = 72 * time.Hour
+	// maxDiskFullProjection bounds the projection, slower growth is not
+	// worth reporting.
+	maxDiskFullProjection = 5 * 365 * 24 * time.Hour
+)
+
+// projectDiskFull fits a line to the samples with least squares. It returns
+// the growth rate in bytes per hour, zero if the samples span less than
+// minDiskUsageSpan, and the time the line reaches totalBytes, zero if the
+// usage is not growing.
+func projectDiskFull(samples []v1alpha1.DiskUsageSample, totalBytes int64) (float64, time.Time) {
+	if len(samples) < 2 {
+		return 0, time.Time{}
+	}
+	first, last := samples[0].Time.Time, samples[len(samples)-1]
+	if last.Time.Sub(first) < minDiskUsageSpan {
+		return 0, time.Time{}
+	}
+
+	n := float64(len(samples))
+	var meanHours, meanBytes float64
+	for _, s := range samples {
+		meanHours += s.Time.Sub(first).Hours()
+		meanBytes += float64(s.UsedBytes)
+	}
+	meanHours /= n
+	meanBytes /= n
+	var covariance, variance float64
+	for _, s := range samples {
+		dx := s.Time.Sub(first).Hours() - meanHours
+		covariance += dx * (float64(s.UsedBytes) - meanBytes)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return 0, time.Time{}
+	}
+
+	growth := covariance / variance
+	if growth <= 0 {
+		return growth, time.Time{}
+	}
+	hours := math.Max(float64(totalBytes-last.UsedBytes)/growth, 0)
+	if hours > maxDiskFullProjection.Hours() {
+		return growth, time.Time{}
+	}
+	return growth, last.Time.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+// addDiskUsageSamples appends the mount usage observed at now to the
+// samples of each mount, drops the samples older than diskUsageWindow and
+// refreshes the projections. Mounts no longer reported are dropped.
+func addDiskUsageSamples(usage []v1alpha1.DiskUsageStatus, mounts []*dbdpb.GetHostStatsResponse_Mount, now time.Time) []v1alpha1.DiskUsageStatus {
+	prevSamples := make(map[string][]v1alpha1.DiskUsageSample)
+	for _, u := range usage {
+		prevSamples[u.Mount] = u.Samples
+	}
+
+	var result []v1alpha1.DiskUsageStatus
+	for _, m := range mounts {
+		var samples []v1alpha1.DiskUsageSample
+		for _, s := range prevSamples[m.GetPath()] {
+			if now.Sub(s.Time.Time) < diskUsageWindow {
+				samples = append(samples, s)
+			}
+		}
+		samples = append(samples, v1alpha1.DiskUsageSample{Time: v1.NewTime(now), UsedBytes: m.GetUsedBytes()})
+
+		status := v1alpha1.DiskUsageStatus{
+			Mount:      m.GetPath(),
+			TotalBytes: m.GetTotalBytes(),
+			Samples:    samples,
+		}
+		growth, full := projectDiskFull(samples, m.GetTotalBytes())
+		status.GrowthBytesPerHour = int64(growth)
+		if !full.IsZero() {
+			status.EstimatedFullTime = &v1.Time{Time: full}
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// lastDiskUsageSampleTime returns the time of the most recent sample, zero
+// if there is none.
+func lastDiskUsageSampleTime(usage []v1alpha1.DiskUsageStatus) time.Time {
+	var last time.Time
+	for _, u := range usage {
+		if n := len(u.Samples); n > 0 && u.Samples[n-1].Time.After(last) {
+			last = u.Samples[n-1].Time.Time
+		}
+	}
+	return last
+}
+
+// reconcileDiskUsage samples the usage of the data and log mounts every
+// diskUsageSampleInterval and reports the mounts projected to be full
+// within diskFullWarningWindow as the DiskSpaceHealthy condition, so that
+// disks can be expanded in time. The samples are kept in the instance
+// status to survive operator restarts.
+func (r *InstanceReconciler) reconcileDiskUsage(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	now := time.Now()
+	if last := lastDiskUsageSampleTime(inst.Status.DiskUsage); now.Sub(last) < diskUsageSampleInterval {
+		return ctrl.Result{RequeueAfter: diskUsageSampleInterval - now.Sub(last)}, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	stats, err := dbClient.GetHostStats(ctx, &dbdpb.GetHostStatsRequest{})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.DiskSpaceHealthy, v1.ConditionUnknown, k8s.DiskUsageUnknown, fmt.Sprintf("failed to get disk usage: %v", err))
+		return ctrl.Result{}, err
+	}
+	inst.Status.DiskUsage = addDiskUsageSamples(inst.Status.DiskUsage, stats.GetMounts(), now)
+
+	var predictions []string
+	for _, u := range inst.Status.DiskUsage {
+		if u.EstimatedFullTime == nil || u.EstimatedFullTime.Sub(now) >= diskFullWarningWindow || u.TotalBytes <= 0 {
+			continue
+		}
+		last := u.Samples[len(u.Samples)-1]
+		predictions = append(predictions, fmt.Sprintf("%s is %.0f%% used and projected to be full in %.0f hours",
+			u.Mount, float64(last.UsedBytes)*100/float64(u.TotalBytes), math.Max(u.EstimatedFullTime.Sub(now).Hours(), 0)))
+	}
+
+	result := ctrl.Result{RequeueAfter: diskUsageSampleInterval}
+	if len(predictions) > 0 {
+		msg := strings.Join(predictions, ", ")
+		if !k8s.ConditionReasonEquals(k8s.FindCondition(inst.Status.Conditions, k8s.DiskSpaceHealthy), k8s.DiskFullPredicted) {
+			log.Info("disk full predicted", "predictions", predictions)
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DiskFullPredicted, "%s, expand the disk to avoid an outage", msg)
+		}
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.DiskSpaceHealthy, v1.ConditionFalse, k8s.DiskFullPredicted, msg)
+		return result, nil
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.DiskSpaceHealthy, v1.ConditionTrue, k8s.DiskUsageNormal, fmt.Sprintf("No mount is projected to be full within %.0f hours", diskFullWarningWindow.Hours()))
+	return result, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// hourlySamples returns samples taken every hour, the last one at end.
+func hourlySamples(end time.Time, usedGiB ...float64) []v1alpha1.DiskUsageSample {
+	var samples []v1alpha1.DiskUsageSample
+	for i, used := range usedGiB {
+		samples = append(samples, v1alpha1.DiskUsageSample{
+			Time:      metav1.NewTime(end.Add(time.Duration(i-len(usedGiB)+1) * time.Hour)),
+			UsedBytes: int64(used * gib),
+		})
+	}
+	return samples
+}
+
+func TestProjectDiskFull(t *testing.T) {
+	end := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		samples    []v1alpha1.DiskUsageSample
+		total      int64
+		wantGrowth float64
+		wantFull   time.Time
+	}{
+		{
+			name:    "single sample",
+			samples: hourlySamples(end, 10),
+			total:   20 * gib,
+		},
+		{
+			name:    "short span",
+			samples: hourlySamples(end, 10, 11),
+			total:   20 * gib,
+		},
+		{
+			name:       "linear growth",
+			samples:    hourlySamples(end, 10, 11, 12, 13, 14),
+			total:      20 * gib,
+			wantGrowth: gib,
+			wantFull:   end.Add(6 * time.Hour),
+		},
+		{
+			name:       "noisy growth",
+			samples:    hourlySamples(end, 10, 12, 11, 13, 12),
+			total:      20 * gib,
+			wantGrowth: gib / 2,
+			wantFull:   end.Add(16 * time.Hour),
+		},
+		{
+			name:    "flat",
+			samples: hourlySamples(end, 10, 10, 10, 10),
+			total:   20 * gib,
+		},
+		{
+			name:       "shrinking",
+			samples:    hourlySamples(end, 14, 13, 12, 11, 10),
+			total:      20 * gib,
+			wantGrowth: -gib,
+		},
+		{
+			name:       "already full",
+			samples:    hourlySamples(end, 18, 19, 20),
+			total:      20 * gib,
+			wantGrowth: gib,
+			wantFull:   end,
+		},
+		{
+			name: "slow growth",
+			samples: []v1alpha1.DiskUsageSample{
+				{Time: metav1.NewTime(end.Add(-2 * time.Hour)), UsedBytes: gib},
+				{Time: metav1.NewTime(end), UsedBytes: gib + 2},
+			},
+			total:      20 * gib,
+			wantGrowth: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotGrowth, gotFull := projectDiskFull(tc.samples, tc.total)
+			if math.Abs(gotGrowth-tc.wantGrowth) > 1 {
+				t.Errorf("projectDiskFull got growth %v bytes per hour, want %v", gotGrowth, tc.wantGrowth)
+			}
+			if gotFull.IsZero() != tc.wantFull.IsZero() || math.Abs(gotFull.Sub(tc.wantFull).Seconds()) > 1 {
+				t.Errorf("projectDiskFull got full time %v, want %v", gotFull, tc.wantFull)
+			}
+		})
+	}
+}
+
+func TestAddDiskUsageSamples(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	usage := []v1alpha1.DiskUsageStatus{
+		{
+			Mount:      "/u02",
+			TotalBytes: 20 * gib,
+			Samples: []v1alpha1.DiskUsageSample{
+				{Time: metav1.NewTime(now.Add(-25 * time.Hour)), UsedBytes: 5 * gib},
+				{Time: metav1.NewTime(now.Add(-3 * time.Hour)), UsedBytes: 10 * gib},
+				{Time: metav1.NewTime(now.Add(-2 * time.Hour)), UsedBytes: 11 * gib},
+				{Time: metav1.NewTime(now.Add(-1 * time.Hour)), UsedBytes: 12 * gib},
+			},
+		},
+		{
+			Mount:   "/u04",
+			Samples: []v1alpha1.DiskUsageSample{{Time: metav1.NewTime(now.Add(-1 * time.Hour)), UsedBytes: gib}},
+		},
+	}
+	mounts := []*dbdpb.GetHostStatsResponse_Mount{
+		{Path: "/u02", TotalBytes: 20 * gib, UsedBytes: 13 * gib},
+		{Path: "/u03", TotalBytes: 50 * gib, UsedBytes: 7 * gib},
+	}
+
+	got := addDiskUsageSamples(usage, mounts, now)
+	if len(got) != 2 || got[0].Mount != "/u02" || got[1].Mount != "/u03" {
+		t.Fatalf("addDiskUsageSamples got %+v, want the /u02 and /u03 mounts", got)
+	}
+	data, log := got[0], got[1]
+	if len(data.Samples) != 4 || !data.Samples[0].Time.Equal(&usage[0].Samples[1].Time) || data.Samples[3].UsedBytes != 13*gib {
+		t.Errorf("addDiskUsageSamples got /u02 samples %+v, want the samples of the last day and the new one", data.Samples)
+	}
+	if data.GrowthBytesPerHour != gib || data.EstimatedFullTime == nil || !data.EstimatedFullTime.Time.Equal(now.Add(7*time.Hour)) {
+		t.Errorf("addDiskUsageSamples got /u02 growth %v and full time %v, want %v and %v", data.GrowthBytesPerHour, data.EstimatedFullTime, int64(gib), now.Add(7*time.Hour))
+	}
+	if len(log.Samples) != 1 || log.TotalBytes != 50*gib || log.GrowthBytesPerHour != 0 || log.EstimatedFullTime != nil {
+		t.Errorf("addDiskUsageSamples got /u03 usage %+v, want a single sample without projection", log)
+	}
+}
+
+func TestReconcileDiskUsage(t *testing.T) {
+	now := time.Now()
+	growingSamples := hourlySamples(now.Add(-time.Hour), 10, 11, 12)
+	tests := []struct {
+		name           string
+		usage          []v1alpha1.DiskUsageStatus
+		mounts         []*dbdpb.GetHostStatsResponse_Mount
+		err            error
+		wantErr        bool
+		wantCalls      int
+		wantCondStatus metav1.ConditionStatus
+		wantCondReason string
+		wantEvents     int
+	}{
+		{
+			name:      "sampled recently",
+			usage:     []v1alpha1.DiskUsageStatus{{Mount: "/u02", Samples: hourlySamples(now.Add(-10*time.Minute), 10)}},
+			wantCalls: 0,
+		},
+		{
+			name:           "full predicted",
+			usage:          []v1alpha1.DiskUsageStatus{{Mount: "/u02", Samples: growingSamples}},
+			mounts:         []*dbdpb.GetHostStatsResponse_Mount{{Path: "/u02", TotalBytes: 20 * gib, UsedBytes: 13 * gib}},
+			wantCalls:      1,
+			wantCondStatus: metav1.ConditionFalse,
+			wantCondReason: k8s.DiskFullPredicted,
+			wantEvents:     1,
+		},
+		{
+			name:           "full far away",
+			usage:          []v1alpha1.DiskUsageStatus{{Mount: "/u02", Samples: growingSamples}},
+			mounts:         []*dbdpb.GetHostStatsResponse_Mount{{Path: "/u02", TotalBytes: 1000 * gib, UsedBytes: 13 * gib}},
+			wantCalls:      1,
+			wantCondStatus: metav1.ConditionTrue,
+			wantCondReason: k8s.DiskUsageNormal,
+		},
+		{
+			name:           "failed",
+			err:            errors.New("df failed"),
+			wantErr:        true,
+			wantCalls:      1,
+			wantCondStatus: metav1.ConditionUnknown,
+			wantCondReason: k8s.DiskUsageUnknown,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("GetHostStats", &dbdpb.GetHostStatsResponse{Mounts: tc.mounts})
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("GetHostStats", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{DiskUsage: tc.usage}}
+
+			result, err := r.reconcileDiskUsage(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileDiskUsage got error %v, want error: %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && (result.RequeueAfter <= 0 || result.RequeueAfter > diskUsageSampleInterval) {
+				t.Errorf("reconcileDiskUsage got requeue after %v, want up to %v", result.RequeueAfter, diskUsageSampleInterval)
+			}
+			if got := factory.Dbclient.GetHostStatsCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileDiskUsage called GetHostStats %d times, want %d", got, tc.wantCalls)
+			}
+			cond := k8s.FindCondition(inst.Status.Conditions, k8s.DiskSpaceHealthy)
+			if tc.wantCondReason == "" {
+				if cond != nil {
+					t.Errorf("reconcileDiskUsage set condition %+v, want none", cond)
+				}
+			} else if cond == nil || cond.Status != tc.wantCondStatus || cond.Reason != tc.wantCondReason {
+				t.Errorf("reconcileDiskUsage set condition %+v, want status %v reason %v", cond, tc.wantCondStatus, tc.wantCondReason)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileDiskUsage emitted %d events, want %d", got, tc.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/standby"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// drDrillRequested returns whether the instance requests a drill later than
+// the last one.
+func drDrillRequested(inst *v1alpha1.Instance) bool {
+	if inst.Spec.DataGuard == nil || inst.Spec.DataGuard.Drill == nil {
+		return false
+	}
+	last := inst.Status.LastDRDrill
+	return last == nil || inst.Spec.DataGuard.Drill.RequestTime.After(last.RequestTime.Time)
+}
+
+// drDrillStatus converts the result of a drill to its status.
+func drDrillStatus(requestTime, start, end metav1.Time, result *standby.DRDrillResult) *v1alpha1.DRDrillStatus {
+	status := &v1alpha1.DRDrillStatus{
+		RequestTime:       requestTime,
+		State:             string(result.State),
+		StartTime:         &start,
+		CompletionTime:    &end,
+		SwitchoverSeconds: int64(result.Durations[standby.DRDrillSwitchover].Seconds()),
+		SwitchbackSeconds: int64((result.Durations[standby.DRDrillSwitchback] + result.Durations[standby.DRDrillRollback]).Seconds()),
+	}
+	if result.Err != nil {
+		status.FailedState = string(result.FailedState)
+		status.Message = result.Err.Error()
+	}
+	return status
+}
+
+// reconcileDRDrill runs the drill requested by the instance, if any, and
+// records its outcome in the status. The drill runs once per request time
+// whether it succeeds or not.
+func (r *InstanceReconciler) reconcileDRDrill(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if !drDrillRequested(inst) {
+		return nil
+	}
+	credentialReq, err := toCredentialReq(inst.Spec.ReplicationSettings.PrimaryUser)
+	if err != nil {
+		return err
+	}
+	drill := inst.Spec.DataGuard.Drill
+	log.Info("running DR drill", "requestTime", drill.RequestTime)
+	start := metav1.Now()
+	result, err := controllers.RunDRDrill(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.RunDRDrillRequest{
+		PrimaryHost:         inst.Spec.ReplicationSettings.PrimaryHost,
+		PrimaryPort:         inst.Spec.ReplicationSettings.PrimaryPort,
+		PrimaryService:      inst.Spec.ReplicationSettings.PrimaryServiceName,
+		PrimaryUser:         inst.Spec.ReplicationSettings.PrimaryUser.Name,
+		PrimaryCredential:   credentialReq,
+		StandbyDbUniqueName: inst.Spec.DBUniqueName,
+		ValidationQueries:   drill.ValidationQueries,
+	})
+	if err != nil {
+		return err
+	}
+	inst.Status.LastDRDrill = drDrillStatus(drill.RequestTime, start, metav1.Now(), result)
+	if result.State != standby.DRDrillCompleted {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.DRDrillFailed, "DR drill %s in state %s: %v", result.State, result.FailedState, result.Err)
+		return nil
+	}
+	r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.DRDrillCompleted, fmt.Sprintf("DR drill completed, switchover took %ds", inst.Status.LastDRDrill.SwitchoverSeconds))
+	return nil
+}
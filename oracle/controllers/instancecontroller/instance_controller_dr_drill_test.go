@@ -0,0 +1,97 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/standby"
+)
+
+func TestDRDrillRequested(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		drill *v1alpha1.DRDrillSpec
+		last  *v1alpha1.DRDrillStatus
+		want  bool
+	}{
+		{
+			name: "no drill",
+		},
+		{
+			name:  "first drill",
+			drill: &v1alpha1.DRDrillSpec{RequestTime: metav1.NewTime(now)},
+			want:  true,
+		},
+		{
+			name:  "already run",
+			drill: &v1alpha1.DRDrillSpec{RequestTime: metav1.NewTime(now)},
+			last:  &v1alpha1.DRDrillStatus{RequestTime: metav1.NewTime(now)},
+		},
+		{
+			name:  "new request",
+			drill: &v1alpha1.DRDrillSpec{RequestTime: metav1.NewTime(now)},
+			last:  &v1alpha1.DRDrillStatus{RequestTime: metav1.NewTime(now.Add(-time.Hour))},
+			want:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst := &v1alpha1.Instance{
+				Spec:   v1alpha1.InstanceSpec{DataGuard: &v1alpha1.DataGuardSpec{Drill: tc.drill}},
+				Status: v1alpha1.InstanceStatus{LastDRDrill: tc.last},
+			}
+			if got := drDrillRequested(inst); got != tc.want {
+				t.Errorf("drDrillRequested got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDRDrillStatus(t *testing.T) {
+	request := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	start := metav1.NewTime(request.Add(time.Minute))
+	end := metav1.NewTime(request.Add(10 * time.Minute))
+	got := drDrillStatus(request, start, end, &standby.DRDrillResult{
+		State:       standby.DRDrillRolledBack,
+		FailedState: standby.DRDrillValidation,
+		Err:         errors.New("validation query failed"),
+		Durations: map[standby.DRDrillState]time.Duration{
+			standby.DRDrillSwitchover: 90 * time.Second,
+			standby.DRDrillValidation: time.Second,
+			standby.DRDrillRollback:   80 * time.Second,
+		},
+	})
+	want := &v1alpha1.DRDrillStatus{
+		RequestTime:       request,
+		State:             "RolledBack",
+		FailedState:       "Validation",
+		StartTime:         &start,
+		CompletionTime:    &end,
+		SwitchoverSeconds: 90,
+		SwitchbackSeconds: 80,
+		Message:           "validation query failed",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("drDrillStatus got unexpected status (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const cdbRootContainer = "CDB$ROOT"
+
+// reconcileEncryption verifies that user tablespaces in the CDB root and in
+// every PDB are encrypted when spec.tde.enforceAll is set. Unencrypted
+// tablespaces are recorded in the instance status and, if
+// spec.tde.encryptOnline is set, encrypted online. Verification only runs
+// again once the spec generation or the set of PDBs changes.
+func (r *InstanceReconciler) reconcileEncryption(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.TDE == nil || !inst.Spec.TDE.EnforceAll {
+		inst.Status.UnencryptedTablespaces = nil
+		inst.Status.EncryptionVerifiedDatabases = nil
+		return nil
+	}
+	if encryptionVerified(inst) {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	var unencrypted []string
+	for _, pdb := range append([]string{""}, inst.Status.DatabaseNames...) {
+		container := pdb
+		if container == "" {
+			container = cdbRootContainer
+		}
+		resp, err := dbClient.VerifyEncryption(ctx, &dbdpb.VerifyEncryptionRequest{
+			PdbName:       pdb,
+			EnforceAll:    true,
+			EncryptOnline: inst.Spec.TDE.EncryptOnline,
+		})
+		if err != nil {
+			k8s.InstanceUpsertCondition(&inst.Status, k8s.TablespacesEncrypted, v1.ConditionUnknown, k8s.EncryptionVerifyFailed, fmt.Sprintf("failed to verify encryption of %s: %v", container, err)).ObservedGeneration = inst.Generation
+			return fmt.Errorf("failed to verify encryption of %s: %v", container, err)
+		}
+		for _, ts := range resp.GetEncryptedTablespaces() {
+			r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.EncryptionEnforced, "Tablespace %s.%s encrypted online", container, ts)
+		}
+		for _, ts := range resp.GetUnencryptedUserTablespaces() {
+			unencrypted = append(unencrypted, fmt.Sprintf("%s.%s", container, ts))
+		}
+	}
+
+	inst.Status.UnencryptedTablespaces = unencrypted
+	inst.Status.EncryptionVerifiedDatabases = append([]string(nil), inst.Status.DatabaseNames...)
+	if len(unencrypted) > 0 {
+		msg := fmt.Sprintf("Unencrypted user tablespaces: %s", strings.Join(unencrypted, ", "))
+		log.Info("encryption enforcement found unencrypted tablespaces", "tablespaces", unencrypted)
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.UnencryptedTablespacesFound, msg)
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.TablespacesEncrypted, v1.ConditionFalse, k8s.UnencryptedTablespacesFound, msg).ObservedGeneration = inst.Generation
+		return nil
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.TablespacesEncrypted, v1.ConditionTrue, k8s.EncryptionEnforced, "").ObservedGeneration = inst.Generation
+	return nil
+}
+
+// encryptionVerified returns true if the last verification succeeded for the
+// current spec generation and covered the current set of PDBs.
+func encryptionVerified(inst *v1alpha1.Instance) bool {
+	cond := k8s.FindCondition(inst.Status.Conditions, k8s.TablespacesEncrypted)
+	if cond == nil || cond.Reason == k8s.EncryptionVerifyFailed || cond.ObservedGeneration != inst.Generation {
+		return false
+	}
+	if len(inst.Status.EncryptionVerifiedDatabases) != len(inst.Status.DatabaseNames) {
+		return false
+	}
+	verified := make(map[string]bool)
+	for _, name := range inst.Status.EncryptionVerifiedDatabases {
+		verified[name] = true
+	}
+	for _, name := range inst.Status.DatabaseNames {
+		if !verified[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcileTablespaceEncryptionPolicy applies spec.tde.encryptNewTablespaces
+// to the default encryption policy of new tablespaces. The dbdaemon only
+// sets the policy if it differs, so this is safe to call on every reconcile.
+func (r *InstanceReconciler) reconcileTablespaceEncryptionPolicy(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.TDE == nil || inst.Spec.TDE.EncryptNewTablespaces == nil {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.SetTablespaceEncryptionPolicy(ctx, &dbdpb.SetTablespaceEncryptionPolicyRequest{
+		EncryptNewTablespaces: *inst.Spec.TDE.EncryptNewTablespaces,
+	})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.TablespaceEncryptionPolicyFailed, "Failed to set the tablespace encryption policy: %v", err)
+		return err
+	}
+	if !resp.GetChanged() {
+		return nil
+	}
+	log.Info("tablespace encryption policy set", "parameter", resp.GetParameter(), "value", resp.GetValue(), "restartRequired", resp.GetRestartRequired())
+	msg := fmt.Sprintf("Tablespace encryption policy set: %s=%s", resp.GetParameter(), resp.GetValue())
+	if resp.GetRestartRequired() {
+		msg += ", it applies once the database restarts"
+	}
+	r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.TablespaceEncryptionPolicySet, msg)
+	return nil
+}
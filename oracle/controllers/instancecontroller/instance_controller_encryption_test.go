@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileEncryptionOnlyOnChange(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec: v1alpha1.InstanceSpec{
+			TDE: &v1alpha1.TDESpec{EnforceAll: true},
+		},
+		Status: v1alpha1.InstanceStatus{DatabaseNames: []string{"PDB1"}},
+	}
+
+	steps := []struct {
+		name      string
+		update    func()
+		wantCalls int
+	}{
+		{
+			name:      "first reconcile verifies root and PDB",
+			update:    func() {},
+			wantCalls: 2,
+		},
+		{
+			name:      "unchanged instance is not verified again",
+			update:    func() {},
+			wantCalls: 2,
+		},
+		{
+			name:      "new PDB is verified",
+			update:    func() { inst.Status.DatabaseNames = append(inst.Status.DatabaseNames, "PDB2") },
+			wantCalls: 5,
+		},
+		{
+			name:      "spec change is verified",
+			update:    func() { inst.Generation = 2 },
+			wantCalls: 8,
+		},
+	}
+	for _, step := range steps {
+		step.update()
+		if err := r.reconcileEncryption(context.Background(), inst, logr.Discard()); err != nil {
+			t.Fatalf("%s: reconcileEncryption failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.VerifyEncryptionCalledCnt(); got != step.wantCalls {
+			t.Errorf("%s: VerifyEncryption called %d times, want %d", step.name, got, step.wantCalls)
+		}
+	}
+}
+
+func TestReconcileTablespaceEncryptionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		tde         *v1alpha1.TDESpec
+		resp        *dbdpb.SetTablespaceEncryptionPolicyResponse
+		err         error
+		wantErr     bool
+		wantRequest *dbdpb.SetTablespaceEncryptionPolicyRequest
+		wantEvent   string
+	}{
+		{
+			name: "not managed",
+			tde:  &v1alpha1.TDESpec{EnforceAll: true},
+		},
+		{
+			name:        "encrypt new tablespaces",
+			tde:         &v1alpha1.TDESpec{EncryptNewTablespaces: pointer.Bool(true)},
+			resp:        &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: "encrypt_new_tablespaces", Value: "ALWAYS", Changed: true},
+			wantRequest: &dbdpb.SetTablespaceEncryptionPolicyRequest{EncryptNewTablespaces: true},
+			wantEvent:   "Normal TablespaceEncryptionPolicySet Tablespace encryption policy set: encrypt_new_tablespaces=ALWAYS",
+		},
+		{
+			name:        "restart required",
+			tde:         &v1alpha1.TDESpec{EncryptNewTablespaces: pointer.Bool(true)},
+			resp:        &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: "tablespace_encryption", Value: "AUTO_ENABLE", Changed: true, RestartRequired: true},
+			wantRequest: &dbdpb.SetTablespaceEncryptionPolicyRequest{EncryptNewTablespaces: true},
+			wantEvent:   "Normal TablespaceEncryptionPolicySet Tablespace encryption policy set: tablespace_encryption=AUTO_ENABLE, it applies once the database restarts",
+		},
+		{
+			name:        "unchanged",
+			tde:         &v1alpha1.TDESpec{EncryptNewTablespaces: pointer.Bool(false)},
+			resp:        &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: "encrypt_new_tablespaces", Value: "DDL"},
+			wantRequest: &dbdpb.SetTablespaceEncryptionPolicyRequest{},
+		},
+		{
+			name:        "failed",
+			tde:         &v1alpha1.TDESpec{EncryptNewTablespaces: pointer.Bool(true)},
+			err:         errors.New("ORA-02095: specified initialization parameter cannot be modified"),
+			wantErr:     true,
+			wantRequest: &dbdpb.SetTablespaceEncryptionPolicyRequest{EncryptNewTablespaces: true},
+			wantEvent:   "Warning TablespaceEncryptionPolicyFailed Failed to set the tablespace encryption policy: ORA-02095: specified initialization parameter cannot be modified",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("SetTablespaceEncryptionPolicy", tc.resp)
+			}
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("SetTablespaceEncryptionPolicy", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{TDE: tc.tde}}
+
+			err := r.reconcileTablespaceEncryptionPolicy(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileTablespaceEncryptionPolicy got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantRequest, factory.Dbclient.GotSetTablespaceEncryptionPolicyRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("reconcileTablespaceEncryptionPolicy got unexpected request (-want +got):\n%v", diff)
+			}
+			var gotEvent string
+			if len(recorder.Events) > 0 {
+				gotEvent = <-recorder.Events
+			}
+			if gotEvent != tc.wantEvent {
+				t.Errorf("reconcileTablespaceEncryptionPolicy emitted event %q, want %q", gotEvent, tc.wantEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// keystoreOpen is the status of a keystore open with a master key.
+const keystoreOpen = "OPEN"
+
+// keystoreReady returns true if the keystore reported by check needs no
+// setup. An auto-login keystore only becomes the open one once the
+// database restarts, so the keystore set up for the current spec
+// generation is ready while it's still open with its password.
+func keystoreReady(inst *v1alpha1.Instance, check *controllers.CheckStatusResponse) bool {
+	if check.KeystoreStatus != keystoreOpen {
+		return false
+	}
+	if !inst.Spec.TDE.AutoLogin || strings.Contains(check.KeystoreWalletType, "AUTOLOGIN") {
+		return true
+	}
+	cond := k8s.FindCondition(inst.Status.Conditions, k8s.KeystoreOpen)
+	return k8s.ConditionStatusEquals(cond, v1.ConditionTrue) && cond.ObservedGeneration == inst.Generation
+}
+
+// reconcileKeystore sets up the TDE keystore when spec.tde.manageKeystore is
+// set. The keystore status is checked on every reconcile, the password is
+// only read from the secret if the keystore has to be created or opened, or
+// a container needs a master key. A database without wallet_root is
+// restarted once to set it. Standby instances are skipped, their keystore is
+// a copy of the primary one.
+func (r *InstanceReconciler) reconcileKeystore(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.TDE == nil || !inst.Spec.TDE.ManageKeystore {
+		inst.Status.Keystore = nil
+		return nil
+	}
+	if inst.Spec.TDE.WalletPasswordGsmSecretRef == nil {
+		return fmt.Errorf("spec.tde.manageKeystore requires spec.tde.walletPasswordGsmSecretRef")
+	}
+	if isStandbyDR(inst) {
+		return nil
+	}
+
+	check, err := controllers.CheckStatus(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.CheckStatusRequest{
+		Name:            inst.Name,
+		CdbName:         inst.Spec.CDBName,
+		CheckStatusType: controllers.CheckStatusRequest_INSTANCE,
+		DbDomain:        controllers.GetDBDomain(inst),
+		VerifyKeystore:  true,
+	})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.KeystoreOpen, v1.ConditionUnknown, k8s.KeystoreFailed, fmt.Sprintf("failed to check the keystore: %v", err)).ObservedGeneration = inst.Generation
+		return err
+	}
+	if check.Status != controllers.StatusReady {
+		return nil
+	}
+	if keystoreReady(inst, check) {
+		inst.Status.Keystore = &v1alpha1.KeystoreStatus{Location: check.KeystoreLocation, WalletType: check.KeystoreWalletType, Status: check.KeystoreStatus}
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.KeystoreOpen, v1.ConditionTrue, k8s.KeystoreConfigured, "").ObservedGeneration = inst.Generation
+		return nil
+	}
+
+	req := controllers.SetupKeystoreRequest{PasswordRef: walletPasswordRef(inst), AutoLogin: inst.Spec.TDE.AutoLogin}
+	setup, err := controllers.SetupKeystore(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, req)
+	if err == nil && setup.RestartRequired {
+		log.Info("set wallet_root, restarting the database to create the TDE keystore")
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.WalletRootSet, "wallet_root set, restarting the database to create the TDE keystore")
+		if err = r.restartDatabase(ctx, inst); err == nil {
+			setup, err = controllers.SetupKeystore(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, req)
+		}
+	}
+	if err == nil && setup.RestartRequired {
+		err = fmt.Errorf("wallet_root isn't set after the database restarted")
+	}
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.KeystoreFailed, "Failed to set up the TDE keystore: %v", err)
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.KeystoreOpen, v1.ConditionFalse, k8s.KeystoreFailed, fmt.Sprintf("failed to set up the keystore: %v", err)).ObservedGeneration = inst.Generation
+		return err
+	}
+
+	if setup.Created {
+		log.Info("created the TDE keystore", "location", setup.Location)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.KeystoreConfigured, "TDE keystore created in %s", setup.Location)
+	}
+	if len(setup.MasterKeyContainers) > 0 {
+		log.Info("set the TDE master keys", "containers", setup.MasterKeyContainers)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.MasterKeySet, "TDE master key set in %s", strings.Join(setup.MasterKeyContainers, ", "))
+	}
+	inst.Status.Keystore = &v1alpha1.KeystoreStatus{Location: setup.Location, WalletType: setup.WalletType, Status: setup.Status}
+	if setup.Status != keystoreOpen {
+		msg := fmt.Sprintf("the keystore is %s", setup.Status)
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.KeystoreOpen, v1.ConditionFalse, k8s.KeystoreFailed, msg).ObservedGeneration = inst.Generation
+		return fmt.Errorf("%s after its setup", msg)
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.KeystoreOpen, v1.ConditionTrue, k8s.KeystoreConfigured, "").ObservedGeneration = inst.Generation
+	return nil
+}
+
+// restartDatabase shuts the database down and starts it again, applying
+// the spfile changes.
+func (r *InstanceReconciler) restartDatabase(ctx context.Context, inst *v1alpha1.Instance) error {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName: inst.Spec.CDBName,
+		Option:       "immediate",
+	}); err != nil {
+		return fmt.Errorf("BounceDatabase: error while shutting db: %v", err)
+	}
+	if _, err := dbClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:         dbdpb.BounceDatabaseRequest_STARTUP,
+		DatabaseName:      inst.Spec.CDBName,
+		AvoidConfigBackup: false,
+	}); err != nil {
+		return fmt.Errorf("dbClient/BounceDatabase: error while starting db: %v", err)
+	}
+	return nil
+}
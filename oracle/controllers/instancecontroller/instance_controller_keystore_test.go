@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func keystoreRows(status, walletType string) *dbdpb.RunCMDResponse {
+	return &dbdpb.RunCMDResponse{Msg: []string{
+		fmt.Sprintf(`{"NAME":"CDB$ROOT","WRL_PARAMETER":"/u02/app/oracle/keystore/tde/","WALLET_TYPE":%q,"STATUS":%q}`, walletType, status),
+	}}
+}
+
+func TestReconcileKeystore(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("FileExists", &dbdpb.FileExistsResponse{Exists: true})
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	var accessed []string
+	access := controllers.AccessSecretVersionFunc
+	defer func() { controllers.AccessSecretVersionFunc = access }()
+	controllers.AccessSecretVersionFunc = func(ctx context.Context, name string) (string, error) {
+		accessed = append(accessed, name)
+		return "Tde_pw1", nil
+	}
+	inst := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec: v1alpha1.InstanceSpec{
+			CDBName: "GCLOUD",
+			TDE: &v1alpha1.TDESpec{
+				ManageKeystore:             true,
+				AutoLogin:                  true,
+				WalletPasswordGsmSecretRef: &commonv1alpha1.GsmSecretReference{ProjectId: "p", SecretId: "tde-password", Version: "1"},
+			},
+		},
+	}
+
+	steps := []struct {
+		name          string
+		keystore      *dbdpb.RunCMDResponse
+		configure     *dbdpb.ConfigureKeystoreResponse
+		wantErr       bool
+		wantAccessed  int
+		wantBounces   int
+		wantStatus    string
+		wantCondition metav1.ConditionStatus
+	}{
+		{
+			name:          "wallet_root stays unset",
+			keystore:      keystoreRows("NOT_AVAILABLE", "UNKNOWN"),
+			configure:     &dbdpb.ConfigureKeystoreResponse{Status: "NOT_AVAILABLE", RestartRequired: true},
+			wantErr:       true,
+			wantAccessed:  2,
+			wantBounces:   2,
+			wantCondition: metav1.ConditionFalse,
+		},
+		{
+			name:          "keystore created",
+			keystore:      keystoreRows("NOT_AVAILABLE", "UNKNOWN"),
+			configure:     &dbdpb.ConfigureKeystoreResponse{KeystoreLocation: "/u02/app/oracle/keystore/tde/", WalletType: "PASSWORD", Status: "OPEN_NO_MASTER_KEY", Created: true},
+			wantAccessed:  3,
+			wantBounces:   2,
+			wantStatus:    "OPEN",
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:          "auto-login keystore opens after a restart",
+			keystore:      keystoreRows("OPEN", "PASSWORD"),
+			wantAccessed:  3,
+			wantBounces:   2,
+			wantStatus:    "OPEN",
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:          "keystore closed",
+			keystore:      keystoreRows("CLOSED", "UNKNOWN"),
+			configure:     &dbdpb.ConfigureKeystoreResponse{KeystoreLocation: "/u02/app/oracle/keystore/tde/", WalletType: "AUTOLOGIN", Status: "OPEN"},
+			wantAccessed:  4,
+			wantBounces:   2,
+			wantStatus:    "OPEN",
+			wantCondition: metav1.ConditionTrue,
+		},
+	}
+	for _, step := range steps {
+		factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", step.keystore)
+		if step.configure != nil {
+			factory.Dbclient.SetMethodToResp("ConfigureKeystore", step.configure)
+		}
+		err := r.reconcileKeystore(context.Background(), inst, logr.Discard())
+		if gotErr := err != nil; gotErr != step.wantErr {
+			t.Fatalf("%s: reconcileKeystore got error %v, want error: %v", step.name, err, step.wantErr)
+		}
+		if len(accessed) != step.wantAccessed {
+			t.Errorf("%s: got %d keystore password reads, want %d", step.name, len(accessed), step.wantAccessed)
+		}
+		if got := factory.Dbclient.BounceDatabaseCalledCnt(); got != step.wantBounces {
+			t.Errorf("%s: got %d database bounces, want %d", step.name, got, step.wantBounces)
+		}
+		if got := inst.Status.Keystore; step.wantStatus != "" && (got == nil || got.Status != step.wantStatus) {
+			t.Errorf("%s: got keystore status %+v, want %s", step.name, got, step.wantStatus)
+		}
+		if cond := k8s.FindCondition(inst.Status.Conditions, k8s.KeystoreOpen); cond == nil || cond.Status != step.wantCondition {
+			t.Errorf("%s: got %s condition %+v, want status %s", step.name, k8s.KeystoreOpen, cond, step.wantCondition)
+		}
+	}
+
+	inst.Spec.TDE.ManageKeystore = false
+	if err := r.reconcileKeystore(context.Background(), inst, logr.Discard()); err != nil || inst.Status.Keystore != nil {
+		t.Errorf("reconcileKeystore without manageKeystore got error %v and status %+v, want neither", err, inst.Status.Keystore)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// licensedOptions maps spec.licensedOptions to the dbdaemon options.
+var licensedOptions = map[v1alpha1.LicensedOption]dbdpb.GetFeatureUsageRequest_Option{
+	"Partitioning":        dbdpb.GetFeatureUsageRequest_PARTITIONING,
+	"AdvancedCompression": dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"DatabaseInMemory":    dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY,
+	"DiagnosticsPack":     dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"TuningPack":          dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+}
+
+// licenseRequest converts spec.edition and spec.licensedOptions into a
+// SetLicense request, unknown options are skipped.
+func licenseRequest(inst *v1alpha1.Instance) *dbdpb.SetLicenseRequest {
+	req := &dbdpb.SetLicenseRequest{Edition: inst.Spec.Edition}
+	for _, o := range inst.Spec.LicensedOptions {
+		if option, ok := licensedOptions[o]; ok {
+			req.LicensedOptions = append(req.LicensedOptions, option)
+		}
+	}
+	return req
+}
+
+// reconcileLicense declares the license of spec.edition and
+// spec.licensedOptions to the dbdaemon, which refuses to enable the options
+// it doesn't cover. The dbdaemon only persists a changed license, so this is
+// safe to call on every reconcile.
+func (r *InstanceReconciler) reconcileLicense(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	if _, err := dbClient.SetLicense(ctx, licenseRequest(inst)); err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.LicenseDeclareFailed, "Failed to declare the database license: %v", err)
+		return err
+	}
+	return nil
+}
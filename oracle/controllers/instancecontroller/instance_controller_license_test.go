@@ -0,0 +1,95 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/client-go/tools/record"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileLicense(t *testing.T) {
+	tests := []struct {
+		name        string
+		edition     string
+		options     []v1alpha1.LicensedOption
+		err         error
+		wantErr     bool
+		wantRequest *dbdpb.SetLicenseRequest
+	}{
+		{
+			name:        "edition only",
+			edition:     "Standard",
+			wantRequest: &dbdpb.SetLicenseRequest{Edition: "Standard"},
+		},
+		{
+			name:    "licensed options",
+			edition: "Enterprise",
+			options: []v1alpha1.LicensedOption{"Partitioning", "DiagnosticsPack", "TuningPack"},
+			wantRequest: &dbdpb.SetLicenseRequest{
+				Edition: "Enterprise",
+				LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{
+					dbdpb.GetFeatureUsageRequest_PARTITIONING,
+					dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+					dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+				},
+			},
+		},
+		{
+			name:        "failed",
+			err:         errors.New("failed to persist the license"),
+			wantErr:     true,
+			wantRequest: &dbdpb.SetLicenseRequest{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("SetLicense", tc.err)
+			}
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{
+				InstanceSpec:    commonv1alpha1.InstanceSpec{Edition: tc.edition},
+				LicensedOptions: tc.options,
+			}}
+
+			err := r.reconcileLicense(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileLicense got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.SetLicenseCalledCnt(); got != 1 {
+				t.Errorf("reconcileLicense called SetLicense %d times, want 1", got)
+			}
+			if diff := cmp.Diff(tc.wantRequest, factory.Dbclient.GotSetLicenseRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("reconcileLicense got unexpected request (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
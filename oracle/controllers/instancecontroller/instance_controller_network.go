@@ -0,0 +1,246 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// reconcileNetworkEncryption applies spec.networkEncryption to the listener
+// sqlnet.ora. The applied settings are recorded in the instance status so the
+// dbdaemon is only called when the spec changes. Removing the spec restores
+// the Oracle defaults.
+func (r *InstanceReconciler) reconcileNetworkEncryption(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if reflect.DeepEqual(inst.Spec.NetworkEncryption, inst.Status.CurrentNetworkEncryption) {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	req := &dbdpb.ConfigureNetworkEncryptionRequest{Disable: true}
+	if inst.Spec.NetworkEncryption != nil {
+		req = &dbdpb.ConfigureNetworkEncryptionRequest{
+			Level:      inst.Spec.NetworkEncryption.Level,
+			Algorithms: inst.Spec.NetworkEncryption.Algorithms,
+		}
+	}
+	resp, err := dbClient.ConfigureNetworkEncryption(ctx, req)
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.NetworkEncryptionFailed, "Failed to configure network encryption: %v", err)
+		return err
+	}
+	inst.Status.CurrentNetworkEncryption = inst.Spec.NetworkEncryption.DeepCopy()
+	if resp.GetChanged() {
+		log.Info("network encryption configured, listener restarted", "networkEncryption", inst.Spec.NetworkEncryption)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.NetworkEncryptionConfigured, "Network encryption configured, listener restarted")
+	}
+	return nil
+}
+
+// reconcileAllowedClients applies spec.allowedClients to the listener
+// sqlnet.ora. Valid node checking is turned off when the spec is removed.
+func (r *InstanceReconciler) reconcileAllowedClients(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	var clusterNodes []string
+	if inst.Spec.AllowedClients != nil && len(inst.Spec.AllowedClients.Invited) > 0 {
+		var err error
+		if clusterNodes, err = r.clusterPodCIDRs(ctx); err != nil {
+			return err
+		}
+	}
+	return r.applyAllowedClients(ctx, inst, clusterNodes, log)
+}
+
+// clusterPodCIDRs returns the sorted pod CIDR blocks of the cluster nodes.
+// Connections through a Service keep the client pod address, so these cover
+// agents running outside the database pod.
+func (r *InstanceReconciler) clusterPodCIDRs(ctx context.Context) ([]string, error) {
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %v", err)
+	}
+	seen := make(map[string]bool)
+	var cidrs []string
+	for _, n := range nodes.Items {
+		for _, cidr := range append([]string{n.Spec.PodCIDR}, n.Spec.PodCIDRs...) {
+			if cidr != "" && !seen[cidr] {
+				seen[cidr] = true
+				cidrs = append(cidrs, cidr)
+			}
+		}
+	}
+	sort.Strings(cidrs)
+	return cidrs, nil
+}
+
+// applyAllowedClients calls the dbdaemon only if the spec or the cluster
+// nodes differ from the configuration recorded in the instance status.
+func (r *InstanceReconciler) applyAllowedClients(ctx context.Context, inst *v1alpha1.Instance, clusterNodes []string, log logr.Logger) error {
+	req := &dbdpb.ConfigureAllowedClientsRequest{}
+	var want *v1alpha1.AllowedClientsSpec
+	if inst.Spec.AllowedClients != nil {
+		req.InvitedNodes = inst.Spec.AllowedClients.Invited
+		req.ExcludedNodes = inst.Spec.AllowedClients.Excluded
+		want = inst.Spec.AllowedClients.DeepCopy()
+		if len(want.Invited) > 0 {
+			req.ClusterNodes = clusterNodes
+			want.Invited = append(append([]string{}, clusterNodes...), want.Invited...)
+		}
+	}
+	if reflect.DeepEqual(want, inst.Status.CurrentAllowedClients) {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureAllowedClients(ctx, req)
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.AllowedClientsFailed, "Failed to configure allowed clients: %v", err)
+		return err
+	}
+	inst.Status.CurrentAllowedClients = want
+	if resp.GetChanged() {
+		log.Info("allowed clients configured, listener restarted", "allowedClients", inst.Spec.AllowedClients, "clusterNodes", clusterNodes)
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.AllowedClientsConfigured, "Allowed clients configured, listener restarted")
+	}
+	return nil
+}
+
+// tcpsWalletFiles are the keys of a TCPS wallet Secret passed to the
+// listener, an auto-login wallet and optionally the wallet it was created
+// from.
+var tcpsWalletFiles = []string{"cwallet.sso", "ewallet.p12"}
+
+// wantTCPS returns the TCPS listener status spec.tcps asks for, with the
+// request configuring it. The wallet Secret is read so that its changes
+// reinstall the wallet.
+func (r *InstanceReconciler) wantTCPS(ctx context.Context, inst *v1alpha1.Instance) (*v1alpha1.TCPSStatus, *dbdpb.ConfigureTCPSListenerRequest, error) {
+	spec := inst.Spec.TCPS
+	if spec == nil {
+		return nil, &dbdpb.ConfigureTCPSListenerRequest{Disable: true}, nil
+	}
+	want := &v1alpha1.TCPSStatus{Port: consts.SSLListenerPort}
+	req := &dbdpb.ConfigureTCPSListenerRequest{
+		DatabaseName: inst.Spec.CDBName,
+		DbDomain:     controllers.GetDBDomain(inst),
+		Port:         consts.SSLListenerPort,
+	}
+	if spec.WalletSecret == "" {
+		want.CertificateConfigMap = fmt.Sprintf(controllers.TCPSCertConfigMapName, inst.Name)
+		return want, req, nil
+	}
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: inst.Namespace, Name: spec.WalletSecret}, &secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to get the TCPS wallet Secret %q: %v", spec.WalletSecret, err)
+	}
+	req.Wallet = make(map[string][]byte)
+	for _, name := range tcpsWalletFiles {
+		if data, ok := secret.Data[name]; ok {
+			req.Wallet[name] = data
+		}
+	}
+	if len(req.Wallet[tcpsWalletFiles[0]]) == 0 {
+		return nil, nil, fmt.Errorf("the TCPS wallet Secret %q has no %s", spec.WalletSecret, tcpsWalletFiles[0])
+	}
+	want.WalletSecret = spec.WalletSecret
+	want.WalletSecretVersion = secret.ResourceVersion
+	return want, req, nil
+}
+
+// reconcileTCPS creates the TCPS listener of spec.tcps, which authenticates
+// with the wallet of its Secret or with a wallet the dbdaemon generates.
+// The certificate of a generated wallet is published in a ConfigMap for
+// clients to trust. The listener is stopped when the spec is removed.
+func (r *InstanceReconciler) reconcileTCPS(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	want, req, err := r.wantTCPS(ctx, inst)
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.TCPSListenerFailed, "Failed to configure the TCPS listener: %v", err)
+		return err
+	}
+	if reflect.DeepEqual(want, inst.Status.TCPS) {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureTCPSListener(ctx, req)
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.TCPSListenerFailed, "Failed to configure the TCPS listener: %v", err)
+		return err
+	}
+	if want != nil && want.CertificateConfigMap != "" {
+		if err := r.publishTCPSCert(ctx, inst, want.CertificateConfigMap, resp.GetCertificate()); err != nil {
+			return err
+		}
+	}
+	if last := inst.Status.TCPS; last != nil && last.CertificateConfigMap != "" && (want == nil || want.CertificateConfigMap == "") {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: last.CertificateConfigMap}}
+		if err := r.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "failed to delete the certificate ConfigMap of the generated TCPS wallet", "configMap", last.CertificateConfigMap)
+		}
+	}
+	inst.Status.TCPS = want
+	if want == nil {
+		log.Info("TCPS listener stopped")
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.TCPSListenerConfigured, "TCPS listener stopped")
+		return nil
+	}
+	log.Info("TCPS listener configured", "port", want.Port, "walletSecret", want.WalletSecret)
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.TCPSListenerConfigured, "TCPS listener configured on port %d", want.Port)
+	return nil
+}
+
+// publishTCPSCert writes the certificate of a generated TCPS wallet to the
+// ConfigMap name, owned by the instance.
+func (r *InstanceReconciler) publishTCPSCert(ctx context.Context, inst *v1alpha1.Instance, name, cert string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name}}
+	if _, err := ctrl.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if err := ctrl.SetControllerReference(inst, cm, r.Scheme()); err != nil {
+			return err
+		}
+		cm.Data = map[string]string{"ca.crt": cert}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to publish the TCPS certificate in ConfigMap %q: %v", name, err)
+	}
+	return nil
+}
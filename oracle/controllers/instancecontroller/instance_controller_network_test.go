@@ -0,0 +1,255 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileNetworkEncryption(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{}
+
+	steps := []struct {
+		name        string
+		spec        *v1alpha1.NetworkEncryptionSpec
+		wantCalls   int
+		wantDisable bool
+	}{
+		{
+			name: "never configured",
+		},
+		{
+			name:      "spec added",
+			spec:      &v1alpha1.NetworkEncryptionSpec{Level: "REQUIRED", Algorithms: []string{"AES256"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "spec unchanged",
+			spec:      &v1alpha1.NetworkEncryptionSpec{Level: "REQUIRED", Algorithms: []string{"AES256"}},
+			wantCalls: 1,
+		},
+		{
+			name:        "spec removed",
+			wantCalls:   2,
+			wantDisable: true,
+		},
+		{
+			name:        "spec still removed",
+			wantCalls:   2,
+			wantDisable: true,
+		},
+	}
+	for _, step := range steps {
+		inst.Spec.NetworkEncryption = step.spec
+		if err := r.reconcileNetworkEncryption(context.Background(), inst, logr.Discard()); err != nil {
+			t.Fatalf("%s: reconcileNetworkEncryption failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.ConfigureNetworkEncryptionCalledCnt(); got != step.wantCalls {
+			t.Errorf("%s: ConfigureNetworkEncryption called %d times, want %d", step.name, got, step.wantCalls)
+		}
+		if got := factory.Dbclient.GotConfigureNetworkEncryptionRequest.GetDisable(); got != step.wantDisable {
+			t.Errorf("%s: ConfigureNetworkEncryption got disable=%v, want %v", step.name, got, step.wantDisable)
+		}
+		if (inst.Status.CurrentNetworkEncryption == nil) != (step.spec == nil) {
+			t.Errorf("%s: status.currentNetworkEncryption=%v, want %v", step.name, inst.Status.CurrentNetworkEncryption, step.spec)
+		}
+	}
+}
+
+func TestApplyAllowedClients(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{}
+	clusterNodes := []string{"10.4.0.0/24"}
+
+	steps := []struct {
+		name         string
+		spec         *v1alpha1.AllowedClientsSpec
+		clusterNodes []string
+		wantCalls    int
+		wantCurrent  *v1alpha1.AllowedClientsSpec
+	}{
+		{
+			name: "never configured",
+		},
+		{
+			name:         "allow list added",
+			spec:         &v1alpha1.AllowedClientsSpec{Invited: []string{"app.example.com"}},
+			clusterNodes: clusterNodes,
+			wantCalls:    1,
+			wantCurrent:  &v1alpha1.AllowedClientsSpec{Invited: []string{"10.4.0.0/24", "app.example.com"}},
+		},
+		{
+			name:         "allow list unchanged",
+			spec:         &v1alpha1.AllowedClientsSpec{Invited: []string{"app.example.com"}},
+			clusterNodes: clusterNodes,
+			wantCalls:    1,
+			wantCurrent:  &v1alpha1.AllowedClientsSpec{Invited: []string{"10.4.0.0/24", "app.example.com"}},
+		},
+		{
+			name:         "cluster node added",
+			spec:         &v1alpha1.AllowedClientsSpec{Invited: []string{"app.example.com"}},
+			clusterNodes: []string{"10.4.0.0/24", "10.4.1.0/24"},
+			wantCalls:    2,
+			wantCurrent:  &v1alpha1.AllowedClientsSpec{Invited: []string{"10.4.0.0/24", "10.4.1.0/24", "app.example.com"}},
+		},
+		{
+			name:      "spec removed",
+			wantCalls: 3,
+		},
+		{
+			name:      "spec still removed",
+			wantCalls: 3,
+		},
+	}
+	for _, step := range steps {
+		inst.Spec.AllowedClients = step.spec
+		if err := r.applyAllowedClients(context.Background(), inst, step.clusterNodes, logr.Discard()); err != nil {
+			t.Fatalf("%s: applyAllowedClients failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.ConfigureAllowedClientsCalledCnt(); got != step.wantCalls {
+			t.Errorf("%s: ConfigureAllowedClients called %d times, want %d", step.name, got, step.wantCalls)
+		}
+		if diff := cmp.Diff(step.wantCurrent, inst.Status.CurrentAllowedClients); diff != "" {
+			t.Errorf("%s: got unexpected status.currentAllowedClients (-want +got):\n%v", step.name, diff)
+		}
+	}
+}
+
+func TestReconcileTCPS(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"}}
+	wallet := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-wallet"},
+		Data:       map[string][]byte{"cwallet.sso": []byte("wallet"), "tls.crt": []byte("cert")},
+	}
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("ConfigureTCPSListener", &dbdpb.ConfigureTCPSListenerResponse{Certificate: "CERT"})
+	r := &InstanceReconciler{
+		Client:                fake.NewClientBuilder().WithScheme(scheme).WithObjects(inst, wallet).Build(),
+		SchemeVal:             scheme,
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	certConfigMap := types.NamespacedName{Namespace: "db", Name: "mydb-tcps-cert"}
+
+	steps := []struct {
+		name          string
+		spec          *v1alpha1.TCPSSpec
+		wantCalls     int
+		wantWallet    []string
+		wantDisable   bool
+		wantPublished bool
+	}{
+		{
+			name: "never configured",
+		},
+		{
+			name:          "generated wallet",
+			spec:          &v1alpha1.TCPSSpec{},
+			wantCalls:     1,
+			wantPublished: true,
+		},
+		{
+			name:          "generated wallet unchanged",
+			spec:          &v1alpha1.TCPSSpec{},
+			wantCalls:     1,
+			wantPublished: true,
+		},
+		{
+			name:       "wallet Secret",
+			spec:       &v1alpha1.TCPSSpec{WalletSecret: "mydb-wallet"},
+			wantCalls:  2,
+			wantWallet: []string{"cwallet.sso"},
+		},
+		{
+			name:        "spec removed",
+			wantCalls:   3,
+			wantDisable: true,
+		},
+		{
+			name:        "spec still removed",
+			wantCalls:   3,
+			wantDisable: true,
+		},
+	}
+	for _, step := range steps {
+		inst.Spec.TCPS = step.spec
+		if err := r.reconcileTCPS(context.Background(), inst, logr.Discard()); err != nil {
+			t.Fatalf("%s: reconcileTCPS failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.ConfigureTCPSListenerCalledCnt(); got != step.wantCalls {
+			t.Errorf("%s: ConfigureTCPSListener called %d times, want %d", step.name, got, step.wantCalls)
+		}
+		if step.wantCalls == 0 {
+			continue
+		}
+		req := factory.Dbclient.GotConfigureTCPSListenerRequest
+		var gotWallet []string
+		for name := range req.GetWallet() {
+			gotWallet = append(gotWallet, name)
+		}
+		if diff := cmp.Diff(step.wantWallet, gotWallet); diff != "" {
+			t.Errorf("%s: ConfigureTCPSListener got unexpected wallet files (-want +got):\n%v", step.name, diff)
+		}
+		if got := req.GetDisable(); got != step.wantDisable {
+			t.Errorf("%s: ConfigureTCPSListener got disable=%v, want %v", step.name, got, step.wantDisable)
+		}
+		var cm corev1.ConfigMap
+		err := r.Get(context.Background(), certConfigMap, &cm)
+		if step.wantPublished && (err != nil || cm.Data["ca.crt"] != "CERT") {
+			t.Errorf("%s: certificate ConfigMap got (%v, %v), want the generated certificate", step.name, cm.Data, err)
+		}
+		if !step.wantPublished && !apierrors.IsNotFound(err) {
+			t.Errorf("%s: certificate ConfigMap got %v, want it deleted", step.name, err)
+		}
+		if (inst.Status.TCPS == nil) != (step.spec == nil) {
+			t.Errorf("%s: status.tcps=%v, want %v", step.name, inst.Status.TCPS, step.spec)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/standby"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	// observerCheckInterval is the interval of the checks of the primary.
+	observerCheckInterval = 15 * time.Second
+	// defaultFailoverThreshold is how long the primary has to be lost before
+	// an automatic failover if the observer doesn't set a threshold.
+	defaultFailoverThreshold = 2 * time.Minute
+
+	roleTransitionCompleted = "Completed"
+	roleTransitionFailed    = "Failed"
+)
+
+// roleTransitionRequested returns whether the instance requests a role
+// transition later than the last one.
+func roleTransitionRequested(inst *v1alpha1.Instance) bool {
+	if inst.Spec.DataGuard == nil || inst.Spec.DataGuard.RoleTransition == nil {
+		return false
+	}
+	last := inst.Status.LastRoleTransition
+	// Only completed automatic failovers have no request time, the instance
+	// is a primary after them.
+	return last == nil || (last.RequestTime != nil && inst.Spec.DataGuard.RoleTransition.RequestTime.After(last.RequestTime.Time))
+}
+
+// standbyRequeueInterval returns the interval of the reconciles of a
+// replicating standby, shorter than interval if the observer checks the
+// primary more often.
+func standbyRequeueInterval(inst *v1alpha1.Instance, interval time.Duration) time.Duration {
+	if inst.Spec.DataGuard != nil && inst.Spec.DataGuard.Observer != nil && observerCheckInterval < interval {
+		return observerCheckInterval
+	}
+	return interval
+}
+
+// observePrimary returns the observer status following the last one given
+// the health of the primary.
+func observePrimary(last *v1alpha1.ObserverStatus, health *standby.PrimaryHealth, now metav1.Time) *v1alpha1.ObserverStatus {
+	status := &v1alpha1.ObserverStatus{
+		LastCheckTime:       now,
+		BrokerStatus:        health.BrokerStatus,
+		TransportLagSeconds: health.TransportLagSeconds,
+	}
+	if !health.Lost() {
+		return status
+	}
+	// The lag of a lost primary grows with the outage, the data a failover
+	// loses is the lag observed while the primary was healthy.
+	status.TransportLagSeconds = -1
+	if last != nil {
+		status.TransportLagSeconds = last.TransportLagSeconds
+		status.LostSince = last.LostSince
+	}
+	if status.LostSince == nil {
+		status.LostSince = &now
+	}
+	brokerStatus := health.BrokerStatus
+	if brokerStatus == "" {
+		brokerStatus = "no configuration status"
+	}
+	status.Message = fmt.Sprintf("the primary listener doesn't answer and the broker reports %s: %v", brokerStatus, health.ListenerErr)
+	return status
+}
+
+// failoverDue returns whether the observer fails over to the standby and,
+// if the primary is lost but it doesn't, why not.
+func failoverDue(spec *v1alpha1.ObserverSpec, status *v1alpha1.ObserverStatus, now time.Time) (bool, string) {
+	if status.LostSince == nil {
+		return false, ""
+	}
+	if spec.Policy != v1alpha1.AutomaticFailover {
+		return false, "the failover policy is Manual, request a Failover roleTransition to fail over"
+	}
+	if spec.PrimaryInstance == "" {
+		return false, "the observer has no primaryInstance to fence, a standby can't tell a lost primary from its own network partition"
+	}
+	threshold := defaultFailoverThreshold
+	if spec.Threshold != nil {
+		threshold = spec.Threshold.Duration
+	}
+	if lost := now.Sub(status.LostSince.Time); lost < threshold {
+		return false, fmt.Sprintf("the primary has been lost for %v, failing over after %v", lost.Round(time.Second), threshold)
+	}
+	if spec.LagLimit != nil {
+		if status.TransportLagSeconds < 0 {
+			return false, fmt.Sprintf("the transport lag isn't known, failing over needs it within the lagLimit %v", spec.LagLimit.Duration)
+		}
+		if lag := time.Duration(status.TransportLagSeconds) * time.Second; lag > spec.LagLimit.Duration {
+			return false, fmt.Sprintf("the transport lag %v exceeds the lagLimit %v", lag, spec.LagLimit.Duration)
+		}
+	}
+	return true, ""
+}
+
+// reconcileObserver runs the role transition requested by the instance or
+// checks the primary and fails over to the standby if the observer policy
+// says so. It returns whether the standby took over the primary role.
+func (r *InstanceReconciler) reconcileObserver(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (bool, error) {
+	dg := inst.Spec.DataGuard
+	if dg == nil {
+		return false, nil
+	}
+	if roleTransitionRequested(inst) {
+		if dg.RoleTransition.Type == v1alpha1.FailoverTransition && dg.Observer != nil && dg.Observer.PrimaryInstance != "" {
+			fenced, reason, err := r.fencePrimary(ctx, inst, dg.Observer.PrimaryInstance, log)
+			if err != nil || !fenced {
+				if reason != "" {
+					log.Info("failover waits for the primary to be fenced", "reason", reason)
+				}
+				return false, err
+			}
+		}
+		return r.transitionRole(ctx, inst, dg.RoleTransition.Type, &dg.RoleTransition.RequestTime, log)
+	}
+	if dg.Observer == nil {
+		inst.Status.Observer = nil
+		return false, nil
+	}
+	// Status updates trigger reconciles, the primary is only checked once
+	// per interval.
+	last := inst.Status.Observer
+	if last != nil && time.Since(last.LastCheckTime.Time) < observerCheckInterval {
+		return false, nil
+	}
+
+	settings := inst.Spec.ReplicationSettings
+	health, err := controllers.CheckPrimaryHealth(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.CheckPrimaryHealthRequest{
+		PrimaryHost:         settings.PrimaryHost,
+		PrimaryPort:         settings.PrimaryPort,
+		PrimaryService:      settings.PrimaryServiceName,
+		StandbyDbUniqueName: inst.Spec.DBUniqueName,
+	})
+	if err != nil {
+		return false, err
+	}
+	status := observePrimary(last, health, metav1.Now())
+	wasLost := last != nil && last.LostSince != nil
+	if status.LostSince != nil && !wasLost {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.PrimaryUnreachable, "The primary is lost: %s", status.Message)
+	} else if status.LostSince == nil && wasLost {
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.PrimaryRecovered, "The primary is healthy again after %v", status.LastCheckTime.Sub(last.LostSince.Time).Round(time.Second))
+	}
+	due, reason := failoverDue(dg.Observer, status, status.LastCheckTime.Time)
+	if reason != "" {
+		status.Message = fmt.Sprintf("%s; %s", status.Message, reason)
+	}
+	inst.Status.Observer = status
+	if !due {
+		return false, nil
+	}
+	fenced, reason, err := r.fencePrimary(ctx, inst, dg.Observer.PrimaryInstance, log)
+	if err != nil {
+		return false, err
+	}
+	if !fenced {
+		status.Message = fmt.Sprintf("%s; %s", status.Message, reason)
+		return false, nil
+	}
+	log.Info("primary lost and fenced, failing over to the standby", "lostSince", status.LostSince)
+	return r.transitionRole(ctx, inst, v1alpha1.FailoverTransition, nil, log)
+}
+
+// fencePrimary stops the Instance of the primary so that it can't keep
+// serving clients as a second primary after a failover, the standby alone
+// can't tell a lost primary from its own network partition. It returns
+// whether the database pod of the primary is gone and, if not, what the
+// failover waits for. A pod on an unreachable node isn't gone until the
+// node is, the failover waits for it too.
+func (r *InstanceReconciler) fencePrimary(ctx context.Context, inst *v1alpha1.Instance, name string, log logr.Logger) (bool, string, error) {
+	var primary v1alpha1.Instance
+	if err := r.Get(ctx, client.ObjectKey{Namespace: inst.Namespace, Name: name}, &primary); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Sprintf("the primary Instance %s doesn't exist, it can't be fenced", name), nil
+		}
+		return false, "", err
+	}
+	if !IsStopped(&primary) {
+		log.Info("stopping the primary Instance before failing over", "primary", name)
+		primary.Spec.IsStopped = pointer.Bool(true)
+		if err := r.Update(ctx, &primary); err != nil {
+			return false, "", fmt.Errorf("failed to stop the primary Instance %s: %v", name, err)
+		}
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.PrimaryFenced, "Stopping the primary Instance %s before failing over", name)
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(inst.Namespace), client.MatchingLabels{"instance": name, "task-type": controllers.DatabaseTaskType}); err != nil {
+		return false, "", err
+	}
+	if n := len(pods.Items); n > 0 {
+		return false, fmt.Sprintf("waiting for %d database pod(s) of the primary Instance %s to stop", n, name), nil
+	}
+	return true, "", nil
+}
+
+// transitionRole switches over or fails over to the standby, points the
+// primary Service at it and removes its replication settings, so that the
+// standby state machine bootstraps it as a primary. It returns whether the
+// standby took over the primary role, a failed role transition is recorded
+// in the status.
+func (r *InstanceReconciler) transitionRole(ctx context.Context, inst *v1alpha1.Instance, transition v1alpha1.RoleTransitionType, requestTime *metav1.Time, log logr.Logger) (bool, error) {
+	settings := inst.Spec.ReplicationSettings
+	req := controllers.TransitionRoleRequest{
+		PrimaryHost:         settings.PrimaryHost,
+		PrimaryPort:         settings.PrimaryPort,
+		PrimaryService:      settings.PrimaryServiceName,
+		StandbyDbUniqueName: inst.Spec.DBUniqueName,
+		Switchover:          transition == v1alpha1.SwitchoverTransition,
+	}
+	if req.Switchover {
+		credentialReq, err := toCredentialReq(settings.PrimaryUser)
+		if err != nil {
+			return false, err
+		}
+		req.PrimaryUser = settings.PrimaryUser.Name
+		req.PrimaryCredential = credentialReq
+	}
+	log.Info("transitioning the standby to the primary role", "type", transition)
+	start := metav1.Now()
+	err := controllers.TransitionRole(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, req)
+	end := metav1.Now()
+	status := &v1alpha1.RoleTransitionStatus{
+		Type:           transition,
+		RequestTime:    requestTime,
+		State:          roleTransitionCompleted,
+		StartTime:      &start,
+		CompletionTime: &end,
+	}
+	if err != nil {
+		status.State = roleTransitionFailed
+		status.Message = err.Error()
+		// Failed automatic failovers are retried by the next checks, the
+		// last role transition records the requested ones.
+		if requestTime != nil {
+			inst.Status.LastRoleTransition = status
+		} else if inst.Status.Observer != nil {
+			inst.Status.Observer.Message = fmt.Sprintf("the failover failed: %v", err)
+		}
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.RoleTransitionFailed, "%s to the standby failed: %v", transition, err)
+		return false, nil
+	}
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.RoleTransitionCompleted, "%s to the standby completed in %v, it is the primary now", transition, end.Sub(start.Time).Round(time.Second))
+
+	if name := inst.Spec.DataGuard.PrimaryService; name != "" {
+		if err := r.updatePrimaryService(ctx, inst, name); err != nil {
+			log.Error(err, "failed to update the primary Service", "service", name)
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.RoleTransitionFailed, "Failed to point Service %q at the new primary: %v", name, err)
+		} else {
+			r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.PrimaryServiceUpdated, "Service %q points at the new primary", name)
+		}
+	}
+
+	// The standby is the primary now, it no longer replicates.
+	inst.Spec.ReplicationSettings = nil
+	if err := r.Update(ctx, inst); err != nil {
+		return false, fmt.Errorf("failed to update instance spec: %v", err)
+	}
+	inst.Status.LastRoleTransition = status
+	inst.Status.Observer = nil
+	return true, nil
+}
+
+// updatePrimaryService points the Service clients connect to the primary
+// with at the database pod of the instance.
+func (r *InstanceReconciler) updatePrimaryService(ctx context.Context, inst *v1alpha1.Instance, name string) error {
+	svc := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: inst.Namespace},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				"instance":  inst.Name,
+				"task-type": controllers.DatabaseTaskType,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "secure-listener",
+					Protocol:   "TCP",
+					Port:       consts.SecureListenerPort,
+					TargetPort: intstr.FromInt(consts.SecureListenerPort),
+				},
+				{
+					Name:       "ssl-listener",
+					Protocol:   "TCP",
+					Port:       consts.SSLListenerPort,
+					TargetPort: intstr.FromInt(consts.SSLListenerPort),
+				},
+			},
+		},
+	}
+	return r.Patch(ctx, svc, client.Apply, client.ForceOwnership, client.FieldOwner("instance-controller"))
+}
@@ -0,0 +1,297 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/standby"
+)
+
+func TestRoleTransitionRequested(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	tests := []struct {
+		name       string
+		transition *v1alpha1.RoleTransitionSpec
+		last       *v1alpha1.RoleTransitionStatus
+		want       bool
+	}{
+		{
+			name: "no request",
+		},
+		{
+			name:       "first request",
+			transition: &v1alpha1.RoleTransitionSpec{Type: v1alpha1.SwitchoverTransition, RequestTime: now},
+			want:       true,
+		},
+		{
+			name:       "already run",
+			transition: &v1alpha1.RoleTransitionSpec{Type: v1alpha1.SwitchoverTransition, RequestTime: now},
+			last:       &v1alpha1.RoleTransitionStatus{Type: v1alpha1.SwitchoverTransition, RequestTime: &now, State: roleTransitionFailed},
+		},
+		{
+			name:       "new request",
+			transition: &v1alpha1.RoleTransitionSpec{Type: v1alpha1.FailoverTransition, RequestTime: now},
+			last:       &v1alpha1.RoleTransitionStatus{Type: v1alpha1.SwitchoverTransition, RequestTime: &earlier, State: roleTransitionFailed},
+			want:       true,
+		},
+		{
+			name:       "after an automatic failover",
+			transition: &v1alpha1.RoleTransitionSpec{Type: v1alpha1.FailoverTransition, RequestTime: now},
+			last:       &v1alpha1.RoleTransitionStatus{Type: v1alpha1.FailoverTransition, State: roleTransitionCompleted},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst := &v1alpha1.Instance{
+				Spec:   v1alpha1.InstanceSpec{DataGuard: &v1alpha1.DataGuardSpec{RoleTransition: tc.transition}},
+				Status: v1alpha1.InstanceStatus{LastRoleTransition: tc.last},
+			}
+			if got := roleTransitionRequested(inst); got != tc.want {
+				t.Errorf("roleTransitionRequested got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestObservePrimary(t *testing.T) {
+	start := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(start.Add(time.Minute))
+	unreachable := errors.New("TNS-12541: TNS:no listener")
+	tests := []struct {
+		name          string
+		last          *v1alpha1.ObserverStatus
+		health        *standby.PrimaryHealth
+		wantLostSince *metav1.Time
+		wantLag       int64
+	}{
+		{
+			name:    "healthy",
+			health:  &standby.PrimaryHealth{BrokerStatus: "SUCCESS", TransportLagSeconds: 2},
+			wantLag: 2,
+		},
+		{
+			name:    "listener unreachable only",
+			last:    &v1alpha1.ObserverStatus{TransportLagSeconds: 2},
+			health:  &standby.PrimaryHealth{ListenerErr: unreachable, BrokerStatus: "SUCCESS", TransportLagSeconds: 3},
+			wantLag: 3,
+		},
+		{
+			name:          "lost",
+			last:          &v1alpha1.ObserverStatus{TransportLagSeconds: 2},
+			health:        &standby.PrimaryHealth{ListenerErr: unreachable, BrokerStatus: "ERROR", TransportLagSeconds: 40},
+			wantLostSince: &now,
+			wantLag:       2,
+		},
+		{
+			name:          "still lost",
+			last:          &v1alpha1.ObserverStatus{LostSince: &start, TransportLagSeconds: 2},
+			health:        &standby.PrimaryHealth{ListenerErr: unreachable, TransportLagSeconds: 100},
+			wantLostSince: &start,
+			wantLag:       2,
+		},
+		{
+			name:          "lost on the first check",
+			health:        &standby.PrimaryHealth{ListenerErr: unreachable, BrokerStatus: "ERROR", TransportLagSeconds: 40},
+			wantLostSince: &now,
+			wantLag:       -1,
+		},
+		{
+			name:    "recovered",
+			last:    &v1alpha1.ObserverStatus{LostSince: &start, TransportLagSeconds: 2},
+			health:  &standby.PrimaryHealth{BrokerStatus: "WARNING", TransportLagSeconds: 30},
+			wantLag: 30,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := observePrimary(tc.last, tc.health, now)
+			if (got.LostSince == nil) != (tc.wantLostSince == nil) || (got.LostSince != nil && !got.LostSince.Equal(tc.wantLostSince)) {
+				t.Errorf("observePrimary got lost since %v, want %v", got.LostSince, tc.wantLostSince)
+			}
+			if got.TransportLagSeconds != tc.wantLag {
+				t.Errorf("observePrimary got transport lag %d, want %d", got.TransportLagSeconds, tc.wantLag)
+			}
+			if (got.Message != "") != (tc.wantLostSince != nil) {
+				t.Errorf("observePrimary got message %q, want one only if the primary is lost", got.Message)
+			}
+		})
+	}
+}
+
+func TestFailoverDue(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 10, 0, 0, time.UTC)
+	lostSince := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-d))
+		return &t
+	}
+	tests := []struct {
+		name       string
+		spec       v1alpha1.ObserverSpec
+		status     v1alpha1.ObserverStatus
+		want       bool
+		wantReason bool
+	}{
+		{
+			name:   "healthy",
+			spec:   v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary"},
+			status: v1alpha1.ObserverStatus{TransportLagSeconds: 0},
+		},
+		{
+			name:       "manual policy",
+			status:     v1alpha1.ObserverStatus{LostSince: lostSince(time.Hour)},
+			wantReason: true,
+		},
+		{
+			name:       "no primary instance to fence",
+			spec:       v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover},
+			status:     v1alpha1.ObserverStatus{LostSince: lostSince(time.Hour)},
+			wantReason: true,
+		},
+		{
+			name:       "within the default threshold",
+			spec:       v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary"},
+			status:     v1alpha1.ObserverStatus{LostSince: lostSince(time.Minute)},
+			wantReason: true,
+		},
+		{
+			name:   "past the default threshold",
+			spec:   v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary"},
+			status: v1alpha1.ObserverStatus{LostSince: lostSince(3 * time.Minute), TransportLagSeconds: -1},
+			want:   true,
+		},
+		{
+			name:   "past a threshold",
+			spec:   v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary", Threshold: &metav1.Duration{Duration: 30 * time.Second}},
+			status: v1alpha1.ObserverStatus{LostSince: lostSince(time.Minute)},
+			want:   true,
+		},
+		{
+			name:   "within the lag limit",
+			spec:   v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary", LagLimit: &metav1.Duration{Duration: 30 * time.Second}},
+			status: v1alpha1.ObserverStatus{LostSince: lostSince(time.Hour), TransportLagSeconds: 30},
+			want:   true,
+		},
+		{
+			name:       "over the lag limit",
+			spec:       v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary", LagLimit: &metav1.Duration{Duration: 30 * time.Second}},
+			status:     v1alpha1.ObserverStatus{LostSince: lostSince(time.Hour), TransportLagSeconds: 31},
+			wantReason: true,
+		},
+		{
+			name:       "unknown lag with a lag limit",
+			spec:       v1alpha1.ObserverSpec{Policy: v1alpha1.AutomaticFailover, PrimaryInstance: "primary", LagLimit: &metav1.Duration{Duration: 30 * time.Second}},
+			status:     v1alpha1.ObserverStatus{LostSince: lostSince(time.Hour), TransportLagSeconds: -1},
+			wantReason: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, reason := failoverDue(&tc.spec, &tc.status, now)
+			if got != tc.want {
+				t.Errorf("failoverDue got %v, want %v", got, tc.want)
+			}
+			if (reason != "") != tc.wantReason {
+				t.Errorf("failoverDue got reason %q, want a reason %v", reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+// TestFencePrimaryPartition covers a standby partitioned from the network:
+// it sees the primary as lost while the primary still runs, so the failover
+// has to wait until the primary Instance is stopped and its pod is gone.
+func TestFencePrimaryPartition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	standbyInst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "standby"}}
+	primary := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "primary"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "db",
+		Name:      "primary-sts-0",
+		Labels:    map[string]string{"instance": "primary", "task-type": controllers.DatabaseTaskType},
+	}}
+	recorder := record.NewFakeRecorder(10)
+	r := &InstanceReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(standbyInst, primary, pod).Build(),
+		SchemeVal: scheme,
+		Recorder:  recorder,
+	}
+	ctx := context.Background()
+
+	fenced, reason, err := r.fencePrimary(ctx, standbyInst, "primary", logr.Discard())
+	if err != nil {
+		t.Fatalf("fencePrimary failed: %v", err)
+	}
+	if fenced || reason == "" {
+		t.Errorf("fencePrimary with a running primary pod got fenced %v, reason %q, want not fenced with a reason", fenced, reason)
+	}
+	var got v1alpha1.Instance
+	if err := r.Get(ctx, client.ObjectKeyFromObject(primary), &got); err != nil {
+		t.Fatalf("failed to get the primary Instance: %v", err)
+	}
+	if !IsStopped(&got) {
+		t.Errorf("fencePrimary didn't stop the primary Instance")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("fencePrimary recorded %d events, want 1", len(recorder.Events))
+	}
+
+	if err := r.Delete(ctx, pod); err != nil {
+		t.Fatalf("failed to delete the primary pod: %v", err)
+	}
+	fenced, reason, err = r.fencePrimary(ctx, standbyInst, "primary", logr.Discard())
+	if err != nil {
+		t.Fatalf("fencePrimary failed: %v", err)
+	}
+	if !fenced {
+		t.Errorf("fencePrimary with the primary pod gone got not fenced: %s", reason)
+	}
+
+	if fenced, reason, err := r.fencePrimary(ctx, standbyInst, "missing", logr.Discard()); err != nil || fenced || reason == "" {
+		t.Errorf("fencePrimary of a missing Instance got (%v, %q, %v), want not fenced with a reason", fenced, reason, err)
+	}
+}
+
+func TestStandbyRequeueInterval(t *testing.T) {
+	inst := &v1alpha1.Instance{}
+	if got := standbyRequeueInterval(inst, StandbyReconcileInterval); got != StandbyReconcileInterval {
+		t.Errorf("standbyRequeueInterval without an observer got %v, want %v", got, StandbyReconcileInterval)
+	}
+	inst.Spec.DataGuard = &v1alpha1.DataGuardSpec{Observer: &v1alpha1.ObserverSpec{}}
+	if got := standbyRequeueInterval(inst, StandbyReconcileInterval); got != observerCheckInterval {
+		t.Errorf("standbyRequeueInterval with an observer got %v, want %v", got, observerCheckInterval)
+	}
+}
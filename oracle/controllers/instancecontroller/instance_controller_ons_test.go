@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileONS(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        *v1alpha1.ONSSpec
+		err         error
+		wantErr     bool
+		wantCalls   int
+		wantRequest *dbdpb.ConfigureFANRequest
+	}{
+		{
+			name: "not managed",
+		},
+		{
+			name:        "defaults",
+			spec:        &v1alpha1.ONSSpec{},
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureFANRequest{Ons: &dbdpb.ConfigureFANRequest_ONS{}},
+		},
+		{
+			name:      "nodes",
+			spec:      &v1alpha1.ONSSpec{RemotePort: 6201, Nodes: []string{"standby:6201"}},
+			wantCalls: 1,
+			wantRequest: &dbdpb.ConfigureFANRequest{
+				Ons: &dbdpb.ConfigureFANRequest_ONS{RemotePort: 6201, Nodes: []string{"standby:6201"}},
+			},
+		},
+		{
+			name:        "failed",
+			spec:        &v1alpha1.ONSSpec{Nodes: []string{"standby"}},
+			err:         errors.New("invalid ONS node"),
+			wantErr:     true,
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureFANRequest{Ons: &dbdpb.ConfigureFANRequest_ONS{Nodes: []string{"standby"}}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("ConfigureFAN", tc.err)
+			}
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{ONS: tc.spec}}
+
+			err := r.reconcileONS(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileONS got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.ConfigureFANCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileONS called ConfigureFAN %d times, want %d", got, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantRequest, factory.Dbclient.GotConfigureFANRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("reconcileONS got unexpected request (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
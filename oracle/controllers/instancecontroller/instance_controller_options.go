@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// optionComponents maps the database options to their dba_registry
+// component IDs.
+var optionComponents = map[v1alpha1.DatabaseOption]string{
+	"Text":    "CONTEXT",
+	"JSON":    "XDB",
+	"Spatial": "SDO",
+}
+
+// requiredComponents returns the dba_registry component IDs of the required
+// options of the instance.
+func requiredComponents(inst *v1alpha1.Instance) []string {
+	var ids []string
+	for _, option := range inst.Spec.RequiredOptions {
+		if id, ok := optionComponents[option]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// reconcileRequiredOptions verifies that the options required by
+// spec.requiredOptions are installed and VALID, recompiling the invalid ones
+// if spec.recompileInvalidOptions is set. Verification only runs again once
+// the spec generation changes or if it didn't succeed.
+func (r *InstanceReconciler) reconcileRequiredOptions(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if len(inst.Spec.RequiredOptions) == 0 {
+		inst.Status.InstalledOptions = nil
+		return nil
+	}
+	if cond := k8s.FindCondition(inst.Status.Conditions, k8s.RequiredOptionsValid); cond != nil && cond.Reason == k8s.OptionsVerified && cond.ObservedGeneration == inst.Generation {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.GetInstalledOptions(ctx, &dbdpb.GetInstalledOptionsRequest{
+		RequiredComponents: requiredComponents(inst),
+		RecompileInvalid:   inst.Spec.RecompileInvalidOptions,
+	})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RequiredOptionsValid, v1.ConditionUnknown, k8s.OptionsVerifyFailed, fmt.Sprintf("failed to verify the installed options: %v", err)).ObservedGeneration = inst.Generation
+		return fmt.Errorf("failed to verify the installed options: %v", err)
+	}
+
+	installed := make(map[string]string)
+	for _, option := range resp.GetOptions() {
+		installed[option.GetCompId()] = option.GetStatus()
+	}
+	inst.Status.InstalledOptions = installed
+	if recompiled := resp.GetRecompiledComponents(); len(recompiled) > 0 {
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.OptionsRecompiled, "Recompiled invalid components %s", strings.Join(recompiled, ", "))
+	}
+
+	var msg, reason string
+	switch {
+	case len(resp.GetMissingComponents()) > 0:
+		reason = k8s.OptionsMissing
+		msg = fmt.Sprintf("Required components aren't installed: %s", strings.Join(resp.GetMissingComponents(), ", "))
+	case len(resp.GetInvalidComponents()) > 0:
+		reason = k8s.OptionsInvalid
+		msg = fmt.Sprintf("Required components aren't VALID: %s", strings.Join(resp.GetInvalidComponents(), ", "))
+	default:
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RequiredOptionsValid, v1.ConditionTrue, k8s.OptionsVerified, "").ObservedGeneration = inst.Generation
+		return nil
+	}
+	log.Info("required options verification failed", "reason", reason, "message", msg)
+	r.Recorder.Event(inst, corev1.EventTypeWarning, reason, msg)
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.RequiredOptionsValid, v1.ConditionFalse, reason, msg).ObservedGeneration = inst.Generation
+	return nil
+}
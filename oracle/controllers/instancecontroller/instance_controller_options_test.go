@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestReconcileRequiredOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *dbdpb.GetInstalledOptionsResponse
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name: "valid",
+			resp: &dbdpb.GetInstalledOptionsResponse{
+				Options: []*dbdpb.InstalledOption{{CompId: "CONTEXT", Status: "VALID"}, {CompId: "SDO", Status: "VALID"}},
+			},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: k8s.OptionsVerified,
+		},
+		{
+			name: "missing",
+			resp: &dbdpb.GetInstalledOptionsResponse{
+				Options:           []*dbdpb.InstalledOption{{CompId: "CONTEXT", Status: "VALID"}},
+				MissingComponents: []string{"SDO"},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.OptionsMissing,
+		},
+		{
+			name: "invalid",
+			resp: &dbdpb.GetInstalledOptionsResponse{
+				Options:           []*dbdpb.InstalledOption{{CompId: "CONTEXT", Status: "INVALID"}, {CompId: "SDO", Status: "VALID"}},
+				InvalidComponents: []string{"CONTEXT"},
+			},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.OptionsInvalid,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("GetInstalledOptions", tc.resp)
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec: v1alpha1.InstanceSpec{
+					RequiredOptions:         []v1alpha1.DatabaseOption{"Text", "Spatial"},
+					RecompileInvalidOptions: true,
+				},
+			}
+			if err := r.reconcileRequiredOptions(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileRequiredOptions failed: %v", err)
+			}
+			gotReq := factory.Dbclient.GotGetInstalledOptionsRequest
+			if diff := cmp.Diff([]string{"CONTEXT", "SDO"}, gotReq.GetRequiredComponents()); diff != "" {
+				t.Errorf("reconcileRequiredOptions requested unexpected components (-want +got):\n%v", diff)
+			}
+			if !gotReq.GetRecompileInvalid() {
+				t.Errorf("reconcileRequiredOptions didn't request the recompilation of invalid components")
+			}
+			cond := k8s.FindCondition(inst.Status.Conditions, k8s.RequiredOptionsValid)
+			if cond == nil || cond.Status != tc.wantStatus || cond.Reason != tc.wantReason {
+				t.Errorf("reconcileRequiredOptions got condition %+v, want status %s and reason %s", cond, tc.wantStatus, tc.wantReason)
+			}
+			want := make(map[string]string)
+			for _, option := range tc.resp.Options {
+				want[option.CompId] = option.Status
+			}
+			if diff := cmp.Diff(want, inst.Status.InstalledOptions); diff != "" {
+				t.Errorf("reconcileRequiredOptions got unexpected installed options (-want +got):\n%v", diff)
+			}
+
+			if err := r.reconcileRequiredOptions(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileRequiredOptions failed: %v", err)
+			}
+			wantCalls := 2
+			if tc.wantReason == k8s.OptionsVerified {
+				wantCalls = 1
+			}
+			if got := factory.Dbclient.GetInstalledOptionsCalledCnt(); got != wantCalls {
+				t.Errorf("GetInstalledOptions called %d times, want %d", got, wantCalls)
+			}
+		})
+	}
+}
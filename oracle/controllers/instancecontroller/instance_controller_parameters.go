@@ -33,27 +33,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// reservedParameters holds the list of parameters that aren't allowed for modification.
-var reservedParameters = map[string]bool{
-	"audit_file_dest":           true,
-	"audit_trail":               true,
-	"compatible":                true,
-	"control_files":             true,
-	"db_block_size":             true,
-	"db_recovery_file_dest":     true,
-	"diagnostic_dest":           true,
-	"dispatchers":               true,
-	"enable_pluggable_database": true,
-	"filesystemio_options":      true,
-	"local_listener":            true,
-	"remote_login_passwordfile": true,
-	"undo_tablespace":           true,
-	"log_archive_dest_1":        true,
-	"log_archive_dest_state_1":  true,
-	"log_archive_format":        true,
-	"standby_file_management":   true,
-}
-
 func (r *InstanceReconciler) recordEventAndUpdateStatus(ctx context.Context, inst *v1alpha1.Instance, conditionStatus v1.ConditionStatus, reason, msg string, log logr.Logger) {
 	if conditionStatus == v1.ConditionTrue {
 		r.Recorder.Eventf(inst, corev1.EventTypeNormal, reason, msg)
@@ -80,7 +59,7 @@ func fetchCurrentParameterState(ctx context.Context, r client.Reader, dbClientFa
 	var unacceptableParams []string
 	var keys []string
 	for k := range spec.Parameters {
-		if _, ok := reservedParameters[k]; ok {
+		if _, ok := controllers.ReservedParameters[k]; ok {
 			unacceptableParams = append(unacceptableParams, k)
 		}
 		keys = append(keys, k)
@@ -212,6 +191,19 @@ func (r *InstanceReconciler) parameterUpdateStateMachine(ctx context.Context, re
 		log.Info("parameterUpdateStateMachine: SM CreateComplete -> ParameterUpdateInProgress")
 
 	case k8s.ParameterUpdateInProgress:
+		// Parameters scoped to a single SID in the spfile take precedence
+		// over the sid='*' values set below, so scope them to all instances
+		// first.
+		statements, err := controllers.NormalizeParameters(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name)
+		if err != nil {
+			msg := "parameterUpdateStateMachine: Error while normalizing instance parameters"
+			r.recordEventAndUpdateStatus(ctx, &inst, v1.ConditionFalse, k8s.ParameterUpdateRollbackInProgress, fmt.Sprintf("%s: %v", msg, err), log)
+			log.Info("parameterUpdateStateMachine: SM ParameterUpdateInProgress -> ParameterUpdateRollbackInProgress")
+			return ctrl.Result{Requeue: true}, nil
+		}
+		if len(statements) > 0 {
+			log.Info("parameterUpdateStateMachine: normalized instance parameters", "statements", statements)
+		}
 		restartRequired, err := r.setParameters(ctx, inst, log)
 		if err != nil {
 			msg := "parameterUpdateStateMachine: Error while setting instance parameters"
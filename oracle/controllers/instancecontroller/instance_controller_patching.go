@@ -39,6 +39,14 @@ import (
 
 const (
 	deploymentPatchingTimeout = 3 * time.Minute
+
+	// awrBaselineWindowHours is the period of the AWR baselines captured
+	// before and after patching, AWR drops them after
+	// awrBaselineExpirationDays.
+	awrBaselineWindowHours    = 24
+	awrBaselineExpirationDays = 90
+	// maxAWRBaselines bounds the baselines listed on the Instance status.
+	maxAWRBaselines = 10
 )
 
 var statefulSetImages = []string{"service", "dbinit", "logging_sidecar"}
@@ -76,6 +84,10 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 			return ctrl.Result{}, nil, true
 		}
 
+		if err := controllers.RunHooks(ctx, r, r.DatabaseClientFactory, r.Recorder, inst, v1alpha1.PreUpgradeHook); err != nil {
+			log.Error(err, "patchingStateMachine: pre-upgrade hook failed, patching postponed")
+			return ctrl.Result{RequeueAfter: hookRetryInterval}, nil, true
+		}
 		inst.Status.CurrentActiveStateMachine = "PatchingStateMachine"
 		if result, err := r.startPatchingBackup(req, ctx, inst, log); err != nil {
 			// In case of k8s conflict retry, otherwise switch to failed state
@@ -84,6 +96,7 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 			}
 			return result, err, true
 		}
+		r.captureAWRBaseline(ctx, inst, false, log)
 		log.Info("patchingStateMachine: CreateComplete->PatchingBackupStarted")
 		k8s.InstanceUpsertCondition(&inst.Status, k8s.Ready, v1.ConditionFalse, k8s.PatchingBackupStarted, "Patching Backup Started")
 		return ctrl.Result{Requeue: true}, nil, true
@@ -133,7 +146,7 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 			return ctrl.Result{}, err, true
 		}
 		if !oracleRunning {
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil, true
+			return ctrl.Result{RequeueAfter: oracleStartupRequeueAfter(inst)}, nil, true
 		}
 		// If there are no new images specified with respect to the stateful set skip this state.
 		if !isStatefulSetPatchingRequired(inst.Status.ActiveImages, inst.Spec.Images) {
@@ -199,7 +212,7 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 			return ctrl.Result{}, err, true
 		}
 		if !oracleRunning {
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil, true
+			return ctrl.Result{RequeueAfter: oracleStartupRequeueAfter(inst)}, nil, true
 		}
 		// Start patching
 		if err := r.startDatabasePatching(req, ctx, *inst, log); err != nil {
@@ -240,6 +253,7 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 		// Update current service image path
 		inst.Status.ActiveImages = cloneMap(stsParams.Images)
 		inst.Status.CurrentActiveStateMachine = ""
+		r.captureAWRBaseline(ctx, inst, true, log)
 		log.Info("patchingStateMachine: patching done", "updating CurrentServiceImage", inst.Spec.Images)
 		return ctrl.Result{}, nil, true
 
@@ -277,7 +291,7 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 			return ctrl.Result{}, err, true
 		}
 		if !oracleRunning {
-			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil, true
+			return ctrl.Result{RequeueAfter: oracleStartupRequeueAfter(inst)}, nil, true
 		}
 		inst.Status.LastFailedImages = cloneMap(inst.Spec.Images)
 		log.Info("patchingStateMachine: PatchingRecoveryInProgress->PatchingRecoveryCompleted")
@@ -291,6 +305,33 @@ func (r *InstanceReconciler) patchingStateMachine(req ctrl.Request, instanceRead
 	}
 }
 
+// captureAWRBaseline captures the AWR baseline of the last day before
+// patching, or schedules the baseline of the next day after patching, so the
+// performance can be compared. A failure, e.g. without the Diagnostics Pack,
+// doesn't stop the patching.
+func (r *InstanceReconciler) captureAWRBaseline(ctx context.Context, inst *v1alpha1.Instance, afterPatching bool, log logr.Logger) {
+	prefix := "ELCARRO_PRE_PATCH_"
+	if afterPatching {
+		prefix = "ELCARRO_POST_PATCH_"
+	}
+	name := prefix + time.Now().UTC().Format("20060102150405")
+	if err := controllers.CreateAWRBaseline(ctx, r, r.DatabaseClientFactory, inst.GetNamespace(), inst.GetName(), controllers.CreateAWRBaselineRequest{
+		BaselineName:   name,
+		WindowHours:    awrBaselineWindowHours,
+		Future:         afterPatching,
+		ExpirationDays: awrBaselineExpirationDays,
+	}); err != nil {
+		log.Error(err, "captureAWRBaseline: failed to capture an AWR baseline", "baseline", name)
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.AWRBaselineFailed, "Failed to capture AWR baseline %s: %v", name, err)
+		return
+	}
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.AWRBaselineCaptured, "Captured AWR baseline %s", name)
+	inst.Status.AWRBaselines = append(inst.Status.AWRBaselines, name)
+	if n := len(inst.Status.AWRBaselines); n > maxAWRBaselines {
+		inst.Status.AWRBaselines = inst.Status.AWRBaselines[n-maxAWRBaselines:]
+	}
+}
+
 func isStatefulSetPatchingRequired(currentImages map[string]string, newImages map[string]string) bool {
 	for _, image := range statefulSetImages {
 		if currentImages[image] != newImages[image] {
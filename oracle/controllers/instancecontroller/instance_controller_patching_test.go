@@ -0,0 +1,88 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestPatchingCapturesAWRBaselines(t *testing.T) {
+	ctx := context.Background()
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{}
+
+	// Simulate a patch operation: the baseline before patching is captured
+	// once the patching starts, the one after patching once the database
+	// patching completes.
+	r.captureAWRBaseline(ctx, inst, false, logr.Discard())
+	completePatching := func() {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.Ready, v1.ConditionFalse, k8s.DatabasePatchingComplete, "")
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseInstanceReady, v1.ConditionTrue, k8s.CreateComplete, "")
+		readyCond := k8s.FindCondition(inst.Status.Conditions, k8s.Ready)
+		dbInstanceCond := k8s.FindCondition(inst.Status.Conditions, k8s.DatabaseInstanceReady)
+		if _, err, _ := r.patchingStateMachine(ctrl.Request{}, readyCond, dbInstanceCond, inst, ctx, &controllers.StsParams{}, nil, time.Hour, logr.Discard()); err != nil {
+			t.Fatalf("patchingStateMachine failed: %v", err)
+		}
+		if cond := k8s.FindCondition(inst.Status.Conditions, k8s.Ready); cond.Reason != k8s.CreateComplete {
+			t.Errorf("patchingStateMachine got Ready reason %q, want %q", cond.Reason, k8s.CreateComplete)
+		}
+	}
+	completePatching()
+
+	reqs := factory.Dbclient.GotCreateAWRBaselineRequests
+	if len(reqs) != 2 {
+		t.Fatalf("got %d CreateAWRBaseline calls, want 2", len(reqs))
+	}
+	if pre := reqs[0]; !strings.HasPrefix(pre.GetBaselineName(), "ELCARRO_PRE_PATCH_") || pre.GetFuture() {
+		t.Errorf("got baseline %v before patching, want a ELCARRO_PRE_PATCH_ baseline of the last %d hours", pre, awrBaselineWindowHours)
+	}
+	if post := reqs[1]; !strings.HasPrefix(post.GetBaselineName(), "ELCARRO_POST_PATCH_") || !post.GetFuture() {
+		t.Errorf("got baseline %v after patching, want a ELCARRO_POST_PATCH_ baseline of the next %d hours", post, awrBaselineWindowHours)
+	}
+	want := []string{reqs[0].GetBaselineName(), reqs[1].GetBaselineName()}
+	if got := inst.Status.AWRBaselines; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got AWRBaselines %v, want %v", got, want)
+	}
+
+	// A failing baseline, e.g. without the Diagnostics Pack, doesn't fail
+	// the patching.
+	factory.Dbclient.SetMethodToError("CreateAWRBaseline", errors.New("ORA-20200: diagnostic pack not enabled"))
+	completePatching()
+	if got := factory.Dbclient.CreateAWRBaselineCalledCnt(); got != 3 {
+		t.Errorf("got %d CreateAWRBaseline calls, want 3", got)
+	}
+	if got := inst.Status.AWRBaselines; len(got) != 2 {
+		t.Errorf("got AWRBaselines %v after a failure, want %v", got, want)
+	}
+}
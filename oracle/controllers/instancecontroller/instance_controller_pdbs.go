@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// updatePDBStatus refreshes the summary of the PDBs in the instance status.
+func (r *InstanceReconciler) updatePDBStatus(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	resp, err := controllers.FetchPDBStates(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name)
+	if err != nil {
+		return fmt.Errorf("failed to fetch PDB states: %v", err)
+	}
+
+	var pdbs []v1alpha1.PDBStatus
+	for _, pdb := range resp.PDBs {
+		pdbs = append(pdbs, v1alpha1.PDBStatus{
+			Name:       pdb.Name,
+			OpenMode:   pdb.OpenMode,
+			Restricted: pdb.Restricted,
+			SizeBytes:  pdb.SizeBytes,
+		})
+	}
+	log.V(1).Info("PDB states", "pdbs", pdbs)
+	inst.Status.PDBs = pdbs
+	return nil
+}
+
+// adoptedDatabaseName returns the name of the Database resource adopting
+// pdb, PDB names may hold characters which resource names can't.
+func adoptedDatabaseName(pdb string) (string, error) {
+	name := strings.ReplaceAll(strings.ToLower(pdb), "_", "-")
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("PDB %s can't be adopted as Database %q: %s", pdb, name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// reconcileOrphanedPDBs compares the PDBs of the CDB with the Database
+// resources of the instance, reports the PDBs without a Database resource
+// and the Database resources without a PDB in the instance status, and
+// adopts or drops the orphaned PDBs as set by spec.orphanedPDBPolicy. The
+// PDBs of a standby come from its primary, so standbys are skipped.
+func (r *InstanceReconciler) reconcileOrphanedPDBs(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if isStandbyDR(inst) {
+		return nil
+	}
+	var dbs v1alpha1.DatabaseList
+	if err := r.List(ctx, &dbs, client.InNamespace(inst.Namespace)); err != nil {
+		return fmt.Errorf("failed to list Database resources: %v", err)
+	}
+	// Databases being deleted are still desired, they drop their own PDB.
+	var desired []string
+	for _, db := range dbs.Items {
+		if db.Spec.Instance == inst.Name {
+			desired = append(desired, db.Spec.Name)
+		}
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	policy := inst.Spec.OrphanedPDBPolicy
+	resp, err := dbClient.ReconcilePDBs(ctx, &dbdpb.ReconcilePDBsRequest{DesiredPdbs: desired, DropOrphaned: policy == v1alpha1.OrphanedPDBDrop})
+	dropped := make(map[string]bool)
+	for _, pdb := range resp.GetDroppedPdbs() {
+		dropped[pdb] = true
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.PDBDropped, "Dropped PDB %s which had no Database resource", pdb)
+	}
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.PDBReconcileFailed, "Failed to reconcile the PDBs with the Database resources: %v", err)
+		return err
+	}
+
+	var orphaned []string
+	for _, pdb := range resp.GetOrphanedPdbs() {
+		if dropped[pdb] {
+			continue
+		}
+		if policy == v1alpha1.OrphanedPDBAdopt {
+			if err := r.adoptPDB(ctx, inst, pdb); err != nil {
+				r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.PDBReconcileFailed, "Failed to adopt PDB %s: %v", pdb, err)
+			} else {
+				r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.PDBAdopted, "Created a Database resource for PDB %s", pdb)
+				continue
+			}
+		}
+		orphaned = append(orphaned, pdb)
+	}
+	if len(orphaned) > 0 && strings.Join(orphaned, ",") != strings.Join(inst.Status.OrphanedPDBs, ",") {
+		log.Info("orphaned PDBs found", "pdbs", orphaned)
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.OrphanedPDBsFound, "PDBs without a Database resource: %s", strings.Join(orphaned, ", "))
+	}
+	inst.Status.OrphanedPDBs = orphaned
+	inst.Status.MissingPDBs = resp.GetMissingPdbs()
+	return nil
+}
+
+// adoptPDB creates a Database resource for an existing PDB, its creation
+// finds the PDB and leaves it as is.
+func (r *InstanceReconciler) adoptPDB(ctx context.Context, inst *v1alpha1.Instance, pdb string) error {
+	name, err := adoptedDatabaseName(pdb)
+	if err != nil {
+		return err
+	}
+	db := &v1alpha1.Database{
+		ObjectMeta: metav1.ObjectMeta{Namespace: inst.Namespace, Name: name},
+		Spec: v1alpha1.DatabaseSpec{
+			DatabaseSpec: commonv1alpha1.DatabaseSpec{Instance: inst.Name, Name: pdb},
+		},
+	}
+	return r.Create(ctx, db)
+}
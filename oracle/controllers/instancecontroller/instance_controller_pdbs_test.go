@@ -0,0 +1,243 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestUpdatePDBStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		want    []v1alpha1.PDBStatus
+		wantErr bool
+	}{
+		{
+			name: "no PDBs",
+		},
+		{
+			name: "open and mounted PDBs",
+			rows: []string{
+				`{"NAME":"MYDB","OPEN_MODE":"READ WRITE","RESTRICTED":"NO","TOTAL_SIZE":"1073741824"}`,
+				`{"NAME":"OTHERDB","OPEN_MODE":"MOUNTED","RESTRICTED":"","TOTAL_SIZE":"0"}`,
+				`{"NAME":"UPGRADEDB","OPEN_MODE":"READ WRITE","RESTRICTED":"YES","TOTAL_SIZE":"2147483648"}`,
+			},
+			want: []v1alpha1.PDBStatus{
+				{Name: "MYDB", OpenMode: "READ WRITE", SizeBytes: gib},
+				{Name: "OTHERDB", OpenMode: "MOUNTED"},
+				{Name: "UPGRADEDB", OpenMode: "READ WRITE", Restricted: true, SizeBytes: 2 * gib},
+			},
+		},
+		{
+			name:    "invalid size",
+			rows:    []string{`{"NAME":"MYDB","OPEN_MODE":"READ WRITE","RESTRICTED":"NO","TOTAL_SIZE":"big"}`},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("RunSQLPlusFormatted", &dbdpb.RunCMDResponse{Msg: tc.rows})
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Status: v1alpha1.InstanceStatus{PDBs: []v1alpha1.PDBStatus{{Name: "DROPPED"}}}}
+
+			err := r.updatePDBStatus(context.Background(), inst, logr.Discard())
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("updatePDBStatus succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("updatePDBStatus failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, inst.Status.PDBs); diff != "" {
+				t.Errorf("updatePDBStatus got unexpected status (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestAdoptedDatabaseName(t *testing.T) {
+	if got, err := adoptedDatabaseName("SALES_EU"); err != nil || got != "sales-eu" {
+		t.Errorf("adoptedDatabaseName(SALES_EU) = %q, %v, want sales-eu", got, err)
+	}
+	if _, err := adoptedDatabaseName("PDB$1"); err == nil {
+		t.Errorf("adoptedDatabaseName(PDB$1) succeeded, want error")
+	}
+}
+
+func TestReconcileOrphanedPDBs(t *testing.T) {
+	database := func(name, instance, pdb string) *v1alpha1.Database {
+		return &v1alpha1.Database{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name},
+			Spec:       v1alpha1.DatabaseSpec{DatabaseSpec: commonv1alpha1.DatabaseSpec{Instance: instance, Name: pdb}},
+		}
+	}
+	tests := []struct {
+		name         string
+		policy       v1alpha1.OrphanedPDBPolicy
+		standby      bool
+		resp         *dbdpb.ReconcilePDBsResponse
+		err          error
+		wantErr      bool
+		wantCalls    int
+		wantDrop     bool
+		wantOrphaned []string
+		wantMissing  []string
+		wantAdopted  []string
+		wantEvents   int
+	}{
+		{
+			name:      "in sync",
+			resp:      &dbdpb.ReconcilePDBsResponse{},
+			wantCalls: 1,
+		},
+		{
+			name:         "report",
+			resp:         &dbdpb.ReconcilePDBsResponse{OrphanedPdbs: []string{"LEGACY"}, MissingPdbs: []string{"PDB2"}},
+			wantCalls:    1,
+			wantOrphaned: []string{"LEGACY"},
+			wantMissing:  []string{"PDB2"},
+			wantEvents:   1,
+		},
+		{
+			name:        "adopt",
+			policy:      v1alpha1.OrphanedPDBAdopt,
+			resp:        &dbdpb.ReconcilePDBsResponse{OrphanedPdbs: []string{"LEGACY_EU"}},
+			wantCalls:   1,
+			wantAdopted: []string{"legacy-eu"},
+			wantEvents:  1,
+		},
+		{
+			name:         "adopt invalid name",
+			policy:       v1alpha1.OrphanedPDBAdopt,
+			resp:         &dbdpb.ReconcilePDBsResponse{OrphanedPdbs: []string{"PDB$1"}},
+			wantCalls:    1,
+			wantOrphaned: []string{"PDB$1"},
+			wantEvents:   2,
+		},
+		{
+			name:       "drop",
+			policy:     v1alpha1.OrphanedPDBDrop,
+			resp:       &dbdpb.ReconcilePDBsResponse{OrphanedPdbs: []string{"LEGACY"}, DroppedPdbs: []string{"LEGACY"}},
+			wantCalls:  1,
+			wantDrop:   true,
+			wantEvents: 1,
+		},
+		{
+			name:       "failed",
+			policy:     v1alpha1.OrphanedPDBDrop,
+			err:        errors.New("ORA-65025: Pluggable database LEGACY is not closed on all instances"),
+			wantErr:    true,
+			wantCalls:  1,
+			wantDrop:   true,
+			wantEvents: 1,
+		},
+		{
+			name:    "standby",
+			standby: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				database("pdb1", "mydb", "pdb1"),
+				database("pdb2", "mydb", "pdb2"),
+				database("other", "otherdb", "other"),
+			).Build()
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("ReconcilePDBs", tc.resp)
+			}
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("ReconcilePDBs", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Client:                k8sClient,
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb"},
+				Spec:       v1alpha1.InstanceSpec{OrphanedPDBPolicy: tc.policy},
+			}
+			if tc.standby {
+				inst.Spec.ReplicationSettings = &v1alpha1.ReplicationSettings{}
+			}
+
+			err := r.reconcileOrphanedPDBs(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileOrphanedPDBs got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.ReconcilePDBsCalledCnt(); got != tc.wantCalls {
+				t.Fatalf("reconcileOrphanedPDBs called ReconcilePDBs %d times, want %d", got, tc.wantCalls)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileOrphanedPDBs emitted %d events, want %d", got, tc.wantEvents)
+			}
+			if tc.wantCalls == 0 {
+				return
+			}
+			req := factory.Dbclient.GotReconcilePDBsRequest
+			if diff := cmp.Diff([]string{"pdb1", "pdb2"}, req.GetDesiredPdbs()); diff != "" {
+				t.Errorf("reconcileOrphanedPDBs got unexpected desired PDBs (-want +got):\n%v", diff)
+			}
+			if req.GetDropOrphaned() != tc.wantDrop {
+				t.Errorf("reconcileOrphanedPDBs got drop orphaned %v, want %v", req.GetDropOrphaned(), tc.wantDrop)
+			}
+			if diff := cmp.Diff(tc.wantOrphaned, inst.Status.OrphanedPDBs); diff != "" {
+				t.Errorf("reconcileOrphanedPDBs got unexpected orphaned PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantMissing, inst.Status.MissingPDBs); diff != "" {
+				t.Errorf("reconcileOrphanedPDBs got unexpected missing PDBs (-want +got):\n%v", diff)
+			}
+			for _, name := range tc.wantAdopted {
+				var db v1alpha1.Database
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "db", Name: name}, &db); err != nil {
+					t.Errorf("reconcileOrphanedPDBs didn't create Database %s: %v", name, err)
+				} else if db.Spec.Instance != "mydb" {
+					t.Errorf("reconcileOrphanedPDBs created Database %s for instance %q, want mydb", name, db.Spec.Instance)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/backup"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	// fraUsageWarningPercent matches the default Oracle warning
+	// threshold for the recovery area space usage alert.
+	fraUsageWarningPercent = 85
+
+	deleteObsoleteRMANScript = "delete noprompt obsolete;"
+
+	archivelogBackup = "ArchivelogBackup"
+	// archivelogBackupPollInterval is the delay between checks of a running
+	// archived log backup, or of the LROs it waits for.
+	archivelogBackupPollInterval = time.Minute
+	// archivelogBackupMinInterval throttles threshold triggered backups
+	// while logs within the keep window hold the usage above the threshold.
+	archivelogBackupMinInterval = 15 * time.Minute
+)
+
+// fraUsedPercent returns the FRA space used as a percentage of its limit.
+func fraUsedPercent(usage *dbdpb.GetFRAUsageResponse) float64 {
+	if usage.GetSpaceLimitBytes() <= 0 {
+		return 0
+	}
+	return float64(usage.GetSpaceUsedBytes()) * 100 / float64(usage.GetSpaceLimitBytes())
+}
+
+// fraPressurePercent returns the FRA space used and not reclaimable as a
+// percentage of its limit. Oracle frees reclaimable space on demand, the
+// archiver hangs (ORA-19815) only once this reaches 100%.
+func fraPressurePercent(usage *dbdpb.GetFRAUsageResponse) float64 {
+	if usage.GetSpaceLimitBytes() <= 0 {
+		return 0
+	}
+	return float64(usage.GetSpaceUsedBytes()-usage.GetSpaceReclaimableBytes()) * 100 / float64(usage.GetSpaceLimitBytes())
+}
+
+// deleteObsoleteNeeded reports whether FRA usage crossed the configured
+// threshold. Obsolete backups in the FRA are reported as reclaimable,
+// there is nothing to delete if no space is reclaimable.
+func deleteObsoleteNeeded(spec *v1alpha1.RecoveryAreaSpec, usage *dbdpb.GetFRAUsageResponse) bool {
+	if spec == nil || spec.DeleteObsoleteThreshold <= 0 || usage.GetSpaceReclaimableBytes() <= 0 {
+		return false
+	}
+	return fraUsedPercent(usage) >= float64(spec.DeleteObsoleteThreshold)
+}
+
+// restorePointsToDrop returns the oldest guaranteed restore points to drop
+// to bring the FRA usage not counting reclaimable space below the threshold.
+// Points are ordered oldest first, those without flashback logs are kept
+// since dropping them frees no space.
+func restorePointsToDrop(threshold int32, usage *dbdpb.GetFRAUsageResponse, points []*dbdpb.RestorePoint) []*dbdpb.RestorePoint {
+	if threshold <= 0 || usage.GetSpaceLimitBytes() <= 0 {
+		return nil
+	}
+	pressure := usage.GetSpaceUsedBytes() - usage.GetSpaceReclaimableBytes()
+	limit := usage.GetSpaceLimitBytes() * int64(threshold) / 100
+	var drop []*dbdpb.RestorePoint
+	for _, p := range points {
+		if pressure < limit {
+			break
+		}
+		if !p.GetGuaranteed() || p.GetStorageSizeBytes() <= 0 {
+			continue
+		}
+		drop = append(drop, p)
+		pressure -= p.GetStorageSizeBytes()
+	}
+	return drop
+}
+
+// restorePointsUsedPercent returns the FRA space used by the flashback logs
+// of guaranteed restore points as a percentage of its limit.
+func restorePointsUsedPercent(usage *dbdpb.GetFRAUsageResponse, points []*dbdpb.RestorePoint) float64 {
+	if usage.GetSpaceLimitBytes() <= 0 {
+		return 0
+	}
+	var used int64
+	for _, p := range points {
+		used += p.GetStorageSizeBytes()
+	}
+	return float64(used) * 100 / float64(usage.GetSpaceLimitBytes())
+}
+
+// archivelogBackupDue reports whether archived logs should be backed up and
+// deleted, either because the FRA usage crossed the threshold or because the
+// schedule fired since the last run. Threshold triggered backups run at most
+// once per archivelogBackupMinInterval. It also returns the next scheduled
+// run, zero if no schedule is set.
+func archivelogBackupDue(spec *v1alpha1.ArchivelogBackupSpec, usage *dbdpb.GetFRAUsageResponse, lastRun, now time.Time) (bool, time.Time, error) {
+	var next time.Time
+	if spec.Schedule != "" {
+		schedule, err := cron.ParseStandard(spec.Schedule)
+		if err != nil {
+			return false, next, fmt.Errorf("failed to parse archivelog backup schedule %q: %v", spec.Schedule, err)
+		}
+		if next = schedule.Next(lastRun); !next.After(now) {
+			return true, schedule.Next(now), nil
+		}
+	}
+	if spec.Threshold > 0 && fraUsedPercent(usage) >= float64(spec.Threshold) && now.Sub(lastRun) >= archivelogBackupMinInterval {
+		return true, next, nil
+	}
+	return false, next, nil
+}
+
+// reconcileRecoveryArea reports the FRA usage as the RecoveryAreaHealthy
+// condition, deletes obsolete backups once spec.recoveryArea
+// deleteObsoleteThreshold is crossed, runs archived log backups
+// configured in spec.recoveryArea.archivelogBackup and drops the oldest
+// guaranteed restore points once dropRestorePointsThreshold is crossed.
+// The result requeues the instance for the next scheduled archived log
+// backup.
+func (r *InstanceReconciler) reconcileRecoveryArea(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	usage, err := dbClient.GetFRAUsage(ctx, &dbdpb.GetFRAUsageRequest{})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionUnknown, k8s.RecoveryAreaUsageUnknown, fmt.Sprintf("failed to get recovery area usage: %v", err))
+		return ctrl.Result{}, err
+	}
+	if usage.GetSpaceLimitBytes() == 0 {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionUnknown, k8s.RecoveryAreaUsageUnknown, "recovery area is not configured")
+		return ctrl.Result{}, nil
+	}
+
+	if deleteObsoleteNeeded(inst.Spec.RecoveryArea, usage) {
+		log.Info("recovery area usage crossed the threshold, deleting obsolete backups", "usedPercent", fraUsedPercent(usage), "threshold", inst.Spec.RecoveryArea.DeleteObsoleteThreshold)
+		if _, err := dbClient.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{deleteObsoleteRMANScript}}); err != nil {
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ObsoleteBackupsFailed, "Failed to delete obsolete backups: %v", err)
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.ObsoleteBackupsDeleted, "Recovery area was %.0f%% used, deleted obsolete backups", fraUsedPercent(usage))
+		if usage, err = dbClient.GetFRAUsage(ctx, &dbdpb.GetFRAUsageRequest{}); err != nil {
+			k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionUnknown, k8s.RecoveryAreaUsageUnknown, fmt.Sprintf("failed to get recovery area usage: %v", err))
+			return ctrl.Result{}, err
+		}
+	}
+
+	var result ctrl.Result
+	if spec := inst.Spec.RecoveryArea; spec != nil && spec.ArchivelogBackup != nil {
+		if result, usage, err = r.reconcileArchivelogBackup(ctx, dbClient, inst, spec.ArchivelogBackup, usage, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	points, err := dbClient.ListRestorePoints(ctx, &dbdpb.ListRestorePointsRequest{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list restore points: %v", err)
+	}
+	if spec := inst.Spec.RecoveryArea; spec != nil {
+		if drop := restorePointsToDrop(spec.DropRestorePointsThreshold, usage, points.GetRestorePoints()); len(drop) > 0 {
+			if usage, points, err = r.dropRestorePoints(ctx, dbClient, inst, drop, usage, log); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	pressure := fraPressurePercent(usage)
+	msg := fmt.Sprintf("Recovery area is %.0f%% used, %.0f%% is not reclaimable", fraUsedPercent(usage), pressure)
+	if rp := restorePointsUsedPercent(usage, points.GetRestorePoints()); rp > 0 {
+		msg += fmt.Sprintf(", %.0f%% is used by guaranteed restore points", rp)
+	}
+	if pressure >= fraUsageWarningPercent {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionFalse, k8s.RecoveryAreaUsageHigh, msg)
+		return result, nil
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionTrue, k8s.RecoveryAreaUsageNormal, msg)
+	return result, nil
+}
+
+// dropRestorePoints drops the given guaranteed restore points, oldest
+// first. It returns the FRA usage and the restore points, refreshed after
+// the drop.
+func (r *InstanceReconciler) dropRestorePoints(ctx context.Context, dbClient dbdpb.DatabaseDaemonClient, inst *v1alpha1.Instance, drop []*dbdpb.RestorePoint, usage *dbdpb.GetFRAUsageResponse, log logr.Logger) (*dbdpb.GetFRAUsageResponse, *dbdpb.ListRestorePointsResponse, error) {
+	var names []string
+	for _, p := range drop {
+		log.Info("recovery area usage crossed the threshold, dropping guaranteed restore point", "name", p.GetName(), "scn", p.GetScn(), "storageSizeBytes", p.GetStorageSizeBytes())
+		if _, err := dbClient.DropRestorePoint(ctx, &dbdpb.DropRestorePointRequest{Name: p.GetName()}); err != nil {
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.RestorePointDropFailed, "Failed to drop guaranteed restore point %s: %v", p.GetName(), err)
+			return usage, nil, err
+		}
+		names = append(names, p.GetName())
+	}
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.RestorePointsDropped, "Recovery area was %.0f%% used not counting reclaimable space, dropped guaranteed restore points %s", fraPressurePercent(usage), strings.Join(names, ", "))
+
+	usage, err := dbClient.GetFRAUsage(ctx, &dbdpb.GetFRAUsageRequest{})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionUnknown, k8s.RecoveryAreaUsageUnknown, fmt.Sprintf("failed to get recovery area usage: %v", err))
+		return usage, nil, err
+	}
+	points, err := dbClient.ListRestorePoints(ctx, &dbdpb.ListRestorePointsRequest{})
+	if err != nil {
+		return usage, nil, fmt.Errorf("failed to list restore points: %v", err)
+	}
+	return usage, points, nil
+}
+
+// lroArchivelogBackupOperationID returns the ID of the archived log backup
+// LRO started at the last archived log backup time.
+func lroArchivelogBackupOperationID(inst *v1alpha1.Instance) string {
+	return fmt.Sprintf("%s_%s_%s", archivelogBackup, inst.GetUID(), inst.Status.LastArchivelogBackupTime.UTC().Format(time.RFC3339))
+}
+
+// lroInProgress reports whether the dbdaemon is running an LRO, e.g. a
+// backup or a restore using the RMAN staging directory.
+func lroInProgress(ctx context.Context, dbClient dbdpb.DatabaseDaemonClient) (bool, error) {
+	req := &lropb.ListOperationsRequest{}
+	for {
+		resp, err := dbClient.ListOperations(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		for _, op := range resp.GetOperations() {
+			if !op.GetDone() {
+				return true, nil
+			}
+		}
+		if resp.GetNextPageToken() == "" {
+			return false, nil
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+// reconcileArchivelogBackup runs the archived log backups configured in
+// spec.recoveryArea.archivelogBackup as LROs. The LRO ID is derived from the
+// last run time recorded in the instance status, so a running backup is
+// polled instead of being started again. Backups are postponed while another
+// LRO is running. Backed up archived logs are deleted once the LRO is done.
+// It returns the FRA usage, refreshed if logs were deleted.
+func (r *InstanceReconciler) reconcileArchivelogBackup(ctx context.Context, dbClient dbdpb.DatabaseDaemonClient, inst *v1alpha1.Instance, spec *v1alpha1.ArchivelogBackupSpec, usage *dbdpb.GetFRAUsageResponse, log logr.Logger) (ctrl.Result, *dbdpb.GetFRAUsageResponse, error) {
+	params := &backup.ArchivelogParams{
+		Client:  dbClient,
+		GCSPath: spec.GcsPath,
+	}
+	if spec.KeepWindow != nil {
+		params.KeepWindow = spec.KeepWindow.Duration
+	}
+
+	if inst.Status.LastArchivelogBackupTime != nil {
+		id := lroArchivelogBackupOperationID(inst)
+		op, err := dbClient.GetOperation(ctx, &lropb.GetOperationRequest{Name: id})
+		switch {
+		case controllers.IsNotFoundError(err):
+			// The last backup was handled already, or the dbdaemon restarted.
+		case err != nil:
+			return ctrl.Result{}, usage, fmt.Errorf("failed to get the archived log backup operation: %v", err)
+		case !op.GetDone():
+			log.Info("archived log backup in progress", "operationID", id)
+			return ctrl.Result{RequeueAfter: archivelogBackupPollInterval}, usage, nil
+		case op.GetError() != nil:
+			r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ArchivelogBackupFailed, "Failed to back up archived logs: %s", op.GetError().GetMessage())
+			if _, err := dbClient.DeleteOperation(ctx, &lropb.DeleteOperationRequest{Name: id}); err != nil {
+				log.Error(err, "failed to delete the archived log backup operation", "operationID", id)
+			}
+		default:
+			if err := backup.DeleteArchivelogs(ctx, params); err != nil {
+				r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ArchivelogBackupFailed, "Failed to delete backed up archived logs: %v", err)
+				return ctrl.Result{}, usage, err
+			}
+			if _, err := dbClient.DeleteOperation(ctx, &lropb.DeleteOperationRequest{Name: id}); err != nil {
+				log.Error(err, "failed to delete the archived log backup operation", "operationID", id)
+			}
+			r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.ArchivelogBackupComplete, "Archived logs backed up to %s and deleted from the recovery area", spec.GcsPath)
+			if usage, err = dbClient.GetFRAUsage(ctx, &dbdpb.GetFRAUsageRequest{}); err != nil {
+				k8s.InstanceUpsertCondition(&inst.Status, k8s.RecoveryAreaHealthy, v1.ConditionUnknown, k8s.RecoveryAreaUsageUnknown, fmt.Sprintf("failed to get recovery area usage: %v", err))
+				return ctrl.Result{}, usage, err
+			}
+		}
+	}
+
+	lastRun := inst.CreationTimestamp.Time
+	if inst.Status.LastArchivelogBackupTime != nil {
+		lastRun = inst.Status.LastArchivelogBackupTime.Time
+	}
+	now := time.Now()
+	due, next, err := archivelogBackupDue(spec, usage, lastRun, now)
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ArchivelogBackupFailed, "Invalid archivelog backup schedule: %v", err)
+		return ctrl.Result{}, usage, err
+	}
+	var result ctrl.Result
+	if !next.IsZero() {
+		result.RequeueAfter = next.Sub(now)
+	}
+	if !due {
+		return result, usage, nil
+	}
+
+	busy, err := lroInProgress(ctx, dbClient)
+	if err != nil {
+		return ctrl.Result{}, usage, fmt.Errorf("failed to list operations: %v", err)
+	}
+	if busy {
+		log.Info("another operation is in progress, postponing the archived log backup")
+		return mergeResults(result, ctrl.Result{RequeueAfter: archivelogBackupPollInterval}), usage, nil
+	}
+
+	log.Info("starting an archived log backup", "usedPercent", fraUsedPercent(usage), "lastRun", lastRun)
+	prevRun := inst.Status.LastArchivelogBackupTime
+	inst.Status.LastArchivelogBackupTime = &v1.Time{Time: now}
+	params.BackupTag = fmt.Sprintf("ARCHLOG_%s", now.UTC().Format("20060102T150405"))
+	params.OperationID = lroArchivelogBackupOperationID(inst)
+	if _, err := backup.StartArchivelogBackup(ctx, params); err != nil {
+		inst.Status.LastArchivelogBackupTime = prevRun
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.ArchivelogBackupFailed, "Failed to back up archived logs: %v", err)
+		return ctrl.Result{}, usage, err
+	}
+	return mergeResults(result, ctrl.Result{RequeueAfter: archivelogBackupPollInterval}), usage, nil
+}
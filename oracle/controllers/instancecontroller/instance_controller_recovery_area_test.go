@@ -0,0 +1,397 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func TestDeleteObsoleteNeeded(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  *v1alpha1.RecoveryAreaSpec
+		usage *dbdpb.GetFRAUsageResponse
+		want  bool
+	}{
+		{
+			name:  "not configured",
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 10 * gib, SpaceReclaimableBytes: gib},
+		},
+		{
+			name:  "threshold not set",
+			spec:  &v1alpha1.RecoveryAreaSpec{},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 10 * gib, SpaceReclaimableBytes: gib},
+		},
+		{
+			name:  "below threshold",
+			spec:  &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 7 * gib, SpaceReclaimableBytes: gib},
+		},
+		{
+			name:  "at threshold",
+			spec:  &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 8 * gib, SpaceReclaimableBytes: gib},
+			want:  true,
+		},
+		{
+			name:  "nothing reclaimable",
+			spec:  &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+		},
+		{
+			name:  "no recovery area",
+			spec:  &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceUsedBytes: 9 * gib, SpaceReclaimableBytes: gib},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := deleteObsoleteNeeded(tc.spec, tc.usage); got != tc.want {
+				t.Errorf("deleteObsoleteNeeded(%v, %v) = %v, want %v", tc.spec, tc.usage, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRestorePointsToDrop(t *testing.T) {
+	points := []*dbdpb.RestorePoint{
+		{Name: "RP1", Guaranteed: true, StorageSizeBytes: 2 * gib},
+		{Name: "RP2", StorageSizeBytes: 0},
+		{Name: "RP3", Guaranteed: true},
+		{Name: "RP4", Guaranteed: true, StorageSizeBytes: gib},
+		{Name: "RP5", Guaranteed: true, StorageSizeBytes: gib},
+	}
+	tests := []struct {
+		name      string
+		threshold int32
+		usage     *dbdpb.GetFRAUsageResponse
+		want      []string
+	}{
+		{
+			name:  "threshold not set",
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 10 * gib},
+		},
+		{
+			name:      "below threshold",
+			threshold: 80,
+			usage:     &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 7 * gib},
+		},
+		{
+			name:      "reclaimable space not counted",
+			threshold: 80,
+			usage:     &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib, SpaceReclaimableBytes: 2 * gib},
+		},
+		{
+			name:      "oldest dropped first",
+			threshold: 80,
+			usage:     &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			want:      []string{"RP1"},
+		},
+		{
+			name:      "points without flashback logs kept",
+			threshold: 60,
+			usage:     &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			want:      []string{"RP1", "RP4", "RP5"},
+		},
+		{
+			name:      "no recovery area",
+			threshold: 80,
+			usage:     &dbdpb.GetFRAUsageResponse{SpaceUsedBytes: 9 * gib},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, p := range restorePointsToDrop(tc.threshold, tc.usage, points) {
+				got = append(got, p.GetName())
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("restorePointsToDrop got unexpected result (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileRecoveryArea(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            *v1alpha1.RecoveryAreaSpec
+		usage           *dbdpb.GetFRAUsageResponse
+		restorePoints   []*dbdpb.RestorePoint
+		wantRMANCnt     int
+		wantDropped     []string
+		wantCondStatus  metav1.ConditionStatus
+		wantCondReason  string
+		wantGetUsageCnt int
+	}{
+		{
+			name:            "normal usage",
+			spec:            &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage:           &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 5 * gib, SpaceReclaimableBytes: gib},
+			wantCondStatus:  metav1.ConditionTrue,
+			wantCondReason:  k8s.RecoveryAreaUsageNormal,
+			wantGetUsageCnt: 1,
+		},
+		{
+			name:            "high usage without threshold",
+			usage:           &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			wantCondStatus:  metav1.ConditionFalse,
+			wantCondReason:  k8s.RecoveryAreaUsageHigh,
+			wantGetUsageCnt: 1,
+		},
+		{
+			name:            "threshold crossed",
+			spec:            &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage:           &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib, SpaceReclaimableBytes: 2 * gib},
+			wantRMANCnt:     1,
+			wantCondStatus:  metav1.ConditionTrue,
+			wantCondReason:  k8s.RecoveryAreaUsageNormal,
+			wantGetUsageCnt: 2,
+		},
+		{
+			name: "archivelog backup threshold crossed",
+			spec: &v1alpha1.RecoveryAreaSpec{
+				ArchivelogBackup: &v1alpha1.ArchivelogBackupSpec{GcsPath: "gs://bucket/archivelogs", Threshold: 80},
+			},
+			usage:           &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			wantCondStatus:  metav1.ConditionFalse,
+			wantCondReason:  k8s.RecoveryAreaUsageHigh,
+			wantGetUsageCnt: 1,
+		},
+		{
+			name:  "restore points threshold crossed",
+			spec:  &v1alpha1.RecoveryAreaSpec{DropRestorePointsThreshold: 80},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			restorePoints: []*dbdpb.RestorePoint{
+				{Name: "BEFORE_UPGRADE", Guaranteed: true, StorageSizeBytes: 2 * gib},
+				{Name: "NIGHTLY"},
+				{Name: "BEFORE_RELEASE", Guaranteed: true, StorageSizeBytes: gib},
+			},
+			wantDropped:     []string{"BEFORE_UPGRADE"},
+			wantCondStatus:  metav1.ConditionFalse,
+			wantCondReason:  k8s.RecoveryAreaUsageHigh,
+			wantGetUsageCnt: 2,
+		},
+		{
+			name:  "restore points below threshold",
+			spec:  &v1alpha1.RecoveryAreaSpec{DropRestorePointsThreshold: 95},
+			usage: &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib},
+			restorePoints: []*dbdpb.RestorePoint{
+				{Name: "BEFORE_UPGRADE", Guaranteed: true, StorageSizeBytes: 2 * gib},
+			},
+			wantCondStatus:  metav1.ConditionFalse,
+			wantCondReason:  k8s.RecoveryAreaUsageHigh,
+			wantGetUsageCnt: 1,
+		},
+		{
+			name:            "no recovery area",
+			spec:            &v1alpha1.RecoveryAreaSpec{DeleteObsoleteThreshold: 80},
+			usage:           &dbdpb.GetFRAUsageResponse{},
+			wantCondStatus:  metav1.ConditionUnknown,
+			wantCondReason:  k8s.RecoveryAreaUsageUnknown,
+			wantGetUsageCnt: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("GetFRAUsage", tc.usage)
+			factory.Dbclient.SetMethodToResp("ListRestorePoints", &dbdpb.ListRestorePointsResponse{RestorePoints: tc.restorePoints})
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{RecoveryArea: tc.spec}}
+
+			if _, err := r.reconcileRecoveryArea(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileRecoveryArea failed: %v", err)
+			}
+			var dropped []string
+			for _, req := range factory.Dbclient.GotDropRestorePointRequests {
+				dropped = append(dropped, req.GetName())
+			}
+			if diff := cmp.Diff(tc.wantDropped, dropped); diff != "" {
+				t.Errorf("reconcileRecoveryArea dropped unexpected restore points (-want +got):\n%v", diff)
+			}
+			if got := factory.Dbclient.RunRMANCalledCnt(); got != tc.wantRMANCnt {
+				t.Errorf("reconcileRecoveryArea called RunRMAN %d times, want %d", got, tc.wantRMANCnt)
+			}
+			if got := factory.Dbclient.GetFRAUsageCalledCnt(); got != tc.wantGetUsageCnt {
+				t.Errorf("reconcileRecoveryArea called GetFRAUsage %d times, want %d", got, tc.wantGetUsageCnt)
+			}
+			if tc.spec != nil && tc.spec.ArchivelogBackup != nil && inst.Status.LastArchivelogBackupTime == nil {
+				t.Errorf("reconcileRecoveryArea did not set the last archivelog backup time")
+			}
+			cond := k8s.FindCondition(inst.Status.Conditions, k8s.RecoveryAreaHealthy)
+			if cond == nil || cond.Status != tc.wantCondStatus || cond.Reason != tc.wantCondReason {
+				t.Errorf("reconcileRecoveryArea set condition %+v, want status %v reason %v", cond, tc.wantCondStatus, tc.wantCondReason)
+			}
+		})
+	}
+}
+
+func TestReconcileArchivelogBackup(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("GetFRAUsage", &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 9 * gib})
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{RecoveryArea: &v1alpha1.RecoveryAreaSpec{
+		ArchivelogBackup: &v1alpha1.ArchivelogBackupSpec{GcsPath: "gs://bucket/archivelogs", Threshold: 80},
+	}}}
+
+	steps := []struct {
+		name          string
+		ops           []*lropb.Operation
+		opStatus      testhelpers.FakeOperationStatus
+		wantStarted   int
+		wantDeleteCnt int
+		wantRequeue   bool
+	}{
+		{
+			name:        "postponed while a backup is running",
+			ops:         []*lropb.Operation{{Name: "Backup_1"}},
+			opStatus:    testhelpers.StatusNotFound,
+			wantRequeue: true,
+		},
+		{
+			name:        "started",
+			opStatus:    testhelpers.StatusNotFound,
+			wantStarted: 1,
+			wantRequeue: true,
+		},
+		{
+			name:        "running backup is not restarted",
+			opStatus:    testhelpers.StatusRunning,
+			wantStarted: 1,
+			wantRequeue: true,
+		},
+		{
+			name:          "archived logs deleted once done",
+			opStatus:      testhelpers.StatusDone,
+			wantStarted:   1,
+			wantDeleteCnt: 1,
+		},
+	}
+	for _, step := range steps {
+		factory.Dbclient.SetMethodToResp("ListOperations", &lropb.ListOperationsResponse{Operations: step.ops})
+		factory.Dbclient.SetNextGetOperationStatus(step.opStatus)
+		result, err := r.reconcileRecoveryArea(context.Background(), inst, logr.Discard())
+		if err != nil {
+			t.Fatalf("%s: reconcileRecoveryArea failed: %v", step.name, err)
+		}
+		if got := factory.Dbclient.RunRMANAsyncCalledCnt(); got != step.wantStarted {
+			t.Errorf("%s: reconcileRecoveryArea started %d archived log backups, want %d", step.name, got, step.wantStarted)
+		}
+		if got := factory.Dbclient.DeleteOperationCalledCnt(); got != step.wantDeleteCnt {
+			t.Errorf("%s: reconcileRecoveryArea deleted %d operations, want %d", step.name, got, step.wantDeleteCnt)
+		}
+		if got := result.RequeueAfter == archivelogBackupPollInterval; got != step.wantRequeue {
+			t.Errorf("%s: reconcileRecoveryArea requeued after %v, want poll interval %v", step.name, result.RequeueAfter, step.wantRequeue)
+		}
+	}
+	if got := factory.Dbclient.GotRMANAsyncRequest.GetSyncRequest().GetLocalPath(); !strings.HasPrefix(got, consts.RMANStagingDir+"/archlog_") {
+		t.Errorf("archived log backup staged in %q, want a per-run subdirectory of %q", got, consts.RMANStagingDir)
+	}
+}
+
+func TestArchivelogBackupDue(t *testing.T) {
+	lastRun := time.Date(2022, 5, 1, 1, 0, 0, 0, time.UTC)
+	usage := &dbdpb.GetFRAUsageResponse{SpaceLimitBytes: 10 * gib, SpaceUsedBytes: 7 * gib}
+	tests := []struct {
+		name     string
+		spec     *v1alpha1.ArchivelogBackupSpec
+		now      time.Time
+		wantDue  bool
+		wantNext time.Time
+	}{
+		{
+			name: "nothing configured",
+			spec: &v1alpha1.ArchivelogBackupSpec{},
+			now:  lastRun.Add(time.Hour),
+		},
+		{
+			name: "below threshold",
+			spec: &v1alpha1.ArchivelogBackupSpec{Threshold: 80},
+			now:  lastRun.Add(time.Hour),
+		},
+		{
+			name:    "threshold crossed",
+			spec:    &v1alpha1.ArchivelogBackupSpec{Threshold: 70},
+			now:     lastRun.Add(time.Hour),
+			wantDue: true,
+		},
+		{
+			name: "threshold crossed right after the last run",
+			spec: &v1alpha1.ArchivelogBackupSpec{Threshold: 70},
+			now:  lastRun.Add(time.Minute),
+		},
+		{
+			name:     "schedule not fired",
+			spec:     &v1alpha1.ArchivelogBackupSpec{Schedule: "0 */4 * * *"},
+			now:      lastRun.Add(time.Hour),
+			wantNext: time.Date(2022, 5, 1, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "schedule fired",
+			spec:     &v1alpha1.ArchivelogBackupSpec{Schedule: "0 */4 * * *"},
+			now:      lastRun.Add(4 * time.Hour),
+			wantDue:  true,
+			wantNext: time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "threshold crossed before schedule",
+			spec:     &v1alpha1.ArchivelogBackupSpec{Schedule: "0 */4 * * *", Threshold: 70},
+			now:      lastRun.Add(time.Hour),
+			wantDue:  true,
+			wantNext: time.Date(2022, 5, 1, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			due, next, err := archivelogBackupDue(tc.spec, usage, lastRun, tc.now)
+			if err != nil {
+				t.Fatalf("archivelogBackupDue failed: %v", err)
+			}
+			if due != tc.wantDue || !next.Equal(tc.wantNext) {
+				t.Errorf("archivelogBackupDue(%+v) = %v, %v, want %v, %v", tc.spec, due, next, tc.wantDue, tc.wantNext)
+			}
+		})
+	}
+
+	if _, _, err := archivelogBackupDue(&v1alpha1.ArchivelogBackupSpec{Schedule: "every hour"}, usage, lastRun, lastRun); err == nil {
+		t.Error("archivelogBackupDue with an invalid schedule succeeded, want error")
+	}
+}
@@ -18,12 +18,10 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
 	lropb "google.golang.org/genproto/googleapis/longrunning"
-	"google.golang.org/protobuf/types/known/timestamppb"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -34,6 +32,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
 )
 
@@ -50,6 +49,11 @@ import (
 func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReadyCond *v1.Condition, dbInstanceCond *v1.Condition, inst *v1alpha1.Instance, ctx context.Context, stsParams controllers.StsParams, log logr.Logger) (ctrl.Result, error) {
 	log.Info("restoreStateMachine start")
 
+	// A clone restores the backup into a new instance instead.
+	if inst.Spec.Restore.CloneToNewInstance != nil {
+		return r.cloneToNewInstance(ctx, inst, log)
+	}
+
 	// Check instance is provisioned
 	if instanceReadyCond == nil || k8s.ConditionReasonEquals(instanceReadyCond, k8s.CreateInProgress) {
 		log.Info("restoreStateMachine: instance not ready yet, proceed with main reconciliation")
@@ -62,6 +66,11 @@ func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReady
 		return ctrl.Result{}, nil
 	}
 
+	// A dry run only checks the PITR restore point, it doesn't need force.
+	if inst.Spec.Restore.PITRRestore != nil && inst.Spec.Restore.PITRRestore.DryRun {
+		return r.pitrRestoreDryRun(ctx, inst, req.Namespace, log)
+	}
+
 	// Check the Force flag
 	if !inst.Spec.Restore.Force {
 		log.Info("instance is up and running. To replace (restore from a backup), set force=true")
@@ -113,6 +122,32 @@ func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReady
 			log.Error(e, "AcquireInstanceMaintenanceLock failed")
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, e
 		}
+		// Block restores the Oracle home of the instance can't open, and
+		// restores of incremental backups missing a backup of their chain.
+		msg, err := r.restoreIncompatibility(ctx, inst, backup)
+		if err != nil {
+			log.Error(err, "restoreIncompatibility failed")
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		if msg == "" {
+			if msg, err = r.incrementalChainGap(ctx, backup); err != nil {
+				log.Error(err, "incrementalChainGap failed")
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+		}
+		// A PITR restore point the replicated redo logs don't reach would
+		// otherwise fail in RMAN after the database was replaced.
+		if msg == "" && inst.Spec.Restore.PITRRestore != nil {
+			if msg, err = r.pitrTargetUnrecoverable(ctx, inst, backup, log); err != nil {
+				log.Error(err, "pitrTargetUnrecoverable failed")
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+		}
+		if msg != "" {
+			inst.Status.LastRestoreTime = inst.Spec.Restore.RequestTime.DeepCopy()
+			e := r.setRestoreFailed(ctx, inst, msg, log)
+			return ctrl.Result{}, e
+		}
 		inst.Status.LastRestoreTime = inst.Spec.Restore.RequestTime.DeepCopy()
 		inst.Status.BackupID = ""
 		k8s.InstanceUpsertCondition(&inst.Status, k8s.Ready, v1.ConditionFalse, k8s.RestorePreparationInProgress, "")
@@ -241,7 +276,7 @@ func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReady
 			}
 			if !oracleRunning {
 				log.Info("post restore bootstrap still in progress, waiting")
-				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+				return ctrl.Result{RequeueAfter: oracleStartupRequeueAfter(inst)}, nil
 			}
 		case "Physical":
 			req := &controllers.BootstrapDatabaseRequest{
@@ -258,6 +293,14 @@ func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReady
 				return ctrl.Result{}, nil
 			}
 		}
+		if isClone(inst) {
+			if err := r.changeCloneDBID(ctx, inst); err != nil {
+				if e := r.setRestoreFailed(ctx, inst, fmt.Sprintf("Failed to change the DBID of the clone: %v", err), log); e != nil {
+					return ctrl.Result{}, e
+				}
+				return ctrl.Result{}, nil
+			}
+		}
 
 		log.Info("restoreStateMachine: PostRestoreBootstrapInProgress->PostRestoreBootstrapComplete")
 		k8s.InstanceUpsertCondition(&inst.Status, k8s.Ready, v1.ConditionFalse, k8s.PostRestoreBootstrapComplete, "")
@@ -308,6 +351,9 @@ func (r *InstanceReconciler) restoreStateMachine(req ctrl.Request, instanceReady
 
 // Update spec and status of the instance to reflect restore success.
 func (r *InstanceReconciler) setRestoreSucceeded(ctx context.Context, inst *v1alpha1.Instance, message string, log logr.Logger) error {
+	if err := controllers.RunHooks(ctx, r, r.DatabaseClientFactory, r.Recorder, inst, v1alpha1.PostRestoreHook); err != nil {
+		return r.setRestoreFailed(ctx, inst, fmt.Sprintf("Post-restore hook failed: %v", err), log)
+	}
 	log.Info("Restore succeeded")
 	// Release maintenance lock
 	if err := ReleaseInstanceMaintenanceLock(ctx, r.Client, inst, "instancecontroller"); err != nil {
@@ -448,48 +494,27 @@ func (r *InstanceReconciler) restorePhysical(ctx context.Context, inst v1alpha1.
 	ctxRestore, cancel := context.WithTimeout(context.Background(), timeLimitMinutes)
 	defer cancel()
 
-	var sTime, eTime *timestamppb.Timestamp
-	var incarnation, backupIncarnation string
-	var sSCN, eSCN int64
-	var p v1alpha1.PITR
-	var err error
-	backupIncarnation = backup.Labels[controllers.IncarnationLabel]
-	incarnation = backupIncarnation
+	backupIncarnation := backup.Labels[controllers.IncarnationLabel]
+	pitrInput := &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{Incarnation: backupIncarnation}
 	if inst.Spec.Restore.PITRRestore != nil {
-		// preflight check in findPITRBackupForRestore that only either SCN or timestamp must be set in PITRRestore.
-		if inst.Spec.Restore.PITRRestore.SCN != "" {
-			sSCN, err = strconv.ParseInt(backup.Annotations[controllers.SCNAnnotation], 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("PITR restore preflight check: failed to parse backup SCN %v from backup %v", err, backup)
-			}
-			eSCN, err = strconv.ParseInt(inst.Spec.Restore.PITRRestore.SCN, 10, 64)
-			if err != nil {
-				return nil, fmt.Errorf("PITR restore preflight check: failed to parse restore SCN %v from spec %v", err, inst.Spec.Restore)
-			}
+		var err error
+		if pitrInput, err = r.pitrRestoreInput(ctx, &inst, backup, log); err != nil {
+			return nil, err
 		}
+	}
 
-		if inst.Spec.Restore.PITRRestore.Timestamp != nil {
-			backupTimestamp, err := time.Parse(time.RFC3339, backup.Annotations[controllers.TimestampAnnotation])
-			if err != nil {
-				log.Error(err, "failed to find backup timestamp")
-				return nil, err
-			}
-			sTime = timestamppb.New(backupTimestamp)
-			eTime = timestamppb.New(inst.Spec.Restore.PITRRestore.Timestamp.Time)
-		}
+	// A backup taken in the current incarnation of this instance must belong
+	// to the current DBID.
+	var dbid string
+	if backup.Spec.Instance == inst.Name && backupIncarnation != "" && backupIncarnation == inst.Status.CurrentDatabaseIncarnation {
+		dbid = inst.Status.DBID
+	}
 
-		p, err = r.findRestorePITR(ctx, &inst)
-		if err != nil {
-			return nil, err
-		}
-		incarnation = inst.Spec.Restore.PITRRestore.Incarnation
-		if incarnation == "" {
-			if inst.Spec.Restore.PITRRestore.PITRRef != nil {
-				// PITRRef was specified.
-				incarnation = p.Status.CurrentDatabaseIncarnation
-			} else {
-				incarnation = inst.Status.CurrentDatabaseIncarnation
-			}
+	var chainGcsPaths []string
+	if backup.Spec.GcsPath != "" {
+		var err error
+		if chainGcsPaths, err = r.incrementalChainGcsPaths(ctx, backup); err != nil {
+			return nil, fmt.Errorf("failed to get the incremental chain of the backup: %v", err)
 		}
 	}
 
@@ -500,13 +525,16 @@ func (r *InstanceReconciler) restorePhysical(ctx context.Context, inst v1alpha1.
 		LocalPath:         backup.Spec.LocalPath,
 		GcsPath:           backup.Spec.GcsPath,
 		LroInput:          &controllers.LROInput{OperationId: lroRestoreOperationID(physicalRestore, inst)},
-		LogGcsPath:        p.Spec.StorageURI,
-		Incarnation:       incarnation,
+		LogGcsPath:        pitrInput.GetLogGcsPath(),
+		Incarnation:       pitrInput.GetIncarnation(),
 		BackupIncarnation: backupIncarnation,
-		StartTime:         sTime,
-		EndTime:           eTime,
-		StartScn:          sSCN,
-		EndScn:            eSCN,
+		DBID:              dbid,
+		StartTime:         pitrInput.GetStartTime(),
+		EndTime:           pitrInput.GetEndTime(),
+		StartScn:          pitrInput.GetStartScn(),
+		EndScn:            pitrInput.GetEndScn(),
+		Stream:            backup.Spec.StreamToGcs,
+		ChainGcsPaths:     chainGcsPaths,
 	}
 	resp, err := controllers.PhysicalRestore(ctxRestore, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, *restoreReq)
 	if err != nil {
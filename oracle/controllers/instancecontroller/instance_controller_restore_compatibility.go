@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// restoreIncompatibility returns why the backup can not be restored into the
+// Oracle home of the instance, an empty string if it can. The source version
+// is read from the metadata object of a GCS backup, or else from the backup
+// annotation; backups without a recorded source version are not checked.
+func (r *InstanceReconciler) restoreIncompatibility(ctx context.Context, inst *v1alpha1.Instance, backup *v1alpha1.Backup) (string, error) {
+	var source string
+	if backup.Status.GcsPath != "" {
+		metadata, err := controllers.ReadBackupMetadata(ctx, r, r.DatabaseClientFactory, inst.GetNamespace(), inst.Name, backup.Status.GcsPath)
+		if err != nil {
+			return "", err
+		}
+		source = metadata.GetSourceVersion()
+	}
+	if source == "" {
+		source = backup.Annotations[controllers.DatabaseVersionAnnotation]
+	}
+	if source == "" {
+		return "", nil
+	}
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create database daemon client: %v", err)
+	}
+	defer closeConn()
+	resp, err := dbClient.CheckRestoreCompatibility(ctx, &dbdpb.CheckRestoreCompatibilityRequest{SourceVersion: source})
+	if err != nil {
+		return "", fmt.Errorf("failed to check the restore compatibility: %v", err)
+	}
+	if resp.GetCompatible() {
+		return "", nil
+	}
+	return fmt.Sprintf("Backup %s is incompatible with the Oracle home %s of the instance: %s", backup.Name, resp.GetTargetVersion(), resp.GetReason()), nil
+}
+
+// incrementalChainGap returns why an incremental backup can not be restored
+// for lack of the level 0 backup anchoring its chain or of a level 1 backup
+// it builds on, an empty string if they all succeeded. The backups of the
+// chain of a GCS backup must be in GCS too, their pieces are downloaded
+// along with it.
+func (r *InstanceReconciler) incrementalChainGap(ctx context.Context, backup *v1alpha1.Backup) (string, error) {
+	chain := backup.Status.IncrementalChain
+	if chain == nil || chain.Level == 0 {
+		return "", nil
+	}
+	for _, name := range chainBackups(chain) {
+		kind := "level 1 backup"
+		if name == chain.Anchor {
+			kind = "level 0 backup"
+		}
+		var b v1alpha1.Backup
+		if err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: name}, &b); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Sprintf("Incremental backup %s requires the %s %s, which doesn't exist", backup.Name, kind, name), nil
+			}
+			return "", err
+		}
+		if !b.DeletionTimestamp.IsZero() {
+			return fmt.Sprintf("Incremental backup %s requires the %s %s, which is being deleted", backup.Name, kind, name), nil
+		}
+		if b.Status.Phase != commonv1alpha1.BackupSucceeded {
+			return fmt.Sprintf("Incremental backup %s requires the %s %s, which is in phase %q", backup.Name, kind, name, b.Status.Phase), nil
+		}
+		if controllers.GetBackupGcsPath(backup) != "" && b.Status.GcsPath == "" {
+			return fmt.Sprintf("Incremental backup %s requires the %s %s, which isn't stored in GCS", backup.Name, kind, name), nil
+		}
+	}
+	return "", nil
+}
+
+// incrementalChainGcsPaths returns the GCS paths of the backups an
+// incremental backup builds on, level 0 backup first.
+func (r *InstanceReconciler) incrementalChainGcsPaths(ctx context.Context, backup *v1alpha1.Backup) ([]string, error) {
+	chain := backup.Status.IncrementalChain
+	if chain == nil || chain.Level == 0 {
+		return nil, nil
+	}
+	var paths []string
+	for _, name := range chainBackups(chain) {
+		var b v1alpha1.Backup
+		if err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: name}, &b); err != nil {
+			return nil, err
+		}
+		paths = append(paths, b.Status.GcsPath)
+	}
+	return paths, nil
+}
+
+// chainBackups returns the names of the backups of a chain a backup builds
+// on, level 0 backup first.
+func chainBackups(chain *v1alpha1.IncrementalChainStatus) []string {
+	return append([]string{chain.Anchor}, chain.Parents...)
+}
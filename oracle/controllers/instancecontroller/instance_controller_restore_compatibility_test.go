@@ -0,0 +1,253 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestRestoreIncompatibility(t *testing.T) {
+	tests := []struct {
+		name          string
+		sourceVersion string
+		gcsPath       string
+		gcsMetadata   *dbdpb.BackupMetadata
+		resp          *dbdpb.CheckRestoreCompatibilityResponse
+		respErr       error
+		wantMsg       string
+		wantErr       bool
+		wantCalls     int
+	}{
+		{
+			name: "backup without a source version",
+		},
+		{
+			name:          "compatible",
+			sourceVersion: "19.3.0.0.0",
+			resp:          &dbdpb.CheckRestoreCompatibilityResponse{TargetVersion: "19.15.0.0.0", Compatible: true},
+			wantCalls:     1,
+		},
+		{
+			name:          "incompatible",
+			sourceVersion: "19.15.0.0.0",
+			resp: &dbdpb.CheckRestoreCompatibilityResponse{
+				TargetVersion: "19.3.0.0.0",
+				Reason:        "the backup was taken on 19.15.0.0.0, a later patch level than the Oracle home 19.3.0.0.0",
+			},
+			wantMsg:   "Backup bkp is incompatible with the Oracle home 19.3.0.0.0 of the instance: the backup was taken on 19.15.0.0.0",
+			wantCalls: 1,
+		},
+		{
+			name:          "source version from the metadata object",
+			sourceVersion: "19.10.0.0.0",
+			gcsPath:       "gs://bucket/backups/bkp",
+			gcsMetadata:   &dbdpb.BackupMetadata{Tag: "20220501010000", SourceVersion: "19.15.0.0.0"},
+			resp:          &dbdpb.CheckRestoreCompatibilityResponse{TargetVersion: "19.15.0.0.0", Compatible: true},
+			wantCalls:     1,
+		},
+		{
+			name:          "old GCS backup without a metadata object",
+			sourceVersion: "19.10.0.0.0",
+			gcsPath:       "gs://bucket/backups/bkp",
+			resp:          &dbdpb.CheckRestoreCompatibilityResponse{TargetVersion: "19.15.0.0.0", Compatible: true},
+			wantCalls:     1,
+		},
+		{
+			name:          "check failure",
+			sourceVersion: "19.15.0.0.0",
+			respErr:       errors.New("sqlplus -V failed"),
+			wantErr:       true,
+			wantCalls:     1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("CheckRestoreCompatibility", tc.resp)
+			}
+			if tc.respErr != nil {
+				factory.Dbclient.SetMethodToError("CheckRestoreCompatibility", tc.respErr)
+			}
+			wantSource := tc.sourceVersion
+			if tc.gcsMetadata != nil {
+				factory.Dbclient.SetMethodToResp("ReadBackupMetadata", &dbdpb.ReadBackupMetadataResponse{Metadata: tc.gcsMetadata, Found: true})
+				wantSource = tc.gcsMetadata.GetSourceVersion()
+			}
+			r := &InstanceReconciler{DatabaseClientFactory: factory}
+			backup := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "bkp"}}
+			if tc.sourceVersion != "" {
+				backup.Annotations = map[string]string{controllers.DatabaseVersionAnnotation: tc.sourceVersion}
+			}
+			backup.Status.GcsPath = tc.gcsPath
+
+			msg, err := r.restoreIncompatibility(context.Background(), &v1alpha1.Instance{}, backup)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("restoreIncompatibility got error %v, want error %v", err, tc.wantErr)
+			}
+			if (msg == "") != (tc.wantMsg == "") || !strings.HasPrefix(msg, tc.wantMsg) {
+				t.Errorf("restoreIncompatibility got %q, want %q", msg, tc.wantMsg)
+			}
+			if got := factory.Dbclient.CheckRestoreCompatibilityCalledCnt(); got != tc.wantCalls {
+				t.Errorf("CheckRestoreCompatibility called %d times, want %d", got, tc.wantCalls)
+			}
+			if tc.wantCalls > 0 {
+				if got := factory.Dbclient.GotCheckRestoreCompatibilityRequest.GetSourceVersion(); got != wantSource {
+					t.Errorf("CheckRestoreCompatibility got source version %q, want %q", got, wantSource)
+				}
+			}
+		})
+	}
+}
+
+func TestIncrementalChainGap(t *testing.T) {
+	backup := func(name string, phase commonv1alpha1.BackupPhase) *v1alpha1.Backup {
+		return &v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name},
+			Status:     v1alpha1.BackupStatus{BackupStatus: commonv1alpha1.BackupStatus{Phase: phase}},
+		}
+	}
+	inGCS := func(b *v1alpha1.Backup) *v1alpha1.Backup {
+		b.Status.GcsPath = "gs://bucket/backups/" + b.Name
+		return b
+	}
+	tests := []struct {
+		name    string
+		chain   *v1alpha1.IncrementalChainStatus
+		gcsPath string
+		backups []client.Object
+		wantMsg string
+	}{
+		{
+			name: "not part of a chain",
+		},
+		{
+			name:  "level 0 backup",
+			chain: &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "bkp"},
+		},
+		{
+			name:    "complete chain",
+			chain:   &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a"}},
+			backups: []client.Object{backup("l0", commonv1alpha1.BackupSucceeded), backup("l1a", commonv1alpha1.BackupSucceeded)},
+		},
+		{
+			name:    "missing level 0 backup",
+			chain:   &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Cumulative: true},
+			wantMsg: "Incremental backup bkp requires the level 0 backup l0, which doesn't exist",
+		},
+		{
+			name:    "failed level 1 parent",
+			chain:   &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a"}},
+			backups: []client.Object{backup("l0", commonv1alpha1.BackupSucceeded), backup("l1a", commonv1alpha1.BackupFailed)},
+			wantMsg: `Incremental backup bkp requires the level 1 backup l1a, which is in phase "Failed"`,
+		},
+		{
+			name:    "complete GCS chain",
+			chain:   &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a"}},
+			gcsPath: "gs://bucket/backups/bkp",
+			backups: []client.Object{inGCS(backup("l0", commonv1alpha1.BackupSucceeded)), inGCS(backup("l1a", commonv1alpha1.BackupSucceeded))},
+		},
+		{
+			name:    "GCS backup with a local level 0 backup",
+			chain:   &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Cumulative: true},
+			gcsPath: "gs://bucket/backups/bkp",
+			backups: []client.Object{backup("l0", commonv1alpha1.BackupSucceeded)},
+			wantMsg: "Incremental backup bkp requires the level 0 backup l0, which isn't stored in GCS",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build the scheme: %v", err)
+			}
+			r := &InstanceReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.backups...).Build()}
+			b := backup("bkp", commonv1alpha1.BackupSucceeded)
+			b.Status.IncrementalChain = tc.chain
+			b.Spec.GcsPath = tc.gcsPath
+
+			msg, err := r.incrementalChainGap(context.Background(), b)
+			if err != nil {
+				t.Fatalf("incrementalChainGap failed: %v", err)
+			}
+			if msg != tc.wantMsg {
+				t.Errorf("incrementalChainGap got %q, want %q", msg, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestIncrementalChainGcsPaths(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	var backups []client.Object
+	for _, name := range []string{"l0", "l1a", "l1b"} {
+		b := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name}}
+		b.Status.GcsPath = "gs://bucket/backups/" + name
+		backups = append(backups, b)
+	}
+	r := &InstanceReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(backups...).Build()}
+
+	tests := []struct {
+		name  string
+		chain *v1alpha1.IncrementalChainStatus
+		want  []string
+	}{
+		{
+			name: "not part of a chain",
+		},
+		{
+			name:  "level 0 backup",
+			chain: &v1alpha1.IncrementalChainStatus{Level: 0, Anchor: "bkp"},
+		},
+		{
+			name:  "differential backup",
+			chain: &v1alpha1.IncrementalChainStatus{Level: 1, Anchor: "l0", Parents: []string{"l1a", "l1b"}},
+			want:  []string{"gs://bucket/backups/l0", "gs://bucket/backups/l1a", "gs://bucket/backups/l1b"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "bkp"}}
+			b.Status.IncrementalChain = tc.chain
+
+			got, err := r.incrementalChainGcsPaths(context.Background(), b)
+			if err != nil {
+				t.Fatalf("incrementalChainGcsPaths failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("incrementalChainGcsPaths got unexpected paths (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -23,10 +23,13 @@ import (
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
 	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -191,6 +194,118 @@ func (r *InstanceReconciler) findRestorePITR(ctx context.Context, inst *v1alpha1
 	if !found {
 		return p, fmt.Errorf("PITR preflight check: instance doesn't have PITR enabled or specified")
 	}
-	// TODO: check PITRRestoreSpec.scn/timestamp against actual recovery window
 	return PITRList.Items[0], nil
 }
+
+// pitrRestoreInput returns the replicated redo logs and the range a PITR
+// restore recovers the backup with, from the backup to the restore point.
+func (r *InstanceReconciler) pitrRestoreInput(ctx context.Context, inst *v1alpha1.Instance, backup *v1alpha1.Backup, log logr.Logger) (*dbdpb.PhysicalRestoreRequest_PITRRestoreInput, error) {
+	spec := inst.Spec.Restore.PITRRestore
+	input := &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{}
+	// preflight check in findPITRBackupForRestore that only either SCN or timestamp must be set in PITRRestore.
+	if spec.SCN != "" {
+		var err error
+		input.StartScn, err = strconv.ParseInt(backup.Annotations[controllers.SCNAnnotation], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("PITR restore preflight check: failed to parse backup SCN %v from backup %v", err, backup)
+		}
+		input.EndScn, err = strconv.ParseInt(spec.SCN, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("PITR restore preflight check: failed to parse restore SCN %v from spec %v", err, inst.Spec.Restore)
+		}
+	}
+
+	if spec.Timestamp != nil {
+		backupTimestamp, err := time.Parse(time.RFC3339, backup.Annotations[controllers.TimestampAnnotation])
+		if err != nil {
+			log.Error(err, "failed to find backup timestamp")
+			return nil, err
+		}
+		input.StartTime = timestamppb.New(backupTimestamp)
+		input.EndTime = timestamppb.New(spec.Timestamp.Time)
+	}
+
+	p, err := r.findRestorePITR(ctx, inst)
+	if err != nil {
+		return nil, err
+	}
+	input.LogGcsPath = p.Spec.StorageURI
+	input.Incarnation = spec.Incarnation
+	if input.Incarnation == "" {
+		if spec.PITRRef != nil {
+			// PITRRef was specified.
+			input.Incarnation = p.Status.CurrentDatabaseIncarnation
+		} else {
+			input.Incarnation = inst.Status.CurrentDatabaseIncarnation
+		}
+	}
+	return input, nil
+}
+
+// pitrRestorePoint describes the restore point of a PITR restore.
+func pitrRestorePoint(spec *v1alpha1.PITRRestoreSpec) string {
+	if spec.Timestamp != nil {
+		return spec.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return "SCN " + spec.SCN
+}
+
+// pitrTargetUnrecoverable checks that the replicated redo logs recover the
+// backup to the restore point of a PITR restore and reports it in the
+// PITRTargetRecoverable condition. It returns why the restore point isn't
+// recoverable, with the closest recoverable point, an empty string if it is.
+func (r *InstanceReconciler) pitrTargetUnrecoverable(ctx context.Context, inst *v1alpha1.Instance, backup *v1alpha1.Backup, log logr.Logger) (string, error) {
+	input, err := r.pitrRestoreInput(ctx, inst, backup, log)
+	if err != nil {
+		return "", err
+	}
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to create database daemon client: %v", err)
+	}
+	defer closeConn()
+	resp, err := dbClient.CheckPITRTarget(ctx, &dbdpb.CheckPITRTargetRequest{PitrRestoreInput: input})
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.PITRTargetRecoverable, v1.ConditionUnknown, k8s.PITRTargetCheckFailed, fmt.Sprintf("failed to check the restore point: %v", err))
+		return "", fmt.Errorf("failed to check the PITR restore point: %v", err)
+	}
+	point := pitrRestorePoint(inst.Spec.Restore.PITRRestore)
+	if resp.GetRecoverable() {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.PITRTargetRecoverable, v1.ConditionTrue, k8s.PITRTargetValid,
+			fmt.Sprintf("Backup %s recovers to %s", backup.Name, point)).ObservedGeneration = inst.Generation
+		return "", nil
+	}
+
+	msg := fmt.Sprintf("The restore point %s isn't recoverable from backup %s: %s", point, backup.Name, resp.GetReason())
+	switch {
+	case resp.GetClosestScn() != 0 && resp.GetClosestTime() != nil:
+		msg += fmt.Sprintf(", the closest recoverable point is SCN %d at %s", resp.GetClosestScn(), resp.GetClosestTime().AsTime().UTC().Format(time.RFC3339))
+	case resp.GetClosestScn() != 0:
+		msg += fmt.Sprintf(", the closest recoverable point is SCN %d", resp.GetClosestScn())
+	case resp.GetClosestTime() != nil:
+		msg += fmt.Sprintf(", the closest recoverable point is %s", resp.GetClosestTime().AsTime().UTC().Format(time.RFC3339))
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.PITRTargetRecoverable, v1.ConditionFalse, k8s.PITRTargetInvalid, msg).ObservedGeneration = inst.Generation
+	return msg, nil
+}
+
+// pitrRestoreDryRun checks the restore point of a PITR restore without
+// restoring the database, once per generation of the instance.
+func (r *InstanceReconciler) pitrRestoreDryRun(ctx context.Context, inst *v1alpha1.Instance, namespace string, log logr.Logger) (ctrl.Result, error) {
+	cond := k8s.FindCondition(inst.Status.Conditions, k8s.PITRTargetRecoverable)
+	if cond != nil && cond.ObservedGeneration == inst.Generation && cond.Reason != k8s.PITRTargetCheckFailed {
+		return ctrl.Result{}, nil
+	}
+	backup, err := r.findBackupForRestore(ctx, *inst, namespace, log)
+	if err != nil {
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.PITRTargetRecoverable, v1.ConditionFalse, k8s.PITRTargetInvalid, err.Error()).ObservedGeneration = inst.Generation
+		return ctrl.Result{}, nil
+	}
+	msg, err := r.pitrTargetUnrecoverable(ctx, inst, backup, log)
+	if err != nil {
+		log.Error(err, "pitrTargetUnrecoverable failed")
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	log.Info("PITR restore dry run completed", "backup", backup.Name, "unrecoverable", msg)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func newPITRRestoreReconciler(t *testing.T, factory *testhelpers.FakeDatabaseClientFactory, objs ...client.Object) *InstanceReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build the scheme: %v", err)
+	}
+	pitr := &v1alpha1.PITR{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb-pitr"},
+		Spec:       v1alpha1.PITRSpec{InstanceRef: &v1alpha1.InstanceReference{Name: "mydb"}, StorageURI: "gs://bucket/pitr"},
+	}
+	return &InstanceReconciler{
+		Client:                fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, pitr)...).Build(),
+		DatabaseClientFactory: factory,
+	}
+}
+
+func pitrRestoreInstance(spec *v1alpha1.PITRRestoreSpec) *v1alpha1.Instance {
+	inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mydb", Generation: 3}}
+	inst.Spec.Restore = &v1alpha1.RestoreSpec{BackupType: "Physical", PITRRestore: spec}
+	inst.Status.CurrentDatabaseIncarnation = "2"
+	return inst
+}
+
+func TestPITRTargetUnrecoverable(t *testing.T) {
+	target := metav1.NewTime(time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC))
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "db",
+			Name:      "bkp",
+			Annotations: map[string]string{
+				controllers.SCNAnnotation:       "1500",
+				controllers.TimestampAnnotation: "2022-05-01T10:00:00Z",
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		spec       *v1alpha1.PITRRestoreSpec
+		resp       *dbdpb.CheckPITRTargetResponse
+		respErr    error
+		wantInput  *dbdpb.PhysicalRestoreRequest_PITRRestoreInput
+		wantMsg    string
+		wantErr    bool
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "recoverable SCN",
+			spec:       &v1alpha1.PITRRestoreSpec{SCN: "2500"},
+			wantInput:  &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/pitr", Incarnation: "2", StartScn: 1500, EndScn: 2500},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: k8s.PITRTargetValid,
+		},
+		{
+			name: "timestamp past the logs",
+			spec: &v1alpha1.PITRRestoreSpec{Timestamp: &target, Incarnation: "1"},
+			resp: &dbdpb.CheckPITRTargetResponse{
+				ClosestScn:  3000,
+				ClosestTime: timestamppb.New(time.Date(2022, 5, 1, 11, 0, 0, 0, time.UTC)),
+				Reason:      "the replicated redo logs following the backup at 2022-05-01T10:00:00Z end at 2022-05-01T11:00:00Z",
+			},
+			wantInput: &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{
+				LogGcsPath:  "gs://bucket/pitr",
+				Incarnation: "1",
+				StartTime:   timestamppb.New(time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)),
+				EndTime:     timestamppb.New(target.Time),
+			},
+			wantMsg:    "The restore point 2022-05-01T12:00:00Z isn't recoverable from backup bkp: the replicated redo logs following the backup at 2022-05-01T10:00:00Z end at 2022-05-01T11:00:00Z, the closest recoverable point is SCN 3000 at 2022-05-01T11:00:00Z",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.PITRTargetInvalid,
+		},
+		{
+			name:       "no log covers the backup",
+			spec:       &v1alpha1.PITRRestoreSpec{SCN: "2500"},
+			resp:       &dbdpb.CheckPITRTargetResponse{Reason: "no replicated redo log covers the backup at SCN 1500"},
+			wantMsg:    "The restore point SCN 2500 isn't recoverable from backup bkp: no replicated redo log covers the backup at SCN 1500",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.PITRTargetInvalid,
+		},
+		{
+			name:       "check failure",
+			spec:       &v1alpha1.PITRRestoreSpec{SCN: "2500"},
+			respErr:    errors.New("storage: bucket doesn't exist"),
+			wantErr:    true,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: k8s.PITRTargetCheckFailed,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("CheckPITRTarget", tc.resp)
+			}
+			if tc.respErr != nil {
+				factory.Dbclient.SetMethodToError("CheckPITRTarget", tc.respErr)
+			}
+			r := newPITRRestoreReconciler(t, factory)
+			inst := pitrRestoreInstance(tc.spec)
+
+			msg, err := r.pitrTargetUnrecoverable(context.Background(), inst, backup, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("pitrTargetUnrecoverable got error %v, want error %v", err, tc.wantErr)
+			}
+			if msg != tc.wantMsg {
+				t.Errorf("pitrTargetUnrecoverable got %q, want %q", msg, tc.wantMsg)
+			}
+			if tc.wantInput != nil {
+				if diff := cmp.Diff(tc.wantInput, factory.Dbclient.GotCheckPITRTargetRequest.GetPitrRestoreInput(), protocmp.Transform()); diff != "" {
+					t.Errorf("CheckPITRTarget got unexpected input: want-, got+: %s\n", diff)
+				}
+			}
+			cond := k8s.FindCondition(inst.Status.Conditions, k8s.PITRTargetRecoverable)
+			if cond == nil || cond.Status != tc.wantStatus || cond.Reason != tc.wantReason {
+				t.Fatalf("pitrTargetUnrecoverable got condition %+v, want status %s and reason %s", cond, tc.wantStatus, tc.wantReason)
+			}
+			if tc.wantMsg != "" && cond.Message != tc.wantMsg {
+				t.Errorf("pitrTargetUnrecoverable got condition message %q, want %q", cond.Message, tc.wantMsg)
+			}
+		})
+	}
+}
+
+func TestPITRRestoreDryRun(t *testing.T) {
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "db",
+			Name:        "bkp",
+			Labels:      map[string]string{controllers.PITRLabel: "mydb-pitr", controllers.IncarnationLabel: "2"},
+			Annotations: map[string]string{controllers.SCNAnnotation: "1500"},
+		},
+	}
+	backup.Spec.Type = "Physical"
+	backup.Status.Conditions = k8s.Upsert(backup.Status.Conditions, k8s.Ready, metav1.ConditionTrue, k8s.BackupReady, "")
+
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("CheckPITRTarget", &dbdpb.CheckPITRTargetResponse{ClosestScn: 2000, Reason: "the replicated redo logs following the backup at SCN 1500 end at SCN 2000"})
+	r := newPITRRestoreReconciler(t, factory, backup)
+	inst := pitrRestoreInstance(&v1alpha1.PITRRestoreSpec{SCN: "2500", DryRun: true})
+
+	for i := 0; i < 2; i++ {
+		res, err := r.pitrRestoreDryRun(context.Background(), inst, "db", logr.Discard())
+		if err != nil || !res.IsZero() {
+			t.Fatalf("pitrRestoreDryRun got %v, %v, want an empty result", res, err)
+		}
+	}
+	// The second reconcile of the same generation doesn't check again.
+	if got := factory.Dbclient.CheckPITRTargetCalledCnt(); got != 1 {
+		t.Errorf("CheckPITRTarget called %d times, want 1", got)
+	}
+	cond := k8s.FindCondition(inst.Status.Conditions, k8s.PITRTargetRecoverable)
+	if cond == nil || cond.Reason != k8s.PITRTargetInvalid || cond.ObservedGeneration != inst.Generation {
+		t.Fatalf("pitrRestoreDryRun got condition %+v, want reason %s in generation %d", cond, k8s.PITRTargetInvalid, inst.Generation)
+	}
+	if want := "the closest recoverable point is SCN 2000"; !strings.Contains(cond.Message, want) {
+		t.Errorf("pitrRestoreDryRun got condition message %q, want it to contain %q", cond.Message, want)
+	}
+
+	// Without a backup before the restore point the dry run reports it too.
+	inst = pitrRestoreInstance(&v1alpha1.PITRRestoreSpec{SCN: "1000", DryRun: true})
+	if _, err := r.pitrRestoreDryRun(context.Background(), inst, "db", logr.Discard()); err != nil {
+		t.Fatalf("pitrRestoreDryRun failed: %v", err)
+	}
+	if cond := k8s.FindCondition(inst.Status.Conditions, k8s.PITRTargetRecoverable); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("pitrRestoreDryRun without a backup got condition %+v, want status False", cond)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// rmanConfigRequest converts spec.rmanConfig into a ConfigureRMAN request.
+func rmanConfigRequest(spec *v1alpha1.RMANConfigSpec) *dbdpb.ConfigureRMANRequest {
+	req := &dbdpb.ConfigureRMANRequest{
+		RecoveryWindowDays:    spec.RecoveryWindowDays,
+		Redundancy:            spec.Redundancy,
+		ControlfileAutobackup: true,
+		Parallelism:           spec.Parallelism,
+	}
+	if spec.ControlfileAutobackup != nil {
+		req.ControlfileAutobackup = *spec.ControlfileAutobackup
+	}
+	return req
+}
+
+// reconcileRMANConfig applies spec.rmanConfig to the RMAN persistent
+// configuration. The dbdaemon only runs CONFIGURE commands for settings
+// which differ, so this is safe to call on every reconcile.
+func (r *InstanceReconciler) reconcileRMANConfig(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.RMANConfig == nil {
+		return nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.ConfigureRMAN(ctx, rmanConfigRequest(inst.Spec.RMANConfig))
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.RMANConfigureFailed, "Failed to configure RMAN: %v", err)
+		return err
+	}
+	if len(resp.GetAppliedStatements()) > 0 {
+		log.Info("RMAN configured", "statements", resp.GetAppliedStatements(), "settings", resp.GetSettings())
+		r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.RMANConfigured, "RMAN configured: %s", strings.Join(resp.GetAppliedStatements(), " "))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// rmanCatalogExportDue reports whether an RMAN catalog export is due at now
+// and returns when the following export is scheduled. An export is due if
+// there was none yet or if a scheduled time passed since the last one.
+func rmanCatalogExportDue(spec *v1alpha1.RMANCatalogExportSpec, status *v1alpha1.RMANCatalogExportStatus, now time.Time) (bool, time.Time, error) {
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to parse RMAN catalog export schedule %q: %v", spec.Schedule, err)
+	}
+	next := schedule.Next(now)
+	if status == nil || status.LastExportTime == nil {
+		return true, next, nil
+	}
+	if schedule.Next(status.LastExportTime.Time).After(now) {
+		return false, schedule.Next(status.LastExportTime.Time), nil
+	}
+	return true, next, nil
+}
+
+// rmanCatalogExportPath returns the GCS directory of an export taken at now.
+func rmanCatalogExportPath(gcsPath string, now time.Time) string {
+	return strings.TrimSuffix(gcsPath, "/") + "/" + now.UTC().Format("20060102T150405Z")
+}
+
+// reconcileRMANCatalogExport exports the RMAN catalog and a control file copy
+// to spec.rmanCatalogExport.gcsPath on its schedule. The result requeues the
+// instance for the next export.
+func (r *InstanceReconciler) reconcileRMANCatalogExport(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	spec := inst.Spec.RMANCatalogExport
+	if spec == nil {
+		inst.Status.RMANCatalogExport = nil
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	due, nextExport, err := rmanCatalogExportDue(spec, inst.Status.RMANCatalogExport, now)
+	if err != nil {
+		r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.RMANCatalogExportFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	status := &v1alpha1.RMANCatalogExportStatus{}
+	if inst.Status.RMANCatalogExport != nil {
+		status = inst.Status.RMANCatalogExport.DeepCopy()
+	}
+	next := v1.NewTime(nextExport)
+	status.NextExportTime = &next
+	inst.Status.RMANCatalogExport = status
+	result := ctrl.Result{RequeueAfter: nextExport.Sub(now)}
+	if !due {
+		return result, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	path := rmanCatalogExportPath(spec.GcsPath, now)
+	resp, err := dbClient.ExportRMANCatalog(ctx, &dbdpb.ExportRMANCatalogRequest{GcsPath: path})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.RMANCatalogExportFailed, "Failed to export the RMAN catalog to %s: %v", path, err)
+		return result, err
+	}
+	log.Info("RMAN catalog exported", "gcsPath", path, "dbid", resp.GetDbid())
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.RMANCatalogExported, "RMAN catalog exported to %s", path)
+	last := v1.NewTime(now)
+	status.LastExportTime = &last
+	status.LastExportGcsPath = path
+	return result, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+)
+
+func TestRMANCatalogExportDue(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, time.Local)
+	}
+	lastAt := func(day, hour, min int) *v1alpha1.RMANCatalogExportStatus {
+		last := metav1.NewTime(at(day, hour, min))
+		return &v1alpha1.RMANCatalogExportStatus{LastExportTime: &last}
+	}
+	nightly := &v1alpha1.RMANCatalogExportSpec{Schedule: "0 2 * * *", GcsPath: "gs://bucket/catalog"}
+	tests := []struct {
+		name     string
+		spec     *v1alpha1.RMANCatalogExportSpec
+		status   *v1alpha1.RMANCatalogExportStatus
+		now      time.Time
+		wantDue  bool
+		wantNext time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "first export",
+			spec:     nightly,
+			now:      at(16, 1, 0),
+			wantDue:  true,
+			wantNext: at(16, 2, 0),
+		},
+		{
+			name:     "exported since the last scheduled time",
+			spec:     nightly,
+			status:   lastAt(16, 2, 0),
+			now:      at(16, 9, 0),
+			wantNext: at(17, 2, 0),
+		},
+		{
+			name:     "scheduled time passed",
+			spec:     nightly,
+			status:   lastAt(15, 2, 0),
+			now:      at(16, 2, 0),
+			wantDue:  true,
+			wantNext: at(17, 2, 0),
+		},
+		{
+			name:     "scheduled time missed",
+			spec:     nightly,
+			status:   lastAt(12, 2, 0),
+			now:      at(16, 9, 0),
+			wantDue:  true,
+			wantNext: at(17, 2, 0),
+		},
+		{
+			name:    "invalid schedule",
+			spec:    &v1alpha1.RMANCatalogExportSpec{Schedule: "nightly"},
+			now:     at(16, 1, 0),
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			due, next, err := rmanCatalogExportDue(tc.spec, tc.status, tc.now)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("rmanCatalogExportDue got error %v, want error: %v", err, tc.wantErr)
+			}
+			if due != tc.wantDue || !next.Equal(tc.wantNext) {
+				t.Errorf("rmanCatalogExportDue got (%v, %v), want (%v, %v)", due, next, tc.wantDue, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestReconcileRMANCatalogExport(t *testing.T) {
+	spec := &v1alpha1.RMANCatalogExportSpec{Schedule: "0 2 * * *", GcsPath: "gs://bucket/catalog/"}
+	recent := metav1.NewTime(time.Now())
+	tests := []struct {
+		name          string
+		spec          *v1alpha1.RMANCatalogExportSpec
+		status        *v1alpha1.RMANCatalogExportStatus
+		err           error
+		wantErr       bool
+		wantExportCnt int
+		wantEvents    int
+		wantExported  bool
+	}{
+		{
+			name: "not managed",
+		},
+		{
+			name:   "spec removed",
+			status: &v1alpha1.RMANCatalogExportStatus{LastExportTime: &recent},
+		},
+		{
+			name:          "first export",
+			spec:          spec,
+			wantExportCnt: 1,
+			wantEvents:    1,
+			wantExported:  true,
+		},
+		{
+			name:   "not due",
+			spec:   spec,
+			status: &v1alpha1.RMANCatalogExportStatus{LastExportTime: &recent, LastExportGcsPath: "gs://bucket/catalog/previous"},
+		},
+		{
+			name:          "failed",
+			spec:          spec,
+			err:           errors.New("RMAN-06062: can not backup SPFILE because the instance was not started with SPFILE"),
+			wantErr:       true,
+			wantExportCnt: 1,
+			wantEvents:    1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("ExportRMANCatalog", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				Spec:   v1alpha1.InstanceSpec{RMANCatalogExport: tc.spec},
+				Status: v1alpha1.InstanceStatus{RMANCatalogExport: tc.status},
+			}
+
+			result, err := r.reconcileRMANCatalogExport(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileRMANCatalogExport got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.ExportRMANCatalogCalledCnt(); got != tc.wantExportCnt {
+				t.Errorf("reconcileRMANCatalogExport called ExportRMANCatalog %d times, want %d", got, tc.wantExportCnt)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileRMANCatalogExport emitted %d events, want %d", got, tc.wantEvents)
+			}
+			status := inst.Status.RMANCatalogExport
+			if tc.spec == nil {
+				if status != nil {
+					t.Errorf("reconcileRMANCatalogExport got status %+v, want none", status)
+				}
+				return
+			}
+			if result.RequeueAfter <= 0 || status == nil || status.NextExportTime == nil {
+				t.Fatalf("reconcileRMANCatalogExport got requeue after %v and status %+v, want the next export", result.RequeueAfter, status)
+			}
+			if !tc.wantExported {
+				return
+			}
+			path := factory.Dbclient.GotExportRMANCatalogRequest.GetGcsPath()
+			if !strings.HasPrefix(path, "gs://bucket/catalog/20") || strings.Contains(path, "//20") {
+				t.Errorf("reconcileRMANCatalogExport exported to %q, want a timestamped directory of gs://bucket/catalog", path)
+			}
+			if status.LastExportTime == nil || status.LastExportGcsPath != path {
+				t.Errorf("reconcileRMANCatalogExport got status %+v, want the last export at %q", status, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestReconcileRMANConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        *v1alpha1.RMANConfigSpec
+		wantCalls   int
+		wantRequest *dbdpb.ConfigureRMANRequest
+	}{
+		{
+			name: "not managed",
+		},
+		{
+			name:        "defaults",
+			spec:        &v1alpha1.RMANConfigSpec{},
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureRMANRequest{ControlfileAutobackup: true},
+		},
+		{
+			name:        "configured",
+			spec:        &v1alpha1.RMANConfigSpec{RecoveryWindowDays: 7, ControlfileAutobackup: pointer.Bool(false), Parallelism: 4},
+			wantCalls:   1,
+			wantRequest: &dbdpb.ConfigureRMANRequest{RecoveryWindowDays: 7, Parallelism: 4},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			r := &InstanceReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{Spec: v1alpha1.InstanceSpec{RMANConfig: tc.spec}}
+
+			if err := r.reconcileRMANConfig(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileRMANConfig failed: %v", err)
+			}
+			if got := factory.Dbclient.ConfigureRMANCalledCnt(); got != tc.wantCalls {
+				t.Errorf("reconcileRMANConfig called ConfigureRMAN %d times, want %d", got, tc.wantCalls)
+			}
+			if diff := cmp.Diff(tc.wantRequest, factory.Dbclient.GotConfigureRMANRequest, protocmp.Transform()); diff != "" {
+				t.Errorf("reconcileRMANConfig got unexpected request (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
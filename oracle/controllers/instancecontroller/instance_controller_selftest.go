@@ -0,0 +1,112 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// selfTestRetryInterval is how long to wait before rerunning a failed
+// self-test.
+const selfTestRetryInterval = 30 * time.Second
+
+// selfTestGCSPath returns the GCS location the instance restores backups
+// from or uploads them to, if any, to check it is readable: the location of
+// the backup being restored, else of the latest physical backup of the
+// instance, else of the archived log backups.
+func selfTestGCSPath(inst *v1alpha1.Instance, backups []v1alpha1.Backup) string {
+	var latest *v1alpha1.Backup
+	for i := range backups {
+		b := &backups[i]
+		if b.Spec.Type != commonv1alpha1.BackupTypePhysical || backupGCSPath(b) == "" {
+			continue
+		}
+		if restore := inst.Spec.Restore; restore != nil {
+			if ref := restore.BackupRef; ref != nil && ref.Name == b.Name && ref.Namespace == b.Namespace {
+				return backupGCSPath(b)
+			}
+			if restore.BackupID != "" && restore.BackupID == b.Status.BackupID {
+				return backupGCSPath(b)
+			}
+		}
+		if b.Spec.Instance == inst.Name && (latest == nil || latest.CreationTimestamp.Before(&b.CreationTimestamp)) {
+			latest = b
+		}
+	}
+	if latest != nil {
+		return backupGCSPath(latest)
+	}
+	if spec := inst.Spec.RecoveryArea; spec != nil && spec.ArchivelogBackup != nil {
+		return spec.ArchivelogBackup.GcsPath
+	}
+	return ""
+}
+
+// backupGCSPath returns the GCS location of a backup, if it was uploaded
+// to GCS.
+func backupGCSPath(b *v1alpha1.Backup) string {
+	if b.Status.GcsPath != "" {
+		return b.Status.GcsPath
+	}
+	return controllers.GetBackupGcsPath(b)
+}
+
+// selfTestBackupsGCSPath lists the backups in the instance namespace and
+// returns the GCS location to check with the self-test.
+func (r *InstanceReconciler) selfTestBackupsGCSPath(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) string {
+	var backups v1alpha1.BackupList
+	if err := r.List(ctx, &backups, client.InNamespace(inst.Namespace)); err != nil {
+		log.Error(err, "failed to list backups for the self-test, checking the archived log backups location only")
+	}
+	return selfTestGCSPath(inst, backups.Items)
+}
+
+// selfTestFailure runs the database daemon self-test, checking gcsPath is
+// readable if set, and returns a message describing the failed checks, or
+// an empty string if all checks passed.
+func (r *InstanceReconciler) selfTestFailure(ctx context.Context, inst *v1alpha1.Instance, gcsPath string, log logr.Logger) string {
+	resp, err := controllers.SelfTest(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, gcsPath)
+	if err != nil {
+		msg := fmt.Sprintf("Self-test failed: %v", err)
+		r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.SelfTestFailed, msg)
+		return msg
+	}
+	if len(resp.Failures) == 0 {
+		return ""
+	}
+
+	var failures []string
+	for name, reason := range resp.Failures {
+		failures = append(failures, fmt.Sprintf("%s: %s", name, reason))
+	}
+	sort.Strings(failures)
+	msg := fmt.Sprintf("Self-test failed: %s", strings.Join(failures, "; "))
+	log.Info("database daemon self-test failed", "failures", resp.Failures)
+	r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.SelfTestFailed, msg)
+	return msg
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestSelfTestFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *dbdpb.SelfTestResponse
+		err     error
+		want    string
+		wantEvt bool
+	}{
+		{
+			name: "passed",
+			resp: &dbdpb.SelfTestResponse{Checks: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+			}},
+		},
+		{
+			name: "checks failed",
+			resp: &dbdpb.SelfTestResponse{Checks: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+				{Name: "listener", Error: "TNS-12541: TNS:no listener"},
+				{Name: "gcs", Error: "permission denied"},
+			}},
+			want:    "Self-test failed: gcs: permission denied; listener: TNS-12541: TNS:no listener",
+			wantEvt: true,
+		},
+		{
+			name:    "dbdaemon unavailable",
+			err:     errors.New("connection refused"),
+			want:    "Self-test failed: config_agent_helpers/SelfTest: failed to run the self-test: connection refused",
+			wantEvt: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("SelfTest", tc.resp)
+			}
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("SelfTest", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{}
+
+			if got := r.selfTestFailure(context.Background(), inst, "", logr.Discard()); got != tc.want {
+				t.Errorf("selfTestFailure got %q, want %q", got, tc.want)
+			}
+			if got := factory.Dbclient.SelfTestCalledCnt(); got != 1 {
+				t.Errorf("selfTestFailure called SelfTest %d times, want 1", got)
+			}
+			if gotEvt := len(recorder.Events) > 0; gotEvt != tc.wantEvt {
+				t.Errorf("selfTestFailure recorded an event: %v, want %v", gotEvt, tc.wantEvt)
+			}
+		})
+	}
+}
+
+func TestSelfTestGCSPath(t *testing.T) {
+	physicalBackup := func(name, instName, gcsPath string, created time.Time) v1alpha1.Backup {
+		b := v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "db", CreationTimestamp: metav1.NewTime(created)}}
+		b.Spec.Type = commonv1alpha1.BackupTypePhysical
+		b.Spec.Instance = instName
+		b.Spec.GcsPath = gcsPath
+		return b
+	}
+	now := time.Now()
+	backups := []v1alpha1.Backup{
+		physicalBackup("old", "mydb", "gs://bucket/old", now.Add(-2*time.Hour)),
+		physicalBackup("latest", "mydb", "gs://bucket/latest", now.Add(-time.Hour)),
+		physicalBackup("local", "mydb", "", now),
+		physicalBackup("other", "otherdb", "gs://bucket/other", now),
+	}
+	backups[1].Status.BackupID = "latest-id"
+	archivelogBackup := &v1alpha1.RecoveryAreaSpec{ArchivelogBackup: &v1alpha1.ArchivelogBackupSpec{GcsPath: "gs://bucket/archivelog"}}
+
+	tests := []struct {
+		name    string
+		spec    v1alpha1.InstanceSpec
+		backups []v1alpha1.Backup
+		want    string
+	}{
+		{
+			name:    "latest physical backup",
+			spec:    v1alpha1.InstanceSpec{RecoveryArea: archivelogBackup},
+			backups: backups,
+			want:    "gs://bucket/latest",
+		},
+		{
+			name:    "restore by reference",
+			spec:    v1alpha1.InstanceSpec{Restore: &v1alpha1.RestoreSpec{BackupRef: &v1alpha1.BackupReference{Namespace: "db", Name: "other"}}},
+			backups: backups,
+			want:    "gs://bucket/other",
+		},
+		{
+			name:    "restore by backup ID",
+			spec:    v1alpha1.InstanceSpec{Restore: &v1alpha1.RestoreSpec{BackupID: "latest-id"}},
+			backups: backups,
+			want:    "gs://bucket/latest",
+		},
+		{
+			name:    "archived log backups",
+			spec:    v1alpha1.InstanceSpec{RecoveryArea: archivelogBackup},
+			backups: backups[2:3],
+			want:    "gs://bucket/archivelog",
+		},
+		{
+			name: "no GCS location",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: "mydb", Namespace: "db"}, Spec: tc.spec}
+			if got := selfTestGCSPath(inst, tc.backups); got != tc.want {
+				t.Errorf("selfTestGCSPath got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// defaultSQLPlanCaptureDuration is how long a SQL plan baseline capture
+// window lasts if spec.sqlPlanManagement.captureDuration is not set.
+const defaultSQLPlanCaptureDuration = time.Hour
+
+// sqlPlanCaptureWindow reports whether now is within a capture window and
+// returns when that window started and when the capture should next be
+// enabled or disabled. If windows overlap, the earliest open window is
+// returned, the capture stays enabled at its end as the next one is open.
+func sqlPlanCaptureWindow(spec *v1alpha1.SQLPlanManagementSpec, now time.Time) (bool, time.Time, time.Time, error) {
+	schedule, err := cron.ParseStandard(spec.CaptureSchedule)
+	if err != nil {
+		return false, time.Time{}, time.Time{}, fmt.Errorf("failed to parse SQL plan capture schedule %q: %v", spec.CaptureSchedule, err)
+	}
+	duration := defaultSQLPlanCaptureDuration
+	if spec.CaptureDuration != nil && spec.CaptureDuration.Duration > 0 {
+		duration = spec.CaptureDuration.Duration
+	}
+	start := schedule.Next(now.Add(-duration))
+	if start.After(now) {
+		return false, time.Time{}, start, nil
+	}
+	return true, start, start.Add(duration), nil
+}
+
+// reconcileSQLPlanManagement enables optimizer_capture_sql_plan_baselines
+// during the capture windows of spec.sqlPlanManagement and disables it
+// outside of them, then reports the SQL plan baselines in the instance
+// status. The result requeues the instance for the next window change.
+func (r *InstanceReconciler) reconcileSQLPlanManagement(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	spec := inst.Spec.SQLPlanManagement
+	if spec == nil && inst.Status.SQLPlanManagement == nil {
+		return ctrl.Result{}, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	if spec == nil {
+		// Stop a capture left running when spec.sqlPlanManagement was removed.
+		if inst.Status.SQLPlanManagement.CaptureEnabled {
+			if _, err := dbClient.SetSQLPlanCapture(ctx, &dbdpb.SetSQLPlanCaptureRequest{Enabled: false}); err != nil {
+				r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.SQLPlanCaptureFailed, "Failed to stop the SQL plan baseline capture: %v", err)
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.SQLPlanCaptureStopped, "SQL plan baseline capture stopped")
+		}
+		inst.Status.SQLPlanManagement = nil
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	inWindow, windowStart, nextChange, err := sqlPlanCaptureWindow(spec, now)
+	if err != nil {
+		r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.SQLPlanCaptureFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	setResp, err := dbClient.SetSQLPlanCapture(ctx, &dbdpb.SetSQLPlanCaptureRequest{Enabled: inWindow})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.SQLPlanCaptureFailed, "Failed to set the SQL plan baseline capture: %v", err)
+		return ctrl.Result{}, err
+	}
+	if setResp.GetChanged() {
+		log.Info("SQL plan baseline capture changed", "enabled", inWindow, "nextChange", nextChange)
+		if inWindow {
+			r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.SQLPlanCaptureStarted, "SQL plan baseline capture started until %s", nextChange.Format(time.RFC3339))
+		} else {
+			r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.SQLPlanCaptureStopped, "SQL plan baseline capture stopped until %s", nextChange.Format(time.RFC3339))
+		}
+	}
+
+	status := &v1alpha1.SQLPlanManagementStatus{}
+	if inst.Status.SQLPlanManagement != nil {
+		status = inst.Status.SQLPlanManagement.DeepCopy()
+	}
+	status.CaptureEnabled = inWindow
+	next := v1.NewTime(nextChange)
+	status.NextChangeTime = &next
+	if inWindow {
+		start := v1.NewTime(windowStart)
+		status.WindowStartTime = &start
+	}
+	inst.Status.SQLPlanManagement = status
+	result := ctrl.Result{RequeueAfter: nextChange.Sub(now)}
+
+	req := &dbdpb.GetSQLPlanBaselinesRequest{}
+	if status.WindowStartTime != nil {
+		req.CapturedSince = timestamppb.New(status.WindowStartTime.Time)
+	}
+	resp, err := dbClient.GetSQLPlanBaselines(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	status.Baselines = resp.GetBaselines()
+	status.AcceptedBaselines = resp.GetAcceptedBaselines()
+	status.AutoCapturedBaselines = resp.GetAutoCapturedBaselines()
+	status.LastWindowCapturedBaselines = resp.GetCapturedSinceCount()
+	status.LastCaptureTime = nil
+	if resp.GetLastCaptureTime() != nil {
+		last := v1.NewTime(resp.GetLastCaptureTime().AsTime())
+		status.LastCaptureTime = &last
+	}
+	return result, nil
+}
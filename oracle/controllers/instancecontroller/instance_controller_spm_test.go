@@ -0,0 +1,249 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestSQLPlanCaptureWindow(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name       string
+		spec       *v1alpha1.SQLPlanManagementSpec
+		now        time.Time
+		wantIn     bool
+		wantStart  time.Time
+		wantChange time.Time
+		wantErr    bool
+	}{
+		{
+			name:       "before the window",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 2 * * *"},
+			now:        at(16, 1, 30),
+			wantChange: at(16, 2, 0),
+		},
+		{
+			name:       "window start",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 2 * * *"},
+			now:        at(16, 2, 0),
+			wantIn:     true,
+			wantStart:  at(16, 2, 0),
+			wantChange: at(16, 3, 0),
+		},
+		{
+			name:       "within the window",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 2 * * *", CaptureDuration: &metav1.Duration{Duration: 3 * time.Hour}},
+			now:        at(16, 4, 15),
+			wantIn:     true,
+			wantStart:  at(16, 2, 0),
+			wantChange: at(16, 5, 0),
+		},
+		{
+			name:       "window end",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 2 * * *"},
+			now:        at(16, 3, 0),
+			wantChange: at(17, 2, 0),
+		},
+		{
+			name:       "overlapping windows",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 1-3 * * *", CaptureDuration: &metav1.Duration{Duration: 90 * time.Minute}},
+			now:        at(16, 1, 45),
+			wantIn:     true,
+			wantStart:  at(16, 1, 0),
+			wantChange: at(16, 2, 30),
+		},
+		{
+			name:       "overlapping window end",
+			spec:       &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "0 1-3 * * *", CaptureDuration: &metav1.Duration{Duration: 90 * time.Minute}},
+			now:        at(16, 2, 30),
+			wantIn:     true,
+			wantStart:  at(16, 2, 0),
+			wantChange: at(16, 3, 30),
+		},
+		{
+			name:    "invalid schedule",
+			spec:    &v1alpha1.SQLPlanManagementSpec{CaptureSchedule: "nightly"},
+			now:     at(16, 1, 0),
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in, start, change, err := sqlPlanCaptureWindow(tc.spec, tc.now)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("sqlPlanCaptureWindow got error %v, want error: %v", err, tc.wantErr)
+			}
+			if in != tc.wantIn || !start.Equal(tc.wantStart) || !change.Equal(tc.wantChange) {
+				t.Errorf("sqlPlanCaptureWindow got (%v, %v, %v), want (%v, %v, %v)", in, start, change, tc.wantIn, tc.wantStart, tc.wantChange)
+			}
+		})
+	}
+}
+
+func TestReconcileSQLPlanManagement(t *testing.T) {
+	// A window from the previous minute for a day is always open.
+	open := &v1alpha1.SQLPlanManagementSpec{
+		CaptureSchedule: "* * * * *",
+		CaptureDuration: &metav1.Duration{Duration: 24 * time.Hour},
+	}
+	closed := &v1alpha1.SQLPlanManagementSpec{
+		CaptureSchedule: "0 0 1 1 *",
+		CaptureDuration: &metav1.Duration{Duration: time.Minute},
+	}
+	lastCapture := time.Now().Add(-time.Minute).Truncate(time.Second)
+	tests := []struct {
+		name        string
+		spec        *v1alpha1.SQLPlanManagementSpec
+		status      *v1alpha1.SQLPlanManagementStatus
+		changed     bool
+		err         error
+		wantErr     bool
+		wantSetCnt  int
+		wantEnabled bool
+		wantEvents  int
+		wantStatus  bool
+	}{
+		{
+			name: "not managed",
+		},
+		{
+			name:        "window opens",
+			spec:        open,
+			changed:     true,
+			wantSetCnt:  1,
+			wantEnabled: true,
+			wantEvents:  1,
+			wantStatus:  true,
+		},
+		{
+			name:        "within the window",
+			spec:        open,
+			status:      &v1alpha1.SQLPlanManagementStatus{CaptureEnabled: true},
+			wantSetCnt:  1,
+			wantEnabled: true,
+			wantStatus:  true,
+		},
+		{
+			name:       "window closes",
+			spec:       closed,
+			status:     &v1alpha1.SQLPlanManagementStatus{CaptureEnabled: true},
+			changed:    true,
+			wantSetCnt: 1,
+			wantEvents: 1,
+			wantStatus: true,
+		},
+		{
+			name:       "spec removed during the window",
+			status:     &v1alpha1.SQLPlanManagementStatus{CaptureEnabled: true},
+			wantSetCnt: 1,
+			wantEvents: 1,
+		},
+		{
+			name:       "failed",
+			spec:       open,
+			err:        errors.New("ORA-02097: parameter cannot be modified"),
+			wantErr:    true,
+			wantSetCnt: 1,
+			wantEvents: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			factory.Dbclient.SetMethodToResp("SetSQLPlanCapture", &dbdpb.SetSQLPlanCaptureResponse{Changed: tc.changed})
+			factory.Dbclient.SetMethodToResp("GetSQLPlanBaselines", &dbdpb.GetSQLPlanBaselinesResponse{
+				CaptureEnabled:        tc.wantEnabled,
+				Baselines:             5,
+				AcceptedBaselines:     3,
+				AutoCapturedBaselines: 4,
+				CapturedSinceCount:    2,
+				LastCaptureTime:       timestamppb.New(lastCapture),
+			})
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("SetSQLPlanCapture", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				Spec:   v1alpha1.InstanceSpec{SQLPlanManagement: tc.spec},
+				Status: v1alpha1.InstanceStatus{SQLPlanManagement: tc.status},
+			}
+
+			result, err := r.reconcileSQLPlanManagement(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileSQLPlanManagement got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.SetSQLPlanCaptureCalledCnt(); got != tc.wantSetCnt {
+				t.Errorf("reconcileSQLPlanManagement called SetSQLPlanCapture %d times, want %d", got, tc.wantSetCnt)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileSQLPlanManagement emitted %d events, want %d", got, tc.wantEvents)
+			}
+			if tc.wantErr {
+				return
+			}
+			status := inst.Status.SQLPlanManagement
+			if !tc.wantStatus {
+				if status != nil {
+					t.Errorf("reconcileSQLPlanManagement got status %+v, want none", status)
+				}
+				return
+			}
+			if status == nil {
+				t.Fatalf("reconcileSQLPlanManagement didn't set the SQL plan management status")
+			}
+			if status.CaptureEnabled != tc.wantEnabled {
+				t.Errorf("reconcileSQLPlanManagement got capture enabled %v, want %v", status.CaptureEnabled, tc.wantEnabled)
+			}
+			if result.RequeueAfter <= 0 || status.NextChangeTime == nil {
+				t.Errorf("reconcileSQLPlanManagement got requeue after %v and next change %v, want the next window change", result.RequeueAfter, status.NextChangeTime)
+			}
+			if status.Baselines != 5 || status.AcceptedBaselines != 3 || status.AutoCapturedBaselines != 4 {
+				t.Errorf("reconcileSQLPlanManagement got status %+v, want 5 baselines, 3 accepted and 4 auto captured", status)
+			}
+			if status.LastCaptureTime == nil || !status.LastCaptureTime.Time.Equal(lastCapture) {
+				t.Errorf("reconcileSQLPlanManagement got last capture %v, want %v", status.LastCaptureTime, lastCapture)
+			}
+			if tc.wantEnabled {
+				since := factory.Dbclient.GotGetSQLPlanBaselinesRequest.GetCapturedSince()
+				if status.WindowStartTime == nil || since == nil || !since.AsTime().Equal(status.WindowStartTime.Time) {
+					t.Errorf("reconcileSQLPlanManagement asked for the baselines captured since %v, want since the window start %v", since, status.WindowStartTime)
+				}
+				if status.LastWindowCapturedBaselines != 2 {
+					t.Errorf("reconcileSQLPlanManagement got %d baselines captured in the window, want 2", status.LastWindowCapturedBaselines)
+				}
+			}
+		})
+	}
+}
@@ -220,20 +220,34 @@ func (r *InstanceReconciler) standbyStateMachine(ctx context.Context, inst *v1al
 				"promote standby completed")
 			return ctrl.Result{Requeue: true}, nil
 		}
+		// The observer runs before Data Guard is reconciled, which fails
+		// while the primary is lost.
+		if transitioned, err := r.reconcileObserver(ctx, inst, log); err != nil {
+			log.Error(err, "failed to observe the primary")
+		} else if transitioned {
+			r.updateStandbyDataReplicationStatus(ctx,
+				inst, metav1.ConditionFalse,
+				k8s.StandbyDRPromoteCompleted,
+				"role transition to the standby completed")
+			return ctrl.Result{Requeue: true}, nil
+		}
 		if err := r.reconcileDataGuard(ctx, inst); err != nil {
 			r.updateStandbyDataReplicationStatus(ctx,
 				inst, metav1.ConditionFalse,
 				k8s.StandbyDRDataGuardReplicationInProgress,
 				"Data Guard data replication in progress with errors", internalErrToMsg(err))
 			r.updateDataGuardStatus(ctx, inst, standbyErrorRetryInterval, log)
-			return ctrl.Result{RequeueAfter: standbyErrorRetryInterval}, nil
+			return ctrl.Result{RequeueAfter: standbyRequeueInterval(inst, standbyErrorRetryInterval)}, nil
+		}
+		if err := r.reconcileDRDrill(ctx, inst, log); err != nil {
+			log.Error(err, "failed to run the DR drill")
 		}
 		r.updateStandbyDataReplicationStatus(ctx,
 			inst, metav1.ConditionFalse,
 			k8s.StandbyDRDataGuardReplicationInProgress,
 			"Data Guard data replication in progress")
 		r.updateDataGuardStatus(ctx, inst, StandbyReconcileInterval, log)
-		return ctrl.Result{RequeueAfter: StandbyReconcileInterval}, nil
+		return ctrl.Result{RequeueAfter: standbyRequeueInterval(inst, StandbyReconcileInterval)}, nil
 
 	case k8s.StandbyDRPromoteFailed:
 		if err := r.reconcilePromoteStandby(ctx, inst, log); err != nil {
@@ -362,7 +376,7 @@ func (r *InstanceReconciler) reconcileDataGuard(ctx context.Context, inst *v1alp
 		return err
 	}
 
-	if err := controllers.SetUpDataGuard(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.SetUpDataGuardRequest{
+	req := controllers.SetUpDataGuardRequest{
 		PrimaryHost:         inst.Spec.ReplicationSettings.PrimaryHost,
 		PrimaryPort:         inst.Spec.ReplicationSettings.PrimaryPort,
 		PrimaryService:      inst.Spec.ReplicationSettings.PrimaryServiceName,
@@ -371,10 +385,24 @@ func (r *InstanceReconciler) reconcileDataGuard(ctx context.Context, inst *v1alp
 		StandbyDbUniqueName: inst.Spec.DBUniqueName,
 		StandbyHost:         standbyHost,
 		PasswordFileGcsPath: inst.Spec.ReplicationSettings.PasswordFileURI,
-	}); err != nil {
+	}
+	if inst.Spec.DataGuard != nil {
+		req.RedoCompression = inst.Spec.DataGuard.RedoCompression
+		req.RedoEncryption = inst.Spec.DataGuard.RedoEncryption
+	}
+	if err := controllers.SetUpDataGuard(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, req); err != nil {
 		return err
 	}
 	inst.Status.CurrentReplicationSettings = inst.Spec.ReplicationSettings
+
+	if inst.Spec.DataGuard != nil && inst.Spec.DataGuard.ProtectionMode != "" {
+		if err := controllers.SetProtectionMode(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.SetProtectionModeRequest{
+			StandbyDbUniqueName: inst.Spec.DBUniqueName,
+			ProtectionMode:      inst.Spec.DataGuard.ProtectionMode,
+		}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
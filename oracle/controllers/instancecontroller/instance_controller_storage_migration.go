@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// storageMigrationRetryInterval is how long to wait before retrying a
+// failed storage migration step.
+const storageMigrationRetryInterval = 30 * time.Second
+
+// reconcileStorageMigration prepares the instance for a storage migration
+// while spec.prepareForStorageMigration is set, and completes the migration
+// once it is unset. The StorageMigrationReady condition is first recorded as
+// in progress, so that a migration interrupted by an operator restart is
+// still completed. Both RPCs are idempotent and the dbdaemon records the
+// PDBs it reopened read only, so an interrupted step is resumed on the next
+// reconcile.
+func (r *InstanceReconciler) reconcileStorageMigration(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	cond := k8s.FindCondition(inst.Status.Conditions, k8s.StorageMigrationReady)
+	if !inst.Spec.PrepareForStorageMigration {
+		if cond == nil || cond.Reason == k8s.StorageMigrationCompleted {
+			return ctrl.Result{}, nil
+		}
+		return r.completeStorageMigration(ctx, inst, log)
+	}
+	switch {
+	case k8s.ConditionStatusEquals(cond, v1.ConditionTrue):
+		return ctrl.Result{}, nil
+	case cond == nil || cond.Reason == k8s.StorageMigrationCompleted:
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionFalse, k8s.StorageMigrationInProgress, "Preparing for storage migration")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return r.prepareForStorageMigration(ctx, inst, log)
+}
+
+func (r *InstanceReconciler) prepareForStorageMigration(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.PrepareForStorageMigration(ctx, &dbdpb.PrepareForStorageMigrationRequest{})
+	if err != nil {
+		msg := fmt.Sprintf("Failed to prepare for storage migration: %v", err)
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionFalse, k8s.StorageMigrationFailed, msg)
+		r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.StorageMigrationFailed, msg)
+		return ctrl.Result{RequeueAfter: storageMigrationRetryInterval}, err
+	}
+	inst.Status.StorageMigrationPDBs = resp.GetPdbs()
+	msg := fmt.Sprintf("Ready for storage migration, PDBs reopened read only: %s", strings.Join(resp.GetPdbs(), ", "))
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionTrue, k8s.StorageMigrationPrepared, msg)
+	r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.StorageMigrationPrepared, msg)
+	log.Info("prepared for storage migration", "pdbs", resp.GetPdbs())
+	return ctrl.Result{}, nil
+}
+
+func (r *InstanceReconciler) completeStorageMigration(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	resp, err := dbClient.CompleteStorageMigration(ctx, &dbdpb.CompleteStorageMigrationRequest{})
+	if err != nil {
+		msg := fmt.Sprintf("Failed to complete storage migration: %v", err)
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionFalse, k8s.StorageMigrationFailed, msg)
+		r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.StorageMigrationFailed, msg)
+		return ctrl.Result{RequeueAfter: storageMigrationRetryInterval}, err
+	}
+	inst.Status.StorageMigrationPDBs = nil
+	msg := fmt.Sprintf("Storage migration completed, PDBs reopened read write: %s", strings.Join(resp.GetPdbs(), ", "))
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionFalse, k8s.StorageMigrationCompleted, msg)
+	r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.StorageMigrationCompleted, msg)
+	log.Info("completed storage migration", "pdbs", resp.GetPdbs())
+	return ctrl.Result{}, nil
+}
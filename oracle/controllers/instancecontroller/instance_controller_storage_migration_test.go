@@ -0,0 +1,182 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestReconcileStorageMigration(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("PrepareForStorageMigration", &dbdpb.PrepareForStorageMigrationResponse{Pdbs: []string{"PDB1", "PDB2"}})
+	factory.Dbclient.SetMethodToResp("CompleteStorageMigration", &dbdpb.CompleteStorageMigrationResponse{Pdbs: []string{"PDB1", "PDB2"}})
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	inst := &v1alpha1.Instance{}
+
+	steps := []struct {
+		name         string
+		prepare      bool
+		prepareErr   error
+		wantReason   string
+		wantStatus   v1.ConditionStatus
+		wantPDBs     []string
+		wantPrepares int
+		wantComplete int
+	}{
+		{
+			name: "no migration",
+		},
+		{
+			name:       "migration requested",
+			prepare:    true,
+			wantReason: k8s.StorageMigrationInProgress,
+			wantStatus: v1.ConditionFalse,
+		},
+		{
+			name:         "prepare failed",
+			prepare:      true,
+			prepareErr:   errors.New("ORA-65019"),
+			wantReason:   k8s.StorageMigrationFailed,
+			wantStatus:   v1.ConditionFalse,
+			wantPrepares: 1,
+		},
+		{
+			name:         "prepare retried",
+			prepare:      true,
+			wantReason:   k8s.StorageMigrationPrepared,
+			wantStatus:   v1.ConditionTrue,
+			wantPDBs:     []string{"PDB1", "PDB2"},
+			wantPrepares: 2,
+		},
+		{
+			name:         "prepared",
+			prepare:      true,
+			wantReason:   k8s.StorageMigrationPrepared,
+			wantStatus:   v1.ConditionTrue,
+			wantPDBs:     []string{"PDB1", "PDB2"},
+			wantPrepares: 2,
+		},
+		{
+			name:         "migration completed",
+			wantReason:   k8s.StorageMigrationCompleted,
+			wantStatus:   v1.ConditionFalse,
+			wantPrepares: 2,
+			wantComplete: 1,
+		},
+		{
+			name:         "completed",
+			wantReason:   k8s.StorageMigrationCompleted,
+			wantStatus:   v1.ConditionFalse,
+			wantPrepares: 2,
+			wantComplete: 1,
+		},
+	}
+	for _, step := range steps {
+		inst.Spec.PrepareForStorageMigration = step.prepare
+		factory.Dbclient.RemoveMethodToError("PrepareForStorageMigration")
+		if step.prepareErr != nil {
+			factory.Dbclient.SetMethodToError("PrepareForStorageMigration", step.prepareErr)
+		}
+		_, err := r.reconcileStorageMigration(context.Background(), inst, logr.Discard())
+		if gotErr := err != nil; gotErr != (step.prepareErr != nil) {
+			t.Fatalf("%s: reconcileStorageMigration got error %v, want error %v", step.name, err, step.prepareErr)
+		}
+		cond := k8s.FindCondition(inst.Status.Conditions, k8s.StorageMigrationReady)
+		if step.wantReason == "" {
+			if cond != nil {
+				t.Errorf("%s: reconcileStorageMigration set condition %v, want none", step.name, cond)
+			}
+		} else if cond == nil || cond.Reason != step.wantReason || cond.Status != step.wantStatus {
+			t.Errorf("%s: reconcileStorageMigration set condition %v, want %s %s", step.name, cond, step.wantStatus, step.wantReason)
+		}
+		if diff := cmp.Diff(step.wantPDBs, inst.Status.StorageMigrationPDBs); diff != "" {
+			t.Errorf("%s: reconcileStorageMigration got unexpected PDBs (-want +got):\n%v", step.name, diff)
+		}
+		if got := factory.Dbclient.PrepareForStorageMigrationCalledCnt(); got != step.wantPrepares {
+			t.Errorf("%s: PrepareForStorageMigration called %d times, want %d", step.name, got, step.wantPrepares)
+		}
+		if got := factory.Dbclient.CompleteStorageMigrationCalledCnt(); got != step.wantComplete {
+			t.Errorf("%s: CompleteStorageMigration called %d times, want %d", step.name, got, step.wantComplete)
+		}
+	}
+}
+
+// TestReconcileStorageMigrationResume checks that a migration is completed
+// after an operator restart lost the result of the prepare step.
+func TestReconcileStorageMigrationResume(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+
+	for _, tc := range []struct {
+		name         string
+		prepare      bool
+		wantPrepares int
+		wantComplete int
+		wantReason   string
+	}{
+		{
+			name:         "still requested",
+			prepare:      true,
+			wantPrepares: 1,
+			wantReason:   k8s.StorageMigrationPrepared,
+		},
+		{
+			name:         "no longer requested",
+			wantComplete: 1,
+			wantReason:   k8s.StorageMigrationCompleted,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.Dbclient.Reset()
+			factory.Dbclient.SetMethodToResp("PrepareForStorageMigration", &dbdpb.PrepareForStorageMigrationResponse{Pdbs: []string{"PDB1"}})
+			inst := &v1alpha1.Instance{}
+			inst.Spec.PrepareForStorageMigration = tc.prepare
+			k8s.InstanceUpsertCondition(&inst.Status, k8s.StorageMigrationReady, v1.ConditionFalse, k8s.StorageMigrationInProgress, "Preparing for storage migration")
+
+			if _, err := r.reconcileStorageMigration(context.Background(), inst, logr.Discard()); err != nil {
+				t.Fatalf("reconcileStorageMigration failed: %v", err)
+			}
+			if got := factory.Dbclient.PrepareForStorageMigrationCalledCnt(); got != tc.wantPrepares {
+				t.Errorf("PrepareForStorageMigration called %d times, want %d", got, tc.wantPrepares)
+			}
+			if got := factory.Dbclient.CompleteStorageMigrationCalledCnt(); got != tc.wantComplete {
+				t.Errorf("CompleteStorageMigration called %d times, want %d", got, tc.wantComplete)
+			}
+			if cond := k8s.FindCondition(inst.Status.Conditions, k8s.StorageMigrationReady); cond == nil || cond.Reason != tc.wantReason {
+				t.Errorf("reconcileStorageMigration set condition %v, want %s", cond, tc.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/security"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+// walletPasswordSecret returns the name of the secret referenced by ref,
+// e.g. projects/p/secrets/s.
+func walletPasswordSecret(ref *commonv1alpha1.GsmSecretReference) string {
+	return fmt.Sprintf("projects/%s/secrets/%s", ref.ProjectId, ref.SecretId)
+}
+
+// walletPasswordRef returns the reference to the secret version holding the
+// current keystore password, the one recorded by the last rotation of the
+// referenced secret or else the referenced one.
+func walletPasswordRef(inst *v1alpha1.Instance) controllers.GsmSecretReference {
+	ref := inst.Spec.TDE.WalletPasswordGsmSecretRef
+	current := controllers.GsmSecretReference{ProjectId: ref.ProjectId, SecretId: ref.SecretId, Version: ref.Version}
+	if version := strings.TrimPrefix(inst.Status.WalletPasswordVersion, walletPasswordSecret(ref)+"/versions/"); version != inst.Status.WalletPasswordVersion {
+		current.Version = version
+	}
+	return current
+}
+
+// walletPasswordRotationDue returns true if the keystore password is due
+// for rotation at now.
+func walletPasswordRotationDue(inst *v1alpha1.Instance, now time.Time) bool {
+	days := inst.Spec.TDE.WalletPasswordRotationDays
+	last := inst.Status.WalletPasswordRotationTime
+	return days > 0 && last != nil && !now.Before(last.Add(time.Duration(days)*24*time.Hour))
+}
+
+// reconcileWalletPassword tracks the GSM secret version holding the TDE
+// keystore password and rotates the password every
+// spec.tde.walletPasswordRotationDays. The rotation clock starts when the
+// operator first sees the secret. Standby instances are skipped, their
+// keystore is a copy of the primary one and gets its password from it.
+func (r *InstanceReconciler) reconcileWalletPassword(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	if inst.Spec.TDE == nil || inst.Spec.TDE.WalletPasswordGsmSecretRef == nil {
+		if inst.Spec.TDE != nil && inst.Spec.TDE.WalletPasswordRotationDays > 0 {
+			return fmt.Errorf("spec.tde.walletPasswordRotationDays requires spec.tde.walletPasswordGsmSecretRef")
+		}
+		inst.Status.WalletPasswordVersion = ""
+		inst.Status.WalletPasswordRotationTime = nil
+		return nil
+	}
+	ref := walletPasswordRef(inst)
+	if version := fmt.Sprintf("%s/versions/%s", walletPasswordSecret(inst.Spec.TDE.WalletPasswordGsmSecretRef), ref.Version); inst.Status.WalletPasswordVersion != version {
+		inst.Status.WalletPasswordVersion = version
+		inst.Status.WalletPasswordRotationTime = &v1.Time{Time: time.Now()}
+	}
+	if isStandbyDR(inst) || !walletPasswordRotationDue(inst, time.Now()) {
+		return nil
+	}
+
+	password, err := security.RandOraclePassword()
+	if err != nil {
+		return fmt.Errorf("failed to generate a keystore password: %v", err)
+	}
+	version, err := controllers.RotateWalletPassword(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name, controllers.RotateWalletPasswordRequest{
+		PasswordRef: ref,
+		NewPassword: password,
+	})
+	if err != nil {
+		r.Recorder.Eventf(inst, corev1.EventTypeWarning, k8s.WalletPasswordRotationFailed, "Failed to rotate the TDE keystore password: %v", err)
+		return err
+	}
+	log.Info("rotated the TDE keystore password", "version", version)
+	r.Recorder.Eventf(inst, corev1.EventTypeNormal, k8s.WalletPasswordRotated, "TDE keystore password rotated, the new password is in %s", version)
+	inst.Status.WalletPasswordVersion = version
+	inst.Status.WalletPasswordRotationTime = &v1.Time{Time: time.Now()}
+	return nil
+}
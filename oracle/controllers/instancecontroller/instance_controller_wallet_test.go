@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+)
+
+func TestReconcileWalletPassword(t *testing.T) {
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	r := &InstanceReconciler{
+		Recorder:              record.NewFakeRecorder(10),
+		DatabaseClientFactory: factory,
+	}
+	var accessed []string
+	access, addVersion := controllers.AccessSecretVersionFunc, controllers.AddSecretVersionFunc
+	defer func() {
+		controllers.AccessSecretVersionFunc, controllers.AddSecretVersionFunc = access, addVersion
+	}()
+	controllers.AccessSecretVersionFunc = func(ctx context.Context, name string) (string, error) {
+		accessed = append(accessed, name)
+		return "Old_pw1", nil
+	}
+	controllers.AddSecretVersionFunc = func(ctx context.Context, secret, payload string) (string, error) {
+		return secret + "/versions/4", nil
+	}
+	inst := &v1alpha1.Instance{
+		Spec: v1alpha1.InstanceSpec{
+			TDE: &v1alpha1.TDESpec{
+				WalletPasswordGsmSecretRef: &commonv1alpha1.GsmSecretReference{ProjectId: "p", SecretId: "tde-password", Version: "latest"},
+				WalletPasswordRotationDays: 30,
+			},
+		},
+	}
+
+	steps := []struct {
+		name        string
+		update      func()
+		wantVersion string
+		wantRotated int
+	}{
+		{
+			name:        "first reconcile starts the rotation clock",
+			update:      func() {},
+			wantVersion: "projects/p/secrets/tde-password/versions/latest",
+		},
+		{
+			name: "password not due is not rotated",
+			update: func() {
+				inst.Status.WalletPasswordRotationTime = &metav1.Time{Time: time.Now().Add(-29 * 24 * time.Hour)}
+			},
+			wantVersion: "projects/p/secrets/tde-password/versions/latest",
+		},
+		{
+			name: "standby password is not rotated",
+			update: func() {
+				inst.Spec.ReplicationSettings = &v1alpha1.ReplicationSettings{}
+				inst.Status.WalletPasswordRotationTime = &metav1.Time{Time: time.Now().Add(-31 * 24 * time.Hour)}
+			},
+			wantVersion: "projects/p/secrets/tde-password/versions/latest",
+		},
+		{
+			name:        "password due is rotated",
+			update:      func() { inst.Spec.ReplicationSettings = nil },
+			wantVersion: "projects/p/secrets/tde-password/versions/4",
+			wantRotated: 1,
+		},
+		{
+			name:        "rotated password is tracked",
+			update:      func() {},
+			wantVersion: "projects/p/secrets/tde-password/versions/4",
+			wantRotated: 1,
+		},
+		{
+			name: "rotated password is rotated from the version added",
+			update: func() {
+				inst.Status.WalletPasswordRotationTime = &metav1.Time{Time: time.Now().Add(-31 * 24 * time.Hour)}
+			},
+			wantVersion: "projects/p/secrets/tde-password/versions/4",
+			wantRotated: 2,
+		},
+		{
+			name: "new secret starts over",
+			update: func() {
+				inst.Spec.TDE.WalletPasswordGsmSecretRef = &commonv1alpha1.GsmSecretReference{ProjectId: "p", SecretId: "tde-password-2", Version: "1"}
+			},
+			wantVersion: "projects/p/secrets/tde-password-2/versions/1",
+			wantRotated: 2,
+		},
+	}
+	for _, step := range steps {
+		step.update()
+		if err := r.reconcileWalletPassword(context.Background(), inst, logr.Discard()); err != nil {
+			t.Fatalf("%s: reconcileWalletPassword failed: %v", step.name, err)
+		}
+		if got := inst.Status.WalletPasswordVersion; got != step.wantVersion {
+			t.Errorf("%s: reconcileWalletPassword got wallet password version %q, want %q", step.name, got, step.wantVersion)
+		}
+		if got := factory.Dbclient.RotateWalletPasswordCalledCnt(); got != step.wantRotated {
+			t.Errorf("%s: RotateWalletPassword called %d times, want %d", step.name, got, step.wantRotated)
+		}
+	}
+	wantAccessed := []string{
+		"projects/p/secrets/tde-password/versions/latest",
+		"projects/p/secrets/tde-password/versions/4",
+	}
+	if diff := cmp.Diff(wantAccessed, accessed); diff != "" {
+		t.Errorf("reconcileWalletPassword read unexpected secret versions (-want +got):\n%v", diff)
+	}
+
+	inst.Spec.TDE = &v1alpha1.TDESpec{WalletPasswordRotationDays: 30}
+	if err := r.reconcileWalletPassword(context.Background(), inst, logr.Discard()); err == nil {
+		t.Errorf("reconcileWalletPassword without a secret succeeded, want error")
+	}
+}
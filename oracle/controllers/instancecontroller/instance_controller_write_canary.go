@@ -0,0 +1,114 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+const (
+	defaultWriteCanaryInterval         = time.Minute
+	defaultWriteCanaryLatencyThreshold = 5 * time.Second
+)
+
+// writeCanarySettings returns the interval and latency threshold of spec,
+// or their defaults if not set.
+func writeCanarySettings(spec *v1alpha1.WriteCanarySpec) (time.Duration, time.Duration) {
+	interval, threshold := defaultWriteCanaryInterval, defaultWriteCanaryLatencyThreshold
+	if spec.Interval != nil && spec.Interval.Duration > 0 {
+		interval = spec.Interval.Duration
+	}
+	if spec.LatencyThreshold != nil && spec.LatencyThreshold.Duration > 0 {
+		threshold = spec.LatencyThreshold.Duration
+	}
+	return interval, threshold
+}
+
+// reconcileWriteCanary runs the write canary every spec.writeCanary.interval
+// and reports whether the database accepts writes in time as the
+// DatabaseWritable condition. Events are only emitted when the condition
+// reason changes.
+func (r *InstanceReconciler) reconcileWriteCanary(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) (ctrl.Result, error) {
+	spec := inst.Spec.WriteCanary
+	if spec == nil {
+		inst.Status.WriteCanary = nil
+		inst.Status.Conditions = k8s.Remove(inst.Status.Conditions, k8s.DatabaseWritable)
+		return ctrl.Result{}, nil
+	}
+
+	interval, threshold := writeCanarySettings(spec)
+	now := time.Now()
+	if status := inst.Status.WriteCanary; status != nil && status.LastRunTime != nil && now.Sub(status.LastRunTime.Time) < interval {
+		return ctrl.Result{RequeueAfter: interval - now.Sub(status.LastRunTime.Time)}, nil
+	}
+
+	dbClient, closeConn, err := r.DatabaseClientFactory.New(ctx, r, inst.GetNamespace(), inst.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer closeConn()
+
+	status := &v1alpha1.WriteCanaryStatus{}
+	if inst.Status.WriteCanary != nil {
+		status = inst.Status.WriteCanary.DeepCopy()
+	}
+	run := v1.NewTime(now)
+	status.LastRunTime = &run
+	inst.Status.WriteCanary = status
+	result := ctrl.Result{RequeueAfter: interval}
+	prev := k8s.FindCondition(inst.Status.Conditions, k8s.DatabaseWritable)
+
+	resp, err := dbClient.RunWriteCanary(ctx, &dbdpb.RunWriteCanaryRequest{})
+	if err != nil {
+		msg := fmt.Sprintf("Failed to write to the database: %v", err)
+		if !k8s.ConditionReasonEquals(prev, k8s.WriteCanaryFailed) {
+			r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.WriteCanaryFailed, msg)
+		}
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseWritable, v1.ConditionFalse, k8s.WriteCanaryFailed, msg)
+		return result, err
+	}
+
+	writeLatency := time.Duration(resp.GetWriteSeconds() * float64(time.Second)).Round(time.Millisecond)
+	readLatency := time.Duration(resp.GetReadSeconds() * float64(time.Second)).Round(time.Millisecond)
+	status.LastSuccessTime = &run
+	status.WriteLatency = &v1.Duration{Duration: writeLatency}
+	status.ReadLatency = &v1.Duration{Duration: readLatency}
+	if writeLatency > threshold {
+		msg := fmt.Sprintf("Write took %v, more than the %v threshold", writeLatency, threshold)
+		if !k8s.ConditionReasonEquals(prev, k8s.WriteCanarySlow) {
+			log.Info("slow database write", "writeLatency", writeLatency, "threshold", threshold)
+			r.Recorder.Event(inst, corev1.EventTypeWarning, k8s.WriteCanarySlow, msg)
+		}
+		k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseWritable, v1.ConditionTrue, k8s.WriteCanarySlow, msg)
+		return result, nil
+	}
+	msg := fmt.Sprintf("Write took %v and read took %v", writeLatency, readLatency)
+	if prev != nil && !k8s.ConditionReasonEquals(prev, k8s.WriteCanarySucceeded) {
+		r.Recorder.Event(inst, corev1.EventTypeNormal, k8s.WriteCanarySucceeded, msg)
+	}
+	k8s.InstanceUpsertCondition(&inst.Status, k8s.DatabaseWritable, v1.ConditionTrue, k8s.WriteCanarySucceeded, msg)
+	return result, nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+)
+
+func TestReconcileWriteCanary(t *testing.T) {
+	spec := &v1alpha1.WriteCanarySpec{LatencyThreshold: &metav1.Duration{Duration: time.Second}}
+	justRun := metav1.NewTime(time.Now())
+	writable := func(reason string) []metav1.Condition {
+		return []metav1.Condition{{Type: k8s.DatabaseWritable, Status: metav1.ConditionTrue, Reason: reason}}
+	}
+	tests := []struct {
+		name            string
+		spec            *v1alpha1.WriteCanarySpec
+		status          *v1alpha1.WriteCanaryStatus
+		conditions      []metav1.Condition
+		resp            *dbdpb.RunWriteCanaryResponse
+		err             error
+		wantErr         bool
+		wantRunCnt      int
+		wantEvents      int
+		wantStatus      metav1.ConditionStatus
+		wantReason      string
+		wantWriteMillis int64
+	}{
+		{
+			name:       "disabled",
+			conditions: writable(k8s.WriteCanarySucceeded),
+		},
+		{
+			name:            "first run",
+			spec:            spec,
+			resp:            &dbdpb.RunWriteCanaryResponse{WriteSeconds: 0.012, ReadSeconds: 0.003},
+			wantRunCnt:      1,
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      k8s.WriteCanarySucceeded,
+			wantWriteMillis: 12,
+		},
+		{
+			name:       "not due",
+			spec:       spec,
+			status:     &v1alpha1.WriteCanaryStatus{LastRunTime: &justRun},
+			conditions: writable(k8s.WriteCanarySucceeded),
+			wantStatus: metav1.ConditionTrue,
+			wantReason: k8s.WriteCanarySucceeded,
+		},
+		{
+			name:            "slow write",
+			spec:            spec,
+			conditions:      writable(k8s.WriteCanarySucceeded),
+			resp:            &dbdpb.RunWriteCanaryResponse{WriteSeconds: 2.5},
+			wantRunCnt:      1,
+			wantEvents:      1,
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      k8s.WriteCanarySlow,
+			wantWriteMillis: 2500,
+		},
+		{
+			name:       "write failed",
+			spec:       spec,
+			conditions: writable(k8s.WriteCanarySucceeded),
+			err:        errors.New("ORA-16000: database or pluggable database open for read-only access"),
+			wantErr:    true,
+			wantRunCnt: 1,
+			wantEvents: 1,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.WriteCanaryFailed,
+		},
+		{
+			name:       "still failing",
+			spec:       spec,
+			conditions: []metav1.Condition{{Type: k8s.DatabaseWritable, Status: metav1.ConditionFalse, Reason: k8s.WriteCanaryFailed}},
+			err:        errors.New("ORA-00257: Archiver error"),
+			wantErr:    true,
+			wantRunCnt: 1,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: k8s.WriteCanaryFailed,
+		},
+		{
+			name:            "recovered",
+			spec:            spec,
+			conditions:      []metav1.Condition{{Type: k8s.DatabaseWritable, Status: metav1.ConditionFalse, Reason: k8s.WriteCanaryFailed}},
+			resp:            &dbdpb.RunWriteCanaryResponse{WriteSeconds: 0.02},
+			wantRunCnt:      1,
+			wantEvents:      1,
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      k8s.WriteCanarySucceeded,
+			wantWriteMillis: 20,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			factory := &testhelpers.FakeDatabaseClientFactory{}
+			factory.Reset()
+			if tc.resp != nil {
+				factory.Dbclient.SetMethodToResp("RunWriteCanary", tc.resp)
+			}
+			if tc.err != nil {
+				factory.Dbclient.SetMethodToError("RunWriteCanary", tc.err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &InstanceReconciler{
+				Recorder:              recorder,
+				DatabaseClientFactory: factory,
+			}
+			inst := &v1alpha1.Instance{
+				Spec: v1alpha1.InstanceSpec{WriteCanary: tc.spec},
+				Status: v1alpha1.InstanceStatus{
+					InstanceStatus: commonv1alpha1.InstanceStatus{Conditions: tc.conditions},
+					WriteCanary:    tc.status,
+				},
+			}
+
+			result, err := r.reconcileWriteCanary(context.Background(), inst, logr.Discard())
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileWriteCanary got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got := factory.Dbclient.RunWriteCanaryCalledCnt(); got != tc.wantRunCnt {
+				t.Errorf("reconcileWriteCanary called RunWriteCanary %d times, want %d", got, tc.wantRunCnt)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("reconcileWriteCanary emitted %d events, want %d", got, tc.wantEvents)
+			}
+			cond := k8s.FindCondition(inst.Status.Conditions, k8s.DatabaseWritable)
+			if tc.spec == nil {
+				if cond != nil || inst.Status.WriteCanary != nil {
+					t.Errorf("reconcileWriteCanary got condition %v and status %+v, want none", cond, inst.Status.WriteCanary)
+				}
+				return
+			}
+			if result.RequeueAfter <= 0 || result.RequeueAfter > defaultWriteCanaryInterval {
+				t.Errorf("reconcileWriteCanary got requeue after %v, want the next run within %v", result.RequeueAfter, defaultWriteCanaryInterval)
+			}
+			if !k8s.ConditionStatusEquals(cond, tc.wantStatus) || !k8s.ConditionReasonEquals(cond, tc.wantReason) {
+				t.Errorf("reconcileWriteCanary got condition %v, want %s with reason %s", cond, tc.wantStatus, tc.wantReason)
+			}
+			if tc.wantWriteMillis == 0 {
+				return
+			}
+			status := inst.Status.WriteCanary
+			if status == nil || status.LastSuccessTime == nil || status.WriteLatency == nil || status.WriteLatency.Milliseconds() != tc.wantWriteMillis {
+				t.Errorf("reconcileWriteCanary got status %+v, want a success with a %dms write", status, tc.wantWriteMillis)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/pkg/maintenance"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers"
+)
+
+// +kubebuilder:webhook:path=/validate-oracle-db-anthosapis-com-v1alpha1-instance,mutating=false,failurePolicy=fail,sideEffects=None,groups=oracle.db.anthosapis.com,resources=instances,verbs=create;update,versions=v1alpha1,name=vinstance.oracle.db.anthosapis.com,admissionReviewVersions=v1
+
+var parametersPath = field.NewPath("spec", "parameters")
+
+// InstanceValidator rejects Instances whose database parameters the
+// reconciler would fail to set, before they end up in the spfile.
+type InstanceValidator struct{}
+
+// SetupWebhookWithManager registers the validating webhook of Instances.
+func (v *InstanceValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Instance{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates the parameters of a new Instance. They're set
+// while the database is created, static ones don't need a restart.
+func (v *InstanceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	inst, ok := obj.(*v1alpha1.Instance)
+	if !ok {
+		return fmt.Errorf("expected an Instance, got %T", obj)
+	}
+	return invalidInstance(inst, validateParameters(inst, inst.Spec.Parameters))
+}
+
+// ValidateUpdate validates the parameters added or changed by an update of
+// an Instance, so that updates of Instances with parameters set before they
+// were validated don't fail. Changes to static parameters restart the
+// database, they're rejected unless the Instance has a maintenance window.
+func (v *InstanceValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldInst, ok := oldObj.(*v1alpha1.Instance)
+	if !ok {
+		return fmt.Errorf("expected an Instance, got %T", oldObj)
+	}
+	inst, ok := newObj.(*v1alpha1.Instance)
+	if !ok {
+		return fmt.Errorf("expected an Instance, got %T", newObj)
+	}
+	changed := make(map[string]string)
+	for name, value := range inst.Spec.Parameters {
+		if old, ok := oldInst.Spec.Parameters[name]; !ok || old != value {
+			changed[name] = value
+		}
+	}
+	errs := validateParameters(inst, changed)
+	if !maintenance.HasValidTimeRanges(inst.Spec.MaintenanceWindow) {
+		var static []string
+		for name := range changed {
+			if controllers.IsStaticParameter(name) {
+				static = append(static, name)
+			}
+		}
+		sort.Strings(static)
+		for _, name := range static {
+			errs = append(errs, field.Forbidden(parametersPath.Key(name), "static parameters can only be changed with a maintenanceWindow for the database restart"))
+		}
+	}
+	return invalidInstance(inst, errs)
+}
+
+// validateParameters validates the parameters of the instance, the ones
+// unknown to the operator are only logged, the database validates them when
+// the reconciler sets them.
+func validateParameters(inst *v1alpha1.Instance, params map[string]string) field.ErrorList {
+	if unknown := controllers.UnknownParameters(params); len(unknown) > 0 {
+		klog.InfoS("instance webhook: parameters unknown to the operator are left to the database to validate", "namespace", inst.Namespace, "instance", inst.Name, "parameters", unknown)
+	}
+	return controllers.ValidateParameters(params, parametersPath)
+}
+
+// ValidateDelete doesn't validate anything, Instances can always be deleted.
+func (v *InstanceValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// invalidInstance returns the Invalid error of the instance with errs, nil
+// if there are none.
+func invalidInstance(inst *v1alpha1.Instance, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("Instance").GroupKind(), inst.Name, errs)
+}
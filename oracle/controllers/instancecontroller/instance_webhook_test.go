@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instancecontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+)
+
+func TestInstanceValidator(t *testing.T) {
+	start := metav1.NewTime(time.Now())
+	window := &commonv1alpha1.MaintenanceWindowSpec{
+		TimeRanges: []commonv1alpha1.TimeRange{{Start: &start, Duration: &metav1.Duration{Duration: time.Hour}}},
+	}
+	instance := func(params map[string]string, mw *commonv1alpha1.MaintenanceWindowSpec) *v1alpha1.Instance {
+		inst := &v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: "mydb", Namespace: "db"}}
+		inst.Spec.Parameters = params
+		inst.Spec.MaintenanceWindow = mw
+		return inst
+	}
+	tests := []struct {
+		name    string
+		old     *v1alpha1.Instance
+		inst    *v1alpha1.Instance
+		wantErr bool
+	}{
+		{
+			name: "create with a static parameter",
+			inst: instance(map[string]string{"processes": "300"}, nil),
+		},
+		{
+			name:    "create with a reserved parameter",
+			inst:    instance(map[string]string{"db_name": "MYDB"}, nil),
+			wantErr: true,
+		},
+		{
+			name: "update a dynamic parameter",
+			old:  instance(map[string]string{"open_cursors": "300"}, nil),
+			inst: instance(map[string]string{"open_cursors": "500"}, nil),
+		},
+		{
+			name:    "update a static parameter without a maintenance window",
+			old:     instance(map[string]string{"processes": "300"}, nil),
+			inst:    instance(map[string]string{"processes": "500"}, nil),
+			wantErr: true,
+		},
+		{
+			name: "update a static parameter with a maintenance window",
+			old:  instance(map[string]string{"processes": "300"}, nil),
+			inst: instance(map[string]string{"processes": "500"}, window),
+		},
+		{
+			name: "update an unchanged static parameter",
+			old:  instance(map[string]string{"processes": "300"}, nil),
+			inst: instance(map[string]string{"processes": "300", "open_cursors": "500"}, nil),
+		},
+		{
+			name:    "update with a wrong datatype",
+			old:     instance(nil, nil),
+			inst:    instance(map[string]string{"open_cursors": "many"}, nil),
+			wantErr: true,
+		},
+		{
+			name: "create with parameters unknown to the operator",
+			inst: instance(map[string]string{"fal_server": "mydb_stby", "log_archive_dest_2": "SERVICE=mydb_stby"}, nil),
+		},
+		{
+			name: "update keeping parameters set before they were validated",
+			old:  instance(map[string]string{"sql_trace": "TRUE", "open_cursors": "many", "audit_trail": "db"}, nil),
+			inst: instance(map[string]string{"sql_trace": "TRUE", "open_cursors": "many", "audit_trail": "db", "fal_server": "mydb_stby"}, nil),
+		},
+		{
+			name:    "update adding a reserved parameter",
+			old:     instance(map[string]string{"sql_trace": "TRUE"}, nil),
+			inst:    instance(map[string]string{"sql_trace": "TRUE", "db_name": "MYDB"}, nil),
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &InstanceValidator{}
+			var err error
+			if tc.old == nil {
+				err = v.ValidateCreate(context.Background(), tc.inst)
+			} else {
+				err = v.ValidateUpdate(context.Background(), tc.old, tc.inst)
+			}
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("InstanceValidator got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("InstanceValidator got error %v, want an Invalid error", err)
+			}
+		})
+	}
+}
@@ -172,6 +172,10 @@ func (r *InstanceReconciler) createMonitoringDeployment(ctx context.Context, ins
 		if err != nil {
 			return err
 		}
+		dbdaemonTLSSecret, err := r.dbdaemonTLSSecretName(ctx, inst)
+		if err != nil {
+			return err
+		}
 		matchLabels := map[string]string{"instance": inst.Name, "task-type": controllers.MonitorTaskType}
 		deployment.Spec = appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -180,7 +184,7 @@ func (r *InstanceReconciler) createMonitoringDeployment(ctx context.Context, ins
 				MatchLabels: matchLabels,
 			},
 			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType},
-			Template: controllers.MonitoringPodTemplate(inst, monitoringSecret, images),
+			Template: controllers.MonitoringPodTemplate(inst, monitoringSecret, dbdaemonTLSSecret, images),
 		}
 		deployment.Spec.Template.Labels = matchLabels
 		return nil
@@ -609,6 +613,32 @@ func IsPatchingStateMachineEntryCondition(enabledServices map[commonv1alpha1.Ser
 	return false
 }
 
+// oracleStartupRequeueAfter returns the delay before checking again whether
+// Oracle is up, backing off with the time spent in the current Ready state.
+func oracleStartupRequeueAfter(inst *v1alpha1.Instance) time.Duration {
+	return controllers.ProvisioningRequeueAfter(k8s.ElapsedTimeFromLastTransitionTime(k8s.FindCondition(inst.Status.Conditions, k8s.Ready), time.Second))
+}
+
+// databaseProvisioningRequeueAfter returns the delay before checking again
+// whether a database creation or bootstrap LRO is done, backing off with the
+// time spent in the current DatabaseInstanceReady state.
+func databaseProvisioningRequeueAfter(inst *v1alpha1.Instance) time.Duration {
+	return controllers.ProvisioningRequeueAfter(k8s.ElapsedTimeFromLastTransitionTime(k8s.FindCondition(inst.Status.Conditions, k8s.DatabaseInstanceReady), time.Second))
+}
+
+// mergeResults combines the results of independent reconcile steps, taking
+// the shortest non-zero RequeueAfter so no step's requeue is lost.
+func mergeResults(results ...ctrl.Result) ctrl.Result {
+	var merged ctrl.Result
+	for _, res := range results {
+		merged.Requeue = merged.Requeue || res.Requeue
+		if res.RequeueAfter > 0 && (merged.RequeueAfter == 0 || res.RequeueAfter < merged.RequeueAfter) {
+			merged.RequeueAfter = res.RequeueAfter
+		}
+	}
+	return merged
+}
+
 func (r *InstanceReconciler) isOracleUpAndRunning(ctx context.Context, inst *v1alpha1.Instance, namespace string, log logr.Logger) (bool, error) {
 	status, err := CheckStatusInstanceFunc(ctx, r, r.DatabaseClientFactory, inst.Name, inst.Spec.CDBName, inst.Namespace, "", controllers.GetDBDomain(inst), log)
 	if err != nil {
@@ -622,16 +652,55 @@ func (r *InstanceReconciler) isOracleUpAndRunning(ctx context.Context, inst *v1a
 	return true, nil
 }
 
+// updateDatabaseIncarnationStatus records the current database incarnation
+// and the DBID in the instance status.
 func (r *InstanceReconciler) updateDatabaseIncarnationStatus(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
 	incResp, err := controllers.FetchDatabaseIncarnation(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name)
 	if err != nil {
 		return fmt.Errorf("failed to fetch current database incarnation: %v", err)
 	}
 
-	if inst.Status.CurrentDatabaseIncarnation != incResp.Incarnation {
+	incarnationChanged := inst.Status.CurrentDatabaseIncarnation != incResp.Incarnation
+	if incarnationChanged {
 		inst.Status.LastDatabaseIncarnation = inst.Status.CurrentDatabaseIncarnation
 	}
 	inst.Status.CurrentDatabaseIncarnation = incResp.Incarnation
+
+	// The DBID only changes when the database is replaced by a restore,
+	// which also opens a new incarnation.
+	if inst.Status.DBID == "" || incarnationChanged {
+		dbidResp, err := controllers.FetchDBID(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the DBID: %v", err)
+		}
+		inst.Status.DBID = dbidResp.DBID
+	}
+	return nil
+}
+
+// updateInstanceInfoStatus records the state of the running database
+// instance in the instance status.
+func (r *InstanceReconciler) updateInstanceInfoStatus(ctx context.Context, inst *v1alpha1.Instance, log logr.Logger) error {
+	info, err := controllers.FetchInstanceInfo(ctx, r, r.DatabaseClientFactory, inst.Namespace, inst.Name)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the instance info: %v", err)
+	}
+	log.V(1).Info("database instance info", "info", info)
+
+	status := &v1alpha1.DatabaseInstanceInfo{
+		Incarnation:    info.Incarnation,
+		OpenMode:       info.OpenMode,
+		DatabaseRole:   info.DatabaseRole,
+		ProtectionMode: info.ProtectionMode,
+		Version:        info.Version,
+	}
+	if !info.StartupTime.IsZero() {
+		status.StartupTime = &metav1.Time{Time: info.StartupTime}
+	}
+	if info.Uptime > 0 {
+		status.Uptime = &metav1.Duration{Duration: info.Uptime}
+	}
+	inst.Status.InstanceInfo = status
 	return nil
 }
 
@@ -814,10 +883,11 @@ func (r *InstanceReconciler) handleResize(ctx context.Context, inst *v1alpha1.In
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	done, err := tryResizeDisksOf(ctx, r.Client, newSts, log)
+	done, resized, err := tryResizeDisksOf(ctx, r.Client, newSts, log)
 	if err != nil {
 		return ctrl.Result{}, err
 	} else if !done {
+		recordDiskGrowth(inst, newSts, resized)
 		k8s.InstanceUpsertCondition(&inst.Status, k8s.Ready, v1.ConditionFalse, k8s.ResizingInProgress, "Resizing disk")
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 	}
@@ -839,7 +909,11 @@ func (r *InstanceReconciler) handleResize(ctx context.Context, inst *v1alpha1.In
 	return ctrl.Result{}, nil
 }
 
-func tryResizeDisksOf(ctx context.Context, c client.Client, newSts *appsv1.StatefulSet, log logr.Logger) (bool, error) {
+// tryResizeDisksOf expands the PVCs of the disks whose size changed and
+// replaces the statefulset to pick up the new volume claim templates. It
+// returns the volume claim templates of the expanded disks once their PVCs
+// are expanded.
+func tryResizeDisksOf(ctx context.Context, c client.Client, newSts *appsv1.StatefulSet, log logr.Logger) (bool, []*corev1.PersistentVolumeClaim, error) {
 	oldSts := &appsv1.StatefulSet{}
 	key := client.ObjectKeyFromObject(newSts)
 
@@ -848,15 +922,15 @@ func tryResizeDisksOf(ctx context.Context, c client.Client, newSts *appsv1.State
 			// no existing sts, we only delete sts after all PVCs has been resized,
 			// so this is either a new sts or PVC has been resized.
 			// Still return false here so that the request is requeued and sts is recreated in the following cycle.s
-			return false, nil
+			return false, nil, nil
 		}
-		return false,
+		return false, nil,
 			fmt.Errorf("error getting statefulset [%v]: %v", key, err)
 	}
 
 	if !oldSts.DeletionTimestamp.IsZero() {
 		// sts has been requested to delete, let's wait for it to be completely gone
-		return false, nil
+		return false, nil, nil
 	}
 
 	changedDisks := FilterDiskWithSizeChanged(
@@ -867,39 +941,50 @@ func tryResizeDisksOf(ctx context.Context, c client.Client, newSts *appsv1.State
 
 	if len(changedDisks) == 0 {
 		// no disk has size changed
-		return true, nil
+		return true, nil, nil
 	}
 
 	// sanity check: all sc can expand
 	if err := PvcsCanBeExpanded(ctx, c, newSts, changedDisks); err != nil {
-		return false,
+		return false, nil,
 			fmt.Errorf("pvcs may not be expanded")
 	}
 	// actually do the update
-	done, err := resizePvcs(ctx, c, newSts, changedDisks, log)
+	done, restart, err := resizePvcs(ctx, c, newSts, changedDisks, log)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 
 	if !done {
-		return false, nil
+		return false, nil, nil
 	}
 
-	// resize done, now let's delete the sts
-	if err := c.Delete(ctx, oldSts); err != nil {
-		return false,
+	// resize done, now let's delete the sts. The pods are orphaned and
+	// adopted by the recreated sts, so the database keeps running, unless
+	// a filesystem can only be resized once its volume is mounted again.
+	propagation := metav1.DeletePropagationOrphan
+	if restart {
+		log.Info("filesystem resize pending, restarting the database pods")
+		propagation = metav1.DeletePropagationBackground
+	}
+	if err := c.Delete(ctx, oldSts, client.PropagationPolicy(propagation)); err != nil {
+		return false, nil,
 			fmt.Errorf("error while deleting sts [%v]: %v", key, err)
 	}
-	return false, nil
+	return false, changedDisks, nil
 
 }
 
 // resizePvcs resizes the provided PVC templates, and return true if resize has
 // completed; false if it's done but PVC still undergoing resizing; or error if
 // and error occured during the resizing process
+// resizePvcs requests the expansion of the PVCs of the disks. It reports
+// whether all the PVCs are expanded, and whether a pod restart is needed
+// for their filesystems to be resized.
 func resizePvcs(ctx context.Context, c client.Client, sts *appsv1.StatefulSet, disks []*corev1.PersistentVolumeClaim, log logr.Logger,
-) (bool, error) {
+) (bool, bool, error) {
 	done := true
+	restart := false
 	var requested []string
 	var completed []string
 
@@ -911,29 +996,30 @@ func resizePvcs(ctx context.Context, c client.Client, sts *appsv1.StatefulSet, d
 
 			pvc := &corev1.PersistentVolumeClaim{}
 			if err := c.Get(ctx, key, pvc); err != nil {
-				return false,
+				return false, false,
 					fmt.Errorf("error getting pvc [%v]: %v", key, err)
 			}
 
-			if newSize.Equal(*pvc.Spec.Resources.Requests.Storage()) {
-				// spec has been updated before, check status
-				if !newSize.Equal(*pvc.Status.Capacity.Storage()) {
-					// status is not equal, meaning the resizing is still in-progress
-					done = false
-				} else {
-					completed = append(completed, key.String())
-				}
-			} else {
+			if resized := pvcResizeRequest(pvc, newSize); resized != nil {
 				// spec is not updated yet, update it
 				done = false
-				oldCliObj := pvc.DeepCopyObject().(client.Object)
-				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = newSize
-				if err := Patch(ctx, c, pvc, oldCliObj); err != nil {
-					return false,
+				if err := Patch(ctx, c, resized, pvc); err != nil {
+					return false, false,
 						fmt.Errorf("error resizing pvc [%v]: %v", key, err)
-				} else {
-					requested = append(requested, key.String())
 				}
+				requested = append(requested, key.String())
+				continue
+			}
+			// spec has been updated before, check status
+			switch expanded, fsResizePending := pvcResizeState(pvc, newSize); {
+			case fsResizePending:
+				restart = true
+				completed = append(completed, key.String())
+			case expanded:
+				completed = append(completed, key.String())
+			default:
+				// the resizing is still in-progress
+				done = false
 			}
 		}
 	}
@@ -946,7 +1032,7 @@ func resizePvcs(ctx context.Context, c client.Client, sts *appsv1.StatefulSet, d
 				strings.Join(completed, ", ")))
 	}
 
-	return done, nil
+	return done, restart, nil
 }
 
 // IsReadyWithObj returns true if the statefulset has a non-zero number of
@@ -15,11 +15,20 @@
 package instancecontroller
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/testhelpers"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/k8s"
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 func TestPatchingSMEntryCondition(t *testing.T) {
@@ -152,3 +161,70 @@ func TestPatchingSMEntryCondition(t *testing.T) {
 	}
 
 }
+
+func TestMergeResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []ctrl.Result
+		want    ctrl.Result
+	}{
+		{
+			name:    "no requeue",
+			results: []ctrl.Result{{}, {}},
+			want:    ctrl.Result{},
+		},
+		{
+			name:    "shortest requeue wins",
+			results: []ctrl.Result{{RequeueAfter: time.Minute}, {RequeueAfter: 10 * time.Second}},
+			want:    ctrl.Result{RequeueAfter: 10 * time.Second},
+		},
+		{
+			name:    "zero requeue is ignored",
+			results: []ctrl.Result{{}, {RequeueAfter: time.Minute}},
+			want:    ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:    "requeue is kept",
+			results: []ctrl.Result{{Requeue: true}, {RequeueAfter: time.Minute}},
+			want:    ctrl.Result{Requeue: true, RequeueAfter: time.Minute},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mergeResults(tc.results...); got != tc.want {
+				t.Errorf("mergeResults(%v) = %v, want %v", tc.results, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateInstanceInfoStatus(t *testing.T) {
+	startup := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	factory := &testhelpers.FakeDatabaseClientFactory{}
+	factory.Reset()
+	factory.Dbclient.SetMethodToResp("GetInstanceInfo", &dbdpb.GetInstanceInfoResponse{
+		StartupTime:   timestamppb.New(startup),
+		UptimeSeconds: 7200,
+		Incarnation:   "2",
+		OpenMode:      "READ WRITE",
+		DatabaseRole:  "PRIMARY",
+		Version:       "19.3.0.0.0",
+	})
+	r := &InstanceReconciler{DatabaseClientFactory: factory}
+	inst := &v1alpha1.Instance{}
+
+	if err := r.updateInstanceInfoStatus(context.Background(), inst, logr.Discard()); err != nil {
+		t.Fatalf("updateInstanceInfoStatus failed: %v", err)
+	}
+	want := &v1alpha1.DatabaseInstanceInfo{
+		StartupTime:  &v1.Time{Time: startup},
+		Uptime:       &v1.Duration{Duration: 2 * time.Hour},
+		Incarnation:  "2",
+		OpenMode:     "READ WRITE",
+		DatabaseRole: "PRIMARY",
+		Version:      "19.3.0.0.0",
+	}
+	if diff := cmp.Diff(want, inst.Status.InstanceInfo); diff != "" {
+		t.Errorf("updateInstanceInfoStatus got unexpected instance info (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ParameterType is the datatype of a database parameter, as reported in the
+// TYPE column of v$parameter.
+type ParameterType int
+
+const (
+	BooleanParameter    ParameterType = 1
+	StringParameter     ParameterType = 2
+	IntegerParameter    ParameterType = 3
+	BigIntegerParameter ParameterType = 6
+)
+
+// ParameterInfo describes a database parameter known to the operator.
+type ParameterInfo struct {
+	Type ParameterType
+	// Static parameters are only applied after a database restart.
+	Static bool
+}
+
+// bigIntegerValue matches the values of big integer parameters, a number
+// with an optional size unit, e.g. 512M.
+var bigIntegerValue = regexp.MustCompile(`^[0-9]+[KkMmGgTt]?$`)
+
+// KnownParameters holds the database parameters which can be set in the
+// Instance spec, with their datatype and whether they're static.
+var KnownParameters = map[string]ParameterInfo{
+	"archive_lag_target":                   {Type: IntegerParameter},
+	"audit_sys_operations":                 {Type: BooleanParameter, Static: true},
+	"audit_syslog_level":                   {Type: StringParameter, Static: true},
+	"awr_pdb_autoflush_enabled":            {Type: BooleanParameter},
+	"commit_logging":                       {Type: StringParameter},
+	"commit_wait":                          {Type: StringParameter},
+	"control_file_record_keep_time":        {Type: IntegerParameter},
+	"cpu_count":                            {Type: IntegerParameter},
+	"cursor_sharing":                       {Type: StringParameter},
+	"db_big_table_cache_percent_target":    {Type: StringParameter},
+	"db_block_checking":                    {Type: StringParameter},
+	"db_block_checksum":                    {Type: StringParameter},
+	"db_cache_size":                        {Type: BigIntegerParameter},
+	"db_file_multiblock_read_count":        {Type: IntegerParameter},
+	"db_files":                             {Type: IntegerParameter, Static: true},
+	"db_flashback_retention_target":        {Type: IntegerParameter},
+	"db_keep_cache_size":                   {Type: BigIntegerParameter},
+	"db_lost_write_protect":                {Type: StringParameter},
+	"db_recovery_file_dest_size":           {Type: BigIntegerParameter},
+	"db_recycle_cache_size":                {Type: BigIntegerParameter},
+	"db_securefile":                        {Type: StringParameter},
+	"db_writer_processes":                  {Type: IntegerParameter, Static: true},
+	"dbwr_io_slaves":                       {Type: IntegerParameter, Static: true},
+	"ddl_lock_timeout":                     {Type: IntegerParameter},
+	"deferred_segment_creation":            {Type: BooleanParameter},
+	"disk_asynch_io":                       {Type: BooleanParameter, Static: true},
+	"enable_ddl_logging":                   {Type: BooleanParameter},
+	"enable_goldengate_replication":        {Type: BooleanParameter},
+	"event":                                {Type: StringParameter, Static: true},
+	"fast_start_mttr_target":               {Type: IntegerParameter},
+	"fast_start_parallel_rollback":         {Type: StringParameter},
+	"global_names":                         {Type: BooleanParameter},
+	"heat_map":                             {Type: StringParameter},
+	"inmemory_max_populate_servers":        {Type: IntegerParameter},
+	"inmemory_query":                       {Type: StringParameter},
+	"inmemory_size":                        {Type: BigIntegerParameter},
+	"java_jit_enabled":                     {Type: BooleanParameter},
+	"java_pool_size":                       {Type: BigIntegerParameter},
+	"job_queue_processes":                  {Type: IntegerParameter},
+	"large_pool_size":                      {Type: BigIntegerParameter},
+	"lock_sga":                             {Type: BooleanParameter, Static: true},
+	"log_archive_max_processes":            {Type: IntegerParameter},
+	"log_buffer":                           {Type: BigIntegerParameter, Static: true},
+	"log_checkpoint_interval":              {Type: IntegerParameter},
+	"log_checkpoint_timeout":               {Type: IntegerParameter},
+	"max_dump_file_size":                   {Type: StringParameter},
+	"max_idle_time":                        {Type: IntegerParameter},
+	"max_pdbs":                             {Type: IntegerParameter},
+	"max_string_size":                      {Type: StringParameter, Static: true},
+	"memory_max_target":                    {Type: BigIntegerParameter, Static: true},
+	"memory_target":                        {Type: BigIntegerParameter},
+	"nls_date_format":                      {Type: StringParameter, Static: true},
+	"nls_language":                         {Type: StringParameter, Static: true},
+	"nls_length_semantics":                 {Type: StringParameter},
+	"nls_territory":                        {Type: StringParameter, Static: true},
+	"open_cursors":                         {Type: IntegerParameter},
+	"open_links":                           {Type: IntegerParameter, Static: true},
+	"optimizer_adaptive_plans":             {Type: BooleanParameter},
+	"optimizer_adaptive_statistics":        {Type: BooleanParameter},
+	"optimizer_capture_sql_plan_baselines": {Type: BooleanParameter},
+	"optimizer_dynamic_sampling":           {Type: IntegerParameter},
+	"optimizer_features_enable":            {Type: StringParameter},
+	"optimizer_index_caching":              {Type: IntegerParameter},
+	"optimizer_index_cost_adj":             {Type: IntegerParameter},
+	"optimizer_inmemory_aware":             {Type: BooleanParameter},
+	"optimizer_mode":                       {Type: StringParameter},
+	"optimizer_use_invisible_indexes":      {Type: BooleanParameter},
+	"optimizer_use_pending_statistics":     {Type: BooleanParameter},
+	"optimizer_use_sql_plan_baselines":     {Type: BooleanParameter},
+	"os_authent_prefix":                    {Type: StringParameter, Static: true},
+	"parallel_degree_limit":                {Type: StringParameter},
+	"parallel_degree_policy":               {Type: StringParameter},
+	"parallel_force_local":                 {Type: BooleanParameter},
+	"parallel_max_servers":                 {Type: IntegerParameter},
+	"parallel_min_servers":                 {Type: IntegerParameter},
+	"parallel_servers_target":              {Type: IntegerParameter},
+	"parallel_threads_per_cpu":             {Type: IntegerParameter},
+	"pga_aggregate_limit":                  {Type: BigIntegerParameter},
+	"pga_aggregate_target":                 {Type: BigIntegerParameter},
+	"plsql_code_type":                      {Type: StringParameter},
+	"plsql_optimize_level":                 {Type: IntegerParameter},
+	"plsql_warnings":                       {Type: StringParameter},
+	"pre_page_sga":                         {Type: BooleanParameter, Static: true},
+	"processes":                            {Type: IntegerParameter, Static: true},
+	"query_rewrite_enabled":                {Type: StringParameter},
+	"query_rewrite_integrity":              {Type: StringParameter},
+	"recyclebin":                           {Type: StringParameter},
+	"remote_dependencies_mode":             {Type: StringParameter},
+	"resource_limit":                       {Type: BooleanParameter},
+	"resource_manager_plan":                {Type: StringParameter},
+	"result_cache_max_size":                {Type: BigIntegerParameter},
+	"result_cache_mode":                    {Type: StringParameter},
+	"resumable_timeout":                    {Type: IntegerParameter},
+	"sec_max_failed_login_attempts":        {Type: IntegerParameter, Static: true},
+	"sec_protocol_error_trace_action":      {Type: StringParameter},
+	"session_cached_cursors":               {Type: IntegerParameter, Static: true},
+	"sessions":                             {Type: IntegerParameter, Static: true},
+	"sga_max_size":                         {Type: BigIntegerParameter, Static: true},
+	"sga_min_size":                         {Type: BigIntegerParameter},
+	"sga_target":                           {Type: BigIntegerParameter, Static: overrideParamTypeStatic["sga_target"]},
+	"shared_pool_reserved_size":            {Type: BigIntegerParameter, Static: true},
+	"shared_pool_size":                     {Type: BigIntegerParameter},
+	"sort_area_size":                       {Type: IntegerParameter},
+	"spatial_vector_acceleration":          {Type: BooleanParameter},
+	"sql92_security":                       {Type: BooleanParameter, Static: true},
+	"star_transformation_enabled":          {Type: StringParameter},
+	"statistics_level":                     {Type: StringParameter},
+	"streams_pool_size":                    {Type: BigIntegerParameter},
+	"temp_undo_enabled":                    {Type: BooleanParameter},
+	"timed_statistics":                     {Type: BooleanParameter},
+	"transactions":                         {Type: IntegerParameter, Static: true},
+	"undo_retention":                       {Type: IntegerParameter},
+	"use_large_pages":                      {Type: StringParameter, Static: true},
+	"workarea_size_policy":                 {Type: StringParameter},
+}
+
+// IsStaticParameter returns whether the known parameter is only applied
+// after a database restart.
+func IsStaticParameter(name string) bool {
+	return KnownParameters[name].Static
+}
+
+// ValidateParameters returns the errors of the database parameters, which
+// are reserved by the operator, identify the database or whose values don't
+// match the datatype of a known parameter. Parameters unknown to the
+// operator, e.g. hidden ones which start with an underscore, are left to the
+// database to validate.
+func ValidateParameters(params map[string]string, path *field.Path) field.ErrorList {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs field.ErrorList
+	for _, name := range names {
+		value := params[name]
+		p := path.Key(name)
+		if ReservedParameters[name] || instanceParameters[name] {
+			errs = append(errs, field.Forbidden(p, "the parameter is managed by the operator"))
+			continue
+		}
+		info, ok := KnownParameters[name]
+		if !ok {
+			continue
+		}
+		switch info.Type {
+		case BooleanParameter:
+			if v := strings.ToUpper(value); v != "TRUE" && v != "FALSE" {
+				errs = append(errs, field.Invalid(p, value, "must be TRUE or FALSE"))
+			}
+		case IntegerParameter:
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				errs = append(errs, field.Invalid(p, value, "must be an integer"))
+			}
+		case BigIntegerParameter:
+			if !bigIntegerValue.MatchString(value) {
+				errs = append(errs, field.Invalid(p, value, "must be an integer with an optional K, M, G or T unit"))
+			}
+		case StringParameter:
+			if value == "" {
+				errs = append(errs, field.Required(p, ""))
+			}
+		}
+	}
+	return errs
+}
+
+// UnknownParameters returns the sorted names of the database parameters
+// unknown to the operator, which ValidateParameters doesn't validate.
+func UnknownParameters(params map[string]string) []string {
+	var names []string
+	for name := range params {
+		if _, ok := KnownParameters[name]; !ok && !ReservedParameters[name] && !instanceParameters[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
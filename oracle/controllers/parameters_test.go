@@ -0,0 +1,92 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{
+			name: "valid",
+			params: map[string]string{
+				"cpu_count":            "4",
+				"sga_max_size":         "7900M",
+				"pga_aggregate_target": "2147483648",
+				"resource_limit":       "true",
+				"optimizer_mode":       "ALL_ROWS",
+				"_fix_control":         "27321179:0",
+			},
+		},
+		{
+			name:   "reserved",
+			params: map[string]string{"audit_trail": "db", "db_name": "MYDB"},
+			want:   []string{"spec.parameters[audit_trail]", "spec.parameters[db_name]"},
+		},
+		{
+			name:   "unknown",
+			params: map[string]string{"fal_server": "mydb_stby", "log_archive_dest_2": "SERVICE=mydb_stby"},
+		},
+		{
+			name: "wrong datatype",
+			params: map[string]string{
+				"processes":      "many",
+				"sga_max_size":   "7.9G",
+				"resource_limit": "yes",
+				"optimizer_mode": "",
+			},
+			want: []string{
+				"spec.parameters[optimizer_mode]",
+				"spec.parameters[processes]",
+				"spec.parameters[resource_limit]",
+				"spec.parameters[sga_max_size]",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, err := range ValidateParameters(tc.params, field.NewPath("spec", "parameters")) {
+				got = append(got, err.Field)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ValidateParameters got unexpected invalid fields (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestUnknownParameters(t *testing.T) {
+	params := map[string]string{
+		"open_cursors":       "300",
+		"db_name":            "MYDB",
+		"audit_trail":        "db",
+		"log_archive_dest_2": "SERVICE=mydb_stby",
+		"_fix_control":       "27321179:0",
+		"fal_server":         "mydb_stby",
+	}
+	want := []string{"_fix_control", "fal_server", "log_archive_dest_2"}
+	if diff := cmp.Diff(want, UnknownParameters(params)); diff != "" {
+		t.Errorf("UnknownParameters got unexpected names (-want +got):\n%v", diff)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/robfig/cron"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -404,6 +405,18 @@ func (r *PITRReconciler) ensureAgent(ctx context.Context, p *v1alpha1.PITR, i *v
 		},
 	}
 
+	// The agent dials the database daemon over mutual TLS if it serves it.
+	tlsSecret := &corev1.Secret{}
+	tlsSecretName := fmt.Sprintf(controllers.DBDaemonTLSSecretName, i.GetName())
+	if err := r.Get(ctx, types.NamespacedName{Name: tlsSecretName, Namespace: i.GetNamespace()}, tlsSecret); err == nil {
+		podSpec := &deployment.Spec.Template.Spec
+		podSpec.Volumes = append(podSpec.Volumes, controllers.DBDaemonTLSVolume(tlsSecretName))
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, controllers.DBDaemonTLSVolumeMount())
+		podSpec.Containers[0].Args = append(podSpec.Containers[0].Args, "--tls_cert_dir="+consts.DBDaemonTLSDir)
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
 	if err := ctrl.SetControllerReference(p, deployment, r.Scheme); err != nil {
 		return err
 	}
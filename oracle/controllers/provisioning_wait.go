@@ -0,0 +1,53 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// provisioningDoneWaitSeconds is how long CheckStatus lets the database
+// daemon wait for the provisioning done file before reporting provisioning
+// as in progress.
+const provisioningDoneWaitSeconds = 3
+
+// provisioningRequeueBackoff is the schedule of the checks for the end of
+// provisioning. Early checks are frequent so short provisioning steps are
+// picked up promptly, long ones are polled less often.
+var provisioningRequeueBackoff = wait.Backoff{
+	Duration: 5 * time.Second,
+	Factor:   1.5,
+	Jitter:   0.2,
+	Cap:      time.Minute,
+}
+
+// ProvisioningRequeueAfter returns the delay before checking again for the
+// end of a provisioning step which has been running for elapsed. The delay
+// grows exponentially with elapsed and is jittered so that instances which
+// started provisioning together don't poll in lockstep.
+func ProvisioningRequeueAfter(elapsed time.Duration) time.Duration {
+	b := provisioningRequeueBackoff
+	delay, waited := b.Duration, time.Duration(0)
+	for delay < b.Cap && waited+delay <= elapsed {
+		waited += delay
+		delay = time.Duration(float64(delay) * b.Factor)
+	}
+	if delay > b.Cap {
+		delay = b.Cap
+	}
+	return wait.Jitter(delay, b.Jitter)
+}
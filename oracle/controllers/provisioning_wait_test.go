@@ -0,0 +1,40 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProvisioningRequeueAfter(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{elapsed: 0, want: 5 * time.Second},
+		{elapsed: 4 * time.Second, want: 5 * time.Second},
+		{elapsed: 5 * time.Second, want: 7500 * time.Millisecond},
+		{elapsed: 12500 * time.Millisecond, want: 11250 * time.Millisecond},
+		{elapsed: 30 * time.Second, want: 16875 * time.Millisecond},
+		{elapsed: time.Hour, want: time.Minute},
+	}
+	for _, tc := range tests {
+		// Jitter only ever adds up to 20% to the delay.
+		if got := ProvisioningRequeueAfter(tc.elapsed); got < tc.want || got > tc.want+tc.want/5 {
+			t.Errorf("ProvisioningRequeueAfter(%v) = %v, want between %v and %v", tc.elapsed, got, tc.want, tc.want+tc.want/5)
+		}
+	}
+}
@@ -59,11 +59,14 @@ const (
 )
 
 var (
-	podInfoDir      = "/etc/podinfo"
-	defaultDiskSize = resource.MustParse("100Gi")
-	dialTimeout     = 3 * time.Minute
-	configList      = []string{configAgentName, OperatorName}
-	defaultDisks    = []commonv1alpha1.DiskSpec{
+	podInfoDir = "/etc/podinfo"
+	// customMetricsDir is where the custom metrics ConfigMap is mounted in
+	// the monitoring agent.
+	customMetricsDir = "/custom-metrics"
+	defaultDiskSize  = resource.MustParse("100Gi")
+	dialTimeout      = 3 * time.Minute
+	configList       = []string{configAgentName, OperatorName}
+	defaultDisks     = []commonv1alpha1.DiskSpec{
 		{
 			Name: "DataDisk",
 			Size: resource.MustParse("100Gi"),
@@ -209,7 +212,10 @@ func GetLogLevelArgs(config *v1alpha1.Config) map[string][]string {
 	return agentArgs
 }
 
-func MonitoringPodTemplate(inst *v1alpha1.Instance, monitoringSecret *corev1.Secret, images map[string]string) corev1.PodTemplateSpec {
+// MonitoringPodTemplate returns the pod template of the monitoring agent.
+// The agent dials the database daemon over mutual TLS with the certificates
+// of dbdaemonTLSSecret, plaintext if empty.
+func MonitoringPodTemplate(inst *v1alpha1.Instance, monitoringSecret *corev1.Secret, dbdaemonTLSSecret string, images map[string]string) corev1.PodTemplateSpec {
 	svcName := fmt.Sprintf(SvcName, inst.Name)
 	dbdName := GetDBDomain(inst)
 	names := []string{inst.Spec.CDBName}
@@ -234,15 +240,20 @@ func MonitoringPodTemplate(inst *v1alpha1.Instance, monitoringSecret *corev1.Sec
 				Name:  "DATA_SOURCE_PASS_FILE",
 				Value: "/mon-creds/password",
 			},
+			{
+				Name:  "DBDAEMON_ADDRESS",
+				Value: fmt.Sprintf("%s:%d", fmt.Sprintf(DbdaemonSvcName, inst.Name), consts.DefaultDBDaemonPort),
+			},
+			{
+				Name:  "WRITE_CANARY",
+				Value: strconv.FormatBool(inst.Spec.WriteCanary != nil),
+			},
 		},
 		// TODO: Standardize metrics port.
 		Ports: []corev1.ContainerPort{
 			{ContainerPort: 9187, Protocol: corev1.ProtocolTCP},
 		},
-		SecurityContext: &corev1.SecurityContext{
-			AllowPrivilegeEscalation: &falseVal,
-			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-		},
+		SecurityContext: containerSecurityContext(falseVal, containerSecurityContexts(inst).Monitoring),
 		ImagePullPolicy: corev1.PullAlways,
 		VolumeMounts: []corev1.VolumeMount{
 			{MountPath: "/mon-creds/", Name: "mon-creds"},
@@ -282,6 +293,29 @@ func MonitoringPodTemplate(inst *v1alpha1.Instance, monitoringSecret *corev1.Sec
 		}},
 	}
 
+	// Mount the custom metric sets so that the agent appends them to its
+	// default set.
+	if mc := inst.Spec.MonitoringConfig; mc != nil && mc.CustomMetricsConfigMap != nil {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "custom-metrics",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: *mc.CustomMetricsConfigMap,
+				},
+			},
+		})
+		c := &podSpec.Containers[0]
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{MountPath: customMetricsDir, Name: "custom-metrics", ReadOnly: true})
+		c.Env = append(c.Env, corev1.EnvVar{Name: "CUSTOM_METRICS_DIR", Value: customMetricsDir})
+	}
+
+	if dbdaemonTLSSecret != "" {
+		podSpec.Volumes = append(podSpec.Volumes, DBDaemonTLSVolume(dbdaemonTLSSecret))
+		c := &podSpec.Containers[0]
+		c.VolumeMounts = append(c.VolumeMounts, DBDaemonTLSVolumeMount())
+		c.Env = append(c.Env, corev1.EnvVar{Name: "DBDAEMON_TLS_DIR", Value: consts.DBDaemonTLSDir})
+	}
+
 	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: inst.Namespace,
@@ -414,6 +448,7 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 
 	sp.Log.Info("NewPodTemplate: creating new template with images", "images", sp.Images)
 	dataDiskPVC, dataDiskMountName := GetPVCNameAndMount(sp.Inst.Name, "DataDisk")
+	securityContexts := containerSecurityContexts(&inst)
 
 	containers := []corev1.Container{
 		{
@@ -442,10 +477,7 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 				{Name: podInfoVolume, MountPath: podInfoDir, ReadOnly: true},
 			},
 				buildPVCMounts(sp)...),
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: &sp.PrivEscalation,
-				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-			},
+			SecurityContext: containerSecurityContext(sp.PrivEscalation, securityContexts.Database),
 			EnvFrom: []corev1.EnvFromSource{
 				{
 					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: sp.ConfigMap.ObjectMeta.Name}},
@@ -461,10 +493,7 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 			Ports: []corev1.ContainerPort{
 				{Name: "dbdaemon", Protocol: "TCP", ContainerPort: consts.DefaultDBDaemonPort},
 			},
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: &sp.PrivEscalation,
-				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-			},
+			SecurityContext: containerSecurityContext(sp.PrivEscalation, securityContexts.DBDaemon),
 			VolumeMounts: append([]corev1.VolumeMount{
 				{Name: "var-tmp", MountPath: "/var/tmp"},
 				{Name: "agent-repo", MountPath: "/agents"},
@@ -474,14 +503,11 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 			ImagePullPolicy: imagePullPolicy,
 		},
 		{
-			Name:    "alert-log-sidecar",
-			Image:   sp.Images["logging_sidecar"],
-			Command: []string{"/logging_main"},
-			Args:    []string{"--logType=ALERT"},
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: &sp.PrivEscalation,
-				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-			},
+			Name:            "alert-log-sidecar",
+			Image:           sp.Images["logging_sidecar"],
+			Command:         []string{"/logging_main"},
+			Args:            []string{"--logType=ALERT"},
+			SecurityContext: containerSecurityContext(sp.PrivEscalation, securityContexts.Logging),
 			VolumeMounts: []corev1.VolumeMount{
 				{Name: dataDiskPVC, MountPath: fmt.Sprintf("/%s", dataDiskMountName)},
 				{Name: podInfoVolume, MountPath: podInfoDir, ReadOnly: true},
@@ -489,14 +515,11 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 			ImagePullPolicy: imagePullPolicy,
 		},
 		{
-			Name:    "listener-log-sidecar",
-			Image:   sp.Images["logging_sidecar"],
-			Command: []string{"/logging_main"},
-			Args:    []string{"--logType=LISTENER"},
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: &sp.PrivEscalation,
-				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-			},
+			Name:            "listener-log-sidecar",
+			Image:           sp.Images["logging_sidecar"],
+			Command:         []string{"/logging_main"},
+			Args:            []string{"--logType=LISTENER"},
+			SecurityContext: containerSecurityContext(sp.PrivEscalation, securityContexts.Logging),
 			VolumeMounts: []corev1.VolumeMount{
 				{Name: dataDiskPVC, MountPath: fmt.Sprintf("/%s", dataDiskMountName)},
 				{Name: podInfoVolume, MountPath: podInfoDir, ReadOnly: true},
@@ -506,13 +529,10 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 	}
 	initContainers := []corev1.Container{
 		{
-			Name:    "dbinit",
-			Image:   sp.Images["dbinit"],
-			Command: []string{"sh", "-c", "cp -r agent_repo/. /agents/ && chmod -R 750 /agents/*"},
-			SecurityContext: &corev1.SecurityContext{
-				AllowPrivilegeEscalation: &sp.PrivEscalation,
-				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
-			},
+			Name:            "dbinit",
+			Image:           sp.Images["dbinit"],
+			Command:         []string{"sh", "-c", "cp -r agent_repo/. /agents/ && chmod -R 750 /agents/*"},
+			SecurityContext: containerSecurityContext(sp.PrivEscalation, securityContexts.Database),
 			VolumeMounts: []corev1.VolumeMount{
 				{Name: "agent-repo", MountPath: "/agents"},
 			},
@@ -545,6 +565,19 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 		},
 	}
 
+	// The database daemon and its proxy, which runs in the database
+	// container, serve mutual TLS with the certificates of the instance.
+	if sp.DBDaemonTLSSecret != "" {
+		volumes = append(volumes, DBDaemonTLSVolume(sp.DBDaemonTLSSecret))
+		for i := range containers {
+			if containers[i].Name != dbContainerName && containers[i].Name != "dbdaemon" {
+				continue
+			}
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, DBDaemonTLSVolumeMount())
+			containers[i].Args = append(containers[i].Args, "--tls_cert_dir="+consts.DBDaemonTLSDir)
+		}
+	}
+
 	uid := sp.Inst.Spec.DatabaseUID
 	if uid == nil {
 		sp.Log.Info("set pod user ID to default value", "UID", DefaultUID)
@@ -559,19 +592,21 @@ func NewPodTemplate(sp StsParams, inst v1alpha1.Instance) corev1.PodTemplateSpec
 		gid = func(i int64) *int64 { return &i }(DefaultGID)
 	}
 
+	podSecurityContext := mergePodSecurityContext(&corev1.PodSecurityContext{
+		RunAsUser:    uid,
+		RunAsGroup:   gid,
+		FSGroup:      gid,
+		RunAsNonRoot: func(b bool) *bool { return &b }(true),
+	}, inst.Spec.PodSpec.SecurityContext)
+
 	// for minikube/kind, the default csi-hostpath-driver mounts persistent volumes writable by root only, so explicitly
 	// change owner and permissions of mounted pvs with an init container.
 	if sp.Config != nil && (sp.Config.Spec.Platform == utils.PlatformMinikube || sp.Config.Spec.Platform == utils.PlatformKind) {
-		initContainers = addHostpathInitContainer(sp, initContainers, *uid, *gid)
+		initContainers = addHostpathInitContainer(sp, initContainers, *podSecurityContext.RunAsUser, *podSecurityContext.RunAsGroup)
 	}
 
 	podSpec := corev1.PodSpec{
-		SecurityContext: &corev1.PodSecurityContext{
-			RunAsUser:    uid,
-			RunAsGroup:   gid,
-			FSGroup:      gid,
-			RunAsNonRoot: func(b bool) *bool { return &b }(true),
-		},
+		SecurityContext: podSecurityContext,
 		// ImagePullSecrets: []corev1.LocalObjectReference {{Name: GcrSecretName }},
 		Containers:            containers,
 		InitContainers:        initContainers,
@@ -676,14 +711,130 @@ func addHostpathInitContainer(sp StsParams, containers []corev1.Container, uid,
 	})
 }
 
+// containerSecurityContexts returns the container security contexts
+// requested in the Instance spec, never nil.
+func containerSecurityContexts(inst *v1alpha1.Instance) commonv1alpha1.ContainerSecurityContexts {
+	if inst.Spec.PodSpec.ContainerSecurityContexts == nil {
+		return commonv1alpha1.ContainerSecurityContexts{}
+	}
+	return *inst.Spec.PodSpec.ContainerSecurityContexts
+}
+
+// containerSecurityContext returns the default security context of the
+// operator managed containers with the fields set in override applied.
+func containerSecurityContext(privEscalation bool, override *corev1.SecurityContext) *corev1.SecurityContext {
+	sc := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &privEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}},
+	}
+	if override == nil {
+		return sc
+	}
+	override = override.DeepCopy()
+	if override.Capabilities != nil {
+		sc.Capabilities = override.Capabilities
+	}
+	if override.Privileged != nil {
+		sc.Privileged = override.Privileged
+	}
+	if override.SELinuxOptions != nil {
+		sc.SELinuxOptions = override.SELinuxOptions
+	}
+	if override.WindowsOptions != nil {
+		sc.WindowsOptions = override.WindowsOptions
+	}
+	if override.RunAsUser != nil {
+		sc.RunAsUser = override.RunAsUser
+	}
+	if override.RunAsGroup != nil {
+		sc.RunAsGroup = override.RunAsGroup
+	}
+	if override.RunAsNonRoot != nil {
+		sc.RunAsNonRoot = override.RunAsNonRoot
+	}
+	if override.ReadOnlyRootFilesystem != nil {
+		sc.ReadOnlyRootFilesystem = override.ReadOnlyRootFilesystem
+	}
+	if override.AllowPrivilegeEscalation != nil {
+		sc.AllowPrivilegeEscalation = override.AllowPrivilegeEscalation
+	}
+	if override.ProcMount != nil {
+		sc.ProcMount = override.ProcMount
+	}
+	if override.SeccompProfile != nil {
+		sc.SeccompProfile = override.SeccompProfile
+	}
+	return sc
+}
+
+// mergePodSecurityContext returns the default pod security context with the
+// fields set in override applied.
+func mergePodSecurityContext(sc, override *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	if override == nil {
+		return sc
+	}
+	override = override.DeepCopy()
+	if override.SELinuxOptions != nil {
+		sc.SELinuxOptions = override.SELinuxOptions
+	}
+	if override.WindowsOptions != nil {
+		sc.WindowsOptions = override.WindowsOptions
+	}
+	if override.RunAsUser != nil {
+		sc.RunAsUser = override.RunAsUser
+	}
+	if override.RunAsGroup != nil {
+		sc.RunAsGroup = override.RunAsGroup
+	}
+	if override.RunAsNonRoot != nil {
+		sc.RunAsNonRoot = override.RunAsNonRoot
+	}
+	if override.SupplementalGroups != nil {
+		sc.SupplementalGroups = override.SupplementalGroups
+	}
+	if override.FSGroup != nil {
+		sc.FSGroup = override.FSGroup
+	}
+	if override.Sysctls != nil {
+		sc.Sysctls = override.Sysctls
+	}
+	if override.FSGroupChangePolicy != nil {
+		sc.FSGroupChangePolicy = override.FSGroupChangePolicy
+	}
+	if override.SeccompProfile != nil {
+		sc.SeccompProfile = override.SeccompProfile
+	}
+	return sc
+}
+
 func DiskSpecs(inst *v1alpha1.Instance, config *v1alpha1.Config) []commonv1alpha1.DiskSpec {
+	var disks []commonv1alpha1.DiskSpec
 	if inst != nil && inst.Spec.Disks != nil {
-		return inst.Spec.Disks
+		disks = inst.Spec.Disks
+	} else if config != nil && config.Spec.Disks != nil {
+		disks = config.Spec.Disks
+	} else {
+		disks = defaultDisks
 	}
-	if config != nil && config.Spec.Disks != nil {
-		return config.Spec.Disks
+	return autoResizedDisks(inst, disks)
+}
+
+// autoResizedDisks returns a copy of the disks with the size of the DataDisk
+// raised to the size it was automatically expanded to, if larger.
+func autoResizedDisks(inst *v1alpha1.Instance, disks []commonv1alpha1.DiskSpec) []commonv1alpha1.DiskSpec {
+	if inst == nil || inst.Status.AutoResize == nil || inst.Status.AutoResize.Size == nil {
+		return disks
+	}
+	size := *inst.Status.AutoResize.Size
+	var resized []commonv1alpha1.DiskSpec
+	for _, d := range disks {
+		if d.Name == "DataDisk" && size.Cmp(d.Size) > 0 {
+			d = *d.DeepCopy()
+			d.Size = size.DeepCopy()
+		}
+		resized = append(resized, d)
 	}
-	return defaultDisks
+	return resized
 }
 
 func RequestedMemoryInMi() (int, error) {
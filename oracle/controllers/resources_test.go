@@ -21,11 +21,14 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonv1alpha1 "github.com/GoogleCloudPlatform/elcarro-oracle-operator/common/api/v1alpha1"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
 )
 
 func TestBuildPVCMounts(t *testing.T) {
@@ -178,3 +181,149 @@ func TestRequestMemoryInMi(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPodTemplateSecurityContext(t *testing.T) {
+	seccomp := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	dropAll := &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+	inst := v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Name: "myinst", Namespace: "db"},
+		Spec: v1alpha1.InstanceSpec{
+			PodSpec: commonv1alpha1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					FSGroup:        func(i int64) *int64 { return &i }(2000),
+					SeccompProfile: seccomp,
+				},
+				ContainerSecurityContexts: &commonv1alpha1.ContainerSecurityContexts{
+					Database: &corev1.SecurityContext{Capabilities: dropAll},
+					Logging:  &corev1.SecurityContext{ReadOnlyRootFilesystem: func(b bool) *bool { return &b }(true)},
+				},
+			},
+		},
+	}
+	sp := StsParams{
+		Inst:      &inst,
+		ConfigMap: &corev1.ConfigMap{},
+		Log:       logr.Discard(),
+	}
+
+	template := NewPodTemplate(sp, inst)
+
+	podSC := template.Spec.SecurityContext
+	if podSC.RunAsUser == nil || *podSC.RunAsUser != DefaultUID {
+		t.Errorf("NewPodTemplate got runAsUser %v, want the default %d", podSC.RunAsUser, DefaultUID)
+	}
+	if podSC.FSGroup == nil || *podSC.FSGroup != 2000 {
+		t.Errorf("NewPodTemplate got fsGroup %v, want 2000", podSC.FSGroup)
+	}
+	if diff := cmp.Diff(seccomp, podSC.SeccompProfile); diff != "" {
+		t.Errorf("NewPodTemplate got unexpected seccomp profile (-want +got):\n%v", diff)
+	}
+
+	wantCapabilities := map[string]*corev1.Capabilities{
+		dbContainerName:        dropAll,
+		"dbdaemon":             {Drop: []corev1.Capability{"NET_RAW"}},
+		"alert-log-sidecar":    {Drop: []corev1.Capability{"NET_RAW"}},
+		"listener-log-sidecar": {Drop: []corev1.Capability{"NET_RAW"}},
+		"dbinit":               dropAll,
+	}
+	for _, c := range append(template.Spec.Containers, template.Spec.InitContainers...) {
+		if diff := cmp.Diff(wantCapabilities[c.Name], c.SecurityContext.Capabilities); diff != "" {
+			t.Errorf("NewPodTemplate got unexpected capabilities for %s (-want +got):\n%v", c.Name, diff)
+		}
+		if c.SecurityContext.AllowPrivilegeEscalation == nil || *c.SecurityContext.AllowPrivilegeEscalation {
+			t.Errorf("NewPodTemplate got allowPrivilegeEscalation %v for %s, want false", c.SecurityContext.AllowPrivilegeEscalation, c.Name)
+		}
+		wantReadOnly := strings.HasSuffix(c.Name, "-log-sidecar")
+		if got := c.SecurityContext.ReadOnlyRootFilesystem != nil && *c.SecurityContext.ReadOnlyRootFilesystem; got != wantReadOnly {
+			t.Errorf("NewPodTemplate got readOnlyRootFilesystem %v for %s, want %v", got, c.Name, wantReadOnly)
+		}
+	}
+}
+
+func TestMonitoringPodTemplateSecurityContext(t *testing.T) {
+	inst := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Name: "myinst", Namespace: "db"},
+		Spec: v1alpha1.InstanceSpec{
+			PodSpec: commonv1alpha1.PodSpec{
+				ContainerSecurityContexts: &commonv1alpha1.ContainerSecurityContexts{
+					Monitoring: &corev1.SecurityContext{RunAsNonRoot: func(b bool) *bool { return &b }(true)},
+				},
+			},
+		},
+	}
+
+	template := MonitoringPodTemplate(inst, &corev1.Secret{}, "", map[string]string{})
+
+	sc := template.Spec.Containers[0].SecurityContext
+	if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+		t.Errorf("MonitoringPodTemplate got runAsNonRoot %v, want true", sc.RunAsNonRoot)
+	}
+	if diff := cmp.Diff(&corev1.Capabilities{Drop: []corev1.Capability{"NET_RAW"}}, sc.Capabilities); diff != "" {
+		t.Errorf("MonitoringPodTemplate got unexpected capabilities (-want +got):\n%v", diff)
+	}
+}
+
+func TestMonitoringPodTemplateCustomMetrics(t *testing.T) {
+	inst := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Name: "myinst", Namespace: "db"},
+	}
+	template := MonitoringPodTemplate(inst, &corev1.Secret{}, "", map[string]string{})
+	if n := len(template.Spec.Volumes); n != 1 {
+		t.Errorf("MonitoringPodTemplate without custom metrics got %d volumes, want 1", n)
+	}
+
+	inst.Spec.MonitoringConfig = &v1alpha1.MonitoringConfig{
+		CustomMetricsConfigMap: &corev1.LocalObjectReference{Name: "my-metrics"},
+	}
+	template = MonitoringPodTemplate(inst, &corev1.Secret{}, "", map[string]string{})
+
+	wantVolume := corev1.Volume{
+		Name: "custom-metrics",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "my-metrics"}},
+		},
+	}
+	if diff := cmp.Diff(wantVolume, template.Spec.Volumes[len(template.Spec.Volumes)-1]); diff != "" {
+		t.Errorf("MonitoringPodTemplate got unexpected custom metrics volume (-want +got):\n%v", diff)
+	}
+	c := template.Spec.Containers[0]
+	wantMount := corev1.VolumeMount{Name: "custom-metrics", MountPath: customMetricsDir, ReadOnly: true}
+	if diff := cmp.Diff(wantMount, c.VolumeMounts[len(c.VolumeMounts)-1]); diff != "" {
+		t.Errorf("MonitoringPodTemplate got unexpected custom metrics mount (-want +got):\n%v", diff)
+	}
+	wantEnv := corev1.EnvVar{Name: "CUSTOM_METRICS_DIR", Value: customMetricsDir}
+	if diff := cmp.Diff(wantEnv, c.Env[len(c.Env)-1]); diff != "" {
+		t.Errorf("MonitoringPodTemplate got unexpected custom metrics env (-want +got):\n%v", diff)
+	}
+}
+
+func TestNewPodTemplateDBDaemonTLS(t *testing.T) {
+	inst := v1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: "myinst", Namespace: "db"}}
+	sp := StsParams{
+		Inst:              &inst,
+		ConfigMap:         &corev1.ConfigMap{},
+		Log:               logr.Discard(),
+		DBDaemonTLSSecret: "myinst-dbdaemon-tls",
+	}
+
+	template := NewPodTemplate(sp, inst)
+
+	if diff := cmp.Diff(DBDaemonTLSVolume("myinst-dbdaemon-tls"), template.Spec.Volumes[len(template.Spec.Volumes)-1]); diff != "" {
+		t.Errorf("NewPodTemplate got unexpected dbdaemon TLS volume (-want +got):\n%v", diff)
+	}
+	wantArg := "--tls_cert_dir=" + consts.DBDaemonTLSDir
+	for _, c := range template.Spec.Containers {
+		var mounted bool
+		for _, m := range c.VolumeMounts {
+			mounted = mounted || m == DBDaemonTLSVolumeMount()
+		}
+		var hasArg bool
+		for _, a := range c.Args {
+			hasArg = hasArg || a == wantArg
+		}
+		want := c.Name == dbContainerName || c.Name == "dbdaemon"
+		if mounted != want || hasArg != want {
+			t.Errorf("NewPodTemplate got dbdaemon TLS mount %v and arg %v for %s, want %v", mounted, hasArg, c.Name, want)
+		}
+	}
+}
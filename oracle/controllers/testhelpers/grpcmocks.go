@@ -49,40 +49,170 @@ const (
 
 // FakeDatabaseClient mocks DatabaseDaemon
 type FakeDatabaseClient struct {
-	getOperationCalledCnt             int32
-	listOperationsCalledCnt           int32
-	fetchServiceImageMetaDataCnt      int32
-	deleteOperationCalledCnt          int32
-	bounceDatabaseCalledCnt           int32
-	recoverConfigFileCalledCnt        int32
-	checkDatabaseStateCalledCnt       int32
-	runSQLPlusCalledCnt               int32
-	runSQLPlusFormattedCalledCnt      int32
-	bootstrapStandbyCalledCnt         int32
-	createCDBAsyncCalledCnt           int32
-	createDirsCalledCnt               int32
-	bootstrapDatabaseCalledCnt        int32
-	bootstrapDatabaseAsyncCalledCnt   int32
-	fileExistsCalledCnt               int32
-	createListenerCalledCnt           int32
-	createFileCalledCnt               int32
-	downloadDirectoryFromGCSCalledCnt int32
-	runRMANCalledCnt                  int32
-	runRMANAsyncCalledCnt             int32
-	readDirCalledCnt                  int32
-	physicalRestoreAsyncCalledCnt     int32
-	asyncPhysicalBackup               bool
-	asyncPhysicalRestore              bool
-	deleteDirCalledCnt                int32
-	dataPumpImportAsyncCalledCnt      int32
-	dataPumpExportAsyncCalledCnt      int32
-	runDataGuardCalledCnt             int32
-	tnspingCalledCnt                  int32
-	nidCalledCnt                      int32
-	createPasswordFileCalledCnt       int32
-	applyDataPatchAsyncCalledCnt      int32
-
-	GotRMANAsyncRequest *dbdpb.RunRMANAsyncRequest
+	getOperationCalledCnt                  int32
+	listOperationsCalledCnt                int32
+	fetchServiceImageMetaDataCnt           int32
+	deleteOperationCalledCnt               int32
+	bounceDatabaseCalledCnt                int32
+	recoverConfigFileCalledCnt             int32
+	checkDatabaseStateCalledCnt            int32
+	runSQLPlusCalledCnt                    int32
+	runSQLPlusFormattedCalledCnt           int32
+	bootstrapStandbyCalledCnt              int32
+	createCDBAsyncCalledCnt                int32
+	createDirsCalledCnt                    int32
+	bootstrapDatabaseCalledCnt             int32
+	bootstrapDatabaseAsyncCalledCnt        int32
+	fileExistsCalledCnt                    int32
+	createListenerCalledCnt                int32
+	createFileCalledCnt                    int32
+	downloadDirectoryFromGCSCalledCnt      int32
+	runRMANCalledCnt                       int32
+	runRMANAsyncCalledCnt                  int32
+	readDirCalledCnt                       int32
+	physicalRestoreAsyncCalledCnt          int32
+	asyncPhysicalBackup                    bool
+	asyncPhysicalRestore                   bool
+	deleteDirCalledCnt                     int32
+	dataPumpImportAsyncCalledCnt           int32
+	dataPumpExportAsyncCalledCnt           int32
+	runDataGuardCalledCnt                  int32
+	tnspingCalledCnt                       int32
+	nidCalledCnt                           int32
+	createPasswordFileCalledCnt            int32
+	applyDataPatchAsyncCalledCnt           int32
+	verifyEncryptionCalledCnt              int32
+	configureNetworkEncryptionCalledCnt    int32
+	configureAllowedClientsCalledCnt       int32
+	configureTCPSListenerCalledCnt         int32
+	getFRAUsageCalledCnt                   int32
+	forceLogSwitchCalledCnt                int32
+	configureRMANCalledCnt                 int32
+	getDBIDCalledCnt                       int32
+	normalizeParametersCalledCnt           int32
+	exportParametersCalledCnt              int32
+	getInstanceInfoCalledCnt               int32
+	selfTestCalledCnt                      int32
+	prepareForStorageMigrationCalledCnt    int32
+	completeStorageMigrationCalledCnt      int32
+	validateOratabCalledCnt                int32
+	createDataPumpDirCalledCnt             int32
+	checkStoragePermissionsCalledCnt       int32
+	configureInMemoryCalledCnt             int32
+	getInMemoryStatusCalledCnt             int32
+	maintainPartitionsCalledCnt            int32
+	runSQLTuningAdvisorCalledCnt           int32
+	createAWRBaselineCalledCnt             int32
+	getSysauxOccupantsCalledCnt            int32
+	purgeSysauxCalledCnt                   int32
+	configureAWRCalledCnt                  int32
+	getHostStatsCalledCnt                  int32
+	growMountCalledCnt                     int32
+	getFeatureUsageCalledCnt               int32
+	setLicenseCalledCnt                    int32
+	getDefaultTablespacesCalledCnt         int32
+	setDefaultTablespacesCalledCnt         int32
+	setUserQuotaCalledCnt                  int32
+	getBlockingSessionsCalledCnt           int32
+	getLongRunningOpsCalledCnt             int32
+	getDeadlocksCalledCnt                  int32
+	startOnlineRedefinitionCalledCnt       int32
+	getStaleStatsCalledCnt                 int32
+	gatherStatsCalledCnt                   int32
+	getSQLMonitorReportCalledCnt           int32
+	captureSQLMonitorReportsCalledCnt      int32
+	setOwnershipCalledCnt                  int32
+	fixOwnershipCalledCnt                  int32
+	streamOperationLogCalledCnt            int32
+	getNLSSettingsCalledCnt                int32
+	setNLSSettingsCalledCnt                int32
+	configurePDBResourcesCalledCnt         int32
+	configureRowLevelSecurityCalledCnt     int32
+	fullInstanceExportAsyncCalledCnt       int32
+	fullInstanceImportAsyncCalledCnt       int32
+	getStandbyApplyStatusCalledCnt         int32
+	resolveArchiveLogGapCalledCnt          int32
+	configureRedoTransportCalledCnt        int32
+	getInstalledOptionsCalledCnt           int32
+	runSQLScriptFromGCSCalledCnt           int32
+	switchOracleHomeAsyncCalledCnt         int32
+	checkRestoreCompatibilityCalledCnt     int32
+	writeBackupMetadataCalledCnt           int32
+	readBackupMetadataCalledCnt            int32
+	listRestorePointsCalledCnt             int32
+	createRestorePointCalledCnt            int32
+	dropRestorePointCalledCnt              int32
+	validateSnapshotFilesCalledCnt         int32
+	getRedoRateCalledCnt                   int32
+	rotateWalletPasswordCalledCnt          int32
+	configureEditionsCalledCnt             int32
+	getPGAUsageCalledCnt                   int32
+	setSQLPlanCaptureCalledCnt             int32
+	getSQLPlanBaselinesCalledCnt           int32
+	setTablespaceEncryptionPolicyCalledCnt int32
+	restoreValidationReportAsyncCalledCnt  int32
+	diffParametersAgainstBaselineCalledCnt int32
+	configureFANCalledCnt                  int32
+	createManagedTriggerCalledCnt          int32
+	dropManagedTriggerCalledCnt            int32
+	listManagedTriggersCalledCnt           int32
+	checkStandbySynchronizedCalledCnt      int32
+	exportRMANCatalogCalledCnt             int32
+	runWriteCanaryCalledCnt                int32
+	reconcilePDBsCalledCnt                 int32
+	checkPITRTargetCalledCnt               int32
+	configureKeystoreCalledCnt             int32
+	setMasterKeyCalledCnt                  int32
+	encryptTablespacesCalledCnt            int32
+
+	GotRMANAsyncRequest                     *dbdpb.RunRMANAsyncRequest
+	GotRunSQLPlusRequest                    *dbdpb.RunSQLPlusCMDRequest
+	GotConfigureRMANRequest                 *dbdpb.ConfigureRMANRequest
+	GotConfigureNetworkEncryptionRequest    *dbdpb.ConfigureNetworkEncryptionRequest
+	GotConfigureTCPSListenerRequest         *dbdpb.ConfigureTCPSListenerRequest
+	GotCheckStoragePermissionsRequest       *dbdpb.CheckStoragePermissionsRequest
+	GotConfigureInMemoryRequest             *dbdpb.ConfigureInMemoryRequest
+	GotMaintainPartitionsRequest            *dbdpb.MaintainPartitionsRequest
+	GotCreateAWRBaselineRequests            []*dbdpb.CreateAWRBaselineRequest
+	GotConfigureAWRRequest                  *dbdpb.ConfigureAWRRequest
+	GotGrowMountRequest                     *dbdpb.GrowMountRequest
+	GotSetLicenseRequest                    *dbdpb.SetLicenseRequest
+	GotSetDefaultTablespacesRequest         *dbdpb.SetDefaultTablespacesRequest
+	GotSetUserQuotaRequests                 []*dbdpb.SetUserQuotaRequest
+	GotGetDeadlocksRequest                  *dbdpb.GetDeadlocksRequest
+	GotGetSQLPlanBaselinesRequest           *dbdpb.GetSQLPlanBaselinesRequest
+	GotSetTablespaceEncryptionPolicyRequest *dbdpb.SetTablespaceEncryptionPolicyRequest
+	GotRestoreValidationReportAsyncRequest  *dbdpb.RestoreValidationReportAsyncRequest
+	GotDiffParametersAgainstBaselineRequest *dbdpb.DiffParametersAgainstBaselineRequest
+	GotConfigureFANRequest                  *dbdpb.ConfigureFANRequest
+	GotCreateManagedTriggerRequest          *dbdpb.CreateManagedTriggerRequest
+	GotDropManagedTriggerRequest            *dbdpb.DropManagedTriggerRequest
+	GotExportRMANCatalogRequest             *dbdpb.ExportRMANCatalogRequest
+	GotReconcilePDBsRequest                 *dbdpb.ReconcilePDBsRequest
+	GotSetNLSSettingsRequests               []*dbdpb.SetNLSSettingsRequest
+	GotConfigurePDBResourcesRequests        []*dbdpb.ConfigurePDBResourcesRequest
+	GotConfigureRowLevelSecurityRequest     *dbdpb.ConfigureRowLevelSecurityRequest
+	GotGetInstalledOptionsRequest           *dbdpb.GetInstalledOptionsRequest
+	GotRunSQLScriptFromGCSRequests          []*dbdpb.RunSQLScriptFromGCSRequest
+	GotCheckRestoreCompatibilityRequest     *dbdpb.CheckRestoreCompatibilityRequest
+	GotWriteBackupMetadataRequest           *dbdpb.WriteBackupMetadataRequest
+	GotDropRestorePointRequests             []*dbdpb.DropRestorePointRequest
+	GotValidateSnapshotFilesRequest         *dbdpb.ValidateSnapshotFilesRequest
+	GotRotateWalletPasswordRequests         []*dbdpb.RotateWalletPasswordRequest
+	GotConfigureEditionsRequest             *dbdpb.ConfigureEditionsRequest
+	GotCheckPITRTargetRequest               *dbdpb.CheckPITRTargetRequest
+	GotConfigureKeystoreRequest             *dbdpb.ConfigureKeystoreRequest
+	GotSetMasterKeyRequest                  *dbdpb.SetMasterKeyRequest
+	GotEncryptTablespacesRequest            *dbdpb.EncryptTablespacesRequest
+	GotDataPumpImportAsyncRequest           *dbdpb.DataPumpImportAsyncRequest
+
+	// RunSQLPlusFunc, if set, serves RunSQLPlus so tests can fail some of
+	// the statements only.
+	RunSQLPlusFunc func(in *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error)
+
+	// RunSQLScriptFromGCSFunc, if set, serves RunSQLScriptFromGCS so tests
+	// can fail some of the scripts only.
+	RunSQLScriptFromGCSFunc func(in *dbdpb.RunSQLScriptFromGCSRequest) (*dbdpb.RunSQLScriptFromGCSResponse, error)
 
 	lock                   sync.Mutex
 	nextGetOperationStatus FakeOperationStatus
@@ -97,6 +227,1258 @@ func (cli *FakeDatabaseClient) SetDnfsState(ctx context.Context, in *dbdpb.SetDn
 	panic("implement me")
 }
 
+// VerifyEncryption reports the encryption status of tablespaces.
+func (cli *FakeDatabaseClient) VerifyEncryption(ctx context.Context, in *dbdpb.VerifyEncryptionRequest, opts ...grpc.CallOption) (*dbdpb.VerifyEncryptionResponse, error) {
+	atomic.AddInt32(&cli.verifyEncryptionCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("VerifyEncryption")
+	if resp != nil {
+		return resp.(*dbdpb.VerifyEncryptionResponse), err
+	}
+	return &dbdpb.VerifyEncryptionResponse{}, err
+}
+
+// VerifyEncryptionCalledCnt returns call count.
+func (cli *FakeDatabaseClient) VerifyEncryptionCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.verifyEncryptionCalledCnt))
+}
+
+// ConfigureNetworkEncryption sets native network encryption in sqlnet.ora.
+func (cli *FakeDatabaseClient) ConfigureNetworkEncryption(ctx context.Context, in *dbdpb.ConfigureNetworkEncryptionRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureNetworkEncryptionResponse, error) {
+	atomic.AddInt32(&cli.configureNetworkEncryptionCalledCnt, 1)
+	cli.GotConfigureNetworkEncryptionRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureNetworkEncryption")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureNetworkEncryptionResponse), err
+	}
+	return &dbdpb.ConfigureNetworkEncryptionResponse{}, err
+}
+
+// ConfigureNetworkEncryptionCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureNetworkEncryptionCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureNetworkEncryptionCalledCnt))
+}
+
+// ConfigureAllowedClients sets listener valid node checking in sqlnet.ora.
+func (cli *FakeDatabaseClient) ConfigureAllowedClients(ctx context.Context, in *dbdpb.ConfigureAllowedClientsRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureAllowedClientsResponse, error) {
+	atomic.AddInt32(&cli.configureAllowedClientsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ConfigureAllowedClients")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureAllowedClientsResponse), err
+	}
+	return &dbdpb.ConfigureAllowedClientsResponse{}, err
+}
+
+// ConfigureAllowedClientsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureAllowedClientsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureAllowedClientsCalledCnt))
+}
+
+// ConfigureTCPSListener installs the wallet of the TCPS listener and creates it.
+func (cli *FakeDatabaseClient) ConfigureTCPSListener(ctx context.Context, in *dbdpb.ConfigureTCPSListenerRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureTCPSListenerResponse, error) {
+	atomic.AddInt32(&cli.configureTCPSListenerCalledCnt, 1)
+	cli.GotConfigureTCPSListenerRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureTCPSListener")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureTCPSListenerResponse), err
+	}
+	return &dbdpb.ConfigureTCPSListenerResponse{}, err
+}
+
+// ConfigureTCPSListenerCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureTCPSListenerCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureTCPSListenerCalledCnt))
+}
+
+// GetFRAUsage reports the fast recovery area usage.
+func (cli *FakeDatabaseClient) GetFRAUsage(ctx context.Context, in *dbdpb.GetFRAUsageRequest, opts ...grpc.CallOption) (*dbdpb.GetFRAUsageResponse, error) {
+	atomic.AddInt32(&cli.getFRAUsageCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetFRAUsage")
+	if resp != nil {
+		return resp.(*dbdpb.GetFRAUsageResponse), err
+	}
+	return &dbdpb.GetFRAUsageResponse{}, err
+}
+
+// GetFRAUsageCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetFRAUsageCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getFRAUsageCalledCnt))
+}
+
+// ForceLogSwitch archives the current redo log.
+func (cli *FakeDatabaseClient) ForceLogSwitch(ctx context.Context, in *dbdpb.ForceLogSwitchRequest, opts ...grpc.CallOption) (*dbdpb.ForceLogSwitchResponse, error) {
+	atomic.AddInt32(&cli.forceLogSwitchCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ForceLogSwitch")
+	if resp != nil {
+		return resp.(*dbdpb.ForceLogSwitchResponse), err
+	}
+	return &dbdpb.ForceLogSwitchResponse{}, err
+}
+
+// ForceLogSwitchCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ForceLogSwitchCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.forceLogSwitchCalledCnt))
+}
+
+// ConfigureRMAN applies the RMAN persistent configuration.
+func (cli *FakeDatabaseClient) ConfigureRMAN(ctx context.Context, in *dbdpb.ConfigureRMANRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureRMANResponse, error) {
+	atomic.AddInt32(&cli.configureRMANCalledCnt, 1)
+	cli.GotConfigureRMANRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureRMAN")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureRMANResponse), err
+	}
+	return &dbdpb.ConfigureRMANResponse{}, err
+}
+
+// ConfigureRMANCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureRMANCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureRMANCalledCnt))
+}
+
+// GetDBID returns the DBID of the database.
+func (cli *FakeDatabaseClient) GetDBID(ctx context.Context, in *dbdpb.GetDBIDRequest, opts ...grpc.CallOption) (*dbdpb.GetDBIDResponse, error) {
+	atomic.AddInt32(&cli.getDBIDCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetDBID")
+	if resp != nil {
+		return resp.(*dbdpb.GetDBIDResponse), err
+	}
+	return &dbdpb.GetDBIDResponse{}, err
+}
+
+// GetDBIDCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetDBIDCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getDBIDCalledCnt))
+}
+
+// NormalizeParameters scopes spfile parameters to sid='*'.
+func (cli *FakeDatabaseClient) NormalizeParameters(ctx context.Context, in *dbdpb.NormalizeParametersRequest, opts ...grpc.CallOption) (*dbdpb.NormalizeParametersResponse, error) {
+	atomic.AddInt32(&cli.normalizeParametersCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("NormalizeParameters")
+	if resp != nil {
+		return resp.(*dbdpb.NormalizeParametersResponse), err
+	}
+	return &dbdpb.NormalizeParametersResponse{}, err
+}
+
+// NormalizeParametersCalledCnt returns call count.
+func (cli *FakeDatabaseClient) NormalizeParametersCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.normalizeParametersCalledCnt))
+}
+
+// ExportParameters returns the non-default parameters.
+func (cli *FakeDatabaseClient) ExportParameters(ctx context.Context, in *dbdpb.ExportParametersRequest, opts ...grpc.CallOption) (*dbdpb.ExportParametersResponse, error) {
+	atomic.AddInt32(&cli.exportParametersCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ExportParameters")
+	if resp != nil {
+		return resp.(*dbdpb.ExportParametersResponse), err
+	}
+	return &dbdpb.ExportParametersResponse{}, err
+}
+
+// ExportParametersCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ExportParametersCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.exportParametersCalledCnt))
+}
+
+// GetInstanceInfo returns the state of the database instance.
+func (cli *FakeDatabaseClient) GetInstanceInfo(ctx context.Context, in *dbdpb.GetInstanceInfoRequest, opts ...grpc.CallOption) (*dbdpb.GetInstanceInfoResponse, error) {
+	atomic.AddInt32(&cli.getInstanceInfoCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetInstanceInfo")
+	if resp != nil {
+		return resp.(*dbdpb.GetInstanceInfoResponse), err
+	}
+	return &dbdpb.GetInstanceInfoResponse{}, err
+}
+
+// GetInstanceInfoCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetInstanceInfoCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getInstanceInfoCalledCnt))
+}
+
+// SelfTest runs the daemon self-test.
+func (cli *FakeDatabaseClient) SelfTest(ctx context.Context, in *dbdpb.SelfTestRequest, opts ...grpc.CallOption) (*dbdpb.SelfTestResponse, error) {
+	atomic.AddInt32(&cli.selfTestCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("SelfTest")
+	if resp != nil {
+		return resp.(*dbdpb.SelfTestResponse), err
+	}
+	return &dbdpb.SelfTestResponse{}, err
+}
+
+// SelfTestCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SelfTestCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.selfTestCalledCnt))
+}
+
+// PrepareForStorageMigration reopens the PDBs read only.
+func (cli *FakeDatabaseClient) PrepareForStorageMigration(ctx context.Context, in *dbdpb.PrepareForStorageMigrationRequest, opts ...grpc.CallOption) (*dbdpb.PrepareForStorageMigrationResponse, error) {
+	atomic.AddInt32(&cli.prepareForStorageMigrationCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("PrepareForStorageMigration")
+	if resp != nil {
+		return resp.(*dbdpb.PrepareForStorageMigrationResponse), err
+	}
+	return &dbdpb.PrepareForStorageMigrationResponse{}, err
+}
+
+// PrepareForStorageMigrationCalledCnt returns call count.
+func (cli *FakeDatabaseClient) PrepareForStorageMigrationCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.prepareForStorageMigrationCalledCnt))
+}
+
+// CompleteStorageMigration reopens the PDBs read write.
+func (cli *FakeDatabaseClient) CompleteStorageMigration(ctx context.Context, in *dbdpb.CompleteStorageMigrationRequest, opts ...grpc.CallOption) (*dbdpb.CompleteStorageMigrationResponse, error) {
+	atomic.AddInt32(&cli.completeStorageMigrationCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("CompleteStorageMigration")
+	if resp != nil {
+		return resp.(*dbdpb.CompleteStorageMigrationResponse), err
+	}
+	return &dbdpb.CompleteStorageMigrationResponse{}, err
+}
+
+// CompleteStorageMigrationCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CompleteStorageMigrationCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.completeStorageMigrationCalledCnt))
+}
+
+// ValidateOratab checks the oratab file.
+func (cli *FakeDatabaseClient) ValidateOratab(ctx context.Context, in *dbdpb.ValidateOratabRequest, opts ...grpc.CallOption) (*dbdpb.ValidateOratabResponse, error) {
+	atomic.AddInt32(&cli.validateOratabCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ValidateOratab")
+	if resp != nil {
+		return resp.(*dbdpb.ValidateOratabResponse), err
+	}
+	return &dbdpb.ValidateOratabResponse{}, err
+}
+
+// ValidateOratabCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ValidateOratabCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.validateOratabCalledCnt))
+}
+
+// CreateDataPumpDir creates the Data Pump directory of a PDB.
+func (cli *FakeDatabaseClient) CreateDataPumpDir(ctx context.Context, in *dbdpb.CreateDataPumpDirRequest, opts ...grpc.CallOption) (*dbdpb.CreateDataPumpDirResponse, error) {
+	atomic.AddInt32(&cli.createDataPumpDirCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("CreateDataPumpDir")
+	if resp != nil {
+		return resp.(*dbdpb.CreateDataPumpDirResponse), err
+	}
+	return &dbdpb.CreateDataPumpDirResponse{}, err
+}
+
+// CreateDataPumpDirCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateDataPumpDirCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createDataPumpDirCalledCnt))
+}
+
+// CheckStoragePermissions probes the permissions under a GCS prefix.
+func (cli *FakeDatabaseClient) CheckStoragePermissions(ctx context.Context, in *dbdpb.CheckStoragePermissionsRequest, opts ...grpc.CallOption) (*dbdpb.CheckStoragePermissionsResponse, error) {
+	atomic.AddInt32(&cli.checkStoragePermissionsCalledCnt, 1)
+	cli.GotCheckStoragePermissionsRequest = in
+	resp, err := cli.getMethodRespErr("CheckStoragePermissions")
+	if resp != nil {
+		return resp.(*dbdpb.CheckStoragePermissionsResponse), err
+	}
+	return &dbdpb.CheckStoragePermissionsResponse{}, err
+}
+
+// CheckStoragePermissionsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CheckStoragePermissionsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.checkStoragePermissionsCalledCnt))
+}
+
+// ConfigureInMemory sizes the In-Memory column store and marks objects INMEMORY.
+func (cli *FakeDatabaseClient) ConfigureInMemory(ctx context.Context, in *dbdpb.ConfigureInMemoryRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureInMemoryResponse, error) {
+	atomic.AddInt32(&cli.configureInMemoryCalledCnt, 1)
+	cli.GotConfigureInMemoryRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureInMemory")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureInMemoryResponse), err
+	}
+	return &dbdpb.ConfigureInMemoryResponse{}, err
+}
+
+// ConfigureInMemoryCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureInMemoryCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureInMemoryCalledCnt))
+}
+
+// GetInMemoryStatus reports the In-Memory column store status.
+func (cli *FakeDatabaseClient) GetInMemoryStatus(ctx context.Context, in *dbdpb.GetInMemoryStatusRequest, opts ...grpc.CallOption) (*dbdpb.GetInMemoryStatusResponse, error) {
+	atomic.AddInt32(&cli.getInMemoryStatusCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetInMemoryStatus")
+	if resp != nil {
+		return resp.(*dbdpb.GetInMemoryStatusResponse), err
+	}
+	return &dbdpb.GetInMemoryStatusResponse{}, err
+}
+
+// GetInMemoryStatusCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetInMemoryStatusCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getInMemoryStatusCalledCnt))
+}
+
+// MaintainPartitions maintains the partitions of a table.
+func (cli *FakeDatabaseClient) MaintainPartitions(ctx context.Context, in *dbdpb.MaintainPartitionsRequest, opts ...grpc.CallOption) (*dbdpb.MaintainPartitionsResponse, error) {
+	atomic.AddInt32(&cli.maintainPartitionsCalledCnt, 1)
+	cli.GotMaintainPartitionsRequest = in
+	resp, err := cli.getMethodRespErr("MaintainPartitions")
+	if resp != nil {
+		return resp.(*dbdpb.MaintainPartitionsResponse), err
+	}
+	return &dbdpb.MaintainPartitionsResponse{}, err
+}
+
+// MaintainPartitionsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) MaintainPartitionsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.maintainPartitionsCalledCnt))
+}
+
+// RunSQLTuningAdvisor runs the SQL Tuning Advisor for a SQL id.
+func (cli *FakeDatabaseClient) RunSQLTuningAdvisor(ctx context.Context, in *dbdpb.RunSQLTuningAdvisorRequest, opts ...grpc.CallOption) (*dbdpb.RunSQLTuningAdvisorResponse, error) {
+	atomic.AddInt32(&cli.runSQLTuningAdvisorCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("RunSQLTuningAdvisor")
+	if resp != nil {
+		return resp.(*dbdpb.RunSQLTuningAdvisorResponse), err
+	}
+	return &dbdpb.RunSQLTuningAdvisorResponse{}, err
+}
+
+// RunSQLTuningAdvisorCalledCnt returns call count.
+func (cli *FakeDatabaseClient) RunSQLTuningAdvisorCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.runSQLTuningAdvisorCalledCnt))
+}
+
+// CreateAWRBaseline creates an AWR baseline.
+func (cli *FakeDatabaseClient) CreateAWRBaseline(ctx context.Context, in *dbdpb.CreateAWRBaselineRequest, opts ...grpc.CallOption) (*dbdpb.CreateAWRBaselineResponse, error) {
+	atomic.AddInt32(&cli.createAWRBaselineCalledCnt, 1)
+	cli.GotCreateAWRBaselineRequests = append(cli.GotCreateAWRBaselineRequests, in)
+	resp, err := cli.getMethodRespErr("CreateAWRBaseline")
+	if resp != nil {
+		return resp.(*dbdpb.CreateAWRBaselineResponse), err
+	}
+	return &dbdpb.CreateAWRBaselineResponse{}, err
+}
+
+// CreateAWRBaselineCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateAWRBaselineCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createAWRBaselineCalledCnt))
+}
+
+// GetSysauxOccupants reports the SYSAUX occupants.
+func (cli *FakeDatabaseClient) GetSysauxOccupants(ctx context.Context, in *dbdpb.GetSysauxOccupantsRequest, opts ...grpc.CallOption) (*dbdpb.GetSysauxOccupantsResponse, error) {
+	atomic.AddInt32(&cli.getSysauxOccupantsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetSysauxOccupants")
+	if resp != nil {
+		return resp.(*dbdpb.GetSysauxOccupantsResponse), err
+	}
+	return &dbdpb.GetSysauxOccupantsResponse{}, err
+}
+
+// GetSysauxOccupantsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetSysauxOccupantsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getSysauxOccupantsCalledCnt))
+}
+
+// PurgeSysaux purges SYSAUX occupants.
+func (cli *FakeDatabaseClient) PurgeSysaux(ctx context.Context, in *dbdpb.PurgeSysauxRequest, opts ...grpc.CallOption) (*dbdpb.PurgeSysauxResponse, error) {
+	atomic.AddInt32(&cli.purgeSysauxCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("PurgeSysaux")
+	if resp != nil {
+		return resp.(*dbdpb.PurgeSysauxResponse), err
+	}
+	return &dbdpb.PurgeSysauxResponse{}, err
+}
+
+// PurgeSysauxCalledCnt returns call count.
+func (cli *FakeDatabaseClient) PurgeSysauxCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.purgeSysauxCalledCnt))
+}
+
+// ConfigureAWR sets the AWR snapshot settings.
+func (cli *FakeDatabaseClient) ConfigureAWR(ctx context.Context, in *dbdpb.ConfigureAWRRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureAWRResponse, error) {
+	atomic.AddInt32(&cli.configureAWRCalledCnt, 1)
+	cli.GotConfigureAWRRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureAWR")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureAWRResponse), err
+	}
+	return &dbdpb.ConfigureAWRResponse{}, err
+}
+
+// ConfigureAWRCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureAWRCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureAWRCalledCnt))
+}
+
+// GetHostStats returns the host stats of the database container.
+func (cli *FakeDatabaseClient) GetHostStats(ctx context.Context, in *dbdpb.GetHostStatsRequest, opts ...grpc.CallOption) (*dbdpb.GetHostStatsResponse, error) {
+	atomic.AddInt32(&cli.getHostStatsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetHostStats")
+	if resp != nil {
+		return resp.(*dbdpb.GetHostStatsResponse), err
+	}
+	return &dbdpb.GetHostStatsResponse{}, err
+}
+
+// GetHostStatsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetHostStatsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getHostStatsCalledCnt))
+}
+
+// GrowMount verifies the filesystem of a mount grew.
+func (cli *FakeDatabaseClient) GrowMount(ctx context.Context, in *dbdpb.GrowMountRequest, opts ...grpc.CallOption) (*dbdpb.GrowMountResponse, error) {
+	atomic.AddInt32(&cli.growMountCalledCnt, 1)
+	cli.GotGrowMountRequest = in
+	resp, err := cli.getMethodRespErr("GrowMount")
+	if resp != nil {
+		return resp.(*dbdpb.GrowMountResponse), err
+	}
+	return &dbdpb.GrowMountResponse{}, err
+}
+
+// GrowMountCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GrowMountCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.growMountCalledCnt))
+}
+
+// GetFeatureUsage reports the feature usage and the license violations.
+func (cli *FakeDatabaseClient) GetFeatureUsage(ctx context.Context, in *dbdpb.GetFeatureUsageRequest, opts ...grpc.CallOption) (*dbdpb.GetFeatureUsageResponse, error) {
+	atomic.AddInt32(&cli.getFeatureUsageCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetFeatureUsage")
+	if resp != nil {
+		return resp.(*dbdpb.GetFeatureUsageResponse), err
+	}
+	return &dbdpb.GetFeatureUsageResponse{}, err
+}
+
+// GetFeatureUsageCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetFeatureUsageCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getFeatureUsageCalledCnt))
+}
+
+// SetLicense declares the license of the database.
+func (cli *FakeDatabaseClient) SetLicense(ctx context.Context, in *dbdpb.SetLicenseRequest, opts ...grpc.CallOption) (*dbdpb.SetLicenseResponse, error) {
+	atomic.AddInt32(&cli.setLicenseCalledCnt, 1)
+	cli.GotSetLicenseRequest = in
+	resp, err := cli.getMethodRespErr("SetLicense")
+	if resp != nil {
+		return resp.(*dbdpb.SetLicenseResponse), err
+	}
+	return &dbdpb.SetLicenseResponse{}, err
+}
+
+// SetLicenseCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetLicenseCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setLicenseCalledCnt))
+}
+
+// GetDefaultTablespaces returns the default tablespaces of a PDB.
+func (cli *FakeDatabaseClient) GetDefaultTablespaces(ctx context.Context, in *dbdpb.GetDefaultTablespacesRequest, opts ...grpc.CallOption) (*dbdpb.GetDefaultTablespacesResponse, error) {
+	atomic.AddInt32(&cli.getDefaultTablespacesCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetDefaultTablespaces")
+	if resp != nil {
+		return resp.(*dbdpb.GetDefaultTablespacesResponse), err
+	}
+	return &dbdpb.GetDefaultTablespacesResponse{}, err
+}
+
+// GetDefaultTablespacesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetDefaultTablespacesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getDefaultTablespacesCalledCnt))
+}
+
+// SetDefaultTablespaces sets the default tablespaces of a PDB.
+func (cli *FakeDatabaseClient) SetDefaultTablespaces(ctx context.Context, in *dbdpb.SetDefaultTablespacesRequest, opts ...grpc.CallOption) (*dbdpb.SetDefaultTablespacesResponse, error) {
+	atomic.AddInt32(&cli.setDefaultTablespacesCalledCnt, 1)
+	cli.GotSetDefaultTablespacesRequest = in
+	resp, err := cli.getMethodRespErr("SetDefaultTablespaces")
+	if resp != nil {
+		return resp.(*dbdpb.SetDefaultTablespacesResponse), err
+	}
+	return &dbdpb.SetDefaultTablespacesResponse{}, err
+}
+
+// SetDefaultTablespacesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetDefaultTablespacesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setDefaultTablespacesCalledCnt))
+}
+
+// SetUserQuota sets the quotas of a user.
+func (cli *FakeDatabaseClient) SetUserQuota(ctx context.Context, in *dbdpb.SetUserQuotaRequest, opts ...grpc.CallOption) (*dbdpb.SetUserQuotaResponse, error) {
+	atomic.AddInt32(&cli.setUserQuotaCalledCnt, 1)
+	cli.GotSetUserQuotaRequests = append(cli.GotSetUserQuotaRequests, in)
+	resp, err := cli.getMethodRespErr("SetUserQuota")
+	if resp != nil {
+		return resp.(*dbdpb.SetUserQuotaResponse), err
+	}
+	return &dbdpb.SetUserQuotaResponse{}, err
+}
+
+// SetUserQuotaCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetUserQuotaCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setUserQuotaCalledCnt))
+}
+
+// GetBlockingSessions returns the chains of blocked sessions.
+func (cli *FakeDatabaseClient) GetBlockingSessions(ctx context.Context, in *dbdpb.GetBlockingSessionsRequest, opts ...grpc.CallOption) (*dbdpb.GetBlockingSessionsResponse, error) {
+	atomic.AddInt32(&cli.getBlockingSessionsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetBlockingSessions")
+	if resp != nil {
+		return resp.(*dbdpb.GetBlockingSessionsResponse), err
+	}
+	return &dbdpb.GetBlockingSessionsResponse{}, err
+}
+
+// GetBlockingSessionsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetBlockingSessionsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getBlockingSessionsCalledCnt))
+}
+
+// GetLongRunningOps returns the running long operations.
+func (cli *FakeDatabaseClient) GetLongRunningOps(ctx context.Context, in *dbdpb.GetLongRunningOpsRequest, opts ...grpc.CallOption) (*dbdpb.GetLongRunningOpsResponse, error) {
+	atomic.AddInt32(&cli.getLongRunningOpsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetLongRunningOps")
+	if resp != nil {
+		return resp.(*dbdpb.GetLongRunningOpsResponse), err
+	}
+	return &dbdpb.GetLongRunningOpsResponse{}, err
+}
+
+// GetLongRunningOpsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetLongRunningOpsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getLongRunningOpsCalledCnt))
+}
+
+// GetDeadlocks returns the deadlocks of the alert log.
+func (cli *FakeDatabaseClient) GetDeadlocks(ctx context.Context, in *dbdpb.GetDeadlocksRequest, opts ...grpc.CallOption) (*dbdpb.GetDeadlocksResponse, error) {
+	atomic.AddInt32(&cli.getDeadlocksCalledCnt, 1)
+	cli.GotGetDeadlocksRequest = in
+	resp, err := cli.getMethodRespErr("GetDeadlocks")
+	if resp != nil {
+		return resp.(*dbdpb.GetDeadlocksResponse), err
+	}
+	return &dbdpb.GetDeadlocksResponse{}, err
+}
+
+// GetDeadlocksCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetDeadlocksCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getDeadlocksCalledCnt))
+}
+
+// StartOnlineRedefinition starts redefining a table online.
+func (cli *FakeDatabaseClient) StartOnlineRedefinition(ctx context.Context, in *dbdpb.StartOnlineRedefinitionRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	atomic.AddInt32(&cli.startOnlineRedefinitionCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("StartOnlineRedefinition")
+	if resp != nil {
+		return resp.(*lropb.Operation), err
+	}
+	return &lropb.Operation{Done: true}, err
+}
+
+// StartOnlineRedefinitionCalledCnt returns call count.
+func (cli *FakeDatabaseClient) StartOnlineRedefinitionCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.startOnlineRedefinitionCalledCnt))
+}
+
+// GetStaleStats returns the tables with stale statistics.
+func (cli *FakeDatabaseClient) GetStaleStats(ctx context.Context, in *dbdpb.GetStaleStatsRequest, opts ...grpc.CallOption) (*dbdpb.GetStaleStatsResponse, error) {
+	atomic.AddInt32(&cli.getStaleStatsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetStaleStats")
+	if resp != nil {
+		return resp.(*dbdpb.GetStaleStatsResponse), err
+	}
+	return &dbdpb.GetStaleStatsResponse{}, err
+}
+
+// GetStaleStatsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetStaleStatsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getStaleStatsCalledCnt))
+}
+
+// GatherStats gathers the statistics of tables.
+func (cli *FakeDatabaseClient) GatherStats(ctx context.Context, in *dbdpb.GatherStatsRequest, opts ...grpc.CallOption) (*dbdpb.GatherStatsResponse, error) {
+	atomic.AddInt32(&cli.gatherStatsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GatherStats")
+	if resp != nil {
+		return resp.(*dbdpb.GatherStatsResponse), err
+	}
+	return &dbdpb.GatherStatsResponse{}, err
+}
+
+// GatherStatsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GatherStatsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.gatherStatsCalledCnt))
+}
+
+// GetSQLMonitorReport returns the SQL Monitor report of an execution.
+func (cli *FakeDatabaseClient) GetSQLMonitorReport(ctx context.Context, in *dbdpb.GetSQLMonitorReportRequest, opts ...grpc.CallOption) (*dbdpb.GetSQLMonitorReportResponse, error) {
+	atomic.AddInt32(&cli.getSQLMonitorReportCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetSQLMonitorReport")
+	if resp != nil {
+		return resp.(*dbdpb.GetSQLMonitorReportResponse), err
+	}
+	return &dbdpb.GetSQLMonitorReportResponse{}, err
+}
+
+// GetSQLMonitorReportCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetSQLMonitorReportCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getSQLMonitorReportCalledCnt))
+}
+
+// CaptureSQLMonitorReports uploads the reports of long executions.
+func (cli *FakeDatabaseClient) CaptureSQLMonitorReports(ctx context.Context, in *dbdpb.CaptureSQLMonitorReportsRequest, opts ...grpc.CallOption) (*dbdpb.CaptureSQLMonitorReportsResponse, error) {
+	atomic.AddInt32(&cli.captureSQLMonitorReportsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("CaptureSQLMonitorReports")
+	if resp != nil {
+		return resp.(*dbdpb.CaptureSQLMonitorReportsResponse), err
+	}
+	return &dbdpb.CaptureSQLMonitorReportsResponse{}, err
+}
+
+// CaptureSQLMonitorReportsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CaptureSQLMonitorReportsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.captureSQLMonitorReportsCalledCnt))
+}
+
+// SetOwnership chowns paths to the oracle user and group.
+func (cli *FakeDatabaseClient) SetOwnership(ctx context.Context, in *dbdpb.SetOwnershipRequest, opts ...grpc.CallOption) (*dbdpb.SetOwnershipResponse, error) {
+	atomic.AddInt32(&cli.setOwnershipCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("SetOwnership")
+	if resp != nil {
+		return resp.(*dbdpb.SetOwnershipResponse), err
+	}
+	return &dbdpb.SetOwnershipResponse{}, err
+}
+
+// SetOwnershipCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetOwnershipCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setOwnershipCalledCnt))
+}
+
+// FixOwnership fixes the ownership of the data and config directories.
+func (cli *FakeDatabaseClient) FixOwnership(ctx context.Context, in *dbdpb.FixOwnershipRequest, opts ...grpc.CallOption) (*dbdpb.FixOwnershipResponse, error) {
+	atomic.AddInt32(&cli.fixOwnershipCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("FixOwnership")
+	if resp != nil {
+		return resp.(*dbdpb.FixOwnershipResponse), err
+	}
+	return &dbdpb.FixOwnershipResponse{}, err
+}
+
+// FixOwnershipCalledCnt returns call count.
+func (cli *FakeDatabaseClient) FixOwnershipCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.fixOwnershipCalledCnt))
+}
+
+// StreamOperationLog streams the log of an operation.
+func (cli *FakeDatabaseClient) StreamOperationLog(ctx context.Context, in *dbdpb.StreamOperationLogRequest, opts ...grpc.CallOption) (dbdpb.DatabaseDaemon_StreamOperationLogClient, error) {
+	atomic.AddInt32(&cli.streamOperationLogCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("StreamOperationLog")
+	if resp != nil {
+		return resp.(dbdpb.DatabaseDaemon_StreamOperationLogClient), err
+	}
+	return nil, err
+}
+
+// StreamOperationLogCalledCnt returns call count.
+func (cli *FakeDatabaseClient) StreamOperationLogCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.streamOperationLogCalledCnt))
+}
+
+// GetNLSSettings reports the NLS parameters.
+func (cli *FakeDatabaseClient) GetNLSSettings(ctx context.Context, in *dbdpb.GetNLSSettingsRequest, opts ...grpc.CallOption) (*dbdpb.GetNLSSettingsResponse, error) {
+	atomic.AddInt32(&cli.getNLSSettingsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetNLSSettings")
+	if resp != nil {
+		return resp.(*dbdpb.GetNLSSettingsResponse), err
+	}
+	return &dbdpb.GetNLSSettingsResponse{}, err
+}
+
+// GetNLSSettingsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetNLSSettingsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getNLSSettingsCalledCnt))
+}
+
+// SetNLSSettings sets the NLS parameters.
+func (cli *FakeDatabaseClient) SetNLSSettings(ctx context.Context, in *dbdpb.SetNLSSettingsRequest, opts ...grpc.CallOption) (*dbdpb.SetNLSSettingsResponse, error) {
+	atomic.AddInt32(&cli.setNLSSettingsCalledCnt, 1)
+	cli.GotSetNLSSettingsRequests = append(cli.GotSetNLSSettingsRequests, in)
+	resp, err := cli.getMethodRespErr("SetNLSSettings")
+	if resp != nil {
+		return resp.(*dbdpb.SetNLSSettingsResponse), err
+	}
+	return &dbdpb.SetNLSSettingsResponse{}, err
+}
+
+// SetNLSSettingsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetNLSSettingsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setNLSSettingsCalledCnt))
+}
+
+// ConfigurePDBResources sets the resource parameters of a PDB.
+func (cli *FakeDatabaseClient) ConfigurePDBResources(ctx context.Context, in *dbdpb.ConfigurePDBResourcesRequest, opts ...grpc.CallOption) (*dbdpb.ConfigurePDBResourcesResponse, error) {
+	atomic.AddInt32(&cli.configurePDBResourcesCalledCnt, 1)
+	cli.GotConfigurePDBResourcesRequests = append(cli.GotConfigurePDBResourcesRequests, in)
+	resp, err := cli.getMethodRespErr("ConfigurePDBResources")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigurePDBResourcesResponse), err
+	}
+	return &dbdpb.ConfigurePDBResourcesResponse{}, err
+}
+
+// ConfigurePDBResourcesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigurePDBResourcesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configurePDBResourcesCalledCnt))
+}
+
+// ConfigureRowLevelSecurity configures the application contexts and VPD policies.
+func (cli *FakeDatabaseClient) ConfigureRowLevelSecurity(ctx context.Context, in *dbdpb.ConfigureRowLevelSecurityRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureRowLevelSecurityResponse, error) {
+	atomic.AddInt32(&cli.configureRowLevelSecurityCalledCnt, 1)
+	cli.GotConfigureRowLevelSecurityRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureRowLevelSecurity")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureRowLevelSecurityResponse), err
+	}
+	return &dbdpb.ConfigureRowLevelSecurityResponse{}, err
+}
+
+// ConfigureRowLevelSecurityCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureRowLevelSecurityCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureRowLevelSecurityCalledCnt))
+}
+
+// FullInstanceExportAsync exports the PDBs of the instance.
+func (cli *FakeDatabaseClient) FullInstanceExportAsync(ctx context.Context, in *dbdpb.FullInstanceExportAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	atomic.AddInt32(&cli.fullInstanceExportAsyncCalledCnt, 1)
+	return &lropb.Operation{Done: false}, nil
+}
+
+// FullInstanceExportAsyncCalledCnt returns call count.
+func (cli *FakeDatabaseClient) FullInstanceExportAsyncCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.fullInstanceExportAsyncCalledCnt))
+}
+
+// FullInstanceImportAsync imports a full instance export.
+func (cli *FakeDatabaseClient) FullInstanceImportAsync(ctx context.Context, in *dbdpb.FullInstanceImportAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	atomic.AddInt32(&cli.fullInstanceImportAsyncCalledCnt, 1)
+	return &lropb.Operation{Done: false}, nil
+}
+
+// FullInstanceImportAsyncCalledCnt returns call count.
+func (cli *FakeDatabaseClient) FullInstanceImportAsyncCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.fullInstanceImportAsyncCalledCnt))
+}
+
+// GetStandbyApplyStatus returns the apply status of a primary.
+func (cli *FakeDatabaseClient) GetStandbyApplyStatus(ctx context.Context, in *dbdpb.GetStandbyApplyStatusRequest, opts ...grpc.CallOption) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+	atomic.AddInt32(&cli.getStandbyApplyStatusCalledCnt, 1)
+	return &dbdpb.GetStandbyApplyStatusResponse{DatabaseRole: "PRIMARY", TransportLagSeconds: -1, ApplyLagSeconds: -1}, nil
+}
+
+// GetStandbyApplyStatusCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetStandbyApplyStatusCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getStandbyApplyStatusCalledCnt))
+}
+
+// ResolveArchiveLogGap resolves no gap.
+func (cli *FakeDatabaseClient) ResolveArchiveLogGap(ctx context.Context, in *dbdpb.ResolveArchiveLogGapRequest, opts ...grpc.CallOption) (*dbdpb.ResolveArchiveLogGapResponse, error) {
+	atomic.AddInt32(&cli.resolveArchiveLogGapCalledCnt, 1)
+	return &dbdpb.ResolveArchiveLogGapResponse{}, nil
+}
+
+// ResolveArchiveLogGapCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ResolveArchiveLogGapCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.resolveArchiveLogGapCalledCnt))
+}
+
+// ConfigureRedoTransport configures the redo transport to the standby.
+func (cli *FakeDatabaseClient) ConfigureRedoTransport(ctx context.Context, in *dbdpb.ConfigureRedoTransportRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureRedoTransportResponse, error) {
+	atomic.AddInt32(&cli.configureRedoTransportCalledCnt, 1)
+	return &dbdpb.ConfigureRedoTransportResponse{}, nil
+}
+
+// ConfigureRedoTransportCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureRedoTransportCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureRedoTransportCalledCnt))
+}
+
+// GetInstalledOptions returns the components of dba_registry.
+func (cli *FakeDatabaseClient) GetInstalledOptions(ctx context.Context, in *dbdpb.GetInstalledOptionsRequest, opts ...grpc.CallOption) (*dbdpb.GetInstalledOptionsResponse, error) {
+	atomic.AddInt32(&cli.getInstalledOptionsCalledCnt, 1)
+	cli.GotGetInstalledOptionsRequest = in
+	resp, err := cli.getMethodRespErr("GetInstalledOptions")
+	if resp != nil {
+		return resp.(*dbdpb.GetInstalledOptionsResponse), err
+	}
+	return &dbdpb.GetInstalledOptionsResponse{}, err
+}
+
+// GetInstalledOptionsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetInstalledOptionsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getInstalledOptionsCalledCnt))
+}
+
+// RunSQLScriptFromGCS runs a SQL script.
+func (cli *FakeDatabaseClient) RunSQLScriptFromGCS(ctx context.Context, in *dbdpb.RunSQLScriptFromGCSRequest, opts ...grpc.CallOption) (*dbdpb.RunSQLScriptFromGCSResponse, error) {
+	atomic.AddInt32(&cli.runSQLScriptFromGCSCalledCnt, 1)
+	cli.GotRunSQLScriptFromGCSRequests = append(cli.GotRunSQLScriptFromGCSRequests, in)
+	if cli.RunSQLScriptFromGCSFunc != nil {
+		return cli.RunSQLScriptFromGCSFunc(in)
+	}
+	return &dbdpb.RunSQLScriptFromGCSResponse{}, nil
+}
+
+// RunSQLScriptFromGCSCalledCnt returns call count.
+func (cli *FakeDatabaseClient) RunSQLScriptFromGCSCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.runSQLScriptFromGCSCalledCnt))
+}
+
+// SwitchOracleHomeAsync switches the database to a new Oracle home.
+func (cli *FakeDatabaseClient) SwitchOracleHomeAsync(context.Context, *dbdpb.SwitchOracleHomeAsyncRequest, ...grpc.CallOption) (*lropb.Operation, error) {
+	atomic.AddInt32(&cli.switchOracleHomeAsyncCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("SwitchOracleHomeAsync")
+	if resp != nil {
+		return resp.(*lropb.Operation), err
+	}
+	return &lropb.Operation{Done: true}, err
+}
+
+// SwitchOracleHomeAsyncCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SwitchOracleHomeAsyncCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.switchOracleHomeAsyncCalledCnt))
+}
+
+// CheckRestoreCompatibility checks that a backup can be restored.
+func (cli *FakeDatabaseClient) CheckRestoreCompatibility(ctx context.Context, in *dbdpb.CheckRestoreCompatibilityRequest, opts ...grpc.CallOption) (*dbdpb.CheckRestoreCompatibilityResponse, error) {
+	atomic.AddInt32(&cli.checkRestoreCompatibilityCalledCnt, 1)
+	cli.GotCheckRestoreCompatibilityRequest = in
+	resp, err := cli.getMethodRespErr("CheckRestoreCompatibility")
+	if resp != nil {
+		return resp.(*dbdpb.CheckRestoreCompatibilityResponse), err
+	}
+	return &dbdpb.CheckRestoreCompatibilityResponse{Compatible: true}, err
+}
+
+// CheckRestoreCompatibilityCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CheckRestoreCompatibilityCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.checkRestoreCompatibilityCalledCnt))
+}
+
+// WriteBackupMetadata writes the metadata object of a backup.
+func (cli *FakeDatabaseClient) WriteBackupMetadata(ctx context.Context, in *dbdpb.WriteBackupMetadataRequest, opts ...grpc.CallOption) (*dbdpb.WriteBackupMetadataResponse, error) {
+	atomic.AddInt32(&cli.writeBackupMetadataCalledCnt, 1)
+	cli.GotWriteBackupMetadataRequest = in
+	_, err := cli.getMethodRespErr("WriteBackupMetadata")
+	return &dbdpb.WriteBackupMetadataResponse{}, err
+}
+
+// WriteBackupMetadataCalledCnt returns call count.
+func (cli *FakeDatabaseClient) WriteBackupMetadataCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.writeBackupMetadataCalledCnt))
+}
+
+// ReadBackupMetadata reads the metadata object of a backup.
+func (cli *FakeDatabaseClient) ReadBackupMetadata(ctx context.Context, in *dbdpb.ReadBackupMetadataRequest, opts ...grpc.CallOption) (*dbdpb.ReadBackupMetadataResponse, error) {
+	atomic.AddInt32(&cli.readBackupMetadataCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ReadBackupMetadata")
+	if resp != nil {
+		return resp.(*dbdpb.ReadBackupMetadataResponse), err
+	}
+	return &dbdpb.ReadBackupMetadataResponse{}, err
+}
+
+// ReadBackupMetadataCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ReadBackupMetadataCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.readBackupMetadataCalledCnt))
+}
+
+// ListRestorePoints lists the restore points of the database.
+func (cli *FakeDatabaseClient) ListRestorePoints(ctx context.Context, in *dbdpb.ListRestorePointsRequest, opts ...grpc.CallOption) (*dbdpb.ListRestorePointsResponse, error) {
+	atomic.AddInt32(&cli.listRestorePointsCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ListRestorePoints")
+	if resp != nil {
+		return resp.(*dbdpb.ListRestorePointsResponse), err
+	}
+	return &dbdpb.ListRestorePointsResponse{}, err
+}
+
+// ListRestorePointsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ListRestorePointsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.listRestorePointsCalledCnt))
+}
+
+// CreateRestorePoint creates a restore point.
+func (cli *FakeDatabaseClient) CreateRestorePoint(ctx context.Context, in *dbdpb.CreateRestorePointRequest, opts ...grpc.CallOption) (*dbdpb.CreateRestorePointResponse, error) {
+	atomic.AddInt32(&cli.createRestorePointCalledCnt, 1)
+	_, err := cli.getMethodRespErr("CreateRestorePoint")
+	return &dbdpb.CreateRestorePointResponse{}, err
+}
+
+// CreateRestorePointCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateRestorePointCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createRestorePointCalledCnt))
+}
+
+// DropRestorePoint drops a restore point.
+func (cli *FakeDatabaseClient) DropRestorePoint(ctx context.Context, in *dbdpb.DropRestorePointRequest, opts ...grpc.CallOption) (*dbdpb.DropRestorePointResponse, error) {
+	atomic.AddInt32(&cli.dropRestorePointCalledCnt, 1)
+	cli.GotDropRestorePointRequests = append(cli.GotDropRestorePointRequests, in)
+	_, err := cli.getMethodRespErr("DropRestorePoint")
+	return &dbdpb.DropRestorePointResponse{}, err
+}
+
+// DropRestorePointCalledCnt returns call count.
+func (cli *FakeDatabaseClient) DropRestorePointCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.dropRestorePointCalledCnt))
+}
+
+// ValidateSnapshotFiles checks that a snapshot of the volumes is restorable.
+func (cli *FakeDatabaseClient) ValidateSnapshotFiles(ctx context.Context, in *dbdpb.ValidateSnapshotFilesRequest, opts ...grpc.CallOption) (*dbdpb.ValidateSnapshotFilesResponse, error) {
+	atomic.AddInt32(&cli.validateSnapshotFilesCalledCnt, 1)
+	cli.GotValidateSnapshotFilesRequest = in
+	resp, err := cli.getMethodRespErr("ValidateSnapshotFiles")
+	if resp != nil {
+		return resp.(*dbdpb.ValidateSnapshotFilesResponse), err
+	}
+	return &dbdpb.ValidateSnapshotFilesResponse{}, err
+}
+
+// ValidateSnapshotFilesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ValidateSnapshotFilesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.validateSnapshotFilesCalledCnt))
+}
+
+// GetRedoRate returns the redo generated by the database.
+func (cli *FakeDatabaseClient) GetRedoRate(ctx context.Context, in *dbdpb.GetRedoRateRequest, opts ...grpc.CallOption) (*dbdpb.GetRedoRateResponse, error) {
+	atomic.AddInt32(&cli.getRedoRateCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetRedoRate")
+	if resp != nil {
+		return resp.(*dbdpb.GetRedoRateResponse), err
+	}
+	return &dbdpb.GetRedoRateResponse{}, err
+}
+
+// GetRedoRateCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetRedoRateCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getRedoRateCalledCnt))
+}
+
+// RotateWalletPassword changes the TDE keystore password.
+func (cli *FakeDatabaseClient) RotateWalletPassword(ctx context.Context, in *dbdpb.RotateWalletPasswordRequest, opts ...grpc.CallOption) (*dbdpb.RotateWalletPasswordResponse, error) {
+	atomic.AddInt32(&cli.rotateWalletPasswordCalledCnt, 1)
+	cli.GotRotateWalletPasswordRequests = append(cli.GotRotateWalletPasswordRequests, in)
+	resp, err := cli.getMethodRespErr("RotateWalletPassword")
+	if resp != nil {
+		return resp.(*dbdpb.RotateWalletPasswordResponse), err
+	}
+	return &dbdpb.RotateWalletPasswordResponse{}, err
+}
+
+// RotateWalletPasswordCalledCnt returns call count.
+func (cli *FakeDatabaseClient) RotateWalletPasswordCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.rotateWalletPasswordCalledCnt))
+}
+
+// ConfigureEditions creates and drops editions and sets the default edition.
+func (cli *FakeDatabaseClient) ConfigureEditions(ctx context.Context, in *dbdpb.ConfigureEditionsRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureEditionsResponse, error) {
+	atomic.AddInt32(&cli.configureEditionsCalledCnt, 1)
+	cli.GotConfigureEditionsRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureEditions")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureEditionsResponse), err
+	}
+	return &dbdpb.ConfigureEditionsResponse{}, err
+}
+
+// ConfigureEditionsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureEditionsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureEditionsCalledCnt))
+}
+
+// GetPGAUsage reports the PGA usage.
+func (cli *FakeDatabaseClient) GetPGAUsage(ctx context.Context, in *dbdpb.GetPGAUsageRequest, opts ...grpc.CallOption) (*dbdpb.GetPGAUsageResponse, error) {
+	atomic.AddInt32(&cli.getPGAUsageCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("GetPGAUsage")
+	if resp != nil {
+		return resp.(*dbdpb.GetPGAUsageResponse), err
+	}
+	return &dbdpb.GetPGAUsageResponse{}, err
+}
+
+// GetPGAUsageCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetPGAUsageCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getPGAUsageCalledCnt))
+}
+
+// SetSQLPlanCapture enables or disables the SQL plan baseline capture.
+func (cli *FakeDatabaseClient) SetSQLPlanCapture(ctx context.Context, in *dbdpb.SetSQLPlanCaptureRequest, opts ...grpc.CallOption) (*dbdpb.SetSQLPlanCaptureResponse, error) {
+	atomic.AddInt32(&cli.setSQLPlanCaptureCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("SetSQLPlanCapture")
+	if resp != nil {
+		return resp.(*dbdpb.SetSQLPlanCaptureResponse), err
+	}
+	return &dbdpb.SetSQLPlanCaptureResponse{}, err
+}
+
+// SetSQLPlanCaptureCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetSQLPlanCaptureCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setSQLPlanCaptureCalledCnt))
+}
+
+// GetSQLPlanBaselines reports the SQL plan baselines.
+func (cli *FakeDatabaseClient) GetSQLPlanBaselines(ctx context.Context, in *dbdpb.GetSQLPlanBaselinesRequest, opts ...grpc.CallOption) (*dbdpb.GetSQLPlanBaselinesResponse, error) {
+	atomic.AddInt32(&cli.getSQLPlanBaselinesCalledCnt, 1)
+	cli.GotGetSQLPlanBaselinesRequest = in
+	resp, err := cli.getMethodRespErr("GetSQLPlanBaselines")
+	if resp != nil {
+		return resp.(*dbdpb.GetSQLPlanBaselinesResponse), err
+	}
+	return &dbdpb.GetSQLPlanBaselinesResponse{}, err
+}
+
+// GetSQLPlanBaselinesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) GetSQLPlanBaselinesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.getSQLPlanBaselinesCalledCnt))
+}
+
+// SetTablespaceEncryptionPolicy sets the new tablespace encryption policy.
+func (cli *FakeDatabaseClient) SetTablespaceEncryptionPolicy(ctx context.Context, in *dbdpb.SetTablespaceEncryptionPolicyRequest, opts ...grpc.CallOption) (*dbdpb.SetTablespaceEncryptionPolicyResponse, error) {
+	atomic.AddInt32(&cli.setTablespaceEncryptionPolicyCalledCnt, 1)
+	cli.GotSetTablespaceEncryptionPolicyRequest = in
+	resp, err := cli.getMethodRespErr("SetTablespaceEncryptionPolicy")
+	if resp != nil {
+		return resp.(*dbdpb.SetTablespaceEncryptionPolicyResponse), err
+	}
+	return &dbdpb.SetTablespaceEncryptionPolicyResponse{}, err
+}
+
+// SetTablespaceEncryptionPolicyCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetTablespaceEncryptionPolicyCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setTablespaceEncryptionPolicyCalledCnt))
+}
+
+// RestoreValidationReportAsync starts a restore validation report.
+func (cli *FakeDatabaseClient) RestoreValidationReportAsync(ctx context.Context, in *dbdpb.RestoreValidationReportAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	atomic.AddInt32(&cli.restoreValidationReportAsyncCalledCnt, 1)
+	cli.GotRestoreValidationReportAsyncRequest = in
+	resp, err := cli.getMethodRespErr("RestoreValidationReportAsync")
+	if resp != nil {
+		return resp.(*lropb.Operation), err
+	}
+	return &lropb.Operation{Done: false}, err
+}
+
+// RestoreValidationReportAsyncCalledCnt returns call count.
+func (cli *FakeDatabaseClient) RestoreValidationReportAsyncCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.restoreValidationReportAsyncCalledCnt))
+}
+
+// DiffParametersAgainstBaseline compares the parameters with a baseline.
+func (cli *FakeDatabaseClient) DiffParametersAgainstBaseline(ctx context.Context, in *dbdpb.DiffParametersAgainstBaselineRequest, opts ...grpc.CallOption) (*dbdpb.DiffParametersAgainstBaselineResponse, error) {
+	atomic.AddInt32(&cli.diffParametersAgainstBaselineCalledCnt, 1)
+	cli.GotDiffParametersAgainstBaselineRequest = in
+	resp, err := cli.getMethodRespErr("DiffParametersAgainstBaseline")
+	if resp != nil {
+		return resp.(*dbdpb.DiffParametersAgainstBaselineResponse), err
+	}
+	return &dbdpb.DiffParametersAgainstBaselineResponse{}, err
+}
+
+// DiffParametersAgainstBaselineCalledCnt returns call count.
+func (cli *FakeDatabaseClient) DiffParametersAgainstBaselineCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.diffParametersAgainstBaselineCalledCnt))
+}
+
+// ConfigureFAN configures the services HA notifications and ONS.
+func (cli *FakeDatabaseClient) ConfigureFAN(ctx context.Context, in *dbdpb.ConfigureFANRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureFANResponse, error) {
+	atomic.AddInt32(&cli.configureFANCalledCnt, 1)
+	cli.GotConfigureFANRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureFAN")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureFANResponse), err
+	}
+	return &dbdpb.ConfigureFANResponse{}, err
+}
+
+// ConfigureFANCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureFANCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureFANCalledCnt))
+}
+
+// CreateManagedTrigger creates or replaces a managed trigger.
+func (cli *FakeDatabaseClient) CreateManagedTrigger(ctx context.Context, in *dbdpb.CreateManagedTriggerRequest, opts ...grpc.CallOption) (*dbdpb.CreateManagedTriggerResponse, error) {
+	atomic.AddInt32(&cli.createManagedTriggerCalledCnt, 1)
+	cli.GotCreateManagedTriggerRequest = in
+	resp, err := cli.getMethodRespErr("CreateManagedTrigger")
+	if resp != nil {
+		return resp.(*dbdpb.CreateManagedTriggerResponse), err
+	}
+	return &dbdpb.CreateManagedTriggerResponse{}, err
+}
+
+// CreateManagedTriggerCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateManagedTriggerCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createManagedTriggerCalledCnt))
+}
+
+// DropManagedTrigger drops a managed trigger.
+func (cli *FakeDatabaseClient) DropManagedTrigger(ctx context.Context, in *dbdpb.DropManagedTriggerRequest, opts ...grpc.CallOption) (*dbdpb.DropManagedTriggerResponse, error) {
+	atomic.AddInt32(&cli.dropManagedTriggerCalledCnt, 1)
+	cli.GotDropManagedTriggerRequest = in
+	resp, err := cli.getMethodRespErr("DropManagedTrigger")
+	if resp != nil {
+		return resp.(*dbdpb.DropManagedTriggerResponse), err
+	}
+	return &dbdpb.DropManagedTriggerResponse{}, err
+}
+
+// DropManagedTriggerCalledCnt returns call count.
+func (cli *FakeDatabaseClient) DropManagedTriggerCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.dropManagedTriggerCalledCnt))
+}
+
+// ListManagedTriggers lists the managed triggers.
+func (cli *FakeDatabaseClient) ListManagedTriggers(ctx context.Context, in *dbdpb.ListManagedTriggersRequest, opts ...grpc.CallOption) (*dbdpb.ListManagedTriggersResponse, error) {
+	atomic.AddInt32(&cli.listManagedTriggersCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("ListManagedTriggers")
+	if resp != nil {
+		return resp.(*dbdpb.ListManagedTriggersResponse), err
+	}
+	return &dbdpb.ListManagedTriggersResponse{}, err
+}
+
+// ListManagedTriggersCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ListManagedTriggersCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.listManagedTriggersCalledCnt))
+}
+
+// CheckStandbySynchronized checks the standby can switch over without data loss.
+func (cli *FakeDatabaseClient) CheckStandbySynchronized(ctx context.Context, in *dbdpb.CheckStandbySynchronizedRequest, opts ...grpc.CallOption) (*dbdpb.CheckStandbySynchronizedResponse, error) {
+	atomic.AddInt32(&cli.checkStandbySynchronizedCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("CheckStandbySynchronized")
+	if resp != nil {
+		return resp.(*dbdpb.CheckStandbySynchronizedResponse), err
+	}
+	return &dbdpb.CheckStandbySynchronizedResponse{Synchronized: true}, err
+}
+
+// CheckStandbySynchronizedCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CheckStandbySynchronizedCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.checkStandbySynchronizedCalledCnt))
+}
+
+// ExportRMANCatalog exports the RMAN catalog and a controlfile copy.
+func (cli *FakeDatabaseClient) ExportRMANCatalog(ctx context.Context, in *dbdpb.ExportRMANCatalogRequest, opts ...grpc.CallOption) (*dbdpb.ExportRMANCatalogResponse, error) {
+	atomic.AddInt32(&cli.exportRMANCatalogCalledCnt, 1)
+	cli.GotExportRMANCatalogRequest = in
+	resp, err := cli.getMethodRespErr("ExportRMANCatalog")
+	if resp != nil {
+		return resp.(*dbdpb.ExportRMANCatalogResponse), err
+	}
+	return &dbdpb.ExportRMANCatalogResponse{}, err
+}
+
+// ExportRMANCatalogCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ExportRMANCatalogCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.exportRMANCatalogCalledCnt))
+}
+
+// RunWriteCanary writes and reads back the canary row.
+func (cli *FakeDatabaseClient) RunWriteCanary(ctx context.Context, in *dbdpb.RunWriteCanaryRequest, opts ...grpc.CallOption) (*dbdpb.RunWriteCanaryResponse, error) {
+	atomic.AddInt32(&cli.runWriteCanaryCalledCnt, 1)
+	resp, err := cli.getMethodRespErr("RunWriteCanary")
+	if resp != nil {
+		return resp.(*dbdpb.RunWriteCanaryResponse), err
+	}
+	return &dbdpb.RunWriteCanaryResponse{}, err
+}
+
+// RunWriteCanaryCalledCnt returns call count.
+func (cli *FakeDatabaseClient) RunWriteCanaryCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.runWriteCanaryCalledCnt))
+}
+
+// ReconcilePDBs compares the PDBs with the desired ones.
+func (cli *FakeDatabaseClient) ReconcilePDBs(ctx context.Context, in *dbdpb.ReconcilePDBsRequest, opts ...grpc.CallOption) (*dbdpb.ReconcilePDBsResponse, error) {
+	atomic.AddInt32(&cli.reconcilePDBsCalledCnt, 1)
+	cli.GotReconcilePDBsRequest = in
+	resp, err := cli.getMethodRespErr("ReconcilePDBs")
+	if resp != nil {
+		return resp.(*dbdpb.ReconcilePDBsResponse), err
+	}
+	return &dbdpb.ReconcilePDBsResponse{}, err
+}
+
+// ReconcilePDBsCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ReconcilePDBsCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.reconcilePDBsCalledCnt))
+}
+
+// CheckPITRTarget checks that a backup can be recovered to a PITR target.
+func (cli *FakeDatabaseClient) CheckPITRTarget(ctx context.Context, in *dbdpb.CheckPITRTargetRequest, opts ...grpc.CallOption) (*dbdpb.CheckPITRTargetResponse, error) {
+	atomic.AddInt32(&cli.checkPITRTargetCalledCnt, 1)
+	cli.GotCheckPITRTargetRequest = in
+	resp, err := cli.getMethodRespErr("CheckPITRTarget")
+	if resp != nil {
+		return resp.(*dbdpb.CheckPITRTargetResponse), err
+	}
+	return &dbdpb.CheckPITRTargetResponse{Recoverable: true}, err
+}
+
+// CheckPITRTargetCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CheckPITRTargetCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.checkPITRTargetCalledCnt))
+}
+
+// ConfigureKeystore creates and opens the TDE keystore.
+func (cli *FakeDatabaseClient) ConfigureKeystore(ctx context.Context, in *dbdpb.ConfigureKeystoreRequest, opts ...grpc.CallOption) (*dbdpb.ConfigureKeystoreResponse, error) {
+	atomic.AddInt32(&cli.configureKeystoreCalledCnt, 1)
+	cli.GotConfigureKeystoreRequest = in
+	resp, err := cli.getMethodRespErr("ConfigureKeystore")
+	if resp != nil {
+		return resp.(*dbdpb.ConfigureKeystoreResponse), err
+	}
+	return &dbdpb.ConfigureKeystoreResponse{Status: "OPEN_NO_MASTER_KEY", WalletType: "PASSWORD"}, err
+}
+
+// ConfigureKeystoreCalledCnt returns call count.
+func (cli *FakeDatabaseClient) ConfigureKeystoreCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.configureKeystoreCalledCnt))
+}
+
+// SetMasterKey sets the TDE master keys of the containers without one.
+func (cli *FakeDatabaseClient) SetMasterKey(ctx context.Context, in *dbdpb.SetMasterKeyRequest, opts ...grpc.CallOption) (*dbdpb.SetMasterKeyResponse, error) {
+	atomic.AddInt32(&cli.setMasterKeyCalledCnt, 1)
+	cli.GotSetMasterKeyRequest = in
+	resp, err := cli.getMethodRespErr("SetMasterKey")
+	if resp != nil {
+		return resp.(*dbdpb.SetMasterKeyResponse), err
+	}
+	return &dbdpb.SetMasterKeyResponse{Status: "OPEN"}, err
+}
+
+// SetMasterKeyCalledCnt returns call count.
+func (cli *FakeDatabaseClient) SetMasterKeyCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.setMasterKeyCalledCnt))
+}
+
+// EncryptTablespaces encrypts tablespaces online.
+func (cli *FakeDatabaseClient) EncryptTablespaces(ctx context.Context, in *dbdpb.EncryptTablespacesRequest, opts ...grpc.CallOption) (*dbdpb.EncryptTablespacesResponse, error) {
+	atomic.AddInt32(&cli.encryptTablespacesCalledCnt, 1)
+	cli.GotEncryptTablespacesRequest = in
+	resp, err := cli.getMethodRespErr("EncryptTablespaces")
+	if resp != nil {
+		return resp.(*dbdpb.EncryptTablespacesResponse), err
+	}
+	return &dbdpb.EncryptTablespacesResponse{}, err
+}
+
+// EncryptTablespacesCalledCnt returns call count.
+func (cli *FakeDatabaseClient) EncryptTablespacesCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.encryptTablespacesCalledCnt))
+}
+
 // ApplyDataPatchAsync wrapper.
 func (cli *FakeDatabaseClient) ApplyDataPatchAsync(context.Context, *dbdpb.ApplyDataPatchAsyncRequest, ...grpc.CallOption) (*lropb.Operation, error) {
 	atomic.AddInt32(&cli.applyDataPatchAsyncCalledCnt, 1)
@@ -142,6 +1524,11 @@ func (cli *FakeDatabaseClient) BounceDatabase(ctx context.Context, in *dbdpb.Bou
 	return nil, nil
 }
 
+// BounceDatabaseCalledCnt returns call count.
+func (cli *FakeDatabaseClient) BounceDatabaseCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.bounceDatabaseCalledCnt))
+}
+
 // BounceListener RPC call to start/stop a listener.
 func (cli *FakeDatabaseClient) BounceListener(ctx context.Context, in *dbdpb.BounceListenerRequest, opts ...grpc.CallOption) (*dbdpb.BounceListenerResponse, error) {
 	panic("implement me")
@@ -156,6 +1543,10 @@ func (cli *FakeDatabaseClient) CheckDatabaseState(ctx context.Context, in *dbdpb
 // RunSQLPlus RPC call executes Oracle's sqlplus utility.
 func (cli *FakeDatabaseClient) RunSQLPlus(ctx context.Context, in *dbdpb.RunSQLPlusCMDRequest, opts ...grpc.CallOption) (*dbdpb.RunCMDResponse, error) {
 	atomic.AddInt32(&cli.runSQLPlusCalledCnt, 1)
+	cli.GotRunSQLPlusRequest = in
+	if cli.RunSQLPlusFunc != nil {
+		return cli.RunSQLPlusFunc(in)
+	}
 	return nil, nil
 }
 
@@ -205,6 +1596,11 @@ func (cli *FakeDatabaseClient) NID(ctx context.Context, in *dbdpb.NIDRequest, op
 	return &dbdpb.NIDResponse{}, nil
 }
 
+// NIDCalledCnt returns call count.
+func (cli *FakeDatabaseClient) NIDCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.nidCalledCnt))
+}
+
 // GetDatabaseType returns database type(eg. ORACLE_12_2_ENTERPRISE_NONCDB)
 func (cli *FakeDatabaseClient) GetDatabaseType(ctx context.Context, in *dbdpb.GetDatabaseTypeRequest, opts ...grpc.CallOption) (*dbdpb.GetDatabaseTypeResponse, error) {
 	panic("implement me")
@@ -251,6 +1647,11 @@ func (cli *FakeDatabaseClient) CreateListener(ctx context.Context, in *dbdpb.Cre
 	return &dbdpb.CreateListenerResponse{}, nil
 }
 
+// CreateListenerCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateListenerCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createListenerCalledCnt))
+}
+
 // FileExists runs a simple check to confirm whether a requested file
 // exists in a database container or not.
 // An example of where FileExists is used is a check on
@@ -278,6 +1679,7 @@ func (cli *FakeDatabaseClient) PhysicalRestoreAsync(ctx context.Context, in *dbd
 // DataPumpImportAsync imports data from a .dmp file to an existing PDB.
 func (cli *FakeDatabaseClient) DataPumpImportAsync(ctx context.Context, in *dbdpb.DataPumpImportAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
 	atomic.AddInt32(&cli.dataPumpImportAsyncCalledCnt, 1)
+	cli.GotDataPumpImportAsyncRequest = in
 	return &lropb.Operation{Done: false}, nil
 }
 
@@ -291,7 +1693,11 @@ func (cli *FakeDatabaseClient) DataPumpExportAsync(ctx context.Context, in *dbdp
 // request.
 func (cli *FakeDatabaseClient) ListOperations(ctx context.Context, in *lropb.ListOperationsRequest, opts ...grpc.CallOption) (*lropb.ListOperationsResponse, error) {
 	atomic.AddInt32(&cli.listOperationsCalledCnt, 1)
-	return nil, nil
+	resp, err := cli.getMethodRespErr("ListOperations")
+	if resp != nil {
+		return resp.(*lropb.ListOperationsResponse), err
+	}
+	return &lropb.ListOperationsResponse{}, err
 }
 
 // DeleteOperation deletes a long-running operation. This method indicates
@@ -350,6 +1756,11 @@ func (cli *FakeDatabaseClient) CreateFile(ctx context.Context, in *dbdpb.CreateF
 	return &dbdpb.CreateFileResponse{}, nil
 }
 
+// CreateFileCalledCnt returns call count.
+func (cli *FakeDatabaseClient) CreateFileCalledCnt() int {
+	return int(atomic.LoadInt32(&cli.createFileCalledCnt))
+}
+
 // BootstrapDatabase bootstraps seeded database by executing init_oracle
 func (cli *FakeDatabaseClient) BootstrapDatabase(ctx context.Context, in *dbdpb.BootstrapDatabaseRequest, opts ...grpc.CallOption) (*dbdpb.BootstrapDatabaseResponse, error) {
 	atomic.AddInt32(&cli.bootstrapDatabaseCalledCnt, 1)
@@ -394,6 +1805,9 @@ func (cli *FakeDatabaseClient) GetOperation(context.Context, *lropb.GetOperation
 
 	switch cli.NextGetOperationStatus() {
 	case StatusDone:
+		if resp, _ := cli.getMethodRespErr("GetOperation"); resp != nil {
+			return resp.(*lropb.Operation), nil
+		}
 		return &lropb.Operation{Done: true}, nil
 
 	case StatusDoneWithError:
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"bitbucket.org/creachadair/stringset"
@@ -27,6 +28,10 @@ import (
 	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
+// oraPasswordVerificationFailed is the error Oracle returns when the password
+// verify function of the user profile rejects a password.
+const oraPasswordVerificationFailed = "ORA-28003"
+
 // users describe the managed Oracle PDB users.
 type users struct {
 	databaseName  string
@@ -67,7 +72,7 @@ func (us *users) diff(ctx context.Context, client dbdpb.DatabaseDaemonClient) (t
 		if err != nil {
 			return nil, nil, nil, nil, fmt.Errorf("failed to read the env user %v: %v", u, err)
 		}
-		if len(toGrant) != 0 || len(toRevoke) != 0 {
+		if len(toGrant) != 0 || len(toRevoke) != 0 || len(u.quotaDiff()) != 0 {
 			toUpdateUsers = append(toUpdateUsers, u)
 		}
 		if toUpdatePwd {
@@ -157,6 +162,18 @@ type user struct {
 	newPassword string
 	// curPassword is only used for plaintext status diff.
 	curPassword string
+	// specQuotas are the quotas of the user from the spec.
+	specQuotas []UserQuota
+	// envQuotas keeps track of the quotas of the user by tablespace, it is
+	// only read if the spec has quotas.
+	// The value will be initialized/refreshed with method user.readEnv
+	envQuotas map[string]envQuota
+}
+
+// envQuota is the quota of a user on a tablespace from dba_ts_quotas.
+type envQuota struct {
+	maxBytes  int64
+	blockSize int64
 }
 
 func (u *user) readEnv(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
@@ -188,9 +205,42 @@ func (u *user) readEnv(ctx context.Context, client dbdpb.DatabaseDaemonClient) e
 		return fmt.Errorf("failed to query dba role privileges: %v", err)
 	}
 	u.envDbaRolePrivs = rolePrivs
+	if len(u.specQuotas) == 0 {
+		return nil
+	}
+	quotas, err := queryQuotas(ctx, client, u.databaseName, u.userName)
+	if err != nil {
+		return fmt.Errorf("failed to query tablespace quotas: %v", err)
+	}
+	u.envQuotas = quotas
 	return nil
 }
 
+// quotaDiff returns the spec quotas which differ from the env quotas read by
+// the last readEnv.
+func (u *user) quotaDiff() []*dbdpb.SetUserQuotaRequest_Quota {
+	var diff []*dbdpb.SetUserQuotaRequest_Quota
+	for _, q := range u.specQuotas {
+		if !quotaMatches(q.MaxBytes, u.envQuotas[q.Tablespace]) {
+			diff = append(diff, &dbdpb.SetUserQuotaRequest_Quota{Tablespace: q.Tablespace, MaxBytes: q.MaxBytes})
+		}
+	}
+	return diff
+}
+
+// quotaMatches tells whether the env quota is the spec quota, which Oracle
+// rounds to whole blocks.
+func quotaMatches(specMaxBytes int64, env envQuota) bool {
+	if specMaxBytes < 0 || env.maxBytes < 0 {
+		return specMaxBytes == env.maxBytes
+	}
+	diff := specMaxBytes - env.maxBytes
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff == 0 || diff < env.blockSize
+}
+
 // diff returns privileges, which should be granted/revoked by comparing k8s spec with real environment.
 func (u *user) diff(ctx context.Context, client dbdpb.DatabaseDaemonClient) (toGrant, toRevoke []string, toUpdatePwd bool, err error) {
 	if err := u.readEnv(ctx, client); err != nil {
@@ -231,9 +281,9 @@ func (u *user) create(ctx context.Context, client dbdpb.DatabaseDaemonClient) er
 	if _, err := client.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
 		Commands: sqls,
 	}); err != nil {
-		return fmt.Errorf("failed to create user %v: %v", u, err)
+		return fmt.Errorf("failed to create user %v: %v", u, passwordError(u.userName, err))
 	}
-	return nil
+	return u.updateQuotas(ctx, client)
 }
 
 func (u *user) update(ctx context.Context, client dbdpb.DatabaseDaemonClient, roles map[string]bool) error {
@@ -243,7 +293,8 @@ func (u *user) update(ctx context.Context, client dbdpb.DatabaseDaemonClient, ro
 	if err := u.updateSysPrivs(ctx, client, roles); err != nil {
 		return err
 	}
-	return nil
+	// updateSysPrivs read the env quotas already.
+	return u.updateQuotas(ctx, client)
 
 }
 
@@ -323,7 +374,24 @@ func (u *user) updatePassword(ctx context.Context, client dbdpb.DatabaseDaemonCl
 		Commands: sqls,
 		Suppress: true,
 	}); err != nil {
-		return fmt.Errorf("failed to alter user %s: %v", u.userName, err)
+		return fmt.Errorf("failed to alter user %s: %v", u.userName, passwordError(u.userName, err))
+	}
+	return nil
+}
+
+// updateQuotas sets the quotas of the user which differ from the env
+// quotas.
+func (u *user) updateQuotas(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+	quotas := u.quotaDiff()
+	if len(quotas) == 0 {
+		return nil
+	}
+	if _, err := client.SetUserQuota(ctx, &dbdpb.SetUserQuotaRequest{
+		PdbName:  u.databaseName,
+		UserName: u.userName,
+		Quotas:   quotas,
+	}); err != nil {
+		return fmt.Errorf("failed to set the quotas of user %s: %v", u.userName, err)
 	}
 	return nil
 }
@@ -392,6 +460,10 @@ func newUser(databaseName string, specUser *User) *user {
 			privs = append(privs, upperP)
 		}
 	}
+	var quotas []UserQuota
+	for _, q := range specUser.Quotas {
+		quotas = append(quotas, UserQuota{Tablespace: strings.ToUpper(q.Tablespace), MaxBytes: q.MaxBytes})
+	}
 	user := &user{
 		databaseName: strings.ToUpper(databaseName),
 		userName:     strings.ToUpper(specUser.Name),
@@ -401,6 +473,7 @@ func newUser(databaseName string, specUser *User) *user {
 		// Only used for plaintext status diff.
 		curPassword: specUser.LastPassword,
 		specPrivs:   privs,
+		specQuotas:  quotas,
 	}
 	if specUser.PasswordGsmSecretRef != nil {
 		user.gsmSecCurVer = specUser.PasswordGsmSecretRef.LastVersion
@@ -416,6 +489,16 @@ func newNoSpecUser(databaseName, userName string) *user {
 	}
 }
 
+// passwordError turns the rejection of a password by the password verify
+// function of the user profile into an actionable error. The original error
+// is dropped as it may quote the statement, password included.
+func passwordError(userName string, err error) error {
+	if strings.Contains(err.Error(), oraPasswordVerificationFailed) {
+		return fmt.Errorf("the password of user %s doesn't meet the password policy of its profile (%s), set a password the profile password verify function accepts", userName, oraPasswordVerificationFailed)
+	}
+	return err
+}
+
 func queryDB(ctx context.Context, client dbdpb.DatabaseDaemonClient, databaseName, sqlQuery, key string, filter func(val string) bool) ([]string, error) {
 	resp, err := client.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{
 		Commands: []string{
@@ -437,6 +520,38 @@ func queryDB(ctx context.Context, client dbdpb.DatabaseDaemonClient, databaseNam
 	return userNames, nil
 }
 
+// queryQuotas returns the quotas of a user by tablespace, with the block size
+// of every tablespace, the quota is 0 if the user has none.
+func queryQuotas(ctx context.Context, client dbdpb.DatabaseDaemonClient, databaseName, userName string) (map[string]envQuota, error) {
+	resp, err := client.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{
+			sql.QuerySetSessionContainer(databaseName),
+			fmt.Sprintf("select t.tablespace_name, nvl(q.max_bytes, 0) as max_bytes, t.block_size from dba_tablespaces t "+
+				"left join dba_ts_quotas q on q.tablespace_name = t.tablespace_name and q.username = '%s'", sql.StringParam(userName)),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("queryQuotas failed to query data: %v", err)
+	}
+	rows, err := parseSQLResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("queryQuotas failed to parse data from %v: %v", resp, err)
+	}
+	quotas := make(map[string]envQuota)
+	for _, row := range rows {
+		maxBytes, err := strconv.ParseInt(row["MAX_BYTES"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MAX_BYTES in %v: %v", row, err)
+		}
+		blockSize, err := strconv.ParseInt(row["BLOCK_SIZE"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BLOCK_SIZE in %v: %v", row, err)
+		}
+		quotas[row["TABLESPACE_NAME"]] = envQuota{maxBytes: maxBytes, blockSize: blockSize}
+	}
+	return quotas, nil
+}
+
 func queryRowsByKey(rows []map[string]string, rowKey string, filter func(val string) bool) ([]string, error) {
 	var res []string
 	for _, row := range rows {
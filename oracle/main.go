@@ -18,6 +18,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/pitrcontroller"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -41,6 +43,7 @@ import (
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/exportcontroller"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/importcontroller"
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/controllers/instancecontroller"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/audit"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -52,6 +55,15 @@ var (
 	serviceImage         = flag.String("service_image_uri", "", "GCR service URI")
 	loggingSidecarImage  = flag.String("logging_sidecar_image_uri", "gcr.io/elcarro/oracle.db.anthosapis.com/loggingsidecar:latest", "Logging Sidecar image URI")
 	monitoringAgentImage = flag.String("monitoring_agent_image_uri", "gcr.io/elcarro/oracle.db.anthosapis.com/monitoring:latest", "Monitoring Agent image URI")
+	connectionPoolImage  = flag.String("connection_pool_image_uri", "haproxy:2.6", "Default image URI of the Proxy connection poolers")
+
+	auditLogPath    = flag.String("audit_log_path", "", "File, e.g. on a mounted PVC, the audited operator actions are appended to")
+	auditLogGcsPath = flag.String("audit_log_gcs_path", "", "GCS directory the audited operator actions are uploaded to")
+	auditActions    = flag.String("audit_actions", "", "Comma separated event reasons of the operator actions written to the audit log, all are if empty")
+
+	dbdaemonPlaintext = flag.Bool("dbdaemon_plaintext", false, "Don't issue database daemon certificates to new Instances, their daemons serve plaintext gRPC")
+
+	enableWebhooks = flag.Bool("enable_webhooks", false, "Serve the validating webhooks, requires the webhook serving certificate of config/certmanager")
 
 	namespace = flag.String("namespace", "", "TESTING ONLY: Limits controller to watching resources in this namespace only")
 )
@@ -100,6 +112,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Every event is annotated with the audit fields, the audit log is only
+	// written if a destination is set.
+	var auditWriter *audit.Writer
+	if auditSink := audit.NewSink(*auditLogPath, *auditLogGcsPath); auditSink != nil {
+		auditWriter = audit.NewWriter(auditSink, audit.DefaultBufferSize, audit.DefaultFlushInterval, ctrl.Log.WithName("audit"))
+		if err := mgr.Add(auditWriter); err != nil {
+			setupLog.Error(err, "unable to add the audit log writer")
+			os.Exit(1)
+		}
+	}
+	recorderFor := func(name string) record.EventRecorder {
+		return audit.NewEventRecorder(mgr.GetEventRecorderFor(name), name, auditWriter, strings.Split(*auditActions, ","))
+	}
+
 	var locker = sync.Map{}
 
 	if err = (&instancecontroller.InstanceReconciler{
@@ -107,9 +133,11 @@ func main() {
 		Log:           ctrl.Log.WithName("controllers").WithName("Instance"),
 		SchemeVal:     mgr.GetScheme(),
 		Images:        images,
-		Recorder:      mgr.GetEventRecorderFor("instance-controller"),
+		Recorder:      recorderFor("instance-controller"),
 		InstanceLocks: &locker,
 
+		DBDaemonPlaintext:     *dbdaemonPlaintext,
+		ConnectionPoolImage:   *connectionPoolImage,
 		DatabaseClientFactory: &controllers.GRPCDatabaseClientFactory{},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Instance")
@@ -119,7 +147,7 @@ func main() {
 		Client:                mgr.GetClient(),
 		Log:                   ctrl.Log.WithName("controllers").WithName("Database"),
 		Scheme:                mgr.GetScheme(),
-		Recorder:              mgr.GetEventRecorderFor("database-controller"),
+		Recorder:              recorderFor("database-controller"),
 		InstanceLocks:         &locker,
 		DatabaseClientFactory: &controllers.GRPCDatabaseClientFactory{},
 	}).SetupWithManager(mgr); err != nil {
@@ -130,7 +158,7 @@ func main() {
 		Client:              mgr.GetClient(),
 		Log:                 ctrl.Log.WithName("controllers").WithName("Backup"),
 		Scheme:              mgr.GetScheme(),
-		Recorder:            mgr.GetEventRecorderFor("backup-controller"),
+		Recorder:            recorderFor("backup-controller"),
 		InstanceLocks:       &locker,
 		OracleBackupFactory: &backupcontroller.RealOracleBackupFactory{},
 		BackupCtrl:          &backupcontroller.RealBackupControl{Client: mgr.GetClient()},
@@ -144,7 +172,7 @@ func main() {
 		Client:        mgr.GetClient(),
 		Log:           ctrl.Log.WithName("controllers").WithName("Export"),
 		Scheme:        mgr.GetScheme(),
-		Recorder:      mgr.GetEventRecorderFor("export-controller"),
+		Recorder:      recorderFor("export-controller"),
 		InstanceLocks: &locker,
 
 		DatabaseClientFactory: &controllers.GRPCDatabaseClientFactory{},
@@ -156,7 +184,7 @@ func main() {
 		Client:        mgr.GetClient(),
 		Log:           ctrl.Log.WithName("controllers").WithName("Import"),
 		Scheme:        mgr.GetScheme(),
-		Recorder:      mgr.GetEventRecorderFor("import-controller"),
+		Recorder:      recorderFor("import-controller"),
 		InstanceLocks: &locker,
 
 		DatabaseClientFactory: &controllers.GRPCDatabaseClientFactory{},
@@ -211,6 +239,12 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "CronAnything")
 		os.Exit(1)
 	}
+	if *enableWebhooks {
+		if err = (&instancecontroller.InstanceValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Instance")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	// Use the testing namespace if supplied, otherwise deploy to the same namespace as the operator.
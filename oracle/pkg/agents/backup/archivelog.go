@@ -0,0 +1,174 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// archivelogBackupStmtTemplate backs up the archived logs which
+	// have not been backed up yet. The format is:
+	//	run {
+	//		backup
+	//			archivelog all not backed up 1 times
+	//			to destination '<backupDir>'
+	//			tag='<tag>';
+	//	}
+	archivelogBackupStmtTemplate = `run {
+			backup
+				archivelog all not backed up 1 times
+				to destination '%s'
+				tag='%s';
+		}
+	`
+
+	// archivelogDeleteStmtTemplate deletes archived logs older than the
+	// cutoff. RMAN only deletes logs that were backed up, even if the
+	// safety check raced with newly archived logs.
+	archivelogDeleteStmtTemplate = `delete noprompt archivelog until time '%s' backed up 1 times to device type disk;`
+
+	// archivelogsBeforeCutoffSQL lists the archived logs still on disk which
+	// an until time deletion with the same cutoff can select.
+	archivelogsBeforeCutoffSQL = "select thread#, sequence#, backup_count from v$archived_log " +
+		"where deleted = 'NO' and status = 'A' and first_time < %s order by thread#, sequence#"
+)
+
+// ArchivelogParams that can be passed to StartArchivelogBackup and
+// DeleteArchivelogs.
+type ArchivelogParams struct {
+	Client      dbdpb.DatabaseDaemonClient
+	GCSPath     string
+	BackupTag   string
+	OperationID string
+	// KeepWindow keeps archived logs which started within the window
+	// on disk, e.g. for standby databases or fast local recovery.
+	KeepWindow time.Duration
+}
+
+// archivelogStagingDir returns the staging directory of a backup run. Each
+// run uploads and removes only its own subdirectory of the RMAN staging
+// directory.
+func archivelogStagingDir(tag string) string {
+	return filepath.Join(consts.RMANStagingDir, strings.ToLower(tag))
+}
+
+// archivelogCutoff returns the SQL date expression of the deletion cutoff,
+// evaluated by the database to avoid clock skew between the agent and the
+// database.
+func archivelogCutoff(keepWindow time.Duration) string {
+	seconds := int64(keepWindow / time.Second)
+	if seconds <= 0 {
+		return "sysdate"
+	}
+	return fmt.Sprintf("sysdate-%d/86400", seconds)
+}
+
+// archivelog is a row of archivelogsBeforeCutoffSQL.
+type archivelog struct {
+	Thread      string `json:"THREAD#"`
+	Sequence    string `json:"SEQUENCE#"`
+	BackupCount string `json:"BACKUP_COUNT"`
+}
+
+// checkArchivelogsBackedUp is the safety check run before archived logs
+// are deleted, every log selected by the cutoff must have been backed up,
+// otherwise deleting it would shrink the recoverable window.
+func checkArchivelogsBackedUp(rows []string) error {
+	var notBackedUp []string
+	for _, row := range rows {
+		var al archivelog
+		if err := json.Unmarshal([]byte(row), &al); err != nil {
+			return fmt.Errorf("failed to parse archived log row %q: %v", row, err)
+		}
+		if al.BackupCount == "" || al.BackupCount == "0" {
+			notBackedUp = append(notBackedUp, fmt.Sprintf("thread %s sequence %s", al.Thread, al.Sequence))
+		}
+	}
+	if len(notBackedUp) > 0 {
+		return fmt.Errorf("%d archived logs are not backed up: %v", len(notBackedUp), notBackedUp)
+	}
+	return nil
+}
+
+// StartArchivelogBackup starts an LRO backing up the archived logs which
+// were not backed up yet to GCS.
+func StartArchivelogBackup(ctx context.Context, params *ArchivelogParams) (*lropb.Operation, error) {
+	klog.InfoS("oracle/StartArchivelogBackup", "params", params)
+	if params.GCSPath == "" {
+		return nil, fmt.Errorf("oracle/StartArchivelogBackup: GCS path is required")
+	}
+
+	backupDir := archivelogStagingDir(params.BackupTag)
+	if _, err := params.Client.CreateDirs(ctx, &dbdpb.CreateDirsRequest{
+		Dirs: []*dbdpb.CreateDirsRequest_DirInfo{{Path: backupDir, Perm: 0760}},
+	}); err != nil {
+		return nil, fmt.Errorf("oracle/StartArchivelogBackup: failed to create a backup dir %q: %v", backupDir, err)
+	}
+
+	backupStmt := fmt.Sprintf(archivelogBackupStmtTemplate, backupDir, params.BackupTag)
+	operation, err := params.Client.RunRMANAsync(ctx, &dbdpb.RunRMANAsyncRequest{
+		SyncRequest: &dbdpb.RunRMANRequest{
+			Scripts:   []string{backupStmt},
+			GcsPath:   params.GCSPath,
+			LocalPath: backupDir,
+			GcsOp:     dbdpb.RunRMANRequest_UPLOAD,
+		},
+		LroInput: &dbdpb.LROInput{OperationId: params.OperationID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oracle/StartArchivelogBackup: failed to back up archived logs: %v", err)
+	}
+	return operation, nil
+}
+
+// DeleteArchivelogs deletes the backed up archived logs older than the keep
+// window, freeing the fast recovery area. It is called once the backup LRO
+// uploaded the logs, and only deletes them if the safety check passed.
+func DeleteArchivelogs(ctx context.Context, params *ArchivelogParams) error {
+	klog.InfoS("oracle/DeleteArchivelogs", "params", params)
+	cutoff := archivelogCutoff(params.KeepWindow)
+	resp, err := params.Client.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{fmt.Sprintf(archivelogsBeforeCutoffSQL, cutoff)},
+	})
+	if err != nil {
+		return fmt.Errorf("oracle/DeleteArchivelogs: failed to list archived logs: %v", err)
+	}
+	if err := checkArchivelogsBackedUp(resp.GetMsg()); err != nil {
+		return fmt.Errorf("oracle/DeleteArchivelogs: refusing to delete archived logs: %v", err)
+	}
+	if len(resp.GetMsg()) == 0 {
+		klog.InfoS("oracle/DeleteArchivelogs: no archived logs to delete", "cutoff", cutoff)
+		return nil
+	}
+
+	deleteStmt := fmt.Sprintf(archivelogDeleteStmtTemplate, cutoff)
+	if _, err := params.Client.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{deleteStmt}}); err != nil {
+		return fmt.Errorf("oracle/DeleteArchivelogs: failed to delete archived logs: %v", err)
+	}
+	klog.InfoS("oracle/DeleteArchivelogs: deleted archived logs", "count", len(resp.GetMsg()), "cutoff", cutoff)
+	return nil
+}
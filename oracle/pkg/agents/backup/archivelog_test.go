@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchivelogCutoff(t *testing.T) {
+	tests := []struct {
+		keepWindow time.Duration
+		want       string
+	}{
+		{keepWindow: 0, want: "sysdate"},
+		{keepWindow: -time.Hour, want: "sysdate"},
+		{keepWindow: 500 * time.Millisecond, want: "sysdate"},
+		{keepWindow: 2 * time.Hour, want: "sysdate-7200/86400"},
+	}
+	for _, tc := range tests {
+		if got := archivelogCutoff(tc.keepWindow); got != tc.want {
+			t.Errorf("archivelogCutoff(%v) = %q, want %q", tc.keepWindow, got, tc.want)
+		}
+	}
+}
+
+func TestArchivelogStatements(t *testing.T) {
+	backupStmt := fmt.Sprintf(archivelogBackupStmtTemplate, "/u03/app/oracle/rmanstaging", "fra-relief")
+	for _, want := range []string{
+		"archivelog all not backed up 1 times",
+		"to destination '/u03/app/oracle/rmanstaging'",
+		"tag='fra-relief'",
+	} {
+		if !strings.Contains(backupStmt, want) {
+			t.Errorf("archived log backup statement %q does not contain %q", backupStmt, want)
+		}
+	}
+	if strings.Contains(backupStmt, "delete") {
+		t.Errorf("archived log backup statement %q deletes input before the backup is uploaded", backupStmt)
+	}
+
+	cutoff := archivelogCutoff(time.Hour)
+	deleteStmt := fmt.Sprintf(archivelogDeleteStmtTemplate, cutoff)
+	if want := "delete noprompt archivelog until time 'sysdate-3600/86400' backed up 1 times to device type disk;"; deleteStmt != want {
+		t.Errorf("archived log delete statement = %q, want %q", deleteStmt, want)
+	}
+	if query := fmt.Sprintf(archivelogsBeforeCutoffSQL, cutoff); !strings.Contains(query, "first_time < sysdate-3600/86400") {
+		t.Errorf("archived log query %q does not select logs by the deletion cutoff", query)
+	}
+}
+
+func TestArchivelogStagingDir(t *testing.T) {
+	got := archivelogStagingDir("ARCHLOG_20220501T010000")
+	if want := "/u03/app/oracle/rmanstaging/archlog_20220501t010000"; got != want {
+		t.Errorf("archivelogStagingDir() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckArchivelogsBackedUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []string
+		wantErr bool
+	}{
+		{
+			name: "nothing to delete",
+		},
+		{
+			name: "all backed up",
+			rows: []string{
+				`{"THREAD#":"1","SEQUENCE#":"10","BACKUP_COUNT":"1"}`,
+				`{"THREAD#":"1","SEQUENCE#":"11","BACKUP_COUNT":"2"}`,
+			},
+		},
+		{
+			name: "log not backed up",
+			rows: []string{
+				`{"THREAD#":"1","SEQUENCE#":"10","BACKUP_COUNT":"1"}`,
+				`{"THREAD#":"1","SEQUENCE#":"11","BACKUP_COUNT":"0"}`,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing backup count",
+			rows:    []string{`{"THREAD#":"1","SEQUENCE#":"10"}`},
+			wantErr: true,
+		},
+		{
+			name:    "invalid row",
+			rows:    []string{"not json"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkArchivelogsBackedUp(tc.rows); (err != nil) != tc.wantErr {
+				t.Errorf("checkArchivelogsBackedUp(%q) error = %v, wantErr %v", tc.rows, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package backup
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
 	lropb "google.golang.org/genproto/googleapis/longrunning"
@@ -33,6 +34,22 @@ import (
 const (
 	allocateChannel = "allocate channel disk%d device type disk;\n"
 
+	// allocateStreamChannel allocates a channel writing its backup pieces
+	// to a named pipe, which the database daemon streams to GCS.
+	allocateStreamChannel = "allocate channel disk%d device type disk format '%s';\n"
+
+	// streamFilesperset is the filesperset of a streamed backup. RMAN puts
+	// the lower of the filesperset and the datafiles per channel in a backup
+	// set, above the maximum number of datafiles of a database it gives each
+	// channel a single set.
+	streamFilesperset = 65536
+
+	// streamControlfileSpec backs up the control file along with the
+	// datafiles of a streamed backup to a staged backup piece. RMAN adds it
+	// to the datafile 1 backup otherwise, as a second set written to the
+	// pipe of a channel.
+	streamControlfileSpec = "(current controlfile format '%s/%%U')"
+
 	// The format of the backup statement template is:
 	// 	run {
 	//		<initialization statements>
@@ -42,11 +59,19 @@ const (
 	//			<check logical>
 	//			<filesperset X>
 	//			<section size Y>
-	//			incremental level <Z>
-	//			to destination '<W>'
+	//			incremental level <Z> <cumulative>
+	//			<to destination '<W>' | reuse>
 	// 			<granularity: (database|pluggable database pdb1,pdb2)>
+	//			<control file spec>
+	//		sql 'alter system archive log current';
+	//		backup to destination '<W>' archivelog all;
 	//		backup...
 	//	}
+	// The current redo log is archived before the archived logs are backed
+	// up, so that the database is recoverable right to the end of the backup.
+	// The datafiles of a streamed backup are written to the named pipes of
+	// the channels, which are reused for each backup, and its control file
+	// is staged with <control file spec>.
 	backupStmtTemplate = `run {
 			%s
 			%s
@@ -55,10 +80,14 @@ const (
 				%s
 				%s
 				%s
-				incremental level %d
+				incremental level %d %s
+				%s
+				tag='%s' (%s) %s;
+			sql 'alter system archive log current';
+			backup
 				to destination '%s'
-				tag='%s' (%s)
-				plus archivelog;
+				tag='%s'
+				archivelog all;
 			backup
 				to destination '%s'
  				tag='%s'
@@ -67,6 +96,11 @@ const (
 	`
 
 	backupDeletionStmt = `delete noprompt backup tag='%s';`
+
+	// backupCPUShare is the share of the CPU limit of the database container
+	// the backup channels may use, each channel keeps about a core busy.
+	// The rest is left to the database workload.
+	backupCPUShare = 0.5
 )
 
 // Params that can be passed to PhysicalBackup.
@@ -89,19 +123,46 @@ type Params struct {
 	LogGcsDir         string
 	Incarnation       string
 	BackupIncarnation string
+	DBID              string
 	StartTime         *timestamppb.Timestamp
 	EndTime           *timestamppb.Timestamp
 	StartSCN          int64
 	EndSCN            int64
+	// IgnoreCPULimit allocates DOP backup channels even if they oversubscribe
+	// the CPU limit of the database container.
+	IgnoreCPULimit bool
+	// Cumulative takes a cumulative instead of a differential incremental
+	// backup at a level above 0.
+	Cumulative bool
+	// Stream streams the datafile backup piece of each channel through a
+	// named pipe to GCS instead of staging it on local disk first.
+	Stream bool
+	// ChainGCSPaths are the GCS paths of the backups, level 0 backup first,
+	// the restored incremental backup builds on.
+	ChainGCSPaths []string
 }
 
 // PhysicalBackup takes a physical backup of the oracle database.
 func PhysicalBackup(ctx context.Context, params *Params) (*lropb.Operation, error) {
 	klog.InfoS("oracle/PhysicalBackup", "params", params)
 
+	dop := params.DOP
+	if !params.IgnoreCPULimit {
+		dop = cappedChannels(ctx, params.Client, params.DOP)
+	}
+	klog.InfoS("oracle/PhysicalBackup", "requestedDOP", params.DOP, "channelCount", dop)
+
+	if err := checkStream(params); err != nil {
+		return nil, fmt.Errorf("oracle/PhysicalBackup: failed a pre-flight check: %v", err)
+	}
+
 	var channels string
-	for i := 1; i <= int(params.DOP); i++ {
-		channels += fmt.Sprintf(allocateChannel, i)
+	for i := 1; i <= int(dop); i++ {
+		if params.Stream {
+			channels += fmt.Sprintf(allocateStreamChannel, i, filepath.Join(consts.RMANPipeDir, fmt.Sprintf(consts.RMANStreamPipe, i)))
+		} else {
+			channels += fmt.Sprintf(allocateChannel, i)
+		}
 	}
 	klog.InfoS("oracle/PhysicalBackup", "channels", channels)
 
@@ -171,13 +232,31 @@ func PhysicalBackup(ctx context.Context, params *Params) (*lropb.Operation, erro
 	// The default location is '/u01/app/oracle/product/<VERSION>/db/dbs/snapcf_<CDB>.f'
 	// and it causes flaky behaviour (ORA-00246) in Oracle 19.3
 	initStatement := fmt.Sprintf("CONFIGURE SNAPSHOT CONTROLFILE NAME TO '%s/snapcf_%s.f';", backupDir, params.CDBName)
+	// Write the control file autobackup taken at the end of the backup next
+	// to the backup pieces, so that it is uploaded along with them.
+	initStatement += fmt.Sprintf("\n\t\t\tset controlfile autobackup format for device type disk to '%s/%%F';", backupDir)
+
+	var cumulative string
+	if params.Cumulative && params.Level > 0 {
+		cumulative = "cumulative"
+	}
+
+	destination := fmt.Sprintf("to destination '%s'", backupDir)
+	var streamChannels int32
+	var controlfileSpec string
+	if params.Stream {
+		destination = "reuse"
+		streamChannels = dop
+		filesperset = fmt.Sprintf("filesperset %d", streamFilesperset)
+		controlfileSpec = fmt.Sprintf(streamControlfileSpec, backupDir)
+	}
 
 	tag := params.BackupTag
-	backupStmt := fmt.Sprintf(backupStmtTemplate, initStatement, channels, compressed, backupset, checklogical, filesperset, sectionSize, params.Level, backupDir, tag, granularity, backupDir, tag)
+	backupStmt := fmt.Sprintf(backupStmtTemplate, initStatement, channels, compressed, backupset, checklogical, filesperset, sectionSize, params.Level, cumulative, destination, tag, granularity, controlfileSpec, backupDir, tag, backupDir, tag)
 	klog.InfoS("oracle/PhysicalBackup", "finalBackupRequest", backupStmt)
 
 	backupReq := &dbdpb.RunRMANAsyncRequest{
-		SyncRequest: &dbdpb.RunRMANRequest{Scripts: []string{backupStmt}, GcsPath: params.GCSPath, LocalPath: params.LocalPath, GcsOp: dbdpb.RunRMANRequest_UPLOAD},
+		SyncRequest: &dbdpb.RunRMANRequest{Scripts: []string{backupStmt}, GcsPath: params.GCSPath, LocalPath: params.LocalPath, GcsOp: dbdpb.RunRMANRequest_UPLOAD, StreamChannels: streamChannels},
 		LroInput:    &dbdpb.LROInput{OperationId: params.OperationID},
 	}
 	klog.InfoS("oracle/PhysicalBackup", "backupReq", backupReq)
@@ -189,6 +268,59 @@ func PhysicalBackup(ctx context.Context, params *Params) (*lropb.Operation, erro
 	return operation, nil
 }
 
+// checkStream checks that the backup pieces of a streamed backup can be
+// streamed. Each channel streams its datafiles in a single backup piece to
+// GCS, section sizes and a filesperset split them into several pieces and
+// image copies aren't pieces.
+func checkStream(params *Params) error {
+	if !params.Stream {
+		return nil
+	}
+	if params.GCSPath == "" {
+		return fmt.Errorf("a streamed backup needs a GCS path")
+	}
+	if !params.Backupset {
+		return fmt.Errorf("an Image Copy type of backup is not compatible with streaming")
+	}
+	if !params.SectionSize.IsZero() {
+		return fmt.Errorf("a section size is not compatible with streaming")
+	}
+	if params.Filesperset != 0 {
+		return fmt.Errorf("a filesperset is not compatible with streaming")
+	}
+	return nil
+}
+
+// channelCap returns the number of backup channels a CPU limit allows, it
+// returns zero if the container has no CPU limit.
+func channelCap(limitCores float64) int32 {
+	if limitCores <= 0 {
+		return 0
+	}
+	c := int32(limitCores * backupCPUShare)
+	if c < 1 {
+		c = 1
+	}
+	return c
+}
+
+// cappedChannels caps the requested backup channels at the CPU limit of the
+// database container so that a backup doesn't starve the database workload.
+// The requested DOP is kept if the limit can't be read.
+func cappedChannels(ctx context.Context, client dbdpb.DatabaseDaemonClient, dop int32) int32 {
+	stats, err := client.GetHostStats(ctx, &dbdpb.GetHostStatsRequest{})
+	if err != nil {
+		klog.ErrorS(err, "oracle/PhysicalBackup: failed to read the CPU limit, using the requested DOP", "dop", dop)
+		return dop
+	}
+	limit := stats.GetCpu().GetLimitCores()
+	if c := channelCap(limit); c > 0 && c < dop {
+		klog.InfoS("oracle/PhysicalBackup: capped the backup channels at the CPU limit", "requestedDOP", dop, "cpuLimit", limit, "channelCount", c)
+		return c
+	}
+	return dop
+}
+
 func sectionSize(sectionSize resource.Quantity) string {
 	if sectionSize.IsZero() {
 		return ""
@@ -1,10 +1,20 @@
 package backup
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
 func TestSectionSize_Zero(t *testing.T) {
@@ -61,3 +71,181 @@ func TestSectionSize_GBytes(t *testing.T) {
 		t.Errorf("Diff: \n%v\n", diff)
 	}
 }
+
+func TestBackupStmtArchivesCurrentLog(t *testing.T) {
+	stmt := fmt.Sprintf(backupStmtTemplate, "", "", "compressed", "backupset", "", "", "", 0, "", "to destination '/backup'", "tag1", "database", "", "/backup", "tag1", "/backup", "tag1")
+	if strings.Contains(stmt, "%!") {
+		t.Fatalf("backup statement has mismatched arguments: %q", stmt)
+	}
+	logSwitch := strings.Index(stmt, "sql 'alter system archive log current';")
+	archivelogs := strings.Index(stmt, "archivelog all;")
+	if logSwitch < 0 || archivelogs < 0 || logSwitch > archivelogs {
+		t.Errorf("backup statement %q does not archive the current redo log before backing up archived logs", stmt)
+	}
+}
+
+func TestChannelCap(t *testing.T) {
+	testCases := []struct {
+		limitCores float64
+		want       int32
+	}{
+		{limitCores: 0, want: 0},
+		{limitCores: 0.5, want: 1},
+		{limitCores: 1, want: 1},
+		{limitCores: 2, want: 1},
+		{limitCores: 3.5, want: 1},
+		{limitCores: 4, want: 2},
+		{limitCores: 16, want: 8},
+	}
+	for _, tc := range testCases {
+		if got := channelCap(tc.limitCores); got != tc.want {
+			t.Errorf("channelCap(%v) got %d, want %d", tc.limitCores, got, tc.want)
+		}
+	}
+}
+
+type fakeHostStatsClient struct {
+	dbdpb.DatabaseDaemonClient
+	resp *dbdpb.GetHostStatsResponse
+	err  error
+}
+
+func (c *fakeHostStatsClient) GetHostStats(context.Context, *dbdpb.GetHostStatsRequest, ...grpc.CallOption) (*dbdpb.GetHostStatsResponse, error) {
+	return c.resp, c.err
+}
+
+func TestCappedChannels(t *testing.T) {
+	testCases := []struct {
+		name   string
+		client *fakeHostStatsClient
+		dop    int32
+		want   int32
+	}{
+		{
+			name:   "capped at the CPU limit",
+			client: &fakeHostStatsClient{resp: &dbdpb.GetHostStatsResponse{Cpu: &dbdpb.GetHostStatsResponse_CPU{LimitCores: 4}}},
+			dop:    8,
+			want:   2,
+		},
+		{
+			name:   "requested DOP under the cap",
+			client: &fakeHostStatsClient{resp: &dbdpb.GetHostStatsResponse{Cpu: &dbdpb.GetHostStatsResponse_CPU{LimitCores: 16}}},
+			dop:    4,
+			want:   4,
+		},
+		{
+			name:   "no CPU limit",
+			client: &fakeHostStatsClient{resp: &dbdpb.GetHostStatsResponse{Cpu: &dbdpb.GetHostStatsResponse_CPU{}}},
+			dop:    8,
+			want:   8,
+		},
+		{
+			name:   "CPU limit unavailable",
+			client: &fakeHostStatsClient{err: errors.New("rpc error: code = Unimplemented")},
+			dop:    8,
+			want:   8,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cappedChannels(context.Background(), tc.client, tc.dop); got != tc.want {
+				t.Errorf("cappedChannels(%d) got %d, want %d", tc.dop, got, tc.want)
+			}
+		})
+	}
+}
+
+// fakeBackupClient records the RMAN request of a physical backup.
+type fakeBackupClient struct {
+	dbdpb.DatabaseDaemonClient
+	rmanReq *dbdpb.RunRMANAsyncRequest
+}
+
+func (c *fakeBackupClient) CreateDirs(context.Context, *dbdpb.CreateDirsRequest, ...grpc.CallOption) (*dbdpb.CreateDirsResponse, error) {
+	return &dbdpb.CreateDirsResponse{}, nil
+}
+
+func (c *fakeBackupClient) RunRMANAsync(ctx context.Context, req *dbdpb.RunRMANAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	c.rmanReq = req
+	return &lropb.Operation{}, nil
+}
+
+// TestPhysicalBackupStreamSets covers a single channel which backs up both
+// the datafiles and the control file of a streamed backup: the datafiles go
+// to its pipe in a single set, the control file set is staged.
+func TestPhysicalBackupStreamSets(t *testing.T) {
+	client := &fakeBackupClient{}
+	if _, err := PhysicalBackup(context.Background(), &Params{
+		Client:         client,
+		CDBName:        "GCLOUD",
+		Backupset:      true,
+		DOP:            1,
+		IgnoreCPULimit: true,
+		GCSPath:        "gs://bucket/backups/l0",
+		BackupTag:      "tag1",
+		Stream:         true,
+	}); err != nil {
+		t.Fatalf("PhysicalBackup failed: %v", err)
+	}
+	req := client.rmanReq.GetSyncRequest()
+	if got := req.GetStreamChannels(); got != 1 {
+		t.Errorf("PhysicalBackup streamed %d channels, want 1", got)
+	}
+	stmt := req.GetScripts()[0]
+	for _, want := range []string{
+		"allocate channel disk1 device type disk format '" + filepath.Join(consts.RMANPipeDir, "channel1") + "';",
+		"filesperset 65536",
+		"(database) (current controlfile format '" + consts.RMANStagingDir + "/%U');",
+	} {
+		if !strings.Contains(stmt, want) {
+			t.Errorf("PhysicalBackup statement %q doesn't contain %q", stmt, want)
+		}
+	}
+	if strings.Contains(stmt, "disk2") {
+		t.Errorf("PhysicalBackup statement %q allocates more than one channel", stmt)
+	}
+}
+
+func TestCheckStream(t *testing.T) {
+	testCases := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{
+			name:   "not streamed",
+			params: Params{Filesperset: 4},
+		},
+		{
+			name:   "backup set",
+			params: Params{Stream: true, Backupset: true, GCSPath: "gs://bucket/backup"},
+		},
+		{
+			name:    "no GCS path",
+			params:  Params{Stream: true, Backupset: true},
+			wantErr: true,
+		},
+		{
+			name:    "image copy",
+			params:  Params{Stream: true, GCSPath: "gs://bucket/backup"},
+			wantErr: true,
+		},
+		{
+			name:    "section size",
+			params:  Params{Stream: true, Backupset: true, GCSPath: "gs://bucket/backup", SectionSize: *resource.NewQuantity(1_000_000, resource.DecimalSI)},
+			wantErr: true,
+		},
+		{
+			name:    "filesperset",
+			params:  Params{Stream: true, Backupset: true, GCSPath: "gs://bucket/backup", Filesperset: 4},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkStream(&tc.params); (err != nil) != tc.wantErr {
+				t.Errorf("checkStream got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,9 +45,13 @@ const (
 				)
 	`
 
+	// restoreStmtTemplate starts with the optional SET DBID command, which is
+	// only allowed outside of RUN blocks and before the control file is
+	// mounted.
 	restoreStmtTemplate = `
+	startup force nomount;
+	%s
 	run {
-				startup force nomount;
 				restore spfile to '%s' from '%s';
 				shutdown immediate;
 				startup nomount;
@@ -61,6 +67,12 @@ const (
 	reset database to incarnation %s;
 	`
 
+	// catalogChainTemplate catalogs the backup pieces of an incremental
+	// chain downloaded to the staging directory, the restored control file
+	// only knows them by the paths they were taken to.
+	catalogChainTemplate = `catalog start with '%s/' noprompt;
+	`
+
 	recoverStmtTemplate = `run {
 				recover database until %s;
 				alter database open resetlogs;
@@ -69,6 +81,14 @@ const (
 	`
 )
 
+// chainStagingDir is the directory the backups an incremental backup builds
+// on are downloaded to, one subdirectory per backup.
+var chainStagingDir = filepath.Join(consts.RMANStagingDir, "chain")
+
+// autobackupPiece matches the name of control file autobackups in the
+// default %F format, c-<DBID>-<YYYYMMDD>-<QQ>.
+var autobackupPiece = regexp.MustCompile(`^c-(\d+)-\d{8}-[0-9a-f]{2}$`)
+
 type fileTime struct {
 	name    string
 	modTime time.Time
@@ -97,6 +117,10 @@ func PhysicalRestore(ctx context.Context, params *Params) (*lropb.Operation, err
 			GcsPath:   params.GCSPath,
 			LocalPath: backupDir,
 		}
+		// The streamed backup pieces are fed to RMAN during the restore.
+		if params.Stream {
+			downloadReq.ExcludeDirs = []string{consts.RMANStreamsDir}
+		}
 		klog.InfoS("oracle/PhysicalRestore", "restore from gcs, downloadReq", downloadReq)
 
 		if _, err := params.Client.DownloadDirectoryFromGCS(ctx, downloadReq); err != nil {
@@ -113,16 +137,18 @@ func PhysicalRestore(ctx context.Context, params *Params) (*lropb.Operation, err
 		return nil, fmt.Errorf("PhysicalRestore: failed to read backup dir: %v", err)
 	}
 
-	// Files stored in default format:
-	// "nnsnf" is used to locate spfile backup piece;
-	// "ncnnf" is used to locate control file backup piece;
-	latestSpfileBackup, err := findLatestBackupPiece(resp, "nnsnf")
+	latestSpfileBackup, latestControlfileBackup, err := controlfileBackupPieces(resp)
 	if err != nil {
-		return nil, fmt.Errorf("PhysicalRestore: failed to find latest spfile backup piece: %v", err)
+		return nil, fmt.Errorf("PhysicalRestore: %v", err)
 	}
-	latestControlfileBackup, err := findLatestBackupPiece(resp, "ncnnf")
-	if err != nil {
-		return nil, fmt.Errorf("PhysicalRestore: failed to find latest control file backup piece: %v", err)
+
+	// The chain is downloaded after the control file backup of the restored
+	// backup was picked, so that none of the older backups is picked.
+	if len(params.ChainGCSPaths) > 0 {
+		if err := downloadChain(ctx, params.Client, params.ChainGCSPaths); err != nil {
+			return nil, fmt.Errorf("PhysicalRestore: %v", err)
+		}
+		channels += fmt.Sprintf(catalogChainTemplate, chainStagingDir)
 	}
 
 	// Delete spfile and datafiles.
@@ -139,7 +165,11 @@ func PhysicalRestore(ctx context.Context, params *Params) (*lropb.Operation, err
 		fmt.Sprintf(consts.ConfigDir, consts.DataMount, params.CDBName),
 		fmt.Sprintf("spfile%s.ora", params.CDBName),
 	)
-	restoreStmt := fmt.Sprintf(restoreStmtTemplate, spfileLoc, latestSpfileBackup, latestControlfileBackup, params.BackupIncarnation, channels, params.Incarnation)
+	setDBID, err := setDBIDStatement(latestControlfileBackup, params.DBID)
+	if err != nil {
+		return nil, fmt.Errorf("PhysicalRestore: %v", err)
+	}
+	restoreStmt := fmt.Sprintf(restoreStmtTemplate, setDBID, spfileLoc, latestSpfileBackup, latestControlfileBackup, params.BackupIncarnation, channels, params.Incarnation)
 
 	req := &dbdpb.PhysicalRestoreAsyncRequest{
 		SyncRequest: &dbdpb.PhysicalRestoreRequest{
@@ -167,6 +197,9 @@ func PhysicalRestore(ctx context.Context, params *Params) (*lropb.Operation, err
 			LroInput: &dbdpb.LROInput{OperationId: params.OperationID},
 		}
 	}
+	if params.Stream {
+		req.SyncRequest.StreamGcsPath = strings.TrimSuffix(params.GCSPath, "/") + "/" + consts.RMANStreamsDir
+	}
 	operation, err := params.Client.PhysicalRestoreAsync(ctx, req)
 
 	if err != nil {
@@ -175,11 +208,83 @@ func PhysicalRestore(ctx context.Context, params *Params) (*lropb.Operation, err
 	return operation, nil
 }
 
+// downloadChain downloads the backup pieces of the backups of an incremental
+// chain, including the streamed ones, to their own directories in the chain
+// staging directory.
+func downloadChain(ctx context.Context, client dbdpb.DatabaseDaemonClient, gcsPaths []string) error {
+	for i, gcsPath := range gcsPaths {
+		downloadReq := &dbdpb.DownloadDirectoryFromGCSRequest{
+			GcsPath:   strings.TrimSuffix(gcsPath, "/") + "/",
+			LocalPath: filepath.Join(chainStagingDir, strconv.Itoa(i)),
+		}
+		klog.InfoS("oracle/PhysicalRestore", "restore incremental chain from gcs, downloadReq", downloadReq)
+		if _, err := client.DownloadDirectoryFromGCS(ctx, downloadReq); err != nil {
+			return fmt.Errorf("failed to download incremental chain backup %s from GCS: %v", gcsPath, err)
+		}
+	}
+	return nil
+}
+
+// controlfileBackupPieces returns the backup pieces to restore the spfile
+// and the control file from. Autobackups contain both, the latest control
+// file autobackup is used if the backup has no spfile or control file
+// backup piece.
+func controlfileBackupPieces(readDirResp *dbdpb.ReadDirResponse) (spfile, controlfile string, err error) {
+	// Files stored in default format:
+	// "nnsnf" is used to locate spfile backup piece;
+	// "ncnnf" is used to locate control file backup piece;
+	spfile, spfileErr := findLatestBackupPiece(readDirResp, "nnsnf")
+	controlfile, controlfileErr := findLatestBackupPiece(readDirResp, "ncnnf")
+	if spfileErr == nil && controlfileErr == nil {
+		return spfile, controlfile, nil
+	}
+
+	autobackup, err := findLatestAutobackup(readDirResp)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find latest spfile backup piece (%v) or control file backup piece (%v), and no autobackup: %v", spfileErr, controlfileErr, err)
+	}
+	klog.InfoS("controlfileBackupPieces: restoring from control file autobackup", "autobackup", autobackup, "spfileErr", spfileErr, "controlfileErr", controlfileErr)
+	if spfileErr != nil {
+		spfile = autobackup
+	}
+	if controlfileErr != nil {
+		controlfile = autobackup
+	}
+	return spfile, controlfile, nil
+}
+
+// setDBIDStatement returns the SET DBID command needed to restore from the
+// control file autobackup, empty for regular control file backup pieces.
+// The DBID is taken from the autobackup name and must match dbid if set.
+func setDBIDStatement(controlfile, dbid string) (string, error) {
+	m := autobackupPiece.FindStringSubmatch(filepath.Base(controlfile))
+	if m == nil {
+		return "", nil
+	}
+	if dbid != "" && dbid != m[1] {
+		return "", fmt.Errorf("control file autobackup %s belongs to DBID %s, want DBID %s", controlfile, m[1], dbid)
+	}
+	return fmt.Sprintf("set dbid %s;", m[1]), nil
+}
+
 // findLatestBackupPiece finds the latest modified backup piece whose name contains substr.
 func findLatestBackupPiece(readDirResp *dbdpb.ReadDirResponse, substr string) (string, error) {
+	return findLatestFile(readDirResp, substr, func(name string) bool {
+		return strings.Contains(name, substr)
+	})
+}
+
+// findLatestAutobackup finds the latest modified control file autobackup.
+func findLatestAutobackup(readDirResp *dbdpb.ReadDirResponse) (string, error) {
+	return findLatestFile(readDirResp, "autobackup", autobackupPiece.MatchString)
+}
+
+// findLatestFile finds the latest modified file whose name matches, desc
+// describes the files in logs and errors.
+func findLatestFile(readDirResp *dbdpb.ReadDirResponse, desc string, match func(name string) bool) (string, error) {
 	var fileTimes []fileTime
 	for _, fileInfo := range readDirResp.SubPaths {
-		if !fileInfo.IsDir && strings.Contains(fileInfo.Name, substr) {
+		if !fileInfo.IsDir && match(fileInfo.Name) {
 			if err := fileInfo.ModTime.CheckValid(); err != nil {
 				return "", fmt.Errorf("findLatestBackupPiece: failed to convert timestamp: %v", err)
 			}
@@ -188,18 +293,18 @@ func findLatestBackupPiece(readDirResp *dbdpb.ReadDirResponse, substr string) (s
 		}
 	}
 	if len(fileTimes) < 1 {
-		return "", fmt.Errorf("findLatestBackupPiece: failed to find candidates for substr %s: %d", substr, len(fileTimes))
+		return "", fmt.Errorf("findLatestBackupPiece: failed to find candidates for %s: %d", desc, len(fileTimes))
 	}
 
 	for i, t := range fileTimes {
-		klog.InfoS(fmt.Sprintf("%s time", substr), "index", i, "name", t.name, "modTime", t.modTime)
+		klog.InfoS(fmt.Sprintf("%s time", desc), "index", i, "name", t.name, "modTime", t.modTime)
 	}
 
 	sort.Slice(fileTimes, func(i, j int) bool {
 		return fileTimes[i].modTime.After(fileTimes[j].modTime)
 	})
 
-	klog.InfoS(fmt.Sprintf("findLatestBackupPiece: sorted %s files", substr), "fileTimes", fileTimes)
+	klog.InfoS(fmt.Sprintf("findLatestBackupPiece: sorted %s files", desc), "fileTimes", fileTimes)
 
 	return fileTimes[0].name, nil
 }
@@ -0,0 +1,239 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const backupDir = "/u03/app/oracle/rman"
+
+// backupFiles returns a ReadDirResponse of files modified a minute apart
+// in the given order.
+func backupFiles(names ...string) *dbdpb.ReadDirResponse {
+	resp := &dbdpb.ReadDirResponse{}
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, name := range names {
+		resp.SubPaths = append(resp.SubPaths, &dbdpb.ReadDirResponse_FileInfo{
+			Name:    name,
+			AbsPath: filepath.Join(backupDir, name),
+			ModTime: timestamppb.New(start.Add(time.Duration(i) * time.Minute)),
+		})
+	}
+	return resp
+}
+
+func TestControlfileBackupPieces(t *testing.T) {
+	tests := []struct {
+		name            string
+		files           *dbdpb.ReadDirResponse
+		wantSpfile      string
+		wantControlfile string
+	}{
+		{
+			name:            "backup pieces",
+			files:           backupFiles("o1_mf_nnsnf_TAG1_1.bkp", "o1_mf_ncnnf_TAG1_1.bkp", "c-1234567890-20220501-00"),
+			wantSpfile:      filepath.Join(backupDir, "o1_mf_nnsnf_TAG1_1.bkp"),
+			wantControlfile: filepath.Join(backupDir, "o1_mf_ncnnf_TAG1_1.bkp"),
+		},
+		{
+			name:            "control file backup piece missing",
+			files:           backupFiles("o1_mf_nnsnf_TAG1_1.bkp", "c-1234567890-20220501-00", "c-1234567890-20220501-01"),
+			wantSpfile:      filepath.Join(backupDir, "o1_mf_nnsnf_TAG1_1.bkp"),
+			wantControlfile: filepath.Join(backupDir, "c-1234567890-20220501-01"),
+		},
+		{
+			name:            "autobackup only",
+			files:           backupFiles("o1_mf_nnndf_TAG1_1.bkp", "c-1234567890-20220501-00"),
+			wantSpfile:      filepath.Join(backupDir, "c-1234567890-20220501-00"),
+			wantControlfile: filepath.Join(backupDir, "c-1234567890-20220501-00"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			spfile, controlfile, err := controlfileBackupPieces(tc.files)
+			if err != nil {
+				t.Fatalf("controlfileBackupPieces failed: %v", err)
+			}
+			if spfile != tc.wantSpfile || controlfile != tc.wantControlfile {
+				t.Errorf("controlfileBackupPieces = %q, %q, want %q, %q", spfile, controlfile, tc.wantSpfile, tc.wantControlfile)
+			}
+		})
+	}
+
+	if _, _, err := controlfileBackupPieces(backupFiles("o1_mf_nnndf_TAG1_1.bkp", "abc-1234567890-20220501-00")); err == nil {
+		t.Errorf("controlfileBackupPieces without control file backups succeeded, want error")
+	}
+}
+
+func TestRestoreFromAutobackupStatement(t *testing.T) {
+	spfile, controlfile, err := controlfileBackupPieces(backupFiles("o1_mf_nnndf_TAG1_1.bkp", "c-1234567890-20220501-00"))
+	if err != nil {
+		t.Fatalf("controlfileBackupPieces failed: %v", err)
+	}
+	setDBID, err := setDBIDStatement(controlfile, "1234567890")
+	if err != nil {
+		t.Fatalf("setDBIDStatement failed: %v", err)
+	}
+	stmt := fmt.Sprintf(restoreStmtTemplate, setDBID, "/u02/app/oracle/oraconfig/GCLOUD/spfileGCLOUD.ora", spfile, controlfile, "1", fmt.Sprintf(allocateChannel, 1), "2")
+
+	// The DBID must be set outside of the RUN blocks, then the spfile and the
+	// control file must be restored from the autobackup before the datafiles.
+	autobackup := filepath.Join(backupDir, "c-1234567890-20220501-00")
+	var last int
+	for _, step := range []string{
+		"startup force nomount;",
+		"set dbid 1234567890;",
+		"run {",
+		fmt.Sprintf("restore spfile to '/u02/app/oracle/oraconfig/GCLOUD/spfileGCLOUD.ora' from '%s';", autobackup),
+		"startup nomount;",
+		fmt.Sprintf("restore controlfile from '%s';", autobackup),
+		"startup mount;",
+		"reset database to incarnation 1;",
+		"allocate channel disk1 device type disk;",
+		"restore database;",
+		"reset database to incarnation 2;",
+	} {
+		i := strings.Index(stmt[last:], step)
+		if i < 0 {
+			t.Fatalf("restore statement is missing %q after position %d:\n%s", step, last, stmt)
+		}
+		last += i + len(step)
+	}
+}
+
+func TestSetDBIDStatement(t *testing.T) {
+	autobackup := filepath.Join(backupDir, "c-1234567890-20220501-0a")
+	tests := []struct {
+		name        string
+		controlfile string
+		dbid        string
+		want        string
+	}{
+		{
+			name:        "backup piece",
+			controlfile: filepath.Join(backupDir, "o1_mf_ncnnf_TAG1_1.bkp"),
+			dbid:        "1234567890",
+		},
+		{
+			name:        "autobackup",
+			controlfile: autobackup,
+			want:        "set dbid 1234567890;",
+		},
+		{
+			name:        "autobackup with matching DBID",
+			controlfile: autobackup,
+			dbid:        "1234567890",
+			want:        "set dbid 1234567890;",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := setDBIDStatement(tc.controlfile, tc.dbid)
+			if err != nil {
+				t.Fatalf("setDBIDStatement(%q, %q) failed: %v", tc.controlfile, tc.dbid, err)
+			}
+			if got != tc.want {
+				t.Errorf("setDBIDStatement(%q, %q) = %q, want %q", tc.controlfile, tc.dbid, got, tc.want)
+			}
+		})
+	}
+
+	if _, err := setDBIDStatement(autobackup, "987654321"); err == nil {
+		t.Errorf("setDBIDStatement(%q, %q) succeeded, want error", autobackup, "987654321")
+	}
+}
+
+// fakeRestoreClient records the downloads and the restore request of a
+// physical restore.
+type fakeRestoreClient struct {
+	dbdpb.DatabaseDaemonClient
+	files      *dbdpb.ReadDirResponse
+	downloads  []*dbdpb.DownloadDirectoryFromGCSRequest
+	restoreReq *dbdpb.PhysicalRestoreAsyncRequest
+}
+
+func (c *fakeRestoreClient) DownloadDirectoryFromGCS(ctx context.Context, req *dbdpb.DownloadDirectoryFromGCSRequest, opts ...grpc.CallOption) (*dbdpb.DownloadDirectoryFromGCSResponse, error) {
+	c.downloads = append(c.downloads, req)
+	return &dbdpb.DownloadDirectoryFromGCSResponse{}, nil
+}
+
+func (c *fakeRestoreClient) ReadDir(ctx context.Context, req *dbdpb.ReadDirRequest, opts ...grpc.CallOption) (*dbdpb.ReadDirResponse, error) {
+	return c.files, nil
+}
+
+func (c *fakeRestoreClient) DeleteDir(ctx context.Context, req *dbdpb.DeleteDirRequest, opts ...grpc.CallOption) (*dbdpb.DeleteDirResponse, error) {
+	return &dbdpb.DeleteDirResponse{}, nil
+}
+
+func (c *fakeRestoreClient) CreateDirs(ctx context.Context, req *dbdpb.CreateDirsRequest, opts ...grpc.CallOption) (*dbdpb.CreateDirsResponse, error) {
+	return &dbdpb.CreateDirsResponse{}, nil
+}
+
+func (c *fakeRestoreClient) PhysicalRestoreAsync(ctx context.Context, req *dbdpb.PhysicalRestoreAsyncRequest, opts ...grpc.CallOption) (*lropb.Operation, error) {
+	c.restoreReq = req
+	return &lropb.Operation{}, nil
+}
+
+func TestPhysicalRestoreIncrementalChain(t *testing.T) {
+	client := &fakeRestoreClient{files: backupFiles("o1_mf_nnnd1_TAG2_1.bkp", "c-1234567890-20220502-00")}
+	if _, err := PhysicalRestore(context.Background(), &Params{
+		Client:        client,
+		CDBName:       "GCLOUD",
+		DOP:           1,
+		GCSPath:       "gs://bucket/backups/l1b",
+		OperationID:   "Restore_1",
+		ChainGCSPaths: []string{"gs://bucket/backups/l0", "gs://bucket/backups/l1a/"},
+	}); err != nil {
+		t.Fatalf("PhysicalRestore failed: %v", err)
+	}
+
+	var got []string
+	for _, req := range client.downloads {
+		got = append(got, req.GetGcsPath()+" -> "+req.GetLocalPath())
+	}
+	want := []string{
+		"gs://bucket/backups/l1b -> " + consts.RMANStagingDir,
+		"gs://bucket/backups/l0/ -> " + filepath.Join(consts.RMANStagingDir, "chain", "0"),
+		"gs://bucket/backups/l1a/ -> " + filepath.Join(consts.RMANStagingDir, "chain", "1"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PhysicalRestore got unexpected downloads (-want +got):\n%v", diff)
+	}
+
+	// The downloaded chain is cataloged before the datafiles are restored.
+	stmt := client.restoreReq.GetSyncRequest().GetRestoreStatement()
+	catalog := strings.Index(stmt, fmt.Sprintf("catalog start with '%s/' noprompt;", filepath.Join(consts.RMANStagingDir, "chain")))
+	restore := strings.Index(stmt, "restore database;")
+	if catalog < 0 || catalog > restore {
+		t.Errorf("PhysicalRestore restore statement doesn't catalog the chain before restoring the database:\n%s", stmt)
+	}
+	if want := filepath.Join(backupDir, "c-1234567890-20220502-00"); !strings.Contains(stmt, fmt.Sprintf("restore controlfile from '%s';", want)) {
+		t.Errorf("PhysicalRestore restore statement doesn't restore the control file from %s:\n%s", want, stmt)
+	}
+}
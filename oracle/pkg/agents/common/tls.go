@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/local"
+)
+
+const (
+	// CACertFile is the CA certificate in a certificate directory. The
+	// file names match the keys of the kubernetes.io/tls Secrets issued by
+	// the operator or by cert-manager.
+	CACertFile = "ca.crt"
+	// TLSCertFile is the certificate in a certificate directory.
+	TLSCertFile = "tls.crt"
+	// TLSKeyFile is the private key in a certificate directory.
+	TLSKeyFile = "tls.key"
+
+	// TLSServerName is the name the database daemon certificates are issued
+	// for. Clients dial the daemons by IP or socket, they verify this name
+	// instead, the CA of the instance vouches for the daemon.
+	TLSServerName = "dbdaemon"
+)
+
+// certPool returns a pool of the PEM encoded CA certificates.
+func certPool(caPEM []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no CA certificate found")
+	}
+	return pool, nil
+}
+
+// loadCertDir reads the CA pool and the key pair of a certificate directory.
+func loadCertDir(dir string) (*x509.CertPool, *tls.Certificate, error) {
+	caPEM, err := ioutil.ReadFile(filepath.Join(dir, CACertFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	pool, err := certPool(caPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", filepath.Join(dir, CACertFile), err)
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, TLSCertFile), filepath.Join(dir, TLSKeyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool, &cert, nil
+}
+
+// ServerCredentials returns the credentials of a gRPC server only accepting
+// clients with a certificate issued by the CA in dir. The certificates are
+// read again on every handshake, so rotated ones are picked up without a
+// restart.
+func ServerCredentials(dir string) credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			pool, cert, err := loadCertDir(dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the server certificates: %v", err)
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+			}, nil
+		},
+	})
+}
+
+// localOrTLS serves loopback connections with local credentials and all
+// others with the embedded TLS credentials.
+type localOrTLS struct {
+	credentials.TransportCredentials
+	local credentials.TransportCredentials
+}
+
+func (c *localOrTLS) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok && addr.IP.IsLoopback() {
+		return c.local.ServerHandshake(conn)
+	}
+	return c.TransportCredentials.ServerHandshake(conn)
+}
+
+func (c *localOrTLS) Clone() credentials.TransportCredentials {
+	return &localOrTLS{TransportCredentials: c.TransportCredentials.Clone(), local: c.local.Clone()}
+}
+
+// LocalOrServerCredentials returns the credentials of a gRPC server only
+// accepting remote clients with a certificate issued by the CA in dir, like
+// ServerCredentials. Clients in the same pod keep connecting over loopback
+// with local credentials.
+func LocalOrServerCredentials(dir string) credentials.TransportCredentials {
+	return &localOrTLS{TransportCredentials: ServerCredentials(dir), local: local.NewCredentials()}
+}
+
+// ClientCredentials returns the credentials of a gRPC client of a database
+// daemon using the certificates in dir. The client certificate is read
+// again on every handshake, so that long-lived connections reconnect with
+// the rotated one.
+func ClientCredentials(dir string) (credentials.TransportCredentials, error) {
+	pool, _, err := loadCertDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the client certificates: %v", err)
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: TLSServerName,
+		RootCAs:    pool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			_, cert, err := loadCertDir(dir)
+			return cert, err
+		},
+	}), nil
+}
+
+// ClientCredentialsFromPEM returns the credentials of a gRPC client of a
+// database daemon using the PEM encoded CA certificate and key pair.
+func ClientCredentialsFromPEM(caPEM, certPEM, keyPEM []byte) (credentials.TransportCredentials, error) {
+	pool, err := certPool(caPEM)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		ServerName:   TLSServerName,
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+	}), nil
+}
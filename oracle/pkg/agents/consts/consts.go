@@ -69,6 +69,9 @@ const (
 	// GetDatabaseIncarnationSQL is used to get current database incarnation number.
 	GetDatabaseIncarnationSQL = "select incarnation# from v$database_incarnation where status='CURRENT'"
 
+	// ListPDBStatesSQL is used to get the state of all PDBs except the seed.
+	ListPDBStatesSQL = "select name, open_mode, restricted, total_size from v$pdbs where name!='PDB$SEED' order by name"
+
 	// DefaultPGAMB is the default size of the PGA which the CDBs are created.
 	DefaultPGAMB = 1200
 
@@ -195,6 +198,14 @@ var (
 	// WalletDir is where the SSL Certs are stored.
 	WalletDir = "/u02/app/oracle/wallet"
 
+	// TDEWalletRoot is the wallet_root of databases with an operator
+	// managed TDE keystore, the keystore is in its tde subdirectory.
+	TDEWalletRoot = "/u02/app/oracle/keystore"
+
+	// DBDaemonTLSDir is where the mutual TLS certificates of the database
+	// daemon gRPC APIs are mounted.
+	DBDaemonTLSDir = "/etc/dbdaemon-tls"
+
 	// OracleDir is where the env file is located
 	OracleDir = "/home/oracle"
 
@@ -204,6 +215,18 @@ var (
 	// RMANStagingDir sets the staging directory for rman backup to GCS.
 	RMANStagingDir = "/u03/app/oracle/rmanstaging"
 
+	// RMANPipeDir is the directory of the named pipes the backup channels of
+	// a streamed backup write their backup pieces to.
+	RMANPipeDir = "/u03/app/oracle/rmanpipes"
+
+	// RMANStreamsDir is the subdirectory of the GCS path of a streamed backup
+	// the backup pieces of the channels are streamed to.
+	RMANStreamsDir = "streams"
+
+	// RMANStreamPipe is the name format of the named pipe of a backup
+	// channel in RMANPipeDir and of its GCS object in RMANStreamsDir.
+	RMANStreamPipe = "channel%d"
+
 	// OracleTimestampToRFC3339Format defines the format used in Oracle to_char() to cast timestamp to RFC3339 format.
 	OracleTimestampToRFC3339Format = `YYYY-MM-DD\"T\"HH24:MI:SS\"Z\"`
 )
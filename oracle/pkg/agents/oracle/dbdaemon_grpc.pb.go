@@ -71,7 +71,8 @@ type DatabaseDaemonClient interface {
 	// exists in a database container or not.
 	// An example of where FileExists is used is a check on
 	// the provisioning_successful file, but any file (nor a dir) can be
-	// checked via this RPC call.
+	// checked via this RPC call. Callers waiting for a file can set
+	// wait_seconds instead of polling FileExists repeatedly.
 	FileExists(ctx context.Context, in *FileExistsRequest, opts ...grpc.CallOption) (*FileExistsResponse, error)
 	// PhysicalRestoreAsync runs RMAN and SQL queries in sequence to restore
 	// a database from an RMAN backup.
@@ -106,6 +107,273 @@ type DatabaseDaemonClient interface {
 	BootstrapDatabase(ctx context.Context, in *BootstrapDatabaseRequest, opts ...grpc.CallOption) (*BootstrapDatabaseResponse, error)
 	// SetDnfsState sets dNFS state
 	SetDnfsState(ctx context.Context, in *SetDnfsStateRequest, opts ...grpc.CallOption) (*SetDnfsStateResponse, error)
+	// VerifyEncryption reports the encryption status of tablespaces and
+	// optionally encrypts unencrypted user tablespaces online.
+	VerifyEncryption(ctx context.Context, in *VerifyEncryptionRequest, opts ...grpc.CallOption) (*VerifyEncryptionResponse, error)
+	// ConfigureNetworkEncryption sets Oracle native network encryption
+	// parameters in sqlnet.ora and bounces the listener if they changed.
+	ConfigureNetworkEncryption(ctx context.Context, in *ConfigureNetworkEncryptionRequest, opts ...grpc.CallOption) (*ConfigureNetworkEncryptionResponse, error)
+	// ConfigureAllowedClients sets the listener valid node checking
+	// parameters in sqlnet.ora and bounces the listener if they changed.
+	ConfigureAllowedClients(ctx context.Context, in *ConfigureAllowedClientsRequest, opts ...grpc.CallOption) (*ConfigureAllowedClientsResponse, error)
+	// ConfigureTCPSListener installs the Oracle wallet of the TCPS listener
+	// and creates the listener, or stops it.
+	ConfigureTCPSListener(ctx context.Context, in *ConfigureTCPSListenerRequest, opts ...grpc.CallOption) (*ConfigureTCPSListenerResponse, error)
+	// GetFRAUsage reports the fast recovery area space usage.
+	GetFRAUsage(ctx context.Context, in *GetFRAUsageRequest, opts ...grpc.CallOption) (*GetFRAUsageResponse, error)
+	// ForceLogSwitch switches the online redo log and waits until the
+	// current log is archived.
+	ForceLogSwitch(ctx context.Context, in *ForceLogSwitchRequest, opts ...grpc.CallOption) (*ForceLogSwitchResponse, error)
+	// ConfigureRMAN applies the RMAN persistent configuration settings which
+	// differ from the current ones and reports the configuration (SHOW ALL).
+	ConfigureRMAN(ctx context.Context, in *ConfigureRMANRequest, opts ...grpc.CallOption) (*ConfigureRMANResponse, error)
+	// GetDBID returns the DBID of the database, required by RMAN to restore
+	// a controlfile autobackup without a recovery catalog.
+	GetDBID(ctx context.Context, in *GetDBIDRequest, opts ...grpc.CallOption) (*GetDBIDResponse, error)
+	// NormalizeParameters rewrites spfile parameters scoped to a specific SID
+	// to sid='*', keeping the values in effect for the instance.
+	NormalizeParameters(ctx context.Context, in *NormalizeParametersRequest, opts ...grpc.CallOption) (*NormalizeParametersResponse, error)
+	// ExportParameters returns the parameters which are not set to their
+	// default values.
+	ExportParameters(ctx context.Context, in *ExportParametersRequest, opts ...grpc.CallOption) (*ExportParametersResponse, error)
+	// GetInstanceInfo returns the startup time, the uptime and the state of
+	// the database instance.
+	GetInstanceInfo(ctx context.Context, in *GetInstanceInfoRequest, opts ...grpc.CallOption) (*GetInstanceInfoResponse, error)
+	// SelfTest runs read-only checks of the operations the daemon depends on
+	// and reports the result of each check.
+	SelfTest(ctx context.Context, in *SelfTestRequest, opts ...grpc.CallOption) (*SelfTestResponse, error)
+	// PrepareForStorageMigration reopens the read write PDBs read only and
+	// checkpoints the database, so that its storage can be copied.
+	PrepareForStorageMigration(ctx context.Context, in *PrepareForStorageMigrationRequest, opts ...grpc.CallOption) (*PrepareForStorageMigrationResponse, error)
+	// CompleteStorageMigration reopens the PDBs made read only by
+	// PrepareForStorageMigration read write, it also aborts a migration.
+	CompleteStorageMigration(ctx context.Context, in *CompleteStorageMigrationRequest, opts ...grpc.CallOption) (*CompleteStorageMigrationResponse, error)
+	// ValidateOratab checks the format of the oratab file and its
+	// DATABASETYPE comment, which GetDatabaseType relies on.
+	ValidateOratab(ctx context.Context, in *ValidateOratabRequest, opts ...grpc.CallOption) (*ValidateOratabResponse, error)
+	// CreateDataPumpDir creates the Data Pump directory of a PDB: the OS
+	// directory, the Oracle directory object pointing to it and the grants of
+	// the PDB loader user, it is idempotent.
+	CreateDataPumpDir(ctx context.Context, in *CreateDataPumpDirRequest, opts ...grpc.CallOption) (*CreateDataPumpDirResponse, error)
+	// CheckStoragePermissions probes listing, reading, writing and deleting
+	// objects under a GCS prefix and reports which of them are permitted.
+	CheckStoragePermissions(ctx context.Context, in *CheckStoragePermissionsRequest, opts ...grpc.CallOption) (*CheckStoragePermissionsResponse, error)
+	// ConfigureInMemory sizes the In-Memory column store of the instance and
+	// sets the INMEMORY attribute of tablespaces and tables of a PDB.
+	ConfigureInMemory(ctx context.Context, in *ConfigureInMemoryRequest, opts ...grpc.CallOption) (*ConfigureInMemoryResponse, error)
+	// GetInMemoryStatus reports the In-Memory column store size and the
+	// population of the In-Memory segments of a PDB.
+	GetInMemoryStatus(ctx context.Context, in *GetInMemoryStatusRequest, opts ...grpc.CallOption) (*GetInMemoryStatusResponse, error)
+	// MaintainPartitions adds, drops and splits range partitions of a table in
+	// a PDB, sets up its interval partitioning and drops the partitions falling
+	// out of its retention.
+	MaintainPartitions(ctx context.Context, in *MaintainPartitionsRequest, opts ...grpc.CallOption) (*MaintainPartitionsResponse, error)
+	// RunSQLTuningAdvisor runs the SQL Tuning Advisor (DBMS_SQLTUNE) on a SQL
+	// statement of a PDB and reports its recommendations. It requires the
+	// Tuning Pack.
+	RunSQLTuningAdvisor(ctx context.Context, in *RunSQLTuningAdvisorRequest, opts ...grpc.CallOption) (*RunSQLTuningAdvisorResponse, error)
+	// CreateAWRBaseline preserves the AWR snapshots of a period as a baseline
+	// to compare the performance before and after a maintenance. It requires
+	// the Diagnostics Pack.
+	CreateAWRBaseline(ctx context.Context, in *CreateAWRBaselineRequest, opts ...grpc.CallOption) (*CreateAWRBaselineResponse, error)
+	// GetSysauxOccupants reports the SYSAUX usage and the space used by its
+	// occupants.
+	GetSysauxOccupants(ctx context.Context, in *GetSysauxOccupantsRequest, opts ...grpc.CallOption) (*GetSysauxOccupantsResponse, error)
+	// PurgeSysaux reclaims SYSAUX space by purging the old data of its
+	// occupants once the SYSAUX usage crosses a threshold.
+	PurgeSysaux(ctx context.Context, in *PurgeSysauxRequest, opts ...grpc.CallOption) (*PurgeSysauxResponse, error)
+	// ConfigureAWR sets the AWR snapshot retention and interval, only the
+	// settings which differ are modified.
+	ConfigureAWR(ctx context.Context, in *ConfigureAWRRequest, opts ...grpc.CallOption) (*ConfigureAWRResponse, error)
+	// GetHostStats returns the CPU, memory, swap and disk I/O stats of the
+	// database container and the usage of its mounts.
+	GetHostStats(ctx context.Context, in *GetHostStatsRequest, opts ...grpc.CallOption) (*GetHostStatsResponse, error)
+	// GrowMount verifies the filesystem of a mount grew after its disk was
+	// expanded and raises the autoextend limit of the datafiles on the mount
+	// which are close to it, so they can use the new space.
+	GrowMount(ctx context.Context, in *GrowMountRequest, opts ...grpc.CallOption) (*GrowMountResponse, error)
+	// GetFeatureUsage reports the database feature usage statistics and flags
+	// the separately licensed options in use which the edition and the
+	// licensed options don't include.
+	GetFeatureUsage(ctx context.Context, in *GetFeatureUsageRequest, opts ...grpc.CallOption) (*GetFeatureUsageResponse, error)
+	// SetLicense declares the edition and the licensed options of the
+	// database. The RPCs enabling a separately licensed option refuse to run
+	// unless the declared license covers it.
+	SetLicense(ctx context.Context, in *SetLicenseRequest, opts ...grpc.CallOption) (*SetLicenseResponse, error)
+	// GetDefaultTablespaces returns the default and default temporary
+	// tablespaces of a PDB and the users whose objects land in SYSTEM.
+	GetDefaultTablespaces(ctx context.Context, in *GetDefaultTablespacesRequest, opts ...grpc.CallOption) (*GetDefaultTablespacesResponse, error)
+	// SetDefaultTablespaces sets the default and default temporary
+	// tablespaces of a PDB, only the settings which differ are modified.
+	SetDefaultTablespaces(ctx context.Context, in *SetDefaultTablespacesRequest, opts ...grpc.CallOption) (*SetDefaultTablespacesResponse, error)
+	// SetUserQuota sets the quotas of a PDB user on tablespaces.
+	SetUserQuota(ctx context.Context, in *SetUserQuotaRequest, opts ...grpc.CallOption) (*SetUserQuotaResponse, error)
+	// GetBlockingSessions returns the chains of sessions waiting on a lock
+	// held by another session, rooted at the sessions which block the others.
+	GetBlockingSessions(ctx context.Context, in *GetBlockingSessionsRequest, opts ...grpc.CallOption) (*GetBlockingSessionsResponse, error)
+	// GetLongRunningOps returns the progress of the operations of
+	// v$session_longops which are still running.
+	GetLongRunningOps(ctx context.Context, in *GetLongRunningOpsRequest, opts ...grpc.CallOption) (*GetLongRunningOpsResponse, error)
+	// GetDeadlocks scans the alert log for ORA-00060 errors and summarizes
+	// the deadlock graph of the trace file each one points to.
+	GetDeadlocks(ctx context.Context, in *GetDeadlocksRequest, opts ...grpc.CallOption) (*GetDeadlocksResponse, error)
+	// StartOnlineRedefinition changes the structure of a table, for example
+	// renames its columns, without blocking its users, with DBMS_REDEFINITION.
+	// The operation metadata is an OnlineRedefinitionProgress and its result
+	// a StartOnlineRedefinitionResponse.
+	StartOnlineRedefinition(ctx context.Context, in *StartOnlineRedefinitionRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// GetStaleStats returns the tables of a PDB whose optimizer statistics
+	// are stale or missing.
+	GetStaleStats(ctx context.Context, in *GetStaleStatsRequest, opts ...grpc.CallOption) (*GetStaleStatsResponse, error)
+	// GatherStats gathers the optimizer statistics of tables of a PDB,
+	// optionally only of those GetStaleStats reports.
+	GatherStats(ctx context.Context, in *GatherStatsRequest, opts ...grpc.CallOption) (*GatherStatsResponse, error)
+	// GetSQLMonitorReport generates the SQL Monitor report of an execution of
+	// a SQL id, returned or uploaded to GCS. It requires the Tuning Pack.
+	GetSQLMonitorReport(ctx context.Context, in *GetSQLMonitorReportRequest, opts ...grpc.CallOption) (*GetSQLMonitorReportResponse, error)
+	// CaptureSQLMonitorReports uploads to GCS the SQL Monitor reports of the
+	// recent executions which ran longer than a threshold. It requires the
+	// Tuning Pack.
+	CaptureSQLMonitorReports(ctx context.Context, in *CaptureSQLMonitorReportsRequest, opts ...grpc.CallOption) (*CaptureSQLMonitorReportsResponse, error)
+	// SetOwnership validates that files and directories are owned by the
+	// oracle user and group and chowns those which aren't.
+	SetOwnership(ctx context.Context, in *SetOwnershipRequest, opts ...grpc.CallOption) (*SetOwnershipResponse, error)
+	// FixOwnership recursively restores the oracle ownership and the owner
+	// permissions of the data and config directories, e.g. after a restore.
+	FixOwnership(ctx context.Context, in *FixOwnershipRequest, opts ...grpc.CallOption) (*FixOwnershipResponse, error)
+	// StreamOperationLog streams the RMAN or Data Pump log of an operation
+	// line by line while it runs, and ends once the operation is done.
+	StreamOperationLog(ctx context.Context, in *StreamOperationLogRequest, opts ...grpc.CallOption) (DatabaseDaemon_StreamOperationLogClient, error)
+	// GetNLSSettings reports the NLS parameters of the database and of the
+	// instance or a PDB.
+	GetNLSSettings(ctx context.Context, in *GetNLSSettingsRequest, opts ...grpc.CallOption) (*GetNLSSettingsResponse, error)
+	// SetNLSSettings sets and resets NLS parameters of the instance or a PDB,
+	// the static ones take effect once the database restarts.
+	SetNLSSettings(ctx context.Context, in *SetNLSSettingsRequest, opts ...grpc.CallOption) (*SetNLSSettingsResponse, error)
+	// ConfigurePDBResources sets and resets the resource parameters of a PDB,
+	// e.g. cpu_count, and reports those in effect.
+	ConfigurePDBResources(ctx context.Context, in *ConfigurePDBResourcesRequest, opts ...grpc.CallOption) (*ConfigurePDBResourcesResponse, error)
+	// ConfigureRowLevelSecurity creates and drops the application contexts
+	// and the VPD (DBMS_RLS) policies of a PDB.
+	ConfigureRowLevelSecurity(ctx context.Context, in *ConfigureRowLevelSecurityRequest, opts ...grpc.CallOption) (*ConfigureRowLevelSecurityResponse, error)
+	// FullInstanceExportAsync exports the PDBs of the instance as of the same
+	// SCN to a GCS prefix, one PDB after the other, and uploads a manifest
+	// describing the set of exports.
+	FullInstanceExportAsync(ctx context.Context, in *FullInstanceExportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// FullInstanceImportAsync imports the PDBs of a full instance export
+	// described by its manifest, one PDB after the other, creating the PDBs
+	// which don't exist.
+	FullInstanceImportAsync(ctx context.Context, in *FullInstanceImportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// GetStandbyApplyStatus returns the role of the database and, on a
+	// standby, the state of redo transport and apply.
+	GetStandbyApplyStatus(ctx context.Context, in *GetStandbyApplyStatusRequest, opts ...grpc.CallOption) (*GetStandbyApplyStatusResponse, error)
+	// ResolveArchiveLogGap stages the archived logs missing on a standby from
+	// the replicated logs in GCS, registers them and restarts redo apply.
+	ResolveArchiveLogGap(ctx context.Context, in *ResolveArchiveLogGapRequest, opts ...grpc.CallOption) (*ResolveArchiveLogGapResponse, error)
+	// ConfigureRedoTransport sets the compression of the redo shipped to the
+	// standby and requires its encryption in the standby listener sqlnet.ora.
+	ConfigureRedoTransport(ctx context.Context, in *ConfigureRedoTransportRequest, opts ...grpc.CallOption) (*ConfigureRedoTransportResponse, error)
+	// GetInstalledOptions returns the components of dba_registry and checks
+	// that the required ones are installed and VALID, optionally recompiling
+	// the invalid ones.
+	GetInstalledOptions(ctx context.Context, in *GetInstalledOptionsRequest, opts ...grpc.CallOption) (*GetInstalledOptionsResponse, error)
+	// RunSQLScriptFromGCS runs the statements of a SQL script downloaded from
+	// GCS, or given inline, in the CDB root or in a PDB.
+	RunSQLScriptFromGCS(ctx context.Context, in *RunSQLScriptFromGCSRequest, opts ...grpc.CallOption) (*RunSQLScriptFromGCSResponse, error)
+	// SwitchOracleHomeAsync patches the database out of place: it restarts the
+	// database from a new Oracle home and runs datapatch, switching back to the
+	// current Oracle home if any step fails.
+	SwitchOracleHomeAsync(ctx context.Context, in *SwitchOracleHomeAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// CheckRestoreCompatibility checks that a backup taken on the source version
+	// can be restored into the Oracle home the database runs from.
+	CheckRestoreCompatibility(ctx context.Context, in *CheckRestoreCompatibilityRequest, opts ...grpc.CallOption) (*CheckRestoreCompatibilityResponse, error)
+	// WriteBackupMetadata writes the metadata of a backup to a JSON object
+	// next to the backup in GCS.
+	WriteBackupMetadata(ctx context.Context, in *WriteBackupMetadataRequest, opts ...grpc.CallOption) (*WriteBackupMetadataResponse, error)
+	// ReadBackupMetadata reads the metadata object of a backup in GCS.
+	ReadBackupMetadata(ctx context.Context, in *ReadBackupMetadataRequest, opts ...grpc.CallOption) (*ReadBackupMetadataResponse, error)
+	// ListRestorePoints lists the restore points of the database from
+	// v$restore_point, with the FRA space used by guaranteed restore points.
+	ListRestorePoints(ctx context.Context, in *ListRestorePointsRequest, opts ...grpc.CallOption) (*ListRestorePointsResponse, error)
+	// CreateRestorePoint creates a normal or a guaranteed restore point.
+	CreateRestorePoint(ctx context.Context, in *CreateRestorePointRequest, opts ...grpc.CallOption) (*CreateRestorePointResponse, error)
+	// DropRestorePoint drops a restore point, releasing the flashback logs
+	// kept for a guaranteed restore point.
+	DropRestorePoint(ctx context.Context, in *DropRestorePointRequest, opts ...grpc.CallOption) (*DropRestorePointResponse, error)
+	// ValidateSnapshotFiles checks that a snapshot of the given volumes is
+	// restorable: the controlfiles, datafiles and online redo logs are on
+	// the volumes and the datafile headers need no media recovery.
+	ValidateSnapshotFiles(ctx context.Context, in *ValidateSnapshotFilesRequest, opts ...grpc.CallOption) (*ValidateSnapshotFilesResponse, error)
+	// GetRedoRate returns the redo generated by the database from its archived
+	// log history and the redo log and log disk sizes that history calls for.
+	GetRedoRate(ctx context.Context, in *GetRedoRateRequest, opts ...grpc.CallOption) (*GetRedoRateResponse, error)
+	// RotateWalletPassword changes the password of the TDE keystore and
+	// regenerates its auto-login keystore, if it has one, to open with the
+	// new password.
+	RotateWalletPassword(ctx context.Context, in *RotateWalletPasswordRequest, opts ...grpc.CallOption) (*RotateWalletPasswordResponse, error)
+	// ConfigureEditions creates and drops the editions of a PDB and sets its
+	// default edition, for edition-based redefinition.
+	ConfigureEditions(ctx context.Context, in *ConfigureEditionsRequest, opts ...grpc.CallOption) (*ConfigureEditionsResponse, error)
+	// GetPGAUsage reports the PGA memory usage of the instance from v$pgastat
+	// and the aggregate target v$pga_target_advice recommends.
+	GetPGAUsage(ctx context.Context, in *GetPGAUsageRequest, opts ...grpc.CallOption) (*GetPGAUsageResponse, error)
+	// SetSQLPlanCapture enables or disables the automatic capture of SQL plan
+	// baselines for the repeatable statements of all the containers.
+	SetSQLPlanCapture(ctx context.Context, in *SetSQLPlanCaptureRequest, opts ...grpc.CallOption) (*SetSQLPlanCaptureResponse, error)
+	// GetSQLPlanBaselines reports the SQL plan baselines of all the
+	// containers.
+	GetSQLPlanBaselines(ctx context.Context, in *GetSQLPlanBaselinesRequest, opts ...grpc.CallOption) (*GetSQLPlanBaselinesResponse, error)
+	// SetTablespaceEncryptionPolicy sets whether new tablespaces, including
+	// those created for imports, are encrypted by default.
+	SetTablespaceEncryptionPolicy(ctx context.Context, in *SetTablespaceEncryptionPolicyRequest, opts ...grpc.CallOption) (*SetTablespaceEncryptionPolicyResponse, error)
+	// RestoreValidationReportAsync validates that the database can be
+	// restored from its backups, reports the files needing a backup and
+	// uploads the consolidated RMAN report to GCS.
+	RestoreValidationReportAsync(ctx context.Context, in *RestoreValidationReportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error)
+	// DiffParametersAgainstBaseline compares the parameters in effect with a
+	// baseline saved from ExportParameters.
+	DiffParametersAgainstBaseline(ctx context.Context, in *DiffParametersAgainstBaselineRequest, opts ...grpc.CallOption) (*DiffParametersAgainstBaselineResponse, error)
+	// ConfigureFAN creates and starts the services of a PDB with Fast
+	// Application Notification (FAN) HA events on or off, and configures the
+	// Oracle Notification Service (ONS) daemon which delivers the events to
+	// the clients.
+	ConfigureFAN(ctx context.Context, in *ConfigureFANRequest, opts ...grpc.CallOption) (*ConfigureFANResponse, error)
+	// CreateManagedTrigger creates or replaces an operator owned trigger of a
+	// PDB, namespaced with the ELCARRO_TRG_ prefix, e.g. the logon trigger of
+	// a maintenance mode.
+	CreateManagedTrigger(ctx context.Context, in *CreateManagedTriggerRequest, opts ...grpc.CallOption) (*CreateManagedTriggerResponse, error)
+	// DropManagedTrigger drops an operator owned trigger of a PDB.
+	DropManagedTrigger(ctx context.Context, in *DropManagedTriggerRequest, opts ...grpc.CallOption) (*DropManagedTriggerResponse, error)
+	// ListManagedTriggers lists the operator owned triggers of a PDB.
+	ListManagedTriggers(ctx context.Context, in *ListManagedTriggersRequest, opts ...grpc.CallOption) (*ListManagedTriggersResponse, error)
+	// CheckStandbySynchronized checks that a standby can switch over without
+	// data loss: redo is applied without lag or gap, and standby redo logs are
+	// configured.
+	CheckStandbySynchronized(ctx context.Context, in *CheckStandbySynchronizedRequest, opts ...grpc.CallOption) (*CheckStandbySynchronizedResponse, error)
+	// ExportRMANCatalog uploads a copy of the control file and a text export
+	// of the RMAN configuration and backup records to GCS, so that the
+	// backups can be found if the control file is lost.
+	ExportRMANCatalog(ctx context.Context, in *ExportRMANCatalogRequest, opts ...grpc.CallOption) (*ExportRMANCatalogResponse, error)
+	// RunWriteCanary writes a row to a one row canary table and reads it back
+	// to check the database accepts writes, which a connection ping doesn't.
+	RunWriteCanary(ctx context.Context, in *RunWriteCanaryRequest, opts ...grpc.CallOption) (*RunWriteCanaryResponse, error)
+	// ReconcilePDBs compares the PDBs of the CDB with the desired ones and
+	// optionally drops the PDBs which aren't desired.
+	ReconcilePDBs(ctx context.Context, in *ReconcilePDBsRequest, opts ...grpc.CallOption) (*ReconcilePDBsResponse, error)
+	// CheckPITRTarget checks that the replicated redo logs recover a backup to
+	// the target of a PITR restore, before the restore replaces the database.
+	CheckPITRTarget(ctx context.Context, in *CheckPITRTargetRequest, opts ...grpc.CallOption) (*CheckPITRTargetResponse, error)
+	// ConfigureKeystore creates the TDE keystore in wallet_root if the
+	// database has none, opens it in all the containers and optionally
+	// creates its auto-login keystore. wallet_root is set first if needed,
+	// the keystore can then only be created once the database restarts.
+	ConfigureKeystore(ctx context.Context, in *ConfigureKeystoreRequest, opts ...grpc.CallOption) (*ConfigureKeystoreResponse, error)
+	// SetMasterKey sets a TDE master encryption key in the open containers
+	// which have none, the CDB root first.
+	SetMasterKey(ctx context.Context, in *SetMasterKeyRequest, opts ...grpc.CallOption) (*SetMasterKeyResponse, error)
+	// EncryptTablespaces encrypts tablespaces of a container online, all its
+	// unencrypted user tablespaces unless the request lists some.
+	EncryptTablespaces(ctx context.Context, in *EncryptTablespacesRequest, opts ...grpc.CallOption) (*EncryptTablespacesResponse, error)
 }
 
 type databaseDaemonClient struct {
@@ -440,868 +708,3652 @@ func (c *databaseDaemonClient) SetDnfsState(ctx context.Context, in *SetDnfsStat
 	return out, nil
 }
 
-// DatabaseDaemonServer is the server API for DatabaseDaemon service.
-// All implementations must embed UnimplementedDatabaseDaemonServer
-// for forward compatibility
-type DatabaseDaemonServer interface {
-	// CreateDirs RPC call to create directories along with any necessary parents.
-	CreateDirs(context.Context, *CreateDirsRequest) (*CreateDirsResponse, error)
-	// ReadDir RPC call to read the directory named by path and returns Fileinfos
-	// for the path and children.
-	ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error)
-	// DeleteDir RPC to call remove path.
-	DeleteDir(context.Context, *DeleteDirRequest) (*DeleteDirResponse, error)
-	// BounceDatabase RPC call to start/stop a database.
-	BounceDatabase(context.Context, *BounceDatabaseRequest) (*BounceDatabaseResponse, error)
-	// BounceListener RPC call to start/stop a listener.
-	BounceListener(context.Context, *BounceListenerRequest) (*BounceListenerResponse, error)
-	// CheckDatabaseState RPC call verifies the database is running.
-	CheckDatabaseState(context.Context, *CheckDatabaseStateRequest) (*CheckDatabaseStateResponse, error)
-	// RunSQLPlus RPC call executes Oracle's sqlplus utility.
-	RunSQLPlus(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error)
-	// RunSQLPlusFormatted RPC is similar to RunSQLPlus, but for queries.
-	RunSQLPlusFormatted(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error)
-	// KnownPDBs RPC call returns a list of known PDBs.
-	KnownPDBs(context.Context, *KnownPDBsRequest) (*KnownPDBsResponse, error)
-	// RunRMAN RPC call executes Oracle's rman utility.
-	RunRMAN(context.Context, *RunRMANRequest) (*RunRMANResponse, error)
-	// RunRMANAsync RPC call executes Oracle's rman utility asynchronously.
-	RunRMANAsync(context.Context, *RunRMANAsyncRequest) (*longrunning.Operation, error)
-	// RunDataGuardBroker RPC call executes Oracle's Data Guard command line
-	// utility.
-	RunDataGuard(context.Context, *RunDataGuardRequest) (*RunDataGuardResponse, error)
-	// TNSPing RPC call executes Oracle's tnsping utility.
-	TNSPing(context.Context, *TNSPingRequest) (*TNSPingResponse, error)
-	// NID changes a database id and/or database name.
-	NID(context.Context, *NIDRequest) (*NIDResponse, error)
-	// GetDatabaseType returns database type(eg. ORACLE_12_2_ENTERPRISE_NONCDB)
-	GetDatabaseType(context.Context, *GetDatabaseTypeRequest) (*GetDatabaseTypeResponse, error)
-	// GetDatabaseName returns database name.
-	GetDatabaseName(context.Context, *GetDatabaseNameRequest) (*GetDatabaseNameResponse, error)
-	// CreatePasswordFile creates a password file for the database.
-	CreatePasswordFile(context.Context, *CreatePasswordFileRequest) (*CreatePasswordFileResponse, error)
-	// SetListenerRegistration sets a static listener registration and restarts
-	// the listener.
-	SetListenerRegistration(context.Context, *SetListenerRegistrationRequest) (*BounceListenerResponse, error)
-	// BootstrapStandby performs bootstrap tasks that have to be done by dbdaemon.
-	BootstrapStandby(context.Context, *BootstrapStandbyRequest) (*BootstrapStandbyResponse, error)
-	// CreateCDBAsync creates a database instance asynchronously.
-	CreateCDBAsync(context.Context, *CreateCDBAsyncRequest) (*longrunning.Operation, error)
-	// BootstrapDatabaseAsync bootstraps seeded database asynchronously.
-	BootstrapDatabaseAsync(context.Context, *BootstrapDatabaseAsyncRequest) (*longrunning.Operation, error)
-	// CreateListener creates a database listener.
-	CreateListener(context.Context, *CreateListenerRequest) (*CreateListenerResponse, error)
-	// FileExists runs a simple check to confirm whether a requested file
-	// exists in a database container or not.
-	// An example of where FileExists is used is a check on
-	// the provisioning_successful file, but any file (nor a dir) can be
-	// checked via this RPC call.
-	FileExists(context.Context, *FileExistsRequest) (*FileExistsResponse, error)
-	// PhysicalRestoreAsync runs RMAN and SQL queries in sequence to restore
-	// a database from an RMAN backup.
-	PhysicalRestoreAsync(context.Context, *PhysicalRestoreAsyncRequest) (*longrunning.Operation, error)
-	// DataPumpImportAsync imports data from a .dmp file to an existing PDB.
-	DataPumpImportAsync(context.Context, *DataPumpImportAsyncRequest) (*longrunning.Operation, error)
-	// DataPumpExportAsync exports data to a .dmp file using expdp
-	DataPumpExportAsync(context.Context, *DataPumpExportAsyncRequest) (*longrunning.Operation, error)
-	// ApplyDataPatchAsync applies Oracle `datapatch` utility
-	// and starts DB (CDB+PDBs) up after patching
-	ApplyDataPatchAsync(context.Context, *ApplyDataPatchAsyncRequest) (*longrunning.Operation, error)
-	// ListOperations lists operations that match the specified filter in the
-	// request.
-	ListOperations(context.Context, *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error)
-	// GetOperation gets the latest state of a long-running operation. Clients can
-	// use this method to poll the operation result.
-	GetOperation(context.Context, *longrunning.GetOperationRequest) (*longrunning.Operation, error)
-	// DeleteOperation deletes a long-running operation. This method indicates
-	// that the client is no longer interested in the operation result. It does
-	// not cancel the operation.
-	DeleteOperation(context.Context, *longrunning.DeleteOperationRequest) (*emptypb.Empty, error)
-	// RecoverConfigFile creates a binary pfile from the backed up spfile
-	RecoverConfigFile(context.Context, *RecoverConfigFileRequest) (*RecoverConfigFileResponse, error)
-	// DownloadDirectoryFromGCS downloads a directory from GCS bucket to local
-	// path.
-	DownloadDirectoryFromGCS(context.Context, *DownloadDirectoryFromGCSRequest) (*DownloadDirectoryFromGCSResponse, error)
-	// FetchServiceImageMetaData returns the service image metadata.
-	FetchServiceImageMetaData(context.Context, *FetchServiceImageMetaDataRequest) (*FetchServiceImageMetaDataResponse, error)
-	// CreateFile creates file based on file path and content.
-	CreateFile(context.Context, *CreateFileRequest) (*CreateFileResponse, error)
-	// BootstrapDatabase bootstraps seeded database by executing init_oracle
-	BootstrapDatabase(context.Context, *BootstrapDatabaseRequest) (*BootstrapDatabaseResponse, error)
-	// SetDnfsState sets dNFS state
-	SetDnfsState(context.Context, *SetDnfsStateRequest) (*SetDnfsStateResponse, error)
-	mustEmbedUnimplementedDatabaseDaemonServer()
+func (c *databaseDaemonClient) VerifyEncryption(ctx context.Context, in *VerifyEncryptionRequest, opts ...grpc.CallOption) (*VerifyEncryptionResponse, error) {
+	out := new(VerifyEncryptionResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/VerifyEncryption", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// UnimplementedDatabaseDaemonServer must be embedded to have forward compatible implementations.
-type UnimplementedDatabaseDaemonServer struct {
+func (c *databaseDaemonClient) ConfigureNetworkEncryption(ctx context.Context, in *ConfigureNetworkEncryptionRequest, opts ...grpc.CallOption) (*ConfigureNetworkEncryptionResponse, error) {
+	out := new(ConfigureNetworkEncryptionResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureNetworkEncryption", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (UnimplementedDatabaseDaemonServer) CreateDirs(context.Context, *CreateDirsRequest) (*CreateDirsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateDirs not implemented")
-}
-func (UnimplementedDatabaseDaemonServer) ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ReadDir not implemented")
+func (c *databaseDaemonClient) ConfigureAllowedClients(ctx context.Context, in *ConfigureAllowedClientsRequest, opts ...grpc.CallOption) (*ConfigureAllowedClientsResponse, error) {
+	out := new(ConfigureAllowedClientsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureAllowedClients", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) DeleteDir(context.Context, *DeleteDirRequest) (*DeleteDirResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteDir not implemented")
+
+func (c *databaseDaemonClient) ConfigureTCPSListener(ctx context.Context, in *ConfigureTCPSListenerRequest, opts ...grpc.CallOption) (*ConfigureTCPSListenerResponse, error) {
+	out := new(ConfigureTCPSListenerResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureTCPSListener", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) BounceDatabase(context.Context, *BounceDatabaseRequest) (*BounceDatabaseResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method BounceDatabase not implemented")
+
+func (c *databaseDaemonClient) GetFRAUsage(ctx context.Context, in *GetFRAUsageRequest, opts ...grpc.CallOption) (*GetFRAUsageResponse, error) {
+	out := new(GetFRAUsageResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetFRAUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) BounceListener(context.Context, *BounceListenerRequest) (*BounceListenerResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method BounceListener not implemented")
+
+func (c *databaseDaemonClient) ForceLogSwitch(ctx context.Context, in *ForceLogSwitchRequest, opts ...grpc.CallOption) (*ForceLogSwitchResponse, error) {
+	out := new(ForceLogSwitchResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ForceLogSwitch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) CheckDatabaseState(context.Context, *CheckDatabaseStateRequest) (*CheckDatabaseStateResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CheckDatabaseState not implemented")
+
+func (c *databaseDaemonClient) ConfigureRMAN(ctx context.Context, in *ConfigureRMANRequest, opts ...grpc.CallOption) (*ConfigureRMANResponse, error) {
+	out := new(ConfigureRMANResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureRMAN", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) RunSQLPlus(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunSQLPlus not implemented")
+
+func (c *databaseDaemonClient) GetDBID(ctx context.Context, in *GetDBIDRequest, opts ...grpc.CallOption) (*GetDBIDResponse, error) {
+	out := new(GetDBIDResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetDBID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) RunSQLPlusFormatted(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunSQLPlusFormatted not implemented")
+
+func (c *databaseDaemonClient) NormalizeParameters(ctx context.Context, in *NormalizeParametersRequest, opts ...grpc.CallOption) (*NormalizeParametersResponse, error) {
+	out := new(NormalizeParametersResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/NormalizeParameters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) KnownPDBs(context.Context, *KnownPDBsRequest) (*KnownPDBsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method KnownPDBs not implemented")
+
+func (c *databaseDaemonClient) ExportParameters(ctx context.Context, in *ExportParametersRequest, opts ...grpc.CallOption) (*ExportParametersResponse, error) {
+	out := new(ExportParametersResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ExportParameters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) RunRMAN(context.Context, *RunRMANRequest) (*RunRMANResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunRMAN not implemented")
+
+func (c *databaseDaemonClient) GetInstanceInfo(ctx context.Context, in *GetInstanceInfoRequest, opts ...grpc.CallOption) (*GetInstanceInfoResponse, error) {
+	out := new(GetInstanceInfoResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetInstanceInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) RunRMANAsync(context.Context, *RunRMANAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunRMANAsync not implemented")
+
+func (c *databaseDaemonClient) SelfTest(ctx context.Context, in *SelfTestRequest, opts ...grpc.CallOption) (*SelfTestResponse, error) {
+	out := new(SelfTestResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SelfTest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) RunDataGuard(context.Context, *RunDataGuardRequest) (*RunDataGuardResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RunDataGuard not implemented")
+
+func (c *databaseDaemonClient) PrepareForStorageMigration(ctx context.Context, in *PrepareForStorageMigrationRequest, opts ...grpc.CallOption) (*PrepareForStorageMigrationResponse, error) {
+	out := new(PrepareForStorageMigrationResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/PrepareForStorageMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) TNSPing(context.Context, *TNSPingRequest) (*TNSPingResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method TNSPing not implemented")
+
+func (c *databaseDaemonClient) CompleteStorageMigration(ctx context.Context, in *CompleteStorageMigrationRequest, opts ...grpc.CallOption) (*CompleteStorageMigrationResponse, error) {
+	out := new(CompleteStorageMigrationResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CompleteStorageMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) NID(context.Context, *NIDRequest) (*NIDResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method NID not implemented")
+
+func (c *databaseDaemonClient) ValidateOratab(ctx context.Context, in *ValidateOratabRequest, opts ...grpc.CallOption) (*ValidateOratabResponse, error) {
+	out := new(ValidateOratabResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ValidateOratab", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) GetDatabaseType(context.Context, *GetDatabaseTypeRequest) (*GetDatabaseTypeResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseType not implemented")
+
+func (c *databaseDaemonClient) CreateDataPumpDir(ctx context.Context, in *CreateDataPumpDirRequest, opts ...grpc.CallOption) (*CreateDataPumpDirResponse, error) {
+	out := new(CreateDataPumpDirResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CreateDataPumpDir", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) GetDatabaseName(context.Context, *GetDatabaseNameRequest) (*GetDatabaseNameResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseName not implemented")
+
+func (c *databaseDaemonClient) CheckStoragePermissions(ctx context.Context, in *CheckStoragePermissionsRequest, opts ...grpc.CallOption) (*CheckStoragePermissionsResponse, error) {
+	out := new(CheckStoragePermissionsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CheckStoragePermissions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) CreatePasswordFile(context.Context, *CreatePasswordFileRequest) (*CreatePasswordFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreatePasswordFile not implemented")
+
+func (c *databaseDaemonClient) ConfigureInMemory(ctx context.Context, in *ConfigureInMemoryRequest, opts ...grpc.CallOption) (*ConfigureInMemoryResponse, error) {
+	out := new(ConfigureInMemoryResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureInMemory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) SetListenerRegistration(context.Context, *SetListenerRegistrationRequest) (*BounceListenerResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SetListenerRegistration not implemented")
+
+func (c *databaseDaemonClient) GetInMemoryStatus(ctx context.Context, in *GetInMemoryStatusRequest, opts ...grpc.CallOption) (*GetInMemoryStatusResponse, error) {
+	out := new(GetInMemoryStatusResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetInMemoryStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) BootstrapStandby(context.Context, *BootstrapStandbyRequest) (*BootstrapStandbyResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method BootstrapStandby not implemented")
+
+func (c *databaseDaemonClient) MaintainPartitions(ctx context.Context, in *MaintainPartitionsRequest, opts ...grpc.CallOption) (*MaintainPartitionsResponse, error) {
+	out := new(MaintainPartitionsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/MaintainPartitions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) CreateCDBAsync(context.Context, *CreateCDBAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateCDBAsync not implemented")
+
+func (c *databaseDaemonClient) RunSQLTuningAdvisor(ctx context.Context, in *RunSQLTuningAdvisorRequest, opts ...grpc.CallOption) (*RunSQLTuningAdvisorResponse, error) {
+	out := new(RunSQLTuningAdvisorResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/RunSQLTuningAdvisor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) BootstrapDatabaseAsync(context.Context, *BootstrapDatabaseAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method BootstrapDatabaseAsync not implemented")
+
+func (c *databaseDaemonClient) CreateAWRBaseline(ctx context.Context, in *CreateAWRBaselineRequest, opts ...grpc.CallOption) (*CreateAWRBaselineResponse, error) {
+	out := new(CreateAWRBaselineResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CreateAWRBaseline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) CreateListener(context.Context, *CreateListenerRequest) (*CreateListenerResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateListener not implemented")
+
+func (c *databaseDaemonClient) GetSysauxOccupants(ctx context.Context, in *GetSysauxOccupantsRequest, opts ...grpc.CallOption) (*GetSysauxOccupantsResponse, error) {
+	out := new(GetSysauxOccupantsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetSysauxOccupants", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
-func (UnimplementedDatabaseDaemonServer) FileExists(context.Context, *FileExistsRequest) (*FileExistsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method FileExists not implemented")
+
+func (c *databaseDaemonClient) PurgeSysaux(ctx context.Context, in *PurgeSysauxRequest, opts ...grpc.CallOption) (*PurgeSysauxResponse, error) {
+	out := new(PurgeSysauxResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/PurgeSysaux", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureAWR(ctx context.Context, in *ConfigureAWRRequest, opts ...grpc.CallOption) (*ConfigureAWRResponse, error) {
+	out := new(ConfigureAWRResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureAWR", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetHostStats(ctx context.Context, in *GetHostStatsRequest, opts ...grpc.CallOption) (*GetHostStatsResponse, error) {
+	out := new(GetHostStatsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetHostStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GrowMount(ctx context.Context, in *GrowMountRequest, opts ...grpc.CallOption) (*GrowMountResponse, error) {
+	out := new(GrowMountResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GrowMount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetFeatureUsage(ctx context.Context, in *GetFeatureUsageRequest, opts ...grpc.CallOption) (*GetFeatureUsageResponse, error) {
+	out := new(GetFeatureUsageResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetFeatureUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetLicense(ctx context.Context, in *SetLicenseRequest, opts ...grpc.CallOption) (*SetLicenseResponse, error) {
+	out := new(SetLicenseResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetLicense", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetDefaultTablespaces(ctx context.Context, in *GetDefaultTablespacesRequest, opts ...grpc.CallOption) (*GetDefaultTablespacesResponse, error) {
+	out := new(GetDefaultTablespacesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetDefaultTablespaces", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetDefaultTablespaces(ctx context.Context, in *SetDefaultTablespacesRequest, opts ...grpc.CallOption) (*SetDefaultTablespacesResponse, error) {
+	out := new(SetDefaultTablespacesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetDefaultTablespaces", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetUserQuota(ctx context.Context, in *SetUserQuotaRequest, opts ...grpc.CallOption) (*SetUserQuotaResponse, error) {
+	out := new(SetUserQuotaResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetUserQuota", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetBlockingSessions(ctx context.Context, in *GetBlockingSessionsRequest, opts ...grpc.CallOption) (*GetBlockingSessionsResponse, error) {
+	out := new(GetBlockingSessionsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetBlockingSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetLongRunningOps(ctx context.Context, in *GetLongRunningOpsRequest, opts ...grpc.CallOption) (*GetLongRunningOpsResponse, error) {
+	out := new(GetLongRunningOpsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetLongRunningOps", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetDeadlocks(ctx context.Context, in *GetDeadlocksRequest, opts ...grpc.CallOption) (*GetDeadlocksResponse, error) {
+	out := new(GetDeadlocksResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetDeadlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) StartOnlineRedefinition(ctx context.Context, in *StartOnlineRedefinitionRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/StartOnlineRedefinition", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetStaleStats(ctx context.Context, in *GetStaleStatsRequest, opts ...grpc.CallOption) (*GetStaleStatsResponse, error) {
+	out := new(GetStaleStatsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetStaleStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GatherStats(ctx context.Context, in *GatherStatsRequest, opts ...grpc.CallOption) (*GatherStatsResponse, error) {
+	out := new(GatherStatsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GatherStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetSQLMonitorReport(ctx context.Context, in *GetSQLMonitorReportRequest, opts ...grpc.CallOption) (*GetSQLMonitorReportResponse, error) {
+	out := new(GetSQLMonitorReportResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetSQLMonitorReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CaptureSQLMonitorReports(ctx context.Context, in *CaptureSQLMonitorReportsRequest, opts ...grpc.CallOption) (*CaptureSQLMonitorReportsResponse, error) {
+	out := new(CaptureSQLMonitorReportsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CaptureSQLMonitorReports", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetOwnership(ctx context.Context, in *SetOwnershipRequest, opts ...grpc.CallOption) (*SetOwnershipResponse, error) {
+	out := new(SetOwnershipResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetOwnership", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) FixOwnership(ctx context.Context, in *FixOwnershipRequest, opts ...grpc.CallOption) (*FixOwnershipResponse, error) {
+	out := new(FixOwnershipResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/FixOwnership", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) StreamOperationLog(ctx context.Context, in *StreamOperationLogRequest, opts ...grpc.CallOption) (DatabaseDaemon_StreamOperationLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DatabaseDaemon_ServiceDesc.Streams[0], "/agents.oracle.DatabaseDaemon/StreamOperationLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &databaseDaemonStreamOperationLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DatabaseDaemon_StreamOperationLogClient interface {
+	Recv() (*StreamOperationLogResponse, error)
+	grpc.ClientStream
+}
+
+type databaseDaemonStreamOperationLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *databaseDaemonStreamOperationLogClient) Recv() (*StreamOperationLogResponse, error) {
+	m := new(StreamOperationLogResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *databaseDaemonClient) GetNLSSettings(ctx context.Context, in *GetNLSSettingsRequest, opts ...grpc.CallOption) (*GetNLSSettingsResponse, error) {
+	out := new(GetNLSSettingsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetNLSSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetNLSSettings(ctx context.Context, in *SetNLSSettingsRequest, opts ...grpc.CallOption) (*SetNLSSettingsResponse, error) {
+	out := new(SetNLSSettingsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetNLSSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigurePDBResources(ctx context.Context, in *ConfigurePDBResourcesRequest, opts ...grpc.CallOption) (*ConfigurePDBResourcesResponse, error) {
+	out := new(ConfigurePDBResourcesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigurePDBResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureRowLevelSecurity(ctx context.Context, in *ConfigureRowLevelSecurityRequest, opts ...grpc.CallOption) (*ConfigureRowLevelSecurityResponse, error) {
+	out := new(ConfigureRowLevelSecurityResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureRowLevelSecurity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) FullInstanceExportAsync(ctx context.Context, in *FullInstanceExportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/FullInstanceExportAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) FullInstanceImportAsync(ctx context.Context, in *FullInstanceImportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/FullInstanceImportAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetStandbyApplyStatus(ctx context.Context, in *GetStandbyApplyStatusRequest, opts ...grpc.CallOption) (*GetStandbyApplyStatusResponse, error) {
+	out := new(GetStandbyApplyStatusResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetStandbyApplyStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ResolveArchiveLogGap(ctx context.Context, in *ResolveArchiveLogGapRequest, opts ...grpc.CallOption) (*ResolveArchiveLogGapResponse, error) {
+	out := new(ResolveArchiveLogGapResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ResolveArchiveLogGap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureRedoTransport(ctx context.Context, in *ConfigureRedoTransportRequest, opts ...grpc.CallOption) (*ConfigureRedoTransportResponse, error) {
+	out := new(ConfigureRedoTransportResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureRedoTransport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetInstalledOptions(ctx context.Context, in *GetInstalledOptionsRequest, opts ...grpc.CallOption) (*GetInstalledOptionsResponse, error) {
+	out := new(GetInstalledOptionsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetInstalledOptions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) RunSQLScriptFromGCS(ctx context.Context, in *RunSQLScriptFromGCSRequest, opts ...grpc.CallOption) (*RunSQLScriptFromGCSResponse, error) {
+	out := new(RunSQLScriptFromGCSResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/RunSQLScriptFromGCS", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SwitchOracleHomeAsync(ctx context.Context, in *SwitchOracleHomeAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SwitchOracleHomeAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CheckRestoreCompatibility(ctx context.Context, in *CheckRestoreCompatibilityRequest, opts ...grpc.CallOption) (*CheckRestoreCompatibilityResponse, error) {
+	out := new(CheckRestoreCompatibilityResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CheckRestoreCompatibility", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) WriteBackupMetadata(ctx context.Context, in *WriteBackupMetadataRequest, opts ...grpc.CallOption) (*WriteBackupMetadataResponse, error) {
+	out := new(WriteBackupMetadataResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/WriteBackupMetadata", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ReadBackupMetadata(ctx context.Context, in *ReadBackupMetadataRequest, opts ...grpc.CallOption) (*ReadBackupMetadataResponse, error) {
+	out := new(ReadBackupMetadataResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ReadBackupMetadata", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ListRestorePoints(ctx context.Context, in *ListRestorePointsRequest, opts ...grpc.CallOption) (*ListRestorePointsResponse, error) {
+	out := new(ListRestorePointsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ListRestorePoints", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CreateRestorePoint(ctx context.Context, in *CreateRestorePointRequest, opts ...grpc.CallOption) (*CreateRestorePointResponse, error) {
+	out := new(CreateRestorePointResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CreateRestorePoint", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) DropRestorePoint(ctx context.Context, in *DropRestorePointRequest, opts ...grpc.CallOption) (*DropRestorePointResponse, error) {
+	out := new(DropRestorePointResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/DropRestorePoint", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ValidateSnapshotFiles(ctx context.Context, in *ValidateSnapshotFilesRequest, opts ...grpc.CallOption) (*ValidateSnapshotFilesResponse, error) {
+	out := new(ValidateSnapshotFilesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ValidateSnapshotFiles", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetRedoRate(ctx context.Context, in *GetRedoRateRequest, opts ...grpc.CallOption) (*GetRedoRateResponse, error) {
+	out := new(GetRedoRateResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetRedoRate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) RotateWalletPassword(ctx context.Context, in *RotateWalletPasswordRequest, opts ...grpc.CallOption) (*RotateWalletPasswordResponse, error) {
+	out := new(RotateWalletPasswordResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/RotateWalletPassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureEditions(ctx context.Context, in *ConfigureEditionsRequest, opts ...grpc.CallOption) (*ConfigureEditionsResponse, error) {
+	out := new(ConfigureEditionsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureEditions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetPGAUsage(ctx context.Context, in *GetPGAUsageRequest, opts ...grpc.CallOption) (*GetPGAUsageResponse, error) {
+	out := new(GetPGAUsageResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetPGAUsage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetSQLPlanCapture(ctx context.Context, in *SetSQLPlanCaptureRequest, opts ...grpc.CallOption) (*SetSQLPlanCaptureResponse, error) {
+	out := new(SetSQLPlanCaptureResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetSQLPlanCapture", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) GetSQLPlanBaselines(ctx context.Context, in *GetSQLPlanBaselinesRequest, opts ...grpc.CallOption) (*GetSQLPlanBaselinesResponse, error) {
+	out := new(GetSQLPlanBaselinesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/GetSQLPlanBaselines", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetTablespaceEncryptionPolicy(ctx context.Context, in *SetTablespaceEncryptionPolicyRequest, opts ...grpc.CallOption) (*SetTablespaceEncryptionPolicyResponse, error) {
+	out := new(SetTablespaceEncryptionPolicyResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetTablespaceEncryptionPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) RestoreValidationReportAsync(ctx context.Context, in *RestoreValidationReportAsyncRequest, opts ...grpc.CallOption) (*longrunning.Operation, error) {
+	out := new(longrunning.Operation)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/RestoreValidationReportAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) DiffParametersAgainstBaseline(ctx context.Context, in *DiffParametersAgainstBaselineRequest, opts ...grpc.CallOption) (*DiffParametersAgainstBaselineResponse, error) {
+	out := new(DiffParametersAgainstBaselineResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/DiffParametersAgainstBaseline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureFAN(ctx context.Context, in *ConfigureFANRequest, opts ...grpc.CallOption) (*ConfigureFANResponse, error) {
+	out := new(ConfigureFANResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureFAN", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CreateManagedTrigger(ctx context.Context, in *CreateManagedTriggerRequest, opts ...grpc.CallOption) (*CreateManagedTriggerResponse, error) {
+	out := new(CreateManagedTriggerResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CreateManagedTrigger", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) DropManagedTrigger(ctx context.Context, in *DropManagedTriggerRequest, opts ...grpc.CallOption) (*DropManagedTriggerResponse, error) {
+	out := new(DropManagedTriggerResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/DropManagedTrigger", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ListManagedTriggers(ctx context.Context, in *ListManagedTriggersRequest, opts ...grpc.CallOption) (*ListManagedTriggersResponse, error) {
+	out := new(ListManagedTriggersResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ListManagedTriggers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CheckStandbySynchronized(ctx context.Context, in *CheckStandbySynchronizedRequest, opts ...grpc.CallOption) (*CheckStandbySynchronizedResponse, error) {
+	out := new(CheckStandbySynchronizedResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CheckStandbySynchronized", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ExportRMANCatalog(ctx context.Context, in *ExportRMANCatalogRequest, opts ...grpc.CallOption) (*ExportRMANCatalogResponse, error) {
+	out := new(ExportRMANCatalogResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ExportRMANCatalog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) RunWriteCanary(ctx context.Context, in *RunWriteCanaryRequest, opts ...grpc.CallOption) (*RunWriteCanaryResponse, error) {
+	out := new(RunWriteCanaryResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/RunWriteCanary", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ReconcilePDBs(ctx context.Context, in *ReconcilePDBsRequest, opts ...grpc.CallOption) (*ReconcilePDBsResponse, error) {
+	out := new(ReconcilePDBsResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ReconcilePDBs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) CheckPITRTarget(ctx context.Context, in *CheckPITRTargetRequest, opts ...grpc.CallOption) (*CheckPITRTargetResponse, error) {
+	out := new(CheckPITRTargetResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/CheckPITRTarget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) ConfigureKeystore(ctx context.Context, in *ConfigureKeystoreRequest, opts ...grpc.CallOption) (*ConfigureKeystoreResponse, error) {
+	out := new(ConfigureKeystoreResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/ConfigureKeystore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) SetMasterKey(ctx context.Context, in *SetMasterKeyRequest, opts ...grpc.CallOption) (*SetMasterKeyResponse, error) {
+	out := new(SetMasterKeyResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/SetMasterKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseDaemonClient) EncryptTablespaces(ctx context.Context, in *EncryptTablespacesRequest, opts ...grpc.CallOption) (*EncryptTablespacesResponse, error) {
+	out := new(EncryptTablespacesResponse)
+	err := c.cc.Invoke(ctx, "/agents.oracle.DatabaseDaemon/EncryptTablespaces", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DatabaseDaemonServer is the server API for DatabaseDaemon service.
+// All implementations must embed UnimplementedDatabaseDaemonServer
+// for forward compatibility
+type DatabaseDaemonServer interface {
+	// CreateDirs RPC call to create directories along with any necessary parents.
+	CreateDirs(context.Context, *CreateDirsRequest) (*CreateDirsResponse, error)
+	// ReadDir RPC call to read the directory named by path and returns Fileinfos
+	// for the path and children.
+	ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error)
+	// DeleteDir RPC to call remove path.
+	DeleteDir(context.Context, *DeleteDirRequest) (*DeleteDirResponse, error)
+	// BounceDatabase RPC call to start/stop a database.
+	BounceDatabase(context.Context, *BounceDatabaseRequest) (*BounceDatabaseResponse, error)
+	// BounceListener RPC call to start/stop a listener.
+	BounceListener(context.Context, *BounceListenerRequest) (*BounceListenerResponse, error)
+	// CheckDatabaseState RPC call verifies the database is running.
+	CheckDatabaseState(context.Context, *CheckDatabaseStateRequest) (*CheckDatabaseStateResponse, error)
+	// RunSQLPlus RPC call executes Oracle's sqlplus utility.
+	RunSQLPlus(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error)
+	// RunSQLPlusFormatted RPC is similar to RunSQLPlus, but for queries.
+	RunSQLPlusFormatted(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error)
+	// KnownPDBs RPC call returns a list of known PDBs.
+	KnownPDBs(context.Context, *KnownPDBsRequest) (*KnownPDBsResponse, error)
+	// RunRMAN RPC call executes Oracle's rman utility.
+	RunRMAN(context.Context, *RunRMANRequest) (*RunRMANResponse, error)
+	// RunRMANAsync RPC call executes Oracle's rman utility asynchronously.
+	RunRMANAsync(context.Context, *RunRMANAsyncRequest) (*longrunning.Operation, error)
+	// RunDataGuardBroker RPC call executes Oracle's Data Guard command line
+	// utility.
+	RunDataGuard(context.Context, *RunDataGuardRequest) (*RunDataGuardResponse, error)
+	// TNSPing RPC call executes Oracle's tnsping utility.
+	TNSPing(context.Context, *TNSPingRequest) (*TNSPingResponse, error)
+	// NID changes a database id and/or database name.
+	NID(context.Context, *NIDRequest) (*NIDResponse, error)
+	// GetDatabaseType returns database type(eg. ORACLE_12_2_ENTERPRISE_NONCDB)
+	GetDatabaseType(context.Context, *GetDatabaseTypeRequest) (*GetDatabaseTypeResponse, error)
+	// GetDatabaseName returns database name.
+	GetDatabaseName(context.Context, *GetDatabaseNameRequest) (*GetDatabaseNameResponse, error)
+	// CreatePasswordFile creates a password file for the database.
+	CreatePasswordFile(context.Context, *CreatePasswordFileRequest) (*CreatePasswordFileResponse, error)
+	// SetListenerRegistration sets a static listener registration and restarts
+	// the listener.
+	SetListenerRegistration(context.Context, *SetListenerRegistrationRequest) (*BounceListenerResponse, error)
+	// BootstrapStandby performs bootstrap tasks that have to be done by dbdaemon.
+	BootstrapStandby(context.Context, *BootstrapStandbyRequest) (*BootstrapStandbyResponse, error)
+	// CreateCDBAsync creates a database instance asynchronously.
+	CreateCDBAsync(context.Context, *CreateCDBAsyncRequest) (*longrunning.Operation, error)
+	// BootstrapDatabaseAsync bootstraps seeded database asynchronously.
+	BootstrapDatabaseAsync(context.Context, *BootstrapDatabaseAsyncRequest) (*longrunning.Operation, error)
+	// CreateListener creates a database listener.
+	CreateListener(context.Context, *CreateListenerRequest) (*CreateListenerResponse, error)
+	// FileExists runs a simple check to confirm whether a requested file
+	// exists in a database container or not.
+	// An example of where FileExists is used is a check on
+	// the provisioning_successful file, but any file (nor a dir) can be
+	// checked via this RPC call. Callers waiting for a file can set
+	// wait_seconds instead of polling FileExists repeatedly.
+	FileExists(context.Context, *FileExistsRequest) (*FileExistsResponse, error)
+	// PhysicalRestoreAsync runs RMAN and SQL queries in sequence to restore
+	// a database from an RMAN backup.
+	PhysicalRestoreAsync(context.Context, *PhysicalRestoreAsyncRequest) (*longrunning.Operation, error)
+	// DataPumpImportAsync imports data from a .dmp file to an existing PDB.
+	DataPumpImportAsync(context.Context, *DataPumpImportAsyncRequest) (*longrunning.Operation, error)
+	// DataPumpExportAsync exports data to a .dmp file using expdp
+	DataPumpExportAsync(context.Context, *DataPumpExportAsyncRequest) (*longrunning.Operation, error)
+	// ApplyDataPatchAsync applies Oracle `datapatch` utility
+	// and starts DB (CDB+PDBs) up after patching
+	ApplyDataPatchAsync(context.Context, *ApplyDataPatchAsyncRequest) (*longrunning.Operation, error)
+	// ListOperations lists operations that match the specified filter in the
+	// request.
+	ListOperations(context.Context, *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error)
+	// GetOperation gets the latest state of a long-running operation. Clients can
+	// use this method to poll the operation result.
+	GetOperation(context.Context, *longrunning.GetOperationRequest) (*longrunning.Operation, error)
+	// DeleteOperation deletes a long-running operation. This method indicates
+	// that the client is no longer interested in the operation result. It does
+	// not cancel the operation.
+	DeleteOperation(context.Context, *longrunning.DeleteOperationRequest) (*emptypb.Empty, error)
+	// RecoverConfigFile creates a binary pfile from the backed up spfile
+	RecoverConfigFile(context.Context, *RecoverConfigFileRequest) (*RecoverConfigFileResponse, error)
+	// DownloadDirectoryFromGCS downloads a directory from GCS bucket to local
+	// path.
+	DownloadDirectoryFromGCS(context.Context, *DownloadDirectoryFromGCSRequest) (*DownloadDirectoryFromGCSResponse, error)
+	// FetchServiceImageMetaData returns the service image metadata.
+	FetchServiceImageMetaData(context.Context, *FetchServiceImageMetaDataRequest) (*FetchServiceImageMetaDataResponse, error)
+	// CreateFile creates file based on file path and content.
+	CreateFile(context.Context, *CreateFileRequest) (*CreateFileResponse, error)
+	// BootstrapDatabase bootstraps seeded database by executing init_oracle
+	BootstrapDatabase(context.Context, *BootstrapDatabaseRequest) (*BootstrapDatabaseResponse, error)
+	// SetDnfsState sets dNFS state
+	SetDnfsState(context.Context, *SetDnfsStateRequest) (*SetDnfsStateResponse, error)
+	// VerifyEncryption reports the encryption status of tablespaces and
+	// optionally encrypts unencrypted user tablespaces online.
+	VerifyEncryption(context.Context, *VerifyEncryptionRequest) (*VerifyEncryptionResponse, error)
+	// ConfigureNetworkEncryption sets Oracle native network encryption
+	// parameters in sqlnet.ora and bounces the listener if they changed.
+	ConfigureNetworkEncryption(context.Context, *ConfigureNetworkEncryptionRequest) (*ConfigureNetworkEncryptionResponse, error)
+	// ConfigureAllowedClients sets the listener valid node checking
+	// parameters in sqlnet.ora and bounces the listener if they changed.
+	ConfigureAllowedClients(context.Context, *ConfigureAllowedClientsRequest) (*ConfigureAllowedClientsResponse, error)
+	// ConfigureTCPSListener installs the Oracle wallet of the TCPS listener
+	// and creates the listener, or stops it.
+	ConfigureTCPSListener(context.Context, *ConfigureTCPSListenerRequest) (*ConfigureTCPSListenerResponse, error)
+	// GetFRAUsage reports the fast recovery area space usage.
+	GetFRAUsage(context.Context, *GetFRAUsageRequest) (*GetFRAUsageResponse, error)
+	// ForceLogSwitch switches the online redo log and waits until the
+	// current log is archived.
+	ForceLogSwitch(context.Context, *ForceLogSwitchRequest) (*ForceLogSwitchResponse, error)
+	// ConfigureRMAN applies the RMAN persistent configuration settings which
+	// differ from the current ones and reports the configuration (SHOW ALL).
+	ConfigureRMAN(context.Context, *ConfigureRMANRequest) (*ConfigureRMANResponse, error)
+	// GetDBID returns the DBID of the database, required by RMAN to restore
+	// a controlfile autobackup without a recovery catalog.
+	GetDBID(context.Context, *GetDBIDRequest) (*GetDBIDResponse, error)
+	// NormalizeParameters rewrites spfile parameters scoped to a specific SID
+	// to sid='*', keeping the values in effect for the instance.
+	NormalizeParameters(context.Context, *NormalizeParametersRequest) (*NormalizeParametersResponse, error)
+	// ExportParameters returns the parameters which are not set to their
+	// default values.
+	ExportParameters(context.Context, *ExportParametersRequest) (*ExportParametersResponse, error)
+	// GetInstanceInfo returns the startup time, the uptime and the state of
+	// the database instance.
+	GetInstanceInfo(context.Context, *GetInstanceInfoRequest) (*GetInstanceInfoResponse, error)
+	// SelfTest runs read-only checks of the operations the daemon depends on
+	// and reports the result of each check.
+	SelfTest(context.Context, *SelfTestRequest) (*SelfTestResponse, error)
+	// PrepareForStorageMigration reopens the read write PDBs read only and
+	// checkpoints the database, so that its storage can be copied.
+	PrepareForStorageMigration(context.Context, *PrepareForStorageMigrationRequest) (*PrepareForStorageMigrationResponse, error)
+	// CompleteStorageMigration reopens the PDBs made read only by
+	// PrepareForStorageMigration read write, it also aborts a migration.
+	CompleteStorageMigration(context.Context, *CompleteStorageMigrationRequest) (*CompleteStorageMigrationResponse, error)
+	// ValidateOratab checks the format of the oratab file and its
+	// DATABASETYPE comment, which GetDatabaseType relies on.
+	ValidateOratab(context.Context, *ValidateOratabRequest) (*ValidateOratabResponse, error)
+	// CreateDataPumpDir creates the Data Pump directory of a PDB: the OS
+	// directory, the Oracle directory object pointing to it and the grants of
+	// the PDB loader user, it is idempotent.
+	CreateDataPumpDir(context.Context, *CreateDataPumpDirRequest) (*CreateDataPumpDirResponse, error)
+	// CheckStoragePermissions probes listing, reading, writing and deleting
+	// objects under a GCS prefix and reports which of them are permitted.
+	CheckStoragePermissions(context.Context, *CheckStoragePermissionsRequest) (*CheckStoragePermissionsResponse, error)
+	// ConfigureInMemory sizes the In-Memory column store of the instance and
+	// sets the INMEMORY attribute of tablespaces and tables of a PDB.
+	ConfigureInMemory(context.Context, *ConfigureInMemoryRequest) (*ConfigureInMemoryResponse, error)
+	// GetInMemoryStatus reports the In-Memory column store size and the
+	// population of the In-Memory segments of a PDB.
+	GetInMemoryStatus(context.Context, *GetInMemoryStatusRequest) (*GetInMemoryStatusResponse, error)
+	// MaintainPartitions adds, drops and splits range partitions of a table in
+	// a PDB, sets up its interval partitioning and drops the partitions falling
+	// out of its retention.
+	MaintainPartitions(context.Context, *MaintainPartitionsRequest) (*MaintainPartitionsResponse, error)
+	// RunSQLTuningAdvisor runs the SQL Tuning Advisor (DBMS_SQLTUNE) on a SQL
+	// statement of a PDB and reports its recommendations. It requires the
+	// Tuning Pack.
+	RunSQLTuningAdvisor(context.Context, *RunSQLTuningAdvisorRequest) (*RunSQLTuningAdvisorResponse, error)
+	// CreateAWRBaseline preserves the AWR snapshots of a period as a baseline
+	// to compare the performance before and after a maintenance. It requires
+	// the Diagnostics Pack.
+	CreateAWRBaseline(context.Context, *CreateAWRBaselineRequest) (*CreateAWRBaselineResponse, error)
+	// GetSysauxOccupants reports the SYSAUX usage and the space used by its
+	// occupants.
+	GetSysauxOccupants(context.Context, *GetSysauxOccupantsRequest) (*GetSysauxOccupantsResponse, error)
+	// PurgeSysaux reclaims SYSAUX space by purging the old data of its
+	// occupants once the SYSAUX usage crosses a threshold.
+	PurgeSysaux(context.Context, *PurgeSysauxRequest) (*PurgeSysauxResponse, error)
+	// ConfigureAWR sets the AWR snapshot retention and interval, only the
+	// settings which differ are modified.
+	ConfigureAWR(context.Context, *ConfigureAWRRequest) (*ConfigureAWRResponse, error)
+	// GetHostStats returns the CPU, memory, swap and disk I/O stats of the
+	// database container and the usage of its mounts.
+	GetHostStats(context.Context, *GetHostStatsRequest) (*GetHostStatsResponse, error)
+	// GrowMount verifies the filesystem of a mount grew after its disk was
+	// expanded and raises the autoextend limit of the datafiles on the mount
+	// which are close to it, so they can use the new space.
+	GrowMount(context.Context, *GrowMountRequest) (*GrowMountResponse, error)
+	// GetFeatureUsage reports the database feature usage statistics and flags
+	// the separately licensed options in use which the edition and the
+	// licensed options don't include.
+	GetFeatureUsage(context.Context, *GetFeatureUsageRequest) (*GetFeatureUsageResponse, error)
+	// SetLicense declares the edition and the licensed options of the
+	// database. The RPCs enabling a separately licensed option refuse to run
+	// unless the declared license covers it.
+	SetLicense(context.Context, *SetLicenseRequest) (*SetLicenseResponse, error)
+	// GetDefaultTablespaces returns the default and default temporary
+	// tablespaces of a PDB and the users whose objects land in SYSTEM.
+	GetDefaultTablespaces(context.Context, *GetDefaultTablespacesRequest) (*GetDefaultTablespacesResponse, error)
+	// SetDefaultTablespaces sets the default and default temporary
+	// tablespaces of a PDB, only the settings which differ are modified.
+	SetDefaultTablespaces(context.Context, *SetDefaultTablespacesRequest) (*SetDefaultTablespacesResponse, error)
+	// SetUserQuota sets the quotas of a PDB user on tablespaces.
+	SetUserQuota(context.Context, *SetUserQuotaRequest) (*SetUserQuotaResponse, error)
+	// GetBlockingSessions returns the chains of sessions waiting on a lock
+	// held by another session, rooted at the sessions which block the others.
+	GetBlockingSessions(context.Context, *GetBlockingSessionsRequest) (*GetBlockingSessionsResponse, error)
+	// GetLongRunningOps returns the progress of the operations of
+	// v$session_longops which are still running.
+	GetLongRunningOps(context.Context, *GetLongRunningOpsRequest) (*GetLongRunningOpsResponse, error)
+	// GetDeadlocks scans the alert log for ORA-00060 errors and summarizes
+	// the deadlock graph of the trace file each one points to.
+	GetDeadlocks(context.Context, *GetDeadlocksRequest) (*GetDeadlocksResponse, error)
+	// StartOnlineRedefinition changes the structure of a table, for example
+	// renames its columns, without blocking its users, with DBMS_REDEFINITION.
+	// The operation metadata is an OnlineRedefinitionProgress and its result
+	// a StartOnlineRedefinitionResponse.
+	StartOnlineRedefinition(context.Context, *StartOnlineRedefinitionRequest) (*longrunning.Operation, error)
+	// GetStaleStats returns the tables of a PDB whose optimizer statistics
+	// are stale or missing.
+	GetStaleStats(context.Context, *GetStaleStatsRequest) (*GetStaleStatsResponse, error)
+	// GatherStats gathers the optimizer statistics of tables of a PDB,
+	// optionally only of those GetStaleStats reports.
+	GatherStats(context.Context, *GatherStatsRequest) (*GatherStatsResponse, error)
+	// GetSQLMonitorReport generates the SQL Monitor report of an execution of
+	// a SQL id, returned or uploaded to GCS. It requires the Tuning Pack.
+	GetSQLMonitorReport(context.Context, *GetSQLMonitorReportRequest) (*GetSQLMonitorReportResponse, error)
+	// CaptureSQLMonitorReports uploads to GCS the SQL Monitor reports of the
+	// recent executions which ran longer than a threshold. It requires the
+	// Tuning Pack.
+	CaptureSQLMonitorReports(context.Context, *CaptureSQLMonitorReportsRequest) (*CaptureSQLMonitorReportsResponse, error)
+	// SetOwnership validates that files and directories are owned by the
+	// oracle user and group and chowns those which aren't.
+	SetOwnership(context.Context, *SetOwnershipRequest) (*SetOwnershipResponse, error)
+	// FixOwnership recursively restores the oracle ownership and the owner
+	// permissions of the data and config directories, e.g. after a restore.
+	FixOwnership(context.Context, *FixOwnershipRequest) (*FixOwnershipResponse, error)
+	// StreamOperationLog streams the RMAN or Data Pump log of an operation
+	// line by line while it runs, and ends once the operation is done.
+	StreamOperationLog(*StreamOperationLogRequest, DatabaseDaemon_StreamOperationLogServer) error
+	// GetNLSSettings reports the NLS parameters of the database and of the
+	// instance or a PDB.
+	GetNLSSettings(context.Context, *GetNLSSettingsRequest) (*GetNLSSettingsResponse, error)
+	// SetNLSSettings sets and resets NLS parameters of the instance or a PDB,
+	// the static ones take effect once the database restarts.
+	SetNLSSettings(context.Context, *SetNLSSettingsRequest) (*SetNLSSettingsResponse, error)
+	// ConfigurePDBResources sets and resets the resource parameters of a PDB,
+	// e.g. cpu_count, and reports those in effect.
+	ConfigurePDBResources(context.Context, *ConfigurePDBResourcesRequest) (*ConfigurePDBResourcesResponse, error)
+	// ConfigureRowLevelSecurity creates and drops the application contexts
+	// and the VPD (DBMS_RLS) policies of a PDB.
+	ConfigureRowLevelSecurity(context.Context, *ConfigureRowLevelSecurityRequest) (*ConfigureRowLevelSecurityResponse, error)
+	// FullInstanceExportAsync exports the PDBs of the instance as of the same
+	// SCN to a GCS prefix, one PDB after the other, and uploads a manifest
+	// describing the set of exports.
+	FullInstanceExportAsync(context.Context, *FullInstanceExportAsyncRequest) (*longrunning.Operation, error)
+	// FullInstanceImportAsync imports the PDBs of a full instance export
+	// described by its manifest, one PDB after the other, creating the PDBs
+	// which don't exist.
+	FullInstanceImportAsync(context.Context, *FullInstanceImportAsyncRequest) (*longrunning.Operation, error)
+	// GetStandbyApplyStatus returns the role of the database and, on a
+	// standby, the state of redo transport and apply.
+	GetStandbyApplyStatus(context.Context, *GetStandbyApplyStatusRequest) (*GetStandbyApplyStatusResponse, error)
+	// ResolveArchiveLogGap stages the archived logs missing on a standby from
+	// the replicated logs in GCS, registers them and restarts redo apply.
+	ResolveArchiveLogGap(context.Context, *ResolveArchiveLogGapRequest) (*ResolveArchiveLogGapResponse, error)
+	// ConfigureRedoTransport sets the compression of the redo shipped to the
+	// standby and requires its encryption in the standby listener sqlnet.ora.
+	ConfigureRedoTransport(context.Context, *ConfigureRedoTransportRequest) (*ConfigureRedoTransportResponse, error)
+	// GetInstalledOptions returns the components of dba_registry and checks
+	// that the required ones are installed and VALID, optionally recompiling
+	// the invalid ones.
+	GetInstalledOptions(context.Context, *GetInstalledOptionsRequest) (*GetInstalledOptionsResponse, error)
+	// RunSQLScriptFromGCS runs the statements of a SQL script downloaded from
+	// GCS, or given inline, in the CDB root or in a PDB.
+	RunSQLScriptFromGCS(context.Context, *RunSQLScriptFromGCSRequest) (*RunSQLScriptFromGCSResponse, error)
+	// SwitchOracleHomeAsync patches the database out of place: it restarts the
+	// database from a new Oracle home and runs datapatch, switching back to the
+	// current Oracle home if any step fails.
+	SwitchOracleHomeAsync(context.Context, *SwitchOracleHomeAsyncRequest) (*longrunning.Operation, error)
+	// CheckRestoreCompatibility checks that a backup taken on the source version
+	// can be restored into the Oracle home the database runs from.
+	CheckRestoreCompatibility(context.Context, *CheckRestoreCompatibilityRequest) (*CheckRestoreCompatibilityResponse, error)
+	// WriteBackupMetadata writes the metadata of a backup to a JSON object
+	// next to the backup in GCS.
+	WriteBackupMetadata(context.Context, *WriteBackupMetadataRequest) (*WriteBackupMetadataResponse, error)
+	// ReadBackupMetadata reads the metadata object of a backup in GCS.
+	ReadBackupMetadata(context.Context, *ReadBackupMetadataRequest) (*ReadBackupMetadataResponse, error)
+	// ListRestorePoints lists the restore points of the database from
+	// v$restore_point, with the FRA space used by guaranteed restore points.
+	ListRestorePoints(context.Context, *ListRestorePointsRequest) (*ListRestorePointsResponse, error)
+	// CreateRestorePoint creates a normal or a guaranteed restore point.
+	CreateRestorePoint(context.Context, *CreateRestorePointRequest) (*CreateRestorePointResponse, error)
+	// DropRestorePoint drops a restore point, releasing the flashback logs
+	// kept for a guaranteed restore point.
+	DropRestorePoint(context.Context, *DropRestorePointRequest) (*DropRestorePointResponse, error)
+	// ValidateSnapshotFiles checks that a snapshot of the given volumes is
+	// restorable: the controlfiles, datafiles and online redo logs are on
+	// the volumes and the datafile headers need no media recovery.
+	ValidateSnapshotFiles(context.Context, *ValidateSnapshotFilesRequest) (*ValidateSnapshotFilesResponse, error)
+	// GetRedoRate returns the redo generated by the database from its archived
+	// log history and the redo log and log disk sizes that history calls for.
+	GetRedoRate(context.Context, *GetRedoRateRequest) (*GetRedoRateResponse, error)
+	// RotateWalletPassword changes the password of the TDE keystore and
+	// regenerates its auto-login keystore, if it has one, to open with the
+	// new password.
+	RotateWalletPassword(context.Context, *RotateWalletPasswordRequest) (*RotateWalletPasswordResponse, error)
+	// ConfigureEditions creates and drops the editions of a PDB and sets its
+	// default edition, for edition-based redefinition.
+	ConfigureEditions(context.Context, *ConfigureEditionsRequest) (*ConfigureEditionsResponse, error)
+	// GetPGAUsage reports the PGA memory usage of the instance from v$pgastat
+	// and the aggregate target v$pga_target_advice recommends.
+	GetPGAUsage(context.Context, *GetPGAUsageRequest) (*GetPGAUsageResponse, error)
+	// SetSQLPlanCapture enables or disables the automatic capture of SQL plan
+	// baselines for the repeatable statements of all the containers.
+	SetSQLPlanCapture(context.Context, *SetSQLPlanCaptureRequest) (*SetSQLPlanCaptureResponse, error)
+	// GetSQLPlanBaselines reports the SQL plan baselines of all the
+	// containers.
+	GetSQLPlanBaselines(context.Context, *GetSQLPlanBaselinesRequest) (*GetSQLPlanBaselinesResponse, error)
+	// SetTablespaceEncryptionPolicy sets whether new tablespaces, including
+	// those created for imports, are encrypted by default.
+	SetTablespaceEncryptionPolicy(context.Context, *SetTablespaceEncryptionPolicyRequest) (*SetTablespaceEncryptionPolicyResponse, error)
+	// RestoreValidationReportAsync validates that the database can be
+	// restored from its backups, reports the files needing a backup and
+	// uploads the consolidated RMAN report to GCS.
+	RestoreValidationReportAsync(context.Context, *RestoreValidationReportAsyncRequest) (*longrunning.Operation, error)
+	// DiffParametersAgainstBaseline compares the parameters in effect with a
+	// baseline saved from ExportParameters.
+	DiffParametersAgainstBaseline(context.Context, *DiffParametersAgainstBaselineRequest) (*DiffParametersAgainstBaselineResponse, error)
+	// ConfigureFAN creates and starts the services of a PDB with Fast
+	// Application Notification (FAN) HA events on or off, and configures the
+	// Oracle Notification Service (ONS) daemon which delivers the events to
+	// the clients.
+	ConfigureFAN(context.Context, *ConfigureFANRequest) (*ConfigureFANResponse, error)
+	// CreateManagedTrigger creates or replaces an operator owned trigger of a
+	// PDB, namespaced with the ELCARRO_TRG_ prefix, e.g. the logon trigger of
+	// a maintenance mode.
+	CreateManagedTrigger(context.Context, *CreateManagedTriggerRequest) (*CreateManagedTriggerResponse, error)
+	// DropManagedTrigger drops an operator owned trigger of a PDB.
+	DropManagedTrigger(context.Context, *DropManagedTriggerRequest) (*DropManagedTriggerResponse, error)
+	// ListManagedTriggers lists the operator owned triggers of a PDB.
+	ListManagedTriggers(context.Context, *ListManagedTriggersRequest) (*ListManagedTriggersResponse, error)
+	// CheckStandbySynchronized checks that a standby can switch over without
+	// data loss: redo is applied without lag or gap, and standby redo logs are
+	// configured.
+	CheckStandbySynchronized(context.Context, *CheckStandbySynchronizedRequest) (*CheckStandbySynchronizedResponse, error)
+	// ExportRMANCatalog uploads a copy of the control file and a text export
+	// of the RMAN configuration and backup records to GCS, so that the
+	// backups can be found if the control file is lost.
+	ExportRMANCatalog(context.Context, *ExportRMANCatalogRequest) (*ExportRMANCatalogResponse, error)
+	// RunWriteCanary writes a row to a one row canary table and reads it back
+	// to check the database accepts writes, which a connection ping doesn't.
+	RunWriteCanary(context.Context, *RunWriteCanaryRequest) (*RunWriteCanaryResponse, error)
+	// ReconcilePDBs compares the PDBs of the CDB with the desired ones and
+	// optionally drops the PDBs which aren't desired.
+	ReconcilePDBs(context.Context, *ReconcilePDBsRequest) (*ReconcilePDBsResponse, error)
+	// CheckPITRTarget checks that the replicated redo logs recover a backup to
+	// the target of a PITR restore, before the restore replaces the database.
+	CheckPITRTarget(context.Context, *CheckPITRTargetRequest) (*CheckPITRTargetResponse, error)
+	// ConfigureKeystore creates the TDE keystore in wallet_root if the
+	// database has none, opens it in all the containers and optionally
+	// creates its auto-login keystore. wallet_root is set first if needed,
+	// the keystore can then only be created once the database restarts.
+	ConfigureKeystore(context.Context, *ConfigureKeystoreRequest) (*ConfigureKeystoreResponse, error)
+	// SetMasterKey sets a TDE master encryption key in the open containers
+	// which have none, the CDB root first.
+	SetMasterKey(context.Context, *SetMasterKeyRequest) (*SetMasterKeyResponse, error)
+	// EncryptTablespaces encrypts tablespaces of a container online, all its
+	// unencrypted user tablespaces unless the request lists some.
+	EncryptTablespaces(context.Context, *EncryptTablespacesRequest) (*EncryptTablespacesResponse, error)
+	mustEmbedUnimplementedDatabaseDaemonServer()
+}
+
+// UnimplementedDatabaseDaemonServer must be embedded to have forward compatible implementations.
+type UnimplementedDatabaseDaemonServer struct {
+}
+
+func (UnimplementedDatabaseDaemonServer) CreateDirs(context.Context, *CreateDirsRequest) (*CreateDirsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDirs not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadDir not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DeleteDir(context.Context, *DeleteDirRequest) (*DeleteDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDir not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) BounceDatabase(context.Context, *BounceDatabaseRequest) (*BounceDatabaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BounceDatabase not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) BounceListener(context.Context, *BounceListenerRequest) (*BounceListenerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BounceListener not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CheckDatabaseState(context.Context, *CheckDatabaseStateRequest) (*CheckDatabaseStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckDatabaseState not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunSQLPlus(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSQLPlus not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunSQLPlusFormatted(context.Context, *RunSQLPlusCMDRequest) (*RunCMDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSQLPlusFormatted not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) KnownPDBs(context.Context, *KnownPDBsRequest) (*KnownPDBsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KnownPDBs not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunRMAN(context.Context, *RunRMANRequest) (*RunRMANResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunRMAN not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunRMANAsync(context.Context, *RunRMANAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunRMANAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunDataGuard(context.Context, *RunDataGuardRequest) (*RunDataGuardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunDataGuard not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) TNSPing(context.Context, *TNSPingRequest) (*TNSPingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TNSPing not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) NID(context.Context, *NIDRequest) (*NIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NID not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetDatabaseType(context.Context, *GetDatabaseTypeRequest) (*GetDatabaseTypeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseType not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetDatabaseName(context.Context, *GetDatabaseNameRequest) (*GetDatabaseNameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDatabaseName not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreatePasswordFile(context.Context, *CreatePasswordFileRequest) (*CreatePasswordFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePasswordFile not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetListenerRegistration(context.Context, *SetListenerRegistrationRequest) (*BounceListenerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetListenerRegistration not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) BootstrapStandby(context.Context, *BootstrapStandbyRequest) (*BootstrapStandbyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BootstrapStandby not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateCDBAsync(context.Context, *CreateCDBAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCDBAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) BootstrapDatabaseAsync(context.Context, *BootstrapDatabaseAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BootstrapDatabaseAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateListener(context.Context, *CreateListenerRequest) (*CreateListenerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateListener not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) FileExists(context.Context, *FileExistsRequest) (*FileExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FileExists not implemented")
 }
 func (UnimplementedDatabaseDaemonServer) PhysicalRestoreAsync(context.Context, *PhysicalRestoreAsyncRequest) (*longrunning.Operation, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PhysicalRestoreAsync not implemented")
 }
-func (UnimplementedDatabaseDaemonServer) DataPumpImportAsync(context.Context, *DataPumpImportAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DataPumpImportAsync not implemented")
+func (UnimplementedDatabaseDaemonServer) DataPumpImportAsync(context.Context, *DataPumpImportAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DataPumpImportAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DataPumpExportAsync(context.Context, *DataPumpExportAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DataPumpExportAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ApplyDataPatchAsync(context.Context, *ApplyDataPatchAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyDataPatchAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ListOperations(context.Context, *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOperations not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetOperation(context.Context, *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DeleteOperation(context.Context, *longrunning.DeleteOperationRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOperation not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RecoverConfigFile(context.Context, *RecoverConfigFileRequest) (*RecoverConfigFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecoverConfigFile not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DownloadDirectoryFromGCS(context.Context, *DownloadDirectoryFromGCSRequest) (*DownloadDirectoryFromGCSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadDirectoryFromGCS not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) FetchServiceImageMetaData(context.Context, *FetchServiceImageMetaDataRequest) (*FetchServiceImageMetaDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchServiceImageMetaData not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateFile(context.Context, *CreateFileRequest) (*CreateFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFile not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) BootstrapDatabase(context.Context, *BootstrapDatabaseRequest) (*BootstrapDatabaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BootstrapDatabase not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetDnfsState(context.Context, *SetDnfsStateRequest) (*SetDnfsStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDnfsState not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) VerifyEncryption(context.Context, *VerifyEncryptionRequest) (*VerifyEncryptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyEncryption not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureNetworkEncryption(context.Context, *ConfigureNetworkEncryptionRequest) (*ConfigureNetworkEncryptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureNetworkEncryption not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureAllowedClients(context.Context, *ConfigureAllowedClientsRequest) (*ConfigureAllowedClientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureAllowedClients not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureTCPSListener(context.Context, *ConfigureTCPSListenerRequest) (*ConfigureTCPSListenerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureTCPSListener not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetFRAUsage(context.Context, *GetFRAUsageRequest) (*GetFRAUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFRAUsage not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ForceLogSwitch(context.Context, *ForceLogSwitchRequest) (*ForceLogSwitchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceLogSwitch not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureRMAN(context.Context, *ConfigureRMANRequest) (*ConfigureRMANResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureRMAN not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetDBID(context.Context, *GetDBIDRequest) (*GetDBIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDBID not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) NormalizeParameters(context.Context, *NormalizeParametersRequest) (*NormalizeParametersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NormalizeParameters not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ExportParameters(context.Context, *ExportParametersRequest) (*ExportParametersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportParameters not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetInstanceInfo(context.Context, *GetInstanceInfoRequest) (*GetInstanceInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstanceInfo not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SelfTest(context.Context, *SelfTestRequest) (*SelfTestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelfTest not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) PrepareForStorageMigration(context.Context, *PrepareForStorageMigrationRequest) (*PrepareForStorageMigrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareForStorageMigration not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CompleteStorageMigration(context.Context, *CompleteStorageMigrationRequest) (*CompleteStorageMigrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteStorageMigration not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ValidateOratab(context.Context, *ValidateOratabRequest) (*ValidateOratabResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateOratab not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateDataPumpDir(context.Context, *CreateDataPumpDirRequest) (*CreateDataPumpDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDataPumpDir not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CheckStoragePermissions(context.Context, *CheckStoragePermissionsRequest) (*CheckStoragePermissionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckStoragePermissions not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureInMemory(context.Context, *ConfigureInMemoryRequest) (*ConfigureInMemoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureInMemory not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetInMemoryStatus(context.Context, *GetInMemoryStatusRequest) (*GetInMemoryStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInMemoryStatus not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) MaintainPartitions(context.Context, *MaintainPartitionsRequest) (*MaintainPartitionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MaintainPartitions not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunSQLTuningAdvisor(context.Context, *RunSQLTuningAdvisorRequest) (*RunSQLTuningAdvisorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSQLTuningAdvisor not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateAWRBaseline(context.Context, *CreateAWRBaselineRequest) (*CreateAWRBaselineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAWRBaseline not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetSysauxOccupants(context.Context, *GetSysauxOccupantsRequest) (*GetSysauxOccupantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSysauxOccupants not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) PurgeSysaux(context.Context, *PurgeSysauxRequest) (*PurgeSysauxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSysaux not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureAWR(context.Context, *ConfigureAWRRequest) (*ConfigureAWRResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureAWR not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetHostStats(context.Context, *GetHostStatsRequest) (*GetHostStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHostStats not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GrowMount(context.Context, *GrowMountRequest) (*GrowMountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrowMount not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetFeatureUsage(context.Context, *GetFeatureUsageRequest) (*GetFeatureUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFeatureUsage not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetLicense(context.Context, *SetLicenseRequest) (*SetLicenseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLicense not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetDefaultTablespaces(context.Context, *GetDefaultTablespacesRequest) (*GetDefaultTablespacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDefaultTablespaces not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetDefaultTablespaces(context.Context, *SetDefaultTablespacesRequest) (*SetDefaultTablespacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDefaultTablespaces not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetUserQuota(context.Context, *SetUserQuotaRequest) (*SetUserQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserQuota not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetBlockingSessions(context.Context, *GetBlockingSessionsRequest) (*GetBlockingSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockingSessions not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetLongRunningOps(context.Context, *GetLongRunningOpsRequest) (*GetLongRunningOpsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLongRunningOps not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetDeadlocks(context.Context, *GetDeadlocksRequest) (*GetDeadlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeadlocks not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) StartOnlineRedefinition(context.Context, *StartOnlineRedefinitionRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartOnlineRedefinition not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetStaleStats(context.Context, *GetStaleStatsRequest) (*GetStaleStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStaleStats not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GatherStats(context.Context, *GatherStatsRequest) (*GatherStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GatherStats not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetSQLMonitorReport(context.Context, *GetSQLMonitorReportRequest) (*GetSQLMonitorReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSQLMonitorReport not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CaptureSQLMonitorReports(context.Context, *CaptureSQLMonitorReportsRequest) (*CaptureSQLMonitorReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CaptureSQLMonitorReports not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetOwnership(context.Context, *SetOwnershipRequest) (*SetOwnershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOwnership not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) FixOwnership(context.Context, *FixOwnershipRequest) (*FixOwnershipResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FixOwnership not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) StreamOperationLog(*StreamOperationLogRequest, DatabaseDaemon_StreamOperationLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamOperationLog not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetNLSSettings(context.Context, *GetNLSSettingsRequest) (*GetNLSSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNLSSettings not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetNLSSettings(context.Context, *SetNLSSettingsRequest) (*SetNLSSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNLSSettings not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigurePDBResources(context.Context, *ConfigurePDBResourcesRequest) (*ConfigurePDBResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigurePDBResources not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureRowLevelSecurity(context.Context, *ConfigureRowLevelSecurityRequest) (*ConfigureRowLevelSecurityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureRowLevelSecurity not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) FullInstanceExportAsync(context.Context, *FullInstanceExportAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FullInstanceExportAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) FullInstanceImportAsync(context.Context, *FullInstanceImportAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FullInstanceImportAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetStandbyApplyStatus(context.Context, *GetStandbyApplyStatusRequest) (*GetStandbyApplyStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStandbyApplyStatus not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ResolveArchiveLogGap(context.Context, *ResolveArchiveLogGapRequest) (*ResolveArchiveLogGapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveArchiveLogGap not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureRedoTransport(context.Context, *ConfigureRedoTransportRequest) (*ConfigureRedoTransportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureRedoTransport not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetInstalledOptions(context.Context, *GetInstalledOptionsRequest) (*GetInstalledOptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstalledOptions not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunSQLScriptFromGCS(context.Context, *RunSQLScriptFromGCSRequest) (*RunSQLScriptFromGCSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSQLScriptFromGCS not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SwitchOracleHomeAsync(context.Context, *SwitchOracleHomeAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SwitchOracleHomeAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CheckRestoreCompatibility(context.Context, *CheckRestoreCompatibilityRequest) (*CheckRestoreCompatibilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckRestoreCompatibility not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) WriteBackupMetadata(context.Context, *WriteBackupMetadataRequest) (*WriteBackupMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteBackupMetadata not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ReadBackupMetadata(context.Context, *ReadBackupMetadataRequest) (*ReadBackupMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadBackupMetadata not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ListRestorePoints(context.Context, *ListRestorePointsRequest) (*ListRestorePointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRestorePoints not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateRestorePoint(context.Context, *CreateRestorePointRequest) (*CreateRestorePointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRestorePoint not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DropRestorePoint(context.Context, *DropRestorePointRequest) (*DropRestorePointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropRestorePoint not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ValidateSnapshotFiles(context.Context, *ValidateSnapshotFilesRequest) (*ValidateSnapshotFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateSnapshotFiles not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetRedoRate(context.Context, *GetRedoRateRequest) (*GetRedoRateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRedoRate not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RotateWalletPassword(context.Context, *RotateWalletPasswordRequest) (*RotateWalletPasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateWalletPassword not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureEditions(context.Context, *ConfigureEditionsRequest) (*ConfigureEditionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureEditions not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetPGAUsage(context.Context, *GetPGAUsageRequest) (*GetPGAUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPGAUsage not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetSQLPlanCapture(context.Context, *SetSQLPlanCaptureRequest) (*SetSQLPlanCaptureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSQLPlanCapture not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) GetSQLPlanBaselines(context.Context, *GetSQLPlanBaselinesRequest) (*GetSQLPlanBaselinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSQLPlanBaselines not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetTablespaceEncryptionPolicy(context.Context, *SetTablespaceEncryptionPolicyRequest) (*SetTablespaceEncryptionPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTablespaceEncryptionPolicy not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RestoreValidationReportAsync(context.Context, *RestoreValidationReportAsyncRequest) (*longrunning.Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreValidationReportAsync not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DiffParametersAgainstBaseline(context.Context, *DiffParametersAgainstBaselineRequest) (*DiffParametersAgainstBaselineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffParametersAgainstBaseline not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureFAN(context.Context, *ConfigureFANRequest) (*ConfigureFANResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureFAN not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CreateManagedTrigger(context.Context, *CreateManagedTriggerRequest) (*CreateManagedTriggerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateManagedTrigger not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) DropManagedTrigger(context.Context, *DropManagedTriggerRequest) (*DropManagedTriggerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropManagedTrigger not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ListManagedTriggers(context.Context, *ListManagedTriggersRequest) (*ListManagedTriggersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListManagedTriggers not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CheckStandbySynchronized(context.Context, *CheckStandbySynchronizedRequest) (*CheckStandbySynchronizedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckStandbySynchronized not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ExportRMANCatalog(context.Context, *ExportRMANCatalogRequest) (*ExportRMANCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportRMANCatalog not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) RunWriteCanary(context.Context, *RunWriteCanaryRequest) (*RunWriteCanaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunWriteCanary not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ReconcilePDBs(context.Context, *ReconcilePDBsRequest) (*ReconcilePDBsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcilePDBs not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) CheckPITRTarget(context.Context, *CheckPITRTargetRequest) (*CheckPITRTargetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPITRTarget not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) ConfigureKeystore(context.Context, *ConfigureKeystoreRequest) (*ConfigureKeystoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigureKeystore not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) SetMasterKey(context.Context, *SetMasterKeyRequest) (*SetMasterKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMasterKey not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) EncryptTablespaces(context.Context, *EncryptTablespacesRequest) (*EncryptTablespacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EncryptTablespaces not implemented")
+}
+func (UnimplementedDatabaseDaemonServer) mustEmbedUnimplementedDatabaseDaemonServer() {}
+
+// UnsafeDatabaseDaemonServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DatabaseDaemonServer will
+// result in compilation errors.
+type UnsafeDatabaseDaemonServer interface {
+	mustEmbedUnimplementedDatabaseDaemonServer()
+}
+
+func RegisterDatabaseDaemonServer(s grpc.ServiceRegistrar, srv DatabaseDaemonServer) {
+	s.RegisterService(&DatabaseDaemon_ServiceDesc, srv)
+}
+
+func _DatabaseDaemon_CreateDirs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDirsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateDirs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateDirs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateDirs(ctx, req.(*CreateDirsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ReadDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ReadDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ReadDir",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ReadDir(ctx, req.(*ReadDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_DeleteDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).DeleteDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/DeleteDir",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).DeleteDir(ctx, req.(*DeleteDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_BounceDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BounceDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).BounceDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/BounceDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).BounceDatabase(ctx, req.(*BounceDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_BounceListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BounceListenerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).BounceListener(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/BounceListener",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).BounceListener(ctx, req.(*BounceListenerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CheckDatabaseState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckDatabaseStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CheckDatabaseState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CheckDatabaseState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CheckDatabaseState(ctx, req.(*CheckDatabaseStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunSQLPlus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSQLPlusCMDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunSQLPlus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLPlus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunSQLPlus(ctx, req.(*RunSQLPlusCMDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunSQLPlusFormatted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSQLPlusCMDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunSQLPlusFormatted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLPlusFormatted",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunSQLPlusFormatted(ctx, req.(*RunSQLPlusCMDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_KnownPDBs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KnownPDBsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).KnownPDBs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/KnownPDBs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).KnownPDBs(ctx, req.(*KnownPDBsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunRMAN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRMANRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunRMAN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunRMAN",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunRMAN(ctx, req.(*RunRMANRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunRMANAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunRMANAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunRMANAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunRMANAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunRMANAsync(ctx, req.(*RunRMANAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunDataGuard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunDataGuardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunDataGuard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunDataGuard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunDataGuard(ctx, req.(*RunDataGuardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_TNSPing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TNSPingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).TNSPing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/TNSPing",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).TNSPing(ctx, req.(*TNSPingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_NID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).NID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/NID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).NID(ctx, req.(*NIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetDatabaseType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDatabaseTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetDatabaseType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetDatabaseType",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetDatabaseType(ctx, req.(*GetDatabaseTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetDatabaseName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDatabaseNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetDatabaseName(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetDatabaseName",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetDatabaseName(ctx, req.(*GetDatabaseNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreatePasswordFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePasswordFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreatePasswordFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreatePasswordFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreatePasswordFile(ctx, req.(*CreatePasswordFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SetListenerRegistration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetListenerRegistrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetListenerRegistration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetListenerRegistration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetListenerRegistration(ctx, req.(*SetListenerRegistrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_BootstrapStandby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootstrapStandbyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).BootstrapStandby(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapStandby",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).BootstrapStandby(ctx, req.(*BootstrapStandbyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreateCDBAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCDBAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateCDBAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateCDBAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateCDBAsync(ctx, req.(*CreateCDBAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_BootstrapDatabaseAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootstrapDatabaseAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).BootstrapDatabaseAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapDatabaseAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).BootstrapDatabaseAsync(ctx, req.(*BootstrapDatabaseAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreateListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateListenerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateListener(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateListener",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateListener(ctx, req.(*CreateListenerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_FileExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FileExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).FileExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/FileExists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).FileExists(ctx, req.(*FileExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_PhysicalRestoreAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PhysicalRestoreAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).PhysicalRestoreAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/PhysicalRestoreAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).PhysicalRestoreAsync(ctx, req.(*PhysicalRestoreAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_DataPumpImportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DataPumpImportAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).DataPumpImportAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/DataPumpImportAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).DataPumpImportAsync(ctx, req.(*DataPumpImportAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_DataPumpExportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DataPumpExportAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).DataPumpExportAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/DataPumpExportAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).DataPumpExportAsync(ctx, req.(*DataPumpExportAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ApplyDataPatchAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyDataPatchAsyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ApplyDataPatchAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ApplyDataPatchAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ApplyDataPatchAsync(ctx, req.(*ApplyDataPatchAsyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ListOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(longrunning.ListOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ListOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ListOperations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ListOperations(ctx, req.(*longrunning.ListOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(longrunning.GetOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetOperation(ctx, req.(*longrunning.GetOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_DeleteOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(longrunning.DeleteOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).DeleteOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/DeleteOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).DeleteOperation(ctx, req.(*longrunning.DeleteOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RecoverConfigFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecoverConfigFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RecoverConfigFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RecoverConfigFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RecoverConfigFile(ctx, req.(*RecoverConfigFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_DownloadDirectoryFromGCS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadDirectoryFromGCSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).DownloadDirectoryFromGCS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/DownloadDirectoryFromGCS",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).DownloadDirectoryFromGCS(ctx, req.(*DownloadDirectoryFromGCSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_FetchServiceImageMetaData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchServiceImageMetaDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).FetchServiceImageMetaData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/FetchServiceImageMetaData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).FetchServiceImageMetaData(ctx, req.(*FetchServiceImageMetaDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreateFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateFile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateFile(ctx, req.(*CreateFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_BootstrapDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BootstrapDatabaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).BootstrapDatabase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapDatabase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).BootstrapDatabase(ctx, req.(*BootstrapDatabaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SetDnfsState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDnfsStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetDnfsState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetDnfsState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetDnfsState(ctx, req.(*SetDnfsStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_VerifyEncryption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyEncryptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).VerifyEncryption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/VerifyEncryption",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).VerifyEncryption(ctx, req.(*VerifyEncryptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureNetworkEncryption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureNetworkEncryptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureNetworkEncryption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureNetworkEncryption",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureNetworkEncryption(ctx, req.(*ConfigureNetworkEncryptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureAllowedClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureAllowedClientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureAllowedClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureAllowedClients",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureAllowedClients(ctx, req.(*ConfigureAllowedClientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureTCPSListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureTCPSListenerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureTCPSListener(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureTCPSListener",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureTCPSListener(ctx, req.(*ConfigureTCPSListenerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetFRAUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFRAUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetFRAUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetFRAUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetFRAUsage(ctx, req.(*GetFRAUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ForceLogSwitch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceLogSwitchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ForceLogSwitch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ForceLogSwitch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ForceLogSwitch(ctx, req.(*ForceLogSwitchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureRMAN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRMANRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureRMAN(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureRMAN",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureRMAN(ctx, req.(*ConfigureRMANRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetDBID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDBIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetDBID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetDBID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetDBID(ctx, req.(*GetDBIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_NormalizeParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NormalizeParametersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).NormalizeParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/NormalizeParameters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).NormalizeParameters(ctx, req.(*NormalizeParametersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ExportParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportParametersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ExportParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ExportParameters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ExportParameters(ctx, req.(*ExportParametersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetInstanceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInstanceInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetInstanceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetInstanceInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetInstanceInfo(ctx, req.(*GetInstanceInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SelfTest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SelfTestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SelfTest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SelfTest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SelfTest(ctx, req.(*SelfTestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_PrepareForStorageMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareForStorageMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).PrepareForStorageMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/PrepareForStorageMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).PrepareForStorageMigration(ctx, req.(*PrepareForStorageMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CompleteStorageMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteStorageMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CompleteStorageMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CompleteStorageMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CompleteStorageMigration(ctx, req.(*CompleteStorageMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ValidateOratab_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateOratabRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ValidateOratab(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ValidateOratab",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ValidateOratab(ctx, req.(*ValidateOratabRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreateDataPumpDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDataPumpDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateDataPumpDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateDataPumpDir",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateDataPumpDir(ctx, req.(*CreateDataPumpDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CheckStoragePermissions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckStoragePermissionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CheckStoragePermissions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CheckStoragePermissions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CheckStoragePermissions(ctx, req.(*CheckStoragePermissionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureInMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureInMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureInMemory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureInMemory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureInMemory(ctx, req.(*ConfigureInMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetInMemoryStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInMemoryStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetInMemoryStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetInMemoryStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetInMemoryStatus(ctx, req.(*GetInMemoryStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_MaintainPartitions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintainPartitionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).MaintainPartitions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/MaintainPartitions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).MaintainPartitions(ctx, req.(*MaintainPartitionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_RunSQLTuningAdvisor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSQLTuningAdvisorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).RunSQLTuningAdvisor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLTuningAdvisor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).RunSQLTuningAdvisor(ctx, req.(*RunSQLTuningAdvisorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_CreateAWRBaseline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAWRBaselineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CreateAWRBaseline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateAWRBaseline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CreateAWRBaseline(ctx, req.(*CreateAWRBaselineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetSysauxOccupants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSysauxOccupantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetSysauxOccupants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetSysauxOccupants",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetSysauxOccupants(ctx, req.(*GetSysauxOccupantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_PurgeSysaux_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSysauxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).PurgeSysaux(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/PurgeSysaux",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).PurgeSysaux(ctx, req.(*PurgeSysauxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_ConfigureAWR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureAWRRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureAWR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureAWR",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureAWR(ctx, req.(*ConfigureAWRRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetHostStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHostStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetHostStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetHostStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetHostStats(ctx, req.(*GetHostStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GrowMount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrowMountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GrowMount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GrowMount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GrowMount(ctx, req.(*GrowMountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetFeatureUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFeatureUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetFeatureUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetFeatureUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetFeatureUsage(ctx, req.(*GetFeatureUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SetLicense_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLicenseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetLicense(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetLicense",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetLicense(ctx, req.(*SetLicenseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetDefaultTablespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDefaultTablespacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetDefaultTablespaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetDefaultTablespaces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetDefaultTablespaces(ctx, req.(*GetDefaultTablespacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SetDefaultTablespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDefaultTablespacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetDefaultTablespaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetDefaultTablespaces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetDefaultTablespaces(ctx, req.(*SetDefaultTablespacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_SetUserQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetUserQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetUserQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetUserQuota(ctx, req.(*SetUserQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetBlockingSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockingSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetBlockingSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetBlockingSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetBlockingSessions(ctx, req.(*GetBlockingSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetLongRunningOps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLongRunningOpsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetLongRunningOps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetLongRunningOps",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetLongRunningOps(ctx, req.(*GetLongRunningOpsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetDeadlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeadlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetDeadlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetDeadlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetDeadlocks(ctx, req.(*GetDeadlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_StartOnlineRedefinition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartOnlineRedefinitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).StartOnlineRedefinition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/StartOnlineRedefinition",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).StartOnlineRedefinition(ctx, req.(*StartOnlineRedefinitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) DataPumpExportAsync(context.Context, *DataPumpExportAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DataPumpExportAsync not implemented")
+
+func _DatabaseDaemon_GetStaleStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStaleStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetStaleStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetStaleStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetStaleStats(ctx, req.(*GetStaleStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) ApplyDataPatchAsync(context.Context, *ApplyDataPatchAsyncRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ApplyDataPatchAsync not implemented")
+
+func _DatabaseDaemon_GatherStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GatherStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GatherStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GatherStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GatherStats(ctx, req.(*GatherStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) ListOperations(context.Context, *longrunning.ListOperationsRequest) (*longrunning.ListOperationsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListOperations not implemented")
+
+func _DatabaseDaemon_GetSQLMonitorReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSQLMonitorReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetSQLMonitorReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetSQLMonitorReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetSQLMonitorReport(ctx, req.(*GetSQLMonitorReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) GetOperation(context.Context, *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
+
+func _DatabaseDaemon_CaptureSQLMonitorReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CaptureSQLMonitorReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).CaptureSQLMonitorReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/CaptureSQLMonitorReports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).CaptureSQLMonitorReports(ctx, req.(*CaptureSQLMonitorReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) DeleteOperation(context.Context, *longrunning.DeleteOperationRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteOperation not implemented")
+
+func _DatabaseDaemon_SetOwnership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOwnershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetOwnership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetOwnership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetOwnership(ctx, req.(*SetOwnershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) RecoverConfigFile(context.Context, *RecoverConfigFileRequest) (*RecoverConfigFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RecoverConfigFile not implemented")
+
+func _DatabaseDaemon_FixOwnership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FixOwnershipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).FixOwnership(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/FixOwnership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).FixOwnership(ctx, req.(*FixOwnershipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) DownloadDirectoryFromGCS(context.Context, *DownloadDirectoryFromGCSRequest) (*DownloadDirectoryFromGCSResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DownloadDirectoryFromGCS not implemented")
+
+func _DatabaseDaemon_StreamOperationLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamOperationLogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DatabaseDaemonServer).StreamOperationLog(m, &databaseDaemonStreamOperationLogServer{stream})
 }
-func (UnimplementedDatabaseDaemonServer) FetchServiceImageMetaData(context.Context, *FetchServiceImageMetaDataRequest) (*FetchServiceImageMetaDataResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method FetchServiceImageMetaData not implemented")
+
+type DatabaseDaemon_StreamOperationLogServer interface {
+	Send(*StreamOperationLogResponse) error
+	grpc.ServerStream
 }
-func (UnimplementedDatabaseDaemonServer) CreateFile(context.Context, *CreateFileRequest) (*CreateFileResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateFile not implemented")
+
+type databaseDaemonStreamOperationLogServer struct {
+	grpc.ServerStream
 }
-func (UnimplementedDatabaseDaemonServer) BootstrapDatabase(context.Context, *BootstrapDatabaseRequest) (*BootstrapDatabaseResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method BootstrapDatabase not implemented")
+
+func (x *databaseDaemonStreamOperationLogServer) Send(m *StreamOperationLogResponse) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (UnimplementedDatabaseDaemonServer) SetDnfsState(context.Context, *SetDnfsStateRequest) (*SetDnfsStateResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SetDnfsState not implemented")
+
+func _DatabaseDaemon_GetNLSSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNLSSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).GetNLSSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetNLSSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).GetNLSSettings(ctx, req.(*GetNLSSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedDatabaseDaemonServer) mustEmbedUnimplementedDatabaseDaemonServer() {}
 
-// UnsafeDatabaseDaemonServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to DatabaseDaemonServer will
-// result in compilation errors.
-type UnsafeDatabaseDaemonServer interface {
-	mustEmbedUnimplementedDatabaseDaemonServer()
+func _DatabaseDaemon_SetNLSSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetNLSSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).SetNLSSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetNLSSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).SetNLSSettings(ctx, req.(*SetNLSSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterDatabaseDaemonServer(s grpc.ServiceRegistrar, srv DatabaseDaemonServer) {
-	s.RegisterService(&DatabaseDaemon_ServiceDesc, srv)
+func _DatabaseDaemon_ConfigurePDBResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigurePDBResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigurePDBResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigurePDBResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigurePDBResources(ctx, req.(*ConfigurePDBResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CreateDirs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateDirsRequest)
+func _DatabaseDaemon_ConfigureRowLevelSecurity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRowLevelSecurityRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CreateDirs(ctx, in)
+		return srv.(DatabaseDaemonServer).ConfigureRowLevelSecurity(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CreateDirs",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureRowLevelSecurity",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CreateDirs(ctx, req.(*CreateDirsRequest))
+		return srv.(DatabaseDaemonServer).ConfigureRowLevelSecurity(ctx, req.(*ConfigureRowLevelSecurityRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_ReadDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ReadDirRequest)
+func _DatabaseDaemon_FullInstanceExportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FullInstanceExportAsyncRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).ReadDir(ctx, in)
+		return srv.(DatabaseDaemonServer).FullInstanceExportAsync(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/ReadDir",
+		FullMethod: "/agents.oracle.DatabaseDaemon/FullInstanceExportAsync",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).ReadDir(ctx, req.(*ReadDirRequest))
+		return srv.(DatabaseDaemonServer).FullInstanceExportAsync(ctx, req.(*FullInstanceExportAsyncRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_DeleteDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteDirRequest)
+func _DatabaseDaemon_FullInstanceImportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FullInstanceImportAsyncRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).DeleteDir(ctx, in)
+		return srv.(DatabaseDaemonServer).FullInstanceImportAsync(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/DeleteDir",
+		FullMethod: "/agents.oracle.DatabaseDaemon/FullInstanceImportAsync",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).DeleteDir(ctx, req.(*DeleteDirRequest))
+		return srv.(DatabaseDaemonServer).FullInstanceImportAsync(ctx, req.(*FullInstanceImportAsyncRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_BounceDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BounceDatabaseRequest)
+func _DatabaseDaemon_GetStandbyApplyStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStandbyApplyStatusRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).BounceDatabase(ctx, in)
+		return srv.(DatabaseDaemonServer).GetStandbyApplyStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/BounceDatabase",
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetStandbyApplyStatus",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).BounceDatabase(ctx, req.(*BounceDatabaseRequest))
+		return srv.(DatabaseDaemonServer).GetStandbyApplyStatus(ctx, req.(*GetStandbyApplyStatusRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_BounceListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BounceListenerRequest)
+func _DatabaseDaemon_ResolveArchiveLogGap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveArchiveLogGapRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).BounceListener(ctx, in)
+		return srv.(DatabaseDaemonServer).ResolveArchiveLogGap(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/BounceListener",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ResolveArchiveLogGap",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).BounceListener(ctx, req.(*BounceListenerRequest))
+		return srv.(DatabaseDaemonServer).ResolveArchiveLogGap(ctx, req.(*ResolveArchiveLogGapRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CheckDatabaseState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CheckDatabaseStateRequest)
+func _DatabaseDaemon_ConfigureRedoTransport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRedoTransportRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CheckDatabaseState(ctx, in)
+		return srv.(DatabaseDaemonServer).ConfigureRedoTransport(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CheckDatabaseState",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureRedoTransport",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CheckDatabaseState(ctx, req.(*CheckDatabaseStateRequest))
+		return srv.(DatabaseDaemonServer).ConfigureRedoTransport(ctx, req.(*ConfigureRedoTransportRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RunSQLPlus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RunSQLPlusCMDRequest)
+func _DatabaseDaemon_GetInstalledOptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInstalledOptionsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RunSQLPlus(ctx, in)
+		return srv.(DatabaseDaemonServer).GetInstalledOptions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLPlus",
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetInstalledOptions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RunSQLPlus(ctx, req.(*RunSQLPlusCMDRequest))
+		return srv.(DatabaseDaemonServer).GetInstalledOptions(ctx, req.(*GetInstalledOptionsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RunSQLPlusFormatted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RunSQLPlusCMDRequest)
+func _DatabaseDaemon_RunSQLScriptFromGCS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSQLScriptFromGCSRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RunSQLPlusFormatted(ctx, in)
+		return srv.(DatabaseDaemonServer).RunSQLScriptFromGCS(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLPlusFormatted",
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunSQLScriptFromGCS",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RunSQLPlusFormatted(ctx, req.(*RunSQLPlusCMDRequest))
+		return srv.(DatabaseDaemonServer).RunSQLScriptFromGCS(ctx, req.(*RunSQLScriptFromGCSRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_KnownPDBs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(KnownPDBsRequest)
+func _DatabaseDaemon_SwitchOracleHomeAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwitchOracleHomeAsyncRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).KnownPDBs(ctx, in)
+		return srv.(DatabaseDaemonServer).SwitchOracleHomeAsync(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/KnownPDBs",
+		FullMethod: "/agents.oracle.DatabaseDaemon/SwitchOracleHomeAsync",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).KnownPDBs(ctx, req.(*KnownPDBsRequest))
+		return srv.(DatabaseDaemonServer).SwitchOracleHomeAsync(ctx, req.(*SwitchOracleHomeAsyncRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RunRMAN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RunRMANRequest)
+func _DatabaseDaemon_CheckRestoreCompatibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRestoreCompatibilityRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RunRMAN(ctx, in)
+		return srv.(DatabaseDaemonServer).CheckRestoreCompatibility(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RunRMAN",
+		FullMethod: "/agents.oracle.DatabaseDaemon/CheckRestoreCompatibility",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RunRMAN(ctx, req.(*RunRMANRequest))
+		return srv.(DatabaseDaemonServer).CheckRestoreCompatibility(ctx, req.(*CheckRestoreCompatibilityRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RunRMANAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RunRMANAsyncRequest)
+func _DatabaseDaemon_WriteBackupMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteBackupMetadataRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RunRMANAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).WriteBackupMetadata(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RunRMANAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/WriteBackupMetadata",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RunRMANAsync(ctx, req.(*RunRMANAsyncRequest))
+		return srv.(DatabaseDaemonServer).WriteBackupMetadata(ctx, req.(*WriteBackupMetadataRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RunDataGuard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RunDataGuardRequest)
+func _DatabaseDaemon_ReadBackupMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadBackupMetadataRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RunDataGuard(ctx, in)
+		return srv.(DatabaseDaemonServer).ReadBackupMetadata(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RunDataGuard",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ReadBackupMetadata",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RunDataGuard(ctx, req.(*RunDataGuardRequest))
+		return srv.(DatabaseDaemonServer).ReadBackupMetadata(ctx, req.(*ReadBackupMetadataRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_TNSPing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TNSPingRequest)
+func _DatabaseDaemon_ListRestorePoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRestorePointsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).TNSPing(ctx, in)
+		return srv.(DatabaseDaemonServer).ListRestorePoints(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/TNSPing",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ListRestorePoints",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).TNSPing(ctx, req.(*TNSPingRequest))
+		return srv.(DatabaseDaemonServer).ListRestorePoints(ctx, req.(*ListRestorePointsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_NID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NIDRequest)
+func _DatabaseDaemon_CreateRestorePoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRestorePointRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).NID(ctx, in)
+		return srv.(DatabaseDaemonServer).CreateRestorePoint(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/NID",
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateRestorePoint",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).NID(ctx, req.(*NIDRequest))
+		return srv.(DatabaseDaemonServer).CreateRestorePoint(ctx, req.(*CreateRestorePointRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_GetDatabaseType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetDatabaseTypeRequest)
+func _DatabaseDaemon_DropRestorePoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropRestorePointRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).GetDatabaseType(ctx, in)
+		return srv.(DatabaseDaemonServer).DropRestorePoint(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/GetDatabaseType",
+		FullMethod: "/agents.oracle.DatabaseDaemon/DropRestorePoint",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).GetDatabaseType(ctx, req.(*GetDatabaseTypeRequest))
+		return srv.(DatabaseDaemonServer).DropRestorePoint(ctx, req.(*DropRestorePointRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_GetDatabaseName_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetDatabaseNameRequest)
+func _DatabaseDaemon_ValidateSnapshotFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateSnapshotFilesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).GetDatabaseName(ctx, in)
+		return srv.(DatabaseDaemonServer).ValidateSnapshotFiles(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/GetDatabaseName",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ValidateSnapshotFiles",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).GetDatabaseName(ctx, req.(*GetDatabaseNameRequest))
+		return srv.(DatabaseDaemonServer).ValidateSnapshotFiles(ctx, req.(*ValidateSnapshotFilesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CreatePasswordFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreatePasswordFileRequest)
+func _DatabaseDaemon_GetRedoRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRedoRateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CreatePasswordFile(ctx, in)
+		return srv.(DatabaseDaemonServer).GetRedoRate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CreatePasswordFile",
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetRedoRate",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CreatePasswordFile(ctx, req.(*CreatePasswordFileRequest))
+		return srv.(DatabaseDaemonServer).GetRedoRate(ctx, req.(*GetRedoRateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_SetListenerRegistration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetListenerRegistrationRequest)
+func _DatabaseDaemon_RotateWalletPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateWalletPasswordRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).SetListenerRegistration(ctx, in)
+		return srv.(DatabaseDaemonServer).RotateWalletPassword(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/SetListenerRegistration",
+		FullMethod: "/agents.oracle.DatabaseDaemon/RotateWalletPassword",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).SetListenerRegistration(ctx, req.(*SetListenerRegistrationRequest))
+		return srv.(DatabaseDaemonServer).RotateWalletPassword(ctx, req.(*RotateWalletPasswordRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_BootstrapStandby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BootstrapStandbyRequest)
+func _DatabaseDaemon_ConfigureEditions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureEditionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseDaemonServer).ConfigureEditions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureEditions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseDaemonServer).ConfigureEditions(ctx, req.(*ConfigureEditionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseDaemon_GetPGAUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPGAUsageRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).BootstrapStandby(ctx, in)
+		return srv.(DatabaseDaemonServer).GetPGAUsage(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapStandby",
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetPGAUsage",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).BootstrapStandby(ctx, req.(*BootstrapStandbyRequest))
+		return srv.(DatabaseDaemonServer).GetPGAUsage(ctx, req.(*GetPGAUsageRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CreateCDBAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateCDBAsyncRequest)
+func _DatabaseDaemon_SetSQLPlanCapture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSQLPlanCaptureRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CreateCDBAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).SetSQLPlanCapture(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CreateCDBAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetSQLPlanCapture",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CreateCDBAsync(ctx, req.(*CreateCDBAsyncRequest))
+		return srv.(DatabaseDaemonServer).SetSQLPlanCapture(ctx, req.(*SetSQLPlanCaptureRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_BootstrapDatabaseAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BootstrapDatabaseAsyncRequest)
+func _DatabaseDaemon_GetSQLPlanBaselines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSQLPlanBaselinesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).BootstrapDatabaseAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).GetSQLPlanBaselines(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapDatabaseAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/GetSQLPlanBaselines",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).BootstrapDatabaseAsync(ctx, req.(*BootstrapDatabaseAsyncRequest))
+		return srv.(DatabaseDaemonServer).GetSQLPlanBaselines(ctx, req.(*GetSQLPlanBaselinesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CreateListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateListenerRequest)
+func _DatabaseDaemon_SetTablespaceEncryptionPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTablespaceEncryptionPolicyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CreateListener(ctx, in)
+		return srv.(DatabaseDaemonServer).SetTablespaceEncryptionPolicy(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CreateListener",
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetTablespaceEncryptionPolicy",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CreateListener(ctx, req.(*CreateListenerRequest))
+		return srv.(DatabaseDaemonServer).SetTablespaceEncryptionPolicy(ctx, req.(*SetTablespaceEncryptionPolicyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_FileExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FileExistsRequest)
+func _DatabaseDaemon_RestoreValidationReportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreValidationReportAsyncRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).FileExists(ctx, in)
+		return srv.(DatabaseDaemonServer).RestoreValidationReportAsync(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/FileExists",
+		FullMethod: "/agents.oracle.DatabaseDaemon/RestoreValidationReportAsync",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).FileExists(ctx, req.(*FileExistsRequest))
+		return srv.(DatabaseDaemonServer).RestoreValidationReportAsync(ctx, req.(*RestoreValidationReportAsyncRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_PhysicalRestoreAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PhysicalRestoreAsyncRequest)
+func _DatabaseDaemon_DiffParametersAgainstBaseline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffParametersAgainstBaselineRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).PhysicalRestoreAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).DiffParametersAgainstBaseline(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/PhysicalRestoreAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/DiffParametersAgainstBaseline",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).PhysicalRestoreAsync(ctx, req.(*PhysicalRestoreAsyncRequest))
+		return srv.(DatabaseDaemonServer).DiffParametersAgainstBaseline(ctx, req.(*DiffParametersAgainstBaselineRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_DataPumpImportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DataPumpImportAsyncRequest)
+func _DatabaseDaemon_ConfigureFAN_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureFANRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).DataPumpImportAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).ConfigureFAN(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/DataPumpImportAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureFAN",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).DataPumpImportAsync(ctx, req.(*DataPumpImportAsyncRequest))
+		return srv.(DatabaseDaemonServer).ConfigureFAN(ctx, req.(*ConfigureFANRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_DataPumpExportAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DataPumpExportAsyncRequest)
+func _DatabaseDaemon_CreateManagedTrigger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateManagedTriggerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).DataPumpExportAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).CreateManagedTrigger(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/DataPumpExportAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/CreateManagedTrigger",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).DataPumpExportAsync(ctx, req.(*DataPumpExportAsyncRequest))
+		return srv.(DatabaseDaemonServer).CreateManagedTrigger(ctx, req.(*CreateManagedTriggerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_ApplyDataPatchAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ApplyDataPatchAsyncRequest)
+func _DatabaseDaemon_DropManagedTrigger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropManagedTriggerRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).ApplyDataPatchAsync(ctx, in)
+		return srv.(DatabaseDaemonServer).DropManagedTrigger(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/ApplyDataPatchAsync",
+		FullMethod: "/agents.oracle.DatabaseDaemon/DropManagedTrigger",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).ApplyDataPatchAsync(ctx, req.(*ApplyDataPatchAsyncRequest))
+		return srv.(DatabaseDaemonServer).DropManagedTrigger(ctx, req.(*DropManagedTriggerRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_ListOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(longrunning.ListOperationsRequest)
+func _DatabaseDaemon_ListManagedTriggers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListManagedTriggersRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).ListOperations(ctx, in)
+		return srv.(DatabaseDaemonServer).ListManagedTriggers(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/ListOperations",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ListManagedTriggers",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).ListOperations(ctx, req.(*longrunning.ListOperationsRequest))
+		return srv.(DatabaseDaemonServer).ListManagedTriggers(ctx, req.(*ListManagedTriggersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(longrunning.GetOperationRequest)
+func _DatabaseDaemon_CheckStandbySynchronized_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckStandbySynchronizedRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).GetOperation(ctx, in)
+		return srv.(DatabaseDaemonServer).CheckStandbySynchronized(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/GetOperation",
+		FullMethod: "/agents.oracle.DatabaseDaemon/CheckStandbySynchronized",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).GetOperation(ctx, req.(*longrunning.GetOperationRequest))
+		return srv.(DatabaseDaemonServer).CheckStandbySynchronized(ctx, req.(*CheckStandbySynchronizedRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_DeleteOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(longrunning.DeleteOperationRequest)
+func _DatabaseDaemon_ExportRMANCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportRMANCatalogRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).DeleteOperation(ctx, in)
+		return srv.(DatabaseDaemonServer).ExportRMANCatalog(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/DeleteOperation",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ExportRMANCatalog",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).DeleteOperation(ctx, req.(*longrunning.DeleteOperationRequest))
+		return srv.(DatabaseDaemonServer).ExportRMANCatalog(ctx, req.(*ExportRMANCatalogRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_RecoverConfigFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RecoverConfigFileRequest)
+func _DatabaseDaemon_RunWriteCanary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunWriteCanaryRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).RecoverConfigFile(ctx, in)
+		return srv.(DatabaseDaemonServer).RunWriteCanary(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/RecoverConfigFile",
+		FullMethod: "/agents.oracle.DatabaseDaemon/RunWriteCanary",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).RecoverConfigFile(ctx, req.(*RecoverConfigFileRequest))
+		return srv.(DatabaseDaemonServer).RunWriteCanary(ctx, req.(*RunWriteCanaryRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_DownloadDirectoryFromGCS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DownloadDirectoryFromGCSRequest)
+func _DatabaseDaemon_ReconcilePDBs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcilePDBsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).DownloadDirectoryFromGCS(ctx, in)
+		return srv.(DatabaseDaemonServer).ReconcilePDBs(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/DownloadDirectoryFromGCS",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ReconcilePDBs",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).DownloadDirectoryFromGCS(ctx, req.(*DownloadDirectoryFromGCSRequest))
+		return srv.(DatabaseDaemonServer).ReconcilePDBs(ctx, req.(*ReconcilePDBsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_FetchServiceImageMetaData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(FetchServiceImageMetaDataRequest)
+func _DatabaseDaemon_CheckPITRTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPITRTargetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).FetchServiceImageMetaData(ctx, in)
+		return srv.(DatabaseDaemonServer).CheckPITRTarget(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/FetchServiceImageMetaData",
+		FullMethod: "/agents.oracle.DatabaseDaemon/CheckPITRTarget",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).FetchServiceImageMetaData(ctx, req.(*FetchServiceImageMetaDataRequest))
+		return srv.(DatabaseDaemonServer).CheckPITRTarget(ctx, req.(*CheckPITRTargetRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_CreateFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateFileRequest)
+func _DatabaseDaemon_ConfigureKeystore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureKeystoreRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).CreateFile(ctx, in)
+		return srv.(DatabaseDaemonServer).ConfigureKeystore(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/CreateFile",
+		FullMethod: "/agents.oracle.DatabaseDaemon/ConfigureKeystore",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).CreateFile(ctx, req.(*CreateFileRequest))
+		return srv.(DatabaseDaemonServer).ConfigureKeystore(ctx, req.(*ConfigureKeystoreRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_BootstrapDatabase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BootstrapDatabaseRequest)
+func _DatabaseDaemon_SetMasterKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMasterKeyRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).BootstrapDatabase(ctx, in)
+		return srv.(DatabaseDaemonServer).SetMasterKey(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/BootstrapDatabase",
+		FullMethod: "/agents.oracle.DatabaseDaemon/SetMasterKey",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).BootstrapDatabase(ctx, req.(*BootstrapDatabaseRequest))
+		return srv.(DatabaseDaemonServer).SetMasterKey(ctx, req.(*SetMasterKeyRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DatabaseDaemon_SetDnfsState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetDnfsStateRequest)
+func _DatabaseDaemon_EncryptTablespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EncryptTablespacesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DatabaseDaemonServer).SetDnfsState(ctx, in)
+		return srv.(DatabaseDaemonServer).EncryptTablespaces(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/agents.oracle.DatabaseDaemon/SetDnfsState",
+		FullMethod: "/agents.oracle.DatabaseDaemon/EncryptTablespaces",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DatabaseDaemonServer).SetDnfsState(ctx, req.(*SetDnfsStateRequest))
+		return srv.(DatabaseDaemonServer).EncryptTablespaces(ctx, req.(*EncryptTablespacesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1457,7 +4509,341 @@ var DatabaseDaemon_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetDnfsState",
 			Handler:    _DatabaseDaemon_SetDnfsState_Handler,
 		},
+		{
+			MethodName: "VerifyEncryption",
+			Handler:    _DatabaseDaemon_VerifyEncryption_Handler,
+		},
+		{
+			MethodName: "ConfigureNetworkEncryption",
+			Handler:    _DatabaseDaemon_ConfigureNetworkEncryption_Handler,
+		},
+		{
+			MethodName: "ConfigureAllowedClients",
+			Handler:    _DatabaseDaemon_ConfigureAllowedClients_Handler,
+		},
+		{
+			MethodName: "ConfigureTCPSListener",
+			Handler:    _DatabaseDaemon_ConfigureTCPSListener_Handler,
+		},
+		{
+			MethodName: "GetFRAUsage",
+			Handler:    _DatabaseDaemon_GetFRAUsage_Handler,
+		},
+		{
+			MethodName: "ForceLogSwitch",
+			Handler:    _DatabaseDaemon_ForceLogSwitch_Handler,
+		},
+		{
+			MethodName: "ConfigureRMAN",
+			Handler:    _DatabaseDaemon_ConfigureRMAN_Handler,
+		},
+		{
+			MethodName: "GetDBID",
+			Handler:    _DatabaseDaemon_GetDBID_Handler,
+		},
+		{
+			MethodName: "NormalizeParameters",
+			Handler:    _DatabaseDaemon_NormalizeParameters_Handler,
+		},
+		{
+			MethodName: "ExportParameters",
+			Handler:    _DatabaseDaemon_ExportParameters_Handler,
+		},
+		{
+			MethodName: "GetInstanceInfo",
+			Handler:    _DatabaseDaemon_GetInstanceInfo_Handler,
+		},
+		{
+			MethodName: "SelfTest",
+			Handler:    _DatabaseDaemon_SelfTest_Handler,
+		},
+		{
+			MethodName: "PrepareForStorageMigration",
+			Handler:    _DatabaseDaemon_PrepareForStorageMigration_Handler,
+		},
+		{
+			MethodName: "CompleteStorageMigration",
+			Handler:    _DatabaseDaemon_CompleteStorageMigration_Handler,
+		},
+		{
+			MethodName: "ValidateOratab",
+			Handler:    _DatabaseDaemon_ValidateOratab_Handler,
+		},
+		{
+			MethodName: "CreateDataPumpDir",
+			Handler:    _DatabaseDaemon_CreateDataPumpDir_Handler,
+		},
+		{
+			MethodName: "CheckStoragePermissions",
+			Handler:    _DatabaseDaemon_CheckStoragePermissions_Handler,
+		},
+		{
+			MethodName: "ConfigureInMemory",
+			Handler:    _DatabaseDaemon_ConfigureInMemory_Handler,
+		},
+		{
+			MethodName: "GetInMemoryStatus",
+			Handler:    _DatabaseDaemon_GetInMemoryStatus_Handler,
+		},
+		{
+			MethodName: "MaintainPartitions",
+			Handler:    _DatabaseDaemon_MaintainPartitions_Handler,
+		},
+		{
+			MethodName: "RunSQLTuningAdvisor",
+			Handler:    _DatabaseDaemon_RunSQLTuningAdvisor_Handler,
+		},
+		{
+			MethodName: "CreateAWRBaseline",
+			Handler:    _DatabaseDaemon_CreateAWRBaseline_Handler,
+		},
+		{
+			MethodName: "GetSysauxOccupants",
+			Handler:    _DatabaseDaemon_GetSysauxOccupants_Handler,
+		},
+		{
+			MethodName: "PurgeSysaux",
+			Handler:    _DatabaseDaemon_PurgeSysaux_Handler,
+		},
+		{
+			MethodName: "ConfigureAWR",
+			Handler:    _DatabaseDaemon_ConfigureAWR_Handler,
+		},
+		{
+			MethodName: "GetHostStats",
+			Handler:    _DatabaseDaemon_GetHostStats_Handler,
+		},
+		{
+			MethodName: "GrowMount",
+			Handler:    _DatabaseDaemon_GrowMount_Handler,
+		},
+		{
+			MethodName: "GetFeatureUsage",
+			Handler:    _DatabaseDaemon_GetFeatureUsage_Handler,
+		},
+		{
+			MethodName: "SetLicense",
+			Handler:    _DatabaseDaemon_SetLicense_Handler,
+		},
+		{
+			MethodName: "GetDefaultTablespaces",
+			Handler:    _DatabaseDaemon_GetDefaultTablespaces_Handler,
+		},
+		{
+			MethodName: "SetDefaultTablespaces",
+			Handler:    _DatabaseDaemon_SetDefaultTablespaces_Handler,
+		},
+		{
+			MethodName: "SetUserQuota",
+			Handler:    _DatabaseDaemon_SetUserQuota_Handler,
+		},
+		{
+			MethodName: "GetBlockingSessions",
+			Handler:    _DatabaseDaemon_GetBlockingSessions_Handler,
+		},
+		{
+			MethodName: "GetLongRunningOps",
+			Handler:    _DatabaseDaemon_GetLongRunningOps_Handler,
+		},
+		{
+			MethodName: "GetDeadlocks",
+			Handler:    _DatabaseDaemon_GetDeadlocks_Handler,
+		},
+		{
+			MethodName: "StartOnlineRedefinition",
+			Handler:    _DatabaseDaemon_StartOnlineRedefinition_Handler,
+		},
+		{
+			MethodName: "GetStaleStats",
+			Handler:    _DatabaseDaemon_GetStaleStats_Handler,
+		},
+		{
+			MethodName: "GatherStats",
+			Handler:    _DatabaseDaemon_GatherStats_Handler,
+		},
+		{
+			MethodName: "GetSQLMonitorReport",
+			Handler:    _DatabaseDaemon_GetSQLMonitorReport_Handler,
+		},
+		{
+			MethodName: "CaptureSQLMonitorReports",
+			Handler:    _DatabaseDaemon_CaptureSQLMonitorReports_Handler,
+		},
+		{
+			MethodName: "SetOwnership",
+			Handler:    _DatabaseDaemon_SetOwnership_Handler,
+		},
+		{
+			MethodName: "FixOwnership",
+			Handler:    _DatabaseDaemon_FixOwnership_Handler,
+		},
+		{
+			MethodName: "GetNLSSettings",
+			Handler:    _DatabaseDaemon_GetNLSSettings_Handler,
+		},
+		{
+			MethodName: "SetNLSSettings",
+			Handler:    _DatabaseDaemon_SetNLSSettings_Handler,
+		},
+		{
+			MethodName: "ConfigurePDBResources",
+			Handler:    _DatabaseDaemon_ConfigurePDBResources_Handler,
+		},
+		{
+			MethodName: "ConfigureRowLevelSecurity",
+			Handler:    _DatabaseDaemon_ConfigureRowLevelSecurity_Handler,
+		},
+		{
+			MethodName: "FullInstanceExportAsync",
+			Handler:    _DatabaseDaemon_FullInstanceExportAsync_Handler,
+		},
+		{
+			MethodName: "FullInstanceImportAsync",
+			Handler:    _DatabaseDaemon_FullInstanceImportAsync_Handler,
+		},
+		{
+			MethodName: "GetStandbyApplyStatus",
+			Handler:    _DatabaseDaemon_GetStandbyApplyStatus_Handler,
+		},
+		{
+			MethodName: "ResolveArchiveLogGap",
+			Handler:    _DatabaseDaemon_ResolveArchiveLogGap_Handler,
+		},
+		{
+			MethodName: "ConfigureRedoTransport",
+			Handler:    _DatabaseDaemon_ConfigureRedoTransport_Handler,
+		},
+		{
+			MethodName: "GetInstalledOptions",
+			Handler:    _DatabaseDaemon_GetInstalledOptions_Handler,
+		},
+		{
+			MethodName: "RunSQLScriptFromGCS",
+			Handler:    _DatabaseDaemon_RunSQLScriptFromGCS_Handler,
+		},
+		{
+			MethodName: "SwitchOracleHomeAsync",
+			Handler:    _DatabaseDaemon_SwitchOracleHomeAsync_Handler,
+		},
+		{
+			MethodName: "CheckRestoreCompatibility",
+			Handler:    _DatabaseDaemon_CheckRestoreCompatibility_Handler,
+		},
+		{
+			MethodName: "WriteBackupMetadata",
+			Handler:    _DatabaseDaemon_WriteBackupMetadata_Handler,
+		},
+		{
+			MethodName: "ReadBackupMetadata",
+			Handler:    _DatabaseDaemon_ReadBackupMetadata_Handler,
+		},
+		{
+			MethodName: "ListRestorePoints",
+			Handler:    _DatabaseDaemon_ListRestorePoints_Handler,
+		},
+		{
+			MethodName: "CreateRestorePoint",
+			Handler:    _DatabaseDaemon_CreateRestorePoint_Handler,
+		},
+		{
+			MethodName: "DropRestorePoint",
+			Handler:    _DatabaseDaemon_DropRestorePoint_Handler,
+		},
+		{
+			MethodName: "ValidateSnapshotFiles",
+			Handler:    _DatabaseDaemon_ValidateSnapshotFiles_Handler,
+		},
+		{
+			MethodName: "GetRedoRate",
+			Handler:    _DatabaseDaemon_GetRedoRate_Handler,
+		},
+		{
+			MethodName: "RotateWalletPassword",
+			Handler:    _DatabaseDaemon_RotateWalletPassword_Handler,
+		},
+		{
+			MethodName: "ConfigureEditions",
+			Handler:    _DatabaseDaemon_ConfigureEditions_Handler,
+		},
+		{
+			MethodName: "GetPGAUsage",
+			Handler:    _DatabaseDaemon_GetPGAUsage_Handler,
+		},
+		{
+			MethodName: "SetSQLPlanCapture",
+			Handler:    _DatabaseDaemon_SetSQLPlanCapture_Handler,
+		},
+		{
+			MethodName: "GetSQLPlanBaselines",
+			Handler:    _DatabaseDaemon_GetSQLPlanBaselines_Handler,
+		},
+		{
+			MethodName: "SetTablespaceEncryptionPolicy",
+			Handler:    _DatabaseDaemon_SetTablespaceEncryptionPolicy_Handler,
+		},
+		{
+			MethodName: "RestoreValidationReportAsync",
+			Handler:    _DatabaseDaemon_RestoreValidationReportAsync_Handler,
+		},
+		{
+			MethodName: "DiffParametersAgainstBaseline",
+			Handler:    _DatabaseDaemon_DiffParametersAgainstBaseline_Handler,
+		},
+		{
+			MethodName: "ConfigureFAN",
+			Handler:    _DatabaseDaemon_ConfigureFAN_Handler,
+		},
+		{
+			MethodName: "CreateManagedTrigger",
+			Handler:    _DatabaseDaemon_CreateManagedTrigger_Handler,
+		},
+		{
+			MethodName: "DropManagedTrigger",
+			Handler:    _DatabaseDaemon_DropManagedTrigger_Handler,
+		},
+		{
+			MethodName: "ListManagedTriggers",
+			Handler:    _DatabaseDaemon_ListManagedTriggers_Handler,
+		},
+		{
+			MethodName: "CheckStandbySynchronized",
+			Handler:    _DatabaseDaemon_CheckStandbySynchronized_Handler,
+		},
+		{
+			MethodName: "ExportRMANCatalog",
+			Handler:    _DatabaseDaemon_ExportRMANCatalog_Handler,
+		},
+		{
+			MethodName: "RunWriteCanary",
+			Handler:    _DatabaseDaemon_RunWriteCanary_Handler,
+		},
+		{
+			MethodName: "ReconcilePDBs",
+			Handler:    _DatabaseDaemon_ReconcilePDBs_Handler,
+		},
+		{
+			MethodName: "CheckPITRTarget",
+			Handler:    _DatabaseDaemon_CheckPITRTarget_Handler,
+		},
+		{
+			MethodName: "ConfigureKeystore",
+			Handler:    _DatabaseDaemon_ConfigureKeystore_Handler,
+		},
+		{
+			MethodName: "SetMasterKey",
+			Handler:    _DatabaseDaemon_SetMasterKey_Handler,
+		},
+		{
+			MethodName: "EncryptTablespaces",
+			Handler:    _DatabaseDaemon_EncryptTablespaces_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamOperationLog",
+			Handler:       _DatabaseDaemon_StreamOperationLog_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "oracle/pkg/agents/oracle/dbdaemon.proto",
 }
@@ -29,6 +29,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -1086,15 +1087,149 @@ func Merge(metadata LogMetadata) [][]string {
 	return windows
 }
 
-// StageLogs copies redo logs from src dir to dest dir.
-func StageLogs(ctx context.Context, destDir string, include func(entry LogMetadataEntry) bool, logPath string) error {
-	metadataStore, err := NewSimpleStore(ctx, logPath)
+// RecoveryCheck is whether the replicated redo logs recover a backup to a
+// target SCN or timestamp.
+type RecoveryCheck struct {
+	// Recoverable is whether the redo logs cover the backup up to the target
+	// without a gap.
+	Recoverable bool
+	// ClosestSCN and ClosestTime are the latest point the redo logs recover
+	// the backup to if the target isn't recoverable, zero if no replicated
+	// redo log covers the backup.
+	ClosestSCN  int64
+	ClosestTime time.Time
+	// Reason is why the target isn't recoverable.
+	Reason string
+}
+
+// logWindow is a range of SCNs and times covered by contiguous replicated
+// redo logs.
+type logWindow struct {
+	firstSCN, nextSCN   int64
+	firstTime, nextTime time.Time
+}
+
+// recoveryWindows merges the replicated redo logs into contiguous windows,
+// ordered by time if byTime or else by SCN. Overlapping logs, e.g. of two
+// incarnations, merge into one window.
+func recoveryWindows(metadata LogMetadata, byTime bool) ([]logWindow, error) {
+	var logs []logWindow
+	for _, entry := range metadata.KeyToLogEntry {
+		if entry.ReplicaPath == "" {
+			continue
+		}
+		first, err := strconv.ParseInt(entry.FirstChange, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse FirstChange of %s: %v", entry.SrcPath, err)
+		}
+		next, err := strconv.ParseInt(entry.NextChange, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse NextChange of %s: %v", entry.SrcPath, err)
+		}
+		logs = append(logs, logWindow{firstSCN: first, nextSCN: next, firstTime: entry.FirstTime, nextTime: entry.NextTime})
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if byTime {
+			return logs[i].firstTime.Before(logs[j].firstTime)
+		}
+		return logs[i].firstSCN < logs[j].firstSCN
+	})
+
+	var windows []logWindow
+	for _, l := range logs {
+		if n := len(windows); n > 0 {
+			w := &windows[n-1]
+			contiguous := l.firstSCN <= w.nextSCN
+			if byTime {
+				contiguous = !l.firstTime.After(w.nextTime)
+			}
+			if contiguous {
+				if l.nextSCN > w.nextSCN {
+					w.nextSCN = l.nextSCN
+				}
+				if l.nextTime.After(w.nextTime) {
+					w.nextTime = l.nextTime
+				}
+				continue
+			}
+		}
+		windows = append(windows, l)
+	}
+	return windows, nil
+}
+
+// CheckRecoverySCN checks that the replicated redo logs recover a backup
+// taken at startSCN to endSCN.
+func CheckRecoverySCN(metadata LogMetadata, startSCN, endSCN int64) (RecoveryCheck, error) {
+	windows, err := recoveryWindows(metadata, false)
 	if err != nil {
-		return fmt.Errorf("failed to create a metadata store %v", err)
+		return RecoveryCheck{}, err
 	}
+	if endSCN < startSCN {
+		return RecoveryCheck{ClosestSCN: startSCN, Reason: fmt.Sprintf("SCN %d is before the backup at SCN %d", endSCN, startSCN)}, nil
+	}
+	for _, w := range windows {
+		if w.firstSCN > startSCN || w.nextSCN < startSCN {
+			continue
+		}
+		// Recovering until an SCN applies the changes before it.
+		if endSCN <= w.nextSCN {
+			return RecoveryCheck{Recoverable: true}, nil
+		}
+		return RecoveryCheck{
+			ClosestSCN:  w.nextSCN,
+			ClosestTime: w.nextTime,
+			Reason:      fmt.Sprintf("the replicated redo logs following the backup at SCN %d end at SCN %d", startSCN, w.nextSCN),
+		}, nil
+	}
+	return RecoveryCheck{Reason: fmt.Sprintf("no replicated redo log covers the backup at SCN %d", startSCN)}, nil
+}
+
+// CheckRecoveryTime checks that the replicated redo logs recover a backup
+// taken at startTime to endTime.
+func CheckRecoveryTime(metadata LogMetadata, startTime, endTime time.Time) (RecoveryCheck, error) {
+	windows, err := recoveryWindows(metadata, true)
+	if err != nil {
+		return RecoveryCheck{}, err
+	}
+	if endTime.Before(startTime) {
+		return RecoveryCheck{ClosestTime: startTime, Reason: fmt.Sprintf("%s is before the backup at %s", endTime.Format(time.RFC3339), startTime.Format(time.RFC3339))}, nil
+	}
+	for _, w := range windows {
+		if w.firstTime.After(startTime) || w.nextTime.Before(startTime) {
+			continue
+		}
+		if !endTime.After(w.nextTime) {
+			return RecoveryCheck{Recoverable: true}, nil
+		}
+		return RecoveryCheck{
+			ClosestSCN:  w.nextSCN,
+			ClosestTime: w.nextTime,
+			Reason:      fmt.Sprintf("the replicated redo logs following the backup at %s end at %s", startTime.Format(time.RFC3339), w.nextTime.Format(time.RFC3339)),
+		}, nil
+	}
+	return RecoveryCheck{Reason: fmt.Sprintf("no replicated redo log covers the backup at %s", startTime.Format(time.RFC3339))}, nil
+}
+
+// ReadLogMetadata reads the metadata of the redo logs replicated to logPath.
+func ReadLogMetadata(ctx context.Context, logPath string) (LogMetadata, error) {
 	metadata := LogMetadata{}
+	metadataStore, err := NewSimpleStore(ctx, logPath)
+	if err != nil {
+		return metadata, fmt.Errorf("failed to create a metadata store %v", err)
+	}
+	defer metadataStore.Close(ctx)
 	if err := metadataStore.Read(ctx, MetadataStorePath, &metadata); err != nil {
-		return fmt.Errorf("failed to read metadata: %v", err)
+		return metadata, fmt.Errorf("failed to read metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+// StageLogs copies redo logs from src dir to dest dir.
+func StageLogs(ctx context.Context, destDir string, include func(entry LogMetadataEntry) bool, logPath string) error {
+	metadata, err := ReadLogMetadata(ctx, logPath)
+	if err != nil {
+		return err
 	}
 
 	n := len(metadata.KeyToLogEntry)
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -666,3 +667,136 @@ func TestSetArchiveLag(t *testing.T) {
 		})
 	}
 }
+
+func testLogMetadata() LogMetadata {
+	entry := func(first, next string, firstTime, nextTime int64, replica string) LogMetadataEntry {
+		return LogMetadataEntry{
+			LogHashEntry: LogHashEntry{ReplicaPath: replica},
+			FirstChange:  first,
+			NextChange:   next,
+			FirstTime:    time.Unix(firstTime, 0),
+			NextTime:     time.Unix(nextTime, 0),
+		}
+	}
+	// Logs 1-3 are contiguous, log 4 is missing from the replica and log 5
+	// follows the gap.
+	return LogMetadata{
+		KeyToLogEntry: map[string]LogMetadataEntry{
+			"1-2-1": entry("1000", "2000", 1628281000, 1628282000, "log1"),
+			"1-2-2": entry("2000", "3000", 1628282000, 1628283000, "log2"),
+			"1-2-3": entry("3000", "4000", 1628283000, 1628284000, "log3"),
+			"1-2-4": entry("4000", "5000", 1628284000, 1628285000, ""),
+			"1-2-5": entry("5000", "6000", 1628285000, 1628286000, "log5"),
+		},
+	}
+}
+
+func TestCheckRecoverySCN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		start    int64
+		end      int64
+		want     RecoveryCheck
+		wantText string
+	}{
+		{
+			name:  "within a log",
+			start: 1500,
+			end:   1800,
+			want:  RecoveryCheck{Recoverable: true},
+		},
+		{
+			name:  "across contiguous logs",
+			start: 1500,
+			end:   4000,
+			want:  RecoveryCheck{Recoverable: true},
+		},
+		{
+			name:     "across a gap",
+			start:    1500,
+			end:      5500,
+			want:     RecoveryCheck{ClosestSCN: 4000, ClosestTime: time.Unix(1628284000, 0)},
+			wantText: "end at SCN 4000",
+		},
+		{
+			name:     "backup in the gap",
+			start:    4500,
+			end:      5500,
+			wantText: "no replicated redo log covers the backup at SCN 4500",
+		},
+		{
+			name:     "target before the backup",
+			start:    2500,
+			end:      2000,
+			want:     RecoveryCheck{ClosestSCN: 2500},
+			wantText: "before the backup",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckRecoverySCN(testLogMetadata(), tc.start, tc.end)
+			if err != nil {
+				t.Fatalf("CheckRecoverySCN failed: %v", err)
+			}
+			if !strings.Contains(got.Reason, tc.wantText) || (got.Reason == "") != (tc.wantText == "") {
+				t.Errorf("CheckRecoverySCN got reason %q, want %q", got.Reason, tc.wantText)
+			}
+			got.Reason = ""
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CheckRecoverySCN got unexpected result: want-, got+: %s\n", diff)
+			}
+		})
+	}
+}
+
+func TestCheckRecoveryTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		start    int64
+		end      int64
+		want     RecoveryCheck
+		wantText string
+	}{
+		{
+			name:  "across contiguous logs",
+			start: 1628281500,
+			end:   1628283500,
+			want:  RecoveryCheck{Recoverable: true},
+		},
+		{
+			name:     "across a gap",
+			start:    1628281500,
+			end:      1628285500,
+			want:     RecoveryCheck{ClosestSCN: 4000, ClosestTime: time.Unix(1628284000, 0)},
+			wantText: "end at",
+		},
+		{
+			name:     "after the last log",
+			start:    1628285500,
+			end:      1628287000,
+			want:     RecoveryCheck{ClosestSCN: 6000, ClosestTime: time.Unix(1628286000, 0)},
+			wantText: "end at",
+		},
+		{
+			name:     "backup before the first log",
+			start:    1628280000,
+			end:      1628281500,
+			wantText: "no replicated redo log covers the backup",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CheckRecoveryTime(testLogMetadata(), time.Unix(tc.start, 0), time.Unix(tc.end, 0))
+			if err != nil {
+				t.Fatalf("CheckRecoveryTime failed: %v", err)
+			}
+			if !strings.Contains(got.Reason, tc.wantText) || (got.Reason == "") != (tc.wantText == "") {
+				t.Errorf("CheckRecoveryTime got reason %q, want %q", got.Reason, tc.wantText)
+			}
+			got.Reason = ""
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CheckRecoveryTime got unexpected result: want-, got+: %s\n", diff)
+			}
+		})
+	}
+}
@@ -36,6 +36,9 @@ type fakeServer struct {
 	fakeFileExists                func(ctx context.Context, req *dbdpb.FileExistsRequest) (*dbdpb.FileExistsResponse, error)
 	fakeBounceDatabase            func(ctx context.Context, req *dbdpb.BounceDatabaseRequest) (*dbdpb.BounceDatabaseResponse, error)
 	fakeCreateFile                func(ctx context.Context, req *dbdpb.CreateFileRequest) (*dbdpb.CreateFileResponse, error)
+	fakeCheckStandbySynchronized  func(ctx context.Context, req *dbdpb.CheckStandbySynchronizedRequest) (*dbdpb.CheckStandbySynchronizedResponse, error)
+	fakeTNSPing                   func(ctx context.Context, req *dbdpb.TNSPingRequest) (*dbdpb.TNSPingResponse, error)
+	fakeGetStandbyApplyStatus     func(ctx context.Context, req *dbdpb.GetStandbyApplyStatusRequest) (*dbdpb.GetStandbyApplyStatusResponse, error)
 }
 
 func (f *fakeServer) RunDataGuard(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
@@ -101,6 +104,27 @@ func (f *fakeServer) CreateFile(ctx context.Context, req *dbdpb.CreateFileReques
 	return f.fakeCreateFile(ctx, req)
 }
 
+func (f *fakeServer) CheckStandbySynchronized(ctx context.Context, req *dbdpb.CheckStandbySynchronizedRequest) (*dbdpb.CheckStandbySynchronizedResponse, error) {
+	if f.fakeCheckStandbySynchronized == nil {
+		return nil, errors.New("CheckStandbySynchronized fake not found")
+	}
+	return f.fakeCheckStandbySynchronized(ctx, req)
+}
+
+func (f *fakeServer) TNSPing(ctx context.Context, req *dbdpb.TNSPingRequest) (*dbdpb.TNSPingResponse, error) {
+	if f.fakeTNSPing == nil {
+		return nil, errors.New("TNSPing fake not found")
+	}
+	return f.fakeTNSPing(ctx, req)
+}
+
+func (f *fakeServer) GetStandbyApplyStatus(ctx context.Context, req *dbdpb.GetStandbyApplyStatusRequest) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+	if f.fakeGetStandbyApplyStatus == nil {
+		return nil, errors.New("GetStandbyApplyStatus fake not found")
+	}
+	return f.fakeGetStandbyApplyStatus(ctx, req)
+}
+
 func newFakeDatabaseDaemonClient(t *testing.T, server *fakeServer) (dbdpb.DatabaseDaemonClient, func()) {
 	t.Helper()
 	grpcSvr := grpc.NewServer()
@@ -0,0 +1,247 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	connect "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"k8s.io/klog/v2"
+)
+
+// DRDrillState is a state of a disaster recovery drill.
+type DRDrillState string
+
+const (
+	// DRDrillPreflight checks the standby is synchronized and ready for a
+	// switchover.
+	DRDrillPreflight DRDrillState = "Preflight"
+	// DRDrillSwitchover switches the standby to the primary role.
+	DRDrillSwitchover DRDrillState = "Switchover"
+	// DRDrillValidation runs the validation queries on the new primary.
+	DRDrillValidation DRDrillState = "Validation"
+	// DRDrillSwitchback switches the roles back after a successful drill.
+	DRDrillSwitchback DRDrillState = "Switchback"
+	// DRDrillRollback switches the roles back after a failed drill.
+	DRDrillRollback DRDrillState = "Rollback"
+	// DRDrillCompleted is the final state of a successful drill.
+	DRDrillCompleted DRDrillState = "Completed"
+	// DRDrillRolledBack is the final state of a failed drill after which
+	// the roles were switched back.
+	DRDrillRolledBack DRDrillState = "RolledBack"
+	// DRDrillFailed is the final state of a failed drill which left the
+	// roles as they were when it failed, switched if the switchback failed.
+	DRDrillFailed DRDrillState = "Failed"
+)
+
+// readyForSwitchoverRe matches the readiness in the output of dgmgrl
+// validate database.
+var readyForSwitchoverRe = regexp.MustCompile(`Ready for Switchover:\s*(\w+)`)
+
+// DRDrillResult is the outcome of a disaster recovery drill.
+type DRDrillResult struct {
+	// State is the final state, DRDrillCompleted if the drill succeeded.
+	State DRDrillState
+	// FailedState is the state which failed, if any.
+	FailedState DRDrillState
+	// Err is the failure of FailedState.
+	Err error
+	// Durations are the durations of the states which ran.
+	Durations map[DRDrillState]time.Duration
+}
+
+// nextDRDrillState returns the state following a state of a drill given the
+// error of the state and whether the standby is in the primary role.
+func nextDRDrillState(state DRDrillState, err error, switched bool) DRDrillState {
+	switch state {
+	case DRDrillPreflight:
+		if err != nil {
+			return DRDrillFailed
+		}
+		return DRDrillSwitchover
+	case DRDrillSwitchover:
+		if err == nil {
+			return DRDrillValidation
+		}
+		if switched {
+			return DRDrillRollback
+		}
+		return DRDrillFailed
+	case DRDrillValidation:
+		if err != nil {
+			return DRDrillRollback
+		}
+		return DRDrillSwitchback
+	case DRDrillSwitchback:
+		if err != nil {
+			return DRDrillFailed
+		}
+		return DRDrillCompleted
+	case DRDrillRollback:
+		if err != nil {
+			return DRDrillFailed
+		}
+		return DRDrillRolledBack
+	}
+	return DRDrillFailed
+}
+
+// drDrill runs the states of a drill with the broker, the switchovers
+// connect to the primary with its credentials.
+type drDrill struct {
+	primary           *Primary
+	standby           *Standby
+	validationQueries []string
+	dbdClient         dbdpb.DatabaseDaemonClient
+	// primaryUniqueName is the DB unique name of the primary, read from the
+	// broker configuration by the preflight.
+	primaryUniqueName string
+}
+
+// runDataGuard runs a dgmgrl command connected to the primary.
+func (d *drDrill) runDataGuard(ctx context.Context, script string) (string, error) {
+	passwd, err := d.primary.PasswordAccessor.Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to access the primary credential: %v", err)
+	}
+	resp, err := d.dbdClient.RunDataGuard(ctx, &dbdpb.RunDataGuardRequest{
+		Target:  connect.EZ(d.primary.User, passwd, d.primary.Host, strconv.Itoa(d.primary.Port), d.primary.Service, false),
+		Scripts: []string{script},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(resp.GetOutput(), "\n"), nil
+}
+
+// isPrimary reports whether the standby database is in the primary role.
+func (d *drDrill) isPrimary(ctx context.Context) (bool, error) {
+	roles, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, d.dbdClient, "select database_role from v$database")
+	if err != nil {
+		return false, err
+	}
+	return len(roles) == 1 && roles[0] == "PRIMARY", nil
+}
+
+func (d *drDrill) preflight(ctx context.Context) error {
+	members, err := newDgConfig(d.dbdClient, func(context.Context) (string, error) { return "/", nil }).members(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the Data Guard configuration: %v", err)
+	}
+	if !members.standbyContains(d.standby.DBUniqueName) {
+		return fmt.Errorf("%s isn't a standby of the Data Guard configuration %s", d.standby.DBUniqueName, members.configuration)
+	}
+	d.primaryUniqueName = members.primary
+	// A switchover to a standby which lags or misses logs loses the redo it
+	// didn't apply.
+	sync, err := d.dbdClient.CheckStandbySynchronized(ctx, &dbdpb.CheckStandbySynchronizedRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to check the standby is synchronized: %v", err)
+	}
+	if !sync.GetSynchronized() {
+		return fmt.Errorf("the standby isn't synchronized: %s", strings.Join(sync.GetReasons(), "; "))
+	}
+	out, err := d.runDataGuard(ctx, fmt.Sprintf("validate database %s", d.standby.DBUniqueName))
+	if err != nil {
+		return fmt.Errorf("failed to validate the standby: %v", err)
+	}
+	m := readyForSwitchoverRe.FindStringSubmatch(out)
+	if m == nil || !strings.EqualFold(m[1], "Yes") {
+		return fmt.Errorf("the standby isn't ready for switchover: %s", out)
+	}
+	return nil
+}
+
+func (d *drDrill) validate(ctx context.Context) error {
+	primary, err := d.isPrimary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the database role: %v", err)
+	}
+	if !primary {
+		return fmt.Errorf("the standby isn't in the primary role after the switchover")
+	}
+	for _, query := range d.validationQueries {
+		if _, err := fetchAndParseQueries(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands:    []string{query},
+			ConnectInfo: &dbdpb.RunSQLPlusCMDRequest_Local{},
+		}, d.dbdClient); err != nil {
+			return fmt.Errorf("validation query failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// run runs the state of the drill and returns whether the standby is in the
+// primary role after it.
+func (d *drDrill) run(ctx context.Context, state DRDrillState) (bool, error) {
+	switch state {
+	case DRDrillPreflight:
+		return false, d.preflight(ctx)
+	case DRDrillSwitchover:
+		if _, err := d.runDataGuard(ctx, fmt.Sprintf("switchover to %s", d.standby.DBUniqueName)); err != nil {
+			// The broker may fail after the role transition, the roles
+			// are switched back if so.
+			switched, roleErr := d.isPrimary(ctx)
+			if roleErr != nil {
+				klog.ErrorS(roleErr, "failed to read the database role after a failed switchover")
+			}
+			return switched, err
+		}
+		return true, nil
+	case DRDrillValidation:
+		return true, d.validate(ctx)
+	case DRDrillSwitchback, DRDrillRollback:
+		if _, err := d.runDataGuard(ctx, fmt.Sprintf("switchover to %s", d.primaryUniqueName)); err != nil {
+			return true, err
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected state %s", state)
+}
+
+// RunDRDrill switches the standby to the primary role, runs the validation
+// queries on it and switches the roles back. The switchovers don't lose
+// data, the roles are switched back if the validation fails.
+func RunDRDrill(ctx context.Context, primary *Primary, standby *Standby, validationQueries []string, dbdClient dbdpb.DatabaseDaemonClient) *DRDrillResult {
+	d := &drDrill{
+		primary:           primary,
+		standby:           standby,
+		validationQueries: validationQueries,
+		dbdClient:         dbdClient,
+	}
+	result := &DRDrillResult{Durations: make(map[DRDrillState]time.Duration)}
+	state := DRDrillPreflight
+	for state != DRDrillCompleted && state != DRDrillRolledBack && state != DRDrillFailed {
+		klog.InfoS("DR drill", "state", state)
+		start := time.Now()
+		switched, err := d.run(ctx, state)
+		result.Durations[state] = time.Since(start)
+		if err != nil {
+			klog.ErrorS(err, "DR drill state failed", "state", state, "switched", switched)
+			if result.Err == nil || state == DRDrillRollback {
+				result.FailedState, result.Err = state, err
+			}
+		}
+		state = nextDRDrillState(state, err, switched)
+	}
+	result.State = state
+	return result
+}
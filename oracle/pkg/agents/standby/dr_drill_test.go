@@ -0,0 +1,225 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNextDRDrillState(t *testing.T) {
+	failure := errors.New("failure")
+	testCases := []struct {
+		state    DRDrillState
+		err      error
+		switched bool
+		want     DRDrillState
+	}{
+		{state: DRDrillPreflight, want: DRDrillSwitchover},
+		{state: DRDrillPreflight, err: failure, want: DRDrillFailed},
+		{state: DRDrillSwitchover, switched: true, want: DRDrillValidation},
+		{state: DRDrillSwitchover, err: failure, want: DRDrillFailed},
+		{state: DRDrillSwitchover, err: failure, switched: true, want: DRDrillRollback},
+		{state: DRDrillValidation, switched: true, want: DRDrillSwitchback},
+		{state: DRDrillValidation, err: failure, switched: true, want: DRDrillRollback},
+		{state: DRDrillSwitchback, want: DRDrillCompleted},
+		{state: DRDrillSwitchback, err: failure, switched: true, want: DRDrillFailed},
+		{state: DRDrillRollback, want: DRDrillRolledBack},
+		{state: DRDrillRollback, err: failure, switched: true, want: DRDrillFailed},
+	}
+	for _, tc := range testCases {
+		if got := nextDRDrillState(tc.state, tc.err, tc.switched); got != tc.want {
+			t.Errorf("nextDRDrillState(%s, %v, %v) got %s, want %s", tc.state, tc.err, tc.switched, got, tc.want)
+		}
+	}
+}
+
+func TestRunDRDrill(t *testing.T) {
+	testCases := []struct {
+		name            string
+		ready           string
+		notSyncReasons  []string
+		switchoverErr   bool
+		roleAfterErr    string
+		validationErr   bool
+		switchbackErr   bool
+		wantState       DRDrillState
+		wantFailedState DRDrillState
+		wantSwitchovers []string
+	}{
+		{
+			name:            "completed",
+			ready:           "Yes",
+			wantState:       DRDrillCompleted,
+			wantSwitchovers: []string{"switchover to gcloud_gke", "switchover to gcloud_primary"},
+		},
+		{
+			name:            "standby not ready",
+			ready:           "No",
+			wantState:       DRDrillFailed,
+			wantFailedState: DRDrillPreflight,
+		},
+		{
+			name:            "standby with an apply lag",
+			ready:           "Yes",
+			notSyncReasons:  []string{"the apply lag is 65s"},
+			wantState:       DRDrillFailed,
+			wantFailedState: DRDrillPreflight,
+		},
+		{
+			name:            "standby with a gap",
+			ready:           "Yes",
+			notSyncReasons:  []string{"the apply lag is unknown", "2 archived log gaps"},
+			wantState:       DRDrillFailed,
+			wantFailedState: DRDrillPreflight,
+		},
+		{
+			name:            "validation failure rolls back",
+			ready:           "Yes",
+			validationErr:   true,
+			wantState:       DRDrillRolledBack,
+			wantFailedState: DRDrillValidation,
+			wantSwitchovers: []string{"switchover to gcloud_gke", "switchover to gcloud_primary"},
+		},
+		{
+			name:            "switchover failure without role change",
+			ready:           "Yes",
+			switchoverErr:   true,
+			roleAfterErr:    "PHYSICAL STANDBY",
+			wantState:       DRDrillFailed,
+			wantFailedState: DRDrillSwitchover,
+			wantSwitchovers: []string{"switchover to gcloud_gke"},
+		},
+		{
+			name:            "switchover failure after role change rolls back",
+			ready:           "Yes",
+			switchoverErr:   true,
+			roleAfterErr:    "PRIMARY",
+			wantState:       DRDrillRolledBack,
+			wantFailedState: DRDrillSwitchover,
+			wantSwitchovers: []string{"switchover to gcloud_gke", "switchover to gcloud_primary"},
+		},
+		{
+			name:            "switchback failure",
+			ready:           "Yes",
+			switchbackErr:   true,
+			wantState:       DRDrillFailed,
+			wantFailedState: DRDrillSwitchback,
+			wantSwitchovers: []string{"switchover to gcloud_gke", "switchover to gcloud_primary"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			role := "PHYSICAL STANDBY"
+			var gotSwitchovers []string
+			dbdServer := &fakeServer{
+				fakeRunDataGuard: func(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+					script := req.GetScripts()[0]
+					switch script {
+					case "show configuration":
+						return &dbdpb.RunDataGuardResponse{Output: []string{`
+Configuration - gcloud_config
+
+  Protection Mode: MaxPerformance
+  Members:
+  gcloud_primary - Primary database
+    gcloud_gke     - Physical standby database
+`}}, nil
+					case "validate database gcloud_gke":
+						return &dbdpb.RunDataGuardResponse{Output: []string{fmt.Sprintf("  Ready for Switchover:    %s", tc.ready)}}, nil
+					case "switchover to gcloud_gke":
+						gotSwitchovers = append(gotSwitchovers, script)
+						if tc.switchoverErr {
+							role = tc.roleAfterErr
+							return nil, errors.New("switchover failed")
+						}
+						role = "PRIMARY"
+						return &dbdpb.RunDataGuardResponse{}, nil
+					case "switchover to gcloud_primary":
+						gotSwitchovers = append(gotSwitchovers, script)
+						if tc.switchbackErr {
+							return nil, errors.New("switchback failed")
+						}
+						role = "PHYSICAL STANDBY"
+						return &dbdpb.RunDataGuardResponse{}, nil
+					}
+					return nil, fmt.Errorf("unexpected script %q", script)
+				},
+				fakeCheckStandbySynchronized: func(ctx context.Context, req *dbdpb.CheckStandbySynchronizedRequest) (*dbdpb.CheckStandbySynchronizedResponse, error) {
+					return &dbdpb.CheckStandbySynchronizedResponse{
+						Synchronized: len(tc.notSyncReasons) == 0,
+						Reasons:      tc.notSyncReasons,
+					}, nil
+				},
+				fakeRunSQLPlusFormatted: func(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+					query := req.GetCommands()[0]
+					switch query {
+					case "select database_role from v$database":
+						return &dbdpb.RunCMDResponse{Msg: []string{fmt.Sprintf(`{"DATABASE_ROLE":%q}`, role)}}, nil
+					case "select count(*) from app.orders":
+						if tc.validationErr {
+							return nil, errors.New("ORA-00942: table or view does not exist")
+						}
+						return &dbdpb.RunCMDResponse{Msg: []string{`{"COUNT(*)":"42"}`}}, nil
+					}
+					return nil, fmt.Errorf("unexpected query %q", query)
+				},
+			}
+			client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+			defer cleanup()
+
+			got := RunDRDrill(
+				context.Background(),
+				&Primary{
+					Host:    "123.123.123.123",
+					Port:    6021,
+					Service: "GCLOUD.gke",
+					User:    "sys",
+					PasswordAccessor: &fakeSecretAccessor{
+						fakeGet: func(context.Context) (string, error) {
+							return "pwd", nil
+						},
+					},
+				},
+				&Standby{
+					CDBName:      "GCLOUD",
+					DBUniqueName: "gcloud_gke",
+				},
+				[]string{"select count(*) from app.orders"},
+				client)
+			if got.State != tc.wantState {
+				t.Errorf("RunDRDrill got state %s, want %s", got.State, tc.wantState)
+			}
+			if got.FailedState != tc.wantFailedState {
+				t.Errorf("RunDRDrill got failed state %s, want %s (err %v)", got.FailedState, tc.wantFailedState, got.Err)
+			}
+			if len(tc.notSyncReasons) > 0 && (got.Err == nil || !strings.Contains(got.Err.Error(), tc.notSyncReasons[0])) {
+				t.Errorf("RunDRDrill of an unsynchronized standby got error %v, want the reasons %q", got.Err, tc.notSyncReasons)
+			}
+			if diff := cmp.Diff(tc.wantSwitchovers, gotSwitchovers); diff != "" {
+				t.Errorf("RunDRDrill ran unexpected switchovers (-want +got):\n%v", diff)
+			}
+			if tc.wantState == DRDrillCompleted && role != "PHYSICAL STANDBY" {
+				t.Errorf("RunDRDrill left the standby in role %s", role)
+			}
+		})
+	}
+}
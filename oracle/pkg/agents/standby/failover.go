@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	connect "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"k8s.io/klog/v2"
+)
+
+// configurationStatusRe matches the status in the output of dgmgrl show
+// configuration, e.g. SUCCESS, WARNING or ERROR.
+var configurationStatusRe = regexp.MustCompile(`Configuration Status:\s*(\w+)`)
+
+// PrimaryHealth is the health of the primary as seen from the standby.
+type PrimaryHealth struct {
+	// ListenerErr is the error of the tnsping of the primary service, nil
+	// if its listener answered.
+	ListenerErr error
+	// BrokerStatus is the status of the Data Guard configuration reported
+	// by the broker of the standby, empty if the broker didn't report one.
+	BrokerStatus string
+	// BrokerOutput is the output of the broker, or its error.
+	BrokerOutput string
+	// TransportLagSeconds is the redo transport lag of the standby, -1 if
+	// it isn't known.
+	TransportLagSeconds int64
+}
+
+// Lost returns whether neither the listener of the primary answers nor the
+// broker reports a healthy configuration. A broker error alone may come from
+// the standby, an unreachable listener alone from the network of the
+// standby, neither is a reason to fail over. A network partition of the
+// standby fails both checks while the primary may still serve clients, the
+// primary has to be fenced before a failover.
+func (h *PrimaryHealth) Lost() bool {
+	return h.ListenerErr != nil && h.BrokerStatus != "SUCCESS"
+}
+
+// configurationStatus returns the status of the configuration in the output
+// of the broker, empty if there is none.
+func configurationStatus(output []string) string {
+	m := configurationStatusRe.FindStringSubmatch(strings.Join(output, "\n"))
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(m[1])
+}
+
+// CheckPrimaryHealth pings the listener of the primary and reads the Data
+// Guard configuration status and transport lag on the standby.
+func CheckPrimaryHealth(ctx context.Context, primary *Primary, standby *Standby, dbdClient dbdpb.DatabaseDaemonClient) (*PrimaryHealth, error) {
+	health := &PrimaryHealth{TransportLagSeconds: -1}
+	if _, err := dbdClient.TNSPing(ctx, &dbdpb.TNSPingRequest{
+		ConnectionString: connect.EZ("", "", primary.Host, strconv.Itoa(primary.Port), primary.Service, false),
+	}); err != nil {
+		health.ListenerErr = err
+	}
+	// dgmgrl fails on some broker errors, those count as an unhealthy
+	// configuration.
+	output, err := DataGuardStatus(ctx, standby.DBUniqueName, dbdClient)
+	if err != nil {
+		health.BrokerOutput = err.Error()
+	} else {
+		health.BrokerStatus = configurationStatus(output)
+		health.BrokerOutput = strings.Join(output, "\n")
+	}
+	status, err := dbdClient.GetStandbyApplyStatus(ctx, &dbdpb.GetStandbyApplyStatusRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the standby apply status: %v", err)
+	}
+	health.TransportLagSeconds = status.GetTransportLagSeconds()
+	return health, nil
+}
+
+// Failover fails over to the standby with the broker of the standby, the
+// primary isn't needed. The standby applies the redo it received before it
+// takes the primary role, the redo it didn't receive is lost and the former
+// primary has to be reinstated as a standby. The caller has to make sure
+// the former primary is down, the broker of the standby doesn't stop it.
+func Failover(ctx context.Context, standby *Standby, dbdClient dbdpb.DatabaseDaemonClient) error {
+	klog.InfoS("failing over to the standby", "standby", standby.DBUniqueName)
+	if _, err := dbdClient.RunDataGuard(ctx, &dbdpb.RunDataGuardRequest{
+		Target:  "/",
+		Scripts: []string{fmt.Sprintf("failover to %s", standby.DBUniqueName)},
+	}); err != nil {
+		return fmt.Errorf("failed to fail over to %s: %v", standby.DBUniqueName, err)
+	}
+	d := &drDrill{standby: standby, dbdClient: dbdClient}
+	primary, err := d.isPrimary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the database role: %v", err)
+	}
+	if !primary {
+		return fmt.Errorf("the standby isn't in the primary role after the failover")
+	}
+	return nil
+}
+
+// Switchover switches the standby to the primary role and the primary to the
+// standby role. It runs the preflight checks of a DR drill first, so that
+// it doesn't lose data.
+func Switchover(ctx context.Context, primary *Primary, standby *Standby, dbdClient dbdpb.DatabaseDaemonClient) error {
+	klog.InfoS("switching over to the standby", "standby", standby.DBUniqueName)
+	d := &drDrill{primary: primary, standby: standby, dbdClient: dbdClient}
+	if err := d.preflight(ctx); err != nil {
+		return err
+	}
+	if _, err := d.run(ctx, DRDrillSwitchover); err != nil {
+		return fmt.Errorf("failed to switch over to %s: %v", standby.DBUniqueName, err)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestCheckPrimaryHealth(t *testing.T) {
+	testCases := []struct {
+		name       string
+		pingErr    bool
+		brokerOut  string
+		brokerErr  bool
+		wantStatus string
+		wantLost   bool
+	}{
+		{
+			name:       "healthy",
+			brokerOut:  "Configuration Status:\nSUCCESS   (status updated 12 seconds ago)",
+			wantStatus: "SUCCESS",
+		},
+		{
+			name:       "listener unreachable",
+			pingErr:    true,
+			brokerOut:  "Configuration Status:\nSUCCESS   (status updated 50 seconds ago)",
+			wantStatus: "SUCCESS",
+		},
+		{
+			name:       "broker error",
+			brokerOut:  "Configuration Status:\nERROR   (status updated 0 seconds ago)",
+			wantStatus: "ERROR",
+		},
+		{
+			name:       "lost",
+			pingErr:    true,
+			brokerOut:  "Configuration Status:\nERROR   (status updated 0 seconds ago)",
+			wantStatus: "ERROR",
+			wantLost:   true,
+		},
+		{
+			name:      "lost with a failing broker",
+			pingErr:   true,
+			brokerErr: true,
+			wantLost:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPing string
+			dbdServer := &fakeServer{
+				fakeTNSPing: func(ctx context.Context, req *dbdpb.TNSPingRequest) (*dbdpb.TNSPingResponse, error) {
+					gotPing = req.GetConnectionString()
+					if tc.pingErr {
+						return nil, errors.New("TNS-12541: TNS:no listener")
+					}
+					return &dbdpb.TNSPingResponse{}, nil
+				},
+				fakeRunDataGuard: func(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+					if tc.brokerErr {
+						return nil, errors.New("ORA-16625: cannot reach member")
+					}
+					return &dbdpb.RunDataGuardResponse{Output: []string{tc.brokerOut, "Database - gcloud_gke"}}, nil
+				},
+				fakeGetStandbyApplyStatus: func(ctx context.Context, req *dbdpb.GetStandbyApplyStatusRequest) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+					return &dbdpb.GetStandbyApplyStatusResponse{TransportLagSeconds: 3}, nil
+				},
+			}
+			client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+			defer cleanup()
+
+			got, err := CheckPrimaryHealth(context.Background(), &Primary{Host: "123.123.123.123", Port: 6021, Service: "GCLOUD.gke"}, &Standby{DBUniqueName: "gcloud_gke"}, client)
+			if err != nil {
+				t.Fatalf("CheckPrimaryHealth failed: %v", err)
+			}
+			if want := "123.123.123.123:6021/GCLOUD.gke"; gotPing != want {
+				t.Errorf("CheckPrimaryHealth pinged %q, want %q", gotPing, want)
+			}
+			if got.BrokerStatus != tc.wantStatus {
+				t.Errorf("CheckPrimaryHealth got broker status %q, want %q", got.BrokerStatus, tc.wantStatus)
+			}
+			if got.TransportLagSeconds != 3 {
+				t.Errorf("CheckPrimaryHealth got transport lag %d, want 3", got.TransportLagSeconds)
+			}
+			if got.Lost() != tc.wantLost {
+				t.Errorf("CheckPrimaryHealth got lost %v, want %v", got.Lost(), tc.wantLost)
+			}
+		})
+	}
+}
+
+func TestFailover(t *testing.T) {
+	testCases := []struct {
+		name        string
+		failoverErr bool
+		roleAfter   string
+		wantErr     bool
+	}{
+		{
+			name:      "completed",
+			roleAfter: "PRIMARY",
+		},
+		{
+			name:        "broker failure",
+			failoverErr: true,
+			roleAfter:   "PHYSICAL STANDBY",
+			wantErr:     true,
+		},
+		{
+			name:      "role unchanged",
+			roleAfter: "PHYSICAL STANDBY",
+			wantErr:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotScripts []string
+			dbdServer := &fakeServer{
+				fakeRunDataGuard: func(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+					if req.GetTarget() != "/" {
+						return nil, fmt.Errorf("unexpected target %q", req.GetTarget())
+					}
+					gotScripts = append(gotScripts, req.GetScripts()...)
+					if tc.failoverErr {
+						return nil, errors.New("failover failed")
+					}
+					return &dbdpb.RunDataGuardResponse{}, nil
+				},
+				fakeRunSQLPlusFormatted: func(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+					return &dbdpb.RunCMDResponse{Msg: []string{fmt.Sprintf(`{"DATABASE_ROLE":%q}`, tc.roleAfter)}}, nil
+				},
+			}
+			client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+			defer cleanup()
+
+			err := Failover(context.Background(), &Standby{DBUniqueName: "gcloud_gke"}, client)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Failover got error %v, want error %v", err, tc.wantErr)
+			}
+			if len(gotScripts) != 1 || gotScripts[0] != "failover to gcloud_gke" {
+				t.Errorf("Failover ran %q, want [failover to gcloud_gke]", gotScripts)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	connect "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util/task"
+	"k8s.io/klog/v2"
+)
+
+const (
+	supplementalLoggingSql    = "select log_mode, supplemental_log_data_pk, supplemental_log_data_ui from v$database"
+	addSupplementalLoggingSql = "alter database add supplemental log data (primary key, unique index) columns"
+	unsupportedTablesSql      = "select distinct owner || '.' || table_name as name from dba_logstdby_unsupported order by 1"
+	buildLogMinerDictSql      = "begin dbms_logstdby.build; end;"
+	databaseRoleSql           = "select database_role from v$database"
+	openReadWriteSql          = "select open_mode from v$database where open_mode='READ WRITE'"
+	openResetLogsSql          = "alter database open resetlogs"
+)
+
+// logicalStandbyNameRe matches the database names accepted by
+// "alter database recover to logical standby".
+var logicalStandbyNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_#$]{0,7}$`)
+
+// missingSupplementalLogging returns the supplemental logging SQL Apply needs
+// to identify the modified rows and the primary is missing.
+func missingSupplementalLogging(row map[string]string) []string {
+	var missing []string
+	if row["SUPPLEMENTAL_LOG_DATA_PK"] != "YES" {
+		missing = append(missing, "primary key")
+	}
+	if row["SUPPLEMENTAL_LOG_DATA_UI"] != "YES" {
+		missing = append(missing, "unique index")
+	}
+	return missing
+}
+
+// recoverToLogicalStandbySql returns the statement converting the physical
+// standby to a logical standby. Without a new database name the logical
+// standby keeps the name and DBID of the primary, which is only supported for
+// rolling upgrades.
+func recoverToLogicalStandbySql(dbName string) (string, error) {
+	if dbName == "" {
+		return "alter database recover to logical standby keep identity", nil
+	}
+	if !logicalStandbyNameRe.MatchString(dbName) {
+		return "", fmt.Errorf("invalid logical standby database name %q", dbName)
+	}
+	return fmt.Sprintf("alter database recover to logical standby %s", strings.ToUpper(dbName)), nil
+}
+
+type createLogicalStandbyTask struct {
+	tasks     *task.Tasks
+	primary   *Primary
+	standby   *Standby
+	dbName    string
+	dbdClient dbdpb.DatabaseDaemonClient
+	standbyDg *dgConfig
+	primaryDg *dgConfig
+
+	unsupportedTables []string
+}
+
+// runOnPrimary runs statements on the primary database.
+func (task *createLogicalStandbyTask) runOnPrimary(ctx context.Context, commands ...string) error {
+	passwd, err := task.primary.PasswordAccessor.Get(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = task.dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands:    commands,
+		Suppress:    true,
+		ConnectInfo: &dbdpb.RunSQLPlusCMDRequest_Dsn{Dsn: connect.EZ(task.primary.User, passwd, task.primary.Host, strconv.Itoa(task.primary.Port), task.primary.Service, true)},
+	})
+	return err
+}
+
+// queryPrimary runs a query on the primary database.
+func (task *createLogicalStandbyTask) queryPrimary(ctx context.Context, query string) ([]map[string]string, error) {
+	passwd, err := task.primary.PasswordAccessor.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fetchAndParseQueries(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands:    []string{query},
+		Suppress:    true,
+		ConnectInfo: &dbdpb.RunSQLPlusCMDRequest_Dsn{Dsn: connect.EZ(task.primary.User, passwd, task.primary.Host, strconv.Itoa(task.primary.Port), task.primary.Service, true)},
+	}, task.dbdClient)
+}
+
+// checkPrerequisites checks the primary runs in ARCHIVELOG mode and logs the
+// primary key and unique index columns of the modified rows, which SQL Apply
+// needs to find the rows on the standby. The missing supplemental logging is
+// added.
+func (task *createLogicalStandbyTask) checkPrerequisites(ctx context.Context) error {
+	res, err := task.queryPrimary(ctx, supplementalLoggingSql)
+	if err != nil {
+		return fmt.Errorf("checkPrerequisites: Error while reading primary logging settings: %v", err)
+	}
+	if len(res) != 1 {
+		return fmt.Errorf("checkPrerequisites: unexpected primary logging settings: %v", res)
+	}
+	if res[0]["LOG_MODE"] != "ARCHIVELOG" {
+		return fmt.Errorf("checkPrerequisites: primary database runs in %s mode, a logical standby needs ARCHIVELOG", res[0]["LOG_MODE"])
+	}
+	if missing := missingSupplementalLogging(res[0]); len(missing) > 0 {
+		klog.InfoS("adding supplemental logging on primary", "missing", missing)
+		if err := task.runOnPrimary(ctx, addSupplementalLoggingSql); err != nil {
+			return fmt.Errorf("checkPrerequisites: Error while adding supplemental logging: %v", err)
+		}
+		res, err = task.queryPrimary(ctx, supplementalLoggingSql)
+		if err != nil {
+			return fmt.Errorf("checkPrerequisites: Error while reading primary logging settings: %v", err)
+		}
+		if len(res) != 1 || len(missingSupplementalLogging(res[0])) > 0 {
+			return fmt.Errorf("checkPrerequisites: primary database still misses supplemental logging: %v", res)
+		}
+	}
+	tables, err := fetchAndParseSingleColumnMultiRowQueries(ctx, task.primary, task.dbdClient, unsupportedTablesSql)
+	if err != nil {
+		return fmt.Errorf("checkPrerequisites: Error while listing tables unsupported by SQL Apply: %v", err)
+	}
+	if len(tables) > 0 {
+		klog.InfoS("found tables SQL Apply does not maintain", "tables", tables)
+	}
+	task.unsupportedTables = tables
+	return nil
+}
+
+// removeDataGuardConfig removes the standby from the broker configuration,
+// SQL Apply is controlled directly on the logical standby.
+func (task *createLogicalStandbyTask) removeDataGuardConfig(ctx context.Context) error {
+	return removeStandbyFromDataGuard(ctx, task.primaryDg, task.standbyDg, task.standby.DBUniqueName)
+}
+
+func (task *createLogicalStandbyTask) stopRedoApply(ctx context.Context) error {
+	res, err := fetchAndParseQueries(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands:    []string{consts.ListMRPSql},
+		ConnectInfo: &dbdpb.RunSQLPlusCMDRequest_Local{},
+	}, task.dbdClient)
+	if err != nil {
+		return fmt.Errorf("stopRedoApply: Error while querying managed recovery processes: %v", err)
+	}
+	if len(res) > 0 {
+		klog.InfoS("cancelling managed recovery processes for standby", "res", res)
+		if _, err := task.dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: []string{consts.CancelMRPSql},
+		}); err != nil {
+			return fmt.Errorf("stopRedoApply: Error while cancelling managed recovery processes: %v", err)
+		}
+	}
+	return nil
+}
+
+// buildDictionary builds the LogMiner dictionary into the redo of the
+// primary, the standby recovers up to it before the conversion.
+func (task *createLogicalStandbyTask) buildDictionary(ctx context.Context) error {
+	role, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, task.dbdClient, databaseRoleSql)
+	if err != nil {
+		return fmt.Errorf("buildDictionary: Error while checking standby database role: %v", err)
+	}
+	if len(role) == 1 && role[0] == "LOGICAL STANDBY" {
+		return nil
+	}
+	klog.InfoS("building LogMiner dictionary on primary")
+	if err := task.runOnPrimary(ctx, buildLogMinerDictSql); err != nil {
+		return fmt.Errorf("buildDictionary: Error while building LogMiner dictionary: %v", err)
+	}
+	return nil
+}
+
+func (task *createLogicalStandbyTask) bounceToMount(ctx context.Context) error {
+	if _, err := task.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:         dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName:      task.standby.CDBName,
+		Option:            "immediate",
+		AvoidConfigBackup: true,
+	}); err != nil {
+		return fmt.Errorf("error while shutting down standby database: %v", err)
+	}
+	if _, err := task.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:         dbdpb.BounceDatabaseRequest_STARTUP,
+		DatabaseName:      task.standby.CDBName,
+		Option:            "mount",
+		AvoidConfigBackup: true,
+	}); err != nil {
+		return fmt.Errorf("error while mounting standby database: %v", err)
+	}
+	return nil
+}
+
+// recoverToLogicalStandby applies the redo up to the LogMiner dictionary and
+// converts the physical standby to a logical standby.
+func (task *createLogicalStandbyTask) recoverToLogicalStandby(ctx context.Context) error {
+	role, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, task.dbdClient, databaseRoleSql)
+	if err != nil {
+		return fmt.Errorf("recoverToLogicalStandby: Error while checking standby database role: %v", err)
+	}
+	if len(role) != 1 || role[0] != "PHYSICAL STANDBY" {
+		klog.InfoS("standby database is not a physical standby, skipping conversion", "role", role)
+		return nil
+	}
+	recoverSql, err := recoverToLogicalStandbySql(task.dbName)
+	if err != nil {
+		return fmt.Errorf("recoverToLogicalStandby: %v", err)
+	}
+	if err := task.bounceToMount(ctx); err != nil {
+		return fmt.Errorf("recoverToLogicalStandby: %v", err)
+	}
+	klog.InfoS("converting physical standby to logical standby", "sql", recoverSql)
+	if _, err := task.dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{recoverSql},
+	}); err != nil {
+		return fmt.Errorf("recoverToLogicalStandby: Error while converting to logical standby: %v", err)
+	}
+	return nil
+}
+
+// openLogicalStandby opens the converted standby with new redo logs, the
+// conversion leaves it mounted in a new incarnation.
+func (task *createLogicalStandbyTask) openLogicalStandby(ctx context.Context) error {
+	res, err := fetchAndParseQueries(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands:    []string{openReadWriteSql},
+		ConnectInfo: &dbdpb.RunSQLPlusCMDRequest_Local{},
+	}, task.dbdClient)
+	if err != nil {
+		return fmt.Errorf("openLogicalStandby: Error while checking standby database status: %v", err)
+	}
+	if len(res) > 0 {
+		return nil
+	}
+	if err := task.bounceToMount(ctx); err != nil {
+		return fmt.Errorf("openLogicalStandby: %v", err)
+	}
+	klog.InfoS("opening logical standby database")
+	if _, err := task.dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{openResetLogsSql},
+	}); err != nil {
+		return fmt.Errorf("openLogicalStandby: Error while opening logical standby database: %v", err)
+	}
+	return nil
+}
+
+func (task *createLogicalStandbyTask) startSQLApply(ctx context.Context) error {
+	if err := StartSQLApply(ctx, task.dbdClient); err != nil {
+		return fmt.Errorf("startSQLApply: %v", err)
+	}
+	return nil
+}
+
+// newCreateLogicalStandbyTask converts a physical standby of the primary to a
+// logical standby named dbName.
+func newCreateLogicalStandbyTask(ctx context.Context, primary *Primary, standby *Standby, dbName string, dbdClient dbdpb.DatabaseDaemonClient) *createLogicalStandbyTask {
+	t := &createLogicalStandbyTask{
+		tasks:     task.NewTasks(ctx, "createLogicalStandbyTask"),
+		dbdClient: dbdClient,
+		primary:   primary,
+		standby:   standby,
+		dbName:    dbName,
+		standbyDg: newDgConfig(dbdClient, func(context.Context) (string, error) {
+			return "/", nil
+		}),
+		primaryDg: newDgConfig(dbdClient, func(ctx1 context.Context) (string, error) {
+			passwd, err := primary.PasswordAccessor.Get(ctx1)
+			if err != nil {
+				return "", err
+			}
+			return connect.EZ(primary.User, passwd, primary.Host, strconv.Itoa(primary.Port), primary.Service, false), nil
+		}),
+	}
+
+	t.tasks.AddTask("checkPrerequisites", t.checkPrerequisites)
+	t.tasks.AddTask("removeDataGuardConfig", t.removeDataGuardConfig)
+	t.tasks.AddTask("stopRedoApply", t.stopRedoApply)
+	t.tasks.AddTask("buildDictionary", t.buildDictionary)
+	t.tasks.AddTask("recoverToLogicalStandby", t.recoverToLogicalStandby)
+	t.tasks.AddTask("openLogicalStandby", t.openLogicalStandby)
+	t.tasks.AddTask("startSQLApply", t.startSQLApply)
+
+	return t
+}
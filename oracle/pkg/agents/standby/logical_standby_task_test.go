@@ -0,0 +1,219 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util/task"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecoverToLogicalStandbySql(t *testing.T) {
+	testCases := []struct {
+		dbName  string
+		want    string
+		wantErr bool
+	}{
+		{dbName: "gcloud2", want: "alter database recover to logical standby GCLOUD2"},
+		{dbName: "", want: "alter database recover to logical standby keep identity"},
+		{dbName: "toolongname", wantErr: true},
+		{dbName: "bad;name", wantErr: true},
+	}
+	for _, tc := range testCases {
+		got, err := recoverToLogicalStandbySql(tc.dbName)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("recoverToLogicalStandbySql(%q) got error %v, want error %v", tc.dbName, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("recoverToLogicalStandbySql(%q) got %q, want %q", tc.dbName, got, tc.want)
+		}
+	}
+}
+
+func TestMissingSupplementalLogging(t *testing.T) {
+	testCases := []struct {
+		row  map[string]string
+		want []string
+	}{
+		{
+			row:  map[string]string{"SUPPLEMENTAL_LOG_DATA_PK": "YES", "SUPPLEMENTAL_LOG_DATA_UI": "YES"},
+			want: nil,
+		},
+		{
+			row:  map[string]string{"SUPPLEMENTAL_LOG_DATA_PK": "YES", "SUPPLEMENTAL_LOG_DATA_UI": "NO"},
+			want: []string{"unique index"},
+		},
+		{
+			row:  map[string]string{"SUPPLEMENTAL_LOG_DATA_PK": "NO", "SUPPLEMENTAL_LOG_DATA_UI": "NO"},
+			want: []string{"primary key", "unique index"},
+		},
+	}
+	for _, tc := range testCases {
+		if diff := cmp.Diff(tc.want, missingSupplementalLogging(tc.row)); diff != "" {
+			t.Errorf("missingSupplementalLogging(%v) got unexpected result (-want +got):\n%v", tc.row, diff)
+		}
+	}
+}
+
+func TestCreateLogicalStandbyTask(t *testing.T) {
+	dbdServer := &fakeServer{}
+	secretAccessor := &fakeSecretAccessor{
+		fakeGet: func(ctx context.Context) (string, error) {
+			return "syspwd", nil
+		},
+	}
+	client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+	defer cleanup()
+	ctx := context.Background()
+
+	testCases := []struct {
+		name        string
+		queryToResp map[string][]string
+		wantErr     bool
+		wantSQLs    []string
+		wantBounces int
+		wantTables  []string
+	}{
+		{
+			name: "convert physical standby",
+			queryToResp: map[string][]string{
+				supplementalLoggingSql: {`{"LOG_MODE": "ARCHIVELOG", "SUPPLEMENTAL_LOG_DATA_PK": "YES", "SUPPLEMENTAL_LOG_DATA_UI": "YES"}`},
+				unsupportedTablesSql:   {`{"NAME": "SCOTT.SPATIAL"}`},
+				consts.ListMRPSql:      {`{"PROCESS": "MRP0"}`},
+				consts.CancelMRPSql:    {},
+				databaseRoleSql:        {`{"DATABASE_ROLE": "PHYSICAL STANDBY"}`},
+				buildLogMinerDictSql:   {},
+				"alter database recover to logical standby GCLOUD2": {},
+				openReadWriteSql: {},
+				openResetLogsSql: {},
+				sqlApplyStateSql: {`{"STATE": "SQL APPLY NOT ON"}`},
+				startSQLApplySql: {},
+			},
+			wantSQLs: []string{
+				supplementalLoggingSql,
+				unsupportedTablesSql,
+				consts.ListMRPSql,
+				consts.CancelMRPSql,
+				databaseRoleSql,
+				buildLogMinerDictSql,
+				databaseRoleSql,
+				"alter database recover to logical standby GCLOUD2",
+				openReadWriteSql,
+				openResetLogsSql,
+				sqlApplyStateSql,
+				startSQLApplySql,
+			},
+			wantBounces: 4,
+			wantTables:  []string{"SCOTT.SPATIAL"},
+		},
+		{
+			name: "supplemental logging still missing after adding it",
+			queryToResp: map[string][]string{
+				supplementalLoggingSql:    {`{"LOG_MODE": "ARCHIVELOG", "SUPPLEMENTAL_LOG_DATA_PK": "NO", "SUPPLEMENTAL_LOG_DATA_UI": "NO"}`},
+				addSupplementalLoggingSql: {},
+			},
+			wantErr: true,
+			wantSQLs: []string{
+				supplementalLoggingSql,
+				addSupplementalLoggingSql,
+				supplementalLoggingSql,
+			},
+		},
+		{
+			name: "primary not in archivelog mode",
+			queryToResp: map[string][]string{
+				supplementalLoggingSql: {`{"LOG_MODE": "NOARCHIVELOG", "SUPPLEMENTAL_LOG_DATA_PK": "YES", "SUPPLEMENTAL_LOG_DATA_UI": "YES"}`},
+			},
+			wantErr:  true,
+			wantSQLs: []string{supplementalLoggingSql},
+		},
+		{
+			name: "already converted and applying",
+			queryToResp: map[string][]string{
+				supplementalLoggingSql: {`{"LOG_MODE": "ARCHIVELOG", "SUPPLEMENTAL_LOG_DATA_PK": "YES", "SUPPLEMENTAL_LOG_DATA_UI": "YES"}`},
+				unsupportedTablesSql:   {},
+				consts.ListMRPSql:      {},
+				databaseRoleSql:        {`{"DATABASE_ROLE": "LOGICAL STANDBY"}`},
+				openReadWriteSql:       {`{"OPEN_MODE": "READ WRITE"}`},
+				sqlApplyStateSql:       {`{"STATE": "APPLYING"}`},
+			},
+			wantSQLs: []string{
+				supplementalLoggingSql,
+				unsupportedTablesSql,
+				consts.ListMRPSql,
+				databaseRoleSql,
+				databaseRoleSql,
+				openReadWriteSql,
+				sqlApplyStateSql,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotSQLs []string
+			var gotBounces int
+			run := func(_ context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+				val, ok := tc.queryToResp[req.GetCommands()[0]]
+				if !ok {
+					return nil, errors.New("query failed")
+				}
+				gotSQLs = append(gotSQLs, req.GetCommands()...)
+				return &dbdpb.RunCMDResponse{Msg: val}, nil
+			}
+			dbdServer.fakeRunSQLPlusFormatted = run
+			dbdServer.fakeRunSQLPlus = run
+			dbdServer.fakeRunDataGuard = func(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+				return nil, errors.New("no configuration")
+			}
+			dbdServer.fakeBounceDatabase = func(ctx context.Context, req *dbdpb.BounceDatabaseRequest) (*dbdpb.BounceDatabaseResponse, error) {
+				gotBounces++
+				return &dbdpb.BounceDatabaseResponse{}, nil
+			}
+			lt := newCreateLogicalStandbyTask(
+				ctx,
+				&Primary{
+					Host:             "123.123.123.123",
+					Port:             6021,
+					Service:          "GCLOUD.gke",
+					User:             "sys",
+					PasswordAccessor: secretAccessor,
+				},
+				&Standby{
+					CDBName:      "GCLOUD",
+					DBUniqueName: "gcloud_gke",
+				},
+				"gcloud2",
+				client)
+			err := task.Do(ctx, lt.tasks)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("createLogicalStandbyTask got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantSQLs, gotSQLs); diff != "" {
+				t.Errorf("createLogicalStandbyTask ran unexpected SQLs (-want +got):\n%v", diff)
+			}
+			if gotBounces != tc.wantBounces {
+				t.Errorf("createLogicalStandbyTask bounced the database %d times, want %d", gotBounces, tc.wantBounces)
+			}
+			if diff := cmp.Diff(tc.wantTables, lt.unsupportedTables); !tc.wantErr && diff != "" {
+				t.Errorf("createLogicalStandbyTask got unexpected unsupported tables (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -37,27 +37,34 @@ type promoteStandbyTask struct {
 }
 
 func (task *promoteStandbyTask) removeDataGuardConfig(ctx context.Context) error {
+	return removeStandbyFromDataGuard(ctx, task.primaryDg, task.standbyDg, task.standby.DBUniqueName)
+}
+
+// removeStandbyFromDataGuard removes the standby from the Data Guard broker
+// configuration, or the whole configuration if it is the one created by the
+// operator for this standby only.
+func removeStandbyFromDataGuard(ctx context.Context, primaryDg, standbyDg *dgConfig, standbyUniqueName string) error {
 	// Always try standby config first, so that we do not need password;
 	// No need to remove Data Guard configuration, if they don't exist.
-	if !task.standbyDg.exists(ctx) && !task.primaryDg.exists(ctx) {
+	if !standbyDg.exists(ctx) && !primaryDg.exists(ctx) {
 		return nil
 	}
 	// If it only contains the configurations managed by us, remove configuration.
 	// Otherwise, remove standby database configuration only.
-	members, err := task.primaryDg.members(ctx)
+	members, err := primaryDg.members(ctx)
 	if err != nil {
 		return fmt.Errorf("removeDataGuardConfig: Error while reading DG members: %v", err)
 	}
 
 	if (members.configuration == defaultDGConfigName) &&
-		(members.size() == 1) && members.standbyContains(task.standby.DBUniqueName) {
+		(members.size() == 1) && members.standbyContains(standbyUniqueName) {
 		klog.InfoS("removing Data Guard configuration on primary")
-		if err := task.primaryDg.remove(ctx); err != nil {
+		if err := primaryDg.remove(ctx); err != nil {
 			return fmt.Errorf("removeDataGuardConfig: Error while removing primary Data Guard configuration: %v", err)
 		}
-	} else if members.standbyContains(task.standby.DBUniqueName) {
+	} else if members.standbyContains(standbyUniqueName) {
 		klog.InfoS("removing Data Guard standby database")
-		if err := task.primaryDg.removeStandbyDB(ctx, strings.ToLower(task.standby.DBUniqueName)); err != nil {
+		if err := primaryDg.removeStandbyDB(ctx, strings.ToLower(standbyUniqueName)); err != nil {
 			return fmt.Errorf("removeDataGuardConfig: Error while removing standby Data Guard configuration: %v", err)
 		}
 	}
@@ -0,0 +1,129 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MaxPerformance, MaxAvailability and MaxProtection are the Data Guard
+	// protection modes as v$database reports them.
+	MaxPerformance  = "MAXIMUM PERFORMANCE"
+	MaxAvailability = "MAXIMUM AVAILABILITY"
+	MaxProtection   = "MAXIMUM PROTECTION"
+)
+
+// brokerProtectionModes maps the protection modes to their broker names,
+// from the least to the most strict.
+var brokerProtectionModes = []struct {
+	mode   string
+	broker string
+}{
+	{mode: MaxPerformance, broker: "MaxPerformance"},
+	{mode: MaxAvailability, broker: "MaxAvailability"},
+	{mode: MaxProtection, broker: "MaxProtection"},
+}
+
+// protectionModeRank returns the strictness of a protection mode, -1 if the
+// mode isn't known.
+func protectionModeRank(mode string) int {
+	for i, m := range brokerProtectionModes {
+		if m.mode == mode {
+			return i
+		}
+	}
+	return -1
+}
+
+// protectionModeScripts returns the broker commands setting the protection
+// mode of the configuration. The stricter modes need the standby to receive
+// redo synchronously, the redo transport is set to SYNC before the mode is
+// raised and back to ASYNC after it is lowered to MaxPerformance.
+func protectionModeScripts(standbyUniqueName, mode string) ([]string, error) {
+	rank := protectionModeRank(mode)
+	if rank < 0 {
+		return nil, fmt.Errorf("unsupported protection mode %q", mode)
+	}
+	setMode := fmt.Sprintf("edit configuration set protection mode as %s", brokerProtectionModes[rank].broker)
+	if mode == MaxPerformance {
+		return []string{
+			setMode,
+			fmt.Sprintf("edit database %s set property LogXptMode='ASYNC'", standbyUniqueName),
+		}, nil
+	}
+	return []string{
+		fmt.Sprintf("edit database %s set property LogXptMode='SYNC'", standbyUniqueName),
+		setMode,
+	}, nil
+}
+
+// checkProtectionModePrerequisites checks the standby can run in a mode
+// stricter than MaxPerformance, it needs standby redo logs for the
+// synchronous redo transport, at least as many as the online redo log
+// groups.
+func checkProtectionModePrerequisites(ctx context.Context, dbdClient dbdpb.DatabaseDaemonClient) error {
+	counts, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, dbdClient,
+		"select (select count(*) from v$standby_log) - (select count(*) from v$log) as missing from dual")
+	if err != nil {
+		return fmt.Errorf("failed to count the standby redo logs: %v", err)
+	}
+	if len(counts) != 1 {
+		return fmt.Errorf("failed to count the standby redo logs: got %v", counts)
+	}
+	diff, err := strconv.Atoi(counts[0])
+	if err != nil {
+		return fmt.Errorf("failed to count the standby redo logs: %v", err)
+	}
+	if diff < 0 {
+		return fmt.Errorf("the standby has %d standby redo logs less than online redo log groups, synchronous redo transport needs at least one per group", -diff)
+	}
+	return nil
+}
+
+// SetProtectionMode sets the protection mode of the Data Guard configuration
+// of the standby, it checks the prerequisites of a stricter mode before
+// changing it.
+func SetProtectionMode(ctx context.Context, standbyUniqueName, mode string, dbdClient dbdpb.DatabaseDaemonClient) error {
+	scripts, err := protectionModeScripts(standbyUniqueName, mode)
+	if err != nil {
+		return err
+	}
+	current, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, dbdClient, "select protection_mode from v$database")
+	if err != nil {
+		return fmt.Errorf("failed to read the protection mode: %v", err)
+	}
+	if len(current) == 1 && current[0] == mode {
+		return nil
+	}
+	if len(current) != 1 || protectionModeRank(mode) > protectionModeRank(current[0]) {
+		if err := checkProtectionModePrerequisites(ctx, dbdClient); err != nil {
+			return fmt.Errorf("cannot set protection mode %s: %v", mode, err)
+		}
+	}
+	klog.InfoS("setting the Data Guard protection mode", "current", current, "mode", mode)
+	if resp, err := dbdClient.RunDataGuard(ctx, &dbdpb.RunDataGuardRequest{
+		Target:  "/",
+		Scripts: scripts,
+	}); err != nil {
+		return fmt.Errorf("failed to set protection mode %s: %v, with response: %v", mode, err, resp)
+	}
+	return nil
+}
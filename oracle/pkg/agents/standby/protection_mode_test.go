@@ -0,0 +1,148 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProtectionModeScripts(t *testing.T) {
+	testCases := []struct {
+		mode string
+		want []string
+	}{
+		{
+			mode: MaxPerformance,
+			want: []string{
+				"edit configuration set protection mode as MaxPerformance",
+				"edit database standby_db set property LogXptMode='ASYNC'",
+			},
+		},
+		{
+			mode: MaxAvailability,
+			want: []string{
+				"edit database standby_db set property LogXptMode='SYNC'",
+				"edit configuration set protection mode as MaxAvailability",
+			},
+		},
+		{
+			mode: MaxProtection,
+			want: []string{
+				"edit database standby_db set property LogXptMode='SYNC'",
+				"edit configuration set protection mode as MaxProtection",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			got, err := protectionModeScripts("standby_db", tc.mode)
+			if err != nil {
+				t.Fatalf("protectionModeScripts failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("protectionModeScripts got unexpected scripts (-want +got):\n%v", diff)
+			}
+		})
+	}
+	if _, err := protectionModeScripts("standby_db", "MaxAvailability"); err == nil {
+		t.Errorf("protectionModeScripts of an unknown mode succeeded, want error")
+	}
+}
+
+func TestSetProtectionMode(t *testing.T) {
+	testCases := []struct {
+		name          string
+		current       string
+		mode          string
+		missingLogs   string
+		wantErr       bool
+		wantPrereqs   bool
+		wantDataGuard bool
+	}{
+		{
+			name:          "raise with standby redo logs",
+			current:       MaxPerformance,
+			mode:          MaxAvailability,
+			missingLogs:   "1",
+			wantPrereqs:   true,
+			wantDataGuard: true,
+		},
+		{
+			name:        "raise without enough standby redo logs",
+			current:     MaxPerformance,
+			mode:        MaxProtection,
+			missingLogs: "-2",
+			wantErr:     true,
+			wantPrereqs: true,
+		},
+		{
+			name:          "lower without checks",
+			current:       MaxProtection,
+			mode:          MaxPerformance,
+			missingLogs:   "-2",
+			wantDataGuard: true,
+		},
+		{
+			name:    "unchanged",
+			current: MaxAvailability,
+			mode:    MaxAvailability,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPrereqs, gotDataGuard bool
+			dbdServer := &fakeServer{
+				fakeRunSQLPlusFormatted: func(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+					query := req.GetCommands()[0]
+					switch {
+					case query == "select protection_mode from v$database":
+						return &dbdpb.RunCMDResponse{Msg: []string{fmt.Sprintf(`{"PROTECTION_MODE":%q}`, tc.current)}}, nil
+					case strings.Contains(query, "v$standby_log"):
+						gotPrereqs = true
+						return &dbdpb.RunCMDResponse{Msg: []string{fmt.Sprintf(`{"MISSING":%q}`, tc.missingLogs)}}, nil
+					}
+					return nil, fmt.Errorf("unexpected query %q", query)
+				},
+				fakeRunDataGuard: func(ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+					gotDataGuard = true
+					want, _ := protectionModeScripts("standby_db", tc.mode)
+					if diff := cmp.Diff(want, req.GetScripts()); diff != "" {
+						t.Errorf("SetProtectionMode ran unexpected broker commands (-want +got):\n%v", diff)
+					}
+					return &dbdpb.RunDataGuardResponse{}, nil
+				},
+			}
+			client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+			defer cleanup()
+
+			err := SetProtectionMode(context.Background(), "standby_db", tc.mode, client)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("SetProtectionMode got error %v, want error %v", err, tc.wantErr)
+			}
+			if gotPrereqs != tc.wantPrereqs {
+				t.Errorf("SetProtectionMode checked the prerequisites %v, want %v", gotPrereqs, tc.wantPrereqs)
+			}
+			if gotDataGuard != tc.wantDataGuard {
+				t.Errorf("SetProtectionMode ran broker commands %v, want %v", gotDataGuard, tc.wantDataGuard)
+			}
+		})
+	}
+}
@@ -339,6 +339,19 @@ func (task *setUpDataGuardTask) ensureDGConfigPrimaryMatch(ctx context.Context)
 	return nil
 }
 
+func (task *setUpDataGuardTask) ensureRedoTransport(ctx context.Context) error {
+	resp, err := task.dbdClient.ConfigureRedoTransport(ctx, &dbdpb.ConfigureRedoTransportRequest{
+		StandbyDbUniqueName: task.standby.DBUniqueName,
+		Compression:         task.standby.RedoCompression,
+		Encryption:          task.standby.RedoEncryption,
+	})
+	if err != nil {
+		return fmt.Errorf("ensureRedoTransport: Error while configuring redo transport: %v", err)
+	}
+	klog.InfoS("configured redo transport", "resp", resp)
+	return nil
+}
+
 func (task *setUpDataGuardTask) downloadPasswordFile(ctx context.Context) error {
 	dbName, err := fetchAndParseSingleColumnMultiRowQueries(ctx, task.primary, task.dbdClient, "select value from V$parameter where name='db_name'")
 	if err != nil {
@@ -380,6 +393,7 @@ func newSetUpStandbyTask(ctx context.Context, primary *Primary, standby *Standby
 	t.tasks.AddTask("ensureDGConfigExists", t.ensureDGConfigExists)
 	t.tasks.AddTask("ensureDGConfigStandbyMatch", t.ensureDGConfigStandbyMatch)
 	t.tasks.AddTask("ensureDGConfigPrimaryMatch", t.ensureDGConfigPrimaryMatch)
+	t.tasks.AddTask("ensureRedoTransport", t.ensureRedoTransport)
 
 	return t
 }
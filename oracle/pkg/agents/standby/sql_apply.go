@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"k8s.io/klog/v2"
+)
+
+const (
+	sqlApplyStateSql = "select state from v$logstdby_state"
+	startSQLApplySql = "alter database start logical standby apply immediate"
+	stopSQLApplySql  = "alter database stop logical standby apply"
+	abortSQLApplySql = "alter database abort logical standby apply"
+	sqlApplyOffState = "SQL APPLY NOT ON"
+	skipStatementDML = "DML"
+	skipSchemaDDL    = "SCHEMA_DDL"
+	skipNonSchemaDDL = "NON_SCHEMA_DDL"
+)
+
+// SkipRule is a SQL Apply skip rule, the statements of the Statement type
+// on the objects matching Schema and Object aren't applied on the logical
+// standby. Schema and Object accept the % wildcard, they are ignored by
+// NON_SCHEMA_DDL rules.
+type SkipRule struct {
+	// Statement is DML, SCHEMA_DDL or NON_SCHEMA_DDL.
+	Statement string
+	Schema    string
+	Object    string
+}
+
+// skipRuleSql returns the dbms_logstdby call adding or removing a skip rule.
+func skipRuleSql(rule SkipRule, unskip bool) (string, error) {
+	proc := "skip"
+	if unskip {
+		proc = "unskip"
+	}
+	stmt := strings.ToUpper(rule.Statement)
+	switch stmt {
+	case skipNonSchemaDDL:
+		return fmt.Sprintf("begin dbms_logstdby.%s(stmt => '%s', schema_name => null, object_name => null); end;", proc, stmt), nil
+	case skipStatementDML, skipSchemaDDL:
+		if rule.Schema == "" || rule.Object == "" {
+			return "", fmt.Errorf("skip rule %s needs a schema and an object", stmt)
+		}
+		return fmt.Sprintf("begin dbms_logstdby.%s(stmt => '%s', schema_name => '%s', object_name => '%s'); end;",
+			proc, stmt, sqlq.StringParam(strings.ToUpper(rule.Schema)), sqlq.StringParam(strings.ToUpper(rule.Object))), nil
+	}
+	return "", fmt.Errorf("unsupported skip rule statement %q", rule.Statement)
+}
+
+// sqlApplyRunning returns whether SQL Apply runs on the logical standby.
+func sqlApplyRunning(ctx context.Context, dbdClient dbdpb.DatabaseDaemonClient) (bool, error) {
+	states, err := fetchAndParseSingleColumnMultiRowQueriesLocal(ctx, dbdClient, sqlApplyStateSql)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the SQL Apply state: %v", err)
+	}
+	return len(states) == 1 && states[0] != sqlApplyOffState, nil
+}
+
+// StartSQLApply starts SQL Apply on the logical standby if it isn't running,
+// it applies the redo as it is received.
+func StartSQLApply(ctx context.Context, dbdClient dbdpb.DatabaseDaemonClient) error {
+	running, err := sqlApplyRunning(ctx, dbdClient)
+	if err != nil {
+		return err
+	}
+	if running {
+		return nil
+	}
+	klog.InfoS("starting SQL Apply")
+	if _, err := dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{startSQLApplySql}}); err != nil {
+		return fmt.Errorf("failed to start SQL Apply: %v", err)
+	}
+	return nil
+}
+
+// StopSQLApply stops SQL Apply on the logical standby if it is running. The
+// stop waits for the transactions being applied, abort rolls them back.
+func StopSQLApply(ctx context.Context, abort bool, dbdClient dbdpb.DatabaseDaemonClient) error {
+	running, err := sqlApplyRunning(ctx, dbdClient)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return nil
+	}
+	stop := stopSQLApplySql
+	if abort {
+		stop = abortSQLApplySql
+	}
+	klog.InfoS("stopping SQL Apply", "abort", abort)
+	if _, err := dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{stop}}); err != nil {
+		return fmt.Errorf("failed to stop SQL Apply: %v", err)
+	}
+	return nil
+}
+
+// UpdateSkipRules adds and removes SQL Apply skip rules. The rules can only be
+// changed while SQL Apply is stopped, it is restarted afterwards if it was
+// running.
+func UpdateSkipRules(ctx context.Context, skip, unskip []SkipRule, dbdClient dbdpb.DatabaseDaemonClient) error {
+	var cmds []string
+	for _, rule := range unskip {
+		cmd, err := skipRuleSql(rule, true)
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, rule := range skip {
+		cmd, err := skipRuleSql(rule, false)
+		if err != nil {
+			return err
+		}
+		cmds = append(cmds, cmd)
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	running, err := sqlApplyRunning(ctx, dbdClient)
+	if err != nil {
+		return err
+	}
+	if running {
+		if err := StopSQLApply(ctx, false, dbdClient); err != nil {
+			return err
+		}
+	}
+	klog.InfoS("updating SQL Apply skip rules", "skip", skip, "unskip", unskip)
+	if _, err := dbdClient.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds}); err != nil {
+		return fmt.Errorf("failed to update SQL Apply skip rules: %v", err)
+	}
+	if running {
+		return StartSQLApply(ctx, dbdClient)
+	}
+	return nil
+}
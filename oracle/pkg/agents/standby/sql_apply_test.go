@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standby
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSkipRuleSql(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    SkipRule
+		unskip  bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "skip dml",
+			rule: SkipRule{Statement: "DML", Schema: "hr", Object: "audit%"},
+			want: "begin dbms_logstdby.skip(stmt => 'DML', schema_name => 'HR', object_name => 'AUDIT%'); end;",
+		},
+		{
+			name:   "unskip schema ddl",
+			rule:   SkipRule{Statement: "schema_ddl", Schema: "HR", Object: "%"},
+			unskip: true,
+			want:   "begin dbms_logstdby.unskip(stmt => 'SCHEMA_DDL', schema_name => 'HR', object_name => '%'); end;",
+		},
+		{
+			name: "skip non schema ddl",
+			rule: SkipRule{Statement: "NON_SCHEMA_DDL", Schema: "ignored"},
+			want: "begin dbms_logstdby.skip(stmt => 'NON_SCHEMA_DDL', schema_name => null, object_name => null); end;",
+		},
+		{
+			name: "quotes escaped",
+			rule: SkipRule{Statement: "DML", Schema: "HR", Object: "A'B"},
+			want: "begin dbms_logstdby.skip(stmt => 'DML', schema_name => 'HR', object_name => 'A''B'); end;",
+		},
+		{
+			name:    "missing object",
+			rule:    SkipRule{Statement: "DML", Schema: "HR"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported statement",
+			rule:    SkipRule{Statement: "GRANT"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := skipRuleSql(tc.rule, tc.unskip)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("skipRuleSql(%v) got error %v, want error %v", tc.rule, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("skipRuleSql(%v) got %q, want %q", tc.rule, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSQLApplyControl(t *testing.T) {
+	rule := SkipRule{Statement: "DML", Schema: "HR", Object: "AUDIT"}
+	skip := "begin dbms_logstdby.skip(stmt => 'DML', schema_name => 'HR', object_name => 'AUDIT'); end;"
+	testCases := []struct {
+		name     string
+		state    string
+		call     func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error
+		wantSQLs []string
+	}{
+		{
+			name:  "start stopped apply",
+			state: sqlApplyOffState,
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return StartSQLApply(ctx, client)
+			},
+			wantSQLs: []string{startSQLApplySql},
+		},
+		{
+			name:  "start running apply",
+			state: "IDLE",
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return StartSQLApply(ctx, client)
+			},
+		},
+		{
+			name:  "stop running apply",
+			state: "APPLYING",
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return StopSQLApply(ctx, false, client)
+			},
+			wantSQLs: []string{stopSQLApplySql},
+		},
+		{
+			name:  "abort running apply",
+			state: "APPLYING",
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return StopSQLApply(ctx, true, client)
+			},
+			wantSQLs: []string{abortSQLApplySql},
+		},
+		{
+			name:  "skip rules restart running apply",
+			state: "APPLYING",
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return UpdateSkipRules(ctx, []SkipRule{rule}, nil, client)
+			},
+			wantSQLs: []string{stopSQLApplySql, skip, startSQLApplySql},
+		},
+		{
+			name:  "skip rules leave stopped apply",
+			state: sqlApplyOffState,
+			call: func(ctx context.Context, client dbdpb.DatabaseDaemonClient) error {
+				return UpdateSkipRules(ctx, []SkipRule{rule}, nil, client)
+			},
+			wantSQLs: []string{skip},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotSQLs []string
+			state := tc.state
+			dbdServer := &fakeServer{
+				fakeRunSQLPlusFormatted: func(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+					if req.GetCommands()[0] != sqlApplyStateSql {
+						return nil, errors.New("query failed")
+					}
+					return &dbdpb.RunCMDResponse{Msg: []string{`{"STATE": "` + state + `"}`}}, nil
+				},
+				fakeRunSQLPlus: func(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest) (*dbdpb.RunCMDResponse, error) {
+					gotSQLs = append(gotSQLs, req.GetCommands()...)
+					switch req.GetCommands()[0] {
+					case stopSQLApplySql, abortSQLApplySql:
+						state = sqlApplyOffState
+					case startSQLApplySql:
+						state = "INITIALIZING"
+					}
+					return &dbdpb.RunCMDResponse{}, nil
+				},
+			}
+			client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
+			defer cleanup()
+
+			if err := tc.call(context.Background(), client); err != nil {
+				t.Fatalf("%s failed: %v", tc.name, err)
+			}
+			if diff := cmp.Diff(tc.wantSQLs, gotSQLs); diff != "" {
+				t.Errorf("%s ran unexpected SQLs (-want +got):\n%v", tc.name, diff)
+			}
+		})
+	}
+}
@@ -61,6 +61,11 @@ type Standby struct {
 	Port         int
 	LogDiskSize  int64
 	Version      string
+	// RedoCompression compresses the redo shipped to the standby.
+	RedoCompression bool
+	// RedoEncryption requires native network encryption for the redo
+	// shipped to the standby.
+	RedoEncryption bool
 }
 
 // dgMembers describes members in DG configuration.
@@ -135,6 +140,17 @@ func PromoteStandby(ctx context.Context, primary *Primary, standby *Standby, dbd
 	return task.Do(ctx, t.tasks)
 }
 
+// CreateLogicalStandby converts a physical standby to a logical standby
+// maintained by SQL Apply, dbName is the new name of the standby database. It
+// returns the primary tables SQL Apply does not maintain.
+func CreateLogicalStandby(ctx context.Context, primary *Primary, standby *Standby, dbName string, dbdClient dbdpb.DatabaseDaemonClient) ([]string, error) {
+	t := newCreateLogicalStandbyTask(ctx, primary, standby, dbName, dbdClient)
+	if err := task.Do(ctx, t.tasks); err != nil {
+		return nil, err
+	}
+	return t.unsupportedTables, nil
+}
+
 // BootstrapStandby converts promoted standby to standard El Carro Oracle instance.
 func BootstrapStandby(ctx context.Context, dbdClient dbdpb.DatabaseDaemonClient) error {
 	t := newBootstrapStandbyTask(ctx, dbdClient)
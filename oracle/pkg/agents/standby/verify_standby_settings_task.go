@@ -48,6 +48,7 @@ const (
 var supportedDBVersionToKeyWord = map[string]string{
 	"12.2": "12c Enterprise",
 	"19.3": "19c Enterprise",
+	"21.3": "21c Enterprise",
 }
 
 type verifyStandbySettingsTask struct {
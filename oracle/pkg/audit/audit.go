@@ -0,0 +1,396 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records the actions of the operator. Every event the
+// controllers emit is annotated with who changed the object and the
+// outcome, and the audited events can also be appended to a log on a
+// mounted volume or in GCS for compliance. The audit log is written in the
+// background, so a slow destination never holds up a reconcile.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
+)
+
+const (
+	// ResultSucceeded is the result of the actions reported by normal events.
+	ResultSucceeded = "Succeeded"
+	// ResultFailed is the result of the actions reported by warning events.
+	ResultFailed = "Failed"
+
+	annotationPrefix = "audit.oracle.db.anthosapis.com/"
+	// ActorAnnotation is the event annotation of the field manager which
+	// last changed the object the action was taken on.
+	ActorAnnotation = annotationPrefix + "actor"
+	// ComponentAnnotation is the event annotation of the controller which
+	// took the action.
+	ComponentAnnotation = annotationPrefix + "component"
+	// ResultAnnotation is the event annotation of the action outcome.
+	ResultAnnotation = annotationPrefix + "result"
+
+	sinkTimeout = 30 * time.Second
+
+	// DefaultBufferSize is how many audit records can wait to be written,
+	// more are dropped.
+	DefaultBufferSize = 1000
+	// DefaultFlushInterval is how often the waiting audit records are
+	// written.
+	DefaultFlushInterval = 10 * time.Second
+	// maxBatchSize is how many waiting audit records are written before the
+	// flush interval is up.
+	maxBatchSize = 200
+)
+
+// Record is an entry of the audit log.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Component string    `json:"component"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	// Action is the reason of the event, e.g. BackupSucceeded.
+	Action string `json:"action"`
+	Result string `json:"result"`
+	Actor  string `json:"actor,omitempty"`
+	// Message is the message of the event.
+	Message string `json:"message"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	// Write stores a batch of records, oldest first.
+	Write(ctx context.Context, recs []Record) error
+}
+
+// FileSink appends the records as JSON lines to a file, e.g. on a PVC.
+type FileSink struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Write appends recs to the file, the file is created if it doesn't exist.
+func (s *FileSink) Write(ctx context.Context, recs []Record) error {
+	lines, err := jsonLines(recs)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %v", s.Path, err)
+	}
+	if _, err := f.Write(lines); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log %s: %v", s.Path, err)
+	}
+	return f.Close()
+}
+
+// jsonLines returns recs as JSON lines.
+func jsonLines(recs []Record) ([]byte, error) {
+	var lines []byte
+	for _, rec := range recs {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	return lines, nil
+}
+
+// GCSSink uploads each batch of records as a JSON lines object under a GCS
+// directory, GCS objects can't be appended to. The objects are named after
+// the time of their first record so that listing the directory returns
+// them in order.
+type GCSSink struct {
+	Dir string
+	GCS util.GCSUtil
+}
+
+// objectPath returns the GCS path of the recs object.
+func (s *GCSSink) objectPath(recs []Record) string {
+	first := recs[0].Time.UTC()
+	return fmt.Sprintf("%s/%s/%s-%d.jsonl", strings.TrimSuffix(s.Dir, "/"), first.Format("2006/01/02"), first.Format("150405.000000000"), len(recs))
+}
+
+// Write uploads recs to GCS as one object.
+func (s *GCSSink) Write(ctx context.Context, recs []Record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	content, err := jsonLines(recs)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile("", "audit-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.GCS.UploadFile(ctx, s.objectPath(recs), f.Name(), "application/x-ndjson")
+}
+
+// multiSink writes the records to all its sinks.
+type multiSink []Sink
+
+func (m multiSink) Write(ctx context.Context, recs []Record) error {
+	var errs []string
+	for _, s := range m {
+		if err := s.Write(ctx, recs); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to write audit records: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// NewSink returns a sink appending the records to the file at path and
+// uploading them under gcsPath, either of which can be empty. It returns
+// nil if both are.
+func NewSink(path, gcsPath string) Sink {
+	var sinks multiSink
+	if path != "" {
+		sinks = append(sinks, &FileSink{Path: path})
+	}
+	if gcsPath != "" {
+		sinks = append(sinks, &GCSSink{Dir: gcsPath, GCS: &util.GCSUtilImpl{}})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return sinks
+}
+
+// Writer writes the audit records of the event recorders to a sink from a
+// single goroutine. Recording an event only queues its record, the queued
+// records are written in batches every flush interval, or as soon as a
+// batch is full. Records which don't fit in the queue are dropped and
+// counted, a slow or unreachable sink never blocks the recorders.
+type Writer struct {
+	Sink          Sink
+	FlushInterval time.Duration
+	Log           logr.Logger
+
+	records chan Record
+	dropped int64
+}
+
+// NewWriter returns a writer to sink queuing up to bufferSize records.
+func NewWriter(sink Sink, bufferSize int, flushInterval time.Duration, log logr.Logger) *Writer {
+	return &Writer{Sink: sink, FlushInterval: flushInterval, Log: log, records: make(chan Record, bufferSize)}
+}
+
+// Add queues rec to be written, rec is dropped if the queue is full.
+func (w *Writer) Add(rec Record) {
+	select {
+	case w.records <- rec:
+	default:
+		atomic.AddInt64(&w.dropped, 1)
+	}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (w *Writer) Dropped() int64 {
+	return atomic.LoadInt64(&w.dropped)
+}
+
+// Start writes the queued records until ctx is done, then writes the
+// records left in the queue. It's run by the controller manager.
+func (w *Writer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.FlushInterval)
+	defer ticker.Stop()
+	var batch []Record
+	var reported int64
+	flush := func() {
+		if dropped := w.Dropped(); dropped > reported {
+			w.Log.Info("dropped audit records, the queue was full", "dropped", dropped-reported, "total", dropped)
+			reported = dropped
+		}
+		if len(batch) == 0 {
+			return
+		}
+		sinkCtx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+		defer cancel()
+		if err := w.Sink.Write(sinkCtx, batch); err != nil {
+			w.Log.Error(err, "failed to write audit records", "records", len(batch))
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case rec := <-w.records:
+			if batch = append(batch, rec); len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case rec := <-w.records:
+					batch = append(batch, rec)
+				default:
+					flush()
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, the records are written by every
+// operator replica which records events.
+func (w *Writer) NeedLeaderElection() bool {
+	return false
+}
+
+// EventRecorder annotates the events of a controller with the audit fields
+// and queues the audited ones to a writer.
+type EventRecorder struct {
+	record.EventRecorder
+
+	Component string
+	// Writer writes the audited events, they are only annotated if nil.
+	Writer *Writer
+	// Actions are the event reasons written to the audit log, all are if
+	// empty.
+	Actions map[string]bool
+
+	now func() time.Time
+}
+
+// NewEventRecorder wraps the events recorder of component.
+func NewEventRecorder(events record.EventRecorder, component string, writer *Writer, actions []string) *EventRecorder {
+	r := &EventRecorder{EventRecorder: events, Component: component, Writer: writer, now: time.Now}
+	for _, a := range actions {
+		if a = strings.TrimSpace(a); a != "" {
+			if r.Actions == nil {
+				r.Actions = make(map[string]bool)
+			}
+			r.Actions[a] = true
+		}
+	}
+	return r
+}
+
+// Event records an event and its audit record.
+func (r *EventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+// Eventf records an event and its audit record.
+func (r *EventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+// AnnotatedEventf records an event with the audit annotations added to
+// annotations and queues its audit record to the writer.
+func (r *EventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	rec := r.newRecord(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+	merged := map[string]string{
+		ComponentAnnotation: rec.Component,
+		ResultAnnotation:    rec.Result,
+	}
+	if rec.Actor != "" {
+		merged[ActorAnnotation] = rec.Actor
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	r.EventRecorder.AnnotatedEventf(object, merged, eventtype, reason, "%s", rec.Message)
+
+	if r.Writer == nil || (len(r.Actions) > 0 && !r.Actions[reason]) {
+		return
+	}
+	r.Writer.Add(rec)
+}
+
+// newRecord returns the audit record of an event.
+func (r *EventRecorder) newRecord(object runtime.Object, eventtype, reason, message string) Record {
+	rec := Record{
+		Time:      r.now(),
+		Component: r.Component,
+		Kind:      kind(object),
+		Action:    reason,
+		Result:    ResultSucceeded,
+		Message:   message,
+	}
+	if eventtype == corev1.EventTypeWarning {
+		rec.Result = ResultFailed
+	}
+	if obj, err := meta.Accessor(object); err == nil {
+		rec.Namespace = obj.GetNamespace()
+		rec.Name = obj.GetName()
+		rec.Actor = actor(obj)
+	}
+	return rec
+}
+
+// kind returns the kind of object, objects read with a typed client have
+// no type meta.
+func kind(object runtime.Object) string {
+	if k := object.GetObjectKind().GroupVersionKind().Kind; k != "" {
+		return k
+	}
+	t := reflect.TypeOf(object)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// actor returns the field manager of the last change to the object other
+// than to its status, which is who asked for the action.
+func actor(obj metav1.Object) string {
+	var last metav1.ManagedFieldsEntry
+	for _, f := range obj.GetManagedFields() {
+		if f.Subresource != "" || f.Time == nil {
+			continue
+		}
+		if last.Time == nil || !f.Time.Before(last.Time) {
+			last = f
+		}
+	}
+	return last.Manager
+}
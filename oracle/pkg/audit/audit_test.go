@@ -0,0 +1,342 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/api/v1alpha1"
+)
+
+type fakeEvents struct {
+	annotations []map[string]string
+	messages    []string
+}
+
+func (f *fakeEvents) Event(object runtime.Object, eventtype, reason, message string) {}
+
+func (f *fakeEvents) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+}
+
+func (f *fakeEvents) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	f.annotations = append(f.annotations, annotations)
+	f.messages = append(f.messages, fmt.Sprintf(messageFmt, args...))
+}
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Record
+	err     error
+}
+
+func (s *memorySink) Write(ctx context.Context, recs []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, recs)
+	return s.err
+}
+
+// queued returns the records queued to w.
+func queued(w *Writer) []Record {
+	var recs []Record
+	for {
+		select {
+		case rec := <-w.records:
+			recs = append(recs, rec)
+		default:
+			return recs
+		}
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	now := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	specChange := metav1.NewTime(now.Add(-time.Hour))
+	statusChange := metav1.NewTime(now.Add(-time.Minute))
+	managed := metav1.ObjectMeta{
+		Namespace: "db",
+		Name:      "mydb",
+		ManagedFields: []metav1.ManagedFieldsEntry{
+			{Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate, Time: &specChange},
+			{Manager: "manager", Operation: metav1.ManagedFieldsOperationUpdate, Time: &statusChange, Subresource: "status"},
+		},
+	}
+	tests := []struct {
+		name      string
+		object    runtime.Object
+		eventtype string
+		reason    string
+		message   string
+		actions   []string
+		want      *Record
+	}{
+		{
+			name:      "backup",
+			object:    &v1alpha1.Backup{ObjectMeta: managed},
+			eventtype: corev1.EventTypeNormal,
+			reason:    "BackupSucceeded",
+			message:   "Backup mydb-20261016 completed",
+			want: &Record{Time: now, Component: "backup-controller", Kind: "Backup", Namespace: "db", Name: "mydb", Action: "BackupSucceeded",
+				Result: ResultSucceeded, Actor: "kubectl-client-side-apply", Message: "Backup mydb-20261016 completed"},
+		},
+		{
+			name:      "failed restore",
+			object:    &v1alpha1.Instance{ObjectMeta: managed},
+			eventtype: corev1.EventTypeWarning,
+			reason:    "RestoreFailed",
+			message:   "Failed to restore: ORA-19505",
+			want: &Record{Time: now, Component: "backup-controller", Kind: "Instance", Namespace: "db", Name: "mydb", Action: "RestoreFailed",
+				Result: ResultFailed, Actor: "kubectl-client-side-apply", Message: "Failed to restore: ORA-19505"},
+		},
+		{
+			name:      "user change without managed fields",
+			object:    &v1alpha1.Database{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "pdb1"}},
+			eventtype: corev1.EventTypeNormal,
+			reason:    "SyncedUsers",
+			message:   "Users synced",
+			actions:   []string{"SyncedUsers", " ParameterUpdated"},
+			want: &Record{Time: now, Component: "backup-controller", Kind: "Database", Namespace: "db", Name: "pdb1", Action: "SyncedUsers",
+				Result: ResultSucceeded, Message: "Users synced"},
+		},
+		{
+			name:      "not audited",
+			object:    &v1alpha1.Instance{ObjectMeta: managed},
+			eventtype: corev1.EventTypeNormal,
+			reason:    "DiskUsageNormal",
+			message:   "No mount is full",
+			actions:   []string{"SyncedUsers"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events := &fakeEvents{}
+			w := NewWriter(&memorySink{}, 10, time.Hour, logr.Discard())
+			r := NewEventRecorder(events, "backup-controller", w, tc.actions)
+			r.now = func() time.Time { return now }
+
+			r.Event(tc.object, tc.eventtype, tc.reason, tc.message)
+
+			if len(events.messages) != 1 || events.messages[0] != tc.message {
+				t.Fatalf("Event recorded %q, want %q", events.messages, tc.message)
+			}
+			wantResult := ResultSucceeded
+			if tc.eventtype == corev1.EventTypeWarning {
+				wantResult = ResultFailed
+			}
+			if got := events.annotations[0]; got[ComponentAnnotation] != "backup-controller" || got[ResultAnnotation] != wantResult {
+				t.Errorf("Event annotations got %v, want the component and result %s", got, wantResult)
+			}
+			recs := queued(w)
+			if tc.want == nil {
+				if len(recs) != 0 {
+					t.Errorf("Event queued %v, want no audit record", recs)
+				}
+				return
+			}
+			if len(recs) != 1 {
+				t.Fatalf("Event queued %d audit records, want 1", len(recs))
+			}
+			if diff := cmp.Diff(*tc.want, recs[0]); diff != "" {
+				t.Errorf("Event queued unexpected audit record (-want +got):\n%v", diff)
+			}
+			if got := events.annotations[0][ActorAnnotation]; got != tc.want.Actor {
+				t.Errorf("Event actor annotation got %q, want %q", got, tc.want.Actor)
+			}
+		})
+	}
+}
+
+func TestEventRecorderQueueFull(t *testing.T) {
+	events := &fakeEvents{}
+	// The writer isn't started, nothing drains its queue.
+	w := NewWriter(&memorySink{}, 2, time.Hour, logr.Discard())
+	r := NewEventRecorder(events, "instance-controller", w, nil)
+
+	for i := 0; i < 5; i++ {
+		r.Eventf(&v1alpha1.Instance{}, corev1.EventTypeNormal, "ParameterUpdated", "Set %s to %d", "processes", 300+i)
+	}
+
+	if len(events.messages) != 5 {
+		t.Errorf("Eventf recorded %d events, want all 5 despite the full queue", len(events.messages))
+	}
+	if got := w.Dropped(); got != 3 {
+		t.Errorf("Dropped got %d, want the 3 records which didn't fit in the queue", got)
+	}
+	if recs := queued(w); len(recs) != 2 || recs[0].Message != "Set processes to 300" {
+		t.Errorf("Eventf queued %v, want the first 2 records", recs)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	sink := &memorySink{err: errors.New("disk full")}
+	w := NewWriter(sink, maxBatchSize+10, time.Hour, logr.Discard())
+	var want []Record
+	for i := 0; i < maxBatchSize+5; i++ {
+		rec := Record{Time: time.Date(2026, 10, 16, 2, 0, i, 0, time.UTC), Name: "mydb", Action: "ParameterUpdated"}
+		w.Add(rec)
+		want = append(want, rec)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	// A full batch is written without waiting for the flush interval.
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		sink.mu.Lock()
+		n := len(sink.batches)
+		sink.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("Start wrote no batch of %d records before the flush interval", maxBatchSize)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// The sink error is only logged, the rest of the queue is written on
+	// shutdown.
+	var got []Record
+	for _, batch := range sink.batches {
+		got = append(got, batch...)
+	}
+	if len(sink.batches) != 2 || len(sink.batches[0]) != maxBatchSize {
+		t.Errorf("Start wrote batches of %d records, want a full batch and the rest", len(sink.batches))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Start wrote unexpected records (-want +got):\n%v", diff)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink := NewSink(path, "")
+	recs := []Record{
+		{Time: time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), Kind: "Backup", Name: "b1", Action: "BackupSucceeded", Result: ResultSucceeded},
+		{Time: time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC), Kind: "Instance", Name: "mydb", Action: "RestoreFailed", Result: ResultFailed},
+	}
+	for _, rec := range recs {
+		if err := sink.Write(context.Background(), []Record{rec}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open the audit log: %v", err)
+	}
+	defer f.Close()
+	var got []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("failed to parse audit log line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, rec)
+	}
+	if diff := cmp.Diff(recs, got); diff != "" {
+		t.Errorf("audit log got unexpected records (-want +got):\n%v", diff)
+	}
+}
+
+type fakeGCSUtil struct {
+	uploads map[string]string
+}
+
+func (g *fakeGCSUtil) Download(ctx context.Context, gcsPath string) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) Delete(ctx context.Context, gcsPath string) error {
+	return errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) Size(ctx context.Context, gcsPath string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (g *fakeGCSUtil) UploadFile(ctx context.Context, gcsPath, filePath, contentType string) error {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	g.uploads[gcsPath] = string(content)
+	return nil
+}
+
+func (g *fakeGCSUtil) SplitURI(url string) (string, string, error) {
+	return "", "", errors.New("not implemented")
+}
+
+func TestGCSSink(t *testing.T) {
+	gcs := &fakeGCSUtil{uploads: make(map[string]string)}
+	sink := &GCSSink{Dir: "gs://bucket/audit/", GCS: gcs}
+	recs := []Record{
+		{Time: time.Date(2026, 10, 16, 2, 0, 0, 5, time.UTC), Namespace: "db", Name: "mydb", Action: "BackupSucceeded", Result: ResultSucceeded},
+		{Time: time.Date(2026, 10, 16, 2, 0, 3, 0, time.UTC), Namespace: "db", Name: "mydb", Action: "RestoreFailed", Result: ResultFailed},
+	}
+
+	if err := sink.Write(context.Background(), recs); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	content, ok := gcs.uploads["gs://bucket/audit/2026/10/16/020000.000000005-2.jsonl"]
+	if !ok || len(gcs.uploads) != 1 {
+		t.Fatalf("Write uploaded %v, want one object named after its first record time", gcs.uploads)
+	}
+	var got []Record
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("failed to parse the uploaded record %q: %v", scanner.Text(), err)
+		}
+		got = append(got, rec)
+	}
+	if diff := cmp.Diff(recs, got); diff != "" {
+		t.Errorf("Write uploaded unexpected records (-want +got):\n%v", diff)
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	if s := NewSink("", ""); s != nil {
+		t.Errorf("NewSink without destinations got %v, want nil", s)
+	}
+	if s, ok := NewSink("/audit/audit.log", "gs://bucket/audit").(multiSink); !ok || len(s) != 2 {
+		t.Errorf("NewSink got %v, want a file and a GCS sink", s)
+	}
+}
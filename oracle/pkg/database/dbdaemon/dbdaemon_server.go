@@ -18,10 +18,12 @@ package dbdaemon
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
@@ -58,10 +60,6 @@ import (
 	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
 )
 
-const (
-	listenerDir = "/u02/app/oracle/oraconfig/network"
-)
-
 var (
 	oraDataDir = "/u02/app/oracle/oradata"
 
@@ -82,7 +80,7 @@ type dbdaemon interface {
 	setDatabaseUpgradeMode(ctx context.Context) error
 	openPDBs(ctx context.Context) error
 	runSQL(context.Context, []string, bool, bool, oracleDatabase) ([]string, error)
-	runQuery(context.Context, []string, oracleDatabase) ([]string, error)
+	runQuery(context.Context, []string, bool, oracleDatabase) ([]string, error)
 }
 
 // DB is a wrapper around database/sql.DB database handle.
@@ -104,16 +102,19 @@ type Server struct {
 	lroServer      *lro.Server
 	syncJobs       *syncJobs
 	gcsUtil        util.GCSUtil
+	license        *licenseState
+	operationLogs  *operationLogs
 }
 
 // Remove pdbConnStr from String(), as that may contain the pdb user/password
 // Remove UnimplementedDatabaseDaemonServer field to improve logs for better readability
+// Passwords are redacted from the other fields as well.
 func (s Server) String() string {
 	pdbConnStr := s.pdbConnStr
 	if pdbConnStr != "" {
-		pdbConnStr = "<REDACTED>"
+		pdbConnStr = redacted
 	}
-	return fmt.Sprintf("{hostName=%q, database=%+v, databaseSid=%+v, databaseHome=%q, pdbConnStr=%q}", s.hostName, s.database, s.databaseSid, s.databaseHome, pdbConnStr)
+	return redactPasswords(fmt.Sprintf("{hostName=%q, database=%+v, databaseSid=%+v, databaseHome=%q, pdbConnStr=%q}", s.hostName, s.database, s.databaseSid, s.databaseHome, pdbConnStr))
 }
 
 type syncState struct {
@@ -312,14 +313,14 @@ func (d *DB) openPDBs(ctx context.Context) error {
 // CreatePasswordFile is a Database Daemon method to create password file.
 func (s *Server) CreatePasswordFile(ctx context.Context, req *dbdpb.CreatePasswordFileRequest) (*dbdpb.CreatePasswordFileResponse, error) {
 	if req.GetDatabaseName() == "" {
-		return nil, fmt.Errorf("missing database name for req: %v", req)
+		return nil, fmt.Errorf("missing database name for the password file in %q", req.GetDir())
 	}
 	if req.GetSysPassword() == "" {
-		return nil, fmt.Errorf("missing password for req: %v", req)
+		return nil, fmt.Errorf("missing password for the password file of %q", req.GetDatabaseName())
 	}
 
 	if req.GetDir() != "" {
-		if err := os.MkdirAll(req.GetDir(), 0750); err != nil {
+		if err := s.mkdirAllOracle(req.GetDir(), 0750); err != nil {
 			return nil, fmt.Errorf("failed to create dir: %v", req.GetDir())
 		}
 	}
@@ -342,9 +343,10 @@ func (s *Server) CreatePasswordFile(ctx context.Context, req *dbdpb.CreatePasswo
 
 // CreateFile creates file based on request.
 func (s *Server) CreateFile(ctx context.Context, req *dbdpb.CreateFileRequest) (*dbdpb.CreateFileResponse, error) {
-	klog.InfoS("dbdaemon/CreateFile: ", "req", req)
+	// The content may hold credentials, e.g. of wallets or password files.
+	klog.InfoS("dbdaemon/CreateFile", "path", req.GetPath(), "contentBytes", len(req.GetContent()))
 
-	if err := s.osUtil.createFile(req.Path, strings.NewReader(req.Content)); err != nil {
+	if err := s.createOracleFile(req.Path, strings.NewReader(req.Content)); err != nil {
 		return nil, fmt.Errorf("dbdaemon/CreateFile: create failed: %v", err)
 	}
 	return &dbdpb.CreateFileResponse{}, nil
@@ -355,6 +357,13 @@ func (s *Server) SetListenerRegistration(ctx context.Context, req *dbdpb.SetList
 	return nil, fmt.Errorf("not implemented")
 }
 
+// recoverUntilTime returns the until time clause of a point in time
+// recovery. The time is formatted with numbers only, which the database
+// parses the same whatever the NLS settings applications set.
+func recoverUntilTime(t time.Time) string {
+	return fmt.Sprintf(`time "to_date('%s','YYYY-MM-DD HH24:MI:SS')"`, t.Format("2006-01-02 15:04:05"))
+}
+
 // physicalRestore runs
 //  1. RMAN restore command
 //  2. SQL to get latest SCN
@@ -364,7 +373,21 @@ func (s *Server) SetListenerRegistration(ctx context.Context, req *dbdpb.SetList
 func (s *Server) physicalRestore(ctx context.Context, req *dbdpb.PhysicalRestoreRequest) (*empty.Empty, error) {
 	errorPrefix := "dbdaemon/physicalRestore: "
 
-	if _, err := s.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{req.GetRestoreStatement()}}); err != nil {
+	var streams *pipeStreams
+	if dir := req.GetStreamGcsPath(); dir != "" {
+		var err error
+		if streams, err = s.streamGCSToPipes(ctx, dir); err != nil {
+			return nil, fmt.Errorf(errorPrefix+"failed to stream the backup pieces: %v", err)
+		}
+	}
+	_, err := s.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{req.GetRestoreStatement()}})
+	if streams != nil {
+		// RMAN reports the backup pieces it failed to read.
+		if streamErr := streams.finish(); streamErr != nil {
+			klog.ErrorS(streamErr, errorPrefix+"failed to stream the backup pieces", "restoreErr", err)
+		}
+	}
+	if err != nil {
 		return nil, fmt.Errorf(errorPrefix+"failed to restore a database: %v", err)
 	}
 
@@ -382,13 +405,13 @@ func (s *Server) physicalRestore(ctx context.Context, req *dbdpb.PhysicalRestore
 		}
 		recoverStmt = fmt.Sprintf(req.GetRecoverStatementTemplate(), fmt.Sprintf("scn %d", scn))
 	} else if req.GetPitrRestoreInput().GetEndTime() != nil {
-		recoverStmt = fmt.Sprintf(req.GetRecoverStatementTemplate(), fmt.Sprintf(`time "to_date('%s','DD-MON-YYYY HH24:MI:SS')"`, req.GetPitrRestoreInput().GetEndTime().AsTime().Format("02-Jan-2006 15:04:05")))
+		recoverStmt = fmt.Sprintf(req.GetRecoverStatementTemplate(), recoverUntilTime(req.GetPitrRestoreInput().GetEndTime().AsTime()))
 	} else if req.GetPitrRestoreInput().GetEndScn() != 0 {
 		recoverStmt = fmt.Sprintf(req.GetRecoverStatementTemplate(), fmt.Sprintf("scn %d", req.GetPitrRestoreInput().GetEndScn()))
 	}
 
 	if recoverStmt == "" {
-		return nil, fmt.Errorf(errorPrefix+"failed to build recover statement from req %+v", req)
+		return nil, fmt.Errorf(errorPrefix+"failed to build recover statement from req %+v", loggableRequest(req))
 	}
 
 	klog.InfoS(errorPrefix+"final recovery request", "recoverStmt", recoverStmt)
@@ -402,6 +425,15 @@ func (s *Server) physicalRestore(ctx context.Context, req *dbdpb.PhysicalRestore
 	if err := os.RemoveAll(consts.RMANStagingDir); err != nil {
 		klog.Warningf("physicalRestore: can't cleanup staging dir from local disk.")
 	}
+
+	// The restored files may be owned by another user than oracle, which
+	// fails the startup of the database.
+	s.databaseSid.RLock()
+	dirs := oracleDirs(s.databaseSid.val)
+	s.databaseSid.RUnlock()
+	if _, err := s.fixOwnership(dirs); err != nil {
+		return nil, fmt.Errorf(errorPrefix+"failed to fix the ownership of the restored files: %v", err)
+	}
 	return &empty.Empty{}, nil
 }
 
@@ -433,7 +465,7 @@ func (s *Server) stageAndCatalog(ctx context.Context, req *dbdpb.PhysicalRestore
 	input := req.GetPitrRestoreInput()
 	if input.GetEndTime() != nil {
 		if input.GetStartTime() == nil {
-			return fmt.Errorf("failed to find recover end time in req %+v", req)
+			return fmt.Errorf("failed to find recover end time in req %+v", loggableRequest(req))
 		}
 		include = func(entry pitr.LogMetadataEntry) bool {
 			return !(entry.NextTime.Before(input.GetStartTime().AsTime()) || entry.FirstTime.After(input.GetEndTime().AsTime()))
@@ -457,11 +489,11 @@ func (s *Server) stageAndCatalog(ctx context.Context, req *dbdpb.PhysicalRestore
 	}
 
 	if include == nil {
-		return fmt.Errorf("either start SCN or timestamp must be specified in req %+v", req)
+		return fmt.Errorf("either start SCN or timestamp must be specified in req %+v", loggableRequest(req))
 	}
 
 	dir := filepath.Join(consts.RMANStagingDir, "pitr")
-	if err := os.MkdirAll(dir, 0750); err != nil {
+	if err := os.MkdirAll(dir, dirPerm(0750)); err != nil {
 		return fmt.Errorf("failed to create redo logs staging dir: %v", err)
 	}
 	if err := pitr.StageLogs(ctx, dir, include, input.GetLogGcsPath()); err != nil {
@@ -487,7 +519,7 @@ func (s *Server) PhysicalRestoreAsync(ctx context.Context, req *dbdpb.PhysicalRe
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/PhysicalRestoreAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/PhysicalRestoreAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 
@@ -495,10 +527,12 @@ func (s *Server) PhysicalRestoreAsync(ctx context.Context, req *dbdpb.PhysicalRe
 }
 
 func filterParamsForMetadata(in []string) []string {
-	// These parameters are incompatible with sqlfile, so we must remove
-	// them for our metadata dump.
+	// These parameters are incompatible with sqlfile, or with the include
+	// filters of our metadata dump, so we must remove them.
 	restricted := []string{
 		"TABLE_EXISTS_ACTION",
+		"INCLUDE",
+		"EXCLUDE",
 	}
 
 	var filtered []string
@@ -518,36 +552,62 @@ func filterParamsForMetadata(in []string) []string {
 	return filtered
 }
 
+// hasNetworkLink returns true if the impdp params import over a database
+// link rather than from a dump file.
+func hasNetworkLink(params []string) bool {
+	for _, p := range params {
+		if strings.HasPrefix(strings.ToUpper(p), "NETWORK_LINK=") {
+			return true
+		}
+	}
+	return false
+}
+
 // dataPumpImport runs impdp Oracle tool against existing PDB which
-// imports data from a data pump .dmp file.
+// imports data from a data pump .dmp file, or from the database of the
+// NETWORK_LINK param.
 func (s *Server) dataPumpImport(ctx context.Context, req *dbdpb.DataPumpImportRequest) (*dbdpb.DataPumpImportResponse, error) {
 	s.syncJobs.pdbLoadMutex.Lock()
 	defer s.syncJobs.pdbLoadMutex.Unlock()
 
+	return s.runDataPumpImport(ctx, req)
+}
+
+// runDataPumpImport runs an import of dataPumpImport, the caller holds
+// pdbLoadMutex.
+func (s *Server) runDataPumpImport(ctx context.Context, req *dbdpb.DataPumpImportRequest) (*dbdpb.DataPumpImportResponse, error) {
 	importFilename := "import.dmp"
 	importMetaFile := importFilename + ".meta"
 	logFilename := "import.log"
 
-	pdbPath := fmt.Sprintf(consts.PDBPathPrefix, consts.DataMount, s.databaseSid.val, strings.ToUpper(req.PdbName))
-	dumpDir := filepath.Join(pdbPath, consts.DpdumpDir.Linux)
-	klog.InfoS("dbdaemon/dataPumpImport", "dumpDir", dumpDir)
-
-	dmpReader, err := s.gcsUtil.Download(ctx, req.GcsPath)
+	dirResp, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: req.PdbName})
 	if err != nil {
-		return nil, fmt.Errorf("dbdaemon/dataPumpImport: initiating GCS download failed: %v", err)
+		return nil, fmt.Errorf("dbdaemon/dataPumpImport: %v", err)
 	}
-	defer dmpReader.Close()
+	dumpDir := dirResp.GetPath()
+	klog.InfoS("dbdaemon/dataPumpImport", "dumpDir", dumpDir)
 
-	importFileFullPath := filepath.Join(dumpDir, importFilename)
-	if err := s.osUtil.createFile(importFileFullPath, dmpReader); err != nil {
-		return nil, fmt.Errorf("dbdaemon/dataPumpImport: download from GCS failed: %v", err)
-	}
-	klog.Infof("dbdaemon/dataPumpImport: downloaded import dmp file from %s to %s", req.GcsPath, importFileFullPath)
-	defer func() {
-		if err := s.osUtil.removeFile(importFileFullPath); err != nil {
-			klog.Warning(fmt.Sprintf("dbdaemon/dataPumpImport: failed to remove import dmp file after import: %v", err))
+	// Imports over a database link read the source database directly.
+	var dumpParams []string
+	if !hasNetworkLink(req.CommandParams) {
+		dmpReader, err := s.gcsUtil.Download(ctx, req.GcsPath)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/dataPumpImport: initiating GCS download failed: %v", err)
 		}
-	}()
+		defer dmpReader.Close()
+
+		importFileFullPath := filepath.Join(dumpDir, importFilename)
+		if err := s.createOracleFile(importFileFullPath, dmpReader); err != nil {
+			return nil, fmt.Errorf("dbdaemon/dataPumpImport: download from GCS failed: %v", err)
+		}
+		klog.Infof("dbdaemon/dataPumpImport: downloaded import dmp file from %s to %s", req.GcsPath, importFileFullPath)
+		defer func() {
+			if err := s.osUtil.removeFile(importFileFullPath); err != nil {
+				klog.Warning(fmt.Sprintf("dbdaemon/dataPumpImport: failed to remove import dmp file after import: %v", err))
+			}
+		}()
+		dumpParams = append(dumpParams, "dumpfile="+importFilename)
+	}
 
 	impdpTarget, err := security.SetupUserPwConnStringOnServer(ctx, s, consts.PDBLoaderUser, req.PdbName, req.DbDomain)
 	if err != nil {
@@ -560,7 +620,7 @@ func (s *Server) dataPumpImport(ctx context.Context, req *dbdpb.DataPumpImportRe
 	tsCheckParams := []string{impdpTarget}
 	tsCheckParams = append(tsCheckParams, filterParamsForMetadata(req.CommandParams)...)
 	tsCheckParams = append(tsCheckParams, fmt.Sprintf("directory=%s", consts.DpdumpDir.Oracle))
-	tsCheckParams = append(tsCheckParams, "dumpfile="+importFilename)
+	tsCheckParams = append(tsCheckParams, dumpParams...)
 	tsCheckParams = append(tsCheckParams, "sqlfile="+importMetaFile)
 	tsCheckParams = append(tsCheckParams, "nologfile=YES")
 	tsCheckParams = append(tsCheckParams, "include=TABLESPACE")
@@ -585,9 +645,16 @@ func (s *Server) dataPumpImport(ctx context.Context, req *dbdpb.DataPumpImportRe
 	params := []string{impdpTarget}
 	params = append(params, req.CommandParams...)
 	params = append(params, fmt.Sprintf("directory=%s", consts.DpdumpDir.Oracle))
-	params = append(params, "dumpfile="+importFilename)
+	params = append(params, dumpParams...)
 	params = append(params, "logfile="+logFilename)
 
+	// The log of a previous import would be streamed until impdp replaces it.
+	logPath := filepath.Join(dumpDir, logFilename)
+	if err := os.Remove(logPath); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("dbdaemon/dataPumpImport: failed to remove the previous log %s: %v", logPath, err)
+	}
+	s.setOperationLog(ctx, logPath)
+
 	if err := s.runCommand(impdp(s.databaseHome), params); err != nil {
 		// On error code 5 (EX_SUCC_ERR), process completed reached the
 		// end but data in the DMP might have been skipped (foreign
@@ -683,12 +750,29 @@ func (s *Server) createTablespacesFromSqlfile(ctx context.Context, metaFullPath,
 		}
 	}
 
+	// Encrypt the tablespaces explicitly if the policy of the PDB encrypts
+	// new tablespaces, temporary tablespaces can't be encrypted explicitly.
+	encryption := ""
+	policyResp, err := s.RunSQLPlusFormatted(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{
+			sqlq.QuerySetSessionContainer(PDBName),
+			tablespaceEncryptionPolicySQL,
+		},
+	})
+	if err != nil {
+		klog.Warningf("createTablespacesFromSqlfile: query tablespace encryption policy failed: %v", err)
+	} else if policy, err := parseTablespaceEncryptionPolicy(policyResp.GetMsg()); err != nil {
+		klog.Warningf("createTablespacesFromSqlfile: %v", err)
+	} else if policy.encryptsNewTablespaces() {
+		encryption = " encryption encrypt"
+	}
+
 	// Create any remaining tablespaces
 	for t := range ts {
 		_, err := s.RunSQLPlus(ctx, &dbdpb.RunSQLPlusCMDRequest{
 			Commands: []string{
 				sqlq.QuerySetSessionContainer(PDBName),
-				fmt.Sprintf("create bigfile tablespace \"%s\"", t),
+				fmt.Sprintf("create bigfile tablespace \"%s\"%s", t, encryption),
 			},
 		})
 		if err != nil {
@@ -716,7 +800,7 @@ func (s *Server) DataPumpImportAsync(ctx context.Context, req *dbdpb.DataPumpImp
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/DataPumpImportAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/DataPumpImportAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 
@@ -728,6 +812,12 @@ func (s *Server) dataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRe
 	s.syncJobs.pdbLoadMutex.Lock()
 	defer s.syncJobs.pdbLoadMutex.Unlock()
 
+	return s.runDataPumpExport(ctx, req)
+}
+
+// runDataPumpExport runs an export of dataPumpExport, the caller holds
+// pdbLoadMutex.
+func (s *Server) runDataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRequest) (*dbdpb.DataPumpExportResponse, error) {
 	dmpObjectType := "SCHEMAS"
 	exportName := fmt.Sprintf("export_%s", time.Now().Format("20060102150405"))
 	dmpFile := exportName + ".dmp"
@@ -738,9 +828,12 @@ func (s *Server) dataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRe
 		dmpObjectType = req.ObjectType
 	}
 
-	pdbPath := fmt.Sprintf(consts.PDBPathPrefix, consts.DataMount, s.databaseSid.val, strings.ToUpper(req.PdbName))
-	dmpPath := filepath.Join(pdbPath, consts.DpdumpDir.Linux, dmpFile) // full path
-	parPath := filepath.Join(pdbPath, consts.DpdumpDir.Linux, parFile)
+	dirResp, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: req.PdbName})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/dataPumpExport: %v", err)
+	}
+	dmpPath := filepath.Join(dirResp.GetPath(), dmpFile) // full path
+	parPath := filepath.Join(dirResp.GetPath(), parFile)
 
 	klog.InfoS("dbdaemon/dataPumpExport", "dmpPath", dmpPath)
 
@@ -773,6 +866,7 @@ func (s *Server) dataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRe
 		return nil, fmt.Errorf("data pump export failed, err = %v", err)
 	}
 
+	s.setOperationLog(ctx, filepath.Join(dirResp.GetPath(), dmpLogFile))
 	cmdParams := []string{expdpTarget}
 	cmdParams = append(cmdParams, fmt.Sprintf("parfile=%s", parPath))
 	if err := s.runCommand(expdp(s.databaseHome), cmdParams); err != nil {
@@ -789,7 +883,7 @@ func (s *Server) dataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRe
 	klog.Infof("dbdaemon/dataPumpExport: uploaded dmp file to %s", req.GcsPath)
 
 	if len(req.GcsLogPath) > 0 {
-		logPath := filepath.Join(pdbPath, consts.DpdumpDir.Linux, dmpLogFile)
+		logPath := filepath.Join(dirResp.GetPath(), dmpLogFile)
 
 		if err := s.gcsUtil.UploadFile(ctx, req.GcsLogPath, logPath, contentTypePlainText); err != nil {
 			return nil, fmt.Errorf("dbdaemon/dataPumpExport: failed to upload log file to %s: %v", req.GcsLogPath, err)
@@ -802,7 +896,7 @@ func (s *Server) dataPumpExport(ctx context.Context, req *dbdpb.DataPumpExportRe
 
 // writeParFile writes data pump export parameter file in parPath.
 func writeParFile(parPath string, params []string) error {
-	f, err := os.Create(parPath)
+	f, err := os.OpenFile(parPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, filePerm(0666))
 	if err != nil {
 		return err
 	}
@@ -827,7 +921,7 @@ func (s *Server) DataPumpExportAsync(ctx context.Context, req *dbdpb.DataPumpExp
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/DataPumpExportAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/DataPumpExportAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 	return &lropb.Operation{Name: job.ID(), Done: false}, nil
@@ -848,12 +942,19 @@ func (s *Server) applyDataPatch(ctx context.Context) (*dbdpb.ApplyDataPatchRespo
 	s.syncJobs.maintenanceMutex.Lock()
 	defer s.syncJobs.maintenanceMutex.Unlock()
 
+	return s.runDataPatch(ctx)
+}
+
+// runDataPatch runs the steps of applyDataPatch, the caller holds the
+// maintenance mutex.
+func (s *Server) runDataPatch(ctx context.Context) (*dbdpb.ApplyDataPatchResponse, error) {
 	klog.InfoS("dbdaemon/applyDataPatch started")
 
 	// Ask proxy to shutdown the DB
 	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
 		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
 		DatabaseName: s.databaseSid.val,
+		OracleHome:   s.databaseHome,
 		Option:       "immediate",
 	}); err != nil {
 		return nil, fmt.Errorf("proxy request to shutdown DB failed: %w", err)
@@ -865,6 +966,7 @@ func (s *Server) applyDataPatch(ctx context.Context) (*dbdpb.ApplyDataPatchRespo
 	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
 		Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
 		DatabaseName: s.databaseSid.val,
+		OracleHome:   s.databaseHome,
 		Option:       "nomount",
 	}); err != nil {
 		return nil, fmt.Errorf("proxy request to startup DB failed: %w", err)
@@ -894,6 +996,7 @@ func (s *Server) applyDataPatch(ctx context.Context) (*dbdpb.ApplyDataPatchRespo
 	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
 		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
 		DatabaseName: s.databaseSid.val,
+		OracleHome:   s.databaseHome,
 		Option:       "immediate",
 	}); err != nil {
 		return nil, fmt.Errorf("proxy request to shutdown DB failed: %w", err)
@@ -904,6 +1007,7 @@ func (s *Server) applyDataPatch(ctx context.Context) (*dbdpb.ApplyDataPatchRespo
 	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
 		Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
 		DatabaseName: s.databaseSid.val,
+		OracleHome:   s.databaseHome,
 		Option:       "",
 	}); err != nil {
 		return nil, fmt.Errorf("proxy request to startup DB failed: %w", err)
@@ -942,7 +1046,7 @@ func (s *Server) ApplyDataPatchAsync(ctx context.Context, req *dbdpb.ApplyDataPa
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/ApplyDataPatchAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/ApplyDataPatchAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 	return &lropb.Operation{Name: job.ID(), Done: false}, nil
@@ -960,7 +1064,11 @@ func (s *Server) GetOperation(ctx context.Context, req *lropb.GetOperationReques
 
 // DeleteOperation deletes a long running operation by its id.
 func (s *Server) DeleteOperation(ctx context.Context, req *lropb.DeleteOperationRequest) (*empty.Empty, error) {
-	return s.lroServer.DeleteOperation(ctx, req)
+	resp, err := s.lroServer.DeleteOperation(ctx, req)
+	if err == nil {
+		s.removeOperationLog(req.GetName())
+	}
+	return resp, err
 }
 
 func (s *Server) runCommand(bin string, params []string) error {
@@ -1016,9 +1124,9 @@ func open(ctx context.Context, dbURL string, prelim bool) (oracleDatabase, error
 }
 
 func (d *DB) runSQL(ctx context.Context, sqls []string, prelim, suppress bool, db oracleDatabase) ([]string, error) {
-	sqlForLogging := strings.Join(sqls, ";")
+	sqlForLogging := redactPasswords(strings.Join(sqls, ";"))
 	if suppress {
-		sqlForLogging = "suppressed"
+		sqlForLogging = suppressed
 	}
 
 	// This will fail on prelim connections, so ignore errors in that case
@@ -1027,7 +1135,7 @@ func (d *DB) runSQL(ctx context.Context, sqls []string, prelim, suppress bool, d
 		return nil, err
 	}
 
-	klog.InfoS("dbdaemon/runSQL: running SQL statements", "sql", sqlForLogging)
+	klog.V(logLevelStatements).InfoS("dbdaemon/runSQL: running SQL statements", "sql", sqlForLogging)
 
 	output := []string{}
 	for _, sql := range sqls {
@@ -1046,10 +1154,14 @@ func (d *DB) runSQL(ctx context.Context, sqls []string, prelim, suppress bool, d
 	return output, nil
 }
 
-func (d *DB) runQuery(ctx context.Context, sqls []string, db oracleDatabase) ([]string, error) {
-	//TODO: Query suppression
-	klog.InfoS("dbdaemon/runQuery: running sql", "sql", sqls)
+func (d *DB) runQuery(ctx context.Context, sqls []string, suppress bool, db oracleDatabase) ([]string, error) {
 	sqlLen := len(sqls)
+	queryForLogging := redactPasswords(sqls[sqlLen-1])
+	if suppress {
+		queryForLogging = suppressed
+	}
+
+	klog.V(logLevelStatements).InfoS("dbdaemon/runQuery: running sql", "sql", queryForLogging)
 	for i := 0; i < sqlLen-1; i++ {
 		if _, err := db.ExecContext(ctx, sqls[i]); err != nil {
 			return nil, err
@@ -1057,14 +1169,14 @@ func (d *DB) runQuery(ctx context.Context, sqls []string, db oracleDatabase) ([]
 	}
 	rows, err := db.QueryContext(ctx, sqls[sqlLen-1])
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/runQuery: failed to query a database", "sql", sqls[sqlLen-1])
+		klog.ErrorS(err, "dbdaemon/runQuery: failed to query a database", "sql", queryForLogging)
 		return nil, err
 	}
 	defer rows.Close()
 
 	colNames, err := rows.Columns()
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/runQuery: failed to get column names for query", "sql", sqls[sqlLen-1])
+		klog.ErrorS(err, "dbdaemon/runQuery: failed to get column names for query", "sql", queryForLogging)
 		return nil, err
 	}
 
@@ -1156,7 +1268,7 @@ func (s *Server) runSQLPlusHelper(ctx context.Context, req *dbdpb.RunSQLPlusCMDR
 
 	var o []string
 	if formattedSQL {
-		o, err = s.database.runQuery(ctx, sqls, db)
+		o, err = s.database.runQuery(ctx, sqls, req.GetSuppress(), db)
 	} else {
 		o, err = s.database.runSQL(ctx, sqls, prelim, req.GetSuppress(), db)
 	}
@@ -1165,7 +1277,9 @@ func (s *Server) runSQLPlusHelper(ctx context.Context, req *dbdpb.RunSQLPlusCMDR
 		return nil, err
 	}
 
-	klog.InfoS("dbdaemon/RunSQLPlus", "output", strings.Join(o, "\n"))
+	if !req.GetSuppress() {
+		klog.V(logLevelOutput).InfoS("dbdaemon/RunSQLPlus", "output", strings.Join(o, "\n"))
+	}
 	return &dbdpb.RunCMDResponse{Msg: o}, nil
 }
 
@@ -1178,11 +1292,7 @@ func (s *Server) RunSQLPlus(ctx context.Context, req *dbdpb.RunSQLPlusCMDRequest
 	s.databaseSid.Lock()
 	defer s.databaseSid.Unlock()
 
-	if req.GetSuppress() {
-		klog.InfoS("dbdaemon/RunSQLPlus", "req", "suppressed", "SID", s.databaseSid.val, "serverObj", s)
-	} else {
-		klog.InfoS("dbdaemon/RunSQLPlus", "req", req, "SID", s.databaseSid.val, "serverObj", s)
-	}
+	klog.InfoS("dbdaemon/RunSQLPlus", "req", loggableRequest(req), "SID", s.databaseSid.val, "serverObj", s)
 
 	return s.runSQLPlusHelper(ctx, req, false)
 }
@@ -1195,11 +1305,7 @@ func (s *Server) RunSQLPlusFormatted(ctx context.Context, req *dbdpb.RunSQLPlusC
 	s.databaseSid.Lock()
 	defer s.databaseSid.Unlock()
 
-	if req.GetSuppress() {
-		klog.InfoS("dbdaemon/RunSQLPlusFormatted", "req", "suppressed", "SID", s.databaseSid.val, "serverObj", s)
-	} else {
-		klog.InfoS("dbdaemon/RunSQLPlusFormatted", "req", req, "SID", s.databaseSid.val, "serverObj", s)
-	}
+	klog.InfoS("dbdaemon/RunSQLPlusFormatted", "req", loggableRequest(req), "SID", s.databaseSid.val, "serverObj", s)
 
 	return s.runSQLPlusHelper(ctx, req, true)
 }
@@ -1209,7 +1315,7 @@ func (s *Server) RunSQLPlusFormatted(ctx context.Context, req *dbdpb.RunSQLPlusC
 // It also by default doesn't pay attention to a state of a PDB.
 // A caller can overwrite both of the above settings with the flags.
 func (s *Server) KnownPDBs(ctx context.Context, req *dbdpb.KnownPDBsRequest) (*dbdpb.KnownPDBsResponse, error) {
-	klog.InfoS("dbdaemon/KnownPDBs", "req", req, "serverObj", s)
+	klog.InfoS("dbdaemon/KnownPDBs", "req", loggableRequest(req), "serverObj", s)
 	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
 	// Only add lock in top level API to avoid deadlock.
 	s.databaseSid.RLock()
@@ -1274,7 +1380,7 @@ func (s *Server) isKnownPDB(ctx context.Context, name string, includeSeed, onlyO
 // CheckDatabaseState pings a database to check its status.
 // This method has been tested for checking a CDB state.
 func (s *Server) CheckDatabaseState(ctx context.Context, req *dbdpb.CheckDatabaseStateRequest) (*dbdpb.CheckDatabaseStateResponse, error) {
-	klog.InfoS("dbdaemon/CheckDatabaseState", "req", req, "serverObj", s)
+	klog.InfoS("dbdaemon/CheckDatabaseState", "req", loggableRequest(req), "serverObj", s)
 	reqDatabaseName := req.GetDatabaseName()
 	if reqDatabaseName == "" {
 		return nil, fmt.Errorf("a database check is requested, but a mandatory database name parameter is not provided (server: %v)", s)
@@ -1330,14 +1436,15 @@ func (s *Server) CheckDatabaseState(ctx context.Context, req *dbdpb.CheckDatabas
 
 // RunRMAN will run the script to execute RMAN and create a physical backup in the target directory, then back it up to GCS if requested
 func (s *Server) RunRMAN(ctx context.Context, req *dbdpb.RunRMANRequest) (*dbdpb.RunRMANResponse, error) {
+	return s.runRMAN(ctx, req, nil)
+}
+
+// runRMAN runs RunRMAN and also writes the RMAN output to log if not nil.
+func (s *Server) runRMAN(ctx context.Context, req *dbdpb.RunRMANRequest, log io.Writer) (*dbdpb.RunRMANResponse, error) {
 	// Required for local connections (when no SID is specified on connect string).
 	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
 	// Only add lock in top level API to avoid deadlock.
-	if req.GetSuppress() {
-		klog.InfoS("RunRMAN", "request", "suppressed")
-	} else {
-		klog.InfoS("RunRMAN", "request", req)
-	}
+	klog.InfoS("RunRMAN", "request", loggableRequest(req))
 
 	s.databaseSid.RLock()
 	defer s.databaseSid.RUnlock()
@@ -1360,52 +1467,91 @@ func (s *Server) RunRMAN(ctx context.Context, req *dbdpb.RunRMANRequest) (*dbdpb
 	if len(scripts) < 1 {
 		return nil, fmt.Errorf("RunRMAN requires at least 1 script to run, provided: %d", len(scripts))
 	}
-	var res []string
-	for _, script := range scripts {
-		var args []string
-		target := "/"
-		if req.GetTarget() != "" {
-			target = req.GetTarget()
-		}
+	if req.GetSingleSession() {
+		scripts = []string{strings.Join(scripts, "\n")}
+	}
 
-		if !req.GetWithoutTarget() {
-			args = append(args, fmt.Sprintf("target=%s", target))
-		}
+	var args []string
+	target := "/"
+	if req.GetTarget() != "" {
+		target = req.GetTarget()
+	}
 
-		if req.GetAuxiliary() != "" {
-			args = append(args, fmt.Sprintf("auxiliary=%s", req.Auxiliary))
-		}
+	if !req.GetWithoutTarget() {
+		args = append(args, fmt.Sprintf("target=%s", target))
+	}
+
+	if req.GetAuxiliary() != "" {
+		args = append(args, fmt.Sprintf("auxiliary=%s", req.Auxiliary))
+	}
+
+	args = append(args, "@/dev/stdin")
 
-		args = append(args, "@/dev/stdin")
+	var streams *pipeStreams
+	if req.GetStreamChannels() > 0 {
+		var err error
+		if streams, err = s.streamPipesToGCS(ctx, int(req.GetStreamChannels()), req.GetGcsPath()); err != nil {
+			return nil, fmt.Errorf("RunRMAN failed to stream the backup channels: %v", err)
+		}
+		defer streams.finish()
+	}
 
+	var res []string
+	for _, script := range scripts {
 		cmd := exec.Command(rman(s.databaseHome), args...)
 		cmd.Stdin = strings.NewReader(script)
-		out, err := cmd.CombinedOutput()
-		if err != nil {
-			return nil, fmt.Errorf("RunRMAN failed,\nscript: %q\nFailed with: %v\nErr: %v", script, string(out), err)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		if log != nil {
+			cmd.Stdout = io.MultiWriter(&out, log)
 		}
-		res = append(res, string(out))
+		cmd.Stderr = cmd.Stdout
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("RunRMAN failed,\nscript: %q\nFailed with: %v\nErr: %v", script, out.String(), err)
+		}
+		res = append(res, out.String())
 
 		if req.GetGcsPath() != "" && req.GetGcsOp() == dbdpb.RunRMANRequest_UPLOAD {
-			if err = s.uploadDirectoryContentsToGCS(ctx, consts.RMANStagingDir, req.GetGcsPath()); err != nil {
+			if err := s.uploadDirectoryContentsToGCS(ctx, rmanUploadDir(req), req.GetGcsPath()); err != nil {
 				klog.ErrorS(err, "GCS Upload error:")
 				return nil, err
 			}
 		}
 	}
+	if streams != nil {
+		if err := streams.finish(); err != nil {
+			return nil, fmt.Errorf("RunRMAN failed to stream the backup pieces: %v", err)
+		}
+	}
 
 	return &dbdpb.RunRMANResponse{Output: res}, nil
 }
 
+// rmanUploadDir returns the directory uploaded after an RMAN script ran. A
+// local path inside the RMAN staging directory limits the upload, and the
+// cleanup after it, to that directory so that concurrent runs do not remove
+// each other's files.
+func rmanUploadDir(req *dbdpb.RunRMANRequest) string {
+	if dir := filepath.Clean(req.GetLocalPath()); strings.HasPrefix(dir, consts.RMANStagingDir+string(filepath.Separator)) {
+		return dir
+	}
+	return consts.RMANStagingDir
+}
+
 // RunRMANAsync turns RunRMAN into an async call.
 func (s *Server) RunRMANAsync(ctx context.Context, req *dbdpb.RunRMANAsyncRequest) (*lropb.Operation, error) {
 	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "RMAN", s.lroServer,
 		func(ctx context.Context) (proto.Message, error) {
-			return s.RunRMAN(ctx, req.SyncRequest)
+			log, err := s.createOperationLog(ctx, "rman")
+			if err != nil {
+				return nil, fmt.Errorf("dbdaemon/RunRMANAsync: %v", err)
+			}
+			defer log.Close()
+			return s.runRMAN(ctx, req.SyncRequest, log)
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/RunRMANAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/RunRMANAsync failed to create an LRO job", "operationID", req.GetLroInput().GetOperationId(), "request", loggableRequest(req))
 		return nil, err
 	}
 
@@ -1488,7 +1634,7 @@ func (s *Server) uploadDirectoryContentsToGCS(ctx context.Context, backupDir, gc
 		return nil
 	})
 
-	if err := os.RemoveAll(consts.RMANStagingDir); err != nil {
+	if err := os.RemoveAll(backupDir); err != nil {
 		klog.Warningf("uploadDirectoryContentsToGCS: can't cleanup staging dir from local disk.")
 	}
 	return err
@@ -1498,7 +1644,7 @@ func (s *Server) uploadDirectoryContentsToGCS(ctx context.Context, backupDir, gc
 func (s *Server) NID(ctx context.Context, req *dbdpb.NIDRequest) (*dbdpb.NIDResponse, error) {
 	params := []string{"target=/"}
 	if req.GetSid() == "" {
-		return nil, fmt.Errorf("dbdaemon/NID: missing sid for req: %v", req)
+		return nil, fmt.Errorf("dbdaemon/NID: missing sid for req: %v", loggableRequest(req))
 	}
 
 	if err := os.Setenv("ORACLE_SID", req.GetSid()); err != nil {
@@ -1521,55 +1667,21 @@ func (s *Server) NID(ctx context.Context, req *dbdpb.NIDRequest) (*dbdpb.NIDResp
 		return nil, fmt.Errorf("nid failed: %v", err)
 	}
 
-	klog.InfoS("dbdaemon/NID: done", "req", req)
+	klog.InfoS("dbdaemon/NID: done", "req", loggableRequest(req))
 	return &dbdpb.NIDResponse{}, nil
 }
 
 // GetDatabaseType returns database type, eg. ORACLE_12_2_ENTERPRISE_NONCDB
 func (s *Server) GetDatabaseType(ctx context.Context, req *dbdpb.GetDatabaseTypeRequest) (*dbdpb.GetDatabaseTypeResponse, error) {
-	f, err := os.Open(consts.OraTab)
+	content, err := ioutil.ReadFile(consts.OraTab)
 	if err != nil {
 		return nil, fmt.Errorf("GetDatabaseType: failed to open %q", consts.OraTab)
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			klog.Warningf("failed to close %v: %v", f, err)
-		}
-	}()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		// The content of oratab is expected to be of the form:
-		// # comments
-		// <CDB name>:DatabaseHome:<Y/N>
-		// # DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB
-		if !strings.HasPrefix(line, "# DATABASETYPE") {
-			continue
-		}
-		fragment := strings.Split(line, ":")
-		if len(fragment) != 2 {
-			return nil, fmt.Errorf("GetDatabaseType: failed to parse %q for database type(number of fields is %d, not 2)", consts.OraTab, len(fragment))
-		}
-
-		switch fragment[1] {
-		case "ORACLE_12_2_ENTERPRISE":
-			return &dbdpb.GetDatabaseTypeResponse{
-				DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
-			}, nil
-		case "ORACLE_12_2_ENTERPRISE_NONCDB":
-			return &dbdpb.GetDatabaseTypeResponse{
-				DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE_NONCDB,
-			}, nil
-		default:
-			return nil, fmt.Errorf("GetDatabaseType: failed to get valid database type from %q", consts.OraTab)
-		}
+	databaseType, err := oratabDatabaseType(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("GetDatabaseType: failed to get valid database type from %q: %v", consts.OraTab, err)
 	}
-
-	// For backward compatibility, return ORACLE_12_2_ENTERPRISE by default
-	return &dbdpb.GetDatabaseTypeResponse{
-		DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
-	}, nil
+	return &dbdpb.GetDatabaseTypeResponse{DatabaseType: databaseType}, nil
 }
 
 // GetDatabaseName returns database name.
@@ -1586,7 +1698,7 @@ func (s *Server) GetDatabaseName(ctx context.Context, req *dbdpb.GetDatabaseName
 func (s *Server) BounceDatabase(ctx context.Context, req *dbdpb.BounceDatabaseRequest) (*dbdpb.BounceDatabaseResponse, error) {
 	s.databaseSid.RLock()
 	defer s.databaseSid.RUnlock()
-	klog.InfoS("BounceDatabase request delegated to proxy", "req", req)
+	klog.InfoS("BounceDatabase request delegated to proxy", "req", loggableRequest(req))
 	database, err := s.dbdClient.BounceDatabase(ctx, req)
 	if err != nil {
 		msg := "dbdaemon/BounceDatabase: error while bouncing database"
@@ -1606,7 +1718,7 @@ func (s *Server) BounceDatabase(ctx context.Context, req *dbdpb.BounceDatabaseRe
 
 // BounceListener starts/stops request specified listener.
 func (s *Server) BounceListener(ctx context.Context, req *dbdpb.BounceListenerRequest) (*dbdpb.BounceListenerResponse, error) {
-	klog.InfoS("BounceListener request delegated to proxy", "req", req)
+	klog.InfoS("BounceListener request delegated to proxy", "req", loggableRequest(req))
 	return s.dbdClient.BounceListener(ctx, req)
 }
 
@@ -1618,7 +1730,7 @@ func (s *Server) close() {
 
 // createCDB creates a database instance
 func (s *Server) createCDB(ctx context.Context, req *dbdpb.CreateCDBRequest) (*dbdpb.CreateCDBResponse, error) {
-	klog.InfoS("CreateCDB request invoked", "req", req)
+	klog.InfoS("CreateCDB request invoked", "req", loggableRequest(req))
 
 	password, err := security.RandOraclePassword()
 	if err != nil {
@@ -1710,7 +1822,7 @@ func (s *Server) CreateCDBAsync(ctx context.Context, req *dbdpb.CreateCDBAsyncRe
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/CreateCDBAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/CreateCDBAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 
@@ -1720,7 +1832,7 @@ func (s *Server) CreateCDBAsync(ctx context.Context, req *dbdpb.CreateCDBAsyncRe
 func setEnvNew(s *Server, home string, dbName string) error {
 	s.databaseHome = home
 	s.databaseSid.val = dbName
-	if err := provision.RelinkConfigFiles(home, dbName); err != nil {
+	if err := relinkConfigFiles(home, dbName); err != nil {
 		return err
 	}
 	return nil
@@ -1728,7 +1840,7 @@ func setEnvNew(s *Server, home string, dbName string) error {
 
 // markProvisioned creates a flag file to indicate that CDB provisioning completed successfully
 func markProvisioned() error {
-	f, err := os.Create(consts.ProvisioningDoneFile)
+	f, err := os.OpenFile(consts.ProvisioningDoneFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, filePerm(0666))
 	if err != nil {
 		return fmt.Errorf("could not create %s file: %v", consts.ProvisioningDoneFile, err)
 	}
@@ -1794,7 +1906,7 @@ func (s *Server) CreateListener(ctx context.Context, req *dbdpb.CreateListenerRe
 	if req.GetCdbServiceName() != "" {
 		cdbServiceName = req.GetCdbServiceName()
 	}
-	uid, gid, err := oracleUserUIDGID(true)
+	uid, gid, err := oracleOwner()
 	if err != nil {
 		return nil, fmt.Errorf("initDBListeners: get uid gid failed: %v", err)
 	}
@@ -1816,34 +1928,47 @@ func (s *Server) CreateListener(ctx context.Context, req *dbdpb.CreateListenerRe
 	}
 
 	lType := consts.SECURE
-	lDir := filepath.Join(listenerDir, lType)
+	if req.GetListenerName() != "" {
+		lType = req.GetListenerName()
+	}
+	lDir := filepath.Join(listenerDir(), lType)
 	listenerFileContent, tnsFileContent, sqlNetContent, err := provision.LoadTemplateListener(l, lType, fmt.Sprint(req.Port), req.Protocol)
 	if err != nil {
 		return &dbdpb.CreateListenerResponse{}, fmt.Errorf("initDBListeners: loading template for listener %q failed: %v", req.DatabaseName, err)
 	}
-
-	if err != nil {
-		return nil, fmt.Errorf("initDBListeners: error while fetching uid gid: %v", err)
+	if strings.EqualFold(req.GetProtocol(), "TCPS") {
+		if req.GetWalletDir() == "" {
+			return nil, fmt.Errorf("initDBListeners: the TCPS listener %q needs a wallet", lType)
+		}
+		listenerFileContent = updateSQLNet(listenerFileContent, tcpsParams(req.GetWalletDir()))
+		sqlNetContent = updateSQLNet(sqlNetContent, tcpsSQLNetParams(req.GetWalletDir()))
 	}
-	if err := provision.MakeDirs(ctx, []string{lDir}, uid, gid); err != nil {
+
+	newDirs := missingDirs(lDir)
+	if err := provision.MakeDirs(ctx, []string{lDir}, uint32(uid), uint32(gid)); err != nil {
 		return nil, fmt.Errorf("initDBListeners: making a listener directory %q failed: %v", lDir, err)
 	}
 
 	// Prepare listener.ora.
-	if err := ioutil.WriteFile(filepath.Join(lDir, "listener.ora"), []byte(listenerFileContent), 0600); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(lDir, "listener.ora"), []byte(listenerFileContent), filePerm(0600)); err != nil {
 		return nil, fmt.Errorf("initDBListeners: creating a listener.ora file failed: %v", err)
 	}
 
 	// Prepare sqlnet.ora.
-	if err := ioutil.WriteFile(filepath.Join(lDir, "sqlnet.ora"), []byte(sqlNetContent), 0600); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(lDir, "sqlnet.ora"), []byte(sqlNetContent), filePerm(0600)); err != nil {
 		return nil, fmt.Errorf("initDBListeners: unable to write sqlnet: %v", err)
 	}
 
 	// Prepare tnsnames.ora.
-	if err := ioutil.WriteFile(filepath.Join(lDir, "tnsnames.ora"), []byte(tnsFileContent), 0600); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(lDir, "tnsnames.ora"), []byte(tnsFileContent), filePerm(0600)); err != nil {
 		return nil, fmt.Errorf("initDBListeners: creating a tnsnames.ora file failed: %v", err)
 	}
 
+	listenerFiles := append(newDirs, filepath.Join(lDir, "listener.ora"), filepath.Join(lDir, "sqlnet.ora"), filepath.Join(lDir, "tnsnames.ora"))
+	if err := s.chownToOracle(listenerFiles...); err != nil {
+		return nil, fmt.Errorf("initDBListeners: %v", err)
+	}
+
 	if _, err := s.BounceListener(ctx, &dbdpb.BounceListenerRequest{
 		Operation:    dbdpb.BounceListenerRequest_STOP,
 		ListenerName: lType,
@@ -1897,6 +2022,11 @@ func (s *Server) FileExists(ctx context.Context, req *dbdpb.FileExistsRequest) (
 
 	file := req.GetName()
 
+	if waitSeconds := req.GetWaitSeconds(); waitSeconds > 0 {
+		klog.InfoS("dbdaemon/FileExists: waiting for file", "requested file", file, "waitSeconds", waitSeconds)
+		waitForFile(ctx, file, time.Duration(waitSeconds)*time.Second)
+	}
+
 	if _, err := os.Stat(file); err == nil {
 		klog.InfoS("dbdaemon/FileExists", "requested file", file, "result", "found")
 		return &dbdpb.FileExistsResponse{Exists: true}, nil
@@ -1916,7 +2046,7 @@ func (s *Server) FileExists(ctx context.Context, req *dbdpb.FileExistsRequest) (
 // CreateDirs RPC call to create directories along with any necessary parents.
 func (s *Server) CreateDirs(ctx context.Context, req *dbdpb.CreateDirsRequest) (*dbdpb.CreateDirsResponse, error) {
 	for _, dirInfo := range req.GetDirs() {
-		if err := os.MkdirAll(dirInfo.GetPath(), os.FileMode(dirInfo.GetPerm())); err != nil {
+		if err := s.mkdirAllOracle(dirInfo.GetPath(), os.FileMode(dirInfo.GetPerm())); err != nil {
 			return nil, fmt.Errorf("dbdaemon/CreateDirs failed on dir %s: %v", dirInfo.GetPath(), err)
 		}
 	}
@@ -2017,7 +2147,7 @@ func (s *Server) DeleteDir(ctx context.Context, req *dbdpb.DeleteDirRequest) (*d
 		removeFun = os.RemoveAll
 	}
 	if err := removeFun(req.GetPath()); err != nil {
-		return nil, fmt.Errorf("dbdaemon/DeleteDir(%v) failed: %v", req, err)
+		return nil, fmt.Errorf("dbdaemon/DeleteDir(%v) failed: %v", loggableRequest(req), err)
 	}
 	return &dbdpb.DeleteDirResponse{}, nil
 }
@@ -2027,8 +2157,7 @@ func (s *Server) DeleteDir(ctx context.Context, req *dbdpb.DeleteDirRequest) (*d
 // This file will be used for recovery in the event of parameter update workflow
 // failure due to bad static parameters.
 func (s *Server) BackupConfigFile(ctx context.Context, cdbName string) error {
-	configDir := fmt.Sprintf(consts.ConfigDir, consts.DataMount, cdbName)
-	backupPFileLoc := fmt.Sprintf("%s/%s", configDir, "pfile.lkws")
+	backupPFileLoc := fmt.Sprintf("%s/%s", configDir(cdbName), "pfile.lkws")
 	klog.InfoS("dbdaemon/BackupConfigFile: backup config file", "backupPFileLoc", backupPFileLoc)
 
 	_, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf("create pfile='%s' from spfile", backupPFileLoc)}}, false)
@@ -2036,13 +2165,17 @@ func (s *Server) BackupConfigFile(ctx context.Context, cdbName string) error {
 		klog.InfoS("dbdaemon/BackupConfigFile: error while backing up config file", "err", err)
 		return fmt.Errorf("BackupConfigFile: failed to create pfile due to error: %v", err)
 	}
+	// The database creates the pfile with its own mode.
+	if err := os.Chmod(backupPFileLoc, filePerm(0640)); err != nil {
+		klog.Warningf("dbdaemon/BackupConfigFile: failed to set the mode of %s: %v", backupPFileLoc, err)
+	}
 	klog.InfoS("dbdaemon/BackupConfigFile: Successfully backed up config file")
 	return nil
 }
 
 // RecoverConfigFile generates the binary spfile from the human readable backup pfile
 func (s *Server) RecoverConfigFile(ctx context.Context, req *dbdpb.RecoverConfigFileRequest) (*dbdpb.RecoverConfigFileResponse, error) {
-	configDir := fmt.Sprintf(consts.ConfigDir, consts.DataMount, req.GetCdbName())
+	configDir := configDir(req.GetCdbName())
 	backupPFileLoc := fmt.Sprintf("%s/%s", configDir, "pfile.lkws")
 	spFileLoc := fmt.Sprintf("%s/%s", configDir, fmt.Sprintf("spfile%s.ora", req.CdbName))
 
@@ -2058,10 +2191,11 @@ func (s *Server) RecoverConfigFile(ctx context.Context, req *dbdpb.RecoverConfig
 	return &dbdpb.RecoverConfigFileResponse{}, nil
 }
 
-// New creates a new dbdaemon server.
-func New(ctx context.Context, cdbNameFromYaml string) (*Server, error) {
+// New creates a new dbdaemon server. The proxy is dialed with the options
+// opts in addition to the defaults, e.g. its TLS credentials.
+func New(ctx context.Context, cdbNameFromYaml string, opts ...grpc.DialOption) (*Server, error) {
 	klog.InfoS("dbdaemon/New: Dialing dbdaemon proxy")
-	conn, err := common.DatabaseDaemonDialSocket(ctx, consts.ProxyDomainSocketFile, grpc.WithBlock())
+	conn, err := common.DatabaseDaemonDialSocket(ctx, consts.ProxyDomainSocketFile, append([]grpc.DialOption{grpc.WithBlock()}, opts...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial to database daemon: %v", err)
 	}
@@ -2082,6 +2216,8 @@ func New(ctx context.Context, cdbNameFromYaml string) (*Server, error) {
 		lroServer:      lro.NewServer(ctx),
 		syncJobs:       &syncJobs{},
 		gcsUtil:        &util.GCSUtilImpl{},
+		license:        &licenseState{},
+		operationLogs:  &operationLogs{},
 	}
 
 	oracleHome := os.Getenv("ORACLE_HOME")
@@ -2094,7 +2230,7 @@ func New(ctx context.Context, cdbNameFromYaml string) (*Server, error) {
 // DownloadDirectoryFromGCS downloads objects from GCS bucket using prefix
 func (s *Server) DownloadDirectoryFromGCS(ctx context.Context, req *dbdpb.DownloadDirectoryFromGCSRequest) (*dbdpb.DownloadDirectoryFromGCSResponse, error) {
 
-	klog.Infof("dbdaemon/DownloadDirectoryFromGCS: req %v", req)
+	klog.InfoS("dbdaemon/DownloadDirectoryFromGCS", "req", loggableRequest(req))
 	bucket, prefix, err := s.gcsUtil.SplitURI(req.GcsPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse gcs path %s", err)
@@ -2125,6 +2261,10 @@ func (s *Server) DownloadDirectoryFromGCS(ctx context.Context, req *dbdpb.Downlo
 			return nil, fmt.Errorf("Bucket(%q).Objects(): %v", bucket, err)
 		}
 
+		if excludedDir(attrs.Name, prefix, req.GetExcludeDirs()) {
+			continue
+		}
+
 		if req.GetAccessPermissionCheck() {
 			reader, err := client.Bucket(bucket).Object(attrs.Name).NewRangeReader(ctx, 0, 1)
 			if err != nil {
@@ -2147,7 +2287,22 @@ func (s *Server) FetchServiceImageMetaData(ctx context.Context, req *dbdpb.Fetch
 	if err != nil {
 		return &dbdpb.FetchServiceImageMetaDataResponse{}, err
 	}
-	return &dbdpb.FetchServiceImageMetaDataResponse{Version: proxyResponse.Version, CdbName: proxyResponse.CdbName, OracleHome: proxyResponse.OracleHome, SeededImage: proxyResponse.SeededImage}, nil
+	versionFull, err := homeVersion(proxyResponse.OracleHome)
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/FetchServiceImageMetaData: failed to get the full version", "oracleHome", proxyResponse.OracleHome)
+	}
+	return &dbdpb.FetchServiceImageMetaDataResponse{Version: proxyResponse.Version, CdbName: proxyResponse.CdbName, OracleHome: proxyResponse.OracleHome, SeededImage: proxyResponse.SeededImage, VersionFull: versionFull}, nil
+}
+
+// excludedDir returns whether the object name is in one of the dirs under
+// prefix.
+func excludedDir(name, prefix string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(name, path.Join(prefix, dir)+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *Server) downloadFile(ctx context.Context, c *storage.Client, bucket, gcsPath, baseDir, dest string) error {
@@ -2164,7 +2319,7 @@ func (s *Server) downloadFile(ctx context.Context, c *storage.Client, bucket, gc
 
 	f := filepath.Join(dest, relPath)
 	start := time.Now()
-	if err := s.osUtil.createFile(f, reader); err != nil {
+	if err := s.createOracleFile(f, reader); err != nil {
 		return fmt.Errorf("failed to createFile for file %s, err %s", f, err)
 	}
 	end := time.Now()
@@ -2209,7 +2364,7 @@ func (s *Server) BootstrapDatabaseAsync(ctx context.Context, req *dbdpb.Bootstra
 		})
 
 	if err != nil {
-		klog.ErrorS(err, "dbdaemon/BootstrapDatabaseAsync failed to create an LRO job", "request", req)
+		klog.ErrorS(err, "dbdaemon/BootstrapDatabaseAsync failed to create an LRO job", "request", loggableRequest(req))
 		return nil, err
 	}
 
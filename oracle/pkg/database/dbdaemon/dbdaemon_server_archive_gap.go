@@ -0,0 +1,172 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/pitr"
+)
+
+const (
+	archiveGapSQL = "select thread# as thread, low_sequence# as low_sequence, high_sequence# as high_sequence " +
+		"from v$archive_gap order by thread#, low_sequence#"
+	startApplySQL = "alter database recover managed standby database disconnect from session"
+)
+
+var (
+	// archiveLogGapStagingDir is where the missing logs are staged.
+	archiveLogGapStagingDir = filepath.Join(consts.RMANStagingDir, "gap")
+
+	// stageLogs stages the replicated logs, tests replace it.
+	stageLogs = pitr.StageLogs
+)
+
+// parseArchiveGaps returns the gaps of the archiveGapSQL rows.
+func parseArchiveGaps(rows []string) ([]*dbdpb.ResolveArchiveLogGapResponse_Gap, error) {
+	var gaps []*dbdpb.ResolveArchiveLogGapResponse_Gap
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse archive gap row %q: %v", msg, err)
+		}
+		var values [3]int64
+		for i, col := range []string{"THREAD", "LOW_SEQUENCE", "HIGH_SEQUENCE"} {
+			v, err := strconv.ParseInt(row[col], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s in archive gap row %q: %v", col, msg, err)
+			}
+			values[i] = v
+		}
+		gaps = append(gaps, &dbdpb.ResolveArchiveLogGapResponse_Gap{
+			Thread:       values[0],
+			LowSequence:  values[1],
+			HighSequence: values[2],
+		})
+	}
+	return gaps, nil
+}
+
+// inArchiveGaps reports whether a replicated log is missing on the standby.
+func inArchiveGaps(gaps []*dbdpb.ResolveArchiveLogGapResponse_Gap, entry pitr.LogMetadataEntry) bool {
+	thread, err := strconv.ParseInt(entry.Thread, 10, 64)
+	if err != nil {
+		return false
+	}
+	sequence, err := strconv.ParseInt(entry.Sequence, 10, 64)
+	if err != nil {
+		return false
+	}
+	for _, gap := range gaps {
+		if gap.GetThread() == thread && sequence >= gap.GetLowSequence() && sequence <= gap.GetHighSequence() {
+			return true
+		}
+	}
+	return false
+}
+
+// registerLogfileStatements returns the statements registering the staged
+// logs on the standby.
+func registerLogfileStatements(logs []string) []string {
+	var stmts []string
+	for _, log := range logs {
+		stmts = append(stmts, fmt.Sprintf("alter database register or replace logfile '%s'", sqlq.StringParam(log)))
+	}
+	return stmts
+}
+
+// stageGapLogs stages the replicated logs of the gaps into an empty
+// archiveLogGapStagingDir and returns them.
+func stageGapLogs(ctx context.Context, gaps []*dbdpb.ResolveArchiveLogGapResponse_Gap, logGcsPath string) ([]string, error) {
+	if err := os.RemoveAll(archiveLogGapStagingDir); err != nil {
+		return nil, fmt.Errorf("failed to clean up the staging dir: %v", err)
+	}
+	if err := os.MkdirAll(archiveLogGapStagingDir, dirPerm(0750)); err != nil {
+		return nil, fmt.Errorf("failed to create the staging dir: %v", err)
+	}
+	include := func(entry pitr.LogMetadataEntry) bool { return inArchiveGaps(gaps, entry) }
+	if err := stageLogs(ctx, archiveLogGapStagingDir, include, logGcsPath); err != nil {
+		return nil, fmt.Errorf("failed to stage the missing logs: %v", err)
+	}
+	entries, err := os.ReadDir(archiveLogGapStagingDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the staged logs: %v", err)
+	}
+	var logs []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			logs = append(logs, filepath.Join(archiveLogGapStagingDir, e.Name()))
+		}
+	}
+	sort.Strings(logs)
+	return logs, nil
+}
+
+// ResolveArchiveLogGap stages the archived logs missing on a standby from
+// the replicated logs in GCS, registers them and restarts redo apply.
+func (s *Server) ResolveArchiveLogGap(ctx context.Context, req *dbdpb.ResolveArchiveLogGapRequest) (*dbdpb.ResolveArchiveLogGapResponse, error) {
+	klog.InfoS("dbdaemon/ResolveArchiveLogGap", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	gapResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{archiveGapSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: failed to query the archive gaps: %v", err)
+	}
+	gaps, err := parseArchiveGaps(gapResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: %v", err)
+	}
+	resp := &dbdpb.ResolveArchiveLogGapResponse{Gaps: gaps}
+
+	if len(gaps) > 0 && req.GetLogGcsPath() != "" {
+		logs, err := stageGapLogs(ctx, gaps, req.GetLogGcsPath())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: %v", err)
+		}
+		if len(logs) > 0 {
+			if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: registerLogfileStatements(logs)}, false); err != nil {
+				return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: failed to register the staged logs: %v", err)
+			}
+		}
+		resp.RegisteredLogs = logs
+	}
+
+	mrpResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{mrpStatusSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: failed to query the managed recovery process: %v", err)
+	}
+	if len(mrpResp.GetMsg()) == 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{startApplySQL}}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ResolveArchiveLogGap: failed to start redo apply: %v", err)
+		}
+		resp.ApplyRestarted = true
+	}
+	klog.InfoS("dbdaemon/ResolveArchiveLogGap: done", "gaps", len(gaps), "registeredLogs", len(resp.GetRegisteredLogs()), "applyRestarted", resp.GetApplyRestarted())
+	return resp, nil
+}
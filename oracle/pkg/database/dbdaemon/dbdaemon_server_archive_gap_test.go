@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/pitr"
+)
+
+func TestParseArchiveGaps(t *testing.T) {
+	got, err := parseArchiveGaps([]string{
+		`{"THREAD":"1","LOW_SEQUENCE":"40","HIGH_SEQUENCE":"42"}`,
+		`{"THREAD":"2","LOW_SEQUENCE":"17","HIGH_SEQUENCE":"17"}`,
+	})
+	if err != nil {
+		t.Fatalf("parseArchiveGaps failed: %v", err)
+	}
+	want := []*dbdpb.ResolveArchiveLogGapResponse_Gap{
+		{Thread: 1, LowSequence: 40, HighSequence: 42},
+		{Thread: 2, LowSequence: 17, HighSequence: 17},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseArchiveGaps got unexpected gaps (-want +got):\n%v", diff)
+	}
+	if _, err := parseArchiveGaps([]string{`{"THREAD":"1","LOW_SEQUENCE":"","HIGH_SEQUENCE":"42"}`}); err == nil {
+		t.Errorf("parseArchiveGaps of a row without LOW_SEQUENCE succeeded, want error")
+	}
+}
+
+func TestInArchiveGaps(t *testing.T) {
+	gaps := []*dbdpb.ResolveArchiveLogGapResponse_Gap{
+		{Thread: 1, LowSequence: 40, HighSequence: 42},
+		{Thread: 2, LowSequence: 17, HighSequence: 17},
+	}
+	tests := []struct {
+		thread   string
+		sequence string
+		want     bool
+	}{
+		{thread: "1", sequence: "39", want: false},
+		{thread: "1", sequence: "40", want: true},
+		{thread: "1", sequence: "42", want: true},
+		{thread: "1", sequence: "43", want: false},
+		{thread: "2", sequence: "17", want: true},
+		{thread: "2", sequence: "41", want: false},
+		{thread: "", sequence: "41", want: false},
+	}
+	for _, tc := range tests {
+		entry := pitr.LogMetadataEntry{Thread: tc.thread, Sequence: tc.sequence}
+		if got := inArchiveGaps(gaps, entry); got != tc.want {
+			t.Errorf("inArchiveGaps of thread %q sequence %q got %v, want %v", tc.thread, tc.sequence, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterLogfileStatements(t *testing.T) {
+	got := registerLogfileStatements([]string{"/u03/gap/1_40_1098.arc", "/u03/gap/it's.arc"})
+	want := []string{
+		"alter database register or replace logfile '/u03/gap/1_40_1098.arc'",
+		"alter database register or replace logfile '/u03/gap/it''s.arc'",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("registerLogfileStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+}
+
+// useFakeStageLogs stages an empty file for each replicated log of logs
+// included by the gaps.
+func useFakeStageLogs(t *testing.T, logs map[string]pitr.LogMetadataEntry) {
+	t.Helper()
+	origStage, origDir := stageLogs, archiveLogGapStagingDir
+	archiveLogGapStagingDir = filepath.Join(t.TempDir(), "gap")
+	stageLogs = func(ctx context.Context, destDir string, include func(entry pitr.LogMetadataEntry) bool, logPath string) error {
+		for name, entry := range logs {
+			if include(entry) {
+				if err := ioutil.WriteFile(filepath.Join(destDir, name), nil, 0640); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	t.Cleanup(func() { stageLogs, archiveLogGapStagingDir = origStage, origDir })
+}
+
+func TestResolveArchiveLogGap(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	useFakeStageLogs(t, map[string]pitr.LogMetadataEntry{
+		"1_39_1098.arc": {Thread: "1", Sequence: "39"},
+		"1_40_1098.arc": {Thread: "1", Sequence: "40"},
+		"1_41_1098.arc": {Thread: "1", Sequence: "41"},
+	})
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var executed []string
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case archiveGapSQL:
+			return []string{`{"THREAD":"1","LOW_SEQUENCE":"40","HIGH_SEQUENCE":"41"}`}, nil
+		case mrpStatusSQL:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		executed = append(executed, sqls...)
+		return nil, nil
+	}
+
+	got, err := s.ResolveArchiveLogGap(ctx, &dbdpb.ResolveArchiveLogGapRequest{LogGcsPath: "gs://bucket/pitr"})
+	if err != nil {
+		t.Fatalf("ResolveArchiveLogGap failed: %v", err)
+	}
+	logs := []string{
+		filepath.Join(archiveLogGapStagingDir, "1_40_1098.arc"),
+		filepath.Join(archiveLogGapStagingDir, "1_41_1098.arc"),
+	}
+	want := &dbdpb.ResolveArchiveLogGapResponse{
+		Gaps:           []*dbdpb.ResolveArchiveLogGapResponse_Gap{{Thread: 1, LowSequence: 40, HighSequence: 41}},
+		RegisteredLogs: logs,
+		ApplyRestarted: true,
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("ResolveArchiveLogGap got unexpected response (-want +got):\n%v", diff)
+	}
+	wantExecuted := append(registerLogfileStatements(logs), startApplySQL)
+	if diff := cmp.Diff(wantExecuted, executed); diff != "" {
+		t.Errorf("ResolveArchiveLogGap ran unexpected statements (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	createAWRSnapshotCmd = "begin dbms_workload_repository.create_snapshot(); end;"
+
+	lastAWRSnapshotSQL = "select nvl(max(snap_id), 0) snap_id from dba_hist_snapshot where dbid=(select dbid from v$database)"
+
+	// firstAWRSnapshotSQL finds the oldest snapshot of the window ending at
+	// a snapshot.
+	firstAWRSnapshotSQL = "select nvl(min(snap_id), 0) snap_id from dba_hist_snapshot " +
+		"where dbid=(select dbid from v$database) and begin_interval_time >= systimestamp - numtodsinterval(%d, 'HOUR') and snap_id < %d"
+
+	createAWRBaselineCmd = "begin dbms_workload_repository.create_baseline(" +
+		"start_snap_id => %d, end_snap_id => %d, baseline_name => '%s', expiration => %s); end;"
+
+	createAWRBaselineTemplateCmd = "begin dbms_workload_repository.create_baseline_template(" +
+		"start_time => sysdate, end_time => sysdate + %d/24, baseline_name => '%s', template_name => '%s', expiration => %s); end;"
+
+	// maxAWRBaselineWindowHours bounds the window of a baseline to the
+	// longest default AWR retention.
+	maxAWRBaselineWindowHours = 8 * 24
+
+	awrSettingsSQL = "select extract(day from retention) retention_days, " +
+		"extract(day from snap_interval)*1440 + extract(hour from snap_interval)*60 + extract(minute from snap_interval) interval_minutes " +
+		"from dba_hist_wr_control where dbid=(select dbid from v$database)"
+
+	// modifyAWRSettingsCmd sets the retention and the interval in minutes,
+	// null leaves a setting unchanged.
+	modifyAWRSettingsCmd = "declare w number; begin %sdbms_workload_repository.modify_snapshot_settings(retention => %s, interval => %s); end;"
+
+	// shrinkAWRWindowCmd shrinks the moving window baseline to a number of
+	// days as the retention can't be shorter than the moving window.
+	shrinkAWRWindowCmd = "select nvl(max(moving_window_size), 0) into w from dba_hist_baseline where baseline_type='MOVING_WINDOW' and dbid=(select dbid from v$database); " +
+		"if w > %[1]d then dbms_workload_repository.modify_baseline_window_size(window_size => %[1]d); end if; "
+
+	// Sensible AWR settings: keeping snapshots for 1 day to 10 years, taken
+	// every 10 minutes to once a day.
+	minAWRRetentionDays   = 1
+	maxAWRRetentionDays   = 3650
+	minAWRIntervalMinutes = 10
+	maxAWRIntervalMinutes = 24 * 60
+)
+
+// awrExpiration converts expiration days into the expiration argument of
+// DBMS_WORKLOAD_REPOSITORY, null keeps the baseline forever.
+func awrExpiration(days int32) string {
+	if days == 0 {
+		return "null"
+	}
+	return strconv.Itoa(int(days))
+}
+
+// validateAWRBaselineRequest checks the name and the window of a baseline.
+func validateAWRBaselineRequest(req *dbdpb.CreateAWRBaselineRequest) error {
+	if _, err := sqlq.ObjectName(req.GetBaselineName()); err != nil || req.GetBaselineName() == "" || len(req.GetBaselineName()) > 64 {
+		return fmt.Errorf("invalid baseline name %q", req.GetBaselineName())
+	}
+	if req.GetWindowHours() <= 0 || req.GetWindowHours() > maxAWRBaselineWindowHours {
+		return fmt.Errorf("window of %d hours is out of range, it has to be between 1 and %d hours", req.GetWindowHours(), maxAWRBaselineWindowHours)
+	}
+	if req.GetExpirationDays() < 0 {
+		return fmt.Errorf("negative expiration of %d days", req.GetExpirationDays())
+	}
+	return nil
+}
+
+// awrBaselineCmd returns the statement creating the baseline of the request
+// between two snapshots.
+func awrBaselineCmd(req *dbdpb.CreateAWRBaselineRequest, startSnapID, endSnapID int64) string {
+	return fmt.Sprintf(createAWRBaselineCmd, startSnapID, endSnapID, sqlq.StringParam(req.GetBaselineName()), awrExpiration(req.GetExpirationDays()))
+}
+
+// awrBaselineTemplateCmd returns the statement scheduling the baseline of
+// the request for the next window, the template is named after the
+// baseline.
+func awrBaselineTemplateCmd(req *dbdpb.CreateAWRBaselineRequest) string {
+	name := sqlq.StringParam(req.GetBaselineName())
+	return fmt.Sprintf(createAWRBaselineTemplateCmd, req.GetWindowHours(), name, name, awrExpiration(req.GetExpirationDays()))
+}
+
+// parseSnapID reads the SNAP_ID of the single row of an AWR snapshot query.
+func parseSnapID(rows []string) (int64, error) {
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("expected 1 snapshot row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return 0, fmt.Errorf("failed to parse snapshot row %q: %v", rows[0], err)
+	}
+	id, err := strconv.ParseInt(row["SNAP_ID"], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse SNAP_ID in snapshot row %q: %v", rows[0], err)
+	}
+	return id, nil
+}
+
+// CreateAWRBaseline creates a baseline of the last window up to a new AWR
+// snapshot, or schedules the baseline of the next window.
+func (s *Server) CreateAWRBaseline(ctx context.Context, req *dbdpb.CreateAWRBaselineRequest) (*dbdpb.CreateAWRBaselineResponse, error) {
+	klog.InfoS("dbdaemon/CreateAWRBaseline", "req", loggableRequest(req))
+	if err := validateAWRBaselineRequest(req); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: %v", err)
+	}
+	if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	packs, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{managementPacksSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to query the management packs: %v", err)
+	}
+	if !managementPackEnabled(packs.GetMsg(), "DIAGNOSTIC") {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: the Diagnostics Pack isn't enabled, control_management_pack_access has to include DIAGNOSTIC")
+	}
+
+	if req.GetFuture() {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{awrBaselineTemplateCmd(req)}}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to schedule baseline %s: %v", req.GetBaselineName(), err)
+		}
+		return &dbdpb.CreateAWRBaselineResponse{}, nil
+	}
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{createAWRSnapshotCmd}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to create an AWR snapshot: %v", err)
+	}
+	lastResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{lastAWRSnapshotSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to query the AWR snapshot: %v", err)
+	}
+	endSnapID, err := parseSnapID(lastResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: %v", err)
+	}
+	firstResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{fmt.Sprintf(firstAWRSnapshotSQL, req.GetWindowHours(), endSnapID)},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to query the AWR snapshots: %v", err)
+	}
+	startSnapID, err := parseSnapID(firstResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: %v", err)
+	}
+	if startSnapID == 0 {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: no AWR snapshot in the last %d hours", req.GetWindowHours())
+	}
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{awrBaselineCmd(req, startSnapID, endSnapID)}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateAWRBaseline: failed to create baseline %s: %v", req.GetBaselineName(), err)
+	}
+	return &dbdpb.CreateAWRBaselineResponse{StartSnapId: startSnapID, EndSnapId: endSnapID}, nil
+}
+
+// validateAWRSettings checks the AWR settings of the request are within
+// sensible bounds.
+func validateAWRSettings(req *dbdpb.ConfigureAWRRequest) error {
+	if d := req.GetRetentionDays(); d != 0 && (d < minAWRRetentionDays || d > maxAWRRetentionDays) {
+		return fmt.Errorf("retention of %d days is out of range, it has to be between %d and %d days", d, minAWRRetentionDays, maxAWRRetentionDays)
+	}
+	if m := req.GetSnapshotIntervalMinutes(); m != 0 && (m < minAWRIntervalMinutes || m > maxAWRIntervalMinutes) {
+		return fmt.Errorf("snapshot interval of %d minutes is out of range, it has to be between %d and %d minutes", m, minAWRIntervalMinutes, maxAWRIntervalMinutes)
+	}
+	return nil
+}
+
+// parseAWRSettings reads the retention and the interval of the
+// awrSettingsSQL row.
+func parseAWRSettings(rows []string) (*dbdpb.ConfigureAWRResponse, error) {
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("expected 1 AWR settings row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse AWR settings row %q: %v", rows[0], err)
+	}
+	retention, err := strconv.ParseInt(row["RETENTION_DAYS"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RETENTION_DAYS in AWR settings row %q: %v", rows[0], err)
+	}
+	interval, err := strconv.ParseInt(row["INTERVAL_MINUTES"], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse INTERVAL_MINUTES in AWR settings row %q: %v", rows[0], err)
+	}
+	return &dbdpb.ConfigureAWRResponse{RetentionDays: int32(retention), SnapshotIntervalMinutes: int32(interval)}, nil
+}
+
+// awrSettingsCmd returns the statement modifying the current AWR settings
+// into the ones of the request, or "" if they don't differ.
+func awrSettingsCmd(req *dbdpb.ConfigureAWRRequest, current *dbdpb.ConfigureAWRResponse) string {
+	retention, interval, shrinkWindow := "null", "null", ""
+	if d := req.GetRetentionDays(); d != 0 && d != current.GetRetentionDays() {
+		retention = strconv.Itoa(int(d) * 24 * 60)
+		shrinkWindow = fmt.Sprintf(shrinkAWRWindowCmd, d)
+	}
+	if m := req.GetSnapshotIntervalMinutes(); m != 0 && m != current.GetSnapshotIntervalMinutes() {
+		interval = strconv.Itoa(int(m))
+	}
+	if retention == "null" && interval == "null" {
+		return ""
+	}
+	return fmt.Sprintf(modifyAWRSettingsCmd, shrinkWindow, retention, interval)
+}
+
+// ConfigureAWR modifies the AWR snapshot settings which differ from the
+// request.
+func (s *Server) ConfigureAWR(ctx context.Context, req *dbdpb.ConfigureAWRRequest) (*dbdpb.ConfigureAWRResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureAWR", "req", loggableRequest(req))
+	if err := validateAWRSettings(req); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAWR: %v", err)
+	}
+	if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAWR: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	settings, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{awrSettingsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAWR: failed to query the AWR settings: %v", err)
+	}
+	current, err := parseAWRSettings(settings.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAWR: %v", err)
+	}
+	cmd := awrSettingsCmd(req, current)
+	if cmd == "" {
+		return current, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{cmd}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAWR: failed to modify the AWR settings: %v", err)
+	}
+	resp := &dbdpb.ConfigureAWRResponse{
+		RetentionDays:           current.GetRetentionDays(),
+		SnapshotIntervalMinutes: current.GetSnapshotIntervalMinutes(),
+		AppliedStatement:        cmd,
+	}
+	if req.GetRetentionDays() != 0 {
+		resp.RetentionDays = req.GetRetentionDays()
+	}
+	if req.GetSnapshotIntervalMinutes() != 0 {
+		resp.SnapshotIntervalMinutes = req.GetSnapshotIntervalMinutes()
+	}
+	return resp, nil
+}
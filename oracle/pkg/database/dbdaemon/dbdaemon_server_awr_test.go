@@ -0,0 +1,117 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestAWRBaselineCmds(t *testing.T) {
+	req := &dbdpb.CreateAWRBaselineRequest{BaselineName: "ELCARRO_PRE_PATCH_20220304050607", WindowHours: 24}
+	if err := validateAWRBaselineRequest(req); err != nil {
+		t.Fatalf("validateAWRBaselineRequest(%v) failed: %v", req, err)
+	}
+	want := "begin dbms_workload_repository.create_baseline(start_snap_id => 101, end_snap_id => 125, baseline_name => 'ELCARRO_PRE_PATCH_20220304050607', expiration => null); end;"
+	if got := awrBaselineCmd(req, 101, 125); got != want {
+		t.Errorf("awrBaselineCmd got %q, want %q", got, want)
+	}
+
+	req = &dbdpb.CreateAWRBaselineRequest{BaselineName: "ELCARRO_POST_PATCH_20220304050607", WindowHours: 24, Future: true, ExpirationDays: 30}
+	want = "begin dbms_workload_repository.create_baseline_template(start_time => sysdate, end_time => sysdate + 24/24, " +
+		"baseline_name => 'ELCARRO_POST_PATCH_20220304050607', template_name => 'ELCARRO_POST_PATCH_20220304050607', expiration => 30); end;"
+	if got := awrBaselineTemplateCmd(req); got != want {
+		t.Errorf("awrBaselineTemplateCmd got %q, want %q", got, want)
+	}
+
+	for _, req := range []*dbdpb.CreateAWRBaselineRequest{
+		{WindowHours: 24},
+		{BaselineName: `b"; drop table x`, WindowHours: 24},
+		{BaselineName: "B1"},
+		{BaselineName: "B1", WindowHours: 24 * 30},
+		{BaselineName: "B1", WindowHours: 24, ExpirationDays: -1},
+	} {
+		if err := validateAWRBaselineRequest(req); err == nil {
+			t.Errorf("validateAWRBaselineRequest(%v) succeeded, want error", req)
+		}
+	}
+
+	if id, err := parseSnapID([]string{`{"SNAP_ID": "125"}`}); err != nil || id != 125 {
+		t.Errorf("parseSnapID got %d, %v, want 125", id, err)
+	}
+	if _, err := parseSnapID(nil); err == nil {
+		t.Errorf("parseSnapID without rows succeeded, want error")
+	}
+}
+
+func TestAWRSettingsCmd(t *testing.T) {
+	current := &dbdpb.ConfigureAWRResponse{RetentionDays: 8, SnapshotIntervalMinutes: 60}
+	tests := []struct {
+		name string
+		req  *dbdpb.ConfigureAWRRequest
+		want string
+	}{
+		{
+			name: "retention and interval",
+			req:  &dbdpb.ConfigureAWRRequest{RetentionDays: 30, SnapshotIntervalMinutes: 15},
+			want: "declare w number; begin select nvl(max(moving_window_size), 0) into w from dba_hist_baseline where baseline_type='MOVING_WINDOW' and dbid=(select dbid from v$database); " +
+				"if w > 30 then dbms_workload_repository.modify_baseline_window_size(window_size => 30); end if; " +
+				"dbms_workload_repository.modify_snapshot_settings(retention => 43200, interval => 15); end;",
+		},
+		{
+			name: "interval only",
+			req:  &dbdpb.ConfigureAWRRequest{SnapshotIntervalMinutes: 30},
+			want: "declare w number; begin dbms_workload_repository.modify_snapshot_settings(retention => null, interval => 30); end;",
+		},
+		{
+			name: "unchanged",
+			req:  &dbdpb.ConfigureAWRRequest{RetentionDays: 8, SnapshotIntervalMinutes: 60},
+		},
+		{
+			name: "not set",
+			req:  &dbdpb.ConfigureAWRRequest{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateAWRSettings(tc.req); err != nil {
+				t.Fatalf("validateAWRSettings(%v) failed: %v", tc.req, err)
+			}
+			if got := awrSettingsCmd(tc.req, current); got != tc.want {
+				t.Errorf("awrSettingsCmd(%v) got %q, want %q", tc.req, got, tc.want)
+			}
+		})
+	}
+
+	for _, req := range []*dbdpb.ConfigureAWRRequest{
+		{RetentionDays: -1},
+		{RetentionDays: 4000},
+		{SnapshotIntervalMinutes: 5},
+		{SnapshotIntervalMinutes: 2 * 24 * 60},
+	} {
+		if err := validateAWRSettings(req); err == nil {
+			t.Errorf("validateAWRSettings(%v) succeeded, want error", req)
+		}
+	}
+
+	got, err := parseAWRSettings([]string{`{"RETENTION_DAYS": "8", "INTERVAL_MINUTES": "60"}`})
+	if err != nil {
+		t.Fatalf("parseAWRSettings failed: %v", err)
+	}
+	if got.GetRetentionDays() != 8 || got.GetSnapshotIntervalMinutes() != 60 {
+		t.Errorf("parseAWRSettings got %v, want retention of 8 days and interval of 60 minutes", got)
+	}
+}
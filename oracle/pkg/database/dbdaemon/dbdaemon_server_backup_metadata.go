@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// backupMetadataObject is the name of the metadata object under the GCS
+// prefix of a backup.
+const backupMetadataObject = "backup_metadata.json"
+
+// backupMetadataFile is the format of the backup metadata object.
+type backupMetadataFile struct {
+	Tag           string    `json:"tag"`
+	SCN           string    `json:"scn"`
+	Incarnation   string    `json:"incarnation"`
+	Timestamp     time.Time `json:"timestamp"`
+	SourceVersion string    `json:"sourceVersion,omitempty"`
+	SizeBytes     int64     `json:"sizeBytes"`
+	Type          string    `json:"type"`
+}
+
+// backupMetadataPath returns the path of the metadata object of the backup
+// with the GCS prefix.
+func backupMetadataPath(gcsPath string) (string, error) {
+	if !strings.HasPrefix(gcsPath, "gs://") {
+		return "", fmt.Errorf("invalid GCS path %q", gcsPath)
+	}
+	return strings.TrimSuffix(gcsPath, "/") + "/" + backupMetadataObject, nil
+}
+
+// WriteBackupMetadata writes the metadata of a backup to a JSON object next
+// to the backup in GCS.
+func (s *Server) WriteBackupMetadata(ctx context.Context, req *dbdpb.WriteBackupMetadataRequest) (*dbdpb.WriteBackupMetadataResponse, error) {
+	klog.InfoS("dbdaemon/WriteBackupMetadata", "req", loggableRequest(req))
+	path, err := backupMetadataPath(req.GetGcsPath())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: %v", err)
+	}
+	m := req.GetMetadata()
+	if m.GetTag() == "" {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: backup tag is empty")
+	}
+	content, err := json.MarshalIndent(backupMetadataFile{
+		Tag:           m.GetTag(),
+		SCN:           m.GetScn(),
+		Incarnation:   m.GetIncarnation(),
+		Timestamp:     m.GetTimestamp().AsTime().UTC(),
+		SourceVersion: m.GetSourceVersion(),
+		SizeBytes:     m.GetSizeBytes(),
+		Type:          m.GetType(),
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: failed to encode the metadata: %v", err)
+	}
+
+	f, err := ioutil.TempFile(scratchDir(), "backup_metadata")
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: %v", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: %v", err)
+	}
+	if err := s.gcsUtil.UploadFile(ctx, path, f.Name(), contentTypeJSON); err != nil {
+		return nil, fmt.Errorf("dbdaemon/WriteBackupMetadata: failed to upload %s: %v", path, err)
+	}
+	return &dbdpb.WriteBackupMetadataResponse{}, nil
+}
+
+// ReadBackupMetadata reads the metadata object of a backup in GCS. Backups
+// taken before the metadata objects were written have none, which isn't an
+// error.
+func (s *Server) ReadBackupMetadata(ctx context.Context, req *dbdpb.ReadBackupMetadataRequest) (*dbdpb.ReadBackupMetadataResponse, error) {
+	klog.InfoS("dbdaemon/ReadBackupMetadata", "req", loggableRequest(req))
+	path, err := backupMetadataPath(req.GetGcsPath())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ReadBackupMetadata: %v", err)
+	}
+	r, err := s.gcsUtil.Download(ctx, path)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return &dbdpb.ReadBackupMetadataResponse{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ReadBackupMetadata: %v", err)
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ReadBackupMetadata: failed to read %s: %v", path, err)
+	}
+	var m backupMetadataFile
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ReadBackupMetadata: failed to parse %s: %v", path, err)
+	}
+	return &dbdpb.ReadBackupMetadataResponse{
+		Metadata: &dbdpb.BackupMetadata{
+			Tag:           m.Tag,
+			Scn:           m.SCN,
+			Incarnation:   m.Incarnation,
+			Timestamp:     timestamppb.New(m.Timestamp),
+			SourceVersion: m.SourceVersion,
+			SizeBytes:     m.SizeBytes,
+			Type:          m.Type,
+		},
+		Found: true,
+	}, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestBackupMetadata(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	gcs := &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	s.gcsUtil = gcs
+
+	metadata := &dbdpb.BackupMetadata{
+		Tag:           "20220501010000",
+		Scn:           "1530961",
+		Incarnation:   "2",
+		Timestamp:     timestamppb.New(time.Date(2022, 5, 1, 1, 2, 3, 0, time.UTC)),
+		SourceVersion: "19.15.0.0.0",
+		SizeBytes:     1 << 30,
+		Type:          "Physical",
+	}
+	if _, err := s.WriteBackupMetadata(ctx, &dbdpb.WriteBackupMetadataRequest{GcsPath: "gs://bucket/backups/bkp/", Metadata: metadata}); err != nil {
+		t.Fatalf("WriteBackupMetadata failed: %v", err)
+	}
+	want := `{
+  "tag": "20220501010000",
+  "scn": "1530961",
+  "incarnation": "2",
+  "timestamp": "2022-05-01T01:02:03Z",
+  "sourceVersion": "19.15.0.0.0",
+  "sizeBytes": 1073741824,
+  "type": "Physical"
+}`
+	if diff := cmp.Diff(want, gcs.uploads["gs://bucket/backups/bkp/backup_metadata.json"]); diff != "" {
+		t.Errorf("WriteBackupMetadata got unexpected object (-want +got):\n%v", diff)
+	}
+	if got := gcs.contentTypes["gs://bucket/backups/bkp/backup_metadata.json"]; got != contentTypeJSON {
+		t.Errorf("WriteBackupMetadata uploaded the object as %q, want %q", got, contentTypeJSON)
+	}
+
+	resp, err := s.ReadBackupMetadata(ctx, &dbdpb.ReadBackupMetadataRequest{GcsPath: "gs://bucket/backups/bkp"})
+	if err != nil {
+		t.Fatalf("ReadBackupMetadata failed: %v", err)
+	}
+	if diff := cmp.Diff(&dbdpb.ReadBackupMetadataResponse{Metadata: metadata, Found: true}, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ReadBackupMetadata got unexpected response (-want +got):\n%v", diff)
+	}
+
+	// Backups taken before the metadata objects were written have none.
+	resp, err = s.ReadBackupMetadata(ctx, &dbdpb.ReadBackupMetadataRequest{GcsPath: "gs://bucket/backups/old"})
+	if err != nil {
+		t.Fatalf("ReadBackupMetadata of an old backup failed: %v", err)
+	}
+	if resp.GetFound() || resp.GetMetadata() != nil {
+		t.Errorf("ReadBackupMetadata of an old backup got %v, want no metadata", resp)
+	}
+
+	gcs.uploads["gs://bucket/backups/broken/backup_metadata.json"] = "{"
+	if _, err := s.ReadBackupMetadata(ctx, &dbdpb.ReadBackupMetadataRequest{GcsPath: "gs://bucket/backups/broken"}); err == nil {
+		t.Errorf("ReadBackupMetadata of a broken object succeeded, want an error")
+	}
+}
+
+func TestWriteBackupMetadataErrors(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.gcsUtil = &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	for _, req := range []*dbdpb.WriteBackupMetadataRequest{
+		{GcsPath: "/u03/backups", Metadata: &dbdpb.BackupMetadata{Tag: "20220501010000"}},
+		{GcsPath: "gs://bucket/backups/bkp", Metadata: &dbdpb.BackupMetadata{}},
+	} {
+		if _, err := s.WriteBackupMetadata(ctx, req); err == nil {
+			t.Errorf("WriteBackupMetadata(%v) succeeded, want an error", req)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// createOrReplaceDirCmd recreates the directory object when it already
+// exists, so a directory object pointing to a stale path is fixed up.
+const createOrReplaceDirCmd = "create or replace directory %s as '%s'"
+
+// dataPumpDir returns the OS directory consts.DpdumpDir.Oracle points to in
+// the PDB.
+var dataPumpDir = func(sid, pdbName string) string {
+	return filepath.Join(fmt.Sprintf(consts.PDBPathPrefix, consts.DataMount, sid, strings.ToUpper(pdbName)), consts.DpdumpDir.Linux)
+}
+
+// dataPumpDirStatements returns the statements creating the directory object
+// for path in the PDB and granting the PDB loader user access to it.
+func dataPumpDirStatements(pdbName, path string) []string {
+	return []string{
+		sqlq.QuerySetSessionContainer(pdbName),
+		fmt.Sprintf(createOrReplaceDirCmd, sqlq.MustBeObjectName(consts.DpdumpDir.Oracle), sqlq.StringParam(path)),
+		sqlq.QueryGrantPrivileges(fmt.Sprintf("read,write on directory %s", consts.DpdumpDir.Oracle), consts.PDBLoaderUser),
+	}
+}
+
+// firstMissingDir returns the outermost directory of path which doesn't
+// exist yet, or "" if path exists.
+func firstMissingDir(path string) string {
+	missing := ""
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			return missing
+		}
+		missing = dir
+		if parent := filepath.Dir(dir); parent == dir {
+			return missing
+		}
+	}
+}
+
+// CreateDataPumpDir creates the OS directory, the directory object and the
+// grants Data Pump imports and exports of the PDB need. It is idempotent and
+// removes the OS directories it created if the directory object can't be
+// created, so the two are never left inconsistent by a failed call.
+func (s *Server) CreateDataPumpDir(ctx context.Context, req *dbdpb.CreateDataPumpDirRequest) (*dbdpb.CreateDataPumpDirResponse, error) {
+	klog.InfoS("dbdaemon/CreateDataPumpDir", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/CreateDataPumpDir: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	path := dataPumpDir(s.databaseSid.val, req.GetPdbName())
+	created := firstMissingDir(path)
+	if err := os.MkdirAll(path, dirPerm(0760)); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateDataPumpDir: failed to create %s: %v", path, err)
+	}
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: dataPumpDirStatements(req.GetPdbName(), path)}, false); err != nil {
+		if created != "" {
+			if err := os.RemoveAll(created); err != nil {
+				klog.ErrorS(err, "dbdaemon/CreateDataPumpDir: failed to remove the directory", "path", created)
+			}
+		}
+		return nil, fmt.Errorf("dbdaemon/CreateDataPumpDir: failed to create directory %s: %v", consts.DpdumpDir.Oracle, err)
+	}
+	klog.InfoS("dbdaemon/CreateDataPumpDir: created the Data Pump directory", "pdb", req.GetPdbName(), "path", path)
+	return &dbdpb.CreateDataPumpDirResponse{Path: path, DirectoryName: consts.DpdumpDir.Oracle}, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestCreateDataPumpDir(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	root := t.TempDir()
+	path := filepath.Join(root, "PDB1", "dmp")
+	orig := dataPumpDir
+	dataPumpDir = func(string, string) string { return path }
+	t.Cleanup(func() { dataPumpDir = orig })
+
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var gotSQLs []string
+	sqlErr := errors.New("ORA-01031: insufficient privileges")
+	useFakeSQLDB(s).runSQLFunc = func(sqls []string) ([]string, error) {
+		gotSQLs = append(gotSQLs, sqls...)
+		return nil, sqlErr
+	}
+
+	// A failed call leaves no directory behind, parents included.
+	if _, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: "pdb1"}); err == nil {
+		t.Fatalf("CreateDataPumpDir succeeded with failing statements, want error")
+	}
+	if _, err := os.Stat(filepath.Join(root, "PDB1")); !os.IsNotExist(err) {
+		t.Errorf("CreateDataPumpDir left %s behind after a failure, got stat error %v", filepath.Join(root, "PDB1"), err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("CreateDataPumpDir removed the existing %s after a failure: %v", root, err)
+	}
+
+	sqlErr = nil
+	for i := 0; i < 2; i++ {
+		gotSQLs = nil
+		resp, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: "pdb1"})
+		if err != nil {
+			t.Fatalf("CreateDataPumpDir call %d failed: %v", i, err)
+		}
+		if resp.GetPath() != path || resp.GetDirectoryName() != "PDB_DATA_PUMP_DIR" {
+			t.Errorf("CreateDataPumpDir call %d = %v, want path %s and directory PDB_DATA_PUMP_DIR", i, resp, path)
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			t.Errorf("CreateDataPumpDir call %d did not create directory %s: %v", i, path, err)
+		}
+		wantSQLs := []string{
+			`alter session set container="PDB1"`,
+			"create or replace directory \"PDB_DATA_PUMP_DIR\" as '" + path + "'",
+			`grant read,write on directory PDB_DATA_PUMP_DIR to "GCSQL$PDBLOADER"`,
+		}
+		if diff := cmp.Diff(wantSQLs, gotSQLs); diff != "" {
+			t.Errorf("CreateDataPumpDir call %d got unexpected statements (-want +got):\n%v", i, diff)
+		}
+	}
+
+	if _, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: `pdb"1`}); err == nil {
+		t.Errorf("CreateDataPumpDir with an invalid PDB name succeeded, want error")
+	}
+}
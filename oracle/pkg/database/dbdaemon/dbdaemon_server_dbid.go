@@ -0,0 +1,63 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const dbidSQL = "select dbid, name from v$database"
+
+// parseDBID returns the DBID and name of the dbidSQL row.
+func parseDBID(rows []string) (int64, string, error) {
+	if len(rows) != 1 {
+		return 0, "", fmt.Errorf("expected 1 database row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return 0, "", fmt.Errorf("failed to parse database row %q: %v", rows[0], err)
+	}
+	dbid, err := strconv.ParseInt(row["DBID"], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to parse DBID in database row %q: %v", rows[0], err)
+	}
+	return dbid, row["NAME"], nil
+}
+
+// GetDBID returns the DBID of the database.
+func (s *Server) GetDBID(ctx context.Context, req *dbdpb.GetDBIDRequest) (*dbdpb.GetDBIDResponse, error) {
+	klog.InfoS("dbdaemon/GetDBID", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{dbidSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDBID: failed to query the DBID: %v", err)
+	}
+	dbid, name, err := parseDBID(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDBID: %v", err)
+	}
+	return &dbdpb.GetDBIDResponse{Dbid: dbid, Name: name}, nil
+}
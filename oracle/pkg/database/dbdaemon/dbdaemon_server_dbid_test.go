@@ -0,0 +1,67 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseDBID(t *testing.T) {
+	dbid, name, err := parseDBID([]string{`{"DBID":"1234567890","NAME":"GCLOUD"}`})
+	if err != nil {
+		t.Fatalf("parseDBID failed: %v", err)
+	}
+	if dbid != 1234567890 || name != "GCLOUD" {
+		t.Errorf("parseDBID = %d, %q, want 1234567890, %q", dbid, name, "GCLOUD")
+	}
+
+	for _, rows := range [][]string{
+		nil,
+		{`{"DBID":"1234567890","NAME":"GCLOUD"}`, `{"DBID":"1","NAME":"OTHER"}`},
+		{"not json"},
+		{`{"NAME":"GCLOUD"}`},
+	} {
+		if _, _, err := parseDBID(rows); err == nil {
+			t.Errorf("parseDBID(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+func TestGetDBID(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 1 || sqls[0] != dbidSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{`{"DBID":"1234567890","NAME":"GCLOUD"}`}, nil
+	}
+
+	resp, err := s.GetDBID(ctx, &dbdpb.GetDBIDRequest{})
+	if err != nil {
+		t.Fatalf("GetDBID failed: %v", err)
+	}
+	if resp.GetDbid() != 1234567890 || resp.GetName() != "GCLOUD" {
+		t.Errorf("GetDBID = %v, want DBID 1234567890 and name GCLOUD", resp)
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// maxDiagFileBytes bounds how much of the end of the alert log and of a
+// trace file is read, older entries are in the part left out.
+const maxDiagFileBytes = 16 << 20
+
+const diagTraceSQL = "select value from v$diag_info where name = 'Diag Trace'"
+
+var (
+	alertLogTimeRE     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?[+-]\d{2}:\d{2}$`)
+	alertLogDeadlockRE = regexp.MustCompile(`^ORA-00060: Deadlock detected\..* More info in file (\S+\.trc)`)
+	traceTimeRE        = regexp.MustCompile(`^\*\*\* (\d{4}-\d{2}-\d{2}T\S+)`)
+	// deadlockGraphRE matches a row of the deadlock graph: the resource,
+	// then the process, session, held and waited modes and serial of the
+	// blocker and then of the waiter. Either mode may be blank.
+	deadlockGraphRE = regexp.MustCompile(`^(\S+-\S+)\s+(\d+)\s+(\d+)\s+((?:[A-Z]+\s+)*)(\d+)\s+(\d+)\s+(\d+)\s+((?:[A-Z]+\s+)*)(\d+)$`)
+	traceSessionRE  = regexp.MustCompile(`^sid: (\d+) ser: (\d+) .*user: \d+/(\S+)`)
+	traceClientRE   = regexp.MustCompile(`^machine: (.*?) program: (.*)$`)
+	traceObjectRE   = regexp.MustCompile(`dictionary objn - (\d+)`)
+)
+
+// readFileTail returns the last maxBytes of a file, starting at a line.
+func readFileTail(path string, maxBytes int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := fi.Size() - maxBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	content := string(b)
+	if offset > 0 {
+		if i := strings.IndexByte(content, '\n'); i >= 0 {
+			content = content[i+1:]
+		}
+	}
+	return content, nil
+}
+
+// parseAlertLogDeadlocks returns the ORA-00060 errors of an alert log
+// logged after since, with the time and the trace file of each.
+func parseAlertLogDeadlocks(content string, since time.Time) []*dbdpb.GetDeadlocksResponse_Deadlock {
+	var deadlocks []*dbdpb.GetDeadlocksResponse_Deadlock
+	var logged time.Time
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if alertLogTimeRE.MatchString(line) {
+			if t, err := time.Parse(time.RFC3339, line); err == nil {
+				logged = t
+			}
+			continue
+		}
+		m := alertLogDeadlockRE.FindStringSubmatch(line)
+		if m == nil || !logged.After(since) {
+			continue
+		}
+		deadlocks = append(deadlocks, &dbdpb.GetDeadlocksResponse_Deadlock{
+			Time:      timestamppb.New(logged),
+			TraceFile: m[1],
+		})
+	}
+	return deadlocks
+}
+
+// deadlockSection returns the part of a trace file about the deadlock
+// detected closest to the given time, a process may hit several deadlocks
+// and write them all to the same trace file.
+func deadlockSection(trace string, at time.Time) string {
+	lines := strings.Split(trace, "\n")
+	var starts []int
+	var times []time.Time
+	var written time.Time
+	for i, line := range lines {
+		if m := traceTimeRE.FindStringSubmatch(line); m != nil {
+			if t, err := time.Parse(time.RFC3339, m[1]); err == nil {
+				written = t
+			}
+		}
+		if strings.Contains(line, "DEADLOCK DETECTED") {
+			starts = append(starts, i)
+			times = append(times, written)
+		}
+	}
+	if len(starts) == 0 {
+		return ""
+	}
+	distance := func(t time.Time) time.Duration {
+		if d := t.Sub(at); d >= 0 {
+			return d
+		}
+		return at.Sub(t)
+	}
+	best := len(starts) - 1
+	for i := range starts {
+		if !times[i].IsZero() && (times[best].IsZero() || distance(times[i]) < distance(times[best])) {
+			best = i
+		}
+	}
+	end := len(lines)
+	if best+1 < len(starts) {
+		end = starts[best+1]
+	}
+	return strings.Join(lines[starts[best]:end], "\n")
+}
+
+// parseDeadlockTrace adds the locks of the deadlock graph, the sessions and
+// the objects of a deadlockSection to the deadlock.
+func parseDeadlockTrace(section string, d *dbdpb.GetDeadlocksResponse_Deadlock) error {
+	var inGraph bool
+	var session, inSQL *dbdpb.GetDeadlocksResponse_Session
+	seenObjects := make(map[int64]bool)
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Deadlock graph:") {
+			inGraph = true
+			continue
+		}
+		if inGraph {
+			if m := deadlockGraphRE.FindStringSubmatch(line); m != nil {
+				blockerSID, _ := strconv.ParseInt(m[3], 10, 64)
+				waiterSID, _ := strconv.ParseInt(m[7], 10, 64)
+				blockerModes, waiterModes := strings.Fields(m[4]), strings.Fields(m[8])
+				lock := &dbdpb.GetDeadlocksResponse_Lock{Resource: m[1], BlockerSid: blockerSID, WaiterSid: waiterSID}
+				// The blocker holds the first mode, the waiter waits for
+				// the last one.
+				if len(blockerModes) > 0 {
+					lock.BlockerMode = blockerModes[0]
+				}
+				if len(waiterModes) > 0 {
+					lock.WaiterMode = waiterModes[len(waiterModes)-1]
+				}
+				d.Locks = append(d.Locks, lock)
+			} else if line == "" && len(d.Locks) > 0 {
+				inGraph = false
+			}
+			continue
+		}
+		if inSQL != nil {
+			if line == "" || strings.HasPrefix(line, "Session ") || strings.HasPrefix(line, "-----") {
+				inSQL = nil
+			} else {
+				inSQL.CurrentSql = strings.TrimSpace(inSQL.GetCurrentSql() + " " + line)
+				continue
+			}
+		}
+		if m := traceSessionRE.FindStringSubmatch(line); m != nil {
+			sid, _ := strconv.ParseInt(m[1], 10, 64)
+			serial, _ := strconv.ParseInt(m[2], 10, 64)
+			session = &dbdpb.GetDeadlocksResponse_Session{Sid: sid, Serial: serial, Username: m[3]}
+			d.Sessions = append(d.Sessions, session)
+			continue
+		}
+		if m := traceClientRE.FindStringSubmatch(line); m != nil && session != nil && session.GetMachine() == "" {
+			session.Machine, session.Program = m[1], m[2]
+			continue
+		}
+		if line == "current SQL:" && session != nil {
+			inSQL = session
+			continue
+		}
+		for _, m := range traceObjectRE.FindAllStringSubmatch(line, -1) {
+			id, _ := strconv.ParseInt(m[1], 10, 64)
+			if !seenObjects[id] {
+				seenObjects[id] = true
+				d.Objects = append(d.Objects, &dbdpb.GetDeadlocksResponse_Object{ObjectId: id})
+			}
+		}
+	}
+	if len(d.GetLocks()) == 0 {
+		return fmt.Errorf("no deadlock graph in trace file %s", d.GetTraceFile())
+	}
+	return nil
+}
+
+// deadlockObjectsSQL resolves object IDs to the objects of the PDBs.
+func deadlockObjectsSQL(ids []int64) string {
+	var list []string
+	for _, id := range ids {
+		list = append(list, strconv.FormatInt(id, 10))
+	}
+	return fmt.Sprintf("select o.object_id, c.name as pdb_name, o.owner, o.object_name from cdb_objects o, v$containers c "+
+		"where o.con_id = c.con_id and o.con_id > 2 and o.object_id in (%s)", strings.Join(list, ", "))
+}
+
+// resolveDeadlockObjects names the objects of the deadlocks found by the
+// deadlockObjectsSQL rows, an object ID used in several PDBs is ambiguous
+// and left unnamed.
+func resolveDeadlockObjects(rows []string, deadlocks []*dbdpb.GetDeadlocksResponse_Deadlock) error {
+	found := make(map[int64][]*dbdpb.GetDeadlocksResponse_Object)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return fmt.Errorf("failed to parse object row %q: %v", msg, err)
+		}
+		id, err := strconv.ParseInt(row["OBJECT_ID"], 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse OBJECT_ID in object row %q: %v", msg, err)
+		}
+		found[id] = append(found[id], &dbdpb.GetDeadlocksResponse_Object{ObjectId: id, PdbName: row["PDB_NAME"], Owner: row["OWNER"], Name: row["OBJECT_NAME"]})
+	}
+	for _, d := range deadlocks {
+		for i, o := range d.GetObjects() {
+			if objects := found[o.GetObjectId()]; len(objects) == 1 {
+				d.Objects[i] = objects[0]
+			}
+		}
+	}
+	return nil
+}
+
+// deadlockSummary describes the sessions and the objects of a deadlock.
+func deadlockSummary(d *dbdpb.GetDeadlocksResponse_Deadlock) string {
+	var sessions []string
+	for _, s := range d.GetSessions() {
+		if s.GetUsername() != "" {
+			sessions = append(sessions, fmt.Sprintf("%d (%s)", s.GetSid(), s.GetUsername()))
+		} else {
+			sessions = append(sessions, strconv.FormatInt(s.GetSid(), 10))
+		}
+	}
+	if len(sessions) == 0 {
+		seen := make(map[int64]bool)
+		for _, l := range d.GetLocks() {
+			if !seen[l.GetBlockerSid()] {
+				seen[l.GetBlockerSid()] = true
+				sessions = append(sessions, strconv.FormatInt(l.GetBlockerSid(), 10))
+			}
+		}
+	}
+	if len(sessions) == 0 {
+		return fmt.Sprintf("deadlock detected, see trace file %s", d.GetTraceFile())
+	}
+
+	var targets []string
+	for _, o := range d.GetObjects() {
+		if o.GetName() != "" {
+			targets = append(targets, fmt.Sprintf("%s.%s in %s", o.GetOwner(), o.GetName(), o.GetPdbName()))
+		}
+	}
+	if len(targets) == 0 {
+		seen := make(map[string]bool)
+		for _, l := range d.GetLocks() {
+			if t := strings.SplitN(l.GetResource(), "-", 2)[0]; !seen[t] {
+				seen[t] = true
+				targets = append(targets, t+" enqueue")
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Sprintf("sessions %s deadlocked", strings.Join(sessions, ", "))
+	}
+	sort.Strings(targets)
+	return fmt.Sprintf("sessions %s deadlocked on %s", strings.Join(sessions, ", "), strings.Join(targets, ", "))
+}
+
+// GetDeadlocks returns the deadlocks logged in the alert log.
+func (s *Server) GetDeadlocks(ctx context.Context, req *dbdpb.GetDeadlocksRequest) (*dbdpb.GetDeadlocksResponse, error) {
+	klog.InfoS("dbdaemon/GetDeadlocks", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{diagTraceSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDeadlocks: failed to query the trace directory: %v", err)
+	}
+	if len(resp.GetMsg()) != 1 {
+		return nil, fmt.Errorf("dbdaemon/GetDeadlocks: expected 1 trace directory row, got %d", len(resp.GetMsg()))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(resp.GetMsg()[0]), &row); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDeadlocks: failed to parse trace directory row %q: %v", resp.GetMsg()[0], err)
+	}
+	alertLog, err := readFileTail(filepath.Join(row["VALUE"], fmt.Sprintf("alert_%s.log", s.databaseSid.val)), maxDiagFileBytes)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDeadlocks: failed to read the alert log: %v", err)
+	}
+
+	var since time.Time
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+	deadlocks := parseAlertLogDeadlocks(alertLog, since)
+	var objectIDs []int64
+	for _, d := range deadlocks {
+		// The trace file may have been purged, the alert log still tells
+		// when the deadlock happened.
+		trace, err := readFileTail(d.GetTraceFile(), maxDiagFileBytes)
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon/GetDeadlocks: failed to read trace file", "traceFile", d.GetTraceFile())
+			continue
+		}
+		if err := parseDeadlockTrace(deadlockSection(trace, d.GetTime().AsTime()), d); err != nil {
+			klog.ErrorS(err, "dbdaemon/GetDeadlocks: failed to parse trace file", "traceFile", d.GetTraceFile())
+			continue
+		}
+		for _, o := range d.GetObjects() {
+			objectIDs = append(objectIDs, o.GetObjectId())
+		}
+	}
+	if len(objectIDs) > 0 {
+		resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{deadlockObjectsSQL(objectIDs)}}, true)
+		if err == nil {
+			err = resolveDeadlockObjects(resp.GetMsg(), deadlocks)
+		}
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon/GetDeadlocks: failed to resolve the deadlock objects", "objectIDs", objectIDs)
+		}
+	}
+	for _, d := range deadlocks {
+		d.Summary = deadlockSummary(d)
+	}
+	return &dbdpb.GetDeadlocksResponse{Deadlocks: deadlocks}, nil
+}
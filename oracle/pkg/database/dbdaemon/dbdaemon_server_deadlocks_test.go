@@ -0,0 +1,184 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const sampleAlertLog = `2023-01-02T03:00:00.000000+00:00
+Thread 1 advanced to log sequence 42 (LGWR switch)
+2023-01-02T03:04:05.123456+00:00
+ORA-00060: Deadlock detected. See Note 60.1 at My Oracle Support for Troubleshooting ORA-60 Errors. More info in file /u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc.
+2023-01-02T04:00:00.000000+00:00
+PDB1(3):Resize operation completed for file# 12
+2023-01-02T05:06:07.000000+00:00
+ORA-00060: Deadlock detected. See Note 60.1 at My Oracle Support for Troubleshooting ORA-60 Errors. More info in file /u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_5678.trc.
+`
+
+const sampleDeadlockTrace = `Trace file /u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc
+Oracle Database 19c Enterprise Edition Release 19.0.0.0.0 - Production
+
+*** 2023-01-01T10:00:00.000000+00:00 (PDB1(3))
+DEADLOCK DETECTED ( ORA-00060 )
+Deadlock graph:
+                                          ------------Blocker(s)-----------  ------------Waiter(s)------------
+Resource Name                             process session holds waits serial  process session holds waits serial
+TX-00010001-00000001-00000000-00000000         10      20     X             1      11      21           X      2
+ 
+*** 2023-01-02T03:04:05.120000+00:00 (PDB1(3))
+DEADLOCK DETECTED ( ORA-00060 )
+See Note 60.1 at My Oracle Support for Troubleshooting ORA-60 Errors
+
+[Transaction Deadlock]
+
+The following deadlock is not an ORACLE error. It is a
+deadlock due to user error in the design of an application
+or from issuing incorrect ad-hoc SQL. The following
+information may aid in determining the deadlock:
+
+Deadlock graph:
+                                          ------------Blocker(s)-----------  ------------Waiter(s)------------
+Resource Name                             process session holds waits serial  process session holds waits serial
+TX-000A0005-00001A9C-00000000-00000000         38     191     X         34451      39     195           X  43697
+TX-00090018-00001B2B-00000000-00000000         39     195     X         43697      38     191     S     X  34451
+ 
+----- VKTM Time Drifts Circular Buffer -----
+ 
+----- Information for waiting sessions -----
+Session 191:
+  sid: 191 ser: 34451 audsid: 1234 user: 111/SCOTT
+    flags: (0x41) USR/- flags2: (0x40009) -/-/INC
+  pid: 38 O/S info: user: oracle, term: UNKNOWN, ospid: 1234
+    image: oracle@db-0
+  client details:
+    O/S info: user: scott, term: pts/1, ospid: 5678
+    machine: app-1 program: sqlplus@app-1 (TNS V1-V3)
+    application name: SQL*Plus, hash value=3669949024
+  current SQL:
+  update emp set sal = sal + 1
+  where empno = 7369
+ 
+Session 195:
+  sid: 195 ser: 43697 audsid: 1235 user: 112/APP
+    flags: (0x41) USR/- flags2: (0x40009) -/-/INC
+  pid: 39 O/S info: user: oracle, term: UNKNOWN, ospid: 1235
+    image: oracle@db-0
+  client details:
+    O/S info: user: app, term: , ospid: 5679
+    machine: app-2 program: JDBC Thin Client
+  current SQL:
+  update emp set sal = sal + 1 where empno = 7499
+ 
+----- End of information for waiting sessions -----
+ 
+Rows waited on:
+  Session 191: obj - rowid = 00012345 - AAASNFAAEAAAACvAAA
+  (dictionary objn - 74565, file - 4, block - 175, slot - 0)
+  Session 195: obj - rowid = 00012345 - AAASNFAAEAAAACvAAB
+  (dictionary objn - 74565, file - 4, block - 175, slot - 1)
+`
+
+func TestParseAlertLogDeadlocks(t *testing.T) {
+	want := []*dbdpb.GetDeadlocksResponse_Deadlock{
+		{
+			Time:      timestamppb.New(time.Date(2023, 1, 2, 3, 4, 5, 123456000, time.UTC)),
+			TraceFile: "/u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc",
+		},
+		{
+			Time:      timestamppb.New(time.Date(2023, 1, 2, 5, 6, 7, 0, time.UTC)),
+			TraceFile: "/u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_5678.trc",
+		},
+	}
+	got := parseAlertLogDeadlocks(sampleAlertLog, time.Time{})
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseAlertLogDeadlocks got unexpected deadlocks (-want +got):\n%v", diff)
+	}
+
+	got = parseAlertLogDeadlocks(sampleAlertLog, time.Date(2023, 1, 2, 3, 4, 5, 123456000, time.UTC))
+	if diff := cmp.Diff(want[1:], got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseAlertLogDeadlocks got unexpected deadlocks after the first one (-want +got):\n%v", diff)
+	}
+}
+
+func TestParseDeadlockTrace(t *testing.T) {
+	d := &dbdpb.GetDeadlocksResponse_Deadlock{
+		Time:      timestamppb.New(time.Date(2023, 1, 2, 3, 4, 5, 123456000, time.UTC)),
+		TraceFile: "/u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc",
+	}
+	if err := parseDeadlockTrace(deadlockSection(sampleDeadlockTrace, d.GetTime().AsTime()), d); err != nil {
+		t.Fatalf("parseDeadlockTrace failed: %v", err)
+	}
+	if err := resolveDeadlockObjects([]string{`{"OBJECT_ID": "74565", "PDB_NAME": "PDB1", "OWNER": "SCOTT", "OBJECT_NAME": "EMP"}`}, []*dbdpb.GetDeadlocksResponse_Deadlock{d}); err != nil {
+		t.Fatalf("resolveDeadlockObjects failed: %v", err)
+	}
+	d.Summary = deadlockSummary(d)
+
+	want := &dbdpb.GetDeadlocksResponse_Deadlock{
+		Time:      timestamppb.New(time.Date(2023, 1, 2, 3, 4, 5, 123456000, time.UTC)),
+		TraceFile: "/u02/app/oracle/diag/rdbms/gcloud/GCLOUD/trace/GCLOUD_ora_1234.trc",
+		Sessions: []*dbdpb.GetDeadlocksResponse_Session{
+			{Sid: 191, Serial: 34451, Username: "SCOTT", Machine: "app-1", Program: "sqlplus@app-1 (TNS V1-V3)", CurrentSql: "update emp set sal = sal + 1 where empno = 7369"},
+			{Sid: 195, Serial: 43697, Username: "APP", Machine: "app-2", Program: "JDBC Thin Client", CurrentSql: "update emp set sal = sal + 1 where empno = 7499"},
+		},
+		Locks: []*dbdpb.GetDeadlocksResponse_Lock{
+			{Resource: "TX-000A0005-00001A9C-00000000-00000000", BlockerSid: 191, BlockerMode: "X", WaiterSid: 195, WaiterMode: "X"},
+			{Resource: "TX-00090018-00001B2B-00000000-00000000", BlockerSid: 195, BlockerMode: "X", WaiterSid: 191, WaiterMode: "X"},
+		},
+		Objects: []*dbdpb.GetDeadlocksResponse_Object{
+			{ObjectId: 74565, PdbName: "PDB1", Owner: "SCOTT", Name: "EMP"},
+		},
+		Summary: "sessions 191 (SCOTT), 195 (APP) deadlocked on SCOTT.EMP in PDB1",
+	}
+	if diff := cmp.Diff(want, d, protocmp.Transform()); diff != "" {
+		t.Errorf("parseDeadlockTrace got unexpected deadlock (-want +got):\n%v", diff)
+	}
+
+	// The older deadlock of the same trace file has neither sessions nor
+	// objects.
+	old := &dbdpb.GetDeadlocksResponse_Deadlock{}
+	if err := parseDeadlockTrace(deadlockSection(sampleDeadlockTrace, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)), old); err != nil {
+		t.Fatalf("parseDeadlockTrace failed on the older deadlock: %v", err)
+	}
+	if got, want := deadlockSummary(old), "sessions 20 deadlocked on TX enqueue"; got != want {
+		t.Errorf("deadlockSummary got %q for the older deadlock, want %q", got, want)
+	}
+
+	if err := parseDeadlockTrace("Trace file without a deadlock", &dbdpb.GetDeadlocksResponse_Deadlock{}); err == nil {
+		t.Errorf("parseDeadlockTrace succeeded without a deadlock graph, want an error")
+	}
+}
+
+func TestResolveDeadlockObjectsAmbiguous(t *testing.T) {
+	d := &dbdpb.GetDeadlocksResponse_Deadlock{Objects: []*dbdpb.GetDeadlocksResponse_Object{{ObjectId: 74565}}}
+	rows := []string{
+		`{"OBJECT_ID": "74565", "PDB_NAME": "PDB1", "OWNER": "SCOTT", "OBJECT_NAME": "EMP"}`,
+		`{"OBJECT_ID": "74565", "PDB_NAME": "PDB2", "OWNER": "APP", "OBJECT_NAME": "ORDERS"}`,
+	}
+	if err := resolveDeadlockObjects(rows, []*dbdpb.GetDeadlocksResponse_Deadlock{d}); err != nil {
+		t.Fatalf("resolveDeadlockObjects failed: %v", err)
+	}
+	want := []*dbdpb.GetDeadlocksResponse_Object{{ObjectId: 74565}}
+	if diff := cmp.Diff(want, d.GetObjects(), protocmp.Transform()); diff != "" {
+		t.Errorf("resolveDeadlockObjects got unexpected objects (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	editionsSQL       = "select edition_name, parent_edition_name from dba_editions"
+	defaultEditionSQL = "select property_value from database_properties where property_name='DEFAULT_EDITION'"
+
+	// editionUsageSQL counts the sessions and the services using an
+	// edition.
+	editionUsageSQL = "select (select count(*) from v$session s, dba_objects o where o.object_type='EDITION' and o.object_name='%[1]s' and s.session_edition_id=o.object_id) as sessions, " +
+		"(select count(*) from dba_services where edition='%[1]s') as services from dual"
+)
+
+// editionChain orders the dba_editions rows from the oldest edition to the
+// newest, each edition has at most one child.
+func editionChain(rows []map[string]string) ([]string, error) {
+	children := make(map[string]string)
+	root := ""
+	for _, row := range rows {
+		name, parent := row["EDITION_NAME"], row["PARENT_EDITION_NAME"]
+		if parent == "" {
+			if root != "" {
+				return nil, fmt.Errorf("editions %s and %s have no parent", root, name)
+			}
+			root = name
+			continue
+		}
+		if child, ok := children[parent]; ok {
+			return nil, fmt.Errorf("edition %s has children %s and %s", parent, child, name)
+		}
+		children[parent] = name
+	}
+	var chain []string
+	for e := root; e != ""; e = children[e] {
+		chain = append(chain, e)
+	}
+	if len(chain) != len(rows) {
+		return nil, fmt.Errorf("editions %v don't form a single chain", rows)
+	}
+	return chain, nil
+}
+
+// createEditionStatements returns the statements creating the editions
+// missing from the chain, each one as a child of the newest edition, and the
+// chain once they are created.
+func createEditionStatements(chain, editions []string) ([]string, []string, error) {
+	exists := make(map[string]bool)
+	for _, e := range chain {
+		exists[e] = true
+	}
+	var stmts []string
+	for _, e := range editions {
+		name := strings.ToUpper(e)
+		if exists[name] {
+			continue
+		}
+		if len(chain) == 0 {
+			return nil, nil, fmt.Errorf("no edition to create edition %s from", name)
+		}
+		stmts = append(stmts, fmt.Sprintf("create edition %s as child of %s", sqlq.MustBeObjectName(name), sqlq.MustBeObjectName(chain[len(chain)-1])))
+		chain = append(chain, name)
+		exists[name] = true
+	}
+	return stmts, chain, nil
+}
+
+// checkDropEdition checks an edition can be dropped from the chain, it has
+// to be the oldest or the newest edition and not the default one.
+func checkDropEdition(chain []string, edition, defaultEdition string) error {
+	if edition == defaultEdition {
+		return fmt.Errorf("edition %s is the default edition", edition)
+	}
+	if len(chain) < 2 {
+		return fmt.Errorf("edition %s is the only edition", edition)
+	}
+	if chain[0] != edition && chain[len(chain)-1] != edition {
+		return fmt.Errorf("edition %s has both a parent and a child, only the oldest and the newest editions can be dropped", edition)
+	}
+	return nil
+}
+
+// hasEdition tells whether an edition is in a list of editions.
+func hasEdition(editions []string, edition string) bool {
+	for _, e := range editions {
+		if e == edition {
+			return true
+		}
+	}
+	return false
+}
+
+// removeEdition returns the chain without an edition.
+func removeEdition(chain []string, edition string) []string {
+	var out []string
+	for _, e := range chain {
+		if e != edition {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ConfigureEditions creates the missing editions of a PDB, sets its default
+// edition, then drops the editions to drop. The editions to drop are checked
+// first, no statement is run if one of them can't be dropped.
+func (s *Server) ConfigureEditions(ctx context.Context, req *dbdpb.ConfigureEditionsRequest) (*dbdpb.ConfigureEditionsResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureEditions", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/ConfigureEditions: invalid PDB name %q", req.GetPdbName())
+	}
+	for _, e := range append(append([]string{req.GetDefaultEdition()}, req.GetEditions()...), req.GetDropEditions()...) {
+		if _, err := sqlq.ObjectName(e); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureEditions: invalid edition name %q", e)
+		}
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	rows, err := s.rlsQuery(ctx, req.GetPdbName(), editionsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureEditions: failed to query the editions: %v", err)
+	}
+	chain, err := editionChain(rows)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureEditions: %v", err)
+	}
+	rows, err = s.rlsQuery(ctx, req.GetPdbName(), defaultEditionSQL)
+	if err != nil || len(rows) != 1 {
+		return nil, fmt.Errorf("dbdaemon/ConfigureEditions: failed to query the default edition: %v", err)
+	}
+	defaultEdition := rows[0]["PROPERTY_VALUE"]
+
+	resp := &dbdpb.ConfigureEditionsResponse{}
+	statements, chain, err := createEditionStatements(chain, req.GetEditions())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureEditions: %v", err)
+	}
+	resp.CreatedEditions = chain[len(chain)-len(statements):]
+
+	if e := strings.ToUpper(req.GetDefaultEdition()); e != "" && e != defaultEdition {
+		if !hasEdition(chain, e) {
+			return nil, fmt.Errorf("dbdaemon/ConfigureEditions: default edition %s doesn't exist", e)
+		}
+		statements = append(statements, "alter database default edition = "+sqlq.MustBeObjectName(e))
+		defaultEdition = e
+	}
+
+	// Drop the editions which are the oldest or the newest of the chain
+	// until none is left, dropping one may make another droppable.
+	var drops []string
+	for _, e := range req.GetDropEditions() {
+		if e = strings.ToUpper(e); hasEdition(chain, e) && !hasEdition(drops, e) {
+			drops = append(drops, e)
+		}
+	}
+	for len(drops) > 0 {
+		var left []string
+		var lastErr error
+		for _, e := range drops {
+			if err := checkDropEdition(chain, e, defaultEdition); err != nil {
+				left = append(left, e)
+				lastErr = err
+				continue
+			}
+			rows, err := s.rlsQuery(ctx, req.GetPdbName(), fmt.Sprintf(editionUsageSQL, sqlq.StringParam(e)))
+			if err != nil || len(rows) != 1 {
+				return nil, fmt.Errorf("dbdaemon/ConfigureEditions: failed to query the usage of edition %s: %v", e, err)
+			}
+			if rows[0]["SESSIONS"] != "0" || rows[0]["SERVICES"] != "0" {
+				return nil, fmt.Errorf("dbdaemon/ConfigureEditions: edition %s is used by %s sessions and %s services", e, rows[0]["SESSIONS"], rows[0]["SERVICES"])
+			}
+			statements = append(statements, fmt.Sprintf("drop edition %s cascade", sqlq.MustBeObjectName(e)))
+			resp.DroppedEditions = append(resp.DroppedEditions, e)
+			chain = removeEdition(chain, e)
+		}
+		if len(left) == len(drops) {
+			return nil, fmt.Errorf("dbdaemon/ConfigureEditions: cannot drop editions %v: %v", left, lastErr)
+		}
+		drops = left
+	}
+
+	if len(statements) > 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...),
+		}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureEditions: failed to configure editions: %v", err)
+		}
+	}
+	resp.Editions = chain
+	resp.DefaultEdition = defaultEdition
+	return resp, nil
+}
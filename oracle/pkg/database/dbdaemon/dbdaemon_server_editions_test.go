@@ -0,0 +1,168 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestEditionChain(t *testing.T) {
+	chain, err := editionChain([]map[string]string{
+		{"EDITION_NAME": "V2", "PARENT_EDITION_NAME": "V1"},
+		{"EDITION_NAME": "ORA$BASE"},
+		{"EDITION_NAME": "V1", "PARENT_EDITION_NAME": "ORA$BASE"},
+	})
+	if err != nil {
+		t.Fatalf("editionChain failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"ORA$BASE", "V1", "V2"}, chain); diff != "" {
+		t.Errorf("editionChain got unexpected chain (-want +got):\n%v", diff)
+	}
+	if _, err := editionChain([]map[string]string{
+		{"EDITION_NAME": "ORA$BASE"},
+		{"EDITION_NAME": "V1", "PARENT_EDITION_NAME": "ORA$BASE"},
+		{"EDITION_NAME": "V2", "PARENT_EDITION_NAME": "ORA$BASE"},
+	}); err == nil {
+		t.Errorf("editionChain of a branched tree succeeded, want error")
+	}
+}
+
+func TestCreateEditionStatements(t *testing.T) {
+	stmts, chain, err := createEditionStatements([]string{"ORA$BASE", "V1"}, []string{"v1", "v2", "v3"})
+	if err != nil {
+		t.Fatalf("createEditionStatements failed: %v", err)
+	}
+	wantStmts := []string{
+		`create edition "V2" as child of "V1"`,
+		`create edition "V3" as child of "V2"`,
+	}
+	if diff := cmp.Diff(wantStmts, stmts); diff != "" {
+		t.Errorf("createEditionStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"ORA$BASE", "V1", "V2", "V3"}, chain); diff != "" {
+		t.Errorf("createEditionStatements got unexpected chain (-want +got):\n%v", diff)
+	}
+}
+
+func TestCheckDropEdition(t *testing.T) {
+	chain := []string{"ORA$BASE", "V1", "V2"}
+	testCases := []struct {
+		edition        string
+		defaultEdition string
+		wantErr        bool
+	}{
+		{edition: "ORA$BASE", defaultEdition: "V2"},
+		{edition: "V2", defaultEdition: "V1"},
+		{edition: "V1", defaultEdition: "V2", wantErr: true},
+		{edition: "V2", defaultEdition: "V2", wantErr: true},
+	}
+	for _, tc := range testCases {
+		err := checkDropEdition(chain, tc.edition, tc.defaultEdition)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("checkDropEdition(%v, %s, %s) got error %v, want error %v", chain, tc.edition, tc.defaultEdition, err, tc.wantErr)
+		}
+	}
+	if err := checkDropEdition([]string{"ORA$BASE"}, "ORA$BASE", "V1"); err == nil {
+		t.Errorf("checkDropEdition of the only edition succeeded, want error")
+	}
+}
+
+func TestConfigureEditions(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	sessions := "0"
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 2 || sqls[0] != `alter session set container="PDB1"` {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		switch {
+		case sqls[1] == editionsSQL:
+			return []string{
+				`{"EDITION_NAME":"ORA$BASE","PARENT_EDITION_NAME":""}`,
+				`{"EDITION_NAME":"V1","PARENT_EDITION_NAME":"ORA$BASE"}`,
+				`{"EDITION_NAME":"V2","PARENT_EDITION_NAME":"V1"}`,
+			}, nil
+		case sqls[1] == defaultEditionSQL:
+			return []string{`{"PROPERTY_VALUE":"V1"}`}, nil
+		case strings.Contains(sqls[1], "from v$session"):
+			return []string{fmt.Sprintf(`{"SESSIONS":%q,"SERVICES":"0"}`, sessions)}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = sqls
+		return nil, nil
+	}
+	req := &dbdpb.ConfigureEditionsRequest{
+		PdbName:        "pdb1",
+		Editions:       []string{"v1", "v2", "v3"},
+		DropEditions:   []string{"v1", "ora$base"},
+		DefaultEdition: "v3",
+	}
+
+	resp, err := s.ConfigureEditions(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureEditions failed: %v", err)
+	}
+	want := []string{
+		`alter session set container="PDB1"`,
+		`create edition "V3" as child of "V2"`,
+		`alter database default edition = "V3"`,
+		`drop edition "ORA$BASE" cascade`,
+		`drop edition "V1" cascade`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ConfigureEditions got unexpected statements (-want +got):\n%v", diff)
+	}
+	wantResp := &dbdpb.ConfigureEditionsResponse{
+		Editions:        []string{"V2", "V3"},
+		DefaultEdition:  "V3",
+		CreatedEditions: []string{"V3"},
+		DroppedEditions: []string{"ORA$BASE", "V1"},
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ConfigureEditions got unexpected response (-want +got):\n%v", diff)
+	}
+
+	sessions = "2"
+	got = nil
+	if _, err := s.ConfigureEditions(ctx, req); err == nil {
+		t.Errorf("ConfigureEditions dropping an edition in use succeeded, want error")
+	}
+	if got != nil {
+		t.Errorf("ConfigureEditions dropping an edition in use ran %q, want no statements", got)
+	}
+
+	sessions = "0"
+	req.DropEditions = []string{"v2"}
+	req.DefaultEdition = ""
+	if _, err := s.ConfigureEditions(ctx, req); err == nil {
+		t.Errorf("ConfigureEditions dropping an edition with a parent and a child succeeded, want error")
+	}
+}
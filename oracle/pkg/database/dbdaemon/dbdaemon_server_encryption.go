@@ -0,0 +1,321 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// encryptionStatusSQL lists the tablespaces of the current container along
+// with their encryption status. v$tablespace is filtered by con_id as in the
+// CDB root it returns the tablespaces of all containers.
+const encryptionStatusSQL = "select t.tablespace_name, t.contents, t.encrypted, " +
+	"nvl(e.encryptionalg, 'NONE') as encryptionalg " +
+	"from dba_tablespaces t " +
+	"join v$tablespace v on v.name = t.tablespace_name and v.con_id = sys_context('userenv', 'con_id') " +
+	"left outer join v$encrypted_tablespaces e on e.ts# = v.ts# and e.con_id = v.con_id " +
+	"order by t.tablespace_name"
+
+// systemTablespaces are permanent tablespaces owned by Oracle,
+// they are not considered user tablespaces.
+var systemTablespaces = map[string]bool{
+	"SYSTEM": true,
+	"SYSAUX": true,
+}
+
+// encryptTablespaceSQL returns the DDL encrypting a tablespace online.
+func encryptTablespaceSQL(tablespace string) (string, error) {
+	name, err := sqlq.Identifier(tablespace)
+	if err != nil {
+		return "", fmt.Errorf("invalid tablespace name %q: %v", tablespace, err)
+	}
+	return fmt.Sprintf("alter tablespace %s encryption online encrypt", name), nil
+}
+
+// parseTablespaceEncryption converts encryptionStatusSQL rows into
+// the response representation.
+func parseTablespaceEncryption(rows []string) ([]*dbdpb.VerifyEncryptionResponse_TablespaceEncryption, error) {
+	var tablespaces []*dbdpb.VerifyEncryptionResponse_TablespaceEncryption
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse tablespace encryption row %q: %v", msg, err)
+		}
+		ts := &dbdpb.VerifyEncryptionResponse_TablespaceEncryption{
+			Name:      row["TABLESPACE_NAME"],
+			Contents:  row["CONTENTS"],
+			Encrypted: row["ENCRYPTED"] == "YES",
+		}
+		if alg := row["ENCRYPTIONALG"]; ts.Encrypted && alg != "NONE" {
+			ts.Algorithm = alg
+		}
+		tablespaces = append(tablespaces, ts)
+	}
+	return tablespaces, nil
+}
+
+// unencryptedUserTablespaces returns permanent non-system tablespaces
+// which are not encrypted.
+func unencryptedUserTablespaces(tablespaces []*dbdpb.VerifyEncryptionResponse_TablespaceEncryption) []string {
+	var names []string
+	for _, ts := range tablespaces {
+		if ts.GetEncrypted() || ts.GetContents() != "PERMANENT" || systemTablespaces[ts.GetName()] {
+			continue
+		}
+		names = append(names, ts.GetName())
+	}
+	return names
+}
+
+// VerifyEncryption reports which tablespaces of a container are encrypted.
+// When enforce_all is set, unencrypted user tablespaces are flagged and,
+// if encrypt_online is also set, encrypted online. Tablespaces which failed
+// to encrypt remain flagged in the response.
+func (s *Server) VerifyEncryption(ctx context.Context, req *dbdpb.VerifyEncryptionRequest) (*dbdpb.VerifyEncryptionResponse, error) {
+	klog.InfoS("dbdaemon/VerifyEncryption", "req", loggableRequest(req), "serverObj", s)
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	var setContainer []string
+	if req.GetPdbName() != "" {
+		if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/VerifyEncryption: invalid PDB name %q: %v", req.GetPdbName(), err)
+		}
+		setContainer = []string{sqlq.QuerySetSessionContainer(req.GetPdbName())}
+	}
+
+	queryResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: append(append([]string{}, setContainer...), encryptionStatusSQL),
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/VerifyEncryption: failed to query tablespace encryption status: %v", err)
+	}
+	tablespaces, err := parseTablespaceEncryption(queryResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/VerifyEncryption: %v", err)
+	}
+
+	resp := &dbdpb.VerifyEncryptionResponse{Tablespaces: tablespaces}
+	if !req.GetEnforceAll() {
+		return resp, nil
+	}
+
+	if !req.GetEncryptOnline() {
+		resp.UnencryptedUserTablespaces = unencryptedUserTablespaces(tablespaces)
+		return resp, nil
+	}
+	resp.EncryptedTablespaces, resp.UnencryptedUserTablespaces = s.encryptTablespacesOnline(ctx, req.GetPdbName(), setContainer, unencryptedUserTablespaces(tablespaces))
+	return resp, nil
+}
+
+// encryptTablespacesOnline encrypts the tablespaces of the container of pdb
+// online one by one. It returns the encrypted tablespaces and those which
+// failed to encrypt, the errors are logged.
+func (s *Server) encryptTablespacesOnline(ctx context.Context, pdb string, setContainer, tablespaces []string) (encrypted, failed []string) {
+	for _, ts := range tablespaces {
+		ddl, err := encryptTablespaceSQL(ts)
+		if err == nil {
+			_, err = s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+				Commands: append(append([]string{}, setContainer...), ddl),
+			}, false)
+		}
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon: failed to encrypt tablespace online", "pdb", pdb, "tablespace", ts)
+			failed = append(failed, ts)
+			continue
+		}
+		klog.InfoS("dbdaemon: encrypted tablespace online", "pdb", pdb, "tablespace", ts)
+		encrypted = append(encrypted, ts)
+	}
+	return encrypted, failed
+}
+
+// tablespacesToEncrypt returns the tablespaces of names which aren't
+// encrypted, or the unencrypted user tablespaces if names is empty.
+func tablespacesToEncrypt(tablespaces []*dbdpb.VerifyEncryptionResponse_TablespaceEncryption, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return unencryptedUserTablespaces(tablespaces), nil
+	}
+	byName := make(map[string]*dbdpb.VerifyEncryptionResponse_TablespaceEncryption)
+	for _, ts := range tablespaces {
+		byName[ts.GetName()] = ts
+	}
+	var unencrypted []string
+	for _, name := range names {
+		ts, ok := byName[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("tablespace %q doesn't exist", name)
+		}
+		if !ts.GetEncrypted() {
+			unencrypted = append(unencrypted, ts.GetName())
+		}
+	}
+	return unencrypted, nil
+}
+
+// EncryptTablespaces encrypts tablespaces of a container online. The
+// tablespaces which fail to encrypt are reported in the response, not as
+// an error, the others are still encrypted.
+func (s *Server) EncryptTablespaces(ctx context.Context, req *dbdpb.EncryptTablespacesRequest) (*dbdpb.EncryptTablespacesResponse, error) {
+	klog.InfoS("dbdaemon/EncryptTablespaces", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	var setContainer []string
+	if req.GetPdbName() != "" {
+		if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/EncryptTablespaces: invalid PDB name %q: %v", req.GetPdbName(), err)
+		}
+		setContainer = []string{sqlq.QuerySetSessionContainer(req.GetPdbName())}
+	}
+	queryResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: append(append([]string{}, setContainer...), encryptionStatusSQL),
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/EncryptTablespaces: failed to query tablespace encryption status: %v", err)
+	}
+	tablespaces, err := parseTablespaceEncryption(queryResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/EncryptTablespaces: %v", err)
+	}
+	names, err := tablespacesToEncrypt(tablespaces, req.GetTablespaces())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/EncryptTablespaces: %v", err)
+	}
+
+	resp := &dbdpb.EncryptTablespacesResponse{}
+	resp.EncryptedTablespaces, resp.FailedTablespaces = s.encryptTablespacesOnline(ctx, req.GetPdbName(), setContainer, names)
+	return resp, nil
+}
+
+const (
+	// encryptNewTablespacesParam sets the new tablespace encryption
+	// policy up to Oracle 21c, it can be changed online.
+	encryptNewTablespacesParam = "encrypt_new_tablespaces"
+	// tablespaceEncryptionParam replaces encryptNewTablespacesParam from
+	// Oracle 21c, it is static.
+	tablespaceEncryptionParam = "tablespace_encryption"
+
+	// tablespaceEncryptionPolicySQL returns the running and the spfile
+	// values of the policy parameters the database supports.
+	tablespaceEncryptionPolicySQL = "select p.name, upper(p.value) as value, upper(nvl(sp.value, p.value)) as spfile_value " +
+		"from v$parameter p left outer join v$spparameter sp on sp.name = p.name and sp.sid = '*' " +
+		"where p.name in ('" + encryptNewTablespacesParam + "', '" + tablespaceEncryptionParam + "')"
+)
+
+// tablespaceEncryptionPolicy is the parameter holding the new tablespace
+// encryption policy and its values.
+type tablespaceEncryptionPolicy struct {
+	parameter   string
+	value       string
+	spfileValue string
+}
+
+// tablespaceEncryptionPolicyValue returns the value of the policy parameter
+// encrypting new tablespaces or leaving it to their DDL.
+func tablespaceEncryptionPolicyValue(parameter string, encrypt bool) string {
+	if parameter == tablespaceEncryptionParam {
+		if encrypt {
+			return "AUTO_ENABLE"
+		}
+		return "MANUAL_ENABLE"
+	}
+	if encrypt {
+		return "ALWAYS"
+	}
+	return "DDL"
+}
+
+// encryptsNewTablespaces returns whether the running instance encrypts new
+// tablespaces by default.
+func (p *tablespaceEncryptionPolicy) encryptsNewTablespaces() bool {
+	return p.value == tablespaceEncryptionPolicyValue(p.parameter, true)
+}
+
+// parseTablespaceEncryptionPolicy converts tablespaceEncryptionPolicySQL
+// rows into the policy, tablespace_encryption is preferred when the
+// database has both parameters.
+func parseTablespaceEncryptionPolicy(rows []string) (*tablespaceEncryptionPolicy, error) {
+	var policy *tablespaceEncryptionPolicy
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse tablespace encryption policy row %q: %v", msg, err)
+		}
+		if policy != nil && policy.parameter == tablespaceEncryptionParam {
+			continue
+		}
+		policy = &tablespaceEncryptionPolicy{
+			parameter:   row["NAME"],
+			value:       row["VALUE"],
+			spfileValue: row["SPFILE_VALUE"],
+		}
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("neither %s nor %s is supported", tablespaceEncryptionParam, encryptNewTablespacesParam)
+	}
+	return policy, nil
+}
+
+// SetTablespaceEncryptionPolicy sets the policy parameter so that new
+// tablespaces are encrypted by default or only if their DDL requests it.
+// encrypt_new_tablespaces is set in all the containers of the running
+// instance and the spfile, tablespace_encryption only in the spfile.
+func (s *Server) SetTablespaceEncryptionPolicy(ctx context.Context, req *dbdpb.SetTablespaceEncryptionPolicyRequest) (*dbdpb.SetTablespaceEncryptionPolicyResponse, error) {
+	klog.InfoS("dbdaemon/SetTablespaceEncryptionPolicy", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	queryResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{tablespaceEncryptionPolicySQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetTablespaceEncryptionPolicy: failed to query the tablespace encryption policy: %v", err)
+	}
+	policy, err := parseTablespaceEncryptionPolicy(queryResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetTablespaceEncryptionPolicy: %v", err)
+	}
+
+	want := tablespaceEncryptionPolicyValue(policy.parameter, req.GetEncryptNewTablespaces())
+	resp := &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: policy.parameter, Value: want}
+	cmd := fmt.Sprintf("alter system set %s=%s container=all scope=both", policy.parameter, want)
+	changed := policy.value != want || policy.spfileValue != want
+	if policy.parameter == tablespaceEncryptionParam {
+		cmd = fmt.Sprintf("alter system set %s=%s scope=spfile", policy.parameter, want)
+		changed = policy.spfileValue != want
+		resp.RestartRequired = policy.value != want
+	}
+	if !changed {
+		return resp, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{cmd}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetTablespaceEncryptionPolicy: failed to set %s: %v", policy.parameter, err)
+	}
+	resp.Changed = true
+	return resp, nil
+}
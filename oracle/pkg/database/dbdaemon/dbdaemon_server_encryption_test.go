@@ -0,0 +1,511 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// fakeSQLDB is a mockDB whose statement execution is stubbed out.
+type fakeSQLDB struct {
+	mockDB
+
+	runSQLFunc   func(sqls []string) ([]string, error)
+	runQueryFunc func(sqls []string) ([]string, error)
+}
+
+func (f *fakeSQLDB) runSQL(ctx context.Context, i []string, b bool, b2 bool, database oracleDatabase) ([]string, error) {
+	if f.runSQLFunc == nil {
+		panic("implement me")
+	}
+	return f.runSQLFunc(i)
+}
+
+func (f *fakeSQLDB) runQuery(ctx context.Context, i []string, b bool, database oracleDatabase) ([]string, error) {
+	if f.runQueryFunc == nil {
+		panic("implement me")
+	}
+	return f.runQueryFunc(i)
+}
+
+// useFakeSQLDB replaces the server database with a fakeSQLDB.
+func useFakeSQLDB(s *Server) *fakeSQLDB {
+	db := &fakeSQLDB{}
+	s.database = db
+	return db
+}
+
+// fakeOracleDatabase is a no-op connection ('oracleDatabase' interface).
+type fakeOracleDatabase struct{}
+
+func (f fakeOracleDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func (f fakeOracleDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (f fakeOracleDatabase) Ping() error {
+	return nil
+}
+
+func (f fakeOracleDatabase) Close() error {
+	return nil
+}
+
+// useFakeOracleDatabase makes database connections opened during
+// the test return a fakeOracleDatabase.
+func useFakeOracleDatabase(t *testing.T) {
+	t.Helper()
+	orig := newDB
+	newDB = func(driverName, dataSourceName string) (oracleDatabase, error) {
+		return fakeOracleDatabase{}, nil
+	}
+	t.Cleanup(func() { newDB = orig })
+}
+
+var encryptionStatusRows = []string{
+	`{"TABLESPACE_NAME":"APP_DATA","CONTENTS":"PERMANENT","ENCRYPTED":"YES","ENCRYPTIONALG":"AES256"}`,
+	`{"TABLESPACE_NAME":"SYSAUX","CONTENTS":"PERMANENT","ENCRYPTED":"NO","ENCRYPTIONALG":"NONE"}`,
+	`{"TABLESPACE_NAME":"SYSTEM","CONTENTS":"PERMANENT","ENCRYPTED":"NO","ENCRYPTIONALG":"NONE"}`,
+	`{"TABLESPACE_NAME":"TEMP","CONTENTS":"TEMPORARY","ENCRYPTED":"NO","ENCRYPTIONALG":"NONE"}`,
+	`{"TABLESPACE_NAME":"UNDOTBS1","CONTENTS":"UNDO","ENCRYPTED":"NO","ENCRYPTIONALG":"NONE"}`,
+	`{"TABLESPACE_NAME":"USERS","CONTENTS":"PERMANENT","ENCRYPTED":"NO","ENCRYPTIONALG":"NONE"}`,
+}
+
+func TestEncryptionStatusSQL(t *testing.T) {
+	for _, want := range []string{"dba_tablespaces", "v$encrypted_tablespaces", "t.encrypted", "sys_context('userenv', 'con_id')"} {
+		if !strings.Contains(encryptionStatusSQL, want) {
+			t.Errorf("encryptionStatusSQL = %q, want it to contain %q", encryptionStatusSQL, want)
+		}
+	}
+}
+
+func TestParseTablespaceEncryption(t *testing.T) {
+	got, err := parseTablespaceEncryption(encryptionStatusRows[:2])
+	if err != nil {
+		t.Fatalf("parseTablespaceEncryption failed: %v", err)
+	}
+	want := []*dbdpb.VerifyEncryptionResponse_TablespaceEncryption{
+		{Name: "APP_DATA", Contents: "PERMANENT", Encrypted: true, Algorithm: "AES256"},
+		{Name: "SYSAUX", Contents: "PERMANENT"},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseTablespaceEncryption got unexpected result (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseTablespaceEncryption([]string{"not json"}); err == nil {
+		t.Error("parseTablespaceEncryption succeeded for an invalid row, want error")
+	}
+}
+
+func TestEncryptTablespaceSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		tablespace string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "user tablespace",
+			tablespace: "USERS",
+			want:       `alter tablespace "USERS" encryption online encrypt`,
+		},
+		{
+			name:       "case preserved",
+			tablespace: "App_Data",
+			want:       `alter tablespace "App_Data" encryption online encrypt`,
+		},
+		{
+			name:       "quote in name",
+			tablespace: `USERS" offline; --`,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := encryptTablespaceSQL(tc.tablespace)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("encryptTablespaceSQL(%q) error = %v, wantErr %v", tc.tablespace, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("encryptTablespaceSQL(%q) = %q, want %q", tc.tablespace, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyEncryption(t *testing.T) {
+	useFakeOracleDatabase(t)
+	tests := []struct {
+		name           string
+		req            *dbdpb.VerifyEncryptionRequest
+		encryptErr     error
+		wantDDL        [][]string
+		wantUnenc      []string
+		wantEncrypted  []string
+		wantQueryCount int
+	}{
+		{
+			name:           "report only",
+			req:            &dbdpb.VerifyEncryptionRequest{PdbName: "pdb1"},
+			wantQueryCount: 1,
+		},
+		{
+			name:           "enforce flags user tablespaces",
+			req:            &dbdpb.VerifyEncryptionRequest{PdbName: "pdb1", EnforceAll: true},
+			wantUnenc:      []string{"USERS"},
+			wantQueryCount: 1,
+		},
+		{
+			name: "enforce encrypts online",
+			req:  &dbdpb.VerifyEncryptionRequest{PdbName: "pdb1", EnforceAll: true, EncryptOnline: true},
+			wantDDL: [][]string{
+				{`alter session set container="PDB1"`, `alter tablespace "USERS" encryption online encrypt`},
+			},
+			wantEncrypted:  []string{"USERS"},
+			wantQueryCount: 1,
+		},
+		{
+			name:       "failed encryption stays flagged",
+			req:        &dbdpb.VerifyEncryptionRequest{EnforceAll: true, EncryptOnline: true},
+			encryptErr: errors.New("ORA-28365: wallet is not open"),
+			wantDDL: [][]string{
+				{`alter tablespace "USERS" encryption online encrypt`},
+			},
+			wantUnenc:      []string{"USERS"},
+			wantQueryCount: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			var gotDDL [][]string
+			queryCount := 0
+			db := useFakeSQLDB(s)
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				queryCount++
+				if got := sqls[len(sqls)-1]; got != encryptionStatusSQL {
+					t.Errorf("runQuery got %q, want encryptionStatusSQL", got)
+				}
+				return encryptionStatusRows, nil
+			}
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				gotDDL = append(gotDDL, sqls)
+				return nil, tc.encryptErr
+			}
+
+			resp, err := s.VerifyEncryption(ctx, tc.req)
+			if err != nil {
+				t.Fatalf("VerifyEncryption failed: %v", err)
+			}
+			if queryCount != tc.wantQueryCount {
+				t.Errorf("VerifyEncryption ran %d queries, want %d", queryCount, tc.wantQueryCount)
+			}
+			if len(resp.GetTablespaces()) != len(encryptionStatusRows) {
+				t.Errorf("VerifyEncryption reported %d tablespaces, want %d", len(resp.GetTablespaces()), len(encryptionStatusRows))
+			}
+			if diff := cmp.Diff(tc.wantDDL, gotDDL); diff != "" {
+				t.Errorf("VerifyEncryption got unexpected DDL (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantUnenc, resp.GetUnencryptedUserTablespaces()); diff != "" {
+				t.Errorf("VerifyEncryption got unexpected unencrypted tablespaces (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantEncrypted, resp.GetEncryptedTablespaces()); diff != "" {
+				t.Errorf("VerifyEncryption got unexpected encrypted tablespaces (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestEncryptTablespaces(t *testing.T) {
+	useFakeOracleDatabase(t)
+	tests := []struct {
+		name          string
+		req           *dbdpb.EncryptTablespacesRequest
+		failDDL       string
+		wantDDL       [][]string
+		wantEncrypted []string
+		wantFailed    []string
+		wantErr       bool
+	}{
+		{
+			name: "unencrypted user tablespaces",
+			req:  &dbdpb.EncryptTablespacesRequest{PdbName: "pdb1"},
+			wantDDL: [][]string{
+				{`alter session set container="PDB1"`, `alter tablespace "USERS" encryption online encrypt`},
+			},
+			wantEncrypted: []string{"USERS"},
+		},
+		{
+			name: "named tablespaces",
+			req:  &dbdpb.EncryptTablespacesRequest{Tablespaces: []string{"app_data", "sysaux", "users"}},
+			wantDDL: [][]string{
+				{`alter tablespace "SYSAUX" encryption online encrypt`},
+				{`alter tablespace "USERS" encryption online encrypt`},
+			},
+			failDDL:       `alter tablespace "SYSAUX" encryption online encrypt`,
+			wantEncrypted: []string{"USERS"},
+			wantFailed:    []string{"SYSAUX"},
+		},
+		{
+			name:    "unknown tablespace",
+			req:     &dbdpb.EncryptTablespacesRequest{Tablespaces: []string{"missing"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid PDB name",
+			req:     &dbdpb.EncryptTablespacesRequest{PdbName: `pdb1"; drop`},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			var gotDDL [][]string
+			db := useFakeSQLDB(s)
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				return encryptionStatusRows, nil
+			}
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				gotDDL = append(gotDDL, sqls)
+				if sqls[len(sqls)-1] == tc.failDDL {
+					return nil, errors.New("ORA-28361: master key not yet set")
+				}
+				return nil, nil
+			}
+
+			resp, err := s.EncryptTablespaces(ctx, tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("EncryptTablespaces got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantDDL, gotDDL); diff != "" {
+				t.Errorf("EncryptTablespaces got unexpected DDL (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantEncrypted, resp.GetEncryptedTablespaces()); diff != "" {
+				t.Errorf("EncryptTablespaces got unexpected encrypted tablespaces (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantFailed, resp.GetFailedTablespaces()); diff != "" {
+				t.Errorf("EncryptTablespaces got unexpected failed tablespaces (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseTablespaceEncryptionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []string
+		want        tablespaceEncryptionPolicy
+		wantEncrypt bool
+		wantErr     bool
+	}{
+		{
+			name:        "19c",
+			rows:        []string{`{"NAME":"encrypt_new_tablespaces","VALUE":"ALWAYS","SPFILE_VALUE":"ALWAYS"}`},
+			want:        tablespaceEncryptionPolicy{parameter: encryptNewTablespacesParam, value: "ALWAYS", spfileValue: "ALWAYS"},
+			wantEncrypt: true,
+		},
+		{
+			name: "21c",
+			rows: []string{
+				`{"NAME":"tablespace_encryption","VALUE":"MANUAL_ENABLE","SPFILE_VALUE":"AUTO_ENABLE"}`,
+				`{"NAME":"encrypt_new_tablespaces","VALUE":"ALWAYS","SPFILE_VALUE":"ALWAYS"}`,
+			},
+			want: tablespaceEncryptionPolicy{parameter: tablespaceEncryptionParam, value: "MANUAL_ENABLE", spfileValue: "AUTO_ENABLE"},
+		},
+		{
+			name:    "no policy parameter",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseTablespaceEncryptionPolicy(tc.rows)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseTablespaceEncryptionPolicy got error %v, want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if *got != tc.want {
+				t.Errorf("parseTablespaceEncryptionPolicy got %+v, want %+v", *got, tc.want)
+			}
+			if got.encryptsNewTablespaces() != tc.wantEncrypt {
+				t.Errorf("encryptsNewTablespaces got %v, want %v", got.encryptsNewTablespaces(), tc.wantEncrypt)
+			}
+		})
+	}
+}
+
+func TestSetTablespaceEncryptionPolicy(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	tests := []struct {
+		name    string
+		rows    []string
+		encrypt bool
+		wantSQL string
+		want    *dbdpb.SetTablespaceEncryptionPolicyResponse
+	}{
+		{
+			name:    "encrypt new tablespaces",
+			rows:    []string{`{"NAME":"encrypt_new_tablespaces","VALUE":"CLOUD_ONLY","SPFILE_VALUE":"CLOUD_ONLY"}`},
+			encrypt: true,
+			wantSQL: "alter system set encrypt_new_tablespaces=ALWAYS container=all scope=both",
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: encryptNewTablespacesParam, Value: "ALWAYS", Changed: true},
+		},
+		{
+			name:    "applied to the spfile only",
+			rows:    []string{`{"NAME":"encrypt_new_tablespaces","VALUE":"CLOUD_ONLY","SPFILE_VALUE":"ALWAYS"}`},
+			encrypt: true,
+			wantSQL: "alter system set encrypt_new_tablespaces=ALWAYS container=all scope=both",
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: encryptNewTablespacesParam, Value: "ALWAYS", Changed: true},
+		},
+		{
+			name:    "encrypt as requested by the DDL",
+			rows:    []string{`{"NAME":"encrypt_new_tablespaces","VALUE":"ALWAYS","SPFILE_VALUE":"ALWAYS"}`},
+			wantSQL: "alter system set encrypt_new_tablespaces=DDL container=all scope=both",
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: encryptNewTablespacesParam, Value: "DDL", Changed: true},
+		},
+		{
+			name:    "already set",
+			rows:    []string{`{"NAME":"encrypt_new_tablespaces","VALUE":"ALWAYS","SPFILE_VALUE":"ALWAYS"}`},
+			encrypt: true,
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: encryptNewTablespacesParam, Value: "ALWAYS"},
+		},
+		{
+			name:    "21c",
+			rows:    []string{`{"NAME":"tablespace_encryption","VALUE":"MANUAL_ENABLE","SPFILE_VALUE":"MANUAL_ENABLE"}`},
+			encrypt: true,
+			wantSQL: "alter system set tablespace_encryption=AUTO_ENABLE scope=spfile",
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: tablespaceEncryptionParam, Value: "AUTO_ENABLE", Changed: true, RestartRequired: true},
+		},
+		{
+			name:    "21c pending restart",
+			rows:    []string{`{"NAME":"tablespace_encryption","VALUE":"MANUAL_ENABLE","SPFILE_VALUE":"AUTO_ENABLE"}`},
+			encrypt: true,
+			want:    &dbdpb.SetTablespaceEncryptionPolicyResponse{Parameter: tablespaceEncryptionParam, Value: "AUTO_ENABLE", RestartRequired: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				if sqls[0] != tablespaceEncryptionPolicySQL {
+					return nil, errors.New("unexpected query")
+				}
+				return tc.rows, nil
+			}
+			var gotSQL string
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				gotSQL = strings.Join(sqls, ";")
+				return nil, nil
+			}
+			got, err := s.SetTablespaceEncryptionPolicy(ctx, &dbdpb.SetTablespaceEncryptionPolicyRequest{EncryptNewTablespaces: tc.encrypt})
+			if err != nil {
+				t.Fatalf("SetTablespaceEncryptionPolicy failed: %v", err)
+			}
+			if gotSQL != tc.wantSQL {
+				t.Errorf("SetTablespaceEncryptionPolicy ran %q, want %q", gotSQL, tc.wantSQL)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("SetTablespaceEncryptionPolicy got unexpected response (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCreateTablespacesFromSqlfileEncryption(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	sqlfile := filepath.Join(t.TempDir(), "import.sql")
+	if err := os.WriteFile(sqlfile, []byte("CREATE TABLESPACE \"APP_DATA\" DATAFILE SIZE 10M;\n"+
+		"CREATE USER \"APP\" IDENTIFIED BY VALUES 'S:...'\n"+
+		"      DEFAULT TABLESPACE \"APP_DATA\"\n"+
+		"      TEMPORARY TABLESPACE \"APP_TEMP\";\n"), 0644); err != nil {
+		t.Fatalf("failed to write the sqlfile: %v", err)
+	}
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name:   "encrypted by default",
+			policy: `{"NAME":"encrypt_new_tablespaces","VALUE":"ALWAYS","SPFILE_VALUE":"ALWAYS"}`,
+			want: []string{
+				`create bigfile tablespace "APP_DATA" encryption encrypt`,
+				`create temporary tablespace "APP_TEMP" size 128M autoextend on`,
+			},
+		},
+		{
+			name:   "encrypted as requested by the DDL",
+			policy: `{"NAME":"encrypt_new_tablespaces","VALUE":"DDL","SPFILE_VALUE":"DDL"}`,
+			want: []string{
+				`create bigfile tablespace "APP_DATA"`,
+				`create temporary tablespace "APP_TEMP" size 128M autoextend on`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				if sqls[len(sqls)-1] == tablespaceEncryptionPolicySQL {
+					return []string{tc.policy}, nil
+				}
+				return []string{`{"TABLESPACE_NAME":"SYSTEM","CONTENTS":"PERMANENT"}`}, nil
+			}
+			var got []string
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				got = append(got, sqls[len(sqls)-1])
+				return nil, nil
+			}
+			s.createTablespacesFromSqlfile(ctx, sqlfile, "PDB1")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("createTablespacesFromSqlfile got unexpected statements (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// fanServicesSQL selects the services of a PDB, whether they publish HA
+	// events and whether they are running.
+	fanServicesSQL = "select s.name, s.aq_ha_notifications, " +
+		"(select count(*) from v$active_services a where a.name=s.name) as active from dba_services s"
+
+	createFANServiceCmd = "declare p dbms_service.svc_parameter_array; begin p('AQ_HA_NOTIFICATIONS') := '%s'; " +
+		"dbms_service.create_service(service_name => '%s', network_name => '%[2]s', parameter_array => p); end;"
+	modifyFANServiceCmd = "declare p dbms_service.svc_parameter_array; begin p('AQ_HA_NOTIFICATIONS') := '%s'; " +
+		"dbms_service.modify_service(service_name => '%s', parameter_array => p); end;"
+	startServiceCmd = "begin dbms_service.start_service('%s'); end;"
+
+	defaultONSLocalPort  = 6100
+	defaultONSRemotePort = 6200
+)
+
+var (
+	serviceNameRE = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.#$-]*$`)
+	onsNodeRE     = regexp.MustCompile(`^[A-Za-z0-9.-]+:[0-9]+$`)
+
+	onsConfigFile = func(databaseHome string) string {
+		return filepath.Join(databaseHome, "opmn", "conf", "ons.config")
+	}
+)
+
+// fanServiceStatements returns the statements which create the missing
+// services, turn the HA notifications of the existing ones on or off and
+// start the services which aren't running.
+func fanServiceStatements(services []*dbdpb.ConfigureFANRequest_Service, rows []map[string]string) (statements, created, modified []string) {
+	existing := make(map[string]map[string]string, len(rows))
+	for _, row := range rows {
+		existing[strings.ToUpper(row["NAME"])] = row
+	}
+	for _, svc := range services {
+		notifications := "FALSE"
+		if svc.GetHaNotifications() {
+			notifications = "TRUE"
+		}
+		name := sqlq.StringParam(svc.GetName())
+		row, ok := existing[strings.ToUpper(svc.GetName())]
+		switch {
+		case !ok:
+			statements = append(statements, fmt.Sprintf(createFANServiceCmd, notifications, name))
+			created = append(created, svc.GetName())
+		case (row["AQ_HA_NOTIFICATIONS"] == "YES") != svc.GetHaNotifications():
+			statements = append(statements, fmt.Sprintf(modifyFANServiceCmd, notifications, name))
+			modified = append(modified, svc.GetName())
+		}
+		if !ok || row["ACTIVE"] == "0" {
+			statements = append(statements, fmt.Sprintf(startServiceCmd, name))
+		}
+	}
+	return statements, created, modified
+}
+
+// onsConfig generates the ons.config of the ONS daemon.
+func onsConfig(ons *dbdpb.ConfigureFANRequest_ONS) string {
+	localPort, remotePort := ons.GetLocalPort(), ons.GetRemotePort()
+	if localPort == 0 {
+		localPort = defaultONSLocalPort
+	}
+	if remotePort == 0 {
+		remotePort = defaultONSRemotePort
+	}
+	config := fmt.Sprintf("localport=%d\nremoteport=%d\n", localPort, remotePort)
+	if len(ons.GetNodes()) > 0 {
+		config += fmt.Sprintf("nodes=%s\n", strings.Join(ons.GetNodes(), ","))
+	}
+	return config
+}
+
+// configureONS writes the ons.config and restarts the ONS daemon if the
+// configuration changed or the daemon isn't running. It returns whether the
+// daemon was restarted.
+func (s *Server) configureONS(ons *dbdpb.ConfigureFANRequest_ONS) (string, bool, error) {
+	config := onsConfig(ons)
+	file := onsConfigFile(s.databaseHome)
+	if current, err := os.ReadFile(file); err == nil && string(current) == config {
+		if err := s.osUtil.runCommand(onsctl(s.databaseHome), []string{"ping"}); err == nil {
+			return config, false, nil
+		}
+	}
+	if err := s.osUtil.createFile(file, strings.NewReader(config)); err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %v", file, err)
+	}
+	// The daemon may not be running, a failure to stop it is expected.
+	if err := s.osUtil.runCommand(onsctl(s.databaseHome), []string{"stop"}); err != nil {
+		klog.InfoS("dbdaemon/ConfigureFAN: failed to stop ONS", "err", err)
+	}
+	if err := s.osUtil.runCommand(onsctl(s.databaseHome), []string{"start"}); err != nil {
+		return "", false, fmt.Errorf("failed to start ONS: %v", err)
+	}
+	return config, true, nil
+}
+
+// ConfigureFAN creates and starts the services of a PDB with FAN HA
+// notifications on or off and configures the ONS daemon, so that client
+// connection pools drain and reconnect on failovers.
+func (s *Server) ConfigureFAN(ctx context.Context, req *dbdpb.ConfigureFANRequest) (*dbdpb.ConfigureFANResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureFAN", "req", loggableRequest(req))
+	if len(req.GetServices()) > 0 && req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/ConfigureFAN: the PDB of the services is required")
+	}
+	for _, svc := range req.GetServices() {
+		if !serviceNameRE.MatchString(svc.GetName()) {
+			return nil, fmt.Errorf("dbdaemon/ConfigureFAN: invalid service name %q", svc.GetName())
+		}
+	}
+	for _, node := range req.GetOns().GetNodes() {
+		if !onsNodeRE.MatchString(node) {
+			return nil, fmt.Errorf("dbdaemon/ConfigureFAN: invalid ONS node %q, want host:port", node)
+		}
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp := &dbdpb.ConfigureFANResponse{}
+	if len(req.GetServices()) > 0 {
+		rows, err := s.rlsQuery(ctx, req.GetPdbName(), fanServicesSQL)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureFAN: failed to query services: %v", err)
+		}
+		statements, created, modified := fanServiceStatements(req.GetServices(), rows)
+		if len(statements) > 0 {
+			if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+				Commands: append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...),
+			}, false); err != nil {
+				return nil, fmt.Errorf("dbdaemon/ConfigureFAN: failed to configure services: %v", err)
+			}
+		}
+		resp.CreatedServices = created
+		resp.ModifiedServices = modified
+	}
+	if req.GetOns() != nil {
+		config, restarted, err := s.configureONS(req.GetOns())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureFAN: %v", err)
+		}
+		resp.OnsConfig = config
+		resp.OnsRestarted = restarted
+	}
+	klog.InfoS("dbdaemon/ConfigureFAN: done", "createdServices", resp.GetCreatedServices(), "modifiedServices", resp.GetModifiedServices(), "onsRestarted", resp.GetOnsRestarted())
+	return resp, nil
+}
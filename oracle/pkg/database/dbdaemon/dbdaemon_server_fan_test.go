@@ -0,0 +1,165 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestFANServiceStatements(t *testing.T) {
+	services := []*dbdpb.ConfigureFANRequest_Service{
+		{Name: "oltp", HaNotifications: true},
+		{Name: "REPORTS", HaNotifications: true},
+		{Name: "batch"},
+		{Name: "etl", HaNotifications: true},
+	}
+	rows := []map[string]string{
+		{"NAME": "REPORTS", "AQ_HA_NOTIFICATIONS": "NO", "ACTIVE": "1"},
+		{"NAME": "BATCH", "AQ_HA_NOTIFICATIONS": "NO", "ACTIVE": "0"},
+		{"NAME": "ETL", "AQ_HA_NOTIFICATIONS": "YES", "ACTIVE": "1"},
+	}
+	stmts, created, modified := fanServiceStatements(services, rows)
+	want := []string{
+		fmt.Sprintf(createFANServiceCmd, "TRUE", "oltp"),
+		fmt.Sprintf(startServiceCmd, "oltp"),
+		fmt.Sprintf(modifyFANServiceCmd, "TRUE", "REPORTS"),
+		fmt.Sprintf(startServiceCmd, "batch"),
+	}
+	if diff := cmp.Diff(want, stmts); diff != "" {
+		t.Errorf("fanServiceStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"oltp"}, created); diff != "" {
+		t.Errorf("fanServiceStatements got unexpected created services (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"REPORTS"}, modified); diff != "" {
+		t.Errorf("fanServiceStatements got unexpected modified services (-want +got):\n%v", diff)
+	}
+}
+
+func TestONSConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+		ons  *dbdpb.ConfigureFANRequest_ONS
+		want string
+	}{
+		{
+			name: "defaults",
+			ons:  &dbdpb.ConfigureFANRequest_ONS{},
+			want: "localport=6100\nremoteport=6200\n",
+		},
+		{
+			name: "nodes",
+			ons:  &dbdpb.ConfigureFANRequest_ONS{LocalPort: 6101, RemotePort: 6201, Nodes: []string{"db1:6200", "db2:6200"}},
+			want: "localport=6101\nremoteport=6201\nnodes=db1:6200,db2:6200\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := onsConfig(tc.ons); got != tc.want {
+				t.Errorf("onsConfig(%v) = %q, want %q", tc.ons, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigureFAN(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.databaseHome = t.TempDir()
+	osUtil := &mockOsUtil{}
+	s.osUtil = osUtil
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 2 || sqls[0] != `alter session set container="PDB1"` || sqls[1] != fanServicesSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{`{"NAME":"OLTP","AQ_HA_NOTIFICATIONS":"NO","ACTIVE":"1"}`}, nil
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = sqls
+		return nil, nil
+	}
+	req := &dbdpb.ConfigureFANRequest{
+		PdbName: "pdb1",
+		Services: []*dbdpb.ConfigureFANRequest_Service{
+			{Name: "OLTP", HaNotifications: true},
+		},
+		Ons: &dbdpb.ConfigureFANRequest_ONS{},
+	}
+
+	resp, err := s.ConfigureFAN(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureFAN failed: %v", err)
+	}
+	want := []string{
+		`alter session set container="PDB1"`,
+		fmt.Sprintf(modifyFANServiceCmd, "TRUE", "OLTP"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ConfigureFAN got unexpected statements (-want +got):\n%v", diff)
+	}
+	wantResp := &dbdpb.ConfigureFANResponse{
+		ModifiedServices: []string{"OLTP"},
+		OnsConfig:        "localport=6100\nremoteport=6200\n",
+		OnsRestarted:     true,
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ConfigureFAN got unexpected response (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{onsConfigFile(s.databaseHome)}, osUtil.created); diff != "" {
+		t.Errorf("ConfigureFAN got unexpected created files (-want +got):\n%v", diff)
+	}
+
+	// An unchanged configuration of a running daemon is left alone.
+	file := onsConfigFile(s.databaseHome)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(resp.GetOnsConfig()), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+	osUtil.created = nil
+	resp, err = s.ConfigureFAN(ctx, &dbdpb.ConfigureFANRequest{Ons: req.GetOns()})
+	if err != nil {
+		t.Fatalf("ConfigureFAN failed: %v", err)
+	}
+	if resp.GetOnsRestarted() || osUtil.created != nil {
+		t.Errorf("ConfigureFAN with an unchanged ONS configuration restarted ONS, want no restart")
+	}
+
+	for _, req := range []*dbdpb.ConfigureFANRequest{
+		{Services: []*dbdpb.ConfigureFANRequest_Service{{Name: "OLTP"}}},
+		{PdbName: "pdb1", Services: []*dbdpb.ConfigureFANRequest_Service{{Name: "oltp'; drop"}}},
+		{Ons: &dbdpb.ConfigureFANRequest_ONS{Nodes: []string{"db1"}}},
+	} {
+		if _, err := s.ConfigureFAN(ctx, req); err == nil {
+			t.Errorf("ConfigureFAN(%v) succeeded, want error", req)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// featureUsageSQL reports the features used at least once with the usage
+// dates in the UTC offset of the database host, the dates are DATEs in the
+// host time zone.
+const featureUsageSQL = "select name, version, detected_usages, currently_used, " +
+	"to_char(first_usage_date, 'YYYY-MM-DD\"T\"HH24:MI:SS') || nvl2(first_usage_date, to_char(systimestamp, 'TZH:TZM'), null) as first_usage_date, " +
+	"to_char(last_usage_date, 'YYYY-MM-DD\"T\"HH24:MI:SS') || nvl2(last_usage_date, to_char(systimestamp, 'TZH:TZM'), null) as last_usage_date " +
+	"from dba_feature_usage_statistics where detected_usages > 0 or currently_used = 'TRUE' order by name, version"
+
+// featureOptions maps the dba_feature_usage_statistics features to the
+// separately licensed options they require.
+var featureOptions = map[string]dbdpb.GetFeatureUsageRequest_Option{
+	"Partitioning (user)":             dbdpb.GetFeatureUsageRequest_PARTITIONING,
+	"Advanced Index Compression":      dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"Backup HIGH Compression":         dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"Backup LOW Compression":          dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"Backup MEDIUM Compression":       dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"Heat Map":                        dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"OLTP Table Compression":          dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"SecureFile Compression (user)":   dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"SecureFile Deduplication (user)": dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+	"In-Memory Column Store":          dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY,
+	"ADDM":                            dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"AWR Baseline":                    dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"AWR Baseline Template":           dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"AWR Report":                      dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"Automatic Workload Repository":   dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+	"Real-Time SQL Monitoring":        dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+	"SQL Access Advisor":              dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+	"SQL Monitoring and Tuning pages": dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+	"SQL Tuning Advisor":              dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+	"SQL Tuning Set (user)":           dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+}
+
+// parseUsageTime parses a featureUsageSQL date, nil if the date is empty.
+func parseUsageTime(v string) (*timestamppb.Timestamp, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return timestamppb.New(t), nil
+}
+
+// parseFeatureUsage converts featureUsageSQL rows into features.
+func parseFeatureUsage(rows []string) ([]*dbdpb.GetFeatureUsageResponse_Feature, error) {
+	var features []*dbdpb.GetFeatureUsageResponse_Feature
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse feature usage row %q: %v", msg, err)
+		}
+		usages, err := strconv.ParseInt(row["DETECTED_USAGES"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DETECTED_USAGES in feature usage row %q: %v", msg, err)
+		}
+		first, err := parseUsageTime(row["FIRST_USAGE_DATE"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse FIRST_USAGE_DATE in feature usage row %q: %v", msg, err)
+		}
+		last, err := parseUsageTime(row["LAST_USAGE_DATE"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LAST_USAGE_DATE in feature usage row %q: %v", msg, err)
+		}
+		features = append(features, &dbdpb.GetFeatureUsageResponse_Feature{
+			Name:           row["NAME"],
+			Version:        row["VERSION"],
+			DetectedUsages: usages,
+			CurrentlyUsed:  row["CURRENTLY_USED"] == "TRUE",
+			FirstUsageTime: first,
+			LastUsageTime:  last,
+		})
+	}
+	return features, nil
+}
+
+// optionUnlicensedReason explains why the license doesn't cover the option,
+// empty if it does. Express and Free include the options, Standard can't
+// license them and Enterprise requires them to be licensed.
+func optionUnlicensedReason(edition string, licensed []dbdpb.GetFeatureUsageRequest_Option, option dbdpb.GetFeatureUsageRequest_Option) string {
+	switch strings.ToUpper(edition) {
+	case "EXPRESS", "XE", "FREE":
+		return ""
+	case "STANDARD", "SE", "SE2":
+		return fmt.Sprintf("%v requires Enterprise Edition, the edition is %s", option, edition)
+	}
+	for _, o := range licensed {
+		if o == option {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%v isn't licensed", option)
+}
+
+// licenseViolations returns the features in use which require an option
+// that the license doesn't cover, one per feature.
+func licenseViolations(edition string, licensed []dbdpb.GetFeatureUsageRequest_Option, features []*dbdpb.GetFeatureUsageResponse_Feature) []*dbdpb.GetFeatureUsageResponse_Violation {
+	seen := make(map[string]bool)
+	var violations []*dbdpb.GetFeatureUsageResponse_Violation
+	for _, f := range features {
+		o, ok := featureOptions[f.GetName()]
+		if !ok || seen[f.GetName()] || (f.GetDetectedUsages() == 0 && !f.GetCurrentlyUsed()) {
+			continue
+		}
+		if reason := optionUnlicensedReason(edition, licensed, o); reason != "" {
+			seen[f.GetName()] = true
+			violations = append(violations, &dbdpb.GetFeatureUsageResponse_Violation{Feature: f.GetName(), Option: o, Reason: reason})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].GetFeature() < violations[j].GetFeature()
+	})
+	return violations
+}
+
+// GetFeatureUsage reports the features of dba_feature_usage_statistics used
+// at least once and flags the ones the license doesn't cover.
+func (s *Server) GetFeatureUsage(ctx context.Context, req *dbdpb.GetFeatureUsageRequest) (*dbdpb.GetFeatureUsageResponse, error) {
+	klog.InfoS("dbdaemon/GetFeatureUsage", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{featureUsageSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFeatureUsage: failed to query the feature usage: %v", err)
+	}
+	features, err := parseFeatureUsage(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFeatureUsage: %v", err)
+	}
+	violations := licenseViolations(req.GetEdition(), req.GetLicensedOptions(), features)
+	for _, v := range violations {
+		klog.InfoS("dbdaemon/GetFeatureUsage: unlicensed feature in use", "feature", v.GetFeature(), "option", v.GetOption(), "reason", v.GetReason())
+	}
+	return &dbdpb.GetFeatureUsageResponse{Features: features, Violations: violations}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseFeatureUsage(t *testing.T) {
+	rows := []string{
+		`{"NAME": "Partitioning (user)", "VERSION": "19.0.0.0.0", "DETECTED_USAGES": "12", "CURRENTLY_USED": "TRUE", "FIRST_USAGE_DATE": "2023-01-02T03:04:05+02:00", "LAST_USAGE_DATE": "2023-02-02T03:04:05+02:00"}`,
+		`{"NAME": "AWR Report", "VERSION": "19.0.0.0.0", "DETECTED_USAGES": "1", "CURRENTLY_USED": "FALSE", "FIRST_USAGE_DATE": "2023-01-10T00:00:00+00:00", "LAST_USAGE_DATE": ""}`,
+	}
+	first := time.Date(2023, 1, 2, 1, 4, 5, 0, time.UTC)
+	want := []*dbdpb.GetFeatureUsageResponse_Feature{
+		{
+			Name:           "Partitioning (user)",
+			Version:        "19.0.0.0.0",
+			DetectedUsages: 12,
+			CurrentlyUsed:  true,
+			FirstUsageTime: timestamppb.New(first),
+			LastUsageTime:  timestamppb.New(first.AddDate(0, 1, 0)),
+		},
+		{
+			Name:           "AWR Report",
+			Version:        "19.0.0.0.0",
+			DetectedUsages: 1,
+			FirstUsageTime: timestamppb.New(time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC)),
+		},
+	}
+	got, err := parseFeatureUsage(rows)
+	if err != nil {
+		t.Fatalf("parseFeatureUsage failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseFeatureUsage got unexpected features (-want +got):\n%v", diff)
+	}
+
+	for _, row := range []string{
+		`{"NAME": "ADDM", "DETECTED_USAGES": ""}`,
+		`{"NAME": "ADDM", "DETECTED_USAGES": "1", "FIRST_USAGE_DATE": "02-JAN-23"}`,
+		`not json`,
+	} {
+		if _, err := parseFeatureUsage([]string{row}); err == nil {
+			t.Errorf("parseFeatureUsage(%q) succeeded, want error", row)
+		}
+	}
+}
+
+func TestLicenseViolations(t *testing.T) {
+	features := []*dbdpb.GetFeatureUsageResponse_Feature{
+		{Name: "Partitioning (user)", Version: "12.2.0.1.0", DetectedUsages: 3},
+		{Name: "Partitioning (user)", Version: "19.0.0.0.0", DetectedUsages: 5, CurrentlyUsed: true},
+		{Name: "In-Memory Column Store", DetectedUsages: 1},
+		{Name: "AWR Report", DetectedUsages: 2},
+		{Name: "SQL Tuning Advisor"},
+		{Name: "Data Guard", DetectedUsages: 7},
+	}
+	tests := []struct {
+		name     string
+		edition  string
+		licensed []dbdpb.GetFeatureUsageRequest_Option
+		want     []*dbdpb.GetFeatureUsageResponse_Violation
+	}{
+		{
+			name:    "enterprise without options",
+			edition: "Enterprise",
+			want: []*dbdpb.GetFeatureUsageResponse_Violation{
+				{Feature: "AWR Report", Option: dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK, Reason: "DIAGNOSTICS_PACK isn't licensed"},
+				{Feature: "In-Memory Column Store", Option: dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY, Reason: "DATABASE_IN_MEMORY isn't licensed"},
+				{Feature: "Partitioning (user)", Option: dbdpb.GetFeatureUsageRequest_PARTITIONING, Reason: "PARTITIONING isn't licensed"},
+			},
+		},
+		{
+			name:     "enterprise by default with licensed options",
+			licensed: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_PARTITIONING, dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK},
+			want: []*dbdpb.GetFeatureUsageResponse_Violation{
+				{Feature: "In-Memory Column Store", Option: dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY, Reason: "DATABASE_IN_MEMORY isn't licensed"},
+			},
+		},
+		{
+			name:     "standard",
+			edition:  "Standard",
+			licensed: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK},
+			want: []*dbdpb.GetFeatureUsageResponse_Violation{
+				{Feature: "AWR Report", Option: dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK, Reason: "DIAGNOSTICS_PACK requires Enterprise Edition, the edition is Standard"},
+				{Feature: "In-Memory Column Store", Option: dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY, Reason: "DATABASE_IN_MEMORY requires Enterprise Edition, the edition is Standard"},
+				{Feature: "Partitioning (user)", Option: dbdpb.GetFeatureUsageRequest_PARTITIONING, Reason: "PARTITIONING requires Enterprise Edition, the edition is Standard"},
+			},
+		},
+		{
+			name:    "express",
+			edition: "Express",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := licenseViolations(tc.edition, tc.licensed, features)
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("licenseViolations got unexpected violations (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
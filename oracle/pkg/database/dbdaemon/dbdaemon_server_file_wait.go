@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// maxFileWait caps how long FileExists waits for a file, whatever the
+// caller requests, so a request can't hold a server goroutine indefinitely.
+const maxFileWait = 30 * time.Second
+
+// fileWaitBackoff returns the schedule of the checks while waiting for a
+// file. The checks are local and cheap, the cap bounds how long after the
+// file appears the wait returns.
+func fileWaitBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: 100 * time.Millisecond,
+		Factor:   2,
+		Jitter:   0.2,
+		Steps:    10,
+		Cap:      2 * time.Second,
+	}
+}
+
+// waitForFile waits up to timeout, at most maxFileWait, for the file to
+// exist and reports whether it does. It returns as soon as the file appears
+// or ctx is done, and stops early if the file status can't be determined,
+// leaving the error to the caller's own check.
+func waitForFile(ctx context.Context, name string, timeout time.Duration) bool {
+	if timeout > maxFileWait {
+		timeout = maxFileWait
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := fileWaitBackoff()
+	for {
+		_, err := os.Stat(name)
+		if err == nil {
+			return true
+		}
+		if !os.IsNotExist(err) {
+			return false
+		}
+		timer := time.NewTimer(backoff.Step())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWaitBackoff(t *testing.T) {
+	backoff := fileWaitBackoff()
+	want := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		1600 * time.Millisecond,
+		2 * time.Second,
+		2 * time.Second,
+		2 * time.Second,
+	}
+	for i, base := range want {
+		// Jitter only ever adds up to 20% to the delay.
+		if got := backoff.Step(); got < base || got > base+base/5 {
+			t.Errorf("step %d got delay %v, want between %v and %v", i, got, base, base+base/5)
+		}
+	}
+}
+
+func TestWaitForFile(t *testing.T) {
+	ctx := context.Background()
+	name := filepath.Join(t.TempDir(), "provisioning_successful")
+
+	start := time.Now()
+	if waitForFile(ctx, name, 300*time.Millisecond) {
+		t.Errorf("waitForFile(%q) = true for a missing file, want false", name)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("waitForFile(%q) returned after %v, want it to wait for the 300ms timeout", name, elapsed)
+	}
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Errorf("failed to create %q: %v", name, err)
+		}
+	}()
+	start = time.Now()
+	if !waitForFile(ctx, name, time.Minute) {
+		t.Errorf("waitForFile(%q) = false after the file was created, want true", name)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("waitForFile(%q) returned after %v, want it to return soon after the file appeared", name, elapsed)
+	}
+}
+
+func TestWaitForFileCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	name := filepath.Join(t.TempDir(), "provisioning_successful")
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	if waitForFile(ctx, name, time.Minute) {
+		t.Errorf("waitForFile(%q) = true for a missing file, want false", name)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waitForFile(%q) returned %v after the context was cancelled, want it to return promptly", name, elapsed)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// fraDestSQL always returns a single row, with zeros if no FRA
+	// is configured.
+	fraDestSQL = "select nvl(sum(space_limit), 0) as space_limit, " +
+		"nvl(sum(space_used), 0) as space_used, " +
+		"nvl(sum(space_reclaimable), 0) as space_reclaimable, " +
+		"nvl(sum(number_of_files), 0) as number_of_files " +
+		"from v$recovery_file_dest"
+
+	fraUsageSQL = "select file_type, percent_space_used, percent_space_reclaimable, number_of_files " +
+		"from v$recovery_area_usage order by file_type"
+)
+
+// parseFRADest fills the FRA totals from the fraDestSQL row.
+func parseFRADest(rows []string, resp *dbdpb.GetFRAUsageResponse) error {
+	if len(rows) != 1 {
+		return fmt.Errorf("expected 1 recovery file destination row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return fmt.Errorf("failed to parse recovery file destination row %q: %v", rows[0], err)
+	}
+	for _, f := range []struct {
+		col string
+		val *int64
+	}{
+		{col: "SPACE_LIMIT", val: &resp.SpaceLimitBytes},
+		{col: "SPACE_USED", val: &resp.SpaceUsedBytes},
+		{col: "SPACE_RECLAIMABLE", val: &resp.SpaceReclaimableBytes},
+		{col: "NUMBER_OF_FILES", val: &resp.NumberOfFiles},
+	} {
+		v, err := strconv.ParseInt(row[f.col], 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s in recovery file destination row %q: %v", f.col, rows[0], err)
+		}
+		*f.val = v
+	}
+	return nil
+}
+
+// parseFRAUsage converts fraUsageSQL rows into the response representation.
+func parseFRAUsage(rows []string) ([]*dbdpb.GetFRAUsageResponse_FileTypeUsage, error) {
+	var usage []*dbdpb.GetFRAUsageResponse_FileTypeUsage
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse recovery area usage row %q: %v", msg, err)
+		}
+		used, err := strconv.ParseFloat(row["PERCENT_SPACE_USED"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PERCENT_SPACE_USED in recovery area usage row %q: %v", msg, err)
+		}
+		reclaimable, err := strconv.ParseFloat(row["PERCENT_SPACE_RECLAIMABLE"], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PERCENT_SPACE_RECLAIMABLE in recovery area usage row %q: %v", msg, err)
+		}
+		files, err := strconv.ParseInt(row["NUMBER_OF_FILES"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse NUMBER_OF_FILES in recovery area usage row %q: %v", msg, err)
+		}
+		usage = append(usage, &dbdpb.GetFRAUsageResponse_FileTypeUsage{
+			FileType:                row["FILE_TYPE"],
+			PercentSpaceUsed:        used,
+			PercentSpaceReclaimable: reclaimable,
+			NumberOfFiles:           files,
+		})
+	}
+	return usage, nil
+}
+
+// GetFRAUsage reports the fast recovery area space usage from
+// v$recovery_file_dest and v$recovery_area_usage.
+func (s *Server) GetFRAUsage(ctx context.Context, req *dbdpb.GetFRAUsageRequest) (*dbdpb.GetFRAUsageResponse, error) {
+	klog.InfoS("dbdaemon/GetFRAUsage", "req", loggableRequest(req), "serverObj", s)
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	destResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fraDestSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFRAUsage: failed to query recovery file destination: %v", err)
+	}
+	resp := &dbdpb.GetFRAUsageResponse{}
+	if err := parseFRADest(destResp.GetMsg(), resp); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFRAUsage: %v", err)
+	}
+
+	usageResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fraUsageSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFRAUsage: failed to query recovery area usage: %v", err)
+	}
+	if resp.FileTypes, err = parseFRAUsage(usageResp.GetMsg()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetFRAUsage: %v", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var (
+	fraDestRows = []string{
+		`{"SPACE_LIMIT":"10737418240","SPACE_USED":"9126805504","SPACE_RECLAIMABLE":"1073741824","NUMBER_OF_FILES":"42"}`,
+	}
+	fraUsageRows = []string{
+		`{"FILE_TYPE":"ARCHIVED LOG","PERCENT_SPACE_USED":"72.5","PERCENT_SPACE_RECLAIMABLE":"10","NUMBER_OF_FILES":"37"}`,
+		`{"FILE_TYPE":"BACKUP PIECE","PERCENT_SPACE_USED":"12.5","PERCENT_SPACE_RECLAIMABLE":".5","NUMBER_OF_FILES":"5"}`,
+		`{"FILE_TYPE":"CONTROL FILE","PERCENT_SPACE_USED":"0","PERCENT_SPACE_RECLAIMABLE":"0","NUMBER_OF_FILES":"0"}`,
+	}
+)
+
+func TestParseFRADest(t *testing.T) {
+	got := &dbdpb.GetFRAUsageResponse{}
+	if err := parseFRADest(fraDestRows, got); err != nil {
+		t.Fatalf("parseFRADest failed: %v", err)
+	}
+	want := &dbdpb.GetFRAUsageResponse{
+		SpaceLimitBytes:       10737418240,
+		SpaceUsedBytes:        9126805504,
+		SpaceReclaimableBytes: 1073741824,
+		NumberOfFiles:         42,
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseFRADest got unexpected result (-want +got):\n%v", diff)
+	}
+
+	for _, rows := range [][]string{
+		nil,
+		{fraDestRows[0], fraDestRows[0]},
+		{"not json"},
+		{`{"SPACE_LIMIT":"10G","SPACE_USED":"0","SPACE_RECLAIMABLE":"0","NUMBER_OF_FILES":"0"}`},
+	} {
+		if err := parseFRADest(rows, &dbdpb.GetFRAUsageResponse{}); err == nil {
+			t.Errorf("parseFRADest(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+func TestParseFRAUsage(t *testing.T) {
+	got, err := parseFRAUsage(fraUsageRows)
+	if err != nil {
+		t.Fatalf("parseFRAUsage failed: %v", err)
+	}
+	want := []*dbdpb.GetFRAUsageResponse_FileTypeUsage{
+		{FileType: "ARCHIVED LOG", PercentSpaceUsed: 72.5, PercentSpaceReclaimable: 10, NumberOfFiles: 37},
+		{FileType: "BACKUP PIECE", PercentSpaceUsed: 12.5, PercentSpaceReclaimable: 0.5, NumberOfFiles: 5},
+		{FileType: "CONTROL FILE"},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseFRAUsage got unexpected result (-want +got):\n%v", diff)
+	}
+
+	for _, row := range []string{
+		"not json",
+		`{"FILE_TYPE":"ARCHIVED LOG","PERCENT_SPACE_USED":"","PERCENT_SPACE_RECLAIMABLE":"0","NUMBER_OF_FILES":"0"}`,
+		`{"FILE_TYPE":"ARCHIVED LOG","PERCENT_SPACE_USED":"0","PERCENT_SPACE_RECLAIMABLE":"x","NUMBER_OF_FILES":"0"}`,
+		`{"FILE_TYPE":"ARCHIVED LOG","PERCENT_SPACE_USED":"0","PERCENT_SPACE_RECLAIMABLE":"0","NUMBER_OF_FILES":"1.5"}`,
+	} {
+		if _, err := parseFRAUsage([]string{row}); err == nil {
+			t.Errorf("parseFRAUsage(%q) succeeded, want error", row)
+		}
+	}
+}
+
+func TestGetFRAUsage(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[len(sqls)-1] {
+		case fraDestSQL:
+			return fraDestRows, nil
+		case fraUsageSQL:
+			return fraUsageRows, nil
+		}
+		t.Fatalf("runQuery got unexpected query %q", sqls)
+		return nil, nil
+	}
+
+	resp, err := s.GetFRAUsage(ctx, &dbdpb.GetFRAUsageRequest{})
+	if err != nil {
+		t.Fatalf("GetFRAUsage failed: %v", err)
+	}
+	if got, want := resp.GetSpaceLimitBytes(), int64(10737418240); got != want {
+		t.Errorf("GetFRAUsage space limit = %d, want %d", got, want)
+	}
+	if got, want := len(resp.GetFileTypes()), len(fraUsageRows); got != want {
+		t.Errorf("GetFRAUsage reported %d file types, want %d", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+const (
+	currentSCNSQL = "select current_scn from v$database"
+
+	fullExportManifestName = "manifest.json"
+	contentTypeJSON        = "application/json"
+)
+
+// dataPumpExportPDB exports a PDB of a full instance export, tests replace
+// it.
+var dataPumpExportPDB = (*Server).runDataPumpExport
+
+// fullExportManifest describes the exports of a full instance export.
+type fullExportManifest struct {
+	CreateTime time.Time `json:"createTime"`
+	// FlashbackSCN or FlashbackTime is what the PDBs were exported as of.
+	FlashbackSCN  string                  `json:"flashbackScn,omitempty"`
+	FlashbackTime string                  `json:"flashbackTime,omitempty"`
+	PDBs          []fullExportManifestPDB `json:"pdbs"`
+}
+
+type fullExportManifestPDB struct {
+	Name     string `json:"name"`
+	DumpFile string `json:"dumpFile"`
+	LogFile  string `json:"logFile"`
+}
+
+// fullExportPaths returns the GCS paths of the dump and log files of a PDB
+// under the prefix of a full instance export.
+func fullExportPaths(gcsPrefix, pdb string) (string, string) {
+	prefix := strings.TrimSuffix(gcsPrefix, "/") + "/" + pdb + "/"
+	return prefix + "export.dmp", prefix + "export.log"
+}
+
+// fullExportRequests returns the requests exporting each PDB in full as of
+// scn, or as of the flashback time of the request if set.
+func fullExportRequests(req *dbdpb.FullInstanceExportRequest, pdbs []string, scn string) []*dbdpb.DataPumpExportRequest {
+	var exports []*dbdpb.DataPumpExportRequest
+	for _, pdb := range pdbs {
+		dmpPath, logPath := fullExportPaths(req.GetGcsPrefix(), pdb)
+		params := append([]string(nil), req.GetCommandParams()...)
+		if req.GetFlashbackTime() == "" {
+			params = append(params, "FLASHBACK_SCN="+scn)
+		}
+		exports = append(exports, &dbdpb.DataPumpExportRequest{
+			PdbName:       pdb,
+			DbDomain:      req.GetDbDomain(),
+			ObjectType:    "FULL",
+			Objects:       "Y",
+			CommandParams: params,
+			GcsPath:       dmpPath,
+			GcsLogPath:    logPath,
+			FlashbackTime: req.GetFlashbackTime(),
+		})
+	}
+	return exports
+}
+
+// fullExportPDBs returns the PDBs of a full instance export and the current
+// SCN of the instance.
+func (s *Server) fullExportPDBs(ctx context.Context, req *dbdpb.FullInstanceExportRequest) ([]string, string, error) {
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	var pdbs []string
+	for _, pdb := range req.GetPdbNames() {
+		pdbs = append(pdbs, strings.ToUpper(pdb))
+	}
+	if len(pdbs) == 0 {
+		resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{readWritePDBsSQL}}, true)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query read write PDBs: %v", err)
+		}
+		if pdbs, err = parsePDBNames(resp.GetMsg()); err != nil {
+			return nil, "", err
+		}
+		if len(pdbs) == 0 {
+			return nil, "", fmt.Errorf("no read write PDB to export")
+		}
+	}
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{currentSCNSQL}}, true)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query the current SCN: %v", err)
+	}
+	if len(resp.GetMsg()) != 1 {
+		return nil, "", fmt.Errorf("unexpected current SCN rows %q", resp.GetMsg())
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(resp.GetMsg()[0]), &row); err != nil || row["CURRENT_SCN"] == "" {
+		return nil, "", fmt.Errorf("failed to parse the current SCN row %q: %v", resp.GetMsg()[0], err)
+	}
+	return pdbs, row["CURRENT_SCN"], nil
+}
+
+// uploadFullExportManifest uploads the manifest of a full instance export.
+func (s *Server) uploadFullExportManifest(ctx context.Context, gcsPath string, m *fullExportManifest) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(scratchDir(), "manifest")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.gcsUtil.UploadFile(ctx, gcsPath, f.Name(), contentTypeJSON)
+}
+
+// fullInstanceExport exports the PDBs one after the other holding
+// pdbLoadMutex, then uploads the manifest. An export failure fails the
+// operation without a manifest.
+func (s *Server) fullInstanceExport(ctx context.Context, req *dbdpb.FullInstanceExportRequest) (*dbdpb.FullInstanceExportResponse, error) {
+	s.syncJobs.pdbLoadMutex.Lock()
+	defer s.syncJobs.pdbLoadMutex.Unlock()
+
+	pdbs, scn, err := s.fullExportPDBs(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceExport: %v", err)
+	}
+	manifest := &fullExportManifest{CreateTime: time.Now().UTC(), FlashbackTime: req.GetFlashbackTime()}
+	if req.GetFlashbackTime() == "" {
+		manifest.FlashbackSCN = scn
+	}
+	resp := &dbdpb.FullInstanceExportResponse{}
+	for _, export := range fullExportRequests(req, pdbs, scn) {
+		klog.InfoS("dbdaemon/FullInstanceExport: exporting PDB", "pdb", export.GetPdbName(), "gcsPath", export.GetGcsPath())
+		if _, err := dataPumpExportPDB(s, ctx, export); err != nil {
+			return nil, fmt.Errorf("dbdaemon/FullInstanceExport: failed to export PDB %s: %v", export.GetPdbName(), err)
+		}
+		manifest.PDBs = append(manifest.PDBs, fullExportManifestPDB{
+			Name:     export.GetPdbName(),
+			DumpFile: export.GetGcsPath(),
+			LogFile:  export.GetGcsLogPath(),
+		})
+		resp.Exports = append(resp.Exports, &dbdpb.FullInstanceExportResponse_Export{
+			PdbName:    export.GetPdbName(),
+			GcsPath:    export.GetGcsPath(),
+			GcsLogPath: export.GetGcsLogPath(),
+		})
+	}
+
+	resp.ManifestGcsPath = strings.TrimSuffix(req.GetGcsPrefix(), "/") + "/" + fullExportManifestName
+	if err := s.uploadFullExportManifest(ctx, resp.GetManifestGcsPath(), manifest); err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceExport: failed to upload the manifest to %s: %v", resp.GetManifestGcsPath(), err)
+	}
+	klog.InfoS("dbdaemon/FullInstanceExport: done", "pdbs", pdbs, "manifest", resp.GetManifestGcsPath())
+	return resp, nil
+}
+
+// FullInstanceExportAsync turns fullInstanceExport into an async call.
+func (s *Server) FullInstanceExportAsync(ctx context.Context, req *dbdpb.FullInstanceExportAsyncRequest) (*lropb.Operation, error) {
+	klog.InfoS("dbdaemon/FullInstanceExportAsync", "req", loggableRequest(req))
+	if _, _, err := s.gcsUtil.SplitURI(req.GetSyncRequest().GetGcsPrefix()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceExportAsync: invalid GCS prefix: %v", err)
+	}
+	for _, pdb := range req.GetSyncRequest().GetPdbNames() {
+		if _, err := sqlq.ObjectName(pdb); err != nil || pdb == "" {
+			return nil, fmt.Errorf("dbdaemon/FullInstanceExportAsync: invalid PDB name %q", pdb)
+		}
+	}
+	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "FullInstanceExport", s.lroServer,
+		func(ctx context.Context) (proto.Message, error) {
+			return s.fullInstanceExport(ctx, req.GetSyncRequest())
+		})
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/FullInstanceExportAsync failed to create an LRO job", "request", loggableRequest(req))
+		return nil, err
+	}
+	return &lropb.Operation{Name: job.ID(), Done: false}, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestFullExportRequests(t *testing.T) {
+	req := &dbdpb.FullInstanceExportRequest{
+		DbDomain:      "gke",
+		GcsPrefix:     "gs://bucket/exports/",
+		CommandParams: []string{"PARALLEL=2"},
+	}
+	got := fullExportRequests(req, []string{"PDB1", "PDB2"}, "2345678")
+	want := []*dbdpb.DataPumpExportRequest{
+		{
+			PdbName:       "PDB1",
+			DbDomain:      "gke",
+			ObjectType:    "FULL",
+			Objects:       "Y",
+			CommandParams: []string{"PARALLEL=2", "FLASHBACK_SCN=2345678"},
+			GcsPath:       "gs://bucket/exports/PDB1/export.dmp",
+			GcsLogPath:    "gs://bucket/exports/PDB1/export.log",
+		},
+		{
+			PdbName:       "PDB2",
+			DbDomain:      "gke",
+			ObjectType:    "FULL",
+			Objects:       "Y",
+			CommandParams: []string{"PARALLEL=2", "FLASHBACK_SCN=2345678"},
+			GcsPath:       "gs://bucket/exports/PDB2/export.dmp",
+			GcsLogPath:    "gs://bucket/exports/PDB2/export.log",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("fullExportRequests got unexpected requests (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"PARALLEL=2"}, req.GetCommandParams()); diff != "" {
+		t.Errorf("fullExportRequests changed the command params of the request (-want +got):\n%v", diff)
+	}
+
+	req.FlashbackTime = "2022-10-16 13:04:05"
+	for _, r := range fullExportRequests(req, []string{"PDB1"}, "2345678") {
+		if r.GetFlashbackTime() != req.GetFlashbackTime() || len(r.GetCommandParams()) != 1 {
+			t.Errorf("fullExportRequests with a flashback time got %v, want the flashback time and no FLASHBACK_SCN", r)
+		}
+	}
+}
+
+// useFakeDataPumpExport records the PDB exports instead of running them,
+// the export of failPDB fails.
+func useFakeDataPumpExport(t *testing.T, failPDB string) *[]string {
+	t.Helper()
+	orig := dataPumpExportPDB
+	var exported []string
+	dataPumpExportPDB = func(s *Server, ctx context.Context, req *dbdpb.DataPumpExportRequest) (*dbdpb.DataPumpExportResponse, error) {
+		if req.GetPdbName() == failPDB {
+			return nil, fmt.Errorf("ORA-39001: invalid argument value")
+		}
+		exported = append(exported, req.GetPdbName())
+		return &dbdpb.DataPumpExportResponse{}, nil
+	}
+	t.Cleanup(func() { dataPumpExportPDB = orig })
+	return &exported
+}
+
+func newFullExportServer(ctx context.Context, t *testing.T) (*Server, *fakeGCSUtil) {
+	t.Helper()
+	useFakeOracleDatabase(t)
+	useWritableRoots(t, t.TempDir(), t.TempDir())
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	gcs := &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	s.gcsUtil = gcs
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case readWritePDBsSQL:
+			return []string{`{"NAME":"PDB1"}`, `{"NAME":"PDB2"}`}, nil
+		case currentSCNSQL:
+			return []string{`{"CURRENT_SCN":"2345678"}`}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	return s, gcs
+}
+
+func TestFullInstanceExport(t *testing.T) {
+	ctx := context.Background()
+	s, gcs := newFullExportServer(ctx, t)
+	exported := useFakeDataPumpExport(t, "")
+
+	resp, err := s.fullInstanceExport(ctx, &dbdpb.FullInstanceExportRequest{GcsPrefix: "gs://bucket/exports"})
+	if err != nil {
+		t.Fatalf("fullInstanceExport failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"PDB1", "PDB2"}, *exported); diff != "" {
+		t.Errorf("fullInstanceExport exported unexpected PDBs (-want +got):\n%v", diff)
+	}
+	wantResp := &dbdpb.FullInstanceExportResponse{
+		Exports: []*dbdpb.FullInstanceExportResponse_Export{
+			{PdbName: "PDB1", GcsPath: "gs://bucket/exports/PDB1/export.dmp", GcsLogPath: "gs://bucket/exports/PDB1/export.log"},
+			{PdbName: "PDB2", GcsPath: "gs://bucket/exports/PDB2/export.dmp", GcsLogPath: "gs://bucket/exports/PDB2/export.log"},
+		},
+		ManifestGcsPath: "gs://bucket/exports/manifest.json",
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("fullInstanceExport got unexpected response (-want +got):\n%v", diff)
+	}
+
+	var manifest fullExportManifest
+	if err := json.Unmarshal([]byte(gcs.uploads["gs://bucket/exports/manifest.json"]), &manifest); err != nil {
+		t.Fatalf("failed to parse the uploaded manifest: %v", err)
+	}
+	wantManifest := fullExportManifest{
+		FlashbackSCN: "2345678",
+		PDBs: []fullExportManifestPDB{
+			{Name: "PDB1", DumpFile: "gs://bucket/exports/PDB1/export.dmp", LogFile: "gs://bucket/exports/PDB1/export.log"},
+			{Name: "PDB2", DumpFile: "gs://bucket/exports/PDB2/export.dmp", LogFile: "gs://bucket/exports/PDB2/export.log"},
+		},
+	}
+	if diff := cmp.Diff(wantManifest, manifest, cmpopts.IgnoreFields(fullExportManifest{}, "CreateTime")); diff != "" {
+		t.Errorf("fullInstanceExport uploaded an unexpected manifest (-want +got):\n%v", diff)
+	}
+	if manifest.CreateTime.IsZero() {
+		t.Errorf("fullInstanceExport uploaded a manifest without createTime")
+	}
+	if got := gcs.contentTypes["gs://bucket/exports/manifest.json"]; got != contentTypeJSON {
+		t.Errorf("fullInstanceExport uploaded the manifest as %q, want %q", got, contentTypeJSON)
+	}
+}
+
+func TestFullInstanceExportFailure(t *testing.T) {
+	ctx := context.Background()
+	s, gcs := newFullExportServer(ctx, t)
+	exported := useFakeDataPumpExport(t, "PDB1")
+
+	if _, err := s.fullInstanceExport(ctx, &dbdpb.FullInstanceExportRequest{PdbNames: []string{"pdb1", "pdb2"}, GcsPrefix: "gs://bucket/exports"}); err == nil {
+		t.Fatalf("fullInstanceExport with a failing export succeeded, want error")
+	}
+	if len(*exported) != 0 {
+		t.Errorf("fullInstanceExport exported %v after a failure, want none", *exported)
+	}
+	if len(gcs.uploads) != 0 {
+		t.Errorf("fullInstanceExport uploaded %v after a failure, want no manifest", gcs.uploads)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/security"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+const (
+	pdbExistsSQL = "select name from v$pdbs where name='%s'"
+
+	// importPDBAdmin is the admin user of the PDBs created for an import,
+	// the same as of the PDBs of the Database resources.
+	importPDBAdmin = "GPDB_ADMIN"
+)
+
+var (
+	// dataPumpImportPDB imports a PDB of a full instance import, tests
+	// replace it.
+	dataPumpImportPDB = (*Server).runDataPumpImport
+
+	// pdbDataDir is the directory of the data files of a PDB.
+	pdbDataDir = func(sid, pdb string) string {
+		return fmt.Sprintf(consts.PDBDataDir, consts.DataMount, sid, pdb)
+	}
+)
+
+// parseFullExportManifest parses the manifest of a full instance export.
+func parseFullExportManifest(content []byte) (*fullExportManifest, error) {
+	m := &fullExportManifest{}
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest: %v", err)
+	}
+	if len(m.PDBs) == 0 {
+		return nil, fmt.Errorf("the manifest lists no PDB")
+	}
+	for _, pdb := range m.PDBs {
+		if _, err := sqlq.ObjectName(pdb.Name); err != nil || pdb.Name == "" || pdb.DumpFile == "" {
+			return nil, fmt.Errorf("invalid PDB %+v in the manifest", pdb)
+		}
+	}
+	return m, nil
+}
+
+// fullImportRequests returns the requests importing the PDBs of the
+// manifest, only those of pdbNames if any. The impdp log of a PDB is
+// uploaded next to its dump file.
+func fullImportRequests(req *dbdpb.FullInstanceImportRequest, m *fullExportManifest) ([]*dbdpb.DataPumpImportRequest, error) {
+	selected := make(map[string]bool)
+	for _, pdb := range req.GetPdbNames() {
+		selected[strings.ToUpper(pdb)] = true
+	}
+	var imports []*dbdpb.DataPumpImportRequest
+	for _, pdb := range m.PDBs {
+		name := strings.ToUpper(pdb.Name)
+		if len(selected) > 0 && !selected[name] {
+			continue
+		}
+		delete(selected, name)
+		imports = append(imports, &dbdpb.DataPumpImportRequest{
+			PdbName:       name,
+			DbDomain:      req.GetDbDomain(),
+			CommandParams: req.GetCommandParams(),
+			GcsPath:       pdb.DumpFile,
+			GcsLogPath:    pdb.DumpFile[:strings.LastIndex(pdb.DumpFile, "/")+1] + "import.log",
+		})
+	}
+	if len(selected) > 0 {
+		var names []string
+		for pdb := range selected {
+			names = append(names, pdb)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("PDBs %v aren't in the manifest", names)
+	}
+	return imports, nil
+}
+
+// createPDBStatements returns the statements creating and opening a PDB
+// for an import the way the Database resources do, and granting the Data
+// Pump privileges to the PDB loader user.
+func createPDBStatements(sid, pdb, adminPassword string) []string {
+	dataDir := pdbDataDir(sid, pdb)
+	return []string{
+		sqlq.QueryCreatePDB(pdb, importPDBAdmin, adminPassword, dataDir, pdb+"_USERS",
+			fmt.Sprintf("%s/%s_users.dbf", dataDir, strings.ToLower(pdb)),
+			fmt.Sprintf(consts.PDBSeedDir, consts.DataMount, sid), dataDir),
+		fmt.Sprintf("alter pluggable database %s open read write", sqlq.MustBeObjectName(pdb)),
+		fmt.Sprintf("alter pluggable database %s save state", sqlq.MustBeObjectName(pdb)),
+		sqlq.QuerySetSessionContainer(pdb),
+		sqlq.QueryGrantPrivileges("create session, dba", importPDBAdmin),
+		sqlq.QueryGrantPrivileges("create session, resource, datapump_imp_full_database, datapump_exp_full_database, unlimited tablespace", consts.PDBLoaderUser),
+	}
+}
+
+// createImportPDB creates a PDB for an import unless it exists, it reports
+// whether it created the PDB.
+func (s *Server) createImportPDB(ctx context.Context, pdb string) (bool, error) {
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf(pdbExistsSQL, sqlq.StringParam(pdb))}}, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if PDB %s exists: %v", pdb, err)
+	}
+	if len(resp.GetMsg()) > 0 {
+		return false, nil
+	}
+	if err := s.mkdirAllOracle(pdbDataDir(s.databaseSid.val, pdb), 0760); err != nil {
+		return false, fmt.Errorf("failed to create the data directory of PDB %s: %v", pdb, err)
+	}
+	password, err := security.RandOraclePassword()
+	if err != nil {
+		return false, fmt.Errorf("failed to generate the admin password of PDB %s: %v", pdb, err)
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: createPDBStatements(s.databaseSid.val, pdb, password),
+		Suppress: true,
+	}, false); err != nil {
+		return false, fmt.Errorf("failed to create PDB %s: %v", pdb, err)
+	}
+	klog.InfoS("dbdaemon/FullInstanceImport: created PDB", "pdb", pdb)
+	return true, nil
+}
+
+// downloadFullExportManifest downloads and parses the manifest of a full
+// instance export.
+func (s *Server) downloadFullExportManifest(ctx context.Context, gcsPath string) (*fullExportManifest, error) {
+	r, err := s.gcsUtil.Download(ctx, gcsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the manifest %s: %v", gcsPath, err)
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the manifest %s: %v", gcsPath, err)
+	}
+	return parseFullExportManifest(content)
+}
+
+// fullInstanceImport imports the PDBs of the manifest one after the other
+// holding pdbLoadMutex, creating those which don't exist. An import failure
+// fails the operation, the PDBs imported before are left as they are.
+func (s *Server) fullInstanceImport(ctx context.Context, req *dbdpb.FullInstanceImportRequest) (*dbdpb.FullInstanceImportResponse, error) {
+	s.syncJobs.pdbLoadMutex.Lock()
+	defer s.syncJobs.pdbLoadMutex.Unlock()
+
+	manifest, err := s.downloadFullExportManifest(ctx, req.GetManifestGcsPath())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceImport: %v", err)
+	}
+	imports, err := fullImportRequests(req, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceImport: %v", err)
+	}
+	resp := &dbdpb.FullInstanceImportResponse{}
+	for _, imp := range imports {
+		created, err := s.createImportPDB(ctx, imp.GetPdbName())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/FullInstanceImport: %v", err)
+		}
+		klog.InfoS("dbdaemon/FullInstanceImport: importing PDB", "pdb", imp.GetPdbName(), "gcsPath", imp.GetGcsPath())
+		if _, err := dataPumpImportPDB(s, ctx, imp); err != nil {
+			return nil, fmt.Errorf("dbdaemon/FullInstanceImport: failed to import PDB %s: %v", imp.GetPdbName(), err)
+		}
+		resp.Imports = append(resp.Imports, &dbdpb.FullInstanceImportResponse_Import{
+			PdbName:    imp.GetPdbName(),
+			Created:    created,
+			GcsLogPath: imp.GetGcsLogPath(),
+		})
+	}
+	klog.InfoS("dbdaemon/FullInstanceImport: done", "manifest", req.GetManifestGcsPath(), "imports", len(resp.GetImports()))
+	return resp, nil
+}
+
+// FullInstanceImportAsync turns fullInstanceImport into an async call.
+func (s *Server) FullInstanceImportAsync(ctx context.Context, req *dbdpb.FullInstanceImportAsyncRequest) (*lropb.Operation, error) {
+	klog.InfoS("dbdaemon/FullInstanceImportAsync", "req", loggableRequest(req))
+	if _, _, err := s.gcsUtil.SplitURI(req.GetSyncRequest().GetManifestGcsPath()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/FullInstanceImportAsync: invalid manifest GCS path: %v", err)
+	}
+	for _, pdb := range req.GetSyncRequest().GetPdbNames() {
+		if _, err := sqlq.ObjectName(pdb); err != nil || pdb == "" {
+			return nil, fmt.Errorf("dbdaemon/FullInstanceImportAsync: invalid PDB name %q", pdb)
+		}
+	}
+	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "FullInstanceImport", s.lroServer,
+		func(ctx context.Context) (proto.Message, error) {
+			return s.fullInstanceImport(ctx, req.GetSyncRequest())
+		})
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/FullInstanceImportAsync failed to create an LRO job", "request", loggableRequest(req))
+		return nil, err
+	}
+	return &lropb.Operation{Name: job.ID(), Done: false}, nil
+}
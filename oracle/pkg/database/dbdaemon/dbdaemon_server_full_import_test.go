@@ -0,0 +1,231 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const testFullExportManifest = `{
+  "createTime": "2022-10-16T13:04:05Z",
+  "flashbackScn": "2345678",
+  "pdbs": [
+    {"name": "PDB1", "dumpFile": "gs://bucket/exports/PDB1/export.dmp", "logFile": "gs://bucket/exports/PDB1/export.log"},
+    {"name": "PDB2", "dumpFile": "gs://bucket/exports/PDB2/export.dmp", "logFile": "gs://bucket/exports/PDB2/export.log"}
+  ]
+}`
+
+func TestParseFullExportManifest(t *testing.T) {
+	m, err := parseFullExportManifest([]byte(testFullExportManifest))
+	if err != nil {
+		t.Fatalf("parseFullExportManifest failed: %v", err)
+	}
+	if m.FlashbackSCN != "2345678" || len(m.PDBs) != 2 {
+		t.Errorf("parseFullExportManifest got %+v, want SCN 2345678 and 2 PDBs", m)
+	}
+
+	for _, content := range []string{
+		`not json`,
+		`{"pdbs": []}`,
+		`{"pdbs": [{"name": "PDB1"}]}`,
+		`{"pdbs": [{"name": "PDB\"1", "dumpFile": "gs://bucket/export.dmp"}]}`,
+	} {
+		if _, err := parseFullExportManifest([]byte(content)); err == nil {
+			t.Errorf("parseFullExportManifest(%s) succeeded, want error", content)
+		}
+	}
+}
+
+func TestFullImportRequests(t *testing.T) {
+	m, err := parseFullExportManifest([]byte(testFullExportManifest))
+	if err != nil {
+		t.Fatalf("parseFullExportManifest failed: %v", err)
+	}
+	req := &dbdpb.FullInstanceImportRequest{
+		DbDomain:      "gke",
+		CommandParams: []string{"PARALLEL=2"},
+	}
+	got, err := fullImportRequests(req, m)
+	if err != nil {
+		t.Fatalf("fullImportRequests failed: %v", err)
+	}
+	want := []*dbdpb.DataPumpImportRequest{
+		{
+			PdbName:       "PDB1",
+			DbDomain:      "gke",
+			CommandParams: []string{"PARALLEL=2"},
+			GcsPath:       "gs://bucket/exports/PDB1/export.dmp",
+			GcsLogPath:    "gs://bucket/exports/PDB1/import.log",
+		},
+		{
+			PdbName:       "PDB2",
+			DbDomain:      "gke",
+			CommandParams: []string{"PARALLEL=2"},
+			GcsPath:       "gs://bucket/exports/PDB2/export.dmp",
+			GcsLogPath:    "gs://bucket/exports/PDB2/import.log",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("fullImportRequests got unexpected requests (-want +got):\n%v", diff)
+	}
+
+	req.PdbNames = []string{"pdb2"}
+	got, err = fullImportRequests(req, m)
+	if err != nil {
+		t.Fatalf("fullImportRequests of PDB2 failed: %v", err)
+	}
+	if len(got) != 1 || got[0].GetPdbName() != "PDB2" {
+		t.Errorf("fullImportRequests of PDB2 got %v, want the import of PDB2 only", got)
+	}
+
+	req.PdbNames = []string{"PDB2", "PDB3"}
+	if _, err := fullImportRequests(req, m); err == nil || !strings.Contains(err.Error(), "PDB3") {
+		t.Errorf("fullImportRequests of a PDB not in the manifest got %v, want an error about PDB3", err)
+	}
+}
+
+func TestCreatePDBStatements(t *testing.T) {
+	got := createPDBStatements("MYDB", "PDB1", "secret")
+	want := []string{
+		`create pluggable database "PDB1" admin user "GPDB_ADMIN" identified by "secret" create_file_dest='/u02/app/oracle/oradata/MYDB/PDB1/data' default tablespace "PDB1_USERS" datafile '/u02/app/oracle/oradata/MYDB/PDB1/data/pdb1_users.dbf' size 1G autoextend on storage unlimited file_name_convert=('/u02/app/oracle/oradata/MYDB/pdbseed', '/u02/app/oracle/oradata/MYDB/PDB1/data')`,
+		`alter pluggable database "PDB1" open read write`,
+		`alter pluggable database "PDB1" save state`,
+		`alter session set container="PDB1"`,
+		`grant create session, dba to "GPDB_ADMIN"`,
+		`grant create session, resource, datapump_imp_full_database, datapump_exp_full_database, unlimited tablespace to "GCSQL$PDBLOADER"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("createPDBStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+}
+
+// useFakeDataPumpImport records the PDB imports instead of running them,
+// the import of failPDB fails.
+func useFakeDataPumpImport(t *testing.T, failPDB string) *[]*dbdpb.DataPumpImportRequest {
+	t.Helper()
+	orig := dataPumpImportPDB
+	var imported []*dbdpb.DataPumpImportRequest
+	dataPumpImportPDB = func(s *Server, ctx context.Context, req *dbdpb.DataPumpImportRequest) (*dbdpb.DataPumpImportResponse, error) {
+		if req.GetPdbName() == failPDB {
+			return nil, fmt.Errorf("ORA-39002: invalid operation")
+		}
+		imported = append(imported, req)
+		return &dbdpb.DataPumpImportResponse{}, nil
+	}
+	t.Cleanup(func() { dataPumpImportPDB = orig })
+	return &imported
+}
+
+// newFullImportServer returns a server with the test manifest uploaded on
+// which PDB1 exists and PDB2 doesn't, it records the statements it runs.
+func newFullImportServer(ctx context.Context, t *testing.T) (*Server, *[]string) {
+	t.Helper()
+	useFakeOracleDatabase(t)
+	useOracleOwner(t, os.Getuid(), os.Getgid())
+	dataDir := t.TempDir()
+	orig := pdbDataDir
+	pdbDataDir = func(sid, pdb string) string { return filepath.Join(dataDir, pdb) }
+	t.Cleanup(func() { pdbDataDir = orig })
+
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.gcsUtil = &fakeGCSUtil{
+		uploads:      map[string]string{"gs://bucket/exports/manifest.json": testFullExportManifest},
+		contentTypes: make(map[string]string),
+	}
+	var executed []string
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case fmt.Sprintf(pdbExistsSQL, "PDB1"):
+			return []string{`{"NAME":"PDB1"}`}, nil
+		case fmt.Sprintf(pdbExistsSQL, "PDB2"):
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		executed = append(executed, sqls...)
+		return nil, nil
+	}
+	return s, &executed
+}
+
+func TestFullInstanceImport(t *testing.T) {
+	ctx := context.Background()
+	s, executed := newFullImportServer(ctx, t)
+	imported := useFakeDataPumpImport(t, "")
+
+	resp, err := s.fullInstanceImport(ctx, &dbdpb.FullInstanceImportRequest{ManifestGcsPath: "gs://bucket/exports/manifest.json"})
+	if err != nil {
+		t.Fatalf("fullInstanceImport failed: %v", err)
+	}
+	var gotImports []string
+	for _, req := range *imported {
+		gotImports = append(gotImports, req.GetPdbName()+" "+req.GetGcsPath())
+	}
+	wantImports := []string{"PDB1 gs://bucket/exports/PDB1/export.dmp", "PDB2 gs://bucket/exports/PDB2/export.dmp"}
+	if diff := cmp.Diff(wantImports, gotImports); diff != "" {
+		t.Errorf("fullInstanceImport imported unexpected dumps (-want +got):\n%v", diff)
+	}
+	wantResp := &dbdpb.FullInstanceImportResponse{
+		Imports: []*dbdpb.FullInstanceImportResponse_Import{
+			{PdbName: "PDB1", GcsLogPath: "gs://bucket/exports/PDB1/import.log"},
+			{PdbName: "PDB2", Created: true, GcsLogPath: "gs://bucket/exports/PDB2/import.log"},
+		},
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("fullInstanceImport got unexpected response (-want +got):\n%v", diff)
+	}
+	if len(*executed) == 0 || !strings.HasPrefix((*executed)[0], `create pluggable database "PDB2" `) {
+		t.Errorf("fullInstanceImport ran %v, want the creation of PDB2", *executed)
+	}
+	for _, sql := range *executed {
+		if strings.Contains(sql, "PDB1") {
+			t.Errorf("fullInstanceImport ran %q, want no statement on the existing PDB1", sql)
+		}
+	}
+	if _, err := os.Stat(pdbDataDir("", "PDB2")); err != nil {
+		t.Errorf("fullInstanceImport didn't create the data directory of PDB2: %v", err)
+	}
+}
+
+func TestFullInstanceImportFailure(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newFullImportServer(ctx, t)
+	imported := useFakeDataPumpImport(t, "PDB1")
+
+	if _, err := s.fullInstanceImport(ctx, &dbdpb.FullInstanceImportRequest{ManifestGcsPath: "gs://bucket/exports/manifest.json"}); err == nil {
+		t.Fatalf("fullInstanceImport with a failing import succeeded, want error")
+	}
+	if len(*imported) != 0 {
+		t.Errorf("fullInstanceImport imported %v after a failure, want none", *imported)
+	}
+	if _, err := s.fullInstanceImport(ctx, &dbdpb.FullInstanceImportRequest{ManifestGcsPath: "gs://bucket/missing/manifest.json"}); err == nil {
+		t.Errorf("fullInstanceImport of a missing manifest succeeded, want error")
+	}
+}
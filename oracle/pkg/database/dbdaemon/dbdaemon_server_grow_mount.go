@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// filesystemOverheadPercent is the share of a disk taken by the
+	// filesystem metadata, the filesystem of an expanded disk is smaller
+	// than the disk.
+	filesystemOverheadPercent = 5
+
+	// autoextendDatafilesSQL lists the autoextensible datafiles on a mount
+	// which grew to within 10% of their autoextend limit. Datafiles of
+	// smallfile tablespaces at the 4194302 blocks limit can't grow further.
+	autoextendDatafilesSQL = "select c.name as con_name, f.file_id, f.file_name " +
+		"from cdb_data_files f join v$containers c on c.con_id = f.con_id " +
+		"where f.autoextensible = 'YES' and f.maxbytes - f.bytes < f.maxbytes / 10 " +
+		"and f.maxblocks < 4194302 and f.file_name like '%s/%%' " +
+		"order by c.name, f.file_id"
+
+	autoextendUnlimitedCmd = "alter database datafile %s autoextend on maxsize unlimited"
+)
+
+// mountPath matches the mount paths GrowMount accepts, which are used in
+// autoextendDatafilesSQL.
+var mountPath = regexp.MustCompile(`^(/[A-Za-z0-9_.-]+)+$`)
+
+// autoextendDatafile is a row of autoextendDatafilesSQL.
+type autoextendDatafile struct {
+	container string
+	fileID    string
+	fileName  string
+}
+
+// filesystemGrown reports whether a filesystem of totalBytes uses a disk of
+// expectedBytes.
+func filesystemGrown(totalBytes, expectedBytes int64) bool {
+	return totalBytes >= expectedBytes/100*(100-filesystemOverheadPercent)
+}
+
+// parseAutoextendDatafiles converts autoextendDatafilesSQL rows.
+func parseAutoextendDatafiles(rows []string) ([]autoextendDatafile, error) {
+	var files []autoextendDatafile
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse datafile row %q: %v", msg, err)
+		}
+		if row["CON_NAME"] == "" || row["FILE_ID"] == "" || row["FILE_NAME"] == "" {
+			return nil, fmt.Errorf("failed to parse datafile row %q: missing CON_NAME, FILE_ID or FILE_NAME", msg)
+		}
+		files = append(files, autoextendDatafile{container: row["CON_NAME"], fileID: row["FILE_ID"], fileName: row["FILE_NAME"]})
+	}
+	return files, nil
+}
+
+// autoextendStatements returns the statements lifting the autoextend limit
+// of the datafiles, switching to the container of each datafile first. The
+// files are expected to be ordered by container.
+func autoextendStatements(files []autoextendDatafile) ([]string, error) {
+	var statements []string
+	container := ""
+	for _, f := range files {
+		if _, err := sqlq.ObjectName(f.container); err != nil {
+			return nil, fmt.Errorf("invalid container name %q: %v", f.container, err)
+		}
+		if !allDigits.MatchString(f.fileID) {
+			return nil, fmt.Errorf("invalid file id %q of datafile %s", f.fileID, f.fileName)
+		}
+		if f.container != container {
+			statements = append(statements, sqlq.QuerySetSessionContainer(f.container))
+			container = f.container
+		}
+		statements = append(statements, fmt.Sprintf(autoextendUnlimitedCmd, f.fileID))
+	}
+	return statements, nil
+}
+
+// GrowMount verifies the filesystem of a mount grew after its disk was
+// expanded. Once it did, the autoextend limit of the datafiles on the mount
+// which are close to it is lifted, so they can use the new space.
+func (s *Server) GrowMount(ctx context.Context, req *dbdpb.GrowMountRequest) (*dbdpb.GrowMountResponse, error) {
+	klog.InfoS("dbdaemon/GrowMount", "req", loggableRequest(req))
+	if !mountPath.MatchString(req.GetMount()) {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: invalid mount %q", req.GetMount())
+	}
+	if req.GetExpectedBytes() <= 0 {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: expected bytes must be positive, got %d", req.GetExpectedBytes())
+	}
+
+	df, err := runDF(ctx, []string{req.GetMount()})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: %v", err)
+	}
+	mounts, err := parseDF(df)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: %v", err)
+	}
+	if len(mounts) != 1 {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: expected 1 filesystem for %s, got %d", req.GetMount(), len(mounts))
+	}
+	resp := &dbdpb.GrowMountResponse{
+		TotalBytes:     mounts[0].GetTotalBytes(),
+		AvailableBytes: mounts[0].GetAvailableBytes(),
+		Grown:          filesystemGrown(mounts[0].GetTotalBytes(), req.GetExpectedBytes()),
+	}
+	if !resp.Grown {
+		klog.InfoS("dbdaemon/GrowMount: filesystem not grown yet", "mount", req.GetMount(), "totalBytes", resp.TotalBytes, "expectedBytes", req.GetExpectedBytes())
+		return resp, nil
+	}
+
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	rows, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf(autoextendDatafilesSQL, req.GetMount())}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: failed to query the datafiles: %v", err)
+	}
+	files, err := parseAutoextendDatafiles(rows.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: %v", err)
+	}
+	statements, err := autoextendStatements(files)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: %v", err)
+	}
+	if len(statements) == 0 {
+		return resp, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GrowMount: failed to raise the autoextend limit: %v", err)
+	}
+	for _, f := range files {
+		resp.AutoextendedDatafiles = append(resp.AutoextendedDatafiles, f.fileName)
+	}
+	return resp, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestFilesystemGrown(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	tests := []struct {
+		name     string
+		total    int64
+		expected int64
+		want     bool
+	}{
+		{name: "not grown", total: 98 * gib, expected: 200 * gib},
+		{name: "grown with ext4 overhead", total: 196 * gib, expected: 200 * gib, want: true},
+		{name: "grown at the overhead limit", total: 190 * gib, expected: 200 * gib, want: true},
+		{name: "grown larger", total: 210 * gib, expected: 200 * gib, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filesystemGrown(tc.total, tc.expected); got != tc.want {
+				t.Errorf("filesystemGrown(%d, %d) = %v, want %v", tc.total, tc.expected, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAutoextendStatements(t *testing.T) {
+	files, err := parseAutoextendDatafiles([]string{
+		`{"CON_NAME":"CDB$ROOT","FILE_ID":"3","FILE_NAME":"/u02/app/oracle/oradata/GCLOUD/sysaux01.dbf"}`,
+		`{"CON_NAME":"PDB1","FILE_ID":"12","FILE_NAME":"/u02/app/oracle/oradata/GCLOUD/PDB1/users01.dbf"}`,
+		`{"CON_NAME":"PDB1","FILE_ID":"13","FILE_NAME":"/u02/app/oracle/oradata/GCLOUD/PDB1/data01.dbf"}`,
+	})
+	if err != nil {
+		t.Fatalf("parseAutoextendDatafiles failed: %v", err)
+	}
+	got, err := autoextendStatements(files)
+	if err != nil {
+		t.Fatalf("autoextendStatements failed: %v", err)
+	}
+	want := []string{
+		`alter session set container="CDB$ROOT"`,
+		"alter database datafile 3 autoextend on maxsize unlimited",
+		`alter session set container="PDB1"`,
+		"alter database datafile 12 autoextend on maxsize unlimited",
+		"alter database datafile 13 autoextend on maxsize unlimited",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("autoextendStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	if _, err := autoextendStatements([]autoextendDatafile{{container: "PDB1", fileID: "12 drop", fileName: "users01.dbf"}}); err == nil {
+		t.Error("autoextendStatements succeeded with an invalid file id, want error")
+	}
+	for _, row := range []string{"not json", `{"CON_NAME":"PDB1","FILE_ID":"12"}`} {
+		if _, err := parseAutoextendDatafiles([]string{row}); err == nil {
+			t.Errorf("parseAutoextendDatafiles(%q) succeeded, want error", row)
+		}
+	}
+}
+
+func TestGrowMountNotGrown(t *testing.T) {
+	oldRunDF := runDF
+	defer func() { runDF = oldRunDF }()
+	runDF = func(ctx context.Context, mounts []string) (string, error) {
+		return "Filesystem     1024-blocks     Used Available Capacity Mounted on\n" +
+			"/dev/sdb         103081248  5242880  97838368       6% /u02\n", nil
+	}
+
+	s := &Server{}
+	got, err := s.GrowMount(context.Background(), &dbdpb.GrowMountRequest{Mount: "/u02", ExpectedBytes: 200 * 1024 * 1024 * 1024})
+	if err != nil {
+		t.Fatalf("GrowMount failed: %v", err)
+	}
+	want := &dbdpb.GrowMountResponse{TotalBytes: 105555197952, AvailableBytes: 100186488832}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GrowMount got unexpected response (-want +got):\n%v", diff)
+	}
+
+	for _, req := range []*dbdpb.GrowMountRequest{
+		{Mount: "/u02'; drop", ExpectedBytes: 1},
+		{Mount: "u02", ExpectedBytes: 1},
+		{Mount: "/u02"},
+	} {
+		if _, err := s.GrowMount(context.Background(), req); err == nil {
+			t.Errorf("GrowMount(%v) succeeded, want error", req)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// userHZ is the unit of the /proc/stat CPU times, USER_HZ is 100 on
+	// all the architectures the database image is built for.
+	userHZ = 100
+	// diskSectorBytes is the unit of the /proc/diskstats sector counters,
+	// which is 512 bytes regardless of the device sector size.
+	diskSectorBytes = 512
+)
+
+var (
+	// procDir, cgroupDir and runDF are overridden in tests.
+	procDir   = "/proc"
+	cgroupDir = "/sys/fs/cgroup"
+	runDF     = func(ctx context.Context, mounts []string) (string, error) {
+		out, err := exec.CommandContext(ctx, "df", append([]string{"-P", "-k"}, mounts...)...).CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("df failed: %v: %s", err, out)
+		}
+		return string(out), nil
+	}
+)
+
+// parseProcStat parses the aggregate and per CPU lines of /proc/stat.
+func parseProcStat(stat string) (*dbdpb.GetHostStatsResponse_CPU, error) {
+	cpu := &dbdpb.GetHostStatsResponse_CPU{}
+	found := false
+	for _, line := range strings.Split(stat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		if fields[0] != "cpu" {
+			cpu.CpuCount++
+			continue
+		}
+		// cpu user nice system idle iowait irq softirq ...
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("failed to parse /proc/stat line %q: expected at least 5 CPU times", line)
+		}
+		var ticks [5]float64
+		for i := range ticks {
+			v, err := strconv.ParseFloat(fields[i+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse /proc/stat line %q: %v", line, err)
+			}
+			ticks[i] = v
+		}
+		cpu.UserSeconds = (ticks[0] + ticks[1]) / userHZ
+		cpu.SystemSeconds = ticks[2] / userHZ
+		cpu.IdleSeconds = ticks[3] / userHZ
+		cpu.IowaitSeconds = ticks[4] / userHZ
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to parse /proc/stat: no aggregate cpu line")
+	}
+	return cpu, nil
+}
+
+// parseLoadAvg fills the load averages from /proc/loadavg.
+func parseLoadAvg(loadAvg string, cpu *dbdpb.GetHostStatsResponse_CPU) error {
+	fields := strings.Fields(loadAvg)
+	if len(fields) < 3 {
+		return fmt.Errorf("failed to parse /proc/loadavg %q: expected 3 load averages", loadAvg)
+	}
+	for i, val := range []*float64{&cpu.Load1, &cpu.Load5, &cpu.Load15} {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse /proc/loadavg %q: %v", loadAvg, err)
+		}
+		*val = v
+	}
+	return nil
+}
+
+// parseMeminfo parses the memory and swap totals of /proc/meminfo.
+func parseMeminfo(meminfo string) (*dbdpb.GetHostStatsResponse_Memory, error) {
+	mem := &dbdpb.GetHostStatsResponse_Memory{}
+	fields := map[string]*int64{
+		"MemTotal":     &mem.TotalBytes,
+		"MemAvailable": &mem.AvailableBytes,
+		"SwapTotal":    &mem.SwapTotalBytes,
+		"SwapFree":     &mem.SwapFreeBytes,
+	}
+	found := 0
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		// MemTotal:       16384000 kB
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		val, wanted := fields[name]
+		if !ok || !wanted {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse /proc/meminfo line %q: %v", scanner.Text(), err)
+		}
+		*val = kb * 1024
+		found++
+	}
+	if found != len(fields) {
+		return nil, fmt.Errorf("failed to parse /proc/meminfo: found %d of the %d expected fields", found, len(fields))
+	}
+	return mem, nil
+}
+
+// parseDF parses the POSIX output of df -P -k.
+func parseDF(out string) ([]*dbdpb.GetHostStatsResponse_Mount, error) {
+	var mounts []*dbdpb.GetHostStatsResponse_Mount
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	// The first line is the header.
+	for _, line := range lines[1:] {
+		// Filesystem 1024-blocks Used Available Capacity Mounted on
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("failed to parse df line %q: expected 6 fields", line)
+		}
+		var kb [3]int64
+		for i := range kb {
+			v, err := strconv.ParseInt(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse df line %q: %v", line, err)
+			}
+			kb[i] = v
+		}
+		mounts = append(mounts, &dbdpb.GetHostStatsResponse_Mount{
+			Path:           strings.Join(fields[5:], " "),
+			Device:         fields[0],
+			TotalBytes:     kb[0] * 1024,
+			UsedBytes:      kb[1] * 1024,
+			AvailableBytes: kb[2] * 1024,
+		})
+	}
+	return mounts, nil
+}
+
+// parseDiskstats returns the I/O counters of the /proc/diskstats devices
+// backing the mounts.
+func parseDiskstats(diskstats string, mounts []*dbdpb.GetHostStatsResponse_Mount) ([]*dbdpb.GetHostStatsResponse_Disk, error) {
+	devices := make(map[string]bool)
+	for _, m := range mounts {
+		if strings.HasPrefix(m.GetDevice(), "/dev/") {
+			devices[filepath.Base(m.GetDevice())] = true
+		}
+	}
+	var disks []*dbdpb.GetHostStatsResponse_Disk
+	for _, line := range strings.Split(diskstats, "\n") {
+		// major minor name reads merged sectors ms writes merged sectors ms in_flight io_ms ...
+		fields := strings.Fields(line)
+		if len(fields) < 13 || !devices[fields[2]] {
+			continue
+		}
+		var counters [11]int64
+		for i := range counters {
+			v, err := strconv.ParseInt(fields[i+3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse /proc/diskstats line %q: %v", line, err)
+			}
+			counters[i] = v
+		}
+		disks = append(disks, &dbdpb.GetHostStatsResponse_Disk{
+			Device:          fields[2],
+			ReadsCompleted:  counters[0],
+			ReadBytes:       counters[2] * diskSectorBytes,
+			WritesCompleted: counters[4],
+			WrittenBytes:    counters[6] * diskSectorBytes,
+			IoSeconds:       float64(counters[9]) / 1000,
+		})
+	}
+	return disks, nil
+}
+
+// parseCPUQuota returns the CPU limit in cores of a CFS quota and period, the
+// quota is "max" in cgroup v2 and -1 in cgroup v1 if there is no limit.
+func parseCPUQuota(quota, period string) (float64, error) {
+	quota, period = strings.TrimSpace(quota), strings.TrimSpace(period)
+	if quota == "max" || quota == "-1" {
+		return 0, nil
+	}
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse CPU quota %q: %v", quota, err)
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("failed to parse CPU period %q", period)
+	}
+	return q / p, nil
+}
+
+// cgroupCPULimit returns the CPU limit in cores of the database container,
+// read from cpu.max in cgroup v2 or the CFS quota in cgroup v1. It returns
+// zero if the container has no CPU limit.
+func cgroupCPULimit() (float64, error) {
+	b, err := os.ReadFile(filepath.Join(cgroupDir, "cpu.max"))
+	if err == nil {
+		// cpu.max is "<quota> <period>".
+		fields := strings.Fields(string(b))
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("failed to parse cpu.max %q", b)
+		}
+		return parseCPUQuota(fields[0], fields[1])
+	}
+	if !os.IsNotExist(err) {
+		return 0, err
+	}
+	quota, err := os.ReadFile(filepath.Join(cgroupDir, "cpu", "cpu.cfs_quota_us"))
+	if os.IsNotExist(err) {
+		// Neither cgroup version has a CPU controller mounted.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	period, err := os.ReadFile(filepath.Join(cgroupDir, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, err
+	}
+	return parseCPUQuota(string(quota), string(period))
+}
+
+func readProcFile(name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(procDir, name))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// GetHostStats returns the CPU, memory, swap and disk I/O stats of the
+// database container and the usage of its mounts.
+func (s *Server) GetHostStats(ctx context.Context, req *dbdpb.GetHostStatsRequest) (*dbdpb.GetHostStatsResponse, error) {
+	klog.InfoS("dbdaemon/GetHostStats", "req", loggableRequest(req))
+
+	mounts := req.GetMounts()
+	if len(mounts) == 0 {
+		mounts = []string{"/" + consts.DataMount, "/" + consts.LogMount}
+	}
+
+	files := make(map[string]string)
+	for _, name := range []string{"stat", "loadavg", "meminfo", "diskstats"} {
+		content, err := readProcFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+		}
+		files[name] = content
+	}
+
+	resp := &dbdpb.GetHostStatsResponse{}
+	var err error
+	if resp.Cpu, err = parseProcStat(files["stat"]); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	if err := parseLoadAvg(files["loadavg"], resp.Cpu); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	if resp.Cpu.LimitCores, err = cgroupCPULimit(); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	if resp.Memory, err = parseMeminfo(files["meminfo"]); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	df, err := runDF(ctx, mounts)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	if resp.Mounts, err = parseDF(df); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	if resp.Disks, err = parseDiskstats(files["diskstats"], resp.Mounts); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetHostStats: %v", err)
+	}
+	return resp, nil
+}
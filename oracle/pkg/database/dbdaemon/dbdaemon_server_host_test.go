@@ -0,0 +1,230 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	procStat = `cpu  10000 500 3000 80000 1200 0 100 0 0 0
+cpu0 5000 250 1500 40000 600 0 50 0 0 0
+cpu1 5000 250 1500 40000 600 0 50 0 0 0
+intr 123456 0 0
+ctxt 987654
+`
+	procLoadAvg = "0.52 0.58 0.59 2/456 7890\n"
+	procMeminfo = `MemTotal:       16384000 kB
+MemFree:         1024000 kB
+MemAvailable:    8192000 kB
+Buffers:          512000 kB
+SwapTotal:       2048000 kB
+SwapFree:        1024000 kB
+`
+	procDiskstats = `   8       0 sda 5000 10 80000 300 6000 20 90000 400 0 700 900
+   8      16 sdb 1200 5 96000 250 3400 12 204800 800 0 1500 1050
+   8      32 sdc 400 0 8000 50 9000 30 409600 1200 0 2500 1250
+   7       0 loop0 0 0 0 0 0 0 0 0 0 0 0
+`
+	dfOutput = `Filesystem     1024-blocks     Used Available Capacity Mounted on
+/dev/sdb         103081248  5242880  97838368       6% /u02
+/dev/sdc         154687468 10485760 144201708       7% /u03
+`
+)
+
+func TestGetHostStats(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"stat":      procStat,
+		"loadavg":   procLoadAvg,
+		"meminfo":   procMeminfo,
+		"diskstats": procDiskstats,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cgroup := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("150000 100000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldProcDir, oldCgroupDir, oldRunDF := procDir, cgroupDir, runDF
+	defer func() { procDir, cgroupDir, runDF = oldProcDir, oldCgroupDir, oldRunDF }()
+	procDir = dir
+	cgroupDir = cgroup
+	var gotMounts []string
+	runDF = func(ctx context.Context, mounts []string) (string, error) {
+		gotMounts = mounts
+		return dfOutput, nil
+	}
+
+	s := &Server{}
+	got, err := s.GetHostStats(context.Background(), &dbdpb.GetHostStatsRequest{})
+	if err != nil {
+		t.Fatalf("GetHostStats failed: %v", err)
+	}
+	want := &dbdpb.GetHostStatsResponse{
+		Cpu: &dbdpb.GetHostStatsResponse_CPU{
+			UserSeconds:   105,
+			SystemSeconds: 30,
+			IdleSeconds:   800,
+			IowaitSeconds: 12,
+			CpuCount:      2,
+			Load1:         0.52,
+			Load5:         0.58,
+			Load15:        0.59,
+			LimitCores:    1.5,
+		},
+		Memory: &dbdpb.GetHostStatsResponse_Memory{
+			TotalBytes:     16777216000,
+			AvailableBytes: 8388608000,
+			SwapTotalBytes: 2097152000,
+			SwapFreeBytes:  1048576000,
+		},
+		Mounts: []*dbdpb.GetHostStatsResponse_Mount{
+			{Path: "/u02", Device: "/dev/sdb", TotalBytes: 105555197952, UsedBytes: 5368709120, AvailableBytes: 100186488832},
+			{Path: "/u03", Device: "/dev/sdc", TotalBytes: 158399967232, UsedBytes: 10737418240, AvailableBytes: 147662548992},
+		},
+		Disks: []*dbdpb.GetHostStatsResponse_Disk{
+			{Device: "sdb", ReadsCompleted: 1200, ReadBytes: 49152000, WritesCompleted: 3400, WrittenBytes: 104857600, IoSeconds: 1.5},
+			{Device: "sdc", ReadsCompleted: 400, ReadBytes: 4096000, WritesCompleted: 9000, WrittenBytes: 209715200, IoSeconds: 2.5},
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetHostStats got unexpected response (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"/u02", "/u03"}, gotMounts); diff != "" {
+		t.Errorf("GetHostStats got unexpected df mounts (-want +got):\n%v", diff)
+	}
+
+	runDF = func(ctx context.Context, mounts []string) (string, error) {
+		return "", errors.New("df: /u04: No such file or directory")
+	}
+	if _, err := s.GetHostStats(context.Background(), &dbdpb.GetHostStatsRequest{Mounts: []string{"/u04"}}); err == nil {
+		t.Error("GetHostStats succeeded with a failing df, want error")
+	}
+}
+
+func TestCgroupCPULimit(t *testing.T) {
+	oldCgroupDir := cgroupDir
+	defer func() { cgroupDir = oldCgroupDir }()
+
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  float64
+	}{
+		{
+			name:  "cgroup v2 limit",
+			files: map[string]string{"cpu.max": "400000 100000\n"},
+			want:  4,
+		},
+		{
+			name:  "cgroup v2 unlimited",
+			files: map[string]string{"cpu.max": "max 100000\n"},
+			want:  0,
+		},
+		{
+			name: "cgroup v1 limit",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "250000\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+			want: 2.5,
+		},
+		{
+			name: "cgroup v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":  "-1\n",
+				"cpu/cpu.cfs_period_us": "100000\n",
+			},
+			want: 0,
+		},
+		{
+			name: "no CPU controller",
+			want: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cgroupDir = t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(cgroupDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := cgroupCPULimit()
+			if err != nil {
+				t.Fatalf("cgroupCPULimit failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("cgroupCPULimit got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseHostStatsErrors(t *testing.T) {
+	for _, stat := range []string{
+		"",
+		"cpu0 5000 250 1500 40000 600 0 50 0 0 0",
+		"cpu  10000 500 3000",
+		"cpu  10000 500 x 80000 1200 0 100 0 0 0",
+	} {
+		if _, err := parseProcStat(stat); err == nil {
+			t.Errorf("parseProcStat(%q) succeeded, want error", stat)
+		}
+	}
+	for _, loadAvg := range []string{"", "0.52 0.58", "0.52 x 0.59 2/456 7890"} {
+		if err := parseLoadAvg(loadAvg, &dbdpb.GetHostStatsResponse_CPU{}); err == nil {
+			t.Errorf("parseLoadAvg(%q) succeeded, want error", loadAvg)
+		}
+	}
+	for _, meminfo := range []string{
+		"",
+		"MemTotal:       16384000 kB\nMemAvailable:    8192000 kB\n",
+		"MemTotal:       16G\nMemAvailable:    8192000 kB\nSwapTotal:       0 kB\nSwapFree:        0 kB\n",
+	} {
+		if _, err := parseMeminfo(meminfo); err == nil {
+			t.Errorf("parseMeminfo(%q) succeeded, want error", meminfo)
+		}
+	}
+	for _, quota := range [][2]string{{"x", "100000"}, {"100000", "0"}, {"100000", "x"}} {
+		if _, err := parseCPUQuota(quota[0], quota[1]); err == nil {
+			t.Errorf("parseCPUQuota(%q, %q) succeeded, want error", quota[0], quota[1])
+		}
+	}
+	for _, df := range []string{
+		"Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/sdb 103081248 5242880",
+		"Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/sdb 100G 5242880 97838368 6% /u02",
+	} {
+		if _, err := parseDF(df); err == nil {
+			t.Errorf("parseDF(%q) succeeded, want error", df)
+		}
+	}
+}
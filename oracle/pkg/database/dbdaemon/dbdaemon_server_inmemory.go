@@ -0,0 +1,271 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// inMemoryOptionSQL reports whether the edition of the database comes
+	// with the In-Memory option, which requires Enterprise Edition.
+	inMemoryOptionSQL = "select value from v$option where parameter='In-Memory Column Store'"
+
+	inMemoryParametersSQL = "select name, value from v$parameter where name in ('inmemory_size', 'sga_target', 'sga_max_size')"
+
+	inMemorySegmentsSQL = "select owner, segment_name, populate_status, inmemory_size, bytes_not_populated " +
+		"from v$im_segments order by owner, segment_name"
+
+	// minInMemorySize is the smallest inmemory_size Oracle accepts.
+	minInMemorySize = 100 * 1024 * 1024
+)
+
+// validateInMemorySize checks the In-Memory column store of size fits in the
+// SGA described by the inMemoryParametersSQL values.
+func validateInMemorySize(size int64, params map[string]int64) error {
+	if size == 0 {
+		return nil
+	}
+	if size < minInMemorySize {
+		return fmt.Errorf("inmemory_size %d is below the minimum of %d bytes", size, minInMemorySize)
+	}
+	sga := params["sga_target"]
+	if sga == 0 {
+		sga = params["sga_max_size"]
+	}
+	if size >= sga {
+		return fmt.Errorf("inmemory_size %d doesn't fit in the SGA of %d bytes", size, sga)
+	}
+	return nil
+}
+
+// escapeTable escapes a table given as OWNER.TABLE.
+func escapeTable(table string) (string, error) {
+	parts := strings.Split(table, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("table %q is not of the form OWNER.TABLE", table)
+	}
+	owner, err := sqlq.ObjectName(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid owner of table %q: %v", table, err)
+	}
+	name, err := sqlq.ObjectName(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid name of table %q: %v", table, err)
+	}
+	return owner + "." + name, nil
+}
+
+// inMemoryStatements returns the statements setting the INMEMORY attribute of
+// the tablespaces and tables of the request in its PDB.
+func inMemoryStatements(req *dbdpb.ConfigureInMemoryRequest) ([]string, error) {
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("invalid PDB name %q", req.GetPdbName())
+	}
+	var statements []string
+	for _, ts := range []struct {
+		names     []string
+		attribute string
+	}{
+		{names: req.GetInmemoryTablespaces(), attribute: "inmemory"},
+		{names: req.GetNoInmemoryTablespaces(), attribute: "no inmemory"},
+	} {
+		for _, n := range ts.names {
+			name, err := sqlq.ObjectName(n)
+			if err != nil || n == "" {
+				return nil, fmt.Errorf("invalid tablespace name %q", n)
+			}
+			statements = append(statements, fmt.Sprintf("alter tablespace %s default %s", name, ts.attribute))
+		}
+	}
+	for _, tables := range []struct {
+		names     []string
+		attribute string
+	}{
+		{names: req.GetInmemoryTables(), attribute: "inmemory"},
+		{names: req.GetNoInmemoryTables(), attribute: "no inmemory"},
+	} {
+		for _, t := range tables.names {
+			name, err := escapeTable(t)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, fmt.Sprintf("alter table %s %s", name, tables.attribute))
+		}
+	}
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	return append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...), nil
+}
+
+// optionEnabled tells whether the v$option rows of inMemoryOptionSQL or
+// partitioningOptionSQL report the option as available.
+func optionEnabled(rows []string) bool {
+	if len(rows) != 1 {
+		return false
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return false
+	}
+	return row["VALUE"] == "TRUE"
+}
+
+// parseInMemoryParameters converts inMemoryParametersSQL rows into a map of
+// parameter values.
+func parseInMemoryParameters(rows []string) (map[string]int64, error) {
+	params := make(map[string]int64)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse parameter row %q: %v", msg, err)
+		}
+		v, err := strconv.ParseInt(row["VALUE"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the value of %s in parameter row %q: %v", row["NAME"], msg, err)
+		}
+		params[row["NAME"]] = v
+	}
+	return params, nil
+}
+
+// parseInMemorySegments converts inMemorySegmentsSQL rows into the response
+// representation.
+func parseInMemorySegments(rows []string) ([]*dbdpb.GetInMemoryStatusResponse_Segment, error) {
+	var segments []*dbdpb.GetInMemoryStatusResponse_Segment
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse In-Memory segment row %q: %v", msg, err)
+		}
+		size, err := strconv.ParseInt(row["INMEMORY_SIZE"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse INMEMORY_SIZE in In-Memory segment row %q: %v", msg, err)
+		}
+		notPopulated, err := strconv.ParseInt(row["BYTES_NOT_POPULATED"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BYTES_NOT_POPULATED in In-Memory segment row %q: %v", msg, err)
+		}
+		segments = append(segments, &dbdpb.GetInMemoryStatusResponse_Segment{
+			Owner:             row["OWNER"],
+			SegmentName:       row["SEGMENT_NAME"],
+			PopulateStatus:    row["POPULATE_STATUS"],
+			InmemorySizeBytes: size,
+			BytesNotPopulated: notPopulated,
+		})
+	}
+	return segments, nil
+}
+
+func (s *Server) inMemoryParameters(ctx context.Context) (map[string]int64, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{inMemoryParametersSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the SGA parameters: %v", err)
+	}
+	return parseInMemoryParameters(resp.GetMsg())
+}
+
+// ConfigureInMemory sizes the In-Memory column store and sets the INMEMORY
+// attribute of the tablespaces and tables of the request. inmemory_size is
+// set in the spfile, the new size takes effect once the database restarts.
+func (s *Server) ConfigureInMemory(ctx context.Context, req *dbdpb.ConfigureInMemoryRequest) (*dbdpb.ConfigureInMemoryResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureInMemory", "req", loggableRequest(req))
+	statements, err := inMemoryStatements(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: %v", err)
+	}
+	if (req.GetResize() && req.GetSizeBytes() > 0) || len(req.GetInmemoryTablespaces()) > 0 || len(req.GetInmemoryTables()) > 0 {
+		if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: %v", err)
+		}
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp := &dbdpb.ConfigureInMemoryResponse{}
+	if req.GetResize() {
+		if req.GetSizeBytes() > 0 {
+			option, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{inMemoryOptionSQL}}, true)
+			if err != nil {
+				return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: failed to query the In-Memory option: %v", err)
+			}
+			if !optionEnabled(option.GetMsg()) {
+				return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: the In-Memory option isn't available, it requires Enterprise Edition")
+			}
+		}
+		params, err := s.inMemoryParameters(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: %v", err)
+		}
+		if err := validateInMemorySize(req.GetSizeBytes(), params); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: %v", err)
+		}
+		if params["inmemory_size"] != req.GetSizeBytes() {
+			if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+				Commands: []string{fmt.Sprintf("alter system set inmemory_size=%d scope=spfile", req.GetSizeBytes())},
+			}, false); err != nil {
+				return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: failed to set inmemory_size: %v", err)
+			}
+			resp.RestartRequired = true
+		}
+	}
+	if len(statements) > 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureInMemory: failed to set the INMEMORY attributes: %v", err)
+		}
+	}
+	return resp, nil
+}
+
+// GetInMemoryStatus reports the inmemory_size in effect and the population
+// of the In-Memory segments of the PDB from v$im_segments.
+func (s *Server) GetInMemoryStatus(ctx context.Context, req *dbdpb.GetInMemoryStatusRequest) (*dbdpb.GetInMemoryStatusResponse, error) {
+	klog.InfoS("dbdaemon/GetInMemoryStatus", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/GetInMemoryStatus: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	params, err := s.inMemoryParameters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInMemoryStatus: %v", err)
+	}
+	segResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), inMemorySegmentsSQL},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInMemoryStatus: failed to query the In-Memory segments: %v", err)
+	}
+	segments, err := parseInMemorySegments(segResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInMemoryStatus: %v", err)
+	}
+	return &dbdpb.GetInMemoryStatusResponse{SizeBytes: params["inmemory_size"], Segments: segments}, nil
+}
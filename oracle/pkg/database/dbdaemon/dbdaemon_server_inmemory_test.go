@@ -0,0 +1,139 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestInMemoryStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *dbdpb.ConfigureInMemoryRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "mark and unmark",
+			req: &dbdpb.ConfigureInMemoryRequest{
+				PdbName:               "pdb1",
+				InmemoryTablespaces:   []string{"sales_ts"},
+				InmemoryTables:        []string{"scott.emp", "Scott.Dept"},
+				NoInmemoryTablespaces: []string{"users"},
+				NoInmemoryTables:      []string{"scott.bonus"},
+			},
+			want: []string{
+				`alter session set container="PDB1"`,
+				`alter tablespace "SALES_TS" default inmemory`,
+				`alter tablespace "USERS" default no inmemory`,
+				`alter table "SCOTT"."EMP" inmemory`,
+				`alter table "SCOTT"."DEPT" inmemory`,
+				`alter table "SCOTT"."BONUS" no inmemory`,
+			},
+		},
+		{
+			name: "size only",
+			req:  &dbdpb.ConfigureInMemoryRequest{PdbName: "pdb1", Resize: true, SizeBytes: 1 << 30},
+		},
+		{
+			name:    "table without owner",
+			req:     &dbdpb.ConfigureInMemoryRequest{PdbName: "pdb1", InmemoryTables: []string{"emp"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid tablespace",
+			req:     &dbdpb.ConfigureInMemoryRequest{PdbName: "pdb1", InmemoryTablespaces: []string{`ts" default inmemory; drop tablespace "users`}},
+			wantErr: true,
+		},
+		{
+			name:    "missing PDB",
+			req:     &dbdpb.ConfigureInMemoryRequest{InmemoryTablespaces: []string{"users"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := inMemoryStatements(tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("inMemoryStatements(%v) got error %v, want error: %v", tc.req, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("inMemoryStatements(%v) got unexpected statements (-want +got):\n%v", tc.req, diff)
+			}
+		})
+	}
+}
+
+func TestValidateInMemorySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		params  map[string]int64
+		wantErr bool
+	}{
+		{name: "disable", size: 0, params: map[string]int64{"sga_target": 1 << 30}},
+		{name: "fits", size: 512 << 20, params: map[string]int64{"sga_target": 2 << 30}},
+		{name: "manual SGA", size: 512 << 20, params: map[string]int64{"sga_max_size": 2 << 30}},
+		{name: "too small", size: 50 << 20, params: map[string]int64{"sga_target": 2 << 30}, wantErr: true},
+		{name: "larger than SGA", size: 2 << 30, params: map[string]int64{"sga_target": 2 << 30}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateInMemorySize(tc.size, tc.params); (err != nil) != tc.wantErr {
+				t.Errorf("validateInMemorySize(%d, %v) got error %v, want error: %v", tc.size, tc.params, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseInMemoryStatus(t *testing.T) {
+	params, err := parseInMemoryParameters([]string{
+		`{"NAME": "inmemory_size", "VALUE": "1073741824"}`,
+		`{"NAME": "sga_target", "VALUE": "4294967296"}`,
+	})
+	if err != nil {
+		t.Fatalf("parseInMemoryParameters failed: %v", err)
+	}
+	if diff := cmp.Diff(map[string]int64{"inmemory_size": 1 << 30, "sga_target": 4 << 30}, params); diff != "" {
+		t.Errorf("parseInMemoryParameters got unexpected parameters (-want +got):\n%v", diff)
+	}
+
+	segments, err := parseInMemorySegments([]string{
+		`{"OWNER": "SCOTT", "SEGMENT_NAME": "EMP", "POPULATE_STATUS": "COMPLETED", "INMEMORY_SIZE": "1179648", "BYTES_NOT_POPULATED": "0"}`,
+		`{"OWNER": "SCOTT", "SEGMENT_NAME": "SALES", "POPULATE_STATUS": "STARTED", "INMEMORY_SIZE": "4194304", "BYTES_NOT_POPULATED": "8388608"}`,
+	})
+	if err != nil {
+		t.Fatalf("parseInMemorySegments failed: %v", err)
+	}
+	want := []*dbdpb.GetInMemoryStatusResponse_Segment{
+		{Owner: "SCOTT", SegmentName: "EMP", PopulateStatus: "COMPLETED", InmemorySizeBytes: 1179648},
+		{Owner: "SCOTT", SegmentName: "SALES", PopulateStatus: "STARTED", InmemorySizeBytes: 4194304, BytesNotPopulated: 8388608},
+	}
+	if diff := cmp.Diff(want, segments, protocmp.Transform()); diff != "" {
+		t.Errorf("parseInMemorySegments got unexpected segments (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseInMemorySegments([]string{`{"OWNER": "SCOTT", "SEGMENT_NAME": "EMP", "INMEMORY_SIZE": "n/a", "BYTES_NOT_POPULATED": "0"}`}); err == nil {
+		t.Errorf("parseInMemorySegments with an invalid size succeeded, want error")
+	}
+	if enabled := optionEnabled([]string{`{"VALUE": "FALSE"}`}); enabled {
+		t.Errorf("optionEnabled(FALSE) = true, want false")
+	}
+}
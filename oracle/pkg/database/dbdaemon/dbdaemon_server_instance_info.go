@@ -0,0 +1,88 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// instanceInfoSQL reports the startup time with the UTC offset of the
+// database host, startup_time itself is a DATE in the host time zone.
+const instanceInfoSQL = "select to_char(i.startup_time, 'YYYY-MM-DD\"T\"HH24:MI:SS') || to_char(systimestamp, 'TZH:TZM') as startup_time, " +
+	"round((sysdate - i.startup_time) * 86400) as uptime_seconds, i.version, " +
+	"d.open_mode, d.database_role, d.protection_mode, d.dataguard_broker, " +
+	"(select incarnation# from v$database_incarnation where status = 'CURRENT') as incarnation " +
+	"from v$instance i, v$database d"
+
+// parseInstanceInfo returns the instance info of the instanceInfoSQL row.
+func parseInstanceInfo(rows []string) (*dbdpb.GetInstanceInfoResponse, error) {
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("expected 1 instance row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse instance row %q: %v", rows[0], err)
+	}
+	startup, err := time.Parse(time.RFC3339, row["STARTUP_TIME"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse STARTUP_TIME in instance row %q: %v", rows[0], err)
+	}
+	uptime, err := strconv.ParseInt(row["UPTIME_SECONDS"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UPTIME_SECONDS in instance row %q: %v", rows[0], err)
+	}
+	resp := &dbdpb.GetInstanceInfoResponse{
+		StartupTime:   timestamppb.New(startup),
+		UptimeSeconds: uptime,
+		Incarnation:   row["INCARNATION"],
+		OpenMode:      row["OPEN_MODE"],
+		DatabaseRole:  row["DATABASE_ROLE"],
+		Version:       row["VERSION"],
+	}
+	// v$database reports MAXIMUM PERFORMANCE even without a standby.
+	if row["DATAGUARD_BROKER"] == "ENABLED" || row["DATABASE_ROLE"] != "PRIMARY" {
+		resp.ProtectionMode = row["PROTECTION_MODE"]
+	}
+	return resp, nil
+}
+
+// GetInstanceInfo returns the startup time, the uptime and the state of the
+// database instance.
+func (s *Server) GetInstanceInfo(ctx context.Context, req *dbdpb.GetInstanceInfoRequest) (*dbdpb.GetInstanceInfoResponse, error) {
+	klog.InfoS("dbdaemon/GetInstanceInfo", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{instanceInfoSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInstanceInfo: failed to query the instance: %v", err)
+	}
+	info, err := parseInstanceInfo(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInstanceInfo: %v", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseInstanceInfo(t *testing.T) {
+	startup := time.Date(2022, 5, 2, 10, 11, 12, 0, time.UTC)
+	tests := []struct {
+		name string
+		row  string
+		want *dbdpb.GetInstanceInfoResponse
+	}{
+		{
+			name: "primary without Data Guard",
+			row:  `{"STARTUP_TIME":"2022-05-02T12:11:12+02:00","UPTIME_SECONDS":"86400","VERSION":"19.0.0.0.0","OPEN_MODE":"READ WRITE","DATABASE_ROLE":"PRIMARY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","DATAGUARD_BROKER":"DISABLED","INCARNATION":"2"}`,
+			want: &dbdpb.GetInstanceInfoResponse{
+				StartupTime:   timestamppb.New(startup),
+				UptimeSeconds: 86400,
+				Incarnation:   "2",
+				OpenMode:      "READ WRITE",
+				DatabaseRole:  "PRIMARY",
+				Version:       "19.0.0.0.0",
+			},
+		},
+		{
+			name: "primary with Data Guard",
+			row:  `{"STARTUP_TIME":"2022-05-02T10:11:12+00:00","UPTIME_SECONDS":"60","VERSION":"19.0.0.0.0","OPEN_MODE":"READ WRITE","DATABASE_ROLE":"PRIMARY","PROTECTION_MODE":"MAXIMUM AVAILABILITY","DATAGUARD_BROKER":"ENABLED","INCARNATION":"1"}`,
+			want: &dbdpb.GetInstanceInfoResponse{
+				StartupTime:    timestamppb.New(startup),
+				UptimeSeconds:  60,
+				Incarnation:    "1",
+				OpenMode:       "READ WRITE",
+				DatabaseRole:   "PRIMARY",
+				ProtectionMode: "MAXIMUM AVAILABILITY",
+				Version:        "19.0.0.0.0",
+			},
+		},
+		{
+			name: "physical standby",
+			row:  `{"STARTUP_TIME":"2022-05-02T03:11:12-07:00","UPTIME_SECONDS":"3600","VERSION":"12.2.0.1.0","OPEN_MODE":"MOUNTED","DATABASE_ROLE":"PHYSICAL STANDBY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","DATAGUARD_BROKER":"DISABLED","INCARNATION":"3"}`,
+			want: &dbdpb.GetInstanceInfoResponse{
+				StartupTime:    timestamppb.New(startup),
+				UptimeSeconds:  3600,
+				Incarnation:    "3",
+				OpenMode:       "MOUNTED",
+				DatabaseRole:   "PHYSICAL STANDBY",
+				ProtectionMode: "MAXIMUM PERFORMANCE",
+				Version:        "12.2.0.1.0",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseInstanceInfo([]string{tc.row})
+			if err != nil {
+				t.Fatalf("parseInstanceInfo failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("parseInstanceInfo got unexpected response (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	for _, rows := range [][]string{
+		nil,
+		{"not json"},
+		{`{"STARTUP_TIME":"02-MAY-22","UPTIME_SECONDS":"60"}`},
+		{`{"STARTUP_TIME":"2022-05-02T10:11:12+00:00","UPTIME_SECONDS":""}`},
+	} {
+		if _, err := parseInstanceInfo(rows); err == nil {
+			t.Errorf("parseInstanceInfo(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+func TestGetInstanceInfo(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 1 || sqls[0] != instanceInfoSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{`{"STARTUP_TIME":"2022-05-02T10:11:12+00:00","UPTIME_SECONDS":"60","VERSION":"19.0.0.0.0","OPEN_MODE":"READ WRITE","DATABASE_ROLE":"PRIMARY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","DATAGUARD_BROKER":"DISABLED","INCARNATION":"1"}`}, nil
+	}
+
+	resp, err := s.GetInstanceInfo(ctx, &dbdpb.GetInstanceInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetInstanceInfo failed: %v", err)
+	}
+	if resp.GetUptimeSeconds() != 60 || resp.GetOpenMode() != "READ WRITE" || resp.GetIncarnation() != "1" {
+		t.Errorf("GetInstanceInfo = %v, want uptime 60, open mode READ WRITE and incarnation 1", resp)
+	}
+}
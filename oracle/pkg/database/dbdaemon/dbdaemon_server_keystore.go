@@ -0,0 +1,278 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// keystoreParametersSQL returns the parameters locating the keystore,
+	// wallet_root is only supported from Oracle 18c.
+	keystoreParametersSQL = "select name, value from v$parameter where name in ('wallet_root', 'tde_configuration')"
+
+	// keystoreContainersSQL reports the keystore of each open container,
+	// the CDB root, or a non-CDB, first.
+	keystoreContainersSQL = "select c.name, w.wrl_parameter, w.wallet_type, w.status " +
+		"from v$encryption_wallet w join v$containers c on c.con_id = w.con_id " +
+		"where c.open_mode = 'READ WRITE' order by w.con_id"
+
+	// keystoreConfiguration is the tde_configuration of a software keystore.
+	keystoreConfiguration = "KEYSTORE_CONFIGURATION=FILE"
+
+	// masterKeyBackupTag identifies the keystore backups setting a master
+	// key leaves in the keystore directory.
+	masterKeyBackupTag = "set_master_key"
+
+	keystoreNotAvailable = "NOT_AVAILABLE"
+	keystoreClosed       = "CLOSED"
+	keystoreNoMasterKey  = "OPEN_NO_MASTER_KEY"
+)
+
+// tdeWalletRoot is the wallet_root set for databases without one, tests
+// replace it.
+var tdeWalletRoot = consts.TDEWalletRoot
+
+// containerKeystore is the keystore of a container as reported by
+// keystoreContainersSQL.
+type containerKeystore struct {
+	container string
+	keystore
+}
+
+// parseKeystoreContainers converts the keystoreContainersSQL rows into the
+// keystores of the containers.
+func parseKeystoreContainers(rows []string) ([]containerKeystore, error) {
+	var containers []containerKeystore
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse keystore row %q: %v", msg, err)
+		}
+		containers = append(containers, containerKeystore{container: row["NAME"], keystore: keystoreFromRow(row)})
+	}
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("got no keystore rows")
+	}
+	return containers, nil
+}
+
+// keystorePassword validates and quotes a keystore password.
+func keystorePassword(password string) (string, error) {
+	quoted, err := sqlq.Identifier(password)
+	if err != nil || password == "" {
+		return "", fmt.Errorf("invalid keystore password")
+	}
+	return quoted, nil
+}
+
+// queryKeystoreContainers returns the keystores of the open containers.
+func (s *Server) queryKeystoreContainers(ctx context.Context) ([]containerKeystore, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{keystoreContainersSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the keystore: %v", err)
+	}
+	return parseKeystoreContainers(resp.GetMsg())
+}
+
+// queryKeystoreParameters returns the values of the keystoreParametersSQL
+// parameters by name.
+func (s *Server) queryKeystoreParameters(ctx context.Context) (map[string]string, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{keystoreParametersSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the keystore parameters: %v", err)
+	}
+	params := make(map[string]string)
+	for _, msg := range resp.GetMsg() {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse parameter row %q: %v", msg, err)
+		}
+		params[row["NAME"]] = row["VALUE"]
+	}
+	return params, nil
+}
+
+// ConfigureKeystore creates the TDE keystore of the database if it has
+// none, opens it in all the open containers and creates its auto-login
+// keystore if requested. The keystore is created in the tde subdirectory of
+// wallet_root. A database without wallet_root gets it in the spfile, the
+// call has to be repeated once the database restarts.
+func (s *Server) ConfigureKeystore(ctx context.Context, req *dbdpb.ConfigureKeystoreRequest) (*dbdpb.ConfigureKeystoreResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureKeystore", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	password, err := keystorePassword(req.GetKeystorePassword())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+	}
+	run := func(cmds ...string) error {
+		_, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds}, false)
+		return err
+	}
+
+	params, err := s.queryKeystoreParameters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+	}
+	walletRoot, ok := params["wallet_root"]
+	if !ok {
+		return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: the database doesn't support wallet_root, which requires Oracle 18c or later")
+	}
+	if walletRoot == "" {
+		if err := s.mkdirAllOracle(filepath.Join(tdeWalletRoot, "tde"), 0700); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to create the keystore dir: %v", err)
+		}
+		if err := run(fmt.Sprintf("alter system set wallet_root='%s' scope=spfile", sqlq.StringParam(tdeWalletRoot))); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to set wallet_root: %v", err)
+		}
+		klog.InfoS("dbdaemon/ConfigureKeystore: set wallet_root, the keystore is created once the database restarts", "walletRoot", tdeWalletRoot)
+		return &dbdpb.ConfigureKeystoreResponse{Status: keystoreNotAvailable, RestartRequired: true}, nil
+	}
+	if !strings.Contains(strings.ToUpper(params["tde_configuration"]), keystoreConfiguration) {
+		if err := run(fmt.Sprintf("alter system set tde_configuration='%s' scope=both", keystoreConfiguration)); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to set tde_configuration: %v", err)
+		}
+	}
+
+	containers, err := s.queryKeystoreContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+	}
+	resp := &dbdpb.ConfigureKeystoreResponse{}
+	if containers[0].status == keystoreNotAvailable {
+		if err := s.mkdirAllOracle(filepath.Join(walletRoot, "tde"), 0700); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to create the keystore dir: %v", err)
+		}
+		if err := run("administer key management create keystore identified by " + password); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to create the keystore: %v", err)
+		}
+		resp.Created = true
+		klog.InfoS("dbdaemon/ConfigureKeystore: created the keystore", "walletRoot", walletRoot)
+		if containers, err = s.queryKeystoreContainers(ctx); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+		}
+	}
+	if containers[0].status == keystoreClosed {
+		if err := run(fmt.Sprintf("administer key management set keystore open identified by %s container=all", password)); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to open the keystore: %v", err)
+		}
+		resp.Opened = true
+		if containers, err = s.queryKeystoreContainers(ctx); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+		}
+	}
+	// PDBs opened after the keystore was opened in the CDB root need
+	// theirs opened.
+	for _, c := range containers[1:] {
+		if c.status != keystoreClosed {
+			continue
+		}
+		if err := run(sqlq.QuerySetSessionContainer(c.container), "administer key management set keystore open identified by "+password); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to open the keystore of %s: %v", c.container, err)
+		}
+		resp.Opened = true
+	}
+
+	root := containers[0].keystore
+	if req.GetAutoLogin() && root.location != "" {
+		if _, err := os.Stat(filepath.Join(root.location, autoLoginWallet)); os.IsNotExist(err) {
+			if err := run(fmt.Sprintf("administer key management create auto_login keystore from keystore '%s' identified by %s", sqlq.StringParam(root.location), password)); err != nil {
+				return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: failed to create the auto-login keystore in %s: %v", root.location, err)
+			}
+			resp.AutoLoginCreated = true
+		}
+	}
+
+	if resp.Opened || resp.AutoLoginCreated {
+		if containers, err = s.queryKeystoreContainers(ctx); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureKeystore: %v", err)
+		}
+		root = containers[0].keystore
+	}
+	resp.KeystoreLocation = root.location
+	resp.WalletType = root.walletType
+	resp.Status = root.status
+	return resp, nil
+}
+
+// setMasterKeyCmd returns the statement setting the master key of the
+// container of the session. The password keystore is used even when the
+// auto-login keystore is the open one.
+func setMasterKeyCmd(password string) string {
+	return fmt.Sprintf("administer key management set key force keystore identified by %s with backup using '%s'", password, masterKeyBackupTag)
+}
+
+// SetMasterKey sets the master encryption key of the open containers with
+// an open keystore but no key. Tablespaces can only be encrypted once
+// their container has a master key, the one of the CDB root is set first.
+func (s *Server) SetMasterKey(ctx context.Context, req *dbdpb.SetMasterKeyRequest) (*dbdpb.SetMasterKeyResponse, error) {
+	klog.InfoS("dbdaemon/SetMasterKey", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	password, err := keystorePassword(req.GetKeystorePassword())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetMasterKey: %v", err)
+	}
+	containers, err := s.queryKeystoreContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetMasterKey: %v", err)
+	}
+	if status := containers[0].status; status == keystoreNotAvailable || status == keystoreClosed {
+		return nil, fmt.Errorf("dbdaemon/SetMasterKey: the keystore is %s", status)
+	}
+
+	resp := &dbdpb.SetMasterKeyResponse{}
+	for i, c := range containers {
+		if c.status != keystoreNoMasterKey {
+			continue
+		}
+		cmds := []string{setMasterKeyCmd(password)}
+		if i > 0 {
+			cmds = append([]string{sqlq.QuerySetSessionContainer(c.container)}, cmds...)
+		}
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/SetMasterKey: failed to set the master key of %s: %v", c.container, err)
+		}
+		klog.InfoS("dbdaemon/SetMasterKey: set the master key", "container", c.container)
+		resp.Containers = append(resp.Containers, c.container)
+	}
+
+	resp.Status = containers[0].status
+	if len(resp.Containers) > 0 {
+		if containers, err = s.queryKeystoreContainers(ctx); err != nil {
+			return nil, fmt.Errorf("dbdaemon/SetMasterKey: %v", err)
+		}
+		resp.Status = containers[0].status
+	}
+	return resp, nil
+}
@@ -0,0 +1,194 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// fakeKeystore is the keystore state of a fakeSQLDB, which the keystore
+// statements change.
+type fakeKeystore struct {
+	walletRoot string
+	location   string
+	// statuses are the keystore statuses of CDB$ROOT and PDB1.
+	statuses   [2]string
+	walletType string
+	statements []string
+}
+
+func (k *fakeKeystore) query(sqls []string) ([]string, error) {
+	switch sqls[len(sqls)-1] {
+	case keystoreParametersSQL:
+		return []string{
+			fmt.Sprintf(`{"NAME":"wallet_root","VALUE":%q}`, k.walletRoot),
+			`{"NAME":"tde_configuration","VALUE":""}`,
+		}, nil
+	case keystoreContainersSQL:
+		var rows []string
+		for i, name := range []string{"CDB$ROOT", "PDB1"} {
+			rows = append(rows, fmt.Sprintf(`{"NAME":%q,"WRL_PARAMETER":%q,"WALLET_TYPE":%q,"STATUS":%q}`, name, k.location, k.walletType, k.statuses[i]))
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", sqls)
+}
+
+func (k *fakeKeystore) run(sqls []string) ([]string, error) {
+	k.statements = append(k.statements, sqls...)
+	switch last := sqls[len(sqls)-1]; {
+	case last == `administer key management create keystore identified by "Tde_pw1"`:
+		k.location = filepath.Join(k.walletRoot, "tde") + "/"
+		k.statuses = [2]string{keystoreClosed, keystoreClosed}
+		k.walletType = "UNKNOWN"
+	case last == `administer key management set keystore open identified by "Tde_pw1" container=all`:
+		k.statuses = [2]string{keystoreNoMasterKey, keystoreNoMasterKey}
+		k.walletType = "PASSWORD"
+	case last == setMasterKeyCmd(`"Tde_pw1"`) && len(sqls) == 1:
+		k.statuses[0] = "OPEN"
+	case last == setMasterKeyCmd(`"Tde_pw1"`):
+		k.statuses[1] = "OPEN"
+	}
+	return nil, nil
+}
+
+func newKeystoreServer(t *testing.T, k *fakeKeystore) *Server {
+	t.Helper()
+	useFakeOracleDatabase(t)
+	useOracleOwner(t, os.Getuid(), os.Getgid())
+	origWalletRoot := tdeWalletRoot
+	tdeWalletRoot = filepath.Join(t.TempDir(), "keystore")
+	t.Cleanup(func() { tdeWalletRoot = origWalletRoot })
+	s, err := NewMockServer(context.Background(), "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = k.query
+	db.runSQLFunc = k.run
+	return s
+}
+
+func TestConfigureKeystore(t *testing.T) {
+	k := &fakeKeystore{statuses: [2]string{keystoreNotAvailable, keystoreNotAvailable}, walletType: "UNKNOWN"}
+	s := newKeystoreServer(t, k)
+	ctx := context.Background()
+	req := &dbdpb.ConfigureKeystoreRequest{KeystorePassword: "Tde_pw1", AutoLogin: true}
+
+	resp, err := s.ConfigureKeystore(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureKeystore failed: %v", err)
+	}
+	want := &dbdpb.ConfigureKeystoreResponse{Status: keystoreNotAvailable, RestartRequired: true}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ConfigureKeystore without wallet_root got unexpected response (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{fmt.Sprintf("alter system set wallet_root='%s' scope=spfile", tdeWalletRoot)}, k.statements); diff != "" {
+		t.Errorf("ConfigureKeystore without wallet_root got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	// The database restarted with wallet_root.
+	k.walletRoot, k.statements = tdeWalletRoot, nil
+	resp, err = s.ConfigureKeystore(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureKeystore failed: %v", err)
+	}
+	location := filepath.Join(tdeWalletRoot, "tde") + "/"
+	want = &dbdpb.ConfigureKeystoreResponse{
+		KeystoreLocation: location,
+		WalletType:       "PASSWORD",
+		Status:           keystoreNoMasterKey,
+		Created:          true,
+		Opened:           true,
+		AutoLoginCreated: true,
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ConfigureKeystore got unexpected response (-want +got):\n%v", diff)
+	}
+	wantStatements := []string{
+		"alter system set tde_configuration='KEYSTORE_CONFIGURATION=FILE' scope=both",
+		`administer key management create keystore identified by "Tde_pw1"`,
+		`administer key management set keystore open identified by "Tde_pw1" container=all`,
+		fmt.Sprintf(`administer key management create auto_login keystore from keystore '%s' identified by "Tde_pw1"`, location),
+	}
+	if diff := cmp.Diff(wantStatements, k.statements); diff != "" {
+		t.Errorf("ConfigureKeystore got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	// A PDB opened after the keystore has its keystore opened.
+	k.statuses[1], k.statements = keystoreClosed, nil
+	if err := os.WriteFile(filepath.Join(location, autoLoginWallet), nil, 0600); err != nil {
+		t.Fatalf("failed to write the auto-login keystore: %v", err)
+	}
+	if _, err := s.ConfigureKeystore(ctx, req); err != nil {
+		t.Fatalf("ConfigureKeystore failed: %v", err)
+	}
+	wantStatements = []string{
+		"alter system set tde_configuration='KEYSTORE_CONFIGURATION=FILE' scope=both",
+		`alter session set container="PDB1"`,
+		`administer key management set keystore open identified by "Tde_pw1"`,
+	}
+	if diff := cmp.Diff(wantStatements, k.statements); diff != "" {
+		t.Errorf("ConfigureKeystore with a closed PDB keystore got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	for _, password := range []string{"", `pw" container=all`} {
+		if _, err := s.ConfigureKeystore(ctx, &dbdpb.ConfigureKeystoreRequest{KeystorePassword: password}); err == nil {
+			t.Errorf("ConfigureKeystore with password %q succeeded, want error", password)
+		}
+	}
+}
+
+func TestSetMasterKey(t *testing.T) {
+	k := &fakeKeystore{walletRoot: "/u02/app/oracle/keystore", location: "/u02/app/oracle/keystore/tde/", statuses: [2]string{keystoreNoMasterKey, keystoreNoMasterKey}, walletType: "PASSWORD"}
+	s := newKeystoreServer(t, k)
+	ctx := context.Background()
+
+	resp, err := s.SetMasterKey(ctx, &dbdpb.SetMasterKeyRequest{KeystorePassword: "Tde_pw1"})
+	if err != nil {
+		t.Fatalf("SetMasterKey failed: %v", err)
+	}
+	want := &dbdpb.SetMasterKeyResponse{Containers: []string{"CDB$ROOT", "PDB1"}, Status: "OPEN"}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("SetMasterKey got unexpected response (-want +got):\n%v", diff)
+	}
+	wantStatements := []string{
+		`administer key management set key force keystore identified by "Tde_pw1" with backup using 'set_master_key'`,
+		`alter session set container="PDB1"`,
+		`administer key management set key force keystore identified by "Tde_pw1" with backup using 'set_master_key'`,
+	}
+	if diff := cmp.Diff(wantStatements, k.statements); diff != "" {
+		t.Errorf("SetMasterKey got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	k.statements = nil
+	if resp, err := s.SetMasterKey(ctx, &dbdpb.SetMasterKeyRequest{KeystorePassword: "Tde_pw1"}); err != nil || len(resp.GetContainers()) != 0 || len(k.statements) != 0 {
+		t.Errorf("SetMasterKey with master keys got %v, %v and ran %q, want no statements", resp, err, k.statements)
+	}
+
+	k.statuses = [2]string{keystoreClosed, keystoreClosed}
+	if _, err := s.SetMasterKey(ctx, &dbdpb.SetMasterKeyRequest{KeystorePassword: "Tde_pw1"}); err == nil {
+		t.Errorf("SetMasterKey with a closed keystore succeeded, want error")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const licenseFileName = "license.json"
+
+// licenseFile persists the declared license under the config root so it
+// survives dbdaemon restarts.
+var licenseFile = filepath.Join(configBaseDir(), licenseFileName)
+
+// licenseState is the license declared with SetLicense.
+type licenseState struct {
+	sync.Mutex
+	license *dbdpb.SetLicenseRequest
+}
+
+// set declares the license and persists it if it changed.
+func (l *licenseState) set(license *dbdpb.SetLicenseRequest) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.license != nil && proto.Equal(l.license, license) {
+		return nil
+	}
+	content, err := protojson.Marshal(license)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(licenseFile), dirPerm(0750)); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(licenseFile, content, filePerm(0640)); err != nil {
+		return err
+	}
+	l.license = license
+	return nil
+}
+
+// get returns the declared license, loading the persisted one after a
+// restart, nil if no license was declared.
+func (l *licenseState) get() (*dbdpb.SetLicenseRequest, error) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.license != nil {
+		return l.license, nil
+	}
+	content, err := ioutil.ReadFile(licenseFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	license := &dbdpb.SetLicenseRequest{}
+	if err := protojson.Unmarshal(content, license); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", licenseFile, err)
+	}
+	l.license = license
+	return license, nil
+}
+
+// requireLicensedOption is the guard of the operations enabling a
+// separately licensed option, it refuses them unless the declared license
+// covers the option.
+func (s *Server) requireLicensedOption(option dbdpb.GetFeatureUsageRequest_Option) error {
+	license, err := s.license.get()
+	if err != nil {
+		return fmt.Errorf("failed to read the declared license: %v", err)
+	}
+	if license == nil {
+		return fmt.Errorf("refusing to enable %v: the database license wasn't declared", option)
+	}
+	if reason := optionUnlicensedReason(license.GetEdition(), license.GetLicensedOptions(), option); reason != "" {
+		return fmt.Errorf("refusing to enable %v: %s", option, reason)
+	}
+	return nil
+}
+
+// SetLicense declares the edition and the licensed options which
+// requireLicensedOption checks.
+func (s *Server) SetLicense(ctx context.Context, req *dbdpb.SetLicenseRequest) (*dbdpb.SetLicenseResponse, error) {
+	klog.InfoS("dbdaemon/SetLicense", "req", loggableRequest(req))
+	for _, o := range req.GetLicensedOptions() {
+		if o == dbdpb.GetFeatureUsageRequest_OPTION_UNSPECIFIED {
+			return nil, fmt.Errorf("dbdaemon/SetLicense: unspecified licensed option")
+		}
+	}
+	if err := s.license.set(req); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetLicense: failed to persist the license: %v", err)
+	}
+	return &dbdpb.SetLicenseResponse{}, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestLicenseGuards(t *testing.T) {
+	guarded := []struct {
+		name string
+		call func(s *Server) error
+	}{
+		{
+			name: "ConfigureInMemory",
+			call: func(s *Server) error {
+				_, err := s.ConfigureInMemory(context.Background(), &dbdpb.ConfigureInMemoryRequest{PdbName: "PDB1", Resize: true, SizeBytes: 1 << 30})
+				return err
+			},
+		},
+		{
+			name: "MaintainPartitions",
+			call: func(s *Server) error {
+				_, err := s.MaintainPartitions(context.Background(), &dbdpb.MaintainPartitionsRequest{PdbName: "PDB1", Table: "SCOTT.SALES", Interval: dbdpb.MaintainPartitionsRequest_MONTH})
+				return err
+			},
+		},
+		{
+			name: "RunSQLTuningAdvisor",
+			call: func(s *Server) error {
+				_, err := s.RunSQLTuningAdvisor(context.Background(), &dbdpb.RunSQLTuningAdvisorRequest{PdbName: "PDB1", SqlId: "7b2twsn8vgfsc"})
+				return err
+			},
+		},
+		{
+			name: "GetSQLMonitorReport",
+			call: func(s *Server) error {
+				_, err := s.GetSQLMonitorReport(context.Background(), &dbdpb.GetSQLMonitorReportRequest{PdbName: "PDB1", SqlId: "7b2twsn8vgfsc"})
+				return err
+			},
+		},
+		{
+			name: "CreateAWRBaseline",
+			call: func(s *Server) error {
+				_, err := s.CreateAWRBaseline(context.Background(), &dbdpb.CreateAWRBaselineRequest{BaselineName: "PRE_PATCH", WindowHours: 24})
+				return err
+			},
+		},
+		{
+			name: "ConfigureAWR",
+			call: func(s *Server) error {
+				_, err := s.ConfigureAWR(context.Background(), &dbdpb.ConfigureAWRRequest{RetentionDays: 8})
+				return err
+			},
+		},
+		{
+			name: "ConfigureRedoTransport",
+			call: func(s *Server) error {
+				_, err := s.ConfigureRedoTransport(context.Background(), &dbdpb.ConfigureRedoTransportRequest{StandbyDbUniqueName: "STBY", Compression: true})
+				return err
+			},
+		},
+	}
+	allOptions := []dbdpb.GetFeatureUsageRequest_Option{
+		dbdpb.GetFeatureUsageRequest_PARTITIONING,
+		dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION,
+		dbdpb.GetFeatureUsageRequest_DATABASE_IN_MEMORY,
+		dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK,
+		dbdpb.GetFeatureUsageRequest_TUNING_PACK,
+	}
+	licenses := []struct {
+		name    string
+		license *dbdpb.SetLicenseRequest
+		wantErr string
+	}{
+		{
+			name:    "undeclared",
+			wantErr: "wasn't declared",
+		},
+		{
+			name:    "SE2",
+			license: &dbdpb.SetLicenseRequest{Edition: "SE2", LicensedOptions: allOptions},
+			wantErr: "requires Enterprise Edition",
+		},
+		{
+			name:    "unlicensed Enterprise",
+			license: &dbdpb.SetLicenseRequest{Edition: "Enterprise"},
+			wantErr: "isn't licensed",
+		},
+	}
+	for _, l := range licenses {
+		for _, g := range guarded {
+			t.Run(l.name+"/"+g.name, func(t *testing.T) {
+				licenseFile = filepath.Join(t.TempDir(), "license.json")
+				s := &Server{databaseSid: &syncState{}, license: &licenseState{}}
+				if l.license != nil {
+					if _, err := s.SetLicense(context.Background(), l.license); err != nil {
+						t.Fatalf("SetLicense failed: %v", err)
+					}
+				}
+				err := g.call(s)
+				if err == nil || !strings.Contains(err.Error(), "refusing to enable") || !strings.Contains(err.Error(), l.wantErr) {
+					t.Errorf("%s got error %v, want a refusal containing %q", g.name, err, l.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestRequireLicensedOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		license *dbdpb.SetLicenseRequest
+		wantErr bool
+	}{
+		{
+			name:    "licensed Enterprise",
+			license: &dbdpb.SetLicenseRequest{Edition: "Enterprise", LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_TUNING_PACK}},
+		},
+		{
+			name:    "Express",
+			license: &dbdpb.SetLicenseRequest{Edition: "Express"},
+		},
+		{
+			name:    "other option licensed",
+			license: &dbdpb.SetLicenseRequest{LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_DIAGNOSTICS_PACK}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			licenseFile = filepath.Join(t.TempDir(), "license.json")
+			s := &Server{license: &licenseState{}}
+			if _, err := s.SetLicense(context.Background(), tc.license); err != nil {
+				t.Fatalf("SetLicense failed: %v", err)
+			}
+			err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_TUNING_PACK)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("requireLicensedOption got error %v, want error: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLicenseStatePersisted(t *testing.T) {
+	licenseFile = filepath.Join(t.TempDir(), "oraconfig", "license.json")
+	want := &dbdpb.SetLicenseRequest{Edition: "Enterprise", LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_PARTITIONING}}
+	if err := (&licenseState{}).set(want); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	// A restarted dbdaemon loads the persisted license.
+	got, err := (&licenseState{}).get()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("get got unexpected license (-want +got):\n%v", diff)
+	}
+
+	licenseFile = filepath.Join(t.TempDir(), "license.json")
+	if got, err := (&licenseState{}).get(); got != nil || err != nil {
+		t.Errorf("get without a declared license got %v, %v, want nil", got, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	currentLogSQL = "select thread#, sequence# from v$log " +
+		"where status = 'CURRENT' and thread# = (select thread# from v$instance)"
+
+	// archiveLogCurrentSQL switches the log and, unlike
+	// "alter system switch logfile", waits for the archiver to finish.
+	archiveLogCurrentSQL = "alter system archive log current"
+
+	archivedLogCountSQL = "select count(*) as archived from v$archived_log " +
+		"where thread# = %d and sequence# = %d and archived = 'YES'"
+)
+
+// parseCurrentLog returns the thread and sequence of the currentLogSQL row.
+func parseCurrentLog(rows []string) (thread, sequence int64, err error) {
+	if len(rows) != 1 {
+		return 0, 0, fmt.Errorf("expected 1 current log row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse current log row %q: %v", rows[0], err)
+	}
+	if thread, err = strconv.ParseInt(row["THREAD#"], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse THREAD# in current log row %q: %v", rows[0], err)
+	}
+	if sequence, err = strconv.ParseInt(row["SEQUENCE#"], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse SEQUENCE# in current log row %q: %v", rows[0], err)
+	}
+	return thread, sequence, nil
+}
+
+// parseArchivedLogCount returns the count of the archivedLogCountSQL row.
+func parseArchivedLogCount(rows []string) (int64, error) {
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("expected 1 archived log count row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return 0, fmt.Errorf("failed to parse archived log count row %q: %v", rows[0], err)
+	}
+	count, err := strconv.ParseInt(row["ARCHIVED"], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ARCHIVED in archived log count row %q: %v", rows[0], err)
+	}
+	return count, nil
+}
+
+// ForceLogSwitch archives the current online redo log, so that all redo
+// generated so far is available for backups and redo shipping.
+func (s *Server) ForceLogSwitch(ctx context.Context, req *dbdpb.ForceLogSwitchRequest) (*dbdpb.ForceLogSwitchResponse, error) {
+	klog.InfoS("dbdaemon/ForceLogSwitch", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	currentResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{currentLogSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: failed to query the current log: %v", err)
+	}
+	thread, sequence, err := parseCurrentLog(currentResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: %v", err)
+	}
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{archiveLogCurrentSQL}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: failed to archive the current log: %v", err)
+	}
+
+	archivedResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf(archivedLogCountSQL, thread, sequence)}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: failed to query archived logs: %v", err)
+	}
+	count, err := parseArchivedLogCount(archivedResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: %v", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("dbdaemon/ForceLogSwitch: log thread %d sequence %d was not archived", thread, sequence)
+	}
+	klog.InfoS("dbdaemon/ForceLogSwitch: archived the current log", "thread", thread, "sequence", sequence)
+	return &dbdpb.ForceLogSwitchResponse{Thread: thread, Sequence: sequence}, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseCurrentLog(t *testing.T) {
+	thread, sequence, err := parseCurrentLog([]string{`{"THREAD#":"1","SEQUENCE#":"42"}`})
+	if err != nil {
+		t.Fatalf("parseCurrentLog failed: %v", err)
+	}
+	if thread != 1 || sequence != 42 {
+		t.Errorf("parseCurrentLog = %d, %d, want 1, 42", thread, sequence)
+	}
+
+	for _, rows := range [][]string{
+		nil,
+		{`{"THREAD#":"1","SEQUENCE#":"42"}`, `{"THREAD#":"2","SEQUENCE#":"7"}`},
+		{"not json"},
+		{`{"THREAD#":"1","SEQUENCE#":""}`},
+	} {
+		if _, _, err := parseCurrentLog(rows); err == nil {
+			t.Errorf("parseCurrentLog(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+// fakeRedoLogs simulates the online redo log of a single thread, archiving
+// the current log on archiveLogCurrentSQL if archive is set.
+type fakeRedoLogs struct {
+	archive  bool
+	current  int64
+	archived map[int64]bool
+	switches int
+}
+
+func (f *fakeRedoLogs) runSQL(sqls []string) ([]string, error) {
+	if sqls[0] != archiveLogCurrentSQL {
+		return nil, fmt.Errorf("unexpected statement %q", sqls)
+	}
+	f.switches++
+	if f.archive {
+		f.archived[f.current] = true
+	}
+	f.current++
+	return nil, nil
+}
+
+func (f *fakeRedoLogs) runQuery(sqls []string) ([]string, error) {
+	if sqls[0] == currentLogSQL {
+		return []string{fmt.Sprintf(`{"THREAD#":"1","SEQUENCE#":"%d"}`, f.current)}, nil
+	}
+	for seq, archived := range f.archived {
+		if archived && sqls[0] == fmt.Sprintf(archivedLogCountSQL, 1, seq) {
+			return []string{`{"ARCHIVED":"1"}`}, nil
+		}
+	}
+	return []string{`{"ARCHIVED":"0"}`}, nil
+}
+
+func TestForceLogSwitch(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name    string
+		archive bool
+		wantErr bool
+	}{
+		{name: "log archived", archive: true},
+		{name: "log not archived", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			logs := &fakeRedoLogs{archive: tc.archive, current: 42, archived: map[int64]bool{41: true}}
+			db := useFakeSQLDB(s)
+			db.runSQLFunc = logs.runSQL
+			db.runQueryFunc = logs.runQuery
+
+			resp, err := s.ForceLogSwitch(ctx, &dbdpb.ForceLogSwitchRequest{})
+			if logs.switches != 1 {
+				t.Errorf("ForceLogSwitch issued %q %d times, want 1", archiveLogCurrentSQL, logs.switches)
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ForceLogSwitch succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForceLogSwitch failed: %v", err)
+			}
+			if resp.GetThread() != 1 || resp.GetSequence() != 42 {
+				t.Errorf("ForceLogSwitch archived thread %d sequence %d, want thread 1 sequence 42", resp.GetThread(), resp.GetSequence())
+			}
+			if !logs.archived[42] {
+				t.Errorf("ForceLogSwitch did not archive sequence 42")
+			}
+		})
+	}
+}
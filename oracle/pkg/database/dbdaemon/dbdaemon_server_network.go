@@ -0,0 +1,346 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	sqlNetFile = "sqlnet.ora"
+
+	defaultNetworkEncryptionLevel     = "REQUIRED"
+	defaultNetworkEncryptionAlgorithm = "AES256"
+)
+
+var (
+	// networkEncryptionLevels are the accepted SQLNET.ENCRYPTION_SERVER values.
+	networkEncryptionLevels = map[string]bool{
+		"REQUIRED":  true,
+		"REQUESTED": true,
+		"ACCEPTED":  true,
+		"REJECTED":  true,
+	}
+	// networkEncryptionAlgorithms are the accepted
+	// SQLNET.ENCRYPTION_TYPES_SERVER values, weaker legacy
+	// algorithms (DES, 3DES, RC4) are intentionally not allowed.
+	networkEncryptionAlgorithms = map[string]bool{
+		"AES256": true,
+		"AES192": true,
+		"AES128": true,
+	}
+	// hostNameLabel matches a single RFC 1123 host name label.
+	hostNameLabel = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+	// allDigits matches a numeric label, used to reject malformed
+	// IP addresses which would otherwise pass as host names.
+	allDigits = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// sqlNetParam is a single name = value entry of sqlnet.ora.
+type sqlNetParam struct {
+	name  string
+	value string
+}
+
+// networkEncryptionParams validates the request and returns the
+// sqlnet.ora entries enabling native network encryption.
+func networkEncryptionParams(req *dbdpb.ConfigureNetworkEncryptionRequest) ([]sqlNetParam, error) {
+	if req.GetDisable() {
+		return []sqlNetParam{
+			{name: "sqlnet.encryption_server"},
+			{name: "sqlnet.encryption_types_server"},
+			{name: "sqlnet.crypto_checksum_server"},
+			{name: "sqlnet.crypto_checksum_types_server"},
+		}, nil
+	}
+	level := strings.ToUpper(req.GetLevel())
+	if level == "" {
+		level = defaultNetworkEncryptionLevel
+	}
+	if !networkEncryptionLevels[level] {
+		return nil, fmt.Errorf("unsupported network encryption level %q", req.GetLevel())
+	}
+
+	var algorithms []string
+	seen := make(map[string]bool)
+	for _, a := range req.GetAlgorithms() {
+		a = strings.ToUpper(strings.TrimSpace(a))
+		if !networkEncryptionAlgorithms[a] {
+			return nil, fmt.Errorf("unsupported network encryption algorithm %q", a)
+		}
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		algorithms = append(algorithms, a)
+	}
+	if len(algorithms) == 0 {
+		algorithms = []string{defaultNetworkEncryptionAlgorithm}
+	}
+
+	return []sqlNetParam{
+		{name: "sqlnet.encryption_server", value: level},
+		{name: "sqlnet.encryption_types_server", value: fmt.Sprintf("(%s)", strings.Join(algorithms, ", "))},
+	}, nil
+}
+
+// validateNode checks that a valid node checking entry is an IP address,
+// a CIDR block or a host name.
+func validateNode(node string) error {
+	if net.ParseIP(node) != nil {
+		return nil
+	}
+	if strings.Contains(node, "/") {
+		if _, _, err := net.ParseCIDR(node); err != nil {
+			return fmt.Errorf("invalid CIDR block %q: %v", node, err)
+		}
+		return nil
+	}
+	if len(node) > 253 {
+		return fmt.Errorf("invalid host name %q: longer than 253 characters", node)
+	}
+	labels := strings.Split(node, ".")
+	for _, l := range labels {
+		if !hostNameLabel.MatchString(l) {
+			return fmt.Errorf("invalid host name %q", node)
+		}
+	}
+	if allDigits.MatchString(labels[len(labels)-1]) {
+		return fmt.Errorf("invalid IP address %q", node)
+	}
+	return nil
+}
+
+// nodeList validates and de-duplicates valid node checking entries,
+// keeping their order.
+func nodeList(nodes []string) ([]string, error) {
+	var list []string
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		n = strings.TrimSpace(n)
+		if err := validateNode(n); err != nil {
+			return nil, err
+		}
+		if seen[strings.ToLower(n)] {
+			continue
+		}
+		seen[strings.ToLower(n)] = true
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// allowedClientsParams validates the request and returns the sqlnet.ora
+// entries configuring valid node checking. localNodes address the database
+// host itself, they are always invited and can not be excluded. The same
+// applies to the cluster nodes of the request. Valid node checking entries
+// are removed if both lists are empty.
+func allowedClientsParams(req *dbdpb.ConfigureAllowedClientsRequest, localNodes []string) ([]sqlNetParam, error) {
+	// Agents outside the database pod, e.g. monitoring, connect through
+	// the instance Service from the pod network.
+	localNodes = append(append([]string{}, localNodes...), req.GetClusterNodes()...)
+	invited, err := nodeList(req.GetInvitedNodes())
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := nodeList(req.GetExcludedNodes())
+	if err != nil {
+		return nil, err
+	}
+	if len(invited) == 0 && len(excluded) == 0 {
+		return []sqlNetParam{
+			{name: "tcp.validnode_checking"},
+			{name: "tcp.invited_nodes"},
+			{name: "tcp.excluded_nodes"},
+		}, nil
+	}
+
+	for _, n := range excluded {
+		for _, l := range localNodes {
+			if strings.EqualFold(n, l) {
+				return nil, fmt.Errorf("the database host %q can not be excluded", n)
+			}
+		}
+	}
+	invitedValue := ""
+	if len(invited) > 0 {
+		// Local connections, e.g. from agents in the database pod,
+		// must keep working.
+		invited, err = nodeList(append(append([]string{}, localNodes...), invited...))
+		if err != nil {
+			return nil, err
+		}
+		invitedValue = fmt.Sprintf("(%s)", strings.Join(invited, ", "))
+	}
+	excludedValue := ""
+	if len(excluded) > 0 {
+		excludedValue = fmt.Sprintf("(%s)", strings.Join(excluded, ", "))
+	}
+	return []sqlNetParam{
+		{name: "tcp.validnode_checking", value: "YES"},
+		{name: "tcp.invited_nodes", value: invitedValue},
+		{name: "tcp.excluded_nodes", value: excludedValue},
+	}, nil
+}
+
+// updateSQLNet sets params in the sqlnet.ora content. Existing entries are
+// matched case-insensitively and replaced in place (including their
+// continuation lines), missing entries are appended. Params with an empty
+// value are removed.
+func updateSQLNet(content string, params []sqlNetParam) string {
+	pending := make(map[string]sqlNetParam)
+	var order []string
+	for _, p := range params {
+		key := strings.ToLower(p.name)
+		if _, ok := pending[key]; !ok {
+			order = append(order, key)
+		}
+		pending[key] = p
+	}
+
+	var out, lines []string
+	if trimmed := strings.TrimRight(content, "\n"); trimmed != "" {
+		lines = strings.Split(trimmed, "\n")
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		key := sqlNetParamName(line)
+		p, ok := pending[key]
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		// Skip continuation lines of a multi-line value.
+		for i+1 < len(lines) && isSQLNetContinuation(lines[i+1]) {
+			i++
+		}
+		if p.value != "" {
+			out = append(out, fmt.Sprintf("%s = %s", p.name, p.value))
+		}
+		delete(pending, key)
+	}
+	for _, key := range order {
+		if p, ok := pending[key]; ok && p.value != "" {
+			out = append(out, fmt.Sprintf("%s = %s", p.name, p.value))
+		}
+	}
+	updated := strings.Join(out, "\n")
+	if content == "" || strings.HasSuffix(content, "\n") {
+		updated += "\n"
+	}
+	return updated
+}
+
+// sqlNetParamName returns the lower-cased parameter name defined on a
+// sqlnet.ora line or an empty string for comments and continuation lines.
+func sqlNetParamName(line string) string {
+	if line == "" || isSQLNetContinuation(line) || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	i := strings.Index(line, "=")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(line[:i]))
+}
+
+// isSQLNetContinuation reports whether a line continues the value of
+// the previous parameter, which Oracle network files mark with
+// leading whitespace.
+func isSQLNetContinuation(line string) bool {
+	return strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// updateListenerSQLNet writes params to the sqlnet.ora in dir and bounces
+// the listener if the file content changed.
+func (s *Server) updateListenerSQLNet(ctx context.Context, dir string, params []sqlNetParam) (bool, error) {
+	path := filepath.Join(dir, sqlNetFile)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to read %s: %v", path, err)
+	}
+	updated := updateSQLNet(string(content), params)
+	if updated == string(content) {
+		return false, nil
+	}
+	if err := ioutil.WriteFile(path, []byte(updated), filePerm(0600)); err != nil {
+		return false, fmt.Errorf("unable to write %s: %v", path, err)
+	}
+
+	lType := filepath.Base(dir)
+	if _, err := s.BounceListener(ctx, &dbdpb.BounceListenerRequest{
+		Operation:    dbdpb.BounceListenerRequest_STOP,
+		ListenerName: lType,
+		TnsAdmin:     dir,
+	}); err != nil {
+		klog.ErrorS(err, "Listener stop failed", "name", lType, "lDir", dir)
+	}
+	if _, err := s.BounceListener(ctx, &dbdpb.BounceListenerRequest{
+		Operation:    dbdpb.BounceListenerRequest_START,
+		ListenerName: lType,
+		TnsAdmin:     dir,
+	}); err != nil {
+		return true, fmt.Errorf("listener %s startup failed: %s, %v", lType, dir, err)
+	}
+	return true, nil
+}
+
+// ConfigureNetworkEncryption sets Oracle native network encryption
+// parameters in the listener sqlnet.ora and bounces the listener
+// if they changed.
+func (s *Server) ConfigureNetworkEncryption(ctx context.Context, req *dbdpb.ConfigureNetworkEncryptionRequest) (*dbdpb.ConfigureNetworkEncryptionResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureNetworkEncryption", "req", loggableRequest(req))
+	params, err := networkEncryptionParams(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureNetworkEncryption: %v", err)
+	}
+	changed, err := s.updateListenerSQLNet(ctx, filepath.Join(listenerDir(), consts.SECURE), params)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureNetworkEncryption: %v", err)
+	}
+	klog.InfoS("dbdaemon/ConfigureNetworkEncryption done", "changed", changed)
+	return &dbdpb.ConfigureNetworkEncryptionResponse{Changed: changed}, nil
+}
+
+// ConfigureAllowedClients sets the listener valid node checking parameters
+// in the listener sqlnet.ora and bounces the listener if they changed.
+func (s *Server) ConfigureAllowedClients(ctx context.Context, req *dbdpb.ConfigureAllowedClientsRequest) (*dbdpb.ConfigureAllowedClientsResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureAllowedClients", "req", loggableRequest(req))
+	localNodes := []string{"127.0.0.1", "localhost"}
+	if s.hostName != "" {
+		localNodes = append(localNodes, s.hostName)
+	}
+	params, err := allowedClientsParams(req, localNodes)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAllowedClients: %v", err)
+	}
+	changed, err := s.updateListenerSQLNet(ctx, filepath.Join(listenerDir(), consts.SECURE), params)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureAllowedClients: %v", err)
+	}
+	klog.InfoS("dbdaemon/ConfigureAllowedClients done", "changed", changed)
+	return &dbdpb.ConfigureAllowedClientsResponse{Changed: changed}, nil
+}
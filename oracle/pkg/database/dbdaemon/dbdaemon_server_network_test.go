@@ -0,0 +1,356 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const testSQLNet = `sqlnet.crypto_checksum_server = REQUIRED
+sqlnet.encryption_server = REQUIRED
+sqlnet.crypto_checksum_types_server = (SHA1)
+sqlnet.encryption_types_server = (AES256)
+# to specify a the time interval, in minutes, to send a probe
+# to verify that client/server connections are active
+sqlnet.expire_time = 15
+`
+
+func TestNetworkEncryptionParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *dbdpb.ConfigureNetworkEncryptionRequest
+		want    []sqlNetParam
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			req:  &dbdpb.ConfigureNetworkEncryptionRequest{},
+			want: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUIRED"},
+				{name: "sqlnet.encryption_types_server", value: "(AES256)"},
+			},
+		},
+		{
+			name: "level and algorithms normalized",
+			req: &dbdpb.ConfigureNetworkEncryptionRequest{
+				Level:      "requested",
+				Algorithms: []string{"aes256", " AES128", "AES256"},
+			},
+			want: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUESTED"},
+				{name: "sqlnet.encryption_types_server", value: "(AES256, AES128)"},
+			},
+		},
+		{
+			name: "disabled",
+			req:  &dbdpb.ConfigureNetworkEncryptionRequest{Level: "REQUIRED", Disable: true},
+			want: []sqlNetParam{
+				{name: "sqlnet.encryption_server"},
+				{name: "sqlnet.encryption_types_server"},
+				{name: "sqlnet.crypto_checksum_server"},
+				{name: "sqlnet.crypto_checksum_types_server"},
+			},
+		},
+		{
+			name:    "unknown level",
+			req:     &dbdpb.ConfigureNetworkEncryptionRequest{Level: "MANDATORY"},
+			wantErr: true,
+		},
+		{
+			name:    "weak algorithm",
+			req:     &dbdpb.ConfigureNetworkEncryptionRequest{Algorithms: []string{"AES256", "3DES168"}},
+			wantErr: true,
+		},
+		{
+			name:    "injected parameter",
+			req:     &dbdpb.ConfigureNetworkEncryptionRequest{Algorithms: []string{"AES256)\nsqlnet.encryption_server = REJECTED"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := networkEncryptionParams(tc.req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("networkEncryptionParams(%v) error = %v, wantErr %v", tc.req, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(sqlNetParam{})); diff != "" {
+				t.Errorf("networkEncryptionParams got unexpected params (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSQLNet(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		params  []sqlNetParam
+		want    string
+	}{
+		{
+			name:    "replace in place",
+			content: testSQLNet,
+			params: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUESTED"},
+				{name: "sqlnet.encryption_types_server", value: "(AES256, AES192)"},
+			},
+			want: `sqlnet.crypto_checksum_server = REQUIRED
+sqlnet.encryption_server = REQUESTED
+sqlnet.crypto_checksum_types_server = (SHA1)
+sqlnet.encryption_types_server = (AES256, AES192)
+# to specify a the time interval, in minutes, to send a probe
+# to verify that client/server connections are active
+sqlnet.expire_time = 15
+`,
+		},
+		{
+			name:    "unchanged",
+			content: testSQLNet,
+			params: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUIRED"},
+				{name: "sqlnet.encryption_types_server", value: "(AES256)"},
+			},
+			want: testSQLNet,
+		},
+		{
+			name:    "case insensitive with continuation lines",
+			content: "SQLNET.ENCRYPTION_TYPES_SERVER=\n  (AES128,\n   AES192)\nsqlnet.expire_time = 15",
+			params: []sqlNetParam{
+				{name: "sqlnet.encryption_types_server", value: "(AES256)"},
+			},
+			want: "sqlnet.encryption_types_server = (AES256)\nsqlnet.expire_time = 15",
+		},
+		{
+			name:    "append missing",
+			content: "sqlnet.expire_time = 15\n",
+			params: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUIRED"},
+				{name: "sqlnet.encryption_types_server", value: "(AES256)"},
+			},
+			want: "sqlnet.expire_time = 15\nsqlnet.encryption_server = REQUIRED\nsqlnet.encryption_types_server = (AES256)\n",
+		},
+		{
+			name:    "commented out entry is not replaced",
+			content: "# sqlnet.encryption_server = REJECTED\n",
+			params: []sqlNetParam{
+				{name: "sqlnet.encryption_server", value: "REQUIRED"},
+			},
+			want: "# sqlnet.encryption_server = REJECTED\nsqlnet.encryption_server = REQUIRED\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, updateSQLNet(tc.content, tc.params)); diff != "" {
+				t.Errorf("updateSQLNet got unexpected content (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateListenerSQLNet(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, sqlNetFile)
+	if err := ioutil.WriteFile(path, []byte(testSQLNet), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	params, err := networkEncryptionParams(&dbdpb.ConfigureNetworkEncryptionRequest{Algorithms: []string{"AES192"}})
+	if err != nil {
+		t.Fatalf("networkEncryptionParams failed: %v", err)
+	}
+
+	proxy := s.dbdClient.(*mockDatabaseDaemonProxyClient)
+	for i, wantChanged := range []bool{true, false} {
+		changed, err := s.updateListenerSQLNet(ctx, dir, params)
+		if err != nil {
+			t.Fatalf("updateListenerSQLNet failed: %v", err)
+		}
+		if changed != wantChanged {
+			t.Errorf("updateListenerSQLNet call %d changed = %v, want %v", i, changed, wantChanged)
+		}
+		if proxy.listenerShutdownCount != 1 || proxy.listenerStartupCount != 1 {
+			t.Errorf("updateListenerSQLNet call %d bounced the listener %d/%d times, want once", i, proxy.listenerShutdownCount, proxy.listenerStartupCount)
+		}
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if want := updateSQLNet(testSQLNet, params); string(got) != want {
+		t.Errorf("updateListenerSQLNet wrote %q, want %q", got, want)
+	}
+
+	if _, err := s.updateListenerSQLNet(ctx, t.TempDir(), params); err == nil {
+		t.Error("updateListenerSQLNet succeeded without sqlnet.ora, want error")
+	}
+}
+
+func TestValidateNode(t *testing.T) {
+	for _, node := range []string{
+		"10.0.0.5",
+		"10.0.0.0/24",
+		"fd00::1",
+		"fd00::/64",
+		"app-server",
+		"app-1.example.com",
+		"App.Example.COM",
+	} {
+		if err := validateNode(node); err != nil {
+			t.Errorf("validateNode(%q) failed: %v", node, err)
+		}
+	}
+	for _, node := range []string{
+		"",
+		"10.0.0.300",
+		"10.0.0.0/33",
+		"10.0.0.0/",
+		"-app",
+		"app_server",
+		"app..example.com",
+		"10.0.0.*",
+		"host, 10.0.0.1",
+		"host)\ntcp.validnode_checking = NO",
+		strings.Repeat("a.", 127) + "com",
+	} {
+		if err := validateNode(node); err == nil {
+			t.Errorf("validateNode(%q) succeeded, want error", node)
+		}
+	}
+}
+
+func TestAllowedClientsParams(t *testing.T) {
+	localNodes := []string{"127.0.0.1", "localhost", "db-host"}
+	tests := []struct {
+		name    string
+		req     *dbdpb.ConfigureAllowedClientsRequest
+		want    []sqlNetParam
+		wantErr bool
+	}{
+		{
+			name: "disabled",
+			req:  &dbdpb.ConfigureAllowedClientsRequest{},
+			want: []sqlNetParam{
+				{name: "tcp.validnode_checking"},
+				{name: "tcp.invited_nodes"},
+				{name: "tcp.excluded_nodes"},
+			},
+		},
+		{
+			name: "allow list includes local nodes",
+			req: &dbdpb.ConfigureAllowedClientsRequest{
+				InvitedNodes: []string{"10.0.0.0/24", "app.example.com", "LOCALHOST", "10.0.0.0/24"},
+			},
+			want: []sqlNetParam{
+				{name: "tcp.validnode_checking", value: "YES"},
+				{name: "tcp.invited_nodes", value: "(127.0.0.1, localhost, db-host, 10.0.0.0/24, app.example.com)"},
+				{name: "tcp.excluded_nodes"},
+			},
+		},
+		{
+			name: "allow list includes cluster nodes",
+			req: &dbdpb.ConfigureAllowedClientsRequest{
+				InvitedNodes: []string{"app.example.com"},
+				ClusterNodes: []string{"10.4.0.0/24", "10.4.1.0/24"},
+			},
+			want: []sqlNetParam{
+				{name: "tcp.validnode_checking", value: "YES"},
+				{name: "tcp.invited_nodes", value: "(127.0.0.1, localhost, db-host, 10.4.0.0/24, 10.4.1.0/24, app.example.com)"},
+				{name: "tcp.excluded_nodes"},
+			},
+		},
+		{
+			name:    "cluster node excluded",
+			req:     &dbdpb.ConfigureAllowedClientsRequest{ExcludedNodes: []string{"10.4.0.0/24"}, ClusterNodes: []string{"10.4.0.0/24"}},
+			wantErr: true,
+		},
+		{
+			name: "deny list",
+			req: &dbdpb.ConfigureAllowedClientsRequest{
+				ExcludedNodes: []string{"192.168.1.10", " batch-host "},
+			},
+			want: []sqlNetParam{
+				{name: "tcp.validnode_checking", value: "YES"},
+				{name: "tcp.invited_nodes"},
+				{name: "tcp.excluded_nodes", value: "(192.168.1.10, batch-host)"},
+			},
+		},
+		{
+			name:    "invalid invited entry",
+			req:     &dbdpb.ConfigureAllowedClientsRequest{InvitedNodes: []string{"10.0.0.0/40"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid excluded entry",
+			req:     &dbdpb.ConfigureAllowedClientsRequest{ExcludedNodes: []string{"bad host"}},
+			wantErr: true,
+		},
+		{
+			name:    "local node excluded",
+			req:     &dbdpb.ConfigureAllowedClientsRequest{ExcludedNodes: []string{"DB-HOST"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := allowedClientsParams(tc.req, localNodes)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("allowedClientsParams(%v) error = %v, wantErr %v", tc.req, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(sqlNetParam{})); diff != "" {
+				t.Errorf("allowedClientsParams got unexpected params (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSQLNetAllowedClients(t *testing.T) {
+	localNodes := []string{"127.0.0.1", "localhost"}
+	enable, err := allowedClientsParams(&dbdpb.ConfigureAllowedClientsRequest{
+		InvitedNodes:  []string{"10.0.0.0/24"},
+		ExcludedNodes: []string{"10.0.0.13"},
+	}, localNodes)
+	if err != nil {
+		t.Fatalf("allowedClientsParams failed: %v", err)
+	}
+	enabled := updateSQLNet(testSQLNet, enable)
+	want := testSQLNet + `tcp.validnode_checking = YES
+tcp.invited_nodes = (127.0.0.1, localhost, 10.0.0.0/24)
+tcp.excluded_nodes = (10.0.0.13)
+`
+	if diff := cmp.Diff(want, enabled); diff != "" {
+		t.Errorf("updateSQLNet got unexpected content (-want +got):\n%v", diff)
+	}
+
+	disable, err := allowedClientsParams(&dbdpb.ConfigureAllowedClientsRequest{}, localNodes)
+	if err != nil {
+		t.Fatalf("allowedClientsParams failed: %v", err)
+	}
+	if diff := cmp.Diff(testSQLNet, updateSQLNet(enabled, disable)); diff != "" {
+		t.Errorf("updateSQLNet did not remove valid node checking (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// nlsParametersSQL reports the NLS parameters in effect in the
+	// container and the values set in its spfile.
+	nlsParametersSQL = "select p.name, p.value, p.type, p.issys_modifiable, sp.value spfile_value " +
+		"from v$parameter p left join v$spparameter sp on sp.name = p.name and sp.sid = '*' " +
+		"where p.name like 'nls\\_%' escape '\\' order by p.name"
+
+	nlsDatabaseParametersSQL = "select parameter, value from nls_database_parameters order by parameter"
+)
+
+// nlsParameterNames are the NLS parameters SetNLSSettings sets.
+var nlsParameterNames = map[string]bool{
+	"nls_calendar":            true,
+	"nls_comp":                true,
+	"nls_currency":            true,
+	"nls_date_format":         true,
+	"nls_date_language":       true,
+	"nls_dual_currency":       true,
+	"nls_iso_currency":        true,
+	"nls_language":            true,
+	"nls_length_semantics":    true,
+	"nls_nchar_conv_excp":     true,
+	"nls_numeric_characters":  true,
+	"nls_sort":                true,
+	"nls_territory":           true,
+	"nls_time_format":         true,
+	"nls_time_tz_format":      true,
+	"nls_timestamp_format":    true,
+	"nls_timestamp_tz_format": true,
+}
+
+// nlsParameter is a row of nlsParametersSQL.
+type nlsParameter struct {
+	value       string
+	spfileValue string
+	// isString parameters are quoted, static ones are set in the spfile.
+	isString bool
+	static   bool
+}
+
+// parseNLSParameters converts nlsParametersSQL rows into a map of the
+// parameters by name.
+func parseNLSParameters(rows []string) (map[string]nlsParameter, error) {
+	params := make(map[string]nlsParameter)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse NLS parameter row %q: %v", msg, err)
+		}
+		// The string parameters are of type 2.
+		params[row["NAME"]] = nlsParameter{
+			value:       row["VALUE"],
+			spfileValue: row["SPFILE_VALUE"],
+			isString:    row["TYPE"] == "2",
+			static:      row["ISSYS_MODIFIABLE"] == "FALSE",
+		}
+	}
+	return params, nil
+}
+
+// nlsStatements returns the statements setting and resetting the NLS
+// parameters of the request which differ from params, and whether the
+// database needs a restart for them to take effect.
+func nlsStatements(req *dbdpb.SetNLSSettingsRequest, params map[string]nlsParameter) ([]string, bool, error) {
+	var names []string
+	for name := range req.GetParameters() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var statements []string
+	restartRequired := false
+	for _, name := range names {
+		value := req.GetParameters()[name]
+		p, ok := params[strings.ToLower(name)]
+		if !ok || !nlsParameterNames[strings.ToLower(name)] {
+			return nil, false, fmt.Errorf("unsupported NLS parameter %q", name)
+		}
+		inEffect := strings.EqualFold(p.value, value)
+		if inEffect && (p.spfileValue == "" || strings.EqualFold(p.spfileValue, value)) {
+			continue
+		}
+		stmt, err := sqlq.QuerySetSystemParameterNoPanic(strings.ToLower(name), value, p.isString)
+		if err != nil {
+			return nil, false, err
+		}
+		if p.static {
+			stmt += " scope=spfile"
+			restartRequired = restartRequired || !inEffect
+		}
+		statements = append(statements, stmt)
+	}
+	for _, name := range req.GetResetParameters() {
+		p, ok := params[strings.ToLower(name)]
+		if !ok || !nlsParameterNames[strings.ToLower(name)] {
+			return nil, false, fmt.Errorf("unsupported NLS parameter %q", name)
+		}
+		if _, ok := req.GetParameters()[name]; ok {
+			return nil, false, fmt.Errorf("NLS parameter %q is both set and reset", name)
+		}
+		// Only the parameters in the spfile can be reset.
+		if p.spfileValue == "" {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("alter system reset %s scope=spfile", strings.ToLower(name)))
+		restartRequired = true
+	}
+	return statements, restartRequired, nil
+}
+
+// nlsContainerCommands prefixes commands with switching to the PDB if any.
+func nlsContainerCommands(pdbName string, commands ...string) []string {
+	if pdbName == "" {
+		return commands
+	}
+	return append([]string{sqlq.QuerySetSessionContainer(pdbName)}, commands...)
+}
+
+// validateNLSPDBName validates the PDB name of a request, empty for the
+// instance.
+func validateNLSPDBName(pdbName string) error {
+	if pdbName == "" {
+		return nil
+	}
+	if _, err := sqlq.ObjectName(pdbName); err != nil {
+		return fmt.Errorf("invalid PDB name %q", pdbName)
+	}
+	return nil
+}
+
+func (s *Server) nlsParameters(ctx context.Context, pdbName string) (map[string]nlsParameter, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: nlsContainerCommands(pdbName, nlsParametersSQL)}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the NLS parameters: %v", err)
+	}
+	return parseNLSParameters(resp.GetMsg())
+}
+
+// GetNLSSettings reports the NLS parameters of the database from
+// nls_database_parameters and those of the instance or the PDB of the
+// request from v$parameter and v$spparameter.
+func (s *Server) GetNLSSettings(ctx context.Context, req *dbdpb.GetNLSSettingsRequest) (*dbdpb.GetNLSSettingsResponse, error) {
+	klog.InfoS("dbdaemon/GetNLSSettings", "req", loggableRequest(req))
+	if err := validateNLSPDBName(req.GetPdbName()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetNLSSettings: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	params, err := s.nlsParameters(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetNLSSettings: %v", err)
+	}
+	dbResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: nlsContainerCommands(req.GetPdbName(), nlsDatabaseParametersSQL)}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetNLSSettings: failed to query the NLS database parameters: %v", err)
+	}
+	resp := &dbdpb.GetNLSSettingsResponse{DatabaseParameters: make(map[string]string)}
+	for _, msg := range dbResp.GetMsg() {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GetNLSSettings: failed to parse NLS database parameter row %q: %v", msg, err)
+		}
+		resp.DatabaseParameters[row["PARAMETER"]] = row["VALUE"]
+	}
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resp.Parameters = append(resp.Parameters, &dbdpb.GetNLSSettingsResponse_Parameter{
+			Name:        name,
+			Value:       params[name].value,
+			SpfileValue: params[name].spfileValue,
+		})
+	}
+	return resp, nil
+}
+
+// SetNLSSettings sets and resets the NLS parameters of the instance or the
+// PDB of the request. The static parameters are set in the spfile and take
+// effect once the database restarts, the parameters already set are left
+// unchanged.
+func (s *Server) SetNLSSettings(ctx context.Context, req *dbdpb.SetNLSSettingsRequest) (*dbdpb.SetNLSSettingsResponse, error) {
+	klog.InfoS("dbdaemon/SetNLSSettings", "req", loggableRequest(req))
+	if err := validateNLSPDBName(req.GetPdbName()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetNLSSettings: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	params, err := s.nlsParameters(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetNLSSettings: %v", err)
+	}
+	statements, restartRequired, err := nlsStatements(req, params)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetNLSSettings: %v", err)
+	}
+	if len(statements) > 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: nlsContainerCommands(req.GetPdbName(), statements...)}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/SetNLSSettings: failed to set the NLS parameters: %v", err)
+		}
+	}
+	return &dbdpb.SetNLSSettingsResponse{RestartRequired: restartRequired}, nil
+}
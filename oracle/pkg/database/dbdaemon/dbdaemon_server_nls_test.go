@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var nlsParameterRows = []string{
+	`{"NAME":"nls_date_format","VALUE":"DD-MON-RR","TYPE":"2","ISSYS_MODIFIABLE":"FALSE"}`,
+	`{"NAME":"nls_length_semantics","VALUE":"BYTE","TYPE":"2","ISSYS_MODIFIABLE":"IMMEDIATE"}`,
+	`{"NAME":"nls_nchar_conv_excp","VALUE":"FALSE","TYPE":"1","ISSYS_MODIFIABLE":"IMMEDIATE"}`,
+	`{"NAME":"nls_sort","VALUE":"BINARY","TYPE":"2","ISSYS_MODIFIABLE":"FALSE","SPFILE_VALUE":"BINARY_CI"}`,
+	`{"NAME":"nls_territory","VALUE":"AMERICA","TYPE":"2","ISSYS_MODIFIABLE":"FALSE","SPFILE_VALUE":"AMERICA"}`,
+}
+
+func TestNLSStatements(t *testing.T) {
+	params, err := parseNLSParameters(nlsParameterRows)
+	if err != nil {
+		t.Fatalf("parseNLSParameters failed: %v", err)
+	}
+	tests := []struct {
+		name        string
+		req         *dbdpb.SetNLSSettingsRequest
+		want        []string
+		wantRestart bool
+		wantErr     bool
+	}{
+		{
+			name: "static parameter",
+			req:  &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_date_format": "YYYY-MM-DD"}},
+			want: []string{
+				"alter system set nls_date_format='YYYY-MM-DD' scope=spfile",
+			},
+			wantRestart: true,
+		},
+		{
+			name: "dynamic parameters",
+			req:  &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_nchar_conv_excp": "TRUE", "NLS_LENGTH_SEMANTICS": "CHAR"}},
+			want: []string{
+				"alter system set nls_length_semantics='CHAR'",
+				"alter system set nls_nchar_conv_excp=TRUE",
+			},
+		},
+		{
+			name: "parameters in effect",
+			req:  &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_date_format": "dd-mon-rr", "nls_territory": "AMERICA"}},
+		},
+		{
+			name: "parameter pending in the spfile",
+			req:  &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_sort": "BINARY"}},
+			want: []string{
+				"alter system set nls_sort='BINARY' scope=spfile",
+			},
+		},
+		{
+			name: "reset",
+			req:  &dbdpb.SetNLSSettingsRequest{ResetParameters: []string{"nls_sort", "nls_date_format"}},
+			want: []string{
+				"alter system reset nls_sort scope=spfile",
+			},
+			wantRestart: true,
+		},
+		{
+			name:    "unsupported parameter",
+			req:     &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"db_name": "GCLOUD"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown parameter",
+			req:     &dbdpb.SetNLSSettingsRequest{ResetParameters: []string{"nls_calendar"}},
+			wantErr: true,
+		},
+		{
+			name:    "set and reset",
+			req:     &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_sort": "BINARY"}, ResetParameters: []string{"nls_sort"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			req:     &dbdpb.SetNLSSettingsRequest{Parameters: map[string]string{"nls_nchar_conv_excp": "TRUE; drop"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, restart, err := nlsStatements(tc.req, params)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("nlsStatements got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("nlsStatements got unexpected statements (-want +got):\n%v", diff)
+			}
+			if restart != tc.wantRestart {
+				t.Errorf("nlsStatements got restart required %v, want %v", restart, tc.wantRestart)
+			}
+		})
+	}
+}
+
+func TestSetNLSSettings(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if diff := cmp.Diff([]string{`alter session set container="PDB1"`, nlsParametersSQL}, sqls); diff != "" {
+			return nil, fmt.Errorf("unexpected query (-want +got):\n%v", diff)
+		}
+		return nlsParameterRows, nil
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = sqls
+		return nil, nil
+	}
+
+	resp, err := s.SetNLSSettings(ctx, &dbdpb.SetNLSSettingsRequest{PdbName: "pdb1", Parameters: map[string]string{"nls_date_format": "YYYY-MM-DD"}})
+	if err != nil {
+		t.Fatalf("SetNLSSettings failed: %v", err)
+	}
+	if !resp.GetRestartRequired() {
+		t.Errorf("SetNLSSettings got restart required false, want true")
+	}
+	want := []string{
+		`alter session set container="PDB1"`,
+		"alter system set nls_date_format='YYYY-MM-DD' scope=spfile",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SetNLSSettings got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	if _, err := s.SetNLSSettings(ctx, &dbdpb.SetNLSSettingsRequest{PdbName: `pdb1"`}); err == nil {
+		t.Errorf("SetNLSSettings with an invalid PDB name succeeded, want error")
+	}
+}
+
+func TestGetNLSSettings(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch {
+		case cmp.Equal(sqls, []string{nlsParametersSQL}):
+			return nlsParameterRows[:2], nil
+		case cmp.Equal(sqls, []string{nlsDatabaseParametersSQL}):
+			return []string{
+				`{"PARAMETER":"NLS_CHARACTERSET","VALUE":"AL32UTF8"}`,
+				`{"PARAMETER":"NLS_NCHAR_CHARACTERSET","VALUE":"AL16UTF16"}`,
+			}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+
+	resp, err := s.GetNLSSettings(ctx, &dbdpb.GetNLSSettingsRequest{})
+	if err != nil {
+		t.Fatalf("GetNLSSettings failed: %v", err)
+	}
+	want := &dbdpb.GetNLSSettingsResponse{
+		DatabaseParameters: map[string]string{
+			"NLS_CHARACTERSET":       "AL32UTF8",
+			"NLS_NCHAR_CHARACTERSET": "AL16UTF16",
+		},
+		Parameters: []*dbdpb.GetNLSSettingsResponse_Parameter{
+			{Name: "nls_date_format", Value: "DD-MON-RR"},
+			{Name: "nls_length_semantics", Value: "BYTE"},
+		},
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("GetNLSSettings got unexpected response (-want +got):\n%v", diff)
+	}
+}
+
+func TestRecoverUntilTime(t *testing.T) {
+	// The clause must not depend on the NLS date format of the session.
+	got := recoverUntilTime(time.Date(2022, time.October, 16, 13, 4, 5, 0, time.UTC))
+	want := `time "to_date('2022-10-16 13:04:05','YYYY-MM-DD HH24:MI:SS')"`
+	if got != want {
+		t.Errorf("recoverUntilTime got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+// operationLogPollInterval is how often StreamOperationLog checks whether
+// the operation registered its log or is done.
+var operationLogPollInterval = time.Second
+
+// operationLog is the log file of an operation.
+type operationLog struct {
+	path string
+	// owned logs were created by the dbdaemon for the operation and are
+	// removed with it.
+	owned bool
+}
+
+// operationLogs maps the ids of the operations to their log files.
+type operationLogs struct {
+	sync.Mutex
+	logs map[string]operationLog
+}
+
+func (l *operationLogs) set(id string, log operationLog) {
+	l.Lock()
+	defer l.Unlock()
+	if l.logs == nil {
+		l.logs = make(map[string]operationLog)
+	}
+	l.logs[id] = log
+}
+
+func (l *operationLogs) get(id string) (operationLog, bool) {
+	l.Lock()
+	defer l.Unlock()
+	log, ok := l.logs[id]
+	return log, ok
+}
+
+func (l *operationLogs) delete(id string) (operationLog, bool) {
+	l.Lock()
+	defer l.Unlock()
+	log, ok := l.logs[id]
+	delete(l.logs, id)
+	return log, ok
+}
+
+// setOperationLog registers path as the log of the operation of the job
+// running the call, it does nothing outside of a job.
+func (s *Server) setOperationLog(ctx context.Context, path string) {
+	if id := lro.OperationID(ctx); id != "" {
+		s.operationLogs.set(id, operationLog{path: path})
+	}
+}
+
+// createOperationLog creates a log in the scratch dir for the operation of
+// the job running the call and registers it.
+func (s *Server) createOperationLog(ctx context.Context, name string) (*os.File, error) {
+	id := lro.OperationID(ctx)
+	if id == "" {
+		return nil, fmt.Errorf("no operation to create the %s log of", name)
+	}
+	path := filepath.Join(scratchDir(), fmt.Sprintf("%s_%s.log", name, url.PathEscape(id)))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm(0640))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s log: %v", name, err)
+	}
+	s.operationLogs.set(id, operationLog{path: path, owned: true})
+	return f, nil
+}
+
+// removeOperationLog forgets the log of an operation and removes it if the
+// dbdaemon created it.
+func (s *Server) removeOperationLog(id string) {
+	log, ok := s.operationLogs.delete(id)
+	if !ok || !log.owned {
+		return
+	}
+	if err := os.Remove(log.path); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("dbdaemon/removeOperationLog: failed to remove %s: %v", log.path, err)
+	}
+}
+
+// operationDone returns whether the operation is done.
+func (s *Server) operationDone(ctx context.Context, id string) (bool, error) {
+	op, err := s.lroServer.GetOperation(ctx, &lropb.GetOperationRequest{Name: id})
+	if err != nil {
+		return false, err
+	}
+	return op.GetDone(), nil
+}
+
+// StreamOperationLog polls the log of an operation for the lines appended
+// to it, until the operation is done and the whole log is sent.
+func (s *Server) StreamOperationLog(req *dbdpb.StreamOperationLogRequest, stream dbdpb.DatabaseDaemon_StreamOperationLogServer) error {
+	klog.InfoS("dbdaemon/StreamOperationLog", "req", loggableRequest(req))
+	ctx := stream.Context()
+	id := req.GetOperationId()
+	if id == "" {
+		return fmt.Errorf("dbdaemon/StreamOperationLog: missing operation id")
+	}
+	tick := time.NewTicker(operationLogPollInterval)
+	defer tick.Stop()
+
+	var f *os.File
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+	var r *bufio.Reader
+	// partial is the last line read until the rest of it is written.
+	var partial string
+	for {
+		// Checking first that the operation is done sends all the lines
+		// written before it was.
+		done, err := s.operationDone(ctx, id)
+		if err != nil {
+			return fmt.Errorf("dbdaemon/StreamOperationLog: %v", err)
+		}
+		// An operation registers its log once it knows where it is, the
+		// log is created later on.
+		if log, ok := s.operationLogs.get(id); ok && f == nil {
+			if f, err = os.Open(log.path); err == nil {
+				r = bufio.NewReader(f)
+			} else if !os.IsNotExist(err) {
+				return fmt.Errorf("dbdaemon/StreamOperationLog: failed to open %s: %v", log.path, err)
+			}
+		}
+		for r != nil {
+			line, err := r.ReadString('\n')
+			partial += line
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("dbdaemon/StreamOperationLog: failed to read the log: %v", err)
+			}
+			if err := stream.Send(&dbdpb.StreamOperationLogResponse{Line: strings.TrimRight(partial, "\r\n")}); err != nil {
+				return err
+			}
+			partial = ""
+		}
+		if done {
+			if r == nil {
+				return fmt.Errorf("dbdaemon/StreamOperationLog: operation %q is done without a log", id)
+			}
+			if partial != "" {
+				return stream.Send(&dbdpb.StreamOperationLogResponse{Line: partial})
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
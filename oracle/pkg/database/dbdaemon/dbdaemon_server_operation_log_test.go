@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+// fakeOperationLogStream passes the streamed lines to a channel.
+type fakeOperationLogStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	lines chan string
+}
+
+func (f *fakeOperationLogStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeOperationLogStream) Send(resp *dbdpb.StreamOperationLogResponse) error {
+	f.lines <- resp.GetLine()
+	return nil
+}
+
+func TestStreamOperationLog(t *testing.T) {
+	ctx := context.Background()
+	useWritableRoots(t, t.TempDir(), t.TempDir())
+	origInterval := operationLogPollInterval
+	operationLogPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { operationLogPollInterval = origInterval })
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.lroServer = lro.NewServer(ctx)
+
+	logPath := make(chan string, 1)
+	next, finish := make(chan struct{}), make(chan struct{})
+	job, err := lro.CreateAndRunLROJobWithID(ctx, "TestStreamOperationLog", "RMAN", s.lroServer, func(ctx context.Context) (proto.Message, error) {
+		log, err := s.createOperationLog(ctx, "rman")
+		if err != nil {
+			return nil, err
+		}
+		defer log.Close()
+		logPath <- log.Name()
+		fmt.Fprintln(log, "Starting backup at 16-OCT-22")
+		<-next
+		fmt.Fprintln(log, "channel ORA_DISK_1: backup set complete")
+		<-finish
+		fmt.Fprintln(log, "Finished backup at 16-OCT-22")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("CreateAndRunLROJobWithID failed: %v", err)
+	}
+
+	stream := &fakeOperationLogStream{ctx: ctx, lines: make(chan string)}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.StreamOperationLog(&dbdpb.StreamOperationLogRequest{OperationId: job.ID()}, stream)
+	}()
+	wantLine := func(want string) {
+		t.Helper()
+		select {
+		case got := <-stream.lines:
+			if got != want {
+				t.Errorf("StreamOperationLog sent %q, want %q", got, want)
+			}
+		case err := <-errc:
+			t.Fatalf("StreamOperationLog returned %v before sending %q", err, want)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("StreamOperationLog didn't send %q", want)
+		}
+	}
+	// The lines are sent as the running operation appends them.
+	wantLine("Starting backup at 16-OCT-22")
+	close(next)
+	wantLine("channel ORA_DISK_1: backup set complete")
+	close(finish)
+	wantLine("Finished backup at 16-OCT-22")
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("StreamOperationLog failed: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("StreamOperationLog didn't end with the operation")
+	}
+
+	if _, err := s.DeleteOperation(ctx, &lropb.DeleteOperationRequest{Name: job.ID()}); err != nil {
+		t.Fatalf("DeleteOperation failed: %v", err)
+	}
+	path := <-logPath
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("DeleteOperation left the operation log %s behind, got stat error %v", path, err)
+	}
+
+	if err := s.StreamOperationLog(&dbdpb.StreamOperationLogRequest{OperationId: "missing"}, stream); err == nil {
+		t.Error("StreamOperationLog of a missing operation succeeded, want an error")
+	}
+}
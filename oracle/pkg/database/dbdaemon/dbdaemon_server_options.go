@@ -0,0 +1,150 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	installedOptionsSQL = "select comp_id, comp_name, version, status, schema from dba_registry order by comp_id"
+	// validateComponentsSQL revalidates the components after their objects
+	// are recompiled, as utlrp.sql does.
+	validateComponentsSQL = "begin sys.dbms_registry_sys.validate_components; end;"
+	validComponentStatus  = "VALID"
+)
+
+// registryComponent is a dba_registry row.
+type registryComponent struct {
+	option *dbdpb.InstalledOption
+	schema string
+}
+
+// parseInstalledOptions returns the components of the rows of
+// installedOptionsSQL keyed by their ID.
+func parseInstalledOptions(rows []string) (map[string]*registryComponent, error) {
+	components := make(map[string]*registryComponent)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse registry row %q: %v", msg, err)
+		}
+		if row["COMP_ID"] == "" {
+			return nil, fmt.Errorf("registry row %q has no COMP_ID", msg)
+		}
+		components[row["COMP_ID"]] = &registryComponent{
+			option: &dbdpb.InstalledOption{
+				CompId:   row["COMP_ID"],
+				CompName: row["COMP_NAME"],
+				Version:  row["VERSION"],
+				Status:   row["STATUS"],
+			},
+			schema: row["SCHEMA"],
+		}
+	}
+	return components, nil
+}
+
+// checkRequiredComponents returns the required components which aren't
+// installed and those which aren't VALID.
+func checkRequiredComponents(components map[string]*registryComponent, required []string) (missing, invalid []string) {
+	for _, id := range required {
+		c, ok := components[id]
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case c.option.GetStatus() != validComponentStatus:
+			invalid = append(invalid, id)
+		}
+	}
+	return missing, invalid
+}
+
+// recompileComponentStatements returns the statements recompiling the
+// schemas of the components and revalidating them.
+func recompileComponentStatements(components map[string]*registryComponent, ids []string) []string {
+	var stmts []string
+	schemas := make(map[string]bool)
+	for _, id := range ids {
+		schema := components[id].schema
+		if schema == "" || schemas[schema] {
+			continue
+		}
+		schemas[schema] = true
+		stmts = append(stmts, fmt.Sprintf("begin sys.utl_recomp.recomp_serial('%s'); end;", sqlq.StringParam(schema)))
+	}
+	return append(stmts, validateComponentsSQL)
+}
+
+func (s *Server) installedOptions(ctx context.Context) (map[string]*registryComponent, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{installedOptionsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the registry: %v", err)
+	}
+	return parseInstalledOptions(resp.GetMsg())
+}
+
+// GetInstalledOptions returns the components of dba_registry and checks that
+// the required ones are installed and VALID, optionally recompiling the
+// invalid ones.
+func (s *Server) GetInstalledOptions(ctx context.Context, req *dbdpb.GetInstalledOptionsRequest) (*dbdpb.GetInstalledOptionsResponse, error) {
+	klog.InfoS("dbdaemon/GetInstalledOptions", "req", loggableRequest(req))
+	var required []string
+	for _, id := range req.GetRequiredComponents() {
+		required = append(required, strings.ToUpper(id))
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	components, err := s.installedOptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetInstalledOptions: %v", err)
+	}
+	missing, invalid := checkRequiredComponents(components, required)
+	var recompiled []string
+	if req.GetRecompileInvalid() && len(invalid) > 0 {
+		klog.InfoS("dbdaemon/GetInstalledOptions: recompiling invalid components", "components", invalid)
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: recompileComponentStatements(components, invalid)}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GetInstalledOptions: failed to recompile %v: %v", invalid, err)
+		}
+		recompiled = invalid
+		if components, err = s.installedOptions(ctx); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GetInstalledOptions: %v", err)
+		}
+		missing, invalid = checkRequiredComponents(components, required)
+	}
+
+	resp := &dbdpb.GetInstalledOptionsResponse{
+		MissingComponents:    missing,
+		InvalidComponents:    invalid,
+		RecompiledComponents: recompiled,
+	}
+	for _, c := range components {
+		resp.Options = append(resp.Options, c.option)
+	}
+	sort.Slice(resp.Options, func(i, j int) bool { return resp.Options[i].GetCompId() < resp.Options[j].GetCompId() })
+	return resp, nil
+}
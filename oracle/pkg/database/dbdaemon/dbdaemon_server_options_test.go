@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseInstalledOptions(t *testing.T) {
+	rows := []string{
+		`{"COMP_ID":"CATALOG","COMP_NAME":"Oracle Database Catalog Views","VERSION":"19.0.0.0.0","STATUS":"VALID","SCHEMA":"SYS"}`,
+		`{"COMP_ID":"CONTEXT","COMP_NAME":"Oracle Text","VERSION":"19.0.0.0.0","STATUS":"INVALID","SCHEMA":"CTXSYS"}`,
+		`{"COMP_ID":"SDO","COMP_NAME":"Spatial","VERSION":"19.0.0.0.0","STATUS":"VALID","SCHEMA":"MDSYS"}`,
+	}
+	components, err := parseInstalledOptions(rows)
+	if err != nil {
+		t.Fatalf("parseInstalledOptions failed: %v", err)
+	}
+	got := make(map[string]string)
+	for id, c := range components {
+		got[id] = c.option.GetStatus()
+	}
+	want := map[string]string{"CATALOG": "VALID", "CONTEXT": "INVALID", "SDO": "VALID"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseInstalledOptions got unexpected statuses (-want +got):\n%v", diff)
+	}
+	if components["CONTEXT"].schema != "CTXSYS" {
+		t.Errorf("parseInstalledOptions got schema %q for CONTEXT, want CTXSYS", components["CONTEXT"].schema)
+	}
+
+	missing, invalid := checkRequiredComponents(components, []string{"CONTEXT", "SDO", "XDB"})
+	if diff := cmp.Diff([]string{"XDB"}, missing); diff != "" {
+		t.Errorf("checkRequiredComponents got unexpected missing components (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{"CONTEXT"}, invalid); diff != "" {
+		t.Errorf("checkRequiredComponents got unexpected invalid components (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseInstalledOptions([]string{`{"STATUS":"VALID"}`}); err == nil {
+		t.Errorf("parseInstalledOptions of a row without COMP_ID succeeded, want error")
+	}
+}
+
+func TestGetInstalledOptions(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	textStatus := "INVALID"
+	var gotStmts []string
+	fakeDB := useFakeSQLDB(s)
+	fakeDB.runQueryFunc = func(sqls []string) ([]string, error) {
+		if sqls[0] != installedOptionsSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{
+			`{"COMP_ID":"CATALOG","COMP_NAME":"Oracle Database Catalog Views","VERSION":"19.0.0.0.0","STATUS":"VALID","SCHEMA":"SYS"}`,
+			fmt.Sprintf(`{"COMP_ID":"CONTEXT","COMP_NAME":"Oracle Text","VERSION":"19.0.0.0.0","STATUS":%q,"SCHEMA":"CTXSYS"}`, textStatus),
+		}, nil
+	}
+	fakeDB.runSQLFunc = func(sqls []string) ([]string, error) {
+		gotStmts = append(gotStmts, sqls...)
+		textStatus = "VALID"
+		return nil, nil
+	}
+
+	got, err := s.GetInstalledOptions(ctx, &dbdpb.GetInstalledOptionsRequest{RequiredComponents: []string{"context", "sdo"}})
+	if err != nil {
+		t.Fatalf("GetInstalledOptions failed: %v", err)
+	}
+	want := &dbdpb.GetInstalledOptionsResponse{
+		Options: []*dbdpb.InstalledOption{
+			{CompId: "CATALOG", CompName: "Oracle Database Catalog Views", Version: "19.0.0.0.0", Status: "VALID"},
+			{CompId: "CONTEXT", CompName: "Oracle Text", Version: "19.0.0.0.0", Status: "INVALID"},
+		},
+		MissingComponents: []string{"SDO"},
+		InvalidComponents: []string{"CONTEXT"},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetInstalledOptions got unexpected response (-want +got):\n%v", diff)
+	}
+	if len(gotStmts) != 0 {
+		t.Errorf("GetInstalledOptions without recompile_invalid ran %v", gotStmts)
+	}
+
+	got, err = s.GetInstalledOptions(ctx, &dbdpb.GetInstalledOptionsRequest{RequiredComponents: []string{"CONTEXT"}, RecompileInvalid: true})
+	if err != nil {
+		t.Fatalf("GetInstalledOptions with recompile_invalid failed: %v", err)
+	}
+	wantStmts := []string{"begin sys.utl_recomp.recomp_serial('CTXSYS'); end;", validateComponentsSQL}
+	if diff := cmp.Diff(wantStmts, gotStmts); diff != "" {
+		t.Errorf("GetInstalledOptions ran unexpected statements (-want +got):\n%v", diff)
+	}
+	if len(got.GetInvalidComponents()) != 0 {
+		t.Errorf("GetInstalledOptions got invalid components %v after the recompilation, want none", got.GetInvalidComponents())
+	}
+	if diff := cmp.Diff([]string{"CONTEXT"}, got.GetRecompiledComponents()); diff != "" {
+		t.Errorf("GetInstalledOptions got unexpected recompiled components (-want +got):\n%v", diff)
+	}
+}
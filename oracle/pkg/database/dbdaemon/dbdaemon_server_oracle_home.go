@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+// switchOracleHome patches the database out of place: it stops the database,
+// switches the daemon to the new, already patched, Oracle home and runs
+// datapatch from it (see applyDataPatch). If a step fails after the database
+// was stopped, the database is restarted from the previous Oracle home.
+func (s *Server) switchOracleHome(ctx context.Context, req *dbdpb.SwitchOracleHomeRequest) (*dbdpb.SwitchOracleHomeResponse, error) {
+	s.syncJobs.maintenanceMutex.Lock()
+	defer s.syncJobs.maintenanceMutex.Unlock()
+
+	if !filepath.IsAbs(req.GetOracleHome()) {
+		return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: Oracle home %q is not an absolute path", req.GetOracleHome())
+	}
+	newHome := filepath.Clean(req.GetOracleHome())
+	oldHome := s.databaseHome
+	if newHome == oldHome {
+		return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: the database already runs from %s", oldHome)
+	}
+	if _, err := os.Stat(datapatch(newHome)); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: %s is not an Oracle home: %v", newHome, err)
+	}
+	klog.InfoS("dbdaemon/SwitchOracleHome started", "oldHome", oldHome, "newHome", newHome)
+
+	// Ask proxy to shutdown the DB running from the current home.
+	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName: s.databaseSid.val,
+		Option:       "immediate",
+		OracleHome:   oldHome,
+	}); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: proxy request to shutdown DB failed: %w", err)
+	}
+
+	err := s.setOracleHome(newHome)
+	if err == nil {
+		_, err = s.runDataPatch(ctx)
+	}
+	if err == nil {
+		klog.InfoS("dbdaemon/SwitchOracleHome completed", "newHome", newHome)
+		return &dbdpb.SwitchOracleHomeResponse{PreviousOracleHome: oldHome}, nil
+	}
+
+	klog.ErrorS(err, "dbdaemon/SwitchOracleHome failed, rolling back", "oldHome", oldHome)
+	if rbErr := s.rollbackOracleHome(ctx, oldHome); rbErr != nil {
+		return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: %v, rollback to %s failed: %v", err, oldHome, rbErr)
+	}
+	return nil, fmt.Errorf("dbdaemon/SwitchOracleHome: %v, rolled back to %s", err, oldHome)
+}
+
+// setOracleHome switches the daemon and the processes it starts to the
+// Oracle home and relinks the config files into it.
+func (s *Server) setOracleHome(home string) error {
+	if err := setEnvNew(s, home, s.databaseSid.val); err != nil {
+		return fmt.Errorf("failed to relink the config files into %s: %v", home, err)
+	}
+	if err := os.Setenv("ORACLE_HOME", home); err != nil {
+		return fmt.Errorf("failed to set env variable: %v", err)
+	}
+	return nil
+}
+
+// rollbackOracleHome restarts the database from the previous Oracle home and
+// reruns its datapatch, which rolls back the SQL patches the new home
+// applied.
+func (s *Server) rollbackOracleHome(ctx context.Context, home string) error {
+	// The database may be in any state, it runs from the home it was started
+	// from.
+	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_SHUTDOWN,
+		DatabaseName: s.databaseSid.val,
+		Option:       "abort",
+		OracleHome:   s.databaseHome,
+	}); err != nil {
+		return fmt.Errorf("proxy request to shutdown DB failed: %w", err)
+	}
+	if err := s.setOracleHome(home); err != nil {
+		return err
+	}
+	if _, err := s.dbdClient.BounceDatabase(ctx, &dbdpb.BounceDatabaseRequest{
+		Operation:    dbdpb.BounceDatabaseRequest_STARTUP,
+		DatabaseName: s.databaseSid.val,
+		OracleHome:   home,
+	}); err != nil {
+		return fmt.Errorf("proxy request to startup DB failed: %w", err)
+	}
+	if err := s.database.openPDBs(ctx); err != nil {
+		return err
+	}
+	if err := s.runCommand(datapatch(home), []string{"-verbose"}); err != nil {
+		return fmt.Errorf("datapatch failed: %w", err)
+	}
+	return nil
+}
+
+// SwitchOracleHomeAsync turns switchOracleHome into an async call.
+func (s *Server) SwitchOracleHomeAsync(ctx context.Context, req *dbdpb.SwitchOracleHomeAsyncRequest) (*lropb.Operation, error) {
+	klog.InfoS("dbdaemon/SwitchOracleHomeAsync", "req", loggableRequest(req))
+	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "SwitchOracleHome", s.lroServer,
+		func(ctx context.Context) (proto.Message, error) {
+			// Protect databaseSid and databaseHome
+			s.databaseSid.Lock()
+			defer s.databaseSid.Unlock()
+			resp, err := s.switchOracleHome(ctx, req.GetSyncRequest())
+			if err != nil {
+				klog.ErrorS(err, "dbdaemon/SwitchOracleHomeAsync failed")
+			}
+			return resp, err
+		})
+
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/SwitchOracleHomeAsync failed to create an LRO job", "request", loggableRequest(req))
+		return nil, err
+	}
+	return &lropb.Operation{Name: job.ID(), Done: false}, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// homeRecordingProxyClient records the bounces with the Oracle home they
+// were requested for and fails the startups from failStartupHome.
+type homeRecordingProxyClient struct {
+	mockDatabaseDaemonProxyClient
+	bounces         []string
+	failStartupHome string
+}
+
+func (m *homeRecordingProxyClient) BounceDatabase(ctx context.Context, in *dbdpb.BounceDatabaseRequest, opts ...grpc.CallOption) (*dbdpb.BounceDatabaseResponse, error) {
+	op := "shutdown"
+	if in.GetOperation() == dbdpb.BounceDatabaseRequest_STARTUP {
+		op = "startup"
+	}
+	m.bounces = append(m.bounces, strings.Join(strings.Fields(strings.Join([]string{op, in.GetOption(), in.GetOracleHome()}, " ")), " "))
+	if op == "startup" && in.GetOracleHome() == m.failStartupHome {
+		return nil, errors.New("ORA-01078: failure in processing system parameters")
+	}
+	return new(dbdpb.BounceDatabaseResponse), nil
+}
+
+// failingBinOsUtil records the commands and fails the runs of failBin.
+type failingBinOsUtil struct {
+	mockOsUtil
+	failBin string
+}
+
+func (m *failingBinOsUtil) runCommand(bin string, params []string) error {
+	m.mockOsUtil.runCommand(bin, params)
+	if bin == m.failBin {
+		return errors.New("datapatch failed")
+	}
+	return nil
+}
+
+func TestSwitchOracleHome(t *testing.T) {
+	ctx := context.Background()
+	oldHome := t.TempDir()
+	newHome := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(newHome, "OPatch"), 0755); err != nil {
+		t.Fatalf("failed to create OPatch dir: %v", err)
+	}
+	if err := os.WriteFile(datapatch(newHome), nil, 0755); err != nil {
+		t.Fatalf("failed to create fake datapatch: %v", err)
+	}
+	t.Setenv("ORACLE_HOME", oldHome)
+
+	for _, tc := range []struct {
+		name            string
+		oracleHome      string
+		failBin         string
+		failRelinkHome  string
+		failStartupHome string
+		wantErr         string
+		wantHome        string
+		wantBounces     []string
+		wantCommands    []string
+	}{
+		{
+			name:       "switched",
+			oracleHome: newHome,
+			wantHome:   newHome,
+			wantBounces: []string{
+				"shutdown immediate " + oldHome,
+				"shutdown immediate " + newHome,
+				"startup nomount " + newHome,
+				"shutdown immediate " + newHome,
+				"startup " + newHome,
+			},
+			wantCommands: []string{datapatch(newHome), datapatch(newHome)},
+		},
+		{
+			name:       "datapatch failure rolls back",
+			oracleHome: newHome,
+			failBin:    datapatch(newHome),
+			wantErr:    "rolled back to " + oldHome,
+			wantHome:   oldHome,
+			wantBounces: []string{
+				"shutdown immediate " + oldHome,
+				"shutdown immediate " + newHome,
+				"startup nomount " + newHome,
+				"shutdown abort " + newHome,
+				"startup " + oldHome,
+			},
+			wantCommands: []string{datapatch(newHome), datapatch(oldHome)},
+		},
+		{
+			name:           "relink failure rolls back",
+			oracleHome:     newHome,
+			failRelinkHome: newHome,
+			wantErr:        "rolled back to " + oldHome,
+			wantHome:       oldHome,
+			wantBounces: []string{
+				"shutdown immediate " + oldHome,
+				"shutdown abort " + newHome,
+				"startup " + oldHome,
+			},
+			wantCommands: []string{datapatch(oldHome)},
+		},
+		{
+			name:            "rollback failure",
+			oracleHome:      newHome,
+			failBin:         datapatch(newHome),
+			failStartupHome: oldHome,
+			wantErr:         "rollback to " + oldHome + " failed",
+			wantHome:        oldHome,
+			wantBounces: []string{
+				"shutdown immediate " + oldHome,
+				"shutdown immediate " + newHome,
+				"startup nomount " + newHome,
+				"shutdown abort " + newHome,
+				"startup " + oldHome,
+			},
+			wantCommands: []string{datapatch(newHome)},
+		},
+		{
+			name:       "relative home",
+			oracleHome: "product/19.3/db",
+			wantErr:    "not an absolute path",
+			wantHome:   oldHome,
+		},
+		{
+			name:       "same home",
+			oracleHome: oldHome,
+			wantErr:    "already runs from",
+			wantHome:   oldHome,
+		},
+		{
+			name:       "no datapatch in the home",
+			oracleHome: t.TempDir(),
+			wantErr:    "is not an Oracle home",
+			wantHome:   oldHome,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			s.databaseHome = oldHome
+			proxy := &homeRecordingProxyClient{failStartupHome: tc.failStartupHome}
+			s.dbdClient = proxy
+			osUtil := &failingBinOsUtil{failBin: tc.failBin}
+			s.osUtil = osUtil
+			origRelink := relinkConfigFiles
+			relinkConfigFiles = func(home, cdbName string) error {
+				if home == tc.failRelinkHome {
+					return errors.New("permission denied")
+				}
+				return nil
+			}
+			defer func() { relinkConfigFiles = origRelink }()
+
+			resp, err := s.switchOracleHome(ctx, &dbdpb.SwitchOracleHomeRequest{OracleHome: tc.oracleHome})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("switchOracleHome failed: %v", err)
+				}
+				if got := resp.GetPreviousOracleHome(); got != oldHome {
+					t.Errorf("switchOracleHome got previous home %q, want %q", got, oldHome)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("switchOracleHome got error %v, want an error containing %q", err, tc.wantErr)
+			}
+			if s.databaseHome != tc.wantHome {
+				t.Errorf("switchOracleHome left the database home at %q, want %q", s.databaseHome, tc.wantHome)
+			}
+			if diff := cmp.Diff(tc.wantBounces, proxy.bounces); diff != "" {
+				t.Errorf("switchOracleHome got unexpected bounces (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantCommands, osUtil.commands); diff != "" {
+				t.Errorf("switchOracleHome got unexpected commands (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
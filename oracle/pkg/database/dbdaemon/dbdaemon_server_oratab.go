@@ -0,0 +1,165 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// oratabDatabaseTypePrefix starts the comment GetDatabaseType reads the
+// database type from, e.g. # DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB.
+const oratabDatabaseTypePrefix = "# DATABASETYPE"
+
+var oratabDatabaseTypes = map[string]dbdpb.GetDatabaseTypeResponse_DatabaseType{
+	"ORACLE_12_2_ENTERPRISE":        dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+	"ORACLE_12_2_ENTERPRISE_NONCDB": dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE_NONCDB,
+	"ORACLE_21_ENTERPRISE":          dbdpb.GetDatabaseTypeResponse_ORACLE_21_ENTERPRISE,
+}
+
+// oratabDatabaseType returns the database type of the oratab content. The
+// DATABASETYPE comment takes precedence, without one the type is inferred
+// from the ORACLE_HOME of the entries: a home under a 21.x or 21c directory,
+// e.g. /u01/app/oracle/product/21c/db, is an Oracle 21c home. Anything else
+// defaults to ORACLE_12_2_ENTERPRISE for backward compatibility.
+func oratabDatabaseType(content string) (dbdpb.GetDatabaseTypeResponse_DatabaseType, error) {
+	databaseType := dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, oratabDatabaseTypePrefix):
+			fragment := strings.Split(line, ":")
+			if len(fragment) != 2 {
+				return dbdpb.GetDatabaseTypeResponse_UNKNOWN_DATABASE_TYPE, fmt.Errorf("failed to parse %q for database type(number of fields is %d, not 2)", line, len(fragment))
+			}
+			t, ok := oratabDatabaseTypes[fragment[1]]
+			if !ok {
+				return dbdpb.GetDatabaseTypeResponse_UNKNOWN_DATABASE_TYPE, fmt.Errorf("unknown database type %q", fragment[1])
+			}
+			return t, nil
+		case strings.TrimSpace(line) == "", strings.HasPrefix(strings.TrimSpace(line), "#"):
+		default:
+			fields := strings.Split(strings.TrimSpace(line), ":")
+			if len(fields) >= 2 && isOracle21Home(fields[1]) {
+				databaseType = dbdpb.GetDatabaseTypeResponse_ORACLE_21_ENTERPRISE
+			}
+		}
+	}
+	return databaseType, nil
+}
+
+// isOracle21Home returns whether one of the directories of the ORACLE_HOME
+// is named after the 21c release.
+func isOracle21Home(home string) bool {
+	for _, dir := range strings.Split(filepath.Clean(home), string(filepath.Separator)) {
+		if dir == "21c" || strings.HasPrefix(dir, "21.") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOratab checks the oratab content, which is expected to be of the
+// form:
+// # comments
+// <SID>:<ORACLE_HOME>:<Y|N>
+// # DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB
+func validateOratab(content string) *dbdpb.ValidateOratabResponse {
+	resp := &dbdpb.ValidateOratabResponse{DatabaseTypeMissing: true}
+	issue := func(n int, format string, a ...interface{}) {
+		resp.Issues = append(resp.Issues, fmt.Sprintf("line %d: ", n)+fmt.Sprintf(format, a...))
+	}
+
+	entries := 0
+	for i, line := range strings.Split(content, "\n") {
+		n := i + 1
+		switch {
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, oratabDatabaseTypePrefix):
+			if !resp.DatabaseTypeMissing {
+				issue(n, "duplicate DATABASETYPE comment %q, only the first one is used", line)
+				continue
+			}
+			resp.DatabaseTypeMissing = false
+			fragment := strings.Split(line, ":")
+			if len(fragment) != 2 {
+				issue(n, "expected %s:<type>, got %q", oratabDatabaseTypePrefix, line)
+				continue
+			}
+			databaseType, ok := oratabDatabaseTypes[fragment[1]]
+			if !ok {
+				issue(n, "unknown database type %q", fragment[1])
+				continue
+			}
+			resp.DatabaseType = databaseType
+		case strings.HasPrefix(strings.TrimSpace(line), "#"):
+		default:
+			entries++
+			fields := strings.Split(strings.TrimSpace(line), ":")
+			// Oracle tools may write a trailing colon.
+			if len(fields) == 4 && fields[3] == "" {
+				fields = fields[:3]
+			}
+			if len(fields) != 3 {
+				issue(n, "expected <SID>:<ORACLE_HOME>:<Y|N>, got %q", line)
+				continue
+			}
+			if fields[0] == "" {
+				issue(n, "SID is empty")
+			}
+			if !filepath.IsAbs(fields[1]) {
+				issue(n, "ORACLE_HOME %q is not an absolute path", fields[1])
+			}
+			if fields[2] != "Y" && fields[2] != "N" {
+				issue(n, "startup flag %q is neither Y nor N", fields[2])
+			}
+		}
+	}
+	if entries == 0 {
+		resp.Warnings = append(resp.Warnings, "no database entries found")
+	}
+	return resp
+}
+
+// ValidateOratab checks the format of the oratab file and its DATABASETYPE
+// comment. A malformed file is reported in the response issues, a missing or
+// empty one in the warnings, not as an error.
+func (s *Server) ValidateOratab(ctx context.Context, req *dbdpb.ValidateOratabRequest) (*dbdpb.ValidateOratabResponse, error) {
+	klog.InfoS("dbdaemon/ValidateOratab", "req", loggableRequest(req))
+	content, err := ioutil.ReadFile(consts.OraTab)
+	if os.IsNotExist(err) {
+		klog.InfoS("dbdaemon/ValidateOratab: file not found", "file", consts.OraTab)
+		return &dbdpb.ValidateOratabResponse{
+			DatabaseTypeMissing: true,
+			Warnings:            []string{fmt.Sprintf("%s not found", consts.OraTab)},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ValidateOratab: failed to read %q: %v", consts.OraTab, err)
+	}
+	resp := validateOratab(string(content))
+	if len(resp.GetIssues()) > 0 || len(resp.GetWarnings()) > 0 {
+		klog.InfoS("dbdaemon/ValidateOratab: found issues", "file", consts.OraTab, "issues", resp.GetIssues(), "warnings", resp.GetWarnings())
+	}
+	return resp, nil
+}
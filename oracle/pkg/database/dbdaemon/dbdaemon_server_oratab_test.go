@@ -0,0 +1,162 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestValidateOratab(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *dbdpb.ValidateOratabResponse
+	}{
+		{
+			name: "valid",
+			content: `# This file is used by ORACLE utilities.
+GCLOUD:/u01/app/oracle/product/19.3/db:N
+# DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB
+`,
+			want: &dbdpb.ValidateOratabResponse{DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE_NONCDB},
+		},
+		{
+			name:    "trailing colon",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:Y:\n# DATABASETYPE:ORACLE_12_2_ENTERPRISE\n",
+			want:    &dbdpb.ValidateOratabResponse{DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE},
+		},
+		{
+			name:    "missing database type",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:N\n",
+			want:    &dbdpb.ValidateOratabResponse{DatabaseTypeMissing: true},
+		},
+		{
+			name:    "malformed database type",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:N\n# DATABASETYPE ORACLE_12_2_ENTERPRISE\n",
+			want: &dbdpb.ValidateOratabResponse{
+				Issues: []string{`line 2: expected # DATABASETYPE:<type>, got "# DATABASETYPE ORACLE_12_2_ENTERPRISE"`},
+			},
+		},
+		{
+			name:    "unknown database type",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:N\n# DATABASETYPE:ORACLE_EXPRESS\n",
+			want: &dbdpb.ValidateOratabResponse{
+				Issues: []string{`line 2: unknown database type "ORACLE_EXPRESS"`},
+			},
+		},
+		{
+			name:    "duplicate database type",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:N\n# DATABASETYPE:ORACLE_12_2_ENTERPRISE\n# DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB\n",
+			want: &dbdpb.ValidateOratabResponse{
+				Issues:       []string{`line 3: duplicate DATABASETYPE comment "# DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB", only the first one is used`},
+				DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+			},
+		},
+		{
+			name:    "malformed entries",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db\n:relative/home:maybe\n# DATABASETYPE:ORACLE_12_2_ENTERPRISE\n",
+			want: &dbdpb.ValidateOratabResponse{
+				Issues: []string{
+					`line 1: expected <SID>:<ORACLE_HOME>:<Y|N>, got "GCLOUD:/u01/app/oracle/product/19.3/db"`,
+					"line 2: SID is empty",
+					`line 2: ORACLE_HOME "relative/home" is not an absolute path`,
+					`line 2: startup flag "maybe" is neither Y nor N`,
+				},
+				DatabaseType: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+			},
+		},
+		{
+			name:    "empty",
+			content: "# This file is used by ORACLE utilities.\n",
+			want: &dbdpb.ValidateOratabResponse{
+				DatabaseTypeMissing: true,
+				Warnings:            []string{"no database entries found"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := validateOratab(tc.content)
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("validateOratab(%q) got unexpected response (-want +got):\n%v", tc.content, diff)
+			}
+		})
+	}
+}
+
+func TestOratabDatabaseType(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    dbdpb.GetDatabaseTypeResponse_DatabaseType
+		wantErr bool
+	}{
+		{
+			name:    "database type comment",
+			content: "GCLOUD:/u01/app/oracle/product/21c/db:N\n# DATABASETYPE:ORACLE_12_2_ENTERPRISE_NONCDB\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE_NONCDB,
+		},
+		{
+			name:    "21c database type comment",
+			content: "GCLOUD:/u01/app/oracle/product/21c/db:N\n# DATABASETYPE:ORACLE_21_ENTERPRISE\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_21_ENTERPRISE,
+		},
+		{
+			name:    "21c home",
+			content: "# This file is used by ORACLE utilities.\nGCLOUD:/u01/app/oracle/product/21c/db:N\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_21_ENTERPRISE,
+		},
+		{
+			name:    "21.3 home",
+			content: "GCLOUD:/u01/app/oracle/product/21.3/db:Y:\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_21_ENTERPRISE,
+		},
+		{
+			name:    "19.3 home",
+			content: "GCLOUD:/u01/app/oracle/product/19.3/db:N\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+		},
+		{
+			name:    "home named after 21",
+			content: "GCLOUD:/u01/app/oracle/product/2100/db:N\n",
+			want:    dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+		},
+		{
+			name: "empty",
+			want: dbdpb.GetDatabaseTypeResponse_ORACLE_12_2_ENTERPRISE,
+		},
+		{
+			name:    "unknown database type",
+			content: "GCLOUD:/u01/app/oracle/product/21c/db:N\n# DATABASETYPE:ORACLE_EXPRESS\n",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := oratabDatabaseType(tc.content)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("oratabDatabaseType(%q) got error %v, want error: %v", tc.content, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("oratabDatabaseType(%q) = %v, want %v", tc.content, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// ownerDirPerm and ownerFilePerm are the permissions the oracle user
+	// needs on its directories and files.
+	ownerDirPerm  os.FileMode = 0700
+	ownerFilePerm os.FileMode = 0600
+)
+
+// oracleOwner returns the uid and gid of the oracle user and group which
+// own the files and directories the dbdaemon creates.
+var oracleOwner = func() (int, int, error) {
+	uid, gid, err := oracleUserUIDGID(false)
+	return int(uid), int(gid), err
+}
+
+// fileOwner returns the uid and gid of a file, of a symlink itself rather
+// than of its target.
+func fileOwner(path string) (int, int, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported file info of %s", path)
+	}
+	return int(st.Uid), int(st.Gid), nil
+}
+
+// missingDirs returns the directories of dir, dir included, which don't
+// exist yet, parents first.
+func missingDirs(dir string) []string {
+	var dirs []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || d == filepath.Dir(d) {
+			break
+		}
+		dirs = append([]string{d}, dirs...)
+	}
+	return dirs
+}
+
+// chownToOracle sets the owner of the paths to the oracle user and group.
+func (s *Server) chownToOracle(paths ...string) error {
+	uid, gid, err := oracleOwner()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := s.osUtil.chown(p, uid, gid); err != nil {
+			return fmt.Errorf("failed to chown %s to %d:%d: %v", p, uid, gid, err)
+		}
+	}
+	return nil
+}
+
+// mkdirAllOracle creates a directory with its missing parents owned by the
+// oracle user and group.
+func (s *Server) mkdirAllOracle(dir string, perm os.FileMode) error {
+	dirs := missingDirs(dir)
+	if err := os.MkdirAll(dir, dirPerm(perm)); err != nil {
+		return err
+	}
+	return s.chownToOracle(dirs...)
+}
+
+// createOracleFile creates a file with its missing parent directories owned
+// by the oracle user and group.
+func (s *Server) createOracleFile(file string, content io.Reader) error {
+	dirs := missingDirs(filepath.Dir(file))
+	if err := s.osUtil.createFile(file, content); err != nil {
+		return err
+	}
+	return s.chownToOracle(append(dirs, file)...)
+}
+
+// SetOwnership chowns the paths not owned by the oracle user and group.
+func (s *Server) SetOwnership(ctx context.Context, req *dbdpb.SetOwnershipRequest) (*dbdpb.SetOwnershipResponse, error) {
+	klog.InfoS("dbdaemon/SetOwnership", "req", loggableRequest(req))
+	uid, gid, err := oracleOwner()
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetOwnership: %v", err)
+	}
+	resp := &dbdpb.SetOwnershipResponse{}
+	visited := 0
+	check := func(path string) error {
+		if visited++; visited > maxWalkFiles {
+			return fmt.Errorf("visited more than %v files, try reduce the dir scope", maxWalkFiles)
+		}
+		fileUID, fileGID, err := fileOwner(path)
+		if err != nil {
+			return err
+		}
+		if fileUID == uid && fileGID == gid {
+			return nil
+		}
+		resp.MismatchedPaths = append(resp.MismatchedPaths, path)
+		if req.GetValidateOnly() {
+			return nil
+		}
+		klog.InfoS("dbdaemon/SetOwnership: chown", "path", path, "uid", fileUID, "gid", fileGID, "wantUID", uid, "wantGID", gid)
+		return s.osUtil.chown(path, uid, gid)
+	}
+	for _, p := range req.GetPaths() {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("dbdaemon/SetOwnership: %q is not an absolute path", p)
+		}
+		if !req.GetRecursive() {
+			if err := check(p); err != nil {
+				return nil, fmt.Errorf("dbdaemon/SetOwnership: %v", err)
+			}
+			continue
+		}
+		if err := filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return check(path)
+		}); err != nil {
+			return nil, fmt.Errorf("dbdaemon/SetOwnership: failed to set the ownership of %s: %v", p, err)
+		}
+	}
+	return resp, nil
+}
+
+// oracleDirs returns the data and config directories of a database.
+func oracleDirs(sid string) []string {
+	return []string{fmt.Sprintf(consts.DataDir, consts.DataMount, sid), configDir(sid)}
+}
+
+// fixOwnership recursively chowns the contents of the directories to the
+// oracle user and group and grants the missing owner permissions. It returns
+// the paths it changed, the directories which don't exist are skipped.
+func (s *Server) fixOwnership(dirs []string) ([]string, error) {
+	uid, gid, err := oracleOwner()
+	if err != nil {
+		return nil, err
+	}
+	var fixed []string
+	fix := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		changed := false
+		fileUID, fileGID, err := fileOwner(path)
+		if err != nil {
+			return err
+		}
+		if fileUID != uid || fileGID != gid {
+			if err := s.osUtil.chown(path, uid, gid); err != nil {
+				return fmt.Errorf("failed to chown %s to %d:%d: %v", path, uid, gid, err)
+			}
+			changed = true
+		}
+		perm := ownerFilePerm
+		if info.IsDir() {
+			perm = ownerDirPerm
+		}
+		// The permissions of a symlink are those of its target.
+		if info.Mode()&os.ModeSymlink == 0 && info.Mode().Perm()&perm != perm {
+			if err := os.Chmod(path, info.Mode().Perm()|perm); err != nil {
+				return fmt.Errorf("failed to chmod %s: %v", path, err)
+			}
+			changed = true
+		}
+		if changed {
+			fixed = append(fixed, path)
+		}
+		return nil
+	}
+	for _, dir := range dirs {
+		if _, err := os.Lstat(dir); os.IsNotExist(err) {
+			klog.InfoS("dbdaemon/fixOwnership: skipping a missing dir", "dir", dir)
+			continue
+		}
+		if err := filepath.Walk(dir, fix); err != nil {
+			return fixed, fmt.Errorf("failed to fix the ownership of %s: %v", dir, err)
+		}
+	}
+	klog.InfoS("dbdaemon/fixOwnership: done", "dirs", dirs, "fixedPaths", len(fixed))
+	return fixed, nil
+}
+
+// FixOwnership recursively restores the oracle ownership and the owner
+// permissions of the data and config directories of the database or of the
+// requested directories.
+func (s *Server) FixOwnership(ctx context.Context, req *dbdpb.FixOwnershipRequest) (*dbdpb.FixOwnershipResponse, error) {
+	klog.InfoS("dbdaemon/FixOwnership", "req", loggableRequest(req))
+	dirs := req.GetDirs()
+	for _, d := range dirs {
+		if !filepath.IsAbs(d) {
+			return nil, fmt.Errorf("dbdaemon/FixOwnership: %q is not an absolute path", d)
+		}
+	}
+	if len(dirs) == 0 {
+		s.databaseSid.RLock()
+		dirs = oracleDirs(s.databaseSid.val)
+		s.databaseSid.RUnlock()
+	}
+	fixed, err := s.fixOwnership(dirs)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/FixOwnership: %v", err)
+	}
+	return &dbdpb.FixOwnershipResponse{FixedPaths: fixed}, nil
+}
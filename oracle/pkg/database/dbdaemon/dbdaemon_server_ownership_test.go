@@ -0,0 +1,237 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// useOracleOwner makes uid and gid the oracle user and group and returns
+// them as uid:gid.
+func useOracleOwner(t *testing.T, uid, gid int) string {
+	t.Helper()
+	orig := oracleOwner
+	oracleOwner = func() (int, int, error) { return uid, gid, nil }
+	t.Cleanup(func() { oracleOwner = orig })
+	return fmt.Sprintf("%d:%d", uid, gid)
+}
+
+func TestMissingDirs(t *testing.T) {
+	dir := t.TempDir()
+	got := missingDirs(filepath.Join(dir, "a", "b"))
+	want := []string{filepath.Join(dir, "a"), filepath.Join(dir, "a", "b")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("missingDirs got unexpected dirs (-want +got):\n%v", diff)
+	}
+	if got := missingDirs(dir); len(got) != 0 {
+		t.Errorf("missingDirs(%q) got %v, want none", dir, got)
+	}
+}
+
+func TestCreatedArtifactsOwnership(t *testing.T) {
+	ctx := context.Background()
+	owner := useOracleOwner(t, os.Getuid()+54321, os.Getgid()+54321)
+	dir := t.TempDir()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	osUtil := s.osUtil.(*mockOsUtil)
+
+	file := filepath.Join(dir, "wallet", "cwallet.sso")
+	if _, err := s.CreateFile(ctx, &dbdpb.CreateFileRequest{Path: file, Content: "secret"}); err != nil {
+		t.Fatalf("CreateFile failed: %v", err)
+	}
+	if _, err := s.CreateDirs(ctx, &dbdpb.CreateDirsRequest{
+		Dirs: []*dbdpb.CreateDirsRequest_DirInfo{{Path: filepath.Join(dir, "dump", "pdb1"), Perm: 0750}},
+	}); err != nil {
+		t.Fatalf("CreateDirs failed: %v", err)
+	}
+
+	// The mocked createFile creates nothing, the parent of the file is
+	// chowned as missing.
+	want := map[string]string{
+		filepath.Join(dir, "wallet"):       owner,
+		file:                               owner,
+		filepath.Join(dir, "dump"):         owner,
+		filepath.Join(dir, "dump", "pdb1"): owner,
+	}
+	if diff := cmp.Diff(want, osUtil.chowned); diff != "" {
+		t.Errorf("created artifacts got unexpected owners (-want +got):\n%v", diff)
+	}
+	if diff := cmp.Diff([]string{file}, osUtil.created); diff != "" {
+		t.Errorf("CreateFile created unexpected files (-want +got):\n%v", diff)
+	}
+}
+
+func TestSetOwnership(t *testing.T) {
+	ctx := context.Background()
+	owner := useOracleOwner(t, os.Getuid()+54321, os.Getgid()+54321)
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0750); err != nil {
+		t.Fatalf("failed to create %s: %v", sub, err)
+	}
+	file := filepath.Join(sub, "init.ora")
+	if err := os.WriteFile(file, []byte("db_name=GCLOUD"), 0640); err != nil {
+		t.Fatalf("failed to create %s: %v", file, err)
+	}
+
+	tests := []struct {
+		name        string
+		req         *dbdpb.SetOwnershipRequest
+		wantPaths   []string
+		wantChowned map[string]string
+	}{
+		{
+			name:        "path only",
+			req:         &dbdpb.SetOwnershipRequest{Paths: []string{sub}},
+			wantPaths:   []string{sub},
+			wantChowned: map[string]string{sub: owner},
+		},
+		{
+			name:        "recursive",
+			req:         &dbdpb.SetOwnershipRequest{Paths: []string{dir}, Recursive: true},
+			wantPaths:   []string{dir, sub, file},
+			wantChowned: map[string]string{dir: owner, sub: owner, file: owner},
+		},
+		{
+			name:      "validate only",
+			req:       &dbdpb.SetOwnershipRequest{Paths: []string{dir}, Recursive: true, ValidateOnly: true},
+			wantPaths: []string{dir, sub, file},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			resp, err := s.SetOwnership(ctx, tc.req)
+			if err != nil {
+				t.Fatalf("SetOwnership failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantPaths, resp.GetMismatchedPaths()); diff != "" {
+				t.Errorf("SetOwnership got unexpected mismatched paths (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantChowned, s.osUtil.(*mockOsUtil).chowned); diff != "" {
+				t.Errorf("SetOwnership chowned unexpected paths (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	if _, err := s.SetOwnership(ctx, &dbdpb.SetOwnershipRequest{Paths: []string{"relative/path"}}); err == nil {
+		t.Errorf("SetOwnership with a relative path succeeded, want error")
+	}
+}
+
+func TestFixOwnership(t *testing.T) {
+	ctx := context.Background()
+	// newTree creates a datafile the owner can't write in a data dir the
+	// owner can't write, next to a control file with correct permissions.
+	newTree := func(t *testing.T) (dir, dataDir, datafile, controlFile string) {
+		dir = t.TempDir()
+		dataDir = filepath.Join(dir, "oradata", "GCLOUD")
+		if err := os.MkdirAll(dataDir, 0750); err != nil {
+			t.Fatalf("failed to create %s: %v", dataDir, err)
+		}
+		datafile = filepath.Join(dataDir, "system01.dbf")
+		controlFile = filepath.Join(dataDir, "control01.ctl")
+		for f, perm := range map[string]os.FileMode{datafile: 0440, controlFile: 0640} {
+			if err := os.WriteFile(f, []byte("data"), 0600); err != nil {
+				t.Fatalf("failed to create %s: %v", f, err)
+			}
+			if err := os.Chmod(f, perm); err != nil {
+				t.Fatalf("failed to chmod %s: %v", f, err)
+			}
+		}
+		if err := os.Chmod(dataDir, 0550); err != nil {
+			t.Fatalf("failed to chmod %s: %v", dataDir, err)
+		}
+		return dir, dataDir, datafile, controlFile
+	}
+
+	t.Run("other owner", func(t *testing.T) {
+		owner := useOracleOwner(t, os.Getuid()+54321, os.Getgid()+54321)
+		dir, dataDir, datafile, controlFile := newTree(t)
+		oradata := filepath.Dir(dataDir)
+		s, err := NewMockServer(ctx, "")
+		if err != nil {
+			t.Fatalf("error calling New: %v", err)
+		}
+		resp, err := s.FixOwnership(ctx, &dbdpb.FixOwnershipRequest{Dirs: []string{oradata, filepath.Join(dir, "missing")}})
+		if err != nil {
+			t.Fatalf("FixOwnership failed: %v", err)
+		}
+		wantPaths := []string{oradata, dataDir, controlFile, datafile}
+		if diff := cmp.Diff(wantPaths, resp.GetFixedPaths()); diff != "" {
+			t.Errorf("FixOwnership got unexpected fixed paths (-want +got):\n%v", diff)
+		}
+		wantChowned := map[string]string{oradata: owner, dataDir: owner, controlFile: owner, datafile: owner}
+		if diff := cmp.Diff(wantChowned, s.osUtil.(*mockOsUtil).chowned); diff != "" {
+			t.Errorf("FixOwnership chowned unexpected paths (-want +got):\n%v", diff)
+		}
+	})
+
+	t.Run("permissions only", func(t *testing.T) {
+		useOracleOwner(t, os.Getuid(), os.Getgid())
+		dir, dataDir, datafile, controlFile := newTree(t)
+		s, err := NewMockServer(ctx, "")
+		if err != nil {
+			t.Fatalf("error calling New: %v", err)
+		}
+		resp, err := s.FixOwnership(ctx, &dbdpb.FixOwnershipRequest{Dirs: []string{dir}})
+		if err != nil {
+			t.Fatalf("FixOwnership failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{dataDir, datafile}, resp.GetFixedPaths()); diff != "" {
+			t.Errorf("FixOwnership got unexpected fixed paths (-want +got):\n%v", diff)
+		}
+		if got := s.osUtil.(*mockOsUtil).chowned; len(got) != 0 {
+			t.Errorf("FixOwnership chowned %v, want none", got)
+		}
+		for path, want := range map[string]os.FileMode{dataDir: 0750, datafile: 0640, controlFile: 0640} {
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat %s: %v", path, err)
+			}
+			if got := fi.Mode().Perm(); got != want {
+				t.Errorf("FixOwnership set the permissions of %s to %v, want %v", path, got, want)
+			}
+		}
+	})
+
+	t.Run("relative dir", func(t *testing.T) {
+		s, err := NewMockServer(ctx, "")
+		if err != nil {
+			t.Fatalf("error calling New: %v", err)
+		}
+		if _, err := s.FixOwnership(ctx, &dbdpb.FixOwnershipRequest{Dirs: []string{"oradata"}}); err == nil {
+			t.Error("FixOwnership succeeded for a relative dir, want an error")
+		}
+	})
+}
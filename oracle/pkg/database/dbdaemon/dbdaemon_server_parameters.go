@@ -0,0 +1,374 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// normalizePfileName is the pfile dumped from the spfile to inspect the
+	// parameter scoping.
+	normalizePfileName = "pfile.normalize"
+
+	// allSIDs is the scope of parameters which apply to every instance.
+	allSIDs = "*"
+
+	// exportParametersSQL selects the parameters not set to their defaults.
+	// Hidden (underscore) parameters are left out as they need Oracle
+	// Support guidance to be set on another database.
+	exportParametersSQL = "select name, value, type, issys_modifiable from v$parameter " +
+		"where isdefault = 'FALSE' and value is not null and name not like '\\_%' escape '\\' order by name"
+
+	// stringParameterType is the v$parameter type of string parameters.
+	stringParameterType = "2"
+
+	// currentParametersSQL selects all the parameters exportParametersSQL
+	// may return, including those set to their defaults.
+	currentParametersSQL = "select name, value, issys_modifiable, isdefault from v$parameter " +
+		"where name not like '\\_%' escape '\\' order by name"
+)
+
+// pfileParameter is a <sid>.<name>=<value> entry of a pfile.
+type pfileParameter struct {
+	sid   string
+	name  string
+	value string
+}
+
+// parsePfile returns the parameters of a pfile in order. Entries without a
+// SID prefix apply to every instance. Values may span several lines, either
+// within a quoted string or as a list continued after a trailing comma or
+// on a line starting with one, and quoted strings may contain '=' and '#'.
+func parsePfile(content string) ([]pfileParameter, error) {
+	var entries []string
+	for _, line := range strings.Split(content, "\n") {
+		var quote rune
+		n := len(entries)
+		if n > 0 {
+			quote = openQuote(entries[n-1])
+		}
+		line = strings.TrimSpace(stripPfileComment(line, quote))
+		if line == "" {
+			continue
+		}
+		if n > 0 && (quote != 0 || strings.HasSuffix(entries[n-1], ",") || strings.HasPrefix(line, ",")) {
+			entries[n-1] += line
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	var params []pfileParameter
+	for _, entry := range entries {
+		if openQuote(entry) != 0 {
+			return nil, fmt.Errorf("failed to parse pfile entry %q: unterminated quoted string", entry)
+		}
+		key, value, found := strings.Cut(entry, "=")
+		if !found || strings.ContainsAny(key, `'"`) {
+			return nil, fmt.Errorf("failed to parse pfile entry %q", entry)
+		}
+		sid, name, found := strings.Cut(strings.TrimSpace(key), ".")
+		if !found {
+			sid, name = allSIDs, sid
+		}
+		param := pfileParameter{sid: sid, name: strings.ToLower(name), value: strings.TrimSpace(value)}
+		if param.sid == "" || param.name == "" {
+			return nil, fmt.Errorf("failed to parse pfile entry %q", entry)
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// stripPfileComment removes a '#' comment which is not within a quoted
+// string from a pfile line. quote is the quote left open by the previous
+// lines, if any.
+func stripPfileComment(line string, quote rune) string {
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// openQuote returns the quote s ends within, or 0 if all its quoted strings
+// are terminated. Doubled quotes, which escape a quote, cancel out.
+func openQuote(s string) rune {
+	var quote rune
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		}
+	}
+	return quote
+}
+
+// normalizeParameters scopes the parameters to sid='*' with the values in
+// effect for the instance sid, where an entry for the instance overrides
+// sid='*' and the last of duplicate entries wins. Entries of other SIDs are
+// dropped. It returns the normalized parameters and the ALTER SYSTEM
+// commands which normalize the spfile. Memory sizes auto-tuned per instance
+// (__ prefix) are left alone.
+func normalizeParameters(params []pfileParameter, sid string) ([]pfileParameter, []string) {
+	var names []string
+	entries := make(map[string][]pfileParameter)
+	for _, param := range params {
+		if _, ok := entries[param.name]; !ok {
+			names = append(names, param.name)
+		}
+		entries[param.name] = append(entries[param.name], param)
+	}
+
+	var normalized []pfileParameter
+	var statements []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "__") {
+			normalized = append(normalized, entries[name]...)
+			continue
+		}
+		var all, instance *pfileParameter
+		// scopedSIDs are the SIDs of entries to reset.
+		var scopedSIDs []string
+		for i, param := range entries[name] {
+			if param.sid == allSIDs {
+				all = &entries[name][i]
+				continue
+			}
+			if strings.EqualFold(param.sid, sid) {
+				instance = &entries[name][i]
+			}
+			scopedSIDs = appendUnique(scopedSIDs, param.sid)
+		}
+
+		effective := all
+		if instance != nil {
+			effective = instance
+		}
+		if effective != nil {
+			normalized = append(normalized, pfileParameter{sid: allSIDs, name: name, value: effective.value})
+			if all == nil || all.value != effective.value {
+				statements = append(statements, fmt.Sprintf("alter system set %s=%s scope=spfile sid='*'", name, effective.value))
+			}
+		}
+		for _, scoped := range scopedSIDs {
+			statements = append(statements, fmt.Sprintf("alter system reset %s scope=spfile sid='%s'", name, scoped))
+		}
+	}
+	return normalized, statements
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, e := range list {
+		if e == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// parseExportedParameters returns the parameters of the
+// exportParametersSQL rows.
+func parseExportedParameters(rows []string) ([]*dbdpb.ExportParametersResponse_Parameter, error) {
+	var params []*dbdpb.ExportParametersResponse_Parameter
+	for _, r := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(r), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse parameter row %q: %v", r, err)
+		}
+		if row["NAME"] == "" {
+			return nil, fmt.Errorf("missing NAME in parameter row %q", r)
+		}
+		params = append(params, &dbdpb.ExportParametersResponse_Parameter{
+			Name:       row["NAME"],
+			Value:      row["VALUE"],
+			Static:     row["ISSYS_MODIFIABLE"] == "FALSE",
+			StringType: row["TYPE"] == stringParameterType,
+		})
+	}
+	return params, nil
+}
+
+// ExportParameters returns the parameters which are not set to their
+// default values, sorted by name.
+func (s *Server) ExportParameters(ctx context.Context, req *dbdpb.ExportParametersRequest) (*dbdpb.ExportParametersResponse, error) {
+	klog.InfoS("dbdaemon/ExportParameters", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{exportParametersSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportParameters: failed to query parameters: %v", err)
+	}
+	params, err := parseExportedParameters(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportParameters: %v", err)
+	}
+	return &dbdpb.ExportParametersResponse{Parameters: params}, nil
+}
+
+// currentParameter is a currentParametersSQL row.
+type currentParameter struct {
+	value     string
+	static    bool
+	isDefault bool
+}
+
+// parseCurrentParameters returns the currentParametersSQL rows by name.
+func parseCurrentParameters(rows []string) (map[string]currentParameter, error) {
+	params := make(map[string]currentParameter, len(rows))
+	for _, r := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(r), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse parameter row %q: %v", r, err)
+		}
+		if row["NAME"] == "" {
+			return nil, fmt.Errorf("missing NAME in parameter row %q", r)
+		}
+		params[row["NAME"]] = currentParameter{
+			value:     row["VALUE"],
+			static:    row["ISSYS_MODIFIABLE"] == "FALSE",
+			isDefault: row["ISDEFAULT"] == "TRUE",
+		}
+	}
+	return params, nil
+}
+
+// diffParameters compares the current parameters with a baseline. The
+// changes are sorted by name.
+func diffParameters(baseline []*dbdpb.ExportParametersResponse_Parameter, current map[string]currentParameter) *dbdpb.DiffParametersAgainstBaselineResponse {
+	resp := &dbdpb.DiffParametersAgainstBaselineResponse{}
+	inBaseline := make(map[string]bool, len(baseline))
+	for _, b := range baseline {
+		inBaseline[b.GetName()] = true
+		c, ok := current[b.GetName()]
+		switch {
+		case !ok:
+			// The parameter is obsolete in the current version.
+			resp.Removed = append(resp.Removed, &dbdpb.DiffParametersAgainstBaselineResponse_Change{
+				Name: b.GetName(), BaselineValue: b.GetValue(), Static: b.GetStatic(),
+			})
+		case c.isDefault:
+			resp.Removed = append(resp.Removed, &dbdpb.DiffParametersAgainstBaselineResponse_Change{
+				Name: b.GetName(), BaselineValue: b.GetValue(), CurrentValue: c.value, Static: c.static,
+			})
+		case c.value != b.GetValue():
+			resp.Changed = append(resp.Changed, &dbdpb.DiffParametersAgainstBaselineResponse_Change{
+				Name: b.GetName(), BaselineValue: b.GetValue(), CurrentValue: c.value, Static: c.static,
+			})
+		}
+	}
+	for name, c := range current {
+		if inBaseline[name] || c.isDefault || c.value == "" {
+			continue
+		}
+		resp.Added = append(resp.Added, &dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			Name: name, CurrentValue: c.value, Static: c.static,
+		})
+	}
+	for _, changes := range [][]*dbdpb.DiffParametersAgainstBaselineResponse_Change{resp.Added, resp.Removed, resp.Changed} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].GetName() < changes[j].GetName() })
+	}
+	return resp
+}
+
+// DiffParametersAgainstBaseline compares the parameters in effect with a
+// baseline saved from ExportParameters. Parameters of the baseline reset to
+// their defaults are removed, and non-default parameters missing from the
+// baseline are added.
+func (s *Server) DiffParametersAgainstBaseline(ctx context.Context, req *dbdpb.DiffParametersAgainstBaselineRequest) (*dbdpb.DiffParametersAgainstBaselineResponse, error) {
+	klog.InfoS("dbdaemon/DiffParametersAgainstBaseline", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{currentParametersSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/DiffParametersAgainstBaseline: failed to query parameters: %v", err)
+	}
+	current, err := parseCurrentParameters(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/DiffParametersAgainstBaseline: %v", err)
+	}
+	diff := diffParameters(req.GetBaseline().GetParameters(), current)
+	klog.InfoS("dbdaemon/DiffParametersAgainstBaseline: done", "added", len(diff.GetAdded()), "removed", len(diff.GetRemoved()), "changed", len(diff.GetChanged()))
+	return diff, nil
+}
+
+// NormalizeParameters rewrites the spfile so that every parameter is scoped
+// to sid='*' with the value in effect for the instance. The values do not
+// change, so no restart is needed.
+func (s *Server) NormalizeParameters(ctx context.Context, req *dbdpb.NormalizeParametersRequest) (*dbdpb.NormalizeParametersResponse, error) {
+	klog.InfoS("dbdaemon/NormalizeParameters", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	pfile := filepath.Join(configDir(s.databaseSid.val), normalizePfileName)
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf("create pfile='%s' from spfile", pfile)}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/NormalizeParameters: failed to create pfile: %v", err)
+	}
+	defer func() {
+		if err := os.Remove(pfile); err != nil {
+			klog.ErrorS(err, "dbdaemon/NormalizeParameters: failed to remove pfile", "pfile", pfile)
+		}
+	}()
+
+	content, err := ioutil.ReadFile(pfile)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/NormalizeParameters: failed to read pfile: %v", err)
+	}
+	params, err := parsePfile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/NormalizeParameters: %v", err)
+	}
+	_, statements := normalizeParameters(params, s.databaseSid.val)
+	if len(statements) == 0 {
+		return &dbdpb.NormalizeParametersResponse{}, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/NormalizeParameters: failed to normalize parameters: %v", err)
+	}
+	klog.InfoS("dbdaemon/NormalizeParameters: normalized parameters", "statements", statements)
+	return &dbdpb.NormalizeParametersResponse{Statements: statements}, nil
+}
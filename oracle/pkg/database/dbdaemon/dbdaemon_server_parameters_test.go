@@ -0,0 +1,243 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const conflictingPfile = `GCLOUD.__db_cache_size=1073741824
+GCLOUD.__shared_pool_size=452984832
+*.audit_file_dest='/u01/app/oracle/admin/GCLOUD/adump'
+*.db_name='GCLOUD'
+GCLOUD.undo_tablespace='UNDOTBS1'
+*.undo_tablespace='UNDOTBS2'
+gcloud.open_cursors=500
+*.processes=300
+OLDSID.processes=150
+OLDSID.sga_target=2G
+*.db_name='GCLOUD'
+*.db_recovery_file_dest_size=10G
+*.db_recovery_file_dest_size=20G
+`
+
+func TestParsePfile(t *testing.T) {
+	got, err := parsePfile("# comment\n*.db_name='GCLOUD'\n\nGCLOUD.Open_Cursors=500\ncontrol_files='/u02/control01.ctl','/u03/control02.ctl'\n")
+	if err != nil {
+		t.Fatalf("parsePfile failed: %v", err)
+	}
+	want := []pfileParameter{
+		{sid: "*", name: "db_name", value: "'GCLOUD'"},
+		{sid: "GCLOUD", name: "open_cursors", value: "500"},
+		{sid: "*", name: "control_files", value: "'/u02/control01.ctl','/u03/control02.ctl'"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(pfileParameter{})); diff != "" {
+		t.Errorf("parsePfile got unexpected parameters (-want +got):\n%v", diff)
+	}
+
+	for _, content := range []string{"db_name", "=500", ".open_cursors=500", "*.db_name='GCLOUD", "'LOCATION=/u03'"} {
+		if _, err := parsePfile(content); err == nil {
+			t.Errorf("parsePfile(%q) succeeded, want error", content)
+		}
+	}
+}
+
+func TestParsePfileContinuedValues(t *testing.T) {
+	content := `*.control_files='/u02/app/oracle/oradata/GCLOUD/control01.ctl',
+'/u03/app/oracle/fast_recovery_area/GCLOUD/control02.ctl' # mirrored
+*.db_file_name_convert='/u02/old/','/u02/new/'
+	,'/u03/old/','/u03/new/'
+*.log_archive_dest_1='LOCATION=USE_DB_RECOVERY_FILE_DEST VALID_FOR=(ALL_LOGFILES,ALL_ROLES)'
+*.log_archive_format='%t_%s_%r.dbf'
+*.dispatchers='(PROTOCOL=TCP) (SERVICE=GCLOUDXDB)'
+*.utl_file_dir='/tmp/a#b'
+*.log_archive_config='dg_config=(GCLOUD,
+#GCLOUD_STBY)'
+`
+	got, err := parsePfile(content)
+	if err != nil {
+		t.Fatalf("parsePfile failed: %v", err)
+	}
+	want := []pfileParameter{
+		{sid: "*", name: "control_files", value: "'/u02/app/oracle/oradata/GCLOUD/control01.ctl','/u03/app/oracle/fast_recovery_area/GCLOUD/control02.ctl'"},
+		{sid: "*", name: "db_file_name_convert", value: "'/u02/old/','/u02/new/','/u03/old/','/u03/new/'"},
+		{sid: "*", name: "log_archive_dest_1", value: "'LOCATION=USE_DB_RECOVERY_FILE_DEST VALID_FOR=(ALL_LOGFILES,ALL_ROLES)'"},
+		{sid: "*", name: "log_archive_format", value: "'%t_%s_%r.dbf'"},
+		{sid: "*", name: "dispatchers", value: "'(PROTOCOL=TCP) (SERVICE=GCLOUDXDB)'"},
+		{sid: "*", name: "utl_file_dir", value: "'/tmp/a#b'"},
+		{sid: "*", name: "log_archive_config", value: "'dg_config=(GCLOUD,#GCLOUD_STBY)'"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(pfileParameter{})); diff != "" {
+		t.Errorf("parsePfile got unexpected parameters (-want +got):\n%v", diff)
+	}
+}
+
+func TestNormalizeParameters(t *testing.T) {
+	params, err := parsePfile(conflictingPfile)
+	if err != nil {
+		t.Fatalf("parsePfile failed: %v", err)
+	}
+	normalized, statements := normalizeParameters(params, "GCLOUD")
+
+	wantNormalized := []pfileParameter{
+		{sid: "GCLOUD", name: "__db_cache_size", value: "1073741824"},
+		{sid: "GCLOUD", name: "__shared_pool_size", value: "452984832"},
+		{sid: "*", name: "audit_file_dest", value: "'/u01/app/oracle/admin/GCLOUD/adump'"},
+		{sid: "*", name: "db_name", value: "'GCLOUD'"},
+		{sid: "*", name: "undo_tablespace", value: "'UNDOTBS1'"},
+		{sid: "*", name: "open_cursors", value: "500"},
+		{sid: "*", name: "processes", value: "300"},
+		{sid: "*", name: "db_recovery_file_dest_size", value: "20G"},
+	}
+	if diff := cmp.Diff(wantNormalized, normalized, cmp.AllowUnexported(pfileParameter{})); diff != "" {
+		t.Errorf("normalizeParameters got unexpected parameters (-want +got):\n%v", diff)
+	}
+
+	wantStatements := []string{
+		"alter system set undo_tablespace='UNDOTBS1' scope=spfile sid='*'",
+		"alter system reset undo_tablespace scope=spfile sid='GCLOUD'",
+		"alter system set open_cursors=500 scope=spfile sid='*'",
+		"alter system reset open_cursors scope=spfile sid='gcloud'",
+		"alter system reset processes scope=spfile sid='OLDSID'",
+		"alter system reset sga_target scope=spfile sid='OLDSID'",
+	}
+	if diff := cmp.Diff(wantStatements, statements); diff != "" {
+		t.Errorf("normalizeParameters got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	// Normalized parameters need no further changes.
+	if _, statements := normalizeParameters(normalized, "GCLOUD"); len(statements) != 0 {
+		t.Errorf("normalizeParameters of normalized parameters got statements %q, want none", statements)
+	}
+}
+
+func TestExportParameters(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 1 || sqls[0] != exportParametersSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{
+			`{"NAME":"db_files","VALUE":"400","TYPE":"3","ISSYS_MODIFIABLE":"FALSE"}`,
+			`{"NAME":"nls_date_format","VALUE":"YYYY-MM-DD","TYPE":"2","ISSYS_MODIFIABLE":"FALSE"}`,
+			`{"NAME":"open_cursors","VALUE":"500","TYPE":"3","ISSYS_MODIFIABLE":"IMMEDIATE"}`,
+			`{"NAME":"resource_manager_plan","VALUE":"DEFAULT_PLAN","TYPE":"2","ISSYS_MODIFIABLE":"IMMEDIATE"}`,
+		}, nil
+	}
+
+	resp, err := s.ExportParameters(ctx, &dbdpb.ExportParametersRequest{})
+	if err != nil {
+		t.Fatalf("ExportParameters failed: %v", err)
+	}
+	want := &dbdpb.ExportParametersResponse{
+		Parameters: []*dbdpb.ExportParametersResponse_Parameter{
+			{Name: "db_files", Value: "400", Static: true},
+			{Name: "nls_date_format", Value: "YYYY-MM-DD", Static: true, StringType: true},
+			{Name: "open_cursors", Value: "500"},
+			{Name: "resource_manager_plan", Value: "DEFAULT_PLAN", StringType: true},
+		},
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ExportParameters got unexpected response (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseExportedParameters([]string{`{"VALUE":"500"}`}); err == nil {
+		t.Errorf("parseExportedParameters of a row without NAME succeeded, want error")
+	}
+}
+
+func TestDiffParametersAgainstBaseline(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 1 || sqls[0] != currentParametersSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{
+			`{"NAME":"db_files","VALUE":"400","ISSYS_MODIFIABLE":"FALSE","ISDEFAULT":"FALSE"}`,
+			`{"NAME":"nls_date_format","VALUE":"DD-MON-RR","ISSYS_MODIFIABLE":"FALSE","ISDEFAULT":"FALSE"}`,
+			`{"NAME":"open_cursors","VALUE":"300","ISSYS_MODIFIABLE":"IMMEDIATE","ISDEFAULT":"TRUE"}`,
+			`{"NAME":"processes","VALUE":"600","ISSYS_MODIFIABLE":"FALSE","ISDEFAULT":"FALSE"}`,
+			`{"NAME":"resource_manager_plan","VALUE":"DEFAULT_PLAN","ISSYS_MODIFIABLE":"IMMEDIATE","ISDEFAULT":"FALSE"}`,
+			`{"NAME":"sga_target","VALUE":"0","ISSYS_MODIFIABLE":"IMMEDIATE","ISDEFAULT":"TRUE"}`,
+			`{"NAME":"utl_file_dir","ISSYS_MODIFIABLE":"FALSE","ISDEFAULT":"FALSE"}`,
+		}, nil
+	}
+
+	resp, err := s.DiffParametersAgainstBaseline(ctx, &dbdpb.DiffParametersAgainstBaselineRequest{
+		Baseline: &dbdpb.ExportParametersResponse{
+			Parameters: []*dbdpb.ExportParametersResponse_Parameter{
+				{Name: "db_files", Value: "400", Static: true},
+				{Name: "nls_date_format", Value: "YYYY-MM-DD", Static: true, StringType: true},
+				{Name: "open_cursors", Value: "500"},
+				{Name: "resource_manager_plan", Value: "DEFAULT_PLAN", StringType: true},
+				{Name: "sec_case_sensitive_logon", Value: "FALSE", Static: true},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DiffParametersAgainstBaseline failed: %v", err)
+	}
+	want := &dbdpb.DiffParametersAgainstBaselineResponse{
+		Added: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "processes", CurrentValue: "600", Static: true},
+		},
+		Removed: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "open_cursors", BaselineValue: "500", CurrentValue: "300"},
+			{Name: "sec_case_sensitive_logon", BaselineValue: "FALSE", Static: true},
+		},
+		Changed: []*dbdpb.DiffParametersAgainstBaselineResponse_Change{
+			{Name: "nls_date_format", BaselineValue: "YYYY-MM-DD", CurrentValue: "DD-MON-RR", Static: true},
+		},
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("DiffParametersAgainstBaseline got unexpected response (-want +got):\n%v", diff)
+	}
+
+	resp, err = s.DiffParametersAgainstBaseline(ctx, &dbdpb.DiffParametersAgainstBaselineRequest{})
+	if err != nil {
+		t.Fatalf("DiffParametersAgainstBaseline without a baseline failed: %v", err)
+	}
+	var added []string
+	for _, c := range resp.GetAdded() {
+		added = append(added, c.GetName())
+	}
+	if diff := cmp.Diff([]string{"db_files", "nls_date_format", "processes", "resource_manager_plan"}, added); diff != "" {
+		t.Errorf("DiffParametersAgainstBaseline without a baseline got unexpected added parameters (-want +got):\n%v", diff)
+	}
+	if len(resp.GetRemoved()) != 0 || len(resp.GetChanged()) != 0 {
+		t.Errorf("DiffParametersAgainstBaseline without a baseline got removed %v and changed %v, want none", resp.GetRemoved(), resp.GetChanged())
+	}
+
+	if _, err := parseCurrentParameters([]string{`{"VALUE":"500"}`}); err == nil {
+		t.Errorf("parseCurrentParameters of a row without NAME succeeded, want error")
+	}
+}
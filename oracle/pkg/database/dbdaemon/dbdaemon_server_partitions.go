@@ -0,0 +1,279 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// partitioningOptionSQL reports whether the edition of the database
+	// comes with the Partitioning option, which requires Enterprise Edition.
+	partitioningOptionSQL = "select value from v$option where parameter='Partitioning'"
+
+	// partitionsSQL lists the partitions of a table, interval tells whether
+	// a partition was created by interval partitioning and
+	// interval_partitioned whether the table is interval partitioned.
+	partitionsSQL = "select p.partition_name, p.partition_position, p.high_value, p.interval, " +
+		"nvl2(t.interval, 'YES', 'NO') interval_partitioned " +
+		"from dba_tab_partitions p join dba_part_tables t on t.owner=p.table_owner and t.table_name=p.table_name " +
+		"where p.table_owner='%s' and p.table_name='%s' order by p.partition_position"
+
+	partitionDateLayout     = "2006-01-02"
+	partitionDateTimeLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	integerBound = regexp.MustCompile(`^-?[0-9]+$`)
+
+	intervals = map[dbdpb.MaintainPartitionsRequest_Interval]string{
+		dbdpb.MaintainPartitionsRequest_NONE:  "",
+		dbdpb.MaintainPartitionsRequest_DAY:   "numtodsinterval(1, 'DAY')",
+		dbdpb.MaintainPartitionsRequest_WEEK:  "numtodsinterval(7, 'DAY')",
+		dbdpb.MaintainPartitionsRequest_MONTH: "numtoyminterval(1, 'MONTH')",
+		dbdpb.MaintainPartitionsRequest_YEAR:  "numtoyminterval(1, 'YEAR')",
+	}
+)
+
+// partition is a partition of a table as listed by partitionsSQL.
+type partition struct {
+	name     string
+	position int
+	// highValue is the upper bound of the partition as an SQL expression.
+	highValue string
+	// interval is set if the partition was created by interval partitioning.
+	interval bool
+}
+
+// partitionBound converts an integer or a date as YYYY-MM-DD or
+// YYYY-MM-DD HH:MM:SS into a partition bound expression.
+func partitionBound(v string) (string, error) {
+	if integerBound.MatchString(v) {
+		return v, nil
+	}
+	for _, layout := range []string{partitionDateLayout, partitionDateTimeLayout} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return fmt.Sprintf("to_date('%s', 'YYYY-MM-DD HH24:MI:SS')", t.Format(partitionDateTimeLayout)), nil
+		}
+	}
+	return "", fmt.Errorf("partition bound %q is neither an integer nor a date as YYYY-MM-DD or YYYY-MM-DD HH:MM:SS", v)
+}
+
+// partitionName escapes the name of a partition.
+func partitionName(n string) (string, error) {
+	name, err := sqlq.ObjectName(n)
+	if err != nil || n == "" {
+		return "", fmt.Errorf("invalid partition name %q", n)
+	}
+	return name, nil
+}
+
+// partitionStatements returns the statements adding, dropping and splitting
+// the partitions of the request and setting up its interval partitioning.
+// Converting a table back to range partitioning comes first and setting up
+// an interval last as partitions can't be added to an interval partitioned
+// table.
+func partitionStatements(req *dbdpb.MaintainPartitionsRequest) ([]string, error) {
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("invalid PDB name %q", req.GetPdbName())
+	}
+	table, err := escapeTable(req.GetTable())
+	if err != nil {
+		return nil, err
+	}
+	interval, ok := intervals[req.GetInterval()]
+	if !ok && req.GetInterval() != dbdpb.MaintainPartitionsRequest_INTERVAL_UNSPECIFIED {
+		return nil, fmt.Errorf("unsupported interval %v", req.GetInterval())
+	}
+
+	var statements []string
+	if req.GetInterval() == dbdpb.MaintainPartitionsRequest_NONE {
+		statements = append(statements, fmt.Sprintf("alter table %s set interval ()", table))
+	}
+	for _, split := range req.GetSplitPartitions() {
+		name, err := partitionName(split.GetName())
+		if err != nil {
+			return nil, err
+		}
+		lower, err := partitionName(split.GetLowerName())
+		if err != nil {
+			return nil, err
+		}
+		upper, err := partitionName(split.GetUpperName())
+		if err != nil {
+			return nil, err
+		}
+		at, err := partitionBound(split.GetAt())
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s split partition %s at (%s) into (partition %s, partition %s) update global indexes", table, name, at, lower, upper))
+	}
+	for _, add := range req.GetAddPartitions() {
+		name, err := partitionName(add.GetName())
+		if err != nil {
+			return nil, err
+		}
+		bound, err := partitionBound(add.GetHighValue())
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s add partition %s values less than (%s)", table, name, bound))
+	}
+	for _, n := range req.GetDropPartitions() {
+		name, err := partitionName(n)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s drop partition %s update global indexes", table, name))
+	}
+	if interval != "" {
+		statements = append(statements, fmt.Sprintf("alter table %s set interval (%s)", table, interval))
+	}
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	return append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...), nil
+}
+
+// parsePartitions converts partitionsSQL rows into partitions in position
+// order and tells whether the table is interval partitioned.
+func parsePartitions(rows []string) ([]partition, bool, error) {
+	var partitions []partition
+	intervalPartitioned := false
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, false, fmt.Errorf("failed to parse partition row %q: %v", msg, err)
+		}
+		position, err := strconv.Atoi(row["PARTITION_POSITION"])
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse PARTITION_POSITION in partition row %q: %v", msg, err)
+		}
+		intervalPartitioned = row["INTERVAL_PARTITIONED"] == "YES"
+		partitions = append(partitions, partition{
+			name:      row["PARTITION_NAME"],
+			position:  position,
+			highValue: row["HIGH_VALUE"],
+			interval:  row["INTERVAL"] == "YES",
+		})
+	}
+	return partitions, intervalPartitioned, nil
+}
+
+// expiredPartitions selects the partitions to drop to keep the newest retain
+// partitions. The MAXVALUE partition doesn't count as it holds no time
+// range, and the range partitions of an interval partitioned table are
+// kept as interval partitioning needs them.
+func expiredPartitions(partitions []partition, intervalPartitioned bool, retain int) []string {
+	var data []partition
+	for _, p := range partitions {
+		if strings.EqualFold(strings.TrimSpace(p.highValue), "MAXVALUE") {
+			continue
+		}
+		data = append(data, p)
+	}
+	if retain <= 0 || len(data) <= retain {
+		return nil
+	}
+	var expired []string
+	for _, p := range data[:len(data)-retain] {
+		if intervalPartitioned && !p.interval {
+			continue
+		}
+		expired = append(expired, p.name)
+	}
+	return expired
+}
+
+// MaintainPartitions adds, drops and splits the partitions of a table, sets
+// up its interval partitioning and then drops the partitions falling out of
+// its retention.
+func (s *Server) MaintainPartitions(ctx context.Context, req *dbdpb.MaintainPartitionsRequest) (*dbdpb.MaintainPartitionsResponse, error) {
+	klog.InfoS("dbdaemon/MaintainPartitions", "req", loggableRequest(req))
+	statements, err := partitionStatements(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/MaintainPartitions: %v", err)
+	}
+	if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_PARTITIONING); err != nil {
+		return nil, fmt.Errorf("dbdaemon/MaintainPartitions: %v", err)
+	}
+	parts := strings.Split(req.GetTable(), ".")
+	owner, table := strings.ToUpper(parts[0]), strings.ToUpper(parts[1])
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if len(statements) > 0 || req.GetRetain() > 0 {
+		option, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{partitioningOptionSQL}}, true)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/MaintainPartitions: failed to query the Partitioning option: %v", err)
+		}
+		if !optionEnabled(option.GetMsg()) {
+			return nil, fmt.Errorf("dbdaemon/MaintainPartitions: the Partitioning option isn't available, it requires Enterprise Edition")
+		}
+	}
+	if len(statements) > 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/MaintainPartitions: failed to maintain the partitions of %s: %v", req.GetTable(), err)
+		}
+	}
+
+	listResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{
+			sqlq.QuerySetSessionContainer(req.GetPdbName()),
+			fmt.Sprintf(partitionsSQL, sqlq.StringParam(owner), sqlq.StringParam(table)),
+		},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/MaintainPartitions: failed to list the partitions of %s: %v", req.GetTable(), err)
+	}
+	partitions, intervalPartitioned, err := parsePartitions(listResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/MaintainPartitions: %v", err)
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("dbdaemon/MaintainPartitions: table %s doesn't exist or isn't partitioned", req.GetTable())
+	}
+
+	expired := expiredPartitions(partitions, intervalPartitioned, int(req.GetRetain()))
+	if len(expired) > 0 {
+		drops := []string{sqlq.QuerySetSessionContainer(req.GetPdbName())}
+		for _, name := range expired {
+			drops = append(drops, fmt.Sprintf("alter table %s.%s drop partition %s update global indexes",
+				sqlq.MustBeIdentifier(owner), sqlq.MustBeIdentifier(table), sqlq.MustBeIdentifier(name)))
+		}
+		klog.InfoS("dbdaemon/MaintainPartitions: dropping expired partitions", "table", req.GetTable(), "partitions", expired)
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: drops}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/MaintainPartitions: failed to drop the expired partitions of %s: %v", req.GetTable(), err)
+		}
+	}
+	return &dbdpb.MaintainPartitionsResponse{
+		DroppedPartitions: expired,
+		Partitions:        int32(len(partitions) - len(expired)),
+	}, nil
+}
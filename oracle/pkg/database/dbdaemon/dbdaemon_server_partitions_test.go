@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestPartitionStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *dbdpb.MaintainPartitionsRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "add, drop, split and set interval",
+			req: &dbdpb.MaintainPartitionsRequest{
+				PdbName:        "pdb1",
+				Table:          "app.events",
+				Interval:       dbdpb.MaintainPartitionsRequest_DAY,
+				AddPartitions:  []*dbdpb.MaintainPartitionsRequest_AddPartition{{Name: "p20220103", HighValue: "2022-01-04"}},
+				DropPartitions: []string{"p20211231"},
+				SplitPartitions: []*dbdpb.MaintainPartitionsRequest_SplitPartition{
+					{Name: "p_old", At: "2022-01-01 12:00:00", LowerName: "p_old1", UpperName: "p_old2"},
+				},
+			},
+			want: []string{
+				`alter session set container="PDB1"`,
+				`alter table "APP"."EVENTS" split partition "P_OLD" at (to_date('2022-01-01 12:00:00', 'YYYY-MM-DD HH24:MI:SS')) into (partition "P_OLD1", partition "P_OLD2") update global indexes`,
+				`alter table "APP"."EVENTS" add partition "P20220103" values less than (to_date('2022-01-04 00:00:00', 'YYYY-MM-DD HH24:MI:SS'))`,
+				`alter table "APP"."EVENTS" drop partition "P20211231" update global indexes`,
+				`alter table "APP"."EVENTS" set interval (numtodsinterval(1, 'DAY'))`,
+			},
+		},
+		{
+			name: "convert back to range partitioning first",
+			req: &dbdpb.MaintainPartitionsRequest{
+				PdbName:       "pdb1",
+				Table:         "app.orders",
+				Interval:      dbdpb.MaintainPartitionsRequest_NONE,
+				AddPartitions: []*dbdpb.MaintainPartitionsRequest_AddPartition{{Name: "p100", HighValue: "100"}},
+			},
+			want: []string{
+				`alter session set container="PDB1"`,
+				`alter table "APP"."ORDERS" set interval ()`,
+				`alter table "APP"."ORDERS" add partition "P100" values less than (100)`,
+			},
+		},
+		{
+			name: "retention only",
+			req:  &dbdpb.MaintainPartitionsRequest{PdbName: "pdb1", Table: "app.events", Retain: 7},
+		},
+		{
+			name:    "invalid bound",
+			req:     &dbdpb.MaintainPartitionsRequest{PdbName: "pdb1", Table: "app.events", AddPartitions: []*dbdpb.MaintainPartitionsRequest_AddPartition{{Name: "p1", HighValue: "sysdate); drop table app.events; --"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			req:     &dbdpb.MaintainPartitionsRequest{PdbName: "pdb1", Table: "app.events", AddPartitions: []*dbdpb.MaintainPartitionsRequest_AddPartition{{Name: "p1", HighValue: "2022-02-30"}}},
+			wantErr: true,
+		},
+		{
+			name:    "split without partition names",
+			req:     &dbdpb.MaintainPartitionsRequest{PdbName: "pdb1", Table: "app.events", SplitPartitions: []*dbdpb.MaintainPartitionsRequest_SplitPartition{{Name: "p1", At: "10"}}},
+			wantErr: true,
+		},
+		{
+			name:    "table without owner",
+			req:     &dbdpb.MaintainPartitionsRequest{PdbName: "pdb1", Table: "events", DropPartitions: []string{"p1"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing PDB",
+			req:     &dbdpb.MaintainPartitionsRequest{Table: "app.events", DropPartitions: []string{"p1"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := partitionStatements(tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("partitionStatements(%v) got error %v, want error: %v", tc.req, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("partitionStatements(%v) got unexpected statements (-want +got):\n%v", tc.req, diff)
+			}
+		})
+	}
+}
+
+func TestExpiredPartitions(t *testing.T) {
+	rangeRows := []string{
+		`{"PARTITION_NAME": "P1", "PARTITION_POSITION": "1", "HIGH_VALUE": "TO_DATE(' 2022-01-02 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "NO", "INTERVAL_PARTITIONED": "NO"}`,
+		`{"PARTITION_NAME": "P2", "PARTITION_POSITION": "2", "HIGH_VALUE": "TO_DATE(' 2022-01-03 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "NO", "INTERVAL_PARTITIONED": "NO"}`,
+		`{"PARTITION_NAME": "P3", "PARTITION_POSITION": "3", "HIGH_VALUE": "TO_DATE(' 2022-01-04 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "NO", "INTERVAL_PARTITIONED": "NO"}`,
+		`{"PARTITION_NAME": "PMAX", "PARTITION_POSITION": "4", "HIGH_VALUE": "MAXVALUE", "INTERVAL": "NO", "INTERVAL_PARTITIONED": "NO"}`,
+	}
+	intervalRows := []string{
+		`{"PARTITION_NAME": "P0", "PARTITION_POSITION": "1", "HIGH_VALUE": "TO_DATE(' 2022-01-01 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "NO", "INTERVAL_PARTITIONED": "YES"}`,
+		`{"PARTITION_NAME": "SYS_P101", "PARTITION_POSITION": "2", "HIGH_VALUE": "TO_DATE(' 2022-01-02 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "YES", "INTERVAL_PARTITIONED": "YES"}`,
+		`{"PARTITION_NAME": "SYS_P102", "PARTITION_POSITION": "3", "HIGH_VALUE": "TO_DATE(' 2022-01-03 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "YES", "INTERVAL_PARTITIONED": "YES"}`,
+		`{"PARTITION_NAME": "SYS_P103", "PARTITION_POSITION": "4", "HIGH_VALUE": "TO_DATE(' 2022-01-04 00:00:00', 'SYYYY-MM-DD HH24:MI:SS', 'NLS_CALENDAR=GREGORIAN')", "INTERVAL": "YES", "INTERVAL_PARTITIONED": "YES"}`,
+	}
+	tests := []struct {
+		name   string
+		rows   []string
+		retain int
+		want   []string
+	}{
+		{
+			name:   "range partitions keep MAXVALUE",
+			rows:   rangeRows,
+			retain: 1,
+			want:   []string{"P1", "P2"},
+		},
+		{
+			name:   "interval partitions keep the range section",
+			rows:   intervalRows,
+			retain: 1,
+			want:   []string{"SYS_P101", "SYS_P102"},
+		},
+		{
+			name:   "nothing expired",
+			rows:   rangeRows,
+			retain: 3,
+		},
+		{
+			name:   "retention disabled",
+			rows:   intervalRows,
+			retain: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			partitions, intervalPartitioned, err := parsePartitions(tc.rows)
+			if err != nil {
+				t.Fatalf("parsePartitions failed: %v", err)
+			}
+			got := expiredPartitions(partitions, intervalPartitioned, tc.retain)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("expiredPartitions(retain=%d) got unexpected partitions (-want +got):\n%v", tc.retain, diff)
+			}
+		})
+	}
+
+	if _, _, err := parsePartitions([]string{`{"PARTITION_NAME": "P1", "PARTITION_POSITION": "first"}`}); err == nil {
+		t.Errorf("parsePartitions with an invalid position succeeded, want error")
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// diffPDBs returns the known PDBs which aren't desired and the desired PDBs
+// which aren't known, sorted. PDB names are case insensitive.
+func diffPDBs(known, desired []string) ([]string, []string) {
+	knownSet := make(map[string]bool)
+	for _, pdb := range known {
+		knownSet[strings.ToUpper(pdb)] = true
+	}
+	desiredSet := make(map[string]bool)
+	for _, pdb := range desired {
+		desiredSet[strings.ToUpper(pdb)] = true
+	}
+	var orphaned, missing []string
+	for pdb := range knownSet {
+		if !desiredSet[pdb] {
+			orphaned = append(orphaned, pdb)
+		}
+	}
+	for pdb := range desiredSet {
+		if !knownSet[pdb] {
+			missing = append(missing, pdb)
+		}
+	}
+	sort.Strings(orphaned)
+	sort.Strings(missing)
+	return orphaned, missing
+}
+
+// dropPDBCommands closes and drops a PDB with its datafiles.
+func dropPDBCommands(pdb string) []string {
+	name := sqlq.MustBeIdentifier(pdb)
+	return []string{
+		fmt.Sprintf("alter pluggable database %s close immediate", name),
+		fmt.Sprintf("drop pluggable database %s including datafiles", name),
+	}
+}
+
+// ReconcilePDBs reports the PDBs of the CDB which aren't desired and the
+// desired PDBs the CDB doesn't have, and drops the former if requested.
+func (s *Server) ReconcilePDBs(ctx context.Context, req *dbdpb.ReconcilePDBsRequest) (*dbdpb.ReconcilePDBsResponse, error) {
+	klog.InfoS("dbdaemon/ReconcilePDBs", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	known, err := s.knownPDBs(ctx, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ReconcilePDBs: failed to list the PDBs: %v", err)
+	}
+	orphaned, missing := diffPDBs(known, req.GetDesiredPdbs())
+	resp := &dbdpb.ReconcilePDBsResponse{OrphanedPdbs: orphaned, MissingPdbs: missing}
+	if !req.GetDropOrphaned() {
+		return resp, nil
+	}
+	for _, pdb := range orphaned {
+		if _, err := sqlq.Identifier(pdb); err != nil {
+			return resp, fmt.Errorf("dbdaemon/ReconcilePDBs: invalid PDB name %q: %v", pdb, err)
+		}
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: dropPDBCommands(pdb)}, false); err != nil {
+			return resp, fmt.Errorf("dbdaemon/ReconcilePDBs: failed to drop PDB %s: %v", pdb, err)
+		}
+		klog.InfoS("dbdaemon/ReconcilePDBs: dropped orphaned PDB", "pdb", pdb)
+		resp.DroppedPdbs = append(resp.DroppedPdbs, pdb)
+	}
+	return resp, nil
+}
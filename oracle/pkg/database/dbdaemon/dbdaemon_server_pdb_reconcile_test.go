@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestDiffPDBs(t *testing.T) {
+	tests := []struct {
+		name         string
+		known        []string
+		desired      []string
+		wantOrphaned []string
+		wantMissing  []string
+	}{
+		{
+			name:    "in sync",
+			known:   []string{"PDB1", "PDB2"},
+			desired: []string{"pdb2", "Pdb1"},
+		},
+		{
+			name:         "orphaned",
+			known:        []string{"PDB1", "PDB3", "PDB2"},
+			desired:      []string{"pdb1"},
+			wantOrphaned: []string{"PDB2", "PDB3"},
+		},
+		{
+			name:        "missing",
+			known:       []string{"PDB1"},
+			desired:     []string{"pdb1", "pdb2"},
+			wantMissing: []string{"PDB2"},
+		},
+		{
+			name:         "both",
+			known:        []string{"PDB1", "LEGACY"},
+			desired:      []string{"pdb1", "pdb2"},
+			wantOrphaned: []string{"LEGACY"},
+			wantMissing:  []string{"PDB2"},
+		},
+		{
+			name:         "no Database resources",
+			known:        []string{"PDB1"},
+			wantOrphaned: []string{"PDB1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orphaned, missing := diffPDBs(tc.known, tc.desired)
+			if diff := cmp.Diff(tc.wantOrphaned, orphaned); diff != "" {
+				t.Errorf("diffPDBs got unexpected orphaned PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantMissing, missing); diff != "" {
+				t.Errorf("diffPDBs got unexpected missing PDBs (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestReconcilePDBs(t *testing.T) {
+	tests := []struct {
+		name        string
+		drop        bool
+		dropErr     error
+		wantErr     bool
+		wantDropped []string
+		wantSQLs    []string
+	}{
+		{
+			name: "report",
+		},
+		{
+			name:        "drop",
+			drop:        true,
+			wantDropped: []string{"LEGACY"},
+			wantSQLs: []string{
+				`alter pluggable database "LEGACY" close immediate`,
+				`drop pluggable database "LEGACY" including datafiles`,
+			},
+		},
+		{
+			name:    "drop failed",
+			drop:    true,
+			dropErr: errors.New("ORA-65025: Pluggable database LEGACY is not closed on all instances"),
+			wantErr: true,
+			wantSQLs: []string{
+				`alter pluggable database "LEGACY" close immediate`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			useFakeOracleDatabase(t)
+			ctx := context.Background()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			db := useFakeSQLDB(s)
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				if len(sqls) != 1 || !strings.HasPrefix(sqls[0], "select name from v$containers") {
+					return nil, fmt.Errorf("unexpected query %q", sqls)
+				}
+				return []string{`{"NAME":"PDB1"}`, `{"NAME":"LEGACY"}`}, nil
+			}
+			var gotSQLs []string
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				for _, sql := range sqls {
+					gotSQLs = append(gotSQLs, sql)
+					if tc.dropErr != nil {
+						return nil, tc.dropErr
+					}
+				}
+				return nil, nil
+			}
+
+			resp, err := s.ReconcilePDBs(ctx, &dbdpb.ReconcilePDBsRequest{DesiredPdbs: []string{"pdb1", "pdb2"}, DropOrphaned: tc.drop})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("ReconcilePDBs got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff([]string{"LEGACY"}, resp.GetOrphanedPdbs()); diff != "" {
+				t.Errorf("ReconcilePDBs got unexpected orphaned PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff([]string{"PDB2"}, resp.GetMissingPdbs()); diff != "" {
+				t.Errorf("ReconcilePDBs got unexpected missing PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantDropped, resp.GetDroppedPdbs()); diff != "" {
+				t.Errorf("ReconcilePDBs got unexpected dropped PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantSQLs, gotSQLs); diff != "" {
+				t.Errorf("ReconcilePDBs ran unexpected SQL (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
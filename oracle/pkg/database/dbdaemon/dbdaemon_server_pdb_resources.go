@@ -0,0 +1,163 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// pdbResourcesSQL reports the resource parameters in effect in the
+// container and the SGA granule size in a granuleSizeRow row.
+const pdbResourcesSQL = "select name, value from v$parameter " +
+	"where name in ('cpu_count', 'max_iops', 'max_mbps', 'pga_aggregate_target', 'sga_target') " +
+	"union all select '" + granuleSizeRow + "', to_char(bytes) from v$sgainfo where name = 'Granule Size' order by name"
+
+// granuleSizeRow is the name of the pdbResourcesSQL row of the SGA granule
+// size, parameter names have no spaces.
+const granuleSizeRow = "granule size"
+
+// pdbResourceParameterNames are the integer parameters ConfigurePDBResources
+// sets, they are modifiable in a PDB.
+var pdbResourceParameterNames = map[string]bool{
+	"cpu_count":            true,
+	"max_iops":             true,
+	"max_mbps":             true,
+	"pga_aggregate_target": true,
+	"sga_target":           true,
+}
+
+// parsePDBResources converts pdbResourcesSQL rows into a map of the values
+// by parameter name and the SGA granule size, which is 0 if it's missing.
+func parsePDBResources(rows []string) (map[string]string, int64, error) {
+	params := make(map[string]string)
+	var granule int64
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse resource parameter row %q: %v", msg, err)
+		}
+		if row["NAME"] == granuleSizeRow {
+			var err error
+			if granule, err = strconv.ParseInt(row["VALUE"], 10, 64); err != nil {
+				return nil, 0, fmt.Errorf("failed to parse the granule size %q: %v", row["VALUE"], err)
+			}
+			continue
+		}
+		params[row["NAME"]] = row["VALUE"]
+	}
+	return params, granule, nil
+}
+
+// inEffectValue returns the value Oracle puts in effect for a parameter set
+// to value, it rounds the sga_target up to a multiple of the granule.
+func inEffectValue(name string, value, granule int64) int64 {
+	if name != "sga_target" || granule <= 0 || value%granule == 0 {
+		return value
+	}
+	return (value/granule + 1) * granule
+}
+
+// pdbResourceStatements returns the statements setting the parameters of
+// the request which differ from those in effect in params and resetting
+// its reset parameters, with the names of the parameters they change.
+// Values Oracle rounds to the granule are compared after rounding them.
+func pdbResourceStatements(req *dbdpb.ConfigurePDBResourcesRequest, params map[string]string, granule int64) ([]string, []string, error) {
+	var names []string
+	for name := range req.GetParameters() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var statements, changed []string
+	for _, name := range names {
+		if !pdbResourceParameterNames[name] {
+			return nil, nil, fmt.Errorf("unsupported resource parameter %q", name)
+		}
+		value, err := strconv.ParseInt(req.GetParameters()[name], 10, 64)
+		if err != nil || value < 0 {
+			return nil, nil, fmt.Errorf("invalid value %q of resource parameter %q, want a non-negative integer", req.GetParameters()[name], name)
+		}
+		if inEffect, err := strconv.ParseInt(params[name], 10, 64); err == nil && inEffect == inEffectValue(name, value, granule) {
+			continue
+		}
+		stmt, err := sqlq.QuerySetSystemParameterNoPanic(name, strconv.FormatInt(value, 10), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		statements = append(statements, stmt+" scope=both")
+		changed = append(changed, name)
+	}
+	for _, name := range req.GetResetParameters() {
+		if !pdbResourceParameterNames[name] {
+			return nil, nil, fmt.Errorf("unsupported resource parameter %q", name)
+		}
+		if _, ok := req.GetParameters()[name]; ok {
+			return nil, nil, fmt.Errorf("resource parameter %q is both set and reset", name)
+		}
+		statements = append(statements, fmt.Sprintf("alter system reset %s scope=both", name))
+		changed = append(changed, name)
+	}
+	return statements, changed, nil
+}
+
+func (s *Server) pdbResources(ctx context.Context, pdbName string) (map[string]string, int64, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: nlsContainerCommands(pdbName, pdbResourcesSQL)}, true)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query the resource parameters: %v", err)
+	}
+	return parsePDBResources(resp.GetMsg())
+}
+
+// ConfigurePDBResources sets the resource parameters of the request in the
+// PDB which differ from those in effect, resets its reset parameters and
+// reports the parameters in effect afterwards.
+func (s *Server) ConfigurePDBResources(ctx context.Context, req *dbdpb.ConfigurePDBResourcesRequest) (*dbdpb.ConfigurePDBResourcesResponse, error) {
+	klog.InfoS("dbdaemon/ConfigurePDBResources", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/ConfigurePDBResources: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	params, granule, err := s.pdbResources(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigurePDBResources: %v", err)
+	}
+	statements, changed, err := pdbResourceStatements(req, params, granule)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigurePDBResources: %v", err)
+	}
+	if len(statements) == 0 {
+		return &dbdpb.ConfigurePDBResourcesResponse{Parameters: params}, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: nlsContainerCommands(req.GetPdbName(), statements...)}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigurePDBResources: failed to set the resource parameters %s: %v", strings.Join(changed, ", "), err)
+	}
+	if params, _, err = s.pdbResources(ctx, req.GetPdbName()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigurePDBResources: %v", err)
+	}
+	return &dbdpb.ConfigurePDBResourcesResponse{Parameters: params, Changed: changed}, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var pdbResourceRows = []string{
+	`{"NAME":"cpu_count","VALUE":"4"}`,
+	`{"NAME":"granule size","VALUE":"16777216"}`,
+	`{"NAME":"max_iops","VALUE":"0"}`,
+	`{"NAME":"max_mbps","VALUE":"0"}`,
+	`{"NAME":"pga_aggregate_target","VALUE":"0"}`,
+	`{"NAME":"sga_target","VALUE":"1073741824"}`,
+}
+
+func TestPDBResourceStatements(t *testing.T) {
+	params, granule, err := parsePDBResources(pdbResourceRows)
+	if err != nil {
+		t.Fatalf("parsePDBResources failed: %v", err)
+	}
+	if granule != 16777216 {
+		t.Errorf("parsePDBResources got granule size %d, want 16777216", granule)
+	}
+	tests := []struct {
+		name        string
+		req         *dbdpb.ConfigurePDBResourcesRequest
+		want        []string
+		wantChanged []string
+		wantErr     bool
+	}{
+		{
+			name: "set",
+			req:  &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"max_iops": "500", "cpu_count": "2"}},
+			want: []string{
+				"alter system set cpu_count=2 scope=both",
+				"alter system set max_iops=500 scope=both",
+			},
+			wantChanged: []string{"cpu_count", "max_iops"},
+		},
+		{
+			name: "parameters in effect",
+			req:  &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"cpu_count": "4", "sga_target": "1073741824"}},
+		},
+		{
+			name: "rounded up to the granule",
+			req:  &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"sga_target": "1073741000"}},
+		},
+		{
+			name: "over the rounded value",
+			req:  &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"sga_target": "1073741825"}},
+			want: []string{
+				"alter system set sga_target=1073741825 scope=both",
+			},
+			wantChanged: []string{"sga_target"},
+		},
+		{
+			name: "reset",
+			req:  &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"cpu_count": "4"}, ResetParameters: []string{"sga_target"}},
+			want: []string{
+				"alter system reset sga_target scope=both",
+			},
+			wantChanged: []string{"sga_target"},
+		},
+		{
+			name:    "unsupported parameter",
+			req:     &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"processes": "300"}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported reset parameter",
+			req:     &dbdpb.ConfigurePDBResourcesRequest{ResetParameters: []string{"db_name"}},
+			wantErr: true,
+		},
+		{
+			name:    "set and reset",
+			req:     &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"cpu_count": "2"}, ResetParameters: []string{"cpu_count"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			req:     &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"cpu_count": "2; drop"}},
+			wantErr: true,
+		},
+		{
+			name:    "negative value",
+			req:     &dbdpb.ConfigurePDBResourcesRequest{Parameters: map[string]string{"max_mbps": "-1"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, changed, err := pdbResourceStatements(tc.req, params, granule)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("pdbResourceStatements got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("pdbResourceStatements got unexpected statements (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.wantChanged, changed); diff != "" {
+				t.Errorf("pdbResourceStatements got unexpected changed parameters (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestConfigurePDBResources(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	rows := pdbResourceRows
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if diff := cmp.Diff([]string{`alter session set container="PDB1"`, pdbResourcesSQL}, sqls); diff != "" {
+			return nil, fmt.Errorf("unexpected query (-want +got):\n%v", diff)
+		}
+		return rows, nil
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = sqls
+		rows = append([]string{`{"NAME":"cpu_count","VALUE":"2"}`}, pdbResourceRows[1:]...)
+		return nil, nil
+	}
+
+	resp, err := s.ConfigurePDBResources(ctx, &dbdpb.ConfigurePDBResourcesRequest{PdbName: "pdb1", Parameters: map[string]string{"cpu_count": "2"}})
+	if err != nil {
+		t.Fatalf("ConfigurePDBResources failed: %v", err)
+	}
+	want := []string{
+		`alter session set container="PDB1"`,
+		"alter system set cpu_count=2 scope=both",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ConfigurePDBResources got unexpected statements (-want +got):\n%v", diff)
+	}
+	wantResp := &dbdpb.ConfigurePDBResourcesResponse{
+		Parameters: map[string]string{
+			"cpu_count":            "2",
+			"max_iops":             "0",
+			"max_mbps":             "0",
+			"pga_aggregate_target": "0",
+			"sga_target":           "1073741824",
+		},
+		Changed: []string{"cpu_count"},
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ConfigurePDBResources got unexpected response (-want +got):\n%v", diff)
+	}
+
+	for _, pdbName := range []string{"", `pdb1"`} {
+		if _, err := s.ConfigurePDBResources(ctx, &dbdpb.ConfigurePDBResourcesRequest{PdbName: pdbName}); err == nil {
+			t.Errorf("ConfigurePDBResources with the PDB name %q succeeded, want error", pdbName)
+		}
+	}
+}
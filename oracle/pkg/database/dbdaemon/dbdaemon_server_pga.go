@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	pgaStatSQL = "select name, to_char(value) as value from v$pgastat where name in " +
+		"('aggregate PGA target parameter', 'total PGA allocated', 'total PGA inuse', 'maximum PGA allocated', 'over allocation count', 'cache hit percentage')"
+
+	pgaTargetAdviceSQL = "select to_char(pga_target_for_estimate) as target, to_char(estd_pga_cache_hit_percentage) as cache_hit_percentage, " +
+		"to_char(estd_overalloc_count) as overalloc_count from v$pga_target_advice order by pga_target_for_estimate"
+
+	// pgaCacheHitTolerance is how many points below the best estimate the
+	// cache hit percentage of the recommended target may be, the estimates
+	// flatten out well before the largest target.
+	pgaCacheHitTolerance = 1
+)
+
+// parseRows parses the JSON rows returned by runSQLPlusHelper.
+func parsePGARows(msgs []string) ([]map[string]string, error) {
+	var rows []map[string]string
+	for _, msg := range msgs {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse row %q: %v", msg, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parsePGAStat converts pgaStatSQL rows into a GetPGAUsage response.
+func parsePGAStat(rows []map[string]string) (*dbdpb.GetPGAUsageResponse, error) {
+	resp := &dbdpb.GetPGAUsageResponse{}
+	ints := map[string]*int64{
+		"aggregate PGA target parameter": &resp.AggregateTargetBytes,
+		"total PGA allocated":            &resp.AllocatedBytes,
+		"total PGA inuse":                &resp.InUseBytes,
+		"maximum PGA allocated":          &resp.MaxAllocatedBytes,
+		"over allocation count":          &resp.OverAllocationCount,
+	}
+	for _, row := range rows {
+		name, value := row["NAME"], row["VALUE"]
+		if name == "cache hit percentage" {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s %q: %v", name, value, err)
+			}
+			resp.CacheHitPercentage = v
+			continue
+		}
+		field, ok := ints[name]
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s %q: %v", name, value, err)
+		}
+		*field = v
+	}
+	return resp, nil
+}
+
+// recommendPGATarget returns the smallest pgaTargetAdviceSQL target with no
+// estimated over allocation and a cache hit percentage within
+// pgaCacheHitTolerance of the best estimate, 0 if there is none.
+func recommendPGATarget(rows []map[string]string) (int64, error) {
+	type estimate struct {
+		target    int64
+		hit       float64
+		overalloc int64
+	}
+	var estimates []estimate
+	best := 0.0
+	for _, row := range rows {
+		var e estimate
+		var err error
+		if e.target, err = strconv.ParseInt(row["TARGET"], 10, 64); err != nil {
+			return 0, fmt.Errorf("failed to parse PGA target estimate %v: %v", row, err)
+		}
+		if e.hit, err = strconv.ParseFloat(row["CACHE_HIT_PERCENTAGE"], 64); err != nil {
+			return 0, fmt.Errorf("failed to parse PGA target estimate %v: %v", row, err)
+		}
+		if e.overalloc, err = strconv.ParseInt(row["OVERALLOC_COUNT"], 10, 64); err != nil {
+			return 0, fmt.Errorf("failed to parse PGA target estimate %v: %v", row, err)
+		}
+		if e.hit > best {
+			best = e.hit
+		}
+		estimates = append(estimates, e)
+	}
+	for _, e := range estimates {
+		if e.overalloc == 0 && e.hit >= best-pgaCacheHitTolerance {
+			return e.target, nil
+		}
+	}
+	return 0, nil
+}
+
+// GetPGAUsage returns the PGA usage of the instance and the aggregate target
+// recommended by the PGA advice.
+func (s *Server) GetPGAUsage(ctx context.Context, req *dbdpb.GetPGAUsageRequest) (*dbdpb.GetPGAUsageResponse, error) {
+	klog.InfoS("dbdaemon/GetPGAUsage", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	sqlResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{pgaStatSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: failed to query PGA statistics: %v", err)
+	}
+	rows, err := parsePGARows(sqlResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: %v", err)
+	}
+	resp, err := parsePGAStat(rows)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: %v", err)
+	}
+
+	sqlResp, err = s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{pgaTargetAdviceSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: failed to query PGA target advice: %v", err)
+	}
+	if rows, err = parsePGARows(sqlResp.GetMsg()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: %v", err)
+	}
+	if resp.RecommendedAggregateTargetBytes, err = recommendPGATarget(rows); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetPGAUsage: %v", err)
+	}
+	return resp, nil
+}
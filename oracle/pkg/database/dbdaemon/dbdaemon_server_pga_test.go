@@ -0,0 +1,145 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParsePGAStat(t *testing.T) {
+	rows := []map[string]string{
+		{"NAME": "aggregate PGA target parameter", "VALUE": "1073741824"},
+		{"NAME": "total PGA allocated", "VALUE": "805306368"},
+		{"NAME": "total PGA inuse", "VALUE": "536870912"},
+		{"NAME": "maximum PGA allocated", "VALUE": "1610612736"},
+		{"NAME": "over allocation count", "VALUE": "42"},
+		{"NAME": "cache hit percentage", "VALUE": "87.5"},
+	}
+	got, err := parsePGAStat(rows)
+	if err != nil {
+		t.Fatalf("parsePGAStat failed: %v", err)
+	}
+	want := &dbdpb.GetPGAUsageResponse{
+		AggregateTargetBytes: 1 << 30,
+		AllocatedBytes:       768 << 20,
+		InUseBytes:           512 << 20,
+		MaxAllocatedBytes:    1536 << 20,
+		OverAllocationCount:  42,
+		CacheHitPercentage:   87.5,
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parsePGAStat got unexpected response (-want +got):\n%v", diff)
+	}
+	if _, err := parsePGAStat([]map[string]string{{"NAME": "over allocation count", "VALUE": "many"}}); err == nil {
+		t.Errorf("parsePGAStat of an invalid value succeeded, want error")
+	}
+}
+
+func TestRecommendPGATarget(t *testing.T) {
+	advice := func(target, hit, overalloc string) map[string]string {
+		return map[string]string{"TARGET": target, "CACHE_HIT_PERCENTAGE": hit, "OVERALLOC_COUNT": overalloc}
+	}
+	testCases := []struct {
+		name string
+		rows []map[string]string
+		want int64
+	}{
+		{
+			name: "smallest target without over allocation near the best hit percentage",
+			rows: []map[string]string{
+				advice("536870912", "70", "12"),
+				advice("1073741824", "85", "0"),
+				advice("2147483648", "95.5", "0"),
+				advice("4294967296", "96", "0"),
+			},
+			want: 2 << 30,
+		},
+		{
+			name: "over allocation at the best hit percentage",
+			rows: []map[string]string{
+				advice("536870912", "96", "3"),
+				advice("1073741824", "96", "0"),
+			},
+			want: 1 << 30,
+		},
+		{
+			name: "over allocation at every target",
+			rows: []map[string]string{
+				advice("536870912", "90", "3"),
+				advice("1073741824", "96", "1"),
+			},
+		},
+		{
+			name: "advice off",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := recommendPGATarget(tc.rows)
+			if err != nil {
+				t.Fatalf("recommendPGATarget failed: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("recommendPGATarget got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetPGAUsage(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case pgaStatSQL:
+			return []string{
+				`{"NAME":"aggregate PGA target parameter","VALUE":"1073741824"}`,
+				`{"NAME":"over allocation count","VALUE":"7"}`,
+				`{"NAME":"cache hit percentage","VALUE":"64.2"}`,
+			}, nil
+		case pgaTargetAdviceSQL:
+			return []string{
+				`{"TARGET":"1073741824","CACHE_HIT_PERCENTAGE":"64","OVERALLOC_COUNT":"7"}`,
+				`{"TARGET":"2147483648","CACHE_HIT_PERCENTAGE":"92","OVERALLOC_COUNT":"0"}`,
+			}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	got, err := s.GetPGAUsage(ctx, &dbdpb.GetPGAUsageRequest{})
+	if err != nil {
+		t.Fatalf("GetPGAUsage failed: %v", err)
+	}
+	want := &dbdpb.GetPGAUsageResponse{
+		AggregateTargetBytes:            1 << 30,
+		OverAllocationCount:             7,
+		CacheHitPercentage:              64.2,
+		RecommendedAggregateTargetBytes: 2 << 30,
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetPGAUsage got unexpected response (-want +got):\n%v", diff)
+	}
+}
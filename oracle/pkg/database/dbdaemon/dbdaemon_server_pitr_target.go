@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/pitr"
+)
+
+// readLogMetadata reads the metadata of the replicated logs, tests replace
+// it.
+var readLogMetadata = pitr.ReadLogMetadata
+
+// CheckPITRTarget checks that the replicated redo logs recover a backup to
+// the target of a PITR restore. A target the logs don't reach otherwise
+// fails the restore in RMAN after the database was replaced.
+func (s *Server) CheckPITRTarget(ctx context.Context, req *dbdpb.CheckPITRTargetRequest) (*dbdpb.CheckPITRTargetResponse, error) {
+	klog.InfoS("dbdaemon/CheckPITRTarget", "req", loggableRequest(req))
+	input := req.GetPitrRestoreInput()
+	if input.GetLogGcsPath() == "" {
+		return nil, fmt.Errorf("dbdaemon/CheckPITRTarget: log path is empty")
+	}
+	if input.GetEndTime() == nil && input.GetEndScn() == 0 {
+		return nil, fmt.Errorf("dbdaemon/CheckPITRTarget: either end SCN or timestamp must be specified in req %+v", loggableRequest(req))
+	}
+	if input.GetEndTime() != nil && input.GetStartTime() == nil {
+		return nil, fmt.Errorf("dbdaemon/CheckPITRTarget: start time must be specified with end time in req %+v", loggableRequest(req))
+	}
+	metadata, err := readLogMetadata(ctx, input.GetLogGcsPath())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckPITRTarget: %v", err)
+	}
+
+	var check pitr.RecoveryCheck
+	if input.GetEndTime() != nil {
+		check, err = pitr.CheckRecoveryTime(metadata, input.GetStartTime().AsTime(), input.GetEndTime().AsTime())
+	} else {
+		check, err = pitr.CheckRecoverySCN(metadata, input.GetStartScn(), input.GetEndScn())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckPITRTarget: %v", err)
+	}
+	resp := &dbdpb.CheckPITRTargetResponse{
+		Recoverable: check.Recoverable,
+		ClosestScn:  check.ClosestSCN,
+		Reason:      check.Reason,
+	}
+	if !check.ClosestTime.IsZero() {
+		resp.ClosestTime = timestamppb.New(check.ClosestTime)
+	}
+	return resp, nil
+}
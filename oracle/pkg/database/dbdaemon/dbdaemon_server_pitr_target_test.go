@@ -0,0 +1,130 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/pitr"
+)
+
+func TestCheckPITRTarget(t *testing.T) {
+	metadata := pitr.LogMetadata{
+		KeyToLogEntry: map[string]pitr.LogMetadataEntry{
+			"1-2-1": {
+				LogHashEntry: pitr.LogHashEntry{ReplicaPath: "gs://bucket/pitr/log1"},
+				FirstChange:  "1000",
+				NextChange:   "2000",
+				FirstTime:    time.Unix(1628281000, 0),
+				NextTime:     time.Unix(1628282000, 0),
+			},
+			"1-2-2": {
+				LogHashEntry: pitr.LogHashEntry{ReplicaPath: "gs://bucket/pitr/log2"},
+				FirstChange:  "2000",
+				NextChange:   "3000",
+				FirstTime:    time.Unix(1628282000, 0),
+				NextTime:     time.Unix(1628283000, 0),
+			},
+		},
+	}
+	var gotPath string
+	origRead := readLogMetadata
+	readLogMetadata = func(ctx context.Context, logPath string) (pitr.LogMetadata, error) {
+		gotPath = logPath
+		if logPath == "gs://bucket/missing" {
+			return pitr.LogMetadata{}, errors.New("storage: object doesn't exist")
+		}
+		return metadata, nil
+	}
+	t.Cleanup(func() { readLogMetadata = origRead })
+
+	testCases := []struct {
+		name    string
+		input   *dbdpb.PhysicalRestoreRequest_PITRRestoreInput
+		want    *dbdpb.CheckPITRTargetResponse
+		wantErr bool
+	}{
+		{
+			name:  "recoverable SCN",
+			input: &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/pitr", StartScn: 1500, EndScn: 2500},
+			want:  &dbdpb.CheckPITRTargetResponse{Recoverable: true},
+		},
+		{
+			name:  "SCN past the logs",
+			input: &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/pitr", StartScn: 1500, EndScn: 3500},
+			want: &dbdpb.CheckPITRTargetResponse{
+				ClosestScn:  3000,
+				ClosestTime: timestamppb.New(time.Unix(1628283000, 0)),
+				Reason:      "the replicated redo logs following the backup at SCN 1500 end at SCN 3000",
+			},
+		},
+		{
+			name: "recoverable timestamp",
+			input: &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{
+				LogGcsPath: "gs://bucket/pitr",
+				StartTime:  timestamppb.New(time.Unix(1628281500, 0)),
+				EndTime:    timestamppb.New(time.Unix(1628282500, 0)),
+			},
+			want: &dbdpb.CheckPITRTargetResponse{Recoverable: true},
+		},
+		{
+			name:    "timestamp without a start",
+			input:   &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/pitr", EndTime: timestamppb.New(time.Unix(1628282500, 0))},
+			wantErr: true,
+		},
+		{
+			name:    "no target",
+			input:   &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/pitr", StartScn: 1500},
+			wantErr: true,
+		},
+		{
+			name:    "no log path",
+			input:   &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{StartScn: 1500, EndScn: 2500},
+			wantErr: true,
+		},
+		{
+			name:    "unreadable metadata",
+			input:   &dbdpb.PhysicalRestoreRequest_PITRRestoreInput{LogGcsPath: "gs://bucket/missing", StartScn: 1500, EndScn: 2500},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotPath = ""
+			s := &Server{}
+			got, err := s.CheckPITRTarget(context.Background(), &dbdpb.CheckPITRTargetRequest{PitrRestoreInput: tc.input})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CheckPITRTarget got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if gotPath != tc.input.GetLogGcsPath() {
+				t.Errorf("CheckPITRTarget read the metadata of %q, want %q", gotPath, tc.input.GetLogGcsPath())
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("CheckPITRTarget got unexpected response: want-, got+: %s\n", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+const (
+	// redefPrimaryKeySQL counts the primary keys of a table, a table
+	// without one is redefined by rowid.
+	redefPrimaryKeySQL = "select count(*) as count from dba_constraints where owner='%s' and table_name='%s' and constraint_type='P'"
+
+	// redefColumnsSQL lists the columns of a table.
+	redefColumnsSQL = "select column_name from dba_tab_columns where owner='%s' and table_name='%s' order by column_id"
+
+	// redefErrorsSQL lists the dependent objects which failed to be copied
+	// onto the interim table.
+	redefErrorsSQL = "select object_type, object_owner, object_name, err_txt from dba_redefinition_errors " +
+		"where base_table_owner='%s' and base_table_name='%s' order by object_type, object_name"
+
+	canRedefCmd = "begin dbms_redefinition.can_redef_table(uname => '%s', tname => '%s', options_flag => %s); end;"
+
+	startRedefCmd = "begin dbms_redefinition.start_redef_table(uname => '%s', orig_table => '%s', int_table => '%s', " +
+		"col_mapping => '%s', options_flag => %s); end;"
+
+	copyDependentsCmd = "declare n pls_integer; begin dbms_redefinition.copy_table_dependents(uname => '%s', orig_table => '%s', int_table => '%s', " +
+		"copy_indexes => dbms_redefinition.cons_orig_params, copy_triggers => true, copy_constraints => true, copy_privileges => true, " +
+		"ignore_errors => true, num_errors => n); end;"
+
+	syncInterimCmd = "begin dbms_redefinition.sync_interim_table(uname => '%s', orig_table => '%s', int_table => '%s'); end;"
+
+	finishRedefCmd = "begin dbms_redefinition.finish_redef_table(uname => '%s', orig_table => '%s', int_table => '%s'); end;"
+
+	abortRedefCmd = "begin dbms_redefinition.abort_redef_table(uname => '%s', orig_table => '%s', int_table => '%s'); end;"
+
+	redefMethodPK    = "PK"
+	redefMethodRowid = "ROWID"
+)
+
+// redefOptions maps the redefinition methods to their options_flag.
+var redefOptions = map[string]string{
+	redefMethodPK:    "dbms_redefinition.cons_use_pk",
+	redefMethodRowid: "dbms_redefinition.cons_use_rowid",
+}
+
+// redefinition is a validated StartOnlineRedefinitionRequest, names are
+// upper case and unquoted as the DBMS_REDEFINITION parameters expect.
+type redefinition struct {
+	pdb, schema, table, interim string
+	// renamed maps the interim columns to the source columns they copy.
+	renamed map[string]string
+}
+
+// newRedefinition validates a StartOnlineRedefinitionRequest.
+func newRedefinition(req *dbdpb.StartOnlineRedefinitionRequest) (*redefinition, error) {
+	names := []struct{ field, value string }{
+		{"pdb_name", req.GetPdbName()},
+		{"schema", req.GetSchema()},
+		{"table", req.GetTable()},
+		{"interim_table", req.GetInterimTable()},
+	}
+	for _, n := range names {
+		if _, err := sqlq.ObjectName(n.value); err != nil || n.value == "" {
+			return nil, fmt.Errorf("invalid %s %q", n.field, n.value)
+		}
+	}
+	r := &redefinition{
+		pdb:     req.GetPdbName(),
+		schema:  strings.ToUpper(req.GetSchema()),
+		table:   strings.ToUpper(req.GetTable()),
+		interim: strings.ToUpper(req.GetInterimTable()),
+		renamed: make(map[string]string),
+	}
+	if r.table == r.interim {
+		return nil, fmt.Errorf("interim_table must differ from table %s", r.table)
+	}
+	for interim, source := range req.GetRenamedColumns() {
+		for _, c := range []string{interim, source} {
+			if _, err := sqlq.ObjectName(c); err != nil || c == "" {
+				return nil, fmt.Errorf("invalid column name %q", c)
+			}
+		}
+		r.renamed[strings.ToUpper(interim)] = strings.ToUpper(source)
+	}
+	return r, nil
+}
+
+// cmd formats a DBMS_REDEFINITION call taking the schema, the table and the
+// interim table, run in the PDB of the redefinition.
+func (r *redefinition) cmd(format string) []string {
+	return []string{
+		sqlq.QuerySetSessionContainer(r.pdb),
+		fmt.Sprintf(format, sqlq.StringParam(r.schema), sqlq.StringParam(r.table), sqlq.StringParam(r.interim)),
+	}
+}
+
+// columnMapping returns the col_mapping of start_redef_table given the
+// columns of the table and of the interim table. Interim columns neither
+// in the table nor renamed are left out and filled with their default.
+func (r *redefinition) columnMapping(sourceColumns, interimColumns []string) (string, error) {
+	source := make(map[string]bool)
+	for _, c := range sourceColumns {
+		source[c] = true
+	}
+	interim := make(map[string]bool)
+	var mapping []string
+	for _, c := range interimColumns {
+		interim[c] = true
+		from, ok := r.renamed[c]
+		if !ok {
+			if !source[c] {
+				continue
+			}
+			from = c
+		}
+		if !source[from] {
+			return "", fmt.Errorf("column %s renamed to %s doesn't exist in %s.%s", from, c, r.schema, r.table)
+		}
+		mapping = append(mapping, fmt.Sprintf("%s %s", sqlq.MustBeIdentifier(from), sqlq.MustBeIdentifier(c)))
+	}
+	var missing []string
+	for c := range r.renamed {
+		if !interim[c] {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("renamed columns %s don't exist in the interim table %s.%s", strings.Join(missing, ", "), r.schema, r.interim)
+	}
+	if len(mapping) == 0 {
+		return "", fmt.Errorf("the interim table %s.%s has no column to copy from %s.%s", r.schema, r.interim, r.schema, r.table)
+	}
+	return strings.Join(mapping, ", "), nil
+}
+
+// redefQuery runs a query in the PDB of the redefinition and returns its
+// rows.
+func (s *Server) redefQuery(ctx context.Context, r *redefinition, query string) ([]map[string]string, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(r.pdb), query},
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]string
+	for _, msg := range resp.GetMsg() {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse row %q: %v", msg, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// redefColumns lists the columns of a table of the redefinition schema.
+func (s *Server) redefColumns(ctx context.Context, r *redefinition, table string) ([]string, error) {
+	rows, err := s.redefQuery(ctx, r, fmt.Sprintf(redefColumnsSQL, sqlq.StringParam(r.schema), sqlq.StringParam(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the columns of %s.%s: %v", r.schema, table, err)
+	}
+	var columns []string
+	for _, row := range rows {
+		columns = append(columns, row["COLUMN_NAME"])
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s doesn't exist", r.schema, table)
+	}
+	return columns, nil
+}
+
+// onlineRedefinition redefines a table into its interim table, reporting
+// each step as the progress of the LRO. A failure once the redefinition
+// started aborts it, leaving the table as it was.
+func (s *Server) onlineRedefinition(ctx context.Context, r *redefinition) (*dbdpb.StartOnlineRedefinitionResponse, error) {
+	step := func(st dbdpb.OnlineRedefinitionProgress_Step) {
+		klog.InfoS("dbdaemon/StartOnlineRedefinition", "table", r.schema+"."+r.table, "step", st)
+		if err := lro.ReportProgress(ctx, &dbdpb.OnlineRedefinitionProgress{Step: st, StepStartTime: timestamppb.Now()}); err != nil {
+			klog.ErrorS(err, "dbdaemon/StartOnlineRedefinition: failed to report the progress", "step", st)
+		}
+	}
+	run := func(cmds []string) error {
+		_, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds}, false)
+		return err
+	}
+
+	sourceColumns, err := s.redefColumns(ctx, r, r.table)
+	if err != nil {
+		return nil, err
+	}
+	interimColumns, err := s.redefColumns(ctx, r, r.interim)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := r.columnMapping(sourceColumns, interimColumns)
+	if err != nil {
+		return nil, err
+	}
+	pks, err := s.redefQuery(ctx, r, fmt.Sprintf(redefPrimaryKeySQL, sqlq.StringParam(r.schema), sqlq.StringParam(r.table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the primary key of %s.%s: %v", r.schema, r.table, err)
+	}
+	resp := &dbdpb.StartOnlineRedefinitionResponse{Method: redefMethodRowid}
+	if len(pks) == 1 && pks[0]["COUNT"] != "0" {
+		resp.Method = redefMethodPK
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_CAN_REDEF)
+	if err := run([]string{
+		sqlq.QuerySetSessionContainer(r.pdb),
+		fmt.Sprintf(canRedefCmd, sqlq.StringParam(r.schema), sqlq.StringParam(r.table), redefOptions[resp.GetMethod()]),
+	}); err != nil {
+		return nil, fmt.Errorf("%s.%s can't be redefined online: %v", r.schema, r.table, err)
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_START_REDEF)
+	if err := run([]string{
+		sqlq.QuerySetSessionContainer(r.pdb),
+		fmt.Sprintf(startRedefCmd, sqlq.StringParam(r.schema), sqlq.StringParam(r.table), sqlq.StringParam(r.interim),
+			sqlq.StringParam(mapping), redefOptions[resp.GetMethod()]),
+	}); err != nil {
+		// A failed start_redef_table can leave a materialized view log
+		// behind, abort cleans it up.
+		return nil, s.abortRedefinition(ctx, r, fmt.Errorf("failed to start the redefinition of %s.%s: %v", r.schema, r.table, err))
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_COPY_DEPENDENTS)
+	if err := run(r.cmd(copyDependentsCmd)); err != nil {
+		return nil, s.abortRedefinition(ctx, r, fmt.Errorf("failed to copy the dependents of %s.%s: %v", r.schema, r.table, err))
+	}
+	errs, err := s.redefQuery(ctx, r, fmt.Sprintf(redefErrorsSQL, sqlq.StringParam(r.schema), sqlq.StringParam(r.table)))
+	if err != nil {
+		return nil, s.abortRedefinition(ctx, r, fmt.Errorf("failed to query the dependents which failed to be copied: %v", err))
+	}
+	for _, row := range errs {
+		resp.DependentErrors = append(resp.DependentErrors,
+			fmt.Sprintf("%s %s.%s: %s", row["OBJECT_TYPE"], row["OBJECT_OWNER"], row["OBJECT_NAME"], strings.TrimSpace(row["ERR_TXT"])))
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_SYNC_INTERIM)
+	if err := run(r.cmd(syncInterimCmd)); err != nil {
+		return nil, s.abortRedefinition(ctx, r, fmt.Errorf("failed to sync the interim table %s.%s: %v", r.schema, r.interim, err))
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_FINISH_REDEF)
+	if err := run(r.cmd(finishRedefCmd)); err != nil {
+		return nil, s.abortRedefinition(ctx, r, fmt.Errorf("failed to finish the redefinition of %s.%s: %v", r.schema, r.table, err))
+	}
+
+	step(dbdpb.OnlineRedefinitionProgress_DONE)
+	return resp, nil
+}
+
+// abortRedefinition aborts a started redefinition, returning the error
+// which made it fail.
+func (s *Server) abortRedefinition(ctx context.Context, r *redefinition, cause error) error {
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: r.cmd(abortRedefCmd)}, false); err != nil {
+		klog.ErrorS(err, "dbdaemon/StartOnlineRedefinition: failed to abort the redefinition", "table", r.schema+"."+r.table)
+		return fmt.Errorf("%v, then failed to abort the redefinition: %v", cause, err)
+	}
+	return cause
+}
+
+// StartOnlineRedefinition changes the structure of a table, renaming its
+// columns for instance, while it stays available using DBMS_REDEFINITION.
+// The interim table must exist with the new structure, it holds the
+// original structure once the redefinition finishes.
+func (s *Server) StartOnlineRedefinition(ctx context.Context, req *dbdpb.StartOnlineRedefinitionRequest) (*lropb.Operation, error) {
+	klog.InfoS("dbdaemon/StartOnlineRedefinition", "req", loggableRequest(req))
+	r, err := newRedefinition(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/StartOnlineRedefinition: %v", err)
+	}
+	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "OnlineRedefinition", s.lroServer,
+		func(ctx context.Context) (proto.Message, error) {
+			// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+			// Only add lock in top level API to avoid deadlock.
+			s.databaseSid.Lock()
+			defer s.databaseSid.Unlock()
+			resp, err := s.onlineRedefinition(ctx, r)
+			if err != nil {
+				klog.ErrorS(err, "dbdaemon/StartOnlineRedefinition failed", "table", r.schema+"."+r.table)
+				return nil, fmt.Errorf("dbdaemon/StartOnlineRedefinition: %v", err)
+			}
+			return resp, nil
+		})
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/StartOnlineRedefinition failed to create an LRO job", "request", loggableRequest(req))
+		return nil, err
+	}
+	return &lropb.Operation{Name: job.ID(), Done: false}, nil
+}
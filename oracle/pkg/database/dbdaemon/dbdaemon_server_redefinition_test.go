@@ -0,0 +1,269 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+// fakeRedefinitionDB answers the queries of an online redefinition of
+// SCOTT.EMP into SCOTT.EMP_INTERIM and records the DBMS_REDEFINITION
+// calls, failing the first one containing failOn.
+type fakeRedefinitionDB struct {
+	primaryKey bool
+	failOn     string
+	calls      []string
+	// onCall is run before each DBMS_REDEFINITION call.
+	onCall func(call string)
+}
+
+func (f *fakeRedefinitionDB) runSQL(sqls []string) ([]string, error) {
+	call := sqls[len(sqls)-1]
+	if f.onCall != nil {
+		f.onCall(call)
+	}
+	f.calls = append(f.calls, call)
+	if f.failOn != "" && strings.Contains(call, f.failOn) {
+		return nil, fmt.Errorf("ORA-12089: failed %q", call)
+	}
+	return nil, nil
+}
+
+func (f *fakeRedefinitionDB) runQuery(sqls []string) ([]string, error) {
+	if sqls[0] != `alter session set container="PDB1"` {
+		return nil, fmt.Errorf("unexpected container %q", sqls[0])
+	}
+	switch sqls[1] {
+	case fmt.Sprintf(redefColumnsSQL, "SCOTT", "EMP"):
+		return []string{`{"COLUMN_NAME":"EMPNO"}`, `{"COLUMN_NAME":"ENAME"}`, `{"COLUMN_NAME":"SAL"}`}, nil
+	case fmt.Sprintf(redefColumnsSQL, "SCOTT", "EMP_INTERIM"):
+		return []string{`{"COLUMN_NAME":"EMPNO"}`, `{"COLUMN_NAME":"EMPLOYEE_NAME"}`, `{"COLUMN_NAME":"SAL"}`}, nil
+	case fmt.Sprintf(redefPrimaryKeySQL, "SCOTT", "EMP"):
+		if f.primaryKey {
+			return []string{`{"COUNT":"1"}`}, nil
+		}
+		return []string{`{"COUNT":"0"}`}, nil
+	case fmt.Sprintf(redefErrorsSQL, "SCOTT", "EMP"):
+		return []string{`{"OBJECT_TYPE":"INDEX","OBJECT_OWNER":"SCOTT","OBJECT_NAME":"EMP_ENAME_IDX","ERR_TXT":"ORA-00904: \"ENAME\": invalid identifier\n"}`}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", sqls)
+}
+
+// waitForOperation polls an operation until it's done.
+func waitForOperation(ctx context.Context, t *testing.T, s *Server, name string) *lropb.Operation {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		op, err := s.GetOperation(ctx, &lropb.GetOperationRequest{Name: name})
+		if err != nil {
+			t.Fatalf("GetOperation(%q) failed: %v", name, err)
+		}
+		if op.GetDone() {
+			return op
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("operation %q isn't done", name)
+	return nil
+}
+
+func TestStartOnlineRedefinition(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	const mapping = `"EMPNO" "EMPNO", "ENAME" "EMPLOYEE_NAME", "SAL" "SAL"`
+	canRedef := func(option string) string {
+		return fmt.Sprintf("begin dbms_redefinition.can_redef_table(uname => 'SCOTT', tname => 'EMP', options_flag => %s); end;", option)
+	}
+	startRedef := func(option string) string {
+		return fmt.Sprintf("begin dbms_redefinition.start_redef_table(uname => 'SCOTT', orig_table => 'EMP', int_table => 'EMP_INTERIM', "+
+			"col_mapping => '%s', options_flag => %s); end;", mapping, option)
+	}
+	copyDependents := fmt.Sprintf(copyDependentsCmd, "SCOTT", "EMP", "EMP_INTERIM")
+	syncInterim := fmt.Sprintf(syncInterimCmd, "SCOTT", "EMP", "EMP_INTERIM")
+	finishRedef := fmt.Sprintf(finishRedefCmd, "SCOTT", "EMP", "EMP_INTERIM")
+	abortRedef := fmt.Sprintf(abortRedefCmd, "SCOTT", "EMP", "EMP_INTERIM")
+	dependentErrors := []string{`INDEX SCOTT.EMP_ENAME_IDX: ORA-00904: "ENAME": invalid identifier`}
+
+	tests := []struct {
+		name       string
+		primaryKey bool
+		failOn     string
+		wantCalls  []string
+		wantResp   *dbdpb.StartOnlineRedefinitionResponse
+		wantErr    bool
+	}{
+		{
+			name:       "by primary key",
+			primaryKey: true,
+			wantCalls: []string{
+				canRedef("dbms_redefinition.cons_use_pk"), startRedef("dbms_redefinition.cons_use_pk"),
+				copyDependents, syncInterim, finishRedef,
+			},
+			wantResp: &dbdpb.StartOnlineRedefinitionResponse{Method: "PK", DependentErrors: dependentErrors},
+		},
+		{
+			name: "by rowid",
+			wantCalls: []string{
+				canRedef("dbms_redefinition.cons_use_rowid"), startRedef("dbms_redefinition.cons_use_rowid"),
+				copyDependents, syncInterim, finishRedef,
+			},
+			wantResp: &dbdpb.StartOnlineRedefinitionResponse{Method: "ROWID", DependentErrors: dependentErrors},
+		},
+		{
+			name:       "can't redefine",
+			primaryKey: true,
+			failOn:     "can_redef_table",
+			wantCalls:  []string{canRedef("dbms_redefinition.cons_use_pk")},
+			wantErr:    true,
+		},
+		{
+			name:       "failed sync aborts",
+			primaryKey: true,
+			failOn:     "sync_interim_table",
+			wantCalls: []string{
+				canRedef("dbms_redefinition.cons_use_pk"), startRedef("dbms_redefinition.cons_use_pk"),
+				copyDependents, syncInterim, abortRedef,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			s.lroServer = lro.NewServer(ctx)
+			fake := &fakeRedefinitionDB{primaryKey: tc.primaryKey, failOn: tc.failOn}
+			db := useFakeSQLDB(s)
+			db.runSQLFunc = fake.runSQL
+			db.runQueryFunc = fake.runQuery
+
+			opID := "redef-" + strings.ReplaceAll(tc.name, " ", "-")
+			var steps []dbdpb.OnlineRedefinitionProgress_Step
+			fake.onCall = func(string) {
+				op, err := s.GetOperation(ctx, &lropb.GetOperationRequest{Name: opID})
+				if err != nil {
+					t.Errorf("GetOperation(%q) failed: %v", opID, err)
+					return
+				}
+				progress := &dbdpb.OnlineRedefinitionProgress{}
+				if err := op.GetMetadata().UnmarshalTo(progress); err != nil {
+					t.Errorf("failed to unmarshal the operation metadata: %v", err)
+					return
+				}
+				steps = append(steps, progress.GetStep())
+			}
+
+			op, err := s.StartOnlineRedefinition(ctx, &dbdpb.StartOnlineRedefinitionRequest{
+				PdbName:        "pdb1",
+				Schema:         "scott",
+				Table:          "emp",
+				InterimTable:   "emp_interim",
+				RenamedColumns: map[string]string{"employee_name": "ename"},
+				LroInput:       &dbdpb.LROInput{OperationId: opID},
+			})
+			if err != nil {
+				t.Fatalf("StartOnlineRedefinition failed: %v", err)
+			}
+			op = waitForOperation(ctx, t, s, op.GetName())
+
+			if diff := cmp.Diff(tc.wantCalls, fake.calls); diff != "" {
+				t.Errorf("StartOnlineRedefinition got unexpected calls (-want +got):\n%v", diff)
+			}
+			if gotErr := op.GetError() != nil; gotErr != tc.wantErr {
+				t.Fatalf("StartOnlineRedefinition got error %v, want error %v", op.GetError(), tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			resp := &dbdpb.StartOnlineRedefinitionResponse{}
+			if err := op.GetResponse().UnmarshalTo(resp); err != nil {
+				t.Fatalf("failed to unmarshal the operation response: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantResp, resp, protocmp.Transform()); diff != "" {
+				t.Errorf("StartOnlineRedefinition got unexpected response (-want +got):\n%v", diff)
+			}
+			wantSteps := []dbdpb.OnlineRedefinitionProgress_Step{
+				dbdpb.OnlineRedefinitionProgress_CAN_REDEF,
+				dbdpb.OnlineRedefinitionProgress_START_REDEF,
+				dbdpb.OnlineRedefinitionProgress_COPY_DEPENDENTS,
+				dbdpb.OnlineRedefinitionProgress_SYNC_INTERIM,
+				dbdpb.OnlineRedefinitionProgress_FINISH_REDEF,
+			}
+			if diff := cmp.Diff(wantSteps, steps); diff != "" {
+				t.Errorf("StartOnlineRedefinition reported unexpected steps (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestStartOnlineRedefinitionInvalid(t *testing.T) {
+	ctx := context.Background()
+	valid := func() *dbdpb.StartOnlineRedefinitionRequest {
+		return &dbdpb.StartOnlineRedefinitionRequest{PdbName: "pdb1", Schema: "scott", Table: "emp", InterimTable: "emp_interim"}
+	}
+	tests := []struct {
+		name   string
+		modify func(*dbdpb.StartOnlineRedefinitionRequest)
+	}{
+		{name: "no PDB", modify: func(r *dbdpb.StartOnlineRedefinitionRequest) { r.PdbName = "" }},
+		{name: "no schema", modify: func(r *dbdpb.StartOnlineRedefinitionRequest) { r.Schema = "" }},
+		{name: "quoted table", modify: func(r *dbdpb.StartOnlineRedefinitionRequest) { r.Table = `emp"; drop table x; --` }},
+		{name: "same interim table", modify: func(r *dbdpb.StartOnlineRedefinitionRequest) { r.InterimTable = "EMP" }},
+		{name: "quoted column", modify: func(r *dbdpb.StartOnlineRedefinitionRequest) { r.RenamedColumns = map[string]string{"a": `b"`} }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			req := valid()
+			tc.modify(req)
+			if _, err := s.StartOnlineRedefinition(ctx, req); err == nil {
+				t.Errorf("StartOnlineRedefinition(%v) succeeded, want error", req)
+			}
+		})
+	}
+}
+
+func TestColumnMapping(t *testing.T) {
+	r := &redefinition{schema: "SCOTT", table: "EMP", interim: "EMP_INTERIM", renamed: map[string]string{"EMPLOYEE_NAME": "ENAME"}}
+	got, err := r.columnMapping([]string{"EMPNO", "ENAME"}, []string{"EMPNO", "EMPLOYEE_NAME", "HIRED"})
+	if err != nil {
+		t.Fatalf("columnMapping failed: %v", err)
+	}
+	if want := `"EMPNO" "EMPNO", "ENAME" "EMPLOYEE_NAME"`; got != want {
+		t.Errorf("columnMapping got %q, want %q", got, want)
+	}
+
+	if _, err := r.columnMapping([]string{"EMPNO", "NAME"}, []string{"EMPNO", "EMPLOYEE_NAME"}); err == nil {
+		t.Errorf("columnMapping renaming a missing column succeeded, want error")
+	}
+	if _, err := r.columnMapping([]string{"EMPNO", "ENAME"}, []string{"EMPNO", "NAME"}); err == nil {
+		t.Errorf("columnMapping renaming into a missing column succeeded, want error")
+	}
+}
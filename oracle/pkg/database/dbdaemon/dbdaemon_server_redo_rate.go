@@ -0,0 +1,159 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	defaultRedoRateWindowHours    = 7 * 24
+	defaultRedoRateRetentionHours = 24
+	// redoLogSwitchesPerHour is the log switch rate at the peak redo rate
+	// the recommended redo log size aims for.
+	redoLogSwitchesPerHour = 3
+	minRedoLogSize         = 256 << 20
+	redoLogSizeUnit        = 64 << 20
+	// logDiskHeadroom is added to the archived logs the retention needs
+	// for the redo generated above the observed peak.
+	logDiskHeadroom = 0.25
+	logDiskSizeUnit = 1 << 30
+)
+
+const redoSizeSQL = "select to_char(value) as value from v$sysstat where name = 'redo size'"
+
+// archivedRedoSQL sums the archived redo by hour over the last %d hours.
+// Logs archived to several destinations are counted once, by thread and
+// sequence. The hour is reported with the UTC offset of the database host.
+const archivedRedoSQL = "select to_char(trunc(first_time, 'HH24'), 'YYYY-MM-DD\"T\"HH24:MI:SS') || to_char(systimestamp, 'TZH:TZM') as start_time, " +
+	"to_char(sum(bytes)) as bytes " +
+	"from (select distinct thread#, sequence#, first_time, blocks * block_size as bytes from v$archived_log " +
+	"where standby_dest = 'NO' and first_time >= sysdate - %d/24) " +
+	"group by trunc(first_time, 'HH24') order by trunc(first_time, 'HH24')"
+
+// parseArchivedRedo converts archivedRedoSQL rows into the response
+// representation.
+func parseArchivedRedo(rows []string) ([]*dbdpb.GetRedoRateResponse_Hour, error) {
+	var hours []*dbdpb.GetRedoRateResponse_Hour
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse archived redo row %q: %v", msg, err)
+		}
+		t, err := time.Parse(time.RFC3339, row["START_TIME"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse START_TIME in archived redo row %q: %v", msg, err)
+		}
+		bytes, err := strconv.ParseInt(row["BYTES"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BYTES in archived redo row %q: %v", msg, err)
+		}
+		hours = append(hours, &dbdpb.GetRedoRateResponse_Hour{StartTime: timestamppb.New(t), Bytes: bytes})
+	}
+	return hours, nil
+}
+
+// redoRate computes the rates and recommendations of a GetRedoRate
+// response from the redo archived in each hour of the window.
+func redoRate(hours []*dbdpb.GetRedoRateResponse_Hour, windowHours, retentionHours int32) *dbdpb.GetRedoRateResponse {
+	resp := &dbdpb.GetRedoRateResponse{Hours: hours}
+	var byHourOfDay [24]int64
+	for _, h := range hours {
+		resp.WindowBytes += h.GetBytes()
+		if h.GetBytes() > resp.PeakBytesPerHour {
+			resp.PeakBytesPerHour = h.GetBytes()
+		}
+		byHourOfDay[h.GetStartTime().AsTime().UTC().Hour()] += h.GetBytes()
+	}
+	resp.AverageBytesPerSecond = float64(resp.WindowBytes) / (float64(windowHours) * time.Hour.Seconds())
+	for hour, bytes := range byHourOfDay {
+		if bytes < byHourOfDay[resp.QuietestHourUtc] {
+			resp.QuietestHourUtc = int32(hour)
+		}
+	}
+
+	resp.RecommendedRedoLogSizeBytes = roundUp(resp.PeakBytesPerHour/redoLogSwitchesPerHour, redoLogSizeUnit)
+	if resp.RecommendedRedoLogSizeBytes < minRedoLogSize {
+		resp.RecommendedRedoLogSizeBytes = minRedoLogSize
+	}
+	retained := float64(resp.PeakBytesPerHour) * float64(retentionHours) * (1 + logDiskHeadroom)
+	resp.RecommendedLogDiskBytes = roundUp(int64(retained), logDiskSizeUnit)
+	return resp
+}
+
+// roundUp rounds n up to a multiple of unit.
+func roundUp(n, unit int64) int64 {
+	return (n + unit - 1) / unit * unit
+}
+
+// GetRedoRate returns the redo generated by the database, hour by hour from
+// v$archived_log and in total from v$sysstat, and the online redo log size,
+// log disk size and backup hour that redo calls for.
+func (s *Server) GetRedoRate(ctx context.Context, req *dbdpb.GetRedoRateRequest) (*dbdpb.GetRedoRateResponse, error) {
+	klog.InfoS("dbdaemon/GetRedoRate", "req", loggableRequest(req))
+	windowHours := req.GetWindowHours()
+	if windowHours == 0 {
+		windowHours = defaultRedoRateWindowHours
+	}
+	if windowHours < 24 {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: window of %d hours is shorter than a day", windowHours)
+	}
+	retentionHours := req.GetRetentionHours()
+	if retentionHours == 0 {
+		retentionHours = defaultRedoRateRetentionHours
+	}
+	if retentionHours < 0 {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: invalid retention of %d hours", retentionHours)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf(archivedRedoSQL, windowHours)}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: failed to query archived logs: %v", err)
+	}
+	hours, err := parseArchivedRedo(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: %v", err)
+	}
+	rate := redoRate(hours, windowHours, retentionHours)
+
+	resp, err = s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{redoSizeSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: failed to query redo size: %v", err)
+	}
+	if len(resp.GetMsg()) != 1 {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: got %d redo size rows, want 1", len(resp.GetMsg()))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(resp.GetMsg()[0]), &row); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: failed to parse redo size row %q: %v", resp.GetMsg()[0], err)
+	}
+	if rate.RedoSizeBytes, err = strconv.ParseInt(row["VALUE"], 10, 64); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetRedoRate: failed to parse VALUE in redo size row %q: %v", resp.GetMsg()[0], err)
+	}
+	return rate, nil
+}
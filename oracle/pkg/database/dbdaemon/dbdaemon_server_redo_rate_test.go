@@ -0,0 +1,149 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const mib = 1 << 20
+
+// archivedRedoSeries returns the redo archived in each hour of two days,
+// 600 MiB an hour except for a quiet 03:00 UTC and a busy afternoon.
+func archivedRedoSeries() []*dbdpb.GetRedoRateResponse_Hour {
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	var hours []*dbdpb.GetRedoRateResponse_Hour
+	for i := 0; i < 48; i++ {
+		t := start.Add(time.Duration(i) * time.Hour)
+		bytes := int64(600 * mib)
+		switch {
+		case t.Hour() == 3:
+			bytes = 60 * mib
+		case i == 14:
+			bytes = 1500 * mib
+		}
+		hours = append(hours, &dbdpb.GetRedoRateResponse_Hour{StartTime: timestamppb.New(t), Bytes: bytes})
+	}
+	return hours
+}
+
+func TestRedoRate(t *testing.T) {
+	series := archivedRedoSeries()
+	tests := []struct {
+		name           string
+		hours          []*dbdpb.GetRedoRateResponse_Hour
+		windowHours    int32
+		retentionHours int32
+		want           *dbdpb.GetRedoRateResponse
+	}{
+		{
+			name:           "two days",
+			hours:          series,
+			windowHours:    48,
+			retentionHours: 24,
+			want: &dbdpb.GetRedoRateResponse{
+				Hours:                       series,
+				WindowBytes:                 28620 * mib,
+				AverageBytesPerSecond:       28620 * mib / (48 * 3600.0),
+				PeakBytesPerHour:            1500 * mib,
+				RecommendedRedoLogSizeBytes: 512 * mib,
+				RecommendedLogDiskBytes:     44 << 30,
+				QuietestHourUtc:             3,
+			},
+		},
+		{
+			name:           "window longer than the history",
+			hours:          series,
+			windowHours:    96,
+			retentionHours: 6,
+			want: &dbdpb.GetRedoRateResponse{
+				Hours:                       series,
+				WindowBytes:                 28620 * mib,
+				AverageBytesPerSecond:       28620 * mib / (96 * 3600.0),
+				PeakBytesPerHour:            1500 * mib,
+				RecommendedRedoLogSizeBytes: 512 * mib,
+				RecommendedLogDiskBytes:     11 << 30,
+				QuietestHourUtc:             3,
+			},
+		},
+		{
+			name:           "no archived logs",
+			windowHours:    24,
+			retentionHours: 24,
+			want: &dbdpb.GetRedoRateResponse{
+				RecommendedRedoLogSizeBytes: minRedoLogSize,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := redoRate(tc.hours, tc.windowHours, tc.retentionHours)
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("redoRate got unexpected result (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestGetRedoRate(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var archivedRedoRows []string
+	for _, h := range archivedRedoSeries() {
+		// The database host reports its local time.
+		start := h.GetStartTime().AsTime().In(time.FixedZone("", 2*60*60)).Format(time.RFC3339)
+		archivedRedoRows = append(archivedRedoRows, fmt.Sprintf(`{"START_TIME":%q,"BYTES":"%d"}`, start, h.GetBytes()))
+	}
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		switch query := sqls[len(sqls)-1]; {
+		case query == redoSizeSQL:
+			return []string{`{"VALUE":"123456789012"}`}, nil
+		case strings.Contains(query, "v$archived_log") && strings.Contains(query, "sysdate - 48/24"):
+			return archivedRedoRows, nil
+		default:
+			t.Fatalf("runQuery got unexpected query %q", sqls)
+			return nil, nil
+		}
+	}
+
+	resp, err := s.GetRedoRate(ctx, &dbdpb.GetRedoRateRequest{WindowHours: 48})
+	if err != nil {
+		t.Fatalf("GetRedoRate failed: %v", err)
+	}
+	want := redoRate(archivedRedoSeries(), 48, defaultRedoRateRetentionHours)
+	want.RedoSizeBytes = 123456789012
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("GetRedoRate got unexpected result (-want +got):\n%v", diff)
+	}
+
+	if _, err := s.GetRedoRate(ctx, &dbdpb.GetRedoRateRequest{WindowHours: 12}); err == nil {
+		t.Errorf("GetRedoRate with a 12 hour window succeeded, want error")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var (
+	// redoCompressionRe matches the RedoCompression property in the output
+	// of dgmgrl show database.
+	redoCompressionRe = regexp.MustCompile(`(?i)RedoCompression\s*=\s*'(\w+)'`)
+
+	// runDataGuard runs dgmgrl commands, tests replace it.
+	runDataGuard = (*Server).RunDataGuard
+)
+
+// redoCompressionValue returns the RedoCompression property value the
+// broker sets as the COMPRESSION attribute of the log_archive_dest shipping
+// redo to the standby.
+func redoCompressionValue(compression bool) string {
+	if compression {
+		return "ENABLE"
+	}
+	return "DISABLE"
+}
+
+// redoCompressionCommands returns the dgmgrl commands reading and setting
+// the RedoCompression property of the standby.
+func redoCompressionCommands(standbyUniqueName string, compression bool) (show, edit string) {
+	return fmt.Sprintf("show database %s RedoCompression", standbyUniqueName),
+		fmt.Sprintf("edit database %s set property RedoCompression='%s'", standbyUniqueName, redoCompressionValue(compression))
+}
+
+// parseRedoCompression returns the RedoCompression property of the output
+// of dgmgrl show database.
+func parseRedoCompression(output string) (string, error) {
+	m := redoCompressionRe.FindStringSubmatch(output)
+	if m == nil {
+		return "", fmt.Errorf("failed to find RedoCompression in %q", output)
+	}
+	return strings.ToUpper(m[1]), nil
+}
+
+// ConfigureRedoTransport sets the compression of the redo shipped to the
+// standby and requires its encryption in the standby listener sqlnet.ora.
+func (s *Server) ConfigureRedoTransport(ctx context.Context, req *dbdpb.ConfigureRedoTransportRequest) (*dbdpb.ConfigureRedoTransportResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureRedoTransport", "req", loggableRequest(req))
+	standby := req.GetStandbyDbUniqueName()
+	if _, err := sqlq.ObjectName(standby); err != nil || standby == "" {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: invalid standby DB unique name %q", standby)
+	}
+	if req.GetCompression() {
+		if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: %v", err)
+		}
+	}
+	resp := &dbdpb.ConfigureRedoTransportResponse{}
+
+	if req.GetEncryption() {
+		params, err := networkEncryptionParams(&dbdpb.ConfigureNetworkEncryptionRequest{Algorithms: req.GetEncryptionAlgorithms()})
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: %v", err)
+		}
+		changed, err := s.updateListenerSQLNet(ctx, filepath.Join(listenerDir(), consts.SECURE), params)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: %v", err)
+		}
+		resp.SqlnetChanged = changed
+	}
+
+	show, edit := redoCompressionCommands(standby, req.GetCompression())
+	out, err := runDataGuard(s, ctx, &dbdpb.RunDataGuardRequest{Scripts: []string{show}})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: failed to read the redo compression: %v", err)
+	}
+	current, err := parseRedoCompression(strings.Join(out.GetOutput(), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: %v", err)
+	}
+	if current != redoCompressionValue(req.GetCompression()) {
+		if _, err := runDataGuard(s, ctx, &dbdpb.RunDataGuardRequest{Scripts: []string{edit}}); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRedoTransport: failed to set the redo compression: %v", err)
+		}
+		resp.CompressionChanged = true
+	}
+	klog.InfoS("dbdaemon/ConfigureRedoTransport done", "compressionChanged", resp.GetCompressionChanged(), "sqlnetChanged", resp.GetSqlnetChanged())
+	return resp, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestRedoCompressionCommands(t *testing.T) {
+	for _, tc := range []struct {
+		compression bool
+		wantEdit    string
+	}{
+		{compression: true, wantEdit: "edit database STBY set property RedoCompression='ENABLE'"},
+		{compression: false, wantEdit: "edit database STBY set property RedoCompression='DISABLE'"},
+	} {
+		show, edit := redoCompressionCommands("STBY", tc.compression)
+		if show != "show database STBY RedoCompression" {
+			t.Errorf("redoCompressionCommands got show command %q", show)
+		}
+		if edit != tc.wantEdit {
+			t.Errorf("redoCompressionCommands(%v) got edit command %q, want %q", tc.compression, edit, tc.wantEdit)
+		}
+	}
+}
+
+func TestParseRedoCompression(t *testing.T) {
+	got, err := parseRedoCompression("Connected to \"STBY\"\n  RedoCompression = 'enable'\n")
+	if err != nil {
+		t.Fatalf("parseRedoCompression failed: %v", err)
+	}
+	if got != "ENABLE" {
+		t.Errorf("parseRedoCompression got %q, want ENABLE", got)
+	}
+	if _, err := parseRedoCompression("ORA-16525: The Oracle Data Guard broker is not yet available."); err == nil {
+		t.Errorf("parseRedoCompression of an error succeeded, want error")
+	}
+}
+
+// useFakeDataGuard answers the show commands with the RedoCompression
+// property and records the other commands.
+func useFakeDataGuard(t *testing.T, redoCompression string) *[]string {
+	t.Helper()
+	orig := runDataGuard
+	var edits []string
+	runDataGuard = func(s *Server, ctx context.Context, req *dbdpb.RunDataGuardRequest) (*dbdpb.RunDataGuardResponse, error) {
+		var out []string
+		for _, script := range req.GetScripts() {
+			if strings.HasPrefix(script, "show ") {
+				out = append(out, "  RedoCompression = '"+redoCompression+"'")
+				continue
+			}
+			edits = append(edits, script)
+			out = append(out, "Property \"RedoCompression\" updated")
+		}
+		return &dbdpb.RunDataGuardResponse{Output: out}, nil
+	}
+	t.Cleanup(func() { runDataGuard = orig })
+	return &edits
+}
+
+func TestConfigureRedoTransport(t *testing.T) {
+	ctx := context.Background()
+	useWritableRoots(t, t.TempDir(), t.TempDir())
+	origLicense := licenseFile
+	licenseFile = filepath.Join(t.TempDir(), "license.json")
+	t.Cleanup(func() { licenseFile = origLicense })
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.license = &licenseState{}
+	dir := filepath.Join(listenerDir(), consts.SECURE)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("failed to create %s: %v", dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, sqlNetFile), []byte("sqlnet.expire_time = 15\n"), 0600); err != nil {
+		t.Fatalf("failed to write sqlnet.ora: %v", err)
+	}
+	req := &dbdpb.ConfigureRedoTransportRequest{StandbyDbUniqueName: "STBY", Compression: true, Encryption: true}
+
+	useFakeDataGuard(t, "DISABLE")
+	if _, err := s.ConfigureRedoTransport(ctx, req); err == nil {
+		t.Fatalf("ConfigureRedoTransport with compression without a license succeeded, want error")
+	}
+
+	if _, err := s.SetLicense(ctx, &dbdpb.SetLicenseRequest{LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_ADVANCED_COMPRESSION}}); err != nil {
+		t.Fatalf("SetLicense failed: %v", err)
+	}
+	edits := useFakeDataGuard(t, "DISABLE")
+	got, err := s.ConfigureRedoTransport(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureRedoTransport failed: %v", err)
+	}
+	if !got.GetCompressionChanged() || !got.GetSqlnetChanged() {
+		t.Errorf("ConfigureRedoTransport got %v, want the compression and sqlnet.ora changed", got)
+	}
+	if diff := cmp.Diff([]string{"edit database STBY set property RedoCompression='ENABLE'"}, *edits); diff != "" {
+		t.Errorf("ConfigureRedoTransport ran unexpected broker commands (-want +got):\n%v", diff)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, sqlNetFile))
+	if err != nil {
+		t.Fatalf("failed to read sqlnet.ora: %v", err)
+	}
+	if !strings.Contains(string(content), "sqlnet.encryption_server = REQUIRED") {
+		t.Errorf("ConfigureRedoTransport wrote sqlnet.ora %q, want the encryption required", content)
+	}
+
+	edits = useFakeDataGuard(t, "ENABLE")
+	got, err = s.ConfigureRedoTransport(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureRedoTransport failed: %v", err)
+	}
+	if got.GetCompressionChanged() || got.GetSqlnetChanged() || len(*edits) != 0 {
+		t.Errorf("ConfigureRedoTransport of the current settings got %v and ran %v, want no change", got, *edits)
+	}
+
+	if _, err := s.ConfigureRedoTransport(ctx, &dbdpb.ConfigureRedoTransportRequest{StandbyDbUniqueName: `STBY"`}); err == nil {
+		t.Errorf("ConfigureRedoTransport with an invalid standby name succeeded, want error")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// sqlplusVersionRe matches the version sqlplus -V prints, e.g.
+// "Version 19.15.0.0.0" or, before 18c, "Release 12.2.0.1.0".
+var sqlplusVersionRe = regexp.MustCompile(`(?m)^(?:Version|SQL\*Plus: Release) (\d+(?:\.\d+)+)`)
+
+// homeVersion returns the full version of the Oracle home, overridden in
+// unit tests.
+var homeVersion = func(home string) (string, error) {
+	out, err := exec.Command(filepath.Join(home, "bin", "sqlplus"), "-V").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("sqlplus -V failed: %v: %s", err, out)
+	}
+	return parseSQLPlusVersion(string(out))
+}
+
+// parseSQLPlusVersion returns the full version in the output of sqlplus -V,
+// the Version line of 18c and later has the release update.
+func parseSQLPlusVersion(out string) (string, error) {
+	version := ""
+	for _, m := range sqlplusVersionRe.FindAllStringSubmatch(out, -1) {
+		version = m[1]
+	}
+	if version == "" {
+		return "", fmt.Errorf("no version in %q", out)
+	}
+	return version, nil
+}
+
+// oracleVersion is a parsed full version, its release is the part datafiles
+// are compatible within and its patch the release update in the release.
+type oracleVersion struct {
+	release []int
+	patch   []int
+}
+
+func parseOracleVersion(v string) (oracleVersion, error) {
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return oracleVersion{}, fmt.Errorf("invalid version %q", v)
+		}
+		parts = append(parts, n)
+	}
+	// Since 18c the first number is the release, e.g. 19.15 is the 15th
+	// release update of 19c, before it two numbers were, e.g. 12.2.0.1.
+	releaseLen := 1
+	if parts[0] < 18 {
+		releaseLen = 2
+	}
+	if len(parts) < releaseLen+1 {
+		return oracleVersion{}, fmt.Errorf("invalid version %q", v)
+	}
+	return oracleVersion{release: parts[:releaseLen], patch: parts[releaseLen:]}, nil
+}
+
+func compareVersionParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// restoreCompatibility returns why a backup taken on the source version can
+// not be restored into a home of the target version, an empty string if it
+// can. A backup restores into the same release at the same or a later
+// release update, datapatch brings the dictionary up to the target home.
+func restoreCompatibility(source, target string) (string, error) {
+	src, err := parseOracleVersion(source)
+	if err != nil {
+		return "", fmt.Errorf("source: %v", err)
+	}
+	tgt, err := parseOracleVersion(target)
+	if err != nil {
+		return "", fmt.Errorf("target: %v", err)
+	}
+	if compareVersionParts(src.release, tgt.release) != 0 {
+		return fmt.Sprintf("the backup was taken on release %s, the Oracle home is release %s, a restore across releases requires an upgrade", source, target), nil
+	}
+	if compareVersionParts(src.patch, tgt.patch) > 0 {
+		return fmt.Sprintf("the backup was taken on %s, a later patch level than the Oracle home %s, patch the instance to %s or later before the restore", source, target, source), nil
+	}
+	return "", nil
+}
+
+// CheckRestoreCompatibility checks that a backup taken on the source version
+// can be restored into the Oracle home the database runs from.
+func (s *Server) CheckRestoreCompatibility(ctx context.Context, req *dbdpb.CheckRestoreCompatibilityRequest) (*dbdpb.CheckRestoreCompatibilityResponse, error) {
+	klog.InfoS("dbdaemon/CheckRestoreCompatibility", "req", loggableRequest(req))
+	if req.GetSourceVersion() == "" {
+		return nil, fmt.Errorf("dbdaemon/CheckRestoreCompatibility: source version is empty")
+	}
+	target, err := homeVersion(s.databaseHome)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckRestoreCompatibility: failed to get the version of %s: %v", s.databaseHome, err)
+	}
+	reason, err := restoreCompatibility(req.GetSourceVersion(), target)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckRestoreCompatibility: %v", err)
+	}
+	return &dbdpb.CheckRestoreCompatibilityResponse{
+		TargetVersion: target,
+		Compatible:    reason == "",
+		Reason:        reason,
+	}, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseSQLPlusVersion(t *testing.T) {
+	for _, tc := range []struct {
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{
+			out:  "\nSQL*Plus: Release 19.0.0.0.0 - Production\nVersion 19.15.0.0.0\n\n",
+			want: "19.15.0.0.0",
+		},
+		{
+			out:  "\nSQL*Plus: Release 12.2.0.1.0 Production\n\n",
+			want: "12.2.0.1.0",
+		},
+		{
+			out:     "sqlplus: command not found",
+			wantErr: true,
+		},
+	} {
+		got, err := parseSQLPlusVersion(tc.out)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("parseSQLPlusVersion(%q) got error %v, want error %v", tc.out, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("parseSQLPlusVersion(%q) = %q, want %q", tc.out, got, tc.want)
+		}
+	}
+}
+
+func TestRestoreCompatibility(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		source         string
+		target         string
+		wantCompatible bool
+		wantReason     string
+		wantErr        bool
+	}{
+		{name: "same version", source: "19.15.0.0.0", target: "19.15.0.0.0", wantCompatible: true},
+		{name: "later target release update", source: "19.3.0.0.0", target: "19.15.0.0.0", wantCompatible: true},
+		{name: "later target revision", source: "19.15.0.0.0", target: "19.15.1.0.0", wantCompatible: true},
+		{name: "short target version", source: "19.3.0.0.0", target: "19.3", wantCompatible: true},
+		{name: "earlier target release update", source: "19.15.0.0.0", target: "19.3.0.0.0", wantReason: "later patch level"},
+		{name: "earlier target revision", source: "19.15.2.0.0", target: "19.15.1.0.0", wantReason: "later patch level"},
+		{name: "12c same release", source: "12.2.0.1.0", target: "12.2.0.1.0", wantCompatible: true},
+		{name: "12c different release", source: "12.1.0.2.0", target: "12.2.0.1.0", wantReason: "requires an upgrade"},
+		{name: "older release", source: "12.2.0.1.0", target: "19.3.0.0.0", wantReason: "requires an upgrade"},
+		{name: "newer release", source: "21.3.0.0.0", target: "19.15.0.0.0", wantReason: "requires an upgrade"},
+		{name: "invalid source", source: "19c", target: "19.3.0.0.0", wantErr: true},
+		{name: "source without patch", source: "19", target: "19.3.0.0.0", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, err := restoreCompatibility(tc.source, tc.target)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("restoreCompatibility(%q, %q) got error %v, want error %v", tc.source, tc.target, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := reason == ""; got != tc.wantCompatible {
+				t.Errorf("restoreCompatibility(%q, %q) got reason %q, want compatible %v", tc.source, tc.target, reason, tc.wantCompatible)
+			}
+			if !strings.Contains(reason, tc.wantReason) {
+				t.Errorf("restoreCompatibility(%q, %q) got reason %q, want it to contain %q", tc.source, tc.target, reason, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestCheckRestoreCompatibility(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	origHomeVersion := homeVersion
+	defer func() { homeVersion = origHomeVersion }()
+
+	homeVersion = func(home string) (string, error) {
+		if home != "DBHOME" {
+			t.Errorf("homeVersion got home %q, want DBHOME", home)
+		}
+		return "19.10.0.0.0", nil
+	}
+	resp, err := s.CheckRestoreCompatibility(ctx, &dbdpb.CheckRestoreCompatibilityRequest{SourceVersion: "19.15.0.0.0"})
+	if err != nil {
+		t.Fatalf("CheckRestoreCompatibility failed: %v", err)
+	}
+	if resp.GetCompatible() || resp.GetTargetVersion() != "19.10.0.0.0" || resp.GetReason() == "" {
+		t.Errorf("CheckRestoreCompatibility got %v, want an incompatible target 19.10.0.0.0 with a reason", resp)
+	}
+
+	resp, err = s.CheckRestoreCompatibility(ctx, &dbdpb.CheckRestoreCompatibilityRequest{SourceVersion: "19.3.0.0.0"})
+	if err != nil {
+		t.Fatalf("CheckRestoreCompatibility failed: %v", err)
+	}
+	if !resp.GetCompatible() {
+		t.Errorf("CheckRestoreCompatibility got %v, want compatible", resp)
+	}
+
+	if _, err := s.CheckRestoreCompatibility(ctx, &dbdpb.CheckRestoreCompatibilityRequest{}); err == nil {
+		t.Errorf("CheckRestoreCompatibility without a source version succeeded, want an error")
+	}
+
+	homeVersion = func(string) (string, error) { return "", errors.New("sqlplus -V failed") }
+	if _, err := s.CheckRestoreCompatibility(ctx, &dbdpb.CheckRestoreCompatibilityRequest{SourceVersion: "19.3.0.0.0"}); err == nil {
+		t.Errorf("CheckRestoreCompatibility with an unknown home version succeeded, want an error")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// restorePointsSQL reports the restore point time with the UTC offset of
+// the database host, time itself is a TIMESTAMP in the host time zone.
+const restorePointsSQL = "select name, to_char(scn) as scn, " +
+	"to_char(time, 'YYYY-MM-DD\"T\"HH24:MI:SS') || to_char(systimestamp, 'TZH:TZM') as time, " +
+	"guarantee_flashback_database, storage_size " +
+	"from v$restore_point order by scn"
+
+// parseRestorePoints converts restorePointsSQL rows into the response
+// representation.
+func parseRestorePoints(rows []string) ([]*dbdpb.RestorePoint, error) {
+	var points []*dbdpb.RestorePoint
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse restore point row %q: %v", msg, err)
+		}
+		t, err := time.Parse(time.RFC3339, row["TIME"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TIME in restore point row %q: %v", msg, err)
+		}
+		size, err := strconv.ParseInt(row["STORAGE_SIZE"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse STORAGE_SIZE in restore point row %q: %v", msg, err)
+		}
+		points = append(points, &dbdpb.RestorePoint{
+			Name:             row["NAME"],
+			Scn:              row["SCN"],
+			Time:             timestamppb.New(t),
+			Guaranteed:       row["GUARANTEE_FLASHBACK_DATABASE"] == "YES",
+			StorageSizeBytes: size,
+		})
+	}
+	return points, nil
+}
+
+// createRestorePointCmd returns the statement creating the restore point of
+// the request.
+func createRestorePointCmd(req *dbdpb.CreateRestorePointRequest) (string, error) {
+	name, err := sqlq.ObjectName(req.GetName())
+	if err != nil || req.GetName() == "" {
+		return "", fmt.Errorf("invalid restore point name %q", req.GetName())
+	}
+	if req.GetGuaranteed() {
+		return fmt.Sprintf("create restore point %s guarantee flashback database", name), nil
+	}
+	return fmt.Sprintf("create restore point %s", name), nil
+}
+
+// ListRestorePoints lists the restore points of the database from
+// v$restore_point.
+func (s *Server) ListRestorePoints(ctx context.Context, req *dbdpb.ListRestorePointsRequest) (*dbdpb.ListRestorePointsResponse, error) {
+	klog.InfoS("dbdaemon/ListRestorePoints", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{restorePointsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ListRestorePoints: failed to query restore points: %v", err)
+	}
+	points, err := parseRestorePoints(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ListRestorePoints: %v", err)
+	}
+	return &dbdpb.ListRestorePointsResponse{RestorePoints: points}, nil
+}
+
+// CreateRestorePoint creates a normal or a guaranteed restore point. A
+// guaranteed restore point requires the database to be in archivelog mode.
+func (s *Server) CreateRestorePoint(ctx context.Context, req *dbdpb.CreateRestorePointRequest) (*dbdpb.CreateRestorePointResponse, error) {
+	klog.InfoS("dbdaemon/CreateRestorePoint", "req", loggableRequest(req))
+	cmd, err := createRestorePointCmd(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateRestorePoint: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{cmd}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateRestorePoint: failed to create restore point %q: %v", req.GetName(), err)
+	}
+	return &dbdpb.CreateRestorePointResponse{}, nil
+}
+
+// DropRestorePoint drops a restore point. The flashback logs kept for a
+// guaranteed restore point become reclaimable FRA space.
+func (s *Server) DropRestorePoint(ctx context.Context, req *dbdpb.DropRestorePointRequest) (*dbdpb.DropRestorePointResponse, error) {
+	klog.InfoS("dbdaemon/DropRestorePoint", "req", loggableRequest(req))
+	name, err := sqlq.ObjectName(req.GetName())
+	if err != nil || req.GetName() == "" {
+		return nil, fmt.Errorf("dbdaemon/DropRestorePoint: invalid restore point name %q", req.GetName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{"drop restore point " + name}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/DropRestorePoint: failed to drop restore point %q: %v", req.GetName(), err)
+	}
+	return &dbdpb.DropRestorePointResponse{}, nil
+}
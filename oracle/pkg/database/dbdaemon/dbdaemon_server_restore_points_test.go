@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var restorePointRows = []string{
+	`{"NAME":"BEFORE_UPGRADE","SCN":"2345678","TIME":"2022-10-01T10:00:00+00:00","GUARANTEE_FLASHBACK_DATABASE":"YES","STORAGE_SIZE":"1073741824"}`,
+	`{"NAME":"NIGHTLY","SCN":"2456789","TIME":"2022-10-02T01:30:00+02:00","GUARANTEE_FLASHBACK_DATABASE":"NO","STORAGE_SIZE":"0"}`,
+}
+
+func TestParseRestorePoints(t *testing.T) {
+	got, err := parseRestorePoints(restorePointRows)
+	if err != nil {
+		t.Fatalf("parseRestorePoints failed: %v", err)
+	}
+	want := []*dbdpb.RestorePoint{
+		{
+			Name:             "BEFORE_UPGRADE",
+			Scn:              "2345678",
+			Time:             timestamppb.New(time.Date(2022, 10, 1, 10, 0, 0, 0, time.UTC)),
+			Guaranteed:       true,
+			StorageSizeBytes: 1073741824,
+		},
+		{
+			Name: "NIGHTLY",
+			Scn:  "2456789",
+			Time: timestamppb.New(time.Date(2022, 10, 1, 23, 30, 0, 0, time.UTC)),
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseRestorePoints got unexpected result (-want +got):\n%v", diff)
+	}
+
+	for _, row := range []string{
+		"not json",
+		`{"NAME":"RP","SCN":"1","TIME":"2022-10-01 10:00:00","GUARANTEE_FLASHBACK_DATABASE":"NO","STORAGE_SIZE":"0"}`,
+		`{"NAME":"RP","SCN":"1","TIME":"2022-10-01T10:00:00+00:00","GUARANTEE_FLASHBACK_DATABASE":"YES","STORAGE_SIZE":""}`,
+	} {
+		if _, err := parseRestorePoints([]string{row}); err == nil {
+			t.Errorf("parseRestorePoints(%q) succeeded, want error", row)
+		}
+	}
+}
+
+func TestCreateRestorePointCmd(t *testing.T) {
+	tests := []struct {
+		req     *dbdpb.CreateRestorePointRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			req:  &dbdpb.CreateRestorePointRequest{Name: "nightly"},
+			want: `create restore point "NIGHTLY"`,
+		},
+		{
+			req:  &dbdpb.CreateRestorePointRequest{Name: "before_upgrade", Guaranteed: true},
+			want: `create restore point "BEFORE_UPGRADE" guarantee flashback database`,
+		},
+		{
+			req:     &dbdpb.CreateRestorePointRequest{},
+			wantErr: true,
+		},
+		{
+			req:     &dbdpb.CreateRestorePointRequest{Name: `rp"; drop table t; --`},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		got, err := createRestorePointCmd(tc.req)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("createRestorePointCmd(%v) got error %v, want error %v", tc.req, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("createRestorePointCmd(%v) = %q, want %q", tc.req, got, tc.want)
+		}
+	}
+}
+
+func TestRestorePoints(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var statements []string
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if sqls[len(sqls)-1] != restorePointsSQL {
+			t.Fatalf("runQuery got unexpected query %q", sqls)
+		}
+		return restorePointRows, nil
+	}
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		statements = append(statements, sqls...)
+		return nil, nil
+	}
+
+	resp, err := s.ListRestorePoints(ctx, &dbdpb.ListRestorePointsRequest{})
+	if err != nil {
+		t.Fatalf("ListRestorePoints failed: %v", err)
+	}
+	if got, want := len(resp.GetRestorePoints()), len(restorePointRows); got != want {
+		t.Errorf("ListRestorePoints reported %d restore points, want %d", got, want)
+	}
+
+	if _, err := s.CreateRestorePoint(ctx, &dbdpb.CreateRestorePointRequest{Name: "rp1", Guaranteed: true}); err != nil {
+		t.Fatalf("CreateRestorePoint failed: %v", err)
+	}
+	if _, err := s.DropRestorePoint(ctx, &dbdpb.DropRestorePointRequest{Name: "rp1"}); err != nil {
+		t.Fatalf("DropRestorePoint failed: %v", err)
+	}
+	if _, err := s.DropRestorePoint(ctx, &dbdpb.DropRestorePointRequest{}); err == nil {
+		t.Errorf("DropRestorePoint without a name succeeded, want error")
+	}
+	want := []string{
+		`create restore point "RP1" guarantee flashback database`,
+		`drop restore point "RP1"`,
+	}
+	if diff := cmp.Diff(want, statements); diff != "" {
+		t.Errorf("restore point statements got unexpected result (-want +got):\n%v", diff)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	lropb "google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/lib/lro"
+)
+
+const (
+	needBackupCmd    = "report need backup;"
+	unrecoverableCmd = "report unrecoverable;"
+)
+
+// rmanCommandOutput is the output of an RMAN command, or its error which
+// includes the output.
+type rmanCommandOutput struct {
+	command string
+	output  string
+	err     error
+}
+
+// restoreValidationCommands returns the RMAN commands of the report, each
+// runs in its own session so that a failure doesn't skip the others.
+func restoreValidationCommands(checkLogical bool) []string {
+	validate := "restore database validate;"
+	if checkLogical {
+		validate = "restore database validate check logical;"
+	}
+	return []string{validate, needBackupCmd, unrecoverableCmd}
+}
+
+// rmanReportFiles returns the file names listed by an RMAN REPORT command,
+// the rows under the dashed header line which start with a file number.
+func rmanReportFiles(output string) []string {
+	var files []string
+	inRows := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			inRows = false
+		case strings.HasPrefix(fields[0], "----"):
+			inRows = true
+		case inRows && len(fields) > 1:
+			if _, err := strconv.Atoi(fields[0]); err == nil {
+				files = append(files, fields[len(fields)-1])
+			}
+		}
+	}
+	return files
+}
+
+// summarizeRestoreValidation fills the response from the outputs of the
+// restoreValidationCommands.
+func summarizeRestoreValidation(outputs []rmanCommandOutput) *dbdpb.RestoreValidationReportResponse {
+	resp := &dbdpb.RestoreValidationReportResponse{}
+	for i, out := range outputs {
+		if out.err != nil {
+			resp.FailedCommands = append(resp.FailedCommands, out.command)
+			continue
+		}
+		switch {
+		case i == 0:
+			resp.Restorable = true
+		case out.command == needBackupCmd:
+			resp.NeedBackupFiles = rmanReportFiles(out.output)
+		case out.command == unrecoverableCmd:
+			resp.UnrecoverableFiles = rmanReportFiles(out.output)
+		}
+	}
+	return resp
+}
+
+// formatRestoreValidationReport consolidates the summary and the outputs
+// of the RMAN commands into a text report.
+func formatRestoreValidationReport(generated time.Time, resp *dbdpb.RestoreValidationReportResponse, outputs []rmanCommandOutput) string {
+	yesNo := map[bool]string{true: "YES", false: "NO"}
+	var b strings.Builder
+	fmt.Fprintf(&b, "RMAN restore validation report\n")
+	fmt.Fprintf(&b, "Generated: %s\n", generated.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Restorable: %s\n", yesNo[resp.GetRestorable()])
+	fmt.Fprintf(&b, "Files needing backup: %d\n", len(resp.GetNeedBackupFiles()))
+	for _, f := range resp.GetNeedBackupFiles() {
+		fmt.Fprintf(&b, "  %s\n", f)
+	}
+	fmt.Fprintf(&b, "Unrecoverable files: %d\n", len(resp.GetUnrecoverableFiles()))
+	for _, f := range resp.GetUnrecoverableFiles() {
+		fmt.Fprintf(&b, "  %s\n", f)
+	}
+	fmt.Fprintf(&b, "Failed commands: %d\n", len(resp.GetFailedCommands()))
+	for _, c := range resp.GetFailedCommands() {
+		fmt.Fprintf(&b, "  %s\n", c)
+	}
+	for _, out := range outputs {
+		fmt.Fprintf(&b, "\n==> %s\n", out.command)
+		if out.err != nil {
+			fmt.Fprintf(&b, "%v\n", out.err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n", strings.TrimRight(out.output, "\n"))
+	}
+	return b.String()
+}
+
+// uploadRestoreValidationReport uploads a report to a GCS object.
+func (s *Server) uploadRestoreValidationReport(ctx context.Context, gcsPath, report string) error {
+	f, err := ioutil.TempFile(scratchDir(), "restore-validation")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(report); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.gcsUtil.UploadFile(ctx, gcsPath, f.Name(), contentTypePlainText)
+}
+
+// restoreValidationReport runs the restore validation and the RMAN reports,
+// then uploads the consolidated report. A failed RMAN command is recorded
+// in the report rather than failing the call.
+func (s *Server) restoreValidationReport(ctx context.Context, req *dbdpb.RestoreValidationReportRequest) (*dbdpb.RestoreValidationReportResponse, error) {
+	var outputs []rmanCommandOutput
+	for _, cmd := range restoreValidationCommands(req.GetCheckLogical()) {
+		out := rmanCommandOutput{command: cmd}
+		rmanResp, err := s.runRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{cmd}}, nil)
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon/RestoreValidationReport: RMAN command failed", "command", cmd)
+			out.err = err
+		} else {
+			out.output = strings.Join(rmanResp.GetOutput(), "\n")
+		}
+		outputs = append(outputs, out)
+	}
+
+	resp := summarizeRestoreValidation(outputs)
+	report := formatRestoreValidationReport(time.Now(), resp, outputs)
+	if err := s.uploadRestoreValidationReport(ctx, req.GetGcsPath(), report); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RestoreValidationReport: failed to upload the report to %s: %v", req.GetGcsPath(), err)
+	}
+	resp.GcsPath = req.GetGcsPath()
+	klog.InfoS("dbdaemon/RestoreValidationReport: uploaded the report", "gcsPath", resp.GetGcsPath(), "restorable", resp.GetRestorable(),
+		"needBackup", len(resp.GetNeedBackupFiles()), "unrecoverable", len(resp.GetUnrecoverableFiles()))
+	return resp, nil
+}
+
+// RestoreValidationReportAsync turns restoreValidationReport into an async
+// call, restoring the database validate reads all of its backups.
+func (s *Server) RestoreValidationReportAsync(ctx context.Context, req *dbdpb.RestoreValidationReportAsyncRequest) (*lropb.Operation, error) {
+	klog.InfoS("dbdaemon/RestoreValidationReportAsync", "req", loggableRequest(req))
+	if _, _, err := s.gcsUtil.SplitURI(req.GetSyncRequest().GetGcsPath()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RestoreValidationReportAsync: invalid GCS path: %v", err)
+	}
+	job, err := lro.CreateAndRunLROJobWithID(ctx, req.GetLroInput().GetOperationId(), "RestoreValidationReport", s.lroServer,
+		func(ctx context.Context) (proto.Message, error) {
+			return s.restoreValidationReport(ctx, req.GetSyncRequest())
+		})
+	if err != nil {
+		klog.ErrorS(err, "dbdaemon/RestoreValidationReportAsync failed to create an LRO job", "request", loggableRequest(req))
+		return nil, err
+	}
+	return &lropb.Operation{Name: job.ID(), Done: false}, nil
+}
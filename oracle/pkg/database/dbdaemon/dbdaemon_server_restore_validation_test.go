@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const needBackupOutput = `RMAN retention policy will be applied to the command
+RMAN retention policy is set to redundancy 1
+Report of files with less than 1 redundant backups
+File #bkps Name
+---- ----- -----------------------------------------------------
+4    0     /u02/app/oracle/oradata/GCLOUD/users01.dbf
+12   0     /u02/app/oracle/oradata/GCLOUD/PDB1/app01.dbf
+
+Recovery Manager complete.`
+
+const unrecoverableOutput = `Report of files that need backup due to unrecoverable operations
+File Type of Backup Required Name
+---- ----------------------- -----------------------------------
+
+Recovery Manager complete.`
+
+func TestRestoreValidationCommands(t *testing.T) {
+	want := []string{"restore database validate;", "report need backup;", "report unrecoverable;"}
+	if diff := cmp.Diff(want, restoreValidationCommands(false)); diff != "" {
+		t.Errorf("restoreValidationCommands got unexpected commands (-want +got):\n%v", diff)
+	}
+	want[0] = "restore database validate check logical;"
+	if diff := cmp.Diff(want, restoreValidationCommands(true)); diff != "" {
+		t.Errorf("restoreValidationCommands with check logical got unexpected commands (-want +got):\n%v", diff)
+	}
+}
+
+func TestRMANReportFiles(t *testing.T) {
+	want := []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf", "/u02/app/oracle/oradata/GCLOUD/PDB1/app01.dbf"}
+	if diff := cmp.Diff(want, rmanReportFiles(needBackupOutput)); diff != "" {
+		t.Errorf("rmanReportFiles got unexpected files (-want +got):\n%v", diff)
+	}
+	if got := rmanReportFiles(unrecoverableOutput); len(got) != 0 {
+		t.Errorf("rmanReportFiles got %v, want no files", got)
+	}
+}
+
+func TestFormatRestoreValidationReport(t *testing.T) {
+	outputs := []rmanCommandOutput{
+		{command: "restore database validate;", err: errors.New("RMAN-06026: some targets not found - aborting restore")},
+		{command: needBackupCmd, output: needBackupOutput},
+		{command: unrecoverableCmd, output: unrecoverableOutput},
+	}
+	resp := summarizeRestoreValidation(outputs)
+	wantResp := &dbdpb.RestoreValidationReportResponse{
+		NeedBackupFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf", "/u02/app/oracle/oradata/GCLOUD/PDB1/app01.dbf"},
+		FailedCommands:  []string{"restore database validate;"},
+	}
+	if diff := cmp.Diff(wantResp, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("summarizeRestoreValidation got unexpected response (-want +got):\n%v", diff)
+	}
+
+	report := formatRestoreValidationReport(time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), resp, outputs)
+	wantHeader := `RMAN restore validation report
+Generated: 2026-10-16T02:00:00Z
+Restorable: NO
+Files needing backup: 2
+  /u02/app/oracle/oradata/GCLOUD/users01.dbf
+  /u02/app/oracle/oradata/GCLOUD/PDB1/app01.dbf
+Unrecoverable files: 0
+Failed commands: 1
+  restore database validate;
+
+==> restore database validate;
+RMAN-06026: some targets not found - aborting restore
+
+==> report need backup;
+`
+	if !strings.HasPrefix(report, wantHeader) {
+		t.Errorf("formatRestoreValidationReport got\n%s\nwant it to start with\n%s", report, wantHeader)
+	}
+	if !strings.HasSuffix(report, "==> report unrecoverable;\n"+unrecoverableOutput+"\n") {
+		t.Errorf("formatRestoreValidationReport got\n%s\nwant it to end with the report unrecoverable output", report)
+	}
+}
+
+func TestRestoreValidationReport(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	gcs := &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	s.gcsUtil = gcs
+
+	// The fake rman logs each script and prints the output of the command.
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "scripts")
+	fakeRMAN := filepath.Join(dir, "rman")
+	if err := ioutil.WriteFile(fakeRMAN, []byte(fmt.Sprintf(`#!/bin/sh
+script=$(cat)
+echo "$script" >> %s
+case "$script" in
+  restore*) echo "Finished restore";;
+  "report need backup;") cat <<'OUT'
+%s
+OUT
+  ;;
+  *) cat <<'OUT'
+%s
+OUT
+  ;;
+esac
+`, scripts, needBackupOutput, unrecoverableOutput)), 0755); err != nil {
+		t.Fatalf("failed to create fake rman: %v", err)
+	}
+	origRMAN := rman
+	rman = func(string) string { return fakeRMAN }
+	defer func() { rman = origRMAN }()
+
+	gcsPath := "gs://bucket/reports/restore-validation.txt"
+	resp, err := s.restoreValidationReport(ctx, &dbdpb.RestoreValidationReportRequest{GcsPath: gcsPath, CheckLogical: true})
+	if err != nil {
+		t.Fatalf("restoreValidationReport failed: %v", err)
+	}
+	want := &dbdpb.RestoreValidationReportResponse{
+		Restorable:      true,
+		NeedBackupFiles: []string{"/u02/app/oracle/oradata/GCLOUD/users01.dbf", "/u02/app/oracle/oradata/GCLOUD/PDB1/app01.dbf"},
+		GcsPath:         gcsPath,
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("restoreValidationReport got unexpected response (-want +got):\n%v", diff)
+	}
+	got, err := ioutil.ReadFile(scripts)
+	if err != nil {
+		t.Fatalf("failed to read the scripts: %v", err)
+	}
+	if want := strings.Join(restoreValidationCommands(true), "\n") + "\n"; string(got) != want {
+		t.Errorf("restoreValidationReport ran RMAN scripts %q, want %q", got, want)
+	}
+	report := gcs.uploads[gcsPath]
+	if !strings.Contains(report, "Restorable: YES\n") || !strings.Contains(report, "==> report unrecoverable;\n") {
+		t.Errorf("restoreValidationReport uploaded\n%s\nwant the consolidated report", report)
+	}
+	if got := gcs.contentTypes[gcsPath]; got != contentTypePlainText {
+		t.Errorf("restoreValidationReport uploaded the report as %q, want %q", got, contentTypePlainText)
+	}
+}
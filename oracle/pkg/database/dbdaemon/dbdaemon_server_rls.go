@@ -0,0 +1,343 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	applicationContextSQL = "select namespace from dba_context where namespace='%s'"
+
+	// vpdPolicySQL reports the definition of a VPD policy, sel, ins, upd,
+	// del and idx tell whether it applies to each statement type.
+	vpdPolicySQL = "select pf_owner, package, function, sel, ins, upd, del, idx, chk_option, policy_type " +
+		"from dba_policies where object_owner='%s' and object_name='%s' and policy_name='%s'"
+
+	standaloneFunctionSQL = "select count(*) as count from dba_procedures where owner='%s' and object_name='%s' and object_type='FUNCTION'"
+	packageFunctionSQL    = "select count(*) as count from dba_procedures where owner='%s' and object_name='%s' and procedure_name='%s' and object_type='PACKAGE'"
+
+	addVPDPolicyCmd = "begin dbms_rls.add_policy(object_schema => '%s', object_name => '%s', policy_name => '%s', " +
+		"function_schema => '%s', policy_function => '%s', statement_types => '%s', update_check => %t, policy_type => %s); end;"
+	dropVPDPolicyCmd = "begin dbms_rls.drop_policy(object_schema => '%s', object_name => '%s', policy_name => '%s'); end;"
+)
+
+var (
+	// vpdStatementTypes are the statement types a policy applies to, in
+	// the order of the dba_policies columns reporting them.
+	vpdStatementTypes = []struct {
+		name, column string
+	}{
+		{name: "SELECT", column: "SEL"},
+		{name: "INSERT", column: "INS"},
+		{name: "UPDATE", column: "UPD"},
+		{name: "DELETE", column: "DEL"},
+		{name: "INDEX", column: "IDX"},
+	}
+
+	vpdPolicyTypes = map[dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_PolicyType]string{
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_POLICY_TYPE_UNSPECIFIED:  "DYNAMIC",
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_DYNAMIC:                  "DYNAMIC",
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_STATIC:                   "STATIC",
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_SHARED_STATIC:            "SHARED_STATIC",
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_CONTEXT_SENSITIVE:        "CONTEXT_SENSITIVE",
+		dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_SHARED_CONTEXT_SENSITIVE: "SHARED_CONTEXT_SENSITIVE",
+	}
+)
+
+// splitName splits a name given as n dot separated parts, e.g.
+// SCHEMA.TABLE, into its upper case parts.
+func splitName(name string, n int) ([]string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%q has %d parts, want %d", name, len(parts), n)
+	}
+	for i, p := range parts {
+		if _, err := sqlq.ObjectName(p); err != nil || p == "" {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+		parts[i] = strings.ToUpper(p)
+	}
+	return parts, nil
+}
+
+// applicationContextStatement returns the statement creating or replacing
+// an application context.
+func applicationContextStatement(c *dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext) (string, error) {
+	name, err := sqlq.ObjectName(c.GetName())
+	if err != nil || c.GetName() == "" {
+		return "", fmt.Errorf("invalid application context name %q", c.GetName())
+	}
+	pkg, err := splitName(c.GetPackage(), 2)
+	if err != nil {
+		return "", fmt.Errorf("package of application context %s isn't of the form SCHEMA.PACKAGE: %v", c.GetName(), err)
+	}
+	stmt := fmt.Sprintf("create or replace context %s using %s.%s", name, sqlq.MustBeIdentifier(pkg[0]), sqlq.MustBeIdentifier(pkg[1]))
+	if c.GetAccessedGlobally() {
+		stmt += " accessed globally"
+	}
+	return stmt, nil
+}
+
+// vpdPolicy is a VPD policy of a request with upper case names.
+type vpdPolicy struct {
+	owner, table, name string
+	// functionSchema, functionPackage and function name the policy
+	// function, functionPackage is empty for a standalone function.
+	functionSchema, functionPackage, function string
+	// statementTypes are in the order of vpdStatementTypes.
+	statementTypes []string
+	updateCheck    bool
+	policyType     string
+}
+
+// String returns the policy as OWNER.TABLE.POLICY.
+func (p *vpdPolicy) String() string {
+	return p.owner + "." + p.table + "." + p.name
+}
+
+// parseVPDPolicyName validates the name and the table of a VPD policy.
+func parseVPDPolicyName(req *dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy) (*vpdPolicy, error) {
+	if _, err := sqlq.ObjectName(req.GetName()); err != nil || req.GetName() == "" {
+		return nil, fmt.Errorf("invalid VPD policy name %q", req.GetName())
+	}
+	table, err := splitName(req.GetTable(), 2)
+	if err != nil {
+		return nil, fmt.Errorf("table of VPD policy %s isn't of the form OWNER.TABLE: %v", req.GetName(), err)
+	}
+	return &vpdPolicy{owner: table[0], table: table[1], name: strings.ToUpper(req.GetName())}, nil
+}
+
+// parseVPDPolicy validates a VPD policy of a request.
+func parseVPDPolicy(req *dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy) (*vpdPolicy, error) {
+	p, err := parseVPDPolicyName(req)
+	if err != nil {
+		return nil, err
+	}
+	function, err := splitName(req.GetFunction(), 2)
+	if err == nil {
+		p.functionSchema, p.function = function[0], function[1]
+	} else if function, err = splitName(req.GetFunction(), 3); err == nil {
+		p.functionSchema, p.functionPackage, p.function = function[0], function[1], function[2]
+	} else {
+		return nil, fmt.Errorf("function of VPD policy %s isn't of the form SCHEMA.FUNCTION or SCHEMA.PACKAGE.FUNCTION: %q", req.GetName(), req.GetFunction())
+	}
+
+	types := make(map[string]bool)
+	for _, t := range req.GetStatementTypes() {
+		supported := false
+		for _, st := range vpdStatementTypes {
+			supported = supported || st.name == strings.ToUpper(t)
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported statement type %q of VPD policy %s", t, req.GetName())
+		}
+		types[strings.ToUpper(t)] = true
+	}
+	if len(types) == 0 {
+		types = map[string]bool{"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true}
+	}
+	for _, t := range vpdStatementTypes {
+		if types[t.name] {
+			p.statementTypes = append(p.statementTypes, t.name)
+		}
+	}
+
+	policyType, ok := vpdPolicyTypes[req.GetPolicyType()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported policy type %v of VPD policy %s", req.GetPolicyType(), req.GetName())
+	}
+	p.policyType = policyType
+	p.updateCheck = req.GetUpdateCheck()
+	return p, nil
+}
+
+// addStatement returns the DBMS_RLS.ADD_POLICY call adding the policy.
+func (p *vpdPolicy) addStatement() string {
+	function := p.function
+	if p.functionPackage != "" {
+		function = p.functionPackage + "." + p.function
+	}
+	return fmt.Sprintf(addVPDPolicyCmd,
+		sqlq.StringParam(p.owner), sqlq.StringParam(p.table), sqlq.StringParam(p.name),
+		sqlq.StringParam(p.functionSchema), sqlq.StringParam(function),
+		strings.Join(p.statementTypes, ","), p.updateCheck, "dbms_rls."+strings.ToLower(p.policyType))
+}
+
+// dropStatement returns the DBMS_RLS.DROP_POLICY call dropping the policy.
+func (p *vpdPolicy) dropStatement() string {
+	return fmt.Sprintf(dropVPDPolicyCmd, sqlq.StringParam(p.owner), sqlq.StringParam(p.table), sqlq.StringParam(p.name))
+}
+
+// functionSQL returns the query counting the policy functions named like
+// that of the policy.
+func (p *vpdPolicy) functionSQL() string {
+	if p.functionPackage == "" {
+		return fmt.Sprintf(standaloneFunctionSQL, sqlq.StringParam(p.functionSchema), sqlq.StringParam(p.function))
+	}
+	return fmt.Sprintf(packageFunctionSQL, sqlq.StringParam(p.functionSchema), sqlq.StringParam(p.functionPackage), sqlq.StringParam(p.function))
+}
+
+// matches tells whether a vpdPolicySQL row defines the policy.
+func (p *vpdPolicy) matches(row map[string]string) bool {
+	if row["PF_OWNER"] != p.functionSchema || row["PACKAGE"] != p.functionPackage || row["FUNCTION"] != p.function ||
+		row["POLICY_TYPE"] != p.policyType || (row["CHK_OPTION"] == "YES") != p.updateCheck {
+		return false
+	}
+	applies := make(map[string]bool)
+	for _, t := range p.statementTypes {
+		applies[t] = true
+	}
+	for _, t := range vpdStatementTypes {
+		if (row[t.column] == "YES") != applies[t.name] {
+			return false
+		}
+	}
+	return true
+}
+
+// rlsQuery runs a query in a PDB and returns its rows.
+func (s *Server) rlsQuery(ctx context.Context, pdbName, query string) ([]map[string]string, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(pdbName), query},
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]string
+	for _, msg := range resp.GetMsg() {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse row %q: %v", msg, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// existingVPDPolicy returns the vpdPolicySQL row of a policy, nil if the
+// policy doesn't exist.
+func (s *Server) existingVPDPolicy(ctx context.Context, pdbName string, p *vpdPolicy) (map[string]string, error) {
+	rows, err := s.rlsQuery(ctx, pdbName, fmt.Sprintf(vpdPolicySQL, sqlq.StringParam(p.owner), sqlq.StringParam(p.table), sqlq.StringParam(p.name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query VPD policy %s: %v", p, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// ConfigureRowLevelSecurity creates and drops the application contexts of
+// a PDB, then drops and adds its VPD policies. The policy functions have to
+// exist, the policies which exist with another definition are replaced.
+func (s *Server) ConfigureRowLevelSecurity(ctx context.Context, req *dbdpb.ConfigureRowLevelSecurityRequest) (*dbdpb.ConfigureRowLevelSecurityResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureRowLevelSecurity", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: invalid PDB name %q", req.GetPdbName())
+	}
+	var contexts []string
+	for _, c := range req.GetApplicationContexts() {
+		stmt, err := applicationContextStatement(c)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: %v", err)
+		}
+		contexts = append(contexts, stmt)
+	}
+	for _, c := range req.GetDropApplicationContexts() {
+		if _, err := sqlq.ObjectName(c); err != nil || c == "" {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: invalid application context name %q", c)
+		}
+	}
+	var policies, drops []*vpdPolicy
+	for _, p := range req.GetVpdPolicies() {
+		policy, err := parseVPDPolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: %v", err)
+		}
+		policies = append(policies, policy)
+	}
+	for _, p := range req.GetDropVpdPolicies() {
+		policy, err := parseVPDPolicyName(p)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: %v", err)
+		}
+		drops = append(drops, policy)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp := &dbdpb.ConfigureRowLevelSecurityResponse{}
+	statements := contexts
+	for _, p := range drops {
+		row, err := s.existingVPDPolicy(ctx, req.GetPdbName(), p)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: %v", err)
+		}
+		if row != nil {
+			statements = append(statements, p.dropStatement())
+			resp.ChangedVpdPolicies = append(resp.ChangedVpdPolicies, p.String())
+		}
+	}
+	for _, p := range policies {
+		rows, err := s.rlsQuery(ctx, req.GetPdbName(), p.functionSQL())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: failed to query the function of VPD policy %s: %v", p, err)
+		}
+		if len(rows) != 1 || rows[0]["COUNT"] == "0" {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: function of VPD policy %s doesn't exist", p)
+		}
+		row, err := s.existingVPDPolicy(ctx, req.GetPdbName(), p)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: %v", err)
+		}
+		if row != nil && p.matches(row) {
+			continue
+		}
+		if row != nil {
+			statements = append(statements, p.dropStatement())
+		}
+		statements = append(statements, p.addStatement())
+		resp.ChangedVpdPolicies = append(resp.ChangedVpdPolicies, p.String())
+	}
+	for _, c := range req.GetDropApplicationContexts() {
+		rows, err := s.rlsQuery(ctx, req.GetPdbName(), fmt.Sprintf(applicationContextSQL, sqlq.StringParam(strings.ToUpper(c))))
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: failed to query application context %s: %v", c, err)
+		}
+		if len(rows) > 0 {
+			statements = append(statements, "drop context "+sqlq.MustBeObjectName(c))
+		}
+	}
+
+	if len(statements) > 0 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...),
+		}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureRowLevelSecurity: failed to configure row level security: %v", err)
+		}
+	}
+	return resp, nil
+}
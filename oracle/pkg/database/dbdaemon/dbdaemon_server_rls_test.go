@@ -0,0 +1,238 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestApplicationContextStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		context *dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "context",
+			context: &dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{Name: "tenant_ctx", Package: "app.tenant_pkg"},
+			want:    `create or replace context "TENANT_CTX" using "APP"."TENANT_PKG"`,
+		},
+		{
+			name:    "global context",
+			context: &dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{Name: "tenant_ctx", Package: "app.tenant_pkg", AccessedGlobally: true},
+			want:    `create or replace context "TENANT_CTX" using "APP"."TENANT_PKG" accessed globally`,
+		},
+		{
+			name:    "package without schema",
+			context: &dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{Name: "tenant_ctx", Package: "tenant_pkg"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			context: &dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{Name: `tenant"ctx`, Package: "app.tenant_pkg"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := applicationContextStatement(tc.context)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("applicationContextStatement got error %v, want error: %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("applicationContextStatement got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVPDPolicyAddStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			policy: &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: "tenant_policy", Table: "app.orders", Function: "app.tenant_predicate"},
+			want: "begin dbms_rls.add_policy(object_schema => 'APP', object_name => 'ORDERS', policy_name => 'TENANT_POLICY', " +
+				"function_schema => 'APP', policy_function => 'TENANT_PREDICATE', statement_types => 'SELECT,INSERT,UPDATE,DELETE', " +
+				"update_check => false, policy_type => dbms_rls.dynamic); end;",
+		},
+		{
+			name: "packaged function",
+			policy: &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{
+				Name:           "tenant_policy",
+				Table:          "app.orders",
+				Function:       "sec.tenant_pkg.predicate",
+				StatementTypes: []string{"update", "SELECT", "index", "select"},
+				UpdateCheck:    true,
+				PolicyType:     dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy_SHARED_CONTEXT_SENSITIVE,
+			},
+			want: "begin dbms_rls.add_policy(object_schema => 'APP', object_name => 'ORDERS', policy_name => 'TENANT_POLICY', " +
+				"function_schema => 'SEC', policy_function => 'TENANT_PKG.PREDICATE', statement_types => 'SELECT,UPDATE,INDEX', " +
+				"update_check => true, policy_type => dbms_rls.shared_context_sensitive); end;",
+		},
+		{
+			name:    "table without owner",
+			policy:  &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: "tenant_policy", Table: "orders", Function: "app.tenant_predicate"},
+			wantErr: true,
+		},
+		{
+			name:    "function without schema",
+			policy:  &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: "tenant_policy", Table: "app.orders", Function: "tenant_predicate"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported statement type",
+			policy:  &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: "tenant_policy", Table: "app.orders", Function: "app.tenant_predicate", StatementTypes: []string{"MERGE"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			policy:  &dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: `tenant"policy`, Table: "app.orders", Function: "app.tenant_predicate"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := parseVPDPolicy(tc.policy)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("parseVPDPolicy got error %v, want error: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := p.addStatement(); got != tc.want {
+				t.Errorf("addStatement got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVPDPolicyMatches(t *testing.T) {
+	p, err := parseVPDPolicy(&dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{Name: "tenant_policy", Table: "app.orders", Function: "sec.tenant_pkg.predicate", StatementTypes: []string{"SELECT"}})
+	if err != nil {
+		t.Fatalf("parseVPDPolicy failed: %v", err)
+	}
+	row := map[string]string{
+		"PF_OWNER": "SEC", "PACKAGE": "TENANT_PKG", "FUNCTION": "PREDICATE",
+		"SEL": "YES", "INS": "NO", "UPD": "NO", "DEL": "NO", "IDX": "NO",
+		"CHK_OPTION": "NO", "POLICY_TYPE": "DYNAMIC",
+	}
+	if !p.matches(row) {
+		t.Errorf("matches(%v) got false, want true", row)
+	}
+	for column, value := range map[string]string{"FUNCTION": "OTHER", "INS": "YES", "CHK_OPTION": "YES", "POLICY_TYPE": "STATIC"} {
+		changed := make(map[string]string)
+		for k, v := range row {
+			changed[k] = v
+		}
+		changed[column] = value
+		if p.matches(changed) {
+			t.Errorf("matches with %s %s got true, want false", column, value)
+		}
+	}
+}
+
+func TestConfigureRowLevelSecurity(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	functionExists := true
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 2 || sqls[0] != `alter session set container="PDB1"` {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		switch {
+		case strings.Contains(sqls[1], "from dba_procedures") && functionExists:
+			return []string{`{"COUNT":"1"}`}, nil
+		case strings.Contains(sqls[1], "from dba_procedures"):
+			return []string{`{"COUNT":"0"}`}, nil
+		case strings.Contains(sqls[1], "policy_name='TENANT_POLICY'"):
+			return []string{`{"PF_OWNER":"APP","FUNCTION":"OLD_PREDICATE","SEL":"YES","INS":"YES","UPD":"YES","DEL":"YES","IDX":"NO","CHK_OPTION":"NO","POLICY_TYPE":"DYNAMIC"}`}, nil
+		case strings.Contains(sqls[1], "policy_name='OLD_POLICY'"):
+			return []string{`{"PF_OWNER":"APP","FUNCTION":"OLD_PREDICATE"}`}, nil
+		case strings.Contains(sqls[1], "from dba_context"):
+			return []string{`{"NAMESPACE":"OLD_CTX"}`}, nil
+		}
+		return nil, nil
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = sqls
+		return nil, nil
+	}
+	req := &dbdpb.ConfigureRowLevelSecurityRequest{
+		PdbName:                 "pdb1",
+		ApplicationContexts:     []*dbdpb.ConfigureRowLevelSecurityRequest_ApplicationContext{{Name: "tenant_ctx", Package: "app.tenant_pkg"}},
+		DropApplicationContexts: []string{"old_ctx"},
+		VpdPolicies: []*dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{
+			{Name: "tenant_policy", Table: "app.orders", Function: "app.tenant_predicate"},
+			{Name: "new_policy", Table: "app.items", Function: "app.tenant_predicate"},
+		},
+		DropVpdPolicies: []*dbdpb.ConfigureRowLevelSecurityRequest_VPDPolicy{
+			{Name: "old_policy", Table: "app.orders"},
+			{Name: "gone_policy", Table: "app.orders"},
+		},
+	}
+
+	resp, err := s.ConfigureRowLevelSecurity(ctx, req)
+	if err != nil {
+		t.Fatalf("ConfigureRowLevelSecurity failed: %v", err)
+	}
+	want := []string{
+		`alter session set container="PDB1"`,
+		`create or replace context "TENANT_CTX" using "APP"."TENANT_PKG"`,
+		"begin dbms_rls.drop_policy(object_schema => 'APP', object_name => 'ORDERS', policy_name => 'OLD_POLICY'); end;",
+		"begin dbms_rls.drop_policy(object_schema => 'APP', object_name => 'ORDERS', policy_name => 'TENANT_POLICY'); end;",
+		"begin dbms_rls.add_policy(object_schema => 'APP', object_name => 'ORDERS', policy_name => 'TENANT_POLICY', " +
+			"function_schema => 'APP', policy_function => 'TENANT_PREDICATE', statement_types => 'SELECT,INSERT,UPDATE,DELETE', " +
+			"update_check => false, policy_type => dbms_rls.dynamic); end;",
+		"begin dbms_rls.add_policy(object_schema => 'APP', object_name => 'ITEMS', policy_name => 'NEW_POLICY', " +
+			"function_schema => 'APP', policy_function => 'TENANT_PREDICATE', statement_types => 'SELECT,INSERT,UPDATE,DELETE', " +
+			"update_check => false, policy_type => dbms_rls.dynamic); end;",
+		`drop context "OLD_CTX"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ConfigureRowLevelSecurity got unexpected statements (-want +got):\n%v", diff)
+	}
+	wantChanged := []string{"APP.ORDERS.OLD_POLICY", "APP.ORDERS.TENANT_POLICY", "APP.ITEMS.NEW_POLICY"}
+	if diff := cmp.Diff(wantChanged, resp.GetChangedVpdPolicies()); diff != "" {
+		t.Errorf("ConfigureRowLevelSecurity got unexpected changed policies (-want +got):\n%v", diff)
+	}
+
+	functionExists = false
+	got = nil
+	if _, err := s.ConfigureRowLevelSecurity(ctx, req); err == nil {
+		t.Errorf("ConfigureRowLevelSecurity with a missing policy function succeeded, want error")
+	}
+	if got != nil {
+		t.Errorf("ConfigureRowLevelSecurity with a missing policy function ran %q, want no statements", got)
+	}
+}
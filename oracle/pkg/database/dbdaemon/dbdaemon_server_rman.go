@@ -0,0 +1,161 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	rmanShowAllScript = "show all;"
+
+	// rmanShowAllHeader precedes the settings in the SHOW ALL output.
+	rmanShowAllHeader = "RMAN configuration parameters for database"
+
+	// rmanDefaultComment marks settings which were never configured.
+	rmanDefaultComment = "# default"
+)
+
+// rmanConfigureStatements returns the CONFIGURE commands of the requested
+// configuration, in the form SHOW ALL reports them.
+func rmanConfigureStatements(req *dbdpb.ConfigureRMANRequest) ([]string, error) {
+	if req.GetRecoveryWindowDays() < 0 || req.GetRedundancy() < 0 || req.GetParallelism() < 0 {
+		return nil, fmt.Errorf("recovery window (%d days), redundancy (%d) and parallelism (%d) must not be negative", req.GetRecoveryWindowDays(), req.GetRedundancy(), req.GetParallelism())
+	}
+	if req.GetRecoveryWindowDays() > 0 && req.GetRedundancy() > 0 {
+		return nil, fmt.Errorf("recovery window and redundancy retention policies are mutually exclusive")
+	}
+
+	var retention string
+	if req.GetRecoveryWindowDays() > 0 {
+		retention = fmt.Sprintf("CONFIGURE RETENTION POLICY TO RECOVERY WINDOW OF %d DAYS;", req.GetRecoveryWindowDays())
+	} else {
+		redundancy := int32(1)
+		if req.GetRedundancy() > 0 {
+			redundancy = req.GetRedundancy()
+		}
+		retention = fmt.Sprintf("CONFIGURE RETENTION POLICY TO REDUNDANCY %d;", redundancy)
+	}
+
+	autobackup := "OFF"
+	if req.GetControlfileAutobackup() {
+		autobackup = "ON"
+	}
+
+	parallelism := int32(1)
+	if req.GetParallelism() > 0 {
+		parallelism = req.GetParallelism()
+	}
+
+	return []string{
+		retention,
+		fmt.Sprintf("CONFIGURE CONTROLFILE AUTOBACKUP %s;", autobackup),
+		"CONFIGURE DEFAULT DEVICE TYPE TO DISK;",
+		fmt.Sprintf("CONFIGURE DEVICE TYPE DISK PARALLELISM %d BACKUP TYPE TO BACKUPSET;", parallelism),
+	}, nil
+}
+
+// parseRMANShowAll returns the settings reported by the last SHOW ALL in the
+// RMAN output.
+func parseRMANShowAll(output string) ([]*dbdpb.ConfigureRMANResponse_Setting, error) {
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), rmanShowAllHeader) {
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("failed to find the RMAN configuration in the output %q", output)
+	}
+
+	var settings []*dbdpb.ConfigureRMANResponse_Setting
+	for _, line := range lines[start+1:] {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "CONFIGURE ") {
+			break
+		}
+		setting := &dbdpb.ConfigureRMANResponse_Setting{Statement: line}
+		if strings.HasSuffix(line, rmanDefaultComment) {
+			setting.Statement = strings.TrimSpace(strings.TrimSuffix(line, rmanDefaultComment))
+			setting.IsDefault = true
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// pendingRMANStatements returns the statements which are not in effect yet.
+func pendingRMANStatements(statements []string, settings []*dbdpb.ConfigureRMANResponse_Setting) []string {
+	var pending []string
+	for _, stmt := range statements {
+		found := false
+		for _, setting := range settings {
+			if strings.EqualFold(stmt, setting.GetStatement()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			pending = append(pending, stmt)
+		}
+	}
+	return pending
+}
+
+// ConfigureRMAN applies the RMAN persistent configuration. Only settings
+// which differ from SHOW ALL are configured, so this is cheap to repeat.
+func (s *Server) ConfigureRMAN(ctx context.Context, req *dbdpb.ConfigureRMANRequest) (*dbdpb.ConfigureRMANResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureRMAN", "req", loggableRequest(req))
+	statements, err := rmanConfigureStatements(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: %v", err)
+	}
+
+	showResp, err := s.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{rmanShowAllScript}})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: failed to show the RMAN configuration: %v", err)
+	}
+	settings, err := parseRMANShowAll(strings.Join(showResp.GetOutput(), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: %v", err)
+	}
+	pending := pendingRMANStatements(statements, settings)
+	if len(pending) == 0 {
+		return &dbdpb.ConfigureRMANResponse{Settings: settings}, nil
+	}
+
+	configureResp, err := s.RunRMAN(ctx, &dbdpb.RunRMANRequest{
+		Scripts:       append(append([]string{}, pending...), rmanShowAllScript),
+		SingleSession: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: failed to configure RMAN: %v", err)
+	}
+	if settings, err = parseRMANShowAll(strings.Join(configureResp.GetOutput(), "\n")); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: %v", err)
+	}
+	if notApplied := pendingRMANStatements(pending, settings); len(notApplied) > 0 {
+		return nil, fmt.Errorf("dbdaemon/ConfigureRMAN: RMAN did not apply %q", notApplied)
+	}
+	klog.InfoS("dbdaemon/ConfigureRMAN: configured RMAN", "statements", pending)
+	return &dbdpb.ConfigureRMANResponse{Settings: settings, AppliedStatements: pending}, nil
+}
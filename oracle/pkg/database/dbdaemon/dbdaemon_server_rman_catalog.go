@@ -0,0 +1,108 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	rmanCatalogControlfile = "controlfile.ctl"
+	rmanCatalogFile        = "rman-catalog.txt"
+)
+
+// rmanCatalogCommands are the RMAN commands exported in the catalog.
+var rmanCatalogCommands = []string{"show all;", "list backup;"}
+
+// controlfileCopyCommand returns the RMAN command copying the current
+// control file to a file.
+func controlfileCopyCommand(file string) string {
+	return fmt.Sprintf("backup as copy current controlfile format '%s' reuse;", file)
+}
+
+// formatRMANCatalog assembles the text export of the RMAN catalog from the
+// outputs of the rmanCatalogCommands.
+func formatRMANCatalog(generated time.Time, dbid int64, name string, outputs []rmanCommandOutput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "RMAN catalog export\n")
+	fmt.Fprintf(&b, "Generated: %s\n", generated.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Database: %s\n", name)
+	fmt.Fprintf(&b, "DBID: %d\n", dbid)
+	fmt.Fprintf(&b, "Control file copy: %s\n", rmanCatalogControlfile)
+	fmt.Fprintf(&b, "Restore the control file with: set dbid %d; restore controlfile from '<path of %s>';\n", dbid, rmanCatalogControlfile)
+	for _, out := range outputs {
+		fmt.Fprintf(&b, "\n==> %s\n", out.command)
+		fmt.Fprintf(&b, "%s\n", strings.TrimRight(out.output, "\n"))
+	}
+	return b.String()
+}
+
+// ExportRMANCatalog copies the control file and exports the RMAN
+// configuration and backup records, then uploads them to GCS.
+func (s *Server) ExportRMANCatalog(ctx context.Context, req *dbdpb.ExportRMANCatalogRequest) (*dbdpb.ExportRMANCatalogResponse, error) {
+	klog.InfoS("dbdaemon/ExportRMANCatalog", "req", loggableRequest(req))
+	if _, _, err := s.gcsUtil.SplitURI(req.GetGcsPath()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: invalid GCS path: %v", err)
+	}
+	dbid, err := s.GetDBID(ctx, &dbdpb.GetDBIDRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: %v", err)
+	}
+
+	dir, err := ioutil.TempDir(scratchDir(), "rman-catalog")
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: failed to create a scratch directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	controlfile := filepath.Join(dir, rmanCatalogControlfile)
+	rmanResp, err := s.runRMAN(ctx, &dbdpb.RunRMANRequest{
+		Scripts: append([]string{controlfileCopyCommand(controlfile)}, rmanCatalogCommands...),
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: %v", err)
+	}
+	var outputs []rmanCommandOutput
+	for i, cmd := range rmanCatalogCommands {
+		outputs = append(outputs, rmanCommandOutput{command: cmd, output: rmanResp.GetOutput()[i+1]})
+	}
+	catalog := filepath.Join(dir, rmanCatalogFile)
+	if err := ioutil.WriteFile(catalog, []byte(formatRMANCatalog(time.Now(), dbid.GetDbid(), dbid.GetName(), outputs)), 0640); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: failed to write the catalog: %v", err)
+	}
+
+	gcsDir := strings.TrimSuffix(req.GetGcsPath(), "/")
+	resp := &dbdpb.ExportRMANCatalogResponse{
+		ControlfileGcsPath: gcsDir + "/" + rmanCatalogControlfile,
+		CatalogGcsPath:     gcsDir + "/" + rmanCatalogFile,
+		Dbid:               dbid.GetDbid(),
+	}
+	for _, upload := range [][2]string{{controlfile, resp.GetControlfileGcsPath()}, {catalog, resp.GetCatalogGcsPath()}} {
+		if err := s.gcsUtil.UploadFile(ctx, upload[1], upload[0], contentTypePlainText); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ExportRMANCatalog: failed to upload %s: %v", upload[1], err)
+		}
+	}
+	klog.InfoS("dbdaemon/ExportRMANCatalog: done", "controlfile", resp.GetControlfileGcsPath(), "catalog", resp.GetCatalogGcsPath())
+	return resp, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const listBackupOutput = `List of Backup Sets
+===================
+
+BS Key  Type LV Size       Device Type Elapsed Time Completion Time
+------- ---- -- ---------- ----------- ------------ ---------------
+1       Full    1.05G      DISK        00:00:32     16-OCT-26
+        BP Key: 1   Status: AVAILABLE  Compressed: NO  Tag: TAG20261016T020000
+        Piece Name: /u03/app/oracle/fast_recovery_area/GCLOUD/backupset/o1_mf_nnndf_TAG20261016T020000.bkp`
+
+func TestFormatRMANCatalog(t *testing.T) {
+	outputs := []rmanCommandOutput{
+		{command: "show all;", output: "RMAN configuration parameters for database with db_unique_name GCLOUD are:\nCONFIGURE CONTROLFILE AUTOBACKUP ON;\n"},
+		{command: "list backup;", output: listBackupOutput},
+	}
+	got := formatRMANCatalog(time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), 1234567890, "GCLOUD", outputs)
+	want := `RMAN catalog export
+Generated: 2026-10-16T02:00:00Z
+Database: GCLOUD
+DBID: 1234567890
+Control file copy: controlfile.ctl
+Restore the control file with: set dbid 1234567890; restore controlfile from '<path of controlfile.ctl>';
+
+==> show all;
+RMAN configuration parameters for database with db_unique_name GCLOUD are:
+CONFIGURE CONTROLFILE AUTOBACKUP ON;
+
+==> list backup;
+` + listBackupOutput + "\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("formatRMANCatalog got unexpected catalog (-want +got):\n%v", diff)
+	}
+}
+
+func TestExportRMANCatalog(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		if sqls[0] != dbidSQL {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return []string{`{"DBID":"1234567890","NAME":"GCLOUD"}`}, nil
+	}
+	gcs := &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	s.gcsUtil = gcs
+
+	// The fake rman logs each script, writes the control file copy and
+	// prints the output of the other commands.
+	dir := t.TempDir()
+	scripts := filepath.Join(dir, "scripts")
+	fakeRMAN := filepath.Join(dir, "rman")
+	if err := ioutil.WriteFile(fakeRMAN, []byte(fmt.Sprintf(`#!/bin/sh
+script=$(cat)
+echo "$script" >> %s
+case "$script" in
+  backup*) echo "CONTROLFILE" > "$(echo "$script" | sed "s/.*format '\([^']*\)'.*/\1/")";;
+  "show all;") echo "CONFIGURE CONTROLFILE AUTOBACKUP ON;";;
+  *) cat <<'OUT'
+%s
+OUT
+  ;;
+esac
+`, scripts, listBackupOutput)), 0755); err != nil {
+		t.Fatalf("failed to create fake rman: %v", err)
+	}
+	origRMAN := rman
+	rman = func(string) string { return fakeRMAN }
+	defer func() { rman = origRMAN }()
+
+	resp, err := s.ExportRMANCatalog(ctx, &dbdpb.ExportRMANCatalogRequest{GcsPath: "gs://bucket/rman-catalog/20261016T020000Z/"})
+	if err != nil {
+		t.Fatalf("ExportRMANCatalog failed: %v", err)
+	}
+	want := &dbdpb.ExportRMANCatalogResponse{
+		ControlfileGcsPath: "gs://bucket/rman-catalog/20261016T020000Z/controlfile.ctl",
+		CatalogGcsPath:     "gs://bucket/rman-catalog/20261016T020000Z/rman-catalog.txt",
+		Dbid:               1234567890,
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("ExportRMANCatalog got unexpected response (-want +got):\n%v", diff)
+	}
+	if got := gcs.uploads[want.GetControlfileGcsPath()]; got != "CONTROLFILE\n" {
+		t.Errorf("ExportRMANCatalog uploaded control file %q, want the copy", got)
+	}
+	catalog := gcs.uploads[want.GetCatalogGcsPath()]
+	for _, part := range []string{"DBID: 1234567890\n", "==> show all;\nCONFIGURE CONTROLFILE AUTOBACKUP ON;\n", "==> list backup;\n" + listBackupOutput} {
+		if !strings.Contains(catalog, part) {
+			t.Errorf("ExportRMANCatalog uploaded catalog\n%s\nwant it to contain\n%s", catalog, part)
+		}
+	}
+	got, err := ioutil.ReadFile(scripts)
+	if err != nil {
+		t.Fatalf("failed to read the scripts: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(got)), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "backup as copy current controlfile format '") {
+		t.Errorf("ExportRMANCatalog ran RMAN scripts %q, want the control file copy, show all and list backup", got)
+	}
+}
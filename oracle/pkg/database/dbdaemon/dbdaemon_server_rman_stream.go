@@ -0,0 +1,400 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"cloud.google.com/go/storage"
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+)
+
+const (
+	// pipeUnblockInterval is the interval of the attempts to release the
+	// streams still waiting for RMAN to open their named pipes after it
+	// exited.
+	pipeUnblockInterval = 100 * time.Millisecond
+
+	// maxStreamChannels bounds the channels of a streamed backup looked
+	// up for a restore.
+	maxStreamChannels = 100
+)
+
+// rmanPipeDir is the directory of the named pipes of streamed backups, tests
+// replace it.
+var rmanPipeDir = consts.RMANPipeDir
+
+// streamPipe returns the named pipe of a backup channel.
+func streamPipe(channel int) string {
+	return filepath.Join(rmanPipeDir, fmt.Sprintf(consts.RMANStreamPipe, channel))
+}
+
+// streamsGCSDir returns the GCS directory the backup channels of a backup in
+// gcsPath stream their backup pieces to.
+func streamsGCSDir(gcsPath string) string {
+	return strings.TrimSuffix(gcsPath, "/") + "/" + consts.RMANStreamsDir
+}
+
+// streamObject returns the GCS object of the backup piece of a channel in
+// the GCS directory of the streams.
+func streamObject(gcsDir string, channel int) string {
+	return strings.TrimSuffix(gcsDir, "/") + "/" + fmt.Sprintf(consts.RMANStreamPipe, channel)
+}
+
+// createStreamPipes creates the named pipes of the channels, replacing the
+// ones left over by an earlier run.
+func createStreamPipes(channels int) ([]string, error) {
+	if err := os.MkdirAll(rmanPipeDir, dirPerm(0750)); err != nil {
+		return nil, fmt.Errorf("failed to create the pipe dir %s: %v", rmanPipeDir, err)
+	}
+	var pipes []string
+	for i := 1; i <= channels; i++ {
+		pipe := streamPipe(i)
+		if err := os.Remove(pipe); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove the old pipe %s: %v", pipe, err)
+		}
+		if err := syscall.Mkfifo(pipe, uint32(filePerm(0660))); err != nil {
+			return nil, fmt.Errorf("failed to create the pipe %s: %v", pipe, err)
+		}
+		pipes = append(pipes, pipe)
+	}
+	return pipes, nil
+}
+
+// pipeWatch reads the closes of a named pipe with inotify. Each open of a
+// pipe by RMAN ends with a close, whatever RMAN read or wrote.
+type pipeWatch struct {
+	fd       int
+	mask     uint32
+	nonblock bool
+}
+
+// watchPipe watches the closes of pipe in mask, nonblock reads them without
+// waiting.
+func watchPipe(pipe string, mask uint32, nonblock bool) (*pipeWatch, error) {
+	flags := syscall.IN_CLOEXEC
+	if nonblock {
+		flags |= syscall.IN_NONBLOCK
+	}
+	fd, err := syscall.InotifyInit1(flags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %v", pipe, err)
+	}
+	// Identical events in a row are merged, the opens between the closes
+	// keep them apart.
+	if _, err := syscall.InotifyAddWatch(fd, pipe, mask|syscall.IN_OPEN); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("failed to watch %s: %v", pipe, err)
+	}
+	return &pipeWatch{fd: fd, mask: mask, nonblock: nonblock}, nil
+}
+
+// closes returns the number of closes since the last call. It waits for one
+// unless the watch doesn't block.
+func (w *pipeWatch) closes() (int, error) {
+	buf := make([]byte, 64*syscall.SizeofInotifyEvent)
+	var closes int
+	for {
+		n, err := syscall.Read(w.fd, buf)
+		switch {
+		case err == syscall.EINTR:
+			continue
+		case err == syscall.EAGAIN:
+			return closes, nil
+		case err != nil:
+			return closes, err
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			if event.Mask&w.mask != 0 {
+				closes++
+			}
+			off += syscall.SizeofInotifyEvent + int(event.Len)
+		}
+		if closes > 0 && !w.nonblock {
+			return closes, nil
+		}
+	}
+}
+
+func (w *pipeWatch) close() {
+	syscall.Close(w.fd)
+}
+
+// pipeStreams moves the backup pieces of the channels of a streamed backup
+// between their named pipes and GCS while RMAN runs. Opening a named pipe
+// blocks until RMAN opens the other end, the streams of the channels RMAN
+// didn't use are released by finish.
+type pipeStreams struct {
+	pipes []string
+	// unblockFlag is the flag the other end of the pipes is opened with to
+	// release the streams.
+	unblockFlag int
+	// writes counts the backup pieces RMAN wrote to each pipe of a backup.
+	writes []*pipeWatch
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+
+	mu   sync.Mutex
+	errs []string
+	err  error
+}
+
+func newPipeStreams(pipes []string, unblockFlag int) *pipeStreams {
+	return &pipeStreams{pipes: pipes, unblockFlag: unblockFlag, stop: make(chan struct{})}
+}
+
+// run runs the stream of each pipe in the background.
+func (ps *pipeStreams) run(stream func(channel int, pipe string) error) {
+	for i, pipe := range ps.pipes {
+		ps.wg.Add(1)
+		go func(channel int, pipe string) {
+			defer ps.wg.Done()
+			if err := stream(channel, pipe); err != nil {
+				ps.fail(err)
+			}
+		}(i+1, pipe)
+	}
+}
+
+func (ps *pipeStreams) fail(err error) {
+	klog.ErrorS(err, "dbdaemon/pipeStreams: stream failed")
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.errs = append(ps.errs, err.Error())
+}
+
+// stopped returns whether RMAN exited, the pipes are opened by finish only
+// from then on.
+func (ps *pipeStreams) stopped() bool {
+	select {
+	case <-ps.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkWrites checks that RMAN wrote a single backup piece to each pipe of a
+// backup. RMAN reuses the name of the pipe for the next pieces of a channel,
+// which replace the first one in the control file. A streamed backup gives
+// each channel a single set, only a configured MAXSETSIZE or MAXPIECESIZE
+// splits it.
+func (ps *pipeStreams) checkWrites() {
+	for i, w := range ps.writes {
+		pieces, err := w.closes()
+		w.close()
+		if err != nil {
+			ps.fail(fmt.Errorf("failed to count the backup pieces written to %s: %v", ps.pipes[i], err))
+		} else if pieces > 1 {
+			ps.fail(fmt.Errorf("the channel of %s wrote %d backup pieces, a streamed backup doesn't support a MAXSETSIZE or MAXPIECESIZE", ps.pipes[i], pieces))
+		}
+	}
+}
+
+// finish waits for the streams after RMAN exited, removes the pipes and
+// returns the errors of the streams. It releases the streams waiting for
+// RMAN by opening the other end of their pipes, RMAN won't open them
+// anymore. Only the first call waits.
+func (ps *pipeStreams) finish() error {
+	ps.once.Do(func() {
+		close(ps.stop)
+		ps.checkWrites()
+		done := make(chan struct{})
+		go func() {
+			ps.wg.Wait()
+			close(done)
+		}()
+		for waiting := true; waiting; {
+			for _, pipe := range ps.pipes {
+				// A non-blocking open for writing fails unless a stream
+				// waits to read, one for reading doesn't wait for a
+				// stream to write.
+				if f, err := os.OpenFile(pipe, ps.unblockFlag|syscall.O_NONBLOCK, 0); err == nil {
+					f.Close()
+				}
+			}
+			select {
+			case <-done:
+				waiting = false
+			case <-time.After(pipeUnblockInterval):
+			}
+		}
+		for _, pipe := range ps.pipes {
+			if err := os.Remove(pipe); err != nil {
+				klog.Warningf("dbdaemon/pipeStreams: failed to remove the pipe %s: %v", pipe, err)
+			}
+		}
+		if len(ps.errs) > 0 {
+			ps.err = errors.New(strings.Join(ps.errs, "; "))
+		}
+	})
+	return ps.err
+}
+
+// streamPipesToGCS creates the named pipes of the channels of a streamed
+// backup and uploads the backup piece of each channel to its GCS object
+// under the streams directory of gcsPath.
+func (s *Server) streamPipesToGCS(ctx context.Context, channels int, gcsPath string) (*pipeStreams, error) {
+	if gcsPath == "" {
+		return nil, fmt.Errorf("a streamed backup needs a GCS path")
+	}
+	pipes, err := createStreamPipes(channels)
+	if err != nil {
+		return nil, err
+	}
+	ps := newPipeStreams(pipes, os.O_WRONLY)
+	for _, pipe := range pipes {
+		w, err := watchPipe(pipe, syscall.IN_CLOSE_WRITE, true)
+		if err != nil {
+			for _, w := range ps.writes {
+				w.close()
+			}
+			return nil, err
+		}
+		ps.writes = append(ps.writes, w)
+	}
+	gcsDir := streamsGCSDir(gcsPath)
+	klog.InfoS("dbdaemon/streamPipesToGCS", "channels", channels, "gcsDir", gcsDir)
+	ps.run(func(channel int, pipe string) error {
+		return s.uploadPipe(ctx, ps, pipe, streamObject(gcsDir, channel))
+	})
+	return ps, nil
+}
+
+// uploadPipe uploads the first backup piece written to pipe to object. The
+// pieces after it fail the backup in finish, they are drained until RMAN
+// exits so that RMAN doesn't wait for a reader.
+func (s *Server) uploadPipe(ctx context.Context, ps *pipeStreams, pipe, object string) error {
+	start := time.Now()
+	uploadErr := s.gcsUtil.UploadFile(ctx, object, pipe, contentTypePlainText)
+	if uploadErr == nil {
+		klog.InfoS("dbdaemon/uploadPipe: streamed the backup piece", "pipe", pipe, "object", object, "duration", time.Since(start))
+	}
+	for !ps.stopped() {
+		f, err := os.Open(pipe)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", pipe, err)
+		}
+		io.Copy(ioutil.Discard, f)
+		f.Close()
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("failed to stream %s to %s: %v", pipe, object, uploadErr)
+	}
+	return nil
+}
+
+// streamedChannels returns the number of channels which streamed a backup
+// piece to the GCS directory of the streams.
+func (s *Server) streamedChannels(ctx context.Context, gcsDir string) (int, error) {
+	for channel := 1; channel <= maxStreamChannels; channel++ {
+		r, err := s.gcsUtil.Download(ctx, streamObject(gcsDir, channel))
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return channel - 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		r.Close()
+	}
+	return maxStreamChannels, nil
+}
+
+// streamGCSToPipes creates the named pipes of the channels of a streamed
+// backup and feeds the backup piece of each channel from GCS to its pipe.
+func (s *Server) streamGCSToPipes(ctx context.Context, gcsDir string) (*pipeStreams, error) {
+	channels, err := s.streamedChannels(ctx, gcsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the streamed backup pieces in %s: %v", gcsDir, err)
+	}
+	if channels == 0 {
+		return nil, fmt.Errorf("found no streamed backup pieces in %s", gcsDir)
+	}
+	pipes, err := createStreamPipes(channels)
+	if err != nil {
+		return nil, err
+	}
+	klog.InfoS("dbdaemon/streamGCSToPipes", "channels", channels, "gcsDir", gcsDir)
+	ps := newPipeStreams(pipes, os.O_RDONLY)
+	ps.run(func(channel int, pipe string) error {
+		return s.downloadPipe(ctx, ps, pipe, streamObject(gcsDir, channel))
+	})
+	return ps, nil
+}
+
+// downloadPipe feeds object to pipe each time RMAN opens it to read the
+// backup piece, until RMAN exits. The pipe is fed again only after RMAN
+// closed it, RMAN would read the next piece right after the first one
+// otherwise.
+func (s *Server) downloadPipe(ctx context.Context, ps *pipeStreams, pipe, object string) error {
+	reads, err := watchPipe(pipe, syscall.IN_CLOSE_NOWRITE, false)
+	if err != nil {
+		return err
+	}
+	defer reads.close()
+	var streamErr error
+	for {
+		f, err := os.OpenFile(pipe, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", pipe, err)
+		}
+		if ps.stopped() {
+			f.Close()
+			return streamErr
+		}
+		start := time.Now()
+		err = s.copyObject(ctx, f, object)
+		f.Close()
+		// RMAN may close the pipe before it read the whole piece and open
+		// it again later.
+		if err != nil && !errors.Is(err, syscall.EPIPE) {
+			klog.ErrorS(err, "dbdaemon/downloadPipe: failed to stream the backup piece", "object", object, "pipe", pipe)
+			if streamErr == nil {
+				streamErr = fmt.Errorf("failed to stream %s to %s: %v", object, pipe, err)
+			}
+		} else if err == nil {
+			klog.InfoS("dbdaemon/downloadPipe: streamed the backup piece", "object", object, "pipe", pipe, "duration", time.Since(start))
+		}
+		if _, err := reads.closes(); err != nil {
+			return fmt.Errorf("failed to wait for RMAN to close %s: %v", pipe, err)
+		}
+	}
+}
+
+// copyObject copies the content of a GCS object to w.
+func (s *Server) copyObject(ctx context.Context, w io.Writer, object string) error {
+	r, err := s.gcsUtil.Download(ctx, object)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
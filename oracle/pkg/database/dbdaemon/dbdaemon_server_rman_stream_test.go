@@ -0,0 +1,180 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+)
+
+// lockedGCSUtil is a fakeGCSUtil the streams of several channels can use.
+type lockedGCSUtil struct {
+	mu sync.Mutex
+	fakeGCSUtil
+}
+
+func (f *lockedGCSUtil) Download(ctx context.Context, gcsPath string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fakeGCSUtil.Download(ctx, gcsPath)
+}
+
+func (f *lockedGCSUtil) UploadFile(ctx context.Context, gcsPath, filepath, contentType string) error {
+	content, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads[gcsPath] = string(content)
+	return nil
+}
+
+func newStreamServer(t *testing.T, uploads map[string]string) (*Server, *lockedGCSUtil) {
+	t.Helper()
+	origPipeDir := rmanPipeDir
+	rmanPipeDir = t.TempDir()
+	t.Cleanup(func() { rmanPipeDir = origPipeDir })
+	gcs := &lockedGCSUtil{fakeGCSUtil: fakeGCSUtil{uploads: uploads, contentTypes: make(map[string]string)}}
+	return &Server{gcsUtil: gcs}, gcs
+}
+
+// writePipe writes the backup pieces to the pipe of a channel like RMAN, it
+// may run in another goroutine than the test.
+func writePipe(t *testing.T, channel int, pieces ...string) {
+	t.Helper()
+	for _, piece := range pieces {
+		if err := ioutil.WriteFile(streamPipe(channel), []byte(piece), 0); err != nil {
+			t.Errorf("failed to write to the pipe of channel %d: %v", channel, err)
+		}
+	}
+}
+
+func TestStreamPipesToGCS(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pieces  [][]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "a piece per channel",
+			pieces: [][]string{{"piece1"}, {"piece2"}},
+			want: map[string]string{
+				"gs://bucket/backup/streams/channel1": "piece1",
+				"gs://bucket/backup/streams/channel2": "piece2",
+			},
+		},
+		{
+			name:   "unused channel",
+			pieces: [][]string{{"piece1"}, nil},
+			want: map[string]string{
+				"gs://bucket/backup/streams/channel1": "piece1",
+				"gs://bucket/backup/streams/channel2": "",
+			},
+		},
+		{
+			name:    "more than one piece per channel",
+			pieces:  [][]string{{"piece1", "piece3"}, {"piece2"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, gcs := newStreamServer(t, make(map[string]string))
+			streams, err := s.streamPipesToGCS(context.Background(), len(tc.pieces), "gs://bucket/backup/")
+			if err != nil {
+				t.Fatalf("streamPipesToGCS failed: %v", err)
+			}
+			var wg sync.WaitGroup
+			for i, pieces := range tc.pieces {
+				wg.Add(1)
+				go func(channel int, pieces []string) {
+					defer wg.Done()
+					writePipe(t, channel, pieces...)
+				}(i+1, pieces)
+			}
+			wg.Wait()
+			err = streams.finish()
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("streamPipesToGCS got error %v, want error %v", err, tc.wantErr)
+			}
+			if err == nil {
+				for object, want := range tc.want {
+					if got, ok := gcs.uploads[object]; !ok || got != want {
+						t.Errorf("streamPipesToGCS uploaded %q to %s, want %q", got, object, want)
+					}
+				}
+			}
+			if _, err := os.Stat(streamPipe(1)); !os.IsNotExist(err) {
+				t.Errorf("streamPipesToGCS left the pipes behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamGCSToPipes(t *testing.T) {
+	s, _ := newStreamServer(t, map[string]string{
+		"gs://bucket/backup/streams/channel1": "piece1",
+		"gs://bucket/backup/streams/channel2": "piece2",
+	})
+	streams, err := s.streamGCSToPipes(context.Background(), "gs://bucket/backup/streams")
+	if err != nil {
+		t.Fatalf("streamGCSToPipes failed: %v", err)
+	}
+	// RMAN may read a piece more than once, channel 2 isn't read.
+	for i := 0; i < 2; i++ {
+		got, err := ioutil.ReadFile(streamPipe(1))
+		if err != nil {
+			t.Fatalf("failed to read the pipe of channel 1: %v", err)
+		}
+		if string(got) != "piece1" {
+			t.Errorf("streamGCSToPipes fed %q to channel 1, want %q", got, "piece1")
+		}
+	}
+	if err := streams.finish(); err != nil {
+		t.Errorf("streamGCSToPipes got error %v, want nil", err)
+	}
+	if _, err := os.Stat(streamPipe(2)); !os.IsNotExist(err) {
+		t.Errorf("streamGCSToPipes left the pipes behind: %v", err)
+	}
+}
+
+func TestStreamGCSToPipesNoPieces(t *testing.T) {
+	s, _ := newStreamServer(t, make(map[string]string))
+	if _, err := s.streamGCSToPipes(context.Background(), "gs://bucket/backup/streams"); err == nil {
+		t.Errorf("streamGCSToPipes without streamed pieces succeeded, want an error")
+	}
+}
+
+func TestExcludedDir(t *testing.T) {
+	testCases := []struct {
+		name string
+		want bool
+	}{
+		{name: "rman/mydb/streams/channel1", want: true},
+		{name: "rman/mydb/backupset/o1_mf_nnndf.bkp"},
+		{name: "rman/mydb/streamsfoo/channel1"},
+	}
+	for _, tc := range testCases {
+		if got := excludedDir(tc.name, "rman/mydb", []string{"streams"}); got != tc.want {
+			t.Errorf("excludedDir(%q) got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
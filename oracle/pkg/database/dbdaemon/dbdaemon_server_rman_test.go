@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const rmanShowAllOutput = `
+Recovery Manager: Release 19.0.0.0.0 - Production on Mon May 2 10:11:12 2022
+Version 19.3.0.0.0
+
+Copyright (c) 1982, 2019, Oracle and/or its affiliates.  All rights reserved.
+
+connected to target database: GCLOUD (DBID=1234567890)
+
+RMAN> configure retention policy to recovery window of 7 days;
+using target database control file instead of recovery catalog
+old RMAN configuration parameters:
+CONFIGURE RETENTION POLICY TO REDUNDANCY 1;
+new RMAN configuration parameters:
+CONFIGURE RETENTION POLICY TO RECOVERY WINDOW OF 7 DAYS;
+new RMAN configuration parameters are successfully stored
+
+RMAN> show all;
+RMAN configuration parameters for database with db_unique_name GCLOUD are:
+CONFIGURE RETENTION POLICY TO RECOVERY WINDOW OF 7 DAYS;
+CONFIGURE BACKUP OPTIMIZATION OFF; # default
+CONFIGURE DEFAULT DEVICE TYPE TO DISK; # default
+CONFIGURE CONTROLFILE AUTOBACKUP ON; # default
+CONFIGURE CONTROLFILE AUTOBACKUP FORMAT FOR DEVICE TYPE DISK TO '%F'; # default
+CONFIGURE DEVICE TYPE DISK PARALLELISM 1 BACKUP TYPE TO BACKUPSET; # default
+
+RMAN>
+
+Recovery Manager complete.
+`
+
+func TestRMANConfigureStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *dbdpb.ConfigureRMANRequest
+		want []string
+	}{
+		{
+			name: "defaults",
+			req:  &dbdpb.ConfigureRMANRequest{},
+			want: []string{
+				"CONFIGURE RETENTION POLICY TO REDUNDANCY 1;",
+				"CONFIGURE CONTROLFILE AUTOBACKUP OFF;",
+				"CONFIGURE DEFAULT DEVICE TYPE TO DISK;",
+				"CONFIGURE DEVICE TYPE DISK PARALLELISM 1 BACKUP TYPE TO BACKUPSET;",
+			},
+		},
+		{
+			name: "recovery window",
+			req:  &dbdpb.ConfigureRMANRequest{RecoveryWindowDays: 7, ControlfileAutobackup: true, Parallelism: 4},
+			want: []string{
+				"CONFIGURE RETENTION POLICY TO RECOVERY WINDOW OF 7 DAYS;",
+				"CONFIGURE CONTROLFILE AUTOBACKUP ON;",
+				"CONFIGURE DEFAULT DEVICE TYPE TO DISK;",
+				"CONFIGURE DEVICE TYPE DISK PARALLELISM 4 BACKUP TYPE TO BACKUPSET;",
+			},
+		},
+		{
+			name: "redundancy",
+			req:  &dbdpb.ConfigureRMANRequest{Redundancy: 2, ControlfileAutobackup: true},
+			want: []string{
+				"CONFIGURE RETENTION POLICY TO REDUNDANCY 2;",
+				"CONFIGURE CONTROLFILE AUTOBACKUP ON;",
+				"CONFIGURE DEFAULT DEVICE TYPE TO DISK;",
+				"CONFIGURE DEVICE TYPE DISK PARALLELISM 1 BACKUP TYPE TO BACKUPSET;",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rmanConfigureStatements(tc.req)
+			if err != nil {
+				t.Fatalf("rmanConfigureStatements(%v) failed: %v", tc.req, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("rmanConfigureStatements(%v) got unexpected statements (-want +got):\n%v", tc.req, diff)
+			}
+		})
+	}
+
+	for _, req := range []*dbdpb.ConfigureRMANRequest{
+		{RecoveryWindowDays: 7, Redundancy: 2},
+		{Redundancy: -1},
+		{Parallelism: -1},
+	} {
+		if _, err := rmanConfigureStatements(req); err == nil {
+			t.Errorf("rmanConfigureStatements(%v) succeeded, want error", req)
+		}
+	}
+}
+
+func TestParseRMANShowAll(t *testing.T) {
+	got, err := parseRMANShowAll(rmanShowAllOutput)
+	if err != nil {
+		t.Fatalf("parseRMANShowAll failed: %v", err)
+	}
+	want := []*dbdpb.ConfigureRMANResponse_Setting{
+		{Statement: "CONFIGURE RETENTION POLICY TO RECOVERY WINDOW OF 7 DAYS;"},
+		{Statement: "CONFIGURE BACKUP OPTIMIZATION OFF;", IsDefault: true},
+		{Statement: "CONFIGURE DEFAULT DEVICE TYPE TO DISK;", IsDefault: true},
+		{Statement: "CONFIGURE CONTROLFILE AUTOBACKUP ON;", IsDefault: true},
+		{Statement: "CONFIGURE CONTROLFILE AUTOBACKUP FORMAT FOR DEVICE TYPE DISK TO '%F';", IsDefault: true},
+		{Statement: "CONFIGURE DEVICE TYPE DISK PARALLELISM 1 BACKUP TYPE TO BACKUPSET;", IsDefault: true},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseRMANShowAll got unexpected settings (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseRMANShowAll("RMAN-00569: ERROR MESSAGE STACK FOLLOWS"); err == nil {
+		t.Errorf("parseRMANShowAll without SHOW ALL output succeeded, want error")
+	}
+}
+
+func TestPendingRMANStatements(t *testing.T) {
+	settings, err := parseRMANShowAll(rmanShowAllOutput)
+	if err != nil {
+		t.Fatalf("parseRMANShowAll failed: %v", err)
+	}
+	statements, err := rmanConfigureStatements(&dbdpb.ConfigureRMANRequest{RecoveryWindowDays: 7, ControlfileAutobackup: true, Parallelism: 2})
+	if err != nil {
+		t.Fatalf("rmanConfigureStatements failed: %v", err)
+	}
+	want := []string{"CONFIGURE DEVICE TYPE DISK PARALLELISM 2 BACKUP TYPE TO BACKUPSET;"}
+	if diff := cmp.Diff(want, pendingRMANStatements(statements, settings)); diff != "" {
+		t.Errorf("pendingRMANStatements got unexpected statements (-want +got):\n%v", diff)
+	}
+}
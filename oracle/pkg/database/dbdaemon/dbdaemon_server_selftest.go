@@ -0,0 +1,121 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const selfTestSQL = "select 1 as ok from dual"
+
+// checkGCSAccess verifies the objects under gcsPath can be listed.
+var checkGCSAccess = func(ctx context.Context, gcsPath string) error {
+	u, err := url.Parse(gcsPath)
+	if err != nil || u.Scheme != "gs" || u.Host == "" {
+		return fmt.Errorf("invalid GCS path %q", gcsPath)
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("storage.NewClient: %v", err)
+	}
+	defer client.Close()
+	it := client.Bucket(u.Host).Objects(ctx, &storage.Query{Prefix: strings.TrimPrefix(u.Path, "/")})
+	if _, err := it.Next(); err != nil && err != iterator.Done {
+		return fmt.Errorf("failed to list %s: %v", gcsPath, err)
+	}
+	return nil
+}
+
+type selfTestCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// selfTestChecks returns the checks of the self-test, in the order they run.
+func (s *Server) selfTestChecks(req *dbdpb.SelfTestRequest) []selfTestCheck {
+	checks := []selfTestCheck{
+		{name: "ping", run: s.selfTestPing},
+		{name: "query", run: s.selfTestQuery},
+		{name: "listener", run: s.selfTestListener},
+	}
+	if req.GetGcsPath() != "" {
+		checks = append(checks, selfTestCheck{name: "gcs", run: func(ctx context.Context) error {
+			return checkGCSAccess(ctx, req.GetGcsPath())
+		}})
+	}
+	return checks
+}
+
+// selfTestPing checks the database accepts a local sysdba connection, which
+// open verifies with a ping.
+func (s *Server) selfTestPing(ctx context.Context) error {
+	if err := os.Setenv("ORACLE_SID", s.databaseSid.val); err != nil {
+		return fmt.Errorf("failed to set env variable: %v", err)
+	}
+	db, err := open(ctx, "oracle://?sysdba=1", false)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (s *Server) selfTestQuery(ctx context.Context) error {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{selfTestSQL}}, true)
+	if err != nil {
+		return err
+	}
+	if len(resp.GetMsg()) != 1 {
+		return fmt.Errorf("expected 1 row from %q, got %d", selfTestSQL, len(resp.GetMsg()))
+	}
+	return nil
+}
+
+func (s *Server) selfTestListener(ctx context.Context) error {
+	return s.osUtil.runCommand(lsnrctl(s.databaseHome), []string{"status", consts.SECURE})
+}
+
+// SelfTest runs read-only checks of the operations the daemon depends on:
+// connecting to the database, running a query, reaching the listener and,
+// if requested, reading from GCS. All checks run even if one fails.
+func (s *Server) SelfTest(ctx context.Context, req *dbdpb.SelfTestRequest) (*dbdpb.SelfTestResponse, error) {
+	klog.InfoS("dbdaemon/SelfTest", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp := &dbdpb.SelfTestResponse{}
+	for _, check := range s.selfTestChecks(req) {
+		result := &dbdpb.SelfTestResponse_Check{Name: check.name, Passed: true}
+		if err := check.run(ctx); err != nil {
+			klog.ErrorS(err, "dbdaemon/SelfTest: check failed", "check", check.name)
+			result.Passed = false
+			result.Error = err.Error()
+		}
+		resp.Checks = append(resp.Checks, result)
+	}
+	return resp, nil
+}
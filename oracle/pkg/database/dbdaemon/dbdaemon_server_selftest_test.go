@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// failingOsUtil fails every command.
+type failingOsUtil struct {
+	mockOsUtil
+}
+
+func (f *failingOsUtil) runCommand(bin string, params []string) error {
+	return errors.New("TNS-12541: TNS:no listener")
+}
+
+func TestSelfTest(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name     string
+		req      *dbdpb.SelfTestRequest
+		dbErr    error
+		queryErr error
+		osUtil   osUtil
+		gcsErr   error
+		want     []*dbdpb.SelfTestResponse_Check
+	}{
+		{
+			name: "all passed",
+			req:  &dbdpb.SelfTestRequest{GcsPath: "gs://bucket/backups"},
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+				{Name: "listener", Passed: true},
+				{Name: "gcs", Passed: true},
+			},
+		},
+		{
+			name: "no GCS path",
+			req:  &dbdpb.SelfTestRequest{},
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+				{Name: "listener", Passed: true},
+			},
+		},
+		{
+			name:  "database down",
+			req:   &dbdpb.SelfTestRequest{},
+			dbErr: errors.New("ORA-01034: ORACLE not available"),
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Error: "ORA-01034: ORACLE not available"},
+				{Name: "query", Error: "dbdaemon/RunSQLPlus failed to open a database connection: ORA-01034: ORACLE not available"},
+				{Name: "listener", Passed: true},
+			},
+		},
+		{
+			name:     "query failed",
+			req:      &dbdpb.SelfTestRequest{},
+			queryErr: errFailed,
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Error: "failed"},
+				{Name: "listener", Passed: true},
+			},
+		},
+		{
+			name:   "listener down",
+			req:    &dbdpb.SelfTestRequest{},
+			osUtil: &failingOsUtil{},
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+				{Name: "listener", Error: "TNS-12541: TNS:no listener"},
+			},
+		},
+		{
+			name:   "GCS access denied",
+			req:    &dbdpb.SelfTestRequest{GcsPath: "gs://bucket/backups"},
+			gcsErr: errors.New("storage: permission denied"),
+			want: []*dbdpb.SelfTestResponse_Check{
+				{Name: "ping", Passed: true},
+				{Name: "query", Passed: true},
+				{Name: "listener", Passed: true},
+				{Name: "gcs", Error: "storage: permission denied"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			origNewDB := newDB
+			newDB = func(driverName, dataSourceName string) (oracleDatabase, error) {
+				if tc.dbErr != nil {
+					return nil, tc.dbErr
+				}
+				return fakeOracleDatabase{}, nil
+			}
+			origCheckGCSAccess := checkGCSAccess
+			var gotGCSPath string
+			checkGCSAccess = func(ctx context.Context, gcsPath string) error {
+				gotGCSPath = gcsPath
+				return tc.gcsErr
+			}
+			t.Cleanup(func() {
+				newDB = origNewDB
+				checkGCSAccess = origCheckGCSAccess
+			})
+
+			ctx := context.Background()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+				if tc.queryErr != nil {
+					return nil, tc.queryErr
+				}
+				return []string{`{"OK":"1"}`}, nil
+			}
+			if tc.osUtil != nil {
+				s.osUtil = tc.osUtil
+			}
+
+			resp, err := s.SelfTest(ctx, tc.req)
+			if err != nil {
+				t.Fatalf("SelfTest failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, resp.GetChecks(), protocmp.Transform()); diff != "" {
+				t.Errorf("SelfTest got unexpected checks (-want +got):\n%v", diff)
+			}
+			if gotGCSPath != tc.req.GetGcsPath() {
+				t.Errorf("SelfTest checked GCS path %q, want %q", gotGCSPath, tc.req.GetGcsPath())
+			}
+		})
+	}
+}
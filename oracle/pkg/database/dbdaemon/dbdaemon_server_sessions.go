@@ -0,0 +1,295 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// blockingSessionsSQL reports the sessions waiting on another session and
+// the sessions they wait on.
+const blockingSessionsSQL = "select s.inst_id, s.sid, s.serial# as serial, s.username, s.status, s.program, s.machine, s.sql_id, s.event, " +
+	"case when s.state = 'WAITING' then round(s.wait_time_micro / 1000000) else 0 end as wait_seconds, " +
+	"s.last_call_et as last_call_seconds, s.blocking_instance, s.blocking_session, " +
+	"(select max(l.type) from gv$lock l where l.inst_id = s.inst_id and l.sid = s.sid and l.request > 0) as lock_type " +
+	"from gv$session s where s.blocking_session is not null " +
+	"or (s.inst_id, s.sid) in (select blocking_instance, blocking_session from gv$session where blocking_session is not null)"
+
+// longRunningOpsSQL reports the operations still running with their start
+// time in the UTC offset of the database host, start_time itself is a DATE
+// in the host time zone.
+const longRunningOpsSQL = "select sid, serial# as serial, username, opname, target, sql_id, message, sofar, totalwork, units, " +
+	"elapsed_seconds, time_remaining, " +
+	"to_char(start_time, 'YYYY-MM-DD\"T\"HH24:MI:SS') || to_char(systimestamp, 'TZH:TZM') as start_time " +
+	"from v$session_longops where totalwork > 0 and sofar < totalwork"
+
+// sessionKey identifies a session across the instances.
+type sessionKey struct {
+	instance, sid int64
+}
+
+func (k sessionKey) less(o sessionKey) bool {
+	if k.instance != o.instance {
+		return k.instance < o.instance
+	}
+	return k.sid < o.sid
+}
+
+// rowInt parses an integer column of a row, 0 if the column is null.
+func rowInt(row map[string]string, col string) (int64, error) {
+	if row[col] == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(row[col], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %v", col, err)
+	}
+	return v, nil
+}
+
+// parseBlockingSessions converts blockingSessionsSQL rows into sessions and
+// returns the session each blocked session waits on.
+func parseBlockingSessions(rows []string) (map[sessionKey]*dbdpb.GetBlockingSessionsResponse_Session, map[sessionKey]sessionKey, error) {
+	sessions := make(map[sessionKey]*dbdpb.GetBlockingSessionsResponse_Session)
+	blockers := make(map[sessionKey]sessionKey)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse session row %q: %v", msg, err)
+		}
+		var parseErr error
+		intCol := func(col string) int64 {
+			v, err := rowInt(row, col)
+			if err != nil && parseErr == nil {
+				parseErr = err
+			}
+			return v
+		}
+		session := &dbdpb.GetBlockingSessionsResponse_Session{
+			Instance:        intCol("INST_ID"),
+			Sid:             intCol("SID"),
+			Serial:          intCol("SERIAL"),
+			Username:        row["USERNAME"],
+			Status:          row["STATUS"],
+			Program:         row["PROGRAM"],
+			Machine:         row["MACHINE"],
+			SqlId:           row["SQL_ID"],
+			Event:           row["EVENT"],
+			WaitSeconds:     intCol("WAIT_SECONDS"),
+			LastCallSeconds: intCol("LAST_CALL_SECONDS"),
+			LockType:        row["LOCK_TYPE"],
+		}
+		blocker := sessionKey{instance: intCol("BLOCKING_INSTANCE"), sid: intCol("BLOCKING_SESSION")}
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("%v in session row %q", parseErr, msg)
+		}
+		key := sessionKey{instance: session.GetInstance(), sid: session.GetSid()}
+		sessions[key] = session
+		if row["BLOCKING_SESSION"] != "" {
+			blockers[key] = blocker
+		}
+	}
+	return sessions, blockers, nil
+}
+
+// blockingChains builds the chains of blocked sessions rooted at the
+// sessions which aren't blocked themselves, or at a session of the cycle
+// when the sessions wait on each other. The chains are sorted by longest
+// wait first.
+func blockingChains(sessions map[sessionKey]*dbdpb.GetBlockingSessionsResponse_Session, blockers map[sessionKey]sessionKey) []*dbdpb.GetBlockingSessionsResponse_Chain {
+	waiters := make(map[sessionKey][]sessionKey)
+	for w, b := range blockers {
+		// The blocker may have ended between the reads of gv$session.
+		if _, ok := sessions[b]; !ok {
+			sessions[b] = &dbdpb.GetBlockingSessionsResponse_Session{Instance: b.instance, Sid: b.sid}
+		}
+		waiters[b] = append(waiters[b], w)
+	}
+	var keys []sessionKey
+	for k := range sessions {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+	for _, w := range waiters {
+		sort.Slice(w, func(i, j int) bool { return w[i].less(w[j]) })
+	}
+
+	visited := make(map[sessionKey]bool)
+	var build func(k sessionKey) *dbdpb.GetBlockingSessionsResponse_Session
+	build = func(k sessionKey) *dbdpb.GetBlockingSessionsResponse_Session {
+		visited[k] = true
+		s := sessions[k]
+		s.Waiters = nil
+		for _, w := range waiters[k] {
+			if !visited[w] {
+				s.Waiters = append(s.Waiters, build(w))
+			}
+		}
+		return s
+	}
+	var chains []*dbdpb.GetBlockingSessionsResponse_Chain
+	addChain := func(root sessionKey, deadlock bool) {
+		chain := &dbdpb.GetBlockingSessionsResponse_Chain{Blocker: build(root), Deadlock: deadlock}
+		countWaiters(chain, chain.GetBlocker().GetWaiters())
+		if deadlock && chain.GetBlocker().GetWaitSeconds() > chain.GetMaxWaitSeconds() {
+			chain.MaxWaitSeconds = chain.GetBlocker().GetWaitSeconds()
+		}
+		chains = append(chains, chain)
+	}
+	for _, k := range keys {
+		if _, blocked := blockers[k]; !blocked && len(waiters[k]) > 0 {
+			addChain(k, false)
+		}
+	}
+	// The sessions left wait on a cycle, follow the blockers up to it.
+	for _, k := range keys {
+		if visited[k] {
+			continue
+		}
+		seen := make(map[sessionKey]bool)
+		for !seen[k] {
+			seen[k] = true
+			k = blockers[k]
+		}
+		addChain(k, true)
+	}
+	sort.SliceStable(chains, func(i, j int) bool {
+		return chains[i].GetMaxWaitSeconds() > chains[j].GetMaxWaitSeconds()
+	})
+	return chains
+}
+
+// countWaiters adds the waiters and their own waiters to the chain stats.
+func countWaiters(chain *dbdpb.GetBlockingSessionsResponse_Chain, waiters []*dbdpb.GetBlockingSessionsResponse_Session) {
+	for _, w := range waiters {
+		chain.WaiterCount++
+		if w.GetWaitSeconds() > chain.GetMaxWaitSeconds() {
+			chain.MaxWaitSeconds = w.GetWaitSeconds()
+		}
+		countWaiters(chain, w.GetWaiters())
+	}
+}
+
+// GetBlockingSessions returns the chains of blocked sessions.
+func (s *Server) GetBlockingSessions(ctx context.Context, req *dbdpb.GetBlockingSessionsRequest) (*dbdpb.GetBlockingSessionsResponse, error) {
+	klog.InfoS("dbdaemon/GetBlockingSessions", "req", loggableRequest(req))
+	if req.GetMinWaitSeconds() < 0 {
+		return nil, fmt.Errorf("dbdaemon/GetBlockingSessions: min_wait_seconds must not be negative, got %d", req.GetMinWaitSeconds())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{blockingSessionsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetBlockingSessions: failed to query the blocked sessions: %v", err)
+	}
+	sessions, blockers, err := parseBlockingSessions(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetBlockingSessions: %v", err)
+	}
+	var chains []*dbdpb.GetBlockingSessionsResponse_Chain
+	for _, c := range blockingChains(sessions, blockers) {
+		if c.GetMaxWaitSeconds() >= req.GetMinWaitSeconds() {
+			chains = append(chains, c)
+		}
+	}
+	return &dbdpb.GetBlockingSessionsResponse{Chains: chains}, nil
+}
+
+// parseLongRunningOps converts longRunningOpsSQL rows into operations which
+// have run for at least minElapsedSeconds, longest running first.
+func parseLongRunningOps(rows []string, minElapsedSeconds int64) ([]*dbdpb.GetLongRunningOpsResponse_Operation, error) {
+	var ops []*dbdpb.GetLongRunningOpsResponse_Operation
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse operation row %q: %v", msg, err)
+		}
+		var parseErr error
+		intCol := func(col string) int64 {
+			v, err := rowInt(row, col)
+			if err != nil && parseErr == nil {
+				parseErr = err
+			}
+			return v
+		}
+		op := &dbdpb.GetLongRunningOpsResponse_Operation{
+			Sid:              intCol("SID"),
+			Serial:           intCol("SERIAL"),
+			Username:         row["USERNAME"],
+			Opname:           row["OPNAME"],
+			Target:           row["TARGET"],
+			SqlId:            row["SQL_ID"],
+			Message:          row["MESSAGE"],
+			Sofar:            intCol("SOFAR"),
+			Totalwork:        intCol("TOTALWORK"),
+			Units:            row["UNITS"],
+			ElapsedSeconds:   intCol("ELAPSED_SECONDS"),
+			RemainingSeconds: -1,
+		}
+		if row["TIME_REMAINING"] != "" {
+			op.RemainingSeconds = intCol("TIME_REMAINING")
+		}
+		if parseErr != nil {
+			return nil, fmt.Errorf("%v in operation row %q", parseErr, msg)
+		}
+		if op.GetElapsedSeconds() < minElapsedSeconds {
+			continue
+		}
+		start, err := parseUsageTime(row["START_TIME"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse START_TIME in operation row %q: %v", msg, err)
+		}
+		op.StartTime = start
+		if op.GetTotalwork() > 0 {
+			op.PercentDone = 100 * float64(op.GetSofar()) / float64(op.GetTotalwork())
+		}
+		ops = append(ops, op)
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].GetElapsedSeconds() > ops[j].GetElapsedSeconds()
+	})
+	return ops, nil
+}
+
+// GetLongRunningOps returns the progress of the running operations of
+// v$session_longops.
+func (s *Server) GetLongRunningOps(ctx context.Context, req *dbdpb.GetLongRunningOpsRequest) (*dbdpb.GetLongRunningOpsResponse, error) {
+	klog.InfoS("dbdaemon/GetLongRunningOps", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{longRunningOpsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetLongRunningOps: failed to query the long running operations: %v", err)
+	}
+	ops, err := parseLongRunningOps(resp.GetMsg(), req.GetMinElapsedSeconds())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetLongRunningOps: %v", err)
+	}
+	return &dbdpb.GetLongRunningOpsResponse{Operations: ops}, nil
+}
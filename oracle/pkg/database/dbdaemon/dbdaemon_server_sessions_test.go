@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestBlockingChains(t *testing.T) {
+	rows := []string{
+		// SCOTT left a transaction open and blocks two sessions, one of
+		// which blocks another one.
+		`{"INST_ID": "1", "SID": "11", "SERIAL": "101", "USERNAME": "SCOTT", "STATUS": "INACTIVE", "EVENT": "SQL*Net message from client", "WAIT_SECONDS": "600", "LAST_CALL_SECONDS": "600"}`,
+		`{"INST_ID": "1", "SID": "12", "SERIAL": "102", "USERNAME": "APP", "STATUS": "ACTIVE", "SQL_ID": "a1", "EVENT": "enq: TX - row lock contention", "WAIT_SECONDS": "30", "LAST_CALL_SECONDS": "30", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "11", "LOCK_TYPE": "TX"}`,
+		`{"INST_ID": "1", "SID": "13", "SERIAL": "103", "USERNAME": "APP", "STATUS": "ACTIVE", "SQL_ID": "a2", "EVENT": "enq: TM - contention", "WAIT_SECONDS": "10", "LAST_CALL_SECONDS": "10", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "12", "LOCK_TYPE": "TM"}`,
+		`{"INST_ID": "1", "SID": "14", "SERIAL": "104", "USERNAME": "APP", "STATUS": "ACTIVE", "SQL_ID": "a1", "EVENT": "enq: TX - row lock contention", "WAIT_SECONDS": "60", "LAST_CALL_SECONDS": "60", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "11", "LOCK_TYPE": "TX"}`,
+		// The blocker of this session ended before it was read.
+		`{"INST_ID": "2", "SID": "7", "SERIAL": "70", "USERNAME": "APP", "STATUS": "ACTIVE", "WAIT_SECONDS": "5", "LAST_CALL_SECONDS": "5", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "9", "LOCK_TYPE": "TX"}`,
+		// Two sessions waiting on each other, and a third one waiting on
+		// them.
+		`{"INST_ID": "1", "SID": "20", "SERIAL": "200", "USERNAME": "BATCH", "STATUS": "ACTIVE", "WAIT_SECONDS": "100", "LAST_CALL_SECONDS": "100", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "21", "LOCK_TYPE": "TX"}`,
+		`{"INST_ID": "1", "SID": "21", "SERIAL": "210", "USERNAME": "BATCH", "STATUS": "ACTIVE", "WAIT_SECONDS": "90", "LAST_CALL_SECONDS": "90", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "20", "LOCK_TYPE": "TX"}`,
+		`{"INST_ID": "1", "SID": "22", "SERIAL": "220", "USERNAME": "BATCH", "STATUS": "ACTIVE", "WAIT_SECONDS": "5", "LAST_CALL_SECONDS": "5", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "21", "LOCK_TYPE": "TX"}`,
+	}
+	want := []*dbdpb.GetBlockingSessionsResponse_Chain{
+		{
+			Blocker: &dbdpb.GetBlockingSessionsResponse_Session{
+				Instance: 1, Sid: 20, Serial: 200, Username: "BATCH", Status: "ACTIVE", WaitSeconds: 100, LastCallSeconds: 100, LockType: "TX",
+				Waiters: []*dbdpb.GetBlockingSessionsResponse_Session{
+					{
+						Instance: 1, Sid: 21, Serial: 210, Username: "BATCH", Status: "ACTIVE", WaitSeconds: 90, LastCallSeconds: 90, LockType: "TX",
+						Waiters: []*dbdpb.GetBlockingSessionsResponse_Session{
+							{Instance: 1, Sid: 22, Serial: 220, Username: "BATCH", Status: "ACTIVE", WaitSeconds: 5, LastCallSeconds: 5, LockType: "TX"},
+						},
+					},
+				},
+			},
+			WaiterCount:    2,
+			MaxWaitSeconds: 100,
+			Deadlock:       true,
+		},
+		{
+			Blocker: &dbdpb.GetBlockingSessionsResponse_Session{
+				Instance: 1, Sid: 11, Serial: 101, Username: "SCOTT", Status: "INACTIVE", Event: "SQL*Net message from client", WaitSeconds: 600, LastCallSeconds: 600,
+				Waiters: []*dbdpb.GetBlockingSessionsResponse_Session{
+					{
+						Instance: 1, Sid: 12, Serial: 102, Username: "APP", Status: "ACTIVE", SqlId: "a1", Event: "enq: TX - row lock contention", WaitSeconds: 30, LastCallSeconds: 30, LockType: "TX",
+						Waiters: []*dbdpb.GetBlockingSessionsResponse_Session{
+							{Instance: 1, Sid: 13, Serial: 103, Username: "APP", Status: "ACTIVE", SqlId: "a2", Event: "enq: TM - contention", WaitSeconds: 10, LastCallSeconds: 10, LockType: "TM"},
+						},
+					},
+					{Instance: 1, Sid: 14, Serial: 104, Username: "APP", Status: "ACTIVE", SqlId: "a1", Event: "enq: TX - row lock contention", WaitSeconds: 60, LastCallSeconds: 60, LockType: "TX"},
+				},
+			},
+			WaiterCount:    3,
+			MaxWaitSeconds: 60,
+		},
+		{
+			Blocker: &dbdpb.GetBlockingSessionsResponse_Session{
+				Instance: 1, Sid: 9,
+				Waiters: []*dbdpb.GetBlockingSessionsResponse_Session{
+					{Instance: 2, Sid: 7, Serial: 70, Username: "APP", Status: "ACTIVE", WaitSeconds: 5, LastCallSeconds: 5, LockType: "TX"},
+				},
+			},
+			WaiterCount:    1,
+			MaxWaitSeconds: 5,
+		},
+	}
+	sessions, blockers, err := parseBlockingSessions(rows)
+	if err != nil {
+		t.Fatalf("parseBlockingSessions failed: %v", err)
+	}
+	got := blockingChains(sessions, blockers)
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("blockingChains got unexpected chains (-want +got):\n%v", diff)
+	}
+
+	if got := blockingChains(map[sessionKey]*dbdpb.GetBlockingSessionsResponse_Session{}, map[sessionKey]sessionKey{}); len(got) != 0 {
+		t.Errorf("blockingChains got %v without blocked sessions, want none", got)
+	}
+
+	for _, row := range []string{
+		`{"INST_ID": "1", "SID": "x"}`,
+		`{"INST_ID": "1", "SID": "12", "BLOCKING_INSTANCE": "1", "BLOCKING_SESSION": "eleven"}`,
+		`not json`,
+	} {
+		if _, _, err := parseBlockingSessions([]string{row}); err == nil {
+			t.Errorf("parseBlockingSessions(%q) succeeded, want an error", row)
+		}
+	}
+}
+
+func TestParseLongRunningOps(t *testing.T) {
+	rows := []string{
+		`{"SID": "12", "SERIAL": "102", "USERNAME": "SYS", "OPNAME": "RMAN: aggregate input", "TARGET": "", "SOFAR": "250", "TOTALWORK": "1000", "UNITS": "Blocks", "ELAPSED_SECONDS": "120", "TIME_REMAINING": "360", "START_TIME": "2023-01-02T03:04:05+02:00", "MESSAGE": "RMAN: aggregate input: 250 out of 1000 Blocks done"}`,
+		`{"SID": "13", "SERIAL": "103", "USERNAME": "APP", "OPNAME": "Table Scan", "TARGET": "APP.ORDERS", "SQL_ID": "a1", "SOFAR": "10", "TOTALWORK": "40", "UNITS": "Blocks", "ELAPSED_SECONDS": "600", "TIME_REMAINING": "", "START_TIME": "2023-01-02T02:56:05+02:00"}`,
+		`{"SID": "14", "SERIAL": "104", "USERNAME": "APP", "OPNAME": "Sort Output", "SOFAR": "1", "TOTALWORK": "2", "UNITS": "Blocks", "ELAPSED_SECONDS": "8", "TIME_REMAINING": "8", "START_TIME": "2023-01-02T03:05:57+02:00"}`,
+	}
+	start := time.Date(2023, 1, 2, 1, 4, 5, 0, time.UTC)
+	want := []*dbdpb.GetLongRunningOpsResponse_Operation{
+		{
+			Sid:              13,
+			Serial:           103,
+			Username:         "APP",
+			Opname:           "Table Scan",
+			Target:           "APP.ORDERS",
+			SqlId:            "a1",
+			Sofar:            10,
+			Totalwork:        40,
+			Units:            "Blocks",
+			PercentDone:      25,
+			ElapsedSeconds:   600,
+			RemainingSeconds: -1,
+			StartTime:        timestamppb.New(start.Add(-8 * time.Minute)),
+		},
+		{
+			Sid:              12,
+			Serial:           102,
+			Username:         "SYS",
+			Opname:           "RMAN: aggregate input",
+			Message:          "RMAN: aggregate input: 250 out of 1000 Blocks done",
+			Sofar:            250,
+			Totalwork:        1000,
+			Units:            "Blocks",
+			PercentDone:      25,
+			ElapsedSeconds:   120,
+			RemainingSeconds: 360,
+			StartTime:        timestamppb.New(start),
+		},
+	}
+	got, err := parseLongRunningOps(rows, 60)
+	if err != nil {
+		t.Fatalf("parseLongRunningOps failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseLongRunningOps got unexpected operations (-want +got):\n%v", diff)
+	}
+
+	for _, row := range []string{
+		`{"SID": "12", "SOFAR": "many"}`,
+		`{"SID": "12", "ELAPSED_SECONDS": "120", "START_TIME": "02-JAN-23"}`,
+		`not json`,
+	} {
+		if _, err := parseLongRunningOps([]string{row}, 0); err == nil {
+			t.Errorf("parseLongRunningOps(%q) succeeded, want an error", row)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	datafileHeadersSQL = "select name, status, error, recover, bytes from v$datafile_header order by file#"
+	controlfilesSQL    = "select name from v$controlfile"
+	logfilesSQL        = "select member as name from v$logfile"
+)
+
+// databaseFile is a controlfile, a datafile or an online redo log member.
+// The header fields are only set for datafiles.
+type databaseFile struct {
+	kind    string
+	name    string
+	bytes   int64
+	status  string
+	err     string
+	recover string
+}
+
+// parseDatafileHeaders converts datafileHeadersSQL rows into datafiles.
+func parseDatafileHeaders(rows []string) ([]databaseFile, error) {
+	var files []databaseFile
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse datafile header row %q: %v", msg, err)
+		}
+		var bytes int64
+		if row["BYTES"] != "" {
+			var err error
+			if bytes, err = strconv.ParseInt(row["BYTES"], 10, 64); err != nil {
+				return nil, fmt.Errorf("failed to parse BYTES in datafile header row %q: %v", msg, err)
+			}
+		}
+		files = append(files, databaseFile{
+			kind:    "datafile",
+			name:    row["NAME"],
+			bytes:   bytes,
+			status:  row["STATUS"],
+			err:     row["ERROR"],
+			recover: row["RECOVER"],
+		})
+	}
+	return files, nil
+}
+
+// parseFileNames converts controlfilesSQL or logfilesSQL rows into files of
+// the given kind.
+func parseFileNames(kind string, rows []string) ([]databaseFile, error) {
+	var files []databaseFile
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse %s row %q: %v", kind, msg, err)
+		}
+		files = append(files, databaseFile{kind: kind, name: row["NAME"]})
+	}
+	return files, nil
+}
+
+// onMount reports whether the file is under one of the mount paths.
+func onMount(name string, mounts []string) bool {
+	name = filepath.Clean(name)
+	for _, m := range mounts {
+		m = filepath.Clean(m)
+		if strings.HasPrefix(name, m+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotFileProblems returns why a snapshot of the mounts would not be
+// restorable: files outside of the mounts, missing or truncated files, and
+// datafile headers that are offline, unreadable or need media recovery.
+// fileSize returns the size of a file on disk.
+func snapshotFileProblems(files []databaseFile, mounts []string, fileSize func(string) (int64, error)) []string {
+	var problems []string
+	if len(files) == 0 {
+		return []string{"no database files found"}
+	}
+	for _, f := range files {
+		if f.name == "" {
+			problems = append(problems, fmt.Sprintf("%s without a name, its header is unreadable", f.kind))
+			continue
+		}
+		if !onMount(f.name, mounts) {
+			problems = append(problems, fmt.Sprintf("%s %s is not on a snapshotted volume", f.kind, f.name))
+			continue
+		}
+		size, err := fileSize(f.name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s is not readable: %v", f.kind, f.name, err))
+			continue
+		}
+		if size < f.bytes {
+			problems = append(problems, fmt.Sprintf("%s %s is truncated to %d bytes, its header records %d bytes", f.kind, f.name, size, f.bytes))
+		}
+		if f.err != "" {
+			problems = append(problems, fmt.Sprintf("%s %s header error: %s", f.kind, f.name, f.err))
+		}
+		if f.status == "OFFLINE" {
+			problems = append(problems, fmt.Sprintf("%s %s is offline", f.kind, f.name))
+		}
+		if f.recover == "YES" {
+			problems = append(problems, fmt.Sprintf("%s %s needs media recovery", f.kind, f.name))
+		}
+	}
+	return problems
+}
+
+// ValidateSnapshotFiles checks that a snapshot of the volumes mounted at the
+// mount paths of the request is restorable, that is it includes all the
+// controlfiles, datafiles and online redo logs of the database, the latter
+// for crash recovery, and that no datafile needs media recovery.
+func (s *Server) ValidateSnapshotFiles(ctx context.Context, req *dbdpb.ValidateSnapshotFilesRequest) (*dbdpb.ValidateSnapshotFilesResponse, error) {
+	klog.InfoS("dbdaemon/ValidateSnapshotFiles", "req", loggableRequest(req))
+	if len(req.GetMountPaths()) == 0 {
+		return nil, fmt.Errorf("dbdaemon/ValidateSnapshotFiles: no mount paths")
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	var files []databaseFile
+	for _, q := range []struct {
+		kind  string
+		sql   string
+		parse func([]string) ([]databaseFile, error)
+	}{
+		{kind: "datafile", sql: datafileHeadersSQL, parse: parseDatafileHeaders},
+		{kind: "controlfile", sql: controlfilesSQL, parse: func(rows []string) ([]databaseFile, error) { return parseFileNames("controlfile", rows) }},
+		{kind: "redo log", sql: logfilesSQL, parse: func(rows []string) ([]databaseFile, error) { return parseFileNames("redo log", rows) }},
+	} {
+		resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{q.sql}}, true)
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ValidateSnapshotFiles: failed to query %s files: %v", q.kind, err)
+		}
+		f, err := q.parse(resp.GetMsg())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ValidateSnapshotFiles: %v", err)
+		}
+		files = append(files, f...)
+	}
+
+	problems := snapshotFileProblems(files, req.GetMountPaths(), func(name string) (int64, error) {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return 0, err
+		}
+		return fi.Size(), nil
+	})
+	klog.InfoS("dbdaemon/ValidateSnapshotFiles: done", "files", len(files), "problems", problems)
+	return &dbdpb.ValidateSnapshotFilesResponse{Problems: problems}, nil
+}
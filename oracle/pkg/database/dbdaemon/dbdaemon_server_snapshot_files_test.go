@@ -0,0 +1,191 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseDatafileHeaders(t *testing.T) {
+	got, err := parseDatafileHeaders([]string{
+		`{"NAME":"/u02/app/oracle/oradata/GCLOUD/system01.dbf","STATUS":"ONLINE","RECOVER":"NO","BYTES":"943718400"}`,
+		`{"NAME":"/u02/app/oracle/oradata/GCLOUD/users01.dbf","STATUS":"OFFLINE","ERROR":"FILE NOT FOUND","BYTES":"0"}`,
+		`{"STATUS":"ONLINE","ERROR":"CANNOT OPEN FILE"}`,
+	})
+	if err != nil {
+		t.Fatalf("parseDatafileHeaders failed: %v", err)
+	}
+	want := []databaseFile{
+		{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/system01.dbf", bytes: 943718400, status: "ONLINE", recover: "NO"},
+		{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/users01.dbf", status: "OFFLINE", err: "FILE NOT FOUND"},
+		{kind: "datafile", status: "ONLINE", err: "CANNOT OPEN FILE"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(databaseFile{})); diff != "" {
+		t.Errorf("parseDatafileHeaders got unexpected result (-want +got):\n%v", diff)
+	}
+
+	for _, row := range []string{
+		"not json",
+		`{"NAME":"/u02/system01.dbf","STATUS":"ONLINE","BYTES":"1G"}`,
+	} {
+		if _, err := parseDatafileHeaders([]string{row}); err == nil {
+			t.Errorf("parseDatafileHeaders(%q) succeeded, want error", row)
+		}
+	}
+}
+
+func TestSnapshotFileProblems(t *testing.T) {
+	sizes := map[string]int64{
+		"/u02/app/oracle/oradata/GCLOUD/system01.dbf":      1000,
+		"/u02/app/oracle/oradata/GCLOUD/sysaux01.dbf":      500,
+		"/u02/app/oracle/oradata/GCLOUD/control01.ctl":     100,
+		"/u03/app/oracle/fast_recovery_area/control02.ctl": 100,
+		"/u02/app/oracle/oradata/GCLOUD/redo01.log":        200,
+		"/u020/users01.dbf":                                1000,
+	}
+	fileSize := func(name string) (int64, error) {
+		if size, ok := sizes[name]; ok {
+			return size, nil
+		}
+		return 0, os.ErrNotExist
+	}
+	consistent := []databaseFile{
+		{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/system01.dbf", bytes: 1000, status: "ONLINE", recover: "NO"},
+		{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/sysaux01.dbf", bytes: 500, status: "ONLINE"},
+		{kind: "controlfile", name: "/u02/app/oracle/oradata/GCLOUD/control01.ctl"},
+		{kind: "controlfile", name: "/u03/app/oracle/fast_recovery_area/control02.ctl"},
+		{kind: "redo log", name: "/u02/app/oracle/oradata/GCLOUD/redo01.log"},
+	}
+	tests := []struct {
+		name  string
+		files []databaseFile
+		want  []string
+	}{
+		{
+			name:  "consistent",
+			files: consistent,
+		},
+		{
+			name: "no files",
+			want: []string{"no database files found"},
+		},
+		{
+			name: "file outside of the volumes",
+			files: append(consistent[:2:2],
+				databaseFile{kind: "datafile", name: "/u020/users01.dbf", bytes: 1000, status: "ONLINE"},
+				databaseFile{kind: "redo log", name: "+DATA/GCLOUD/redo02.log"},
+			),
+			want: []string{
+				"datafile /u020/users01.dbf is not on a snapshotted volume",
+				"redo log +DATA/GCLOUD/redo02.log is not on a snapshotted volume",
+			},
+		},
+		{
+			name: "missing file",
+			files: []databaseFile{
+				{kind: "controlfile", name: "/u03/app/oracle/control03.ctl"},
+			},
+			want: []string{"controlfile /u03/app/oracle/control03.ctl is not readable: file does not exist"},
+		},
+		{
+			name: "inconsistent headers",
+			files: []databaseFile{
+				{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/system01.dbf", bytes: 2000, status: "ONLINE"},
+				{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/sysaux01.dbf", bytes: 500, status: "OFFLINE", recover: "YES"},
+				{kind: "datafile", err: "CANNOT OPEN FILE"},
+			},
+			want: []string{
+				"datafile /u02/app/oracle/oradata/GCLOUD/system01.dbf is truncated to 1000 bytes, its header records 2000 bytes",
+				"datafile /u02/app/oracle/oradata/GCLOUD/sysaux01.dbf is offline",
+				"datafile /u02/app/oracle/oradata/GCLOUD/sysaux01.dbf needs media recovery",
+				"datafile without a name, its header is unreadable",
+			},
+		},
+		{
+			name: "header error",
+			files: []databaseFile{
+				{kind: "datafile", name: "/u02/app/oracle/oradata/GCLOUD/system01.dbf", bytes: 1000, status: "ONLINE", err: "WRONG FILE TYPE"},
+			},
+			want: []string{"datafile /u02/app/oracle/oradata/GCLOUD/system01.dbf header error: WRONG FILE TYPE"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := snapshotFileProblems(tc.files, []string{"/u02", "/u03/"}, fileSize)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("snapshotFileProblems got unexpected result (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshotFiles(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	dir := t.TempDir()
+	datafile := filepath.Join(dir, "system01.dbf")
+	if err := os.WriteFile(datafile, make([]byte, 8192), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", datafile, err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[len(sqls)-1] {
+		case datafileHeadersSQL:
+			return []string{`{"NAME":"` + datafile + `","STATUS":"ONLINE","RECOVER":"NO","BYTES":"8192"}`}, nil
+		case controlfilesSQL:
+			return []string{`{"NAME":"` + filepath.Join(dir, "control01.ctl") + `"}`}, nil
+		case logfilesSQL:
+			return nil, errors.New("ORA-01219: database or pluggable database not open")
+		}
+		t.Fatalf("runQuery got unexpected query %q", sqls)
+		return nil, nil
+	}
+
+	if _, err := s.ValidateSnapshotFiles(ctx, &dbdpb.ValidateSnapshotFilesRequest{}); err == nil {
+		t.Errorf("ValidateSnapshotFiles without mount paths succeeded, want error")
+	}
+	if _, err := s.ValidateSnapshotFiles(ctx, &dbdpb.ValidateSnapshotFilesRequest{MountPaths: []string{dir}}); err == nil || !strings.Contains(err.Error(), "ORA-01219") {
+		t.Errorf("ValidateSnapshotFiles got error %v, want the redo log query error", err)
+	}
+
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[len(sqls)-1] {
+		case datafileHeadersSQL:
+			return []string{`{"NAME":"` + datafile + `","STATUS":"ONLINE","RECOVER":"NO","BYTES":"8192"}`}, nil
+		case controlfilesSQL:
+			return []string{`{"NAME":"` + filepath.Join(dir, "control01.ctl") + `"}`}, nil
+		}
+		return nil, nil
+	}
+	resp, err := s.ValidateSnapshotFiles(ctx, &dbdpb.ValidateSnapshotFilesRequest{MountPaths: []string{dir}})
+	if err != nil {
+		t.Fatalf("ValidateSnapshotFiles failed: %v", err)
+	}
+	if got := resp.GetProblems(); len(got) != 1 || !strings.Contains(got[0], "control01.ctl is not readable") {
+		t.Errorf("ValidateSnapshotFiles reported problems %q, want the missing controlfile", got)
+	}
+}
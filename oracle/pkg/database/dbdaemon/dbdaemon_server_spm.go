@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	sqlPlanCaptureSQL = "select upper(value) as value from v$parameter where name='optimizer_capture_sql_plan_baselines'"
+
+	// setSQLPlanCaptureCmd only changes the running instance, an instance
+	// restarted outside of a capture window doesn't capture until the
+	// capture is enabled again.
+	setSQLPlanCaptureCmd = "alter system set optimizer_capture_sql_plan_baselines=%s container=all scope=memory"
+
+	// sqlPlanBaselinesSQL summarizes the baselines of all the containers,
+	// the creation times are converted to UTC with the offset of the
+	// database host. The %s is the UTC time the baselines captured since
+	// are counted from.
+	sqlPlanBaselinesSQL = "select to_char(count(*)) as baselines, " +
+		"to_char(count(case when enabled = 'YES' then 1 end)) as enabled, " +
+		"to_char(count(case when accepted = 'YES' then 1 end)) as accepted, " +
+		"to_char(count(case when fixed = 'YES' then 1 end)) as fixed, " +
+		"to_char(count(case when origin like 'AUTO-CAPTURE%%' then 1 end)) as auto_captured, " +
+		"to_char(count(case when origin like 'AUTO-CAPTURE%%' and sys_extract_utc(from_tz(created, to_char(systimestamp, 'TZH:TZM'))) >= timestamp '%s' then 1 end)) as captured_since, " +
+		"to_char(max(case when origin like 'AUTO-CAPTURE%%' then sys_extract_utc(from_tz(created, to_char(systimestamp, 'TZH:TZM'))) end), 'YYYY-MM-DD\"T\"HH24:MI:SS\"Z\"') as last_capture " +
+		"from cdb_sql_plan_baselines"
+)
+
+// sqlPlanCaptureValue returns the optimizer_capture_sql_plan_baselines
+// value enabling or disabling the capture.
+func sqlPlanCaptureValue(enabled bool) string {
+	return strings.ToUpper(strconv.FormatBool(enabled))
+}
+
+// parseSQLPlanBaselines converts a sqlPlanBaselinesSQL row into a
+// GetSQLPlanBaselines response.
+func parseSQLPlanBaselines(msg string) (*dbdpb.GetSQLPlanBaselinesResponse, error) {
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(msg), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse SQL plan baselines row %q: %v", msg, err)
+	}
+	resp := &dbdpb.GetSQLPlanBaselinesResponse{}
+	for col, field := range map[string]*int64{
+		"BASELINES":      &resp.Baselines,
+		"ENABLED":        &resp.EnabledBaselines,
+		"ACCEPTED":       &resp.AcceptedBaselines,
+		"FIXED":          &resp.FixedBaselines,
+		"AUTO_CAPTURED":  &resp.AutoCapturedBaselines,
+		"CAPTURED_SINCE": &resp.CapturedSinceCount,
+	} {
+		v, err := strconv.ParseInt(row[col], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in SQL plan baselines row %q: %v", col, msg, err)
+		}
+		*field = v
+	}
+	if row["LAST_CAPTURE"] != "" {
+		t, err := time.Parse(time.RFC3339, row["LAST_CAPTURE"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LAST_CAPTURE in SQL plan baselines row %q: %v", msg, err)
+		}
+		resp.LastCaptureTime = timestamppb.New(t)
+	}
+	return resp, nil
+}
+
+// sqlPlanCaptureEnabled returns whether the instance captures SQL plan
+// baselines.
+func (s *Server) sqlPlanCaptureEnabled(ctx context.Context) (bool, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{sqlPlanCaptureSQL}}, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to query optimizer_capture_sql_plan_baselines: %v", err)
+	}
+	if len(resp.GetMsg()) != 1 {
+		return false, fmt.Errorf("got %d optimizer_capture_sql_plan_baselines rows, want 1", len(resp.GetMsg()))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(resp.GetMsg()[0]), &row); err != nil {
+		return false, fmt.Errorf("failed to parse optimizer_capture_sql_plan_baselines row %q: %v", resp.GetMsg()[0], err)
+	}
+	return row["VALUE"] == sqlPlanCaptureValue(true), nil
+}
+
+// SetSQLPlanCapture sets optimizer_capture_sql_plan_baselines in all the
+// containers of the running instance if it differs.
+func (s *Server) SetSQLPlanCapture(ctx context.Context, req *dbdpb.SetSQLPlanCaptureRequest) (*dbdpb.SetSQLPlanCaptureResponse, error) {
+	klog.InfoS("dbdaemon/SetSQLPlanCapture", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	enabled, err := s.sqlPlanCaptureEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetSQLPlanCapture: %v", err)
+	}
+	if enabled == req.GetEnabled() {
+		return &dbdpb.SetSQLPlanCaptureResponse{}, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{fmt.Sprintf(setSQLPlanCaptureCmd, sqlPlanCaptureValue(req.GetEnabled()))},
+	}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetSQLPlanCapture: failed to set optimizer_capture_sql_plan_baselines: %v", err)
+	}
+	return &dbdpb.SetSQLPlanCaptureResponse{Changed: true}, nil
+}
+
+// GetSQLPlanBaselines returns whether the instance captures SQL plan
+// baselines and counts the baselines of all the containers.
+func (s *Server) GetSQLPlanBaselines(ctx context.Context, req *dbdpb.GetSQLPlanBaselinesRequest) (*dbdpb.GetSQLPlanBaselinesResponse, error) {
+	klog.InfoS("dbdaemon/GetSQLPlanBaselines", "req", loggableRequest(req))
+	since := time.Unix(0, 0)
+	if req.GetCapturedSince() != nil {
+		since = req.GetCapturedSince().AsTime()
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	enabled, err := s.sqlPlanCaptureEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLPlanBaselines: %v", err)
+	}
+	sqlResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{fmt.Sprintf(sqlPlanBaselinesSQL, since.UTC().Format("2006-01-02 15:04:05"))},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLPlanBaselines: failed to query SQL plan baselines: %v", err)
+	}
+	if len(sqlResp.GetMsg()) != 1 {
+		return nil, fmt.Errorf("dbdaemon/GetSQLPlanBaselines: got %d SQL plan baselines rows, want 1", len(sqlResp.GetMsg()))
+	}
+	resp, err := parseSQLPlanBaselines(sqlResp.GetMsg()[0])
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLPlanBaselines: %v", err)
+	}
+	resp.CaptureEnabled = enabled
+	if req.GetCapturedSince() == nil {
+		resp.CapturedSinceCount = 0
+	}
+	return resp, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseSQLPlanBaselines(t *testing.T) {
+	got, err := parseSQLPlanBaselines(`{"BASELINES":"12","ENABLED":"11","ACCEPTED":"9","FIXED":"1","AUTO_CAPTURED":"10","CAPTURED_SINCE":"3","LAST_CAPTURE":"2026-10-16T02:30:00Z"}`)
+	if err != nil {
+		t.Fatalf("parseSQLPlanBaselines failed: %v", err)
+	}
+	want := &dbdpb.GetSQLPlanBaselinesResponse{
+		Baselines:             12,
+		EnabledBaselines:      11,
+		AcceptedBaselines:     9,
+		FixedBaselines:        1,
+		AutoCapturedBaselines: 10,
+		CapturedSinceCount:    3,
+		LastCaptureTime:       timestamppb.New(time.Date(2026, 10, 16, 2, 30, 0, 0, time.UTC)),
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseSQLPlanBaselines got unexpected response (-want +got):\n%v", diff)
+	}
+
+	got, err = parseSQLPlanBaselines(`{"BASELINES":"0","ENABLED":"0","ACCEPTED":"0","FIXED":"0","AUTO_CAPTURED":"0","CAPTURED_SINCE":"0","LAST_CAPTURE":""}`)
+	if err != nil {
+		t.Fatalf("parseSQLPlanBaselines without baselines failed: %v", err)
+	}
+	if got.GetLastCaptureTime() != nil {
+		t.Errorf("parseSQLPlanBaselines without baselines got last capture %v, want none", got.GetLastCaptureTime())
+	}
+}
+
+func TestSetSQLPlanCapture(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	testCases := []struct {
+		name        string
+		current     string
+		enabled     bool
+		wantSQL     string
+		wantChanged bool
+	}{
+		{
+			name:        "enable",
+			current:     "FALSE",
+			enabled:     true,
+			wantSQL:     "alter system set optimizer_capture_sql_plan_baselines=TRUE container=all scope=memory",
+			wantChanged: true,
+		},
+		{
+			name:        "disable",
+			current:     "TRUE",
+			wantSQL:     "alter system set optimizer_capture_sql_plan_baselines=FALSE container=all scope=memory",
+			wantChanged: true,
+		},
+		{
+			name:    "already enabled",
+			current: "TRUE",
+			enabled: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				if sqls[0] != sqlPlanCaptureSQL {
+					return nil, fmt.Errorf("unexpected query %q", sqls)
+				}
+				return []string{fmt.Sprintf(`{"VALUE":%q}`, tc.current)}, nil
+			}
+			var got string
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				got = strings.Join(sqls, ";")
+				return nil, nil
+			}
+			resp, err := s.SetSQLPlanCapture(ctx, &dbdpb.SetSQLPlanCaptureRequest{Enabled: tc.enabled})
+			if err != nil {
+				t.Fatalf("SetSQLPlanCapture failed: %v", err)
+			}
+			if got != tc.wantSQL {
+				t.Errorf("SetSQLPlanCapture ran %q, want %q", got, tc.wantSQL)
+			}
+			if resp.GetChanged() != tc.wantChanged {
+				t.Errorf("SetSQLPlanCapture got changed %v, want %v", resp.GetChanged(), tc.wantChanged)
+			}
+		})
+	}
+}
+
+func TestGetSQLPlanBaselines(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	var gotQuery string
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if sqls[0] == sqlPlanCaptureSQL {
+			return []string{`{"VALUE":"TRUE"}`}, nil
+		}
+		gotQuery = sqls[0]
+		return []string{`{"BASELINES":"4","ENABLED":"4","ACCEPTED":"2","FIXED":"0","AUTO_CAPTURED":"4","CAPTURED_SINCE":"2","LAST_CAPTURE":"2026-10-16T02:30:00Z"}`}, nil
+	}
+	since := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	resp, err := s.GetSQLPlanBaselines(ctx, &dbdpb.GetSQLPlanBaselinesRequest{CapturedSince: timestamppb.New(since)})
+	if err != nil {
+		t.Fatalf("GetSQLPlanBaselines failed: %v", err)
+	}
+	if !strings.Contains(gotQuery, "timestamp '2026-10-16 02:00:00'") {
+		t.Errorf("GetSQLPlanBaselines ran %q, want the baselines captured since 2026-10-16 02:00:00 UTC", gotQuery)
+	}
+	if !resp.GetCaptureEnabled() || resp.GetCapturedSinceCount() != 2 || resp.GetAcceptedBaselines() != 2 {
+		t.Errorf("GetSQLPlanBaselines got %v, want the capture enabled, 2 baselines captured since and 2 accepted", resp)
+	}
+}
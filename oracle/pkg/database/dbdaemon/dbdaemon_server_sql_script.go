@@ -0,0 +1,149 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// maxSQLScriptBytes bounds the size of the scripts downloaded from GCS.
+const maxSQLScriptBytes = 10 << 20
+
+var (
+	// plsqlBlockRe matches the first line of a statement terminated by a
+	// line with a single slash rather than by a semicolon.
+	plsqlBlockRe = regexp.MustCompile(`(?i)^(declare|begin|create\s+(or\s+replace\s+)?((non)?editionable\s+)?(procedure|function|package|trigger|type|library))\b`)
+	// sqlPlusCommandRe matches the SQL*Plus commands skipped in scripts.
+	sqlPlusCommandRe = regexp.MustCompile(`(?i)^(rem|remark|prompt|set|spool|whenever|exit|quit)(\s|$)`)
+)
+
+// sqlScriptStatements splits a SQL*Plus script into its statements. SQL
+// statements end with a semicolon, PL/SQL blocks with a line holding a single
+// slash. Comment lines and the SQL*Plus commands which only change the
+// client settings are skipped.
+func sqlScriptStatements(script string) ([]string, error) {
+	var stmts, lines []string
+	plsql := false
+	flush := func() {
+		if stmt := strings.TrimSpace(strings.Join(lines, "\n")); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		lines, plsql = nil, false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(make([]byte, 64*1024), maxSQLScriptBytes)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if len(lines) == 0 {
+			switch {
+			case trimmed == "", trimmed == "/", strings.HasPrefix(trimmed, "--"), sqlPlusCommandRe.MatchString(trimmed):
+				continue
+			case strings.HasPrefix(trimmed, "@"):
+				return nil, fmt.Errorf("line %d: nested scripts aren't supported: %s", n, trimmed)
+			}
+			plsql = plsqlBlockRe.MatchString(trimmed)
+		}
+		if trimmed == "/" {
+			flush()
+			continue
+		}
+		if !plsql && strings.HasSuffix(trimmed, ";") {
+			lines = append(lines, strings.TrimSuffix(strings.TrimRight(line, " \t"), ";"))
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the script: %v", err)
+	}
+	if plsql && len(lines) > 0 {
+		return nil, fmt.Errorf("PL/SQL block isn't terminated by a slash: %s", strings.TrimSpace(lines[0]))
+	}
+	flush()
+	return stmts, nil
+}
+
+// downloadSQLScript downloads a script from GCS.
+func (s *Server) downloadSQLScript(ctx context.Context, gcsPath string) (string, error) {
+	r, err := s.gcsUtil.Download(ctx, gcsPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to download the script %s: %v", gcsPath, err)
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxSQLScriptBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download the script %s: %v", gcsPath, err)
+	}
+	if len(content) > maxSQLScriptBytes {
+		return "", fmt.Errorf("the script %s is larger than %d bytes", gcsPath, maxSQLScriptBytes)
+	}
+	return string(content), nil
+}
+
+// RunSQLScriptFromGCS runs the statements of a SQL script downloaded from
+// GCS, or given inline, in the CDB root or in a PDB.
+func (s *Server) RunSQLScriptFromGCS(ctx context.Context, req *dbdpb.RunSQLScriptFromGCSRequest) (*dbdpb.RunSQLScriptFromGCSResponse, error) {
+	klog.InfoS("dbdaemon/RunSQLScriptFromGCS", "gcsPath", req.GetGcsPath(), "pdbName", req.GetPdbName())
+	var setContainer []string
+	if req.GetPdbName() != "" {
+		if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/RunSQLScriptFromGCS: invalid PDB name %q: %v", req.GetPdbName(), err)
+		}
+		setContainer = []string{sqlq.QuerySetSessionContainer(req.GetPdbName())}
+	}
+
+	script := req.GetScript()
+	if req.GetGcsPath() != "" {
+		var err error
+		if script, err = s.downloadSQLScript(ctx, req.GetGcsPath()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/RunSQLScriptFromGCS: %v", err)
+		}
+	}
+	stmts, err := sqlScriptStatements(script)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLScriptFromGCS: %v", err)
+	}
+	if len(stmts) == 0 {
+		return nil, fmt.Errorf("dbdaemon/RunSQLScriptFromGCS: the script has no statement")
+	}
+
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: append(setContainer, stmts...)}, false)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLScriptFromGCS: failed to run the script: %v", err)
+	}
+	return &dbdpb.RunSQLScriptFromGCSResponse{
+		Statements: int32(len(stmts)),
+		Output:     resp.GetMsg(),
+	}, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestSQLScriptStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name: "SQL statements",
+			script: `-- grants for the application
+set echo on
+prompt granting
+grant select on app.orders to reporting;
+create table app.audit (
+  id number,
+  at timestamp
+);
+exit
+`,
+			want: []string{
+				"grant select on app.orders to reporting",
+				"create table app.audit (\n  id number,\n  at timestamp\n)",
+			},
+		},
+		{
+			name: "PL/SQL blocks",
+			script: `begin
+  dbms_stats.gather_schema_stats('APP');
+end;
+/
+create or replace procedure app.touch as
+begin
+  null;
+end;
+/
+update app.settings set value = 'on'
+/
+`,
+			want: []string{
+				"begin\n  dbms_stats.gather_schema_stats('APP');\nend;",
+				"create or replace procedure app.touch as\nbegin\n  null;\nend;",
+				"update app.settings set value = 'on'",
+			},
+		},
+		{
+			name:   "last statement without terminator",
+			script: "alter system checkpoint",
+			want:   []string{"alter system checkpoint"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sqlScriptStatements(tc.script)
+			if err != nil {
+				t.Fatalf("sqlScriptStatements failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("sqlScriptStatements got unexpected statements (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	for _, script := range []string{"begin\n  null;\nend;\n", "@other.sql\n"} {
+		if _, err := sqlScriptStatements(script); err == nil {
+			t.Errorf("sqlScriptStatements(%q) succeeded, want error", script)
+		}
+	}
+}
+
+func TestRunSQLScriptFromGCS(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.gcsUtil = &fakeGCSUtil{uploads: map[string]string{
+		"gs://bucket/hooks/post_restore.sql": "alter user app account unlock;\n",
+	}}
+	var gotStmts []string
+	useFakeSQLDB(s).runSQLFunc = func(sqls []string) ([]string, error) {
+		gotStmts = append(gotStmts, sqls...)
+		return nil, nil
+	}
+
+	resp, err := s.RunSQLScriptFromGCS(ctx, &dbdpb.RunSQLScriptFromGCSRequest{
+		Source:  &dbdpb.RunSQLScriptFromGCSRequest_GcsPath{GcsPath: "gs://bucket/hooks/post_restore.sql"},
+		PdbName: "PDB1",
+	})
+	if err != nil {
+		t.Fatalf("RunSQLScriptFromGCS failed: %v", err)
+	}
+	want := []string{`alter session set container="PDB1"`, "alter user app account unlock"}
+	if diff := cmp.Diff(want, gotStmts); diff != "" {
+		t.Errorf("RunSQLScriptFromGCS ran unexpected statements (-want +got):\n%v", diff)
+	}
+	if resp.GetStatements() != 1 {
+		t.Errorf("RunSQLScriptFromGCS got %d statements, want 1", resp.GetStatements())
+	}
+
+	gotStmts = nil
+	if _, err := s.RunSQLScriptFromGCS(ctx, &dbdpb.RunSQLScriptFromGCSRequest{
+		Source: &dbdpb.RunSQLScriptFromGCSRequest_Script{Script: "alter system checkpoint;"},
+	}); err != nil {
+		t.Fatalf("RunSQLScriptFromGCS of an inline script failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"alter system checkpoint"}, gotStmts); diff != "" {
+		t.Errorf("RunSQLScriptFromGCS ran unexpected statements (-want +got):\n%v", diff)
+	}
+
+	if _, err := s.RunSQLScriptFromGCS(ctx, &dbdpb.RunSQLScriptFromGCSRequest{
+		Source: &dbdpb.RunSQLScriptFromGCSRequest_GcsPath{GcsPath: "gs://bucket/hooks/missing.sql"},
+	}); err == nil {
+		t.Errorf("RunSQLScriptFromGCS of a missing script succeeded, want error")
+	}
+}
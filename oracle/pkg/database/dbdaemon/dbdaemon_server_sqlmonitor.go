@@ -0,0 +1,271 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	sqlMonitorReportSQL = "select dbms_sql_monitor.report_sql_monitor(sql_id => '%s', sql_exec_id => %s, type => '%s', report_level => 'ALL') as report from dual"
+
+	// longMonitoredSQL lists the monitored executions which ran for at
+	// least a number of seconds and started in the last seconds, longest
+	// first. The parallel execution servers are left out, the report of
+	// their query coordinator covers them.
+	longMonitoredSQL = "select sql_id, sql_exec_id, round(elapsed_time / 1000000) as elapsed_seconds from v$sql_monitor " +
+		"where px_qcsid is null and elapsed_time >= %d * 1000000 and sql_exec_start >= sysdate - %d / 86400 " +
+		"order by elapsed_time desc fetch first %d rows only"
+
+	// maxCapturedReports bounds the reports of a CaptureSQLMonitorReports
+	// call, the longest executions are captured first.
+	maxCapturedReports = 20
+
+	defaultCaptureWindow = time.Hour
+
+	contentTypeHTML = "text/html"
+)
+
+// sqlMonitorFormats maps the report formats to the report type and the
+// extension and content type of the uploaded report.
+var sqlMonitorFormats = map[dbdpb.GetSQLMonitorReportRequest_Format]struct {
+	reportType, extension, contentType string
+}{
+	dbdpb.GetSQLMonitorReportRequest_TEXT: {"TEXT", "txt", contentTypePlainText},
+	dbdpb.GetSQLMonitorReportRequest_HTML: {"HTML", "html", contentTypeHTML},
+}
+
+// sqlMonitorReportQuery returns the query generating the report of an
+// execution of a SQL id, the last one if execID is 0.
+func sqlMonitorReportQuery(sqlID string, execID int64, format dbdpb.GetSQLMonitorReportRequest_Format) (string, error) {
+	if !sqlIDPattern.MatchString(sqlID) {
+		return "", fmt.Errorf("invalid SQL id %q", sqlID)
+	}
+	if execID < 0 {
+		return "", fmt.Errorf("invalid SQL exec id %d", execID)
+	}
+	f, ok := sqlMonitorFormats[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported report format %v", format)
+	}
+	exec := "null"
+	if execID > 0 {
+		exec = strconv.FormatInt(execID, 10)
+	}
+	return fmt.Sprintf(sqlMonitorReportSQL, sqlID, exec, f.reportType), nil
+}
+
+// sqlMonitorReportPath names the object of a captured report under prefix.
+func sqlMonitorReportPath(prefix, sqlID string, execID int64, format dbdpb.GetSQLMonitorReportRequest_Format) string {
+	return fmt.Sprintf("%s/sqlmon_%s_%d.%s", strings.TrimSuffix(prefix, "/"), sqlID, execID, sqlMonitorFormats[format].extension)
+}
+
+// parseMonitoredExecutions converts longMonitoredSQL rows into reports yet
+// to be uploaded.
+func parseMonitoredExecutions(rows []string) ([]*dbdpb.CaptureSQLMonitorReportsResponse_Report, error) {
+	var reports []*dbdpb.CaptureSQLMonitorReportsResponse_Report
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse monitored execution row %q: %v", msg, err)
+		}
+		execID, err := rowInt(row, "SQL_EXEC_ID")
+		if err != nil {
+			return nil, fmt.Errorf("%v in monitored execution row %q", err, msg)
+		}
+		elapsed, err := rowInt(row, "ELAPSED_SECONDS")
+		if err != nil {
+			return nil, fmt.Errorf("%v in monitored execution row %q", err, msg)
+		}
+		reports = append(reports, &dbdpb.CaptureSQLMonitorReportsResponse_Report{
+			SqlId:          row["SQL_ID"],
+			SqlExecId:      execID,
+			ElapsedSeconds: elapsed,
+		})
+	}
+	return reports, nil
+}
+
+// requireTuningPack fails unless the Tuning Pack is licensed and enabled.
+func (s *Server) requireTuningPack(ctx context.Context) error {
+	if err := s.requireLicensedOption(dbdpb.GetFeatureUsageRequest_TUNING_PACK); err != nil {
+		return err
+	}
+	packs, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{managementPacksSQL}}, true)
+	if err != nil {
+		return fmt.Errorf("failed to query the management packs: %v", err)
+	}
+	if !managementPackEnabled(packs.GetMsg(), "TUNING") {
+		return fmt.Errorf("the Tuning Pack isn't enabled, control_management_pack_access has to be DIAGNOSTIC+TUNING")
+	}
+	return nil
+}
+
+// sqlMonitorReport generates the report of the query in a PDB.
+func (s *Server) sqlMonitorReport(ctx context.Context, pdb, query string) (string, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(pdb), query},
+	}, true)
+	if err != nil {
+		return "", err
+	}
+	rows := resp.GetMsg()
+	if len(rows) != 1 {
+		return "", fmt.Errorf("got %d report rows, want 1", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return "", fmt.Errorf("failed to parse the report: %v", err)
+	}
+	if strings.TrimSpace(row["REPORT"]) == "" {
+		return "", fmt.Errorf("the execution isn't monitored")
+	}
+	return row["REPORT"], nil
+}
+
+// uploadSQLMonitorReport uploads a report to a GCS object.
+func (s *Server) uploadSQLMonitorReport(ctx context.Context, gcsPath, report string, format dbdpb.GetSQLMonitorReportRequest_Format) error {
+	f, err := ioutil.TempFile(scratchDir(), "sqlmon")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(report); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.gcsUtil.UploadFile(ctx, gcsPath, f.Name(), sqlMonitorFormats[format].contentType)
+}
+
+// GetSQLMonitorReport generates the SQL Monitor report of an execution.
+func (s *Server) GetSQLMonitorReport(ctx context.Context, req *dbdpb.GetSQLMonitorReportRequest) (*dbdpb.GetSQLMonitorReportResponse, error) {
+	klog.InfoS("dbdaemon/GetSQLMonitorReport", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: invalid PDB name %q", req.GetPdbName())
+	}
+	query, err := sqlMonitorReportQuery(req.GetSqlId(), req.GetSqlExecId(), req.GetFormat())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: %v", err)
+	}
+	if req.GetGcsPath() != "" {
+		if _, _, err := s.gcsUtil.SplitURI(req.GetGcsPath()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: invalid GCS path: %v", err)
+		}
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if err := s.requireTuningPack(ctx); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: %v", err)
+	}
+
+	report, err := s.sqlMonitorReport(ctx, req.GetPdbName(), query)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: failed to report SQL id %s: %v", req.GetSqlId(), err)
+	}
+	if req.GetGcsPath() == "" {
+		return &dbdpb.GetSQLMonitorReportResponse{Report: report}, nil
+	}
+	if err := s.uploadSQLMonitorReport(ctx, req.GetGcsPath(), report, req.GetFormat()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSQLMonitorReport: failed to upload the report to %s: %v", req.GetGcsPath(), err)
+	}
+	klog.InfoS("dbdaemon/GetSQLMonitorReport: uploaded the report", "sqlID", req.GetSqlId(), "gcsPath", req.GetGcsPath())
+	return &dbdpb.GetSQLMonitorReportResponse{GcsPath: req.GetGcsPath()}, nil
+}
+
+// CaptureSQLMonitorReports uploads the reports of the recent long running
+// executions, a report which fails to be generated or uploaded is skipped.
+func (s *Server) CaptureSQLMonitorReports(ctx context.Context, req *dbdpb.CaptureSQLMonitorReportsRequest) (*dbdpb.CaptureSQLMonitorReportsResponse, error) {
+	klog.InfoS("dbdaemon/CaptureSQLMonitorReports", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: invalid PDB name %q", req.GetPdbName())
+	}
+	if req.GetMinElapsedSeconds() <= 0 {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: min_elapsed_seconds must be positive, got %d", req.GetMinElapsedSeconds())
+	}
+	if _, ok := sqlMonitorFormats[req.GetFormat()]; !ok {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: unsupported report format %v", req.GetFormat())
+	}
+	if _, _, err := s.gcsUtil.SplitURI(req.GetGcsPrefix()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: invalid GCS prefix: %v", err)
+	}
+	window := defaultCaptureWindow
+	if req.GetSince() != nil {
+		window = time.Since(req.GetSince().AsTime())
+	}
+	if window <= 0 {
+		return &dbdpb.CaptureSQLMonitorReportsResponse{}, nil
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if err := s.requireTuningPack(ctx); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: %v", err)
+	}
+
+	execResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{
+			sqlq.QuerySetSessionContainer(req.GetPdbName()),
+			fmt.Sprintf(longMonitoredSQL, req.GetMinElapsedSeconds(), int64(window.Seconds()+1), maxCapturedReports),
+		},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: failed to query the monitored executions: %v", err)
+	}
+	execs, err := parseMonitoredExecutions(execResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CaptureSQLMonitorReports: %v", err)
+	}
+	resp := &dbdpb.CaptureSQLMonitorReportsResponse{}
+	for _, e := range execs {
+		query, err := sqlMonitorReportQuery(e.GetSqlId(), e.GetSqlExecId(), req.GetFormat())
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon/CaptureSQLMonitorReports: skipping execution", "sqlID", e.GetSqlId(), "sqlExecID", e.GetSqlExecId())
+			continue
+		}
+		report, err := s.sqlMonitorReport(ctx, req.GetPdbName(), query)
+		if err != nil {
+			klog.ErrorS(err, "dbdaemon/CaptureSQLMonitorReports: failed to report execution", "sqlID", e.GetSqlId(), "sqlExecID", e.GetSqlExecId())
+			continue
+		}
+		path := sqlMonitorReportPath(req.GetGcsPrefix(), e.GetSqlId(), e.GetSqlExecId(), req.GetFormat())
+		if err := s.uploadSQLMonitorReport(ctx, path, report, req.GetFormat()); err != nil {
+			klog.ErrorS(err, "dbdaemon/CaptureSQLMonitorReports: failed to upload report", "gcsPath", path)
+			continue
+		}
+		e.GcsPath = path
+		resp.Reports = append(resp.Reports, e)
+	}
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/util"
+)
+
+// fakeGCSUtil records the content and content type of the uploaded files
+// and serves them back.
+type fakeGCSUtil struct {
+	util.GCSUtilImpl
+	uploads      map[string]string
+	contentTypes map[string]string
+}
+
+func (f *fakeGCSUtil) Download(ctx context.Context, gcsPath string) (io.ReadCloser, error) {
+	content, ok := f.uploads[gcsPath]
+	if !ok {
+		return nil, fmt.Errorf("failed to read URL %s: %w", gcsPath, storage.ErrObjectNotExist)
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+func (f *fakeGCSUtil) Delete(ctx context.Context, gcsPath string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (f *fakeGCSUtil) UploadFile(ctx context.Context, gcsPath, filepath, contentType string) error {
+	content, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+	f.uploads[gcsPath] = string(content)
+	f.contentTypes[gcsPath] = contentType
+	return nil
+}
+
+// newSQLMonitorServer returns a server with the Tuning Pack licensed and
+// enabled which reports the SQL Monitor report queries as their text.
+func newSQLMonitorServer(ctx context.Context, t *testing.T, execRows []string) (*Server, *fakeGCSUtil) {
+	t.Helper()
+	useFakeOracleDatabase(t)
+	licenseFile = filepath.Join(t.TempDir(), "license.json")
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	s.license = &licenseState{}
+	if _, err := s.SetLicense(ctx, &dbdpb.SetLicenseRequest{
+		Edition:         "Enterprise",
+		LicensedOptions: []dbdpb.GetFeatureUsageRequest_Option{dbdpb.GetFeatureUsageRequest_TUNING_PACK},
+	}); err != nil {
+		t.Fatalf("SetLicense failed: %v", err)
+	}
+	gcs := &fakeGCSUtil{uploads: make(map[string]string), contentTypes: make(map[string]string)}
+	s.gcsUtil = gcs
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		query := sqls[len(sqls)-1]
+		switch {
+		case query == managementPacksSQL:
+			return []string{`{"VALUE":"DIAGNOSTIC+TUNING"}`}, nil
+		case strings.HasPrefix(query, "select sql_id, sql_exec_id"):
+			return execRows, nil
+		case strings.Contains(query, "dbms_sql_monitor.report_sql_monitor"):
+			if strings.Contains(query, "16777218") {
+				return nil, fmt.Errorf("ORA-13617: aged out")
+			}
+			return []string{fmt.Sprintf(`{"REPORT":%q}`, "report of "+query)}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	return s, gcs
+}
+
+func TestSQLMonitorReportQuery(t *testing.T) {
+	got, err := sqlMonitorReportQuery("7b2twsn8vgfsc", 0, dbdpb.GetSQLMonitorReportRequest_HTML)
+	if err != nil {
+		t.Fatalf("sqlMonitorReportQuery failed: %v", err)
+	}
+	want := "select dbms_sql_monitor.report_sql_monitor(sql_id => '7b2twsn8vgfsc', sql_exec_id => null, type => 'HTML', report_level => 'ALL') as report from dual"
+	if got != want {
+		t.Errorf("sqlMonitorReportQuery got %q, want %q", got, want)
+	}
+	if got, _ := sqlMonitorReportQuery("7b2twsn8vgfsc", 16777216, dbdpb.GetSQLMonitorReportRequest_TEXT); !strings.Contains(got, "sql_exec_id => 16777216, type => 'TEXT'") {
+		t.Errorf("sqlMonitorReportQuery got %q, want the exec id and the TEXT type", got)
+	}
+	for _, id := range []string{"", "7b2twsn8vgfs'", "select"} {
+		if _, err := sqlMonitorReportQuery(id, 0, dbdpb.GetSQLMonitorReportRequest_TEXT); err == nil {
+			t.Errorf("sqlMonitorReportQuery(%q) succeeded, want error", id)
+		}
+	}
+}
+
+func TestGetSQLMonitorReport(t *testing.T) {
+	ctx := context.Background()
+	s, gcs := newSQLMonitorServer(ctx, t, nil)
+	wantReport := "report of select dbms_sql_monitor.report_sql_monitor(sql_id => '7b2twsn8vgfsc', sql_exec_id => 16777216, type => 'HTML', report_level => 'ALL') as report from dual"
+
+	resp, err := s.GetSQLMonitorReport(ctx, &dbdpb.GetSQLMonitorReportRequest{
+		PdbName: "pdb1", SqlId: "7b2twsn8vgfsc", SqlExecId: 16777216, Format: dbdpb.GetSQLMonitorReportRequest_HTML,
+	})
+	if err != nil {
+		t.Fatalf("GetSQLMonitorReport failed: %v", err)
+	}
+	if resp.GetReport() != wantReport {
+		t.Errorf("GetSQLMonitorReport got report %q, want %q", resp.GetReport(), wantReport)
+	}
+
+	const path = "gs://bucket/reports/slow.html"
+	resp, err = s.GetSQLMonitorReport(ctx, &dbdpb.GetSQLMonitorReportRequest{
+		PdbName: "pdb1", SqlId: "7b2twsn8vgfsc", SqlExecId: 16777216, Format: dbdpb.GetSQLMonitorReportRequest_HTML, GcsPath: path,
+	})
+	if err != nil {
+		t.Fatalf("GetSQLMonitorReport with a GCS path failed: %v", err)
+	}
+	if resp.GetGcsPath() != path || resp.GetReport() != "" {
+		t.Errorf("GetSQLMonitorReport got %v, want only the GCS path %q", resp, path)
+	}
+	if gcs.uploads[path] != wantReport || gcs.contentTypes[path] != contentTypeHTML {
+		t.Errorf("GetSQLMonitorReport uploaded %q as %q, want %q as %q", gcs.uploads[path], gcs.contentTypes[path], wantReport, contentTypeHTML)
+	}
+
+	if _, err := s.GetSQLMonitorReport(ctx, &dbdpb.GetSQLMonitorReportRequest{PdbName: "pdb1", SqlId: "7b2twsn8vgfsc", GcsPath: "/tmp/report"}); err == nil {
+		t.Errorf("GetSQLMonitorReport with an invalid GCS path succeeded, want error")
+	}
+}
+
+func TestCaptureSQLMonitorReports(t *testing.T) {
+	ctx := context.Background()
+	s, gcs := newSQLMonitorServer(ctx, t, []string{
+		`{"SQL_ID":"7b2twsn8vgfsc","SQL_EXEC_ID":"16777216","ELAPSED_SECONDS":"600"}`,
+		`{"SQL_ID":"7b2twsn8vgfsc","SQL_EXEC_ID":"16777218","ELAPSED_SECONDS":"300"}`,
+		`{"SQL_ID":"0w2qpuc6u2zsp","SQL_EXEC_ID":"16777217","ELAPSED_SECONDS":"90"}`,
+	})
+
+	resp, err := s.CaptureSQLMonitorReports(ctx, &dbdpb.CaptureSQLMonitorReportsRequest{
+		PdbName: "pdb1", MinElapsedSeconds: 60, GcsPrefix: "gs://bucket/sqlmon/",
+	})
+	if err != nil {
+		t.Fatalf("CaptureSQLMonitorReports failed: %v", err)
+	}
+	// The second execution aged out of v$sql_monitor, it's skipped.
+	want := &dbdpb.CaptureSQLMonitorReportsResponse{
+		Reports: []*dbdpb.CaptureSQLMonitorReportsResponse_Report{
+			{SqlId: "7b2twsn8vgfsc", SqlExecId: 16777216, ElapsedSeconds: 600, GcsPath: "gs://bucket/sqlmon/sqlmon_7b2twsn8vgfsc_16777216.txt"},
+			{SqlId: "0w2qpuc6u2zsp", SqlExecId: 16777217, ElapsedSeconds: 90, GcsPath: "gs://bucket/sqlmon/sqlmon_0w2qpuc6u2zsp_16777217.txt"},
+		},
+	}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("CaptureSQLMonitorReports got unexpected response (-want +got):\n%v", diff)
+	}
+	for _, r := range want.GetReports() {
+		if !strings.Contains(gcs.uploads[r.GetGcsPath()], fmt.Sprintf("sql_id => '%s', sql_exec_id => %d, type => 'TEXT'", r.GetSqlId(), r.GetSqlExecId())) {
+			t.Errorf("CaptureSQLMonitorReports uploaded %q to %s, want the report of its execution", gcs.uploads[r.GetGcsPath()], r.GetGcsPath())
+		}
+	}
+	if len(gcs.uploads) != 2 {
+		t.Errorf("CaptureSQLMonitorReports uploaded %d reports, want 2", len(gcs.uploads))
+	}
+
+	if _, err := s.CaptureSQLMonitorReports(ctx, &dbdpb.CaptureSQLMonitorReportsRequest{PdbName: "pdb1", GcsPrefix: "gs://bucket/sqlmon"}); err == nil {
+		t.Errorf("CaptureSQLMonitorReports without a threshold succeeded, want error")
+	}
+}
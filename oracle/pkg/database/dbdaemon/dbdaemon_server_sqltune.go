@@ -0,0 +1,226 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// managementPacksSQL reports the management packs enabled, the SQL
+	// Tuning Advisor requires the Tuning Pack and AWR the Diagnostics Pack.
+	managementPacksSQL = "select value from v$parameter where name='control_management_pack_access'"
+
+	createTuningTaskCmd = "declare t varchar2(128); begin " +
+		"t := dbms_sqltune.create_tuning_task(sql_id => '%s', time_limit => %d, task_name => '%s'); " +
+		"dbms_sqltune.execute_tuning_task(task_name => '%s'); end;"
+
+	dropTuningTaskCmd = "begin dbms_sqltune.drop_tuning_task(task_name => '%s'); end;"
+
+	acceptSQLProfileCmd = "declare n varchar2(128); begin " +
+		"n := dbms_sqltune.accept_sql_profile(task_name => '%s', name => '%s', replace => true); end;"
+
+	tuningReportSQL = "select dbms_sqltune.report_tuning_task('%s') report from dual"
+
+	// tuningRecommendationsSQL lists the recommendations of a tuning task
+	// with one row per action. The advisor reports the benefit in
+	// hundredths of a percent.
+	tuningRecommendationsSQL = "select r.rec_id, r.type, r.benefit, nvl(f.message, ' ') finding, nvl(a.message, ' ') action " +
+		"from dba_advisor_recommendations r " +
+		"join dba_advisor_findings f on f.task_name=r.task_name and f.finding_id=r.finding_id " +
+		"join dba_advisor_actions a on a.task_name=r.task_name and a.rec_id=r.rec_id " +
+		"where r.task_name='%s' order by r.rec_id, a.action_id"
+
+	defaultTuningTimeLimit = 60 * time.Second
+	maxTuningTimeLimit     = 30 * time.Minute
+
+	sqlProfileRecommendation = "SQL PROFILE"
+)
+
+var sqlIDPattern = regexp.MustCompile(`^[0-9a-z]{13}$`)
+
+// tuningTaskName names the tuning task of a SQL id, the time makes the name
+// unique across concurrent and leftover tasks.
+func tuningTaskName(sqlID string, now time.Time) string {
+	return strings.ToUpper(fmt.Sprintf("ELCARRO_TUNE_%s_%s", sqlID, now.Format("20060102150405")))
+}
+
+// sqlProfileName names the SQL profile accepted for a SQL id.
+func sqlProfileName(sqlID string) string {
+	return strings.ToUpper("ELCARRO_PROFILE_" + sqlID)
+}
+
+// tuningTaskStatements returns the statements creating and executing the
+// tuning task of the request in its PDB.
+func tuningTaskStatements(req *dbdpb.RunSQLTuningAdvisorRequest, taskName string) ([]string, error) {
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("invalid PDB name %q", req.GetPdbName())
+	}
+	if !sqlIDPattern.MatchString(req.GetSqlId()) {
+		return nil, fmt.Errorf("invalid SQL id %q", req.GetSqlId())
+	}
+	timeLimit := defaultTuningTimeLimit
+	if req.GetTimeLimitSeconds() != 0 {
+		timeLimit = time.Duration(req.GetTimeLimitSeconds()) * time.Second
+	}
+	if timeLimit < 0 || timeLimit > maxTuningTimeLimit {
+		return nil, fmt.Errorf("time limit %v is out of range, it has to be at most %v", timeLimit, maxTuningTimeLimit)
+	}
+	return []string{
+		sqlq.QuerySetSessionContainer(req.GetPdbName()),
+		fmt.Sprintf(createTuningTaskCmd, req.GetSqlId(), int(timeLimit.Seconds()), taskName, taskName),
+	}, nil
+}
+
+// managementPackEnabled tells whether the managementPacksSQL rows report the
+// pack, DIAGNOSTIC or TUNING, as enabled.
+func managementPackEnabled(rows []string, pack string) bool {
+	if len(rows) != 1 {
+		return false
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return false
+	}
+	return strings.Contains(row["VALUE"], pack)
+}
+
+// parseTuningRecommendations converts tuningRecommendationsSQL rows into
+// recommendations, collecting the actions of each recommendation.
+func parseTuningRecommendations(rows []string) ([]*dbdpb.RunSQLTuningAdvisorResponse_Recommendation, error) {
+	var recs []*dbdpb.RunSQLTuningAdvisorResponse_Recommendation
+	lastID := ""
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse recommendation row %q: %v", msg, err)
+		}
+		action := strings.TrimSpace(row["ACTION"])
+		if row["REC_ID"] == lastID {
+			if action != "" {
+				recs[len(recs)-1].Actions = append(recs[len(recs)-1].Actions, action)
+			}
+			continue
+		}
+		benefit := 0.0
+		if row["BENEFIT"] != "" {
+			b, err := strconv.ParseFloat(row["BENEFIT"], 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse BENEFIT in recommendation row %q: %v", msg, err)
+			}
+			benefit = b / 100
+		}
+		rec := &dbdpb.RunSQLTuningAdvisorResponse_Recommendation{
+			Type:           row["TYPE"],
+			BenefitPercent: benefit,
+			Finding:        strings.TrimSpace(row["FINDING"]),
+		}
+		if action != "" {
+			rec.Actions = append(rec.Actions, action)
+		}
+		recs = append(recs, rec)
+		lastID = row["REC_ID"]
+	}
+	return recs, nil
+}
+
+// RunSQLTuningAdvisor creates and executes a tuning task for a SQL id,
+// reports its recommendations and optionally accepts the recommended SQL
+// profile. The tuning task is dropped once reported.
+func (s *Server) RunSQLTuningAdvisor(ctx context.Context, req *dbdpb.RunSQLTuningAdvisorRequest) (*dbdpb.RunSQLTuningAdvisorResponse, error) {
+	klog.InfoS("dbdaemon/RunSQLTuningAdvisor", "req", loggableRequest(req))
+	taskName := tuningTaskName(req.GetSqlId(), time.Now())
+	statements, err := tuningTaskStatements(req, taskName)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if err := s.requireTuningPack(ctx); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: %v", err)
+	}
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: failed to run the tuning task for SQL id %s: %v", req.GetSqlId(), err)
+	}
+	defer func() {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), fmt.Sprintf(dropTuningTaskCmd, taskName)},
+		}, false); err != nil {
+			klog.ErrorS(err, "dbdaemon/RunSQLTuningAdvisor: failed to drop the tuning task", "task", taskName)
+		}
+	}()
+
+	recResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), fmt.Sprintf(tuningRecommendationsSQL, taskName)},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: failed to query the recommendations: %v", err)
+	}
+	recs, err := parseTuningRecommendations(recResp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: %v", err)
+	}
+	resp := &dbdpb.RunSQLTuningAdvisorResponse{Recommendations: recs}
+
+	reportResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), fmt.Sprintf(tuningReportSQL, taskName)},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: failed to report the tuning task: %v", err)
+	}
+	if rows := reportResp.GetMsg(); len(rows) == 1 {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+			return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: failed to parse the report: %v", err)
+		}
+		resp.Report = row["REPORT"]
+	}
+
+	if !req.GetAcceptSqlProfile() {
+		return resp, nil
+	}
+	for _, rec := range recs {
+		if rec.GetType() != sqlProfileRecommendation {
+			continue
+		}
+		name := sqlProfileName(req.GetSqlId())
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), fmt.Sprintf(acceptSQLProfileCmd, taskName, name)},
+		}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/RunSQLTuningAdvisor: failed to accept the SQL profile: %v", err)
+		}
+		resp.AcceptedSqlProfile = name
+		break
+	}
+	if resp.GetAcceptedSqlProfile() == "" {
+		klog.InfoS("dbdaemon/RunSQLTuningAdvisor: no SQL profile recommended", "sqlID", req.GetSqlId())
+	}
+	return resp, nil
+}
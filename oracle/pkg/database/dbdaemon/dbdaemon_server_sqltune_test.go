@@ -0,0 +1,133 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestTuningTaskStatements(t *testing.T) {
+	taskName := tuningTaskName("g0wn1fptw2aby", time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC))
+	if want := "ELCARRO_TUNE_G0WN1FPTW2ABY_20220304050607"; taskName != want {
+		t.Errorf("tuningTaskName got %q, want %q", taskName, want)
+	}
+	tests := []struct {
+		name    string
+		req     *dbdpb.RunSQLTuningAdvisorRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "default time limit",
+			req:  &dbdpb.RunSQLTuningAdvisorRequest{PdbName: "pdb1", SqlId: "g0wn1fptw2aby"},
+			want: []string{
+				`alter session set container="PDB1"`,
+				"declare t varchar2(128); begin t := dbms_sqltune.create_tuning_task(sql_id => 'g0wn1fptw2aby', time_limit => 60, task_name => 'ELCARRO_TUNE_G0WN1FPTW2ABY_20220304050607'); " +
+					"dbms_sqltune.execute_tuning_task(task_name => 'ELCARRO_TUNE_G0WN1FPTW2ABY_20220304050607'); end;",
+			},
+		},
+		{
+			name: "time limit",
+			req:  &dbdpb.RunSQLTuningAdvisorRequest{PdbName: "pdb1", SqlId: "g0wn1fptw2aby", TimeLimitSeconds: 300},
+			want: []string{
+				`alter session set container="PDB1"`,
+				"declare t varchar2(128); begin t := dbms_sqltune.create_tuning_task(sql_id => 'g0wn1fptw2aby', time_limit => 300, task_name => 'ELCARRO_TUNE_G0WN1FPTW2ABY_20220304050607'); " +
+					"dbms_sqltune.execute_tuning_task(task_name => 'ELCARRO_TUNE_G0WN1FPTW2ABY_20220304050607'); end;",
+			},
+		},
+		{
+			name:    "time limit out of range",
+			req:     &dbdpb.RunSQLTuningAdvisorRequest{PdbName: "pdb1", SqlId: "g0wn1fptw2aby", TimeLimitSeconds: 3600},
+			wantErr: true,
+		},
+		{
+			name:    "invalid SQL id",
+			req:     &dbdpb.RunSQLTuningAdvisorRequest{PdbName: "pdb1", SqlId: "x'); drop user scott; --"},
+			wantErr: true,
+		},
+		{
+			name:    "missing PDB",
+			req:     &dbdpb.RunSQLTuningAdvisorRequest{SqlId: "g0wn1fptw2aby"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tuningTaskStatements(tc.req, taskName)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("tuningTaskStatements(%v) got error %v, want error: %v", tc.req, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("tuningTaskStatements(%v) got unexpected statements (-want +got):\n%v", tc.req, diff)
+			}
+		})
+	}
+}
+
+func TestParseTuningRecommendations(t *testing.T) {
+	rows := []string{
+		`{"REC_ID": "1", "TYPE": "SQL PROFILE", "BENEFIT": "9871", "FINDING": "A potentially better execution plan was found for this statement.", "ACTION": "Consider accepting the recommended SQL profile."}`,
+		`{"REC_ID": "2", "TYPE": "INDEX", "BENEFIT": "9902", "FINDING": "The execution plan of this statement can be improved by creating one or more indices.", "ACTION": "Consider running the Access Advisor to improve the physical schema design or creating the recommended index."}`,
+		`{"REC_ID": "2", "TYPE": "INDEX", "BENEFIT": "9902", "FINDING": "The execution plan of this statement can be improved by creating one or more indices.", "ACTION": " "}`,
+		`{"REC_ID": "3", "TYPE": "STATISTICS", "BENEFIT": "", "FINDING": " ", "ACTION": "Consider collecting optimizer statistics for this table."}`,
+	}
+	want := []*dbdpb.RunSQLTuningAdvisorResponse_Recommendation{
+		{
+			Type:           "SQL PROFILE",
+			BenefitPercent: 98.71,
+			Finding:        "A potentially better execution plan was found for this statement.",
+			Actions:        []string{"Consider accepting the recommended SQL profile."},
+		},
+		{
+			Type:           "INDEX",
+			BenefitPercent: 99.02,
+			Finding:        "The execution plan of this statement can be improved by creating one or more indices.",
+			Actions:        []string{"Consider running the Access Advisor to improve the physical schema design or creating the recommended index."},
+		},
+		{
+			Type:    "STATISTICS",
+			Actions: []string{"Consider collecting optimizer statistics for this table."},
+		},
+	}
+	got, err := parseTuningRecommendations(rows)
+	if err != nil {
+		t.Fatalf("parseTuningRecommendations failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseTuningRecommendations got unexpected recommendations (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseTuningRecommendations([]string{`{"REC_ID": "1", "TYPE": "INDEX", "BENEFIT": "high"}`}); err == nil {
+		t.Errorf("parseTuningRecommendations with an invalid benefit succeeded, want error")
+	}
+	for _, tc := range []struct {
+		rows []string
+		want bool
+	}{
+		{rows: []string{`{"VALUE": "DIAGNOSTIC+TUNING"}`}, want: true},
+		{rows: []string{`{"VALUE": "DIAGNOSTIC"}`}, want: false},
+		{rows: []string{`{"VALUE": "NONE"}`}, want: false},
+	} {
+		if got := managementPackEnabled(tc.rows, "TUNING"); got != tc.want {
+			t.Errorf("managementPackEnabled(%v, TUNING) = %v, want %v", tc.rows, got, tc.want)
+		}
+	}
+}
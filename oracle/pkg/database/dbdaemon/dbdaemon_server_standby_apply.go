@@ -0,0 +1,227 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	standbyDatabaseSQL = "select database_role, protection_mode, to_char(current_scn) as current_scn, " +
+		"(select count(*) from v$archive_gap) as gap_count, " +
+		"(select nvl(max(sequence#), 0) from v$archived_log where applied = 'YES') as last_applied_sequence " +
+		"from v$database"
+	dataGuardLagSQL = "select name, value from v$dataguard_stats where name in ('transport lag', 'apply lag')"
+	mrpStatusSQL    = "select status from v$managed_standby where process like 'MRP%'"
+	// standbyLogsSQL counts the standby redo log groups, the online redo
+	// log groups and the redo threads.
+	standbyLogsSQL = "select (select count(*) from v$standby_log) as standby_logs, " +
+		"(select count(*) from v$log) as online_logs, (select count(distinct thread#) from v$log) as threads from dual"
+)
+
+// dataGuardLagRe matches the INTERVAL DAY TO SECOND values of
+// v$dataguard_stats, e.g. +00 00:01:05.
+var dataGuardLagRe = regexp.MustCompile(`^\+?(\d+) (\d{2}):(\d{2}):(\d{2})(\.\d+)?$`)
+
+// parseDataGuardLag returns the seconds of a v$dataguard_stats lag, -1 if
+// the lag isn't known.
+func parseDataGuardLag(value string) (int64, error) {
+	if value == "" {
+		return -1, nil
+	}
+	m := dataGuardLagRe.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("unexpected lag %q", value)
+	}
+	var seconds int64
+	for i, unit := range []int64{86400, 3600, 60, 1} {
+		n, err := strconv.ParseInt(m[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected lag %q: %v", value, err)
+		}
+		seconds += n * unit
+	}
+	return seconds, nil
+}
+
+// parseStandbyApplyStatus returns the apply status of the rows of
+// standbyDatabaseSQL, dataGuardLagSQL and mrpStatusSQL.
+func parseStandbyApplyStatus(dbRows, lagRows, mrpRows []string) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+	if len(dbRows) != 1 {
+		return nil, fmt.Errorf("expected 1 database row, got %d", len(dbRows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(dbRows[0]), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse database row %q: %v", dbRows[0], err)
+	}
+	gaps, err := strconv.ParseInt(row["GAP_COUNT"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GAP_COUNT in database row %q: %v", dbRows[0], err)
+	}
+	sequence, err := strconv.ParseInt(row["LAST_APPLIED_SEQUENCE"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse LAST_APPLIED_SEQUENCE in database row %q: %v", dbRows[0], err)
+	}
+	resp := &dbdpb.GetStandbyApplyStatusResponse{
+		DatabaseRole:        row["DATABASE_ROLE"],
+		ProtectionMode:      row["PROTECTION_MODE"],
+		TransportLagSeconds: -1,
+		ApplyLagSeconds:     -1,
+		LastAppliedScn:      row["CURRENT_SCN"],
+		LastAppliedSequence: sequence,
+		GapCount:            gaps,
+	}
+
+	for _, msg := range lagRows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse Data Guard stats row %q: %v", msg, err)
+		}
+		lag, err := parseDataGuardLag(row["VALUE"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Data Guard stats row %q: %v", msg, err)
+		}
+		switch row["NAME"] {
+		case "transport lag":
+			resp.TransportLagSeconds = lag
+		case "apply lag":
+			resp.ApplyLagSeconds = lag
+		}
+	}
+
+	for _, msg := range mrpRows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse managed standby row %q: %v", msg, err)
+		}
+		resp.MrpRunning = true
+		resp.MrpStatus = row["STATUS"]
+	}
+	return resp, nil
+}
+
+// standbyApplyStatus queries the apply status of the database.
+func (s *Server) standbyApplyStatus(ctx context.Context) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+	var rows [3][]string
+	for i, query := range []string{standbyDatabaseSQL, dataGuardLagSQL, mrpStatusSQL} {
+		resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{query}}, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the apply status: %v", err)
+		}
+		rows[i] = resp.GetMsg()
+	}
+	return parseStandbyApplyStatus(rows[0], rows[1], rows[2])
+}
+
+// GetStandbyApplyStatus returns the role of the database and, on a standby,
+// the state of redo transport and apply.
+func (s *Server) GetStandbyApplyStatus(ctx context.Context, req *dbdpb.GetStandbyApplyStatusRequest) (*dbdpb.GetStandbyApplyStatusResponse, error) {
+	klog.InfoS("dbdaemon/GetStandbyApplyStatus", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	status, err := s.standbyApplyStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetStandbyApplyStatus: %v", err)
+	}
+	return status, nil
+}
+
+// standbySyncReasons returns why a standby with an apply status, standby
+// redo log groups, online redo log groups and redo threads can't switch over
+// without data loss. Oracle recommends a standby redo log group more than
+// the online redo log groups per thread.
+func standbySyncReasons(status *dbdpb.GetStandbyApplyStatusResponse, standbyLogs, onlineLogs, threads int64) []string {
+	var reasons []string
+	if status.GetDatabaseRole() != "PHYSICAL STANDBY" {
+		reasons = append(reasons, fmt.Sprintf("the database role is %s, want PHYSICAL STANDBY", status.GetDatabaseRole()))
+	}
+	if !status.GetMrpRunning() {
+		reasons = append(reasons, "redo apply (MRP) isn't running")
+	}
+	for _, lag := range []struct {
+		name    string
+		seconds int64
+	}{
+		{"transport", status.GetTransportLagSeconds()},
+		{"apply", status.GetApplyLagSeconds()},
+	} {
+		switch {
+		case lag.seconds < 0:
+			reasons = append(reasons, fmt.Sprintf("the %s lag is unknown", lag.name))
+		case lag.seconds > 0:
+			reasons = append(reasons, fmt.Sprintf("the %s lag is %ds", lag.name, lag.seconds))
+		}
+	}
+	if status.GetGapCount() > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d archived log gaps", status.GetGapCount()))
+	}
+	if want := onlineLogs + threads; standbyLogs == 0 {
+		reasons = append(reasons, "no standby redo logs are configured")
+	} else if standbyLogs < want {
+		reasons = append(reasons, fmt.Sprintf("%d standby redo log groups, want at least %d", standbyLogs, want))
+	}
+	return reasons
+}
+
+// CheckStandbySynchronized checks that the standby applies the redo of the
+// primary without lag or gap and has standby redo logs, so that a
+// switchover doesn't lose data.
+func (s *Server) CheckStandbySynchronized(ctx context.Context, req *dbdpb.CheckStandbySynchronizedRequest) (*dbdpb.CheckStandbySynchronizedResponse, error) {
+	klog.InfoS("dbdaemon/CheckStandbySynchronized", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	status, err := s.standbyApplyStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckStandbySynchronized: %v", err)
+	}
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{standbyLogsSQL}}, true)
+	if err != nil || len(resp.GetMsg()) != 1 {
+		return nil, fmt.Errorf("dbdaemon/CheckStandbySynchronized: failed to query the redo logs: %v", err)
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(resp.GetMsg()[0]), &row); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckStandbySynchronized: failed to parse redo logs row %q: %v", resp.GetMsg()[0], err)
+	}
+	var counts [3]int64
+	for i, col := range []string{"STANDBY_LOGS", "ONLINE_LOGS", "THREADS"} {
+		if counts[i], err = strconv.ParseInt(row[col], 10, 64); err != nil {
+			return nil, fmt.Errorf("dbdaemon/CheckStandbySynchronized: failed to parse %s in redo logs row %q: %v", col, resp.GetMsg()[0], err)
+		}
+	}
+
+	reasons := standbySyncReasons(status, counts[0], counts[1], counts[2])
+	klog.InfoS("dbdaemon/CheckStandbySynchronized: done", "synchronized", len(reasons) == 0, "reasons", reasons)
+	return &dbdpb.CheckStandbySynchronizedResponse{
+		Synchronized:     len(reasons) == 0,
+		Reasons:          reasons,
+		ApplyStatus:      status,
+		StandbyLogGroups: counts[0],
+		OnlineLogGroups:  counts[1],
+	}, nil
+}
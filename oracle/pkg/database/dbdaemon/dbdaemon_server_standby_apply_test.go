@@ -0,0 +1,281 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseDataGuardLag(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{value: "", want: -1},
+		{value: "+00 00:00:00", want: 0},
+		{value: "+00 00:01:05", want: 65},
+		{value: "+01 02:03:04", want: 93784},
+		{value: "+00 00:00:07.250", want: 7},
+	}
+	for _, tc := range tests {
+		got, err := parseDataGuardLag(tc.value)
+		if err != nil {
+			t.Errorf("parseDataGuardLag(%q) failed: %v", tc.value, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseDataGuardLag(%q) got %d, want %d", tc.value, got, tc.want)
+		}
+	}
+	if _, err := parseDataGuardLag("5 seconds"); err == nil {
+		t.Errorf("parseDataGuardLag of an unexpected value succeeded, want error")
+	}
+}
+
+func TestParseStandbyApplyStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbRow   string
+		lagRows []string
+		mrpRows []string
+		want    *dbdpb.GetStandbyApplyStatusResponse
+	}{
+		{
+			name:  "primary",
+			dbRow: `{"DATABASE_ROLE":"PRIMARY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","CURRENT_SCN":"2345678","GAP_COUNT":"0","LAST_APPLIED_SEQUENCE":"0"}`,
+			want: &dbdpb.GetStandbyApplyStatusResponse{
+				DatabaseRole:        "PRIMARY",
+				ProtectionMode:      "MAXIMUM PERFORMANCE",
+				TransportLagSeconds: -1,
+				ApplyLagSeconds:     -1,
+				LastAppliedScn:      "2345678",
+			},
+		},
+		{
+			name:  "applying standby",
+			dbRow: `{"DATABASE_ROLE":"PHYSICAL STANDBY","PROTECTION_MODE":"MAXIMUM AVAILABILITY","CURRENT_SCN":"2345600","GAP_COUNT":"0","LAST_APPLIED_SEQUENCE":"42"}`,
+			lagRows: []string{
+				`{"NAME":"transport lag","VALUE":"+00 00:00:02"}`,
+				`{"NAME":"apply lag","VALUE":"+00 00:01:05"}`,
+			},
+			mrpRows: []string{`{"STATUS":"APPLYING_LOG"}`},
+			want: &dbdpb.GetStandbyApplyStatusResponse{
+				DatabaseRole:        "PHYSICAL STANDBY",
+				ProtectionMode:      "MAXIMUM AVAILABILITY",
+				TransportLagSeconds: 2,
+				ApplyLagSeconds:     65,
+				MrpRunning:          true,
+				MrpStatus:           "APPLYING_LOG",
+				LastAppliedScn:      "2345600",
+				LastAppliedSequence: 42,
+			},
+		},
+		{
+			name:  "standby with a gap and no apply",
+			dbRow: `{"DATABASE_ROLE":"PHYSICAL STANDBY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","CURRENT_SCN":"2300000","GAP_COUNT":"2","LAST_APPLIED_SEQUENCE":"37"}`,
+			lagRows: []string{
+				`{"NAME":"transport lag","VALUE":"+00 01:00:00"}`,
+				`{"NAME":"apply lag","VALUE":""}`,
+			},
+			want: &dbdpb.GetStandbyApplyStatusResponse{
+				DatabaseRole:        "PHYSICAL STANDBY",
+				ProtectionMode:      "MAXIMUM PERFORMANCE",
+				TransportLagSeconds: 3600,
+				ApplyLagSeconds:     -1,
+				LastAppliedScn:      "2300000",
+				LastAppliedSequence: 37,
+				GapCount:            2,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseStandbyApplyStatus([]string{tc.dbRow}, tc.lagRows, tc.mrpRows)
+			if err != nil {
+				t.Fatalf("parseStandbyApplyStatus failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("parseStandbyApplyStatus got unexpected status (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	if _, err := parseStandbyApplyStatus(nil, nil, nil); err == nil {
+		t.Errorf("parseStandbyApplyStatus without a database row succeeded, want error")
+	}
+	if _, err := parseStandbyApplyStatus([]string{`{"GAP_COUNT":"x","LAST_APPLIED_SEQUENCE":"0"}`}, nil, nil); err == nil {
+		t.Errorf("parseStandbyApplyStatus with an invalid gap count succeeded, want error")
+	}
+}
+
+func TestGetStandbyApplyStatus(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case standbyDatabaseSQL:
+			return []string{`{"DATABASE_ROLE":"PHYSICAL STANDBY","PROTECTION_MODE":"MAXIMUM PERFORMANCE","CURRENT_SCN":"2345600","GAP_COUNT":"1","LAST_APPLIED_SEQUENCE":"42"}`}, nil
+		case dataGuardLagSQL:
+			return []string{`{"NAME":"apply lag","VALUE":"+00 00:00:30"}`}, nil
+		case mrpStatusSQL:
+			return []string{`{"STATUS":"WAIT_FOR_LOG"}`}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	got, err := s.GetStandbyApplyStatus(ctx, &dbdpb.GetStandbyApplyStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStandbyApplyStatus failed: %v", err)
+	}
+	want := &dbdpb.GetStandbyApplyStatusResponse{
+		DatabaseRole:        "PHYSICAL STANDBY",
+		ProtectionMode:      "MAXIMUM PERFORMANCE",
+		TransportLagSeconds: -1,
+		ApplyLagSeconds:     30,
+		MrpRunning:          true,
+		MrpStatus:           "WAIT_FOR_LOG",
+		LastAppliedScn:      "2345600",
+		LastAppliedSequence: 42,
+		GapCount:            1,
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetStandbyApplyStatus got unexpected status (-want +got):\n%v", diff)
+	}
+}
+
+func TestStandbySyncReasons(t *testing.T) {
+	synced := &dbdpb.GetStandbyApplyStatusResponse{
+		DatabaseRole:        "PHYSICAL STANDBY",
+		TransportLagSeconds: 0,
+		ApplyLagSeconds:     0,
+		MrpRunning:          true,
+	}
+	tests := []struct {
+		name        string
+		status      *dbdpb.GetStandbyApplyStatusResponse
+		standbyLogs int64
+		want        []string
+	}{
+		{
+			name:        "synchronized",
+			status:      synced,
+			standbyLogs: 4,
+		},
+		{
+			name: "apply lag",
+			status: &dbdpb.GetStandbyApplyStatusResponse{
+				DatabaseRole:        "PHYSICAL STANDBY",
+				TransportLagSeconds: 0,
+				ApplyLagSeconds:     65,
+				MrpRunning:          true,
+			},
+			standbyLogs: 4,
+			want:        []string{"the apply lag is 65s"},
+		},
+		{
+			name: "gap without apply",
+			status: &dbdpb.GetStandbyApplyStatusResponse{
+				DatabaseRole:        "PHYSICAL STANDBY",
+				TransportLagSeconds: 3600,
+				ApplyLagSeconds:     -1,
+				GapCount:            2,
+			},
+			standbyLogs: 4,
+			want: []string{
+				"redo apply (MRP) isn't running",
+				"the transport lag is 3600s",
+				"the apply lag is unknown",
+				"2 archived log gaps",
+			},
+		},
+		{
+			name:   "no standby redo logs",
+			status: synced,
+			want:   []string{"no standby redo logs are configured"},
+		},
+		{
+			name:        "too few standby redo logs",
+			status:      synced,
+			standbyLogs: 3,
+			want:        []string{"3 standby redo log groups, want at least 4"},
+		},
+		{
+			name:        "primary",
+			status:      &dbdpb.GetStandbyApplyStatusResponse{DatabaseRole: "PRIMARY", MrpRunning: true},
+			standbyLogs: 4,
+			want:        []string{"the database role is PRIMARY, want PHYSICAL STANDBY"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := standbySyncReasons(tc.status, tc.standbyLogs, 3, 1)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("standbySyncReasons got unexpected reasons (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCheckStandbySynchronized(t *testing.T) {
+	ctx := context.Background()
+	useFakeOracleDatabase(t)
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	applyLag := "+00 00:00:00"
+	useFakeSQLDB(s).runQueryFunc = func(sqls []string) ([]string, error) {
+		switch sqls[0] {
+		case standbyDatabaseSQL:
+			return []string{`{"DATABASE_ROLE":"PHYSICAL STANDBY","PROTECTION_MODE":"MAXIMUM AVAILABILITY","CURRENT_SCN":"2345600","GAP_COUNT":"0","LAST_APPLIED_SEQUENCE":"42"}`}, nil
+		case dataGuardLagSQL:
+			return []string{
+				`{"NAME":"transport lag","VALUE":"+00 00:00:00"}`,
+				fmt.Sprintf(`{"NAME":"apply lag","VALUE":%q}`, applyLag),
+			}, nil
+		case mrpStatusSQL:
+			return []string{`{"STATUS":"APPLYING_LOG"}`}, nil
+		case standbyLogsSQL:
+			return []string{`{"STANDBY_LOGS":"4","ONLINE_LOGS":"3","THREADS":"1"}`}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	got, err := s.CheckStandbySynchronized(ctx, &dbdpb.CheckStandbySynchronizedRequest{})
+	if err != nil {
+		t.Fatalf("CheckStandbySynchronized failed: %v", err)
+	}
+	if !got.GetSynchronized() || got.GetStandbyLogGroups() != 4 || got.GetOnlineLogGroups() != 3 {
+		t.Errorf("CheckStandbySynchronized got %v, want a synchronized standby with 4 standby and 3 online log groups", got)
+	}
+
+	applyLag = "+00 00:00:12"
+	got, err = s.CheckStandbySynchronized(ctx, &dbdpb.CheckStandbySynchronizedRequest{})
+	if err != nil {
+		t.Fatalf("CheckStandbySynchronized failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"the apply lag is 12s"}, got.GetReasons()); got.GetSynchronized() || diff != "" {
+		t.Errorf("CheckStandbySynchronized with an apply lag got synchronized %v and unexpected reasons (-want +got):\n%v", got.GetSynchronized(), diff)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// flushMonitoringCmd writes the DML monitoring info kept in memory to
+	// the dictionary, stale_stats is only up to date once it's flushed.
+	flushMonitoringCmd = "begin dbms_stats.flush_database_monitoring_info; end;"
+
+	// staleStatsSQL lists the tables and partitions with stale or missing
+	// statistics of the schemas not maintained by Oracle, leaving out the
+	// temporary tables and the tables whose statistics are locked. The
+	// last_analyzed date is in the UTC offset of the database host.
+	staleStatsSQL = "select s.owner, s.table_name, s.partition_name, s.object_type, s.num_rows, " +
+		"to_char(s.last_analyzed, 'YYYY-MM-DD\"T\"HH24:MI:SS') || nvl2(s.last_analyzed, to_char(systimestamp, 'TZH:TZM'), null) as last_analyzed " +
+		"from dba_tab_statistics s join dba_users u on u.username = s.owner " +
+		"join dba_tables t on t.owner = s.owner and t.table_name = s.table_name " +
+		"where u.oracle_maintained = 'N' and t.temporary = 'N' and s.stattype_locked is null " +
+		"and s.object_type in ('TABLE', 'PARTITION') and (s.stale_stats = 'YES' or s.last_analyzed is null)%s " +
+		"order by s.owner, s.table_name, s.partition_position nulls first"
+
+	gatherTableStatsCmd = "begin dbms_stats.gather_table_stats(ownname => '%s', tabname => '%s', cascade => true); end;"
+)
+
+// splitTable validates a table given as OWNER.TABLE and returns its owner
+// and name in upper case.
+func splitTable(table string) (string, string, error) {
+	if _, err := escapeTable(table); err != nil {
+		return "", "", err
+	}
+	parts := strings.Split(strings.ToUpper(table), ".")
+	return parts[0], parts[1], nil
+}
+
+// staleStatsQuery returns staleStatsSQL limited to the schemas, if any.
+func staleStatsQuery(schemas []string) (string, error) {
+	if len(schemas) == 0 {
+		return fmt.Sprintf(staleStatsSQL, ""), nil
+	}
+	var names []string
+	for _, s := range schemas {
+		if _, err := sqlq.ObjectName(s); err != nil || s == "" {
+			return "", fmt.Errorf("invalid schema %q", s)
+		}
+		names = append(names, "'"+sqlq.StringParam(strings.ToUpper(s))+"'")
+	}
+	return fmt.Sprintf(staleStatsSQL, fmt.Sprintf(" and s.owner in (%s)", strings.Join(names, ", "))), nil
+}
+
+// parseStaleStats converts staleStatsSQL rows into tables, the stale
+// partitions of a table are listed with it.
+func parseStaleStats(rows []string) ([]*dbdpb.GetStaleStatsResponse_Table, error) {
+	var tables []*dbdpb.GetStaleStatsResponse_Table
+	byName := make(map[string]*dbdpb.GetStaleStatsResponse_Table)
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse stale statistics row %q: %v", msg, err)
+		}
+		name := row["OWNER"] + "." + row["TABLE_NAME"]
+		t, ok := byName[name]
+		if !ok {
+			t = &dbdpb.GetStaleStatsResponse_Table{Table: name}
+			byName[name] = t
+			tables = append(tables, t)
+		}
+		switch row["OBJECT_TYPE"] {
+		case "TABLE":
+			numRows, err := rowInt(row, "NUM_ROWS")
+			if err != nil {
+				return nil, fmt.Errorf("%v in stale statistics row %q", err, msg)
+			}
+			lastAnalyzed, err := parseUsageTime(row["LAST_ANALYZED"])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse LAST_ANALYZED in stale statistics row %q: %v", msg, err)
+			}
+			t.Missing = lastAnalyzed == nil
+			t.NumRows = numRows
+			t.LastAnalyzed = lastAnalyzed
+		case "PARTITION":
+			t.StalePartitions = append(t.StalePartitions, row["PARTITION_NAME"])
+		default:
+			return nil, fmt.Errorf("unexpected object type in stale statistics row %q", msg)
+		}
+	}
+	return tables, nil
+}
+
+// gatherTargets returns the tables of a GatherStatsRequest to gather the
+// statistics of, as upper case OWNER.TABLE, given the tables with stale
+// statistics.
+func gatherTargets(req *dbdpb.GatherStatsRequest, stale []*dbdpb.GetStaleStatsResponse_Table) ([]string, error) {
+	var requested []string
+	seen := make(map[string]bool)
+	for _, table := range req.GetTables() {
+		owner, name, err := splitTable(table)
+		if err != nil {
+			return nil, err
+		}
+		if t := owner + "." + name; !seen[t] {
+			seen[t] = true
+			requested = append(requested, t)
+		}
+	}
+	if !req.GetStaleOnly() {
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("no tables to gather the statistics of")
+		}
+		return requested, nil
+	}
+	var targets []string
+	for _, t := range stale {
+		if len(requested) == 0 || seen[t.GetTable()] {
+			targets = append(targets, t.GetTable())
+		}
+	}
+	return targets, nil
+}
+
+// staleStats flushes the DML monitoring info and lists the tables of the
+// schemas of a PDB with stale or missing statistics.
+func (s *Server) staleStats(ctx context.Context, pdb string, schemas []string) ([]*dbdpb.GetStaleStatsResponse_Table, error) {
+	query, err := staleStatsQuery(schemas)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(pdb), flushMonitoringCmd},
+	}, false); err != nil {
+		return nil, fmt.Errorf("failed to flush the DML monitoring info: %v", err)
+	}
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(pdb), query},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the stale statistics: %v", err)
+	}
+	return parseStaleStats(resp.GetMsg())
+}
+
+// GetStaleStats returns the tables with stale or missing statistics.
+func (s *Server) GetStaleStats(ctx context.Context, req *dbdpb.GetStaleStatsRequest) (*dbdpb.GetStaleStatsResponse, error) {
+	klog.InfoS("dbdaemon/GetStaleStats", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/GetStaleStats: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	tables, err := s.staleStats(ctx, req.GetPdbName(), req.GetSchemas())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetStaleStats: %v", err)
+	}
+	return &dbdpb.GetStaleStatsResponse{Tables: tables}, nil
+}
+
+// GatherStats gathers the statistics of tables, only of the stale ones if
+// requested.
+func (s *Server) GatherStats(ctx context.Context, req *dbdpb.GatherStatsRequest) (*dbdpb.GatherStatsResponse, error) {
+	klog.InfoS("dbdaemon/GatherStats", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/GatherStats: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	var stale []*dbdpb.GetStaleStatsResponse_Table
+	if req.GetStaleOnly() {
+		var err error
+		if stale, err = s.staleStats(ctx, req.GetPdbName(), nil); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GatherStats: %v", err)
+		}
+	}
+	targets, err := gatherTargets(req, stale)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GatherStats: %v", err)
+	}
+	resp := &dbdpb.GatherStatsResponse{}
+	for _, t := range targets {
+		owner, name, _ := splitTable(t)
+		klog.InfoS("dbdaemon/GatherStats: gathering statistics", "pdb", req.GetPdbName(), "table", t)
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: []string{
+				sqlq.QuerySetSessionContainer(req.GetPdbName()),
+				fmt.Sprintf(gatherTableStatsCmd, sqlq.StringParam(owner), sqlq.StringParam(name)),
+			},
+		}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/GatherStats: failed to gather the statistics of %s: %v", t, err)
+		}
+		resp.GatheredTables = append(resp.GatheredTables, t)
+	}
+	return resp, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var staleStatsRows = []string{
+	`{"OWNER":"APP","TABLE_NAME":"EVENTS","PARTITION_NAME":"","OBJECT_TYPE":"TABLE","NUM_ROWS":"1200","LAST_ANALYZED":"2022-03-04T05:06:07+00:00"}`,
+	`{"OWNER":"APP","TABLE_NAME":"EVENTS","PARTITION_NAME":"P20220303","OBJECT_TYPE":"PARTITION","NUM_ROWS":"100","LAST_ANALYZED":"2022-03-04T05:06:07+00:00"}`,
+	`{"OWNER":"APP","TABLE_NAME":"EVENTS","PARTITION_NAME":"P20220304","OBJECT_TYPE":"PARTITION","NUM_ROWS":"","LAST_ANALYZED":""}`,
+	`{"OWNER":"APP","TABLE_NAME":"ORDERS","PARTITION_NAME":"","OBJECT_TYPE":"TABLE","NUM_ROWS":"","LAST_ANALYZED":""}`,
+	`{"OWNER":"SCOTT","TABLE_NAME":"EMP","PARTITION_NAME":"","OBJECT_TYPE":"TABLE","NUM_ROWS":"14","LAST_ANALYZED":"2022-01-02T03:04:05-08:00"}`,
+}
+
+func TestParseStaleStats(t *testing.T) {
+	got, err := parseStaleStats(staleStatsRows)
+	if err != nil {
+		t.Fatalf("parseStaleStats failed: %v", err)
+	}
+	want := []*dbdpb.GetStaleStatsResponse_Table{
+		{
+			Table:           "APP.EVENTS",
+			StalePartitions: []string{"P20220303", "P20220304"},
+			NumRows:         1200,
+			LastAnalyzed:    timestamppb.New(time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)),
+		},
+		{Table: "APP.ORDERS", Missing: true},
+		{
+			Table:        "SCOTT.EMP",
+			NumRows:      14,
+			LastAnalyzed: timestamppb.New(time.Date(2022, 1, 2, 11, 4, 5, 0, time.UTC)),
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseStaleStats got unexpected tables (-want +got):\n%v", diff)
+	}
+
+	if _, err := parseStaleStats([]string{`{"OWNER":"APP","TABLE_NAME":"T","OBJECT_TYPE":"TABLE","NUM_ROWS":"many"}`}); err == nil {
+		t.Errorf("parseStaleStats with invalid NUM_ROWS succeeded, want error")
+	}
+}
+
+func TestStaleStatsQuery(t *testing.T) {
+	got, err := staleStatsQuery([]string{"app", "scott"})
+	if err != nil {
+		t.Fatalf("staleStatsQuery failed: %v", err)
+	}
+	if want := " and s.owner in ('APP', 'SCOTT') order by"; !strings.Contains(got, want) {
+		t.Errorf("staleStatsQuery got %q, want it to contain %q", got, want)
+	}
+	if _, err := staleStatsQuery([]string{`app"`}); err == nil {
+		t.Errorf("staleStatsQuery with an invalid schema succeeded, want error")
+	}
+}
+
+func TestGatherTargets(t *testing.T) {
+	stale := []*dbdpb.GetStaleStatsResponse_Table{{Table: "APP.EVENTS"}, {Table: "APP.ORDERS"}, {Table: "SCOTT.EMP"}}
+	tests := []struct {
+		name    string
+		req     *dbdpb.GatherStatsRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "all stale tables",
+			req:  &dbdpb.GatherStatsRequest{StaleOnly: true},
+			want: []string{"APP.EVENTS", "APP.ORDERS", "SCOTT.EMP"},
+		},
+		{
+			name: "stale tables among requested ones",
+			req:  &dbdpb.GatherStatsRequest{Tables: []string{"scott.emp", "scott.dept", "app.orders"}, StaleOnly: true},
+			want: []string{"APP.ORDERS", "SCOTT.EMP"},
+		},
+		{
+			name: "requested tables",
+			req:  &dbdpb.GatherStatsRequest{Tables: []string{"scott.emp", "SCOTT.EMP", "scott.dept"}},
+			want: []string{"SCOTT.EMP", "SCOTT.DEPT"},
+		},
+		{
+			name:    "no tables",
+			req:     &dbdpb.GatherStatsRequest{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid table",
+			req:     &dbdpb.GatherStatsRequest{Tables: []string{"emp"}, StaleOnly: true},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := gatherTargets(tc.req, stale)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("gatherTargets got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("gatherTargets got unexpected tables (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestGatherStats(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	var gotSQLs []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		gotSQLs = append(gotSQLs, sqls...)
+		return nil, nil
+	}
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if want := fmt.Sprintf(staleStatsSQL, ""); sqls[len(sqls)-1] != want {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		return staleStatsRows, nil
+	}
+
+	resp, err := s.GatherStats(ctx, &dbdpb.GatherStatsRequest{PdbName: "pdb1", Tables: []string{"app.orders", "app.customers"}, StaleOnly: true})
+	if err != nil {
+		t.Fatalf("GatherStats failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"APP.ORDERS"}, resp.GetGatheredTables()); diff != "" {
+		t.Errorf("GatherStats got unexpected tables (-want +got):\n%v", diff)
+	}
+	wantSQLs := []string{
+		`alter session set container="PDB1"`,
+		flushMonitoringCmd,
+		`alter session set container="PDB1"`,
+		"begin dbms_stats.gather_table_stats(ownname => 'APP', tabname => 'ORDERS', cascade => true); end;",
+	}
+	if diff := cmp.Diff(wantSQLs, gotSQLs); diff != "" {
+		t.Errorf("GatherStats ran unexpected statements (-want +got):\n%v", diff)
+	}
+}
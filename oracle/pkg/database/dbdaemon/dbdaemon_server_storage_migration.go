@@ -0,0 +1,229 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	readWritePDBsSQL = "select name from v$pdbs where open_mode = 'READ WRITE' and name != 'PDB$SEED' order by name"
+
+	// checkpointSQL writes the dirty buffers to the data files.
+	checkpointSQL = "alter system checkpoint"
+
+	storageMigrationStateName = "storage_migration.json"
+)
+
+// storageMigrationStateFile returns where the PDBs reopened read only are
+// recorded, so that they are reopened read write even if the dbdaemon
+// restarts during the migration.
+var storageMigrationStateFile = func(sid string) string {
+	return filepath.Join(configDir(sid), storageMigrationStateName)
+}
+
+type storageMigrationState struct {
+	PDBs []string `json:"pdbs"`
+	// Prepared is set once all PDBs are read only and the database is
+	// checkpointed, an unprepared state is left by an interrupted
+	// PrepareForStorageMigration.
+	Prepared bool `json:"prepared"`
+}
+
+// readStorageMigrationState returns nil if no migration is in progress.
+func readStorageMigrationState(path string) (*storageMigrationState, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the storage migration state: %v", err)
+	}
+	state := &storageMigrationState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse the storage migration state %q: %v", content, err)
+	}
+	return state, nil
+}
+
+func writeStorageMigrationState(path string, state *storageMigrationState) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, content, filePerm(0640)); err != nil {
+		return fmt.Errorf("failed to write the storage migration state: %v", err)
+	}
+	return nil
+}
+
+// parsePDBNames returns the NAME column of the rows.
+func parsePDBNames(rows []string) ([]string, error) {
+	var names []string
+	for _, r := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(r), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse PDB row %q: %v", r, err)
+		}
+		if row["NAME"] == "" {
+			return nil, fmt.Errorf("missing NAME in PDB row %q", r)
+		}
+		names = append(names, row["NAME"])
+	}
+	return names, nil
+}
+
+// reopenPDBStatements returns the statements reopening the PDB in the mode,
+// READ ONLY or READ WRITE.
+func reopenPDBStatements(pdb, mode string) ([]string, error) {
+	name, err := sqlq.ObjectName(pdb)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDB name %q: %v", pdb, err)
+	}
+	return []string{
+		fmt.Sprintf("alter pluggable database %s close immediate", name),
+		fmt.Sprintf("alter pluggable database %s open %s", name, mode),
+	}, nil
+}
+
+func (s *Server) reopenPDB(ctx context.Context, pdb, mode string) error {
+	statements, err := reopenPDBStatements(pdb, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+		return fmt.Errorf("failed to reopen PDB %s %s: %v", pdb, mode, err)
+	}
+	return nil
+}
+
+// reopenPDBsReadWrite reopens the PDBs read write and removes the storage
+// migration state, which is kept if any PDB fails to reopen.
+func (s *Server) reopenPDBsReadWrite(ctx context.Context, pdbs []string, path string) error {
+	for _, pdb := range pdbs {
+		if err := s.reopenPDB(ctx, pdb, "read write"); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the storage migration state: %v", err)
+	}
+	return nil
+}
+
+// PrepareForStorageMigration reopens the read write PDBs read only and
+// checkpoints the database, so that the data files are consistent while the
+// storage is copied. The PDBs are reopened read write if any step fails.
+// Calling it again before CompleteStorageMigration returns the same PDBs,
+// calling it after a dbdaemon restart interrupted it resumes the migration.
+func (s *Server) PrepareForStorageMigration(ctx context.Context, req *dbdpb.PrepareForStorageMigrationRequest) (*dbdpb.PrepareForStorageMigrationResponse, error) {
+	klog.InfoS("dbdaemon/PrepareForStorageMigration", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	path := storageMigrationStateFile(s.databaseSid.val)
+	state, err := readStorageMigrationState(path)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/PrepareForStorageMigration: %v", err)
+	}
+	if state != nil && state.Prepared {
+		klog.InfoS("dbdaemon/PrepareForStorageMigration: storage migration already prepared", "pdbs", state.PDBs)
+		return &dbdpb.PrepareForStorageMigrationResponse{Pdbs: state.PDBs}, nil
+	}
+	if state == nil {
+		state = &storageMigrationState{}
+	} else {
+		klog.InfoS("dbdaemon/PrepareForStorageMigration: resuming an interrupted storage migration", "pdbs", state.PDBs)
+	}
+
+	rollback := func(cause error) error {
+		if err := s.reopenPDBsReadWrite(ctx, state.PDBs, path); err != nil {
+			return fmt.Errorf("dbdaemon/PrepareForStorageMigration: %v, rollback failed: %v", cause, err)
+		}
+		return fmt.Errorf("dbdaemon/PrepareForStorageMigration: %v, PDBs were reopened read write", cause)
+	}
+	// The PDBs recorded by an interrupted run may have been closed and not
+	// reopened yet.
+	for _, pdb := range state.PDBs {
+		if err := s.reopenPDB(ctx, pdb, "read only"); err != nil {
+			return nil, rollback(err)
+		}
+	}
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{readWritePDBsSQL}}, true)
+	if err != nil {
+		return nil, rollback(fmt.Errorf("failed to query read write PDBs: %v", err))
+	}
+	pdbs, err := parsePDBNames(resp.GetMsg())
+	if err != nil {
+		return nil, rollback(err)
+	}
+	for _, pdb := range pdbs {
+		// Record the PDB before closing it, the open may fail after the close.
+		state.PDBs = append(state.PDBs, pdb)
+		if err := writeStorageMigrationState(path, state); err != nil {
+			return nil, rollback(err)
+		}
+		if err := s.reopenPDB(ctx, pdb, "read only"); err != nil {
+			return nil, rollback(err)
+		}
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{checkpointSQL}}, false); err != nil {
+		return nil, rollback(fmt.Errorf("failed to checkpoint: %v", err))
+	}
+	state.Prepared = true
+	if err := writeStorageMigrationState(path, state); err != nil {
+		return nil, rollback(err)
+	}
+	klog.InfoS("dbdaemon/PrepareForStorageMigration: PDBs reopened read only", "pdbs", state.PDBs)
+	return &dbdpb.PrepareForStorageMigrationResponse{Pdbs: state.PDBs}, nil
+}
+
+// CompleteStorageMigration reopens the PDBs made read only by
+// PrepareForStorageMigration read write. It is also used to abort a
+// migration and does nothing if no migration is in progress.
+func (s *Server) CompleteStorageMigration(ctx context.Context, req *dbdpb.CompleteStorageMigrationRequest) (*dbdpb.CompleteStorageMigrationResponse, error) {
+	klog.InfoS("dbdaemon/CompleteStorageMigration", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	path := storageMigrationStateFile(s.databaseSid.val)
+	state, err := readStorageMigrationState(path)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CompleteStorageMigration: %v", err)
+	}
+	if state == nil {
+		return &dbdpb.CompleteStorageMigrationResponse{}, nil
+	}
+	if err := s.reopenPDBsReadWrite(ctx, state.PDBs, path); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CompleteStorageMigration: %v", err)
+	}
+	klog.InfoS("dbdaemon/CompleteStorageMigration: PDBs reopened read write", "pdbs", state.PDBs)
+	return &dbdpb.CompleteStorageMigrationResponse{Pdbs: state.PDBs}, nil
+}
@@ -0,0 +1,266 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+var openPDBStatement = regexp.MustCompile(`^alter pluggable database "(\w+)" open (read only|read write)$`)
+
+// fakePDBs simulates the open mode of PDBs, failing the statements in
+// failOn.
+type fakePDBs struct {
+	modes       map[string]string
+	failOn      map[string]bool
+	checkpoints int
+}
+
+func (f *fakePDBs) runSQL(sqls []string) ([]string, error) {
+	for _, stmt := range sqls {
+		if f.failOn[stmt] {
+			return nil, fmt.Errorf("ORA-65019: failed %q", stmt)
+		}
+		if stmt == checkpointSQL {
+			f.checkpoints++
+			continue
+		}
+		if m := openPDBStatement.FindStringSubmatch(stmt); m != nil {
+			f.modes[m[1]] = m[2]
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePDBs) runQuery(sqls []string) ([]string, error) {
+	if sqls[0] != readWritePDBsSQL {
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	var rows []string
+	for _, name := range []string{"PDB1", "PDB2"} {
+		if f.modes[name] == "read write" {
+			rows = append(rows, fmt.Sprintf(`{"NAME":%q}`, name))
+		}
+	}
+	return rows, nil
+}
+
+// useStorageMigrationStateDir keeps the storage migration state in a
+// temporary directory and returns the state file.
+func useStorageMigrationStateDir(t *testing.T) string {
+	t.Helper()
+	stateFile := filepath.Join(t.TempDir(), storageMigrationStateName)
+	orig := storageMigrationStateFile
+	storageMigrationStateFile = func(string) string { return stateFile }
+	t.Cleanup(func() { storageMigrationStateFile = orig })
+	return stateFile
+}
+
+func newStorageMigrationServer(ctx context.Context, t *testing.T, pdbs *fakePDBs) *Server {
+	t.Helper()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	db.runSQLFunc = pdbs.runSQL
+	db.runQueryFunc = pdbs.runQuery
+	return s
+}
+
+func TestStorageMigration(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		modes     map[string]string
+		wantPDBs  []string
+		wantModes map[string]string
+	}{
+		{
+			name:      "all PDBs open",
+			modes:     map[string]string{"PDB1": "read write", "PDB2": "read write"},
+			wantPDBs:  []string{"PDB1", "PDB2"},
+			wantModes: map[string]string{"PDB1": "read only", "PDB2": "read only"},
+		},
+		{
+			name:      "read only PDB is left alone",
+			modes:     map[string]string{"PDB1": "read write", "PDB2": "read only"},
+			wantPDBs:  []string{"PDB1"},
+			wantModes: map[string]string{"PDB1": "read only", "PDB2": "read only"},
+		},
+		{
+			name:      "no open PDBs",
+			modes:     map[string]string{"PDB1": "mounted"},
+			wantModes: map[string]string{"PDB1": "mounted"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stateFile := useStorageMigrationStateDir(t)
+			pdbs := &fakePDBs{modes: make(map[string]string)}
+			for k, v := range tc.modes {
+				pdbs.modes[k] = v
+			}
+			s := newStorageMigrationServer(ctx, t, pdbs)
+
+			for i := 0; i < 2; i++ {
+				resp, err := s.PrepareForStorageMigration(ctx, &dbdpb.PrepareForStorageMigrationRequest{})
+				if err != nil {
+					t.Fatalf("PrepareForStorageMigration #%d failed: %v", i, err)
+				}
+				if diff := cmp.Diff(tc.wantPDBs, resp.GetPdbs()); diff != "" {
+					t.Errorf("PrepareForStorageMigration #%d got unexpected PDBs (-want +got):\n%v", i, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.wantModes, pdbs.modes); diff != "" {
+				t.Errorf("PrepareForStorageMigration got unexpected PDB modes (-want +got):\n%v", diff)
+			}
+			if pdbs.checkpoints != 1 {
+				t.Errorf("PrepareForStorageMigration checkpointed %d times, want 1", pdbs.checkpoints)
+			}
+
+			// The state survives a dbdaemon restart.
+			s = newStorageMigrationServer(ctx, t, pdbs)
+			resp, err := s.CompleteStorageMigration(ctx, &dbdpb.CompleteStorageMigrationRequest{})
+			if err != nil {
+				t.Fatalf("CompleteStorageMigration failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantPDBs, resp.GetPdbs()); diff != "" {
+				t.Errorf("CompleteStorageMigration got unexpected PDBs (-want +got):\n%v", diff)
+			}
+			if diff := cmp.Diff(tc.modes, pdbs.modes); diff != "" {
+				t.Errorf("CompleteStorageMigration got unexpected PDB modes (-want +got):\n%v", diff)
+			}
+			if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+				t.Errorf("CompleteStorageMigration kept the storage migration state: %v", err)
+			}
+
+			if resp, err := s.CompleteStorageMigration(ctx, &dbdpb.CompleteStorageMigrationRequest{}); err != nil || len(resp.GetPdbs()) != 0 {
+				t.Errorf("CompleteStorageMigration without a migration = %v, %v, want no PDBs", resp, err)
+			}
+		})
+	}
+}
+
+func TestPrepareForStorageMigrationResume(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	stateFile := useStorageMigrationStateDir(t)
+
+	// The dbdaemon restarted after closing PDB1 and before reopening it.
+	if err := writeStorageMigrationState(stateFile, &storageMigrationState{PDBs: []string{"PDB1"}}); err != nil {
+		t.Fatalf("writeStorageMigrationState failed: %v", err)
+	}
+	pdbs := &fakePDBs{modes: map[string]string{"PDB1": "mounted", "PDB2": "read write"}}
+	s := newStorageMigrationServer(ctx, t, pdbs)
+
+	resp, err := s.PrepareForStorageMigration(ctx, &dbdpb.PrepareForStorageMigrationRequest{})
+	if err != nil {
+		t.Fatalf("PrepareForStorageMigration failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"PDB1", "PDB2"}, resp.GetPdbs()); diff != "" {
+		t.Errorf("PrepareForStorageMigration got unexpected PDBs (-want +got):\n%v", diff)
+	}
+	want := map[string]string{"PDB1": "read only", "PDB2": "read only"}
+	if diff := cmp.Diff(want, pdbs.modes); diff != "" {
+		t.Errorf("PrepareForStorageMigration got unexpected PDB modes (-want +got):\n%v", diff)
+	}
+	if pdbs.checkpoints != 1 {
+		t.Errorf("PrepareForStorageMigration checkpointed %d times, want 1", pdbs.checkpoints)
+	}
+	state, err := readStorageMigrationState(stateFile)
+	if err != nil || !state.Prepared {
+		t.Errorf("PrepareForStorageMigration left state %+v, %v, want a prepared migration", state, err)
+	}
+
+	if _, err := s.CompleteStorageMigration(ctx, &dbdpb.CompleteStorageMigrationRequest{}); err != nil {
+		t.Fatalf("CompleteStorageMigration failed: %v", err)
+	}
+	want = map[string]string{"PDB1": "read write", "PDB2": "read write"}
+	if diff := cmp.Diff(want, pdbs.modes); diff != "" {
+		t.Errorf("CompleteStorageMigration got unexpected PDB modes (-want +got):\n%v", diff)
+	}
+}
+
+func TestPrepareForStorageMigrationRollback(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+
+	for _, failOn := range []string{
+		`alter pluggable database "PDB2" open read only`,
+		checkpointSQL,
+	} {
+		t.Run(failOn, func(t *testing.T) {
+			stateFile := useStorageMigrationStateDir(t)
+			pdbs := &fakePDBs{
+				modes:  map[string]string{"PDB1": "read write", "PDB2": "read write"},
+				failOn: map[string]bool{failOn: true},
+			}
+			s := newStorageMigrationServer(ctx, t, pdbs)
+
+			if _, err := s.PrepareForStorageMigration(ctx, &dbdpb.PrepareForStorageMigrationRequest{}); err == nil {
+				t.Fatalf("PrepareForStorageMigration succeeded, want error")
+			}
+			want := map[string]string{"PDB1": "read write", "PDB2": "read write"}
+			if diff := cmp.Diff(want, pdbs.modes); diff != "" {
+				t.Errorf("PrepareForStorageMigration did not roll back PDB modes (-want +got):\n%v", diff)
+			}
+			if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+				t.Errorf("PrepareForStorageMigration kept the storage migration state after a rollback: %v", err)
+			}
+		})
+	}
+
+	t.Run("rollback failed", func(t *testing.T) {
+		useStorageMigrationStateDir(t)
+		pdbs := &fakePDBs{
+			modes: map[string]string{"PDB1": "read write", "PDB2": "read write"},
+			failOn: map[string]bool{
+				checkpointSQL: true,
+				`alter pluggable database "PDB1" open read write`: true,
+			},
+		}
+		s := newStorageMigrationServer(ctx, t, pdbs)
+
+		if _, err := s.PrepareForStorageMigration(ctx, &dbdpb.PrepareForStorageMigrationRequest{}); err == nil {
+			t.Fatalf("PrepareForStorageMigration succeeded, want error")
+		}
+
+		// Aborting the migration reopens the PDBs once the database recovers.
+		delete(pdbs.failOn, `alter pluggable database "PDB1" open read write`)
+		resp, err := s.CompleteStorageMigration(ctx, &dbdpb.CompleteStorageMigrationRequest{})
+		if err != nil {
+			t.Fatalf("CompleteStorageMigration failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"PDB1", "PDB2"}, resp.GetPdbs()); diff != "" {
+			t.Errorf("CompleteStorageMigration got unexpected PDBs (-want +got):\n%v", diff)
+		}
+		want := map[string]string{"PDB1": "read write", "PDB2": "read write"}
+		if diff := cmp.Diff(want, pdbs.modes); diff != "" {
+			t.Errorf("CompleteStorageMigration got unexpected PDB modes (-want +got):\n%v", diff)
+		}
+	})
+}
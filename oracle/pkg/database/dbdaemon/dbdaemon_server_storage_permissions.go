@@ -0,0 +1,170 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// storagePermissionProbeObject prefixes the name of the object written to
+// probe the write and delete permissions.
+const storagePermissionProbeObject = ".elcarro-permission-check"
+
+// storageProber performs the object operations CheckStoragePermissions
+// probes.
+type storageProber interface {
+	// list returns the first object under prefix, empty if there is none.
+	list(ctx context.Context, bucket, prefix string) (string, error)
+	read(ctx context.Context, bucket, object string) error
+	write(ctx context.Context, bucket, object string) error
+	delete(ctx context.Context, bucket, object string) error
+}
+
+type gcsProber struct {
+	client *storage.Client
+}
+
+func (g *gcsProber) list(ctx context.Context, bucket, prefix string) (string, error) {
+	attrs, err := g.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix}).Next()
+	if err == iterator.Done {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return attrs.Name, nil
+}
+
+func (g *gcsProber) read(ctx context.Context, bucket, object string) error {
+	reader, err := g.client.Bucket(bucket).Object(object).NewRangeReader(ctx, 0, 1)
+	if err != nil {
+		return err
+	}
+	return reader.Close()
+}
+
+func (g *gcsProber) write(ctx context.Context, bucket, object string) error {
+	writer := g.client.Bucket(bucket).Object(object).NewWriter(ctx)
+	if _, err := writer.Write([]byte("ok")); err != nil {
+		writer.Close()
+		return err
+	}
+	// The upload completes, and permission errors surface, on Close.
+	return writer.Close()
+}
+
+func (g *gcsProber) delete(ctx context.Context, bucket, object string) error {
+	return g.client.Bucket(bucket).Object(object).Delete(ctx)
+}
+
+// newStorageProber is overridden in tests.
+var newStorageProber = func(ctx context.Context) (storageProber, func() error, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage.NewClient: %v", err)
+	}
+	return &gcsProber{client: client}, client.Close, nil
+}
+
+func setPermissionResult(p *dbdpb.CheckStoragePermissionsResponse_Permission, err error) {
+	p.Granted = err == nil
+	if err != nil {
+		p.Error = err.Error()
+	}
+}
+
+func skipPermission(p *dbdpb.CheckStoragePermissionsResponse_Permission, reason string) {
+	p.Skipped = true
+	p.Error = reason
+}
+
+// probeStoragePermissions lists the prefix, writes a probe object unless
+// readOnly, reads the first listed object or the probe object and deletes
+// the probe object.
+func probeStoragePermissions(ctx context.Context, prober storageProber, bucket, prefix string, readOnly bool) []*dbdpb.CheckStoragePermissionsResponse_Permission {
+	list := &dbdpb.CheckStoragePermissionsResponse_Permission{Name: "list", IamPermission: "storage.objects.list"}
+	read := &dbdpb.CheckStoragePermissionsResponse_Permission{Name: "read", IamPermission: "storage.objects.get"}
+	write := &dbdpb.CheckStoragePermissionsResponse_Permission{Name: "write", IamPermission: "storage.objects.create"}
+	del := &dbdpb.CheckStoragePermissionsResponse_Permission{Name: "delete", IamPermission: "storage.objects.delete"}
+
+	object, err := prober.list(ctx, bucket, prefix)
+	setPermissionResult(list, err)
+
+	var probe string
+	if readOnly {
+		skipPermission(write, "read only check")
+		skipPermission(del, "read only check")
+	} else {
+		probe = path.Join(prefix, fmt.Sprintf("%s-%d", storagePermissionProbeObject, time.Now().UnixNano()))
+		err := prober.write(ctx, bucket, probe)
+		setPermissionResult(write, err)
+		if err != nil {
+			probe = ""
+		}
+	}
+
+	if object == "" {
+		object = probe
+	}
+	if object == "" {
+		skipPermission(read, "no object to read under the prefix")
+	} else {
+		setPermissionResult(read, prober.read(ctx, bucket, object))
+	}
+
+	if !readOnly {
+		if probe == "" {
+			skipPermission(del, "no probe object was written")
+		} else if err := prober.delete(ctx, bucket, probe); err != nil {
+			setPermissionResult(del, fmt.Errorf("%v, the probe object gs://%s/%s needs to be removed manually", err, bucket, probe))
+		} else {
+			setPermissionResult(del, nil)
+		}
+	}
+	return []*dbdpb.CheckStoragePermissionsResponse_Permission{list, read, write, del}
+}
+
+// CheckStoragePermissions reports which object operations the dbdaemon
+// credentials are permitted under a GCS prefix, to diagnose Workload
+// Identity misconfigurations before backups and restores fail.
+func (s *Server) CheckStoragePermissions(ctx context.Context, req *dbdpb.CheckStoragePermissionsRequest) (*dbdpb.CheckStoragePermissionsResponse, error) {
+	klog.InfoS("dbdaemon/CheckStoragePermissions", "req", loggableRequest(req))
+	u, err := url.Parse(req.GetGcsPath())
+	if err != nil || u.Scheme != "gs" || u.Host == "" {
+		return nil, fmt.Errorf("dbdaemon/CheckStoragePermissions: invalid GCS path %q", req.GetGcsPath())
+	}
+	prober, closeProber, err := newStorageProber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CheckStoragePermissions: %v", err)
+	}
+	defer closeProber()
+
+	permissions := probeStoragePermissions(ctx, prober, u.Host, strings.TrimPrefix(u.Path, "/"), req.GetReadOnly())
+	for _, p := range permissions {
+		klog.InfoS("dbdaemon/CheckStoragePermissions: probed", "gcsPath", req.GetGcsPath(), "permission", p.GetIamPermission(), "granted", p.GetGranted(), "skipped", p.GetSkipped(), "error", p.GetError())
+	}
+	return &dbdpb.CheckStoragePermissionsResponse{Permissions: permissions}, nil
+}
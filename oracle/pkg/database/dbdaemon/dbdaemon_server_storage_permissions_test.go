@@ -0,0 +1,209 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// fakeStorage keeps the objects of a bucket and denies the operations in
+// denied, like a service account missing some IAM permissions.
+type fakeStorage struct {
+	objects map[string]bool
+	denied  map[string]bool
+}
+
+func (f *fakeStorage) check(op string) error {
+	if f.denied[op] {
+		return errors.New("googleapi: Error 403: access denied")
+	}
+	return nil
+}
+
+func (f *fakeStorage) list(ctx context.Context, bucket, prefix string) (string, error) {
+	if err := f.check("list"); err != nil {
+		return "", err
+	}
+	for name := range f.objects {
+		if strings.HasPrefix(name, prefix) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeStorage) read(ctx context.Context, bucket, object string) error {
+	if err := f.check("read"); err != nil {
+		return err
+	}
+	if !f.objects[object] {
+		return errors.New("storage: object doesn't exist")
+	}
+	return nil
+}
+
+func (f *fakeStorage) write(ctx context.Context, bucket, object string) error {
+	if err := f.check("write"); err != nil {
+		return err
+	}
+	f.objects[object] = true
+	return nil
+}
+
+func (f *fakeStorage) delete(ctx context.Context, bucket, object string) error {
+	if err := f.check("delete"); err != nil {
+		return err
+	}
+	delete(f.objects, object)
+	return nil
+}
+
+func TestCheckStoragePermissions(t *testing.T) {
+	denied := func(name, iamPermission string) *dbdpb.CheckStoragePermissionsResponse_Permission {
+		return &dbdpb.CheckStoragePermissionsResponse_Permission{Name: name, IamPermission: iamPermission, Error: "googleapi: Error 403: access denied"}
+	}
+	granted := func(name, iamPermission string) *dbdpb.CheckStoragePermissionsResponse_Permission {
+		return &dbdpb.CheckStoragePermissionsResponse_Permission{Name: name, IamPermission: iamPermission, Granted: true}
+	}
+	skipped := func(name, iamPermission, reason string) *dbdpb.CheckStoragePermissionsResponse_Permission {
+		return &dbdpb.CheckStoragePermissionsResponse_Permission{Name: name, IamPermission: iamPermission, Skipped: true, Error: reason}
+	}
+
+	tests := []struct {
+		name         string
+		objects      []string
+		denied       []string
+		readOnly     bool
+		want         []*dbdpb.CheckStoragePermissionsResponse_Permission
+		wantLeftover bool
+	}{
+		{
+			name:    "all granted",
+			objects: []string{"backups/backup.bkp"},
+			want: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+				granted("list", "storage.objects.list"),
+				granted("read", "storage.objects.get"),
+				granted("write", "storage.objects.create"),
+				granted("delete", "storage.objects.delete"),
+			},
+		},
+		{
+			name:    "object viewer",
+			objects: []string{"backups/backup.bkp"},
+			denied:  []string{"write", "delete"},
+			want: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+				granted("list", "storage.objects.list"),
+				granted("read", "storage.objects.get"),
+				denied("write", "storage.objects.create"),
+				skipped("delete", "storage.objects.delete", "no probe object was written"),
+			},
+		},
+		{
+			name:   "object creator",
+			denied: []string{"list", "read", "delete"},
+			want: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+				denied("list", "storage.objects.list"),
+				denied("read", "storage.objects.get"),
+				granted("write", "storage.objects.create"),
+				{Name: "delete", IamPermission: "storage.objects.delete"},
+			},
+			wantLeftover: true,
+		},
+		{
+			name:   "empty prefix without write",
+			denied: []string{"write"},
+			want: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+				granted("list", "storage.objects.list"),
+				skipped("read", "storage.objects.get", "no object to read under the prefix"),
+				denied("write", "storage.objects.create"),
+				skipped("delete", "storage.objects.delete", "no probe object was written"),
+			},
+		},
+		{
+			name:     "read only",
+			objects:  []string{"backups/backup.bkp"},
+			readOnly: true,
+			want: []*dbdpb.CheckStoragePermissionsResponse_Permission{
+				granted("list", "storage.objects.list"),
+				granted("read", "storage.objects.get"),
+				skipped("write", "storage.objects.create", "read only check"),
+				skipped("delete", "storage.objects.delete", "read only check"),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := &fakeStorage{objects: map[string]bool{}, denied: map[string]bool{}}
+			for _, o := range tc.objects {
+				fake.objects[o] = true
+			}
+			for _, op := range tc.denied {
+				fake.denied[op] = true
+			}
+			orig := newStorageProber
+			newStorageProber = func(context.Context) (storageProber, func() error, error) {
+				return fake, func() error { return nil }, nil
+			}
+			defer func() { newStorageProber = orig }()
+
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			resp, err := s.CheckStoragePermissions(ctx, &dbdpb.CheckStoragePermissionsRequest{GcsPath: "gs://bucket/backups", ReadOnly: tc.readOnly})
+			if err != nil {
+				t.Fatalf("CheckStoragePermissions failed: %v", err)
+			}
+			got := resp.GetPermissions()
+			if tc.wantLeftover && !strings.Contains(got[3].GetError(), "needs to be removed manually") {
+				t.Errorf("CheckStoragePermissions delete error %q does not mention the leftover probe object", got[3].GetError())
+			}
+			opts := []cmp.Option{protocmp.Transform()}
+			if tc.wantLeftover {
+				// The error names the probe object, which is timestamped.
+				opts = append(opts, protocmp.IgnoreFields(&dbdpb.CheckStoragePermissionsResponse_Permission{}, "error"))
+			}
+			if diff := cmp.Diff(tc.want, got, opts...); diff != "" {
+				t.Errorf("CheckStoragePermissions got unexpected permissions (-want +got):\n%v", diff)
+			}
+			var leftover []string
+			for name := range fake.objects {
+				if strings.Contains(name, storagePermissionProbeObject) {
+					leftover = append(leftover, name)
+				}
+			}
+			if (len(leftover) > 0) != tc.wantLeftover {
+				t.Errorf("CheckStoragePermissions left probe objects %v, want leftover %v", leftover, tc.wantLeftover)
+			}
+		})
+	}
+
+	s, err := NewMockServer(context.Background(), "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	if _, err := s.CheckStoragePermissions(context.Background(), &dbdpb.CheckStoragePermissionsRequest{GcsPath: "/backups"}); err == nil {
+		t.Errorf("CheckStoragePermissions with a non GCS path succeeded, want error")
+	}
+}
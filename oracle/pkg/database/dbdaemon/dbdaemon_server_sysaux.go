@@ -0,0 +1,229 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	sysauxUsageSQL = "select used_percent from dba_tablespace_usage_metrics where tablespace_name='SYSAUX'"
+
+	sysauxOccupantsSQL = "select occupant_name, schema_name, space_usage_kbytes from v$sysaux_occupants order by space_usage_kbytes desc"
+
+	// purgeAWRCmd lowers the AWR retention, in minutes, and drops the
+	// snapshots older than the retention, in days, right away instead of
+	// waiting for the automatic purge.
+	purgeAWRCmd = "declare lo number; hi number; begin " +
+		"dbms_workload_repository.modify_snapshot_settings(retention => %d); " +
+		"select min(snap_id), max(snap_id) into lo, hi from dba_hist_snapshot " +
+		"where dbid=(select dbid from v$database) and end_interval_time < systimestamp - numtodsinterval(%d, 'DAY'); " +
+		"if lo is not null then dbms_workload_repository.drop_snapshot_range(low_snap_id => lo, high_snap_id => hi); end if; end;"
+
+	purgeAuditCmd = "begin " +
+		"dbms_audit_mgmt.set_last_archive_timestamp(audit_trail_type => dbms_audit_mgmt.audit_trail_unified, last_archive_time => systimestamp - numtodsinterval(%d, 'DAY')); " +
+		"dbms_audit_mgmt.clean_audit_trail(audit_trail_type => dbms_audit_mgmt.audit_trail_unified, use_last_arch_timestamp => true); end;"
+
+	purgeSQLMonitorCmd = "declare lo number; hi number; begin " +
+		"select min(snap_id), max(snap_id) into lo, hi from dba_hist_reports " +
+		"where dbid=(select dbid from v$database) and component_name='sqlmonitor' and period_end_time < sysdate - %d; " +
+		"if lo is not null then dbms_workload_repository.drop_snapshot_range(low_snap_id => lo, high_snap_id => hi); end if; end;"
+
+	defaultAWRRetentionDays   = 8
+	defaultAuditRetentionDays = 30
+
+	// minSysauxOccupantShare is the share of the SYSAUX space used by the
+	// occupants that selects the purge of an occupant.
+	minSysauxOccupantShare = 0.1
+)
+
+// sysauxOccupantActions maps the occupants to the actions purging them.
+var sysauxOccupantActions = map[string]dbdpb.PurgeSysauxRequest_Action{
+	"SM/AWR":       dbdpb.PurgeSysauxRequest_AWR,
+	"AUDSYS":       dbdpb.PurgeSysauxRequest_AUDIT,
+	"AUDIT_TABLES": dbdpb.PurgeSysauxRequest_AUDIT,
+}
+
+// parseSysauxUsage reads the used_percent of the sysauxUsageSQL row.
+func parseSysauxUsage(rows []string) (float64, error) {
+	if len(rows) != 1 {
+		return 0, fmt.Errorf("expected 1 SYSAUX usage row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return 0, fmt.Errorf("failed to parse SYSAUX usage row %q: %v", rows[0], err)
+	}
+	used, err := strconv.ParseFloat(row["USED_PERCENT"], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse USED_PERCENT in SYSAUX usage row %q: %v", rows[0], err)
+	}
+	return used, nil
+}
+
+// parseSysauxOccupants converts sysauxOccupantsSQL rows into occupants.
+func parseSysauxOccupants(rows []string) ([]*dbdpb.GetSysauxOccupantsResponse_Occupant, error) {
+	var occupants []*dbdpb.GetSysauxOccupantsResponse_Occupant
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse SYSAUX occupant row %q: %v", msg, err)
+		}
+		kbytes, err := strconv.ParseInt(row["SPACE_USAGE_KBYTES"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SPACE_USAGE_KBYTES in SYSAUX occupant row %q: %v", msg, err)
+		}
+		occupants = append(occupants, &dbdpb.GetSysauxOccupantsResponse_Occupant{
+			Name:            row["OCCUPANT_NAME"],
+			SchemaName:      row["SCHEMA_NAME"],
+			SpaceUsageBytes: kbytes * 1024,
+		})
+	}
+	sort.SliceStable(occupants, func(i, j int) bool {
+		return occupants[i].GetSpaceUsageBytes() > occupants[j].GetSpaceUsageBytes()
+	})
+	return occupants, nil
+}
+
+// selectSysauxPurges returns the purge actions to run: none below the
+// threshold, the requested ones or, if none were requested, the ones of the
+// occupants using at least minSysauxOccupantShare of the occupied space,
+// largest first.
+func selectSysauxPurges(req *dbdpb.PurgeSysauxRequest, usedPercent float64, occupants []*dbdpb.GetSysauxOccupantsResponse_Occupant) []dbdpb.PurgeSysauxRequest_Action {
+	if usedPercent < req.GetThresholdPercent() {
+		return nil
+	}
+	seen := make(map[dbdpb.PurgeSysauxRequest_Action]bool)
+	var actions []dbdpb.PurgeSysauxRequest_Action
+	add := func(a dbdpb.PurgeSysauxRequest_Action) {
+		if a != dbdpb.PurgeSysauxRequest_ACTION_UNSPECIFIED && !seen[a] {
+			seen[a] = true
+			actions = append(actions, a)
+		}
+	}
+	if len(req.GetActions()) > 0 {
+		for _, a := range req.GetActions() {
+			add(a)
+		}
+		return actions
+	}
+	var total int64
+	for _, o := range occupants {
+		total += o.GetSpaceUsageBytes()
+	}
+	for _, o := range occupants {
+		a, ok := sysauxOccupantActions[o.GetName()]
+		if ok && total > 0 && float64(o.GetSpaceUsageBytes())/float64(total) >= minSysauxOccupantShare {
+			add(a)
+		}
+	}
+	return actions
+}
+
+// sysauxPurgeCmd returns the statement running a purge action.
+func sysauxPurgeCmd(action dbdpb.PurgeSysauxRequest_Action, retentionDays int32) (string, error) {
+	switch action {
+	case dbdpb.PurgeSysauxRequest_AWR:
+		if retentionDays == 0 {
+			retentionDays = defaultAWRRetentionDays
+		}
+		return fmt.Sprintf(purgeAWRCmd, retentionDays*24*60, retentionDays), nil
+	case dbdpb.PurgeSysauxRequest_AUDIT:
+		if retentionDays == 0 {
+			retentionDays = defaultAuditRetentionDays
+		}
+		return fmt.Sprintf(purgeAuditCmd, retentionDays), nil
+	case dbdpb.PurgeSysauxRequest_SQL_MONITOR:
+		if retentionDays == 0 {
+			retentionDays = defaultAWRRetentionDays
+		}
+		return fmt.Sprintf(purgeSQLMonitorCmd, retentionDays), nil
+	default:
+		return "", fmt.Errorf("unsupported purge action %v", action)
+	}
+}
+
+func (s *Server) sysauxOccupants(ctx context.Context) (*dbdpb.GetSysauxOccupantsResponse, error) {
+	usageResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{sysauxUsageSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the SYSAUX usage: %v", err)
+	}
+	used, err := parseSysauxUsage(usageResp.GetMsg())
+	if err != nil {
+		return nil, err
+	}
+	occResp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{sysauxOccupantsSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the SYSAUX occupants: %v", err)
+	}
+	occupants, err := parseSysauxOccupants(occResp.GetMsg())
+	if err != nil {
+		return nil, err
+	}
+	return &dbdpb.GetSysauxOccupantsResponse{UsedPercent: used, Occupants: occupants}, nil
+}
+
+// GetSysauxOccupants reports the SYSAUX usage and its occupants from
+// v$sysaux_occupants.
+func (s *Server) GetSysauxOccupants(ctx context.Context, req *dbdpb.GetSysauxOccupantsRequest) (*dbdpb.GetSysauxOccupantsResponse, error) {
+	klog.InfoS("dbdaemon/GetSysauxOccupants", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.sysauxOccupants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetSysauxOccupants: %v", err)
+	}
+	return resp, nil
+}
+
+// PurgeSysaux runs the purge actions selected by selectSysauxPurges.
+func (s *Server) PurgeSysaux(ctx context.Context, req *dbdpb.PurgeSysauxRequest) (*dbdpb.PurgeSysauxResponse, error) {
+	klog.InfoS("dbdaemon/PurgeSysaux", "req", loggableRequest(req))
+	if req.GetRetentionDays() < 0 {
+		return nil, fmt.Errorf("dbdaemon/PurgeSysaux: negative retention of %d days", req.GetRetentionDays())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	usage, err := s.sysauxOccupants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/PurgeSysaux: %v", err)
+	}
+	resp := &dbdpb.PurgeSysauxResponse{UsedPercent: usage.GetUsedPercent()}
+	for _, action := range selectSysauxPurges(req, usage.GetUsedPercent(), usage.GetOccupants()) {
+		cmd, err := sysauxPurgeCmd(action, req.GetRetentionDays())
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/PurgeSysaux: %v", err)
+		}
+		klog.InfoS("dbdaemon/PurgeSysaux: purging", "action", action, "usedPercent", usage.GetUsedPercent())
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{cmd}}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/PurgeSysaux: failed to purge %v, purged %v: %v", action, resp.GetPurged(), err)
+		}
+		resp.Purged = append(resp.Purged, action)
+	}
+	return resp, nil
+}
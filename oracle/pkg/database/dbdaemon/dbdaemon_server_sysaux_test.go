@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseSysauxOccupants(t *testing.T) {
+	rows := []string{
+		`{"OCCUPANT_NAME": "SM/OPTSTAT", "SCHEMA_NAME": "SYS", "SPACE_USAGE_KBYTES": "20480"}`,
+		`{"OCCUPANT_NAME": "SM/AWR", "SCHEMA_NAME": "SYS", "SPACE_USAGE_KBYTES": "409600"}`,
+		`{"OCCUPANT_NAME": "AUDSYS", "SCHEMA_NAME": "AUDSYS", "SPACE_USAGE_KBYTES": "102400"}`,
+	}
+	want := []*dbdpb.GetSysauxOccupantsResponse_Occupant{
+		{Name: "SM/AWR", SchemaName: "SYS", SpaceUsageBytes: 400 << 20},
+		{Name: "AUDSYS", SchemaName: "AUDSYS", SpaceUsageBytes: 100 << 20},
+		{Name: "SM/OPTSTAT", SchemaName: "SYS", SpaceUsageBytes: 20 << 20},
+	}
+	got, err := parseSysauxOccupants(rows)
+	if err != nil {
+		t.Fatalf("parseSysauxOccupants failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseSysauxOccupants got unexpected occupants (-want +got):\n%v", diff)
+	}
+	if _, err := parseSysauxOccupants([]string{`{"OCCUPANT_NAME": "SM/AWR", "SPACE_USAGE_KBYTES": ""}`}); err == nil {
+		t.Errorf("parseSysauxOccupants with an empty size succeeded, want error")
+	}
+
+	used, err := parseSysauxUsage([]string{`{"USED_PERCENT": "91.25"}`})
+	if err != nil || used != 91.25 {
+		t.Errorf("parseSysauxUsage got %v, %v, want 91.25", used, err)
+	}
+	if _, err := parseSysauxUsage(nil); err == nil {
+		t.Errorf("parseSysauxUsage without rows succeeded, want error")
+	}
+}
+
+func TestSelectSysauxPurges(t *testing.T) {
+	occupants := []*dbdpb.GetSysauxOccupantsResponse_Occupant{
+		{Name: "SM/AWR", SpaceUsageBytes: 600},
+		{Name: "SM/OPTSTAT", SpaceUsageBytes: 300},
+		{Name: "AUDSYS", SpaceUsageBytes: 95},
+		{Name: "AUDIT_TABLES", SpaceUsageBytes: 5},
+	}
+	tests := []struct {
+		name        string
+		req         *dbdpb.PurgeSysauxRequest
+		usedPercent float64
+		occupants   []*dbdpb.GetSysauxOccupantsResponse_Occupant
+		want        []dbdpb.PurgeSysauxRequest_Action
+	}{
+		{
+			name:        "below threshold",
+			req:         &dbdpb.PurgeSysauxRequest{ThresholdPercent: 85},
+			usedPercent: 60,
+			occupants:   occupants,
+		},
+		{
+			name:        "large occupants only",
+			req:         &dbdpb.PurgeSysauxRequest{ThresholdPercent: 85},
+			usedPercent: 90,
+			occupants:   occupants,
+			want:        []dbdpb.PurgeSysauxRequest_Action{dbdpb.PurgeSysauxRequest_AWR},
+		},
+		{
+			name:        "audit above its share",
+			req:         &dbdpb.PurgeSysauxRequest{},
+			usedPercent: 50,
+			occupants: []*dbdpb.GetSysauxOccupantsResponse_Occupant{
+				{Name: "AUDSYS", SpaceUsageBytes: 500},
+				{Name: "SM/AWR", SpaceUsageBytes: 400},
+				{Name: "AUDIT_TABLES", SpaceUsageBytes: 100},
+			},
+			want: []dbdpb.PurgeSysauxRequest_Action{dbdpb.PurgeSysauxRequest_AUDIT, dbdpb.PurgeSysauxRequest_AWR},
+		},
+		{
+			name: "requested actions",
+			req: &dbdpb.PurgeSysauxRequest{
+				ThresholdPercent: 85,
+				Actions:          []dbdpb.PurgeSysauxRequest_Action{dbdpb.PurgeSysauxRequest_SQL_MONITOR, dbdpb.PurgeSysauxRequest_AUDIT, dbdpb.PurgeSysauxRequest_SQL_MONITOR},
+			},
+			usedPercent: 85,
+			occupants:   occupants,
+			want:        []dbdpb.PurgeSysauxRequest_Action{dbdpb.PurgeSysauxRequest_SQL_MONITOR, dbdpb.PurgeSysauxRequest_AUDIT},
+		},
+		{
+			name:        "no occupants",
+			req:         &dbdpb.PurgeSysauxRequest{},
+			usedPercent: 99,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := selectSysauxPurges(tc.req, tc.usedPercent, tc.occupants)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("selectSysauxPurges got unexpected actions (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestSysauxPurgeCmd(t *testing.T) {
+	got, err := sysauxPurgeCmd(dbdpb.PurgeSysauxRequest_AWR, 0)
+	if err != nil {
+		t.Fatalf("sysauxPurgeCmd(AWR) failed: %v", err)
+	}
+	want := "declare lo number; hi number; begin dbms_workload_repository.modify_snapshot_settings(retention => 11520); " +
+		"select min(snap_id), max(snap_id) into lo, hi from dba_hist_snapshot where dbid=(select dbid from v$database) and end_interval_time < systimestamp - numtodsinterval(8, 'DAY'); " +
+		"if lo is not null then dbms_workload_repository.drop_snapshot_range(low_snap_id => lo, high_snap_id => hi); end if; end;"
+	if got != want {
+		t.Errorf("sysauxPurgeCmd(AWR) got %q, want %q", got, want)
+	}
+	got, err = sysauxPurgeCmd(dbdpb.PurgeSysauxRequest_AUDIT, 7)
+	if err != nil {
+		t.Fatalf("sysauxPurgeCmd(AUDIT) failed: %v", err)
+	}
+	want = "begin dbms_audit_mgmt.set_last_archive_timestamp(audit_trail_type => dbms_audit_mgmt.audit_trail_unified, last_archive_time => systimestamp - numtodsinterval(7, 'DAY')); " +
+		"dbms_audit_mgmt.clean_audit_trail(audit_trail_type => dbms_audit_mgmt.audit_trail_unified, use_last_arch_timestamp => true); end;"
+	if got != want {
+		t.Errorf("sysauxPurgeCmd(AUDIT) got %q, want %q", got, want)
+	}
+	if _, err := sysauxPurgeCmd(dbdpb.PurgeSysauxRequest_ACTION_UNSPECIFIED, 0); err == nil {
+		t.Errorf("sysauxPurgeCmd(ACTION_UNSPECIFIED) succeeded, want error")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	defaultTablespacesSQL = "select property_name, property_value from database_properties " +
+		"where property_name in ('DEFAULT_PERMANENT_TABLESPACE', 'DEFAULT_TEMP_TABLESPACE')"
+
+	systemUsersSQL = "select username from dba_users " +
+		"where default_tablespace in ('SYSTEM', 'SYSAUX') and oracle_maintained = 'N' order by username"
+)
+
+// parseDefaultTablespaces reads the tablespaces of the defaultTablespacesSQL
+// rows.
+func parseDefaultTablespaces(rows []string) (*dbdpb.GetDefaultTablespacesResponse, error) {
+	resp := &dbdpb.GetDefaultTablespacesResponse{}
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse default tablespace row %q: %v", msg, err)
+		}
+		switch row["PROPERTY_NAME"] {
+		case "DEFAULT_PERMANENT_TABLESPACE":
+			resp.DefaultTablespace = row["PROPERTY_VALUE"]
+		case "DEFAULT_TEMP_TABLESPACE":
+			resp.DefaultTemporaryTablespace = row["PROPERTY_VALUE"]
+		}
+	}
+	if resp.GetDefaultTablespace() == "" || resp.GetDefaultTemporaryTablespace() == "" {
+		return nil, fmt.Errorf("missing default tablespaces in %q", rows)
+	}
+	return resp, nil
+}
+
+// parseSystemUsers reads the user names of the systemUsersSQL rows.
+func parseSystemUsers(rows []string) ([]string, error) {
+	var users []string
+	for _, msg := range rows {
+		row := make(map[string]string)
+		if err := json.Unmarshal([]byte(msg), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse user row %q: %v", msg, err)
+		}
+		users = append(users, row["USERNAME"])
+	}
+	return users, nil
+}
+
+// validateDefaultTablespaces checks the PDB and the tablespace names of the
+// request.
+func validateDefaultTablespaces(req *dbdpb.SetDefaultTablespacesRequest) error {
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return fmt.Errorf("invalid PDB name %q", req.GetPdbName())
+	}
+	for _, n := range []string{req.GetDefaultTablespace(), req.GetDefaultTemporaryTablespace()} {
+		if _, err := sqlq.ObjectName(n); err != nil {
+			return fmt.Errorf("invalid tablespace name %q", n)
+		}
+	}
+	return nil
+}
+
+// defaultTablespaceStatements returns the statements setting the tablespaces
+// of the request which differ from the current ones.
+func defaultTablespaceStatements(req *dbdpb.SetDefaultTablespacesRequest, current *dbdpb.GetDefaultTablespacesResponse) []string {
+	var statements []string
+	for _, ts := range []struct {
+		name    string
+		current string
+		kind    string
+	}{
+		{name: req.GetDefaultTablespace(), current: current.GetDefaultTablespace(), kind: "default tablespace"},
+		{name: req.GetDefaultTemporaryTablespace(), current: current.GetDefaultTemporaryTablespace(), kind: "default temporary tablespace"},
+	} {
+		if ts.name == "" || strings.EqualFold(ts.name, ts.current) {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("alter database %s %s", ts.kind, sqlq.MustBeObjectName(ts.name)))
+	}
+	return statements
+}
+
+func (s *Server) defaultTablespaces(ctx context.Context, pdbName string) (*dbdpb.GetDefaultTablespacesResponse, error) {
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(pdbName), defaultTablespacesSQL},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the default tablespaces: %v", err)
+	}
+	return parseDefaultTablespaces(resp.GetMsg())
+}
+
+// GetDefaultTablespaces returns the default tablespaces of a PDB from
+// database_properties.
+func (s *Server) GetDefaultTablespaces(ctx context.Context, req *dbdpb.GetDefaultTablespacesRequest) (*dbdpb.GetDefaultTablespacesResponse, error) {
+	klog.InfoS("dbdaemon/GetDefaultTablespaces", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/GetDefaultTablespaces: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.defaultTablespaces(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDefaultTablespaces: %v", err)
+	}
+	users, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), systemUsersSQL},
+	}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDefaultTablespaces: failed to query the users: %v", err)
+	}
+	if resp.SystemUsers, err = parseSystemUsers(users.GetMsg()); err != nil {
+		return nil, fmt.Errorf("dbdaemon/GetDefaultTablespaces: %v", err)
+	}
+	return resp, nil
+}
+
+// SetDefaultTablespaces runs the defaultTablespaceStatements of the request.
+func (s *Server) SetDefaultTablespaces(ctx context.Context, req *dbdpb.SetDefaultTablespacesRequest) (*dbdpb.SetDefaultTablespacesResponse, error) {
+	klog.InfoS("dbdaemon/SetDefaultTablespaces", "req", loggableRequest(req))
+	if err := validateDefaultTablespaces(req); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetDefaultTablespaces: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	current, err := s.defaultTablespaces(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetDefaultTablespaces: %v", err)
+	}
+	resp := &dbdpb.SetDefaultTablespacesResponse{
+		DefaultTablespace:          current.GetDefaultTablespace(),
+		DefaultTemporaryTablespace: current.GetDefaultTemporaryTablespace(),
+	}
+	statements := defaultTablespaceStatements(req, current)
+	if len(statements) == 0 {
+		return resp, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...),
+	}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetDefaultTablespaces: failed to set the default tablespaces: %v", err)
+	}
+	resp.AppliedStatements = statements
+	if req.GetDefaultTablespace() != "" {
+		resp.DefaultTablespace = strings.ToUpper(req.GetDefaultTablespace())
+	}
+	if req.GetDefaultTemporaryTablespace() != "" {
+		resp.DefaultTemporaryTablespace = strings.ToUpper(req.GetDefaultTemporaryTablespace())
+	}
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestParseDefaultTablespaces(t *testing.T) {
+	rows := []string{
+		`{"PROPERTY_NAME": "DEFAULT_PERMANENT_TABLESPACE", "PROPERTY_VALUE": "PDB1_USERS"}`,
+		`{"PROPERTY_NAME": "DEFAULT_TEMP_TABLESPACE", "PROPERTY_VALUE": "TEMP"}`,
+	}
+	want := &dbdpb.GetDefaultTablespacesResponse{DefaultTablespace: "PDB1_USERS", DefaultTemporaryTablespace: "TEMP"}
+	got, err := parseDefaultTablespaces(rows)
+	if err != nil {
+		t.Fatalf("parseDefaultTablespaces failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("parseDefaultTablespaces got unexpected tablespaces (-want +got):\n%v", diff)
+	}
+	if _, err := parseDefaultTablespaces(rows[:1]); err == nil {
+		t.Errorf("parseDefaultTablespaces without the temporary tablespace succeeded, want error")
+	}
+
+	users, err := parseSystemUsers([]string{`{"USERNAME": "APP"}`, `{"USERNAME": "SCOTT"}`})
+	if err != nil || !cmp.Equal(users, []string{"APP", "SCOTT"}) {
+		t.Errorf("parseSystemUsers got %v, %v, want [APP SCOTT]", users, err)
+	}
+}
+
+func TestDefaultTablespaceStatements(t *testing.T) {
+	current := &dbdpb.GetDefaultTablespacesResponse{DefaultTablespace: "SYSTEM", DefaultTemporaryTablespace: "TEMP"}
+	tests := []struct {
+		name string
+		req  *dbdpb.SetDefaultTablespacesRequest
+		want []string
+	}{
+		{
+			name: "both",
+			req:  &dbdpb.SetDefaultTablespacesRequest{PdbName: "pdb1", DefaultTablespace: "pdb1_users", DefaultTemporaryTablespace: "temp2"},
+			want: []string{
+				`alter database default tablespace "PDB1_USERS"`,
+				`alter database default temporary tablespace "TEMP2"`,
+			},
+		},
+		{
+			name: "unchanged temporary tablespace",
+			req:  &dbdpb.SetDefaultTablespacesRequest{PdbName: "pdb1", DefaultTablespace: "PDB1_USERS", DefaultTemporaryTablespace: "temp"},
+			want: []string{`alter database default tablespace "PDB1_USERS"`},
+		},
+		{
+			name: "already set",
+			req:  &dbdpb.SetDefaultTablespacesRequest{PdbName: "pdb1", DefaultTablespace: "system"},
+		},
+		{
+			name: "nothing requested",
+			req:  &dbdpb.SetDefaultTablespacesRequest{PdbName: "pdb1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateDefaultTablespaces(tc.req); err != nil {
+				t.Fatalf("validateDefaultTablespaces failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, defaultTablespaceStatements(tc.req, current)); diff != "" {
+				t.Errorf("defaultTablespaceStatements got unexpected statements (-want +got):\n%v", diff)
+			}
+		})
+	}
+
+	for _, req := range []*dbdpb.SetDefaultTablespacesRequest{
+		{DefaultTablespace: "USERS"},
+		{PdbName: "pdb1", DefaultTablespace: `USERS" quota`},
+		{PdbName: "pdb1", DefaultTemporaryTablespace: `TEMP"`},
+	} {
+		if err := validateDefaultTablespaces(req); err == nil {
+			t.Errorf("validateDefaultTablespaces(%v) succeeded, want error", req)
+		}
+	}
+}
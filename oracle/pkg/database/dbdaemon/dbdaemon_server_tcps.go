@@ -0,0 +1,221 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// tcpsListenerName is the listener of consts.ListenerNames serving TCPS.
+	tcpsListenerName = "SSL"
+
+	// autoLoginWallet is the wallet file the listener opens without a
+	// password.
+	autoLoginWallet = "cwallet.sso"
+	// passwordWallet is the PKCS#12 wallet the auto-login one is created
+	// from.
+	passwordWallet = "ewallet.p12"
+	// selfSignedCert is the certificate of a generated wallet, its presence
+	// marks the wallet as generated.
+	selfSignedCert = "self_signed.crt"
+
+	// selfSignedValidityDays is the validity of the certificate of a
+	// generated wallet.
+	selfSignedValidityDays = 3650
+)
+
+// tcpsWalletDir is the wallet directory of the TCPS listener, tests replace
+// it.
+var tcpsWalletDir = consts.WalletDir
+
+// tcpsParams returns the listener.ora and sqlnet.ora entries of a TCPS
+// listener authenticating with the wallet in walletDir. Clients don't
+// present certificates.
+func tcpsParams(walletDir string) []sqlNetParam {
+	return []sqlNetParam{
+		{name: "WALLET_LOCATION", value: fmt.Sprintf("(SOURCE = (METHOD = FILE)(METHOD_DATA = (DIRECTORY = %s)))", walletDir)},
+		{name: "SSL_CLIENT_AUTHENTICATION", value: "FALSE"},
+	}
+}
+
+// tcpsSQLNetParams returns the sqlnet.ora entries of a TCPS listener. TLS
+// encrypts and checksums the connections already, the native network
+// encryption entries of the default sqlnet.ora are removed.
+func tcpsSQLNetParams(walletDir string) []sqlNetParam {
+	return append(tcpsParams(walletDir),
+		sqlNetParam{name: "sqlnet.encryption_server"},
+		sqlNetParam{name: "sqlnet.encryption_types_server"},
+		sqlNetParam{name: "sqlnet.crypto_checksum_server"},
+		sqlNetParam{name: "sqlnet.crypto_checksum_types_server"},
+	)
+}
+
+// walletFileNames returns the sorted names of the wallet files, the wallet
+// itself is never logged.
+func walletFileNames(wallet map[string][]byte) []string {
+	var names []string
+	for name := range wallet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// installWallet writes the files of a user supplied wallet to the wallet
+// directory, replacing the wallet there.
+func (s *Server) installWallet(wallet map[string][]byte) error {
+	if len(wallet[autoLoginWallet]) == 0 {
+		return fmt.Errorf("the wallet has no %s", autoLoginWallet)
+	}
+	for name := range wallet {
+		if name != autoLoginWallet && name != passwordWallet {
+			return fmt.Errorf("unexpected wallet file %q, want %s or %s", name, autoLoginWallet, passwordWallet)
+		}
+	}
+	if err := s.mkdirAllOracle(tcpsWalletDir, 0700); err != nil {
+		return fmt.Errorf("failed to create the wallet dir %s: %v", tcpsWalletDir, err)
+	}
+	var files []string
+	for _, name := range []string{autoLoginWallet, passwordWallet, selfSignedCert} {
+		file := filepath.Join(tcpsWalletDir, name)
+		content, ok := wallet[name]
+		if !ok {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %v", file, err)
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(file, content, filePerm(0600)); err != nil {
+			return fmt.Errorf("failed to write %s: %v", file, err)
+		}
+		files = append(files, file)
+	}
+	return s.chownToOracle(files...)
+}
+
+// generateWallet generates an auto-login wallet with a self-signed
+// certificate for the host in the wallet directory, unless it holds one
+// already. It returns the PEM certificate of the wallet.
+func (s *Server) generateWallet() (string, error) {
+	certFile := filepath.Join(tcpsWalletDir, selfSignedCert)
+	if cert, err := ioutil.ReadFile(certFile); err == nil {
+		if _, err := os.Stat(filepath.Join(tcpsWalletDir, autoLoginWallet)); err == nil {
+			return string(cert), nil
+		}
+	}
+
+	// orapki writes the wallet to a new directory, a failure leaves the
+	// wallet in use alone.
+	newDir := tcpsWalletDir + ".new"
+	if err := os.RemoveAll(newDir); err != nil {
+		return "", fmt.Errorf("failed to remove %s: %v", newDir, err)
+	}
+	defer os.RemoveAll(newDir)
+	if err := s.mkdirAllOracle(newDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", newDir, err)
+	}
+	dn := fmt.Sprintf("CN=%s", s.hostName)
+	for _, args := range [][]string{
+		{"wallet", "create", "-wallet", newDir, "-auto_login_only"},
+		{"wallet", "add", "-wallet", newDir, "-dn", dn, "-keysize", "2048", "-self_signed", "-validity", fmt.Sprint(selfSignedValidityDays), "-auto_login_only"},
+		{"wallet", "export", "-wallet", newDir, "-dn", dn, "-cert", filepath.Join(newDir, selfSignedCert)},
+	} {
+		if err := s.osUtil.runCommand(orapki(s.databaseHome), args); err != nil {
+			return "", fmt.Errorf("orapki %s %s failed: %v", args[0], args[1], err)
+		}
+	}
+
+	if err := s.mkdirAllOracle(tcpsWalletDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create the wallet dir %s: %v", tcpsWalletDir, err)
+	}
+	if err := os.Remove(filepath.Join(tcpsWalletDir, passwordWallet)); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove the %s of the previous wallet: %v", passwordWallet, err)
+	}
+	var files []string
+	for _, name := range []string{autoLoginWallet, selfSignedCert} {
+		file := filepath.Join(tcpsWalletDir, name)
+		if err := os.Rename(filepath.Join(newDir, name), file); err != nil {
+			return "", fmt.Errorf("failed to install the generated %s: %v", name, err)
+		}
+		files = append(files, file)
+	}
+	if err := s.chownToOracle(files...); err != nil {
+		return "", err
+	}
+	cert, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the generated certificate: %v", err)
+	}
+	klog.InfoS("dbdaemon/generateWallet: generated a self-signed wallet", "dir", tcpsWalletDir, "dn", dn)
+	return string(cert), nil
+}
+
+// ConfigureTCPSListener installs the wallet of the TCPS listener and
+// creates the listener. The wallet is supplied by the request, or generated
+// with a self-signed certificate once and kept until the request supplies
+// one.
+func (s *Server) ConfigureTCPSListener(ctx context.Context, req *dbdpb.ConfigureTCPSListenerRequest) (*dbdpb.ConfigureTCPSListenerResponse, error) {
+	klog.InfoS("dbdaemon/ConfigureTCPSListener", "databaseName", req.GetDatabaseName(), "port", req.GetPort(), "walletFiles", walletFileNames(req.GetWallet()), "disable", req.GetDisable())
+	lDir := filepath.Join(listenerDir(), tcpsListenerName)
+	if req.GetDisable() {
+		if _, err := s.BounceListener(ctx, &dbdpb.BounceListenerRequest{
+			Operation:    dbdpb.BounceListenerRequest_STOP,
+			ListenerName: tcpsListenerName,
+			TnsAdmin:     lDir,
+		}); err != nil {
+			klog.ErrorS(err, "Listener stop failed", "name", tcpsListenerName, "lDir", lDir)
+		}
+		if err := os.RemoveAll(lDir); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureTCPSListener: failed to remove %s: %v", lDir, err)
+		}
+		return &dbdpb.ConfigureTCPSListenerResponse{}, nil
+	}
+
+	resp := &dbdpb.ConfigureTCPSListenerResponse{}
+	if len(req.GetWallet()) > 0 {
+		if err := s.installWallet(req.GetWallet()); err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureTCPSListener: failed to install the wallet: %v", err)
+		}
+	} else {
+		cert, err := s.generateWallet()
+		if err != nil {
+			return nil, fmt.Errorf("dbdaemon/ConfigureTCPSListener: failed to generate a wallet: %v", err)
+		}
+		resp.Certificate = cert
+	}
+
+	if _, err := s.CreateListener(ctx, &dbdpb.CreateListenerRequest{
+		DatabaseName: req.GetDatabaseName(),
+		Port:         req.GetPort(),
+		Protocol:     "TCPS",
+		DbDomain:     req.GetDbDomain(),
+		ListenerName: tcpsListenerName,
+		WalletDir:    tcpsWalletDir,
+	}); err != nil {
+		return nil, fmt.Errorf("dbdaemon/ConfigureTCPSListener: %v", err)
+	}
+	return resp, nil
+}
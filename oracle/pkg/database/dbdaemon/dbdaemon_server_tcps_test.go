@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// orapkiOsUtil creates the files orapki would in the wallet directory.
+type orapkiOsUtil struct {
+	mockOsUtil
+	args [][]string
+}
+
+func (o *orapkiOsUtil) runCommand(bin string, params []string) error {
+	o.args = append(o.args, params)
+	switch params[1] {
+	case "create":
+		return ioutil.WriteFile(filepath.Join(params[3], autoLoginWallet), []byte("generated"), 0600)
+	case "export":
+		return ioutil.WriteFile(params[len(params)-1], []byte("CERT"), 0600)
+	}
+	return nil
+}
+
+func newTCPSServer(t *testing.T) (*Server, *orapkiOsUtil) {
+	t.Helper()
+	useOracleOwner(t, os.Getuid(), os.Getgid())
+	origWalletDir := tcpsWalletDir
+	tcpsWalletDir = filepath.Join(t.TempDir(), "wallet")
+	t.Cleanup(func() { tcpsWalletDir = origWalletDir })
+	osUtil := &orapkiOsUtil{}
+	return &Server{hostName: "mydb-sts-0", osUtil: osUtil}, osUtil
+}
+
+func TestGenerateWallet(t *testing.T) {
+	s, osUtil := newTCPSServer(t)
+	cert, err := s.generateWallet()
+	if err != nil {
+		t.Fatalf("generateWallet failed: %v", err)
+	}
+	if cert != "CERT" {
+		t.Errorf("generateWallet got certificate %q, want %q", cert, "CERT")
+	}
+	if len(osUtil.args) != 3 || !strings.Contains(strings.Join(osUtil.args[1], " "), "-dn CN=mydb-sts-0") {
+		t.Errorf("generateWallet ran orapki with %v, want a self-signed certificate for the host", osUtil.args)
+	}
+	if _, err := os.Stat(tcpsWalletDir + ".new"); !os.IsNotExist(err) {
+		t.Errorf("generateWallet left its staging dir behind: %v", err)
+	}
+
+	// The generated wallet is kept.
+	if cert, err := s.generateWallet(); err != nil || cert != "CERT" {
+		t.Errorf("generateWallet again got (%q, %v), want the generated certificate", cert, err)
+	}
+	if len(osUtil.args) != 3 {
+		t.Errorf("generateWallet ran orapki again for a generated wallet")
+	}
+}
+
+func TestInstallWallet(t *testing.T) {
+	s, _ := newTCPSServer(t)
+	if _, err := s.generateWallet(); err != nil {
+		t.Fatalf("generateWallet failed: %v", err)
+	}
+	if err := s.installWallet(map[string][]byte{autoLoginWallet: []byte("user")}); err != nil {
+		t.Fatalf("installWallet failed: %v", err)
+	}
+	if got, _ := ioutil.ReadFile(filepath.Join(tcpsWalletDir, autoLoginWallet)); string(got) != "user" {
+		t.Errorf("installWallet installed %s %q, want %q", autoLoginWallet, got, "user")
+	}
+	if _, err := os.Stat(filepath.Join(tcpsWalletDir, selfSignedCert)); !os.IsNotExist(err) {
+		t.Errorf("installWallet kept the certificate of the generated wallet: %v", err)
+	}
+
+	for _, wallet := range []map[string][]byte{
+		{passwordWallet: []byte("user")},
+		{autoLoginWallet: []byte("user"), "tls.key": []byte("key")},
+	} {
+		if err := s.installWallet(wallet); err == nil {
+			t.Errorf("installWallet(%v) succeeded, want an error", walletFileNames(wallet))
+		}
+	}
+}
+
+func TestTCPSSQLNetParams(t *testing.T) {
+	content := "sqlnet.encryption_server = REQUIRED\nsqlnet.crypto_checksum_server = REQUIRED\nsqlnet.expire_time = 15\n"
+	want := "sqlnet.expire_time = 15\n" +
+		"WALLET_LOCATION = (SOURCE = (METHOD = FILE)(METHOD_DATA = (DIRECTORY = /u02/app/oracle/wallet)))\n" +
+		"SSL_CLIENT_AUTHENTICATION = FALSE\n"
+	if got := updateSQLNet(content, tcpsSQLNetParams("/u02/app/oracle/wallet")); got != want {
+		t.Errorf("updateSQLNet with the TCPS params got %q, want %q", got, want)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/test/bufconn"
 
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
 	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
@@ -40,6 +41,7 @@ func TestServerCreateDirs(t *testing.T) {
 	ctx := context.Background()
 	client, cleanup := newFakeDatabaseDaemonClient(t)
 	defer cleanup()
+	useOracleOwner(t, os.Getuid(), os.Getgid())
 
 	oldMask := syscall.Umask(0022)
 	testDir, err := ioutil.TempDir("", "TestServerCreateDir")
@@ -356,7 +358,7 @@ func newFakeDatabaseDaemonClient(t *testing.T) (dbdpb.DatabaseDaemonClient, func
 	t.Helper()
 	grpcSvr := grpc.NewServer()
 
-	dbdpb.RegisterDatabaseDaemonServer(grpcSvr, &Server{})
+	dbdpb.RegisterDatabaseDaemonServer(grpcSvr, &Server{osUtil: &osUtilImpl{}})
 	lis := bufconn.Listen(2 * 1024 * 1024)
 	go grpcSvr.Serve(lis)
 
@@ -417,14 +419,16 @@ func (m mockDB) runSQL(ctx context.Context, i []string, b bool, b2 bool, databas
 	panic("implement me")
 }
 
-func (m mockDB) runQuery(ctx context.Context, i []string, database oracleDatabase) ([]string, error) {
+func (m mockDB) runQuery(ctx context.Context, i []string, b bool, database oracleDatabase) ([]string, error) {
 	panic("implement me")
 }
 
 // Mock dbdaemon_proxy client
 type mockDatabaseDaemonProxyClient struct {
-	startupCount  int
-	shutdownCount int
+	startupCount          int
+	shutdownCount         int
+	listenerStartupCount  int
+	listenerShutdownCount int
 }
 
 func (m *mockDatabaseDaemonProxyClient) BounceDatabase(ctx context.Context, in *dbdpb.BounceDatabaseRequest, opts ...grpc.CallOption) (*dbdpb.BounceDatabaseResponse, error) {
@@ -437,7 +441,12 @@ func (m *mockDatabaseDaemonProxyClient) BounceDatabase(ctx context.Context, in *
 }
 
 func (m *mockDatabaseDaemonProxyClient) BounceListener(ctx context.Context, in *dbdpb.BounceListenerRequest, opts ...grpc.CallOption) (*dbdpb.BounceListenerResponse, error) {
-	panic("implement me")
+	if in.Operation == dbdpb.BounceListenerRequest_START {
+		m.listenerStartupCount++
+	} else if in.Operation == dbdpb.BounceListenerRequest_STOP {
+		m.listenerShutdownCount++
+	}
+	return new(dbdpb.BounceListenerResponse), nil
 }
 
 func (m *mockDatabaseDaemonProxyClient) ProxyRunDbca(ctx context.Context, in *dbdpb.ProxyRunDbcaRequest, opts ...grpc.CallOption) (*dbdpb.ProxyRunDbcaResponse, error) {
@@ -463,6 +472,10 @@ func (m *mockDatabaseDaemonProxyClient) SetDnfsState(ctx context.Context, in *db
 // Mock osUtil
 type mockOsUtil struct {
 	commands []string
+	// created are the files created and chowned maps the chowned paths
+	// to their uid:gid.
+	created []string
+	chowned map[string]string
 }
 
 func (m *mockOsUtil) runCommand(bin string, params []string) error {
@@ -475,13 +488,22 @@ func (m *mockOsUtil) isReturnCodeEqual(err error, code int) bool {
 }
 
 func (m *mockOsUtil) createFile(file string, content io.Reader) error {
-	panic("implement me")
+	m.created = append(m.created, file)
+	return nil
 }
 
 func (m *mockOsUtil) removeFile(file string) error {
 	panic("implement me")
 }
 
+func (m *mockOsUtil) chown(path string, uid, gid int) error {
+	if m.chowned == nil {
+		m.chowned = make(map[string]string)
+	}
+	m.chowned[path] = fmt.Sprintf("%d:%d", uid, gid)
+	return nil
+}
+
 func NewMockServer(ctx context.Context, cdbNameFromYaml string) (*Server, error) {
 
 	s := &Server{
@@ -494,6 +516,7 @@ func NewMockServer(ctx context.Context, cdbNameFromYaml string) (*Server, error)
 		lroServer:      nil,
 		syncJobs:       &syncJobs{},
 		gcsUtil:        &util.GCSUtilImpl{},
+		operationLogs:  &operationLogs{},
 	}
 	s.databaseHome = "DBHOME"
 	s.databaseSid.val = "MOCK_DB"
@@ -527,3 +550,100 @@ func TestApplyDataPatch(t *testing.T) {
 	}
 
 }
+
+func TestRunRMANSingleSession(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+
+	// The fake rman logs each invocation and echoes its script.
+	dir := t.TempDir()
+	invocations := filepath.Join(dir, "invocations")
+	fakeRMAN := filepath.Join(dir, "rman")
+	if err := ioutil.WriteFile(fakeRMAN, []byte(fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\ncat\n", invocations)), 0755); err != nil {
+		t.Fatalf("failed to create fake rman: %v", err)
+	}
+	origRMAN := rman
+	rman = func(string) string { return fakeRMAN }
+	defer func() { rman = origRMAN }()
+
+	scripts := []string{"set encryption on;", "backup database;"}
+	for _, tc := range []struct {
+		name            string
+		singleSession   bool
+		wantInvocations int
+		wantOutput      []string
+	}{
+		{
+			name:            "session per script",
+			wantInvocations: 2,
+			wantOutput:      scripts,
+		},
+		{
+			name:            "single session",
+			singleSession:   true,
+			wantInvocations: 1,
+			wantOutput:      []string{"set encryption on;\nbackup database;"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := os.RemoveAll(invocations); err != nil {
+				t.Fatalf("failed to reset invocations: %v", err)
+			}
+			resp, err := s.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: scripts, SingleSession: tc.singleSession})
+			if err != nil {
+				t.Fatalf("RunRMAN failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantOutput, resp.GetOutput()); diff != "" {
+				t.Errorf("RunRMAN got unexpected output (-want +got):\n%v", diff)
+			}
+			log, err := ioutil.ReadFile(invocations)
+			if err != nil {
+				t.Fatalf("failed to read invocations: %v", err)
+			}
+			if got := strings.Count(string(log), "target=/ @/dev/stdin\n"); got != tc.wantInvocations {
+				t.Errorf("RunRMAN invoked rman %d times, want %d", got, tc.wantInvocations)
+			}
+		})
+	}
+}
+
+func TestRMANUploadDir(t *testing.T) {
+	tests := []struct {
+		localPath string
+		want      string
+	}{
+		{localPath: "", want: consts.RMANStagingDir},
+		{localPath: "/u04/app/oracle/rman", want: consts.RMANStagingDir},
+		{localPath: consts.RMANStagingDir, want: consts.RMANStagingDir},
+		{localPath: consts.RMANStagingDir + "/../rman", want: consts.RMANStagingDir},
+		{localPath: consts.RMANStagingDir + "/archlog_20220501t010000/", want: consts.RMANStagingDir + "/archlog_20220501t010000"},
+	}
+	for _, tc := range tests {
+		if got := rmanUploadDir(&dbdpb.RunRMANRequest{LocalPath: tc.localPath}); got != tc.want {
+			t.Errorf("rmanUploadDir(%q) = %q, want %q", tc.localPath, got, tc.want)
+		}
+	}
+}
+
+func TestDataPumpImportParams(t *testing.T) {
+	params := []string{
+		"FULL=YES",
+		"NETWORK_LINK=SRC_LINK",
+		"REMAP_SCHEMA=HR:HR_TEST",
+		"EXCLUDE=SCHEMA:\"='SCOTT'\"",
+		"TABLE_EXISTS_ACTION=REPLACE",
+	}
+	want := []string{"FULL=YES", "NETWORK_LINK=SRC_LINK", "REMAP_SCHEMA=HR:HR_TEST"}
+	if diff := cmp.Diff(want, filterParamsForMetadata(params)); diff != "" {
+		t.Errorf("filterParamsForMetadata got unexpected params (-want +got):\n%v", diff)
+	}
+	if !hasNetworkLink(params) {
+		t.Errorf("hasNetworkLink(%q) = false, want true", params)
+	}
+	if hasNetworkLink([]string{"FULL=YES", "REMAP_SCHEMA=NETWORK_LINK:X"}) {
+		t.Errorf("hasNetworkLink of a dump file import = true, want false")
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// managedTriggerPrefix namespaces the triggers owned by the operator,
+	// which are created in the SYS schema.
+	managedTriggerPrefix = "ELCARRO_TRG_"
+
+	managedTriggersSQL = "select trigger_name, trigger_type, triggering_event, table_owner, table_name, status from dba_triggers " +
+		`where owner='SYS' and trigger_name like 'ELCARRO\_TRG\_%' escape '\' order by trigger_name`
+	managedTriggerStatusSQL = "select status from dba_objects where owner='SYS' and object_type='TRIGGER' and object_name='%s'"
+	managedTriggerErrorsSQL = "select line, text from dba_errors where owner='SYS' and type='TRIGGER' and name='%s' order by sequence"
+)
+
+var (
+	managedTriggerNameRE = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,115}$`)
+
+	// dmlEvents are the events of the DML triggers.
+	dmlEvents = map[string]bool{"INSERT": true, "UPDATE": true, "DELETE": true}
+	// databaseEvents are the events of the database triggers mapped to the
+	// timing they allow, an empty timing allows both.
+	databaseEvents = map[string]string{
+		"LOGON":       "AFTER",
+		"LOGOFF":      "BEFORE",
+		"SERVERERROR": "AFTER",
+		"DDL":         "",
+		"CREATE":      "",
+		"ALTER":       "",
+		"DROP":        "",
+		"TRUNCATE":    "",
+		"RENAME":      "",
+		"GRANT":       "",
+		"REVOKE":      "",
+	}
+)
+
+// managedTriggerName returns the name of a managed trigger, with the
+// ELCARRO_TRG_ prefix.
+func managedTriggerName(name string) (string, error) {
+	name = strings.TrimPrefix(strings.ToUpper(name), managedTriggerPrefix)
+	if !managedTriggerNameRE.MatchString(name) {
+		return "", fmt.Errorf("invalid trigger name %q", name)
+	}
+	return managedTriggerPrefix + name, nil
+}
+
+// createManagedTriggerStatement returns the statement creating or replacing
+// a managed trigger.
+func createManagedTriggerStatement(req *dbdpb.CreateManagedTriggerRequest) (string, error) {
+	name, err := managedTriggerName(req.GetName())
+	if err != nil {
+		return "", err
+	}
+	timing := strings.ToUpper(req.GetTiming())
+	if timing != "BEFORE" && timing != "AFTER" {
+		return "", fmt.Errorf("invalid timing %q of trigger %s, want BEFORE or AFTER", req.GetTiming(), name)
+	}
+	if len(req.GetEvents()) == 0 {
+		return "", fmt.Errorf("trigger %s has no event", name)
+	}
+	body := strings.TrimSpace(req.GetBody())
+	if body == "" {
+		return "", fmt.Errorf("trigger %s has no body", name)
+	}
+
+	var events []string
+	target := "database"
+	if req.GetTable() == "" {
+		if req.GetForEachRow() {
+			return "", fmt.Errorf("database trigger %s can't be a row level trigger", name)
+		}
+		for _, e := range req.GetEvents() {
+			e = strings.ToUpper(e)
+			t, ok := databaseEvents[e]
+			if !ok {
+				return "", fmt.Errorf("unsupported event %q of database trigger %s", e, name)
+			}
+			if t != "" && t != timing {
+				return "", fmt.Errorf("event %s of trigger %s requires %s timing", e, name, t)
+			}
+			events = append(events, e)
+		}
+	} else {
+		table, err := splitName(req.GetTable(), 2)
+		if err != nil {
+			return "", fmt.Errorf("table of trigger %s isn't of the form OWNER.TABLE: %v", name, err)
+		}
+		target = sqlq.MustBeIdentifier(table[0]) + "." + sqlq.MustBeIdentifier(table[1])
+		for _, e := range req.GetEvents() {
+			e = strings.ToUpper(e)
+			if !dmlEvents[e] {
+				return "", fmt.Errorf("unsupported event %q of DML trigger %s", e, name)
+			}
+			events = append(events, e)
+		}
+		if req.GetForEachRow() {
+			target += " for each row"
+		}
+	}
+	return fmt.Sprintf("create or replace trigger sys.%s %s %s on %s\n%s", sqlq.MustBeIdentifier(name), timing, strings.Join(events, " or "), target, body), nil
+}
+
+// parseManagedTriggers converts the managedTriggersSQL rows.
+func parseManagedTriggers(rows []map[string]string) []*dbdpb.ListManagedTriggersResponse_Trigger {
+	var triggers []*dbdpb.ListManagedTriggersResponse_Trigger
+	for _, row := range rows {
+		t := &dbdpb.ListManagedTriggersResponse_Trigger{
+			Name:            row["TRIGGER_NAME"],
+			TriggerType:     row["TRIGGER_TYPE"],
+			TriggeringEvent: strings.TrimSpace(row["TRIGGERING_EVENT"]),
+			Status:          row["STATUS"],
+		}
+		if row["TABLE_NAME"] != "" && row["TABLE_OWNER"] != "" {
+			t.Table = row["TABLE_OWNER"] + "." + row["TABLE_NAME"]
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers
+}
+
+// managedTriggers returns the managed triggers of a PDB by name.
+func (s *Server) managedTriggers(ctx context.Context, pdbName string) (map[string]*dbdpb.ListManagedTriggersResponse_Trigger, []*dbdpb.ListManagedTriggersResponse_Trigger, error) {
+	rows, err := s.rlsQuery(ctx, pdbName, managedTriggersSQL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query the managed triggers: %v", err)
+	}
+	triggers := parseManagedTriggers(rows)
+	byName := make(map[string]*dbdpb.ListManagedTriggersResponse_Trigger, len(triggers))
+	for _, t := range triggers {
+		byName[t.GetName()] = t
+	}
+	return byName, triggers, nil
+}
+
+// CreateManagedTrigger creates or replaces a managed trigger in the SYS
+// schema of a PDB. A trigger which doesn't compile is dropped, as an invalid
+// logon trigger would fail the logons.
+func (s *Server) CreateManagedTrigger(ctx context.Context, req *dbdpb.CreateManagedTriggerRequest) (*dbdpb.CreateManagedTriggerResponse, error) {
+	klog.InfoS("dbdaemon/CreateManagedTrigger", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: invalid PDB name %q", req.GetPdbName())
+	}
+	stmt, err := createManagedTriggerStatement(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: %v", err)
+	}
+	name, _ := managedTriggerName(req.GetName())
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	existing, _, err := s.managedTriggers(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: %v", err)
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), stmt},
+	}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: failed to create trigger %s: %v", name, err)
+	}
+	rows, err := s.rlsQuery(ctx, req.GetPdbName(), fmt.Sprintf(managedTriggerStatusSQL, name))
+	if err != nil || len(rows) != 1 {
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: failed to query the status of trigger %s: %v", name, err)
+	}
+	if rows[0]["STATUS"] != "VALID" {
+		var errs []string
+		if rows, err := s.rlsQuery(ctx, req.GetPdbName(), fmt.Sprintf(managedTriggerErrorsSQL, name)); err == nil {
+			for _, row := range rows {
+				errs = append(errs, fmt.Sprintf("line %s: %s", row["LINE"], strings.TrimSpace(row["TEXT"])))
+			}
+		}
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+			Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), "drop trigger sys." + sqlq.MustBeIdentifier(name)},
+		}, false); err != nil {
+			klog.ErrorS(err, "dbdaemon/CreateManagedTrigger: failed to drop invalid trigger", "trigger", name)
+		}
+		return nil, fmt.Errorf("dbdaemon/CreateManagedTrigger: trigger %s is %s and was dropped: %s", name, rows[0]["STATUS"], strings.Join(errs, "; "))
+	}
+	_, replaced := existing[name]
+	klog.InfoS("dbdaemon/CreateManagedTrigger: done", "trigger", name, "replaced", replaced)
+	return &dbdpb.CreateManagedTriggerResponse{TriggerName: name, Replaced: replaced}, nil
+}
+
+// DropManagedTrigger drops a managed trigger of a PDB, it does nothing if
+// the trigger doesn't exist.
+func (s *Server) DropManagedTrigger(ctx context.Context, req *dbdpb.DropManagedTriggerRequest) (*dbdpb.DropManagedTriggerResponse, error) {
+	klog.InfoS("dbdaemon/DropManagedTrigger", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/DropManagedTrigger: invalid PDB name %q", req.GetPdbName())
+	}
+	name, err := managedTriggerName(req.GetName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/DropManagedTrigger: %v", err)
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	existing, _, err := s.managedTriggers(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/DropManagedTrigger: %v", err)
+	}
+	if _, ok := existing[name]; !ok {
+		return &dbdpb.DropManagedTriggerResponse{}, nil
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{
+		Commands: []string{sqlq.QuerySetSessionContainer(req.GetPdbName()), "drop trigger sys." + sqlq.MustBeIdentifier(name)},
+	}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/DropManagedTrigger: failed to drop trigger %s: %v", name, err)
+	}
+	klog.InfoS("dbdaemon/DropManagedTrigger: done", "trigger", name)
+	return &dbdpb.DropManagedTriggerResponse{Dropped: true}, nil
+}
+
+// ListManagedTriggers lists the managed triggers of a PDB.
+func (s *Server) ListManagedTriggers(ctx context.Context, req *dbdpb.ListManagedTriggersRequest) (*dbdpb.ListManagedTriggersResponse, error) {
+	klog.InfoS("dbdaemon/ListManagedTriggers", "req", loggableRequest(req))
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("dbdaemon/ListManagedTriggers: invalid PDB name %q", req.GetPdbName())
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	_, triggers, err := s.managedTriggers(ctx, req.GetPdbName())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/ListManagedTriggers: %v", err)
+	}
+	return &dbdpb.ListManagedTriggersResponse{Triggers: triggers}, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestCreateManagedTriggerStatement(t *testing.T) {
+	testCases := []struct {
+		name    string
+		req     *dbdpb.CreateManagedTriggerRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "logon trigger",
+			req: &dbdpb.CreateManagedTriggerRequest{
+				Name:   "maintenance_mode",
+				Timing: "after",
+				Events: []string{"logon"},
+				Body:   "begin\n  raise_application_error(-20001, 'maintenance');\nend;\n",
+			},
+			want: "create or replace trigger sys.\"ELCARRO_TRG_MAINTENANCE_MODE\" AFTER LOGON on database\n" +
+				"begin\n  raise_application_error(-20001, 'maintenance');\nend;",
+		},
+		{
+			name: "prefixed name",
+			req: &dbdpb.CreateManagedTriggerRequest{
+				Name:   "ELCARRO_TRG_AUDIT_DDL",
+				Timing: "BEFORE",
+				Events: []string{"DDL"},
+				Body:   "begin null; end;",
+			},
+			want: "create or replace trigger sys.\"ELCARRO_TRG_AUDIT_DDL\" BEFORE DDL on database\nbegin null; end;",
+		},
+		{
+			name: "row level DML trigger",
+			req: &dbdpb.CreateManagedTriggerRequest{
+				Name:       "audit_orders",
+				Timing:     "AFTER",
+				Events:     []string{"insert", "update"},
+				Table:      "app.orders",
+				ForEachRow: true,
+				Body:       "begin null; end;",
+			},
+			want: "create or replace trigger sys.\"ELCARRO_TRG_AUDIT_ORDERS\" AFTER INSERT or UPDATE on \"APP\".\"ORDERS\" for each row\nbegin null; end;",
+		},
+		{
+			name:    "logon before",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: "t", Timing: "BEFORE", Events: []string{"LOGON"}, Body: "begin null; end;"},
+			wantErr: true,
+		},
+		{
+			name:    "DML event on database",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: "t", Timing: "AFTER", Events: []string{"INSERT"}, Body: "begin null; end;"},
+			wantErr: true,
+		},
+		{
+			name:    "row level database trigger",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: "t", Timing: "AFTER", Events: []string{"DDL"}, ForEachRow: true, Body: "begin null; end;"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: `t" after logon`, Timing: "AFTER", Events: []string{"LOGON"}, Body: "begin null; end;"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid table",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: "t", Timing: "AFTER", Events: []string{"DELETE"}, Table: "orders", Body: "begin null; end;"},
+			wantErr: true,
+		},
+		{
+			name:    "no body",
+			req:     &dbdpb.CreateManagedTriggerRequest{Name: "t", Timing: "AFTER", Events: []string{"LOGON"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := createManagedTriggerStatement(tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("createManagedTriggerStatement(%v) got error %v, want error %v", tc.req, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("createManagedTriggerStatement(%v) = %q, want %q", tc.req, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestManagedTriggerLifecycle(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	db := useFakeSQLDB(s)
+	triggers := map[string]string{}
+	status := "VALID"
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if len(sqls) != 2 || sqls[0] != `alter session set container="PDB1"` {
+			return nil, fmt.Errorf("unexpected query %q", sqls)
+		}
+		switch {
+		case sqls[1] == managedTriggersSQL:
+			var rows []string
+			for name, event := range triggers {
+				rows = append(rows, fmt.Sprintf(`{"TRIGGER_NAME":%q,"TRIGGER_TYPE":"AFTER EVENT","TRIGGERING_EVENT":"%s ","TABLE_OWNER":"","TABLE_NAME":"","STATUS":"ENABLED"}`, name, event))
+			}
+			return rows, nil
+		case strings.Contains(sqls[1], "from dba_objects"):
+			return []string{fmt.Sprintf(`{"STATUS":%q}`, status)}, nil
+		case strings.Contains(sqls[1], "from dba_errors"):
+			return []string{`{"LINE":"2","TEXT":"PLS-00201: identifier 'X' must be declared"}`}, nil
+		}
+		return nil, fmt.Errorf("unexpected query %q", sqls)
+	}
+	var got []string
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		got = append(got, sqls[1:]...)
+		for _, stmt := range sqls[1:] {
+			switch {
+			case strings.HasPrefix(stmt, "create or replace trigger"):
+				triggers["ELCARRO_TRG_MAINTENANCE_MODE"] = "LOGON"
+			case strings.HasPrefix(stmt, "drop trigger"):
+				delete(triggers, "ELCARRO_TRG_MAINTENANCE_MODE")
+			}
+		}
+		return nil, nil
+	}
+	createReq := &dbdpb.CreateManagedTriggerRequest{
+		PdbName: "pdb1",
+		Name:    "maintenance_mode",
+		Timing:  "AFTER",
+		Events:  []string{"LOGON"},
+		Body:    "begin null; end;",
+	}
+
+	resp, err := s.CreateManagedTrigger(ctx, createReq)
+	if err != nil {
+		t.Fatalf("CreateManagedTrigger failed: %v", err)
+	}
+	if diff := cmp.Diff(&dbdpb.CreateManagedTriggerResponse{TriggerName: "ELCARRO_TRG_MAINTENANCE_MODE"}, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("CreateManagedTrigger got unexpected response (-want +got):\n%v", diff)
+	}
+	if resp, err := s.CreateManagedTrigger(ctx, createReq); err != nil || !resp.GetReplaced() {
+		t.Errorf("CreateManagedTrigger of an existing trigger = %v, %v, want a replaced trigger", resp, err)
+	}
+
+	list, err := s.ListManagedTriggers(ctx, &dbdpb.ListManagedTriggersRequest{PdbName: "pdb1"})
+	if err != nil {
+		t.Fatalf("ListManagedTriggers failed: %v", err)
+	}
+	wantList := &dbdpb.ListManagedTriggersResponse{
+		Triggers: []*dbdpb.ListManagedTriggersResponse_Trigger{
+			{Name: "ELCARRO_TRG_MAINTENANCE_MODE", TriggerType: "AFTER EVENT", TriggeringEvent: "LOGON", Status: "ENABLED"},
+		},
+	}
+	if diff := cmp.Diff(wantList, list, protocmp.Transform()); diff != "" {
+		t.Errorf("ListManagedTriggers got unexpected response (-want +got):\n%v", diff)
+	}
+
+	got = nil
+	drop, err := s.DropManagedTrigger(ctx, &dbdpb.DropManagedTriggerRequest{PdbName: "pdb1", Name: "ELCARRO_TRG_MAINTENANCE_MODE"})
+	if err != nil || !drop.GetDropped() {
+		t.Fatalf("DropManagedTrigger = %v, %v, want a dropped trigger", drop, err)
+	}
+	if diff := cmp.Diff([]string{`drop trigger sys."ELCARRO_TRG_MAINTENANCE_MODE"`}, got); diff != "" {
+		t.Errorf("DropManagedTrigger got unexpected statements (-want +got):\n%v", diff)
+	}
+	got = nil
+	if drop, err := s.DropManagedTrigger(ctx, &dbdpb.DropManagedTriggerRequest{PdbName: "pdb1", Name: "maintenance_mode"}); err != nil || drop.GetDropped() || got != nil {
+		t.Errorf("DropManagedTrigger of a missing trigger = %v, %v and ran %q, want nothing dropped", drop, err, got)
+	}
+
+	status = "INVALID"
+	got = nil
+	if _, err := s.CreateManagedTrigger(ctx, createReq); err == nil || !strings.Contains(err.Error(), "PLS-00201") {
+		t.Errorf("CreateManagedTrigger of an invalid trigger got error %v, want the compilation errors", err)
+	}
+	if len(triggers) != 0 || len(got) != 2 || !strings.HasPrefix(got[1], "drop trigger") {
+		t.Errorf("CreateManagedTrigger of an invalid trigger ran %q, want the trigger dropped", got)
+	}
+}
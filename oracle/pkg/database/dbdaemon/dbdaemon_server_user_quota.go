@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// userQuotaStatements returns the statements setting the quotas of the
+// request in its PDB.
+func userQuotaStatements(req *dbdpb.SetUserQuotaRequest) ([]string, error) {
+	if _, err := sqlq.ObjectName(req.GetPdbName()); err != nil || req.GetPdbName() == "" {
+		return nil, fmt.Errorf("invalid PDB name %q", req.GetPdbName())
+	}
+	userName, err := sqlq.ObjectName(req.GetUserName())
+	if err != nil || req.GetUserName() == "" {
+		return nil, fmt.Errorf("invalid user name %q", req.GetUserName())
+	}
+	var statements []string
+	for _, q := range req.GetQuotas() {
+		tablespace, err := sqlq.ObjectName(q.GetTablespace())
+		if err != nil || q.GetTablespace() == "" {
+			return nil, fmt.Errorf("invalid tablespace name %q", q.GetTablespace())
+		}
+		switch {
+		case q.GetMaxBytes() == -1:
+			statements = append(statements, fmt.Sprintf("alter user %s quota unlimited on %s", userName, tablespace))
+		case q.GetMaxBytes() >= 0:
+			statements = append(statements, fmt.Sprintf("alter user %s quota %d on %s", userName, q.GetMaxBytes(), tablespace))
+		default:
+			return nil, fmt.Errorf("invalid quota of %d bytes on tablespace %s", q.GetMaxBytes(), q.GetTablespace())
+		}
+	}
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	return append([]string{sqlq.QuerySetSessionContainer(req.GetPdbName())}, statements...), nil
+}
+
+// SetUserQuota runs the userQuotaStatements of the request.
+func (s *Server) SetUserQuota(ctx context.Context, req *dbdpb.SetUserQuotaRequest) (*dbdpb.SetUserQuotaResponse, error) {
+	klog.InfoS("dbdaemon/SetUserQuota", "req", loggableRequest(req))
+	statements, err := userQuotaStatements(req)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetUserQuota: %v", err)
+	}
+	if len(statements) == 0 {
+		return &dbdpb.SetUserQuotaResponse{}, nil
+	}
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: statements}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/SetUserQuota: failed to set the quotas of user %s: %v", req.GetUserName(), err)
+	}
+	return &dbdpb.SetUserQuotaResponse{AppliedStatements: statements[1:]}, nil
+}
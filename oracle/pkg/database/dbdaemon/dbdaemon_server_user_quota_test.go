@@ -0,0 +1,77 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestUserQuotaStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *dbdpb.SetUserQuotaRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "quotas",
+			req: &dbdpb.SetUserQuotaRequest{PdbName: "pdb1", UserName: "scott", Quotas: []*dbdpb.SetUserQuotaRequest_Quota{
+				{Tablespace: "pdb1_users", MaxBytes: 10 << 20},
+				{Tablespace: "DATA", MaxBytes: -1},
+				{Tablespace: "SYSTEM"},
+			}},
+			want: []string{
+				`alter session set container="PDB1"`,
+				`alter user "SCOTT" quota 10485760 on "PDB1_USERS"`,
+				`alter user "SCOTT" quota unlimited on "DATA"`,
+				`alter user "SCOTT" quota 0 on "SYSTEM"`,
+			},
+		},
+		{
+			name: "no quotas",
+			req:  &dbdpb.SetUserQuotaRequest{PdbName: "pdb1", UserName: "scott"},
+		},
+		{
+			name:    "missing user",
+			req:     &dbdpb.SetUserQuotaRequest{PdbName: "pdb1", Quotas: []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: "USERS"}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid tablespace",
+			req:     &dbdpb.SetUserQuotaRequest{PdbName: "pdb1", UserName: "scott", Quotas: []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: `USERS"`}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative quota",
+			req:     &dbdpb.SetUserQuotaRequest{PdbName: "pdb1", UserName: "scott", Quotas: []*dbdpb.SetUserQuotaRequest_Quota{{Tablespace: "USERS", MaxBytes: -2}}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := userQuotaStatements(tc.req)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("userQuotaStatements got error %v, want error: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("userQuotaStatements got unexpected statements (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	sqlq "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/common/sql"
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// keystoreSQL reports the keystore of the CDB root, or of a non-CDB, which
+// PDBs in united mode share.
+const keystoreSQL = "select wrl_parameter, wallet_type, status from v$encryption_wallet where con_id in (0, 1)"
+
+// keystoreBackupTag identifies the keystore backups the password rotation
+// leaves in the keystore directory.
+const keystoreBackupTag = "password_rotation"
+
+// keystore is the keystore of the database as reported by keystoreSQL.
+type keystore struct {
+	location string
+	// walletType is PASSWORD, AUTOLOGIN or LOCAL_AUTOLOGIN for an open
+	// keystore, UNKNOWN for a closed one.
+	walletType string
+	status     string
+}
+
+// keystoreFromRow converts a row with the keystoreSQL columns into a
+// keystore.
+func keystoreFromRow(row map[string]string) keystore {
+	return keystore{location: row["WRL_PARAMETER"], walletType: row["WALLET_TYPE"], status: row["STATUS"]}
+}
+
+// parseKeystore converts the keystoreSQL rows into the keystore of the
+// database.
+func parseKeystore(rows []string) (*keystore, error) {
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("got %d keystore rows, want 1", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore row %q: %v", rows[0], err)
+	}
+	ks := keystoreFromRow(row)
+	if ks.status == keystoreNotAvailable {
+		return nil, fmt.Errorf("the database has no keystore")
+	}
+	return &ks, nil
+}
+
+// rotateWalletPasswordCmds returns the statements changing the password of
+// the keystore, which keep a backup of the keystore with the current
+// password, followed by the one regenerating an auto-login keystore. The
+// password change goes through the password keystore even when the
+// auto-login keystore is the open one.
+func rotateWalletPasswordCmds(req *dbdpb.RotateWalletPasswordRequest, ks *keystore) ([]string, error) {
+	current, err := sqlq.Identifier(req.GetCurrentPassword())
+	if err != nil || req.GetCurrentPassword() == "" {
+		return nil, fmt.Errorf("invalid current keystore password")
+	}
+	next, err := sqlq.Identifier(req.GetNewPassword())
+	if err != nil || req.GetNewPassword() == "" {
+		return nil, fmt.Errorf("invalid new keystore password")
+	}
+	if req.GetNewPassword() == req.GetCurrentPassword() {
+		return nil, fmt.Errorf("the new keystore password is the current one")
+	}
+	cmds := []string{fmt.Sprintf("administer key management alter keystore password force keystore identified by %s set %s with backup using '%s'", current, next, keystoreBackupTag)}
+	switch ks.walletType {
+	case "AUTOLOGIN", "LOCAL_AUTOLOGIN":
+		if ks.location == "" {
+			return nil, fmt.Errorf("the location of the %s keystore is unknown", ks.walletType)
+		}
+		local := ""
+		if ks.walletType == "LOCAL_AUTOLOGIN" {
+			local = "local "
+		}
+		cmds = append(cmds, fmt.Sprintf("administer key management create %sauto_login keystore from keystore '%s' identified by %s", local, sqlq.StringParam(ks.location), next))
+	}
+	return cmds, nil
+}
+
+// RotateWalletPassword changes the password of the TDE keystore. An
+// auto-login keystore is created from the password keystore and has to be
+// created again with the new password.
+func (s *Server) RotateWalletPassword(ctx context.Context, req *dbdpb.RotateWalletPasswordRequest) (*dbdpb.RotateWalletPasswordResponse, error) {
+	klog.InfoS("dbdaemon/RotateWalletPassword", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{keystoreSQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RotateWalletPassword: failed to query the keystore: %v", err)
+	}
+	ks, err := parseKeystore(resp.GetMsg())
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RotateWalletPassword: %v", err)
+	}
+	cmds, err := rotateWalletPasswordCmds(req, ks)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RotateWalletPassword: %v", err)
+	}
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds[:1]}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RotateWalletPassword: failed to change the keystore password: %v", err)
+	}
+	if len(cmds) > 1 {
+		if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: cmds[1:]}, false); err != nil {
+			return nil, fmt.Errorf("dbdaemon/RotateWalletPassword: changed the keystore password but failed to regenerate the auto-login keystore in %s: %v", ks.location, err)
+		}
+	}
+	return &dbdpb.RotateWalletPasswordResponse{KeystoreLocation: ks.location, AutoLoginRegenerated: len(cmds) > 1}, nil
+}
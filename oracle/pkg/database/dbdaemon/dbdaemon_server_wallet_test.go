@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestRotateWalletPasswordCmds(t *testing.T) {
+	const changePassword = `administer key management alter keystore password force keystore identified by "Old_pw1" set "New_pw2" with backup using 'password_rotation'`
+	rotate := &dbdpb.RotateWalletPasswordRequest{CurrentPassword: "Old_pw1", NewPassword: "New_pw2"}
+	tests := []struct {
+		name    string
+		req     *dbdpb.RotateWalletPasswordRequest
+		ks      *keystore
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "password keystore",
+			req:  rotate,
+			ks:   &keystore{location: "/u02/app/oracle/wallet/tde/", walletType: "PASSWORD", status: "OPEN"},
+			want: []string{changePassword},
+		},
+		{
+			name: "auto-login keystore",
+			req:  rotate,
+			ks:   &keystore{location: "/u02/app/oracle/wallet/tde/", walletType: "AUTOLOGIN", status: "OPEN"},
+			want: []string{
+				changePassword,
+				`administer key management create auto_login keystore from keystore '/u02/app/oracle/wallet/tde/' identified by "New_pw2"`,
+			},
+		},
+		{
+			name: "local auto-login keystore",
+			req:  rotate,
+			ks:   &keystore{location: "/u02/app/oracle/wallet/tde/", walletType: "LOCAL_AUTOLOGIN", status: "OPEN"},
+			want: []string{
+				changePassword,
+				`administer key management create local auto_login keystore from keystore '/u02/app/oracle/wallet/tde/' identified by "New_pw2"`,
+			},
+		},
+		{
+			name:    "auto-login keystore without a location",
+			req:     rotate,
+			ks:      &keystore{walletType: "AUTOLOGIN", status: "OPEN"},
+			wantErr: true,
+		},
+		{
+			name:    "no current password",
+			req:     &dbdpb.RotateWalletPasswordRequest{NewPassword: "New_pw2"},
+			ks:      &keystore{walletType: "PASSWORD"},
+			wantErr: true,
+		},
+		{
+			name:    "quote in new password",
+			req:     &dbdpb.RotateWalletPasswordRequest{CurrentPassword: "Old_pw1", NewPassword: `pw" set "x`},
+			ks:      &keystore{walletType: "PASSWORD"},
+			wantErr: true,
+		},
+		{
+			name:    "unchanged password",
+			req:     &dbdpb.RotateWalletPasswordRequest{CurrentPassword: "Old_pw1", NewPassword: "Old_pw1"},
+			ks:      &keystore{walletType: "PASSWORD"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rotateWalletPasswordCmds(tc.req, tc.ks)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("rotateWalletPasswordCmds got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("rotateWalletPasswordCmds got unexpected result (-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestParseKeystore(t *testing.T) {
+	got, err := parseKeystore([]string{`{"WRL_PARAMETER":"/u02/app/oracle/wallet/tde/","WALLET_TYPE":"AUTOLOGIN","STATUS":"OPEN"}`})
+	if err != nil {
+		t.Fatalf("parseKeystore failed: %v", err)
+	}
+	want := &keystore{location: "/u02/app/oracle/wallet/tde/", walletType: "AUTOLOGIN", status: "OPEN"}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(keystore{})); diff != "" {
+		t.Errorf("parseKeystore got unexpected result (-want +got):\n%v", diff)
+	}
+
+	for _, rows := range [][]string{
+		nil,
+		{"not json"},
+		{`{"WRL_PARAMETER":"","WALLET_TYPE":"UNKNOWN","STATUS":"NOT_AVAILABLE"}`},
+	} {
+		if _, err := parseKeystore(rows); err == nil {
+			t.Errorf("parseKeystore(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+func TestRotateWalletPassword(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var statements []string
+	db := useFakeSQLDB(s)
+	db.runQueryFunc = func(sqls []string) ([]string, error) {
+		if sqls[len(sqls)-1] != keystoreSQL {
+			t.Fatalf("runQuery got unexpected query %q", sqls)
+		}
+		return []string{`{"WRL_PARAMETER":"/u02/app/oracle/wallet/tde/","WALLET_TYPE":"AUTOLOGIN","STATUS":"OPEN"}`}, nil
+	}
+	db.runSQLFunc = func(sqls []string) ([]string, error) {
+		statements = append(statements, sqls...)
+		return nil, nil
+	}
+
+	resp, err := s.RotateWalletPassword(ctx, &dbdpb.RotateWalletPasswordRequest{CurrentPassword: "Old_pw1", NewPassword: "New_pw2"})
+	if err != nil {
+		t.Fatalf("RotateWalletPassword failed: %v", err)
+	}
+	want := &dbdpb.RotateWalletPasswordResponse{KeystoreLocation: "/u02/app/oracle/wallet/tde/", AutoLoginRegenerated: true}
+	if diff := cmp.Diff(want, resp, protocmp.Transform()); diff != "" {
+		t.Errorf("RotateWalletPassword got unexpected result (-want +got):\n%v", diff)
+	}
+	if got, want := len(statements), 2; got != want {
+		t.Errorf("RotateWalletPassword ran %d statements, want %d", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+const (
+	// createWriteCanaryTableCmd creates the one row canary table unless it
+	// exists (ORA-00955).
+	createWriteCanaryTableCmd = "declare e exception; pragma exception_init(e, -955); begin " +
+		"execute immediate 'create table sys.elcarro_write_canary (id number primary key, token varchar2(32), written_at timestamp with time zone)'; " +
+		"exception when e then null; end;"
+	writeCanaryCmd = "merge into sys.elcarro_write_canary c using (select 1 id from dual) s on (c.id = s.id) " +
+		"when matched then update set c.token = '%[1]s', c.written_at = systimestamp " +
+		"when not matched then insert (id, token, written_at) values (1, '%[1]s', systimestamp)"
+	readWriteCanarySQL = "select token from sys.elcarro_write_canary where id = 1"
+)
+
+// writeCanaryToken returns a token unique to a canary run, so that reading
+// it back proves the write of this run was committed.
+func writeCanaryToken(now time.Time) string {
+	return strconv.FormatInt(now.UnixNano(), 10)
+}
+
+// checkWriteCanaryToken checks the readWriteCanarySQL rows hold token.
+func checkWriteCanaryToken(rows []string, token string) error {
+	if len(rows) != 1 {
+		return fmt.Errorf("expected 1 canary row, got %d", len(rows))
+	}
+	row := make(map[string]string)
+	if err := json.Unmarshal([]byte(rows[0]), &row); err != nil {
+		return fmt.Errorf("failed to parse canary row %q: %v", rows[0], err)
+	}
+	if row["TOKEN"] != token {
+		return fmt.Errorf("read canary token %q, want %q", row["TOKEN"], token)
+	}
+	return nil
+}
+
+// RunWriteCanary writes a token to the canary table, reads it back and
+// returns how long both took.
+func (s *Server) RunWriteCanary(ctx context.Context, req *dbdpb.RunWriteCanaryRequest) (*dbdpb.RunWriteCanaryResponse, error) {
+	klog.InfoS("dbdaemon/RunWriteCanary", "req", loggableRequest(req))
+	// Add lock to protect server state "databaseSid" and os env variable "ORACLE_SID".
+	// Only add lock in top level API to avoid deadlock.
+	s.databaseSid.Lock()
+	defer s.databaseSid.Unlock()
+
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{createWriteCanaryTableCmd}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunWriteCanary: failed to create the canary table: %v", err)
+	}
+
+	start := time.Now()
+	token := writeCanaryToken(start)
+	if _, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{fmt.Sprintf(writeCanaryCmd, token)}}, false); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunWriteCanary: failed to write the canary row: %v", err)
+	}
+	written := time.Now()
+	resp, err := s.runSQLPlusHelper(ctx, &dbdpb.RunSQLPlusCMDRequest{Commands: []string{readWriteCanarySQL}}, true)
+	if err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunWriteCanary: failed to read the canary row: %v", err)
+	}
+	read := time.Now()
+	if err := checkWriteCanaryToken(resp.GetMsg(), token); err != nil {
+		return nil, fmt.Errorf("dbdaemon/RunWriteCanary: %v", err)
+	}
+	return &dbdpb.RunWriteCanaryResponse{
+		WriteSeconds: written.Sub(start).Seconds(),
+		ReadSeconds:  read.Sub(written).Seconds(),
+	}, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+func TestCheckWriteCanaryToken(t *testing.T) {
+	if err := checkWriteCanaryToken([]string{`{"TOKEN":"123"}`}, "123"); err != nil {
+		t.Errorf("checkWriteCanaryToken failed: %v", err)
+	}
+	for _, rows := range [][]string{
+		nil,
+		{`{"TOKEN":"122"}`},
+		{`{"TOKEN":"123"}`, `{"TOKEN":"123"}`},
+		{"not json"},
+	} {
+		if err := checkWriteCanaryToken(rows, "123"); err == nil {
+			t.Errorf("checkWriteCanaryToken(%q) succeeded, want error", rows)
+		}
+	}
+}
+
+func TestRunWriteCanary(t *testing.T) {
+	tokenRE := regexp.MustCompile(`set c.token = '(\d+)'`)
+	tests := []struct {
+		name     string
+		writeErr error
+		stale    bool
+		wantErr  bool
+	}{
+		{
+			name: "written and read back",
+		},
+		{
+			name:     "write failed",
+			writeErr: errors.New("ORA-16000: database or pluggable database open for read-only access"),
+			wantErr:  true,
+		},
+		{
+			name:    "stale read",
+			stale:   true,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			useFakeOracleDatabase(t)
+			ctx := context.Background()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			db := useFakeSQLDB(s)
+			var executed []string
+			token := "1"
+			db.runSQLFunc = func(sqls []string) ([]string, error) {
+				executed = append(executed, sqls...)
+				for _, sql := range sqls {
+					if m := tokenRE.FindStringSubmatch(sql); m != nil {
+						if tc.writeErr != nil {
+							return nil, tc.writeErr
+						}
+						// Make the write measurable.
+						time.Sleep(10 * time.Millisecond)
+						if !tc.stale {
+							token = m[1]
+						}
+					}
+				}
+				return nil, nil
+			}
+			db.runQueryFunc = func(sqls []string) ([]string, error) {
+				if len(sqls) != 1 || sqls[0] != readWriteCanarySQL {
+					return nil, fmt.Errorf("unexpected query %q", sqls)
+				}
+				return []string{fmt.Sprintf(`{"TOKEN":%q}`, token)}, nil
+			}
+
+			resp, err := s.RunWriteCanary(ctx, &dbdpb.RunWriteCanaryRequest{})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("RunWriteCanary got error %v, want error: %v", err, tc.wantErr)
+			}
+			if len(executed) < 2 || executed[0] != createWriteCanaryTableCmd {
+				t.Errorf("RunWriteCanary executed %q, want the canary table created then written", executed)
+			}
+			if tc.wantErr {
+				return
+			}
+			if resp.GetWriteSeconds() < 0.01 || resp.GetReadSeconds() < 0 {
+				t.Errorf("RunWriteCanary got latencies %v, want a write of at least 10ms", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"k8s.io/klog/v2"
+)
+
+// permissions is the policy of the modes of the files and directories the
+// dbdaemon creates. The process umask still applies on top of it.
+var permissions = struct {
+	// file and dir replace the modes of the creation sites, unless 0.
+	file os.FileMode
+	dir  os.FileMode
+	// umask is cleared from the modes of all the created files and
+	// directories.
+	umask os.FileMode
+}{}
+
+// SetPermissions sets the octal modes of the created files and directories
+// and the octal umask cleared from them, an empty mode keeps the modes of
+// the creation sites and an empty umask clears nothing. The oracle user
+// keeps the permissions it needs on its files and directories.
+func SetPermissions(file, dir, umask string) error {
+	policy := permissions
+	for _, p := range []struct {
+		name  string
+		value string
+		mode  *os.FileMode
+		// owner are the owner permissions the policy has to keep.
+		owner os.FileMode
+	}{
+		{name: "file mode", value: file, mode: &policy.file, owner: ownerFilePerm},
+		{name: "dir mode", value: dir, mode: &policy.dir, owner: ownerDirPerm},
+		{name: "umask", value: umask, mode: &policy.umask},
+	} {
+		if p.value == "" {
+			continue
+		}
+		m, err := strconv.ParseUint(p.value, 8, 32)
+		if err != nil || os.FileMode(m)&^os.ModePerm != 0 {
+			return fmt.Errorf("the %s %q is not an octal permission", p.name, p.value)
+		}
+		*p.mode = os.FileMode(m)
+		if p.owner != 0 && *p.mode&p.owner != p.owner {
+			return fmt.Errorf("the %s %q doesn't grant the owner permissions %#o", p.name, p.value, p.owner)
+		}
+	}
+	if policy.umask&ownerDirPerm != 0 {
+		return fmt.Errorf("the umask %q clears owner permissions", umask)
+	}
+	permissions = policy
+	klog.InfoS("dbdaemon/SetPermissions", "file", fmt.Sprintf("%#o", permissions.file), "dir", fmt.Sprintf("%#o", permissions.dir), "umask", fmt.Sprintf("%#o", permissions.umask))
+	return nil
+}
+
+// filePerm returns the mode of a file created with perm by default.
+func filePerm(perm os.FileMode) os.FileMode {
+	if permissions.file != 0 {
+		perm = permissions.file
+	}
+	return perm &^ permissions.umask
+}
+
+// dirPerm returns the mode of a directory created with perm by default.
+func dirPerm(perm os.FileMode) os.FileMode {
+	if permissions.dir != 0 {
+		perm = permissions.dir
+	}
+	return perm &^ permissions.umask
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// usePermissions sets the permission policy for the test and clears the
+// process umask so that the policy alone decides the modes.
+func usePermissions(t *testing.T, file, dir, umask string) {
+	t.Helper()
+	orig := permissions
+	oldMask := syscall.Umask(0)
+	t.Cleanup(func() {
+		permissions = orig
+		syscall.Umask(oldMask)
+	})
+	if err := SetPermissions(file, dir, umask); err != nil {
+		t.Fatalf("SetPermissions(%q, %q, %q) failed: %v", file, dir, umask, err)
+	}
+}
+
+func TestSetPermissionsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		dir   string
+		umask string
+	}{
+		{name: "not octal", file: "0648"},
+		{name: "not a permission", dir: "01750"},
+		{name: "file without owner write", file: "0440"},
+		{name: "dir without owner execute", dir: "0660"},
+		{name: "umask of owner permissions", umask: "0227"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			orig := permissions
+			if err := SetPermissions(tc.file, tc.dir, tc.umask); err == nil {
+				t.Errorf("SetPermissions(%q, %q, %q) succeeded, want an error", tc.file, tc.dir, tc.umask)
+			}
+			if permissions != orig {
+				t.Errorf("SetPermissions(%q, %q, %q) changed the policy to %+v after an error", tc.file, tc.dir, tc.umask, permissions)
+			}
+		})
+	}
+}
+
+func TestPermissionsApplied(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		dir   string
+		umask string
+		// wantFile and wantDir are the modes of the representative
+		// files and dirs, wantDataPump of the Data Pump dir.
+		wantFile     os.FileMode
+		wantDir      os.FileMode
+		wantDataPump os.FileMode
+	}{
+		{name: "defaults", wantFile: 0640, wantDir: 0750, wantDataPump: 0760},
+		{name: "modes", file: "0600", dir: "0700", wantFile: 0600, wantDir: 0700, wantDataPump: 0700},
+		{name: "umask", umask: "0077", wantFile: 0600, wantDir: 0700, wantDataPump: 0700},
+		{name: "modes and umask", file: "0664", dir: "0775", umask: "0002", wantFile: 0664, wantDir: 0775, wantDataPump: 0775},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			useFakeOracleDatabase(t)
+			usePermissions(t, tc.file, tc.dir, tc.umask)
+			ctx := context.Background()
+			root := t.TempDir()
+			s, err := NewMockServer(ctx, "")
+			if err != nil {
+				t.Fatalf("error calling New: %v", err)
+			}
+			useFakeSQLDB(s).runSQLFunc = func([]string) ([]string, error) { return nil, nil }
+
+			// The license is persisted under a missing dir.
+			origLicenseFile := licenseFile
+			licenseFile = filepath.Join(root, "oraconfig", "license.json")
+			t.Cleanup(func() { licenseFile = origLicenseFile })
+			s.license = &licenseState{}
+			if _, err := s.SetLicense(ctx, &dbdpb.SetLicenseRequest{Edition: "Enterprise"}); err != nil {
+				t.Fatalf("SetLicense failed: %v", err)
+			}
+
+			dataPump := filepath.Join(root, "PDB1", "dmp")
+			origDataPumpDir := dataPumpDir
+			dataPumpDir = func(string, string) string { return dataPump }
+			t.Cleanup(func() { dataPumpDir = origDataPumpDir })
+			if _, err := s.CreateDataPumpDir(ctx, &dbdpb.CreateDataPumpDirRequest{PdbName: "pdb1"}); err != nil {
+				t.Fatalf("CreateDataPumpDir failed: %v", err)
+			}
+
+			for path, want := range map[string]os.FileMode{
+				filepath.Dir(licenseFile): tc.wantDir,
+				licenseFile:               tc.wantFile,
+				dataPump:                  tc.wantDataPump,
+			} {
+				fi, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("failed to stat %s: %v", path, err)
+				}
+				if got := fi.Mode().Perm(); got != want {
+					t.Errorf("%s got mode %v, want %v", path, got, want)
+				}
+			}
+
+			// The files written by CreateFile keep the group and others
+			// permissions the policy allows.
+			file := filepath.Join(root, "wallet", "ewallet.p12")
+			if err := (&osUtilImpl{}).createFile(file, strings.NewReader("secret")); err != nil {
+				t.Fatalf("createFile failed: %v", err)
+			}
+			fi, err := os.Stat(file)
+			if err != nil {
+				t.Fatalf("failed to stat %s: %v", file, err)
+			}
+			if got, want := fi.Mode().Perm(), filePerm(0666); got != want {
+				t.Errorf("createFile got mode %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"path/filepath"
 
 	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/database/provision"
 )
 
 // Override library functions for the benefit of unit tests.
@@ -51,6 +53,13 @@ var (
 	datapatch = func(databaseHome string) string {
 		return filepath.Join(databaseHome, "OPatch", "datapatch")
 	}
+	onsctl = func(databaseHome string) string {
+		return filepath.Join(databaseHome, "opmn", "bin", "onsctl")
+	}
+	orapki = func(databaseHome string) string {
+		return filepath.Join(databaseHome, "bin", "orapki")
+	}
+	relinkConfigFiles = provision.RelinkConfigFiles
 )
 
 const (
@@ -63,6 +72,7 @@ type osUtil interface {
 	isReturnCodeEqual(err error, code int) bool
 	createFile(file string, content io.Reader) error
 	removeFile(file string) error
+	chown(path string, uid, gid int) error
 }
 
 type osUtilImpl struct {
@@ -75,6 +85,9 @@ func (o *osUtilImpl) runCommand(bin string, params []string) error {
 	case rman(ohome), impdp(ohome), expdp(ohome):
 		sanitizedParams = append([]string{"***"}, params[1:]...)
 	case lsnrctl(ohome), orapwd(ohome), datapatch(ohome):
+		sanitizedParams = redactAll(params)
+	case onsctl(ohome), orapki(ohome):
+		// onsctl and auto-login orapki arguments carry no secrets.
 	default:
 		klog.InfoS("command not supported", "bin", bin)
 		return fmt.Errorf("command %q is not supported", bin)
@@ -96,10 +109,10 @@ func (o *osUtilImpl) isReturnCodeEqual(err error, code int) bool {
 
 func (o *osUtilImpl) createFile(file string, content io.Reader) error {
 	dir := filepath.Dir(file)
-	if err := os.MkdirAll(dir, 0750); err != nil {
+	if err := os.MkdirAll(dir, dirPerm(0750)); err != nil {
 		return fmt.Errorf("couldn't create dir err: %v", err)
 	}
-	f, err := os.Create(file) // truncates if file exists.
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, filePerm(0666)) // truncates if file exists.
 	if err != nil {
 		return fmt.Errorf("couldn't create file err: %v", err)
 	}
@@ -121,3 +134,7 @@ func (o *osUtilImpl) createFile(file string, content io.Reader) error {
 func (o *osUtilImpl) removeFile(file string) error {
 	return os.Remove(file)
 }
+
+func (o *osUtilImpl) chown(path string, uid, gid int) error {
+	return os.Lchown(path, uid, gid)
+}
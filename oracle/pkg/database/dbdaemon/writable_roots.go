@@ -0,0 +1,102 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/consts"
+)
+
+// writableRoots are the mounts the dbdaemon writes its own files to, so
+// that it can run with a read-only root filesystem.
+var writableRoots = struct {
+	// config holds the listener and database config files, the pfile
+	// backups included, under app/oracle/oraconfig.
+	config string
+	// scratch holds the temporary files.
+	scratch string
+}{
+	config:  "/" + consts.DataMount,
+	scratch: "/var/tmp",
+}
+
+// SetWritableRoots directs the config files to the config mount and the
+// temporary files to the scratch mount, an empty path keeps the default.
+// Both have to be writable directories.
+func SetWritableRoots(config, scratch string) error {
+	roots := writableRoots
+	for _, r := range []struct {
+		name string
+		path string
+		root *string
+	}{
+		{name: "config", path: config, root: &roots.config},
+		{name: "scratch", path: scratch, root: &roots.scratch},
+	} {
+		if r.path == "" {
+			continue
+		}
+		if !filepath.IsAbs(r.path) {
+			return fmt.Errorf("the %s root %q is not an absolute path", r.name, r.path)
+		}
+		if err := checkWritable(r.path); err != nil {
+			return fmt.Errorf("the %s root %q is not writable: %v", r.name, r.path, err)
+		}
+		*r.root = filepath.Clean(r.path)
+	}
+	writableRoots = roots
+	licenseFile = filepath.Join(configBaseDir(), licenseFileName)
+	klog.InfoS("dbdaemon/SetWritableRoots", "config", writableRoots.config, "scratch", writableRoots.scratch)
+	return nil
+}
+
+// checkWritable creates and removes a file in dir.
+func checkWritable(dir string) error {
+	f, err := ioutil.TempFile(dir, ".writable")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// configBaseDir returns where the config files shared by the databases,
+// such as the listener files, are persisted.
+func configBaseDir() string {
+	return fmt.Sprintf(consts.ConfigBaseDir, strings.TrimPrefix(writableRoots.config, "/"))
+}
+
+// configDir returns where the spfile, pfile and pwd file of a database are
+// persisted.
+func configDir(sid string) string {
+	return fmt.Sprintf(consts.ConfigDir, strings.TrimPrefix(writableRoots.config, "/"), sid)
+}
+
+// listenerDir returns where the listener files are persisted.
+func listenerDir() string {
+	return fmt.Sprintf(consts.ListenerDir, strings.TrimPrefix(writableRoots.config, "/"))
+}
+
+// scratchDir returns where the temporary files are written.
+func scratchDir() string {
+	return writableRoots.scratch
+}
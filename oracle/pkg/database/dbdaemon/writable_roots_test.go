@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbdaemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
+)
+
+// useWritableRoots sets the writable roots for the test and restores the
+// defaults afterwards.
+func useWritableRoots(t *testing.T, config, scratch string) {
+	t.Helper()
+	origRoots, origLicenseFile := writableRoots, licenseFile
+	t.Cleanup(func() { writableRoots, licenseFile = origRoots, origLicenseFile })
+	if err := SetWritableRoots(config, scratch); err != nil {
+		t.Fatalf("SetWritableRoots(%q, %q) failed: %v", config, scratch, err)
+	}
+}
+
+func TestWritableRootsDefaults(t *testing.T) {
+	got := []string{configDir("GCLOUD"), listenerDir(), filepath.Join(configBaseDir(), licenseFileName), storageMigrationStateFile("GCLOUD"), scratchDir()}
+	want := []string{
+		"/u02/app/oracle/oraconfig/GCLOUD",
+		"/u02/app/oracle/oraconfig/network",
+		"/u02/app/oracle/oraconfig/license.json",
+		"/u02/app/oracle/oraconfig/GCLOUD/storage_migration.json",
+		"/var/tmp",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("default writable paths got unexpected paths (-want +got):\n%v", diff)
+	}
+}
+
+func TestSetWritableRoots(t *testing.T) {
+	config, scratch := t.TempDir(), t.TempDir()
+	useWritableRoots(t, config, scratch)
+
+	for _, p := range []string{configDir("GCLOUD"), listenerDir(), licenseFile, storageMigrationStateFile("GCLOUD")} {
+		if !strings.HasPrefix(p, config+"/app/oracle/oraconfig/") {
+			t.Errorf("got writable path %q, want it under the config root %q", p, config)
+		}
+	}
+	if got := scratchDir(); got != scratch {
+		t.Errorf("scratchDir got %q, want %q", got, scratch)
+	}
+
+	// An empty root keeps the current one.
+	if err := SetWritableRoots("", ""); err != nil {
+		t.Fatalf("SetWritableRoots with empty roots failed: %v", err)
+	}
+	if got := configDir("GCLOUD"); got != filepath.Join(config, "app/oracle/oraconfig/GCLOUD") {
+		t.Errorf("configDir got %q after SetWritableRoots with empty roots, want it unchanged", got)
+	}
+
+	for _, tc := range []struct{ config, scratch string }{
+		{config: "relative/config"},
+		{scratch: filepath.Join(scratch, "missing")},
+	} {
+		if err := SetWritableRoots(tc.config, tc.scratch); err == nil {
+			t.Errorf("SetWritableRoots(%q, %q) succeeded, want error", tc.config, tc.scratch)
+		}
+	}
+	if got := scratchDir(); got != scratch {
+		t.Errorf("scratchDir got %q after a failed SetWritableRoots, want it unchanged %q", got, scratch)
+	}
+}
+
+func TestWritableOperationsTargetConfigRoot(t *testing.T) {
+	useFakeOracleDatabase(t)
+	ctx := context.Background()
+	config := t.TempDir()
+	useWritableRoots(t, config, t.TempDir())
+
+	s, err := NewMockServer(ctx, "")
+	if err != nil {
+		t.Fatalf("error calling New: %v", err)
+	}
+	var gotSQLs []string
+	useFakeSQLDB(s).runSQLFunc = func(sqls []string) ([]string, error) {
+		gotSQLs = append(gotSQLs, sqls...)
+		return nil, nil
+	}
+	if err := s.BackupConfigFile(ctx, "GCLOUD"); err != nil {
+		t.Fatalf("BackupConfigFile failed: %v", err)
+	}
+	if _, err := s.RecoverConfigFile(ctx, &dbdpb.RecoverConfigFileRequest{CdbName: "GCLOUD"}); err != nil {
+		t.Fatalf("RecoverConfigFile failed: %v", err)
+	}
+	dir := filepath.Join(config, "app/oracle/oraconfig/GCLOUD")
+	want := []string{
+		fmt.Sprintf("create pfile='%s/pfile.lkws' from spfile", dir),
+		fmt.Sprintf("create spfile='%s/spfileGCLOUD.ora' from pfile='%s/pfile.lkws'", dir, dir),
+	}
+	if diff := cmp.Diff(want, gotSQLs); diff != "" {
+		t.Errorf("config file backups got unexpected statements (-want +got):\n%v", diff)
+	}
+
+	s.license = &licenseState{}
+	if _, err := s.SetLicense(ctx, &dbdpb.SetLicenseRequest{Edition: "Enterprise"}); err != nil {
+		t.Fatalf("SetLicense failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(config, "app/oracle/oraconfig/license.json")); err != nil {
+		t.Errorf("SetLicense didn't write the license under the config root: %v", err)
+	}
+}
@@ -264,6 +264,10 @@ func (s *Server) BounceDatabase(ctx context.Context, req *dbdpb.BounceDatabaseRe
 
 	// Sets env to bounce a database, needed for start and shutdown.
 	os.Setenv("ORACLE_SID", reqDatabaseName)
+	// Switches the Oracle home, e.g. to the new home of an out-of-place patching.
+	if home := req.GetOracleHome(); home != "" {
+		os.Setenv("ORACLE_HOME", home)
+	}
 
 	var err error
 	shutdownEnumMap := map[string]godror.ShutdownMode{
@@ -17,6 +17,7 @@ package lro
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -57,6 +58,46 @@ type Job struct {
 
 	call func(ctx context.Context) (proto.Message, error)
 	task *detach.Task
+
+	mu       sync.Mutex
+	metadata *anypb.Any
+}
+
+type jobContextKey struct{}
+
+// ReportProgress sets the metadata of the operation of the job running the
+// call, for example the step it is at. It does nothing if ctx isn't the
+// context of a job.
+func ReportProgress(ctx context.Context, metadata proto.Message) error {
+	j, ok := ctx.Value(jobContextKey{}).(*Job)
+	if !ok {
+		return nil
+	}
+	any := &anypb.Any{}
+	if err := any.MarshalFrom(metadata); err != nil {
+		return fmt.Errorf("failed to marshal the progress of job %q: %v", j.id, err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.metadata = any
+	return nil
+}
+
+// OperationID returns the id of the operation of the job running the call,
+// empty if ctx isn't the context of a job.
+func OperationID(ctx context.Context) string {
+	j, ok := ctx.Value(jobContextKey{}).(*Job)
+	if !ok {
+		return ""
+	}
+	return j.id
+}
+
+// Metadata returns the progress last reported by the job, nil if none.
+func (j *Job) Metadata() *anypb.Any {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.metadata
 }
 
 // Cancel cancels the job.
@@ -145,6 +186,7 @@ func (j *Job) start(ctx context.Context) {
 	timeOutDuration, _ := taskTimeout(ctx)
 	task := detach.Go(catchPanic(j, func(jobCtx context.Context) {
 		var resp proto.Message
+		jobCtx = context.WithValue(jobCtx, jobContextKey{}, j)
 		if timeOutDuration > 0 {
 			var cancel context.CancelFunc
 			jobCtx, cancel = context.WithTimeout(jobCtx, timeOutDuration)
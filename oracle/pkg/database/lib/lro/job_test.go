@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	opspb "google.golang.org/genproto/googleapis/longrunning"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -119,6 +120,66 @@ func TestCreateAndRunLROJobWithTimeout(t *testing.T) {
 	_, _ = CreateAndRunLROJobWithContext(ctx, "Test", lro, jobFunc)
 }
 
+func TestReportProgress(t *testing.T) {
+	ctx := context.Background()
+	lro := NewServer(ctx)
+	progress := &dbdpb.OnlineRedefinitionProgress{Step: dbdpb.OnlineRedefinitionProgress_COPY_DEPENDENTS}
+	reported := make(chan struct{})
+	done := make(chan struct{})
+	jobFunc := func(jobCtx context.Context) (proto.Message, error) {
+		if err := ReportProgress(jobCtx, progress); err != nil {
+			t.Errorf("ReportProgress failed: %v", err)
+		}
+		close(reported)
+		<-done
+		return nil, nil
+	}
+
+	job, err := CreateAndRunLROJobWithID(ctx, "TestReportProgress", "Test", lro, jobFunc)
+	if err != nil {
+		t.Fatalf("CreateAndRunLROJobWithID failed: %v", err)
+	}
+	<-reported
+	op, err := lro.GetOperation(ctx, &opspb.GetOperationRequest{Name: job.ID()})
+	close(done)
+	if err != nil {
+		t.Fatalf("GetOperation failed: %v", err)
+	}
+	got := &dbdpb.OnlineRedefinitionProgress{}
+	if op.GetMetadata() == nil {
+		t.Fatalf("GetOperation got no metadata, want the reported progress")
+	}
+	if err := op.GetMetadata().UnmarshalTo(got); err != nil {
+		t.Fatalf("failed to unmarshal the operation metadata: %v", err)
+	}
+	if diff := cmp.Diff(progress, got, protocmp.Transform()); diff != "" {
+		t.Errorf("GetOperation got unexpected metadata (-want +got):\n%v", diff)
+	}
+
+	if err := ReportProgress(ctx, progress); err != nil {
+		t.Errorf("ReportProgress outside of a job failed: %v", err)
+	}
+}
+
+func TestOperationID(t *testing.T) {
+	ctx := context.Background()
+	lro := NewServer(ctx)
+	got := make(chan string, 1)
+	jobFunc := func(jobCtx context.Context) (proto.Message, error) {
+		got <- OperationID(jobCtx)
+		return nil, nil
+	}
+	if _, err := CreateAndRunLROJobWithID(ctx, "TestOperationID", "Test", lro, jobFunc); err != nil {
+		t.Fatalf("CreateAndRunLROJobWithID failed: %v", err)
+	}
+	if id := <-got; id != "TestOperationID" {
+		t.Errorf("OperationID in the job got %q, want TestOperationID", id)
+	}
+	if id := OperationID(ctx); id != "" {
+		t.Errorf("OperationID outside of a job got %q, want empty", id)
+	}
+}
+
 func TestCancelJob(t *testing.T) {
 	tests := []struct {
 		name              string
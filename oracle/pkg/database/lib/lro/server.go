@@ -300,7 +300,12 @@ func (s *Server) startOperation(name string) {
 // GetOperationData fills in the operation data for this specific job.
 func GetOperationData(id string, j job) *opspb.Operation {
 	done, result, e := j.Status()
-	return BuildOperation(id, done, result, e)
+	op := BuildOperation(id, done, result, e)
+	// Only some jobs report their progress.
+	if m, ok := j.(interface{ Metadata() *anypb.Any }); ok {
+		op.Metadata = m.Metadata()
+	}
+	return op
 }
 
 // BuildOperation builds the operation response for this specific grpcstatus.
@@ -38,6 +38,8 @@ import (
 // Max number of retries for db startup.
 const startupRetries = 5
 
+const controlfileAutobackupCmd = "configure controlfile autobackup on;"
+
 // BootstrapTask defines a task can be invoked to bootstrap an Oracle DB.
 type BootstrapTask struct {
 	db          oracleDB
@@ -320,6 +322,16 @@ func isFreeVersion(db oracleDB) bool {
 	return false
 }
 
+// compatibleVersion returns the compatible parameter for the database
+// version, e.g. 19.3.0 for 19.3. Release names like 21c map to the first
+// patch set of the release, 21.0.0.
+func compatibleVersion(version string) string {
+	if release := strings.TrimSuffix(version, "c"); release != version {
+		return release + ".0.0"
+	}
+	return version + ".0"
+}
+
 func (task *BootstrapTask) setParametersHelper(ctx context.Context) error {
 	localListener, err := getLocalListener(task.db.GetListeners())
 	if err != nil {
@@ -361,7 +373,7 @@ func (task *BootstrapTask) setParametersHelper(ctx context.Context) error {
 	if task.isSeeded && !isFreeVersion(task.db) {
 		parameters = append(parameters, fmt.Sprintf("pga_aggregate_target=%dM", task.db.GetDatabaseParamPGATargetMB()))
 		parameters = append(parameters, fmt.Sprintf("sga_target=%dM", task.db.GetDatabaseParamSGATargetMB()))
-		parameters = append(parameters, fmt.Sprintf("compatible='%s.0'", task.db.GetVersion()))
+		parameters = append(parameters, fmt.Sprintf("compatible='%s'", compatibleVersion(task.db.GetVersion())))
 	}
 
 	if !task.isSeeded {
@@ -445,6 +457,17 @@ func (task *BootstrapTask) prepDatabase(ctx context.Context) error {
 	return nil
 }
 
+// enableControlfileAutobackup makes RMAN back up the control file and the
+// spfile after each backup and structural change, so that a lost control
+// file can be restored from its autobackup.
+func (task *BootstrapTask) enableControlfileAutobackup(ctx context.Context) error {
+	if _, err := task.dbdClient.RunRMAN(ctx, &dbdpb.RunRMANRequest{Scripts: []string{controlfileAutobackupCmd}}); err != nil {
+		return fmt.Errorf("enableControlfileAutobackup: %q failed: %v", controlfileAutobackupCmd, err)
+	}
+	klog.InfoS("enableControlfileAutobackup: control file autobackup enabled")
+	return nil
+}
+
 func (task *BootstrapTask) fixOratab(ctx context.Context) error {
 	if err := replace(task.db.GetOratabFile(), task.db.GetSourceDatabaseName(), task.db.GetDatabaseName(), task.uid, task.gid); err != nil {
 		return fmt.Errorf("oratab replacing dbname: %v", err)
@@ -775,6 +798,7 @@ func NewBootstrapDatabaseTask(ctx context.Context, iscdb bool, isSeeded bool, cd
 			&simpleTask{name: "moveDatabase", callFun: bootstrapTask.moveDatabase},
 			&simpleTask{name: "runNID", callFun: bootstrapTask.runNID},
 			&simpleTask{name: "prepDatabase", callFun: bootstrapTask.prepDatabase},
+			&simpleTask{name: "enableControlfileAutobackup", callFun: bootstrapTask.enableControlfileAutobackup},
 			&simpleTask{name: "fixOratab", callFun: bootstrapTask.fixOratab},
 			&simpleTask{name: "setupUsers", callFun: bootstrapTask.setupUsers},
 			&simpleTask{name: "cleanup", callFun: bootstrapTask.cleanup},
@@ -807,6 +831,7 @@ func NewBootstrapDatabaseTaskForUnseeded(cdbName, dbUniqueName, dbDomain string,
 	bootstrapTask.subTasks = []task{
 		&simpleTask{name: "setParameters", callFun: bootstrapTask.setParameters},
 		&simpleTask{name: "prepDatabase", callFun: bootstrapTask.prepDatabase},
+		&simpleTask{name: "enableControlfileAutobackup", callFun: bootstrapTask.enableControlfileAutobackup},
 		&simpleTask{name: "setupUsers", callFun: bootstrapTask.setupUsers},
 		&simpleTask{name: "createPDBSeedTemp", callFun: bootstrapTask.createPDBSeedTemp},
 		&simpleTask{name: "createDumpDirs", callFun: bootstrapTask.createDumpDirs},
@@ -28,6 +28,20 @@ import (
 	dbdpb "github.com/GoogleCloudPlatform/elcarro-oracle-operator/oracle/pkg/agents/oracle"
 )
 
+func TestCompatibleVersion(t *testing.T) {
+	for version, want := range map[string]string{
+		"12.2": "12.2.0",
+		"19.3": "19.3.0",
+		"19c":  "19.0.0",
+		"21c":  "21.0.0",
+		"21.3": "21.3.0",
+	} {
+		if got := compatibleVersion(version); got != want {
+			t.Errorf("compatibleVersion(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
 func TestSetParametersHelper(t *testing.T) {
 	dbdServer := &fakeServer{}
 	client, cleanup := newFakeDatabaseDaemonClient(t, dbdServer)
@@ -78,7 +92,7 @@ func TestSetParametersHelper(t *testing.T) {
 	wantSQLsSeeded := []string{
 		fmt.Sprintf("alter system set pga_aggregate_target=%dM scope=spfile", testCDB.GetDatabaseParamPGATargetMB()),
 		fmt.Sprintf("alter system set sga_target=%dM scope=spfile", testCDB.GetDatabaseParamSGATargetMB()),
-		fmt.Sprintf("alter system set compatible='%s.0' scope=spfile", testCDB.GetVersion()),
+		"alter system set compatible='12.2.0' scope=spfile",
 	}
 
 	testcases := []struct {
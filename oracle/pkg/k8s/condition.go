@@ -33,6 +33,14 @@ const (
 	PauseMode               = "Pause"
 	StandbyDRReady          = "StandbyDRReady"
 	InstanceStopped         = "InstanceStopped"
+	TablespacesEncrypted    = "TablespacesEncrypted"
+	KeystoreOpen            = "KeystoreOpen"
+	RecoveryAreaHealthy     = "RecoveryAreaHealthy"
+	StorageMigrationReady   = "StorageMigrationReady"
+	DiskSpaceHealthy        = "DiskSpaceHealthy"
+	RequiredOptionsValid    = "RequiredOptionsValid"
+	DatabaseWritable        = "DatabaseWritable"
+	PITRTargetRecoverable   = "PITRTargetRecoverable"
 
 	// Condition Reasons
 	// Backup schedule concurrent policy is relying on the backup ready condition’s reason,
@@ -116,6 +124,120 @@ const (
 	DatabasePatchingComplete                = "DatabasePatchingComplete"
 	DatabasePatchingFailure                 = "DatabasePatchingFailure"
 	NotSupported                            = "NotSupported"
+
+	EncryptionEnforced          = "EncryptionEnforced"
+	UnencryptedTablespacesFound = "UnencryptedTablespacesFound"
+	EncryptionVerifyFailed      = "EncryptionVerifyFailed"
+
+	TablespaceEncryptionPolicySet    = "TablespaceEncryptionPolicySet"
+	TablespaceEncryptionPolicyFailed = "TablespaceEncryptionPolicyFailed"
+
+	WalletPasswordRotated        = "WalletPasswordRotated"
+	WalletPasswordRotationFailed = "WalletPasswordRotationFailed"
+
+	KeystoreConfigured = "KeystoreConfigured"
+	KeystoreFailed     = "KeystoreFailed"
+	MasterKeySet       = "MasterKeySet"
+	WalletRootSet      = "WalletRootSet"
+
+	NetworkEncryptionConfigured = "NetworkEncryptionConfigured"
+	NetworkEncryptionFailed     = "NetworkEncryptionFailed"
+	AllowedClientsConfigured    = "AllowedClientsConfigured"
+	AllowedClientsFailed        = "AllowedClientsFailed"
+	TCPSListenerConfigured      = "TCPSListenerConfigured"
+	TCPSListenerFailed          = "TCPSListenerFailed"
+	ConnectionPoolConfigured    = "ConnectionPoolConfigured"
+	ConnectionPoolFailed        = "ConnectionPoolFailed"
+	ConnectionPoolRemoved       = "ConnectionPoolRemoved"
+
+	RecoveryAreaUsageNormal  = "RecoveryAreaUsageNormal"
+	RecoveryAreaUsageHigh    = "RecoveryAreaUsageHigh"
+	RecoveryAreaUsageUnknown = "RecoveryAreaUsageUnknown"
+	ObsoleteBackupsDeleted   = "ObsoleteBackupsDeleted"
+	ObsoleteBackupsFailed    = "ObsoleteBackupsFailed"
+	ArchivelogBackupComplete = "ArchivelogBackupComplete"
+	ArchivelogBackupFailed   = "ArchivelogBackupFailed"
+	RestorePointsDropped     = "RestorePointsDropped"
+	RestorePointDropFailed   = "RestorePointDropFailed"
+
+	RMANConfigured      = "RMANConfigured"
+	RMANConfigureFailed = "RMANConfigureFailed"
+
+	AWRConfigured      = "AWRConfigured"
+	AWRConfigureFailed = "AWRConfigureFailed"
+
+	ONSConfigured      = "ONSConfigured"
+	ONSConfigureFailed = "ONSConfigureFailed"
+
+	SelfTestFailed = "SelfTestFailed"
+
+	StorageMigrationInProgress = "StorageMigrationInProgress"
+	StorageMigrationPrepared   = "StorageMigrationPrepared"
+	StorageMigrationCompleted  = "StorageMigrationCompleted"
+	StorageMigrationFailed     = "StorageMigrationFailed"
+
+	AWRBaselineCaptured = "AWRBaselineCaptured"
+	AWRBaselineFailed   = "AWRBaselineFailed"
+
+	DiskUsageNormal   = "DiskUsageNormal"
+	DiskFullPredicted = "DiskFullPredicted"
+	DiskUsageUnknown  = "DiskUsageUnknown"
+
+	DiskGrown        = "DiskGrown"
+	DiskGrowthFailed = "DiskGrowthFailed"
+
+	DiskAutoResized           = "DiskAutoResized"
+	DiskAutoResizeLimited     = "DiskAutoResizeLimited"
+	DiskAutoResizeCheckFailed = "DiskAutoResizeCheckFailed"
+
+	LicenseDeclareFailed = "LicenseDeclareFailed"
+
+	DeadlockDetected = "DeadlockDetected"
+
+	SQLPlanCaptureStarted = "SQLPlanCaptureStarted"
+	SQLPlanCaptureStopped = "SQLPlanCaptureStopped"
+	SQLPlanCaptureFailed  = "SQLPlanCaptureFailed"
+
+	CloneCreated = "CloneCreated"
+	CloneFailed  = "CloneFailed"
+
+	DRDrillCompleted = "DRDrillCompleted"
+	DRDrillFailed    = "DRDrillFailed"
+
+	PrimaryUnreachable      = "PrimaryUnreachable"
+	PrimaryRecovered        = "PrimaryRecovered"
+	RoleTransitionCompleted = "RoleTransitionCompleted"
+	RoleTransitionFailed    = "RoleTransitionFailed"
+	PrimaryServiceUpdated   = "PrimaryServiceUpdated"
+	PrimaryFenced           = "PrimaryFenced"
+
+	RMANCatalogExported     = "RMANCatalogExported"
+	RMANCatalogExportFailed = "RMANCatalogExportFailed"
+
+	WriteCanarySucceeded = "WriteCanarySucceeded"
+	WriteCanarySlow      = "WriteCanarySlow"
+	WriteCanaryFailed    = "WriteCanaryFailed"
+
+	DBDaemonCertIssued  = "DBDaemonCertIssued"
+	DBDaemonCertRenewed = "DBDaemonCertRenewed"
+
+	OrphanedPDBsFound  = "OrphanedPDBsFound"
+	PDBAdopted         = "PDBAdopted"
+	PDBDropped         = "PDBDropped"
+	PDBReconcileFailed = "PDBReconcileFailed"
+
+	OptionsVerified     = "OptionsVerified"
+	OptionsMissing      = "OptionsMissing"
+	OptionsInvalid      = "OptionsInvalid"
+	OptionsVerifyFailed = "OptionsVerifyFailed"
+	OptionsRecompiled   = "OptionsRecompiled"
+
+	HookSucceeded = "HookSucceeded"
+	HookFailed    = "HookFailed"
+
+	PITRTargetValid       = "PITRTargetValid"
+	PITRTargetInvalid     = "PITRTargetInvalid"
+	PITRTargetCheckFailed = "PITRTargetCheckFailed"
 )
 
 var (
@@ -182,6 +304,17 @@ func Upsert(conditions []v1.Condition, name string, status v1.ConditionStatus, r
 	return conditions
 }
 
+// Remove returns conditions without the condition of type name.
+func Remove(conditions []v1.Condition, name string) []v1.Condition {
+	var kept []v1.Condition
+	for _, cond := range conditions {
+		if cond.Type != name {
+			kept = append(kept, cond)
+		}
+	}
+	return kept
+}
+
 func ElapsedTimeFromLastTransitionTime(condition *v1.Condition, roundTo time.Duration) time.Duration {
 	if condition == nil {
 		return 0
@@ -295,6 +295,21 @@ func TestUpsert(t *testing.T) {
 	}
 }
 
+func TestRemove(t *testing.T) {
+	conditions := []v1.Condition{
+		{Type: "Keep", Status: v1.ConditionTrue},
+		{Type: "TestName", Status: v1.ConditionFalse},
+	}
+
+	got := Remove(conditions, "TestName")
+	if len(got) != 1 || got[0].Type != "Keep" {
+		t.Errorf("Remove got %v, want only the Keep condition", got)
+	}
+	if got := Remove(got, "TestName"); len(got) != 1 {
+		t.Errorf("Remove of a missing condition got %v, want it unchanged", got)
+	}
+}
+
 func TestElapsedTimeFromLastTransitionTime(t *testing.T) {
 	now, err := time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
 	if err != nil {
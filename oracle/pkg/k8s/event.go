@@ -16,12 +16,30 @@ package k8s
 
 // database event reason list
 const (
-	CreatingDatabase      = "Creating"
-	CreatedDatabase       = "Created"
-	DatabaseAlreadyExists = "DatabaseAlreadyExists"
-	CreatingUser          = "Creating"
-	CreatedUser           = "Created"
-	SyncingUser           = "Syncing"
-	SyncedUser            = "Synced"
-	FailedToSyncUser      = "Failed"
+	CreatingDatabase             = "Creating"
+	CreatedDatabase              = "Created"
+	DatabaseAlreadyExists        = "DatabaseAlreadyExists"
+	CreatingUser                 = "Creating"
+	CreatedUser                  = "Created"
+	SyncingUser                  = "Syncing"
+	SyncedUser                   = "Synced"
+	FailedToSyncUser             = "Failed"
+	SyncedInMemory               = "InMemorySynced"
+	FailedToSyncInMemory         = "InMemorySyncFailed"
+	DroppedPartitions            = "PartitionsDropped"
+	FailedToMaintainPartitions   = "PartitionMaintenanceFailed"
+	SyncedNLS                    = "NLSSynced"
+	FailedToSyncNLS              = "NLSSyncFailed"
+	SyncedResources              = "ResourcesSynced"
+	FailedToSyncResources        = "ResourcesSyncFailed"
+	ResourcesDrifted             = "ResourcesDrifted"
+	SyncedRowLevelSecurity       = "RowLevelSecuritySynced"
+	FailedToSyncRowLevelSecurity = "RowLevelSecuritySyncFailed"
+	SyncedEditions               = "EditionsSynced"
+	FailedToSyncEditions         = "EditionsSyncFailed"
+	SyncedServices               = "ServicesSynced"
+	FailedToSyncServices         = "ServicesSyncFailed"
+	SyncedEndpoint               = "EndpointSynced"
+	FailedToSyncEndpoint         = "EndpointSyncFailed"
+	DeletedEndpoint              = "EndpointDeleted"
 )
@@ -39,6 +39,9 @@ type GCSUtil interface {
 	Download(ctx context.Context, gcsPath string) (io.ReadCloser, error)
 	// Delete deletes all objects under given gcsPath
 	Delete(ctx context.Context, gcsPath string) error
+	// Size returns the total size in bytes of all objects under given gcsPath
+	// directory.
+	Size(ctx context.Context, gcsPath string) (int64, error)
 	// UploadFile uploads contents of a file at filepath to gcsPath location in
 	// GCS and sets object's contentType.
 	// If gcsPath ends with .gz it also compresses the uploaded contents
@@ -65,7 +68,7 @@ func (g *GCSUtilImpl) Download(ctx context.Context, gcsPath string) (io.ReadClos
 
 	reader, err := client.Bucket(bucket).Object(name).NewReader(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read URL %s: %v", gcsPath, err)
+		return nil, fmt.Errorf("failed to read URL %s: %w", gcsPath, err)
 	}
 
 	return reader, nil
@@ -170,6 +173,46 @@ func (g *GCSUtilImpl) Delete(ctx context.Context, gcsPath string) error {
 	return nil
 }
 
+func (g *GCSUtilImpl) Size(ctx context.Context, gcsPath string) (int64, error) {
+	bucket, prefix, err := g.SplitURI(gcsPath)
+	if err != nil {
+		return 0, err
+	}
+	prefix = dirPrefix(prefix)
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to init GCS client: %v", err)
+	}
+	defer client.Close()
+
+	it := client.Bucket(bucket).Objects(ctx, &storage.Query{
+		Prefix: prefix,
+	})
+	var size int64
+	for {
+		objAttrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("Bucket(%q).Objects(): %v", bucket, err)
+		}
+		size += objAttrs.Size
+	}
+	return size, nil
+}
+
+// dirPrefix returns the listing prefix of the objects under the directory
+// prefix. Without a trailing slash, the prefix of gs://bucket/b1 also lists
+// the objects of gs://bucket/b10.
+func dirPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
 // Contains check whether given "elem" presents in "array"
 func Contains(array []string, elem string) bool {
 	for _, v := range array {
@@ -15,6 +15,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -57,3 +58,33 @@ func TestGcsUtilImplSplitURIError(t *testing.T) {
 		}
 	}
 }
+
+func TestDirPrefix(t *testing.T) {
+	objects := []string{
+		"backups/b1/backupset_1.bkp",
+		"backups/b1/metadata.json",
+		"backups/b10/backupset_1.bkp",
+		"backups/b11.json",
+	}
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{"backups/b1", 2},
+		{"backups/b1/", 2},
+		{"backups/b10", 1},
+		{"backups", 4},
+	}
+
+	for _, test := range tests {
+		got := 0
+		for _, name := range objects {
+			if strings.HasPrefix(name, dirPrefix(test.prefix)) {
+				got++
+			}
+		}
+		if got != test.want {
+			t.Errorf("dirPrefix(%q)=%q lists %d objects; wanted %d", test.prefix, dirPrefix(test.prefix), got, test.want)
+		}
+	}
+}